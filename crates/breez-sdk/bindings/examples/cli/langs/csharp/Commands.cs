@@ -520,7 +520,8 @@ public static class Commands
                     tokenIdentifier: tokenIdentifier,
                     expiryTime: expiryTime,
                     description: description,
-                    senderPublicKey: senderPubKey
+                    senderPublicKey: senderPubKey,
+                    acceptPartialPayments: null
                 );
                 break;
 
@@ -336,6 +336,7 @@ Future<void> _handleReceive(BreezSdk sdk, TokenIssuer tokenIssuer, List<String>
         expiryTime: expiryTime,
         description: description,
         senderPublicKey: senderPublicKey,
+        acceptPartialPayments: null,
       );
     case 'bitcoin':
       paymentMethod = ReceivePaymentMethod.bitcoinAddress(newAddress: newAddress);
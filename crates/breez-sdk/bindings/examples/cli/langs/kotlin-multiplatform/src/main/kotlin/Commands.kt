@@ -341,6 +341,7 @@ suspend fun handleReceive(sdk: BreezSdk, reader: LineReader, args: List<String>)
                 expiryTime = expiryTime,
                 description = description,
                 senderPublicKey = senderPublicKey,
+                acceptPartialPayments = null,
             )
         }
 
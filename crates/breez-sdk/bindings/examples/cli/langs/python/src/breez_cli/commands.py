@@ -273,6 +273,7 @@ async def _handle_receive(sdk, _token_issuer, _session, args):
             expiry_time=expiry_time,
             description=args.description,
             sender_public_key=args.sender_public_key,
+            accept_partial_payments=None,
         )
     elif method == "bitcoin":
         payment_method = ReceivePaymentMethod.BITCOIN_ADDRESS(
@@ -489,6 +489,7 @@ async function handleReceive(sdk: BreezSdkInterface, _tokenIssuer: TokenIssuerIn
         expiryTime,
         description,
         senderPublicKey,
+        acceptPartialPayments: undefined,
       })
       break
     }
@@ -381,7 +381,8 @@ func handleReceive(_ sdk: BreezSdk, _ args: [String]) async throws {
             tokenIdentifier: tokenIdentifier,
             expiryTime: expiryTime,
             description: description,
-            senderPublicKey: senderPublicKey
+            senderPublicKey: senderPublicKey,
+            acceptPartialPayments: nil
         )
 
     case "bitcoin":
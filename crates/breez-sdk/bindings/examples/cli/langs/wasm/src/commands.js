@@ -245,7 +245,8 @@ function buildProgram(getSdk, getTokenIssuer, getGetSparkStatus, rl) {
             tokenIdentifier: options.tokenIdentifier,
             expiryTime,
             description: options.description,
-            senderPublicKey: options.senderPublicKey
+            senderPublicKey: options.senderPublicKey,
+            acceptPartialPayments: undefined
           }
           break
         }
@@ -0,0 +1,27 @@
+//go:build !breez_sdk_spark_static && !breez_sdk_spark_external
+
+package breez_sdk_spark
+
+// Links the bundled shared library for the target platform. On Linux the
+// executable looks for the library next to itself, in ./lib relative to
+// itself, and in the module directory it was built from.
+
+/*
+#cgo !ios LDFLAGS: -lbreez_sdk_spark_bindings
+#cgo android,arm64 LDFLAGS: -L${SRCDIR}/lib/android-aarch64
+#cgo android,arm LDFLAGS: -L${SRCDIR}/lib/android-aarch
+#cgo android,386 LDFLAGS: -L${SRCDIR}/lib/android-386
+#cgo android,amd64 LDFLAGS: -L${SRCDIR}/lib/android-amd64
+#cgo darwin,!ios,arm64 LDFLAGS: -Wl,-rpath,${SRCDIR}/lib/darwin-aarch64 -L${SRCDIR}/lib/darwin-aarch64
+#cgo darwin,!ios,amd64 LDFLAGS: -Wl,-rpath,${SRCDIR}/lib/darwin-amd64 -L${SRCDIR}/lib/darwin-amd64
+#cgo linux,!android,arm64 LDFLAGS: -Wl,-rpath,$ORIGIN -Wl,-rpath,$ORIGIN/lib -Wl,-rpath,${SRCDIR}/lib/linux-aarch64 -L${SRCDIR}/lib/linux-aarch64
+#cgo linux,!android,amd64 LDFLAGS: -Wl,-rpath,$ORIGIN -Wl,-rpath,$ORIGIN/lib -Wl,-rpath,${SRCDIR}/lib/linux-amd64 -L${SRCDIR}/lib/linux-amd64
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/lib/windows-amd64
+*/
+import "C"
+
+// LinkMode is how the native library was linked into this build: "dynamic",
+// "static" or "external". It's fixed at build time by the build tags; the
+// library path can't be changed at runtime, since the dynamic loader resolves
+// the library before main runs.
+const LinkMode = "dynamic"
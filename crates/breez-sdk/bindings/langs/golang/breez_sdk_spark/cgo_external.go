@@ -0,0 +1,21 @@
+//go:build breez_sdk_spark_external && !breez_sdk_spark_static
+
+package breez_sdk_spark
+
+// Links a library provided by the build environment instead of the bundled
+// ones, e.g. when cross-compiling for a platform without a prebuilt library.
+// Enabled with the breez_sdk_spark_external build tag. Point the linker at
+// the library with CGO_LDFLAGS="-L/path/to/lib". At runtime the shared
+// library is looked up in the platform's library search path
+// (LD_LIBRARY_PATH, DYLD_LIBRARY_PATH or PATH) and, on Linux, next to the
+// executable and in ./lib relative to it.
+
+/*
+#cgo !ios LDFLAGS: -lbreez_sdk_spark_bindings
+#cgo linux,!android LDFLAGS: -Wl,-rpath,$ORIGIN -Wl,-rpath,$ORIGIN/lib
+*/
+import "C"
+
+// LinkMode is how the native library was linked into this build: "dynamic",
+// "static" or "external".
+const LinkMode = "external"
@@ -0,0 +1,21 @@
+//go:build breez_sdk_spark_static
+
+package breez_sdk_spark
+
+// Links the bundled static archive for the target platform into the
+// executable, so it can be deployed without the shared library. Enabled with
+// the breez_sdk_spark_static build tag. Available for Linux and macOS.
+
+/*
+#cgo darwin,!ios,arm64 LDFLAGS: ${SRCDIR}/lib/static/darwin-aarch64/libbreez_sdk_spark_bindings.a
+#cgo darwin,!ios,amd64 LDFLAGS: ${SRCDIR}/lib/static/darwin-amd64/libbreez_sdk_spark_bindings.a
+#cgo darwin,!ios LDFLAGS: -framework CoreFoundation -framework Security -framework SystemConfiguration -lc++
+#cgo linux,!android,arm64 LDFLAGS: ${SRCDIR}/lib/static/linux-aarch64/libbreez_sdk_spark_bindings.a
+#cgo linux,!android,amd64 LDFLAGS: ${SRCDIR}/lib/static/linux-amd64/libbreez_sdk_spark_bindings.a
+#cgo linux,!android LDFLAGS: -lm -ldl -lpthread
+*/
+import "C"
+
+// LinkMode is how the native library was linked into this build: "dynamic",
+// "static" or "external".
+const LinkMode = "static"
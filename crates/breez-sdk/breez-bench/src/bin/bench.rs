@@ -278,13 +278,17 @@ async fn main() -> Result<()> {
         Network::Mainnet => {
             // Check balances and consolidate to sender
             info!("Checking balances...");
-            sender.sdk.sync_wallet(SyncWalletRequest {}).await?;
-            receiver.sdk.sync_wallet(SyncWalletRequest {}).await?;
+            sender.sdk.sync_wallet(SyncWalletRequest::default()).await?;
+            receiver
+                .sdk
+                .sync_wallet(SyncWalletRequest::default())
+                .await?;
 
             let sender_balance = sender
                 .sdk
                 .get_info(GetInfoRequest {
                     ensure_synced: Some(false),
+                    ..Default::default()
                 })
                 .await?
                 .balance_sats;
@@ -292,6 +296,7 @@ async fn main() -> Result<()> {
                 .sdk
                 .get_info(GetInfoRequest {
                     ensure_synced: Some(false),
+                    ..Default::default()
                 })
                 .await?
                 .balance_sats;
@@ -315,6 +320,7 @@ async fn main() -> Result<()> {
                         token_identifier: None,
                         conversion_options: None,
                         fee_policy: None,
+                        fiat_amount: None,
                     })
                     .await?;
 
@@ -324,6 +330,9 @@ async fn main() -> Result<()> {
                         prepare_response: prepare,
                         options: None,
                         idempotency_key: None,
+                        max_fee: None,
+                        deliver_after: None,
+                        leaf_selection: None,
                     })
                     .await?;
 
@@ -333,11 +342,12 @@ async fn main() -> Result<()> {
             }
 
             // Now check if sender has minimum balance
-            sender.sdk.sync_wallet(SyncWalletRequest {}).await?;
+            sender.sdk.sync_wallet(SyncWalletRequest::default()).await?;
             let sender_balance = sender
                 .sdk
                 .get_info(GetInfoRequest {
                     ensure_synced: Some(false),
+                    ..Default::default()
                 })
                 .await?
                 .balance_sats;
@@ -378,6 +388,7 @@ async fn main() -> Result<()> {
             .sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?
             .balance_sats;
@@ -390,11 +401,15 @@ async fn main() -> Result<()> {
             );
 
             // Try to get funds back from receiver
-            receiver.sdk.sync_wallet(SyncWalletRequest {}).await?;
+            receiver
+                .sdk
+                .sync_wallet(SyncWalletRequest::default())
+                .await?;
             let receiver_balance = receiver
                 .sdk
                 .get_info(GetInfoRequest {
                     ensure_synced: Some(false),
+                    ..Default::default()
                 })
                 .await?
                 .balance_sats;
@@ -420,11 +435,12 @@ async fn main() -> Result<()> {
             }
 
             // Re-check sender balance after potential return
-            sender.sdk.sync_wallet(SyncWalletRequest {}).await?;
+            sender.sdk.sync_wallet(SyncWalletRequest::default()).await?;
             let new_sender_balance = sender
                 .sdk
                 .get_info(GetInfoRequest {
                     ensure_synced: Some(false),
+                    ..Default::default()
                 })
                 .await?
                 .balance_sats;
@@ -466,6 +482,7 @@ async fn main() -> Result<()> {
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await;
 
@@ -483,6 +500,9 @@ async fn main() -> Result<()> {
                 prepare_response: prepare,
                 options: None,
                 idempotency_key: None,
+                max_fee: None,
+                deliver_after: None,
+                leaf_selection: None,
             })
             .await;
 
@@ -568,11 +588,15 @@ async fn main() -> Result<()> {
     // On mainnet, move all funds back to sender at the end
     if is_mainnet(network) {
         info!("Moving all funds back to sender...");
-        receiver.sdk.sync_wallet(SyncWalletRequest {}).await?;
+        receiver
+            .sdk
+            .sync_wallet(SyncWalletRequest::default())
+            .await?;
         let receiver_final_balance = receiver
             .sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?
             .balance_sats;
@@ -592,12 +616,16 @@ async fn main() -> Result<()> {
         }
 
         // Print final balances
-        sender.sdk.sync_wallet(SyncWalletRequest {}).await?;
-        receiver.sdk.sync_wallet(SyncWalletRequest {}).await?;
+        sender.sdk.sync_wallet(SyncWalletRequest::default()).await?;
+        receiver
+            .sdk
+            .sync_wallet(SyncWalletRequest::default())
+            .await?;
         let sender_final = sender
             .sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?
             .balance_sats;
@@ -605,6 +633,7 @@ async fn main() -> Result<()> {
             .sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?
             .balance_sats;
@@ -725,6 +754,7 @@ async fn initialize_mainnet_sdk(
     let _ = sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -822,6 +852,7 @@ async fn return_funds_to_sender(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -831,6 +862,9 @@ async fn return_funds_to_sender(
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -842,11 +876,15 @@ async fn return_funds_to_sender(
 async fn fund_via_faucet(sdk_instance: &mut BenchSdkInstance, min_balance: u64) -> Result<()> {
     use breez_sdk_itest::RegtestFaucet;
 
-    sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk_instance
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
     let info = sdk_instance
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
 
@@ -883,11 +921,15 @@ async fn fund_via_faucet(sdk_instance: &mut BenchSdkInstance, min_balance: u64)
     // Wait for balance to update
     wait_for_balance(&sdk_instance.sdk, info.balance_sats + 1, 20).await?;
 
-    sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk_instance
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
     let final_info = sdk_instance
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     info!("Funded. New balance: {} sats", final_info.balance_sats);
@@ -904,6 +946,7 @@ async fn wait_for_balance(sdk: &BreezSdk, min_balance: u64, timeout_secs: u64) -
         let info = sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?;
 
@@ -249,6 +249,7 @@ async fn run_single_claim_benchmark(
                         token_identifier: None,
                         conversion_options: None,
                         fee_policy: None,
+                        fiat_amount: None,
                     })
                     .await?;
 
@@ -256,6 +257,9 @@ async fn run_single_claim_benchmark(
                     prepare_response: prepare,
                     options: None,
                     idempotency_key: None,
+                    max_fee: None,
+                    deliver_after: None,
+                    leaf_selection: None,
                 })
                 .await?;
 
@@ -383,6 +387,7 @@ async fn run_single_claim_benchmark(
     let final_info = receiver_sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -444,7 +449,7 @@ async fn fund_sdk_via_faucet(
     events: &mut mpsc::Receiver<SdkEvent>,
     amount: u64,
 ) -> Result<()> {
-    sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     // Get deposit address
     let receive = sdk
@@ -467,10 +472,11 @@ async fn fund_sdk_via_faucet(
     let start = Instant::now();
     let timeout = Duration::from_secs(30);
     loop {
-        sdk.sync_wallet(SyncWalletRequest {}).await?;
+        sdk.sync_wallet(SyncWalletRequest::default()).await?;
         let info = sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?;
 
@@ -405,6 +405,7 @@ async fn execute_single_payment(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -413,6 +414,9 @@ async fn execute_single_payment(
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -792,7 +796,10 @@ async fn fund_via_faucet(
     const FAUCET_MAX_PER_CALL: u64 = 50_000;
     const FAUCET_MIN_PER_CALL: u64 = 1_000;
 
-    sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk_instance
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
 
     let receive = sdk_instance
         .sdk
@@ -808,11 +815,15 @@ async fn fund_via_faucet(
     let mut remaining = target_amount;
     let mut chunk_idx = 0u32;
     while remaining > 0 {
-        sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+        sdk_instance
+            .sdk
+            .sync_wallet(SyncWalletRequest::default())
+            .await?;
         let info = sdk_instance
             .sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?;
         if info.balance_sats >= min_required {
@@ -840,11 +851,15 @@ async fn fund_via_faucet(
         let claim_timeout = Duration::from_secs(240);
         let balance_before = info.balance_sats;
         let after = loop {
-            sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+            sdk_instance
+                .sdk
+                .sync_wallet(SyncWalletRequest::default())
+                .await?;
             let snap = sdk_instance
                 .sdk
                 .get_info(GetInfoRequest {
                     ensure_synced: Some(false),
+                    ..Default::default()
                 })
                 .await?;
             if snap.balance_sats > balance_before {
@@ -877,11 +892,15 @@ async fn fund_via_faucet(
     let start = Instant::now();
     let timeout = Duration::from_secs(60);
     loop {
-        sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+        sdk_instance
+            .sdk
+            .sync_wallet(SyncWalletRequest::default())
+            .await?;
         let info = sdk_instance
             .sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?;
         if info.balance_sats >= min_required {
@@ -905,11 +924,15 @@ async fn fund_via_faucet(
             let claim_timeout = Duration::from_secs(240);
             let balance_before = info.balance_sats;
             let after = loop {
-                sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+                sdk_instance
+                    .sdk
+                    .sync_wallet(SyncWalletRequest::default())
+                    .await?;
                 let snap = sdk_instance
                     .sdk
                     .get_info(GetInfoRequest {
                         ensure_synced: Some(false),
+                        ..Default::default()
                     })
                     .await?;
                 if snap.balance_sats > balance_before {
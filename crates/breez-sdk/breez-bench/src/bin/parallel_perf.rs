@@ -418,6 +418,7 @@ async fn execute_single_payment(sender: &BreezSdk, payment_type: &PaymentType) -
                     token_identifier: None,
                     conversion_options: None,
                     fee_policy: None,
+                    fiat_amount: None,
                 })
                 .await?;
 
@@ -426,6 +427,9 @@ async fn execute_single_payment(sender: &BreezSdk, payment_type: &PaymentType) -
                     prepare_response: prepare,
                     options: None,
                     idempotency_key: None,
+                    max_fee: None,
+                    deliver_after: None,
+                    leaf_selection: None,
                 })
                 .await?;
 
@@ -441,6 +445,7 @@ async fn execute_single_payment(sender: &BreezSdk, payment_type: &PaymentType) -
                     token_identifier: None,
                     conversion_options: None,
                     fee_policy: None,
+                    fiat_amount: None,
                 })
                 .await?;
 
@@ -449,6 +454,9 @@ async fn execute_single_payment(sender: &BreezSdk, payment_type: &PaymentType) -
                     prepare_response: prepare,
                     options: None,
                     idempotency_key: None,
+                    max_fee: None,
+                    deliver_after: None,
+                    leaf_selection: None,
                 })
                 .await?;
 
@@ -683,7 +691,10 @@ async fn run_optimization(sdk: &BreezSdk, label: &str) -> Result<()> {
 
 /// Fund wallet via regtest faucet
 async fn fund_via_faucet(sdk_instance: &mut BenchSdkInstance, amount: u64) -> Result<()> {
-    sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk_instance
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
 
     // Get deposit address
     let receive = sdk_instance
@@ -707,11 +718,15 @@ async fn fund_via_faucet(sdk_instance: &mut BenchSdkInstance, amount: u64) -> Re
     let start = Instant::now();
     let timeout = Duration::from_secs(30);
     loop {
-        sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+        sdk_instance
+            .sdk
+            .sync_wallet(SyncWalletRequest::default())
+            .await?;
         let info = sdk_instance
             .sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?;
 
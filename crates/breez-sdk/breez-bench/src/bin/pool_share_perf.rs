@@ -143,6 +143,7 @@ async fn main() -> Result<()> {
     let futs = sdks.iter().map(|sdk| {
         sdk.get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
     });
     try_join_all(futs).await?;
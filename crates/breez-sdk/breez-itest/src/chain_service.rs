@@ -220,6 +220,15 @@ impl BitcoinChainService for LocalBitcoindChainService {
         Ok(())
     }
 
+    async fn broadcast_package(&self, txs: Vec<String>) -> Result<(), ChainServiceError> {
+        let _: serde_json::Value = self
+            .bitcoind
+            .rpc("submitpackage", &[json!(txs)])
+            .await
+            .map_err(to_chain_err)?;
+        Ok(())
+    }
+
     async fn recommended_fees(&self) -> Result<RecommendedFees, ChainServiceError> {
         Ok(RecommendedFees {
             fastest_fee: 1,
@@ -229,6 +238,13 @@ impl BitcoinChainService for LocalBitcoindChainService {
             minimum_fee: 1,
         })
     }
+
+    async fn get_tip_height(&self) -> Result<u32, ChainServiceError> {
+        self.bitcoind
+            .rpc("getblockcount", &[])
+            .await
+            .map_err(to_chain_err)
+    }
 }
 
 /// Every confirmed output ever paid to `script_hex`, spent or not. bitcoind has
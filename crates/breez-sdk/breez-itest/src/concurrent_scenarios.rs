@@ -116,6 +116,7 @@ where
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -127,9 +128,9 @@ where
     // Scenario 1: Concurrent sync.
     info!("=== Scenario 1: Concurrent sync ===");
     let (sync_0, sync_1, sync_2) = tokio::join!(
-        instance_0.sdk.sync_wallet(SyncWalletRequest {}),
-        instance_1.sdk.sync_wallet(SyncWalletRequest {}),
-        instance_2.sdk.sync_wallet(SyncWalletRequest {})
+        instance_0.sdk.sync_wallet(SyncWalletRequest::default()),
+        instance_1.sdk.sync_wallet(SyncWalletRequest::default()),
+        instance_2.sdk.sync_wallet(SyncWalletRequest::default())
     );
     sync_0?;
     sync_1?;
@@ -177,6 +178,7 @@ where
             token_identifier: None,
             fee_policy: None,
             conversion_options: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -185,9 +187,12 @@ where
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         }),
-        instance_1.sdk.sync_wallet(SyncWalletRequest {}),
-        instance_2.sdk.sync_wallet(SyncWalletRequest {})
+        instance_1.sdk.sync_wallet(SyncWalletRequest::default()),
+        instance_2.sdk.sync_wallet(SyncWalletRequest::default())
     );
 
     send_result?;
@@ -198,9 +203,9 @@ where
     expected_payment_count += 1;
 
     let (_, _, _) = tokio::join!(
-        instance_0.sdk.sync_wallet(SyncWalletRequest {}),
-        instance_1.sdk.sync_wallet(SyncWalletRequest {}),
-        instance_2.sdk.sync_wallet(SyncWalletRequest {})
+        instance_0.sdk.sync_wallet(SyncWalletRequest::default()),
+        instance_1.sdk.sync_wallet(SyncWalletRequest::default()),
+        instance_2.sdk.sync_wallet(SyncWalletRequest::default())
     );
 
     let payments_0 = instance_0
@@ -233,6 +238,7 @@ where
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -267,6 +273,7 @@ where
             token_identifier: None,
             fee_policy: None,
             conversion_options: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -276,6 +283,9 @@ where
             prepare_response: prepare_return,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     expected_payment_count += 1;
@@ -296,8 +306,8 @@ where
     // we drive an explicit 3-way sync at the cross-instance read boundary.
     if matches!(mode, RuntimeMode::Server) {
         let (sync_1, sync_2) = tokio::join!(
-            instance_1.sdk.sync_wallet(SyncWalletRequest {}),
-            instance_2.sdk.sync_wallet(SyncWalletRequest {})
+            instance_1.sdk.sync_wallet(SyncWalletRequest::default()),
+            instance_2.sdk.sync_wallet(SyncWalletRequest::default())
         );
         sync_1?;
         sync_2?;
@@ -308,9 +318,18 @@ where
         RuntimeMode::Server => Some(false),
     };
     let (info_0, info_1, info_2) = tokio::join!(
-        instance_0.sdk.get_info(GetInfoRequest { ensure_synced }),
-        instance_1.sdk.get_info(GetInfoRequest { ensure_synced }),
-        instance_2.sdk.get_info(GetInfoRequest { ensure_synced })
+        instance_0.sdk.get_info(GetInfoRequest {
+            ensure_synced,
+            ..Default::default()
+        }),
+        instance_1.sdk.get_info(GetInfoRequest {
+            ensure_synced,
+            ..Default::default()
+        }),
+        instance_2.sdk.get_info(GetInfoRequest {
+            ensure_synced,
+            ..Default::default()
+        })
     );
 
     let balance_0 = info_0?.balance_sats;
@@ -355,6 +374,7 @@ where
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await?;
 
@@ -365,9 +385,12 @@ where
                         prepare_response: prepare,
                         options: None,
                         idempotency_key: None,
+                        max_fee: None,
+                        deliver_after: None,
+                        leaf_selection: None,
                     }),
-                    instances[1].sdk.sync_wallet(SyncWalletRequest {}),
-                    instances[2].sdk.sync_wallet(SyncWalletRequest {})
+                    instances[1].sdk.sync_wallet(SyncWalletRequest::default()),
+                    instances[2].sdk.sync_wallet(SyncWalletRequest::default())
                 );
                 send?;
                 s1?;
@@ -375,13 +398,16 @@ where
             }
             1 => {
                 let (s0, send, s2) = tokio::join!(
-                    instances[0].sdk.sync_wallet(SyncWalletRequest {}),
+                    instances[0].sdk.sync_wallet(SyncWalletRequest::default()),
                     instances[1].sdk.send_payment(SendPaymentRequest {
                         prepare_response: prepare,
                         options: None,
                         idempotency_key: None,
+                        max_fee: None,
+                        deliver_after: None,
+                        leaf_selection: None,
                     }),
-                    instances[2].sdk.sync_wallet(SyncWalletRequest {})
+                    instances[2].sdk.sync_wallet(SyncWalletRequest::default())
                 );
                 s0?;
                 send?;
@@ -389,12 +415,15 @@ where
             }
             2 => {
                 let (s0, s1, send) = tokio::join!(
-                    instances[0].sdk.sync_wallet(SyncWalletRequest {}),
-                    instances[1].sdk.sync_wallet(SyncWalletRequest {}),
+                    instances[0].sdk.sync_wallet(SyncWalletRequest::default()),
+                    instances[1].sdk.sync_wallet(SyncWalletRequest::default()),
                     instances[2].sdk.send_payment(SendPaymentRequest {
                         prepare_response: prepare,
                         options: None,
                         idempotency_key: None,
+                        max_fee: None,
+                        deliver_after: None,
+                        leaf_selection: None,
                     })
                 );
                 s0?;
@@ -410,9 +439,9 @@ where
         current_balance -= payment_amt;
 
         let (_, _, _) = tokio::join!(
-            instances[0].sdk.sync_wallet(SyncWalletRequest {}),
-            instances[1].sdk.sync_wallet(SyncWalletRequest {}),
-            instances[2].sdk.sync_wallet(SyncWalletRequest {})
+            instances[0].sdk.sync_wallet(SyncWalletRequest::default()),
+            instances[1].sdk.sync_wallet(SyncWalletRequest::default()),
+            instances[2].sdk.sync_wallet(SyncWalletRequest::default())
         );
 
         info!(
@@ -424,9 +453,9 @@ where
     // Final verification.
     info!("=== Final verification ===");
     let (_, _, _) = tokio::join!(
-        instances[0].sdk.sync_wallet(SyncWalletRequest {}),
-        instances[1].sdk.sync_wallet(SyncWalletRequest {}),
-        instances[2].sdk.sync_wallet(SyncWalletRequest {})
+        instances[0].sdk.sync_wallet(SyncWalletRequest::default()),
+        instances[1].sdk.sync_wallet(SyncWalletRequest::default()),
+        instances[2].sdk.sync_wallet(SyncWalletRequest::default())
     );
 
     let final_payments_0 = instances[0]
@@ -455,13 +484,16 @@ where
 
     let (info_0, info_1, info_2) = tokio::join!(
         instances[0].sdk.get_info(GetInfoRequest {
-            ensure_synced: Some(false)
+            ensure_synced: Some(false),
+            ..Default::default()
         }),
         instances[1].sdk.get_info(GetInfoRequest {
-            ensure_synced: Some(false)
+            ensure_synced: Some(false),
+            ..Default::default()
         }),
         instances[2].sdk.get_info(GetInfoRequest {
-            ensure_synced: Some(false)
+            ensure_synced: Some(false),
+            ..Default::default()
         })
     );
 
@@ -513,11 +545,17 @@ where
         .await?;
 
     issuer
-        .mint_issuer_token(MintIssuerTokenRequest { amount: 1_000_000 })
+        .mint_issuer_token(MintIssuerTokenRequest {
+            amount: 1_000_000,
+            idempotency_key: None,
+        })
         .await?;
 
     tokio::time::sleep(std::time::Duration::from_secs(1)).await;
-    instance_0.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    instance_0
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
 
     let token_id = token_metadata.identifier.clone();
     info!("Token created: {} ({})", token_metadata.name, token_id);
@@ -551,6 +589,7 @@ where
             token_identifier: Some(token_id.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -560,12 +599,18 @@ where
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
     wait_for_token_balance_increase(&bob.sdk, &token_id, 0, 60).await?;
 
-    instance_0.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    instance_0
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
     let mut alice_token_balance = get_token_balance(&instance_0.sdk, &token_id).await?;
     let mut bob_token_balance = get_token_balance(&bob.sdk, &token_id).await?;
     assert_eq!(alice_token_balance, 500_000);
@@ -580,9 +625,9 @@ where
     // Phase 1: Concurrent sync verification.
     info!("=== Phase 1: Concurrent sync verification ===");
     let (sync_0, sync_1, sync_2) = tokio::join!(
-        instance_0.sdk.sync_wallet(SyncWalletRequest {}),
-        instance_1.sdk.sync_wallet(SyncWalletRequest {}),
-        instance_2.sdk.sync_wallet(SyncWalletRequest {})
+        instance_0.sdk.sync_wallet(SyncWalletRequest::default()),
+        instance_1.sdk.sync_wallet(SyncWalletRequest::default()),
+        instance_2.sdk.sync_wallet(SyncWalletRequest::default())
     );
     sync_0?;
     sync_1?;
@@ -646,6 +691,7 @@ where
                     token_identifier: Some(token_id.clone()),
                     conversion_options: None,
                     fee_policy: None,
+                    fiat_amount: None,
                 })
                 .await?;
 
@@ -657,13 +703,16 @@ where
                             prepare_response: prepare,
                             options: None,
                             idempotency_key: None,
+                            max_fee: None,
+                            deliver_after: None,
+                            leaf_selection: None,
                         }),
                         instances[syncer_idxs[0]]
                             .sdk
-                            .sync_wallet(SyncWalletRequest {}),
+                            .sync_wallet(SyncWalletRequest::default()),
                         instances[syncer_idxs[1]]
                             .sdk
-                            .sync_wallet(SyncWalletRequest {})
+                            .sync_wallet(SyncWalletRequest::default())
                     );
                     send?;
                     s1?;
@@ -673,15 +722,18 @@ where
                     let (s0, send, s2) = tokio::join!(
                         instances[syncer_idxs[0]]
                             .sdk
-                            .sync_wallet(SyncWalletRequest {}),
+                            .sync_wallet(SyncWalletRequest::default()),
                         instances[1].sdk.send_payment(SendPaymentRequest {
                             prepare_response: prepare,
                             options: None,
                             idempotency_key: None,
+                            max_fee: None,
+                            deliver_after: None,
+                            leaf_selection: None,
                         }),
                         instances[syncer_idxs[1]]
                             .sdk
-                            .sync_wallet(SyncWalletRequest {})
+                            .sync_wallet(SyncWalletRequest::default())
                     );
                     s0?;
                     send?;
@@ -691,14 +743,17 @@ where
                     let (s0, s1, send) = tokio::join!(
                         instances[syncer_idxs[0]]
                             .sdk
-                            .sync_wallet(SyncWalletRequest {}),
+                            .sync_wallet(SyncWalletRequest::default()),
                         instances[syncer_idxs[1]]
                             .sdk
-                            .sync_wallet(SyncWalletRequest {}),
+                            .sync_wallet(SyncWalletRequest::default()),
                         instances[2].sdk.send_payment(SendPaymentRequest {
                             prepare_response: prepare,
                             options: None,
                             idempotency_key: None,
+                            max_fee: None,
+                            deliver_after: None,
+                            leaf_selection: None,
                         })
                     );
                     s0?;
@@ -740,6 +795,7 @@ where
                     token_identifier: Some(token_id.clone()),
                     conversion_options: None,
                     fee_policy: None,
+                    fiat_amount: None,
                 })
                 .await?;
 
@@ -748,10 +804,13 @@ where
                     prepare_response: prepare,
                     options: None,
                     idempotency_key: None,
+                    max_fee: None,
+                    deliver_after: None,
+                    leaf_selection: None,
                 }),
-                instances[0].sdk.sync_wallet(SyncWalletRequest {}),
-                instances[1].sdk.sync_wallet(SyncWalletRequest {}),
-                instances[2].sdk.sync_wallet(SyncWalletRequest {})
+                instances[0].sdk.sync_wallet(SyncWalletRequest::default()),
+                instances[1].sdk.sync_wallet(SyncWalletRequest::default()),
+                instances[2].sdk.sync_wallet(SyncWalletRequest::default())
             );
             send?;
             s0?;
@@ -775,9 +834,9 @@ where
     // Phase 3: Final verification.
     info!("=== Phase 3: Final verification ===");
     let (s0, s1, s2) = tokio::join!(
-        instances[0].sdk.sync_wallet(SyncWalletRequest {}),
-        instances[1].sdk.sync_wallet(SyncWalletRequest {}),
-        instances[2].sdk.sync_wallet(SyncWalletRequest {})
+        instances[0].sdk.sync_wallet(SyncWalletRequest::default()),
+        instances[1].sdk.sync_wallet(SyncWalletRequest::default()),
+        instances[2].sdk.sync_wallet(SyncWalletRequest::default())
     );
     s0?;
     s1?;
@@ -810,7 +869,7 @@ where
     assert_eq!(ids_1, ids_2);
     assert_eq!(ids_0.len(), expected_token_payment_count);
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final = get_token_balance(&bob.sdk, &token_id).await?;
     assert_eq!(bob_final, bob_token_balance);
 
@@ -827,6 +886,7 @@ async fn get_token_balance(sdk: &BreezSdk, token_identifier: &str) -> Result<u12
     let info = sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     Ok(info
@@ -101,6 +101,7 @@ pub async fn alice_sdk_manual_opt() -> Result<SdkInstance> {
     cfg.leaf_optimization_config = LeafOptimizationConfig {
         auto_enabled: false,
         multiplicity: 15,
+        auto_policy: None,
     };
     build_sdk_with_custom_config(path, seed, cfg, Some(dir), true).await
 }
@@ -121,6 +122,7 @@ pub async fn alice_server_sdk_manual_opt() -> Result<SdkInstance> {
     cfg.leaf_optimization_config = LeafOptimizationConfig {
         auto_enabled: false,
         multiplicity: 15,
+        auto_policy: None,
     };
     build_sdk_with_custom_config(path, seed, cfg, Some(dir), true).await
 }
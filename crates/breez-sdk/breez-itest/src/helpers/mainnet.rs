@@ -93,6 +93,7 @@ pub async fn build_mainnet_sdk_from_mnemonic(
     let _ = sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -203,7 +204,7 @@ pub async fn build_mainnet_sdk_server_mode_from_mnemonic(
     // Server mode rejects `ensure_synced=true` (no background sync to await);
     // drive the initial sync explicitly so the tree-store hydrate completes
     // before the caller proceeds.
-    sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     Ok(SdkInstance {
         sdk,
@@ -299,6 +300,7 @@ pub async fn estimate_tobtc_sats_out(
                 completion_timeout_secs: None,
             }),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
     Ok(prepared
@@ -363,18 +365,20 @@ pub async fn snapshot_test_pair(
     bob: &SdkInstance,
     token_id: &str,
 ) -> Result<MainnetTestSnapshot> {
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_info = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     let bob_info = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     let token_balance = |info: &GetInfoResponse| -> u128 {
@@ -478,6 +482,7 @@ pub async fn mainnet_cross_chain_setup() -> Result<Option<(SdkInstance, String,
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -578,11 +583,15 @@ pub async fn ensure_wallet_has_tokens(
     min_required: u128,
     topup_amount: u128,
 ) -> Result<bool> {
-    recipient.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    recipient
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
     let before = recipient
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -617,6 +626,7 @@ pub async fn ensure_wallet_has_tokens(
                 completion_timeout_secs: None,
             }),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
     let estimate = topup_prepare
@@ -629,6 +639,7 @@ pub async fn ensure_wallet_has_tokens(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -646,6 +657,9 @@ pub async fn ensure_wallet_has_tokens(
             prepare_response: topup_prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     wait_for_token_balance_increase(&recipient.sdk, token_id, before, 120).await?;
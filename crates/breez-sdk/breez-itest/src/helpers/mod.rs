@@ -87,10 +87,11 @@ pub async fn wait_for_balance(
     wait_for(
         || async {
             // Sync wallet to ensure we have the latest balance
-            sdk.sync_wallet(SyncWalletRequest {}).await?;
+            sdk.sync_wallet(SyncWalletRequest::default()).await?;
             let info = sdk
                 .get_info(GetInfoRequest {
                     ensure_synced: Some(false),
+                    ..Default::default()
                 })
                 .await?;
 
@@ -158,10 +159,11 @@ pub async fn wait_for_token_balance_increase(
             let sdk = sdk.clone();
             let token_id = token_id.clone();
             async move {
-                sdk.sync_wallet(SyncWalletRequest {}).await?;
+                sdk.sync_wallet(SyncWalletRequest::default()).await?;
                 let info = sdk
                     .get_info(GetInfoRequest {
                         ensure_synced: Some(false),
+                        ..Default::default()
                     })
                     .await?;
                 let token_balance = info
@@ -210,10 +212,11 @@ pub async fn wait_for_token_balance(
             let sdk = sdk.clone();
             let token_id = token_id.clone();
             async move {
-                sdk.sync_wallet(SyncWalletRequest {}).await?;
+                sdk.sync_wallet(SyncWalletRequest::default()).await?;
                 let info = sdk
                     .get_info(GetInfoRequest {
                         ensure_synced: Some(false),
+                        ..Default::default()
                     })
                     .await?;
                 let token_balance = info
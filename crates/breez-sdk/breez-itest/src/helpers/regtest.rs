@@ -203,6 +203,7 @@ pub async fn build_sdk_with_dir(
     let _ = sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -260,6 +261,7 @@ pub async fn build_sdk_with_shared_context(
     let _ = sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -344,10 +346,11 @@ pub async fn build_sdk_with_custom_config_and_backend(
         let _ = sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(true),
+                ..Default::default()
             })
             .await?;
     } else {
-        sdk.sync_wallet(SyncWalletRequest {}).await?;
+        sdk.sync_wallet(SyncWalletRequest::default()).await?;
     }
 
     Ok(SdkInstance {
@@ -725,7 +728,7 @@ pub async fn build_sdk_with_tree_store_config(
     // Force an initial full sync. Works in both modes (auto sync on or off)
     // because `sync_wallet` drives the coordinator directly rather than
     // waiting on the auto-sync initial-synced watcher.
-    sdk.sync_wallet(breez_sdk_spark::SyncWalletRequest {})
+    sdk.sync_wallet(breez_sdk_spark::SyncWalletRequest::default())
         .await?;
 
     Ok(SdkInstance {
@@ -782,6 +785,7 @@ pub async fn build_sdk_from_mnemonic(
     let _ = sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -836,6 +840,7 @@ pub async fn build_sdk_with_external_signer(
     let _ = sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -874,6 +879,7 @@ pub async fn build_sdk_with_external_signer_and_config(
     let _ = sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -999,11 +1005,15 @@ pub async fn ensure_funded(sdk_instance: &mut SdkInstance, min_balance: u64) ->
 }
 
 async fn ensure_funded_inner(sdk_instance: &mut SdkInstance, min_balance: u64) -> Result<()> {
-    sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk_instance
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
     let info = sdk_instance
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     if info.balance_sats < min_balance {
@@ -1035,11 +1045,15 @@ async fn ensure_funded_via_polling_inner(
     sdk_instance: &mut SdkInstance,
     min_balance: u64,
 ) -> Result<()> {
-    sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk_instance
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
     let info = sdk_instance
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     if info.balance_sats < min_balance {
@@ -1081,6 +1095,7 @@ async fn receive_and_fund_inner(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -1114,7 +1129,10 @@ async fn receive_and_fund_inner(
     } else {
         wait_for_balance(&sdk_instance.sdk, Some(initial_balance + 1), None, 200).await?;
     }
-    sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk_instance
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
 
     Ok((deposit_address, txid))
 }
@@ -1169,6 +1187,7 @@ pub async fn build_sdk_with_postgres(
     let _ = sdk
         .get_info(breez_sdk_spark::GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -1227,6 +1246,7 @@ pub async fn build_sdk_with_mysql(
     let _ = sdk
         .get_info(breez_sdk_spark::GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -1283,7 +1303,7 @@ pub async fn build_sdk_with_postgres_server_mode(
     // `ensure_synced=true` is rejected when `background_tasks_enabled` is
     // false; issue an explicit sync_wallet so the initial tree-store hydrate
     // completes before returning.
-    sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     Ok(SdkInstance {
         sdk,
@@ -1330,7 +1350,7 @@ pub async fn build_sdk_with_mysql_server_mode(
     let event_listener = Box::new(ChannelEventListener { tx });
     let _listener_id = sdk.add_event_listener(event_listener).await;
 
-    sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     Ok(SdkInstance {
         sdk,
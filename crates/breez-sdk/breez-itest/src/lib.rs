@@ -5,6 +5,8 @@ pub mod faucet;
 pub mod fixtures;
 pub mod helpers;
 #[cfg(feature = "local-itest")]
+pub mod local_cluster;
+#[cfg(feature = "local-itest")]
 pub mod local_sdk;
 mod log;
 pub mod session_store_scenarios;
@@ -24,6 +26,8 @@ pub use fixtures::lnurl::{LnurlFixture, LnurlImageConfig};
 pub use fixtures::*;
 pub use helpers::*;
 #[cfg(feature = "local-itest")]
+pub use local_cluster::{LocalCluster, local_config};
+#[cfg(feature = "local-itest")]
 pub use local_sdk::{LocalSdk, build_local_sdk};
 pub use rand;
 pub use session_store_scenarios::{SessionRow, run_session_persistence_across_restart};
@@ -0,0 +1,103 @@
+//! A local Spark cluster for hermetic tests: a bitcoind regtest node and a
+//! pool of Spark operators in docker, with a ready SDK [`Config`] pointing at
+//! them.
+//!
+//! The cluster has no SSP, so Lightning payments and other SSP flows are not
+//! available. Spark transfers, deposits, withdrawals and unilateral exits are.
+
+use std::sync::Arc;
+
+use anyhow::Result;
+use breez_sdk_spark::{
+    BitcoinChainService, Config, Network, SparkConfig, SparkSigningOperator, SparkSspConfig,
+    default_config,
+};
+use spark_itest::fixtures::setup::TestFixtures;
+
+use crate::chain_service::LocalBitcoindChainService;
+
+/// A running local cluster. The containers are stopped when the last clone of
+/// [`LocalCluster::fixtures`] is dropped, so keep the cluster alive for the
+/// whole test.
+pub struct LocalCluster {
+    pub fixtures: Arc<TestFixtures>,
+}
+
+impl LocalCluster {
+    /// Starts bitcoind and the operator pool and waits until they are ready.
+    pub async fn start() -> Result<Self> {
+        Ok(Self {
+            fixtures: Arc::new(TestFixtures::new().await?),
+        })
+    }
+
+    /// A regtest config pointing at the local operators, with the services
+    /// the cluster doesn't run (LNURL, real-time sync) disabled.
+    ///
+    /// Build the SDK with [`LocalCluster::chain_service`] as well, so it reads
+    /// the chain from the local bitcoind.
+    pub async fn config(&self) -> Result<Config> {
+        local_config(&self.fixtures).await
+    }
+
+    /// A chain service reading from the local bitcoind.
+    pub fn chain_service(&self) -> Arc<dyn BitcoinChainService> {
+        Arc::new(LocalBitcoindChainService::new(&self.fixtures.bitcoind))
+    }
+}
+
+/// A regtest config pointing at the operators of `fixtures`. See
+/// [`LocalCluster::config`].
+pub async fn local_config(fixtures: &TestFixtures) -> Result<Config> {
+    let wallet_config = fixtures.create_wallet_config().await?;
+
+    let signing_operators: Vec<SparkSigningOperator> = wallet_config
+        .operator_pool
+        .get_all_operators()
+        .map(|op| SparkSigningOperator {
+            id: op.id as u32,
+            identifier: hex::encode(op.identifier.serialize()),
+            address: op.address.clone(),
+            identity_public_key: hex::encode(op.identity_public_key.serialize()),
+            ca_cert_pem: op
+                .ca_cert
+                .as_ref()
+                .and_then(|b| String::from_utf8(b.clone()).ok()),
+        })
+        .collect();
+    let coordinator = wallet_config.operator_pool.get_coordinator();
+    let coordinator_identifier = hex::encode(coordinator.identifier.serialize());
+
+    let mut config = default_config(Network::Regtest);
+    config.api_key = None;
+    config.lnurl_domain = None;
+    config.real_time_sync_server_url = None;
+    config.faucet_config = None;
+    config.sync_interval_secs = 5;
+    config.spark_config = Some(SparkConfig {
+        coordinator_identifier,
+        threshold: wallet_config.split_secret_threshold,
+        signing_operators,
+        ssp_config: SparkSspConfig {
+            base_url: wallet_config.service_provider_config.base_url.clone(),
+            identity_public_key: hex::encode(
+                wallet_config
+                    .service_provider_config
+                    .identity_public_key
+                    .serialize(),
+            ),
+            schema_endpoint: wallet_config
+                .service_provider_config
+                .schema_endpoint
+                .clone(),
+        },
+        expected_withdraw_bond_sats: wallet_config.tokens_config.expected_withdraw_bond_sats,
+        expected_withdraw_relative_block_locktime: wallet_config
+            .tokens_config
+            .expected_withdraw_relative_block_locktime,
+        max_token_transaction_inputs: None,
+        deposit_confirmations: None,
+        expected_transfer_claim_secs: None,
+    });
+    Ok(config)
+}
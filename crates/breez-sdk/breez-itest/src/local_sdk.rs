@@ -8,10 +8,7 @@
 use std::sync::Arc;
 
 use anyhow::Result;
-use breez_sdk_spark::{
-    BreezSdk, Network, SdkBuilder, Seed, SparkConfig, SparkSigningOperator, SparkSspConfig,
-    default_config,
-};
+use breez_sdk_spark::{BreezSdk, SdkBuilder, Seed};
 use spark_itest::fixtures::setup::TestFixtures;
 use spark_wallet::{DefaultSigner, SparkSignerAdapter, SparkWallet, WalletEvent};
 use tempfile::TempDir;
@@ -20,6 +17,7 @@ use tracing::debug;
 
 use crate::chain_service::LocalBitcoindChainService;
 use crate::helpers::regtest::SignerBackend;
+use crate::local_cluster::local_config;
 
 /// A `BreezSdk` connected to local fixtures, plus a side-channel `SparkWallet`
 /// seeded with the same identity for reaching spark-wallet APIs (e.g. deposit
@@ -47,54 +45,10 @@ pub async fn build_local_sdk(
 ) -> Result<LocalSdk> {
     let wallet_config = fixtures.create_wallet_config().await?;
 
-    let signing_operators: Vec<SparkSigningOperator> = wallet_config
-        .operator_pool
-        .get_all_operators()
-        .map(|op| SparkSigningOperator {
-            id: op.id as u32,
-            identifier: hex::encode(op.identifier.serialize()),
-            address: op.address.clone(),
-            identity_public_key: hex::encode(op.identity_public_key.serialize()),
-            ca_cert_pem: op
-                .ca_cert
-                .as_ref()
-                .and_then(|b| String::from_utf8(b.clone()).ok()),
-        })
-        .collect();
-    let coordinator = wallet_config.operator_pool.get_coordinator();
-    let coordinator_identifier = hex::encode(coordinator.identifier.serialize());
-
-    let mut config = default_config(Network::Regtest);
-    config.api_key = None;
-    config.lnurl_domain = None;
-    config.real_time_sync_server_url = None;
-    config.sync_interval_secs = 5;
+    let mut config = local_config(&fixtures).await?;
     // Disable auto-optimization so deposited leaves aren't split/consolidated
     // behind the test's back.
     config.leaf_optimization_config.auto_enabled = false;
-    config.spark_config = Some(SparkConfig {
-        coordinator_identifier,
-        threshold: wallet_config.split_secret_threshold,
-        signing_operators,
-        ssp_config: SparkSspConfig {
-            base_url: wallet_config.service_provider_config.base_url.clone(),
-            identity_public_key: hex::encode(
-                wallet_config
-                    .service_provider_config
-                    .identity_public_key
-                    .serialize(),
-            ),
-            schema_endpoint: wallet_config
-                .service_provider_config
-                .schema_endpoint
-                .clone(),
-        },
-        expected_withdraw_bond_sats: wallet_config.tokens_config.expected_withdraw_bond_sats,
-        expected_withdraw_relative_block_locktime: wallet_config
-            .tokens_config
-            .expected_withdraw_relative_block_locktime,
-        max_token_transaction_inputs: None,
-    });
 
     let storage_dir = tempfile::tempdir()?;
     let storage_path = storage_dir.path().to_string_lossy().into_owned();
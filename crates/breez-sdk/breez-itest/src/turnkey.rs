@@ -208,6 +208,7 @@ pub async fn build_sdk_with_turnkey(
         let _ = sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(true),
+                ..Default::default()
             })
             .await?;
     }
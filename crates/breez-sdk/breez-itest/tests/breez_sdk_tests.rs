@@ -26,6 +26,7 @@ async fn test_01_spark_transfer(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -33,11 +34,12 @@ async fn test_01_spark_transfer(
     info!("Alice balance: {} sats", alice_balance);
 
     // Get Bob's initial balance
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_initial_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -66,6 +68,7 @@ async fn test_01_spark_transfer(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -77,6 +80,9 @@ async fn test_01_spark_transfer(
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -142,11 +148,12 @@ async fn test_01_spark_transfer(
     );
 
     // Verify Bob's balance increased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -182,6 +189,7 @@ async fn test_02_deposit_claim(#[future] alice_sdk: Result<SdkInstance>) -> Resu
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -202,6 +210,7 @@ async fn test_02_deposit_claim(#[future] alice_sdk: Result<SdkInstance>) -> Resu
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -261,11 +270,12 @@ async fn test_03_lightning_invoice_payment(
     ensure_funded(&mut alice, 100_000).await?;
 
     // Get Alice's initial balance
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_initial_balance = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -273,11 +283,12 @@ async fn test_03_lightning_invoice_payment(
     info!("Alice initial balance: {} sats", alice_initial_balance);
 
     // Get Bob's initial balance
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_initial_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -312,6 +323,7 @@ async fn test_03_lightning_invoice_payment(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -337,6 +349,9 @@ async fn test_03_lightning_invoice_payment(
                 completion_timeout_secs: Some(10),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -400,11 +415,12 @@ async fn test_03_lightning_invoice_payment(
         PaymentType::Send,
         "Alice should send a payment"
     );
-    //alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    //alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_final_balance = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -426,11 +442,12 @@ async fn test_03_lightning_invoice_payment(
     );
 
     // Verify Bob's balance increased by invoice amount
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -575,6 +592,7 @@ async fn test_05_lightning_invoice_prefer_spark_fee_path(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -608,6 +626,9 @@ async fn test_05_lightning_invoice_prefer_spark_fee_path(
                 completion_timeout_secs: Some(10),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -672,6 +693,7 @@ async fn test_06_lightning_timeout_and_wait(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -685,6 +707,9 @@ async fn test_06_lightning_timeout_and_wait(
                 completion_timeout_secs: Some(1),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     info!("Immediate return status: {:?}", send_resp.payment.status);
@@ -717,6 +742,7 @@ async fn test_07_spark_invoice(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -724,11 +750,12 @@ async fn test_07_spark_invoice(
     info!("Alice balance: {} sats", alice_initial_balance);
 
     // Get Bob's initial balance
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_initial_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -766,6 +793,7 @@ async fn test_07_spark_invoice(
                 expiry_time: Some(expiry_time),
                 description: Some("Test invoice".to_string()),
                 sender_public_key: Some(alice_identity_public_key),
+                accept_partial_payments: None,
             },
         })
         .await?
@@ -784,6 +812,7 @@ async fn test_07_spark_invoice(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -795,6 +824,9 @@ async fn test_07_spark_invoice(
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -828,11 +860,12 @@ async fn test_07_spark_invoice(
     );
 
     // Verify Bob's balance increased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -869,11 +902,12 @@ async fn test_08_lightning_invoice_expiry_secs(
     ensure_funded(&mut alice, 50_000).await?;
 
     // Get Alice's initial balance
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_initial_balance = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -881,11 +915,12 @@ async fn test_08_lightning_invoice_expiry_secs(
     info!("Alice initial balance: {} sats", alice_initial_balance);
 
     // Get Bob's initial balance
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_initial_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -950,6 +985,7 @@ async fn test_08_lightning_invoice_expiry_secs(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -970,6 +1006,9 @@ async fn test_08_lightning_invoice_expiry_secs(
                 completion_timeout_secs: Some(10),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -1003,11 +1042,12 @@ async fn test_08_lightning_invoice_expiry_secs(
     );
 
     // Verify Bob's balance increased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -1048,6 +1088,7 @@ async fn test_09_bolt11_send_all_with_fee_overpayment(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -1082,6 +1123,7 @@ async fn test_09_bolt11_send_all_with_fee_overpayment(
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await?;
         match prepare.payment_method {
@@ -1186,6 +1228,7 @@ async fn test_09_bolt11_send_all_with_fee_overpayment(
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await?;
 
@@ -1195,6 +1238,9 @@ async fn test_09_bolt11_send_all_with_fee_overpayment(
                 prepare_response: prepare,
                 options: None,
                 idempotency_key: None,
+                max_fee: None,
+                deliver_after: None,
+                leaf_selection: None,
             })
             .await?;
 
@@ -1203,11 +1249,12 @@ async fn test_09_bolt11_send_all_with_fee_overpayment(
         wait_for_payment_succeeded_event(&mut bob.events, PaymentType::Receive, 60).await?;
 
         // Sync and verify
-        alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+        alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
         let new_balance = alice
             .sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?
             .balance_sats;
@@ -1235,6 +1282,7 @@ async fn test_09_bolt11_send_all_with_fee_overpayment(
             token_identifier: None,
             conversion_options: None,
             fee_policy: Some(FeePolicy::FeesIncluded),
+            fiat_amount: None,
         })
         .await?;
 
@@ -1265,6 +1313,9 @@ async fn test_09_bolt11_send_all_with_fee_overpayment(
                 completion_timeout_secs: Some(30),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -1279,11 +1330,12 @@ async fn test_09_bolt11_send_all_with_fee_overpayment(
     info!("Full balance payment completed on Bob's side");
 
     // Verify Alice's balance is zero
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_final = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -1369,6 +1421,7 @@ async fn test_10_lightning_completion_timeout_resolves_to_completed(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -1382,6 +1435,9 @@ async fn test_10_lightning_completion_timeout_resolves_to_completed(
                 completion_timeout_secs: Some(completion_timeout_secs),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     let elapsed = start.elapsed();
@@ -33,6 +33,7 @@ async fn build_alice_manual_opt(mnemonic: String) -> Result<SdkInstance> {
     cfg.leaf_optimization_config = LeafOptimizationConfig {
         auto_enabled: false,
         multiplicity: 15,
+        auto_policy: None,
     };
     build_sdk_with_external_signer_and_config(path, mnemonic, cfg, Some(dir)).await
 }
@@ -50,6 +51,7 @@ async fn build_alice_with_token_cap(
     cfg.leaf_optimization_config = LeafOptimizationConfig {
         auto_enabled: false,
         multiplicity: 15,
+        auto_policy: None,
     };
     if let Some(spark_config) = cfg.spark_config.as_mut() {
         spark_config.max_token_transaction_inputs = Some(max_token_transaction_inputs);
@@ -85,6 +87,7 @@ async fn client_sign_transfer_send(
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await?;
 
@@ -155,6 +158,7 @@ async fn test_client_signing_send_with_denomination_swap() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -179,6 +183,7 @@ async fn test_client_signing_send_with_denomination_swap() -> Result<()> {
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await?;
 
@@ -245,11 +250,12 @@ async fn test_client_signing_send_with_denomination_swap() -> Result<()> {
     assert_eq!(received_payment.amount, send_sats);
     assert_eq!(received_payment.status, PaymentStatus::Completed);
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -303,6 +309,7 @@ async fn test_client_signing_publish_twice_is_idempotent() -> Result<()> {
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await?;
 
@@ -410,10 +417,13 @@ async fn test_client_signing_token_send() -> Result<()> {
         })
         .await?;
     issuer
-        .mint_issuer_token(MintIssuerTokenRequest { amount: 1_000_000 })
+        .mint_issuer_token(MintIssuerTokenRequest {
+            amount: 1_000_000,
+            idempotency_key: None,
+        })
         .await?;
     tokio::time::sleep(std::time::Duration::from_secs(1)).await;
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let token_id = token_metadata.identifier.clone();
 
     let bob_spark_address = bob
@@ -434,6 +444,7 @@ async fn test_client_signing_token_send() -> Result<()> {
             token_identifier: Some(token_id.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -478,11 +489,12 @@ async fn test_client_signing_token_send() -> Result<()> {
     );
 
     tokio::time::sleep(std::time::Duration::from_secs(2)).await;
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_token_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -524,10 +536,13 @@ async fn test_client_signing_token_publish_twice_is_idempotent() -> Result<()> {
         })
         .await?;
     issuer
-        .mint_issuer_token(MintIssuerTokenRequest { amount: 1_000_000 })
+        .mint_issuer_token(MintIssuerTokenRequest {
+            amount: 1_000_000,
+            idempotency_key: None,
+        })
         .await?;
     tokio::time::sleep(std::time::Duration::from_secs(1)).await;
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let token_id = token_metadata.identifier.clone();
 
     let bob_spark_address = bob
@@ -548,6 +563,7 @@ async fn test_client_signing_token_publish_twice_is_idempotent() -> Result<()> {
             token_identifier: Some(token_id.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -599,11 +615,12 @@ async fn test_client_signing_token_publish_twice_is_idempotent() -> Result<()> {
     );
 
     tokio::time::sleep(std::time::Duration::from_secs(2)).await;
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_token_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -647,11 +664,14 @@ async fn test_client_signing_token_send_with_consolidation() -> Result<()> {
     // Three separate mints leave Alice with three separate token outputs.
     for _ in 0..3 {
         issuer
-            .mint_issuer_token(MintIssuerTokenRequest { amount: 100 })
+            .mint_issuer_token(MintIssuerTokenRequest {
+                amount: 100,
+                idempotency_key: None,
+            })
             .await?;
         tokio::time::sleep(std::time::Duration::from_secs(1)).await;
     }
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let token_id = token_metadata.identifier.clone();
 
     let bob_spark_address = bob
@@ -677,6 +697,7 @@ async fn test_client_signing_token_send_with_consolidation() -> Result<()> {
                 token_identifier: Some(token_id.clone()),
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await?;
 
@@ -719,7 +740,7 @@ async fn test_client_signing_token_send_with_consolidation() -> Result<()> {
                 consolidations += 1;
                 assert!(consolidations <= 3, "consolidation did not converge");
                 tokio::time::sleep(std::time::Duration::from_secs(2)).await;
-                alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+                alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
             }
             PublishSignedTransferPackageResponse::PaymentSent { payment } => {
                 assert!(!is_swap, "a real send must not be a swap package");
@@ -740,11 +761,12 @@ async fn test_client_signing_token_send_with_consolidation() -> Result<()> {
     );
 
     tokio::time::sleep(std::time::Duration::from_secs(2)).await;
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_token_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -784,10 +806,13 @@ async fn test_client_signing_token_send_to_spark_invoice() -> Result<()> {
         })
         .await?;
     issuer
-        .mint_issuer_token(MintIssuerTokenRequest { amount: 1_000_000 })
+        .mint_issuer_token(MintIssuerTokenRequest {
+            amount: 1_000_000,
+            idempotency_key: None,
+        })
         .await?;
     tokio::time::sleep(std::time::Duration::from_secs(1)).await;
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let token_id = token_metadata.identifier.clone();
 
     let bob_invoice = bob
@@ -799,6 +824,7 @@ async fn test_client_signing_token_send_to_spark_invoice() -> Result<()> {
                 expiry_time: None,
                 description: Some("client-signing token invoice".to_string()),
                 sender_public_key: None,
+                accept_partial_payments: None,
             },
         })
         .await?
@@ -814,6 +840,7 @@ async fn test_client_signing_token_send_to_spark_invoice() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -858,11 +885,12 @@ async fn test_client_signing_token_send_to_spark_invoice() -> Result<()> {
     );
 
     tokio::time::sleep(std::time::Duration::from_secs(2)).await;
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_token_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -903,11 +931,12 @@ async fn test_client_signing_coop_exit() -> Result<()> {
         .payment_request;
     info!("Withdrawal address: {withdrawal_address}");
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_initial = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -922,6 +951,7 @@ async fn test_client_signing_coop_exit() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -997,16 +1027,17 @@ async fn test_client_signing_coop_exit() -> Result<()> {
     // The coop-exit lands on-chain at Bob's address. Verifying Bob actually
     // receives it proves the withdrawal used the right address (a wrong address
     // would never arrive) and that his balance increases.
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let recv_payment =
         wait_for_payment_succeeded_event(&mut bob.events, PaymentType::Receive, 180).await?;
     assert_eq!(recv_payment.method, PaymentMethod::Deposit);
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -1094,6 +1125,7 @@ async fn test_client_signing_spark_invoice_send() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -1107,6 +1139,7 @@ async fn test_client_signing_spark_invoice_send() -> Result<()> {
                 expiry_time: None,
                 description: Some("client-signing spark invoice".to_string()),
                 sender_public_key: None,
+                accept_partial_payments: None,
             },
         })
         .await?
@@ -1126,11 +1159,12 @@ async fn test_client_signing_spark_invoice_send() -> Result<()> {
     assert_eq!(received.payment_type, PaymentType::Receive);
     assert_eq!(received.amount, invoice_sats);
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -1191,6 +1225,7 @@ async fn test_client_signing_lightning_send_fees_included() -> Result<()> {
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: Some(FeePolicy::FeesIncluded),
+                fiat_amount: None,
             })
             .await?;
 
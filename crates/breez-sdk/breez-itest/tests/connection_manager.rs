@@ -56,6 +56,7 @@ async fn test_shared_connection_manager_spark_transfer(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .identity_pubkey;
@@ -63,6 +64,7 @@ async fn test_shared_connection_manager_spark_transfer(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .identity_pubkey;
@@ -77,6 +79,7 @@ async fn test_shared_connection_manager_spark_transfer(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -99,6 +102,7 @@ async fn test_shared_connection_manager_spark_transfer(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -108,6 +112,9 @@ async fn test_shared_connection_manager_spark_transfer(
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     assert!(matches!(
@@ -119,11 +126,12 @@ async fn test_shared_connection_manager_spark_transfer(
         wait_for_payment_succeeded_event(&mut bob.events, PaymentType::Receive, 60).await?;
     assert!(received.amount >= 5);
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
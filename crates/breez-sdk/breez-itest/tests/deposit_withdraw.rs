@@ -54,11 +54,12 @@ async fn test_onchain_withdraw_to_static_address(
     ensure_funded(&mut alice, 120_000).await?;
 
     // Record Bob's initial balance
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_initial = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -85,6 +86,7 @@ async fn test_onchain_withdraw_to_static_address(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -94,6 +96,9 @@ async fn test_onchain_withdraw_to_static_address(
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -113,7 +118,7 @@ async fn test_onchain_withdraw_to_static_address(
     ));
 
     // Trigger Bob sync and wait for receive + claim
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let recv_payment =
         wait_for_payment_succeeded_event(&mut bob.events, PaymentType::Receive, 180).await?;
     assert!(matches!(recv_payment.method, PaymentMethod::Deposit));
@@ -127,11 +132,12 @@ async fn test_onchain_withdraw_to_static_address(
     assert!(recv_payment.fees > 0);
 
     // Verify Bob's balance increased and no unclaimed deposits remain
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -175,7 +181,7 @@ async fn test_deposit_fee_manual_claim(
     info!("Faucet txid: {}", txid);
 
     // Start sync and wait for UnclaimedDeposits due to fee limit
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let failed = wait_for_unclaimed_event(&mut bob.events, 180).await?;
     assert!(!failed.is_empty());
     let (txid_found, vout) = {
@@ -205,6 +211,7 @@ async fn test_deposit_fee_manual_claim(
             txid: txid_found.clone(),
             vout,
             max_fee: Some(MaxFee::Fixed { amount: 100_000 }),
+            idempotency_key: None,
         })
         .await?;
     assert!(matches!(
@@ -222,7 +229,7 @@ async fn test_deposit_fee_manual_claim(
     );
 
     // After manual claim, deposit should be removed from unclaimed list
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let deposits_after_claim = bob
         .sdk
         .list_unclaimed_deposits(ListUnclaimedDepositsRequest {})
@@ -253,11 +260,12 @@ async fn test_send_all_to_bitcoin_address(
     receive_and_fund(&mut alice, funding_amount, false).await?;
 
     // Get Alice's initial balance (less than funding_amount due to claim fees)
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_initial = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -279,6 +287,7 @@ async fn test_send_all_to_bitcoin_address(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -294,6 +303,7 @@ async fn test_send_all_to_bitcoin_address(
             token_identifier: None,
             conversion_options: None,
             fee_policy: Some(FeePolicy::FeesIncluded),
+            fiat_amount: None,
         })
         .await?;
 
@@ -327,6 +337,9 @@ async fn test_send_all_to_bitcoin_address(
                 confirmation_speed: OnchainConfirmationSpeed::Fast,
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -334,11 +347,12 @@ async fn test_send_all_to_bitcoin_address(
     assert!(matches!(send_resp.payment.method, PaymentMethod::Withdraw));
 
     // Verify Alice's balance is now 0
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_final = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -371,7 +385,7 @@ async fn test_deposit_fee_refund(#[future] bob_no_fee_sdk: Result<SdkInstance>)
     info!("Faucet txid: {}", txid);
 
     // Start sync and wait for UnclaimedDeposits due to no fee set
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let failed = wait_for_unclaimed_event(&mut bob.events, 180).await?;
     assert!(!failed.is_empty());
 
@@ -429,7 +443,7 @@ async fn test_deposit_fee_refund(#[future] bob_no_fee_sdk: Result<SdkInstance>)
     info!("Refunded deposit with tx_id: {}", refund.tx_id);
 
     // Sync and assert the unclaimed deposit shows refund tx id or is removed post-confirmation
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     // give a brief moment for chain status to process
     sleep(Duration::from_secs(2)).await;
     let deposits_after_refund = bob
@@ -485,6 +499,7 @@ async fn test_deposit_low_amount_refund_fee_rate(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -496,6 +511,9 @@ async fn test_deposit_low_amount_refund_fee_rate(
                 confirmation_speed: OnchainConfirmationSpeed::Fast,
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     info!(
@@ -504,7 +522,7 @@ async fn test_deposit_low_amount_refund_fee_rate(
     );
 
     // Sync Bob and wait for UnclaimedDeposits (no fee set blocks auto-claim)
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let failed = wait_for_unclaimed_event(&mut bob.events, 180).await?;
     assert!(!failed.is_empty());
 
@@ -623,6 +641,7 @@ async fn test_deposits_to_multiple_addresses(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
     info!("Final balance: {} sats", info.balance_sats);
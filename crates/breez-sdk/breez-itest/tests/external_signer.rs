@@ -43,6 +43,7 @@ async fn test_external_signer_send_receive(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -55,6 +56,9 @@ async fn test_external_signer_send_receive(
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -73,11 +77,12 @@ async fn test_external_signer_send_receive(
     info!("Bob received payment: {} sats", received_payment.amount);
 
     // Verify Bob's balance increased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -91,7 +96,7 @@ async fn test_external_signer_send_receive(
     info!("Bob's final balance: {} sats", bob_balance);
 
     // Verify Alice's payment is completed
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_payment = alice
         .sdk
         .get_payment(GetPaymentRequest {
@@ -23,6 +23,7 @@ async fn test_01_spark_idempotency_key(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -30,11 +31,12 @@ async fn test_01_spark_idempotency_key(
     info!("Alice balance: {} sats", alice_balance);
 
     // Get Bob's initial balance
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_initial_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -63,6 +65,7 @@ async fn test_01_spark_idempotency_key(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -77,6 +80,9 @@ async fn test_01_spark_idempotency_key(
             prepare_response: prepare.clone(),
             options: None,
             idempotency_key: Some(idempotency_key.clone()),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -93,6 +99,9 @@ async fn test_01_spark_idempotency_key(
             prepare_response: prepare.clone(),
             options: None,
             idempotency_key: Some(idempotency_key.clone()),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     assert_eq!(
@@ -120,6 +129,9 @@ async fn test_01_spark_idempotency_key(
             prepare_response: prepare,
             options: None,
             idempotency_key: Some(idempotency_key.clone()),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     assert_eq!(
@@ -146,11 +158,12 @@ async fn test_01_spark_idempotency_key(
     );
 
     // Verify Bob's balance increased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -188,6 +201,7 @@ async fn test_02_lightning_idempotency_key(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -195,11 +209,12 @@ async fn test_02_lightning_idempotency_key(
     info!("Alice balance: {} sats", alice_balance);
 
     // Get Bob's initial balance
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_initial_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -233,6 +248,7 @@ async fn test_02_lightning_idempotency_key(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -247,6 +263,9 @@ async fn test_02_lightning_idempotency_key(
             prepare_response: prepare.clone(),
             options: None,
             idempotency_key: Some(idempotency_key.clone()),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -263,6 +282,9 @@ async fn test_02_lightning_idempotency_key(
             prepare_response: prepare.clone(),
             options: None,
             idempotency_key: Some(idempotency_key.clone()),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     assert_eq!(
@@ -290,6 +312,9 @@ async fn test_02_lightning_idempotency_key(
             prepare_response: prepare,
             options: None,
             idempotency_key: Some(idempotency_key.clone()),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     assert_eq!(
@@ -316,11 +341,12 @@ async fn test_02_lightning_idempotency_key(
     );
 
     // Verify Bob's balance increased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -356,11 +382,12 @@ async fn test_03_bitcoin_idempotency_key(
     ensure_funded(&mut alice, 50_000).await?;
 
     // Record Bob's initial balance
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_initial_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -387,6 +414,7 @@ async fn test_03_bitcoin_idempotency_key(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -399,6 +427,9 @@ async fn test_03_bitcoin_idempotency_key(
             prepare_response: prepare.clone(),
             options: None,
             idempotency_key: Some(idempotency_key.clone()),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -415,6 +446,9 @@ async fn test_03_bitcoin_idempotency_key(
             prepare_response: prepare.clone(),
             options: None,
             idempotency_key: Some(idempotency_key.clone()),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     assert_eq!(
@@ -427,7 +461,7 @@ async fn test_03_bitcoin_idempotency_key(
     );
 
     // Trigger Bob sync and wait for receive + claim
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let recv_payment =
         wait_for_payment_succeeded_event(&mut bob.events, PaymentType::Receive, 180).await?;
     assert!(matches!(recv_payment.method, PaymentMethod::Deposit));
@@ -439,6 +473,9 @@ async fn test_03_bitcoin_idempotency_key(
             prepare_response: prepare,
             options: None,
             idempotency_key: Some(idempotency_key),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     assert_eq!(
@@ -451,11 +488,12 @@ async fn test_03_bitcoin_idempotency_key(
     );
 
     // Verify Bob's balance increased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -493,6 +531,7 @@ async fn test_04_spark_htlc_idempotency_key(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -500,11 +539,12 @@ async fn test_04_spark_htlc_idempotency_key(
     info!("Alice balance: {} sats", alice_balance);
 
     // Get Bob's initial balance
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_initial_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -533,6 +573,7 @@ async fn test_04_spark_htlc_idempotency_key(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -554,6 +595,9 @@ async fn test_04_spark_htlc_idempotency_key(
                 }),
             }),
             idempotency_key: Some(idempotency_key.clone()),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -575,6 +619,9 @@ async fn test_04_spark_htlc_idempotency_key(
                 }),
             }),
             idempotency_key: Some(idempotency_key.clone()),
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     assert_eq!(
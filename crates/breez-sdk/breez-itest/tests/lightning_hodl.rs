@@ -48,6 +48,7 @@ async fn test_01_lightning_hodl_success(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -62,6 +63,9 @@ async fn test_01_lightning_hodl_success(
                 completion_timeout_secs: Some(1),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -72,7 +76,7 @@ async fn test_01_lightning_hodl_success(
     );
 
     // Bob syncs and verifies the pending HODL receive
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let bob_pending = bob
         .sdk
@@ -150,11 +154,12 @@ async fn test_01_lightning_hodl_success(
     assert_eq!(alice_completed_payment.amount, 10_000);
 
     // Verify Bob's balance increased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -56,7 +56,7 @@ async fn test_send_bolt11_invoice_server_mode(
 
     // Fund Alice via polling (server mode has no ClaimedDeposits event).
     ensure_funded_via_polling(&mut alice, 100_000).await?;
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let bob_invoice = bob
         .sdk
@@ -81,6 +81,7 @@ async fn test_send_bolt11_invoice_server_mode(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -94,6 +95,9 @@ async fn test_send_bolt11_invoice_server_mode(
                 completion_timeout_secs: Some(completion_timeout_secs),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     let elapsed = start.elapsed();
@@ -142,6 +142,7 @@ async fn build_signing_alice(mnemonic: String, prefix: &str) -> Result<SdkInstan
     cfg.leaf_optimization_config = LeafOptimizationConfig {
         auto_enabled: false,
         multiplicity: 15,
+        auto_policy: None,
     };
     build_sdk_with_external_signer_and_config(path, mnemonic, cfg, Some(dir)).await
 }
@@ -213,6 +214,11 @@ async fn test_01_register_lightning_address(#[case] use_postgres: bool) -> Resul
         .register_lightning_address(RegisterLightningAddressRequest {
             username: username.to_string(),
             description: Some("Bob's test Lightning address".to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
 
@@ -258,6 +264,11 @@ async fn test_02_check_lightning_address_available(#[case] use_postgres: bool) -
         .register_lightning_address(RegisterLightningAddressRequest {
             username: "takenuser".to_string(),
             description: Some("Test address".to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
 
@@ -294,6 +305,11 @@ async fn test_03_get_lightning_address(#[case] use_postgres: bool) -> Result<()>
         .register_lightning_address(RegisterLightningAddressRequest {
             username: username.to_string(),
             description: Some(description.to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
 
@@ -341,6 +357,11 @@ async fn test_04_delete_lightning_address(#[case] use_postgres: bool) -> Result<
         .register_lightning_address(RegisterLightningAddressRequest {
             username: username.to_string(),
             description: Some("Address to be deleted".to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
 
@@ -403,6 +424,11 @@ async fn test_05_lnurl_payment_flow(
         .register_lightning_address(RegisterLightningAddressRequest {
             username: username.to_string(),
             description: Some(description.to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
 
@@ -435,6 +461,7 @@ async fn test_05_lnurl_payment_flow(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            quote_token_identifier: None,
         })
         .await?;
 
@@ -447,6 +474,7 @@ async fn test_05_lnurl_payment_flow(
         .lnurl_pay(LnurlPayRequest {
             prepare_response,
             idempotency_key: None,
+            max_fee: None,
         })
         .await?;
 
@@ -544,6 +572,11 @@ async fn test_07_lnurl_send_all_payment(
         .register_lightning_address(RegisterLightningAddressRequest {
             username: username.to_string(),
             description: Some(description.to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
 
@@ -556,12 +589,13 @@ async fn test_07_lnurl_send_all_payment(
     // Fund Alice with a specific amount for testing
     let funding_amount = 10_000u64;
     receive_and_fund(&mut alice, funding_amount, false).await?;
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let alice_balance = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -590,6 +624,7 @@ async fn test_07_lnurl_send_all_payment(
             token_identifier: None,
             conversion_options: None,
             fee_policy: Some(FeePolicy::FeesIncluded),
+            quote_token_identifier: None,
         })
         .await?;
 
@@ -613,6 +648,7 @@ async fn test_07_lnurl_send_all_payment(
         .lnurl_pay(LnurlPayRequest {
             prepare_response: prepare_response.clone(),
             idempotency_key: None,
+            max_fee: None,
         })
         .await?;
 
@@ -626,11 +662,12 @@ async fn test_07_lnurl_send_all_payment(
     info!("Payment completed on Bob's side");
 
     // Verify Alice's balance is now zero
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_final = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -685,6 +722,11 @@ async fn test_08_lnurl_send_all_with_fee_overpayment(
         .register_lightning_address(RegisterLightningAddressRequest {
             username: username.to_string(),
             description: Some(description.to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
 
@@ -697,12 +739,13 @@ async fn test_08_lnurl_send_all_with_fee_overpayment(
     // Fund Alice with max faucet amount to have room for searching
     let funding_amount = 50_000u64;
     receive_and_fund(&mut alice, funding_amount, false).await?;
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let alice_balance = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -740,6 +783,7 @@ async fn test_08_lnurl_send_all_with_fee_overpayment(
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: None,
+                quote_token_identifier: None,
             })
             .await?;
         Ok(prepare.fee_sats)
@@ -836,6 +880,7 @@ async fn test_08_lnurl_send_all_with_fee_overpayment(
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await?;
 
@@ -845,6 +890,9 @@ async fn test_08_lnurl_send_all_with_fee_overpayment(
                 prepare_response: prepare,
                 options: None,
                 idempotency_key: None,
+                max_fee: None,
+                deliver_after: None,
+                leaf_selection: None,
             })
             .await?;
 
@@ -865,11 +913,12 @@ async fn test_08_lnurl_send_all_with_fee_overpayment(
         .await?;
 
         // Sync and verify
-        alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+        alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
         let new_balance = alice
             .sdk
             .get_info(GetInfoRequest {
                 ensure_synced: Some(false),
+                ..Default::default()
             })
             .await?
             .balance_sats;
@@ -897,6 +946,7 @@ async fn test_08_lnurl_send_all_with_fee_overpayment(
             token_identifier: None,
             conversion_options: None,
             fee_policy: Some(FeePolicy::FeesIncluded),
+            quote_token_identifier: None,
         })
         .await?;
 
@@ -919,6 +969,7 @@ async fn test_08_lnurl_send_all_with_fee_overpayment(
         .lnurl_pay(LnurlPayRequest {
             prepare_response: prepare_response.clone(),
             idempotency_key: None,
+            max_fee: None,
         })
         .await?;
 
@@ -944,11 +995,12 @@ async fn test_08_lnurl_send_all_with_fee_overpayment(
     info!("Full balance payment completed on Bob's side");
 
     // Verify Alice's balance is zero
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_final = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -1013,6 +1065,11 @@ async fn test_09_invoice_expiry_parameter(#[case] use_postgres: bool) -> Result<
         .register_lightning_address(RegisterLightningAddressRequest {
             username: username.to_string(),
             description: Some("Expiry test address".to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
 
@@ -1157,6 +1214,11 @@ async fn test_11_lnurl_spark_address_payment(
             .register_lightning_address(RegisterLightningAddressRequest {
                 username: username.to_string(),
                 description: Some(description.to_string()),
+                label: None,
+                image: None,
+                long_description: None,
+                min_sendable_sats: None,
+                max_sendable_sats: None,
             })
             .await?;
 
@@ -1189,6 +1251,7 @@ async fn test_11_lnurl_spark_address_payment(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            quote_token_identifier: None,
         })
         .await?;
 
@@ -1204,6 +1267,7 @@ async fn test_11_lnurl_spark_address_payment(
         .lnurl_pay(LnurlPayRequest {
             prepare_response,
             idempotency_key: None,
+            max_fee: None,
         })
         .await?;
 
@@ -1265,6 +1329,11 @@ async fn test_12_transfer_lightning_address(#[case] use_postgres: bool) -> Resul
         .register_lightning_address(RegisterLightningAddressRequest {
             username: username.to_string(),
             description: Some(description.to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
 
@@ -1272,6 +1341,7 @@ async fn test_12_transfer_lightning_address(#[case] use_postgres: bool) -> Resul
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?
         .identity_pubkey;
@@ -1279,6 +1349,7 @@ async fn test_12_transfer_lightning_address(#[case] use_postgres: bool) -> Resul
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?
         .identity_pubkey;
@@ -1354,6 +1425,11 @@ async fn test_13_transfer_to_self_rejected(#[case] use_postgres: bool) -> Result
         .register_lightning_address(RegisterLightningAddressRequest {
             username: username.to_string(),
             description: None,
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
 
@@ -1361,6 +1437,7 @@ async fn test_13_transfer_to_self_rejected(#[case] use_postgres: bool) -> Result
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?
         .identity_pubkey;
@@ -1415,6 +1492,11 @@ async fn test_14_client_signing_lnurl_pay() -> Result<()> {
         .register_lightning_address(RegisterLightningAddressRequest {
             username: username.to_string(),
             description: Some(description.to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
     let bob_lightning_address = register_response.lightning_address;
@@ -1438,6 +1520,7 @@ async fn test_14_client_signing_lnurl_pay() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            quote_token_identifier: None,
         })
         .await?;
     info!(
@@ -1514,16 +1597,22 @@ async fn test_15_client_signing_lnurl_pay_fees_included() -> Result<()> {
         .register_lightning_address(RegisterLightningAddressRequest {
             username: "bobsigningfullbalance".to_string(),
             description: Some("Bob's client-signing full balance address".to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
     let bob_lightning_address = register_response.lightning_address;
 
     ensure_funded(&mut alice, 10_000).await?;
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_balance = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -1544,6 +1633,7 @@ async fn test_15_client_signing_lnurl_pay_fees_included() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: Some(FeePolicy::FeesIncluded),
+            quote_token_identifier: None,
         })
         .await?;
 
@@ -1561,11 +1651,12 @@ async fn test_15_client_signing_lnurl_pay_fees_included() -> Result<()> {
     let bob_payment =
         wait_for_payment_succeeded_event(&mut bob.events, PaymentType::Receive, 30).await?;
 
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_final = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -1609,6 +1700,11 @@ async fn test_16_client_signing_lnurl_pay_publish_twice() -> Result<()> {
         .register_lightning_address(RegisterLightningAddressRequest {
             username: "bobsigningreplay".to_string(),
             description: Some(description.to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
     let bob_lightning_address = register_response.lightning_address;
@@ -1630,6 +1726,7 @@ async fn test_16_client_signing_lnurl_pay_publish_twice() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            quote_token_identifier: None,
         })
         .await?;
 
@@ -115,11 +115,12 @@ fn reduce_by_bps(amount: u128, bps: u128) -> u128 {
 /// Alice's `(sats, token)` balances, for the per-test cost breadcrumb. Syncs
 /// first so the read reflects the latest state.
 async fn alice_balances(alice: &SdkInstance, token_id: &str) -> Result<(u64, u128)> {
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let info = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     let tokens = info
@@ -311,6 +312,7 @@ async fn run_cross_chain_evm_send(
             token_identifier: token_identifier.clone(),
             conversion_options,
             fee_policy: Some(FeePolicy::FeesExcluded),
+            fiat_amount: None,
         })
         .await?;
 
@@ -389,6 +391,9 @@ async fn run_cross_chain_evm_send(
             prepare_response: prepared,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     let payment_id = resp.payment.id.clone();
@@ -471,6 +476,7 @@ async fn alice_can_cover(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     match token_identifier {
@@ -623,7 +629,7 @@ async fn wait_for_cross_chain_completion(
         }
         // The status update arrives via the background monitor; nudge a sync so
         // the locally-read payment reflects it promptly.
-        let _ = sdk.sync_wallet(SyncWalletRequest {}).await;
+        let _ = sdk.sync_wallet(SyncWalletRequest::default()).await;
         tokio::time::sleep(STATUS_POLL_INTERVAL).await;
     }
 }
@@ -44,11 +44,12 @@ async fn test_server_mode_bitcoin_to_token() -> Result<()> {
     };
     let (alice, bob) = mainnet_alice_bob_server_mode(&mnemonic, &api_key).await?;
 
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_balance = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -66,11 +67,12 @@ async fn test_server_mode_bitcoin_to_token() -> Result<()> {
     // the per-test cost low.
     let target_token_amount = to_btc_min_token.saturating_mul(2);
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_token_before = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -101,6 +103,7 @@ async fn test_server_mode_bitcoin_to_token() -> Result<()> {
                 completion_timeout_secs: None,
             }),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -133,6 +136,9 @@ async fn test_server_mode_bitcoin_to_token() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     info!(
@@ -206,11 +212,12 @@ async fn test_server_mode_token_to_bitcoin() -> Result<()> {
     let token_id = mainnet_test_token_id();
     let snap = snapshot_test_pair(&alice, &bob, &token_id).await?;
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_token_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -242,11 +249,12 @@ async fn test_server_mode_token_to_bitcoin() -> Result<()> {
     )?;
     info!("Invoice sats sized at {invoice_sats} (from pool estimate)");
 
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_sats_before = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -281,6 +289,7 @@ async fn test_server_mode_token_to_bitcoin() -> Result<()> {
                 completion_timeout_secs: None,
             }),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
     let estimate = prepare
@@ -298,6 +307,9 @@ async fn test_server_mode_token_to_bitcoin() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     info!(
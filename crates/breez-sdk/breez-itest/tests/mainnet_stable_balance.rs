@@ -94,6 +94,7 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -125,6 +126,7 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -143,6 +145,7 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -152,6 +155,9 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
             prepare_response: prepare_small,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -168,6 +174,7 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
 
@@ -211,6 +218,7 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -220,6 +228,9 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
             prepare_response: prepare_large,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -239,6 +250,7 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
 
@@ -309,6 +321,7 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -334,6 +347,9 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
             prepare_response: prepare_spend,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -372,11 +388,12 @@ async fn test_stable_balance_auto_conversion() -> Result<()> {
     );
 
     // Verify Bob's token balance decreased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_token_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -436,6 +453,7 @@ async fn test_stable_balance_per_receive_conversion() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -459,6 +477,7 @@ async fn test_stable_balance_per_receive_conversion() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -478,6 +497,7 @@ async fn test_stable_balance_per_receive_conversion() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
     alice
@@ -486,6 +506,9 @@ async fn test_stable_balance_per_receive_conversion() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     wait_for_payment_succeeded_event(&mut alice.events, PaymentType::Send, 60).await?;
@@ -548,6 +571,11 @@ async fn test_stable_balance_send_lightning_address() -> Result<()> {
             .register_lightning_address(RegisterLightningAddressRequest {
                 username: "mainnet-itest-alice".to_string(),
                 description: Some("Mainnet itest Alice".to_string()),
+                label: None,
+                image: None,
+                long_description: None,
+                min_sendable_sats: None,
+                max_sendable_sats: None,
             })
             .await
         {
@@ -603,6 +631,7 @@ async fn test_stable_balance_send_lightning_address() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -625,6 +654,7 @@ async fn test_stable_balance_send_lightning_address() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            quote_token_identifier: None,
         })
         .await?;
 
@@ -653,6 +683,7 @@ async fn test_stable_balance_send_lightning_address() -> Result<()> {
         .lnurl_pay(LnurlPayRequest {
             prepare_response: prepare,
             idempotency_key: None,
+            max_fee: None,
         })
         .await?;
     info!(
@@ -709,11 +740,12 @@ async fn test_stable_balance_send_lightning_address() -> Result<()> {
     info!("Waiting for Alice to receive lightning payment...");
     wait_for_payment_succeeded_event(&mut alice.events, PaymentType::Receive, 120).await?;
 
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_sats_after = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -748,11 +780,12 @@ async fn test_stable_balance_zz_deactivation() -> Result<()> {
     };
     info!("=== Starting test_stable_balance_zz_deactivation ===");
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_info_before = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     let bob_tokens_before = bob_info_before
@@ -773,6 +806,7 @@ async fn test_stable_balance_zz_deactivation() -> Result<()> {
         .update_user_settings(UpdateUserSettingsRequest {
             spark_private_mode_enabled: None,
             stable_balance_active_label: Some(StableBalanceActiveLabel::Unset),
+            display_currency: None,
         })
         .await?;
 
@@ -783,11 +817,12 @@ async fn test_stable_balance_zz_deactivation() -> Result<()> {
         wait_for_balance(&bob.sdk, Some(bob_sats_before.saturating_add(1)), None, 120).await?;
     info!("Bob sats after deactivation: {bob_sats_after} (was {bob_sats_before})");
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_tokens_after = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -65,11 +65,12 @@ async fn test_mainnet_teardown_drain_bob_to_alice() -> Result<()> {
         .and_then(|m| u64::try_from(m).ok())
         .unwrap_or(u64::MAX);
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let pre_info = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     let pre_tokens = pre_info
@@ -87,13 +88,14 @@ async fn test_mainnet_teardown_drain_bob_to_alice() -> Result<()> {
         {
             warn!("Teardown: auto-conversion didn't complete in 180s ({e:#}); proceeding anyway");
         }
-        bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+        bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     }
 
     let bob_info = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     let bob_token_balance = bob_info
@@ -134,6 +136,7 @@ async fn test_mainnet_teardown_drain_bob_to_alice() -> Result<()> {
                     completion_timeout_secs: None,
                 }),
                 fee_policy: Some(FeePolicy::FeesIncluded),
+                fiat_amount: None,
             })
             .await?;
 
@@ -156,6 +159,9 @@ async fn test_mainnet_teardown_drain_bob_to_alice() -> Result<()> {
                 prepare_response: prepare,
                 options: None,
                 idempotency_key: None,
+                max_fee: None,
+                deliver_after: None,
+                leaf_selection: None,
             })
             .await?;
         let details = resp
@@ -179,11 +185,12 @@ async fn test_mainnet_teardown_drain_bob_to_alice() -> Result<()> {
     // Drain any remaining sats. Unconditional: covers the tokenless case and
     // any residual after the token drain. Runs before the final assertions so a
     // partially-drained Bob is still cleaned up.
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let remaining_sats = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -199,6 +206,7 @@ async fn test_mainnet_teardown_drain_bob_to_alice() -> Result<()> {
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: Some(FeePolicy::FeesIncluded),
+                fiat_amount: None,
             })
             .await?;
         bob.sdk
@@ -206,6 +214,9 @@ async fn test_mainnet_teardown_drain_bob_to_alice() -> Result<()> {
                 prepare_response: prepare,
                 options: None,
                 idempotency_key: None,
+                max_fee: None,
+                deliver_after: None,
+                leaf_selection: None,
             })
             .await?;
         wait_for_payment_succeeded_event(&mut alice.events, PaymentType::Receive, 60).await?;
@@ -213,11 +224,12 @@ async fn test_mainnet_teardown_drain_bob_to_alice() -> Result<()> {
 
     // Final assertion: Bob's tokens are fully drained. Deferred to the end so
     // the sat drain above still runs even on partial token cleanup.
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let final_tokens = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -58,6 +58,7 @@ async fn test_token_conversion_success() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -92,6 +93,7 @@ async fn test_token_conversion_success() -> Result<()> {
                 completion_timeout_secs: None,
             }),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
     let conversion_estimate = prepare_btc_to_token
@@ -111,6 +113,7 @@ async fn test_token_conversion_success() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -128,6 +131,9 @@ async fn test_token_conversion_success() -> Result<()> {
             prepare_response: prepare_btc_to_token,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -204,11 +210,12 @@ async fn test_token_conversion_success() -> Result<()> {
     );
 
     // Verify Bob received tokens
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_token_balance_after_btc_to_token = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -236,11 +243,12 @@ async fn test_token_conversion_success() -> Result<()> {
     clear_event_receiver(&mut alice.events).await;
 
     // Get Alice's initial balance before receiving Bitcoin
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_balance_before_token_to_btc = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -286,6 +294,7 @@ async fn test_token_conversion_success() -> Result<()> {
                 completion_timeout_secs: None,
             }),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -304,6 +313,9 @@ async fn test_token_conversion_success() -> Result<()> {
             prepare_response: prepare_token_to_btc,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -380,11 +392,12 @@ async fn test_token_conversion_success() -> Result<()> {
     );
 
     // Verify Alice received Bitcoin
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_balance_after_token_to_btc = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -401,11 +414,12 @@ async fn test_token_conversion_success() -> Result<()> {
     );
 
     // Verify Bob's token balance decreased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_token_balance_after_token_to_btc = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -478,6 +492,7 @@ async fn test_token_conversion_failure() -> Result<()> {
                 completion_timeout_secs: None,
             }),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await;
     assert!(
@@ -490,11 +505,12 @@ async fn test_token_conversion_failure() -> Result<()> {
     // Part B: Insufficient funds rejected at send
     // ==========================================
     info!("--- Part B: Insufficient funds failure ---");
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_balance = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -525,6 +541,7 @@ async fn test_token_conversion_failure() -> Result<()> {
                 completion_timeout_secs: None,
             }),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?
         .conversion_estimate
@@ -555,6 +572,7 @@ async fn test_token_conversion_failure() -> Result<()> {
                 completion_timeout_secs: None,
             }),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
     let oversize_amount_in = prepare_oversize
@@ -574,6 +592,9 @@ async fn test_token_conversion_failure() -> Result<()> {
             prepare_response: prepare_oversize,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await;
     info!("Insufficient-funds send rejected: {}", send_result.is_err());
@@ -583,11 +604,12 @@ async fn test_token_conversion_failure() -> Result<()> {
     );
 
     // Alice's balance must be intact — a rejected send spends nothing.
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_balance_after = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -619,6 +641,7 @@ async fn test_token_conversion_spark_invoice_success() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -638,6 +661,7 @@ async fn test_token_conversion_spark_invoice_success() -> Result<()> {
                 expiry_time: None,
                 description: Some("token conversion via spark invoice test".to_string()),
                 sender_public_key: None,
+                accept_partial_payments: None,
             },
         })
         .await?
@@ -659,6 +683,7 @@ async fn test_token_conversion_spark_invoice_success() -> Result<()> {
                 completion_timeout_secs: None,
             }),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -681,6 +706,7 @@ async fn test_token_conversion_spark_invoice_success() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -698,6 +724,9 @@ async fn test_token_conversion_spark_invoice_success() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     info!(
@@ -181,13 +181,17 @@ async fn create_mint_test_token(instance: &SdkInstance) -> Result<TokenMetadata>
     issuer
         .mint_issuer_token(MintIssuerTokenRequest {
             amount: 100_000_000,
+            idempotency_key: None,
         })
         .await?;
 
     info!("Minted 100,000,000 tokens ({})", token_metadata.identifier);
 
     tokio::time::sleep(std::time::Duration::from_secs(1)).await;
-    instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    instance
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
     Ok(token_metadata)
 }
 
@@ -261,6 +265,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -270,6 +275,9 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -295,6 +303,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -303,6 +312,9 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -322,6 +334,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -336,11 +349,14 @@ async fn test_setup_recovery_wallet() -> Result<()> {
                 }),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
     // Bob claims the HTLC
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     bob.sdk
         .claim_htlc_payment(ClaimHtlcPaymentRequest {
@@ -364,6 +380,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -377,11 +394,14 @@ async fn test_setup_recovery_wallet() -> Result<()> {
                 }),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
     // Alice claims the HTLC
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     alice
         .sdk
@@ -415,6 +435,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -427,6 +448,9 @@ async fn test_setup_recovery_wallet() -> Result<()> {
                 completion_timeout_secs: Some(30),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -457,6 +481,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -468,6 +493,9 @@ async fn test_setup_recovery_wallet() -> Result<()> {
                 completion_timeout_secs: Some(30),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -488,6 +516,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             token_identifier: Some(token_metadata.identifier.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -497,13 +526,16 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
     wait_for_payment_succeeded_event(&mut bob.events, PaymentType::Receive, 60).await?;
 
     // Bob sends tokens back to Alice
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let prepare = bob
         .sdk
@@ -515,6 +547,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             token_identifier: Some(token_metadata.identifier.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -523,6 +556,9 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -546,6 +582,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
                 token_identifier: Some(token_metadata.identifier.clone()),
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await?;
 
@@ -555,13 +592,16 @@ async fn test_setup_recovery_wallet() -> Result<()> {
                 prepare_response: prepare,
                 options: None,
                 idempotency_key: None,
+                max_fee: None,
+                deliver_after: None,
+                leaf_selection: None,
             })
             .await?;
 
         wait_for_payment_succeeded_event(&mut bob.events, PaymentType::Receive, 60).await?;
 
         // Bob → Alice
-        bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+        bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
         let prepare = bob
             .sdk
@@ -573,6 +613,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
                 token_identifier: Some(token_metadata.identifier.clone()),
                 conversion_options: None,
                 fee_policy: None,
+                fiat_amount: None,
             })
             .await?;
 
@@ -581,6 +622,9 @@ async fn test_setup_recovery_wallet() -> Result<()> {
                 prepare_response: prepare,
                 options: None,
                 idempotency_key: None,
+                max_fee: None,
+                deliver_after: None,
+                leaf_selection: None,
             })
             .await?;
 
@@ -615,6 +659,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -624,6 +669,9 @@ async fn test_setup_recovery_wallet() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -637,7 +685,7 @@ async fn test_setup_recovery_wallet() -> Result<()> {
     let start = std::time::Instant::now();
 
     loop {
-        alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+        alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
         let payments = alice
             .sdk
             .list_payments(ListPaymentsRequest::default())
@@ -673,13 +721,14 @@ async fn test_setup_recovery_wallet() -> Result<()> {
 
     // 13. Final sync to ensure all payments are captured
     info!("Final sync...");
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     // Get final balance and payments
     let info = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
@@ -776,13 +825,17 @@ async fn test_wallet_recovery_from_mnemonic() -> Result<()> {
 
     // 4. Wait for sync
     info!("Waiting for wallet sync...");
-    sdk_instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk_instance
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
 
     // 5. Verify balance
     let info = sdk_instance
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
 
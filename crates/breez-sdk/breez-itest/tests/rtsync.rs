@@ -147,6 +147,11 @@ async fn test_01_rtsync_lnurl_info_sync(
         .register_lightning_address(RegisterLightningAddressRequest {
             username: "bob".to_string(),
             description: Some(ln_address_description.clone()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?
         .lightning_address;
@@ -169,6 +174,7 @@ async fn test_01_rtsync_lnurl_info_sync(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            quote_token_identifier: None,
         })
         .await?;
 
@@ -180,6 +186,7 @@ async fn test_01_rtsync_lnurl_info_sync(
         .lnurl_pay(LnurlPayRequest {
             prepare_response,
             idempotency_key: None,
+            max_fee: None,
         })
         .await?;
     info!("Alice1 initiated payment to Bob");
@@ -190,7 +197,7 @@ async fn test_01_rtsync_lnurl_info_sync(
 
     // Wait for data-sync to propagate payment metadata to Alice2
     wait_for_synced_event(&mut alice2.events, 30).await?;
-    alice2.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice2.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     // Alice2 should now see the payment, including LNURL information
     let alice2_payment = alice2
@@ -264,6 +271,11 @@ async fn test_02_rtsync_lightning_address_sync(
         .register_lightning_address(RegisterLightningAddressRequest {
             username: "alicesync".to_string(),
             description: Some("Alice's synced address".to_string()),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable_sats: None,
+            max_sendable_sats: None,
         })
         .await?;
     info!(
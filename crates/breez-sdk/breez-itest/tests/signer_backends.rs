@@ -63,6 +63,7 @@ async fn info_and_address(#[case] backend: SignerBackend) -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?;
     info!("[{backend:?}] balance: {} sats", info.balance_sats);
@@ -88,6 +89,7 @@ async fn fund_onchain_deposit(#[case] backend: SignerBackend) -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?;
     assert!(info.balance_sats >= 1000, "expected funded balance");
@@ -124,6 +126,7 @@ async fn send_receive_spark(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
     let send = tx
@@ -132,6 +135,9 @@ async fn send_receive_spark(
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     assert_eq!(send.payment.payment_type, PaymentType::Send);
@@ -176,6 +182,7 @@ async fn lightning_receive(#[case] backend: SignerBackend) -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
     sender
@@ -187,6 +194,9 @@ async fn lightning_receive(#[case] backend: SignerBackend) -> Result<()> {
                 completion_timeout_secs: Some(10),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -222,7 +232,7 @@ async fn static_deposit_refund(#[case] backend: SignerBackend) -> Result<()> {
     let txid = faucet.fund_address(&address, 25_000).await?;
     info!("[{backend:?}] funded deposit {txid}, awaiting unclaimed event");
 
-    sdk.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    sdk.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let unclaimed = wait_for_unclaimed_event(&mut sdk.events, 180).await?;
     assert!(!unclaimed.is_empty(), "expected an unclaimed deposit");
 
@@ -275,6 +285,7 @@ async fn token_mint(#[case] backend: SignerBackend) -> Result<()> {
             sdk.sdk
                 .get_info(GetInfoRequest {
                     ensure_synced: Some(true),
+                    ..Default::default()
                 })
                 .await?
                 .token_balances
@@ -289,6 +300,7 @@ async fn token_mint(#[case] backend: SignerBackend) -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(true),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -296,7 +308,10 @@ async fn token_mint(#[case] backend: SignerBackend) -> Result<()> {
         .map_or(0, |b| b.balance);
 
     issuer
-        .mint_issuer_token(MintIssuerTokenRequest { amount: 1000 })
+        .mint_issuer_token(MintIssuerTokenRequest {
+            amount: 1000,
+            idempotency_key: None,
+        })
         .await?;
 
     let after = wait_for_token_balance_increase(&sdk.sdk, &token_id, before, 30).await?;
@@ -366,6 +381,7 @@ async fn assert_update_settings_fails_under_deny_export(config: Config) -> Resul
         .update_user_settings(UpdateUserSettingsRequest {
             spark_private_mode_enabled: Some(true),
             stable_balance_active_label: None,
+            display_currency: None,
         })
         .await
         .expect_err(
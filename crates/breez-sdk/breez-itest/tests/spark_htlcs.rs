@@ -32,6 +32,7 @@ async fn send_htlc_alice_to_bob(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -48,6 +49,9 @@ async fn send_htlc_alice_to_bob(
                 }),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -57,7 +61,7 @@ async fn send_htlc_alice_to_bob(
         "Payment should be pending"
     );
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_list_payments_response = bob
         .sdk
         .list_payments(ListPaymentsRequest {
@@ -175,11 +179,12 @@ async fn test_01_htlc_success(
     ));
 
     // Verify Bob's balance increased
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -209,6 +214,7 @@ async fn test_02_htlc_refund(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -223,6 +229,7 @@ async fn test_02_htlc_refund(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -240,7 +247,7 @@ async fn test_02_htlc_refund(
 
     info!("Verifying Bob's failed payment...");
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let bob_payments = bob
         .sdk
@@ -273,7 +280,7 @@ async fn test_02_htlc_refund(
 
     info!("Verifying Alice's failed payment...");
 
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let alice_payments = alice
         .sdk
         .list_payments(ListPaymentsRequest {
@@ -354,6 +361,7 @@ async fn test_03_reconcile_stale_pending_payment(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -368,6 +376,9 @@ async fn test_03_reconcile_stale_pending_payment(
                 }),
             }),
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -394,6 +405,7 @@ async fn test_03_reconcile_stale_pending_payment(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -403,6 +415,9 @@ async fn test_03_reconcile_stale_pending_payment(
             prepare_response: prepare2,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -412,7 +427,7 @@ async fn test_03_reconcile_stale_pending_payment(
 
     // Step 3: Explicit sync to commit the advanced offset. After this the HTLC is
     // invisible to the offset-based sync (which starts from N+1 onwards).
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let pending = alice
         .sdk
@@ -49,6 +49,7 @@ async fn test_shared_ssp_connection_manager_spark_transfer() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .identity_pubkey;
@@ -56,6 +57,7 @@ async fn test_shared_ssp_connection_manager_spark_transfer() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .identity_pubkey;
@@ -70,6 +72,7 @@ async fn test_shared_ssp_connection_manager_spark_transfer() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -92,6 +95,7 @@ async fn test_shared_ssp_connection_manager_spark_transfer() -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -101,6 +105,9 @@ async fn test_shared_ssp_connection_manager_spark_transfer() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     assert!(matches!(
@@ -112,11 +119,12 @@ async fn test_shared_ssp_connection_manager_spark_transfer() -> Result<()> {
         wait_for_payment_succeeded_event(&mut bob.events, PaymentType::Receive, 60).await?;
     assert!(received.amount >= 5);
 
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
     let bob_final = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .balance_sats;
@@ -17,13 +17,19 @@ async fn create_mint_test_token(instance: &SdkInstance) -> Result<TokenMetadata>
         .await?;
 
     issuer
-        .mint_issuer_token(MintIssuerTokenRequest { amount: 1_000_000 })
+        .mint_issuer_token(MintIssuerTokenRequest {
+            amount: 1_000_000,
+            idempotency_key: None,
+        })
         .await?;
 
     info!("Minted 1,000,000 tokens");
 
     tokio::time::sleep(std::time::Duration::from_secs(1)).await;
-    instance.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    instance
+        .sdk
+        .sync_wallet(SyncWalletRequest::default())
+        .await?;
     Ok(token_metadata)
 }
 
@@ -55,6 +61,7 @@ async fn test_01_token_transfer(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -71,6 +78,7 @@ async fn test_01_token_transfer(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -103,6 +111,7 @@ async fn test_01_token_transfer(
             token_identifier: Some(token_metadata.identifier.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
     info!("Prepare response amount: {:?}", prepare.amount);
@@ -113,6 +122,9 @@ async fn test_01_token_transfer(
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -161,7 +173,7 @@ async fn test_01_token_transfer(
 
     // Sync Bob's wallet to receive the payment
     tokio::time::sleep(std::time::Duration::from_secs(2)).await;
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     // Confirm payment is now completed for Bob
     let bob_payment = bob
@@ -212,6 +224,7 @@ async fn test_01_token_transfer(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -228,6 +241,7 @@ async fn test_01_token_transfer(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -267,6 +281,7 @@ async fn test_02_token_invoice(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -288,6 +303,7 @@ async fn test_02_token_invoice(
                 expiry_time: None,
                 description: Some("test invoice".to_string()),
                 sender_public_key: None,
+                accept_partial_payments: None,
             },
         })
         .await?;
@@ -309,6 +325,7 @@ async fn test_02_token_invoice(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -327,6 +344,9 @@ async fn test_02_token_invoice(
             prepare_response,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -360,7 +380,7 @@ async fn test_02_token_invoice(
 
     // Sync Bob's wallet
     tokio::time::sleep(std::time::Duration::from_secs(2)).await;
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let bob_payment = bob
         .sdk
@@ -402,6 +422,7 @@ async fn test_02_token_invoice(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -418,6 +439,7 @@ async fn test_02_token_invoice(
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -453,6 +475,7 @@ async fn test_03_token_burning(#[future] alice_sdk: Result<SdkInstance>) -> Resu
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -471,6 +494,7 @@ async fn test_03_token_burning(#[future] alice_sdk: Result<SdkInstance>) -> Resu
         .get_token_issuer()
         .burn_issuer_token(BurnIssuerTokenRequest {
             amount: burn_amount,
+            idempotency_key: None,
         })
         .await?;
 
@@ -495,12 +519,13 @@ async fn test_03_token_burning(#[future] alice_sdk: Result<SdkInstance>) -> Resu
 
     // Verify token balance after burning
     //tokio::time::sleep(std::time::Duration::from_secs(1)).await;
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let after_burn_balance = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -562,11 +587,14 @@ async fn test_04_token_freeze_unfreeze(
     alice
         .sdk
         .get_token_issuer()
-        .mint_issuer_token(MintIssuerTokenRequest { amount: 1_000_000 })
+        .mint_issuer_token(MintIssuerTokenRequest {
+            amount: 1_000_000,
+            idempotency_key: None,
+        })
         .await?;
 
     tokio::time::sleep(std::time::Duration::from_secs(1)).await;
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     info!(
         "Created freezable token: {} ({})",
@@ -590,6 +618,7 @@ async fn test_04_token_freeze_unfreeze(
             token_identifier: Some(token_metadata.identifier.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -599,17 +628,21 @@ async fn test_04_token_freeze_unfreeze(
             prepare_response: prepare_send,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
     // Sync and verify Bob received tokens
     tokio::time::sleep(std::time::Duration::from_secs(2)).await;
-    bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let bob_balance = bob
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -671,6 +704,7 @@ async fn test_04_token_freeze_unfreeze(
             token_identifier: Some(token_metadata.identifier.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await;
 
@@ -682,6 +716,9 @@ async fn test_04_token_freeze_unfreeze(
                 prepare_response: bob_prepare,
                 options: None,
                 idempotency_key: None,
+                max_fee: None,
+                deliver_after: None,
+                leaf_selection: None,
             })
             .await;
 
@@ -733,6 +770,7 @@ async fn test_04_token_freeze_unfreeze(
             token_identifier: Some(token_metadata.identifier.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -742,6 +780,9 @@ async fn test_04_token_freeze_unfreeze(
             prepare_response: bob_prepare_after_unfreeze,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -790,6 +831,7 @@ async fn test_05_invoice_expiry(
                 expiry_time,
                 description: Some("expiring invoice".to_string()),
                 sender_public_key: None,
+                accept_partial_payments: None,
             },
         })
         .await?;
@@ -810,6 +852,7 @@ async fn test_05_invoice_expiry(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -829,6 +872,7 @@ async fn test_05_invoice_expiry(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await;
 
@@ -846,6 +890,9 @@ async fn test_05_invoice_expiry(
             prepare_response: alice_prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await;
 
@@ -859,12 +906,13 @@ async fn test_05_invoice_expiry(
             // If it succeeded, verify it was processed
             if send_resp.payment.status == PaymentStatus::Completed {
                 tokio::time::sleep(std::time::Duration::from_secs(2)).await;
-                bob.sdk.sync_wallet(SyncWalletRequest {}).await?;
+                bob.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
                 let bob_balance = bob
                     .sdk
                     .get_info(GetInfoRequest {
                         ensure_synced: Some(false),
+                        ..Default::default()
                     })
                     .await?
                     .token_balances
@@ -914,16 +962,20 @@ async fn test_06_supply_limits(#[future] alice_sdk: Result<SdkInstance>) -> Resu
     alice
         .sdk
         .get_token_issuer()
-        .mint_issuer_token(MintIssuerTokenRequest { amount: max_supply })
+        .mint_issuer_token(MintIssuerTokenRequest {
+            amount: max_supply,
+            idempotency_key: None,
+        })
         .await?;
 
     tokio::time::sleep(std::time::Duration::from_secs(1)).await;
-    alice.sdk.sync_wallet(SyncWalletRequest {}).await?;
+    alice.sdk.sync_wallet(SyncWalletRequest::default()).await?;
 
     let balance = alice
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -937,7 +989,10 @@ async fn test_06_supply_limits(#[future] alice_sdk: Result<SdkInstance>) -> Resu
     let mint_extra_result = alice
         .sdk
         .get_token_issuer()
-        .mint_issuer_token(MintIssuerTokenRequest { amount: 100 })
+        .mint_issuer_token(MintIssuerTokenRequest {
+            amount: 100,
+            idempotency_key: None,
+        })
         .await;
 
     // This should fail due to exceeding max supply
@@ -1024,6 +1079,7 @@ async fn test_07_token_payment_realtime_event() -> Result<()> {
             token_identifier: Some(token_metadata.identifier.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -1033,6 +1089,9 @@ async fn test_07_token_payment_realtime_event() -> Result<()> {
             prepare_response: prepare,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -1081,6 +1140,7 @@ async fn test_07_token_payment_realtime_event() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -1115,6 +1175,7 @@ async fn test_07_token_payment_realtime_event() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -1154,6 +1215,7 @@ async fn test_07_token_payment_realtime_event() -> Result<()> {
             token_identifier: Some(token_metadata.identifier.clone()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -1162,6 +1224,9 @@ async fn test_07_token_payment_realtime_event() -> Result<()> {
             prepare_response: prepare2,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
 
@@ -1190,6 +1255,7 @@ async fn test_07_token_payment_realtime_event() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
@@ -1206,6 +1272,7 @@ async fn test_07_token_payment_realtime_event() -> Result<()> {
         .sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            ..Default::default()
         })
         .await?
         .token_balances
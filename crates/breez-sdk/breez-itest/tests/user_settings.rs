@@ -61,6 +61,7 @@ async fn test_01_spark_private_mode_user_setting(
         .update_user_settings(UpdateUserSettingsRequest {
             stable_balance_active_label: None,
             spark_private_mode_enabled: Some(false),
+            display_currency: None,
         })
         .await?;
     default_non_private
@@ -68,6 +69,7 @@ async fn test_01_spark_private_mode_user_setting(
         .update_user_settings(UpdateUserSettingsRequest {
             stable_balance_active_label: None,
             spark_private_mode_enabled: Some(true),
+            display_currency: None,
         })
         .await?;
 
@@ -154,6 +156,7 @@ async fn test_02_stable_balance_user_setting(
         .update_user_settings(UpdateUserSettingsRequest {
             spark_private_mode_enabled: None,
             stable_balance_active_label: Some(StableBalanceActiveLabel::Unset),
+            display_currency: None,
         })
         .await?;
     let settings = sdk_instance.sdk.get_user_settings().await?;
@@ -167,6 +170,7 @@ async fn test_02_stable_balance_user_setting(
             stable_balance_active_label: Some(StableBalanceActiveLabel::Set {
                 label: "BEAN".to_string(),
             }),
+            display_currency: None,
         })
         .await?;
     let settings = sdk_instance.sdk.get_user_settings().await?;
@@ -1,4 +1,7 @@
-use breez_sdk_spark::{AddContactRequest, BreezSdk, ListContactsRequest, UpdateContactRequest};
+use breez_sdk_spark::{
+    AddContactRequest, BreezSdk, GetContactPaymentsRequest, ListContactsRequest,
+    SuggestContactsRequest, UpdateContactRequest,
+};
 use clap::Subcommand;
 
 use crate::command::print_value;
@@ -11,6 +14,15 @@ pub enum ContactCommand {
         name: String,
         /// Lightning address (user@domain)
         payment_identifier: String,
+        /// Another Lightning address of the contact, can be repeated
+        #[arg(long = "also")]
+        additional_payment_identifiers: Vec<String>,
+        /// URL of the contact's avatar image
+        #[arg(long)]
+        avatar_url: Option<String>,
+        /// Notes about the contact
+        #[arg(long)]
+        notes: Option<String>,
     },
     /// Update an existing contact
     Update {
@@ -20,6 +32,15 @@ pub enum ContactCommand {
         name: String,
         /// New Lightning address (user@domain)
         payment_identifier: String,
+        /// Another Lightning address of the contact, can be repeated
+        #[arg(long = "also")]
+        additional_payment_identifiers: Vec<String>,
+        /// URL of the contact's avatar image
+        #[arg(long)]
+        avatar_url: Option<String>,
+        /// Notes about the contact
+        #[arg(long)]
+        notes: Option<String>,
     },
     /// Delete a contact
     Delete {
@@ -33,6 +54,24 @@ pub enum ContactCommand {
         /// Maximum number of contacts to return
         limit: Option<u32>,
     },
+    /// List the payments made to a contact
+    Payments {
+        /// ID of the contact
+        id: String,
+        /// Number of payments to skip
+        offset: Option<u32>,
+        /// Maximum number of payments to return
+        limit: Option<u32>,
+    },
+    /// Suggest contacts from the addresses paid repeatedly
+    Suggest {
+        /// Minimum number of payments to an address to suggest it
+        #[arg(long)]
+        min_payment_count: Option<u32>,
+        /// Maximum number of suggestions to return
+        #[arg(long)]
+        limit: Option<u32>,
+    },
 }
 
 pub async fn handle_command(
@@ -43,11 +82,17 @@ pub async fn handle_command(
         ContactCommand::Add {
             name,
             payment_identifier,
+            additional_payment_identifiers,
+            avatar_url,
+            notes,
         } => {
             let contact = sdk
                 .add_contact(AddContactRequest {
                     name,
                     payment_identifier,
+                    additional_payment_identifiers,
+                    avatar_url,
+                    notes,
                 })
                 .await?;
             print_value(&contact)?;
@@ -57,12 +102,18 @@ pub async fn handle_command(
             id,
             name,
             payment_identifier,
+            additional_payment_identifiers,
+            avatar_url,
+            notes,
         } => {
             let contact = sdk
                 .update_contact(UpdateContactRequest {
                     id,
                     name,
                     payment_identifier,
+                    additional_payment_identifiers,
+                    avatar_url,
+                    notes,
                 })
                 .await?;
             print_value(&contact)?;
@@ -80,5 +131,29 @@ pub async fn handle_command(
             print_value(&contacts)?;
             Ok(true)
         }
+        ContactCommand::Payments { id, offset, limit } => {
+            let response = sdk
+                .get_contact_payments(GetContactPaymentsRequest {
+                    contact_id: id,
+                    offset,
+                    limit,
+                })
+                .await?;
+            print_value(&response)?;
+            Ok(true)
+        }
+        ContactCommand::Suggest {
+            min_payment_count,
+            limit,
+        } => {
+            let response = sdk
+                .suggest_contacts(SuggestContactsRequest {
+                    min_payment_count,
+                    limit,
+                })
+                .await?;
+            print_value(&response)?;
+            Ok(true)
+        }
     }
 }
@@ -0,0 +1,214 @@
+use breez_sdk_spark::{
+    BreezSdk, CreateArbitratedEscrowRequest, CreateEscrowRequest, DisputeArbitratedEscrowRequest,
+    EscrowRole, GetEscrowRequest, RecoverArbitratedEscrowPreimageRequest,
+    RefundArbitratedEscrowRequest, RefundEscrowRequest, ReleaseArbitratedEscrowRequest,
+    ReleaseEscrowRequest,
+};
+use clap::{Subcommand, ValueEnum};
+
+use crate::command::print_value;
+
+#[derive(Clone, Copy, Debug, ValueEnum)]
+#[clap(rename_all = "lower")]
+pub enum EscrowRoleArg {
+    Initiator,
+    Participant,
+}
+
+impl From<EscrowRoleArg> for EscrowRole {
+    fn from(role: EscrowRoleArg) -> Self {
+        match role {
+            EscrowRoleArg::Initiator => EscrowRole::Initiator,
+            EscrowRoleArg::Participant => EscrowRole::Participant,
+        }
+    }
+}
+
+#[derive(Clone, Debug, Subcommand)]
+pub enum EscrowCommand {
+    /// Lock funds in an escrow with a counterparty
+    Create {
+        /// The payment hash both sides lock their funds to
+        payment_hash: String,
+        /// Whether this wallet chose the preimage and locks first
+        #[arg(value_enum)]
+        role: EscrowRoleArg,
+        /// The Spark address of the counterparty
+        counterparty_address: String,
+        /// The amount in sats to lock
+        send_amount_sats: u64,
+        /// The minimum amount in sats the counterparty has to lock
+        receive_amount_sats: u64,
+        /// The duration in seconds after which the locked funds are returned
+        expiry_duration_secs: u64,
+    },
+    /// Get an escrow and its current status
+    Get {
+        /// The ID of the escrow
+        escrow_id: String,
+    },
+    /// List escrows
+    List,
+    /// Claim the counterparty's funds
+    Release {
+        /// The ID of the escrow
+        escrow_id: String,
+        /// The preimage, optional for the participant once the initiator released
+        #[arg(short, long)]
+        preimage: Option<String>,
+    },
+    /// Reclaim the locked funds once they expired
+    Refund {
+        /// The ID of the escrow
+        escrow_id: String,
+    },
+    /// Lock funds for a seller, releasable by two of this wallet, the seller
+    /// and an arbiter
+    CreateArbitrated {
+        /// The Spark address of the seller
+        seller_address: String,
+        /// The public key of the arbiter
+        arbiter_pubkey: String,
+        /// The amount in sats to lock
+        amount_sats: u64,
+        /// The duration in seconds after which the locked funds are returned
+        expiry_duration_secs: u64,
+    },
+    /// List arbitrated escrows
+    ListArbitrated,
+    /// Release an arbitrated escrow to the seller
+    ReleaseArbitrated {
+        /// The ID of the escrow
+        escrow_id: String,
+    },
+    /// Dispute an arbitrated escrow, leaving the decision to the arbiter
+    DisputeArbitrated {
+        /// The ID of the escrow
+        escrow_id: String,
+        /// The reason of the dispute
+        reason: String,
+    },
+    /// Approve the refund of an arbitrated escrow once its HTLC expires
+    RefundArbitrated {
+        /// The ID of the escrow
+        escrow_id: String,
+        /// The seller's or the arbiter's signature of `refund:<payment_hash>`
+        signature: String,
+    },
+    /// Recover the preimage of an arbitrated escrow from two shares
+    RecoverArbitratedPreimage {
+        /// The shares of two of the buyer, the seller and the arbiter
+        shares: Vec<String>,
+    },
+}
+
+pub async fn handle_command(sdk: &BreezSdk, command: EscrowCommand) -> Result<bool, anyhow::Error> {
+    match command {
+        EscrowCommand::Create {
+            payment_hash,
+            role,
+            counterparty_address,
+            send_amount_sats,
+            receive_amount_sats,
+            expiry_duration_secs,
+        } => {
+            let res = sdk
+                .create_escrow(CreateEscrowRequest {
+                    payment_hash,
+                    role: role.into(),
+                    counterparty_address,
+                    send_amount_sats,
+                    receive_amount_sats,
+                    expiry_duration_secs,
+                })
+                .await?;
+            print_value(&res.escrow)?;
+            Ok(true)
+        }
+        EscrowCommand::Get { escrow_id } => {
+            let res = sdk.get_escrow(GetEscrowRequest { escrow_id }).await?;
+            print_value(&res.escrow)?;
+            Ok(true)
+        }
+        EscrowCommand::List => {
+            let res = sdk.list_escrows().await?;
+            print_value(&res.escrows)?;
+            Ok(true)
+        }
+        EscrowCommand::Release {
+            escrow_id,
+            preimage,
+        } => {
+            let res = sdk
+                .release_escrow(ReleaseEscrowRequest {
+                    escrow_id,
+                    preimage,
+                })
+                .await?;
+            print_value(&res.escrow)?;
+            Ok(true)
+        }
+        EscrowCommand::Refund { escrow_id } => {
+            let res = sdk.refund_escrow(RefundEscrowRequest { escrow_id }).await?;
+            print_value(&res.escrow)?;
+            Ok(true)
+        }
+        EscrowCommand::CreateArbitrated {
+            seller_address,
+            arbiter_pubkey,
+            amount_sats,
+            expiry_duration_secs,
+        } => {
+            let res = sdk
+                .create_arbitrated_escrow(CreateArbitratedEscrowRequest {
+                    seller_address,
+                    arbiter_pubkey,
+                    amount_sats,
+                    expiry_duration_secs,
+                })
+                .await?;
+            print_value(&res.escrow)?;
+            Ok(true)
+        }
+        EscrowCommand::ListArbitrated => {
+            let res = sdk.list_arbitrated_escrows().await?;
+            print_value(&res.escrows)?;
+            Ok(true)
+        }
+        EscrowCommand::ReleaseArbitrated { escrow_id } => {
+            let res = sdk
+                .release_arbitrated_escrow(ReleaseArbitratedEscrowRequest { escrow_id })
+                .await?;
+            print_value(&res.escrow)?;
+            Ok(true)
+        }
+        EscrowCommand::DisputeArbitrated { escrow_id, reason } => {
+            let res = sdk
+                .dispute_arbitrated_escrow(DisputeArbitratedEscrowRequest { escrow_id, reason })
+                .await?;
+            print_value(&res.escrow)?;
+            Ok(true)
+        }
+        EscrowCommand::RefundArbitrated {
+            escrow_id,
+            signature,
+        } => {
+            let res = sdk
+                .refund_arbitrated_escrow(RefundArbitratedEscrowRequest {
+                    escrow_id,
+                    signature,
+                })
+                .await?;
+            print_value(&res.escrow)?;
+            Ok(true)
+        }
+        EscrowCommand::RecoverArbitratedPreimage { shares } => {
+            let res =
+                sdk.recover_arbitrated_escrow_preimage(RecoverArbitratedEscrowPreimageRequest {
+                    shares,
+                })?;
+            print_value(&res)?;
+            Ok(true)
+        }
+    }
+}
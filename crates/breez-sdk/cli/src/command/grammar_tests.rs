@@ -8,10 +8,15 @@ use breez_sdk_spark::{
 use clap::Parser;
 
 use super::contacts::ContactCommand;
+use super::escrow::{EscrowCommand, EscrowRoleArg};
 use super::issuer::IssuerCommand;
+use super::jobs::JobCommand;
 use super::stable_balance::StableBalanceCommand;
 use super::webhooks::{WebhookCommand, WebhookEventTypeArg};
-use super::{Command, ReceivePaymentMethodArg};
+use super::{
+    Command, LeafSelectionStrategyArg, PaymentExportFormatArg, RateResolutionArg,
+    ReceivePaymentMethodArg, SyncDomainArg,
+};
 
 fn parse(line: &str) -> Result<Command, clap::Error> {
     let mut args = vec!["breez-cli".to_string()];
@@ -35,23 +40,93 @@ fn get_info() {
     assert!(matches!(
         parse_ok("get-info"),
         Command::GetInfo {
-            ensure_synced: None
+            ensure_synced: None,
+            include_hidden: false
         }
     ));
     assert!(matches!(
         parse_ok("get-info -e true"),
         Command::GetInfo {
-            ensure_synced: Some(true)
+            ensure_synced: Some(true),
+            include_hidden: false
         }
     ));
     assert!(matches!(
         parse_ok("get-info --ensure-synced false"),
         Command::GetInfo {
-            ensure_synced: Some(false)
+            ensure_synced: Some(false),
+            include_hidden: false
+        }
+    ));
+    assert!(matches!(
+        parse_ok("get-info --include-hidden"),
+        Command::GetInfo {
+            include_hidden: true,
+            ..
         }
     ));
 }
 
+#[test]
+fn seed_backup() {
+    let Command::GetSeedBackupChallenge { word_count } = parse_ok("get-seed-backup-challenge -w 4")
+    else {
+        panic!("expected GetSeedBackupChallenge");
+    };
+    assert_eq!(word_count, Some(4));
+
+    let Command::VerifySeedBackup { words } = parse_ok("verify-seed-backup 1:abandon 12:about")
+    else {
+        panic!("expected VerifySeedBackup");
+    };
+    assert_eq!(words, vec!["1:abandon", "12:about"]);
+    parse_err("verify-seed-backup");
+
+    assert!(matches!(
+        parse_ok("run-signer-self-test"),
+        Command::RunSignerSelfTest
+    ));
+}
+
+#[test]
+fn state_backup() {
+    let Command::BackupState { url } = parse_ok("backup-state https://example.com/backup") else {
+        panic!("expected BackupState");
+    };
+    assert_eq!(url, "https://example.com/backup");
+    parse_err("backup-state");
+
+    let Command::RestoreState { url } = parse_ok("restore-state https://example.com/backup") else {
+        panic!("expected RestoreState");
+    };
+    assert_eq!(url, "https://example.com/backup");
+    parse_err("restore-state");
+}
+
+#[test]
+fn derive_application_key() {
+    let Command::DeriveApplicationKey { label } = parse_ok("derive-application-key nostr") else {
+        panic!("expected DeriveApplicationKey");
+    };
+    assert_eq!(label, "nostr");
+    parse_err("derive-application-key");
+}
+
+#[test]
+fn freeze_wallet() {
+    let Command::FreezeWallet { password } = parse_ok("freeze-wallet secret") else {
+        panic!("expected FreezeWallet");
+    };
+    assert_eq!(password, "secret");
+    parse_err("freeze-wallet");
+
+    let Command::UnfreezeWallet { password } = parse_ok("unfreeze-wallet secret") else {
+        panic!("expected UnfreezeWallet");
+    };
+    assert_eq!(password, "secret");
+    parse_err("unfreeze-wallet");
+}
+
 #[test]
 fn get_payment() {
     let Command::GetPayment { payment_id } = parse_ok("get-payment abc123") else {
@@ -63,7 +138,52 @@ fn get_payment() {
 
 #[test]
 fn sync() {
-    assert!(matches!(parse_ok("sync"), Command::Sync));
+    let Command::Sync { domains } = parse_ok("sync") else {
+        panic!("expected Sync");
+    };
+    assert!(domains.is_empty());
+}
+
+#[test]
+fn sync_domains() {
+    let Command::Sync { domains } = parse_ok("sync payments lightning-address") else {
+        panic!("expected Sync");
+    };
+    assert!(matches!(
+        domains.as_slice(),
+        [SyncDomainArg::Payments, SyncDomainArg::LightningAddress]
+    ));
+    parse_err("sync tokens");
+}
+
+#[test]
+fn get_sync_status() {
+    assert!(matches!(
+        parse_ok("get-sync-status"),
+        Command::GetSyncStatus
+    ));
+}
+
+#[test]
+fn background_sync() {
+    assert!(matches!(
+        parse_ok("pause-background-sync"),
+        Command::PauseBackgroundSync
+    ));
+    assert!(matches!(
+        parse_ok("resume-background-sync"),
+        Command::ResumeBackgroundSync
+    ));
+}
+
+#[test]
+fn devices() {
+    assert!(matches!(parse_ok("list-devices"), Command::ListDevices));
+    assert!(matches!(
+        parse_ok("set-device-name Laptop"),
+        Command::SetDeviceName { name } if name == "Laptop"
+    ));
+    parse_err("set-device-name");
 }
 
 #[test]
@@ -144,6 +264,37 @@ fn list_payments_filters() {
     parse_err("list-payments --limit ten");
 }
 
+#[test]
+fn get_token_activity_args() {
+    let Command::GetTokenActivity {
+        token_identifier,
+        limit,
+        offset,
+    } = parse_ok("get-token-activity tok1 -l 5 -o 10")
+    else {
+        panic!("expected GetTokenActivity");
+    };
+    assert_eq!(token_identifier, "tok1");
+    assert_eq!(limit, Some(5));
+    assert_eq!(offset, Some(10));
+
+    parse_err("get-token-activity");
+}
+
+#[test]
+fn hide_token() {
+    let Command::HideToken { token_identifier } = parse_ok("hide-token tok1") else {
+        panic!("expected HideToken");
+    };
+    assert_eq!(token_identifier, "tok1");
+    let Command::UnhideToken { token_identifier } = parse_ok("unhide-token tok1") else {
+        panic!("expected UnhideToken");
+    };
+    assert_eq!(token_identifier, "tok1");
+
+    parse_err("hide-token");
+}
+
 #[test]
 fn receive_methods() {
     for (line, expected) in [
@@ -216,6 +367,11 @@ fn pay() {
         convert_max_slippage_bps,
         cross_chain_max_slippage_bps,
         fees_included,
+        simulate,
+        background,
+        deliver_after,
+        leaf_ids,
+        leaf_strategy,
     } = parse_ok(
         "pay -r lnbc1... -a 1000 -t tok1 -i key1 -s 40 --cross-chain-max-slippage-bps 100",
     )
@@ -231,6 +387,11 @@ fn pay() {
     assert_eq!(convert_max_slippage_bps, Some(40));
     assert_eq!(cross_chain_max_slippage_bps, Some(100));
     assert!(!fees_included);
+    assert!(!simulate);
+    assert!(!background);
+    assert!(deliver_after.is_none());
+    assert!(leaf_ids.is_empty());
+    assert!(leaf_strategy.is_none());
 
     let Command::Pay {
         convert_from_bitcoin,
@@ -252,7 +413,28 @@ fn pay() {
     };
     assert_eq!(convert_from_token_identifier.as_deref(), Some("tok1"));
 
+    let Command::Pay {
+        deliver_after,
+        leaf_ids,
+        ..
+    } = parse_ok("pay -r addr1 --deliver-after 1700000000 --leaf-id l1 --leaf-id l2")
+    else {
+        panic!("expected Pay");
+    };
+    assert_eq!(deliver_after, Some(1_700_000_000));
+    assert_eq!(leaf_ids, vec!["l1".to_string(), "l2".to_string()]);
+
+    let Command::Pay { leaf_strategy, .. } = parse_ok("pay -r addr1 --leaf-strategy privacy")
+    else {
+        panic!("expected Pay");
+    };
+    assert!(matches!(
+        leaf_strategy,
+        Some(LeafSelectionStrategyArg::Privacy)
+    ));
+
     parse_err("pay");
+    parse_err("pay -r addr1 --leaf-id l1 --leaf-strategy privacy");
     let err = parse_err("pay -r addr1 --from-bitcoin --from-token tok1");
     assert!(
         err.contains("cannot be used with"),
@@ -260,6 +442,42 @@ fn pay() {
     );
 }
 
+#[test]
+fn payment_streams() {
+    let Command::OpenPaymentStream {
+        destination,
+        rate_sat_per_sec,
+        max_amount_sat,
+        interval_secs,
+    } = parse_ok("open-payment-stream sp1dest 2 600 --interval-secs 30")
+    else {
+        panic!("expected OpenPaymentStream");
+    };
+    assert_eq!(destination, "sp1dest");
+    assert_eq!(rate_sat_per_sec, 2);
+    assert_eq!(max_amount_sat, 600);
+    assert_eq!(interval_secs, Some(30));
+
+    let Command::OpenPaymentStream { interval_secs, .. } =
+        parse_ok("open-payment-stream sp1dest 2 600")
+    else {
+        panic!("expected OpenPaymentStream");
+    };
+    assert!(interval_secs.is_none());
+    parse_err("open-payment-stream sp1dest 2");
+
+    let Command::ClosePaymentStream { stream_id } = parse_ok("close-payment-stream s1") else {
+        panic!("expected ClosePaymentStream");
+    };
+    assert_eq!(stream_id, "s1");
+    parse_err("close-payment-stream");
+
+    assert!(matches!(
+        parse_ok("list-payment-streams"),
+        Command::ListPaymentStreams
+    ));
+}
+
 #[test]
 fn lnurl_pay() {
     let Command::LnurlPay {
@@ -351,13 +569,65 @@ fn claim_deposit() {
     parse_err("claim-deposit tx1 notanumber");
 }
 
+#[test]
+fn claim_deposits() {
+    let Command::ClaimDeposits {
+        deposits,
+        max_total_fee_sat,
+    } = parse_ok("claim-deposits tx1:0 tx2:1 --max-total-fee-sat 1000")
+    else {
+        panic!("expected ClaimDeposits");
+    };
+    assert_eq!(deposits, vec!["tx1:0", "tx2:1"]);
+    assert_eq!(max_total_fee_sat, Some(1000));
+
+    let Command::ClaimDeposits {
+        max_total_fee_sat, ..
+    } = parse_ok("claim-deposits tx1:0")
+    else {
+        panic!("expected ClaimDeposits");
+    };
+    assert!(max_total_fee_sat.is_none());
+
+    parse_err("claim-deposits");
+}
+
+#[test]
+fn get_deposit_address_history() {
+    assert!(matches!(
+        parse_ok("get-deposit-address-history"),
+        Command::GetDepositAddressHistory
+    ));
+}
+
+#[test]
+fn request_test_funds() {
+    let Command::RequestTestFunds { amount_sats } = parse_ok("request-test-funds 50000") else {
+        panic!("expected RequestTestFunds");
+    };
+    assert_eq!(amount_sats, 50_000);
+    parse_err("request-test-funds");
+    parse_err("request-test-funds abc");
+}
+
 #[test]
 fn parse_input() {
-    let Command::Parse { input } = parse_ok("parse lnbc1...") else {
+    let Command::Parse { input, offline } = parse_ok("parse lnbc1...") else {
         panic!("expected Parse");
     };
     assert_eq!(input, "lnbc1...");
+    assert!(!offline);
+    let Command::Parse { offline, .. } = parse_ok("parse --offline lnbc1...") else {
+        panic!("expected Parse");
+    };
+    assert!(offline);
     parse_err("parse");
+
+    let Command::HandleIncomingUri { uri } = parse_ok("handle-incoming-uri spark:sp1abc") else {
+        panic!("expected HandleIncomingUri");
+    };
+    assert_eq!(uri, "spark:sp1abc");
+    parse_err("handle-incoming-uri");
 }
 
 #[test]
@@ -381,6 +651,23 @@ fn refund_deposit() {
     parse_err("refund-deposit tx1 0");
 }
 
+#[test]
+fn bump_refund_fee() {
+    let Command::BumpRefundFee {
+        tx_id,
+        fee_sat,
+        sat_per_vbyte,
+    } = parse_ok("bump-refund-fee tx1 --fee-sat 500")
+    else {
+        panic!("expected BumpRefundFee");
+    };
+    assert_eq!(tx_id, "tx1");
+    assert_eq!(fee_sat, Some(500));
+    assert!(sat_per_vbyte.is_none());
+
+    parse_err("bump-refund-fee");
+}
+
 #[test]
 fn list_unclaimed_deposits() {
     assert!(matches!(
@@ -389,6 +676,22 @@ fn list_unclaimed_deposits() {
     ));
 }
 
+#[test]
+fn list_onchain_transactions() {
+    assert!(matches!(
+        parse_ok("list-onchain-transactions"),
+        Command::ListOnchainTransactions
+    ));
+}
+
+#[test]
+fn get_remaining_allowance() {
+    assert!(matches!(
+        parse_ok("get-remaining-allowance"),
+        Command::GetRemainingAllowance
+    ));
+}
+
 #[test]
 fn buy_bitcoin() {
     let Command::BuyBitcoin {
@@ -433,24 +736,114 @@ fn lightning_address() {
     let Command::RegisterLightningAddress {
         username,
         description,
+        label,
+        ..
     } = parse_ok("register-lightning-address alice \"my address\"")
     else {
         panic!("expected RegisterLightningAddress");
     };
     assert_eq!(username, "alice");
     assert_eq!(description.as_deref(), Some("my address"));
+    assert!(label.is_none());
 
-    let Command::RegisterLightningAddress { description, .. } =
-        parse_ok("register-lightning-address alice")
+    let Command::RegisterLightningAddress {
+        description, label, ..
+    } = parse_ok("register-lightning-address alice")
     else {
         panic!("expected RegisterLightningAddress");
     };
     assert!(description.is_none());
+    assert!(label.is_none());
+
+    let Command::RegisterLightningAddress {
+        username, label, ..
+    } = parse_ok("register-lightning-address alice.shop --label shop")
+    else {
+        panic!("expected RegisterLightningAddress");
+    };
+    assert_eq!(username, "alice.shop");
+    assert_eq!(label.as_deref(), Some("shop"));
+
+    let Command::RegisterLightningAddress {
+        image,
+        long_description,
+        min_sendable_sats,
+        max_sendable_sats,
+        ..
+    } = parse_ok(
+        "register-lightning-address alice --image iVBORw0KGgo= --long-description \"Alice's bakery\" --min-sendable-sats 100 --max-sendable-sats 50000",
+    )
+    else {
+        panic!("expected RegisterLightningAddress");
+    };
+    assert_eq!(image.as_deref(), Some("iVBORw0KGgo="));
+    assert_eq!(long_description.as_deref(), Some("Alice's bakery"));
+    assert_eq!(min_sendable_sats, Some(100));
+    assert_eq!(max_sendable_sats, Some(50_000));
+
+    assert!(matches!(
+        parse_ok("list-lightning-addresses"),
+        Command::ListLightningAddresses
+    ));
 
     assert!(matches!(
         parse_ok("delete-lightning-address"),
-        Command::DeleteLightningAddress
+        Command::DeleteLightningAddress { label: None }
     ));
+    let Command::DeleteLightningAddress { label } =
+        parse_ok("delete-lightning-address --label shop")
+    else {
+        panic!("expected DeleteLightningAddress");
+    };
+    assert_eq!(label.as_deref(), Some("shop"));
+
+    let nostr_pubkey = "a".repeat(64);
+    let Command::UpdateLightningAddressNostrKey {
+        nostr_pubkey: parsed,
+        username,
+    } = parse_ok(&format!(
+        "update-lightning-address-nostr-key {nostr_pubkey} --username alice.shop"
+    ))
+    else {
+        panic!("expected UpdateLightningAddressNostrKey");
+    };
+    assert_eq!(parsed, Some(nostr_pubkey));
+    assert_eq!(username.as_deref(), Some("alice.shop"));
+
+    let Command::UpdateLightningAddressNostrKey {
+        nostr_pubkey,
+        username,
+    } = parse_ok("update-lightning-address-nostr-key")
+    else {
+        panic!("expected UpdateLightningAddressNostrKey");
+    };
+    assert!(nostr_pubkey.is_none());
+    assert!(username.is_none());
+}
+
+#[test]
+fn payment_links() {
+    let Command::CreatePaymentLink { amount_sats, memo } =
+        parse_ok("create-payment-link 5000 \"split dinner\"")
+    else {
+        panic!("expected CreatePaymentLink");
+    };
+    assert_eq!(amount_sats, 5000);
+    assert_eq!(memo, "split dinner");
+
+    parse_err("create-payment-link 5000");
+
+    let Command::ListPaymentLinks { offset, limit } = parse_ok("list-payment-links --limit 10")
+    else {
+        panic!("expected ListPaymentLinks");
+    };
+    assert!(offset.is_none());
+    assert_eq!(limit, Some(10));
+
+    let Command::DeletePaymentLink { id } = parse_ok("delete-payment-link abc123") else {
+        panic!("expected DeletePaymentLink");
+    };
+    assert_eq!(id, "abc123");
 }
 
 #[test]
@@ -491,6 +884,92 @@ fn fiat() {
         parse_ok("list-fiat-rates"),
         Command::ListFiatRates
     ));
+
+    let Command::FetchHistoricalRates {
+        currency,
+        from_timestamp,
+        to_timestamp,
+        resolution,
+    } = parse_ok("fetch-historical-rates USD 1700000000 1700086400 --resolution hour")
+    else {
+        panic!("expected FetchHistoricalRates");
+    };
+    assert_eq!(currency, "USD");
+    assert_eq!(from_timestamp, 1_700_000_000);
+    assert_eq!(to_timestamp, 1_700_086_400);
+    assert!(matches!(resolution, RateResolutionArg::Hour));
+
+    parse_err("fetch-historical-rates USD 1700000000");
+
+    let Command::LockExchangeRate { currency, ttl_secs } =
+        parse_ok("lock-exchange-rate EUR --ttl-secs 300")
+    else {
+        panic!("expected LockExchangeRate");
+    };
+    assert_eq!(currency, "EUR");
+    assert_eq!(ttl_secs, Some(300));
+
+    let Command::ConvertFiatAmount {
+        locked_rate_id,
+        fiat_amount,
+    } = parse_ok("convert-fiat-amount lock-1 10.5")
+    else {
+        panic!("expected ConvertFiatAmount");
+    };
+    assert_eq!(locked_rate_id, "lock-1");
+    assert!((fiat_amount - 10.5).abs() < f64::EPSILON);
+    parse_err("convert-fiat-amount lock-1");
+
+    let Command::ReceiveFiat {
+        currency,
+        fiat_amount,
+        spark,
+        description,
+        expiry_secs,
+        locked_rate_id,
+    } = parse_ok("receive-fiat EUR 10 --spark -d coffee -l lock-1")
+    else {
+        panic!("expected ReceiveFiat");
+    };
+    assert_eq!(currency, "EUR");
+    assert!((fiat_amount - 10.0).abs() < f64::EPSILON);
+    assert!(spark);
+    assert_eq!(description.as_deref(), Some("coffee"));
+    assert_eq!(expiry_secs, None);
+    assert_eq!(locked_rate_id.as_deref(), Some("lock-1"));
+    parse_err("receive-fiat EUR");
+}
+
+#[test]
+fn accounting() {
+    let Command::ExportPayments {
+        format,
+        from_timestamp,
+        to_timestamp,
+    } = parse_ok("export-payments --format camt053 --from-timestamp 100")
+    else {
+        panic!("expected ExportPayments");
+    };
+    assert!(matches!(format, PaymentExportFormatArg::Camt053));
+    assert_eq!(from_timestamp, Some(100));
+    assert!(to_timestamp.is_none());
+
+    let Command::ExportPayments { format, .. } = parse_ok("export-payments") else {
+        panic!("expected ExportPayments");
+    };
+    assert!(matches!(format, PaymentExportFormatArg::Csv));
+
+    let Command::GetAccountingReport {
+        from_timestamp,
+        to_timestamp,
+    } = parse_ok("get-accounting-report --to-timestamp 200")
+    else {
+        panic!("expected GetAccountingReport");
+    };
+    assert!(from_timestamp.is_none());
+    assert_eq!(to_timestamp, Some(200));
+
+    parse_err("export-payments --format xml");
 }
 
 #[test]
@@ -510,6 +989,31 @@ fn get_tokens_metadata() {
     assert_eq!(token_identifiers, vec!["t1", "t2"]);
 }
 
+#[test]
+fn refresh_tokens_metadata() {
+    let Command::RefreshTokensMetadata { token_identifiers } =
+        parse_ok("refresh-tokens-metadata t1")
+    else {
+        panic!("expected RefreshTokensMetadata");
+    };
+    assert_eq!(token_identifiers, vec!["t1"]);
+
+    let Command::RefreshTokensMetadata { token_identifiers } = parse_ok("refresh-tokens-metadata")
+    else {
+        panic!("expected RefreshTokensMetadata");
+    };
+    assert!(token_identifiers.is_empty());
+}
+
+#[test]
+fn get_token_fiat_rates() {
+    let Command::GetTokenFiatRates { token_identifiers } = parse_ok("get-token-fiat-rates t1 t2")
+    else {
+        panic!("expected GetTokenFiatRates");
+    };
+    assert_eq!(token_identifiers, vec!["t1", "t2"]);
+}
+
 #[test]
 fn fetch_conversion_limits() {
     let Command::FetchConversionLimits {
@@ -538,11 +1042,22 @@ fn user_settings() {
     ));
     let Command::SetUserSettings {
         spark_private_mode_enabled,
+        display_currency,
     } = parse_ok("set-user-settings -p true")
     else {
         panic!("expected SetUserSettings");
     };
     assert_eq!(spark_private_mode_enabled, Some(true));
+    assert_eq!(display_currency, None);
+    let Command::SetUserSettings {
+        spark_private_mode_enabled,
+        display_currency,
+    } = parse_ok("set-user-settings --display-currency EUR")
+    else {
+        panic!("expected SetUserSettings");
+    };
+    assert_eq!(spark_private_mode_enabled, None);
+    assert_eq!(display_currency.as_deref(), Some("EUR"));
 }
 
 #[test]
@@ -553,6 +1068,45 @@ fn get_spark_status() {
     ));
 }
 
+#[test]
+fn check_health() {
+    assert!(matches!(parse_ok("check-health"), Command::CheckHealth));
+}
+
+#[test]
+fn get_service_status() {
+    assert!(matches!(
+        parse_ok("get-service-status"),
+        Command::GetServiceStatus
+    ));
+}
+
+#[test]
+fn diagnostics() {
+    let Command::SetLogFilter { filter } = parse_ok("set-log-filter breez_sdk_spark=debug") else {
+        panic!("expected SetLogFilter");
+    };
+    assert_eq!(filter, "breez_sdk_spark=debug");
+    parse_err("set-log-filter");
+    assert!(matches!(
+        parse_ok("dump-diagnostics"),
+        Command::DumpDiagnostics
+    ));
+}
+
+#[test]
+fn list_plugins() {
+    assert!(matches!(parse_ok("list-plugins"), Command::ListPlugins));
+}
+
+#[test]
+fn get_receive_onboarding_info() {
+    assert!(matches!(
+        parse_ok("get-receive-onboarding-info"),
+        Command::GetReceiveOnboardingInfo
+    ));
+}
+
 #[test]
 fn issuer_subcommands() {
     assert!(matches!(
@@ -596,22 +1150,102 @@ fn issuer_subcommands() {
         parse_ok("issuer unfreeze-token addr1"),
         Command::Issuer(IssuerCommand::UnfreezeToken { .. })
     ));
+    assert!(matches!(
+        parse_ok("issuer issuance-stats"),
+        Command::Issuer(IssuerCommand::IssuanceStats { period_secs: None })
+    ));
+    assert!(matches!(
+        parse_ok("issuer issuance-stats --period-secs 3600"),
+        Command::Issuer(IssuerCommand::IssuanceStats {
+            period_secs: Some(3600)
+        })
+    ));
+    let Command::Issuer(IssuerCommand::DistributeTokens {
+        recipients,
+        distribution_id,
+        mint_missing_supply,
+        chunk_size,
+    }) = parse_ok("issuer distribute-tokens addr1:10 addr2:20 -m --chunk-size 5")
+    else {
+        panic!("expected DistributeTokens");
+    };
+    assert_eq!(recipients, vec!["addr1:10", "addr2:20"]);
+    assert!(distribution_id.is_none());
+    assert!(mint_missing_supply);
+    assert_eq!(chunk_size, Some(5));
+    parse_err("issuer distribute-tokens");
 
     parse_err("issuer");
     parse_err("issuer unknown-sub");
 }
 
+#[test]
+fn escrow_subcommands() {
+    let Command::Escrow(EscrowCommand::Create {
+        role,
+        send_amount_sats,
+        receive_amount_sats,
+        expiry_duration_secs,
+        ..
+    }) = parse_ok("escrow create abcd participant sp1addr 1000 2000 3600")
+    else {
+        panic!("expected Escrow Create");
+    };
+    assert!(matches!(role, EscrowRoleArg::Participant));
+    assert_eq!(send_amount_sats, 1000);
+    assert_eq!(receive_amount_sats, 2000);
+    assert_eq!(expiry_duration_secs, 3600);
+
+    assert!(matches!(
+        parse_ok("escrow release id1"),
+        Command::Escrow(EscrowCommand::Release { preimage: None, .. })
+    ));
+    assert!(matches!(
+        parse_ok("escrow release id1 --preimage ff"),
+        Command::Escrow(EscrowCommand::Release {
+            preimage: Some(_),
+            ..
+        })
+    ));
+    assert!(matches!(
+        parse_ok("escrow list"),
+        Command::Escrow(EscrowCommand::List)
+    ));
+    parse_err("escrow create abcd arbiter sp1addr 1000 2000 3600");
+}
+
 #[test]
 fn contacts_subcommands() {
     let Command::Contacts(ContactCommand::Add {
         name,
         payment_identifier,
+        additional_payment_identifiers,
+        ..
     }) = parse_ok("contacts add Alice alice@domain.com")
     else {
         panic!("expected Contacts Add");
     };
     assert_eq!(name, "Alice");
     assert_eq!(payment_identifier, "alice@domain.com");
+    assert!(additional_payment_identifiers.is_empty());
+
+    let Command::Contacts(ContactCommand::Add {
+        additional_payment_identifiers,
+        avatar_url,
+        notes,
+        ..
+    }) = parse_ok(
+        "contacts add Alice alice@domain.com --also alice@other.com --also a@b.com --avatar-url https://example.com/a.png --notes Neighbour",
+    )
+    else {
+        panic!("expected Contacts Add");
+    };
+    assert_eq!(
+        additional_payment_identifiers,
+        vec!["alice@other.com", "a@b.com"]
+    );
+    assert_eq!(avatar_url.as_deref(), Some("https://example.com/a.png"));
+    assert_eq!(notes.as_deref(), Some("Neighbour"));
 
     assert!(matches!(
         parse_ok("contacts update id1 Bob bob@domain.com"),
@@ -635,6 +1269,21 @@ fn contacts_subcommands() {
             limit: Some(20)
         })
     ));
+    assert!(matches!(
+        parse_ok("contacts payments id1 0 10"),
+        Command::Contacts(ContactCommand::Payments {
+            offset: Some(0),
+            limit: Some(10),
+            ..
+        })
+    ));
+    assert!(matches!(
+        parse_ok("contacts suggest --min-payment-count 3 --limit 5"),
+        Command::Contacts(ContactCommand::Suggest {
+            min_payment_count: Some(3),
+            limit: Some(5)
+        })
+    ));
 }
 
 #[test]
@@ -670,6 +1319,37 @@ fn webhooks_subcommands() {
     parse_err("webhooks register https://hook.example s3cret not-an-event");
 }
 
+#[test]
+fn jobs_subcommands() {
+    assert!(matches!(
+        parse_ok("jobs start-optimize"),
+        Command::Jobs(JobCommand::StartOptimize)
+    ));
+    assert!(matches!(
+        parse_ok("jobs start-export-payments -f json --from-timestamp 10"),
+        Command::Jobs(JobCommand::StartExportPayments {
+            format: PaymentExportFormatArg::Json,
+            from_timestamp: Some(10),
+            to_timestamp: None,
+        })
+    ));
+    assert!(matches!(
+        parse_ok("jobs get job1"),
+        Command::Jobs(JobCommand::Get { .. })
+    ));
+    assert!(matches!(
+        parse_ok("jobs list"),
+        Command::Jobs(JobCommand::List)
+    ));
+    assert!(matches!(
+        parse_ok("jobs cancel job1"),
+        Command::Jobs(JobCommand::Cancel { .. })
+    ));
+
+    parse_err("jobs get");
+    parse_err("jobs cancel");
+}
+
 #[test]
 fn stable_balance_subcommands() {
     assert!(matches!(
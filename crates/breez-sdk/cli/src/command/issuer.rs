@@ -1,6 +1,7 @@
 use breez_sdk_spark::{
-    BurnIssuerTokenRequest, CreateIssuerTokenRequest, FreezeIssuerTokenRequest,
-    MintIssuerTokenRequest, TokenIssuer, UnfreezeIssuerTokenRequest,
+    BurnIssuerTokenRequest, CreateIssuerTokenRequest, DistributeTokensRequest,
+    FreezeIssuerTokenRequest, GetIssuanceStatsRequest, MintIssuerTokenRequest, TokenIssuer,
+    TokenRecipient, UnfreezeIssuerTokenRequest,
 };
 use clap::{ArgAction, Subcommand};
 
@@ -46,6 +47,27 @@ pub enum IssuerCommand {
         /// Address holding the tokens to unfreeze
         address: String,
     },
+    /// Gets supply, holder and freeze statistics of the issuer token
+    IssuanceStats {
+        /// Length of the history periods in seconds
+        #[arg(short = 'p', long)]
+        period_secs: Option<u64>,
+    },
+    /// Distributes issuer tokens to many recipients
+    DistributeTokens {
+        /// The recipients, as address:amount
+        #[arg(required = true)]
+        recipients: Vec<String>,
+        /// Id of a distribution to resume
+        #[arg(short = 'i', long)]
+        distribution_id: Option<String>,
+        /// Mint the supply missing from the issuer balance before sending
+        #[arg(short = 'm', long, action = ArgAction::SetTrue)]
+        mint_missing_supply: bool,
+        /// Number of recipients sent to per token transaction
+        #[arg(short = 'c', long)]
+        chunk_size: Option<u32>,
+    },
 }
 
 pub async fn handle_command(
@@ -84,14 +106,20 @@ pub async fn handle_command(
         }
         IssuerCommand::MintToken { amount } => {
             let payment = token_issuer
-                .mint_issuer_token(MintIssuerTokenRequest { amount })
+                .mint_issuer_token(MintIssuerTokenRequest {
+                    amount,
+                    idempotency_key: None,
+                })
                 .await?;
             print_value(&payment)?;
             Ok(true)
         }
         IssuerCommand::BurnToken { amount } => {
             let payment = token_issuer
-                .burn_issuer_token(BurnIssuerTokenRequest { amount })
+                .burn_issuer_token(BurnIssuerTokenRequest {
+                    amount,
+                    idempotency_key: None,
+                })
                 .await?;
             print_value(&payment)?;
             Ok(true)
@@ -110,5 +138,41 @@ pub async fn handle_command(
             print_value(&response)?;
             Ok(true)
         }
+        IssuerCommand::IssuanceStats { period_secs } => {
+            let stats = token_issuer
+                .get_issuance_stats(GetIssuanceStatsRequest { period_secs })
+                .await?;
+            print_value(&stats)?;
+            Ok(true)
+        }
+        IssuerCommand::DistributeTokens {
+            recipients,
+            distribution_id,
+            mint_missing_supply,
+            chunk_size,
+        } => {
+            let recipients = recipients
+                .iter()
+                .map(|recipient| {
+                    let (address, amount) = recipient.rsplit_once(':').ok_or(anyhow::anyhow!(
+                        "Recipient must be formatted as address:amount"
+                    ))?;
+                    Ok(TokenRecipient {
+                        address: address.to_string(),
+                        amount: amount.parse()?,
+                    })
+                })
+                .collect::<Result<Vec<_>, anyhow::Error>>()?;
+            let response = token_issuer
+                .distribute_tokens(DistributeTokensRequest {
+                    recipients,
+                    distribution_id,
+                    mint_missing_supply: Some(mint_missing_supply),
+                    chunk_size,
+                })
+                .await?;
+            print_value(&response)?;
+            Ok(true)
+        }
     }
 }
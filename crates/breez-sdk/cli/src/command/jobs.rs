@@ -0,0 +1,90 @@
+use breez_sdk_spark::{
+    BreezSdk, CancelJobRequest, ExportPaymentsRequest, GetJobRequest, JobRequest,
+    ListPaymentsRequest, StartJobRequest,
+};
+use clap::Subcommand;
+
+use crate::command::{PaymentExportFormatArg, print_value};
+
+#[derive(Clone, Debug, Subcommand)]
+pub enum JobCommand {
+    /// Start optimizing the wallet leaves in the background
+    StartOptimize,
+    /// Start exporting payments in the background
+    StartExportPayments {
+        /// The export format
+        #[arg(short, long, value_enum, default_value = "csv")]
+        format: PaymentExportFormatArg,
+
+        /// Only include payments created after this timestamp (inclusive)
+        #[arg(long)]
+        from_timestamp: Option<u64>,
+
+        /// Only include payments created before this timestamp (exclusive)
+        #[arg(long)]
+        to_timestamp: Option<u64>,
+    },
+    /// Get the progress and result of a job
+    Get {
+        /// ID of the job
+        job_id: String,
+    },
+    /// List the running and recently finished jobs
+    List,
+    /// Cancel a running job
+    Cancel {
+        /// ID of the job to cancel
+        job_id: String,
+    },
+}
+
+pub async fn handle_command(sdk: &BreezSdk, command: JobCommand) -> Result<bool, anyhow::Error> {
+    match command {
+        JobCommand::StartOptimize => {
+            let response = sdk
+                .start_job(StartJobRequest {
+                    job: JobRequest::OptimizeLeaves,
+                })
+                .await?;
+            print_value(&response)?;
+            Ok(true)
+        }
+        JobCommand::StartExportPayments {
+            format,
+            from_timestamp,
+            to_timestamp,
+        } => {
+            let response = sdk
+                .start_job(StartJobRequest {
+                    job: JobRequest::ExportPayments {
+                        request: ExportPaymentsRequest {
+                            format: format.into(),
+                            filter: Some(ListPaymentsRequest {
+                                from_timestamp,
+                                to_timestamp,
+                                ..Default::default()
+                            }),
+                        },
+                    },
+                })
+                .await?;
+            print_value(&response)?;
+            Ok(true)
+        }
+        JobCommand::Get { job_id } => {
+            let response = sdk.get_job(GetJobRequest { job_id }).await?;
+            print_value(&response)?;
+            Ok(true)
+        }
+        JobCommand::List => {
+            let response = sdk.list_jobs().await?;
+            print_value(&response)?;
+            Ok(true)
+        }
+        JobCommand::Cancel { job_id } => {
+            sdk.cancel_job(CancelJobRequest { job_id }).await?;
+            println!("Job cancellation requested");
+            Ok(true)
+        }
+    }
+}
@@ -1,24 +1,42 @@
 mod advanced;
 mod contacts;
+mod escrow;
 #[cfg(test)]
 mod grammar_tests;
 mod issuer;
+mod jobs;
 mod stable_balance;
 mod webhooks;
 
 use bitcoin::hashes::{Hash, sha256};
 use breez_sdk_spark::{
-    AssetFilter, AuthorizeTransferRequest, BreezSdk, BuyBitcoinRequest,
-    CheckLightningAddressRequest, ClaimDepositRequest, ClaimHtlcPaymentRequest,
-    ClaimTransferRequest, ConversionOptions, ConversionType, CrossChainRoutePair, Fee, FeePolicy,
-    FetchConversionLimitsRequest, GetInfoRequest, GetPaymentRequest, GetTokensMetadataRequest,
-    InputType, LightningAddressDetails, ListPaymentsRequest, ListUnclaimedDepositsRequest,
-    LnurlPayRequest, LnurlWithdrawRequest, MaxFee, OnchainConfirmationSpeed, PaymentDetailsFilter,
+    AssetFilter, AuthorizeTransferRequest, BackupStateRequest, BreezSdk, BumpRefundFeeRequest,
+    BuyBitcoinRequest, CancelHeldPaymentRequest, CancelPaymentRequest, CancelPendingPaymentRequest,
+    CancelTimeLockedPaymentRequest, CheckLightningAddressRequest, ClaimDepositRequest,
+    ClaimDepositsRequest, ClaimHtlcPaymentRequest, ClaimSpecificTransferRequest,
+    ClaimTransferRequest, ClosePaymentStreamRequest, ConversionOptions, ConversionType,
+    ConvertFiatAmountRequest, CreatePaymentLinkRequest, CrossChainRoutePair,
+    DeleteLabeledLightningAddressRequest, DeletePaymentLinkRequest, DepositOutpoint,
+    DeriveApplicationKeyRequest, DisplayCurrency, ExportLedgerRequest, ExportPaymentsRequest, Fee,
+    FeePolicy, FetchConversionLimitsRequest, FetchHistoricalRatesRequest, FiatReceiveMethod,
+    FreezeWalletRequest, GetAccountingReportRequest, GetInfoRequest, GetLedgerRequest,
+    GetPaymentRequest, GetRemainingAllowanceRequest, GetSeedBackupChallengeRequest,
+    GetTokenActivityRequest, GetTokenFiatRatesRequest, GetTokensMetadataRequest,
+    HandleIncomingUriRequest, HideTokenRequest, InputType, LeafSelectionStrategy,
+    LedgerExportFormat, LightningAddressDetails, ListOnchainTransactionsRequest,
+    ListPaymentLinksRequest, ListPaymentsRequest, ListUnclaimedDepositsRequest, LnurlPayRequest,
+    LnurlWithdrawRequest, LockExchangeRateRequest, MaxFee, OnchainConfirmationSpeed,
+    OpenPaymentStreamRequest, PaymentDetailsFilter, PaymentExportFormat, PaymentHandle,
     PaymentRequest, PaymentStatus, PaymentType, PrepareLnurlPayRequest, PrepareSendPaymentRequest,
-    ReceivePaymentMethod, ReceivePaymentRequest, RefundDepositRequest,
-    RegisterLightningAddressRequest, SendPaymentMethod, SendPaymentOptions, SendPaymentRequest,
-    SparkHtlcOptions, SparkHtlcStatus, SyncWalletRequest, TokenIssuer, TokenTransactionType,
-    TransferAuthorization, UpdateUserSettingsRequest,
+    RateResolution, ReceiveFiatPaymentRequest, ReceivePaymentMethod, ReceivePaymentRequest,
+    RefreshTokensMetadataRequest, RefundDepositRequest, RefundHtlcPaymentRequest,
+    RegisterLightningAddressRequest, RequestTestFundsRequest, RestoreStateRequest, SeedBackupWord,
+    SendLeafSelection, SendPaymentMethod, SendPaymentOptions, SendPaymentRequest,
+    SetDeviceNameRequest, SetLogFilterRequest, SettleHeldPaymentRequest,
+    SimulateSendPaymentRequest, SparkHtlcOptions, SparkHtlcStatus, SyncDomain, SyncWalletRequest,
+    TokenIssuer, TokenTransactionType, TransferAuthorization, UnfreezeWalletRequest,
+    UnhideTokenRequest, UpdateLightningAddressNostrKeyRequest, UpdateUserSettingsRequest,
+    VerifySeedBackupRequest,
 };
 use clap::{Parser, ValueEnum};
 use rand::RngCore;
@@ -33,7 +51,9 @@ use std::{
 
 use crate::command::advanced::AdvancedCommand;
 use crate::command::contacts::ContactCommand;
+use crate::command::escrow::EscrowCommand;
 use crate::command::issuer::IssuerCommand;
+use crate::command::jobs::JobCommand;
 use crate::command::stable_balance::StableBalanceCommand;
 use crate::command::webhooks::WebhookCommand;
 
@@ -46,6 +66,78 @@ pub enum ReceivePaymentMethodArg {
     Bolt11,
 }
 
+#[derive(Clone, Copy, Debug, ValueEnum)]
+#[clap(rename_all = "kebab-case")]
+pub enum LeafSelectionStrategyArg {
+    MinimizeFee,
+    MinimizeInputs,
+    Privacy,
+}
+
+impl From<LeafSelectionStrategyArg> for LeafSelectionStrategy {
+    fn from(arg: LeafSelectionStrategyArg) -> Self {
+        match arg {
+            LeafSelectionStrategyArg::MinimizeFee => LeafSelectionStrategy::MinimizeFee,
+            LeafSelectionStrategyArg::MinimizeInputs => LeafSelectionStrategy::MinimizeInputs,
+            LeafSelectionStrategyArg::Privacy => LeafSelectionStrategy::Privacy,
+        }
+    }
+}
+
+#[derive(Clone, Copy, Debug, ValueEnum)]
+#[clap(rename_all = "lower")]
+pub enum PaymentExportFormatArg {
+    Csv,
+    Json,
+    Camt053,
+}
+
+impl From<PaymentExportFormatArg> for PaymentExportFormat {
+    fn from(arg: PaymentExportFormatArg) -> Self {
+        match arg {
+            PaymentExportFormatArg::Csv => PaymentExportFormat::Csv,
+            PaymentExportFormatArg::Json => PaymentExportFormat::Json,
+            PaymentExportFormatArg::Camt053 => PaymentExportFormat::Camt053,
+        }
+    }
+}
+
+#[derive(Clone, Copy, Debug, ValueEnum)]
+#[clap(rename_all = "lower")]
+pub enum RateResolutionArg {
+    Hour,
+    Day,
+}
+
+impl From<RateResolutionArg> for RateResolution {
+    fn from(arg: RateResolutionArg) -> Self {
+        match arg {
+            RateResolutionArg::Hour => RateResolution::Hour,
+            RateResolutionArg::Day => RateResolution::Day,
+        }
+    }
+}
+
+#[derive(Clone, Copy, Debug, ValueEnum)]
+#[clap(rename_all = "kebab-case")]
+pub enum SyncDomainArg {
+    Payments,
+    Tokens,
+    Deposits,
+    LightningAddress,
+}
+
+impl From<SyncDomainArg> for SyncDomain {
+    fn from(arg: SyncDomainArg) -> Self {
+        match arg {
+            SyncDomainArg::Payments => SyncDomain::Payments,
+            SyncDomainArg::Tokens => SyncDomain::Tokens,
+            SyncDomainArg::Deposits => SyncDomain::Deposits,
+            SyncDomainArg::LightningAddress => SyncDomain::LightningAddress,
+        }
+    }
+}
+
 #[derive(Clone, Parser)]
 pub enum Command {
     /// Exit the interactive shell (interactive mode only)
@@ -57,6 +149,63 @@ pub enum Command {
         /// Force sync
         #[arg(short, long)]
         ensure_synced: Option<bool>,
+
+        /// Include the balances of hidden tokens
+        #[arg(long)]
+        include_hidden: bool,
+    },
+
+    /// List the leaves held by the wallet
+    ListLeaves,
+
+    /// Pick the positions of the mnemonic words to check a backup with
+    GetSeedBackupChallenge {
+        /// The number of words to ask for
+        #[arg(short, long)]
+        word_count: Option<u32>,
+    },
+
+    /// Check mnemonic words of a backup against the wallet's mnemonic
+    VerifySeedBackup {
+        /// The words to check, as position:word with 1-based positions
+        #[arg(required = true)]
+        words: Vec<String>,
+    },
+
+    /// Sign and verify a random message to check the signer
+    RunSignerSelfTest,
+
+    /// Derive a key from the wallet seed for application use
+    DeriveApplicationKey {
+        /// The purpose of the key, such as "nostr"
+        label: String,
+    },
+
+    /// Upload an encrypted backup of the contacts and user settings
+    BackupState {
+        /// The URL the backup is posted to
+        url: String,
+    },
+
+    /// Restore the contacts and user settings from an encrypted backup
+    RestoreState {
+        /// The URL the backup is fetched from
+        url: String,
+    },
+
+    /// Delete the wallet data locally and from the servers, then exit
+    DeleteWalletData,
+
+    /// Freeze the wallet, blocking all sends until it is unfrozen
+    FreezeWallet {
+        /// The password needed to unfreeze the wallet
+        password: String,
+    },
+
+    /// Unfreeze a frozen wallet
+    UnfreezeWallet {
+        /// The password the wallet was frozen with
+        password: String,
     },
 
     /// Get the payment with the given ID
@@ -64,7 +213,24 @@ pub enum Command {
         /// The ID of the payment to retrieve
         payment_id: String,
     },
-    Sync,
+    /// Sync the wallet, or only the given domains
+    Sync {
+        #[arg(num_args = 0.., value_enum)]
+        domains: Vec<SyncDomainArg>,
+    },
+    /// Show when each sync domain was last synced successfully
+    GetSyncStatus,
+    /// Pause the periodic background sync
+    PauseBackgroundSync,
+    /// Resume the periodic background sync
+    ResumeBackgroundSync,
+    /// List the devices running this wallet
+    ListDevices,
+    /// Name this device, shown on the other devices
+    SetDeviceName {
+        /// The device name
+        name: String,
+    },
     /// Lists payments
     ListPayments {
         /// Filter by payment type
@@ -112,6 +278,77 @@ pub enum Command {
         sort_ascending: Option<bool>,
     },
 
+    /// Shows the ledger of balance changes with running balances
+    GetLedger {
+        /// The token to show the ledger for. Bitcoin if not set
+        #[arg(short, long)]
+        token_identifier: Option<String>,
+
+        /// Only include entries created after this timestamp (inclusive)
+        #[arg(long)]
+        from_timestamp: Option<u64>,
+
+        /// Only include entries created before this timestamp (exclusive)
+        #[arg(long)]
+        to_timestamp: Option<u64>,
+
+        /// Print the ledger as CSV
+        #[arg(long)]
+        csv: bool,
+    },
+
+    /// Lists the transfers, mints, burns and freezes of a token, newest first
+    GetTokenActivity {
+        /// The token to list the activity of
+        token_identifier: String,
+
+        /// Number of records to show
+        #[arg(short, long, default_value = "10")]
+        limit: Option<u32>,
+
+        /// Number of records to skip
+        #[arg(short, long, default_value = "0")]
+        offset: Option<u32>,
+    },
+
+    /// Hides a token from the balances returned by get-info
+    HideToken {
+        /// The token to hide
+        token_identifier: String,
+    },
+
+    /// Shows a hidden token in the balances returned by get-info again
+    UnhideToken {
+        /// The token to show
+        token_identifier: String,
+    },
+
+    /// Exports payments for accounting
+    ExportPayments {
+        /// The export format
+        #[arg(short, long, value_enum, default_value = "csv")]
+        format: PaymentExportFormatArg,
+
+        /// Only include payments created after this timestamp (inclusive)
+        #[arg(long)]
+        from_timestamp: Option<u64>,
+
+        /// Only include payments created before this timestamp (exclusive)
+        #[arg(long)]
+        to_timestamp: Option<u64>,
+    },
+
+    /// Shows the totals in and out and the fees paid per asset
+    GetAccountingReport {
+        /// Only include payments created after this timestamp (inclusive)
+        #[arg(long)]
+        from_timestamp: Option<u64>,
+
+        /// Only include payments created before this timestamp (exclusive)
+        #[arg(long)]
+        to_timestamp: Option<u64>,
+    },
+
     /// Receive
     Receive {
         #[arg(short = 'm', long = "method", value_enum)]
@@ -188,8 +425,78 @@ pub enum Command {
         /// If set, fees will be deducted from the specified amount instead of added on top.
         #[arg(long = "fees-included", action = clap::ArgAction::SetTrue)]
         fees_included: bool,
+
+        /// If set, the payment is only simulated and nothing is sent.
+        #[arg(long, action = clap::ArgAction::SetTrue)]
+        simulate: bool,
+
+        /// If set, the payment is sent in the background and its progress is reported by events.
+        #[arg(long, conflicts_with = "simulate", action = clap::ArgAction::SetTrue)]
+        background: bool,
+
+        /// If set, the payment can only be claimed by the receiver after this unix timestamp in
+        /// seconds. Only supported for payments to a Spark address.
+        #[arg(long)]
+        deliver_after: Option<u64>,
+
+        /// Spend exactly this leaf instead of selecting the leaves. Can be repeated, and the leaf
+        /// values must add up to the amount. Only supported for payments to a Spark or Bitcoin
+        /// address.
+        #[arg(long = "leaf-id", conflicts_with = "leaf_strategy")]
+        leaf_ids: Vec<String>,
+
+        /// The strategy used to select the leaves to spend. Only supported for payments to a
+        /// Spark or Bitcoin address.
+        #[arg(long, value_enum)]
+        leaf_strategy: Option<LeafSelectionStrategyArg>,
+    },
+
+    /// Cancel a payment sent in the background, if its transfer was not initiated yet
+    CancelPendingPayment {
+        /// The handle id returned when the payment was started
+        handle_id: String,
+    },
+
+    /// Move an outgoing payment stuck in pending to a final status
+    CancelPayment {
+        /// The ID of the pending payment
+        payment_id: String,
+    },
+
+    /// List the payments sent with a delivery time
+    ListTimeLockedPayments,
+
+    /// Cancel a payment sent with a delivery time, before that time
+    CancelTimeLockedPayment {
+        /// The ID of the time-locked payment
+        payment_id: String,
+    },
+
+    /// Stream payments to a Spark address or Spark invoice at a fixed rate
+    OpenPaymentStream {
+        /// The Spark address or Spark invoice to pay
+        destination: String,
+
+        /// The amount in satoshis to stream per second
+        rate_sat_per_sec: u64,
+
+        /// The total amount in satoshis after which the stream stops
+        max_amount_sat: u64,
+
+        /// The interval in seconds between transfers
+        #[arg(long)]
+        interval_secs: Option<u32>,
     },
 
+    /// Close an open payment stream
+    ClosePaymentStream {
+        /// The ID of the payment stream
+        stream_id: String,
+    },
+
+    /// List the payment streams
+    ListPaymentStreams,
+
     /// Pay using LNURL
     LnurlPay {
         /// LN Address or LNURL-pay endpoint
@@ -248,6 +555,32 @@ pub enum Command {
         preimage: String,
     },
 
+    /// Settle the payment of a HODL invoice
+    SettleHeldPayment {
+        /// The payment hash of the HODL invoice
+        payment_hash: String,
+        /// The preimage of the payment hash
+        preimage: String,
+    },
+
+    /// Refuse to settle the payment of a HODL invoice
+    CancelHeldPayment {
+        /// The payment hash of the HODL invoice
+        payment_hash: String,
+    },
+
+    /// Reclaim the funds of an expired outgoing HTLC payment
+    RefundHtlcPayment {
+        /// The ID of the HTLC payment
+        payment_id: String,
+    },
+
+    /// Claim a single incoming transfer without a full sync
+    ClaimSpecificTransfer {
+        /// The id of the incoming transfer
+        transfer_id: String,
+    },
+
     ClaimDeposit {
         /// The txid of the deposit
         txid: String,
@@ -267,8 +600,26 @@ pub enum Command {
         #[arg(long)]
         recommended_fee_leeway: Option<u64>,
     },
+    /// Claim several deposits, in the given order
+    ClaimDeposits {
+        /// The deposits to claim, as txid:vout
+        #[arg(required = true)]
+        deposits: Vec<String>,
+
+        /// The max total fee to claim all the deposits
+        #[arg(long)]
+        max_total_fee_sat: Option<u64>,
+    },
     Parse {
         input: String,
+        /// Parse without network requests, failing for inputs that need a
+        /// lookup unless they were recently resolved
+        #[arg(long)]
+        offline: bool,
+    },
+    /// Parse a URI the app was opened with and show the call to continue with
+    HandleIncomingUri {
+        uri: String,
     },
     RefundDeposit {
         /// The txid of the deposit
@@ -288,7 +639,31 @@ pub enum Command {
         #[arg(long)]
         sat_per_vbyte: Option<u64>,
     },
+    /// Replace an unconfirmed deposit refund with one paying a higher fee
+    BumpRefundFee {
+        /// The txid of the refund transaction to replace
+        tx_id: String,
+
+        /// The new fee of the refund
+        #[arg(long)]
+        fee_sat: Option<u64>,
+
+        /// The new fee per vbyte of the refund
+        #[arg(long)]
+        sat_per_vbyte: Option<u64>,
+    },
     ListUnclaimedDeposits,
+    /// List the deposit addresses of the wallet with their usage
+    GetDepositAddressHistory,
+    /// List the monitored withdrawals and deposit refunds with their on-chain status
+    ListOnchainTransactions,
+    /// Show how much can still be sent under each configured spending cap
+    GetRemainingAllowance,
+    /// Fund the wallet from the regtest faucet
+    RequestTestFunds {
+        /// The amount to request, in sats
+        amount_sats: u64,
+    },
     /// Buy Bitcoin using an external provider
     BuyBitcoin {
         /// Provider to use: "moonpay" (default) or "cashapp"
@@ -314,7 +689,30 @@ pub enum Command {
 
         /// Description in the lnurl response and the invoice.
         description: Option<String>,
+
+        /// Registers an additional address under this label instead of the
+        /// primary address
+        #[arg(long)]
+        label: Option<String>,
+
+        /// Base64 encoded PNG image shown on the pay page
+        #[arg(long)]
+        image: Option<String>,
+
+        /// Longer form description shown on the pay page
+        #[arg(long)]
+        long_description: Option<String>,
+
+        /// The minimum amount the address accepts
+        #[arg(long)]
+        min_sendable_sats: Option<u64>,
+
+        /// The maximum amount the address accepts
+        #[arg(long)]
+        max_sendable_sats: Option<u64>,
     },
+    /// Lists the primary and labeled lightning addresses of this wallet
+    ListLightningAddresses,
     /// Run by the current owner to authorize transferring their registered
     /// lightning address username to `transferee_pubkey`. Prints the
     /// authorization (username, pubkey, signature) to hand to the new owner,
@@ -343,17 +741,118 @@ pub enum Command {
         #[arg(long)]
         from_signature: String,
     },
-    DeleteLightningAddress,
+    /// Delete the primary lightning address, or a labeled one
+    DeleteLightningAddress {
+        /// Delete the address registered under this label instead
+        #[arg(long)]
+        label: Option<String>,
+    },
+    /// Attach a nostr pubkey to a lightning address for NIP-05 verification
+    /// and zaps, or detach it when no pubkey is given
+    UpdateLightningAddressNostrKey {
+        /// Hex encoded x-only nostr pubkey
+        nostr_pubkey: Option<String>,
+
+        /// The address to update, defaults to the primary address
+        #[arg(long)]
+        username: Option<String>,
+    },
+    /// Create a link requesting a fixed amount, to share with the payer
+    CreatePaymentLink {
+        /// The amount requested, in satoshis
+        amount_sats: u64,
+
+        /// Shown to the payer as the invoice description
+        memo: String,
+    },
+    /// List the created payment links, newest first
+    ListPaymentLinks {
+        #[arg(long)]
+        offset: Option<u32>,
+
+        #[arg(long)]
+        limit: Option<u32>,
+    },
+    DeletePaymentLink {
+        /// The id of the payment link
+        id: String,
+    },
     /// List fiat currencies
     ListFiatCurrencies,
     /// List available fiat rates
     ListFiatRates,
+    /// Fetch the rates of a fiat currency over a time range
+    FetchHistoricalRates {
+        /// The fiat currency code, e.g. USD
+        currency: String,
+
+        /// Start of the range, as a unix timestamp in seconds
+        from_timestamp: u64,
+
+        /// End of the range, as a unix timestamp in seconds
+        to_timestamp: u64,
+
+        /// The interval between the returned rates
+        #[arg(short, long, value_enum, default_value = "day")]
+        resolution: RateResolutionArg,
+    },
+    /// Lock the current exchange rate of a fiat currency for a checkout
+    LockExchangeRate {
+        /// The fiat currency code, e.g. EUR
+        currency: String,
+
+        /// How long the rate stays locked, in seconds
+        #[arg(short, long)]
+        ttl_secs: Option<u32>,
+    },
+    /// Convert a fiat amount to sats at a locked exchange rate
+    ConvertFiatAmount {
+        /// The id of the exchange rate lock
+        locked_rate_id: String,
+
+        /// The amount in fiat units, e.g. 10.0
+        fiat_amount: f64,
+    },
+    /// Receive a payment with its amount set in fiat
+    ReceiveFiat {
+        /// The fiat currency code, e.g. EUR
+        currency: String,
+
+        /// The amount in fiat units, e.g. 10.0
+        fiat_amount: f64,
+
+        /// Create a spark invoice instead of a bolt11 invoice
+        #[arg(long)]
+        spark: bool,
+
+        /// Optional description for the invoice
+        #[clap(short = 'd', long = "description")]
+        description: Option<String>,
+
+        /// Optional expiry time for the invoice in seconds from now
+        #[arg(short = 'e', long)]
+        expiry_secs: Option<u32>,
+
+        /// Convert at this exchange rate lock instead of the current rate
+        #[arg(short = 'l', long)]
+        locked_rate_id: Option<String>,
+    },
     /// Get the recommended BTC fees based on the configured chain service
     RecommendedFees,
     GetTokensMetadata {
         /// The token identifiers to get metadata for
         token_identifiers: Vec<String>,
     },
+    /// Refresh the cached metadata of tokens from the network
+    RefreshTokensMetadata {
+        /// The token identifiers to refresh. Refreshes the held tokens when empty
+        token_identifiers: Vec<String>,
+    },
+    /// Get the fiat rates of tokens, per whole token
+    GetTokenFiatRates {
+        /// The token identifiers to get the rates of
+        token_identifiers: Vec<String>,
+    },
     FetchConversionLimits {
         /// Whether we are converting from or to Bitcoin
         #[clap(short = 'f', long, action = clap::ArgAction::SetTrue)]
@@ -367,11 +866,36 @@ pub enum Command {
         /// Whether spark private mode is enabled.
         #[clap(short = 'p', long = "private")]
         spark_private_mode_enabled: Option<bool>,
+
+        /// The fiat currency payment values are recorded in, e.g. EUR, or `none` to unset
+        #[clap(short = 'c', long = "display-currency")]
+        display_currency: Option<String>,
     },
 
     /// Get the status of the Spark network services
     GetSparkStatus,
 
+    /// Check the health of the SDK's external dependencies
+    CheckHealth,
+
+    /// Get the status of the Spark network for each payment rail
+    GetServiceStatus,
+
+    /// Change the log filter of the running session
+    SetLogFilter {
+        /// The filter, e.g. breez_sdk_spark=debug,spark=info
+        filter: String,
+    },
+
+    /// Print a support bundle with the redacted config, leaf stats and recent errors
+    DumpDiagnostics,
+
+    /// List the registered plugins and their capabilities
+    ListPlugins,
+
+    /// Show the minimum amount, expected fee and timing of a receive per rail
+    GetReceiveOnboardingInfo,
+
     /// Expert-only commands that build raw transactions for you to broadcast
     /// yourself. Misuse can strand or lose funds.
     #[command(subcommand)]
@@ -385,10 +909,18 @@ pub enum Command {
     #[command(subcommand)]
     Contacts(ContactCommand),
 
+    /// Atomic swap escrow related commands
+    #[command(subcommand)]
+    Escrow(EscrowCommand),
+
     /// Webhook related commands
     #[command(subcommand)]
     Webhooks(WebhookCommand),
 
+    /// Background job related commands
+    #[command(subcommand)]
+    Jobs(JobCommand),
+
     /// Stable balance related commands
     #[command(subcommand)]
     StableBalance(StableBalanceCommand),
@@ -418,9 +950,86 @@ pub(crate) async fn execute_command(
             sdk.disconnect().await?;
             Ok(false)
         }
-        Command::GetInfo { ensure_synced } => {
-            let value = sdk.get_info(GetInfoRequest { ensure_synced }).await?;
+        Command::GetInfo {
+            ensure_synced,
+            include_hidden,
+        } => {
+            let value = sdk
+                .get_info(GetInfoRequest {
+                    ensure_synced,
+                    include_hidden: Some(include_hidden),
+                })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::ListLeaves => {
+            let value = sdk.list_leaves().await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::GetSeedBackupChallenge { word_count } => {
+            let value = sdk
+                .get_seed_backup_challenge(GetSeedBackupChallengeRequest { word_count })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::VerifySeedBackup { words } => {
+            let words = words
+                .iter()
+                .map(|word| {
+                    let (position, word) = word
+                        .split_once(':')
+                        .ok_or(anyhow::anyhow!("Word must be formatted as position:word"))?;
+                    Ok(SeedBackupWord {
+                        position: position.parse()?,
+                        word: word.to_string(),
+                    })
+                })
+                .collect::<Result<Vec<_>, anyhow::Error>>()?;
+            let value = sdk
+                .verify_seed_backup(VerifySeedBackupRequest { words })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::RunSignerSelfTest => {
+            let value = sdk.run_signer_self_test().await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::DeriveApplicationKey { label } => {
+            let value = sdk
+                .derive_application_key(DeriveApplicationKeyRequest { label })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::BackupState { url } => {
+            let value = sdk.backup_state(BackupStateRequest { url }).await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::RestoreState { url } => {
+            let value = sdk.restore_state(RestoreStateRequest { url }).await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::DeleteWalletData => {
+            let value = sdk.delete_wallet_data().await?;
             print_value(&value)?;
+            Ok(false)
+        }
+        Command::FreezeWallet { password } => {
+            sdk.freeze_wallet(FreezeWalletRequest { password }).await?;
+            println!("Wallet frozen");
+            Ok(true)
+        }
+        Command::UnfreezeWallet { password } => {
+            sdk.unfreeze_wallet(UnfreezeWalletRequest { password })
+                .await?;
+            println!("Wallet unfrozen");
             Ok(true)
         }
         Command::GetPayment { payment_id } => {
@@ -468,26 +1077,139 @@ pub(crate) async fn execute_command(
                 Some(payment_details_filter)
             };
             let value = sdk
-                .list_payments(ListPaymentsRequest {
-                    limit,
-                    offset,
-                    type_filter,
-                    status_filter,
-                    asset_filter,
-                    payment_details_filter,
+                .list_payments(ListPaymentsRequest {
+                    limit,
+                    offset,
+                    type_filter,
+                    status_filter,
+                    asset_filter,
+                    payment_details_filter,
+                    from_timestamp,
+                    to_timestamp,
+                    sort_ascending,
+                })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::GetLedger {
+            token_identifier,
+            from_timestamp,
+            to_timestamp,
+            csv,
+        } => {
+            if csv {
+                let value = sdk
+                    .export_ledger(ExportLedgerRequest {
+                        format: LedgerExportFormat::Csv,
+                        token_identifier,
+                        from_timestamp,
+                        to_timestamp,
+                    })
+                    .await?;
+                print!("{}", value.data);
+            } else {
+                let value = sdk
+                    .get_ledger(GetLedgerRequest {
+                        token_identifier,
+                        from_timestamp,
+                        to_timestamp,
+                    })
+                    .await?;
+                print_value(&value)?;
+            }
+            Ok(true)
+        }
+        Command::GetTokenActivity {
+            token_identifier,
+            limit,
+            offset,
+        } => {
+            let value = sdk
+                .get_token_activity(GetTokenActivityRequest {
+                    token_identifier,
+                    offset,
+                    limit,
+                })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::HideToken { token_identifier } => {
+            sdk.hide_token(HideTokenRequest { token_identifier })
+                .await?;
+            println!("Token hidden");
+            Ok(true)
+        }
+        Command::UnhideToken { token_identifier } => {
+            sdk.unhide_token(UnhideTokenRequest { token_identifier })
+                .await?;
+            println!("Token unhidden");
+            Ok(true)
+        }
+        Command::ExportPayments {
+            format,
+            from_timestamp,
+            to_timestamp,
+        } => {
+            let value = sdk
+                .export_payments(ExportPaymentsRequest {
+                    format: format.into(),
+                    filter: Some(ListPaymentsRequest {
+                        from_timestamp,
+                        to_timestamp,
+                        ..Default::default()
+                    }),
+                })
+                .await?;
+            print!("{}", value.data);
+            Ok(true)
+        }
+        Command::GetAccountingReport {
+            from_timestamp,
+            to_timestamp,
+        } => {
+            let value = sdk
+                .get_accounting_report(GetAccountingReportRequest {
                     from_timestamp,
                     to_timestamp,
-                    sort_ascending,
                 })
                 .await?;
             print_value(&value)?;
             Ok(true)
         }
-        Command::Sync => {
-            let value = sdk.sync_wallet(SyncWalletRequest {}).await?;
+        Command::Sync { domains } => {
+            let domains =
+                (!domains.is_empty()).then(|| domains.into_iter().map(Into::into).collect());
+            let value = sdk.sync_wallet(SyncWalletRequest { domains }).await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::GetSyncStatus => {
+            let value = sdk.get_sync_status().await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::PauseBackgroundSync => {
+            sdk.pause_background_sync();
+            println!("Background sync paused");
+            Ok(true)
+        }
+        Command::ResumeBackgroundSync => {
+            sdk.resume_background_sync().await;
+            println!("Background sync resumed");
+            Ok(true)
+        }
+        Command::ListDevices => {
+            let value = sdk.list_devices().await?;
             print_value(&value)?;
             Ok(true)
         }
+        Command::SetDeviceName { name } => {
+            sdk.set_device_name(SetDeviceNameRequest { name }).await?;
+            println!("Device name set");
+            Ok(true)
+        }
         Command::ListUnclaimedDeposits => {
             let value = sdk
                 .list_unclaimed_deposits(ListUnclaimedDepositsRequest {})
@@ -495,6 +1217,32 @@ pub(crate) async fn execute_command(
             print_value(&value)?;
             Ok(true)
         }
+        Command::GetDepositAddressHistory => {
+            let value = sdk.get_deposit_address_history().await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::ListOnchainTransactions => {
+            let value = sdk
+                .list_onchain_transactions(ListOnchainTransactionsRequest {})
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::GetRemainingAllowance => {
+            let value = sdk
+                .get_remaining_allowance(GetRemainingAllowanceRequest {})
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::RequestTestFunds { amount_sats } => {
+            let value = sdk
+                .request_test_funds(RequestTestFundsRequest { amount_sats })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
         Command::ClaimDeposit {
             txid,
             vout,
@@ -528,13 +1276,50 @@ pub(crate) async fn execute_command(
                     txid,
                     vout,
                     max_fee,
+                    idempotency_key: None,
+                })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::ClaimDeposits {
+            deposits,
+            max_total_fee_sat,
+        } => {
+            let deposits = deposits
+                .iter()
+                .map(|deposit| {
+                    let (txid, vout) = deposit
+                        .rsplit_once(':')
+                        .ok_or(anyhow::anyhow!("Deposit must be formatted as txid:vout"))?;
+                    Ok(DepositOutpoint {
+                        txid: txid.to_string(),
+                        vout: vout.parse()?,
+                    })
+                })
+                .collect::<Result<Vec<_>, anyhow::Error>>()?;
+            let value = sdk
+                .claim_deposits(ClaimDepositsRequest {
+                    deposits,
+                    max_total_fee_sat,
                 })
                 .await?;
             print_value(&value)?;
             Ok(true)
         }
-        Command::Parse { input } => {
-            let value = sdk.parse(&input).await?;
+        Command::Parse { input, offline } => {
+            let value = if offline {
+                sdk.parse_offline(&input)?
+            } else {
+                sdk.parse(&input).await?
+            };
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::HandleIncomingUri { uri } => {
+            let value = sdk
+                .handle_incoming_uri(HandleIncomingUriRequest { uri })
+                .await?;
             print_value(&value)?;
             Ok(true)
         }
@@ -545,20 +1330,7 @@ pub(crate) async fn execute_command(
             fee_sat,
             sat_per_vbyte,
         } => {
-            let fee = match (fee_sat, sat_per_vbyte) {
-                (Some(_), Some(_)) => {
-                    return Err(anyhow::anyhow!(
-                        "Cannot specify both fee_sat and sat_per_vbyte"
-                    ));
-                }
-                (Some(fee_sat), None) => Fee::Fixed { amount: fee_sat },
-                (None, Some(sat_per_vbyte)) => Fee::Rate { sat_per_vbyte },
-                (None, None) => {
-                    return Err(anyhow::anyhow!(
-                        "Must specify either fee_sat or sat_per_vbyte"
-                    ));
-                }
-            };
+            let fee = parse_fee(fee_sat, sat_per_vbyte)?;
             let value = sdk
                 .refund_deposit(RefundDepositRequest {
                     txid,
@@ -570,6 +1342,18 @@ pub(crate) async fn execute_command(
             print_value(&value)?;
             Ok(true)
         }
+        Command::BumpRefundFee {
+            tx_id,
+            fee_sat,
+            sat_per_vbyte,
+        } => {
+            let fee = parse_fee(fee_sat, sat_per_vbyte)?;
+            let value = sdk
+                .bump_refund_fee(BumpRefundFeeRequest { tx_id, fee })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
         Command::BuyBitcoin {
             provider,
             amount_sat,
@@ -618,6 +1402,7 @@ pub(crate) async fn execute_command(
                         .transpose()?,
                     description,
                     sender_public_key,
+                    accept_partial_payments: None,
                 },
                 ReceivePaymentMethodArg::Bitcoin => ReceivePaymentMethod::BitcoinAddress {
                     new_address: Some(new_address),
@@ -671,6 +1456,11 @@ pub(crate) async fn execute_command(
             convert_max_slippage_bps: max_slippage_bps,
             cross_chain_max_slippage_bps,
             fees_included,
+            simulate,
+            background,
+            deliver_after,
+            leaf_ids,
+            leaf_strategy,
         } => {
             let conversion_options = match (convert_from_bitcoin, convert_from_token_identifier) {
                 (Some(true), _) => Some(ConversionOptions {
@@ -692,6 +1482,13 @@ pub(crate) async fn execute_command(
             } else {
                 None
             };
+            let leaf_selection = match leaf_strategy {
+                Some(strategy) => Some(SendLeafSelection::Strategy {
+                    strategy: strategy.into(),
+                }),
+                None if !leaf_ids.is_empty() => Some(SendLeafSelection::Specific { leaf_ids }),
+                None => None,
+            };
 
             // Check if the input is a cross-chain address — if so, we need
             // route selection before we can prepare.
@@ -718,6 +1515,7 @@ pub(crate) async fn execute_command(
                     token_identifier: token_identifier.clone(),
                     conversion_options,
                     fee_policy,
+                    fiat_amount: None,
                 })
                 .await;
 
@@ -728,6 +1526,19 @@ pub(crate) async fn execute_command(
                 ));
             };
 
+            if let Some(duplicate) = &prepare_response.duplicate_payment {
+                println!(
+                    "This invoice was already paid by payment {} ({:?})",
+                    duplicate.payment_id, duplicate.status
+                );
+                let line = rl
+                    .readline_with_initial("Do you want to pay it again (y/n): ", ("n", ""))?
+                    .to_lowercase();
+                if line != "y" {
+                    return Err(anyhow::anyhow!("Payment cancelled"));
+                }
+            }
+
             if let Some(conversion_estimate) = &prepare_response.conversion_estimate {
                 let (in_units, out_units) =
                     if conversion_estimate.options.conversion_type == ConversionType::FromBitcoin {
@@ -790,16 +1601,102 @@ pub(crate) async fn execute_command(
             let payment_options =
                 read_payment_options(prepare_response.payment_method.clone(), rl)?;
 
+            if simulate {
+                let simulation = sdk
+                    .simulate_send_payment(SimulateSendPaymentRequest {
+                        prepare_response,
+                        options: payment_options,
+                        max_fee: None,
+                    })
+                    .await?;
+                print_value(&simulation)?;
+                return Ok(true);
+            }
+
+            if background {
+                let handle = sdk
+                    .send_payment_async(SendPaymentRequest {
+                        prepare_response,
+                        options: payment_options,
+                        idempotency_key,
+                        max_fee: None,
+                        deliver_after,
+                        leaf_selection,
+                    })
+                    .await?;
+                print_value(&handle)?;
+                return Ok(true);
+            }
+
             let send_payment_response = Box::pin(sdk.send_payment(SendPaymentRequest {
                 prepare_response,
                 options: payment_options,
                 idempotency_key,
+                max_fee: None,
+                deliver_after,
+                leaf_selection,
             }))
             .await?;
 
             print_value(&send_payment_response)?;
             Ok(true)
         }
+        Command::CancelPendingPayment { handle_id } => {
+            sdk.cancel_pending_payment(CancelPendingPaymentRequest {
+                handle: PaymentHandle { id: handle_id },
+            })
+            .await?;
+            println!("Payment cancelled");
+            Ok(true)
+        }
+        Command::CancelPayment { payment_id } => {
+            let value = sdk
+                .cancel_payment(CancelPaymentRequest { payment_id })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::ListTimeLockedPayments => {
+            let value = sdk.list_time_locked_payments().await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::CancelTimeLockedPayment { payment_id } => {
+            let value = sdk
+                .cancel_time_locked_payment(CancelTimeLockedPaymentRequest { payment_id })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::OpenPaymentStream {
+            destination,
+            rate_sat_per_sec,
+            max_amount_sat,
+            interval_secs,
+        } => {
+            let value = sdk
+                .open_payment_stream(OpenPaymentStreamRequest {
+                    destination,
+                    rate_sats_per_sec: rate_sat_per_sec,
+                    max_amount_sats: max_amount_sat,
+                    interval_secs,
+                })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::ClosePaymentStream { stream_id } => {
+            let value = sdk
+                .close_payment_stream(ClosePaymentStreamRequest { stream_id })
+                .await?;
+            print_value(&value)?;
+            Ok(true)
+        }
+        Command::ListPaymentStreams => {
+            let value = sdk.list_payment_streams().await?;
+            print_value(&value)?;
+            Ok(true)
+        }
         Command::LnurlPay {
             lnurl,
             comment,
@@ -848,6 +1745,7 @@ pub(crate) async fn execute_command(
                             token_identifier,
                             conversion_options,
                             fee_policy,
+                            quote_token_identifier: None,
                         })
                         .await?;
 
@@ -886,6 +1784,7 @@ pub(crate) async fn execute_command(
                     let pay_res = Box::pin(sdk.lnurl_pay(LnurlPayRequest {
                         prepare_response,
                         idempotency_key,
+                        max_fee: None,
                     }))
                     .await?;
                     Ok(pay_res)
@@ -915,6 +1814,7 @@ pub(crate) async fn execute_command(
                             amount_sats,
                             withdraw_request,
                             completion_timeout_secs,
+                            idempotency_key: None,
                         })
                         .await?;
                     Ok(withdraw_res)
@@ -954,6 +1854,42 @@ pub(crate) async fn execute_command(
             print_value(&res.payment)?;
             Ok(true)
         }
+        Command::SettleHeldPayment {
+            payment_hash,
+            preimage,
+        } => {
+            let res = sdk
+                .settle_held_payment(SettleHeldPaymentRequest {
+                    payment_hash,
+                    preimage,
+                })
+                .await?;
+            print_value(&res.payment)?;
+            Ok(true)
+        }
+        Command::CancelHeldPayment { payment_hash } => {
+            sdk.cancel_held_payment(CancelHeldPaymentRequest { payment_hash })
+                .await?;
+            println!("Held payment cancelled");
+            Ok(true)
+        }
+        Command::RefundHtlcPayment { payment_id } => {
+            let res = sdk
+                .refund_htlc_payment(RefundHtlcPaymentRequest { payment_id })
+                .await?;
+            print_value(&res.payment)?;
+            Ok(true)
+        }
+        Command::ClaimSpecificTransfer { transfer_id } => {
+            let res = sdk
+                .claim_specific_transfer(ClaimSpecificTransferRequest { transfer_id })
+                .await?;
+            match res.payment {
+                Some(payment) => print_value(&payment)?,
+                None => println!("Transfer is not claimable yet"),
+            }
+            Ok(true)
+        }
         Command::CheckLightningAddressAvailable { username } => {
             let res = sdk
                 .check_lightning_address_available(CheckLightningAddressRequest { username })
@@ -969,16 +1905,31 @@ pub(crate) async fn execute_command(
         Command::RegisterLightningAddress {
             username,
             description,
+            label,
+            image,
+            long_description,
+            min_sendable_sats,
+            max_sendable_sats,
         } => {
             let res = sdk
                 .register_lightning_address(RegisterLightningAddressRequest {
                     username,
                     description,
+                    label,
+                    image,
+                    long_description,
+                    min_sendable_sats,
+                    max_sendable_sats,
                 })
                 .await?;
             print_value(&res)?;
             Ok(true)
         }
+        Command::ListLightningAddresses => {
+            let res = sdk.list_lightning_addresses().await?;
+            print_value(&res)?;
+            Ok(true)
+        }
         Command::AuthorizeLightningAddressTransfer { transferee_pubkey } => {
             let res = sdk
                 .authorize_lightning_address_transfer(AuthorizeTransferRequest {
@@ -1007,8 +1958,46 @@ pub(crate) async fn execute_command(
             print_value(&res)?;
             Ok(true)
         }
-        Command::DeleteLightningAddress => {
-            sdk.delete_lightning_address().await?;
+        Command::DeleteLightningAddress { label } => {
+            match label {
+                Some(label) => {
+                    sdk.delete_labeled_lightning_address(DeleteLabeledLightningAddressRequest {
+                        label,
+                    })
+                    .await?;
+                }
+                None => sdk.delete_lightning_address().await?,
+            }
+            Ok(true)
+        }
+        Command::UpdateLightningAddressNostrKey {
+            nostr_pubkey,
+            username,
+        } => {
+            sdk.update_lightning_address_nostr_key(UpdateLightningAddressNostrKeyRequest {
+                nostr_pubkey,
+                username,
+            })
+            .await?;
+            Ok(true)
+        }
+        Command::CreatePaymentLink { amount_sats, memo } => {
+            let res = sdk
+                .create_payment_link(CreatePaymentLinkRequest { amount_sats, memo })
+                .await?;
+            print_value(&res)?;
+            Ok(true)
+        }
+        Command::ListPaymentLinks { offset, limit } => {
+            let res = sdk
+                .list_payment_links(ListPaymentLinksRequest { offset, limit })
+                .await?;
+            print_value(&res)?;
+            Ok(true)
+        }
+        Command::DeletePaymentLink { id } => {
+            sdk.delete_payment_link(DeletePaymentLinkRequest { id })
+                .await?;
             Ok(true)
         }
         Command::ListFiatCurrencies => {
@@ -1021,6 +2010,82 @@ pub(crate) async fn execute_command(
             print_value(&res)?;
             Ok(true)
         }
+        Command::FetchHistoricalRates {
+            currency,
+            from_timestamp,
+            to_timestamp,
+            resolution,
+        } => {
+            let res = sdk
+                .fetch_historical_rates(FetchHistoricalRatesRequest {
+                    currency,
+                    from_timestamp,
+                    to_timestamp,
+                    resolution: resolution.into(),
+                })
+                .await?;
+            print_value(&res)?;
+            Ok(true)
+        }
+        Command::LockExchangeRate { currency, ttl_secs } => {
+            let res = sdk
+                .lock_exchange_rate(LockExchangeRateRequest { currency, ttl_secs })
+                .await?;
+            print_value(&res)?;
+            Ok(true)
+        }
+        Command::ConvertFiatAmount {
+            locked_rate_id,
+            fiat_amount,
+        } => {
+            let res = sdk
+                .convert_fiat_amount(ConvertFiatAmountRequest {
+                    locked_rate_id,
+                    fiat_amount,
+                })
+                .await?;
+            print_value(&res)?;
+            Ok(true)
+        }
+        Command::ReceiveFiat {
+            currency,
+            fiat_amount,
+            spark,
+            description,
+            expiry_secs,
+            locked_rate_id,
+        } => {
+            let payment_method = if spark {
+                FiatReceiveMethod::SparkInvoice {
+                    description,
+                    expiry_time: expiry_secs
+                        .map(|secs| {
+                            SystemTime::now()
+                                .duration_since(UNIX_EPOCH)?
+                                .as_secs()
+                                .checked_add(u64::from(secs))
+                                .ok_or(anyhow::anyhow!("Invalid expiry time"))
+                        })
+                        .transpose()?,
+                    sender_public_key: None,
+                }
+            } else {
+                FiatReceiveMethod::Bolt11Invoice {
+                    description: description.unwrap_or_default(),
+                    expiry_secs,
+                }
+            };
+            let res = sdk
+                .receive_fiat_payment(ReceiveFiatPaymentRequest {
+                    payment_method,
+                    currency,
+                    fiat_amount,
+                    locked_rate_id,
+                })
+                .await?;
+            print_value(&res)?;
+            Ok(true)
+        }
         Command::RecommendedFees => {
             let res = sdk.recommended_fees().await?;
             print_value(&res)?;
@@ -1033,6 +2098,20 @@ pub(crate) async fn execute_command(
             print_value(&res)?;
             Ok(true)
         }
+        Command::RefreshTokensMetadata { token_identifiers } => {
+            let res = sdk
+                .refresh_tokens_metadata(RefreshTokensMetadataRequest { token_identifiers })
+                .await?;
+            print_value(&res)?;
+            Ok(true)
+        }
+        Command::GetTokenFiatRates { token_identifiers } => {
+            let res = sdk
+                .get_token_fiat_rates(GetTokenFiatRatesRequest { token_identifiers })
+                .await?;
+            print_value(&res)?;
+            Ok(true)
+        }
         Command::FetchConversionLimits {
             from_bitcoin,
             token_identifier,
@@ -1061,10 +2140,19 @@ pub(crate) async fn execute_command(
         }
         Command::SetUserSettings {
             spark_private_mode_enabled,
+            display_currency,
         } => {
+            let display_currency = display_currency.map(|currency| {
+                if currency.eq_ignore_ascii_case("none") {
+                    DisplayCurrency::Unset
+                } else {
+                    DisplayCurrency::Set { currency }
+                }
+            });
             sdk.update_user_settings(UpdateUserSettingsRequest {
                 spark_private_mode_enabled,
                 stable_balance_active_label: None,
+                display_currency,
             })
             .await?;
             Ok(true)
@@ -1074,12 +2162,44 @@ pub(crate) async fn execute_command(
             print_value(&res)?;
             Ok(true)
         }
+        Command::CheckHealth => {
+            let res = sdk.check_health().await?;
+            print_value(&res)?;
+            Ok(true)
+        }
+        Command::GetServiceStatus => {
+            let res = sdk.get_service_status().await?;
+            print_value(&res)?;
+            Ok(true)
+        }
+        Command::SetLogFilter { filter } => {
+            sdk.set_log_filter(SetLogFilterRequest { filter }).await?;
+            println!("Log filter updated");
+            Ok(true)
+        }
+        Command::DumpDiagnostics => {
+            let res = sdk.dump_diagnostics().await?;
+            println!("{}", res.bundle);
+            Ok(true)
+        }
+        Command::ListPlugins => {
+            let res = sdk.list_plugins();
+            print_value(&res)?;
+            Ok(true)
+        }
+        Command::GetReceiveOnboardingInfo => {
+            let res = sdk.get_receive_onboarding_info().await?;
+            print_value(&res)?;
+            Ok(true)
+        }
         Command::Advanced(cmd) => advanced::handle_command(rl, sdk, cmd).await,
         Command::Issuer(issuer_command) => {
             issuer::handle_command(token_issuer, issuer_command).await
         }
         Command::Contacts(contact_command) => contacts::handle_command(sdk, contact_command).await,
+        Command::Escrow(escrow_command) => escrow::handle_command(sdk, escrow_command).await,
         Command::Webhooks(webhook_command) => webhooks::handle_command(sdk, webhook_command).await,
+        Command::Jobs(job_command) => jobs::handle_command(sdk, job_command).await,
         Command::StableBalance(sb_command) => stable_balance::handle_command(sdk, sb_command).await,
     }
 }
@@ -1139,6 +2259,19 @@ async fn select_cross_chain_route(
     Ok(route)
 }
 
+fn parse_fee(fee_sat: Option<u64>, sat_per_vbyte: Option<u64>) -> Result<Fee, anyhow::Error> {
+    match (fee_sat, sat_per_vbyte) {
+        (Some(_), Some(_)) => Err(anyhow::anyhow!(
+            "Cannot specify both fee_sat and sat_per_vbyte"
+        )),
+        (Some(fee_sat), None) => Ok(Fee::Fixed { amount: fee_sat }),
+        (None, Some(sat_per_vbyte)) => Ok(Fee::Rate { sat_per_vbyte }),
+        (None, None) => Err(anyhow::anyhow!(
+            "Must specify either fee_sat or sat_per_vbyte"
+        )),
+    }
+}
+
 fn maybe_truncate_address(addr: Option<&str>) -> String {
     addr.map(|c| {
         if c.len() > 12 {
@@ -1234,9 +2367,9 @@ fn read_payment_options(
                 }),
             }))
         }
-        SendPaymentMethod::SparkInvoice { .. } | SendPaymentMethod::CrossChainAddress { .. } => {
-            Ok(None)
-        }
+        SendPaymentMethod::SparkInvoice { .. }
+        | SendPaymentMethod::CrossChainAddress { .. }
+        | SendPaymentMethod::Plugin { .. } => Ok(None),
     }
 }
 
@@ -92,6 +92,15 @@ struct Cli {
     /// (e.g. a local test server).
     #[arg(long)]
     lnurl_domain: Option<String>,
+
+    /// Serve the embedded WebSocket RPC service on this address, e.g.
+    /// `127.0.0.1:9737`
+    #[arg(long, requires = "rpc_token")]
+    rpc_server: Option<String>,
+
+    /// Access token clients of the RPC service must present
+    #[arg(long, requires = "rpc_server")]
+    rpc_token: Option<String>,
 }
 
 fn expand_path(path: &str) -> PathBuf {
@@ -153,6 +162,7 @@ async fn run_interactive_mode(
     stable_balance_config: Option<StableBalanceConfig>,
     passkey_config: Option<PasskeyConfig>,
     lnurl_domain: Option<String>,
+    rpc_server: Option<(String, String)>,
 ) -> Result<()> {
     breez_sdk_spark::init_logging(Some(data_dir.to_string_lossy().into()), None, None)?;
     let persistence = CliPersistence {
@@ -227,6 +237,9 @@ async fn run_interactive_mode(
     if let Some(account_number) = account_number {
         sdk_builder = sdk_builder.with_account_number(account_number);
     }
+    if let Some((addr, token)) = rpc_server {
+        sdk_builder = sdk_builder.with_rpc_server(addr, token);
+    }
 
     let sdk = sdk_builder.build().await?;
 
@@ -350,6 +363,7 @@ async fn main() -> Result<(), anyhow::Error> {
         stable_balance_config,
         passkey_config,
         cli.lnurl_domain,
+        cli.rpc_server.zip(cli.rpc_token),
     ))
     .await?;
 
@@ -379,7 +393,7 @@ mod parse_command_tests {
 
     #[test]
     fn splits_quoted_arguments() {
-        let Command::Parse { input } = parse_command("parse \"two words\"").unwrap() else {
+        let Command::Parse { input, .. } = parse_command("parse \"two words\"").unwrap() else {
             panic!("expected Parse");
         };
         assert_eq!(input, "two words");
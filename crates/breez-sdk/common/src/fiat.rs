@@ -30,6 +30,21 @@ pub trait FiatService: Send + Sync {
 
     /// Get the live rates from the server.
     async fn fetch_fiat_rates(&self) -> Result<Vec<Rate>, ServiceConnectivityError>;
+
+    /// Get the rates of a currency between two unix timestamps, in seconds,
+    /// sorted by timestamp. Optional: the Breez server doesn't provide them.
+    async fn fetch_historical_rates(
+        &self,
+        currency: String,
+        from_timestamp: u64,
+        to_timestamp: u64,
+        resolution: RateResolution,
+    ) -> Result<Vec<HistoricalRate>, ServiceConnectivityError> {
+        let _ = (currency, from_timestamp, to_timestamp, resolution);
+        Err(ServiceConnectivityError::Other(
+            "Historical rates are not supported by this fiat service".to_string(),
+        ))
+    }
 }
 
 fn convert_to_fiat_currency_with_id(id: String, info: CurrencyInfo) -> FiatCurrency {
@@ -115,6 +130,21 @@ pub struct Rate {
     pub value: f64,
 }
 
+/// Interval between the rates of a historical rate series
+#[derive(Clone, Copy, Debug, Deserialize, Eq, PartialEq, Serialize)]
+pub enum RateResolution {
+    Hour,
+    Day,
+}
+
+/// Exchange rate of a currency at a point in time
+#[derive(Clone, Debug, Deserialize, Serialize)]
+pub struct HistoricalRate {
+    /// Unix timestamp, in seconds
+    pub timestamp: u64,
+    pub value: f64,
+}
+
 /// Settings for the symbol representation of a currency
 #[derive(Clone, Debug, Deserialize, Serialize)]
 pub struct Symbol {
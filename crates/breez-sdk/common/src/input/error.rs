@@ -46,6 +46,8 @@ pub enum ParseError {
     ServiceConnectivity(ServiceConnectivityError),
     #[error("Invalid external input parser: {0}")]
     InvalidExternalInputParser(String),
+    #[error("input requires a network lookup")]
+    RequiresNetwork,
 }
 
 impl From<Bip21Error> for ParseError {
@@ -2,7 +2,7 @@ pub mod cross_chain;
 mod error;
 mod models;
 mod parser;
-pub(crate) mod percent_encode;
+pub mod percent_encode;
 
 pub use cross_chain::{
     CrossChainAddressFamily, detect_address_family, parse_cross_chain_uri,
@@ -10,4 +10,7 @@ pub use cross_chain::{
 };
 pub use error::*;
 pub use models::*;
-pub use parser::{parse, parse_invoice, parse_spark_address, validate_lightning_address_format};
+pub use parser::{
+    parse, parse_invoice, parse_offline, parse_spark_address, requires_network_lookup,
+    validate_lightning_address_format,
+};
@@ -237,6 +237,10 @@ pub struct PaymentRequestSource {
 pub struct SilentPaymentAddressDetails {
     pub address: String,
     pub network: BitcoinNetwork,
+    /// The hex-encoded public key the receiver scans for payments with
+    pub scan_public_key: String,
+    /// The hex-encoded public key the payment outputs are tweaked from
+    pub spend_public_key: String,
     pub source: PaymentRequestSource,
 }
 
@@ -1,5 +1,7 @@
 use std::ops::Not;
 
+use bech32::primitives::decode::CheckedHrpstring;
+use bitcoin::secp256k1::PublicKey;
 use bitcoin::{Address, Denomination, address::NetworkUnchecked};
 use lightning::bolt11_invoice::Bolt11InvoiceDescriptionRef;
 use platform_utils::time::UNIX_EPOCH;
@@ -15,6 +17,7 @@ use crate::{
         SparkAddressDetails, SparkInvoiceDetails,
     },
     lnurl::{auth, error::LnurlError, pay::LnurlPayRequestDetails},
+    network::BitcoinNetwork,
 };
 
 use super::percent_encode;
@@ -65,6 +68,89 @@ pub fn parse_invoice(input: &str) -> Option<Bolt11InvoiceDetails> {
     parse_bolt11(input, &PaymentRequestSource::default())
 }
 
+/// Parses the inputs that are recognized without any network request: BIP-21
+/// URIs, BOLT11 invoices, BOLT12 offers and invoice requests, Spark addresses
+/// and invoices, bitcoin addresses and cross-chain addresses.
+///
+/// Lightning addresses, BIP-353 addresses and LNURLs can only be resolved
+/// with a network lookup and fail with [`ParseError::RequiresNetwork`].
+pub fn parse_offline(input: &str) -> Result<InputType, ParseError> {
+    let input = input.trim();
+    if input.is_empty() {
+        return Err(ParseError::EmptyInput);
+    }
+
+    if let Some(input_type) = parse_local(input)? {
+        return Ok(input_type);
+    }
+
+    if requires_network_lookup(input) {
+        return Err(ParseError::RequiresNetwork);
+    }
+
+    Err(ParseError::InvalidInput)
+}
+
+/// Whether the input can only be resolved with a network lookup: a Lightning
+/// or BIP-353 address, or an LNURL
+pub fn requires_network_lookup(input: &str) -> bool {
+    let input = strip_lightning_prefix(input.trim());
+    if input.contains('@') {
+        return true;
+    }
+
+    let extracted = extract_lud01_lightning_param(input);
+    let input = extracted.as_deref().unwrap_or(input);
+    if let Ok((hrp, _)) = bech32::decode(input) {
+        return hrp.to_lowercase() == LNURL_HRP;
+    }
+    ["lnurlp:", "lnurlw:", "keyauth:"]
+        .iter()
+        .any(|prefix| has_prefix(input, prefix))
+}
+
+/// Parses the input types that don't need a network lookup
+fn parse_local(input: &str) -> Result<Option<InputType>, ParseError> {
+    if has_bip_21_prefix(input) {
+        let source = PaymentRequestSource {
+            bip_21_uri: Some(input.to_string()),
+            bip_353_address: None,
+        };
+        if let Some(bip_21) = parse_bip_21(input, &source)? {
+            return Ok(Some(InputType::Bip21(bip_21)));
+        }
+    }
+
+    let source = PaymentRequestSource::default();
+    let lightning_input = strip_lightning_prefix(input);
+    if let Some(payment_method) = parse_lightning_payment_method(lightning_input, &source) {
+        return Ok(Some(payment_method));
+    }
+
+    if let Some(bolt12_invoice_request) = parse_bolt12_invoice_request(lightning_input, &source) {
+        return Ok(Some(InputType::Bolt12InvoiceRequest(
+            bolt12_invoice_request,
+        )));
+    }
+
+    if let Some(input_type) = parse_spark_address(input, &source) {
+        return Ok(Some(input_type));
+    }
+
+    if let Some(input_type) = parse_bitcoin(input, &source) {
+        return Ok(Some(input_type));
+    }
+
+    // Cross-chain address detection (EVM / Solana / Tron bare addresses
+    // and EIP-681 / Solana / Tron URIs). Pure address format detection
+    // with no network calls.
+    if let Some(details) = super::cross_chain::try_parse_cross_chain_address(input) {
+        return Ok(Some(InputType::CrossChainAddress(details)));
+    }
+
+    Ok(None)
+}
+
 pub struct InputParser<C, D> {
     http_client: C,
     dns_resolver: D,
@@ -106,6 +192,11 @@ where
     }
 
     pub async fn parse_core(&self, input: &str) -> Result<Option<InputType>, ParseError> {
+        // Inputs that parse locally never wait on a network lookup
+        if let Some(input_type) = parse_local(input)? {
+            return Ok(Some(input_type));
+        }
+
         if input.contains('@') {
             if let Some(lightning_address) = self.parse_lightning_address(input).await {
                 return Ok(Some(InputType::LightningAddress(lightning_address)));
@@ -116,34 +207,12 @@ where
             }
         }
 
-        if has_bip_21_prefix(input) {
-            let source = PaymentRequestSource {
-                bip_21_uri: Some(input.to_string()),
-                bip_353_address: None,
-            };
-            if let Some(bip_21) = parse_bip_21(input, &source)? {
-                return Ok(Some(InputType::Bip21(bip_21)));
-            }
-        }
-
         let source = PaymentRequestSource::default();
-        if let Some(input_type) = self.parse_lightning(input, &source).await? {
-            return Ok(Some(input_type));
-        }
-
-        if let Some(input_type) = parse_spark_address(input, &source) {
-            return Ok(Some(input_type));
-        }
-
-        if let Some(input_type) = parse_bitcoin(input, &source) {
-            return Ok(Some(input_type));
-        }
-
-        // Cross-chain address detection (EVM / Solana / Tron bare addresses
-        // and EIP-681 / Solana / Tron URIs). Pure address format detection
-        // with no network calls.
-        if let Some(details) = super::cross_chain::try_parse_cross_chain_address(input) {
-            return Ok(Some(InputType::CrossChainAddress(details)));
+        if let Some(lnurl) = self
+            .parse_lnurl(strip_lightning_prefix(input), &source)
+            .await?
+        {
+            return Ok(Some(lnurl));
         }
 
         Ok(None)
@@ -192,34 +261,6 @@ where
         )
     }
 
-    async fn parse_lightning(
-        &self,
-        input: &str,
-        source: &PaymentRequestSource,
-    ) -> Result<Option<InputType>, ParseError> {
-        let input = if has_lightning_prefix(input) {
-            &input[LIGHTNING_PREFIX_LEN..]
-        } else {
-            input
-        };
-
-        if let Some(payment_method) = parse_lightning_payment_method(input, source) {
-            return Ok(Some(payment_method));
-        }
-
-        if let Some(bolt12_invoice_request) = parse_bolt12_invoice_request(input, source) {
-            return Ok(Some(InputType::Bolt12InvoiceRequest(
-                bolt12_invoice_request,
-            )));
-        }
-
-        if let Some(lnurl) = self.parse_lnurl(input, source).await? {
-            return Ok(Some(lnurl));
-        }
-
-        Ok(None)
-    }
-
     async fn parse_lightning_address(&self, input: &str) -> Option<LightningAddressDetails> {
         // Strip the optional ₿ prefix before validation
         let cleaned_input = input.strip_prefix('₿').unwrap_or(input);
@@ -475,6 +516,14 @@ fn has_lightning_prefix(input: &str) -> bool {
     has_prefix(input, LIGHTNING_PREFIX)
 }
 
+fn strip_lightning_prefix(input: &str) -> &str {
+    if has_lightning_prefix(input) {
+        &input[LIGHTNING_PREFIX_LEN..]
+    } else {
+        input
+    }
+}
+
 fn has_prefix(input: &str, prefix: &str) -> bool {
     if input.len() < prefix.len() {
         return false;
@@ -745,17 +794,9 @@ pub fn parse_spark_address(input: &str, source: &PaymentRequestSource) -> Option
 }
 
 fn parse_bitcoin(input: &str, source: &PaymentRequestSource) -> Option<InputType> {
-    if let Ok((hrp, _)) = bech32::decode(input)
-        && hrp.to_lowercase().as_str() == "sp"
-    {
-        match parse_silent_payment_address(input, source) {
-            Some(silent_payment) => {
-                return Some(InputType::SilentPaymentAddress(silent_payment));
-            }
-            None => {
-                return None;
-            }
-        }
+    let lowercase = input.to_lowercase();
+    if lowercase.starts_with("sp1") || lowercase.starts_with("tsp1") {
+        return parse_silent_payment_address(input, source).map(InputType::SilentPaymentAddress);
     }
 
     if let Some(address) = parse_bitcoin_address(input, source) {
@@ -924,12 +965,63 @@ fn parse_lightning_payment_method(input: &str, source: &PaymentRequestSource) ->
     None
 }
 
+/// Bech32m with the longer code length that BIP-352 allows for silent payment
+/// addresses.
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+enum SilentPaymentChecksum {}
+
+impl bech32::Checksum for SilentPaymentChecksum {
+    type MidstateRepr = u32;
+    const CODE_LENGTH: usize = 1023;
+    const CHECKSUM_LENGTH: usize = 6;
+    const GENERATOR_SH: [u32; 5] = [
+        0x3b6a_57b2,
+        0x2650_8e6d,
+        0x1ea1_19fa,
+        0x3d42_33dd,
+        0x2a14_62b3,
+    ];
+    const TARGET_RESIDUE: u32 = 0x2bc8_30a3;
+}
+
+/// Length of the payload of a silent payment address: the scan public key
+/// followed by the spend public key.
+const SILENT_PAYMENT_PAYLOAD_LEN: usize = 66;
+
 fn parse_silent_payment_address(
-    _input: &str,
-    _source: &PaymentRequestSource,
+    input: &str,
+    source: &PaymentRequestSource,
 ) -> Option<SilentPaymentAddressDetails> {
-    // TODO: Support silent payment addresses
-    None
+    let mut checked = CheckedHrpstring::new::<SilentPaymentChecksum>(input).ok()?;
+    let network = match checked.hrp().to_lowercase().as_str() {
+        "sp" => BitcoinNetwork::Bitcoin,
+        // BIP-352 shares the `tsp` prefix between all test networks
+        "tsp" => BitcoinNetwork::Regtest,
+        _ => return None,
+    };
+    let version = checked.remove_witness_version()?.to_u8();
+    let payload: Vec<u8> = checked.byte_iter().collect();
+    // Version 0 carries exactly the two keys, while later versions may append
+    // data that version 0 readers ignore. Version 31 is reserved for a
+    // backwards incompatible change.
+    let keys = match version {
+        0 if payload.len() == SILENT_PAYMENT_PAYLOAD_LEN => &payload[..],
+        1..=30 if payload.len() >= SILENT_PAYMENT_PAYLOAD_LEN => {
+            &payload[..SILENT_PAYMENT_PAYLOAD_LEN]
+        }
+        _ => return None,
+    };
+    let (scan_key, spend_key) = keys.split_at(SILENT_PAYMENT_PAYLOAD_LEN / 2);
+    let scan_public_key = PublicKey::from_slice(scan_key).ok()?;
+    let spend_public_key = PublicKey::from_slice(spend_key).ok()?;
+
+    Some(SilentPaymentAddressDetails {
+        address: input.to_string(),
+        network,
+        scan_public_key: scan_public_key.to_string(),
+        spend_public_key: spend_public_key.to_string(),
+        source: source.clone(),
+    })
 }
 
 #[cfg(test)]
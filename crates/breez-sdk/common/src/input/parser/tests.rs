@@ -1,13 +1,15 @@
 #![allow(clippy::similar_names)]
 
-use macros::async_test_all;
+use bitcoin::secp256k1::{PublicKey, Secp256k1, SecretKey};
+use macros::{async_test_all, test_all};
 use serde_json::json;
 
 use crate::input::error::Bip21Error;
-use crate::input::parser::InputParser;
+use crate::input::parser::{InputParser, parse_offline, requires_network_lookup};
 use crate::input::{
     Bip21Details, Bip21Extra, BitcoinAddressDetails, ExternalInputParser, InputType, ParseError,
 };
+use crate::network::BitcoinNetwork;
 use crate::test_utils::mock_dns_resolver::MockDnsResolver;
 use crate::test_utils::mock_rest_client::{MockResponse, MockRestClient};
 
@@ -227,6 +229,90 @@ async fn test_bip21_with_invalid_silent_payment() {
     ));
 }
 
+fn silent_payment_address(hrp: &str, version: u8, payload: &[u8]) -> String {
+    use bech32::{ByteIterExt, Fe32, Fe32IterExt, Hrp};
+
+    payload
+        .iter()
+        .copied()
+        .bytes_to_fes()
+        .with_checksum::<super::SilentPaymentChecksum>(&Hrp::parse(hrp).unwrap())
+        .with_witness_version(Fe32::try_from(version).unwrap())
+        .chars()
+        .collect()
+}
+
+fn silent_payment_keys() -> (PublicKey, PublicKey) {
+    let secp = Secp256k1::new();
+    let key =
+        |byte| PublicKey::from_secret_key(&secp, &SecretKey::from_slice(&[byte; 32]).unwrap());
+    (key(1), key(2))
+}
+
+#[async_test_all]
+async fn test_silent_payment_address() {
+    let input_parser = InputParser::new(MockDnsResolver::new(), MockRestClient::new(), None);
+    let (scan_key, spend_key) = silent_payment_keys();
+    let mut payload = scan_key.serialize().to_vec();
+    payload.extend_from_slice(&spend_key.serialize());
+
+    let address = silent_payment_address("sp", 0, &payload);
+    assert!(address.starts_with("sp1q"));
+    let result = input_parser.parse(&address).await.unwrap();
+    let InputType::SilentPaymentAddress(details) = result else {
+        panic!("Expected SilentPaymentAddress result");
+    };
+    assert_eq!(details.address, address);
+    assert_eq!(details.network, BitcoinNetwork::Bitcoin);
+    assert_eq!(details.scan_public_key, scan_key.to_string());
+    assert_eq!(details.spend_public_key, spend_key.to_string());
+
+    let address = silent_payment_address("tsp", 0, &payload);
+    let result = input_parser.parse(&address).await.unwrap();
+    let InputType::SilentPaymentAddress(details) = result else {
+        panic!("Expected SilentPaymentAddress result");
+    };
+    assert_eq!(details.network, BitcoinNetwork::Regtest);
+
+    let bip21 = format!("bitcoin:?sp={}", silent_payment_address("sp", 0, &payload));
+    let InputType::Bip21(bip21_details) = input_parser.parse(&bip21).await.unwrap() else {
+        panic!("Expected Bip21 result");
+    };
+    assert!(matches!(
+        bip21_details.payment_methods.as_slice(),
+        [InputType::SilentPaymentAddress(_)]
+    ));
+}
+
+#[async_test_all]
+async fn test_silent_payment_address_versions() {
+    let input_parser = InputParser::new(MockDnsResolver::new(), MockRestClient::new(), None);
+    let (scan_key, spend_key) = silent_payment_keys();
+    let mut payload = scan_key.serialize().to_vec();
+    payload.extend_from_slice(&spend_key.serialize());
+    let mut extended_payload = payload.clone();
+    extended_payload.extend_from_slice(&[0u8; 8]);
+
+    // Later versions may append data, which is ignored
+    let address = silent_payment_address("sp", 1, &extended_payload);
+    let result = input_parser.parse(&address).await.unwrap();
+    let InputType::SilentPaymentAddress(details) = result else {
+        panic!("Expected SilentPaymentAddress result");
+    };
+    assert_eq!(details.spend_public_key, spend_key.to_string());
+
+    for address in [
+        silent_payment_address("sp", 0, &extended_payload),
+        silent_payment_address("sp", 0, &payload[..65]),
+        silent_payment_address("sp", 31, &payload),
+    ] {
+        assert!(matches!(
+            input_parser.parse(&address).await,
+            Err(ParseError::InvalidInput)
+        ));
+    }
+}
+
 #[async_test_all]
 async fn test_bip21_with_missing_equals() {
     let mock_dns_resolver = MockDnsResolver::new();
@@ -1122,3 +1208,41 @@ async fn test_lnurl_domain_is_lowercased() {
         other => panic!("Expected LnurlPay, got: {other:?}"),
     }
 }
+
+#[test_all]
+fn test_parse_offline() {
+    let bolt11 = "lnbc110n1p38q3gtpp5ypz09jrd8p993snjwnm68cph4ftwp22le34xd4r8ftspwshxhmnsdqqxqyjw5qcqpxsp5htlg8ydpywvsa7h3u4hdn77ehs4z4e844em0apjyvmqfkzqhhd2q9qgsqqqyssqszpxzxt9uuqzymr7zxcdccj5g69s8q7zzjs7sgxn9ejhnvdh6gqjcy22mss2yexunagm5r2gqczh8k24cwrqml3njskm548aruhpwssq9nvrvz";
+    assert!(matches!(
+        parse_offline(&format!("lightning:{bolt11}")),
+        Ok(InputType::Bolt11Invoice(_))
+    ));
+    assert!(matches!(
+        parse_offline("bc1qxhmdufsvnuaaaer4ynz88fspdsxq2h9e9cetdj"),
+        Ok(InputType::BitcoinAddress(_))
+    ));
+    assert!(matches!(
+        parse_offline("bitcoin:bc1qxhmdufsvnuaaaer4ynz88fspdsxq2h9e9cetdj?amount=0.001"),
+        Ok(InputType::Bip21(_))
+    ));
+
+    let lnurl_pay_encoded = "lnurl1dp68gurn8ghj7mr0vdskc6r0wd6z7mrww4excttsv9un7um9wdekjmmw84jxywf5x43rvv35xgmr2enrxanr2cfcvsmnwe3jxcukvde48qukgdec89snwde3vfjxvepjxpjnjvtpxd3kvdnxx5crxwpjvyunsephsz36jf";
+    for input in [
+        "user@domain.net",
+        "₿user@domain.net",
+        lnurl_pay_encoded,
+        "lnurlp://domain.com/lnurl-pay",
+    ] {
+        assert!(requires_network_lookup(input), "{input}");
+        assert!(
+            matches!(parse_offline(input), Err(ParseError::RequiresNetwork)),
+            "{input}"
+        );
+    }
+
+    assert!(!requires_network_lookup(bolt11));
+    assert!(matches!(
+        parse_offline("not a payment request"),
+        Err(ParseError::InvalidInput)
+    ));
+    assert!(matches!(parse_offline("  "), Err(ParseError::EmptyInput)));
+}
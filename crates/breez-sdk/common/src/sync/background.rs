@@ -1,3 +1,4 @@
+use futures::StreamExt;
 use platform_utils::time::SystemTime;
 use platform_utils::tokio;
 use std::{
@@ -59,6 +60,7 @@ pub struct SyncProcessor {
     new_record_handler: Arc<dyn NewRecordHandler>,
     client: SigningClient,
     storage: Arc<dyn SyncStorage>,
+    paused: Option<watch::Receiver<bool>>,
 }
 
 impl SyncProcessor {
@@ -73,9 +75,19 @@ impl SyncProcessor {
             new_record_handler,
             client,
             storage,
+            paused: None,
         }
     }
 
+    /// Closes the update subscription while `paused` is true, so that no
+    /// connection is kept open or re-established. Local changes are still
+    /// pushed, and the changes missed meanwhile are pulled on resume.
+    #[must_use]
+    pub fn with_pause_signal(mut self, paused: watch::Receiver<bool>) -> Self {
+        self.paused = Some(paused);
+        self
+    }
+
     pub async fn start(
         self: &Arc<Self>,
         shutdown_receiver: watch::Receiver<()>,
@@ -139,6 +151,12 @@ impl SyncProcessor {
         pull_trigger_tx: watch::Sender<()>,
     ) {
         loop {
+            if !self
+                .wait_until_resumed(&mut shutdown_receiver, &pull_trigger_tx)
+                .await
+            {
+                return;
+            }
             self.subscribe_updates(shutdown_receiver.clone(), pull_trigger_tx.clone())
                 .await;
             tokio::select! {
@@ -153,6 +171,37 @@ impl SyncProcessor {
         }
     }
 
+    /// Waits while the sync is paused, then triggers a pull of the changes
+    /// missed meanwhile. Returns false on shutdown.
+    async fn wait_until_resumed(
+        &self,
+        shutdown_receiver: &mut watch::Receiver<()>,
+        pull_trigger_tx: &watch::Sender<()>,
+    ) -> bool {
+        let Some(mut paused) = self.paused.clone() else {
+            return true;
+        };
+        if !*paused.borrow_and_update() {
+            return true;
+        }
+        debug!("Real-time sync paused");
+        tokio::select! {
+            result = paused.wait_for(|paused| !*paused) => {
+                if result.is_err() {
+                    return false;
+                }
+            }
+            _ = shutdown_receiver.changed() => {
+                return false;
+            }
+        }
+        debug!("Real-time sync resumed");
+        if let Err(e) = pull_trigger_tx.send(()) {
+            error!("Failed to trigger a pull after resuming: {e}");
+        }
+        true
+    }
+
     async fn subscribe_updates(
         &self,
         mut shutdown_receiver: watch::Receiver<()>,
@@ -167,6 +216,7 @@ impl SyncProcessor {
             }
         };
 
+        let mut paused = self.paused.clone();
         loop {
             tokio::select! {
                 _ = shutdown_receiver.changed() => {
@@ -174,9 +224,14 @@ impl SyncProcessor {
                     break;
                 }
 
-                maybe_notification = stream.message() => {
+                () = wait_for_pause(paused.as_mut()) => {
+                    debug!("Real-time sync paused, closing update subscription");
+                    break;
+                }
+
+                maybe_notification = stream.next() => {
                     match maybe_notification {
-                        Ok(Some(notification)) => {
+                        Some(Ok(notification)) => {
                             debug!("Received notification for client id: {:?}", notification.client_id);
                             if let Some(client_id) = notification.client_id && client_id == self.client.client_id {
                                 debug!("Ignoring notification for ourselves");
@@ -188,11 +243,11 @@ impl SyncProcessor {
                                 break;
                             }
                         }
-                        Ok(None) => {
+                        None => {
                             debug!("Notification stream closed by server");
                             break;
                         }
-                        Err(e) => {
+                        Some(Err(e)) => {
                             error!("Error receiving notification: {}", e);
                             break;
                         }
@@ -597,6 +652,7 @@ impl SyncProcessor {
             let mut merged_incoming: crate::sync::model::IncomingChange =
                 (&incoming_record).try_into()?;
             if let Some(outgoing_changes) = outgoing_by_record.get(&incoming_record.new_state.id) {
+                let incoming_data = merged_incoming.new_state.data.clone();
                 for outgoing_change in outgoing_changes {
                     for (k, v) in &outgoing_change.updated_fields {
                         if let Ok(value) = serde_json::from_str(v) {
@@ -604,6 +660,14 @@ impl SyncProcessor {
                         }
                     }
                 }
+                // Report the incoming values the local changes replaced
+                let mut overridden_fields: Vec<String> = incoming_data
+                    .iter()
+                    .filter(|(k, v)| merged_incoming.new_state.data.get(*k) != Some(*v))
+                    .map(|(k, _)| k.clone())
+                    .collect();
+                overridden_fields.sort();
+                merged_incoming.overridden_fields = overridden_fields;
             }
 
             debug!(
@@ -656,6 +720,16 @@ struct BackoffHandle {
     handle: tokio::task::JoinHandle<()>,
 }
 
+/// Resolves once `paused` is true. Never resolves without a pause signal.
+async fn wait_for_pause(paused: Option<&mut watch::Receiver<bool>>) {
+    if let Some(paused) = paused
+        && paused.wait_for(|paused| *paused).await.is_ok()
+    {
+        return;
+    }
+    std::future::pending().await
+}
+
 #[cfg(test)]
 mod tests {
     use crate::sync::proto::SetRecordReply;
@@ -1495,6 +1569,41 @@ mod tests {
         assert_eq!(applied_count, 1);
     }
 
+    #[macros::async_test_all]
+    async fn test_paused_sync_subscribes_only_after_resume() {
+        let (_tx, rx) = broadcast::channel::<RecordId>(10);
+        let mut mock_client = MockSyncerClient::new();
+        mock_client
+            .expect_listen_changes()
+            .times(0)
+            .returning(|_| Err(anyhow!("subscription not expected while paused")));
+        let (paused_tx, paused_rx) = watch::channel(true);
+        let sync_processor = SyncProcessor::new(
+            create_signing_client(mock_client, MockSyncSigner::new()),
+            rx,
+            Arc::new(MockNewRecordHandler::new()),
+            Arc::new(MockSyncStorage::new()),
+        )
+        .with_pause_signal(paused_rx);
+        let (shutdown_tx, mut shutdown_rx) = watch::channel(());
+        let (pull_trigger_tx, mut pull_trigger_rx) = watch::channel(());
+        pull_trigger_rx.borrow_and_update();
+
+        let resumed = tokio::spawn(async move {
+            sync_processor
+                .wait_until_resumed(&mut shutdown_rx, &pull_trigger_tx)
+                .await
+        });
+        tokio::time::sleep(Duration::from_millis(50)).await;
+        assert!(!pull_trigger_rx.has_changed().unwrap());
+
+        paused_tx.send_replace(false);
+        assert!(resumed.await.unwrap());
+        // The changes missed while paused are pulled on resume
+        assert!(pull_trigger_rx.has_changed().unwrap());
+        drop(shutdown_tx);
+    }
+
     #[macros::async_test_all]
     async fn test_start_includes_all_initialization_steps() {
         // Setup
@@ -2089,6 +2198,7 @@ mod tests {
                 change.new_state.data.get("name") == Some(&serde_json::json!("Alice"))
                     && change.new_state.data.get("deleted_at")
                         == Some(&serde_json::json!(1_234_567_890))
+                    && change.overridden_fields.is_empty()
             })
             .returning(|_| Ok(RecordOutcome::Completed));
 
@@ -2171,6 +2281,69 @@ mod tests {
         assert_eq!(result.unwrap(), 1);
     }
 
+    #[macros::async_test_all]
+    async fn test_pull_merge_reports_overridden_fields() {
+        let mut mock_storage = MockSyncStorage::new();
+
+        let mut incoming_data = HashMap::new();
+        incoming_data.insert("name".to_string(), "\"Alice\"".to_string());
+        incoming_data.insert(
+            "payment_identifier".to_string(),
+            "\"alice@x.com\"".to_string(),
+        );
+        let incoming_record = crate::sync::storage::IncomingChange {
+            new_state: create_record_with_data("Contact", "c1", 5, incoming_data),
+            old_state: None,
+        };
+
+        // The local change renames the contact and keeps the same identifier
+        let mut fields = HashMap::new();
+        fields.insert("name".to_string(), "\"Bob\"".to_string());
+        fields.insert(
+            "payment_identifier".to_string(),
+            "\"alice@x.com\"".to_string(),
+        );
+        let outgoing = create_outgoing_change_with_fields("Contact", "c1", 1, fields);
+
+        mock_storage
+            .expect_get_incoming_records()
+            .with(eq(u32::MAX))
+            .returning(move |_| Ok(vec![incoming_record.clone()]));
+
+        mock_storage
+            .expect_get_pending_outgoing_changes()
+            .with(eq(u32::MAX))
+            .returning(move |_| Ok(vec![outgoing.clone()]));
+
+        mock_storage
+            .expect_update_record_from_incoming()
+            .returning(|_| Ok(()));
+
+        let mut mock_handler = MockNewRecordHandler::new();
+        mock_handler
+            .expect_on_incoming_change()
+            .times(1)
+            .withf(|change| {
+                change.new_state.data.get("name") == Some(&serde_json::json!("Bob"))
+                    && change.overridden_fields == vec!["name".to_string()]
+            })
+            .returning(|_| Ok(RecordOutcome::Completed));
+
+        mock_storage
+            .expect_delete_incoming_record()
+            .returning(|_| Ok(()));
+
+        let sync_processor = SyncProcessor::new(
+            create_signing_client(MockSyncerClient::new(), MockSyncSigner::new()),
+            broadcast::channel(10).1,
+            Arc::new(mock_handler),
+            Arc::new(mock_storage),
+        );
+
+        let result = sync_processor.pull_sync_once_local().await;
+        assert_eq!(result.unwrap(), 1);
+    }
+
     #[macros::async_test_all]
     async fn test_pull_merge_different_record_id_no_merge() {
         let mut mock_storage = MockSyncStorage::new();
@@ -1,7 +1,8 @@
 use anyhow::{Result, anyhow};
 
+use futures::StreamExt;
 use tonic::{
-    Request, Status, Streaming,
+    Request, Status,
     metadata::{Ascii, MetadataValue},
     service::{Interceptor, interceptor::InterceptedService},
 };
@@ -15,12 +16,18 @@ use crate::{
     },
 };
 
+/// The notifications of changes pushed by other clients
+#[cfg(not(all(target_family = "wasm", target_os = "unknown")))]
+pub type NotificationStream = futures::stream::BoxStream<'static, Result<Notification>>;
+#[cfg(all(target_family = "wasm", target_os = "unknown"))]
+pub type NotificationStream = futures::stream::LocalBoxStream<'static, Result<Notification>>;
+
 #[cfg_attr(test, mockall::automock)]
 #[macros::async_trait]
 pub trait SyncerClient: Send + Sync {
     async fn set_record(&self, req: SetRecordRequest) -> Result<SetRecordReply>;
     async fn list_changes(&self, req: ListChangesRequest) -> Result<ListChangesReply>;
-    async fn listen_changes(&self, req: ListenChangesRequest) -> Result<Streaming<Notification>>;
+    async fn listen_changes(&self, req: ListenChangesRequest) -> Result<NotificationStream>;
     async fn set_lock(&self, req: SetLockRequest) -> Result<SetLockReply>;
     async fn get_lock(&self, req: GetLockRequest) -> Result<GetLockReply>;
 }
@@ -60,8 +67,9 @@ impl SyncerClient for BreezSyncerClient {
         Ok(self.client.clone().list_changes(req).await?.into_inner())
     }
 
-    async fn listen_changes(&self, req: ListenChangesRequest) -> Result<Streaming<Notification>> {
-        Ok(self.client.clone().listen_changes(req).await?.into_inner())
+    async fn listen_changes(&self, req: ListenChangesRequest) -> Result<NotificationStream> {
+        let stream = self.client.clone().listen_changes(req).await?.into_inner();
+        Ok(Box::pin(stream.map(|item| item.map_err(Into::into))))
     }
 
     async fn set_lock(&self, req: SetLockRequest) -> Result<SetLockReply> {
@@ -152,6 +152,11 @@ pub struct IncomingChange {
 
     /// The current already existing sync state for this record.
     pub old_state: Option<Record>,
+
+    /// Fields of `new_state` whose incoming value was replaced by a pending
+    /// outgoing change. Local changes that weren't pushed yet win, and are
+    /// pushed on top of the incoming revision so all devices converge.
+    pub overridden_fields: Vec<String>,
     // Pending outgoing changes are changes that have been applied to the relational data store already, but not to the parent.
     // The incoming change will come _before_ these outgoing changes, so to do things perfectly these changes have to be rolled back
     // in reverse order, then the incoming change applied, then the outgoing changes reapplied in forward order.
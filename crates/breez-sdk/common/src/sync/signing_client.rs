@@ -3,14 +3,13 @@ use std::{collections::HashMap, sync::Arc};
 use bitcoin::hex::DisplayHex;
 use serde::{Deserialize, Serialize};
 use serde_json::Value;
-use tonic::Streaming;
 
 use crate::{
     sync::{
-        client::SyncerClient,
+        client::{NotificationStream, SyncerClient},
         model::{Record, RecordId},
         proto::{
-            GetLockRequest, ListChangesRequest, ListenChangesRequest, Notification, SetLockRequest,
+            GetLockRequest, ListChangesRequest, ListenChangesRequest, SetLockRequest,
             SetRecordReply, SetRecordRequest,
         },
         signer::SyncSigner,
@@ -100,7 +99,7 @@ impl SigningClient {
         Ok(changes)
     }
 
-    pub async fn listen_changes(&self) -> anyhow::Result<Streaming<Notification>> {
+    pub async fn listen_changes(&self) -> anyhow::Result<NotificationStream> {
         let request_time = now();
         let msg = format!("{request_time}");
         let signature = self.sign_message(msg.as_bytes()).await?;
@@ -86,6 +86,7 @@ impl TryFrom<&IncomingChange> for crate::sync::model::IncomingChange {
                 Some(old_state) => Some(old_state.try_into()?),
                 None => None,
             },
+            overridden_fields: Vec::new(),
         })
     }
 }
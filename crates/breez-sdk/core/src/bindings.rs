@@ -3,9 +3,10 @@ use std::sync::Arc;
 use tokio::sync::Mutex;
 
 use crate::{
-    BitcoinChainService, BreezSdk, Config, Credentials, FiatService, PaymentObserver, RestClient,
-    SdkContext, SdkError, Seed, SessionStore, Storage, StorageBackend,
-    chain::rest_client::ChainApiType,
+    BitcoinChainService, BreezSdk, Clock, Config, Credentials, DuressConfig, FiatService,
+    InputParser, IssuerApprovalConfig, IssuerApprovalSigner, PaymentObserver, RestClient,
+    SdkContext, SdkError, SdkPlugin, Seed, SendApprover, SessionStore, Storage, StorageBackend,
+    TokenPriceService, chain::rest_client::ChainApiType, token_conversion::ConversionPriceSource,
 };
 
 /// Builder for creating `BreezSdk` instances with customizable components.
@@ -136,6 +137,16 @@ impl SdkBuilder {
         *builder = builder.clone().with_account_number(account_number);
     }
 
+    /// Opens the decoy wallet of the duress configuration if `pin` is its
+    /// duress PIN. Any other PIN leaves the builder unchanged.
+    /// Arguments:
+    /// - `duress_config`: The duress configuration.
+    /// - `pin`: The PIN the user entered.
+    pub async fn with_duress(&self, duress_config: DuressConfig, pin: String) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder.clone().with_duress(duress_config, pin);
+    }
+
     /// Sets the chain service to be used by the SDK.
     /// Arguments:
     /// - `chain_service`: The chain service to be used.
@@ -169,11 +180,42 @@ impl SdkBuilder {
         *builder = builder.clone().with_fiat_service(fiat_service);
     }
 
+    /// Sets the service providing the fiat prices of tokens.
+    /// Arguments:
+    /// - `token_price_service`: The token price service to be used.
+    pub async fn with_token_price_service(&self, token_price_service: Arc<dyn TokenPriceService>) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder
+            .clone()
+            .with_token_price_service(token_price_service);
+    }
+
+    /// Sets the external price source used to verify token conversion quotes.
+    /// Arguments:
+    /// - `conversion_price_source`: The price source to check conversion rates against.
+    pub async fn with_conversion_price_source(
+        &self,
+        conversion_price_source: Arc<dyn ConversionPriceSource>,
+    ) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder
+            .clone()
+            .with_conversion_price_source(conversion_price_source);
+    }
+
     pub async fn with_lnurl_client(&self, lnurl_client: Arc<dyn RestClient>) {
         let mut builder = self.inner.lock().await;
         *builder = builder.clone().with_lnurl_client(lnurl_client);
     }
 
+    /// Sets the REST client used to upload and download state backups.
+    /// Arguments:
+    /// - `backup_client`: The REST client to be used.
+    pub async fn with_backup_client(&self, backup_client: Arc<dyn RestClient>) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder.clone().with_backup_client(backup_client);
+    }
+
     /// Sets the payment observer to be used by the SDK.
     /// Arguments:
     /// - `payment_observer`: The payment observer to be used.
@@ -182,6 +224,62 @@ impl SdkBuilder {
         *builder = builder.clone().with_payment_observer(payment_observer);
     }
 
+    /// Sets the send approver to be used by the SDK.
+    /// Arguments:
+    /// - `send_approver`: The send approver to be used.
+    pub async fn with_send_approver(&self, send_approver: Arc<dyn SendApprover>) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder.clone().with_send_approver(send_approver);
+    }
+
+    /// Requires issuer operations to be approved by an additional key held by
+    /// the approval signer.
+    /// Arguments:
+    /// - `approval_signer`: The signer holding the approval key.
+    /// - `config`: The approval key to expect.
+    pub async fn with_issuer_approver(
+        &self,
+        approval_signer: Arc<dyn IssuerApprovalSigner>,
+        config: IssuerApprovalConfig,
+    ) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder
+            .clone()
+            .with_issuer_approver(approval_signer, config);
+    }
+
+    /// Registers a plugin that provides a custom payment rail or balance
+    /// provider.
+    /// Arguments:
+    /// - `plugin`: The plugin to be registered.
+    pub async fn with_plugin(&self, plugin: Arc<dyn SdkPlugin>) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder.clone().with_plugin(plugin);
+    }
+
+    /// Registers a parser for inputs the SDK doesn't recognize.
+    /// Arguments:
+    /// - `parser`: The input parser to be registered.
+    pub async fn with_input_parser(&self, parser: Arc<dyn InputParser>) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder.clone().with_input_parser(parser);
+    }
+
+    /// Sets the clock the SDK reads the current time from when checking
+    /// expiries, instead of the system clock.
+    /// Arguments:
+    /// - `clock`: The clock to be used.
+    pub async fn with_clock(&self, clock: Arc<dyn Clock>) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder.clone().with_clock(clock);
+    }
+
+    /// Starts the SDK with the periodic background sync paused.
+    pub async fn with_background_sync_paused(&self) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder.clone().with_background_sync_paused();
+    }
+
     /// Threads a shared [`SdkContext`](crate::SdkContext) into the builder.
     ///
     /// Construct the context once via
@@ -215,6 +313,36 @@ impl SdkBuilder {
     }
 }
 
+#[cfg(feature = "nostr-sync")]
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl SdkBuilder {
+    /// Runs real-time sync over the given Nostr relays instead of the sync
+    /// server. The records are encrypted so the relays can't read them, and
+    /// all devices of the wallet must use the same relays.
+    /// Arguments:
+    /// - `relays`: The relay URLs, e.g. `wss://relay.example.com`.
+    pub async fn with_nostr_sync_storage(&self, relays: Vec<String>) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder.clone().with_nostr_sync_storage(relays);
+    }
+}
+
+#[cfg(feature = "rpc-server")]
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl SdkBuilder {
+    /// Serves an embedded WebSocket RPC service on `addr` for as long as the
+    /// SDK is connected. Connections authenticate with `token` as a bearer
+    /// token. It exposes no spending calls, so bind it to a loopback or
+    /// private address only.
+    /// Arguments:
+    /// - `addr`: The socket address to listen on.
+    /// - `token`: The access token connections authenticate with.
+    pub async fn with_rpc_server(&self, addr: String, token: String) {
+        let mut builder = self.inner.lock().await;
+        *builder = builder.clone().with_rpc_server(addr, token);
+    }
+}
+
 #[cfg(feature = "mysql")]
 #[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
 impl SdkBuilder {
@@ -47,7 +47,24 @@ pub trait BitcoinChainService: Send + Sync {
     async fn get_transaction_hex(&self, txid: String) -> Result<String, ChainServiceError>;
     async fn get_outspend(&self, txid: String, vout: u32) -> Result<Outspend, ChainServiceError>;
     async fn broadcast_transaction(&self, tx: String) -> Result<(), ChainServiceError>;
+    /// Broadcasts a parent transaction together with its children, so that
+    /// a parent paying no fee is relayed along with the CPFP child paying for
+    /// it. Optional: without it, the SDK does not broadcast unilateral exit
+    /// transactions that need a CPFP child.
+    async fn broadcast_package(&self, txs: Vec<String>) -> Result<(), ChainServiceError> {
+        let _ = txs;
+        Err(ChainServiceError::Generic(
+            "Package broadcast is not supported by this chain service".to_string(),
+        ))
+    }
     async fn recommended_fees(&self) -> Result<RecommendedFees, ChainServiceError>;
+    /// The height of the chain tip. Optional: without it, the SDK reports
+    /// confirmed on-chain transactions without their number of confirmations.
+    async fn get_tip_height(&self) -> Result<u32, ChainServiceError> {
+        Err(ChainServiceError::Generic(
+            "Tip height is not supported by this chain service".to_string(),
+        ))
+    }
 }
 
 #[derive(Deserialize, Serialize, Clone, Debug, PartialEq, Eq)]
@@ -232,9 +232,14 @@ impl RestClientChainServiceInner {
         }
     }
 
-    async fn post(&self, url: &str, body: Option<String>) -> Result<String, ChainServiceError> {
+    async fn post(
+        &self,
+        url: &str,
+        body: Option<String>,
+        content_type: ContentType,
+    ) -> Result<String, ChainServiceError> {
         let mut headers: HashMap<String, String> = HashMap::new();
-        add_content_type_header(&mut headers, ContentType::TextPlain);
+        add_content_type_header(&mut headers, content_type);
         if let Some(basic_auth) = &self.basic_auth {
             add_basic_auth_header(&mut headers, &basic_auth.username, &basic_auth.password);
         }
@@ -352,10 +357,26 @@ impl RestClientChainServiceInner {
 
     async fn do_broadcast_transaction(&self, tx: String) -> Result<(), ChainServiceError> {
         let url = format!("{}{}", self.base_url, "/tx");
-        self.post(&url, Some(tx)).await?;
+        self.post(&url, Some(tx), ContentType::TextPlain).await?;
         Ok(())
     }
 
+    async fn do_broadcast_package(&self, txs: Vec<String>) -> Result<(), ChainServiceError> {
+        let url = format!("{}{}", self.base_url, "/txs/package");
+        let body =
+            serde_json::to_string(&txs).map_err(|e| ChainServiceError::Generic(e.to_string()))?;
+        self.post(&url, Some(body), ContentType::Json).await?;
+        Ok(())
+    }
+
+    async fn do_get_tip_height(&self) -> Result<u32, ChainServiceError> {
+        let height = self.get_response_text("/blocks/tip/height").await?;
+        height
+            .trim()
+            .parse()
+            .map_err(|e| ChainServiceError::Generic(format!("Invalid tip height {height}: {e}")))
+    }
+
     async fn do_recommended_fees(&self) -> Result<RecommendedFees, ChainServiceError> {
         match self.api_type {
             ChainApiType::Esplora => self.recommended_fees_esplora().await,
@@ -399,10 +420,20 @@ impl BitcoinChainService for RestClientChainService {
             .await
     }
 
+    async fn broadcast_package(&self, txs: Vec<String>) -> Result<(), ChainServiceError> {
+        self.run_on_runtime(|inner| async move { inner.do_broadcast_package(txs).await })
+            .await
+    }
+
     async fn recommended_fees(&self) -> Result<RecommendedFees, ChainServiceError> {
         self.run_on_runtime(|inner| async move { inner.do_recommended_fees().await })
             .await
     }
+
+    async fn get_tip_height(&self) -> Result<u32, ChainServiceError> {
+        self.run_on_runtime(|inner| async move { inner.do_get_tip_height().await })
+            .await
+    }
 }
 
 fn is_status_retryable(status: u16) -> bool {
@@ -2,7 +2,7 @@ use std::sync::Arc;
 
 use serde::{Deserialize, Serialize};
 
-use crate::ServiceConnectivityError;
+use crate::{ServiceConnectivityError, TokenMetadata};
 
 /// Trait covering fiat-related functionality
 #[cfg_attr(feature = "uniffi", uniffi::export(with_foreign))]
@@ -13,6 +13,38 @@ pub trait FiatService: Send + Sync {
 
     /// Get the live rates from the server.
     async fn fetch_fiat_rates(&self) -> Result<Vec<Rate>, ServiceConnectivityError>;
+
+    /// Get the rates of a currency between two unix timestamps, in seconds,
+    /// sorted by timestamp. Optional: without it, `fetch_historical_rates`
+    /// on the SDK fails.
+    async fn fetch_historical_rates(
+        &self,
+        currency: String,
+        from_timestamp: u64,
+        to_timestamp: u64,
+        resolution: RateResolution,
+    ) -> Result<Vec<HistoricalRate>, ServiceConnectivityError> {
+        let _ = (currency, from_timestamp, to_timestamp, resolution);
+        Err(ServiceConnectivityError::Other(
+            "Historical rates are not supported by this fiat service".to_string(),
+        ))
+    }
+}
+
+/// Trait providing the fiat prices of tokens.
+///
+/// Set it with `SdkBuilder::with_token_price_service`. By default tokens are
+/// priced by their Flashnet pool against bitcoin, converted to fiat with the
+/// rates of the [`FiatService`].
+#[cfg_attr(feature = "uniffi", uniffi::export(with_foreign))]
+#[macros::async_trait]
+pub trait TokenPriceService: Send + Sync {
+    /// Get the live rates of the tokens, in fiat units per whole token
+    /// (`10^decimals` base units). Tokens without a known price are left out.
+    async fn fetch_token_fiat_rates(
+        &self,
+        tokens: Vec<TokenMetadata>,
+    ) -> Result<Vec<TokenFiatRates>, ServiceConnectivityError>;
 }
 
 pub(crate) struct FiatServiceWrapper {
@@ -54,6 +86,25 @@ impl breez_sdk_common::fiat::FiatService for FiatServiceWrapper {
             .map(From::from)
             .collect())
     }
+
+    async fn fetch_historical_rates(
+        &self,
+        currency: String,
+        from_timestamp: u64,
+        to_timestamp: u64,
+        resolution: breez_sdk_common::fiat::RateResolution,
+    ) -> Result<
+        Vec<breez_sdk_common::fiat::HistoricalRate>,
+        breez_sdk_common::error::ServiceConnectivityError,
+    > {
+        Ok(self
+            .inner
+            .fetch_historical_rates(currency, from_timestamp, to_timestamp, resolution.into())
+            .await?
+            .into_iter()
+            .map(From::from)
+            .collect())
+    }
 }
 
 /// Wrapper around the [`CurrencyInfo`] of a fiat currency
@@ -115,6 +166,35 @@ pub struct Rate {
     pub value: f64,
 }
 
+/// The fiat rates of a token, in fiat units per whole token
+#[derive(Clone, Debug, Deserialize, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct TokenFiatRates {
+    pub token_identifier: String,
+    pub rates: Vec<Rate>,
+}
+
+/// Interval between the rates of a historical rate series
+#[derive(Clone, Copy, Debug, Deserialize, Eq, PartialEq, Serialize)]
+#[macros::derive_from(breez_sdk_common::fiat::RateResolution)]
+#[macros::derive_into(breez_sdk_common::fiat::RateResolution)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum RateResolution {
+    Hour,
+    Day,
+}
+
+/// Exchange rate of a currency at a point in time
+#[derive(Clone, Debug, Deserialize, Serialize)]
+#[macros::derive_from(breez_sdk_common::fiat::HistoricalRate)]
+#[macros::derive_into(breez_sdk_common::fiat::HistoricalRate)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct HistoricalRate {
+    /// Unix timestamp, in seconds
+    pub timestamp: u64,
+    pub value: f64,
+}
+
 /// Settings for the symbol representation of a currency
 #[derive(Clone, Debug, Deserialize, Serialize)]
 #[macros::derive_from(breez_sdk_common::fiat::Symbol)]
@@ -471,6 +471,8 @@ pub struct PaymentRequestSource {
 pub struct SilentPaymentAddressDetails {
     pub address: String,
     pub network: BitcoinNetwork,
+    pub scan_public_key: String,
+    pub spend_public_key: String,
     pub source: PaymentRequestSource,
 }
 
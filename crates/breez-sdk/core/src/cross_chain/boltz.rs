@@ -16,11 +16,8 @@ use boltz_client::{
 };
 use breez_sdk_common::fiat::FiatService;
 use breez_sdk_common::input::CrossChainAddressFamily;
+use platform_utils::tokio;
 use platform_utils::tokio::sync::OnceCell;
-use platform_utils::{
-    time::{SystemTime, UNIX_EPOCH},
-    tokio,
-};
 use spark_wallet::SparkWallet;
 use tokio::{select, sync::watch, time::sleep};
 use tracing::{debug, error, info, warn};
@@ -31,10 +28,10 @@ use super::{
     boltz_storage_adapter::PROVIDER_TAG_BOLTZ, derive_btc_leg_transfer_id,
 };
 use crate::{
-    ConversionInfo, ConversionStatus, CrossChainAddressDetails, Network, PaymentMetadata,
+    Clock, ConversionInfo, ConversionStatus, CrossChainAddressDetails, Network, PaymentMetadata,
     PaymentStatus, Storage,
     error::SdkError,
-    sdk::LightningSender,
+    sdk::{LightningSender, clock_now},
     utils::{
         payments::resolve_and_insert_payment_metadata,
         polling::{PollSchedule, poll_until},
@@ -60,6 +57,8 @@ pub(crate) struct BoltzService {
     /// by this provider so Boltz hold-invoice pays behave identically to
     /// direct LN sends.
     lightning_sender: Arc<LightningSender>,
+    /// Clock quote expiries are checked against, unset to use the system clock
+    clock: Option<Arc<dyn Clock>>,
 }
 
 impl BoltzService {
@@ -87,6 +86,7 @@ impl BoltzService {
         fiat_service: Arc<dyn FiatService>,
         lightning_sender: Arc<LightningSender>,
         shutdown_receiver: watch::Receiver<()>,
+        clock: Option<Arc<dyn Clock>>,
     ) -> Result<Option<Arc<dyn CrossChainService>>, SdkError> {
         let Some(config) = Self::default_client_config(network) else {
             return Ok(None);
@@ -112,6 +112,7 @@ impl BoltzService {
             storage,
             fiat_service,
             lightning_sender,
+            clock,
         });
         info!("Boltz service initialized");
         service.spawn_resume_monitor(shutdown_receiver);
@@ -625,7 +626,7 @@ impl CrossChainService for BoltzService {
         // display value (token base units on the conversion path) instead of sats.
         let invoice_amount_sats = *invoice_amount_sats;
 
-        validate_quote_expiry(&prepared.expires_at)?;
+        validate_quote_expiry(&prepared.expires_at, clock_now(self.clock.as_ref()))?;
 
         let transfer_id = Some(derive_btc_leg_transfer_id(
             idempotency_key.as_deref(),
@@ -821,14 +822,10 @@ async fn poll_to_terminal_or_fallback(
 /// at send time if the wall clock has passed it so the user sees a clean
 /// "quote expired, re-prepare" rather than a server-side error after the LN
 /// pay attempt.
-fn validate_quote_expiry(expires_at: &str) -> Result<(), SdkError> {
+fn validate_quote_expiry(expires_at: &str, now_secs: u64) -> Result<(), SdkError> {
     let exp_secs: u64 = expires_at
         .parse()
         .map_err(|e| SdkError::Generic(format!("Boltz: invalid expires_at {expires_at:?}: {e}")))?;
-    let now_secs = SystemTime::now()
-        .duration_since(UNIX_EPOCH)
-        .map_err(|_| SdkError::Generic("Failed to read current time".to_string()))?
-        .as_secs();
     if now_secs >= exp_secs {
         return Err(SdkError::InvalidInput(
             "Cross-chain quote has expired. Please re-prepare.".to_string(),
@@ -1203,6 +1200,8 @@ mod tests {
                 method: crate::PaymentMethod::Lightning,
                 details: None,
                 conversion_details: None,
+                fiat_value: None,
+                receive_metadata: None,
             }
         }
 
@@ -1304,26 +1303,23 @@ mod tests {
 
     // ---- validate_quote_expiry ----
 
+    const NOW_SECS: u64 = 1_700_000_000;
+
     #[test_all]
     fn validate_quote_expiry_accepts_future_unix_secs() {
-        use platform_utils::time::{SystemTime, UNIX_EPOCH};
-        let future = SystemTime::now()
-            .duration_since(UNIX_EPOCH)
-            .unwrap()
-            .as_secs()
-            .saturating_add(600);
-        assert!(validate_quote_expiry(&future.to_string()).is_ok());
+        let future = NOW_SECS.saturating_add(600);
+        assert!(validate_quote_expiry(&future.to_string(), NOW_SECS).is_ok());
     }
 
     #[test_all]
     fn validate_quote_expiry_rejects_past_unix_secs() {
-        let err = validate_quote_expiry("1000000000").unwrap_err();
+        let err = validate_quote_expiry("1000000000", NOW_SECS).unwrap_err();
         assert!(matches!(err, SdkError::InvalidInput(ref m) if m.contains("expired")));
     }
 
     #[test_all]
     fn validate_quote_expiry_rejects_malformed() {
-        let err = validate_quote_expiry("not-a-number").unwrap_err();
+        let err = validate_quote_expiry("not-a-number", NOW_SECS).unwrap_err();
         assert!(matches!(err, SdkError::Generic(ref m) if m.contains("invalid expires_at")));
     }
 }
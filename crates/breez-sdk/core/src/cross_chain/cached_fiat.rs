@@ -13,13 +13,14 @@ use std::time::Duration;
 
 use breez_sdk_common::{
     error::ServiceConnectivityError,
-    fiat::{FiatCurrency, FiatService, Rate},
+    fiat::{FiatCurrency, FiatService, HistoricalRate, Rate, RateResolution},
 };
-use platform_utils::time::{SystemTime, UNIX_EPOCH};
 use serde::{Serialize, de::DeserializeOwned};
 use tokio::sync::Mutex;
 use tracing::trace;
 
+use crate::{Clock, sdk::clock_now_ms};
+
 /// Default cache TTL. Long enough to amortize repeated fetches in a session,
 /// short enough to bound fiat-rate drift between estimate and quote.
 pub(crate) const DEFAULT_FIAT_CACHE_TTL: Duration = Duration::from_mins(1);
@@ -32,6 +33,8 @@ pub(crate) struct CachedFiatService {
     inner: Arc<dyn FiatService>,
     ttl_ms: u128,
     cache: Mutex<HashMap<&'static str, CachedEntry>>,
+    /// Clock the entries expire by, unset to use the system clock
+    clock: Option<Arc<dyn Clock>>,
 }
 
 struct CachedEntry {
@@ -45,9 +48,18 @@ impl CachedFiatService {
             inner,
             ttl_ms: ttl.as_millis(),
             cache: Mutex::new(HashMap::new()),
+            clock: None,
         }
     }
 
+    /// Expires the entries by `clock`, e.g. the one set with
+    /// `SdkBuilder::with_clock`, instead of the system clock.
+    #[must_use]
+    pub(crate) fn with_clock(mut self, clock: Option<Arc<dyn Clock>>) -> Self {
+        self.clock = clock;
+        self
+    }
+
     /// Look up `key` or invoke `fetch` to populate it. Single-flight: the lock
     /// is intentionally held across `fetch().await` so concurrent cold callers
     /// serialize and the second arrival reads the fresh cache instead of
@@ -64,7 +76,7 @@ impl CachedFiatService {
         T: Serialize + DeserializeOwned,
     {
         let mut cache = self.cache.lock().await;
-        let now = now_ms();
+        let now = clock_now_ms(self.clock.as_ref());
         if let Some(entry) = cache.get(key)
             && entry.expires_at_ms > now
         {
@@ -93,12 +105,6 @@ impl CachedFiatService {
     }
 }
 
-fn now_ms() -> u128 {
-    SystemTime::now()
-        .duration_since(UNIX_EPOCH)
-        .map_or(0, |d| d.as_millis())
-}
-
 #[macros::async_trait]
 impl FiatService for CachedFiatService {
     async fn fetch_fiat_currencies(&self) -> Result<Vec<FiatCurrency>, ServiceConnectivityError> {
@@ -110,6 +116,18 @@ impl FiatService for CachedFiatService {
         self.get_or_fetch(RATES_KEY, || self.inner.fetch_fiat_rates())
             .await
     }
+
+    async fn fetch_historical_rates(
+        &self,
+        currency: String,
+        from_timestamp: u64,
+        to_timestamp: u64,
+        resolution: RateResolution,
+    ) -> Result<Vec<HistoricalRate>, ServiceConnectivityError> {
+        self.inner
+            .fetch_historical_rates(currency, from_timestamp, to_timestamp, resolution)
+            .await
+    }
 }
 
 #[cfg(test)]
@@ -16,7 +16,7 @@ use flashnet::orchestra::{
     RouteAsset, StatusResponse, SubmitResponse,
 };
 use flashnet::{FlashnetError, OrchestraClient, OrchestraConfig, OrchestraConfigResolver};
-use platform_utils::time::{Duration, SystemTime, UNIX_EPOCH};
+use platform_utils::time::Duration;
 use platform_utils::tokio;
 use spark_wallet::SparkWallet;
 use tokio::{
@@ -27,7 +27,8 @@ use tracing::{Instrument, debug, error, info};
 
 use crate::error::SdkError;
 use crate::persist::{ConversionFilter, StorageListPaymentsRequest, StoragePaymentDetailsFilter};
-use crate::{ConversionInfo, ConversionStatus, PaymentDetails, Storage};
+use crate::sdk::clock_now;
+use crate::{Clock, ConversionInfo, ConversionStatus, PaymentDetails, Storage};
 
 use super::{
     CrossChainFeeMode, CrossChainPrepared, CrossChainProvider, CrossChainProviderContext,
@@ -98,6 +99,8 @@ pub(crate) struct OrchestraService {
     storage: Arc<dyn Storage>,
     fiat_service: Arc<dyn FiatService>,
     monitor_trigger: broadcast::Sender<()>,
+    /// Clock quote expiries are checked against, unset to use the system clock
+    clock: Option<Arc<dyn Clock>>,
 }
 
 impl OrchestraService {
@@ -107,6 +110,7 @@ impl OrchestraService {
         storage: Arc<dyn Storage>,
         fiat_service: Arc<dyn FiatService>,
         shutdown_receiver: watch::Receiver<()>,
+        clock: Option<Arc<dyn Clock>>,
     ) -> Self {
         let client = Arc::new(OrchestraClient::new(
             config_resolver,
@@ -120,6 +124,7 @@ impl OrchestraService {
             storage,
             fiat_service,
             monitor_trigger: monitor_trigger.clone(),
+            clock,
         };
         info!("Orchestra service initialized");
         service.spawn_monitor(shutdown_receiver, &monitor_trigger);
@@ -606,7 +611,7 @@ impl CrossChainService for OrchestraService {
         // display value (token base units on the conversion path) instead.
         let deposit_amount = *deposit_amount;
 
-        validate_quote_expiry(&prepared.expires_at)?;
+        validate_quote_expiry(&prepared.expires_at, clock_now(self.clock.as_ref()))?;
 
         let transfer_id = Some(derive_btc_leg_transfer_id(
             idempotency_key.as_deref(),
@@ -903,15 +908,11 @@ fn apply_terminal_status(
 
 /// Rejects an expired quote at send time so the caller can re-prepare
 /// instead of getting a less helpful error from `/submit`.
-fn validate_quote_expiry(expires_at: &str) -> Result<(), SdkError> {
+fn validate_quote_expiry(expires_at: &str, now_secs: u64) -> Result<(), SdkError> {
     let exp = DateTime::parse_from_rfc3339(expires_at).map_err(|e| {
         SdkError::Generic(format!("Orchestra: invalid expires_at {expires_at:?}: {e}"))
     })?;
     let exp_secs = u64::try_from(exp.timestamp()).unwrap_or(0);
-    let now_secs = SystemTime::now()
-        .duration_since(UNIX_EPOCH)
-        .map_err(|_| SdkError::Generic("Failed to read current time".to_string()))?
-        .as_secs();
     if now_secs >= exp_secs {
         return Err(SdkError::InvalidInput(
             "Cross-chain quote has expired. Please re-prepare.".to_string(),
@@ -1250,6 +1251,8 @@ mod tests {
             method,
             details: Some(details),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -1696,30 +1699,27 @@ mod tests {
 
     // ---- validate_quote_expiry ----
 
+    const NOW_SECS: u64 = 1_700_000_000;
+
     #[test_all]
     fn validate_quote_expiry_accepts_future_rfc3339() {
-        use platform_utils::time::{SystemTime, UNIX_EPOCH};
-        let future_secs = SystemTime::now()
-            .duration_since(UNIX_EPOCH)
-            .unwrap()
-            .as_secs()
-            .saturating_add(600);
+        let future_secs = NOW_SECS.saturating_add(600);
         let dt =
             chrono::DateTime::<chrono::Utc>::from_timestamp(future_secs.cast_signed(), 0).unwrap();
         let s = dt.to_rfc3339();
-        assert!(validate_quote_expiry(&s).is_ok());
+        assert!(validate_quote_expiry(&s, NOW_SECS).is_ok());
     }
 
     #[test_all]
     fn validate_quote_expiry_rejects_past_rfc3339() {
         // 2001-09-09 — well in the past.
-        let err = validate_quote_expiry("2001-09-09T01:46:40Z").unwrap_err();
+        let err = validate_quote_expiry("2001-09-09T01:46:40Z", NOW_SECS).unwrap_err();
         assert!(matches!(err, SdkError::InvalidInput(ref m) if m.contains("expired")));
     }
 
     #[test_all]
     fn validate_quote_expiry_rejects_malformed() {
-        let err = validate_quote_expiry("not-a-timestamp").unwrap_err();
+        let err = validate_quote_expiry("not-a-timestamp", NOW_SECS).unwrap_err();
         assert!(matches!(err, SdkError::Generic(ref m) if m.contains("invalid expires_at")));
     }
 
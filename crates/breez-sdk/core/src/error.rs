@@ -1,5 +1,5 @@
 use crate::{
-    Fee,
+    Fee, PaymentRail, SpendingCapScope,
     lnurl::LnurlServerError,
     persist::{self},
 };
@@ -51,6 +51,9 @@ pub enum SdkError {
         required_fee_rate_sat_per_vbyte: u64,
     },
 
+    #[error("Max fee exceeded: fee of {fee_sats} sats is above the maximum of {max_fee_sats} sats")]
+    MaxFeeExceeded { fee_sats: u64, max_fee_sats: u64 },
+
     #[error("Missing utxo: {tx}:{vout}")]
     MissingUtxo { tx: String, vout: u32 },
 
@@ -79,6 +82,49 @@ pub enum SdkError {
     #[error("Funding UTXO {txid}:{vout} was spent by an unrelated transaction")]
     FundingUtxoConflict { txid: String, vout: u32 },
 
+    /// The Spark operators or service provider don't match the configured
+    /// [`OperatorAllowlistConfig`](crate::OperatorAllowlistConfig).
+    #[error("Operator not allowed: {0}")]
+    OperatorNotAllowed(String),
+
+    /// A previous call with the same idempotency key did not complete, so its
+    /// outcome is unknown and the operation is not retried.
+    #[error("Idempotency key in use: {0}")]
+    IdempotencyKeyInUse(String),
+
+    /// A payment middleware rejected the send or receive flow, or the send
+    /// approver rejected a payment.
+    #[error("Payment rejected: {0}")]
+    PaymentRejected(String),
+
+    /// The wallet is frozen, so funds can't be sent out of it until it is
+    /// unfrozen.
+    #[error("Wallet is frozen")]
+    WalletFrozen,
+
+    /// Too many wrong passwords, PINs or backup words were entered. The next
+    /// attempt is accepted after `retry_after_secs`.
+    #[error("Too many failed attempts, retry in {retry_after_secs} seconds")]
+    TooManyAttempts { retry_after_secs: u64 },
+
+    /// The issuer approval key didn't approve an issuer operation, see
+    /// [`IssuerApprovalConfig`](crate::IssuerApprovalConfig).
+    #[error("Issuer approval failed: {0}")]
+    IssuerApprovalFailed(String),
+
+    /// The payment uses a rail missing from
+    /// [`Config::enabled_rails`](crate::Config::enabled_rails).
+    #[error("Payment rail disabled: {rail}")]
+    PaymentRailDisabled { rail: PaymentRail },
+
+    /// The payment would exceed one of
+    /// [`Config::spending_caps`](crate::Config::spending_caps).
+    #[error("Spending cap exceeded for {scope}: {remaining} remaining")]
+    SpendingCapExceeded {
+        scope: SpendingCapScope,
+        remaining: u128,
+    },
+
     #[error("Error: {0}")]
     Generic(String),
 }
@@ -224,6 +270,9 @@ impl From<SparkWalletError> for SdkError {
                 txid,
                 vout,
             }) => SdkError::FundingUtxoConflict { txid, vout },
+            SparkWalletError::ServiceError(spark_wallet::ServiceError::TransferObserverError(
+                spark_wallet::TransferObserverError::Rejected(reason),
+            )) => SdkError::PaymentRejected(reason),
             _ => SdkError::SparkError(e.to_string()),
         }
     }
@@ -257,6 +306,16 @@ impl From<ServiceConnectivityError> for SdkError {
     }
 }
 
+impl From<crate::PluginError> for SdkError {
+    fn from(value: crate::PluginError) -> Self {
+        match value {
+            crate::PluginError::ServiceConnectivity(msg) => SdkError::NetworkError(msg),
+            crate::PluginError::InsufficientFunds => SdkError::InsufficientFunds,
+            crate::PluginError::Generic(msg) => SdkError::Generic(msg),
+        }
+    }
+}
+
 impl From<LnurlServerError> for SdkError {
     fn from(value: LnurlServerError) -> Self {
         match value {
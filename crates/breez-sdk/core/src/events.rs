@@ -10,7 +10,12 @@ use tokio::sync::{Mutex, RwLock};
 use tracing::info;
 use uuid::Uuid;
 
-use crate::{DepositInfo, LightningAddressInfo, Payment, sdk::RuntimeEvent};
+use crate::{
+    ArbitratedEscrow, DepositInfo, DepositRefund, Job, LightningAddressInfo, OnchainTransaction,
+    Payment, PaymentHandle, PaymentProgressStage, PaymentStream, ServiceStatusReport, SyncConflict,
+    SyncProgress, TokenMetadata, TokenRecipientResult, UnilateralExitLeafProgress,
+    sdk::RuntimeEvent,
+};
 
 /// Events emitted by the SDK
 #[allow(clippy::large_enum_variant)]
@@ -51,10 +56,110 @@ pub enum SdkEvent {
     NewDeposits {
         new_deposits: Vec<DepositInfo>,
     },
+    /// Emitted when a payment is received towards a Spark invoice that accepts
+    /// partial payments
+    PartialInvoicePaymentProgress {
+        /// The invoice the payment was made towards
+        invoice: String,
+        /// The id of the received payment
+        payment_id: String,
+        /// Total amount received towards the invoice so far.
+        /// Denominated in sats if token identifier is empty, otherwise in the token base units
+        received_amount: u128,
+        /// The amount the invoice is complete at
+        target_amount: u128,
+        /// The token identifier if the invoice is for a token
+        token_identifier: Option<String>,
+        /// Whether the received amount has reached the target amount
+        completed: bool,
+    },
+    /// Emitted when a payment started with `send_payment_async` reaches a new stage
+    PaymentProgress {
+        handle: PaymentHandle,
+        stage: PaymentProgressStage,
+    },
+    /// Emitted when the funds of an expired outgoing HTLC were reclaimed
+    HtlcRefunded {
+        payment: Payment,
+    },
+    /// Emitted when an arbitrated escrow is created or changes status
+    ArbitratedEscrowUpdated {
+        escrow: ArbitratedEscrow,
+    },
+    /// Emitted when a deposit refund was replaced by one paying a higher fee
+    RefundFeeBumped {
+        refund: DepositRefund,
+        replaced_tx_id: String,
+    },
+    /// Emitted when a deposit refund transaction is confirmed
+    RefundConfirmed {
+        refund: DepositRefund,
+    },
+    /// Emitted when a payment stream sends a transfer or stops
+    PaymentStreamUpdated {
+        stream: PaymentStream,
+    },
+    /// Emitted when the number of incoming transfers waiting to be claimed
+    /// changes, see [`Config::max_claims_per_second`](crate::Config::max_claims_per_second)
+    ClaimQueueChanged {
+        queue_depth: u32,
+    },
+    /// Emitted when a leaf of the tracked unilateral exit moves to a new stage
+    UnilateralExitProgress {
+        leaf: UnilateralExitLeafProgress,
+    },
+    /// Emitted when a monitored on-chain transaction changes status, see
+    /// `list_onchain_transactions`
+    OnchainTransactionUpdated {
+        transaction: OnchainTransaction,
+    },
+    /// Emitted for each token balance converted to sats by a sweep, see
+    /// [`Config::token_sweep_policy`](crate::Config::token_sweep_policy)
+    TokenSweep {
+        // Named with `sweep` prefix to avoid collision with `event` keyword in C#
+        sweep_event: TokenSweepEvent,
+    },
+    /// Emitted as a token distribution started with `distribute_tokens` sends
+    /// each chunk and when it finishes
+    TokenDistribution {
+        // Named with `distribution` prefix to avoid collision with `event` keyword in C#
+        distribution_event: TokenDistributionEvent,
+    },
+    /// Emitted when a job started with `start_job` makes progress or finishes
+    JobUpdated {
+        job: Job,
+    },
+    /// Emitted when the metadata of a token held by the wallet, like its name,
+    /// ticker or decimals, changed since it was cached
+    TokenMetadataChanged {
+        metadata: TokenMetadata,
+    },
+    /// Emitted when the status of the Spark network changes, e.g. when a
+    /// payment rail is degraded or recovers
+    ServiceStatusChanged {
+        status: ServiceStatusReport,
+    },
+    /// Emitted as each stage of a wallet sync finishes
+    SyncProgress {
+        progress: SyncProgress,
+    },
+    /// Emitted when a change received through real-time sync conflicted with
+    /// a local change that wasn't synced yet, see [`SyncConflict`]
+    SyncConflictResolved {
+        conflict: SyncConflict,
+    },
+    /// Emitted when the LNURL server issued a Nostr zap receipt for a zap
+    /// received through the lightning address
+    NostrZapReceiptIssued {
+        payment_hash: String,
+        /// The zap receipt event (kind 9735) as JSON
+        zap_receipt: String,
+    },
 }
 
 impl SdkEvent {
-    pub(crate) fn from_payment(payment: Payment) -> Self {
+    pub(crate) fn from_payment(mut payment: Payment) -> Self {
+        payment.fill_receive_metadata();
         match payment.status {
             crate::PaymentStatus::Completed => SdkEvent::PaymentSucceeded { payment },
             crate::PaymentStatus::Pending => SdkEvent::PaymentPending { payment },
@@ -93,10 +198,155 @@ impl fmt::Display for SdkEvent {
             SdkEvent::NewDeposits { new_deposits } => {
                 write!(f, "NewDeposits: {new_deposits:?}")
             }
+            SdkEvent::PartialInvoicePaymentProgress {
+                payment_id,
+                received_amount,
+                target_amount,
+                completed,
+                ..
+            } => {
+                write!(
+                    f,
+                    "PartialInvoicePaymentProgress: {payment_id} {received_amount}/{target_amount} completed: {completed}"
+                )
+            }
+            SdkEvent::PaymentProgress { handle, stage } => {
+                write!(f, "PaymentProgress: {} {stage:?}", handle.id)
+            }
+            SdkEvent::HtlcRefunded { payment } => {
+                write!(f, "HtlcRefunded: {payment:?}")
+            }
+            SdkEvent::ArbitratedEscrowUpdated { escrow } => {
+                write!(
+                    f,
+                    "ArbitratedEscrowUpdated: {} {}",
+                    escrow.id, escrow.status
+                )
+            }
+            SdkEvent::RefundFeeBumped {
+                refund,
+                replaced_tx_id,
+            } => {
+                write!(
+                    f,
+                    "RefundFeeBumped: {replaced_tx_id} -> {} ({} sats fee)",
+                    refund.tx_id, refund.fee_sats
+                )
+            }
+            SdkEvent::RefundConfirmed { refund } => {
+                write!(f, "RefundConfirmed: {}", refund.tx_id)
+            }
+            SdkEvent::PaymentStreamUpdated { stream } => {
+                write!(
+                    f,
+                    "PaymentStreamUpdated: {} {} {}/{} sats",
+                    stream.id, stream.status, stream.sent_sats, stream.max_amount_sats
+                )
+            }
+            SdkEvent::ClaimQueueChanged { queue_depth } => {
+                write!(f, "ClaimQueueChanged: {queue_depth}")
+            }
+            SdkEvent::UnilateralExitProgress { leaf } => {
+                write!(
+                    f,
+                    "UnilateralExitProgress: {} {:?}",
+                    leaf.leaf_id, leaf.stage
+                )
+            }
+            SdkEvent::OnchainTransactionUpdated { transaction } => {
+                write!(
+                    f,
+                    "OnchainTransactionUpdated: {} {:?}",
+                    transaction.tx_id, transaction.status
+                )
+            }
+            SdkEvent::TokenSweep { sweep_event } => {
+                write!(f, "TokenSweep: {sweep_event:?}")
+            }
+            SdkEvent::TokenDistribution { distribution_event } => {
+                write!(f, "TokenDistribution: {distribution_event:?}")
+            }
+            SdkEvent::JobUpdated { job } => {
+                write!(f, "JobUpdated: {} {:?}", job.id, job.status)
+            }
+            SdkEvent::TokenMetadataChanged { metadata } => {
+                write!(f, "TokenMetadataChanged: {}", metadata.identifier)
+            }
+            SdkEvent::ServiceStatusChanged { status } => {
+                write!(f, "ServiceStatusChanged: {:?}", status.status)
+            }
+            SdkEvent::SyncProgress { progress } => {
+                write!(
+                    f,
+                    "SyncProgress: {:?} {}%",
+                    progress.stage, progress.percent
+                )
+            }
+            SdkEvent::SyncConflictResolved { conflict } => {
+                write!(
+                    f,
+                    "SyncConflictResolved: {:?} {} {:?}",
+                    conflict.record_type, conflict.record_id, conflict.kept_local_fields
+                )
+            }
+            SdkEvent::NostrZapReceiptIssued { payment_hash, .. } => {
+                write!(f, "NostrZapReceiptIssued: {payment_hash}")
+            }
         }
     }
 }
 
+/// Progress of a sweep of token balances into sats. All events of a sweep
+/// carry the same `sweep_id`, linking its conversions together.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum TokenSweepEvent {
+    /// A token balance was converted to sats.
+    Swept {
+        sweep_id: String,
+        token_identifier: String,
+        /// The converted amount, in token base units
+        amount: u128,
+        /// The id of the payment sending the tokens
+        sent_payment_id: String,
+        /// The id of the payment receiving the sats
+        received_payment_id: String,
+    },
+    /// Converting a token balance failed. It's retried on the next sweep.
+    Failed {
+        sweep_id: String,
+        token_identifier: String,
+        error: String,
+    },
+    /// The sweep finished after converting `swept_count` balances.
+    Completed { sweep_id: String, swept_count: u32 },
+}
+
+/// Progress of a token distribution. All events of a distribution carry the
+/// same `distribution_id`.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum TokenDistributionEvent {
+    /// A chunk of recipients was processed.
+    Progress {
+        distribution_id: String,
+        /// The number of recipients processed so far, including those
+        /// processed before the distribution was resumed
+        processed_count: u32,
+        total_count: u32,
+        /// The results of the recipients of the chunk
+        results: Vec<TokenRecipientResult>,
+    },
+    /// The distribution finished. Failed recipients are retried when the
+    /// distribution is resumed.
+    Completed {
+        distribution_id: String,
+        sent_count: u32,
+        failed_count: u32,
+        unknown_count: u32,
+    },
+}
+
 #[derive(Debug, Clone, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
 pub enum AutoOptimizationEvent {
@@ -1041,6 +1291,8 @@ mod tests {
             method: crate::PaymentMethod::Spark,
             details: None,
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -1316,4 +1568,48 @@ mod tests {
 
         assert_eq!(count.load(Ordering::Relaxed), 2); // Now count should be 2
     }
+
+    #[macros::test_all]
+    fn test_payment_event_includes_receive_metadata() {
+        let mut payment = test_payment();
+        payment.method = crate::PaymentMethod::Lightning;
+        payment.details = Some(crate::PaymentDetails::Lightning {
+            description: None,
+            invoice: "lnbc1".to_string(),
+            destination_pubkey: "pubkey".to_string(),
+            htlc_details: crate::SparkHtlcDetails {
+                payment_hash: "hash".to_string(),
+                preimage: None,
+                expiry_time: 0,
+                status: crate::SparkHtlcStatus::PreimageShared,
+            },
+            lnurl_pay_info: None,
+            lnurl_withdraw_info: None,
+            lnurl_receive_metadata: Some(crate::LnurlReceiveMetadata {
+                nostr_zap_request: Some(
+                    r#"{"kind":9734,"pubkey":"abcd","content":"Great post"}"#.to_string(),
+                ),
+                nostr_zap_receipt: None,
+                sender_comment: Some("Thanks!".to_string()),
+                lightning_address: None,
+            }),
+            conversion_info: None,
+        });
+
+        let SdkEvent::PaymentSucceeded { payment } = SdkEvent::from_payment(payment) else {
+            panic!("Expected a PaymentSucceeded event");
+        };
+        let metadata = payment.receive_metadata.expect("receive metadata");
+        assert_eq!(metadata.sender_comment.as_deref(), Some("Thanks!"));
+        assert_eq!(metadata.payer_identity.as_deref(), Some("abcd"));
+        assert_eq!(metadata.payer_note.as_deref(), Some("Great post"));
+    }
+
+    #[macros::test_all]
+    fn test_payment_event_without_receive_metadata() {
+        let SdkEvent::PaymentSucceeded { payment } = SdkEvent::from_payment(test_payment()) else {
+            panic!("Expected a PaymentSucceeded event");
+        };
+        assert!(payment.receive_metadata.is_none());
+    }
 }
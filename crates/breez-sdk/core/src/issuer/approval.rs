@@ -0,0 +1,211 @@
+use std::{str::FromStr, sync::Arc};
+
+use bitcoin::{
+    hashes::{Hash, sha256},
+    secp256k1::{Message, PublicKey, Secp256k1},
+};
+
+use crate::{IssuerApprovalConfig, IssuerApprovalRequest, IssuerApprovalSigner, SdkError};
+
+const APPROVAL_MESSAGE_PREFIX: &str = "breez-issuer-approval";
+
+/// An issuer operation that needs the approval of the issuer approval key.
+pub(crate) enum IssuerOperation {
+    Mint { amount: u128 },
+    Burn { amount: u128 },
+    Freeze { address: String },
+    Unfreeze { address: String },
+}
+
+impl IssuerOperation {
+    /// The operation and its parameters, as shown to the approval signer
+    fn operation(&self) -> String {
+        match self {
+            IssuerOperation::Mint { amount } => format!("mint:{amount}"),
+            IssuerOperation::Burn { amount } => format!("burn:{amount}"),
+            IssuerOperation::Freeze { address } => format!("freeze:{address}"),
+            IssuerOperation::Unfreeze { address } => format!("unfreeze:{address}"),
+        }
+    }
+
+    /// The message the approval key signs, binding the approval to the
+    /// issuer, the token and the operation parameters.
+    fn approval_message(&self, issuer_public_key: &PublicKey, token_identifier: &str) -> String {
+        format!(
+            "{APPROVAL_MESSAGE_PREFIX}:{issuer_public_key}:{token_identifier}:{}",
+            self.operation()
+        )
+    }
+}
+
+/// Asks the issuer approval key to approve issuer operations, see
+/// [`SdkBuilder::with_issuer_approver`](crate::SdkBuilder::with_issuer_approver).
+///
+/// The approval is only checked here, before the operation is sent. Nothing
+/// binds it to the token transaction, so the Spark operators don't enforce it.
+pub(crate) struct IssuerApprover {
+    signer: Arc<dyn IssuerApprovalSigner>,
+    public_key: PublicKey,
+    derivation_path: String,
+}
+
+impl IssuerApprover {
+    pub(crate) fn new(
+        signer: Arc<dyn IssuerApprovalSigner>,
+        config: IssuerApprovalConfig,
+    ) -> Result<Self, SdkError> {
+        let public_key = PublicKey::from_str(&config.public_key).map_err(|_| {
+            SdkError::InvalidInput("Invalid issuer approval public key".to_string())
+        })?;
+        Ok(Self {
+            signer,
+            public_key,
+            derivation_path: config.derivation_path,
+        })
+    }
+
+    /// Has the approval signer sign the SHA256 hash of the approval message
+    /// of the operation, and checks the signature against the configured
+    /// public key. Fails with [`SdkError::IssuerApprovalFailed`] if the signer
+    /// refuses to sign or signs with a different key.
+    pub(crate) async fn approve(
+        &self,
+        issuer_public_key: &PublicKey,
+        token_identifier: &str,
+        operation: &IssuerOperation,
+    ) -> Result<(), SdkError> {
+        let message = operation.approval_message(issuer_public_key, token_identifier);
+        let digest = sha256::Hash::hash(message.as_bytes());
+        let signature = self
+            .signer
+            .sign_approval(IssuerApprovalRequest {
+                issuer_public_key: issuer_public_key.to_string(),
+                token_identifier: token_identifier.to_string(),
+                operation: operation.operation(),
+                message,
+                derivation_path: self.derivation_path.clone(),
+            })
+            .await
+            .map_err(|e| SdkError::IssuerApprovalFailed(e.to_string()))?
+            .to_signature()
+            .map_err(|e| SdkError::IssuerApprovalFailed(e.to_string()))?;
+        Secp256k1::verification_only()
+            .verify_ecdsa(
+                &Message::from_digest(digest.to_byte_array()),
+                &signature,
+                &self.public_key,
+            )
+            .map_err(|_| {
+                SdkError::IssuerApprovalFailed(
+                    "Signature doesn't match the issuer approval public key".to_string(),
+                )
+            })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::sync::Mutex;
+
+    use bitcoin::secp256k1::SecretKey;
+    use macros::test_all;
+
+    use super::*;
+    use crate::{SignerError, signer::external_types::EcdsaSignatureBytes};
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_approval_message() {
+        let issuer = PublicKey::from_str(
+            "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+        )
+        .unwrap();
+        assert_eq!(
+            IssuerOperation::Mint { amount: 100 }.approval_message(&issuer, "btkn1"),
+            "breez-issuer-approval:0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798:btkn1:mint:100"
+        );
+        assert_eq!(
+            IssuerOperation::Freeze {
+                address: "sp1addr".to_string()
+            }
+            .approval_message(&issuer, "btkn1"),
+            "breez-issuer-approval:0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798:btkn1:freeze:sp1addr"
+        );
+    }
+
+    /// Signs every approval with its key and records the requests
+    struct MockApprovalSigner {
+        secret_key: SecretKey,
+        requests: Mutex<Vec<IssuerApprovalRequest>>,
+    }
+
+    #[macros::async_trait]
+    impl IssuerApprovalSigner for MockApprovalSigner {
+        async fn sign_approval(
+            &self,
+            request: IssuerApprovalRequest,
+        ) -> Result<EcdsaSignatureBytes, SignerError> {
+            let digest = sha256::Hash::hash(request.message.as_bytes());
+            let signature = Secp256k1::new().sign_ecdsa(
+                &Message::from_digest(digest.to_byte_array()),
+                &self.secret_key,
+            );
+            self.requests.lock().unwrap().push(request);
+            Ok(EcdsaSignatureBytes::from_signature(&signature))
+        }
+    }
+
+    #[macros::async_test_all]
+    async fn test_approve() {
+        let issuer = PublicKey::from_str(
+            "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+        )
+        .unwrap();
+        let secret_key = SecretKey::from_slice(&[7; 32]).unwrap();
+        let signer = Arc::new(MockApprovalSigner {
+            secret_key,
+            requests: Mutex::new(Vec::new()),
+        });
+        let approver = IssuerApprover::new(
+            signer.clone(),
+            IssuerApprovalConfig {
+                public_key: secret_key.public_key(&Secp256k1::new()).to_string(),
+                derivation_path: "m/0".to_string(),
+            },
+        )
+        .unwrap();
+        approver
+            .approve(&issuer, "btkn1", &IssuerOperation::Mint { amount: 100 })
+            .await
+            .unwrap();
+        {
+            let requests = signer.requests.lock().unwrap();
+            assert_eq!(requests.len(), 1);
+            assert_eq!(requests[0].operation, "mint:100");
+            assert_eq!(requests[0].token_identifier, "btkn1");
+            assert_eq!(requests[0].derivation_path, "m/0");
+            assert_eq!(
+                requests[0].message,
+                IssuerOperation::Mint { amount: 100 }.approval_message(&issuer, "btkn1")
+            );
+        }
+
+        // A signature by another key is refused
+        let other_approver = IssuerApprover::new(
+            signer,
+            IssuerApprovalConfig {
+                public_key: issuer.to_string(),
+                derivation_path: "m/0".to_string(),
+            },
+        )
+        .unwrap();
+        assert!(matches!(
+            other_approver
+                .approve(&issuer, "btkn1", &IssuerOperation::Burn { amount: 1 })
+                .await,
+            Err(SdkError::IssuerApprovalFailed(_))
+        ));
+    }
+}
@@ -1,5 +1,9 @@
+pub(crate) mod approval;
 mod models;
 mod sdk;
+mod stats;
+
+pub(crate) use approval::IssuerApprover;
 
 pub use models::*;
 pub use sdk::TokenIssuer;
@@ -1,4 +1,58 @@
-use serde::Serialize;
+use serde::{Deserialize, Serialize};
+
+use crate::{error::SignerError, signer::external_types::EcdsaSignatureBytes};
+
+/// Requires mints, burns, freezes and unfreezes of the issuer token to be
+/// approved by an additional key, see `SdkBuilder::with_issuer_approver`
+#[derive(Clone, Debug, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct IssuerApprovalConfig {
+    /// The hex encoded public key of the approval key
+    pub public_key: String,
+    /// The derivation path of the approval key in the approval signer
+    pub derivation_path: String,
+}
+
+/// An issuer operation to be approved by the [`IssuerApprovalSigner`]
+#[derive(Clone, Debug, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct IssuerApprovalRequest {
+    /// The hex encoded identity public key of the issuer
+    pub issuer_public_key: String,
+    /// The identifier of the issuer token
+    pub token_identifier: String,
+    /// The operation: `mint:<amount>`, `burn:<amount>`, `freeze:<address>` or
+    /// `unfreeze:<address>`
+    pub operation: String,
+    /// The message to sign,
+    /// `breez-issuer-approval:<issuer public key>:<token identifier>:<operation>`
+    pub message: String,
+    /// The derivation path of the approval key, from the [`IssuerApprovalConfig`]
+    pub derivation_path: String,
+}
+
+/// This interface is used to sign the approvals of issuer operations with the issuer approval
+/// key, see `SdkBuilder::with_issuer_approver`.
+///
+/// It receives the operation in plaintext, so an HSM or a signing service can apply its own policy
+/// to it before signing. The approval is checked by the SDK before the operation is sent. It is
+/// not enforced by the Spark operators.
+#[cfg_attr(
+    feature = "uniffi",
+    uniffi::export(with_foreign, async_runtime = "tokio")
+)]
+#[macros::async_trait]
+pub trait IssuerApprovalSigner: Send + Sync {
+    /// Signs the SHA256 hash of `request.message` using ECDSA with the approval key. Returning an
+    /// error refuses the operation.
+    ///
+    /// # Returns
+    /// 64-byte compact ECDSA signature, or a `SignerError`
+    async fn sign_approval(
+        &self,
+        request: IssuerApprovalRequest,
+    ) -> Result<EcdsaSignatureBytes, SignerError>;
+}
 
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 #[derive(Debug, Serialize)]
@@ -14,12 +68,22 @@ pub struct CreateIssuerTokenRequest {
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct MintIssuerTokenRequest {
     pub amount: u128,
+    /// If set, providing the same idempotency key for multiple requests will ensure that only one
+    /// mint is made. If an idempotency key is re-used, the same payment will be returned.
+    /// The idempotency key must be a valid UUID.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub idempotency_key: Option<String>,
 }
 
 #[derive(Debug, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct BurnIssuerTokenRequest {
     pub amount: u128,
+    /// If set, providing the same idempotency key for multiple requests will ensure that only one
+    /// burn is made. If an idempotency key is re-used, the same payment will be returned.
+    /// The idempotency key must be a valid UUID.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub idempotency_key: Option<String>,
 }
 
 #[derive(Debug, Serialize)]
@@ -65,3 +129,109 @@ impl From<spark_wallet::FreezeIssuerTokenResponse> for UnfreezeIssuerTokenRespon
         }
     }
 }
+
+/// A recipient of a token distribution
+#[derive(Clone, Debug, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct TokenRecipient {
+    /// The spark address receiving the tokens
+    pub address: String,
+    /// The amount to send, in token base units
+    pub amount: u128,
+}
+
+#[derive(Debug, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DistributeTokensRequest {
+    pub recipients: Vec<TokenRecipient>,
+    /// Identifies the distribution, so an interrupted or partially failed distribution can be
+    /// resumed by calling again with the same id and recipients. Recipients already sent to are
+    /// skipped. If not set, a new id is generated. The distribution id must be a valid UUID.
+    /// A distribution can't be resumed while it's still running.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub distribution_id: Option<String>,
+    /// If true, mints the supply missing from the issuer balance to cover the distribution
+    /// before sending. Defaults to false.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub mint_missing_supply: Option<bool>,
+    /// The number of recipients sent to in a single token transaction. Defaults to 10, at most 50.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub chunk_size: Option<u32>,
+}
+
+#[derive(Debug, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DistributeTokensResponse {
+    pub distribution_id: String,
+    /// The id of the payment minting the missing supply, if any was minted
+    pub mint_payment_id: Option<String>,
+    /// The result of each recipient, in the order of the request
+    pub results: Vec<TokenRecipientResult>,
+}
+
+#[derive(Clone, Debug, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct TokenRecipientResult {
+    pub address: String,
+    pub amount: u128,
+    pub status: TokenRecipientStatus,
+}
+
+#[derive(Clone, Debug, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum TokenRecipientStatus {
+    /// Not sent to yet
+    Pending,
+    /// The tokens were sent in the payment with the given id
+    Sent { payment_id: String },
+    /// Sending failed. It's retried when the distribution is resumed.
+    Failed { error: String },
+    /// The distribution was interrupted while sending, or the send failed after it may have
+    /// reached the operators, so it's unknown whether the tokens were sent. It's never retried,
+    /// to avoid paying the recipient twice. Check the payments to find out.
+    Unknown,
+}
+
+#[derive(Debug, Default, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetIssuanceStatsRequest {
+    /// The length of the periods the mint and burn history is grouped in, in seconds.
+    /// Defaults to a day.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub period_secs: Option<u64>,
+}
+
+/// Statistics of the issuer token, for issuance dashboards
+#[derive(Debug, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct IssuanceStats {
+    pub token_identifier: String,
+    pub max_supply: u128,
+    /// The supply minted by the issuer, in token base units
+    pub total_minted: u128,
+    /// The supply burned by the issuer, in token base units
+    pub total_burned: u128,
+    /// The minted supply that wasn't burned
+    pub total_supply: u128,
+    /// The supply held by addresses other than the issuer, as known to the operators
+    pub circulating_supply: u128,
+    /// The number of distinct holders other than the issuer
+    pub holder_count: u32,
+    /// The number of addresses currently frozen by the issuer, counted from the freezes and
+    /// unfreezes made on this device. They aren't synced, so a restored wallet or another
+    /// device only counts its own.
+    pub frozen_address_count: u32,
+    /// The supply minted and burned in each period with mints or burns, oldest first
+    pub history: Vec<IssuancePeriod>,
+}
+
+#[derive(Clone, Debug, PartialEq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct IssuancePeriod {
+    /// The start of the period, as a UNIX timestamp in seconds
+    pub start: u64,
+    pub minted: u128,
+    pub burned: u128,
+    /// The total supply at the end of the period
+    pub total_supply: u128,
+}
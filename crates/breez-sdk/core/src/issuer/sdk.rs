@@ -1,27 +1,112 @@
-use std::sync::Arc;
+use std::{
+    collections::BTreeSet,
+    sync::{Arc, Mutex},
+};
 
-use spark_wallet::{SparkAddress, SparkWallet};
+use bitcoin::secp256k1::PublicKey;
+use spark_wallet::{SparkAddress, SparkWallet, TokenTransaction, TransferTokenOutput};
+use tracing::warn;
 
 use crate::{
-    BurnIssuerTokenRequest, CreateIssuerTokenRequest, FreezeIssuerTokenRequest,
-    FreezeIssuerTokenResponse, MintIssuerTokenRequest, Payment, SdkError, Storage, TokenBalance,
-    TokenMetadata, UnfreezeIssuerTokenRequest, UnfreezeIssuerTokenResponse,
-    utils::token::map_and_persist_token_transaction,
+    AssetFilter, BurnIssuerTokenRequest, Clock, CreateIssuerTokenRequest, DistributeTokensRequest,
+    DistributeTokensResponse, FreezeIssuerTokenRequest, FreezeIssuerTokenResponse,
+    GetIssuanceStatsRequest, IssuanceStats, MintIssuerTokenRequest, Payment, PaymentRail, SdkError,
+    Storage, StorageListPaymentsRequest, TokenBalance, TokenMetadata, TokenRecipient,
+    TokenRecipientResult, TokenRecipientStatus, UnfreezeIssuerTokenRequest,
+    UnfreezeIssuerTokenResponse,
+    events::{EventEmitter, SdkEvent, TokenDistributionEvent},
+    issuer::{IssuerApprover, approval::IssuerOperation, stats},
+    persist::{
+        CachedTokenDistribution, CachedTokenFreeze, IdempotentOperation, ObjectCacheRepository,
+    },
+    sdk::{CappedSend, SpendingCaps, clock_now, ensure_not_frozen},
+    utils::{
+        idempotency::{fails_before_sending, run_idempotent_payment},
+        token::{map_and_persist_token_transaction, map_and_persist_token_transaction_payments},
+    },
 };
 
+const DEFAULT_DISTRIBUTION_CHUNK_SIZE: u32 = 10;
+const MAX_DISTRIBUTION_CHUNK_SIZE: u32 = 50;
+
+/// The ids of the distributions running in this process, so the same
+/// distribution is never sent by two calls at once
+static RUNNING_DISTRIBUTIONS: Mutex<BTreeSet<String>> = Mutex::new(BTreeSet::new());
+
+/// Marks a distribution as running until dropped.
+struct RunningDistribution(String);
+
+impl RunningDistribution {
+    fn start(distribution_id: &str) -> Result<Self, SdkError> {
+        let mut running = RUNNING_DISTRIBUTIONS
+            .lock()
+            .unwrap_or_else(std::sync::PoisonError::into_inner);
+        if !running.insert(distribution_id.to_string()) {
+            return Err(SdkError::IdempotencyKeyInUse(format!(
+                "distribution {distribution_id} is already running"
+            )));
+        }
+        Ok(Self(distribution_id.to_string()))
+    }
+}
+
+impl Drop for RunningDistribution {
+    fn drop(&mut self) {
+        RUNNING_DISTRIBUTIONS
+            .lock()
+            .unwrap_or_else(std::sync::PoisonError::into_inner)
+            .remove(&self.0);
+    }
+}
+
 #[cfg_attr(feature = "uniffi", derive(uniffi::Object))]
 pub struct TokenIssuer {
     spark_wallet: Arc<SparkWallet>,
     storage: Arc<dyn Storage>,
+    event_emitter: Arc<EventEmitter>,
+    approver: Option<Arc<IssuerApprover>>,
+    spending_caps: Arc<SpendingCaps>,
+    clock: Option<Arc<dyn Clock>>,
 }
 
 impl TokenIssuer {
-    pub fn new(spark_wallet: Arc<SparkWallet>, storage: Arc<dyn Storage>) -> Self {
+    pub(crate) fn new(
+        spark_wallet: Arc<SparkWallet>,
+        storage: Arc<dyn Storage>,
+        event_emitter: Arc<EventEmitter>,
+        approver: Option<Arc<IssuerApprover>>,
+        spending_caps: Arc<SpendingCaps>,
+        clock: Option<Arc<dyn Clock>>,
+    ) -> Self {
         Self {
             spark_wallet,
             storage,
+            event_emitter,
+            approver,
+            spending_caps,
+            clock,
         }
     }
+
+    /// Gets the approval of the issuer approval key for the operation, if one
+    /// is configured.
+    async fn approve(&self, operation: IssuerOperation) -> Result<(), SdkError> {
+        let Some(approver) = &self.approver else {
+            return Ok(());
+        };
+        let token_identifier = self
+            .spark_wallet
+            .get_issuer_token_metadata()
+            .await?
+            .identifier;
+        approver
+            .approve(
+                &self.spark_wallet.get_identity_public_key(),
+                &token_identifier,
+                &operation,
+            )
+            .await
+    }
 }
 
 #[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
@@ -90,9 +175,25 @@ impl TokenIssuer {
         &self,
         request: MintIssuerTokenRequest,
     ) -> Result<Payment, SdkError> {
-        let token_transaction = self.spark_wallet.mint_issuer_token(request.amount).await?;
-        map_and_persist_token_transaction(&self.spark_wallet, &self.storage, &token_transaction)
-            .await
+        run_idempotent_payment(
+            self.storage.clone(),
+            request.idempotency_key.as_deref(),
+            IdempotentOperation::MintIssuerToken,
+            || async {
+                self.approve(IssuerOperation::Mint {
+                    amount: request.amount,
+                })
+                .await?;
+                let token_transaction = self.spark_wallet.mint_issuer_token(request.amount).await?;
+                map_and_persist_token_transaction(
+                    &self.spark_wallet,
+                    &self.storage,
+                    &token_transaction,
+                )
+                .await
+            },
+        )
+        .await
     }
 
     /// Burns supply of the issuer token
@@ -110,12 +211,39 @@ impl TokenIssuer {
         &self,
         request: BurnIssuerTokenRequest,
     ) -> Result<Payment, SdkError> {
-        let token_transaction = self
-            .spark_wallet
-            .burn_issuer_token(request.amount, None)
-            .await?;
-        map_and_persist_token_transaction(&self.spark_wallet, &self.storage, &token_transaction)
-            .await
+        ensure_not_frozen(&self.storage).await?;
+        run_idempotent_payment(
+            self.storage.clone(),
+            request.idempotency_key.as_deref(),
+            IdempotentOperation::BurnIssuerToken,
+            || async {
+                let _reservation = self
+                    .spending_caps
+                    .reserve(token_send(
+                        self.spark_wallet
+                            .get_issuer_token_metadata()
+                            .await?
+                            .identifier,
+                        request.amount,
+                    ))
+                    .await?;
+                self.approve(IssuerOperation::Burn {
+                    amount: request.amount,
+                })
+                .await?;
+                let token_transaction = self
+                    .spark_wallet
+                    .burn_issuer_token(request.amount, None)
+                    .await?;
+                map_and_persist_token_transaction(
+                    &self.spark_wallet,
+                    &self.storage,
+                    &token_transaction,
+                )
+                .await
+            },
+        )
+        .await
     }
 
     /// Freezes tokens held at the specified address
@@ -137,11 +265,18 @@ impl TokenIssuer {
             .address
             .parse::<SparkAddress>()
             .map_err(|_| SdkError::InvalidInput("Invalid spark address".to_string()))?;
-        Ok(self
+        self.approve(IssuerOperation::Freeze {
+            address: request.address.clone(),
+        })
+        .await?;
+        let response: FreezeIssuerTokenResponse = self
             .spark_wallet
             .freeze_issuer_token(&spark_address)
             .await?
-            .into())
+            .into();
+        self.record_freeze(request.address, true, response.impacted_token_amount)
+            .await;
+        Ok(response)
     }
 
     /// Unfreezes tokens held at the specified address
@@ -163,10 +298,606 @@ impl TokenIssuer {
             .address
             .parse::<SparkAddress>()
             .map_err(|_| SdkError::InvalidInput("Invalid spark address".to_string()))?;
-        Ok(self
+        self.approve(IssuerOperation::Unfreeze {
+            address: request.address.clone(),
+        })
+        .await?;
+        let response: UnfreezeIssuerTokenResponse = self
             .spark_wallet
             .unfreeze_issuer_token(&spark_address)
             .await?
-            .into())
+            .into();
+        self.record_freeze(request.address, false, response.impacted_token_amount)
+            .await;
+        Ok(response)
+    }
+
+    /// Gets statistics of the issuer token for issuance dashboards
+    ///
+    /// The minted and burned supply and its history are aggregated from the
+    /// mints and burns of the wallet, and the frozen addresses from its freezes
+    /// and unfreezes. The holders and circulating supply are queried from the
+    /// operators.
+    ///
+    /// # Arguments
+    ///
+    /// * `request`: The request containing the length of the history periods
+    ///
+    /// # Returns
+    ///
+    /// Result containing either:
+    /// * `IssuanceStats` - The statistics of the issuer token
+    /// * `SdkError` - If there was an error during the retrieval or no issuer token exists
+    pub async fn get_issuance_stats(
+        &self,
+        request: GetIssuanceStatsRequest,
+    ) -> Result<IssuanceStats, SdkError> {
+        let period_secs = request
+            .period_secs
+            .unwrap_or(stats::DEFAULT_ISSUANCE_PERIOD_SECS);
+        if period_secs == 0 {
+            return Err(SdkError::InvalidInput(
+                "Period must be greater than 0".to_string(),
+            ));
+        }
+        let metadata = self.spark_wallet.get_issuer_token_metadata().await?;
+        let payments = self
+            .storage
+            .list_payments(StorageListPaymentsRequest {
+                asset_filter: Some(AssetFilter::Token {
+                    token_identifier: Some(metadata.identifier.clone()),
+                }),
+                ..Default::default()
+            })
+            .await?;
+        let freezes = ObjectCacheRepository::new(self.storage.clone())
+            .fetch_token_freezes(&metadata.identifier)
+            .await?;
+        let issuer_public_key = self.spark_wallet.get_identity_public_key();
+        let holders: Vec<u128> = self
+            .spark_wallet
+            .query_token_holder_balances(&metadata.identifier)
+            .await?
+            .into_iter()
+            .filter(|(owner, balance)| *owner != issuer_public_key && *balance > 0)
+            .map(|(_, balance)| balance)
+            .collect();
+
+        let history = stats::issuance_history(&payments, period_secs);
+        let (total_minted, total_burned) =
+            history.iter().fold((0u128, 0u128), |(minted, burned), p| {
+                (
+                    minted.saturating_add(p.minted),
+                    burned.saturating_add(p.burned),
+                )
+            });
+        Ok(IssuanceStats {
+            token_identifier: metadata.identifier,
+            max_supply: metadata.max_supply,
+            total_minted,
+            total_burned,
+            total_supply: total_minted.saturating_sub(total_burned),
+            circulating_supply: holders
+                .iter()
+                .fold(0u128, |total, balance| total.saturating_add(*balance)),
+            holder_count: u32::try_from(holders.len()).unwrap_or(u32::MAX),
+            frozen_address_count: stats::frozen_address_count(&freezes),
+            history,
+        })
+    }
+
+    /// Distributes the issuer token to many recipients, sending to a chunk of
+    /// recipients per token transaction
+    ///
+    /// The progress is saved after each chunk, so a distribution that was
+    /// interrupted or partially failed can be resumed by calling again with
+    /// the same distribution id and recipients. Recipients already sent to are
+    /// skipped, and recipients that failed are retried. A [`TokenDistributionEvent`]
+    /// is emitted for each chunk and when the distribution finishes.
+    ///
+    /// # Arguments
+    ///
+    /// * `request`: The request containing the recipients and distribution options
+    ///
+    /// # Returns
+    ///
+    /// Result containing either:
+    /// * `DistributeTokensResponse` - The result of each recipient
+    /// * `SdkError` - If the request is invalid or the distribution couldn't be started
+    #[allow(clippy::too_many_lines)]
+    pub async fn distribute_tokens(
+        &self,
+        request: DistributeTokensRequest,
+    ) -> Result<DistributeTokensResponse, SdkError> {
+        ensure_not_frozen(&self.storage).await?;
+        let chunk_size = request
+            .chunk_size
+            .unwrap_or(DEFAULT_DISTRIBUTION_CHUNK_SIZE);
+        if chunk_size == 0 || chunk_size > MAX_DISTRIBUTION_CHUNK_SIZE {
+            return Err(SdkError::InvalidInput(format!(
+                "Chunk size must be between 1 and {MAX_DISTRIBUTION_CHUNK_SIZE}"
+            )));
+        }
+        if request.recipients.is_empty() {
+            return Err(SdkError::InvalidInput("No recipients".to_string()));
+        }
+        let mut addresses = Vec::with_capacity(request.recipients.len());
+        for recipient in &request.recipients {
+            if recipient.amount == 0 {
+                return Err(SdkError::InvalidInput(format!(
+                    "Amount for {} must be greater than 0",
+                    recipient.address
+                )));
+            }
+            addresses.push(recipient.address.parse::<SparkAddress>().map_err(|_| {
+                SdkError::InvalidInput(format!("Invalid spark address: {}", recipient.address))
+            })?);
+        }
+        let distribution_id = match request.distribution_id {
+            Some(id) => {
+                uuid::Uuid::parse_str(&id).map_err(|_| SdkError::InvalidUuid(id.clone()))?;
+                id
+            }
+            None => uuid::Uuid::new_v4().to_string(),
+        };
+        let _running = RunningDistribution::start(&distribution_id)?;
+
+        let token_identifier = self
+            .spark_wallet
+            .get_issuer_token_metadata()
+            .await?
+            .identifier;
+        let cache = ObjectCacheRepository::new(self.storage.clone());
+        let mut distribution = match cache.fetch_token_distribution(&distribution_id).await? {
+            Some(distribution) => {
+                validate_resumed_distribution(
+                    &distribution,
+                    &token_identifier,
+                    &request.recipients,
+                )?;
+                distribution
+            }
+            None => CachedTokenDistribution {
+                token_identifier: token_identifier.clone(),
+                mint_payment_id: None,
+                recipients: request
+                    .recipients
+                    .into_iter()
+                    .map(|recipient| TokenRecipientResult {
+                        address: recipient.address,
+                        amount: recipient.amount,
+                        status: TokenRecipientStatus::Pending,
+                    })
+                    .collect(),
+            },
+        };
+        for recipient in &mut distribution.recipients {
+            if matches!(recipient.status, TokenRecipientStatus::Failed { .. }) {
+                recipient.status = TokenRecipientStatus::Pending;
+            }
+        }
+        cache
+            .save_token_distribution(&distribution_id, &distribution)
+            .await?;
+
+        if request.mint_missing_supply.unwrap_or(false) && distribution.mint_payment_id.is_none() {
+            let needed = distribution
+                .recipients
+                .iter()
+                .filter(|r| r.status == TokenRecipientStatus::Pending)
+                .fold(0u128, |total, r| total.saturating_add(r.amount));
+            let balance = self.spark_wallet.get_issuer_token_balance().await?.balance;
+            if needed > balance {
+                // Keyed by the distribution id, so resuming never mints twice
+                let payment = run_idempotent_payment(
+                    self.storage.clone(),
+                    Some(&distribution_id),
+                    IdempotentOperation::DistributeIssuerToken,
+                    || async {
+                        self.approve(IssuerOperation::Mint {
+                            amount: needed - balance,
+                        })
+                        .await?;
+                        let token_transaction = self
+                            .spark_wallet
+                            .mint_issuer_token(needed - balance)
+                            .await?;
+                        map_and_persist_token_transaction(
+                            &self.spark_wallet,
+                            &self.storage,
+                            &token_transaction,
+                        )
+                        .await
+                    },
+                )
+                .await?;
+                distribution.mint_payment_id = Some(payment.id);
+                cache
+                    .save_token_distribution(&distribution_id, &distribution)
+                    .await?;
+            }
+        }
+
+        let total_count = u32::try_from(distribution.recipients.len()).unwrap_or(u32::MAX);
+        let pending: Vec<usize> = distribution
+            .recipients
+            .iter()
+            .enumerate()
+            .filter(|(_, r)| r.status == TokenRecipientStatus::Pending)
+            .map(|(i, _)| i)
+            .collect();
+        let mut processed_count =
+            total_count.saturating_sub(u32::try_from(pending.len()).unwrap_or(u32::MAX));
+        for chunk in pending.chunks(chunk_size as usize) {
+            let receivers: Vec<(PublicKey, u128)> = chunk
+                .iter()
+                .map(|&i| {
+                    (
+                        addresses[i].identity_public_key,
+                        distribution.recipients[i].amount,
+                    )
+                })
+                .collect();
+            let chunk_amount = receivers
+                .iter()
+                .fold(0u128, |total, (_, amount)| total.saturating_add(*amount));
+            let statuses = match self
+                .spending_caps
+                .reserve(token_send(token_identifier.clone(), chunk_amount))
+                .await
+            {
+                Ok(_reservation) => {
+                    // Marked unknown until the outcome is saved, so a chunk
+                    // interrupted mid-send is never sent twice
+                    for &i in chunk {
+                        distribution.recipients[i].status = TokenRecipientStatus::Unknown;
+                    }
+                    cache
+                        .save_token_distribution(&distribution_id, &distribution)
+                        .await?;
+
+                    let outputs = chunk
+                        .iter()
+                        .map(|&i| TransferTokenOutput {
+                            token_id: token_identifier.clone(),
+                            amount: distribution.recipients[i].amount,
+                            receiver_address: addresses[i].clone(),
+                            spark_invoice: None,
+                        })
+                        .collect();
+                    self.transfer_chunk(&distribution_id, outputs, &receivers)
+                        .await
+                }
+                Err(e) => vec![
+                    TokenRecipientStatus::Failed {
+                        error: e.to_string()
+                    };
+                    chunk.len()
+                ],
+            };
+            for (&i, status) in chunk.iter().zip(statuses) {
+                distribution.recipients[i].status = status;
+            }
+            cache
+                .save_token_distribution(&distribution_id, &distribution)
+                .await?;
+
+            processed_count =
+                processed_count.saturating_add(u32::try_from(chunk.len()).unwrap_or(u32::MAX));
+            self.event_emitter
+                .emit(&SdkEvent::TokenDistribution {
+                    distribution_event: TokenDistributionEvent::Progress {
+                        distribution_id: distribution_id.clone(),
+                        processed_count,
+                        total_count,
+                        results: chunk
+                            .iter()
+                            .map(|&i| distribution.recipients[i].clone())
+                            .collect(),
+                    },
+                })
+                .await;
+        }
+
+        let count = |f: fn(&TokenRecipientStatus) -> bool| {
+            u32::try_from(
+                distribution
+                    .recipients
+                    .iter()
+                    .filter(|r| f(&r.status))
+                    .count(),
+            )
+            .unwrap_or(u32::MAX)
+        };
+        self.event_emitter
+            .emit(&SdkEvent::TokenDistribution {
+                distribution_event: TokenDistributionEvent::Completed {
+                    distribution_id: distribution_id.clone(),
+                    sent_count: count(|s| matches!(s, TokenRecipientStatus::Sent { .. })),
+                    failed_count: count(|s| matches!(s, TokenRecipientStatus::Failed { .. })),
+                    unknown_count: count(|s| matches!(s, TokenRecipientStatus::Unknown)),
+                },
+            })
+            .await;
+
+        Ok(DistributeTokensResponse {
+            distribution_id,
+            mint_payment_id: distribution.mint_payment_id,
+            results: distribution.recipients,
+        })
+    }
+}
+
+impl TokenIssuer {
+    /// Sends a chunk of a distribution and returns the status of each of its
+    /// recipients.
+    async fn transfer_chunk(
+        &self,
+        distribution_id: &str,
+        outputs: Vec<TransferTokenOutput>,
+        receivers: &[(PublicKey, u128)],
+    ) -> Vec<TokenRecipientStatus> {
+        match self.spark_wallet.transfer_tokens(outputs, None, None).await {
+            Ok(token_transaction) => match map_and_persist_token_transaction_payments(
+                &self.spark_wallet,
+                &self.storage,
+                &token_transaction,
+            )
+            .await
+            {
+                Ok(payments) => recipient_statuses(receivers, &token_transaction, &payments),
+                Err(e) => {
+                    warn!(
+                        "Failed to persist the payments of distribution {distribution_id}: {e:?}"
+                    );
+                    vec![TokenRecipientStatus::Unknown; receivers.len()]
+                }
+            },
+            Err(e) => {
+                let e = SdkError::from(e);
+                if fails_before_sending(&e) {
+                    vec![
+                        TokenRecipientStatus::Failed {
+                            error: e.to_string()
+                        };
+                        receivers.len()
+                    ]
+                } else {
+                    // The transaction may have reached the operators, so
+                    // retrying it could pay the recipients twice
+                    warn!(
+                        "Sending a chunk of distribution {distribution_id} failed with an unknown outcome: {e:?}"
+                    );
+                    vec![TokenRecipientStatus::Unknown; receivers.len()]
+                }
+            }
+        }
+    }
+
+    /// Records a freeze or unfreeze for the token activity. Failing to record
+    /// it doesn't fail the freeze, which already happened.
+    async fn record_freeze(&self, address: String, frozen: bool, impacted_token_amount: u128) {
+        let token_identifier = match self.spark_wallet.get_issuer_token_metadata().await {
+            Ok(metadata) => metadata.identifier,
+            Err(e) => {
+                warn!("Failed to get the issuer token to record a freeze: {e:?}");
+                return;
+            }
+        };
+        let result = ObjectCacheRepository::new(self.storage.clone())
+            .add_token_freeze(
+                &token_identifier,
+                CachedTokenFreeze {
+                    address,
+                    frozen,
+                    impacted_token_amount,
+                    timestamp: clock_now(self.clock.as_ref()),
+                },
+            )
+            .await;
+        if let Err(e) = result {
+            warn!("Failed to record the freeze of token {token_identifier}: {e:?}");
+        }
+    }
+}
+
+/// An issuer token spend, counted against the spending caps of the token.
+fn token_send(token_identifier: String, amount: u128) -> CappedSend {
+    CappedSend {
+        rail: Some(PaymentRail::Spark),
+        token_identifier: Some(token_identifier),
+        plugin: false,
+        amount,
+    }
+}
+
+/// Checks that a distribution being resumed is for the same token and
+/// recipients as when it was started.
+fn validate_resumed_distribution(
+    distribution: &CachedTokenDistribution,
+    token_identifier: &str,
+    recipients: &[TokenRecipient],
+) -> Result<(), SdkError> {
+    if distribution.token_identifier != token_identifier {
+        return Err(SdkError::InvalidInput(
+            "Distribution was started for a different token".to_string(),
+        ));
+    }
+    let same_recipients = distribution.recipients.len() == recipients.len()
+        && distribution
+            .recipients
+            .iter()
+            .zip(recipients)
+            .all(|(stored, r)| stored.address == r.address && stored.amount == r.amount);
+    if !same_recipients {
+        return Err(SdkError::InvalidInput(
+            "Distribution was started with different recipients".to_string(),
+        ));
+    }
+    Ok(())
+}
+
+/// Matches the recipients of a chunk, by receiver and amount, to the outputs
+/// of the token transaction sending to them, and returns the payment of each.
+/// A recipient without a matching payment was sent to, but its payment is
+/// unknown.
+fn recipient_statuses(
+    receivers: &[(PublicKey, u128)],
+    token_transaction: &TokenTransaction,
+    payments: &[Payment],
+) -> Vec<TokenRecipientStatus> {
+    let mut used = vec![false; token_transaction.outputs.len()];
+    receivers
+        .iter()
+        .map(|(receiver, amount)| {
+            let vout = token_transaction
+                .outputs
+                .iter()
+                .enumerate()
+                .find(|(vout, output)| {
+                    !used[*vout]
+                        && output.owner_public_key == *receiver
+                        && output.token_amount == *amount
+                })
+                .map(|(vout, _)| vout);
+            let Some(vout) = vout else {
+                return TokenRecipientStatus::Unknown;
+            };
+            used[vout] = true;
+            let payment_id = format!("{}:{vout}", token_transaction.hash);
+            match payments.iter().find(|p| p.id == payment_id) {
+                Some(payment) => TokenRecipientStatus::Sent {
+                    payment_id: payment.id.clone(),
+                },
+                None => TokenRecipientStatus::Unknown,
+            }
+        })
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use platform_utils::time::SystemTime;
+    use spark_wallet::{TokenInputs, TokenOutput, TokenTransactionStatus, TokenTransferInput};
+
+    use super::*;
+    use crate::{PaymentType, sdk::ledger::tests::payment};
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn pk(fill_byte: u8) -> PublicKey {
+        let mut bytes = [fill_byte; 33];
+        bytes[0] = 2;
+        PublicKey::from_slice(&bytes).unwrap()
+    }
+
+    fn token_transaction(outputs: &[(PublicKey, u128)]) -> TokenTransaction {
+        TokenTransaction {
+            hash: "tx".to_string(),
+            inputs: TokenInputs::Transfer(TokenTransferInput {
+                outputs_to_spend: vec![],
+            }),
+            outputs: outputs
+                .iter()
+                .map(|(owner, amount)| TokenOutput {
+                    owner_public_key: *owner,
+                    revocation_commitment: "commitment".to_string(),
+                    withdraw_bond_sats: 1000,
+                    withdraw_relative_block_locktime: 144,
+                    token_public_key: None,
+                    token_identifier: "token".to_string(),
+                    token_amount: *amount,
+                })
+                .collect(),
+            status: TokenTransactionStatus::Finalized,
+            created_timestamp: SystemTime::now(),
+            fulfilled_invoices: vec![],
+        }
+    }
+
+    fn recipient(address: &str, amount: u128) -> TokenRecipient {
+        TokenRecipient {
+            address: address.to_string(),
+            amount,
+        }
+    }
+
+    #[test_all]
+    fn test_recipient_statuses() {
+        // Two recipients of the same amount and a change output of that
+        // amount back to the issuer
+        let transaction = token_transaction(&[(pk(1), 10), (pk(2), 20), (pk(3), 10), (pk(9), 10)]);
+        let payments = vec![
+            payment("tx:1", PaymentType::Send, 20, 0, 1),
+            payment("tx:0", PaymentType::Send, 10, 0, 1),
+            payment("tx:2", PaymentType::Send, 10, 0, 1),
+        ];
+        let statuses = recipient_statuses(
+            &[(pk(3), 10), (pk(1), 10), (pk(2), 20), (pk(4), 30)],
+            &transaction,
+            &payments,
+        );
+        assert_eq!(
+            statuses,
+            vec![
+                TokenRecipientStatus::Sent {
+                    payment_id: "tx:2".to_string()
+                },
+                TokenRecipientStatus::Sent {
+                    payment_id: "tx:0".to_string()
+                },
+                TokenRecipientStatus::Sent {
+                    payment_id: "tx:1".to_string()
+                },
+                TokenRecipientStatus::Unknown,
+            ]
+        );
+    }
+
+    #[test_all]
+    fn test_running_distribution() {
+        let id = uuid::Uuid::new_v4().to_string();
+        let running = RunningDistribution::start(&id).unwrap();
+        assert!(matches!(
+            RunningDistribution::start(&id),
+            Err(SdkError::IdempotencyKeyInUse(_))
+        ));
+        drop(running);
+        assert!(RunningDistribution::start(&id).is_ok());
+    }
+
+    #[test_all]
+    fn test_validate_resumed_distribution() {
+        let distribution = CachedTokenDistribution {
+            token_identifier: "token".to_string(),
+            mint_payment_id: None,
+            recipients: vec![TokenRecipientResult {
+                address: "addr".to_string(),
+                amount: 10,
+                status: TokenRecipientStatus::Unknown,
+            }],
+        };
+        assert!(
+            validate_resumed_distribution(&distribution, "token", &[recipient("addr", 10)]).is_ok()
+        );
+        assert!(
+            validate_resumed_distribution(&distribution, "other", &[recipient("addr", 10)])
+                .is_err()
+        );
+        assert!(
+            validate_resumed_distribution(&distribution, "token", &[recipient("addr", 11)])
+                .is_err()
+        );
+        assert!(
+            validate_resumed_distribution(
+                &distribution,
+                "token",
+                &[recipient("addr", 10), recipient("addr2", 10)]
+            )
+            .is_err()
+        );
     }
 }
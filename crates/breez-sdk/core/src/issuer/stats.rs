@@ -0,0 +1,161 @@
+use std::collections::{BTreeMap, HashMap};
+
+use crate::{
+    IssuancePeriod, Payment, PaymentDetails, PaymentStatus, TokenTransactionType,
+    persist::CachedTokenFreeze,
+};
+
+pub(super) const DEFAULT_ISSUANCE_PERIOD_SECS: u64 = 24 * 60 * 60;
+
+/// Groups the completed mints and burns of the payments into periods of
+/// `period_secs`, oldest first.
+pub(super) fn issuance_history(payments: &[Payment], period_secs: u64) -> Vec<IssuancePeriod> {
+    let mut periods: BTreeMap<u64, (u128, u128)> = BTreeMap::new();
+    for payment in payments {
+        if payment.status != PaymentStatus::Completed {
+            continue;
+        }
+        let Some(PaymentDetails::Token { tx_type, .. }) = &payment.details else {
+            continue;
+        };
+        let start = payment.timestamp - payment.timestamp % period_secs;
+        let (minted, burned) = periods.entry(start).or_default();
+        match tx_type {
+            TokenTransactionType::Mint => *minted = minted.saturating_add(payment.amount),
+            TokenTransactionType::Burn => *burned = burned.saturating_add(payment.amount),
+            TokenTransactionType::Transfer => {}
+        }
+    }
+
+    let mut total_supply = 0u128;
+    periods
+        .into_iter()
+        .filter(|(_, (minted, burned))| *minted > 0 || *burned > 0)
+        .map(|(start, (minted, burned))| {
+            total_supply = total_supply.saturating_add(minted).saturating_sub(burned);
+            IssuancePeriod {
+                start,
+                minted,
+                burned,
+                total_supply,
+            }
+        })
+        .collect()
+}
+
+/// Counts the addresses whose latest freeze or unfreeze was a freeze. The
+/// Spark operators don't report which addresses are frozen, so this only
+/// counts the freezes recorded locally.
+pub(super) fn frozen_address_count(freezes: &[CachedTokenFreeze]) -> u32 {
+    let mut frozen: HashMap<&str, (u64, bool)> = HashMap::new();
+    for freeze in freezes {
+        let latest = frozen
+            .entry(&freeze.address)
+            .or_insert((freeze.timestamp, freeze.frozen));
+        // Freezes are recorded in order, so a later record wins on a tie
+        if freeze.timestamp >= latest.0 {
+            *latest = (freeze.timestamp, freeze.frozen);
+        }
+    }
+    u32::try_from(frozen.values().filter(|(_, frozen)| *frozen).count()).unwrap_or(u32::MAX)
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+    use crate::{PaymentType, sdk::ledger::tests::token_payment};
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn payment(
+        tx_type: TokenTransactionType,
+        amount: u128,
+        timestamp: u64,
+        status: PaymentStatus,
+    ) -> Payment {
+        Payment {
+            status,
+            ..token_payment(
+                &format!("{tx_type}-{timestamp}"),
+                PaymentType::Receive,
+                amount,
+                timestamp,
+                tx_type,
+            )
+        }
+    }
+
+    fn freeze(address: &str, frozen: bool, timestamp: u64) -> CachedTokenFreeze {
+        CachedTokenFreeze {
+            address: address.to_string(),
+            frozen,
+            impacted_token_amount: 0,
+            timestamp,
+        }
+    }
+
+    #[test_all]
+    fn test_issuance_history() {
+        let payments = vec![
+            payment(
+                TokenTransactionType::Mint,
+                1000,
+                10,
+                PaymentStatus::Completed,
+            ),
+            payment(
+                TokenTransactionType::Mint,
+                500,
+                50,
+                PaymentStatus::Completed,
+            ),
+            payment(
+                TokenTransactionType::Transfer,
+                300,
+                60,
+                PaymentStatus::Completed,
+            ),
+            payment(
+                TokenTransactionType::Burn,
+                200,
+                250,
+                PaymentStatus::Completed,
+            ),
+            payment(TokenTransactionType::Mint, 700, 260, PaymentStatus::Failed),
+        ];
+        assert_eq!(
+            issuance_history(&payments, 100),
+            vec![
+                IssuancePeriod {
+                    start: 0,
+                    minted: 1500,
+                    burned: 0,
+                    total_supply: 1500,
+                },
+                IssuancePeriod {
+                    start: 200,
+                    minted: 0,
+                    burned: 200,
+                    total_supply: 1300,
+                },
+            ]
+        );
+    }
+
+    #[test_all]
+    fn test_frozen_address_count() {
+        let freezes = vec![
+            freeze("a", true, 10),
+            freeze("b", true, 20),
+            freeze("a", false, 30),
+            freeze("c", true, 40),
+            freeze("c", false, 40),
+            freeze("c", true, 40),
+        ];
+        assert_eq!(frozen_address_count(&freezes), 2);
+        assert_eq!(frozen_address_count(&[]), 0);
+    }
+}
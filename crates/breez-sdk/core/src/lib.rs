@@ -9,11 +9,14 @@ mod issuer;
 mod jwt_header_provider;
 mod lnurl;
 mod logger;
+mod middleware;
 mod models;
 #[cfg(feature = "passkey")]
 pub mod passkey;
 mod persist;
 mod realtime_sync;
+#[cfg(feature = "rpc-server")]
+mod rpc_server;
 mod sdk;
 mod sdk_builder;
 mod sdk_context;
@@ -38,9 +41,13 @@ pub use cross_chain::{
     CrossChainRoutePair, SourceAsset,
 };
 pub use error::{DepositClaimError, SdkError, SignerError};
-pub use events::{AutoOptimizationEvent, EventEmitter, EventListener, SdkEvent};
+pub use events::{
+    AutoOptimizationEvent, EventEmitter, EventListener, SdkEvent, TokenDistributionEvent,
+    TokenSweepEvent,
+};
 pub use issuer::*;
-pub use logger::DEFAULT_FILTER;
+pub use logger::{DEFAULT_FILTER, log_entry_from_event};
+pub use middleware::{MiddlewareDecision, PaymentMiddleware, PaymentStage};
 pub use models::*;
 pub use persist::{
     ConversionFilter, PaymentMetadata, SetLnurlMetadataItem, Storage, StorageError,
@@ -52,7 +59,9 @@ pub use persist::{
     path::default_storage_path,
 };
 pub use sdk::{
-    BreezSdk, default_config, default_server_config, get_spark_status, init_logging, parse_input,
+    BreezSdk, amount_to_base_units, base_units_to_amount, create_duress_config,
+    decrypt_success_action, default_config, default_server_config, duress_account_number,
+    get_spark_status, init_logging, is_duress_pin, parse_input,
 };
 pub use sdk_builder::SdkBuilder;
 pub use sdk_context::{SdkContext, SdkContextConfig, new_shared_sdk_context};
@@ -77,7 +86,10 @@ pub use persist::{
 #[cfg(feature = "sqlite")]
 pub use {
     persist::{backend::default_storage, sqlite::SqliteStorage},
-    sdk::{connect, connect_with_signer, connect_with_signing_only_signer},
+    sdk::{
+        connect, connect_for_claiming, connect_with_signer, connect_with_signing_only_signer,
+        handle_notification_payload,
+    },
 };
 
 pub use sdk::{ExternalSigners, SigningOnlyExternalSigners, default_external_signers};
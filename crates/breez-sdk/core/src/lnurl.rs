@@ -1,15 +1,18 @@
 use bitcoin::hex::DisplayHex;
 use lnurl_models::{
-    CheckUsernameAvailableResponse, ListMetadataResponse, RecoverLnurlPayRequest,
-    RecoverLnurlPayResponse, RegisterLnurlPayRequest, RegisterLnurlPayResponse,
-    TransferLnurlPayRequest, UnregisterLnurlPayRequest,
+    CheckUsernameAvailableResponse, CreatePaymentLinkResponse, DeletePaymentLinkRequest,
+    ListLnurlPayResponse, ListMetadataResponse, ListPaymentLinksResponse, PaymentLink,
+    RecoverLnurlPayRequest, RecoverLnurlPayResponse, RegisterLnurlPayRequest,
+    RegisterLnurlPayResponse, SetNostrPubkeyRequest, TransferLnurlPayRequest,
+    UnregisterLnurlPayRequest,
 };
-use platform_utils::time::{SystemTime, UNIX_EPOCH};
 use platform_utils::{ContentType, HttpClient, add_content_type_header};
 use std::collections::HashMap;
 use std::fmt::Write as _;
 use std::sync::Arc;
 
+use crate::{Clock, sdk::clock_now};
+
 #[derive(Debug)]
 pub enum LnurlServerError {
     InvalidApiKey,
@@ -41,6 +44,14 @@ impl std::fmt::Display for LnurlServerError {
 pub struct RegisterLightningAddressRequest {
     pub username: String,
     pub description: String,
+    /// Registers an additional address under this label instead of the
+    /// primary address
+    pub label: Option<String>,
+    /// Base64 encoded PNG for the LNURL-pay metadata
+    pub image: Option<String>,
+    pub long_description: Option<String>,
+    pub min_sendable_msat: Option<u64>,
+    pub max_sendable_msat: Option<u64>,
 }
 
 #[derive(Debug, Clone)]
@@ -59,6 +70,13 @@ pub struct UnregisterLightningAddressRequest {
     pub username: String,
 }
 
+#[derive(Debug, Clone)]
+pub struct SetLightningAddressNostrPubkeyRequest {
+    pub username: String,
+    /// Hex encoded x-only nostr pubkey, `None` detaches the current one
+    pub nostr_pubkey: Option<String>,
+}
+
 #[derive(Debug, Clone)]
 pub struct ListMetadataRequest {
     pub offset: Option<u32>,
@@ -66,6 +84,18 @@ pub struct ListMetadataRequest {
     pub updated_after: Option<i64>,
 }
 
+#[derive(Debug, Clone)]
+pub struct CreatePaymentLinkRequest {
+    pub amount_sat: u64,
+    pub memo: String,
+}
+
+#[derive(Debug, Clone)]
+pub struct ListPaymentLinksRequest {
+    pub offset: Option<u32>,
+    pub limit: Option<u32>,
+}
+
 #[macros::async_trait]
 pub trait LnurlServerClient: Send + Sync {
     fn domain(&self) -> &str;
@@ -73,6 +103,7 @@ pub trait LnurlServerClient: Send + Sync {
     async fn recover_lightning_address(
         &self,
     ) -> Result<Option<RecoverLnurlPayResponse>, LnurlServerError>;
+    async fn list_lightning_addresses(&self) -> Result<ListLnurlPayResponse, LnurlServerError>;
     async fn register_lightning_address(
         &self,
         request: &RegisterLightningAddressRequest,
@@ -85,18 +116,36 @@ pub trait LnurlServerClient: Send + Sync {
         &self,
         request: &UnregisterLightningAddressRequest,
     ) -> Result<(), LnurlServerError>;
+    async fn set_lightning_address_nostr_pubkey(
+        &self,
+        request: &SetLightningAddressNostrPubkeyRequest,
+    ) -> Result<(), LnurlServerError>;
     async fn list_metadata(
         &self,
         request: &ListMetadataRequest,
     ) -> Result<ListMetadataResponse, LnurlServerError>;
+    async fn create_payment_link(
+        &self,
+        request: &CreatePaymentLinkRequest,
+    ) -> Result<PaymentLink, LnurlServerError>;
+    async fn list_payment_links(
+        &self,
+        request: &ListPaymentLinksRequest,
+    ) -> Result<ListPaymentLinksResponse, LnurlServerError>;
+    async fn delete_payment_link(&self, id: &str) -> Result<(), LnurlServerError>;
 }
 
 /// Default `LnurlServerClient` implementation using `HttpClient` abstraction.
 pub struct DefaultLnurlServerClient {
     http_client: Arc<dyn HttpClient>,
     domain: String,
+    /// Overrides the base URL derived from `domain`
+    server_url: Option<String>,
     api_key: Option<String>,
     wallet: Arc<spark_wallet::SparkWallet>,
+    /// Clock the signed request timestamps are read from, unset to use the
+    /// system clock
+    clock: Option<Arc<dyn Clock>>,
 }
 
 impl DefaultLnurlServerClient {
@@ -109,13 +158,34 @@ impl DefaultLnurlServerClient {
         Self {
             http_client,
             domain,
+            server_url: None,
             api_key,
             wallet,
+            clock: None,
         }
     }
 
+    /// Reaches the server at `server_url` rather than at the domain, e.g. a
+    /// self-hosted instance serving the domain's Lightning addresses.
+    #[must_use]
+    pub fn with_server_url(mut self, server_url: Option<String>) -> Self {
+        self.server_url = server_url;
+        self
+    }
+
+    /// Reads the timestamps of signed requests from `clock`, e.g. the one set
+    /// with `SdkBuilder::with_clock`, instead of the system clock.
+    #[must_use]
+    pub(crate) fn with_clock(mut self, clock: Option<Arc<dyn Clock>>) -> Self {
+        self.clock = clock;
+        self
+    }
+
     /// Construct the base URL for the lnurl server.
     fn base_url(&self) -> String {
+        if let Some(server_url) = &self.server_url {
+            return server_url.trim_end_matches('/').to_string();
+        }
         if self.domain.contains("://") {
             self.domain.clone()
         } else {
@@ -141,10 +211,7 @@ impl DefaultLnurlServerClient {
     }
 
     async fn sign_message(&self, message: &str) -> Result<(String, u64), LnurlServerError> {
-        let timestamp = SystemTime::now()
-            .duration_since(UNIX_EPOCH)
-            .map_err(|_| LnurlServerError::SigningError("invalid systemtime".to_string()))?
-            .as_secs();
+        let timestamp = clock_now(self.clock.as_ref());
         let signature = self
             .wallet
             .sign_message(&format!("{message}-{timestamp}"))
@@ -231,18 +298,45 @@ impl LnurlServerClient for DefaultLnurlServerClient {
         }
     }
 
+    async fn list_lightning_addresses(&self) -> Result<ListLnurlPayResponse, LnurlServerError> {
+        let pubkey = self.wallet.get_identity_public_key();
+
+        let (signature, timestamp) = self.sign_message(&pubkey.to_string()).await?;
+
+        let url = format!(
+            "{}/lnurlpay/{pubkey}/addresses?signature={signature}&timestamp={timestamp}",
+            self.base_url(),
+        );
+        let response = self
+            .http_client
+            .get(url, Some(self.get_common_headers()))
+            .await
+            .map_err(|e| LnurlServerError::RequestFailure(e.to_string()))?;
+
+        Self::handle_response(response.status, &response.body)
+    }
+
     async fn register_lightning_address(
         &self,
         request: &RegisterLightningAddressRequest,
     ) -> Result<RegisterLnurlPayResponse, LnurlServerError> {
         let pubkey = self.wallet.get_identity_public_key();
 
-        let (signature, timestamp) = self.sign_message(&request.username).await?;
+        let message = match &request.label {
+            Some(label) => format!("{}:{label}", request.username),
+            None => request.username.clone(),
+        };
+        let (signature, timestamp) = self.sign_message(&message).await?;
         let api_request = RegisterLnurlPayRequest {
             username: request.username.clone(),
             description: request.description.clone(),
             signature,
             timestamp,
+            label: request.label.clone(),
+            image: request.image.clone(),
+            long_description: request.long_description.clone(),
+            min_sendable: request.min_sendable_msat,
+            max_sendable: request.max_sendable_msat,
         };
         let url = format!("{}/lnurlpay/{}", self.base_url(), pubkey);
         let body = serde_json::to_string(&api_request)
@@ -328,6 +422,45 @@ impl LnurlServerClient for DefaultLnurlServerClient {
         }
     }
 
+    async fn set_lightning_address_nostr_pubkey(
+        &self,
+        request: &SetLightningAddressNostrPubkeyRequest,
+    ) -> Result<(), LnurlServerError> {
+        let pubkey = self.wallet.get_identity_public_key();
+
+        let message = format!(
+            "nostr:{}:{}",
+            request.username,
+            request.nostr_pubkey.as_deref().unwrap_or_default()
+        );
+        let (signature, timestamp) = self.sign_message(&message).await?;
+        let api_request = SetNostrPubkeyRequest {
+            username: request.username.clone(),
+            nostr_pubkey: request.nostr_pubkey.clone(),
+            signature,
+            timestamp,
+        };
+
+        let url = format!("{}/lnurlpay/{}/nostr", self.base_url(), pubkey);
+        let body = serde_json::to_string(&api_request)
+            .map_err(|e| LnurlServerError::RequestFailure(e.to_string()))?;
+
+        let response = self
+            .http_client
+            .post(url, Some(self.get_post_headers()), Some(body))
+            .await
+            .map_err(|e| LnurlServerError::RequestFailure(e.to_string()))?;
+
+        match response.status {
+            401 => Err(LnurlServerError::InvalidApiKey),
+            s if (200..300).contains(&s) => Ok(()),
+            other => Err(LnurlServerError::Network {
+                statuscode: other,
+                message: Some(response.body),
+            }),
+        }
+    }
+
     async fn list_metadata(
         &self,
         request: &ListMetadataRequest,
@@ -358,4 +491,97 @@ impl LnurlServerClient for DefaultLnurlServerClient {
 
         Self::handle_response(response.status, &response.body)
     }
+
+    async fn create_payment_link(
+        &self,
+        request: &CreatePaymentLinkRequest,
+    ) -> Result<PaymentLink, LnurlServerError> {
+        let pubkey = self.wallet.get_identity_public_key();
+
+        let (signature, timestamp) = self
+            .sign_message(&format!(
+                "payment-link:{}:{}",
+                request.amount_sat, request.memo
+            ))
+            .await?;
+        let api_request = lnurl_models::CreatePaymentLinkRequest {
+            signature,
+            timestamp,
+            amount_sat: request.amount_sat,
+            memo: request.memo.clone(),
+        };
+        let url = format!("{}/lnurlpay/{}/links", self.base_url(), pubkey);
+        let body = serde_json::to_string(&api_request)
+            .map_err(|e| LnurlServerError::RequestFailure(e.to_string()))?;
+
+        let response = self
+            .http_client
+            .post(url, Some(self.get_post_headers()), Some(body))
+            .await
+            .map_err(|e| LnurlServerError::RequestFailure(e.to_string()))?;
+
+        let result: CreatePaymentLinkResponse =
+            Self::handle_response(response.status, &response.body)?;
+        Ok(result.link)
+    }
+
+    async fn list_payment_links(
+        &self,
+        request: &ListPaymentLinksRequest,
+    ) -> Result<ListPaymentLinksResponse, LnurlServerError> {
+        let pubkey = self.wallet.get_identity_public_key();
+
+        let (signature, timestamp) = self
+            .sign_message(&format!("payment-links:{pubkey}"))
+            .await?;
+
+        let mut url = format!(
+            "{}/lnurlpay/{pubkey}/links?signature={signature}&timestamp={timestamp}",
+            self.base_url(),
+        );
+        if let Some(offset) = request.offset {
+            let _ = write!(url, "&offset={offset}");
+        }
+        if let Some(limit) = request.limit {
+            let _ = write!(url, "&limit={limit}");
+        }
+
+        let response = self
+            .http_client
+            .get(url, Some(self.get_common_headers()))
+            .await
+            .map_err(|e| LnurlServerError::RequestFailure(e.to_string()))?;
+
+        Self::handle_response(response.status, &response.body)
+    }
+
+    async fn delete_payment_link(&self, id: &str) -> Result<(), LnurlServerError> {
+        let pubkey = self.wallet.get_identity_public_key();
+
+        let (signature, timestamp) = self
+            .sign_message(&format!("delete-payment-link:{id}"))
+            .await?;
+        let api_request = DeletePaymentLinkRequest {
+            signature,
+            timestamp,
+        };
+        let url = format!("{}/lnurlpay/{}/links/{}", self.base_url(), pubkey, id);
+        let body = serde_json::to_string(&api_request)
+            .map_err(|e| LnurlServerError::RequestFailure(e.to_string()))?;
+
+        let response = self
+            .http_client
+            .delete(url, Some(self.get_post_headers()), Some(body))
+            .await
+            .map_err(|e| LnurlServerError::RequestFailure(e.to_string()))?;
+
+        match response.status {
+            401 => Err(LnurlServerError::InvalidApiKey),
+            s if (200..300).contains(&s) => Ok(()),
+            other => Err(LnurlServerError::Network {
+                statuscode: other,
+                message: Some(response.body),
+            }),
+        }
+    }
 }
@@ -0,0 +1,389 @@
+mod rotating_file;
+
+use std::{
+    collections::{HashMap, VecDeque},
+    fmt::Debug,
+    io::Write,
+    sync::{Arc, Mutex, OnceLock},
+};
+
+use platform_utils::time::{Duration, SystemTime, UNIX_EPOCH};
+use tracing::{
+    Event, Subscriber,
+    field::{Field, Visit},
+};
+use tracing_subscriber::{
+    EnvFilter, Layer,
+    filter::LevelFilter,
+    fmt::{FormatFields, format::Writer},
+    layer::{Context, SubscriberExt},
+    reload,
+    util::SubscriberInitExt,
+};
+
+use crate::{LogEntry, LogFileFormat, Logger, LoggingConfig, SdkError};
+
+use rotating_file::RotatingFileWriter;
+
+const LOG_FILE_NAME: &str = "sdk.log";
+/// Rotated files kept when `LoggingConfig::max_rotated_files` is unset
+const DEFAULT_MAX_ROTATED_FILES: u32 = 5;
+
+/// Default tracing filter: `info` globally, `debug` for first-party crates,
+/// and noisy third-party crates silenced below `warn`. Shared with the WASM
+/// bindings so both default to the same behaviour.
+pub const DEFAULT_FILTER: &str = concat!(
+    "info",
+    // First-party crates: keep debug logging.
+    ",breez_sdk_spark=debug",
+    ",breez_sdk_common=debug",
+    ",breez_sdk_spark_wasm=debug",
+    ",breez_sdk_spark_bindings=debug",
+    ",spark=debug",
+    ",spark_wallet=debug",
+    ",spark_postgres=debug",
+    ",spark_mysql=debug",
+    ",flashnet=debug",
+    ",platform_utils=debug",
+    // Noisy third-party crates: silence below warn.
+    ",h2=warn",
+    ",rustls=warn",
+    ",rustyline=warn",
+    ",hyper=warn",
+    ",hyper_util=warn",
+    ",tower=warn",
+    ",Connection=warn",
+    ",tonic=warn",
+);
+
+pub(crate) struct GlobalSdkLogger {
+    /// Optional external log listener, that can receive a stream of log statements
+    pub(crate) log_listener: Option<Box<dyn Logger>>,
+}
+
+impl<S> Layer<S> for GlobalSdkLogger
+where
+    S: Subscriber,
+{
+    fn on_event(&self, event: &Event<'_>, _ctx: Context<'_, S>) {
+        if let Some(s) = self.log_listener.as_ref()
+            && let Some(entry) = log_entry_from_event(event)
+        {
+            s.log(entry);
+        }
+    }
+}
+
+/// Writes every event to the log file as a JSON-serialized [`LogEntry`], one
+/// per line.
+struct JsonFileLayer {
+    writer: Arc<RotatingFileWriter>,
+}
+
+impl<S> Layer<S> for JsonFileLayer
+where
+    S: Subscriber,
+{
+    fn on_event(&self, event: &Event<'_>, _ctx: Context<'_, S>) {
+        let Some(entry) = log_entry_from_event(event) else {
+            return;
+        };
+        if let Ok(mut line) = serde_json::to_vec(&entry) {
+            line.push(b'\n');
+            let _ = self.writer.as_ref().write_all(&line);
+        }
+    }
+}
+
+/// Collects the fields of an event, keyed by field name
+#[derive(Default)]
+struct FieldsVisitor {
+    fields: HashMap<String, String>,
+}
+
+impl Visit for FieldsVisitor {
+    fn record_str(&mut self, field: &Field, value: &str) {
+        self.fields
+            .insert(field.name().to_string(), value.to_string());
+    }
+
+    fn record_debug(&mut self, field: &Field, value: &dyn Debug) {
+        self.fields
+            .insert(field.name().to_string(), format!("{value:?}"));
+    }
+}
+
+/// Builds the structured [`LogEntry`] of a tracing event. Shared with the WASM
+/// bindings so both forward the same entries to the app logger.
+pub fn log_entry_from_event(event: &Event<'_>) -> Option<LogEntry> {
+    let mut line = String::new();
+    tracing_subscriber::fmt::format::DefaultFields::new()
+        .format_fields(Writer::new(&mut line), event)
+        .ok()?;
+    let mut visitor = FieldsVisitor::default();
+    event.record(&mut visitor);
+    let metadata = event.metadata();
+    Some(LogEntry {
+        line,
+        level: metadata.level().to_string(),
+        target: metadata.target().to_string(),
+        fields: visitor.fields,
+        timestamp: SystemTime::now()
+            .duration_since(UNIX_EPOCH)
+            .map(|d| u64::try_from(d.as_millis()).unwrap_or(u64::MAX))
+            .unwrap_or_default(),
+    })
+}
+
+pub(super) fn init_logging(
+    log_dir: Option<&str>,
+    app_logger: Option<Box<dyn Logger>>,
+    log_filter: Option<&str>,
+) -> Result<(), SdkError> {
+    init_logging_with_config(
+        LoggingConfig {
+            log_dir: log_dir.map(ToString::to_string),
+            log_filter: log_filter.map(ToString::to_string),
+            app_logger_filter: None,
+            file_format: LogFileFormat::Text,
+            max_file_size_bytes: None,
+            max_file_age_secs: None,
+            max_rotated_files: None,
+        },
+        app_logger,
+    )
+}
+
+pub(super) fn init_logging_with_config(
+    config: LoggingConfig,
+    app_logger: Option<Box<dyn Logger>>,
+) -> Result<(), SdkError> {
+    let filter = config.log_filter.as_deref().unwrap_or(DEFAULT_FILTER);
+    let app_logger_filter = config.app_logger_filter.as_deref().unwrap_or(filter);
+
+    let (app_logger_filter, app_logger_handle) =
+        reload::Layer::new(EnvFilter::new(app_logger_filter));
+    let mut reloaders = vec![filter_reloader(app_logger_handle)];
+    let registry = tracing_subscriber::registry()
+        .with(RecentErrorsLayer.with_filter(LevelFilter::ERROR))
+        .with(
+            GlobalSdkLogger {
+                log_listener: app_logger,
+            }
+            .with_filter(app_logger_filter),
+        );
+
+    let Some(log_dir) = config.log_dir else {
+        registry.try_init()?;
+        let _ = FILTER_RELOADERS.set(reloaders);
+        return Ok(());
+    };
+    let writer = Arc::new(
+        RotatingFileWriter::new(
+            log_dir,
+            LOG_FILE_NAME,
+            config.max_file_size_bytes,
+            config.max_file_age_secs.map(Duration::from_secs),
+            config
+                .max_rotated_files
+                .unwrap_or(DEFAULT_MAX_ROTATED_FILES),
+        )
+        .map_err(|e| SdkError::Generic(e.to_string()))?,
+    );
+    let (file_filter, file_handle) = reload::Layer::new(EnvFilter::new(filter));
+    reloaders.push(filter_reloader(file_handle));
+
+    match config.file_format {
+        LogFileFormat::Text => {
+            let fmt_layer = tracing_subscriber::fmt::layer()
+                .with_ansi(false)
+                .with_line_number(true)
+                .with_writer(writer);
+            // Bench-only: render span CLOSE lines (`time.busy` / `time.idle`)
+            // so the breez-bench aggregator can attribute per-RPC latency.
+            // The user's filter (`spark::operator_rpc=info`, etc.) controls
+            // which spans actually emit.
+            #[cfg(feature = "span-trace")]
+            let fmt_layer =
+                fmt_layer.with_span_events(tracing_subscriber::fmt::format::FmtSpan::CLOSE);
+            let fmt_layer = fmt_layer.with_filter(file_filter);
+            registry.with(fmt_layer).try_init()?;
+        }
+        LogFileFormat::Json => {
+            let json_layer = JsonFileLayer { writer }.with_filter(file_filter);
+            registry.with(json_layer).try_init()?;
+        }
+    }
+    let _ = FILTER_RELOADERS.set(reloaders);
+
+    Ok(())
+}
+
+/// Replaces the filter of every sink installed by `init_logging`, e.g. to
+/// raise a single session to `debug` without restarting the app.
+pub(crate) fn set_log_filter(filter: &str) -> Result<(), SdkError> {
+    // Validated up front, as `EnvFilter::new` silently drops bad directives
+    EnvFilter::try_new(filter)
+        .map_err(|e| SdkError::InvalidInput(format!("Invalid log filter: {e}")))?;
+    let reloaders = FILTER_RELOADERS.get().ok_or_else(|| {
+        SdkError::Generic("Logging was not initialized with init_logging".to_string())
+    })?;
+    for reload in reloaders {
+        reload(filter)?;
+    }
+    Ok(())
+}
+
+/// The most recent error entries, oldest first
+pub(crate) fn recent_errors() -> Vec<LogEntry> {
+    RECENT_ERRORS
+        .lock()
+        .map(|errors| errors.iter().cloned().collect())
+        .unwrap_or_default()
+}
+
+type FilterReloader = Box<dyn Fn(&str) -> Result<(), SdkError> + Send + Sync>;
+
+/// Filter reloaders of the sinks installed by `init_logging`
+static FILTER_RELOADERS: OnceLock<Vec<FilterReloader>> = OnceLock::new();
+
+fn filter_reloader<S: 'static>(handle: reload::Handle<EnvFilter, S>) -> FilterReloader {
+    Box::new(move |filter| {
+        handle
+            .reload(EnvFilter::new(filter))
+            .map_err(|e| SdkError::Generic(format!("Failed to set log filter: {e}")))
+    })
+}
+
+/// Error entries kept for diagnostics
+const RECENT_ERRORS_CAPACITY: usize = 20;
+
+static RECENT_ERRORS: Mutex<VecDeque<LogEntry>> = Mutex::new(VecDeque::new());
+
+/// Keeps the last [`RECENT_ERRORS_CAPACITY`] error entries for
+/// `dump_diagnostics`.
+struct RecentErrorsLayer;
+
+impl<S> Layer<S> for RecentErrorsLayer
+where
+    S: Subscriber,
+{
+    fn on_event(&self, event: &Event<'_>, _ctx: Context<'_, S>) {
+        let Some(entry) = log_entry_from_event(event) else {
+            return;
+        };
+        if let Ok(mut errors) = RECENT_ERRORS.lock() {
+            if errors.len() == RECENT_ERRORS_CAPACITY {
+                errors.pop_front();
+            }
+            errors.push_back(entry);
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::sync::{Arc, Mutex};
+
+    use tracing::{debug, info, trace};
+
+    use super::{EnvFilter, GlobalSdkLogger, Layer, SubscriberExt};
+    use crate::{LogEntry, Logger};
+
+    /// External logger that records every entry it receives.
+    struct EntryLogger {
+        entries: Arc<Mutex<Vec<LogEntry>>>,
+    }
+
+    impl Logger for EntryLogger {
+        fn log(&self, l: LogEntry) {
+            self.entries.lock().unwrap().push(l);
+        }
+    }
+
+    /// External logger that records the level of every entry it receives.
+    struct CapturingLogger {
+        levels: Arc<Mutex<Vec<String>>>,
+    }
+
+    impl Logger for CapturingLogger {
+        fn log(&self, l: LogEntry) {
+            self.levels.lock().unwrap().push(l.level);
+        }
+    }
+
+    /// Runs `emit` with a [`GlobalSdkLogger`] filtered by `filter` installed as
+    /// the thread-local default, returning the levels that reached the external
+    /// logger. `emit` must use a literal `target:` (the macros bake it into a
+    /// `static` callsite) matching a directive in `filter`.
+    fn forwarded_levels(filter: &str, emit: impl FnOnce()) -> Vec<String> {
+        let levels = Arc::new(Mutex::new(Vec::new()));
+        let subscriber = tracing_subscriber::registry().with(
+            GlobalSdkLogger {
+                log_listener: Some(Box::new(CapturingLogger {
+                    levels: levels.clone(),
+                })),
+            }
+            .with_filter(EnvFilter::new(filter)),
+        );
+
+        tracing::subscriber::with_default(subscriber, emit);
+
+        levels.lock().unwrap().clone()
+    }
+
+    #[test]
+    fn external_logger_respects_filter_level() {
+        // A `debug` filter forwards debug (and above) but not trace — this is
+        // the behaviour that regressed when the layer hard-capped at INFO.
+        let levels = forwarded_levels("brz_logtest_debug=debug", || {
+            info!(target: "brz_logtest_debug", "info");
+            debug!(target: "brz_logtest_debug", "debug");
+            trace!(target: "brz_logtest_debug", "trace");
+        });
+        assert!(levels.contains(&"INFO".to_string()), "got {levels:?}");
+        assert!(levels.contains(&"DEBUG".to_string()), "got {levels:?}");
+        assert!(!levels.contains(&"TRACE".to_string()), "got {levels:?}");
+
+        // A `trace` filter forwards trace too.
+        let levels = forwarded_levels("brz_logtest_trace=trace", || {
+            debug!(target: "brz_logtest_trace", "debug");
+            trace!(target: "brz_logtest_trace", "trace");
+        });
+        assert!(levels.contains(&"DEBUG".to_string()), "got {levels:?}");
+        assert!(levels.contains(&"TRACE".to_string()), "got {levels:?}");
+    }
+
+    #[test]
+    fn external_logger_receives_structured_entries() {
+        let entries = Arc::new(Mutex::new(Vec::new()));
+        let subscriber = tracing_subscriber::registry().with(
+            GlobalSdkLogger {
+                log_listener: Some(Box::new(EntryLogger {
+                    entries: entries.clone(),
+                })),
+            }
+            .with_filter(EnvFilter::new("brz_logtest_fields=info")),
+        );
+
+        tracing::subscriber::with_default(subscriber, || {
+            info!(target: "brz_logtest_fields", payment_id = "p1", amount = 21, "sent");
+        });
+
+        let entries = entries.lock().unwrap();
+        let entry = entries.first().expect("an entry");
+        assert_eq!(entry.level, "INFO");
+        assert_eq!(entry.target, "brz_logtest_fields");
+        assert_eq!(
+            entry.fields.get("message").map(String::as_str),
+            Some("sent")
+        );
+        assert_eq!(
+            entry.fields.get("payment_id").map(String::as_str),
+            Some("p1")
+        );
+        assert_eq!(entry.fields.get("amount").map(String::as_str), Some("21"));
+        assert!(entry.line.contains("sent"));
+        assert!(entry.timestamp > 0);
+    }
+}
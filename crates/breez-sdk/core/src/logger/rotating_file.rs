@@ -0,0 +1,188 @@
+use std::{
+    fs::{self, File, OpenOptions},
+    io::{self, Write},
+    path::PathBuf,
+    sync::Mutex,
+    time::{Duration, SystemTime},
+};
+
+/// Log file writer that rotates the file once it exceeds a size or age cap.
+///
+/// The active file is `<dir>/<file_name>`. On rotation it becomes
+/// `<file_name>.1`, older files shift up by one and those beyond
+/// `max_rotated_files` are deleted.
+pub(crate) struct RotatingFileWriter {
+    dir: PathBuf,
+    file_name: String,
+    max_size_bytes: Option<u64>,
+    max_age: Option<Duration>,
+    max_rotated_files: u32,
+    state: Mutex<ActiveFile>,
+}
+
+struct ActiveFile {
+    file: File,
+    size: u64,
+    opened_at: SystemTime,
+}
+
+impl RotatingFileWriter {
+    pub(crate) fn new(
+        dir: impl Into<PathBuf>,
+        file_name: &str,
+        max_size_bytes: Option<u64>,
+        max_age: Option<Duration>,
+        max_rotated_files: u32,
+    ) -> io::Result<Self> {
+        let dir = dir.into();
+        let state = open_active(&dir.join(file_name))?;
+        Ok(Self {
+            dir,
+            file_name: file_name.to_string(),
+            max_size_bytes,
+            max_age,
+            max_rotated_files,
+            state: Mutex::new(state),
+        })
+    }
+
+    fn path(&self, index: u32) -> PathBuf {
+        if index == 0 {
+            self.dir.join(&self.file_name)
+        } else {
+            self.dir.join(format!("{}.{index}", self.file_name))
+        }
+    }
+
+    fn needs_rotation(&self, active: &ActiveFile, incoming: usize) -> bool {
+        // A file holding nothing yet is never rotated, so a single oversized
+        // entry can't trigger a rotation per write
+        if active.size == 0 {
+            return false;
+        }
+        let too_big = self
+            .max_size_bytes
+            .is_some_and(|max| active.size.saturating_add(incoming as u64) > max);
+        let too_old = self.max_age.is_some_and(|max| {
+            SystemTime::now()
+                .duration_since(active.opened_at)
+                .is_ok_and(|age| age >= max)
+        });
+        too_big || too_old
+    }
+
+    fn rotate(&self, active: &mut ActiveFile) -> io::Result<()> {
+        active.file.flush()?;
+        if self.max_rotated_files == 0 {
+            fs::remove_file(self.path(0))?;
+        } else {
+            let _ = fs::remove_file(self.path(self.max_rotated_files));
+            for index in (0..self.max_rotated_files).rev() {
+                let from = self.path(index);
+                if from.exists() {
+                    fs::rename(from, self.path(index + 1))?;
+                }
+            }
+        }
+        *active = open_active(&self.path(0))?;
+        Ok(())
+    }
+}
+
+fn open_active(path: &PathBuf) -> io::Result<ActiveFile> {
+    let file = OpenOptions::new().create(true).append(true).open(path)?;
+    let metadata = file.metadata()?;
+    Ok(ActiveFile {
+        size: metadata.len(),
+        opened_at: metadata.created().unwrap_or_else(|_| SystemTime::now()),
+        file,
+    })
+}
+
+impl Write for &RotatingFileWriter {
+    fn write(&mut self, buf: &[u8]) -> io::Result<usize> {
+        let mut active = self
+            .state
+            .lock()
+            .map_err(|_| io::Error::other("log file lock poisoned"))?;
+        if self.needs_rotation(&active, buf.len()) {
+            self.rotate(&mut active)?;
+        }
+        let written = active.file.write(buf)?;
+        active.size = active.size.saturating_add(written as u64);
+        Ok(written)
+    }
+
+    fn flush(&mut self) -> io::Result<()> {
+        self.state
+            .lock()
+            .map_err(|_| io::Error::other("log file lock poisoned"))?
+            .file
+            .flush()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn temp_dir(name: &str) -> PathBuf {
+        let dir = std::env::temp_dir().join(format!("breez-log-{name}-{}", uuid::Uuid::new_v4()));
+        fs::create_dir_all(&dir).unwrap();
+        dir
+    }
+
+    #[test]
+    fn rotates_when_size_cap_is_exceeded() {
+        let dir = temp_dir("size");
+        let writer = RotatingFileWriter::new(&dir, "sdk.log", Some(10), None, 2).unwrap();
+        for line in ["aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"] {
+            (&writer).write_all(line.as_bytes()).unwrap();
+        }
+
+        assert_eq!(
+            fs::read_to_string(dir.join("sdk.log")).unwrap(),
+            "dddddddd\n"
+        );
+        assert_eq!(
+            fs::read_to_string(dir.join("sdk.log.1")).unwrap(),
+            "cccccccc\n"
+        );
+        assert_eq!(
+            fs::read_to_string(dir.join("sdk.log.2")).unwrap(),
+            "bbbbbbbb\n"
+        );
+        assert!(!dir.join("sdk.log.3").exists());
+        fs::remove_dir_all(dir).unwrap();
+    }
+
+    #[test]
+    fn rotates_when_age_cap_is_reached() {
+        let dir = temp_dir("age");
+        let writer =
+            RotatingFileWriter::new(&dir, "sdk.log", None, Some(Duration::ZERO), 1).unwrap();
+        (&writer).write_all(b"first\n").unwrap();
+        (&writer).write_all(b"second\n").unwrap();
+
+        assert_eq!(fs::read_to_string(dir.join("sdk.log")).unwrap(), "second\n");
+        assert_eq!(
+            fs::read_to_string(dir.join("sdk.log.1")).unwrap(),
+            "first\n"
+        );
+        fs::remove_dir_all(dir).unwrap();
+    }
+
+    #[test]
+    fn appends_without_caps() {
+        let dir = temp_dir("append");
+        let writer = RotatingFileWriter::new(&dir, "sdk.log", None, None, 5).unwrap();
+        (&writer).write_all(b"first\n").unwrap();
+        (&writer).write_all(b"second\n").unwrap();
+
+        assert_eq!(
+            fs::read_to_string(dir.join("sdk.log")).unwrap(),
+            "first\nsecond\n"
+        );
+        fs::remove_dir_all(dir).unwrap();
+    }
+}
@@ -0,0 +1,215 @@
+use std::sync::{
+    Arc,
+    atomic::{AtomicU64, Ordering},
+};
+
+use tokio::sync::RwLock;
+use tracing::{debug, info};
+use uuid::Uuid;
+
+use crate::{
+    Payment, PrepareLnurlPayRequest, PrepareLnurlPayResponse, PrepareSendPaymentRequest,
+    PrepareSendPaymentResponse, ReceivePaymentRequest,
+    error::SdkError,
+    events::{EventListener, SdkEvent},
+};
+
+/// A stage of a send or receive flow at which payment middleware runs.
+///
+/// For a send, the stages run in order: `BeforePrepareSend` (or
+/// `BeforePrepareLnurlPay`), then `BeforeSend` (or `BeforeLnurlPay`), then
+/// `AfterSettle` once the payment succeeded.
+#[allow(clippy::large_enum_variant)]
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum PaymentStage {
+    /// Before a payment is prepared with `prepare_send_payment`
+    BeforePrepareSend { request: PrepareSendPaymentRequest },
+    /// Before an LNURL payment is prepared with `prepare_lnurl_pay`
+    BeforePrepareLnurlPay { request: PrepareLnurlPayRequest },
+    /// Before a payment request is created with `receive_payment`
+    BeforeReceive { request: ReceivePaymentRequest },
+    /// Before a prepared payment is sent with `send_payment` or
+    /// `send_payment_async`
+    BeforeSend {
+        prepare_response: PrepareSendPaymentResponse,
+    },
+    /// Before a prepared LNURL payment is sent with `lnurl_pay`
+    BeforeLnurlPay {
+        prepare_response: PrepareLnurlPayResponse,
+    },
+    /// After a sent or received payment succeeded. Rejecting at this stage
+    /// has no effect.
+    AfterSettle { payment: Payment },
+}
+
+/// The decision of a [`PaymentMiddleware`] on a [`PaymentStage`]
+#[derive(Debug, Clone, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum MiddlewareDecision {
+    /// Continue the flow, running the next middleware
+    Continue,
+    /// Abort the flow, failing the call with [`SdkError::PaymentRejected`]
+    Reject { reason: String },
+}
+
+/// Trait for payment middleware, hooking into the send and receive flows
+#[cfg_attr(feature = "uniffi", uniffi::export(callback_interface))]
+#[macros::async_trait]
+pub trait PaymentMiddleware: Send + Sync {
+    /// Called when a flow reaches `stage`
+    async fn on_stage(&self, stage: PaymentStage) -> MiddlewareDecision;
+}
+
+/// The registered payment middleware, run in registration order.
+#[derive(Default)]
+pub(crate) struct MiddlewarePipeline {
+    middleware_index: AtomicU64,
+    middleware: RwLock<Vec<(String, Arc<dyn PaymentMiddleware>)>>,
+}
+
+impl MiddlewarePipeline {
+    /// Appends middleware to the pipeline, returning its identifier
+    pub(crate) async fn add(&self, middleware: Box<dyn PaymentMiddleware>) -> String {
+        let index = self.middleware_index.fetch_add(1, Ordering::Relaxed);
+        let id = format!("middleware_{}-{}", index, Uuid::new_v4());
+        self.middleware
+            .write()
+            .await
+            .push((id.clone(), Arc::from(middleware)));
+        id
+    }
+
+    /// Removes middleware by its identifier
+    pub(crate) async fn remove(&self, id: &str) -> bool {
+        let mut middleware = self.middleware.write().await;
+        let len = middleware.len();
+        middleware.retain(|(middleware_id, _)| middleware_id != id);
+        middleware.len() != len
+    }
+
+    /// Removes all middleware.
+    ///
+    /// Like event listeners, middleware is owned by the SDK, so middleware
+    /// that references the SDK pins the whole instance until removed.
+    pub(crate) async fn clear(&self) {
+        self.middleware.write().await.clear();
+    }
+
+    /// Runs `stage` through the pipeline, stopping at the first middleware
+    /// that rejects it.
+    pub(crate) async fn run(&self, stage: PaymentStage) -> Result<(), SdkError> {
+        // Clone the list so middleware can add or remove middleware from
+        // `on_stage` without deadlocking on the lock.
+        let middleware = self.middleware.read().await.clone();
+        for (id, mw) in &middleware {
+            if let MiddlewareDecision::Reject { reason } = mw.on_stage(stage.clone()).await {
+                info!("Payment middleware {id} rejected stage: {reason}");
+                return Err(SdkError::PaymentRejected(reason));
+            }
+        }
+        Ok(())
+    }
+}
+
+/// Runs the `AfterSettle` stage for every succeeded payment.
+///
+/// Registered as an internal listener, so settled payments reach the
+/// middleware even if event middleware suppresses them for listeners.
+pub(crate) struct SettledPaymentListener {
+    pub(crate) pipeline: Arc<MiddlewarePipeline>,
+}
+
+#[macros::async_trait]
+impl EventListener for SettledPaymentListener {
+    async fn on_event(&self, event: SdkEvent) {
+        if let SdkEvent::PaymentSucceeded { payment } = event
+            && let Err(e) = self
+                .pipeline
+                .run(PaymentStage::AfterSettle { payment })
+                .await
+        {
+            debug!("Ignoring middleware rejection after settlement: {e}");
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::sync::Mutex;
+
+    use super::*;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    struct RecordingMiddleware {
+        name: &'static str,
+        decision: MiddlewareDecision,
+        calls: Arc<Mutex<Vec<&'static str>>>,
+    }
+
+    #[macros::async_trait]
+    impl PaymentMiddleware for RecordingMiddleware {
+        async fn on_stage(&self, _stage: PaymentStage) -> MiddlewareDecision {
+            self.calls.lock().unwrap().push(self.name);
+            self.decision.clone()
+        }
+    }
+
+    fn stage() -> PaymentStage {
+        PaymentStage::BeforeReceive {
+            request: ReceivePaymentRequest {
+                payment_method: crate::ReceivePaymentMethod::SparkAddress,
+            },
+        }
+    }
+
+    #[macros::async_test_all]
+    async fn test_pipeline_runs_in_order_and_stops_on_reject() {
+        let calls = Arc::new(Mutex::new(Vec::new()));
+        let pipeline = MiddlewarePipeline::default();
+        for (name, decision) in [
+            ("first", MiddlewareDecision::Continue),
+            (
+                "second",
+                MiddlewareDecision::Reject {
+                    reason: "blocked".to_string(),
+                },
+            ),
+            ("third", MiddlewareDecision::Continue),
+        ] {
+            pipeline
+                .add(Box::new(RecordingMiddleware {
+                    name,
+                    decision,
+                    calls: calls.clone(),
+                }))
+                .await;
+        }
+
+        let result = pipeline.run(stage()).await;
+        assert!(matches!(result, Err(SdkError::PaymentRejected(reason)) if reason == "blocked"));
+        assert_eq!(*calls.lock().unwrap(), vec!["first", "second"]);
+    }
+
+    #[macros::async_test_all]
+    async fn test_pipeline_remove() {
+        let calls = Arc::new(Mutex::new(Vec::new()));
+        let pipeline = MiddlewarePipeline::default();
+        let id = pipeline
+            .add(Box::new(RecordingMiddleware {
+                name: "rejecting",
+                decision: MiddlewareDecision::Reject {
+                    reason: "blocked".to_string(),
+                },
+                calls: calls.clone(),
+            }))
+            .await;
+
+        assert!(pipeline.remove(&id).await);
+        assert!(!pipeline.remove(&id).await);
+        assert!(pipeline.run(stage()).await.is_ok());
+        assert!(calls.lock().unwrap().is_empty());
+    }
+}
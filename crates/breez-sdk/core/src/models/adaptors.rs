@@ -325,6 +325,8 @@ impl TryFrom<WalletTransfer> for Payment {
             method: PaymentMethod::from_transfer(&transfer),
             details,
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         })
     }
 }
@@ -382,6 +384,8 @@ impl Payment {
             method: PaymentMethod::Lightning,
             details: Some(details),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         })
     }
 }
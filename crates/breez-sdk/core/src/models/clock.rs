@@ -0,0 +1,10 @@
+/// A source of the current time, used by the SDK to decide whether invoices,
+/// HTLCs, quotes, locks and claim deadlines have expired.
+///
+/// The system clock is used unless one is set with `SdkBuilder::with_clock`,
+/// which lets tests control time, for example to fast-forward past an expiry.
+#[cfg_attr(feature = "uniffi", uniffi::export(with_foreign))]
+pub trait Clock: Send + Sync {
+    /// Returns the current time in seconds since the Unix epoch
+    fn now_secs(&self) -> u64;
+}
@@ -0,0 +1,58 @@
+use serde::{Deserialize, Serialize};
+use thiserror::Error;
+
+use crate::InputType;
+
+/// When an [`InputParser`] runs relative to the built-in parsers
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum InputParserPriority {
+    /// Runs before the built-in parsers, so it can take over inputs the SDK
+    /// would otherwise recognize
+    BeforeBuiltIn,
+    /// Runs only for inputs the built-in parsers and the
+    /// [`ExternalInputParser`](crate::ExternalInputParser)s don't recognize
+    AfterBuiltIn,
+}
+
+/// Describes a registered [`InputParser`]
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct InputParserInfo {
+    /// The unique name of the parser, e.g. `nfc-tags`
+    pub name: String,
+    pub priority: InputParserPriority,
+    /// How long the parser may take to resolve an input before it's
+    /// cancelled and the input is treated as not recognized. Defaults to 10
+    /// seconds.
+    pub timeout_secs: Option<u32>,
+}
+
+#[derive(Debug, Error, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Error))]
+pub enum InputParserError {
+    #[error("Service connectivity: {0}")]
+    ServiceConnectivity(String),
+    #[error("Generic: {0}")]
+    Generic(String),
+}
+
+/// This interface is used to recognize inputs the SDK doesn't, for example
+/// app-specific QR codes or NFC payloads, resolving them with any async work
+/// they need such as a request to the app's backend.
+///
+/// Parsers are registered with `SdkBuilder::with_input_parser` and consulted
+/// by [`BreezSdk::parse`](crate::BreezSdk::parse) according to their
+/// priority, in registration order. A parse that takes longer than the
+/// parser's timeout, or is still running when the SDK is disconnected, is
+/// cancelled.
+#[cfg_attr(feature = "uniffi", uniffi::export(with_foreign))]
+#[macros::async_trait]
+pub trait InputParser: Send + Sync {
+    /// Returns the name, priority and timeout of the parser. Called once
+    /// when the SDK is built.
+    fn info(&self) -> InputParserInfo;
+    /// Parses the trimmed input, or returns `None` if the parser doesn't
+    /// recognize it. An error fails the parse.
+    async fn parse(&self, input: String) -> Result<Option<InputType>, InputParserError>;
+}
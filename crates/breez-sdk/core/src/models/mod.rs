@@ -1,6 +1,12 @@
 pub(crate) mod adaptors;
+pub mod clock;
+pub use clock::*;
 pub mod payment_observer;
 pub use payment_observer::*;
+pub mod input_parser;
+pub use input_parser::*;
+pub mod plugin;
+pub use plugin::*;
 
 // Re-export public conversion types from the conversion module
 pub use crate::token_conversion::{
@@ -10,7 +16,7 @@ pub use crate::token_conversion::{
 };
 
 use core::fmt;
-use lnurl_models::RecoverLnurlPayResponse;
+use lnurl_models::{RecoverLnurlPayResponse, RegisteredLnurlPay};
 use serde::{Deserialize, Serialize};
 use serde_json::Value;
 use std::{
@@ -21,8 +27,9 @@ use std::{
 
 use crate::{
     BitcoinAddressDetails, BitcoinChainService, BitcoinNetwork, Bolt11InvoiceDetails,
-    ExternalInputParser, FiatCurrency, LnurlPayRequestDetails, LnurlWithdrawRequestDetails, Rate,
-    SdkError, SparkInvoiceDetails, SuccessAction, SuccessActionProcessed,
+    CrossChainAddressDetails, ExternalInputParser, FiatCurrency, HistoricalRate, InputType,
+    LnurlAuthRequestDetails, LnurlPayRequestDetails, LnurlWithdrawRequestDetails, Rate,
+    RateResolution, SdkError, SparkInvoiceDetails, SuccessAction, SuccessActionProcessed,
     cross_chain::{CrossChainFeeMode, CrossChainProviderContext, CrossChainRoutePair},
     error::DepositClaimError,
 };
@@ -198,6 +205,8 @@ pub enum PaymentMethod {
     Token,
     Deposit,
     Withdraw,
+    /// Sent by a payment rail plugin
+    Plugin,
     Unknown,
 }
 
@@ -209,6 +218,7 @@ impl Display for PaymentMethod {
             PaymentMethod::Token => write!(f, "token"),
             PaymentMethod::Deposit => write!(f, "deposit"),
             PaymentMethod::Withdraw => write!(f, "withdraw"),
+            PaymentMethod::Plugin => write!(f, "plugin"),
             PaymentMethod::Unknown => write!(f, "unknown"),
         }
     }
@@ -224,6 +234,7 @@ impl FromStr for PaymentMethod {
             "token" => Ok(PaymentMethod::Token),
             "deposit" => Ok(PaymentMethod::Deposit),
             "withdraw" => Ok(PaymentMethod::Withdraw),
+            "plugin" => Ok(PaymentMethod::Plugin),
             "unknown" => Ok(PaymentMethod::Unknown),
             _ => Err(()),
         }
@@ -253,9 +264,36 @@ pub struct Payment {
     pub details: Option<PaymentDetails>,
     /// If set, this payment involved a conversion before the payment
     pub conversion_details: Option<ConversionDetails>,
+    /// The fiat value of the payment at completion, recorded when
+    /// `Config::payment_fiat_currency` is set
+    pub fiat_value: Option<PaymentFiatValue>,
+    /// What the payer attached to a received payment, such as an LNURL
+    /// comment or the description of a Spark invoice
+    pub receive_metadata: Option<ReceiveMetadata>,
+}
+
+/// The fiat value of a payment, from the exchange rate at the time it
+/// completed.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct PaymentFiatValue {
+    /// The fiat currency code, e.g. `USD`
+    pub currency: String,
+    /// The exchange rate, in fiat units per bitcoin, or per whole token for
+    /// token payments
+    pub rate: f64,
+    /// The payment amount in fiat units
+    pub amount: f64,
+    /// The time the rate was recorded, as a unix timestamp in seconds
+    pub recorded_at: u64,
 }
 
 impl Payment {
+    /// Sets [`Payment::receive_metadata`] from the payment details
+    pub(crate) fn fill_receive_metadata(&mut self) {
+        self.receive_metadata = ReceiveMetadata::from_payment(self);
+    }
+
     /// Returns `true` if this payment is a child of a conversion operation.
     ///
     /// Conversion operations (stable balance, ongoing sends) create internal child
@@ -694,6 +732,292 @@ pub struct Config {
     /// run background work (e.g. web sockets), so enabling is left to the
     /// caller. Cross-chain sends are only supported on mainnet.
     pub cross_chain_config: Option<CrossChainConfig>,
+
+    /// Restricts the Spark operators and service provider the SDK may be
+    /// configured with.
+    ///
+    /// When set, the SDK refuses to start if any operator in the effective
+    /// Spark configuration (either [`Config::spark_config`] or the built-in
+    /// defaults) is not in the allowlist, or if the configured service
+    /// provider identity key differs from the expected one. This guards
+    /// against an unexpected change of the configuration only: the check runs
+    /// on the configuration before connecting, and neither the operators' TLS
+    /// certificates nor their identity keys are pinned on the connections.
+    /// `None` (default) disables the check.
+    pub operator_allowlist: Option<OperatorAllowlistConfig>,
+
+    /// Maximum adverse deviation, in basis points, between the rate a token
+    /// conversion is executed at and the price reported by the conversion
+    /// price source set with `SdkBuilder::with_conversion_price_source`.
+    ///
+    /// Only applies when a price source is set. Conversions beyond this
+    /// deviation are refused. `None` uses
+    /// [`DEFAULT_CONVERSION_MAX_PRICE_DEVIATION_BPS`](crate::token_conversion::DEFAULT_CONVERSION_MAX_PRICE_DEVIATION_BPS).
+    pub conversion_max_price_deviation_bps: Option<u32>,
+
+    /// Minimum amounts of each asset that are displayed and payable.
+    ///
+    /// When set, sends below the payable minimum of their asset are rejected
+    /// by `prepare_send_payment`, and `get_info` can leave out dust balances.
+    /// `None` (default) applies no minimums.
+    pub dust_config: Option<DustConfig>,
+
+    /// Which tokens `get_info` lists, to keep unsolicited tokens out of the
+    /// balances. Tokens can also be hidden per wallet with
+    /// `BreezSdk::hide_token`. `None` (default) lists all tokens.
+    pub token_visibility: Option<TokenVisibilityConfig>,
+
+    /// Whether outgoing Spark HTLCs that expired without being claimed are
+    /// refunded automatically during sync, emitting
+    /// [`SdkEvent::HtlcRefunded`](crate::SdkEvent::HtlcRefunded).
+    ///
+    /// When `false`, expired HTLCs are refunded with
+    /// `BreezSdk::refund_htlc_payment`. Default is `true`.
+    pub auto_refund_htlc_payments: bool,
+
+    /// Maximum number of incoming transfers claimed per second.
+    ///
+    /// Transfers received over the cap are queued and claimed as the rate
+    /// allows, protecting low-power devices from bursts of incoming payments
+    /// such as zap storms. The queue depth is reported by `get_info` and
+    /// [`SdkEvent::ClaimQueueChanged`](crate::SdkEvent::ClaimQueueChanged)
+    /// events. `None` (default) claims transfers as they arrive.
+    pub max_claims_per_second: Option<u32>,
+
+    /// Whether each Bitcoin address request returns a fresh deposit address.
+    ///
+    /// When `true`, [`ReceivePaymentMethod::BitcoinAddress`] rotates to a new
+    /// address unless `new_address` is explicitly `false`, so that each
+    /// customer can be given a unique address. All issued addresses keep
+    /// crediting the wallet. Default is `false`.
+    pub rotate_deposit_address: bool,
+
+    /// The faucet used by `BreezSdk::request_test_funds` to fund the wallet.
+    ///
+    /// Only used on regtest, where it defaults to the Lightspark regtest
+    /// faucet. `None` disables requesting test funds.
+    pub faucet_config: Option<FaucetConfig>,
+
+    /// The payment rails the SDK may send and receive over.
+    ///
+    /// Preparing, sending or receiving a payment over a rail missing from
+    /// this list fails with [`SdkError::PaymentRailDisabled`](crate::SdkError::PaymentRailDisabled)
+    /// before any quote is fetched. Cross-chain sends are enabled separately
+    /// with `cross_chain_config`. Default is all rails.
+    pub enabled_rails: Vec<PaymentRail>,
+
+    /// The fiat currency, e.g. `USD`, in which to record the value of
+    /// payments when they complete. The recorded value is returned as
+    /// [`Payment::fiat_value`]. Default is `None`, which disables recording.
+    pub payment_fiat_currency: Option<String>,
+
+    /// Caps on the amount sent over a rolling window, per rail or per token.
+    ///
+    /// A send that would exceed a cap fails with
+    /// [`SdkError::SpendingCapExceeded`](crate::SdkError::SpendingCapExceeded)
+    /// before any funds move. The remaining allowance of each cap is returned
+    /// by [`BreezSdk::get_remaining_allowance`](crate::BreezSdk::get_remaining_allowance).
+    /// Default is no caps.
+    pub spending_caps: Vec<SpendingCap>,
+
+    /// Policy for converting residual token balances to sats during sync.
+    ///
+    /// Each sweep is reported with [`SdkEvent::TokenSweep`](crate::SdkEvent::TokenSweep)
+    /// events. The active stable balance token is never swept. Default is
+    /// `None`, which keeps all token balances.
+    pub token_sweep_policy: Option<TokenSweepPolicy>,
+
+    /// Overrides of the external services the SDK reaches, for deployments
+    /// that use mirrored or self-hosted instances behind a firewall.
+    ///
+    /// The Spark operators and service provider are overridden with
+    /// [`Config::spark_config`]. Default is `None`, which uses the Breez
+    /// hosted services.
+    pub service_endpoints: Option<ServiceEndpoints>,
+
+    /// How long, in seconds, [`BreezSdk::parse`](crate::BreezSdk::parse)
+    /// keeps the results of inputs resolved with a network lookup, such as
+    /// Lightning addresses and LNURLs, so that parsing them again doesn't
+    /// repeat the lookup. LNURL-withdraw and LNURL-auth results are never
+    /// cached, as their challenge may be single-use. Default is 300. Set to 0
+    /// to disable caching.
+    pub parse_cache_ttl_secs: u32,
+}
+
+/// URLs of self-hosted or mirrored services, see [`Config::service_endpoints`].
+/// Unset fields keep their default.
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ServiceEndpoints {
+    /// Base URL of the LNURL server backing Lightning addresses and payment
+    /// links, e.g. `https://lnurl.example.com`. Defaults to `https://` followed
+    /// by [`Config::lnurl_domain`], which stays the domain of the Lightning
+    /// addresses.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub lnurl_server_url: Option<String>,
+    /// gRPC URL of the Breez server providing fiat currencies and rates.
+    /// Ignored when a fiat service is set with `SdkBuilder::with_fiat_service`.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub fiat_rates_url: Option<String>,
+    /// gRPC URL of the Breez server signing the provider URLs of
+    /// `buy_bitcoin`.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub buy_bitcoin_url: Option<String>,
+}
+
+/// Which token balances are converted to sats, and how often.
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct TokenSweepPolicy {
+    /// Balances estimated to convert to fewer sats than this are swept.
+    pub max_balance_sat: u64,
+    /// Tokens the wallet expects to hold. Only their balances below
+    /// `max_balance_sat` are swept.
+    pub recognized_token_identifiers: Vec<String>,
+    /// Whether the full balance of tokens missing from
+    /// `recognized_token_identifiers` is swept, regardless of its value.
+    pub sweep_unrecognized_tokens: bool,
+    /// Minimum time between sweeps, in seconds.
+    pub interval_secs: u64,
+}
+
+/// A network a payment can be sent or received over.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum PaymentRail {
+    /// Lightning payments, including LNURL and Lightning addresses.
+    Lightning,
+    /// On-chain deposits and withdrawals.
+    Bitcoin,
+    /// Spark transfers, including token payments.
+    Spark,
+}
+
+impl fmt::Display for PaymentRail {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            PaymentRail::Lightning => write!(f, "Lightning"),
+            PaymentRail::Bitcoin => write!(f, "Bitcoin"),
+            PaymentRail::Spark => write!(f, "Spark"),
+        }
+    }
+}
+
+/// A cap on the amount sent over a rolling window.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SpendingCap {
+    pub scope: SpendingCapScope,
+    /// The maximum amount sent within the window, including fees, in
+    /// satoshis for a rail or the plugins, or token base units for a token.
+    pub max_amount: u128,
+    /// The length of the rolling window, in seconds.
+    pub window_secs: u64,
+}
+
+/// The payments a [`SpendingCap`] applies to.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum SpendingCapScope {
+    /// Bitcoin payments sent over the rail. Token payments are capped
+    /// per token instead.
+    Rail { rail: PaymentRail },
+    /// Payments of the token, over any rail.
+    Token { token_identifier: String },
+    /// Payments sent through the payment rail plugins.
+    Plugins,
+}
+
+impl fmt::Display for SpendingCapScope {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            SpendingCapScope::Rail { rail } => write!(f, "{rail}"),
+            SpendingCapScope::Token { token_identifier } => write!(f, "token {token_identifier}"),
+            SpendingCapScope::Plugins => write!(f, "plugins"),
+        }
+    }
+}
+
+/// A regtest faucet that funds Bitcoin addresses.
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct FaucetConfig {
+    /// The GraphQL endpoint of the faucet.
+    pub url: String,
+    /// Basic authentication credentials, when the faucet requires them.
+    pub credentials: Option<Credentials>,
+}
+
+/// Minimum amounts below which balances and payments are treated as dust.
+///
+/// Sharing these rules through the config keeps wallet UIs consistent across
+/// bindings, without each of them filtering balances on its own.
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DustConfig {
+    /// Limits for the Bitcoin balance and payments, in sats.
+    pub sats: AssetDustLimit,
+    /// Limits for tokens, in token base units, keyed by token identifier.
+    /// Tokens without an entry have no limits.
+    pub tokens: HashMap<String, AssetDustLimit>,
+    /// Whether `get_info` leaves out token balances below their displayable
+    /// minimum, and reports a sats balance below its displayable minimum as 0.
+    pub suppress_dust_balances: bool,
+}
+
+impl DustConfig {
+    /// Returns the limits of the asset, which is Bitcoin when no token
+    /// identifier is given.
+    pub(crate) fn limit(&self, token_identifier: Option<&str>) -> Option<&AssetDustLimit> {
+        match token_identifier {
+            Some(token_identifier) => self.tokens.get(token_identifier),
+            None => Some(&self.sats),
+        }
+    }
+
+    /// Removes the balances below their displayable minimum from `info`.
+    pub(crate) fn suppress_dust_balances(&self, info: &mut GetInfoResponse) {
+        if u128::from(info.balance_sats) < self.sats.min_displayable {
+            info.balance_sats = 0;
+        }
+        info.token_balances.retain(|token_identifier, balance| {
+            self.tokens
+                .get(token_identifier)
+                .is_none_or(|limit| balance.balance >= limit.min_displayable)
+        });
+    }
+}
+
+/// The tokens listed by `get_info`, applied before the tokens the user hid or
+/// unhid with `BreezSdk::hide_token` and `BreezSdk::unhide_token`.
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct TokenVisibilityConfig {
+    /// When set, only these tokens are listed.
+    pub allowlist: Option<Vec<String>>,
+    /// Tokens that aren't listed, such as known spam tokens.
+    pub denylist: Vec<String>,
+}
+
+impl TokenVisibilityConfig {
+    /// Whether the token is hidden by the config
+    pub(crate) fn hides(&self, token_identifier: &str) -> bool {
+        self.denylist.iter().any(|t| t == token_identifier)
+            || self
+                .allowlist
+                .as_ref()
+                .is_some_and(|allowlist| !allowlist.iter().any(|t| t == token_identifier))
+    }
+}
+
+/// Dust limits of a single asset.
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct AssetDustLimit {
+    /// Balances below this amount are considered dust and can be hidden.
+    pub min_displayable: u128,
+    /// Payments below this amount are rejected.
+    pub min_payable: u128,
 }
 
 /// Configuration for cross-chain sends.
@@ -741,6 +1065,48 @@ pub struct LeafOptimizationConfig {
     ///
     /// Default value is 1.
     pub multiplicity: u8,
+    /// Conditions automatic optimization runs are gated on. See
+    /// [`LeafOptimizationPolicy`].
+    ///
+    /// When set, automatic optimization is checked against the policy after
+    /// each wallet sync instead of running whenever the leaf set changes.
+    /// Has no effect when [`Self::auto_enabled`] is false.
+    ///
+    /// Default value is `None`.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub auto_policy: Option<LeafOptimizationPolicy>,
+}
+
+/// Conditions under which automatic leaf optimization runs. All set
+/// conditions must hold for a run to start.
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LeafOptimizationPolicy {
+    /// Only optimize when the wallet holds more than this many leaves.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub min_leaf_count: Option<u32>,
+    /// Only optimize when no payment was made or received in the last
+    /// `min_idle_secs` seconds, and no payment is in flight.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub min_idle_secs: Option<u64>,
+    /// Only optimize when the host reported an unmetered connection through
+    /// [`BreezSdk::set_host_conditions`](crate::BreezSdk::set_host_conditions).
+    /// Optimization is skipped while the connection type is unknown.
+    pub unmetered_only: bool,
+    /// Hours of the day during which optimization never starts.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub quiet_hours: Option<QuietHours>,
+}
+
+/// A daily window, in UTC hours. The window wraps around midnight when
+/// `start_hour` is greater than `end_hour`, e.g. 22 to 6.
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct QuietHours {
+    /// First hour of the window (0-23), inclusive
+    pub start_hour: u8,
+    /// Last hour of the window (0-23), exclusive
+    pub end_hour: u8,
 }
 
 /// Configuration for token-output optimization.
@@ -845,6 +1211,16 @@ pub enum StableBalanceActiveLabel {
     Unset,
 }
 
+/// Specifies how to update the display currency.
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum DisplayCurrency {
+    /// Record payment fiat values in the given currency, e.g. `EUR`.
+    Set { currency: String },
+    /// Fall back to [`Config::payment_fiat_currency`].
+    Unset,
+}
+
 /// Configuration for a custom Spark environment.
 ///
 /// When set on [`Config`], overrides the default Spark operator pool,
@@ -869,6 +1245,12 @@ pub struct SparkConfig {
     /// more first consolidates the wallet's token outputs. Unset uses the SDK
     /// default (500).
     pub max_token_transaction_inputs: Option<u32>,
+    /// Confirmations the operators require before a deposit can be claimed.
+    /// Unset uses the SDK default (3), the policy of the default operators.
+    pub deposit_confirmations: Option<u32>,
+    /// Expected time, in seconds, for the operators to let an incoming
+    /// transfer be claimed. Unset uses the SDK default (5).
+    pub expected_transfer_claim_secs: Option<u64>,
 }
 
 /// A Spark signing operator.
@@ -903,6 +1285,39 @@ pub struct SparkSspConfig {
     pub schema_endpoint: Option<String>,
 }
 
+/// Allowlist of Spark operators and the expected service provider identity.
+///
+/// See [`Config::operator_allowlist`].
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct OperatorAllowlistConfig {
+    /// The operators the SDK is allowed to connect to. Every signing operator
+    /// in the effective Spark configuration must match one entry.
+    pub operators: Vec<AllowedOperator>,
+    /// Hex-encoded compressed public key the service provider (SSP) must be
+    /// configured with. Unset allows any SSP identity key.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub ssp_identity_public_key: Option<String>,
+}
+
+/// A single allowed Spark operator.
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct AllowedOperator {
+    /// Hex-encoded compressed identity public key of the operator.
+    pub identity_public_key: String,
+    /// gRPC address the operator must be reached at. Unset allows any address
+    /// for this identity key.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub address: Option<String>,
+    /// PEM-encoded CA certificate used as the trust root for this operator's
+    /// TLS connections, instead of the default web PKI roots. Any certificate
+    /// issued by this CA is accepted, so it is not a pin of the operator's
+    /// own certificate. Ignored on WASM, where TLS is handled by the browser.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub ca_cert_pem: Option<String>,
+}
+
 impl Config {
     /// Validates the configuration.
     ///
@@ -914,6 +1329,44 @@ impl Config {
             ));
         }
 
+        if self.max_claims_per_second == Some(0) {
+            return Err(SdkError::InvalidInput(
+                "max_claims_per_second must be greater than 0".to_string(),
+            ));
+        }
+
+        if self.spending_caps.iter().any(|cap| cap.window_secs == 0) {
+            return Err(SdkError::InvalidInput(
+                "spending cap window_secs must be greater than 0".to_string(),
+            ));
+        }
+
+        if self
+            .token_sweep_policy
+            .as_ref()
+            .is_some_and(|policy| policy.interval_secs == 0)
+        {
+            return Err(SdkError::InvalidInput(
+                "token sweep interval_secs must be greater than 0".to_string(),
+            ));
+        }
+
+        if let Some(endpoints) = &self.service_endpoints {
+            for (name, url) in [
+                ("lnurl_server_url", &endpoints.lnurl_server_url),
+                ("fiat_rates_url", &endpoints.fiat_rates_url),
+                ("buy_bitcoin_url", &endpoints.buy_bitcoin_url),
+            ] {
+                if let Some(url) = url
+                    && !(url.starts_with("https://") || url.starts_with("http://"))
+                {
+                    return Err(SdkError::InvalidInput(format!(
+                        "service_endpoints.{name} must be an http(s) URL"
+                    )));
+                }
+            }
+        }
+
         if let Some(sb) = &self.stable_balance_config {
             if sb.tokens.is_empty() {
                 return Err(SdkError::InvalidInput(
@@ -965,6 +1418,18 @@ impl Config {
             }
         }
 
+        if let Some(quiet_hours) = self
+            .leaf_optimization_config
+            .auto_policy
+            .as_ref()
+            .and_then(|policy| policy.quiet_hours.as_ref())
+            && (quiet_hours.start_hour > 23 || quiet_hours.end_hour > 23)
+        {
+            return Err(SdkError::InvalidInput(
+                "leaf optimization quiet hours must be between 0 and 23".to_string(),
+            ));
+        }
+
         let token_opt = &self.token_optimization_config;
         if token_opt.min_outputs_threshold <= 1 {
             return Err(SdkError::InvalidInput(
@@ -982,6 +1447,22 @@ impl Config {
             ));
         }
 
+        if let Some(allowlist) = &self.operator_allowlist
+            && allowlist.operators.is_empty()
+        {
+            return Err(SdkError::InvalidInput(
+                "operator allowlist requires at least one allowed operator".to_string(),
+            ));
+        }
+
+        if let Some(bps) = self.conversion_max_price_deviation_bps
+            && bps > 10_000
+        {
+            return Err(SdkError::InvalidInput(
+                "conversion max price deviation must be at most 10000 bps".to_string(),
+            ));
+        }
+
         if let Some(cc) = &self.cross_chain_config {
             if self.network != Network::Mainnet {
                 return Err(SdkError::InvalidInput(format!(
@@ -1035,6 +1516,43 @@ impl Config {
     }
 }
 
+/// Upper bound on the fees a send may incur. It is checked against the fee
+/// effective when the payment is sent, not the one quoted by the prepare step.
+#[derive(Debug, Clone, Copy, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum SendMaxFee {
+    // Fixed fee amount in sats
+    Fixed { amount: u64 },
+    // Fee proportional to the amount sent, in parts per million
+    Proportional { ppm: u32 },
+}
+
+impl SendMaxFee {
+    /// Returns the maximum fee in sats allowed for sending `amount_sats`.
+    pub(crate) fn max_fee_sats(&self, amount_sats: u64) -> u64 {
+        match self {
+            SendMaxFee::Fixed { amount } => *amount,
+            SendMaxFee::Proportional { ppm } => {
+                let max_fee = u128::from(amount_sats).saturating_mul(u128::from(*ppm)) / 1_000_000;
+                u64::try_from(max_fee).unwrap_or(u64::MAX)
+            }
+        }
+    }
+
+    /// Fails with [`SdkError::MaxFeeExceeded`] if `fee_sats` is above the
+    /// maximum fee allowed for sending `amount_sats`.
+    pub(crate) fn check(&self, amount_sats: u64, fee_sats: u64) -> Result<(), SdkError> {
+        let max_fee_sats = self.max_fee_sats(amount_sats);
+        if fee_sats > max_fee_sats {
+            return Err(SdkError::MaxFeeExceeded {
+                fee_sats,
+                max_fee_sats,
+            });
+        }
+        Ok(())
+    }
+}
+
 #[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
 pub enum MaxFee {
@@ -1104,6 +1622,11 @@ pub struct ClaimDepositRequest {
     pub vout: u32,
     #[cfg_attr(feature = "uniffi", uniffi(default=None))]
     pub max_fee: Option<MaxFee>,
+    /// If set, providing the same idempotency key for multiple requests will ensure that only one
+    /// claim is made. If an idempotency key is re-used, the same payment will be returned.
+    /// The idempotency key must be a valid UUID.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub idempotency_key: Option<String>,
 }
 
 #[derive(Debug, Clone, Serialize)]
@@ -1112,6 +1635,47 @@ pub struct ClaimDepositResponse {
     pub payment: Payment,
 }
 
+/// A deposit identified by its outpoint.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DepositOutpoint {
+    pub txid: String,
+    pub vout: u32,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ClaimDepositsRequest {
+    /// The deposits to claim, in the order they are claimed
+    pub deposits: Vec<DepositOutpoint>,
+    /// The maximum fee in satoshis to claim all the deposits. Deposits whose
+    /// fee doesn't fit in what is left of it are not claimed. If not set, each
+    /// claim is bounded by `max_deposit_claim_fee` instead.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub max_total_fee_sat: Option<u64>,
+}
+
+/// The outcome of claiming one of the deposits of a [`ClaimDepositsRequest`].
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ClaimDepositResult {
+    pub txid: String,
+    pub vout: u32,
+    /// The claim payment, set if the deposit was claimed
+    pub payment: Option<Payment>,
+    /// The reason the deposit was not claimed
+    pub error: Option<String>,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ClaimDepositsResponse {
+    /// One result per requested deposit, in the requested order
+    pub results: Vec<ClaimDepositResult>,
+    /// The total fee in satoshis paid for the claimed deposits
+    pub total_fee_sat: u64,
+}
+
 #[derive(Debug, Clone, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct RefundDepositRequest {
@@ -1128,6 +1692,38 @@ pub struct RefundDepositResponse {
     pub tx_hex: String,
 }
 
+/// Replaces an unconfirmed deposit refund with one paying a higher fee (RBF)
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct BumpRefundFeeRequest {
+    /// The id of the refund transaction to replace
+    pub tx_id: String,
+    /// The new fee, which must be higher than the fee of the replaced transaction
+    pub fee: Fee,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct BumpRefundFeeResponse {
+    pub tx_id: String,
+    pub tx_hex: String,
+}
+
+/// A broadcast deposit refund, tracked until it confirms
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DepositRefund {
+    /// The txid of the refunded deposit
+    pub deposit_txid: String,
+    /// The output index of the refunded deposit
+    pub deposit_vout: u32,
+    /// The id of the refund transaction
+    pub tx_id: String,
+    pub destination_address: String,
+    /// The fee paid by the refund transaction
+    pub fee_sats: u64,
+}
+
 #[derive(Debug, Clone, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct ListUnclaimedDepositsRequest {}
@@ -1204,7 +1800,7 @@ pub struct Credentials {
 }
 
 /// Request to get the balance of the wallet
-#[derive(Debug, Clone)]
+#[derive(Debug, Clone, Default)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct GetInfoRequest {
     /// When `Some(true)`, and `background_tasks_enabled` is `true`, the call
@@ -1214,6 +1810,10 @@ pub struct GetInfoRequest {
     /// is rejected with an invalid-input error. There is no background sync to
     /// wait on; call `sync_wallet` explicitly first if you need fresh state.
     pub ensure_synced: Option<bool>,
+    /// Whether `token_balances` includes the hidden tokens. Defaults to
+    /// `false`.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub include_hidden: Option<bool>,
 }
 
 /// Response containing the balance of the wallet
@@ -1224,8 +1824,83 @@ pub struct GetInfoResponse {
     pub identity_pubkey: String,
     /// The balance in satoshis
     pub balance_sats: u64,
-    /// The balances of the tokens in the wallet keyed by the token identifier
+    /// The balances of the tokens in the wallet keyed by the token identifier.
+    /// Hidden tokens are left out unless [`GetInfoRequest::include_hidden`]
+    /// is set.
     pub token_balances: HashMap<String, TokenBalance>,
+    /// The identifiers of the tokens in the wallet that are hidden, by
+    /// [`Config::token_visibility`] or with `BreezSdk::hide_token`
+    pub hidden_tokens: Vec<String>,
+    /// Aggregate statistics of the wallet's leaves. See [`BreezSdk::list_leaves`]
+    /// for the individual leaves.
+    pub leaf_stats: LeafStats,
+    /// The number of incoming transfers waiting to be claimed because
+    /// [`Config::max_claims_per_second`] was reached
+    pub claim_queue_depth: u32,
+    /// Whether the wallet is frozen with [`BreezSdk::freeze_wallet`]
+    pub wallet_frozen: bool,
+    /// The balances held by balance provider plugins, see
+    /// [`SdkPlugin`](crate::SdkPlugin). Not included in `balance_sats`.
+    pub plugin_balances: Vec<PluginBalance>,
+}
+
+/// The state of a leaf
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum LeafState {
+    /// The leaf can be spent
+    Available,
+    /// The leaf is locked by a swap in progress, e.g. a leaf optimization
+    LockedForSwap,
+    /// The leaf is locked by a payment in progress
+    PendingTransfer,
+    /// The leaf can't be spent, e.g. an incoming transfer not yet claimed
+    Unavailable,
+}
+
+/// A single leaf of the wallet
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LeafInfo {
+    pub id: String,
+    /// The denomination of the leaf, in satoshis
+    pub value_sats: u64,
+    pub state: LeafState,
+    /// Seconds since the SDK first saw the leaf. `None` if the leaf was
+    /// never seen during a sync, e.g. right after it was received.
+    pub age_secs: Option<u64>,
+}
+
+/// The number of available leaves of one denomination
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LeafDenomination {
+    pub value_sats: u64,
+    pub count: u32,
+}
+
+/// Aggregate statistics of the wallet's leaves
+#[derive(Debug, Clone, Default, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LeafStats {
+    /// The number of leaves in any state
+    pub leaf_count: u32,
+    /// The number of leaves that can be spent
+    pub available_leaf_count: u32,
+    /// The number of leaves locked by a swap or payment in progress
+    pub locked_leaf_count: u32,
+    pub smallest_available_sats: Option<u64>,
+    pub largest_available_sats: Option<u64>,
+    /// The available leaves grouped by denomination, largest first
+    pub available_denominations: Vec<LeafDenomination>,
+}
+
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListLeavesResponse {
+    /// The leaves of the wallet, largest first
+    pub leaves: Vec<LeafInfo>,
+    pub stats: LeafStats,
 }
 
 #[derive(Debug, Clone, Serialize, Deserialize)]
@@ -1249,37 +1924,186 @@ pub struct TokenMetadata {
     pub is_freezable: bool,
 }
 
+/// How digits beyond the decimals of a token are rounded when converting an
+/// amount to base units
+#[derive(Debug, Clone, Copy, Default, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum AmountRounding {
+    /// Towards zero
+    #[default]
+    Down,
+    /// Away from zero
+    Up,
+    /// To the nearest base unit, away from zero on ties
+    HalfUp,
+    /// To the nearest base unit, to the even one on ties
+    HalfEven,
+}
+
 /// Request to sync the wallet with the Spark network
-#[derive(Debug, Clone)]
+#[derive(Debug, Clone, Default)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-pub struct SyncWalletRequest {}
+pub struct SyncWalletRequest {
+    /// The domains to sync. Unset syncs all of them.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub domains: Option<Vec<SyncDomain>>,
+}
 
 /// Response from synchronizing the wallet
 #[derive(Debug, Clone, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct SyncWalletResponse {}
 
+/// A part of the wallet that can be synced on its own with
+/// [`BreezSdk::sync_wallet`]
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum SyncDomain {
+    /// The wallet's balances and payments, including token balances and
+    /// token payments
+    Payments,
+    /// Only the token balances and payments, quicker to sync than
+    /// [`SyncDomain::Payments`] as the Bitcoin leaves and payments are skipped
+    Tokens,
+    /// On-chain deposits, which are claimed automatically when the fee allows
+    Deposits,
+    /// The metadata of payments received to the Lightning address
+    LightningAddress,
+}
+
+/// A stage of a wallet sync
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum SyncStage {
+    /// Syncing the wallet's leaves and token outputs with the Spark operators
+    Wallet,
+    /// Storing the balances and payment history
+    Payments,
+    /// Syncing the token balances and payments, when synced on their own
+    Tokens,
+    /// Checking for and claiming on-chain deposits
+    Deposits,
+    /// Fetching the metadata of Lightning address payments
+    LightningAddress,
+}
+
+/// The progress of a wallet sync, reported by
+/// [`SdkEvent::SyncProgress`](crate::SdkEvent::SyncProgress) as each stage
+/// finishes. The stages run concurrently and may finish in any order.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SyncProgress {
+    /// The stage that finished
+    pub stage: SyncStage,
+    /// Whether the stage succeeded
+    pub succeeded: bool,
+    /// The share of the sync's stages that finished, from 0 to 100
+    pub percent: u8,
+}
+
+/// When a [`SyncDomain`] was last synced successfully
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DomainSyncStatus {
+    pub domain: SyncDomain,
+    /// The time of the last successful sync, as a unix timestamp in seconds.
+    /// `None` if the domain wasn't synced yet.
+    pub last_synced_at: Option<u64>,
+}
+
+/// Response from [`BreezSdk::get_sync_status`]
 #[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetSyncStatusResponse {
+    pub domains: Vec<DomainSyncStatus>,
+}
+
+/// The kinds of data shared between devices by real-time sync
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
-pub enum ReceivePaymentMethod {
-    SparkAddress,
-    SparkInvoice {
-        /// Amount to receive. Denominated in sats if token identifier is empty, otherwise in the token base units
-        amount: Option<u128>,
-        /// The presence of this field indicates that the payment is for a token
-        /// If empty, it is a Bitcoin payment
-        token_identifier: Option<String>,
-        /// The expiry time of the invoice as a unix timestamp in seconds
-        expiry_time: Option<u64>,
-        /// A description to embed in the invoice.
+pub enum SyncRecordType {
+    /// Payment metadata, e.g. LNURL descriptions and fiat values
+    PaymentMetadata,
+    Contact,
+    LightningAddress,
+    CrossChainSwap,
+    Device,
+    /// User settings kept by the SDK, e.g. the display currency
+    UserSettings,
+}
+
+/// A conflict between a change received from another device and a change
+/// made on this device that wasn't synced yet.
+///
+/// Conflicts are resolved per field: the fields changed on this device keep
+/// their local value and are then pushed to the other devices, the other
+/// fields take the incoming value. The device whose change reaches the sync
+/// server last wins, regardless of when the change was made on each device,
+/// and every device ends up with its values.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SyncConflict {
+    pub record_type: SyncRecordType,
+    /// The id of the record, e.g. the contact id or the payment id
+    pub record_id: String,
+    /// The fields whose incoming value was replaced by the local value
+    pub kept_local_fields: Vec<String>,
+}
+
+/// A device running the wallet, see [`BreezSdk::list_devices`](crate::BreezSdk::list_devices)
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DeviceInfo {
+    pub id: String,
+    /// The name set with [`BreezSdk::set_device_name`](crate::BreezSdk::set_device_name)
+    pub name: Option<String>,
+    /// When the device last connected, in seconds since the Unix epoch
+    pub last_seen_at: u64,
+    /// Whether this is the device the SDK runs on
+    pub is_current: bool,
+}
+
+/// Response from [`BreezSdk::list_devices`](crate::BreezSdk::list_devices)
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListDevicesResponse {
+    /// The devices, this device first and then the most recently seen
+    pub devices: Vec<DeviceInfo>,
+}
+
+/// Request for [`BreezSdk::set_device_name`](crate::BreezSdk::set_device_name)
+#[derive(Debug, Clone, Deserialize, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SetDeviceNameRequest {
+    pub name: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum ReceivePaymentMethod {
+    SparkAddress,
+    SparkInvoice {
+        /// Amount to receive. Denominated in sats if token identifier is empty, otherwise in the token base units
+        amount: Option<u128>,
+        /// The presence of this field indicates that the payment is for a token
+        /// If empty, it is a Bitcoin payment
+        token_identifier: Option<String>,
+        /// The expiry time of the invoice as a unix timestamp in seconds
+        expiry_time: Option<u64>,
+        /// A description to embed in the invoice.
         description: Option<String>,
         /// If set, the invoice may only be fulfilled by a payer with this public key
         sender_public_key: Option<String>,
+        /// If true, the invoice accepts partial payments. It is created without an
+        /// embedded amount and received payments towards it are aggregated until
+        /// `amount` is reached, emitting `SdkEvent::PartialInvoicePaymentProgress`
+        /// on each one. Requires `amount`.
+        accept_partial_payments: Option<bool>,
     },
     BitcoinAddress {
         /// If true, rotate to a new deposit address. Previous ones remain valid.
-        /// If false or absent, return the existing address (creating one if none
-        /// exists yet).
+        /// If false, return the existing address (creating one if none exists
+        /// yet). If absent, follows [`Config::rotate_deposit_address`].
         new_address: Option<bool>,
     },
     Bolt11Invoice {
@@ -1361,6 +2185,13 @@ pub enum SendPaymentMethod {
         /// when sending.
         provider_context: CrossChainProviderContext,
     },
+    /// A send through a payment rail plugin, see [`SdkPlugin`](crate::SdkPlugin)
+    Plugin {
+        plugin_name: String,
+        /// The destination as passed to `prepare_send_payment`
+        destination: String,
+        quote: PluginSendQuote,
+    },
 }
 
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
@@ -1386,6 +2217,7 @@ impl SendOnchainSpeedFeeQuote {
     }
 }
 
+#[derive(Debug, Clone)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct ReceivePaymentRequest {
     pub payment_method: ReceivePaymentMethod,
@@ -1400,6 +2232,7 @@ pub struct ReceivePaymentResponse {
     pub fee: u128,
 }
 
+#[derive(Debug, Clone)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct PrepareLnurlPayRequest {
     /// The amount to send. Denominated in satoshis, or in token base units
@@ -1419,6 +2252,26 @@ pub struct PrepareLnurlPayRequest {
     /// How fees are handled. See [`FeePolicy`]. Defaults to `FeesExcluded`.
     #[cfg_attr(feature = "uniffi", uniffi(default=None))]
     pub fee_policy: Option<FeePolicy>,
+    /// The token the endpoint quoted its price in. When set, `amount` is the
+    /// quoted price in token base units, which is converted to sats at the
+    /// current conversion rate. To pay with the token itself, also set
+    /// `token_identifier` to the same token along with `ToBitcoin`
+    /// conversion options.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub quote_token_identifier: Option<String>,
+}
+
+/// The conversion of a token-denominated LNURL pay price to sats
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LnurlPayTokenQuote {
+    pub token_identifier: String,
+    /// The quoted price, in token base units
+    pub token_amount: u128,
+    /// The equivalent amount in satoshis
+    pub amount_sats: u64,
+    /// The effective rate used, in satoshis per whole token
+    pub sats_per_token: f64,
 }
 
 #[derive(Debug, Clone)]
@@ -1442,6 +2295,13 @@ pub struct PrepareLnurlPayResponse {
     /// LNURL sends with `token_identifier` set + conversion are always
     /// `FeesIncluded` (explicit `FeesExcluded` is rejected).
     pub fee_policy: FeePolicy,
+    /// Set when the price was quoted in a token, with the rate used to
+    /// convert it to sats
+    pub token_quote: Option<LnurlPayTokenQuote>,
+    /// Set when the same amount was already paid to this LNURL pay endpoint
+    /// recently, so the user can be asked whether to pay it again. Sending is
+    /// not blocked.
+    pub duplicate_payment: Option<DuplicatePayment>,
 }
 
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
@@ -1452,6 +2312,10 @@ pub struct LnurlPayRequest {
     /// The idempotency key must be a valid UUID.
     #[cfg_attr(feature = "uniffi", uniffi(default=None))]
     pub idempotency_key: Option<String>,
+    /// If set, the payment is aborted when its routing or transfer fee, as
+    /// determined at send time, exceeds this limit.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub max_fee: Option<SendMaxFee>,
 }
 
 #[derive(Debug, Serialize)]
@@ -1490,6 +2354,11 @@ pub struct LnurlWithdrawRequest {
     /// initiating the LNURL withdraw.
     #[cfg_attr(feature = "uniffi", uniffi(default=None))]
     pub completion_timeout_secs: Option<u32>,
+    /// If set, providing the same idempotency key for multiple requests will ensure that only one
+    /// withdraw is made. If an idempotency key is re-used, the same invoice will be returned.
+    /// The idempotency key must be a valid UUID.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub idempotency_key: Option<String>,
 }
 
 #[derive(Debug, Serialize)]
@@ -1699,6 +2568,7 @@ pub struct BuildUnsignedTransferPackageRequest {
     pub options: Option<BuildTransferPackageOptions>,
 }
 
+#[derive(Debug, Clone)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct PrepareSendPaymentRequest {
     pub payment_request: PaymentRequest,
@@ -1724,6 +2594,11 @@ pub struct PrepareSendPaymentRequest {
     /// prepare response's `fee_policy` reflects what was actually applied.
     #[cfg_attr(feature = "uniffi", uniffi(default=None))]
     pub fee_policy: Option<FeePolicy>,
+    /// Sets the amount in fiat, converted to sats at the rate of a lock from
+    /// [`BreezSdk::lock_exchange_rate`](crate::BreezSdk::lock_exchange_rate).
+    /// Can't be combined with `amount` or `token_identifier`.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub fiat_amount: Option<LockedFiatAmount>,
 }
 
 #[derive(Debug, Clone, Serialize)]
@@ -1743,8 +2618,27 @@ pub struct PrepareSendPaymentResponse {
     /// The fee policy actually applied. May differ from the request — e.g.,
     /// cross-chain AMM-conversion sends are always `FeesIncluded`.
     pub fee_policy: FeePolicy,
+    /// Set when the invoice being paid was already paid recently, or the same
+    /// amount was already sent to the address being paid, so the user can be
+    /// asked whether to pay it again. Sending is not blocked.
+    pub duplicate_payment: Option<DuplicatePayment>,
+}
+
+/// An earlier payment to the same destination, see
+/// [`PrepareSendPaymentResponse::duplicate_payment`] and
+/// [`PrepareLnurlPayResponse::duplicate_payment`]
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DuplicatePayment {
+    /// The id of the earlier payment
+    pub payment_id: String,
+    /// The status of the earlier payment, either pending or completed
+    pub status: PaymentStatus,
+    /// When the earlier payment was sent, in seconds since the Unix epoch
+    pub timestamp: u64,
 }
 
+#[derive(Debug, Clone)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
 pub enum SendPaymentOptions {
     BitcoinAddress {
@@ -1763,8 +2657,19 @@ pub enum SendPaymentOptions {
         /// The receiver will need to provide the preimage to claim it.
         htlc_options: Option<SparkHtlcOptions>,
     },
+    SparkInvoice {
+        /// Guardrail for amountless invoices: the payment is aborted if the
+        /// amount being sent is below this value.
+        /// Denominated in sats if token identifier is empty, otherwise in the token base units
+        min_amount: Option<u128>,
+        /// Guardrail for amountless invoices: the payment is aborted if the
+        /// amount being sent is above this value.
+        /// Denominated in sats if token identifier is empty, otherwise in the token base units
+        max_amount: Option<u128>,
+    },
 }
 
+#[derive(Debug, Clone)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct SparkHtlcOptions {
     /// The payment hash of the HTLC. The receiver will need to provide the associated preimage to claim it.
@@ -1774,18 +2679,125 @@ pub struct SparkHtlcOptions {
     pub expiry_duration_secs: u64,
 }
 
+#[derive(Debug, Clone)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct SendPaymentRequest {
     pub prepare_response: PrepareSendPaymentResponse,
     #[cfg_attr(feature = "uniffi", uniffi(default=None))]
     pub options: Option<SendPaymentOptions>,
-    /// The optional idempotency key for all Spark based transfers (excludes token payments
-    /// and cross-chain sends).
+    /// The optional idempotency key.
     /// If set, providing the same idempotency key for multiple requests will ensure that only one
     /// payment is made. If an idempotency key is re-used, the same payment will be returned.
+    /// For payments with a token transfer leg, a retry of a call that was interrupted fails with
+    /// [`SdkError::IdempotencyKeyInUse`] instead.
     /// The idempotency key must be a valid UUID.
     #[cfg_attr(feature = "uniffi", uniffi(default=None))]
     pub idempotency_key: Option<String>,
+    /// If set, the payment is aborted when its routing or transfer fee, as
+    /// determined at send time, exceeds this limit.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub max_fee: Option<SendMaxFee>,
+    /// If set, the payment is sent as a [`TimeLockedPayment`] the receiver can
+    /// only claim after this unix timestamp in seconds.
+    /// Only supported for Bitcoin payments to a Spark address.
+    ///
+    /// The SDK doesn't deliver the payment: once the delivery time passed,
+    /// the preimage returned by
+    /// [`BreezSdk::list_time_locked_payments`](crate::sdk::BreezSdk::list_time_locked_payments)
+    /// has to be passed on to the receiver, who claims the payment with it.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub deliver_after: Option<u64>,
+    /// Which leaves to spend, instead of the default selection.
+    /// Only supported for Bitcoin payments to a Spark or Bitcoin address.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub leaf_selection: Option<SendLeafSelection>,
+}
+
+/// Controls which leaves are spent by a send
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum SendLeafSelection {
+    /// Spends exactly the given leaves, as listed by [`ListLeavesResponse`].
+    /// Their values must add up to the amount sent, fees included for a
+    /// Bitcoin address.
+    Specific { leaf_ids: Vec<String> },
+    /// Picks the leaves using the given strategy. When no leaves add up to
+    /// the amount, the default selection is used, which swaps leaves first.
+    Strategy { strategy: LeafSelectionStrategy },
+}
+
+/// How leaves are picked for a send
+#[derive(Debug, Clone, Copy, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum LeafSelectionStrategy {
+    /// The default selection, which only swaps leaves when no combination of
+    /// them adds up to the amount
+    MinimizeFee,
+    /// Spends as few leaves as possible, starting with the largest
+    MinimizeInputs,
+    /// Like `MinimizeInputs`, but picks at random among leaves of the same
+    /// value, so that repeated payments don't spend leaves in a predictable order
+    Privacy,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SimulateSendPaymentRequest {
+    pub prepare_response: PrepareSendPaymentResponse,
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub options: Option<SendPaymentOptions>,
+    /// The max fee the payment would be sent with, checked by the simulation
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub max_fee: Option<SendMaxFee>,
+}
+
+/// The outcome of a simulated send. Nothing is reserved or broadcast, so the
+/// actual send may still differ if the wallet or the fees change in between.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SimulateSendPaymentResponse {
+    /// Whether the payment is expected to succeed, i.e. there are no failure reasons
+    pub will_succeed: bool,
+    /// The amount the receiver is expected to get, in the same unit as the
+    /// prepare response amount
+    pub amount: u128,
+    /// The expected fee, denominated in satoshis if token identifier is empty,
+    /// otherwise in the token base units
+    pub fee: u128,
+    pub token_identifier: Option<String>,
+    /// The leaves the payment would spend. Empty for token payments.
+    pub leaves: Vec<SimulatedLeaf>,
+    /// Whether the leaves must first be swapped for exact denominations,
+    /// which adds a round trip to the SSP before the payment is sent
+    pub requires_swap: bool,
+    /// Rough estimate of the time until the payment completes, if known
+    pub estimated_completion_secs: Option<u64>,
+    pub failure_reasons: Vec<SendFailureReason>,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SimulatedLeaf {
+    pub id: String,
+    pub value: u64,
+}
+
+/// A reason a simulated send would fail
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum SendFailureReason {
+    /// The balance does not cover the amount and fee
+    InsufficientFunds { required: u128, available: u128 },
+    /// The fee is above the max fee of the request
+    MaxFeeExceeded { fee_sats: u64, max_fee_sats: u64 },
+    /// The amount is below the dust limit of the destination address
+    BelowDustLimit {
+        amount_sats: u64,
+        dust_limit_sats: u64,
+    },
+    /// The quote of the prepare response has expired and must be prepared again
+    QuoteExpired,
+    /// The options do not match the payment method
+    InvalidOptions { reason: String },
 }
 
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
@@ -1807,6 +2819,56 @@ pub struct SendPaymentResponse {
     pub payment: Payment,
 }
 
+/// Identifies a payment started with `send_payment_async`
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct PaymentHandle {
+    /// The idempotency key of the send request if set, otherwise a generated id
+    pub id: String,
+}
+
+/// The stage reached by a payment started with `send_payment_async`, reported
+/// by `SdkEvent::PaymentProgress`
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum PaymentProgressStage {
+    /// The payment is queued and nothing was sent yet. The payment can still
+    /// be cancelled.
+    Queued,
+    /// The transfer was sent and the payment is pending until it settles or
+    /// fails. It can no longer be cancelled.
+    TransferInitiated,
+    /// The preimage of a Lightning payment was received
+    PreimageReceived { preimage: String },
+    /// The payment completed
+    Settled { payment: Payment },
+    /// The payment failed, or the SDK stopped tracking the pending payment,
+    /// e.g. because it was disconnected. In the latter case the payment may
+    /// still settle, see `get_payment`.
+    Failed { error: String },
+    /// The payment was cancelled before the transfer was initiated
+    Cancelled,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CancelPendingPaymentRequest {
+    pub handle: PaymentHandle,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CancelPaymentRequest {
+    /// The id of the pending outgoing payment
+    pub payment_id: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CancelPaymentResponse {
+    /// The payment in its final status
+    pub payment: Payment,
+}
+
 #[derive(Debug, Clone)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
 pub enum PaymentDetailsFilter {
@@ -1911,121 +2973,759 @@ pub struct GetPaymentResponse {
     pub payment: Payment,
 }
 
-#[cfg_attr(feature = "uniffi", uniffi::export(callback_interface))]
-pub trait Logger: Send + Sync {
-    fn log(&self, l: LogEntry);
+/// Request to build the ledger of balance changes for one asset
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetLedgerRequest {
+    /// The token to build the ledger for. If not set, the ledger is built for Bitcoin.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub token_identifier: Option<String>,
+    /// Only include entries created after this timestamp (inclusive)
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub from_timestamp: Option<u64>,
+    /// Only include entries created before this timestamp (exclusive)
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub to_timestamp: Option<u64>,
 }
 
-#[derive(Debug, Clone, Serialize)]
+/// A single balance-affecting event in the ledger
+#[derive(Debug, Clone, Serialize, PartialEq)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-pub struct LogEntry {
-    pub line: String,
-    pub level: String,
+pub struct LedgerEntry {
+    /// The id of the payment that caused the balance change
+    pub payment_id: String,
+    /// Set when the payment is a leg of a conversion performed for another payment
+    pub parent_payment_id: Option<String>,
+    pub timestamp: u64,
+    pub payment_type: PaymentType,
+    pub method: PaymentMethod,
+    /// Amount added to the balance, in satoshis or token base units
+    pub credit: u128,
+    /// Amount removed from the balance including fees, in satoshis or token base units
+    pub debit: u128,
+    /// Fees included in the debit, in satoshis or token base units
+    pub fees: u128,
+    /// The balance after this entry was applied
+    pub balance: u128,
 }
 
+/// Response from building the ledger
+#[derive(Debug, Clone, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-#[derive(Debug, Clone, Serialize, Deserialize)]
-pub struct CheckLightningAddressRequest {
-    pub username: String,
+pub struct GetLedgerResponse {
+    pub token_identifier: Option<String>,
+    /// The balance before the first returned entry
+    pub opening_balance: u128,
+    /// The balance after the last returned entry
+    pub closing_balance: u128,
+    /// The entries in chronological order
+    pub entries: Vec<LedgerEntry>,
 }
 
-#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-#[derive(Debug, Clone, Serialize, Deserialize)]
-pub struct RegisterLightningAddressRequest {
-    pub username: String,
-    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
-    pub description: Option<String>,
+#[derive(Debug, Clone, Copy, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum LedgerExportFormat {
+    Csv,
+    Json,
 }
 
-/// Authorization from the current owner granting a specific new owner the
-/// right to take over a username. Produced by
-/// [`BreezSdk::authorize_lightning_address_transfer`] and handed to the new
-/// owner, who passes it to [`BreezSdk::claim_lightning_address_transfer`]. It
-/// fully describes the transfer, so the new owner needs nothing else to claim.
+/// Request to export the ledger of balance changes for one asset
+#[derive(Debug, Clone)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-#[derive(Debug, Clone, Serialize, Deserialize)]
-pub struct TransferAuthorization {
-    /// The username being handed over.
-    pub username: String,
-    /// The current owner's public key.
-    pub pubkey: String,
-    /// The current owner's signature authorizing the transfer.
-    pub signature: String,
+pub struct ExportLedgerRequest {
+    pub format: LedgerExportFormat,
+    /// The token to export the ledger for. If not set, the ledger is exported for Bitcoin.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub token_identifier: Option<String>,
+    /// Only include entries created after this timestamp (inclusive)
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub from_timestamp: Option<u64>,
+    /// Only include entries created before this timestamp (exclusive)
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub to_timestamp: Option<u64>,
 }
 
-/// Request for [`BreezSdk::authorize_lightning_address_transfer`]. Called by
-/// the *current owner* to authorize handing their registered username over to
-/// `transferee_pubkey`.
+#[derive(Debug, Clone, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-#[derive(Debug, Clone, Serialize, Deserialize)]
-pub struct AuthorizeTransferRequest {
-    /// The new owner's identity public key.
-    pub transferee_pubkey: String,
+pub struct ExportLedgerResponse {
+    pub format: LedgerExportFormat,
+    /// The exported ledger
+    pub data: String,
 }
 
-/// Request for [`BreezSdk::claim_lightning_address_transfer`]. Called by the
-/// *new owner* to complete the takeover using the authorization produced by
-/// the current owner.
+/// Request to list the activity of one token
+#[derive(Debug, Clone)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-#[derive(Debug, Clone, Serialize, Deserialize)]
-pub struct ClaimTransferRequest {
-    /// Authorization produced by the current owner via
-    /// [`BreezSdk::authorize_lightning_address_transfer`].
-    pub authorization: TransferAuthorization,
-    /// Description for the address. Defaults to `"Pay to {username}@{domain}"`.
+pub struct GetTokenActivityRequest {
+    pub token_identifier: String,
+    /// Number of records to skip
     #[cfg_attr(feature = "uniffi", uniffi(default=None))]
-    pub description: Option<String>,
+    pub offset: Option<u32>,
+    /// Maximum number of records to return
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub limit: Option<u32>,
 }
 
-#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-#[derive(Debug, Clone, PartialEq, Deserialize, Serialize)]
-pub struct LnurlInfo {
-    pub url: String,
-    pub bech32: String,
+/// An event affecting a token of this wallet
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum TokenActivity {
+    /// A transfer, mint or burn of the token, see [`TokenTransactionType`]
+    Payment { payment: Payment },
+    /// The token held at an address was frozen or unfrozen, either by this
+    /// wallet as the token issuer or, when the address is this wallet's, by
+    /// the issuer of a token this wallet holds
+    Freeze {
+        /// The Spark address holding the token
+        address: String,
+        /// Whether the token was frozen or unfrozen
+        frozen: bool,
+        /// The amount of the token affected, in base units
+        impacted_token_amount: u128,
+        timestamp: u64,
+    },
 }
 
-impl LnurlInfo {
-    pub fn new(url: String) -> Self {
-        let bech32 =
-            breez_sdk_common::lnurl::encode_lnurl_to_bech32(&url).unwrap_or_else(|_| url.clone());
-        Self { url, bech32 }
+impl TokenActivity {
+    pub fn timestamp(&self) -> u64 {
+        match self {
+            TokenActivity::Payment { payment } => payment.timestamp,
+            TokenActivity::Freeze { timestamp, .. } => *timestamp,
+        }
     }
 }
 
+/// Response from listing the activity of a token
+#[derive(Debug, Clone, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-#[derive(Debug, Clone, PartialEq, Deserialize, Serialize)]
-pub struct LightningAddressInfo {
-    pub description: String,
-    pub lightning_address: String,
-    pub lnurl: LnurlInfo,
-    pub username: String,
+pub struct GetTokenActivityResponse {
+    /// The activity, newest first
+    pub activity: Vec<TokenActivity>,
 }
 
-impl From<RecoverLnurlPayResponse> for LightningAddressInfo {
-    fn from(resp: RecoverLnurlPayResponse) -> Self {
-        Self {
-            description: resp.description,
-            lightning_address: resp.lightning_address,
-            lnurl: LnurlInfo::new(resp.lnurl),
-            username: resp.username,
-        }
-    }
+#[derive(Debug, Clone, Copy, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum PaymentExportFormat {
+    Csv,
+    Json,
+    /// An ISO 20022 CAMT.053 bank statement, with one entry per payment
+    Camt053,
 }
 
-/// Response from listing fiat currencies
-#[derive(Debug, Clone, Serialize)]
+/// Request to export payments for accounting
+#[derive(Debug, Clone)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-pub struct ListFiatCurrenciesResponse {
-    /// The list of fiat currencies
-    pub currencies: Vec<FiatCurrency>,
+pub struct ExportPaymentsRequest {
+    pub format: PaymentExportFormat,
+    /// The payments to export. If not set, all payments are exported.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub filter: Option<ListPaymentsRequest>,
+}
+
+/// A payment as exported for accounting
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct PaymentExportRecord {
+    pub payment_id: String,
+    pub timestamp: u64,
+    pub payment_type: PaymentType,
+    pub status: PaymentStatus,
+    pub method: PaymentMethod,
+    /// Amount in satoshis or token base units, excluding fees
+    pub amount: u128,
+    /// Fees in satoshis or token base units
+    pub fees: u128,
+    /// Set for token payments
+    pub token_identifier: Option<String>,
+    /// The ticker of the token, or `BTC` for Bitcoin payments
+    pub asset_ticker: String,
+    /// The number of decimals of `amount` and `fees`: 8 for Bitcoin payments
+    pub asset_decimals: u32,
+    /// The fiat value at completion, see [`Payment::fiat_value`]
+    pub fiat_value: Option<PaymentFiatValue>,
+    /// The description or comment of the payment
+    pub label: Option<String>,
+    /// The contact name or Lightning address of the other party, when known
+    pub counterparty: Option<String>,
 }
 
-/// Response from listing fiat rates
 #[derive(Debug, Clone, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-pub struct ListFiatRatesResponse {
-    /// The list of fiat rates
-    pub rates: Vec<Rate>,
+pub struct ExportPaymentsResponse {
+    pub format: PaymentExportFormat,
+    /// The exported payments
+    pub data: String,
+}
+
+/// Request to summarize completed payments over a period
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetAccountingReportRequest {
+    /// Start of the period (inclusive)
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub from_timestamp: Option<u64>,
+    /// End of the period (exclusive)
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub to_timestamp: Option<u64>,
+}
+
+/// Totals of the completed payments of one asset over a period
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct AccountingAssetTotals {
+    /// The token of the totals, or `None` for Bitcoin
+    pub token_identifier: Option<String>,
+    /// Amount received, in satoshis or token base units
+    pub total_in: u128,
+    /// Amount sent excluding fees, in satoshis or token base units
+    pub total_out: u128,
+    /// Fees paid on sent payments, in satoshis or token base units
+    pub fees_paid: u128,
+    pub received_count: u32,
+    pub sent_count: u32,
+}
+
+/// Request to get the remaining allowance of the spending caps
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetRemainingAllowanceRequest {}
+
+/// The spending of one [`SpendingCap`] within its current window
+#[derive(Debug, Clone, Serialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SpendingAllowance {
+    pub cap: SpendingCap,
+    /// The amount sent within the window, including fees and pending payments
+    pub spent: u128,
+    /// The amount that can still be sent within the window
+    pub remaining: u128,
+    /// When the oldest payment of the window leaves it, freeing up allowance,
+    /// as a unix timestamp in seconds. Not set when nothing was sent.
+    pub next_release_at: Option<u64>,
+}
+
+/// Response from getting the remaining allowance of the spending caps
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetRemainingAllowanceResponse {
+    /// One allowance per configured cap, in the order of `Config::spending_caps`
+    pub allowances: Vec<SpendingAllowance>,
+}
+
+/// Response from summarizing completed payments
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetAccountingReportResponse {
+    pub from_timestamp: Option<u64>,
+    pub to_timestamp: Option<u64>,
+    /// The totals per asset, Bitcoin first
+    pub assets: Vec<AccountingAssetTotals>,
+}
+
+#[cfg_attr(feature = "uniffi", uniffi::export(callback_interface))]
+pub trait Logger: Send + Sync {
+    fn log(&self, l: LogEntry);
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LogEntry {
+    /// The entry's fields formatted as a single line
+    pub line: String,
+    pub level: String,
+    /// The module that emitted the entry, e.g. `breez_sdk_spark::sdk`
+    pub target: String,
+    /// The entry's fields by name, including its `message`
+    pub fields: HashMap<String, String>,
+    /// When the entry was emitted, as a unix timestamp in milliseconds
+    pub timestamp: u64,
+}
+
+/// Configuration of the SDK's logging, see `init_logging_with_config`
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LoggingConfig {
+    /// Directory to write `sdk.log` to. No log file is written when unset.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub log_dir: Option<String>,
+    /// Module-level filter of the log file, e.g.
+    /// `breez_sdk_spark=debug,spark=info`. Defaults to [`DEFAULT_FILTER`](crate::DEFAULT_FILTER).
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub log_filter: Option<String>,
+    /// Module-level filter of the entries forwarded to the app logger.
+    /// Defaults to `log_filter`.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub app_logger_filter: Option<String>,
+    /// Whether the log file holds text lines or JSON entries
+    pub file_format: LogFileFormat,
+    /// Rotates the log file once it would grow beyond this size
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub max_file_size_bytes: Option<u64>,
+    /// Rotates the log file once it is this old
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub max_file_age_secs: Option<u64>,
+    /// How many rotated files to keep, as `sdk.log.1` (newest) onwards.
+    /// Defaults to 5.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub max_rotated_files: Option<u32>,
+}
+
+/// The format of the log file, see [`LoggingConfig`]
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum LogFileFormat {
+    /// One formatted text line per entry
+    Text,
+    /// One JSON-serialized [`LogEntry`] per line
+    Json,
+}
+
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SetLogFilterRequest {
+    /// Module-level filter applied to every log sink, e.g.
+    /// `breez_sdk_spark=debug,spark=info`
+    pub filter: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DumpDiagnosticsResponse {
+    /// JSON support bundle with the SDK version, the config with its secrets
+    /// redacted, the leaf statistics and the most recent errors
+    pub bundle: String,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct CheckLightningAddressRequest {
+    pub username: String,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct RegisterLightningAddressRequest {
+    pub username: String,
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub description: Option<String>,
+    /// Registers the username as an additional address of the wallet under
+    /// this label, e.g. `business`, instead of replacing its primary address.
+    /// Registering a label again replaces the address it names.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub label: Option<String>,
+    /// Base64 encoded PNG image shown by paying wallets on the pay page. The
+    /// decoded image is limited to 64 KiB.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub image: Option<String>,
+    /// A longer form description shown by paying wallets on the pay page,
+    /// next to the description.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub long_description: Option<String>,
+    /// The minimum amount the address accepts. Defaults to, and can't be
+    /// less than, the LNURL server's minimum.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub min_sendable_sats: Option<u64>,
+    /// The maximum amount the address accepts. Defaults to, and can't be
+    /// more than, the LNURL server's maximum.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub max_sendable_sats: Option<u64>,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct DeleteLabeledLightningAddressRequest {
+    /// The label the address was registered under
+    pub label: String,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct UpdateLightningAddressNostrKeyRequest {
+    /// Hex encoded x-only nostr pubkey to attach, or `None` to detach the
+    /// current one
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub nostr_pubkey: Option<String>,
+    /// The username of the address to update. Defaults to the primary
+    /// address.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub username: Option<String>,
+}
+
+/// Authorization from the current owner granting a specific new owner the
+/// right to take over a username. Produced by
+/// [`BreezSdk::authorize_lightning_address_transfer`] and handed to the new
+/// owner, who passes it to [`BreezSdk::claim_lightning_address_transfer`]. It
+/// fully describes the transfer, so the new owner needs nothing else to claim.
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct TransferAuthorization {
+    /// The username being handed over.
+    pub username: String,
+    /// The current owner's public key.
+    pub pubkey: String,
+    /// The current owner's signature authorizing the transfer.
+    pub signature: String,
+}
+
+/// Request for [`BreezSdk::authorize_lightning_address_transfer`]. Called by
+/// the *current owner* to authorize handing their registered username over to
+/// `transferee_pubkey`.
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct AuthorizeTransferRequest {
+    /// The new owner's identity public key.
+    pub transferee_pubkey: String,
+}
+
+/// Request for [`BreezSdk::claim_lightning_address_transfer`]. Called by the
+/// *new owner* to complete the takeover using the authorization produced by
+/// the current owner.
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct ClaimTransferRequest {
+    /// Authorization produced by the current owner via
+    /// [`BreezSdk::authorize_lightning_address_transfer`].
+    pub authorization: TransferAuthorization,
+    /// Description for the address. Defaults to `"Pay to {username}@{domain}"`.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub description: Option<String>,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+#[derive(Debug, Clone, PartialEq, Deserialize, Serialize)]
+pub struct LnurlInfo {
+    pub url: String,
+    pub bech32: String,
+}
+
+impl LnurlInfo {
+    pub fn new(url: String) -> Self {
+        let bech32 =
+            breez_sdk_common::lnurl::encode_lnurl_to_bech32(&url).unwrap_or_else(|_| url.clone());
+        Self { url, bech32 }
+    }
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+#[derive(Debug, Clone, PartialEq, Deserialize, Serialize)]
+pub struct LightningAddressInfo {
+    pub description: String,
+    pub lightning_address: String,
+    pub lnurl: LnurlInfo,
+    pub username: String,
+    /// The label of an additional address, `None` for the primary address
+    pub label: Option<String>,
+}
+
+impl From<RecoverLnurlPayResponse> for LightningAddressInfo {
+    fn from(resp: RecoverLnurlPayResponse) -> Self {
+        Self {
+            description: resp.description,
+            lightning_address: resp.lightning_address,
+            lnurl: LnurlInfo::new(resp.lnurl),
+            username: resp.username,
+            label: None,
+        }
+    }
+}
+
+impl From<RegisteredLnurlPay> for LightningAddressInfo {
+    fn from(resp: RegisteredLnurlPay) -> Self {
+        Self {
+            description: resp.description,
+            lightning_address: resp.lightning_address,
+            lnurl: LnurlInfo::new(resp.lnurl),
+            username: resp.username,
+            label: resp.label,
+        }
+    }
+}
+
+/// Request to handle a URI the app was opened with
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct HandleIncomingUriRequest {
+    /// The URI, e.g. a `bitcoin:`, `lightning:`, `lnurl:`, `spark:` or
+    /// `breez:` deep link
+    pub uri: String,
+}
+
+/// Response from handling an incoming URI
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct HandleIncomingUriResponse {
+    /// The parsed URI
+    pub input_type: InputType,
+    /// The call to continue with
+    pub action: IncomingUriAction,
+}
+
+/// The SDK call that handles an incoming URI, with the arguments taken from it
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum IncomingUriAction {
+    /// Call [`BreezSdk::prepare_send_payment`](crate::BreezSdk::prepare_send_payment)
+    /// with [`PaymentRequest::Input`]
+    PrepareSendPayment {
+        input: String,
+        /// The amount requested by the URI, in satoshis, if the input itself
+        /// doesn't carry it
+        amount_sats: Option<u64>,
+    },
+    /// Call [`BreezSdk::prepare_lnurl_pay`](crate::BreezSdk::prepare_lnurl_pay)
+    PrepareLnurlPay { pay_request: LnurlPayRequestDetails },
+    /// Call [`BreezSdk::lnurl_withdraw`](crate::BreezSdk::lnurl_withdraw)
+    LnurlWithdraw {
+        withdraw_request: LnurlWithdrawRequestDetails,
+    },
+    /// Call [`BreezSdk::lnurl_auth`](crate::BreezSdk::lnurl_auth)
+    LnurlAuth {
+        request_data: LnurlAuthRequestDetails,
+    },
+    /// Call [`BreezSdk::get_cross_chain_routes`](crate::BreezSdk::get_cross_chain_routes),
+    /// then [`BreezSdk::prepare_send_payment`](crate::BreezSdk::prepare_send_payment)
+    /// with [`PaymentRequest::CrossChain`]
+    GetCrossChainRoutes { address: CrossChainAddressDetails },
+    /// Not a payment request, open the url in a browser
+    OpenUrl { url: String },
+    /// The input is recognized but can't be handled by the SDK
+    Unsupported { reason: String },
+}
+
+/// Request to create a payment link
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CreatePaymentLinkRequest {
+    /// The amount requested, in satoshis
+    pub amount_sats: u64,
+    /// Shown to the payer as the invoice description
+    pub memo: String,
+}
+
+/// Response from creating a payment link
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CreatePaymentLinkResponse {
+    pub link: PaymentLink,
+}
+
+/// Request to list the payment links, newest first
+#[derive(Debug, Clone, Default, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListPaymentLinksRequest {
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub offset: Option<u32>,
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub limit: Option<u32>,
+}
+
+/// Response from listing the payment links
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListPaymentLinksResponse {
+    pub links: Vec<PaymentLink>,
+}
+
+/// Request to delete a payment link
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DeletePaymentLinkRequest {
+    pub id: String,
+}
+
+/// A shareable url requesting a fixed amount, hosted on the LNURL server.
+///
+/// Whoever opens the url is served a Lightning invoice for the amount, which
+/// is reused until it is paid or expires, so a link is paid at most once.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct PaymentLink {
+    pub id: String,
+    /// The url to share with the payer
+    pub url: String,
+    pub amount_sats: u64,
+    pub memo: String,
+    /// The time the link was created, as a unix timestamp in seconds
+    pub created_at: u64,
+    pub status: PaymentLinkStatus,
+    /// The id of the received payment once the link is paid and the
+    /// payment is synced
+    pub payment_id: Option<String>,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum PaymentLinkStatus {
+    /// Not opened yet, or its invoice is unpaid
+    Pending,
+    Paid,
+}
+
+/// Response from listing fiat currencies
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListFiatCurrenciesResponse {
+    /// The list of fiat currencies
+    pub currencies: Vec<FiatCurrency>,
+}
+
+/// Response from listing fiat rates
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListFiatRatesResponse {
+    /// The list of fiat rates
+    pub rates: Vec<Rate>,
+}
+
+/// Request to fetch the rates of a fiat currency over a time range
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct FetchHistoricalRatesRequest {
+    /// The fiat currency code, e.g. `USD`
+    pub currency: String,
+    /// Start of the range, as a unix timestamp in seconds
+    pub from_timestamp: u64,
+    /// End of the range, as a unix timestamp in seconds
+    pub to_timestamp: u64,
+    /// The interval between the returned rates
+    pub resolution: RateResolution,
+}
+
+/// Request to lock the exchange rate of a fiat currency for a checkout
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LockExchangeRateRequest {
+    /// The fiat currency code, e.g. `EUR`
+    pub currency: String,
+    /// How long the rate stays locked, in seconds. Defaults to 600 and can't
+    /// exceed 3600.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub ttl_secs: Option<u32>,
+}
+
+/// Response from locking an exchange rate
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LockExchangeRateResponse {
+    pub locked_rate: LockedExchangeRate,
+}
+
+/// An exchange rate held fixed until it expires, see
+/// [`BreezSdk::lock_exchange_rate`](crate::BreezSdk::lock_exchange_rate)
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LockedExchangeRate {
+    /// The token identifying the lock
+    pub id: String,
+    /// The fiat currency code, e.g. `EUR`
+    pub currency: String,
+    /// The locked exchange rate, in fiat units per bitcoin
+    pub rate: f64,
+    /// When the lock expires, as a unix timestamp in seconds
+    pub expires_at: u64,
+}
+
+/// Request to convert a fiat amount to sats at a locked exchange rate
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ConvertFiatAmountRequest {
+    /// The id of the lock returned by `lock_exchange_rate`
+    pub locked_rate_id: String,
+    /// The amount in fiat units, e.g. `10.0` for €10.00
+    pub fiat_amount: f64,
+}
+
+/// An amount in fiat to send at a locked exchange rate
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct LockedFiatAmount {
+    /// The id of the lock returned by `lock_exchange_rate`
+    pub locked_rate_id: String,
+    /// The amount in fiat units, e.g. `10.0` for €10.00
+    pub fiat_amount: f64,
+}
+
+/// Response from converting a fiat amount to sats
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ConvertFiatAmountResponse {
+    /// The amount in sats, rounded to the nearest sat
+    pub amount_sats: u64,
+    /// The lock the amount was converted at
+    pub locked_rate: LockedExchangeRate,
+}
+
+/// Request to receive a payment whose amount is set in fiat
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ReceiveFiatPaymentRequest {
+    pub payment_method: FiatReceiveMethod,
+    /// The fiat currency code, e.g. `EUR`
+    pub currency: String,
+    /// The amount to receive in fiat units, e.g. `10.0` for €10.00
+    pub fiat_amount: f64,
+    /// Converts at this rate lock, see
+    /// [`BreezSdk::lock_exchange_rate`](crate::BreezSdk::lock_exchange_rate),
+    /// instead of the current rate. The lock must be for `currency`.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub locked_rate_id: Option<String>,
+}
+
+/// The payment request to create for a [`ReceiveFiatPaymentRequest`]
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum FiatReceiveMethod {
+    Bolt11Invoice {
+        description: String,
+        /// The expiry of the invoice as a duration in seconds
+        expiry_secs: Option<u32>,
+    },
+    SparkInvoice {
+        /// A description to embed in the invoice
+        description: Option<String>,
+        /// The expiry time of the invoice as a unix timestamp in seconds
+        expiry_time: Option<u64>,
+        /// If set, the invoice may only be fulfilled by a payer with this public key
+        sender_public_key: Option<String>,
+    },
+}
+
+/// Response from receiving a payment whose amount is set in fiat
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ReceiveFiatPaymentResponse {
+    pub payment_request: String,
+    /// Fee to pay to receive the payment, in sats
+    pub fee: u128,
+    /// The amount of the payment request, in sats
+    pub amount_sats: u64,
+    /// The fiat amount and the rate it was converted at. Recorded as the
+    /// payment's [`Payment::fiat_value`] once it is received.
+    pub fiat_value: PaymentFiatValue,
+}
+
+/// Response from fetching historical fiat rates
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct FetchHistoricalRatesResponse {
+    /// The rates in the range, sorted by timestamp
+    pub rates: Vec<HistoricalRate>,
+}
+
+/// Request to fetch the fiat rates of tokens
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetTokenFiatRatesRequest {
+    pub token_identifiers: Vec<String>,
+}
+
+/// Response from fetching the fiat rates of tokens
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetTokenFiatRatesResponse {
+    /// The rates of the tokens with a known price, in fiat units per whole
+    /// token
+    pub rates: Vec<TokenFiatRates>,
 }
 
 /// The operational status of a Spark service.
@@ -2044,14 +3744,248 @@ pub enum ServiceStatus {
     Major,
 }
 
-/// The status of the Spark network services relevant to the SDK.
+/// The status of the Spark network services relevant to the SDK.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SparkStatus {
+    /// The worst status across all relevant services.
+    pub status: ServiceStatus,
+    /// The last time the status was updated, as a unix timestamp in seconds.
+    pub last_updated: u64,
+}
+
+/// An external dependency of the SDK checked by `check_health`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum HealthDependency {
+    /// The Spark operators, through the coordinator.
+    SparkOperators,
+    /// The Spark service provider (SSP).
+    ServiceProvider,
+    /// The Bitcoin chain service.
+    ChainService,
+    /// The LNURL server backing Lightning addresses.
+    LnurlService,
+    /// The fiat rates service.
+    FiatService,
+    /// The validity of the Breez API key, verified against the LNURL server.
+    ApiKey,
+}
+
+/// The health of a dependency.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum HealthStatus {
+    /// The dependency answered successfully.
+    Healthy,
+    /// The dependency failed or didn't answer in time.
+    Unhealthy { error: String },
+    /// The dependency couldn't be checked, e.g. the API key without an LNURL
+    /// server to verify it against.
+    Unknown { reason: String },
+    /// The dependency isn't configured, so it doesn't affect the SDK.
+    NotConfigured,
+}
+
+/// The result of checking a single dependency.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DependencyHealth {
+    pub dependency: HealthDependency,
+    pub status: HealthStatus,
+    /// How long the check took, in milliseconds. `None` when no request was
+    /// made.
+    pub latency_ms: Option<u64>,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CheckHealthResponse {
+    /// Whether no dependency is [`HealthStatus::Unhealthy`]
+    pub healthy: bool,
+    pub dependencies: Vec<DependencyHealth>,
+}
+
+/// The status of the Spark services a payment rail depends on
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RailStatus {
+    pub rail: PaymentRail,
+    /// The worst status across the services the rail depends on
+    pub status: ServiceStatus,
+}
+
+/// Planned maintenance of the Spark services, during which the affected rails
+/// may be unavailable
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct MaintenanceWindow {
+    /// The title of the maintenance, as published by the status feed
+    pub title: String,
+    /// The rails that depend on the services under maintenance
+    pub rails: Vec<PaymentRail>,
+    /// When the maintenance starts, as a unix timestamp in seconds
+    pub starts_at: u64,
+    /// When the maintenance is expected to end, as a unix timestamp in
+    /// seconds. Unset when no end is announced.
+    pub ends_at: Option<u64>,
+}
+
+/// The status of the Spark network, as returned by
+/// [`BreezSdk::get_service_status`] and reported by
+/// [`SdkEvent::ServiceStatusChanged`](crate::SdkEvent::ServiceStatusChanged)
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ServiceStatusReport {
+    /// The worst status across all rails
+    pub status: ServiceStatus,
+    pub rails: Vec<RailStatus>,
+    /// Ongoing and upcoming maintenance, ordered by start time
+    pub maintenance_windows: Vec<MaintenanceWindow>,
+    /// The last time the status was updated, as a unix timestamp in seconds.
+    pub last_updated: u64,
+}
+
+/// A long-running operation to run in the background with
+/// [`BreezSdk::start_job`]
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum JobRequest {
+    /// Optimizes the wallet's leaves round by round, see
+    /// [`BreezSdk::optimize_leaves`]
+    OptimizeLeaves,
+    /// See [`BreezSdk::export_payments`]
+    ExportPayments { request: ExportPaymentsRequest },
+    /// See [`BreezSdk::export_ledger`]
+    ExportLedger { request: ExportLedgerRequest },
+    /// Follows the exit built with [`BreezSdk::unilateral_exit`] until all
+    /// its leaves are swept, broadcasting its transactions as they become
+    /// ready. A step is a swept leaf.
+    UnilateralExit,
+    /// Sends a payment prepared with a conversion step, see
+    /// [`BreezSdk::send_payment`]. Once the conversion started the job runs
+    /// to the end, a cancellation only applies before it.
+    Conversion { request: SendPaymentRequest },
+}
+
+/// The kind of operation a [`Job`] runs
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum JobKind {
+    OptimizeLeaves,
+    ExportPayments,
+    ExportLedger,
+    UnilateralExit,
+    Conversion,
+}
+
+/// The outcome of a completed [`Job`]
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum JobResult {
+    OptimizeLeaves { rounds_executed: u32 },
+    ExportPayments { response: ExportPaymentsResponse },
+    ExportLedger { response: ExportLedgerResponse },
+    UnilateralExit { progress: UnilateralExitProgress },
+    Conversion { response: SendPaymentResponse },
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum JobStatus {
+    Running,
+    Completed {
+        result: JobResult,
+    },
+    Failed {
+        error: String,
+    },
+    /// The job was cancelled with [`BreezSdk::cancel_job`] or by
+    /// [`BreezSdk::disconnect`]
+    Cancelled,
+}
+
+/// A background operation started with [`BreezSdk::start_job`]
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct Job {
+    pub id: String,
+    pub kind: JobKind,
+    pub status: JobStatus,
+    /// The number of steps completed so far, e.g. optimization rounds
+    pub completed_steps: u32,
+    /// The number of steps of the job, unset if not known in advance
+    pub total_steps: Option<u32>,
+    /// The time the job was started, as a unix timestamp in seconds
+    pub created_at: u64,
+    /// The time the job last changed, as a unix timestamp in seconds
+    pub updated_at: u64,
+}
+
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct StartJobRequest {
+    pub job: JobRequest,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct StartJobResponse {
+    pub job_id: String,
+}
+
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetJobRequest {
+    pub job_id: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetJobResponse {
+    pub job: Job,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListJobsResponse {
+    /// The jobs started since the SDK was connected, oldest first
+    pub jobs: Vec<Job>,
+}
+
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CancelJobRequest {
+    pub job_id: String,
+}
+
+/// What a new wallet can expect when receiving over a payment rail
 #[derive(Debug, Clone, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-pub struct SparkStatus {
-    /// The worst status across all relevant services.
-    pub status: ServiceStatus,
-    /// The last time the status was updated, as a unix timestamp in seconds.
-    pub last_updated: u64,
+pub struct ReceiveOnboardingInfo {
+    pub rail: PaymentRail,
+    /// Whether receiving over the rail is enabled by [`Config::enabled_rails`]
+    pub enabled: bool,
+    /// The smallest amount that can be received, in sats. For on-chain
+    /// deposits this is the smallest deposit that covers the claim fee.
+    pub min_amount_sats: u64,
+    /// The fee expected to be deducted from a receive at current rates, in sats
+    pub estimated_fee_sats: u64,
+    /// The on-chain confirmations needed before the funds are credited
+    pub required_confirmations: u32,
+    /// The expected time until the funds are spendable, in seconds
+    pub estimated_settlement_secs: u64,
+    /// Whether a receive at the estimated fee is credited without user
+    /// action. A deposit whose claim fee exceeds
+    /// [`Config::max_deposit_claim_fee`] has to be claimed manually.
+    pub claimed_automatically: bool,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetReceiveOnboardingInfoResponse {
+    /// One entry per rail: Spark, Lightning and Bitcoin
+    pub rails: Vec<ReceiveOnboardingInfo>,
 }
 
 pub(crate) enum WaitForPaymentIdentifier {
@@ -2070,6 +4004,21 @@ pub struct GetTokensMetadataResponse {
     pub tokens_metadata: Vec<TokenMetadata>,
 }
 
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RefreshTokensMetadataRequest {
+    /// The tokens to refresh. When empty, the tokens held by the wallet are
+    /// refreshed.
+    #[cfg_attr(feature = "uniffi", uniffi(default = []))]
+    pub token_identifiers: Vec<String>,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RefreshTokensMetadataResponse {
+    pub tokens_metadata: Vec<TokenMetadata>,
+}
+
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct SignMessageRequest {
     pub message: String,
@@ -2085,46 +4034,568 @@ pub struct SignMessageResponse {
 }
 
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-pub struct CheckMessageRequest {
-    /// The message that was signed
-    pub message: String,
-    /// The public key that signed the message
-    pub pubkey: String,
-    /// The DER or compact hex encoded signature
-    pub signature: String,
+pub struct CheckMessageRequest {
+    /// The message that was signed
+    pub message: String,
+    /// The public key that signed the message
+    pub pubkey: String,
+    /// The DER or compact hex encoded signature
+    pub signature: String,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CheckMessageResponse {
+    pub is_valid: bool,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+#[derive(Debug, Clone, Serialize)]
+pub struct UserSettings {
+    pub spark_private_mode_enabled: bool,
+
+    /// The label of the currently active stable balance token, or `None` if deactivated.
+    pub stable_balance_active_label: Option<String>,
+
+    /// The fiat currency in which the value of Bitcoin payments is recorded
+    /// when they complete, in place of [`Config::payment_fiat_currency`], or
+    /// `None` if unset.
+    pub display_currency: Option<String>,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct UpdateUserSettingsRequest {
+    pub spark_private_mode_enabled: Option<bool>,
+
+    /// Update the active stable balance token. `None` means no change.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub stable_balance_active_label: Option<StableBalanceActiveLabel>,
+
+    /// Update the display currency. `None` means no change.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub display_currency: Option<DisplayCurrency>,
+}
+
+/// Request to hide a token from the balances returned by `get_info`
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct HideTokenRequest {
+    pub token_identifier: String,
+}
+
+/// Request to list a token hidden with `hide_token` or by
+/// [`Config::token_visibility`] again
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct UnhideTokenRequest {
+    pub token_identifier: String,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ClaimHtlcPaymentRequest {
+    pub preimage: String,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ClaimHtlcPaymentResponse {
+    pub payment: Payment,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SettleHeldPaymentRequest {
+    /// The payment hash of the HODL invoice
+    pub payment_hash: String,
+    /// The preimage of the payment hash
+    pub preimage: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SettleHeldPaymentResponse {
+    pub payment: Payment,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CancelHeldPaymentRequest {
+    /// The payment hash of the HODL invoice
+    pub payment_hash: String,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RefundHtlcPaymentRequest {
+    /// The id of the expired outgoing HTLC payment
+    pub payment_id: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RefundHtlcPaymentResponse {
+    /// The refunded payment, now failed with a returned HTLC
+    pub payment: Payment,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ClaimSpecificTransferRequest {
+    /// The id of the incoming Spark transfer
+    pub transfer_id: String,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ClaimSpecificTransferResponse {
+    /// The payment of the transfer, or `None` if the transfer is not visible
+    /// on the operators yet or can't be claimed yet
+    pub payment: Option<Payment>,
+}
+
+/// An atomic swap between this wallet and a counterparty, made of two Spark
+/// HTLCs locked to the same payment hash: one sent by each side.
+///
+/// The initiator chooses the preimage and locks its funds first. The
+/// participant locks its funds once the initiator's HTLC is visible, with an
+/// earlier expiry, so that it can still claim the initiator's HTLC after the
+/// initiator reveals the preimage by claiming the participant's HTLC.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct Escrow {
+    pub id: String,
+    /// The payment hash both HTLCs are locked to
+    pub payment_hash: String,
+    pub role: EscrowRole,
+    /// The Spark address of the counterparty
+    pub counterparty_address: String,
+    /// The amount in satoshis locked by this wallet
+    pub send_amount_sats: u64,
+    /// The minimum amount in satoshis the counterparty has to lock
+    pub receive_amount_sats: u64,
+    /// The id of the HTLC payment sent by this wallet
+    pub send_payment_id: String,
+    /// The expiry of the HTLC sent by this wallet, as a unix timestamp in seconds
+    pub send_expiry_time: u64,
+    /// The id of the counterparty's HTLC payment, once claimed
+    pub receive_payment_id: Option<String>,
+    pub status: EscrowStatus,
+    /// The creation time of the escrow, as a unix timestamp in seconds
+    pub created_at: u64,
+}
+
+#[derive(Debug, Clone, Copy, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum EscrowRole {
+    /// Chose the preimage and locks its funds first
+    Initiator,
+    /// Locks its funds once the initiator did, with an earlier expiry
+    Participant,
+}
+
+#[derive(Debug, Clone, Copy, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum EscrowStatus {
+    /// The HTLC of this wallet is locked, the counterparty's is not visible yet
+    Funded,
+    /// Both HTLCs are locked
+    CounterpartyFunded,
+    /// The counterparty's HTLC was claimed by this wallet
+    Released,
+    /// The HTLC of this wallet expired and its funds were returned
+    Refunded,
+}
+
+impl fmt::Display for EscrowStatus {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            EscrowStatus::Funded => write!(f, "funded"),
+            EscrowStatus::CounterpartyFunded => write!(f, "counterparty funded"),
+            EscrowStatus::Released => write!(f, "released"),
+            EscrowStatus::Refunded => write!(f, "refunded"),
+        }
+    }
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CreateEscrowRequest {
+    /// The payment hash both HTLCs are locked to
+    pub payment_hash: String,
+    pub role: EscrowRole,
+    /// The Spark address of the counterparty
+    pub counterparty_address: String,
+    /// The amount in satoshis to lock
+    pub send_amount_sats: u64,
+    /// The minimum amount in satoshis the counterparty has to lock
+    pub receive_amount_sats: u64,
+    /// The duration in seconds after which the locked funds are returned.
+    /// As participant, the HTLC has to expire at least 10 minutes before the
+    /// initiator's.
+    pub expiry_duration_secs: u64,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CreateEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetEscrowRequest {
+    pub escrow_id: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListEscrowsResponse {
+    pub escrows: Vec<Escrow>,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ReleaseEscrowRequest {
+    pub escrow_id: String,
+    /// The preimage of the payment hash. Required for the initiator. The
+    /// participant can omit it once the initiator claimed its HTLC, which
+    /// reveals the preimage.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub preimage: Option<String>,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ReleaseEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RefundEscrowRequest {
+    pub escrow_id: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RefundEscrowResponse {
+    pub escrow: Escrow,
+}
+
+/// A marketplace escrow between this wallet as buyer, a seller and an
+/// arbiter, made of a Spark HTLC from the buyer to the seller.
+///
+/// The preimage of the HTLC is generated by the SDK and split into a share
+/// for each of the buyer, the seller and the arbiter, so that any two of
+/// them are needed to recover it, see
+/// [`BreezSdk::recover_arbitrated_escrow_preimage`](crate::BreezSdk::recover_arbitrated_escrow_preimage).
+/// The seller claims the funds once the buyer or the arbiter hands over its
+/// share. A refund likewise needs the approval of two of them, see
+/// [`BreezSdk::refund_arbitrated_escrow`](crate::BreezSdk::refund_arbitrated_escrow),
+/// and only takes effect once the HTLC expires. Escrows that are not
+/// disputed are refunded on expiry like other HTLCs.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ArbitratedEscrow {
+    pub id: String,
+    /// The payment hash the HTLC is locked to
+    pub payment_hash: String,
+    /// The Spark address of the seller
+    pub seller_address: String,
+    /// The public key of the arbiter, hex encoded
+    pub arbiter_pubkey: String,
+    /// The arbiter's share encrypted to its public key with ECIES, hex
+    /// encoded. To be handed to the arbiter, who can decrypt it to release
+    /// the escrow to the seller.
+    pub arbiter_secret: String,
+    /// The amount in satoshis locked by this wallet
+    pub amount_sats: u64,
+    /// The id of the HTLC payment sent to the seller
+    pub payment_id: String,
+    /// The expiry of the HTLC, as a unix timestamp in seconds
+    pub expiry_time: u64,
+    pub status: ArbitratedEscrowStatus,
+    /// The reason given when the escrow was disputed
+    pub dispute_reason: Option<String>,
+    /// The buyer's share, hex encoded, once released. To be handed to the
+    /// seller.
+    pub buyer_share: Option<String>,
+    /// The seller's share, hex encoded. Only set on the escrow returned on
+    /// creation, to be handed to the seller, and not kept by this wallet.
+    pub seller_share: Option<String>,
+    /// The creation time of the escrow, as a unix timestamp in seconds
+    pub created_at: u64,
+}
+
+#[derive(Debug, Clone, Copy, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum ArbitratedEscrowStatus {
+    /// The funds are locked, waiting for the buyer to release them
+    Funded,
+    /// The escrow was disputed, waiting for the arbiter to decide. The funds
+    /// are not returned on expiry until the refund is approved.
+    Disputed,
+    /// The buyer's share was revealed to the seller
+    Released,
+    /// The refund was approved, the funds are returned once the HTLC expires
+    RefundApproved,
+    /// The seller claimed the funds
+    Claimed,
+    /// The HTLC expired and the funds were returned to the buyer
+    Refunded,
+}
+
+impl fmt::Display for ArbitratedEscrowStatus {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            ArbitratedEscrowStatus::Funded => write!(f, "funded"),
+            ArbitratedEscrowStatus::Disputed => write!(f, "disputed"),
+            ArbitratedEscrowStatus::Released => write!(f, "released"),
+            ArbitratedEscrowStatus::RefundApproved => write!(f, "refund approved"),
+            ArbitratedEscrowStatus::Claimed => write!(f, "claimed"),
+            ArbitratedEscrowStatus::Refunded => write!(f, "refunded"),
+        }
+    }
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CreateArbitratedEscrowRequest {
+    /// The Spark address of the seller
+    pub seller_address: String,
+    /// The public key of the arbiter, hex encoded
+    pub arbiter_pubkey: String,
+    /// The amount in satoshis to lock
+    pub amount_sats: u64,
+    /// The duration in seconds after which the locked funds are returned,
+    /// unless released
+    pub expiry_duration_secs: u64,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CreateArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListArbitratedEscrowsResponse {
+    pub escrows: Vec<ArbitratedEscrow>,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ReleaseArbitratedEscrowRequest {
+    pub escrow_id: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ReleaseArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DisputeArbitratedEscrowRequest {
+    pub escrow_id: String,
+    pub reason: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DisputeArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RefundArbitratedEscrowRequest {
+    pub escrow_id: String,
+    /// The seller's or the arbiter's signature of `refund:<payment_hash>`,
+    /// hex encoded in DER or compact format, as created by
+    /// [`BreezSdk::sign_message`](crate::BreezSdk::sign_message)
+    pub signature: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RefundArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RecoverArbitratedEscrowPreimageRequest {
+    /// The hex encoded shares of two of the buyer, the seller and the
+    /// arbiter
+    pub shares: Vec<String>,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RecoverArbitratedEscrowPreimageResponse {
+    /// The preimage to claim the escrow's HTLC with, hex encoded
+    pub preimage: String,
+}
+
+/// A payment that becomes claimable by the receiver only after a delivery
+/// time, created by setting [`SendPaymentRequest::deliver_after`].
+///
+/// The funds are locked in a Spark HTLC to the receiver, whose preimage is
+/// held by this wallet and only revealed once the delivery time passed. The
+/// SDK doesn't send the preimage to the receiver: the sender has to pass it
+/// on, for example in a message, and the receiver claims the payment with
+/// [`BreezSdk::claim_htlc_payment`](crate::sdk::BreezSdk::claim_htlc_payment)
+/// within a week after the delivery time. Otherwise the funds are returned to
+/// the sender.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct TimeLockedPayment {
+    /// The id of the HTLC payment
+    pub payment_id: String,
+    /// The Spark address of the receiver
+    pub receiver_address: String,
+    pub amount_sats: u64,
+    /// The time after which the payment can be claimed, as a unix timestamp in seconds
+    pub deliver_after: u64,
+    /// The expiry of the HTLC, a week after the delivery time, as a unix
+    /// timestamp in seconds. Unclaimed or cancelled funds are only returned
+    /// to the sender after this time.
+    pub expiry_time: u64,
+    pub status: TimeLockedPaymentStatus,
+    /// The preimage the receiver claims the payment with, once the payment
+    /// is deliverable
+    pub preimage: Option<String>,
+    /// The creation time of the payment, as a unix timestamp in seconds
+    pub created_at: u64,
+}
+
+#[derive(Debug, Clone, Copy, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum TimeLockedPaymentStatus {
+    /// The delivery time did not pass yet
+    Locked,
+    /// The delivery time passed and the preimage is revealed
+    Deliverable,
+    /// The receiver claimed the payment
+    Claimed,
+    /// The payment was cancelled before delivery. Its funds stay locked
+    /// until the HTLC expires, a week after the delivery time, and are then
+    /// returned
+    Cancelled,
+    /// The HTLC expired and its funds were returned
+    Returned,
+}
+
+impl fmt::Display for TimeLockedPaymentStatus {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            TimeLockedPaymentStatus::Locked => write!(f, "locked"),
+            TimeLockedPaymentStatus::Deliverable => write!(f, "deliverable"),
+            TimeLockedPaymentStatus::Claimed => write!(f, "claimed"),
+            TimeLockedPaymentStatus::Cancelled => write!(f, "cancelled"),
+            TimeLockedPaymentStatus::Returned => write!(f, "returned"),
+        }
+    }
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListTimeLockedPaymentsResponse {
+    pub payments: Vec<TimeLockedPayment>,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CancelTimeLockedPaymentRequest {
+    pub payment_id: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct CancelTimeLockedPaymentResponse {
+    pub payment: TimeLockedPayment,
 }
 
+/// A stream of small Spark transfers sent to a destination at a fixed rate,
+/// for paying per second of media playback or API usage.
+///
+/// The receiver can aggregate the transfers into a single payment by having
+/// the stream pay a Spark invoice that accepts partial payments.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-pub struct CheckMessageResponse {
-    pub is_valid: bool,
+pub struct PaymentStream {
+    pub id: String,
+    /// The Spark address or Spark invoice the stream pays
+    pub destination: String,
+    /// The amount in satoshis streamed per second
+    pub rate_sats_per_sec: u64,
+    /// The total amount in satoshis after which the stream stops
+    pub max_amount_sats: u64,
+    /// The interval in seconds between transfers
+    pub interval_secs: u32,
+    /// The amount in satoshis sent so far
+    pub sent_sats: u64,
+    pub status: PaymentStreamStatus,
+    /// The ids of the payments sent by the stream
+    pub payment_ids: Vec<String>,
+    /// The reason the stream failed
+    pub error: Option<String>,
+    /// The time the stream was opened, as a unix timestamp in seconds
+    pub started_at: u64,
+    /// The time the stream stopped, as a unix timestamp in seconds
+    pub closed_at: Option<u64>,
 }
 
-#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-#[derive(Debug, Clone, Serialize)]
-pub struct UserSettings {
-    pub spark_private_mode_enabled: bool,
+#[derive(Debug, Clone, Copy, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum PaymentStreamStatus {
+    /// The stream is sending transfers
+    Open,
+    /// The stream was closed before reaching its maximum amount
+    Closed,
+    /// The stream stopped after sending its maximum amount
+    CapReached,
+    /// The stream stopped after a transfer failed
+    Failed,
+}
 
-    /// The label of the currently active stable balance token, or `None` if deactivated.
-    pub stable_balance_active_label: Option<String>,
+impl fmt::Display for PaymentStreamStatus {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            PaymentStreamStatus::Open => write!(f, "open"),
+            PaymentStreamStatus::Closed => write!(f, "closed"),
+            PaymentStreamStatus::CapReached => write!(f, "cap reached"),
+            PaymentStreamStatus::Failed => write!(f, "failed"),
+        }
+    }
 }
 
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-pub struct UpdateUserSettingsRequest {
-    pub spark_private_mode_enabled: Option<bool>,
+pub struct OpenPaymentStreamRequest {
+    /// The Spark address or Spark invoice to pay
+    pub destination: String,
+    /// The amount in satoshis to stream per second
+    pub rate_sats_per_sec: u64,
+    /// The total amount in satoshis after which the stream stops
+    pub max_amount_sats: u64,
+    /// The interval in seconds between transfers. Defaults to 10 seconds.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub interval_secs: Option<u32>,
+}
 
-    /// Update the active stable balance token. `None` means no change.
-    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
-    pub stable_balance_active_label: Option<StableBalanceActiveLabel>,
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct OpenPaymentStreamResponse {
+    pub stream: PaymentStream,
 }
 
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-pub struct ClaimHtlcPaymentRequest {
-    pub preimage: String,
+pub struct ClosePaymentStreamRequest {
+    pub stream_id: String,
 }
 
+#[derive(Debug, Clone, Serialize)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
-pub struct ClaimHtlcPaymentResponse {
-    pub payment: Payment,
+pub struct ClosePaymentStreamResponse {
+    pub stream: PaymentStream,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListPaymentStreamsResponse {
+    pub streams: Vec<PaymentStream>,
 }
 
 #[derive(Debug, Clone, Deserialize, Serialize)]
@@ -2133,6 +4604,83 @@ pub struct LnurlReceiveMetadata {
     pub nostr_zap_request: Option<String>,
     pub nostr_zap_receipt: Option<String>,
     pub sender_comment: Option<String>,
+    /// The lightning address of the wallet the payment was sent to, which
+    /// tells its addresses apart when it has several
+    pub lightning_address: Option<String>,
+}
+
+/// What the payer attached to a received payment, unified across Lightning
+/// and Spark payments
+#[derive(Debug, Clone, Default, Deserialize, Serialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ReceiveMetadata {
+    /// The comment of an LNURL-pay (LUD-12)
+    pub sender_comment: Option<String>,
+    /// The Nostr zap request (kind 9734) as JSON, if the payment is a zap
+    pub nostr_zap_request: Option<String>,
+    /// The public key identifying the payer: the Nostr public key of a zap,
+    /// or the public key a Spark invoice could only be paid by
+    pub payer_identity: Option<String>,
+    /// A note for the receiver: the content of a zap, or the description of
+    /// a Spark invoice
+    pub payer_note: Option<String>,
+}
+
+impl ReceiveMetadata {
+    /// Collects the receive metadata of a received payment, or `None` if the
+    /// payer attached nothing
+    fn from_payment(payment: &Payment) -> Option<Self> {
+        if payment.payment_type != PaymentType::Receive {
+            return None;
+        }
+        let metadata = match payment.details.as_ref()? {
+            PaymentDetails::Lightning {
+                lnurl_receive_metadata: Some(lnurl_receive_metadata),
+                ..
+            } => {
+                let zap_request = lnurl_receive_metadata
+                    .nostr_zap_request
+                    .as_deref()
+                    .and_then(|zap_request| serde_json::from_str::<Value>(zap_request).ok());
+                let zap_field = |field: &str| {
+                    zap_request
+                        .as_ref()
+                        .and_then(|zap_request| zap_request.get(field)?.as_str())
+                        .filter(|value| !value.is_empty())
+                        .map(ToString::to_string)
+                };
+                ReceiveMetadata {
+                    sender_comment: lnurl_receive_metadata.sender_comment.clone(),
+                    nostr_zap_request: lnurl_receive_metadata.nostr_zap_request.clone(),
+                    payer_identity: zap_field("pubkey"),
+                    payer_note: zap_field("content"),
+                }
+            }
+            PaymentDetails::Spark {
+                invoice_details: Some(invoice_details),
+                ..
+            }
+            | PaymentDetails::Token {
+                invoice_details: Some(invoice_details),
+                ..
+            } => ReceiveMetadata {
+                payer_note: invoice_details.description.clone(),
+                payer_identity: spark_invoice_sender(&invoice_details.invoice),
+                ..Default::default()
+            },
+            _ => return None,
+        };
+        (metadata != ReceiveMetadata::default()).then_some(metadata)
+    }
+}
+
+/// The public key a Spark invoice can only be paid by, if it was restricted
+/// to a payer
+fn spark_invoice_sender(invoice: &str) -> Option<String> {
+    match breez_sdk_common::input::parse_spark_address(invoice, &Default::default())? {
+        breez_sdk_common::input::InputType::SparkInvoice(details) => details.sender_public_key,
+        _ => None,
+    }
 }
 
 /// Mode of a manually-triggered optimization run.
@@ -2155,6 +4703,18 @@ pub struct OptimizeLeavesRequest {
     pub mode: OptimizationMode,
 }
 
+/// Conditions of the host device, reported through
+/// [`BreezSdk::set_host_conditions`], that automatic leaf optimization is
+/// gated on. See [`LeafOptimizationPolicy`].
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct HostConditions {
+    /// Whether the device is on a metered connection, such as cellular data.
+    /// `None` if unknown.
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub is_metered: Option<bool>,
+}
+
 /// Response from a [`BreezSdk::optimize_leaves`] call.
 #[derive(Debug, Clone, Deserialize, Serialize, PartialEq)]
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
@@ -2210,16 +4770,46 @@ pub struct Contact {
     pub name: String,
     /// A Lightning address (user@domain).
     pub payment_identifier: String,
+    /// Other Lightning addresses of the contact, e.g. of their other wallets.
+    #[serde(default)]
+    pub additional_payment_identifiers: Vec<String>,
+    /// The URL of the contact's avatar image. A `data:` URL can hold the
+    /// image itself.
+    #[serde(default)]
+    pub avatar_url: Option<String>,
+    /// Freeform notes about the contact.
+    #[serde(default)]
+    pub notes: Option<String>,
     pub created_at: u64,
     pub updated_at: u64,
 }
 
+impl Contact {
+    /// Returns true if the Lightning address is one of the contact's,
+    /// ignoring case.
+    pub(crate) fn has_payment_identifier(&self, identifier: &str) -> bool {
+        let identifier = identifier.trim();
+        std::iter::once(&self.payment_identifier)
+            .chain(&self.additional_payment_identifiers)
+            .any(|id| id.trim().eq_ignore_ascii_case(identifier))
+    }
+}
+
 /// Request to add a new contact.
 #[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
 pub struct AddContactRequest {
     pub name: String,
     /// A Lightning address (user@domain).
     pub payment_identifier: String,
+    /// Other Lightning addresses of the contact.
+    #[cfg_attr(feature = "uniffi", uniffi(default = []))]
+    pub additional_payment_identifiers: Vec<String>,
+    /// The URL of the contact's avatar image, or a `data:` URL of the image.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub avatar_url: Option<String>,
+    /// Freeform notes about the contact.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub notes: Option<String>,
 }
 
 /// Request to update an existing contact.
@@ -2229,6 +4819,79 @@ pub struct UpdateContactRequest {
     pub name: String,
     /// A Lightning address (user@domain).
     pub payment_identifier: String,
+    /// Other Lightning addresses of the contact.
+    #[cfg_attr(feature = "uniffi", uniffi(default = []))]
+    pub additional_payment_identifiers: Vec<String>,
+    /// The URL of the contact's avatar image, or a `data:` URL of the image.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub avatar_url: Option<String>,
+    /// Freeform notes about the contact.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub notes: Option<String>,
+}
+
+/// Request to list the payments made to a contact.
+#[derive(Debug, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetContactPaymentsRequest {
+    pub contact_id: String,
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub offset: Option<u32>,
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub limit: Option<u32>,
+}
+
+/// Response from [`BreezSdk::get_contact_payments`](crate::BreezSdk::get_contact_payments).
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetContactPaymentsResponse {
+    /// The payments to any of the contact's Lightning addresses, newest first
+    pub payments: Vec<Payment>,
+}
+
+/// Request to suggest contacts from the payment history.
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SuggestContactsRequest {
+    /// The number of payments to a counterparty needed to suggest it.
+    /// Defaults to 2.
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub min_payment_count: Option<u32>,
+    /// The maximum number of suggestions to return
+    #[cfg_attr(feature = "uniffi", uniffi(default = None))]
+    pub limit: Option<u32>,
+}
+
+/// Response from [`BreezSdk::suggest_contacts`](crate::BreezSdk::suggest_contacts).
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SuggestContactsResponse {
+    /// The suggestions, most paid first
+    pub suggestions: Vec<ContactSuggestion>,
+}
+
+/// A recurring counterparty from the payment history that isn't a contact yet.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ContactSuggestion {
+    /// The Lightning address, Spark address or LNURL domain paid
+    pub payment_identifier: String,
+    pub kind: ContactSuggestionKind,
+    /// The number of completed payments sent to the counterparty
+    pub payment_count: u32,
+    /// The time of the last payment, as a unix timestamp in seconds
+    pub last_paid_at: u64,
+}
+
+/// The kind of counterparty of a [`ContactSuggestion`]. Only Lightning
+/// addresses can be saved as contacts.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum ContactSuggestionKind {
+    LightningAddress,
+    SparkAddress,
+    /// The domain of an LNURL-pay service paid without a Lightning address
+    LnurlDomain,
 }
 
 /// Request to list contacts with optional pagination.
@@ -2298,6 +4961,29 @@ pub struct UnregisterWebhookRequest {
     pub webhook_id: String,
 }
 
+/// Request to handle a push notification sent for a registered webhook.
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct HandleNotificationPayloadRequest {
+    pub config: Config,
+    pub seed: Seed,
+    pub storage_dir: String,
+    /// The JSON payload of the notification, whose `type` field holds the
+    /// webhook event type.
+    pub payload: String,
+}
+
+/// The outcome of handling a push notification, for the notification
+/// extension to display.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct HandleNotificationPayloadResponse {
+    /// The event the notification was sent for.
+    pub event_type: WebhookEventType,
+    /// The payments claimed or updated while handling the notification, in
+    /// their latest state.
+    pub payments: Vec<Payment>,
+}
+
 // ===========================================================================
 // Unilateral exit
 // ===========================================================================
@@ -2513,3 +5199,294 @@ pub struct UnilateralExitResponse {
     /// with shared ancestors appearing once and the sweep last.
     pub transactions: Vec<UnilateralExitTransaction>,
 }
+
+/// How far a leaf of the tracked unilateral exit got on-chain.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum UnilateralExitLeafStage {
+    /// The transactions down to the leaf are not all confirmed yet, or the
+    /// leaf's refund was already on-chain when the exit was built and the
+    /// sweep has not confirmed.
+    Pending,
+    /// The leaf's node transaction confirmed. Its refund can be broadcast once
+    /// its timelock matured.
+    LeafConfirmed,
+    /// The leaf's refund confirmed, waiting for the sweep.
+    RefundConfirmed,
+    /// The leaf's funds were swept to the destination.
+    Swept,
+}
+
+/// A leaf of the tracked unilateral exit, with its progress.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct UnilateralExitLeafProgress {
+    pub leaf_id: String,
+    /// The leaf's value in satoshis.
+    pub value: u64,
+    pub stage: UnilateralExitLeafStage,
+}
+
+/// The unilateral exit last built with `unilateral_exit`, persisted so that
+/// broadcasting it can resume after a restart.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct UnilateralExitProgress {
+    /// The Bitcoin address the swept funds are sent to.
+    pub destination: String,
+    /// The fee rate the exit was built at, in sat/vByte.
+    pub fee_rate_sat_per_vbyte: u64,
+    pub leaves: Vec<UnilateralExitLeafProgress>,
+    /// The signed transaction set returned by `unilateral_exit`, with the
+    /// status of each transaction updated from the chain.
+    pub transactions: Vec<UnilateralExitTransaction>,
+    /// The time the exit was built, as a unix timestamp in seconds.
+    pub built_at: u64,
+}
+
+/// Response from `get_unilateral_exit_progress`.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetUnilateralExitProgressResponse {
+    /// The tracked exit, unset when no exit was built.
+    pub progress: Option<UnilateralExitProgress>,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct FreezeWalletRequest {
+    /// The password needed to unfreeze the wallet
+    pub password: String,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct UnfreezeWalletRequest {
+    /// The password the wallet was frozen with
+    pub password: String,
+}
+
+#[derive(Debug, Clone, Default)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetSeedBackupChallengeRequest {
+    /// The number of words to ask for. Defaults to 3
+    #[cfg_attr(feature = "uniffi", uniffi(default=None))]
+    pub word_count: Option<u32>,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetSeedBackupChallengeResponse {
+    /// The 1-based positions of the mnemonic words to ask for, in ascending
+    /// order
+    pub positions: Vec<u32>,
+    /// The number of words of the mnemonic
+    pub seed_word_count: u32,
+}
+
+/// A word of a mnemonic backup, at its 1-based position.
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SeedBackupWord {
+    pub position: u32,
+    pub word: String,
+}
+
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct VerifySeedBackupRequest {
+    /// The words at the positions of the last challenge, each exactly once
+    pub words: Vec<SeedBackupWord>,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct VerifySeedBackupResponse {
+    /// Whether every word matches the mnemonic. Which words didn't match is
+    /// not reported
+    pub verified: bool,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct SignerSelfTestResponse {
+    /// Whether the signer signed a message that verifies against the identity
+    /// public key
+    pub passed: bool,
+    /// The reason the self-test failed
+    pub error: Option<String>,
+}
+
+/// A static deposit address of the wallet along with its usage.
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DepositAddressInfo {
+    pub address: String,
+    /// The time the address was first returned by `receive_payment`, as a
+    /// unix timestamp in seconds. `None` for addresses issued before the
+    /// history was recorded or by another instance of the wallet.
+    pub issued_at: Option<u64>,
+    /// The number of outputs sent to the address
+    pub deposit_count: u32,
+    pub total_received_sats: u64,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct GetDepositAddressHistoryResponse {
+    /// The addresses, most recently issued first
+    pub addresses: Vec<DepositAddressInfo>,
+}
+
+#[derive(Debug, Clone, Deserialize, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DeriveApplicationKeyRequest {
+    /// Identifies the purpose of the key, such as `nostr`. Up to 128 bytes.
+    pub label: String,
+}
+
+#[derive(Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DeriveApplicationKeyResponse {
+    /// The hex-encoded secp256k1 private key
+    pub private_key: String,
+    /// The hex-encoded compressed public key. Its last 32 bytes are the
+    /// x-only public key used by Nostr.
+    pub public_key: String,
+}
+
+#[derive(Debug, Clone, Deserialize, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct BackupStateRequest {
+    /// The URL the encrypted backup is posted to
+    pub url: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct BackupStateResponse {
+    /// The number of contacts in the backup
+    pub contact_count: u32,
+    /// The time of the backup, as a unix timestamp in seconds
+    pub backed_up_at: u64,
+}
+
+#[derive(Debug, Clone, Deserialize, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RestoreStateRequest {
+    /// The URL the encrypted backup is fetched from
+    pub url: String,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RestoreStateResponse {
+    /// The number of contacts restored
+    pub contacts_restored: u32,
+    /// The time the restored backup was made, as a unix timestamp in seconds
+    pub backed_up_at: u64,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DeleteWalletDataResponse {
+    /// Whether a lightning address was registered and is now deleted
+    pub lightning_address_deleted: bool,
+    /// The number of labeled lightning addresses deleted
+    pub labeled_lightning_addresses_deleted: u32,
+    /// The number of payment links deleted
+    pub payment_links_deleted: u32,
+    /// The number of webhooks unregistered
+    pub webhooks_deleted: u32,
+    /// Whether real-time sync is configured, in which case the records
+    /// synced across devices are kept encrypted on the sync server, which
+    /// offers no way to delete them
+    pub sync_records_kept: bool,
+}
+
+#[derive(Debug, Clone, Deserialize, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RequestTestFundsRequest {
+    /// The amount to request from the faucet, in sats
+    pub amount_sats: u64,
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct RequestTestFundsResponse {
+    /// The deposit address the funds were sent to
+    pub address: String,
+    /// The id of the faucet transaction
+    pub txid: String,
+}
+
+/// A duress configuration, created with
+/// [`create_duress_config`](crate::create_duress_config). Entering the duress
+/// PIN opens a decoy wallet, derived from the same seed at a separate account
+/// number, instead of the real one.
+#[derive(Debug, Clone, Deserialize, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct DuressConfig {
+    /// The scrypt hash of the duress PIN, with its salt and cost parameters
+    pub pin_hash: String,
+    /// The account number of the decoy wallet
+    pub decoy_account_number: u32,
+}
+
+/// An on-chain transaction the SDK broadcast or requested on behalf of the
+/// wallet, monitored until it is buried under enough blocks.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct OnchainTransaction {
+    pub tx_id: String,
+    pub kind: OnchainTransactionKind,
+    pub status: OnchainTransactionStatus,
+}
+
+/// The operation an [`OnchainTransaction`] belongs to.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum OnchainTransactionKind {
+    /// A cooperative exit started with `send_payment` to a Bitcoin address.
+    Withdrawal { payment_id: String },
+    /// A refund of a deposit, started with `refund_deposit` or
+    /// `bump_refund_fee`.
+    DepositRefund {
+        deposit_txid: String,
+        deposit_vout: u32,
+    },
+    /// A transaction of the unilateral exit, broadcast by the SDK during
+    /// sync. `node_id` is the tree node of a node or refund transaction.
+    UnilateralExit {
+        tx_kind: UnilateralExitTxKind,
+        node_id: Option<String>,
+    },
+}
+
+/// The status of an [`OnchainTransaction`], from broadcast until final.
+#[derive(Debug, Clone, Serialize, Deserialize, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum OnchainTransactionStatus {
+    /// The transaction was broadcast but not seen by the chain service yet.
+    Broadcast,
+    /// The transaction is in the mempool.
+    InMempool,
+    /// The transaction is in a block. `confirmations` is unset when the chain
+    /// service doesn't report the tip height.
+    Confirmed {
+        block_height: u32,
+        confirmations: Option<u32>,
+    },
+    /// The transaction left the mempool without confirming, and its inputs
+    /// are unspent again.
+    Evicted,
+    /// The transaction's inputs were spent by another transaction.
+    Replaced { replacement_tx_id: String },
+}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListOnchainTransactionsRequest {}
+
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct ListOnchainTransactionsResponse {
+    /// The monitored transactions, most recently broadcast first
+    pub transactions: Vec<OnchainTransaction>,
+}
@@ -84,13 +84,64 @@ pub trait PaymentObserver: Send + Sync {
     async fn after_send(&self, updates: Vec<PaymentIdUpdate>) -> Result<(), PaymentObserverError>;
 }
 
+/// The decision of a [`SendApprover`] on an outgoing payment
+#[derive(Debug, Clone, PartialEq)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
+pub enum SendApproval {
+    /// Send the payment
+    Approved,
+    /// Cancel the payment, failing the send with `SdkError::PaymentRejected`
+    Rejected { reason: String },
+}
+
+/// This interface is used to approve every outgoing Lightning, Spark, onchain Bitcoin and token
+/// payment before funds move, for example with a 2FA or biometric prompt or a server-side risk
+/// check.
+///
+/// `approve` is called for each payment of every send path, including LNURL payments and token
+/// conversions, right before the funds are sent. Unlike [`PaymentObserver`], it can wait for user
+/// input and reject a payment with a reason. If it returns an error the payment is cancelled too.
+#[cfg_attr(feature = "uniffi", uniffi::export(with_foreign))]
+#[macros::async_trait]
+pub trait SendApprover: Send + Sync {
+    /// Called before a Lightning, Spark, onchain Bitcoin or token payment is made
+    async fn approve(
+        &self,
+        payment: ProvisionalPayment,
+    ) -> Result<SendApproval, PaymentObserverError>;
+}
+
+/// Adapts the payment observer and the send approver to the Spark wallet's transfer observer.
+/// The approver runs first, so the observer only sees approved payments.
 pub(crate) struct SparkTransferObserver {
-    inner: Arc<dyn PaymentObserver>,
+    observer: Option<Arc<dyn PaymentObserver>>,
+    approver: Option<Arc<dyn SendApprover>>,
 }
 
 impl SparkTransferObserver {
-    pub fn new(inner: Arc<dyn PaymentObserver>) -> Self {
-        Self { inner }
+    pub fn new(
+        observer: Option<Arc<dyn PaymentObserver>>,
+        approver: Option<Arc<dyn SendApprover>>,
+    ) -> Self {
+        Self { observer, approver }
+    }
+
+    async fn before_send(
+        &self,
+        payments: Vec<ProvisionalPayment>,
+    ) -> Result<(), TransferObserverError> {
+        if let Some(approver) = &self.approver {
+            for payment in &payments {
+                if let SendApproval::Rejected { reason } = approver.approve(payment.clone()).await?
+                {
+                    return Err(TransferObserverError::Rejected(reason));
+                }
+            }
+        }
+        if let Some(observer) = &self.observer {
+            observer.before_send(payments).await?;
+        }
+        Ok(())
     }
 }
 
@@ -102,16 +153,14 @@ impl spark_wallet::TransferObserver for SparkTransferObserver {
         withdrawal_address: &bitcoin::Address,
         amount_sats: u64,
     ) -> Result<(), TransferObserverError> {
-        Ok(self
-            .inner
-            .before_send(vec![ProvisionalPayment {
-                payment_id: transfer_id.to_string(),
-                amount: u128::from(amount_sats),
-                details: ProvisionalPaymentDetails::Bitcoin {
-                    withdrawal_address: withdrawal_address.to_string(),
-                },
-            }])
-            .await?)
+        self.before_send(vec![ProvisionalPayment {
+            payment_id: transfer_id.to_string(),
+            amount: u128::from(amount_sats),
+            details: ProvisionalPaymentDetails::Bitcoin {
+                withdrawal_address: withdrawal_address.to_string(),
+            },
+        }])
+        .await
     }
     async fn before_send_lightning_payment(
         &self,
@@ -119,16 +168,14 @@ impl spark_wallet::TransferObserver for SparkTransferObserver {
         invoice: &str,
         amount_sats: u64,
     ) -> Result<(), TransferObserverError> {
-        Ok(self
-            .inner
-            .before_send(vec![ProvisionalPayment {
-                payment_id: transfer_id.to_string(),
-                amount: u128::from(amount_sats),
-                details: ProvisionalPaymentDetails::Lightning {
-                    invoice: invoice.to_string(),
-                },
-            }])
-            .await?)
+        self.before_send(vec![ProvisionalPayment {
+            payment_id: transfer_id.to_string(),
+            amount: u128::from(amount_sats),
+            details: ProvisionalPaymentDetails::Lightning {
+                invoice: invoice.to_string(),
+            },
+        }])
+        .await
     }
 
     async fn before_send_token(
@@ -137,23 +184,21 @@ impl spark_wallet::TransferObserver for SparkTransferObserver {
         token_id: &str,
         receiver_outputs: Vec<spark_wallet::ReceiverTokenOutput>,
     ) -> Result<(), TransferObserverError> {
-        Ok(self
-            .inner
-            .before_send(
-                receiver_outputs
-                    .into_iter()
-                    .enumerate()
-                    .map(|(index, output)| ProvisionalPayment {
-                        payment_id: format!("{partial_tx_id}:{index}"),
-                        amount: output.amount,
-                        details: ProvisionalPaymentDetails::Token {
-                            token_id: token_id.to_string(),
-                            pay_request: output.pay_request,
-                        },
-                    })
-                    .collect(),
-            )
-            .await?)
+        self.before_send(
+            receiver_outputs
+                .into_iter()
+                .enumerate()
+                .map(|(index, output)| ProvisionalPayment {
+                    payment_id: format!("{partial_tx_id}:{index}"),
+                    amount: output.amount,
+                    details: ProvisionalPaymentDetails::Token {
+                        token_id: token_id.to_string(),
+                        pay_request: output.pay_request,
+                    },
+                })
+                .collect(),
+        )
+        .await
     }
 
     async fn before_send_transfer(
@@ -162,16 +207,14 @@ impl spark_wallet::TransferObserver for SparkTransferObserver {
         receiver_address: &str,
         amount_sats: u64,
     ) -> Result<(), TransferObserverError> {
-        Ok(self
-            .inner
-            .before_send(vec![ProvisionalPayment {
-                payment_id: transfer_id.to_string(),
-                amount: u128::from(amount_sats),
-                details: ProvisionalPaymentDetails::Spark {
-                    pay_request: receiver_address.to_string(),
-                },
-            }])
-            .await?)
+        self.before_send(vec![ProvisionalPayment {
+            payment_id: transfer_id.to_string(),
+            amount: u128::from(amount_sats),
+            details: ProvisionalPaymentDetails::Spark {
+                pay_request: receiver_address.to_string(),
+            },
+        }])
+        .await
     }
 
     async fn after_send_token(
@@ -180,6 +223,9 @@ impl spark_wallet::TransferObserver for SparkTransferObserver {
         final_tx_id: &str,
         receiver_output_count: usize,
     ) -> Result<(), TransferObserverError> {
+        let Some(observer) = &self.observer else {
+            return Ok(());
+        };
         // Pair each provisional id minted by before_send_token with its final id. The receiver
         // outputs keep their order (and vout) across the partial and final transaction, so index i
         // maps to vout i.
@@ -189,6 +235,87 @@ impl spark_wallet::TransferObserver for SparkTransferObserver {
                 final_payment_id: format!("{final_tx_id}:{i}"),
             })
             .collect();
-        Ok(self.inner.after_send(updates).await?)
+        Ok(observer.after_send(updates).await?)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::sync::Mutex;
+
+    use macros::async_test_all;
+    use spark_wallet::TransferObserver;
+
+    use super::*;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    struct RejectLargeSends;
+
+    #[macros::async_trait]
+    impl SendApprover for RejectLargeSends {
+        async fn approve(
+            &self,
+            payment: ProvisionalPayment,
+        ) -> Result<SendApproval, PaymentObserverError> {
+            if payment.amount > 1_000 {
+                return Ok(SendApproval::Rejected {
+                    reason: "Amount too large".to_string(),
+                });
+            }
+            Ok(SendApproval::Approved)
+        }
+    }
+
+    #[derive(Default)]
+    struct RecordingObserver {
+        payments: Mutex<Vec<String>>,
+    }
+
+    #[macros::async_trait]
+    impl PaymentObserver for RecordingObserver {
+        async fn before_send(
+            &self,
+            payments: Vec<ProvisionalPayment>,
+        ) -> Result<(), PaymentObserverError> {
+            self.payments
+                .lock()
+                .unwrap()
+                .extend(payments.into_iter().map(|p| p.payment_id));
+            Ok(())
+        }
+
+        async fn after_send(
+            &self,
+            _updates: Vec<PaymentIdUpdate>,
+        ) -> Result<(), PaymentObserverError> {
+            Ok(())
+        }
+    }
+
+    #[async_test_all]
+    async fn test_send_approver_runs_before_observer() {
+        let observer = Arc::new(RecordingObserver::default());
+        let transfer_observer = SparkTransferObserver::new(
+            Some(Arc::clone(&observer) as Arc<dyn PaymentObserver>),
+            Some(Arc::new(RejectLargeSends)),
+        );
+
+        let small = TransferId::generate();
+        transfer_observer
+            .before_send_transfer(&small, "spark1address", 500)
+            .await
+            .unwrap();
+        let err = transfer_observer
+            .before_send_transfer(&TransferId::generate(), "spark1address", 5_000)
+            .await
+            .unwrap_err();
+        assert!(
+            matches!(err, TransferObserverError::Rejected(reason) if reason == "Amount too large")
+        );
+
+        // The rejected payment never reaches the observer
+        assert_eq!(*observer.payments.lock().unwrap(), vec![small.to_string()]);
     }
 }
@@ -0,0 +1,97 @@
+use serde::{Deserialize, Serialize};
+use thiserror::Error;
+
+use crate::PaymentStatus;
+
+/// What a [`SdkPlugin`] provides to the SDK
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct PluginCapabilities {
+    /// The plugin holds funds outside the Spark wallet, reported in
+    /// [`GetInfoResponse::plugin_balances`](crate::GetInfoResponse::plugin_balances)
+    pub balance_provider: bool,
+    /// The plugin can pay destinations the SDK doesn't support, as
+    /// [`SendPaymentMethod::Plugin`](crate::SendPaymentMethod::Plugin)
+    pub payment_rail: bool,
+}
+
+/// Describes a registered [`SdkPlugin`]
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct PluginInfo {
+    /// The unique name of the plugin, e.g. `fedimint`
+    pub name: String,
+    pub capabilities: PluginCapabilities,
+}
+
+/// The balance held by a balance provider plugin
+#[derive(Debug, Clone, Serialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct PluginBalance {
+    pub plugin_name: String,
+    /// The balance in satoshis, unset if the plugin failed to report it
+    pub balance_sats: Option<u64>,
+}
+
+/// A plugin's quote to pay a destination, returned by [`SdkPlugin::prepare_send`]
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct PluginSendQuote {
+    /// The amount the recipient receives, in satoshis
+    pub amount_sats: u64,
+    /// The fee charged by the plugin, in satoshis
+    pub fee_sats: u64,
+    /// Plugin-internal state, produced by `prepare_send` and passed back to `send`
+    pub data: String,
+}
+
+/// The outcome of a payment sent by a plugin
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Record))]
+pub struct PluginSendResult {
+    /// The id of the payment on the plugin's rail
+    pub payment_id: String,
+    pub status: PaymentStatus,
+}
+
+#[derive(Debug, Error, Clone)]
+#[cfg_attr(feature = "uniffi", derive(uniffi::Error))]
+pub enum PluginError {
+    #[error("Service connectivity: {0}")]
+    ServiceConnectivity(String),
+    #[error("Insufficient funds")]
+    InsufficientFunds,
+    #[error("Generic: {0}")]
+    Generic(String),
+}
+
+/// This interface is used to extend the SDK with custom payment rails and
+/// balance providers, for example an ecash wallet or an exchange account.
+///
+/// Plugins are registered with `SdkBuilder::with_plugin`. `start` is called
+/// once the SDK is connected and `stop` when it is disconnected. A balance
+/// provider's balance is reported by `get_info`, and a payment rail is offered
+/// every destination `prepare_send_payment` can't pay itself.
+#[cfg_attr(feature = "uniffi", uniffi::export(with_foreign))]
+#[macros::async_trait]
+pub trait SdkPlugin: Send + Sync {
+    /// Returns the name and capabilities of the plugin. Called once when the
+    /// SDK is built.
+    fn info(&self) -> PluginInfo;
+    /// Called once the SDK is connected
+    async fn start(&self) -> Result<(), PluginError>;
+    /// Called when the SDK is disconnected
+    async fn stop(&self) -> Result<(), PluginError>;
+    /// Returns the balance held by the plugin in satoshis. Only called for
+    /// balance providers.
+    async fn get_balance(&self) -> Result<u64, PluginError>;
+    /// Quotes a payment to `destination`, or returns `None` if the plugin
+    /// can't pay it. Only called for payment rails.
+    async fn prepare_send(
+        &self,
+        destination: String,
+        amount_sats: Option<u64>,
+    ) -> Result<Option<PluginSendQuote>, PluginError>;
+    /// Sends a payment quoted by `prepare_send`
+    async fn send(&self, quote: PluginSendQuote) -> Result<PluginSendResult, PluginError>;
+}
@@ -20,25 +20,33 @@ compile_error!(
      cannot be enabled for the wasm32 target"
 );
 
-use std::{collections::HashMap, sync::Arc};
+use std::{
+    collections::{HashMap, HashSet},
+    sync::Arc,
+};
 
 use macros::async_trait;
-use serde::{Deserialize, Serialize};
+use serde::{Deserialize, Serialize, de::DeserializeOwned};
 use thiserror::Error;
+use tokio::sync::Mutex;
 
 use crate::{
-    AssetFilter, Contact, ConversionInfo, ConversionStatus, DepositClaimError, DepositInfo,
-    LightningAddressInfo, ListContactsRequest, ListPaymentsRequest, LnurlPayInfo,
-    LnurlWithdrawInfo, PaymentDetailsFilter, PaymentStatus, PaymentType, SparkHtlcStatus,
-    TokenBalance, TokenMetadata, TokenTransactionType,
+    ArbitratedEscrow, AssetFilter, Contact, ConversionInfo, ConversionStatus, DepositClaimError,
+    DepositInfo, DepositRefund, Escrow, LightningAddressInfo, ListContactsRequest,
+    ListPaymentsRequest, LnurlPayInfo, LnurlWithdrawInfo, OnchainTransaction, PaymentDetailsFilter,
+    PaymentFiatValue, PaymentStatus, PaymentStream, PaymentType, SparkHtlcStatus, SyncDomain,
+    TimeLockedPayment, TokenBalance, TokenMetadata, TokenRecipientResult, TokenTransactionType,
+    UnilateralExitProgress,
     models::Payment,
     sync_storage::{IncomingChange, OutgoingChange, Record, UnversionedRecordChange},
+    utils::secret::{FailedAttempts, SecretHash},
 };
 
 const ACCOUNT_INFO_KEY: &str = "account_info";
 const LAST_SYNC_TIME_KEY: &str = "last_sync_time";
 pub(crate) const LIGHTNING_ADDRESS_KEY: &str = "lightning_address";
 const LNURL_METADATA_UPDATED_AFTER_KEY: &str = "lnurl_metadata_updated_after";
+const LNURL_ZAP_RECEIPTS_KEY: &str = "lnurl_zap_receipts";
 const SYNC_OFFSET_KEY: &str = "sync_offset";
 const TX_CACHE_KEY: &str = "tx_cache";
 // Note: the key "static_deposit_address" may still exist in storage from older versions.
@@ -47,7 +55,31 @@ const PAYMENT_METADATA_KEY_PREFIX: &str = "payment_metadata";
 const PUBLISHED_PACKAGE_KEY_PREFIX: &str = "published_package_";
 const SPARK_PRIVATE_MODE_INITIALIZED_KEY: &str = "spark_private_mode_initialized";
 pub(crate) const STABLE_BALANCE_ACTIVE_LABEL_KEY: &str = "stable_balance_active_label";
+pub(crate) const DISPLAY_CURRENCY_KEY: &str = "display_currency";
 const PENDING_CONVERSIONS_KEY: &str = "pending_conversions";
+const ESCROWS_KEY: &str = "escrows";
+const TIME_LOCKED_PAYMENTS_KEY: &str = "time_locked_payments";
+const ARBITRATED_ESCROWS_KEY: &str = "arbitrated_escrows";
+const LEAF_FIRST_SEEN_KEY: &str = "leaf_first_seen";
+const LAST_TOKEN_SWEEP_KEY: &str = "last_token_sweep";
+const DOMAIN_SYNC_TIMES_KEY: &str = "domain_sync_times";
+const DEPOSIT_REFUNDS_KEY: &str = "deposit_refunds";
+const ONCHAIN_TRANSACTIONS_KEY: &str = "onchain_transactions";
+const PAYMENT_STREAMS_KEY: &str = "payment_streams";
+const UNILATERAL_EXIT_KEY: &str = "unilateral_exit";
+const WALLET_FREEZE_KEY: &str = "wallet_freeze";
+const SEED_BACKUP_ATTEMPTS_KEY: &str = "seed_backup_attempts";
+const DEPOSIT_ADDRESSES_KEY: &str = "deposit_addresses";
+const DEVICE_ID_KEY: &str = "device_id";
+pub(crate) const DEVICE_NAME_KEY: &str = "device_name";
+const SYNCED_DEVICES_KEY: &str = "synced_devices";
+const SENT_DESTINATIONS_KEY: &str = "sent_destinations";
+const CANCELLED_HELD_PAYMENT_KEY_PREFIX: &str = "cancelled_held_payment_";
+const PARTIAL_INVOICE_KEY_PREFIX: &str = "partial_invoice_";
+const IDEMPOTENCY_KEY_PREFIX: &str = "idempotency_";
+const TOKEN_FREEZES_KEY_PREFIX: &str = "token_freezes_";
+const TOKEN_DISTRIBUTION_KEY_PREFIX: &str = "token_distribution_";
+const TOKEN_VISIBILITY_KEY: &str = "token_visibility";
 
 /// Wrapper stored in the cache that carries context about whether the value
 /// was written as part of a recovery or a client-initiated change.
@@ -64,6 +96,19 @@ pub(crate) fn parse_cached_lightning_address(
     serde_json::from_str(value).map_err(|e| StorageError::Serialization(e.to_string()))
 }
 
+/// Parses a cached token metadata value. Values cached before the fetch time
+/// was recorded hold the bare metadata, and are read as fetched at 0 so they
+/// are refreshed first.
+fn parse_cached_token_metadata(value: &str) -> Result<CachedTokenMetadata, StorageError> {
+    if let Ok(cached) = serde_json::from_str(value) {
+        return Ok(cached);
+    }
+    Ok(CachedTokenMetadata {
+        metadata: serde_json::from_str(value)?,
+        fetched_at: Some(0),
+    })
+}
+
 #[cfg_attr(feature = "uniffi", derive(uniffi::Enum))]
 pub enum UpdateDepositPayload {
     ClaimError {
@@ -81,6 +126,7 @@ pub struct SetLnurlMetadataItem {
     pub sender_comment: Option<String>,
     pub nostr_zap_request: Option<String>,
     pub nostr_zap_receipt: Option<String>,
+    pub lightning_address: Option<String>,
 }
 
 impl From<lnurl_models::ListMetadataMetadata> for SetLnurlMetadataItem {
@@ -90,6 +136,7 @@ impl From<lnurl_models::ListMetadataMetadata> for SetLnurlMetadataItem {
             sender_comment: value.sender_comment,
             nostr_zap_request: value.nostr_zap_request,
             nostr_zap_receipt: value.nostr_zap_receipt,
+            lightning_address: value.lightning_address,
         }
     }
 }
@@ -304,6 +351,8 @@ pub struct PaymentMetadata {
     pub conversion_info: Option<ConversionInfo>,
     #[serde(skip_serializing_if = "Option::is_none")]
     pub conversion_status: Option<ConversionStatus>,
+    #[serde(skip_serializing_if = "Option::is_none", default)]
+    pub fiat_value: Option<PaymentFiatValue>,
 }
 
 #[cfg(any(feature = "sqlite", feature = "postgres", feature = "mysql"))]
@@ -582,17 +631,109 @@ pub trait Storage: Send + Sync {
 
     /// Update the sync state record from an incoming record
     async fn update_record_from_incoming(&self, record: Record) -> Result<(), StorageError>;
+
+    /// Deletes all data of the wallet, including the sync state, leaving the
+    /// storage as if newly created.
+    async fn delete_all_data(&self) -> Result<(), StorageError>;
 }
 
 pub(crate) struct ObjectCacheRepository {
     storage: Arc<dyn Storage>,
 }
 
+/// An entry of a list cached as a single item, read and updated through
+/// [`ObjectCacheRepository::update_cached_list`] and its helpers.
+pub(crate) trait CachedListEntry: Serialize + DeserializeOwned + Clone {
+    const CACHE_KEY: &'static str;
+
+    fn entry_id(&self) -> &str;
+}
+
+/// Serializes the read-modify-writes of the cached lists, so that concurrent
+/// updates of different entries are not lost.
+static CACHED_LIST_LOCK: Mutex<()> = Mutex::const_new(());
+
 impl ObjectCacheRepository {
     pub(crate) fn new(storage: Arc<dyn Storage>) -> Self {
         ObjectCacheRepository { storage }
     }
 
+    pub(crate) async fn fetch_cached_list<T: CachedListEntry>(
+        &self,
+    ) -> Result<Vec<T>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(T::CACHE_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(serde_json::from_str(&value)?),
+            None => Ok(Vec::new()),
+        }
+    }
+
+    pub(crate) async fn fetch_cached_list_entry<T: CachedListEntry>(
+        &self,
+        id: &str,
+    ) -> Result<Option<T>, StorageError> {
+        Ok(self
+            .fetch_cached_list::<T>()
+            .await?
+            .into_iter()
+            .find(|entry| entry.entry_id() == id))
+    }
+
+    /// Applies `f` to the cached list and saves it, holding the lock so that
+    /// no concurrent update is lost.
+    pub(crate) async fn update_cached_list<T: CachedListEntry, R>(
+        &self,
+        f: impl FnOnce(&mut Vec<T>) -> R,
+    ) -> Result<R, StorageError> {
+        let _guard = CACHED_LIST_LOCK.lock().await;
+        let mut entries = self.fetch_cached_list::<T>().await?;
+        let result = f(&mut entries);
+        self.storage
+            .set_cached_item(T::CACHE_KEY.to_string(), serde_json::to_string(&entries)?)
+            .await?;
+        Ok(result)
+    }
+
+    /// Inserts the entry, or replaces the one with the same id.
+    pub(crate) async fn upsert_cached_list_entry<T: CachedListEntry>(
+        &self,
+        entry: &T,
+    ) -> Result<(), StorageError> {
+        self.update_cached_list(|entries: &mut Vec<T>| {
+            match entries
+                .iter_mut()
+                .find(|e| e.entry_id() == entry.entry_id())
+            {
+                Some(existing) => *existing = entry.clone(),
+                None => entries.push(entry.clone()),
+            }
+        })
+        .await
+    }
+
+    /// Applies `f` to the entry with the given id, returning the updated
+    /// entry along with the result of `f`, or `None` if there is no such
+    /// entry.
+    pub(crate) async fn update_cached_list_entry<T: CachedListEntry, R>(
+        &self,
+        id: &str,
+        f: impl FnOnce(&mut T) -> R,
+    ) -> Result<Option<(T, R)>, StorageError> {
+        self.update_cached_list(|entries: &mut Vec<T>| {
+            entries
+                .iter_mut()
+                .find(|e| e.entry_id() == id)
+                .map(|entry| {
+                    let result = f(entry);
+                    (entry.clone(), result)
+                })
+        })
+        .await
+    }
+
     pub(crate) async fn save_account_info(
         &self,
         value: &CachedAccountInfo,
@@ -740,11 +881,11 @@ impl ObjectCacheRepository {
 
     pub(crate) async fn save_token_metadata(
         &self,
-        value: &TokenMetadata,
+        value: &CachedTokenMetadata,
     ) -> Result<(), StorageError> {
         self.storage
             .set_cached_item(
-                format!("{TOKEN_METADATA_KEY_PREFIX}{}", value.identifier),
+                format!("{TOKEN_METADATA_KEY_PREFIX}{}", value.metadata.identifier),
                 serde_json::to_string(value)?,
             )
             .await?;
@@ -754,13 +895,13 @@ impl ObjectCacheRepository {
     pub(crate) async fn fetch_token_metadata(
         &self,
         identifier: &str,
-    ) -> Result<Option<TokenMetadata>, StorageError> {
+    ) -> Result<Option<CachedTokenMetadata>, StorageError> {
         let value = self
             .storage
             .get_cached_item(format!("{TOKEN_METADATA_KEY_PREFIX}{identifier}"))
             .await?;
         match value {
-            Some(value) => Ok(Some(serde_json::from_str(&value)?)),
+            Some(value) => Ok(Some(parse_cached_token_metadata(&value)?)),
             None => Ok(None),
         }
     }
@@ -850,6 +991,24 @@ impl ObjectCacheRepository {
             .await
     }
 
+    pub(crate) async fn save_display_currency(&self, currency: &str) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(DISPLAY_CURRENCY_KEY.to_string(), currency.to_string())
+            .await
+    }
+
+    pub(crate) async fn fetch_display_currency(&self) -> Result<Option<String>, StorageError> {
+        self.storage
+            .get_cached_item(DISPLAY_CURRENCY_KEY.to_string())
+            .await
+    }
+
+    pub(crate) async fn delete_display_currency(&self) -> Result<(), StorageError> {
+        self.storage
+            .delete_cached_item(DISPLAY_CURRENCY_KEY.to_string())
+            .await
+    }
+
     pub(crate) async fn save_pending_conversions(
         &self,
         pending: &[super::stable_balance::PendingConversion],
@@ -882,6 +1041,300 @@ impl ObjectCacheRepository {
             .await
     }
 
+    pub(crate) async fn save_held_payment_cancelled(
+        &self,
+        payment_hash: &str,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                format!("{CANCELLED_HELD_PAYMENT_KEY_PREFIX}{payment_hash}"),
+                "true".to_string(),
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_held_payment_cancelled(
+        &self,
+        payment_hash: &str,
+    ) -> Result<bool, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(format!("{CANCELLED_HELD_PAYMENT_KEY_PREFIX}{payment_hash}"))
+            .await?;
+        Ok(value.is_some_and(|v| v == "true"))
+    }
+
+    /// Saves when each leaf was first seen, keyed by leaf id.
+    pub(crate) async fn save_leaf_first_seen(
+        &self,
+        first_seen: &HashMap<String, u64>,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                LEAF_FIRST_SEEN_KEY.to_string(),
+                serde_json::to_string(first_seen)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_leaf_first_seen(&self) -> Result<HashMap<String, u64>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(LEAF_FIRST_SEEN_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(serde_json::from_str(&value)?),
+            None => Ok(HashMap::new()),
+        }
+    }
+
+    /// Saves when token balances were last swept into sats, in seconds since
+    /// the epoch.
+    pub(crate) async fn save_last_token_sweep(&self, timestamp: u64) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(LAST_TOKEN_SWEEP_KEY.to_string(), timestamp.to_string())
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_last_token_sweep(&self) -> Result<Option<u64>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(LAST_TOKEN_SWEEP_KEY.to_string())
+            .await?;
+        Ok(value.and_then(|value| value.parse().ok()))
+    }
+
+    /// Saves the time of the last successful sync of each domain
+    pub(crate) async fn save_domain_sync_times(
+        &self,
+        times: &HashMap<SyncDomain, u64>,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                DOMAIN_SYNC_TIMES_KEY.to_string(),
+                serde_json::to_string(times)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_domain_sync_times(
+        &self,
+    ) -> Result<HashMap<SyncDomain, u64>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(DOMAIN_SYNC_TIMES_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(serde_json::from_str(&value)?),
+            None => Ok(HashMap::new()),
+        }
+    }
+
+    /// Saves the deposit refunds that are not confirmed yet.
+    pub(crate) async fn save_deposit_refunds(
+        &self,
+        refunds: &[DepositRefund],
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                DEPOSIT_REFUNDS_KEY.to_string(),
+                serde_json::to_string(refunds)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_deposit_refunds(&self) -> Result<Vec<DepositRefund>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(DEPOSIT_REFUNDS_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(serde_json::from_str(&value)?),
+            None => Ok(Vec::new()),
+        }
+    }
+
+    pub(crate) async fn save_unilateral_exit(
+        &self,
+        progress: &UnilateralExitProgress,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                UNILATERAL_EXIT_KEY.to_string(),
+                serde_json::to_string(progress)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_unilateral_exit(
+        &self,
+    ) -> Result<Option<UnilateralExitProgress>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(UNILATERAL_EXIT_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(Some(serde_json::from_str(&value)?)),
+            None => Ok(None),
+        }
+    }
+
+    pub(crate) async fn save_wallet_freeze(
+        &self,
+        freeze: &WalletFreeze,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                WALLET_FREEZE_KEY.to_string(),
+                serde_json::to_string(freeze)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_wallet_freeze(&self) -> Result<Option<WalletFreeze>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(WALLET_FREEZE_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(Some(serde_json::from_str(&value)?)),
+            None => Ok(None),
+        }
+    }
+
+    pub(crate) async fn delete_wallet_freeze(&self) -> Result<(), StorageError> {
+        self.storage
+            .delete_cached_item(WALLET_FREEZE_KEY.to_string())
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn save_seed_backup_attempts(
+        &self,
+        attempts: &FailedAttempts,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                SEED_BACKUP_ATTEMPTS_KEY.to_string(),
+                serde_json::to_string(attempts)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_seed_backup_attempts(&self) -> Result<FailedAttempts, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(SEED_BACKUP_ATTEMPTS_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(serde_json::from_str(&value)?),
+            None => Ok(FailedAttempts::default()),
+        }
+    }
+
+    pub(crate) async fn delete_seed_backup_attempts(&self) -> Result<(), StorageError> {
+        self.storage
+            .delete_cached_item(SEED_BACKUP_ATTEMPTS_KEY.to_string())
+            .await?;
+        Ok(())
+    }
+
+    /// Returns the id of this device, generating it on first use
+    pub(crate) async fn fetch_or_create_device_id(&self) -> Result<String, StorageError> {
+        if let Some(device_id) = self
+            .storage
+            .get_cached_item(DEVICE_ID_KEY.to_string())
+            .await?
+        {
+            return Ok(device_id);
+        }
+        let device_id = uuid::Uuid::new_v4().to_string();
+        self.storage
+            .set_cached_item(DEVICE_ID_KEY.to_string(), device_id.clone())
+            .await?;
+        Ok(device_id)
+    }
+
+    /// Saves the name of this device. When real-time sync is enabled, the
+    /// write also pushes the device to the other devices.
+    pub(crate) async fn save_device_name(&self, name: &str) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(DEVICE_NAME_KEY.to_string(), name.to_string())
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_device_name(&self) -> Result<Option<String>, StorageError> {
+        self.storage
+            .get_cached_item(DEVICE_NAME_KEY.to_string())
+            .await
+    }
+
+    /// Saves a device received through real-time sync
+    pub(crate) async fn save_synced_device(
+        &self,
+        device_id: &str,
+        device: &SyncedDevice,
+    ) -> Result<(), StorageError> {
+        let mut devices = self.fetch_synced_devices().await?;
+        devices.insert(device_id.to_string(), device.clone());
+        self.storage
+            .set_cached_item(
+                SYNCED_DEVICES_KEY.to_string(),
+                serde_json::to_string(&devices)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    /// The devices received through real-time sync, keyed by device id
+    pub(crate) async fn fetch_synced_devices(
+        &self,
+    ) -> Result<HashMap<String, SyncedDevice>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(SYNCED_DEVICES_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(serde_json::from_str(&value)?),
+            None => Ok(HashMap::new()),
+        }
+    }
+
+    pub(crate) async fn save_deposit_addresses(
+        &self,
+        addresses: &[IssuedDepositAddress],
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                DEPOSIT_ADDRESSES_KEY.to_string(),
+                serde_json::to_string(addresses)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_deposit_addresses(
+        &self,
+    ) -> Result<Vec<IssuedDepositAddress>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(DEPOSIT_ADDRESSES_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(serde_json::from_str(&value)?),
+            None => Ok(Vec::new()),
+        }
+    }
+
     pub(crate) async fn save_lnurl_metadata_updated_after(
         &self,
         offset: i64,
@@ -908,6 +1361,32 @@ impl ObjectCacheRepository {
         }
     }
 
+    /// Saves the payment hashes of the synced lnurl metadata that carry a
+    /// zap receipt.
+    pub(crate) async fn save_lnurl_zap_receipts(
+        &self,
+        payment_hashes: &HashSet<String>,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                LNURL_ZAP_RECEIPTS_KEY.to_string(),
+                serde_json::to_string(payment_hashes)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_lnurl_zap_receipts(&self) -> Result<HashSet<String>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(LNURL_ZAP_RECEIPTS_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(serde_json::from_str(&value)?),
+            None => Ok(HashSet::new()),
+        }
+    }
+
     pub(crate) async fn get_last_sync_time(&self) -> Result<Option<u64>, StorageError> {
         let value = self
             .storage
@@ -926,6 +1405,161 @@ impl ObjectCacheRepository {
             .set_cached_item(LAST_SYNC_TIME_KEY.to_string(), time.to_string())
             .await
     }
+
+    pub(crate) async fn save_partial_invoice(
+        &self,
+        invoice: &str,
+        value: &CachedPartialInvoice,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                format!("{PARTIAL_INVOICE_KEY_PREFIX}{invoice}"),
+                serde_json::to_string(value)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_partial_invoice(
+        &self,
+        invoice: &str,
+    ) -> Result<Option<CachedPartialInvoice>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(format!("{PARTIAL_INVOICE_KEY_PREFIX}{invoice}"))
+            .await?;
+        match value {
+            Some(value) => Ok(Some(serde_json::from_str(&value)?)),
+            None => Ok(None),
+        }
+    }
+
+    /// Appends a freeze of the token, holding the cached list lock so that
+    /// concurrent freezes are not lost.
+    pub(crate) async fn add_token_freeze(
+        &self,
+        token_identifier: &str,
+        freeze: CachedTokenFreeze,
+    ) -> Result<(), StorageError> {
+        let _guard = CACHED_LIST_LOCK.lock().await;
+        let mut freezes = self.fetch_token_freezes(token_identifier).await?;
+        freezes.push(freeze);
+        self.storage
+            .set_cached_item(
+                format!("{TOKEN_FREEZES_KEY_PREFIX}{token_identifier}"),
+                serde_json::to_string(&freezes)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_token_freezes(
+        &self,
+        token_identifier: &str,
+    ) -> Result<Vec<CachedTokenFreeze>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(format!("{TOKEN_FREEZES_KEY_PREFIX}{token_identifier}"))
+            .await?;
+        match value {
+            Some(value) => Ok(serde_json::from_str(&value)?),
+            None => Ok(Vec::new()),
+        }
+    }
+
+    pub(crate) async fn save_token_distribution(
+        &self,
+        distribution_id: &str,
+        distribution: &CachedTokenDistribution,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                format!("{TOKEN_DISTRIBUTION_KEY_PREFIX}{distribution_id}"),
+                serde_json::to_string(distribution)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_token_distribution(
+        &self,
+        distribution_id: &str,
+    ) -> Result<Option<CachedTokenDistribution>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(format!("{TOKEN_DISTRIBUTION_KEY_PREFIX}{distribution_id}"))
+            .await?;
+        match value {
+            Some(value) => Ok(Some(serde_json::from_str(&value)?)),
+            None => Ok(None),
+        }
+    }
+
+    /// Saves whether the user hid (`true`) or unhid (`false`) each token,
+    /// keyed by token identifier.
+    pub(crate) async fn save_token_visibility(
+        &self,
+        value: &HashMap<String, bool>,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                TOKEN_VISIBILITY_KEY.to_string(),
+                serde_json::to_string(value)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_token_visibility(
+        &self,
+    ) -> Result<HashMap<String, bool>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(TOKEN_VISIBILITY_KEY.to_string())
+            .await?;
+        match value {
+            Some(value) => Ok(serde_json::from_str(&value)?),
+            None => Ok(HashMap::new()),
+        }
+    }
+
+    pub(crate) async fn save_idempotency_record(
+        &self,
+        idempotency_key: &str,
+        value: &CachedIdempotencyRecord,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .set_cached_item(
+                format!("{IDEMPOTENCY_KEY_PREFIX}{idempotency_key}"),
+                serde_json::to_string(value)?,
+            )
+            .await?;
+        Ok(())
+    }
+
+    pub(crate) async fn fetch_idempotency_record(
+        &self,
+        idempotency_key: &str,
+    ) -> Result<Option<CachedIdempotencyRecord>, StorageError> {
+        let value = self
+            .storage
+            .get_cached_item(format!("{IDEMPOTENCY_KEY_PREFIX}{idempotency_key}"))
+            .await?;
+        match value {
+            Some(value) => Ok(Some(serde_json::from_str(&value)?)),
+            None => Ok(None),
+        }
+    }
+
+    pub(crate) async fn delete_idempotency_record(
+        &self,
+        idempotency_key: &str,
+    ) -> Result<(), StorageError> {
+        self.storage
+            .delete_cached_item(format!("{IDEMPOTENCY_KEY_PREFIX}{idempotency_key}"))
+            .await?;
+        Ok(())
+    }
 }
 
 #[derive(Serialize, Deserialize, Default)]
@@ -946,5 +1580,191 @@ pub(crate) struct CachedTx {
     pub(crate) raw_tx: String,
 }
 
+/// A freeze or unfreeze of a token, made by this wallet as the issuer or
+/// affecting the tokens it holds.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub(crate) struct CachedTokenFreeze {
+    pub(crate) address: String,
+    pub(crate) frozen: bool,
+    pub(crate) impacted_token_amount: u128,
+    pub(crate) timestamp: u64,
+}
+
+/// Token metadata with the time it was fetched from the Spark network.
+#[derive(Serialize, Deserialize)]
+pub(crate) struct CachedTokenMetadata {
+    pub(crate) metadata: TokenMetadata,
+    /// Unset when the metadata was cached on first sight, outside of an SDK
+    /// method, until the next wallet sync stamps it with the SDK clock
+    pub(crate) fetched_at: Option<u64>,
+}
+
+/// Progress of a token distribution, saved after each chunk so it can be resumed.
+#[derive(Serialize, Deserialize)]
+pub(crate) struct CachedTokenDistribution {
+    pub(crate) token_identifier: String,
+    pub(crate) mint_payment_id: Option<String>,
+    pub(crate) recipients: Vec<TokenRecipientResult>,
+}
+
+/// Aggregation state of a Spark invoice that accepts partial payments.
+#[derive(Serialize, Deserialize, Default)]
+pub(crate) struct CachedPartialInvoice {
+    pub(crate) target_amount: u128,
+    pub(crate) token_identifier: Option<String>,
+    pub(crate) received_amount: u128,
+    /// Ids of the payments already counted in `received_amount`
+    pub(crate) payment_ids: Vec<String>,
+}
+
+/// A time-locked payment together with its preimage, which is only exposed
+/// once the payment is deliverable.
+#[derive(Clone, Serialize, Deserialize)]
+pub(crate) struct CachedTimeLockedPayment {
+    pub(crate) payment: TimeLockedPayment,
+    pub(crate) preimage: String,
+}
+
+impl CachedListEntry for CachedTimeLockedPayment {
+    const CACHE_KEY: &'static str = TIME_LOCKED_PAYMENTS_KEY;
+
+    fn entry_id(&self) -> &str {
+        &self.payment.payment_id
+    }
+}
+
+/// An arbitrated escrow together with the buyer's share of its preimage,
+/// which is only exposed once the escrow is released.
+#[derive(Clone, Serialize, Deserialize)]
+pub(crate) struct CachedArbitratedEscrow {
+    pub(crate) escrow: ArbitratedEscrow,
+    pub(crate) buyer_share: String,
+}
+
+impl CachedListEntry for CachedArbitratedEscrow {
+    const CACHE_KEY: &'static str = ARBITRATED_ESCROWS_KEY;
+
+    fn entry_id(&self) -> &str {
+        &self.escrow.id
+    }
+}
+
+impl CachedListEntry for Escrow {
+    const CACHE_KEY: &'static str = ESCROWS_KEY;
+
+    fn entry_id(&self) -> &str {
+        &self.id
+    }
+}
+
+impl CachedListEntry for PaymentStream {
+    const CACHE_KEY: &'static str = PAYMENT_STREAMS_KEY;
+
+    fn entry_id(&self) -> &str {
+        &self.id
+    }
+}
+
+/// A freeze of the wallet, with the hash of the password that lifts it and
+/// the failed attempts at that password.
+#[derive(Clone, Serialize, Deserialize)]
+pub(crate) struct WalletFreeze {
+    pub(crate) password_hash: SecretHash,
+    pub(crate) frozen_at: u64,
+    #[serde(default)]
+    pub(crate) failed_attempts: FailedAttempts,
+}
+
+/// A device running the wallet, as pushed to the other devices through
+/// real-time sync.
+#[derive(Clone, Serialize, Deserialize)]
+pub(crate) struct SyncedDevice {
+    pub(crate) name: Option<String>,
+    pub(crate) last_seen_at: u64,
+}
+
+/// A deposit address handed out by `receive_payment`, with the time it was
+/// first issued.
+#[derive(Clone, Serialize, Deserialize)]
+pub(crate) struct IssuedDepositAddress {
+    pub(crate) address: String,
+    pub(crate) issued_at: u64,
+}
+
+/// A monitored on-chain transaction, with the outpoint it spends, used to
+/// tell an evicted transaction from a replaced one.
+#[derive(Clone, Serialize, Deserialize)]
+pub(crate) struct CachedOnchainTransaction {
+    pub(crate) transaction: OnchainTransaction,
+    /// The first outpoint spent by the transaction, unset until known
+    pub(crate) spent_outpoint: Option<(String, u32)>,
+    pub(crate) broadcast_at: u64,
+    pub(crate) updated_at: u64,
+}
+
+impl CachedListEntry for CachedOnchainTransaction {
+    const CACHE_KEY: &'static str = ONCHAIN_TRANSACTIONS_KEY;
+
+    fn entry_id(&self) -> &str {
+        &self.transaction.tx_id
+    }
+}
+
+/// The address a recent payment was sent to, which the payment itself doesn't
+/// record, used to flag repeat sends of the same amount to it.
+#[derive(Clone, Serialize, Deserialize)]
+pub(crate) struct CachedSentDestination {
+    pub(crate) payment_id: String,
+    pub(crate) destination: String,
+    pub(crate) amount: u128,
+    pub(crate) token_identifier: Option<String>,
+    pub(crate) sent_at: u64,
+}
+
+impl CachedListEntry for CachedSentDestination {
+    const CACHE_KEY: &'static str = SENT_DESTINATIONS_KEY;
+
+    fn entry_id(&self) -> &str {
+        &self.payment_id
+    }
+}
+
+/// The mutating operations deduplicated by a caller-supplied idempotency key.
+#[derive(Clone, Copy, Debug, PartialEq, Serialize, Deserialize)]
+pub(crate) enum IdempotentOperation {
+    ClaimDeposit,
+    LnurlWithdraw,
+    MintIssuerToken,
+    BurnIssuerToken,
+    TokenSend,
+    DistributeIssuerToken,
+}
+
+/// Progress of an operation started with an idempotency key.
+#[derive(Serialize, Deserialize)]
+pub(crate) struct CachedIdempotencyRecord {
+    pub(crate) operation: IdempotentOperation,
+    pub(crate) completed: bool,
+    /// Id of the payment produced by the operation
+    pub(crate) payment_id: Option<String>,
+    /// LNURL withdraw only: the invoice handed to the service, reused on
+    /// retry so the service can pay at most once
+    pub(crate) withdraw_invoice: Option<String>,
+    /// LNURL withdraw only: the SSP receive id of `withdraw_invoice`
+    pub(crate) withdraw_ssp_receive_id: Option<String>,
+}
+
+impl CachedIdempotencyRecord {
+    pub(crate) fn new(operation: IdempotentOperation) -> Self {
+        Self {
+            operation,
+            completed: false,
+            payment_id: None,
+            withdraw_invoice: None,
+            withdraw_ssp_receive_id: None,
+        }
+    }
+}
+
 #[cfg(feature = "test-utils")]
 pub mod tests;
@@ -141,6 +141,25 @@ const SCHEMA_RENAMES: SchemaRenames<'static> = SchemaRenames {
     foreign_keys: &[],
 };
 
+/// The tables holding per-tenant rows, children before parents.
+const TENANT_TABLES: &[&str] = &[
+    "brz_payment_details_lightning",
+    "brz_payment_details_token",
+    "brz_payment_details_spark",
+    "brz_payment_details_deposit",
+    "brz_payment_metadata",
+    "brz_payments",
+    "brz_unclaimed_deposits",
+    "brz_lnurl_receive_metadata",
+    "brz_contacts",
+    "brz_cross_chain_swaps",
+    "brz_settings",
+    "brz_sync_outgoing",
+    "brz_sync_incoming",
+    "brz_sync_state",
+    "brz_sync_revision",
+];
+
 /// `MySQL`-based storage implementation using `mysql_async`'s connection pool.
 ///
 /// Each instance is scoped to a single tenant identity (a 33-byte secp256k1
@@ -530,10 +549,68 @@ impl MysqlStorage {
                         (user_id, provider, is_terminal)
                 )",
             )],
+            // Migration 21: Fiat value of the payment at completion
+            vec![Migration::AddColumn {
+                table: "brz_payment_metadata",
+                column: "fiat_value",
+                definition: "JSON NULL",
+            }],
+            // Migration 22: Contact details
+            vec![
+                Migration::AddColumn {
+                    table: "brz_contacts",
+                    column: "additional_payment_identifiers",
+                    definition: "JSON NULL",
+                },
+                Migration::AddColumn {
+                    table: "brz_contacts",
+                    column: "avatar_url",
+                    definition: "MEDIUMTEXT NULL",
+                },
+                Migration::AddColumn {
+                    table: "brz_contacts",
+                    column: "notes",
+                    definition: "TEXT NULL",
+                },
+            ],
+            // Migration 23: The lightning address an lnurl payment was received on
+            vec![Migration::AddColumn {
+                table: "brz_lnurl_receive_metadata",
+                column: "lightning_address",
+                definition: "VARCHAR(255) NULL",
+            }],
         ]
     }
 }
 
+const CONTACT_COLUMNS: &str = "id, name, payment_identifier, created_at, updated_at, additional_payment_identifiers, avatar_url, notes";
+
+/// A `brz_contacts` row selected with [`CONTACT_COLUMNS`]
+type ContactRow = (
+    String,
+    String,
+    String,
+    i64,
+    i64,
+    Option<String>,
+    Option<String>,
+    Option<String>,
+);
+
+fn map_contact(row: ContactRow) -> Result<Contact, StorageError> {
+    let (id, name, payment_identifier, created_at, updated_at, additional, avatar_url, notes) = row;
+    Ok(Contact {
+        id,
+        name,
+        payment_identifier,
+        additional_payment_identifiers: from_json_string_opt(additional)?.unwrap_or_default(),
+        avatar_url,
+        notes,
+        created_at: u64::try_from(created_at)?,
+        updated_at: u64::try_from(updated_at)?,
+    })
+}
+
 /// Maps a `brz_cross_chain_swaps` row tuple `(provider, id, is_terminal,
 /// updated_at, data, secrets)` to a [`StoredCrossChainSwap`].
 fn cross_chain_swap_from_parts(
@@ -1188,17 +1265,19 @@ impl Storage for MysqlStorage {
             .conversion_status
             .as_ref()
             .map(std::string::ToString::to_string);
+        let fiat_value_json = to_json_string_opt(metadata.fiat_value.as_ref())?;
 
         conn.exec_drop(
-            "INSERT INTO brz_payment_metadata (user_id, payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status)
-             VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+            "INSERT INTO brz_payment_metadata (user_id, payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status, fiat_value)
+             VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
              ON DUPLICATE KEY UPDATE
                 parent_payment_id = COALESCE(VALUES(parent_payment_id), parent_payment_id),
                 lnurl_pay_info = COALESCE(VALUES(lnurl_pay_info), lnurl_pay_info),
                 lnurl_withdraw_info = COALESCE(VALUES(lnurl_withdraw_info), lnurl_withdraw_info),
                 lnurl_description = COALESCE(VALUES(lnurl_description), lnurl_description),
                 conversion_info = COALESCE(VALUES(conversion_info), conversion_info),
-                conversion_status = COALESCE(VALUES(conversion_status), conversion_status)",
+                conversion_status = COALESCE(VALUES(conversion_status), conversion_status),
+                fiat_value = COALESCE(VALUES(fiat_value), fiat_value)",
             (
                 self.identity.clone(),
                 payment_id,
@@ -1208,6 +1287,7 @@ impl Storage for MysqlStorage {
                 metadata.lnurl_description,
                 conversion_info_json,
                 conversion_status_str,
+                fiat_value_json,
             ),
         )
         .await
@@ -1446,13 +1526,14 @@ impl Storage for MysqlStorage {
         let mut conn = self.pool.get_conn().await.map_err(map_db_error)?;
         for m in metadata {
             conn.exec_drop(
-                "INSERT INTO brz_lnurl_receive_metadata (user_id, payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment)
-                 VALUES (?, ?, ?, ?, ?)
+                "INSERT INTO brz_lnurl_receive_metadata (user_id, payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment, lightning_address)
+                 VALUES (?, ?, ?, ?, ?, ?)
                  ON DUPLICATE KEY UPDATE
                     nostr_zap_request = VALUES(nostr_zap_request),
                     nostr_zap_receipt = VALUES(nostr_zap_receipt),
-                    sender_comment = VALUES(sender_comment)",
-                (self.identity.clone(), m.payment_hash, m.nostr_zap_request, m.nostr_zap_receipt, m.sender_comment),
+                    sender_comment = VALUES(sender_comment),
+                    lightning_address = VALUES(lightning_address)",
+                (self.identity.clone(), m.payment_hash, m.nostr_zap_request, m.nostr_zap_receipt, m.sender_comment, m.lightning_address),
             )
             .await
             .map_err(map_db_error)?;
@@ -1468,58 +1549,44 @@ impl Storage for MysqlStorage {
         let limit = i64::from(request.limit.unwrap_or(u32::MAX));
         let offset = i64::from(request.offset.unwrap_or(0));
 
-        let rows: Vec<(String, String, String, i64, i64)> = conn
+        let rows: Vec<ContactRow> = conn
             .exec(
-                "SELECT id, name, payment_identifier, created_at, updated_at
-                 FROM brz_contacts WHERE user_id = ? ORDER BY name ASC LIMIT ? OFFSET ?",
+                format!(
+                    "SELECT {CONTACT_COLUMNS}
+                     FROM brz_contacts WHERE user_id = ? ORDER BY name ASC LIMIT ? OFFSET ?"
+                ),
                 (self.identity.clone(), limit, offset),
             )
             .await
             .map_err(map_db_error)?;
 
-        let mut contacts = Vec::new();
-        for (id, name, payment_identifier, created_at, updated_at) in rows {
-            contacts.push(Contact {
-                id,
-                name,
-                payment_identifier,
-                created_at: u64::try_from(created_at)?,
-                updated_at: u64::try_from(updated_at)?,
-            });
-        }
-        Ok(contacts)
+        rows.into_iter().map(map_contact).collect()
     }
 
     async fn get_contact(&self, id: String) -> Result<Contact, StorageError> {
         let mut conn = self.pool.get_conn().await.map_err(map_db_error)?;
-        let row: Option<(String, String, String, i64, i64)> = conn
+        let row: Option<ContactRow> = conn
             .exec_first(
-                "SELECT id, name, payment_identifier, created_at, updated_at
-                 FROM brz_contacts WHERE user_id = ? AND id = ?",
+                format!("SELECT {CONTACT_COLUMNS} FROM brz_contacts WHERE user_id = ? AND id = ?"),
                 (self.identity.clone(), id),
             )
             .await
             .map_err(map_db_error)?;
-        let (id, name, payment_identifier, created_at, updated_at) =
-            row.ok_or(StorageError::NotFound)?;
-        Ok(Contact {
-            id,
-            name,
-            payment_identifier,
-            created_at: u64::try_from(created_at)?,
-            updated_at: u64::try_from(updated_at)?,
-        })
+        map_contact(row.ok_or(StorageError::NotFound)?)
     }
 
     async fn insert_contact(&self, contact: Contact) -> Result<(), StorageError> {
         let mut conn = self.pool.get_conn().await.map_err(map_db_error)?;
         conn.exec_drop(
-            "INSERT INTO brz_contacts (user_id, id, name, payment_identifier, created_at, updated_at)
-             VALUES (?, ?, ?, ?, ?, ?)
+            "INSERT INTO brz_contacts (user_id, id, name, payment_identifier, created_at, updated_at, additional_payment_identifiers, avatar_url, notes)
+             VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
              ON DUPLICATE KEY UPDATE
                name = VALUES(name),
                payment_identifier = VALUES(payment_identifier),
-               updated_at = VALUES(updated_at)",
+               updated_at = VALUES(updated_at),
+               additional_payment_identifiers = VALUES(additional_payment_identifiers),
+               avatar_url = VALUES(avatar_url),
+               notes = VALUES(notes)",
             (
                 self.identity.clone(),
                 contact.id,
@@ -1527,6 +1594,9 @@ impl Storage for MysqlStorage {
                 contact.payment_identifier,
                 i64::try_from(contact.created_at)?,
                 i64::try_from(contact.updated_at)?,
+                to_json_string_opt(Some(&contact.additional_payment_identifiers))?,
+                contact.avatar_url,
+                contact.notes,
             ),
         )
         .await
@@ -1984,6 +2054,30 @@ impl Storage for MysqlStorage {
 
         Ok(())
     }
+
+    async fn delete_all_data(&self) -> Result<(), StorageError> {
+        let mut conn = self.pool.get_conn().await.map_err(map_db_error)?;
+
+        let mut tx = conn
+            .start_transaction(tx_opts())
+            .await
+            .map_err(map_db_error)?;
+
+        // Only this tenant's rows are deleted; other tenants sharing the
+        // database are untouched.
+        for table in TENANT_TABLES {
+            tx.exec_drop(
+                format!("DELETE FROM {table} WHERE user_id = ?"),
+                (self.identity.clone(),),
+            )
+            .await
+            .map_err(map_db_error)?;
+        }
+
+        tx.commit().await.map_err(map_db_error)?;
+
+        Ok(())
+    }
 }
 
 /// Base query for payment lookups. Indices 0-31 are used by `map_payment`,
@@ -2021,7 +2115,9 @@ const SELECT_PAYMENT_SQL: &str = "
            lrm.sender_comment AS lnurl_sender_comment,
            lrm.payment_hash AS lnurl_payment_hash,
            pm.conversion_status,
-           pm.parent_payment_id
+           pm.parent_payment_id,
+           pm.fiat_value,
+           lrm.lightning_address AS lnurl_lightning_address
       FROM brz_payments p
       LEFT JOIN brz_payment_details_lightning l ON p.id = l.payment_id AND p.user_id = l.user_id
       LEFT JOIN brz_payment_details_deposit pd ON p.id = pd.payment_id AND p.user_id = pd.user_id
@@ -2087,6 +2183,7 @@ fn map_payment(row: &Row) -> Result<Payment, StorageError> {
                     nostr_zap_request: lnurl_nostr_zap_request,
                     nostr_zap_receipt: lnurl_nostr_zap_receipt,
                     sender_comment: lnurl_sender_comment,
+                    lightning_address: get_opt_str(row, 34),
                 })
             } else {
                 None
@@ -2186,6 +2283,8 @@ fn map_payment(row: &Row) -> Result<Payment, StorageError> {
                 })
                 .transpose()?
         },
+        fiat_value: from_json_string_opt(get_opt_str(row, 33))?,
+        receive_metadata: None,
     })
 }
 
@@ -2332,6 +2431,12 @@ mod tests {
         crate::persist::tests::test_contacts_crud(Box::new(fixture.storage)).await;
     }
 
+    #[tokio::test]
+    async fn test_delete_all_data() {
+        let fixture = MysqlTestFixture::new().await;
+        crate::persist::tests::test_delete_all_data(Box::new(fixture.storage)).await;
+    }
+
     #[tokio::test]
     async fn test_cross_chain_swaps_crud() {
         let fixture = MysqlTestFixture::new().await;
@@ -2687,6 +2792,8 @@ mod tests {
                 conversion_info: None,
             }),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         };
         let mut pmt_b = pmt_a.clone();
         if let Some(PaymentDetails::Lightning {
@@ -2760,6 +2867,9 @@ mod tests {
             id: "shared_contact_id".to_string(),
             name: "Alice".to_string(),
             payment_identifier: "alice@a".to_string(),
+            additional_payment_identifiers: Vec::new(),
+            avatar_url: None,
+            notes: None,
             created_at: now,
             updated_at: now,
         })
@@ -2818,6 +2928,7 @@ mod tests {
             nostr_zap_request: Some("zap_a".to_string()),
             nostr_zap_receipt: None,
             sender_comment: None,
+            lightning_address: None,
         }])
         .await
         .unwrap();
@@ -2826,6 +2937,7 @@ mod tests {
             nostr_zap_request: Some("zap_b".to_string()),
             nostr_zap_receipt: None,
             sender_comment: None,
+            lightning_address: None,
         }])
         .await
         .unwrap();
@@ -161,6 +161,25 @@ const SCHEMA_RENAMES: SchemaRenames<'static> = SchemaRenames {
     ],
 };
 
+/// The tables holding per-tenant rows, children before parents.
+const TENANT_TABLES: &[&str] = &[
+    "brz_payment_details_lightning",
+    "brz_payment_details_token",
+    "brz_payment_details_spark",
+    "brz_payment_details_deposit",
+    "brz_payment_metadata",
+    "brz_payments",
+    "brz_unclaimed_deposits",
+    "brz_lnurl_receive_metadata",
+    "brz_contacts",
+    "brz_cross_chain_swaps",
+    "brz_settings",
+    "brz_sync_outgoing",
+    "brz_sync_incoming",
+    "brz_sync_state",
+    "brz_sync_revision",
+];
+
 /// PostgreSQL-based storage implementation using connection pooling.
 ///
 /// Each instance is scoped to a single tenant identity (a 33-byte secp256k1
@@ -467,10 +486,39 @@ impl PostgresStorage {
                 "CREATE INDEX IF NOT EXISTS brz_idx_cross_chain_swaps_user_provider_is_terminal
                     ON brz_cross_chain_swaps (user_id, provider, is_terminal)".to_string(),
             ],
+            // Migration 20: Fiat value of the payment at completion
+            vec!["ALTER TABLE brz_payment_metadata ADD COLUMN IF NOT EXISTS fiat_value JSONB".to_string()],
+            // Migration 21: Contact details
+            vec![
+                "ALTER TABLE brz_contacts ADD COLUMN IF NOT EXISTS additional_payment_identifiers JSONB".to_string(),
+                "ALTER TABLE brz_contacts ADD COLUMN IF NOT EXISTS avatar_url TEXT".to_string(),
+                "ALTER TABLE brz_contacts ADD COLUMN IF NOT EXISTS notes TEXT".to_string(),
+            ],
+            // Migration 22: The lightning address an lnurl payment was received on
+            vec![
+                "ALTER TABLE brz_lnurl_receive_metadata ADD COLUMN IF NOT EXISTS lightning_address TEXT"
+                    .to_string(),
+            ],
         ]
     }
 }
 
+const CONTACT_COLUMNS: &str = "id, name, payment_identifier, created_at, updated_at, additional_payment_identifiers, avatar_url, notes";
+
+/// Maps a `brz_contacts` row selected with [`CONTACT_COLUMNS`] to a [`Contact`].
+fn map_contact(row: &Row) -> Result<Contact, StorageError> {
+    Ok(Contact {
+        id: row.get(0),
+        name: row.get(1),
+        payment_identifier: row.get(2),
+        additional_payment_identifiers: from_json_opt(row.get(5))?.unwrap_or_default(),
+        avatar_url: row.get(6),
+        notes: row.get(7),
+        created_at: u64::try_from(row.get::<_, i64>(3))?,
+        updated_at: u64::try_from(row.get::<_, i64>(4))?,
+    })
+}
+
 /// Maps a `brz_cross_chain_swaps` row (columns `provider, id, is_terminal,
 /// updated_at, data, secrets`) to a [`StoredCrossChainSwap`].
 fn cross_chain_swap_from_row(row: &Row) -> Result<StoredCrossChainSwap, StorageError> {
@@ -1068,18 +1116,20 @@ impl Storage for PostgresStorage {
             .conversion_status
             .as_ref()
             .map(std::string::ToString::to_string);
+        let fiat_value_json = to_json_opt(metadata.fiat_value.as_ref())?;
 
         client
             .execute(
-                "INSERT INTO brz_payment_metadata (user_id, payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status)
-                 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+                "INSERT INTO brz_payment_metadata (user_id, payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status, fiat_value)
+                 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
                  ON CONFLICT(user_id, payment_id) DO UPDATE SET
                     parent_payment_id = COALESCE(EXCLUDED.parent_payment_id, brz_payment_metadata.parent_payment_id),
                     lnurl_pay_info = COALESCE(EXCLUDED.lnurl_pay_info, brz_payment_metadata.lnurl_pay_info),
                     lnurl_withdraw_info = COALESCE(EXCLUDED.lnurl_withdraw_info, brz_payment_metadata.lnurl_withdraw_info),
                     lnurl_description = COALESCE(EXCLUDED.lnurl_description, brz_payment_metadata.lnurl_description),
                     conversion_info = COALESCE(EXCLUDED.conversion_info, brz_payment_metadata.conversion_info),
-                    conversion_status = COALESCE(EXCLUDED.conversion_status, brz_payment_metadata.conversion_status)",
+                    conversion_status = COALESCE(EXCLUDED.conversion_status, brz_payment_metadata.conversion_status),
+                    fiat_value = COALESCE(EXCLUDED.fiat_value, brz_payment_metadata.fiat_value)",
                 &[
                     &self.identity,
                     &payment_id,
@@ -1089,6 +1139,7 @@ impl Storage for PostgresStorage {
                     &metadata.lnurl_description,
                     &conversion_info_json,
                     &conversion_status_str,
+                    &fiat_value_json,
                 ],
             )
             .await?;
@@ -1326,13 +1377,14 @@ impl Storage for PostgresStorage {
         for m in metadata {
             client
                 .execute(
-                    "INSERT INTO brz_lnurl_receive_metadata (user_id, payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment)
-                     VALUES ($1, $2, $3, $4, $5)
+                    "INSERT INTO brz_lnurl_receive_metadata (user_id, payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment, lightning_address)
+                     VALUES ($1, $2, $3, $4, $5, $6)
                      ON CONFLICT(user_id, payment_hash) DO UPDATE SET
                         nostr_zap_request = EXCLUDED.nostr_zap_request,
                         nostr_zap_receipt = EXCLUDED.nostr_zap_receipt,
-                        sender_comment = EXCLUDED.sender_comment",
-                    &[&self.identity, &m.payment_hash, &m.nostr_zap_request, &m.nostr_zap_receipt, &m.sender_comment],
+                        sender_comment = EXCLUDED.sender_comment,
+                        lightning_address = EXCLUDED.lightning_address",
+                    &[&self.identity, &m.payment_hash, &m.nostr_zap_request, &m.nostr_zap_receipt, &m.sender_comment, &m.lightning_address],
                 )
                 .await?;
         }
@@ -1349,54 +1401,44 @@ impl Storage for PostgresStorage {
 
         let rows = client
             .query(
-                "SELECT id, name, payment_identifier, created_at, updated_at
-                 FROM brz_contacts WHERE user_id = $1 ORDER BY name ASC LIMIT $2 OFFSET $3",
+                &format!(
+                    "SELECT {CONTACT_COLUMNS}
+                     FROM brz_contacts WHERE user_id = $1 ORDER BY name ASC LIMIT $2 OFFSET $3"
+                ),
                 &[&self.identity, &limit, &offset],
             )
             .await?;
 
-        let mut contacts = Vec::new();
-        for row in rows {
-            contacts.push(Contact {
-                id: row.get(0),
-                name: row.get(1),
-                payment_identifier: row.get(2),
-                created_at: u64::try_from(row.get::<_, i64>(3))?,
-                updated_at: u64::try_from(row.get::<_, i64>(4))?,
-            });
-        }
-        Ok(contacts)
+        rows.iter().map(map_contact).collect()
     }
 
     async fn get_contact(&self, id: String) -> Result<Contact, StorageError> {
         let client = self.pool.get().await.map_err(map_pool_error)?;
         let row = client
             .query_opt(
-                "SELECT id, name, payment_identifier, created_at, updated_at
-                 FROM brz_contacts WHERE user_id = $1 AND id = $2",
+                &format!(
+                    "SELECT {CONTACT_COLUMNS} FROM brz_contacts WHERE user_id = $1 AND id = $2"
+                ),
                 &[&self.identity, &id],
             )
             .await?
             .ok_or(StorageError::NotFound)?;
-        Ok(Contact {
-            id: row.get(0),
-            name: row.get(1),
-            payment_identifier: row.get(2),
-            created_at: u64::try_from(row.get::<_, i64>(3))?,
-            updated_at: u64::try_from(row.get::<_, i64>(4))?,
-        })
+        map_contact(&row)
     }
 
     async fn insert_contact(&self, contact: Contact) -> Result<(), StorageError> {
         let client = self.pool.get().await.map_err(map_pool_error)?;
         let result = client
             .execute(
-                "INSERT INTO brz_contacts (user_id, id, name, payment_identifier, created_at, updated_at)
-                 VALUES ($1, $2, $3, $4, $5, $6)
+                "INSERT INTO brz_contacts (user_id, id, name, payment_identifier, created_at, updated_at, additional_payment_identifiers, avatar_url, notes)
+                 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
                  ON CONFLICT (user_id, id) DO UPDATE SET
                    name = EXCLUDED.name,
                    payment_identifier = EXCLUDED.payment_identifier,
-                   updated_at = EXCLUDED.updated_at",
+                   updated_at = EXCLUDED.updated_at,
+                   additional_payment_identifiers = EXCLUDED.additional_payment_identifiers,
+                   avatar_url = EXCLUDED.avatar_url,
+                   notes = EXCLUDED.notes",
                 &[
                     &self.identity,
                     &contact.id,
@@ -1404,6 +1446,9 @@ impl Storage for PostgresStorage {
                     &contact.payment_identifier,
                     &i64::try_from(contact.created_at)?,
                     &i64::try_from(contact.updated_at)?,
+                    &to_json_opt(Some(&contact.additional_payment_identifiers))?,
+                    &contact.avatar_url,
+                    &contact.notes,
                 ],
             )
             .await;
@@ -1874,10 +1919,36 @@ impl Storage for PostgresStorage {
 
         Ok(())
     }
+
+    async fn delete_all_data(&self) -> Result<(), StorageError> {
+        let mut client = self.pool.get().await.map_err(map_pool_error)?;
+
+        let tx = client
+            .transaction()
+            .await
+            .map_err(|e| StorageError::Connection(e.to_string()))?;
+
+        // Only this tenant's rows are deleted; other tenants sharing the
+        // database are untouched.
+        for table in TENANT_TABLES {
+            tx.execute(
+                &format!("DELETE FROM {table} WHERE user_id = $1"),
+                &[&self.identity],
+            )
+            .await
+            .map_err(|e| StorageError::Connection(e.to_string()))?;
+        }
+
+        tx.commit()
+            .await
+            .map_err(|e| StorageError::Connection(e.to_string()))?;
+
+        Ok(())
+    }
 }
 
 /// Base query for payment lookups.
-/// Column indices 0-31 are used by `map_payment`, index 32 (`parent_payment_id`) is only used by `get_payments_by_parent_ids`.
+/// Column indices 0-31 and 33 are used by `map_payment`, index 32 (`parent_payment_id`) is only used by `get_payments_by_parent_ids`.
 const SELECT_PAYMENT_SQL: &str = "
     SELECT p.id,
            p.payment_type,
@@ -1911,7 +1982,9 @@ const SELECT_PAYMENT_SQL: &str = "
            lrm.sender_comment AS lnurl_sender_comment,
            lrm.payment_hash AS lnurl_payment_hash,
            pm.conversion_status,
-           pm.parent_payment_id
+           pm.parent_payment_id,
+           pm.fiat_value,
+           lrm.lightning_address AS lnurl_lightning_address
       FROM brz_payments p
       LEFT JOIN brz_payment_details_lightning l ON p.id = l.payment_id AND p.user_id = l.user_id
       LEFT JOIN brz_payment_details_token t ON p.id = t.payment_id AND p.user_id = t.user_id
@@ -1974,6 +2047,7 @@ fn map_payment(row: &Row) -> Result<Payment, StorageError> {
                     nostr_zap_request: lnurl_nostr_zap_request,
                     nostr_zap_receipt: lnurl_nostr_zap_receipt,
                     sender_comment: lnurl_sender_comment,
+                    lightning_address: row.get(34),
                 })
             } else {
                 None
@@ -2078,6 +2152,8 @@ fn map_payment(row: &Row) -> Result<Payment, StorageError> {
                 })
                 .transpose()?
         },
+        fiat_value: from_json_opt(row.get(33))?,
+        receive_metadata: None,
     })
 }
 
@@ -2259,6 +2335,12 @@ mod tests {
         crate::persist::tests::test_contacts_crud(Box::new(fixture.storage)).await;
     }
 
+    #[tokio::test]
+    async fn test_delete_all_data() {
+        let fixture = PostgresTestFixture::new().await;
+        crate::persist::tests::test_delete_all_data(Box::new(fixture.storage)).await;
+    }
+
     #[tokio::test]
     async fn test_cross_chain_swaps_crud() {
         let fixture = PostgresTestFixture::new().await;
@@ -2415,6 +2497,8 @@ mod tests {
                 conversion_info: None,
             }),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         };
         let mut pmt_b = pmt_a.clone();
         if let Some(PaymentDetails::Lightning {
@@ -2491,6 +2575,9 @@ mod tests {
             id: "shared_contact_id".to_string(),
             name: "Alice".to_string(),
             payment_identifier: "alice@a".to_string(),
+            additional_payment_identifiers: Vec::new(),
+            avatar_url: None,
+            notes: None,
             created_at: now,
             updated_at: now,
         })
@@ -2551,6 +2638,7 @@ mod tests {
             nostr_zap_request: Some("zap_a".to_string()),
             nostr_zap_receipt: None,
             sender_comment: None,
+            lightning_address: None,
         }])
         .await
         .unwrap();
@@ -2559,6 +2647,7 @@ mod tests {
             nostr_zap_request: Some("zap_b".to_string()),
             nostr_zap_receipt: None,
             sender_comment: None,
+            lightning_address: None,
         }])
         .await
         .unwrap();
@@ -370,10 +370,38 @@ impl SqliteStorage {
             );
             CREATE INDEX idx_cross_chain_swaps_provider_is_terminal
                 ON cross_chain_swaps(provider, is_terminal);",
+            // Fiat value of the payment at completion, as JSON
+            "ALTER TABLE payment_metadata ADD COLUMN fiat_value TEXT;",
+            // Contact details. The additional payment identifiers are a JSON array.
+            "ALTER TABLE contacts ADD COLUMN additional_payment_identifiers TEXT;
+            ALTER TABLE contacts ADD COLUMN avatar_url TEXT;
+            ALTER TABLE contacts ADD COLUMN notes TEXT;",
+            // The lightning address an lnurl payment was received on
+            "ALTER TABLE lnurl_receive_metadata ADD COLUMN lightning_address TEXT;",
         ]
     }
 }
 
+const CONTACT_COLUMNS: &str = "id, name, payment_identifier, created_at, updated_at, additional_payment_identifiers, avatar_url, notes";
+
+/// Maps a `contacts` row selected with [`CONTACT_COLUMNS`] to a [`Contact`].
+fn map_contact(row: &Row<'_>) -> rusqlite::Result<Contact> {
+    let additional_payment_identifiers: Option<String> = row.get(5)?;
+    Ok(Contact {
+        id: row.get(0)?,
+        name: row.get(1)?,
+        payment_identifier: row.get(2)?,
+        additional_payment_identifiers: additional_payment_identifiers
+            .map(|s| serde_json_from_str(&s, 5))
+            .transpose()?
+            .unwrap_or_default(),
+        avatar_url: row.get(6)?,
+        notes: row.get(7)?,
+        created_at: row.get(3)?,
+        updated_at: row.get(4)?,
+    })
+}
+
 /// Maps a `cross_chain_swaps` row to a [`StoredCrossChainSwap`].
 fn parse_cross_chain_swap_row(row: &Row) -> rusqlite::Result<StoredCrossChainSwap> {
     Ok(StoredCrossChainSwap {
@@ -816,15 +844,16 @@ impl Storage for SqliteStorage {
         let connection = self.get_connection()?;
 
         connection.execute(
-            "INSERT INTO payment_metadata (payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status)
-             VALUES (?, ?, ?, ?, ?, ?, ?)
+            "INSERT INTO payment_metadata (payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status, fiat_value)
+             VALUES (?, ?, ?, ?, ?, ?, ?, ?)
              ON CONFLICT(payment_id) DO UPDATE SET
                 parent_payment_id = COALESCE(excluded.parent_payment_id, parent_payment_id),
                 lnurl_pay_info = COALESCE(excluded.lnurl_pay_info, lnurl_pay_info),
                 lnurl_withdraw_info = COALESCE(excluded.lnurl_withdraw_info, lnurl_withdraw_info),
                 lnurl_description = COALESCE(excluded.lnurl_description, lnurl_description),
                 conversion_info = COALESCE(excluded.conversion_info, conversion_info),
-                conversion_status = COALESCE(excluded.conversion_status, conversion_status)",
+                conversion_status = COALESCE(excluded.conversion_status, conversion_status),
+                fiat_value = COALESCE(excluded.fiat_value, fiat_value)",
             params![
                 payment_id,
                 metadata.parent_payment_id,
@@ -833,6 +862,7 @@ impl Storage for SqliteStorage {
                 metadata.lnurl_description,
                 metadata.conversion_info.as_ref().map(serde_json::to_string).transpose()?,
                 metadata.conversion_status.as_ref().map(std::string::ToString::to_string),
+                metadata.fiat_value.as_ref().map(serde_json::to_string).transpose()?,
             ],
         )?;
 
@@ -1028,13 +1058,14 @@ impl Storage for SqliteStorage {
         let connection = self.get_connection()?;
         for metadata in metadata {
             connection.execute(
-                "INSERT OR REPLACE INTO lnurl_receive_metadata (payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment)
-                 VALUES (?, ?, ?, ?)",
+                "INSERT OR REPLACE INTO lnurl_receive_metadata (payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment, lightning_address)
+                 VALUES (?, ?, ?, ?, ?)",
                 params![
                     metadata.payment_hash,
                     metadata.nostr_zap_request,
                     metadata.nostr_zap_receipt,
                     metadata.sender_comment,
+                    metadata.lightning_address,
                 ],
             )?;
         }
@@ -1048,56 +1079,47 @@ impl Storage for SqliteStorage {
         let limit = request.limit.unwrap_or(u32::MAX);
         let offset = request.offset.unwrap_or(0);
         let connection = self.get_connection()?;
-        let query = "SELECT id, name, payment_identifier, created_at, updated_at FROM contacts ORDER BY name ASC LIMIT ? OFFSET ?";
+        let query =
+            format!("SELECT {CONTACT_COLUMNS} FROM contacts ORDER BY name ASC LIMIT ? OFFSET ?");
 
-        let mut stmt = connection.prepare(query)?;
+        let mut stmt = connection.prepare(&query)?;
         let contacts = stmt
-            .query_map(params![limit, offset], |row| {
-                Ok(Contact {
-                    id: row.get(0)?,
-                    name: row.get(1)?,
-                    payment_identifier: row.get(2)?,
-                    created_at: row.get(3)?,
-                    updated_at: row.get(4)?,
-                })
-            })?
+            .query_map(params![limit, offset], map_contact)?
             .collect::<Result<Vec<_>, _>>()?;
         Ok(contacts)
     }
 
     async fn get_contact(&self, id: String) -> Result<Contact, StorageError> {
         let connection = self.get_connection()?;
-        let mut stmt = connection.prepare(
-            "SELECT id, name, payment_identifier, created_at, updated_at FROM contacts WHERE id = ?",
-        )?;
-        stmt.query_row(params![id], |row| {
-            Ok(Contact {
-                id: row.get(0)?,
-                name: row.get(1)?,
-                payment_identifier: row.get(2)?,
-                created_at: row.get(3)?,
-                updated_at: row.get(4)?,
+        let mut stmt = connection.prepare(&format!(
+            "SELECT {CONTACT_COLUMNS} FROM contacts WHERE id = ?"
+        ))?;
+        stmt.query_row(params![id], map_contact)
+            .map_err(|e| match e {
+                rusqlite::Error::QueryReturnedNoRows => StorageError::NotFound,
+                other => other.into(),
             })
-        })
-        .map_err(|e| match e {
-            rusqlite::Error::QueryReturnedNoRows => StorageError::NotFound,
-            other => other.into(),
-        })
     }
 
     async fn insert_contact(&self, contact: Contact) -> Result<(), StorageError> {
         let connection = self.get_connection()?;
         connection.execute(
-            "INSERT INTO contacts (id, name, payment_identifier, created_at, updated_at)
-             VALUES (?, ?, ?, ?, ?)
+            "INSERT INTO contacts (id, name, payment_identifier, additional_payment_identifiers, avatar_url, notes, created_at, updated_at)
+             VALUES (?, ?, ?, ?, ?, ?, ?, ?)
              ON CONFLICT(id) DO UPDATE SET
                name = excluded.name,
                payment_identifier = excluded.payment_identifier,
+               additional_payment_identifiers = excluded.additional_payment_identifiers,
+               avatar_url = excluded.avatar_url,
+               notes = excluded.notes,
                updated_at = excluded.updated_at",
             params![
                 contact.id,
                 contact.name,
                 contact.payment_identifier,
+                serde_json::to_string(&contact.additional_payment_identifiers)?,
+                contact.avatar_url,
+                contact.notes,
                 contact.created_at,
                 contact.updated_at,
             ],
@@ -1531,10 +1553,46 @@ impl Storage for SqliteStorage {
         tx.commit().map_err(map_sqlite_error)?;
         Ok(())
     }
+
+    async fn delete_all_data(&self) -> Result<(), StorageError> {
+        let mut connection = self.get_connection()?;
+        // Overwrite deleted content with zeros rather than only unlinking it
+        connection
+            .pragma_update(None, "secure_delete", true)
+            .map_err(map_sqlite_error)?;
+        let tx = connection
+            .transaction_with_behavior(TransactionBehavior::Immediate)
+            .map_err(map_sqlite_error)?;
+        tx.execute_batch(
+            "DELETE FROM payment_details_lightning;
+             DELETE FROM payment_details_token;
+             DELETE FROM payment_details_spark;
+             DELETE FROM payment_details_deposit;
+             DELETE FROM payment_metadata;
+             DELETE FROM payments;
+             DELETE FROM unclaimed_deposits;
+             DELETE FROM deposit_refunds;
+             DELETE FROM lnurl_receive_metadata;
+             DELETE FROM contacts;
+             DELETE FROM cross_chain_swaps;
+             DELETE FROM settings;
+             DELETE FROM sync_outgoing;
+             DELETE FROM sync_incoming;
+             DELETE FROM sync_state;
+             UPDATE sync_revision SET revision = 0;",
+        )
+        .map_err(map_sqlite_error)?;
+        tx.commit().map_err(map_sqlite_error)?;
+        // Release the freed pages so no deleted data is left in the file
+        connection
+            .execute_batch("VACUUM;")
+            .map_err(map_sqlite_error)?;
+        Ok(())
+    }
 }
 
 /// Base query for payment lookups.
-/// Column indices 0-31 are used by `map_payment`, index 32 (`parent_payment_id`) is only used by `get_payments_by_parent_ids`.
+/// Column indices 0-31, 33 and 34 are used by `map_payment`, index 32 (`parent_payment_id`) is only used by `get_payments_by_parent_ids`.
 const SELECT_PAYMENT_SQL: &str = "
     SELECT p.id,
            p.payment_type,
@@ -1568,7 +1626,9 @@ const SELECT_PAYMENT_SQL: &str = "
            lrm.sender_comment AS lnurl_sender_comment,
            lrm.payment_hash AS lnurl_payment_hash,
            pm.conversion_status,
-           pm.parent_payment_id
+           pm.parent_payment_id,
+           pm.fiat_value,
+           lrm.lightning_address AS lnurl_lightning_address
       FROM payments p
       LEFT JOIN payment_details_lightning l ON p.id = l.payment_id
       LEFT JOIN payment_details_token t ON p.id = t.payment_id
@@ -1622,6 +1682,7 @@ fn map_payment(row: &Row<'_>) -> Result<Payment, rusqlite::Error> {
                     nostr_zap_request: lnurl_nostr_zap_request,
                     nostr_zap_receipt: lnurl_nostr_zap_receipt,
                     sender_comment: lnurl_sender_comment,
+                    lightning_address: row.get(34)?,
                 })
             } else {
                 None
@@ -1697,6 +1758,10 @@ fn map_payment(row: &Row<'_>) -> Result<Payment, rusqlite::Error> {
         status,
         conversions: vec![],
     });
+    let fiat_value_str: Option<String> = row.get(33)?;
+    let fiat_value = fiat_value_str
+        .map(|s| serde_json_from_str(&s, 33))
+        .transpose()?;
 
     Ok(Payment {
         id: row.get(0)?,
@@ -1712,6 +1777,8 @@ fn map_payment(row: &Row<'_>) -> Result<Payment, rusqlite::Error> {
         details,
         method: row.get(6)?,
         conversion_details,
+        fiat_value,
+        receive_metadata: None,
     })
 }
 
@@ -2196,6 +2263,8 @@ mod tests {
                 conversion_info: None,
             }),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         };
 
         storage.apply_payment_update(new_payment).await.unwrap();
@@ -2489,6 +2558,14 @@ mod tests {
         crate::persist::tests::test_contacts_crud(Box::new(storage)).await;
     }
 
+    #[tokio::test]
+    async fn test_delete_all_data() {
+        let temp_dir = create_temp_dir("delete_all_data");
+        let storage = SqliteStorage::new(&temp_dir).unwrap();
+
+        crate::persist::tests::test_delete_all_data(Box::new(storage)).await;
+    }
+
     /// Migration backfill: an untyped (pre-migration) AMM `conversion_info`
     /// row is upgraded to a tagged enum and reads back via the strict
     /// `from_json_string_opt::<ConversionInfo>` path that `list_payments` /
@@ -358,6 +358,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 2: Spark HTLC payment
@@ -380,6 +382,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 3: Transfer token payment with invoice
@@ -412,6 +416,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 4: Mint token payment
@@ -431,6 +437,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 5: Burn token payment
@@ -450,6 +458,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 6: Lightning payment with full details
@@ -484,6 +494,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 7: Lightning payment with full details
@@ -512,6 +524,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 8: Lightning HODL payment with HTLC details
@@ -541,6 +555,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 9: Lightning payment with minimal details
@@ -563,6 +579,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 9: Lightning payment with LNURL receive metadata
@@ -572,6 +590,7 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
         sender_comment: Some("Test sender comment".to_string()),
         nostr_zap_request: Some(r#"{"kind":9734,"content":"test zap"}"#.to_string()),
         nostr_zap_receipt: Some(r#"{"kind":9735,"content":"test receipt"}"#.to_string()),
+        lightning_address: Some("shop@breez.tips".to_string()),
     };
     let lightning_lnurl_receive_payment = Payment {
         id: "lightning_lnurl_receive_pmt".to_string(),
@@ -592,6 +611,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 10: Withdraw payment
@@ -607,6 +628,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             tx_id: "1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef12".to_string(),
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 11: Deposit payment
@@ -623,6 +646,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             vout: 2,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 12: Payment with no details
@@ -636,6 +661,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
         method: PaymentMethod::Unknown,
         details: None,
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 13: Successful conversion payment
@@ -667,6 +694,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
                 .clone(),
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
     let successful_received_conversion_payment_metadata = PaymentMetadata {
         parent_payment_id: Some("after_conversion_pmt124".to_string()),
@@ -688,6 +717,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
     let after_conversion_payment = Payment {
         id: "after_conversion_pmt124".to_string(),
@@ -705,6 +736,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 14: Failed conversion payment with refund info
@@ -735,6 +768,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
                 .clone(),
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Test 15: Failed conversion payment with no refund info
@@ -765,6 +800,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
                 .clone(),
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let test_payments = vec![
@@ -814,6 +851,7 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             nostr_zap_request: lnurl_receive_metadata.nostr_zap_request.clone(),
             payment_hash: lnurl_receive_payment_hash.clone(),
             sender_comment: lnurl_receive_metadata.sender_comment.clone(),
+            lightning_address: lnurl_receive_metadata.lightning_address.clone(),
         }])
         .await
         .unwrap();
@@ -1008,6 +1046,7 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
                     (Some(r_info), Some(e_info)) => {
                         assert_eq!(r_info.nostr_zap_request, e_info.nostr_zap_request);
                         assert_eq!(r_info.sender_comment, e_info.sender_comment);
+                        assert_eq!(r_info.lightning_address, e_info.lightning_address);
                     }
                     (None, None) => {}
                     _ => panic!(
@@ -1102,6 +1141,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     storage
@@ -1121,6 +1162,7 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             nostr_zap_receipt: Some(
                 r#"{"kind":9735,"content":"zap receipt","tags":[]}"#.to_string(),
             ),
+            lightning_address: None,
         }])
         .await
         .unwrap();
@@ -1175,6 +1217,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let lightning_zap_payment3 = Payment {
@@ -1196,6 +1240,8 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     storage
@@ -1215,12 +1261,14 @@ pub async fn test_storage(storage: Box<dyn Storage>) {
                 sender_comment: Some("Nice work!".to_string()),
                 nostr_zap_request: None,
                 nostr_zap_receipt: None,
+                lightning_address: None,
             },
             SetLnurlMetadataItem {
                 payment_hash: "zaphash3".to_string(),
                 sender_comment: None,
                 nostr_zap_request: Some(r#"{"kind":9734,"content":"zap3"}"#.to_string()),
                 nostr_zap_receipt: None,
+                lightning_address: None,
             },
         ])
         .await
@@ -1429,6 +1477,8 @@ pub async fn test_payment_type_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let receive_payment = Payment {
@@ -1450,6 +1500,8 @@ pub async fn test_payment_type_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     storage.apply_payment_update(send_payment).await.unwrap();
@@ -1511,6 +1563,8 @@ pub async fn test_payment_status_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let pending_payment = Payment {
@@ -1527,6 +1581,8 @@ pub async fn test_payment_status_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let failed_payment = Payment {
@@ -1543,6 +1599,8 @@ pub async fn test_payment_status_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     storage
@@ -1604,6 +1662,8 @@ pub async fn test_asset_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let lightning_payment = Payment {
@@ -1625,6 +1685,8 @@ pub async fn test_asset_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let token_payment = Payment {
@@ -1651,6 +1713,8 @@ pub async fn test_asset_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let withdraw_payment = Payment {
@@ -1665,6 +1729,8 @@ pub async fn test_asset_filtering(storage: Box<dyn Storage>) {
             tx_id: "withdraw_tx_1".to_string(),
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let deposit_payment = Payment {
@@ -1680,6 +1746,8 @@ pub async fn test_asset_filtering(storage: Box<dyn Storage>) {
             vout: 0,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     storage.apply_payment_update(spark_payment).await.unwrap();
@@ -1765,6 +1833,8 @@ pub async fn test_spark_htlc_status_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let htlc_shared = Payment {
@@ -1786,6 +1856,8 @@ pub async fn test_spark_htlc_status_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let htlc_returned = Payment {
@@ -1807,6 +1879,8 @@ pub async fn test_spark_htlc_status_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Create a payment that is not HTLC-related
@@ -1827,6 +1901,8 @@ pub async fn test_spark_htlc_status_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Insert all payments
@@ -1955,6 +2031,8 @@ pub async fn test_conversion_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let successful_conversion_metadata = PaymentMetadata {
@@ -1982,6 +2060,8 @@ pub async fn test_conversion_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let payment_without_refund_metadata = PaymentMetadata {
@@ -2009,6 +2089,8 @@ pub async fn test_conversion_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     storage
@@ -2178,6 +2260,8 @@ pub async fn test_conversion_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
     storage
         .apply_payment_update(orchestra_payment)
@@ -2224,6 +2308,8 @@ pub async fn test_conversion_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
     storage
         .apply_payment_update(orchestra_completed_payment)
@@ -2329,6 +2415,8 @@ pub async fn test_conversion_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Pending Boltz conversion → should match BoltzPending.
@@ -2414,6 +2502,8 @@ pub async fn test_token_transaction_type_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
     let payment2 = Payment {
         id: "mint_2".to_string(),
@@ -2431,6 +2521,8 @@ pub async fn test_token_transaction_type_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
     let payment3 = Payment {
         id: "burn_3".to_string(),
@@ -2448,6 +2540,8 @@ pub async fn test_token_transaction_type_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
     storage.apply_payment_update(payment1).await.unwrap();
     storage.apply_payment_update(payment2).await.unwrap();
@@ -2516,6 +2610,8 @@ pub async fn test_timestamp_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let payment2 = Payment {
@@ -2532,6 +2628,8 @@ pub async fn test_timestamp_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let payment3 = Payment {
@@ -2548,6 +2646,8 @@ pub async fn test_timestamp_filtering(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     storage.apply_payment_update(payment1).await.unwrap();
@@ -2606,6 +2706,8 @@ pub async fn test_combined_filters(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let payment2 = Payment {
@@ -2627,6 +2729,8 @@ pub async fn test_combined_filters(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let payment3 = Payment {
@@ -2648,6 +2752,8 @@ pub async fn test_combined_filters(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     storage.apply_payment_update(payment1).await.unwrap();
@@ -2708,6 +2814,8 @@ pub async fn test_sort_order(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let payment2 = Payment {
@@ -2724,6 +2832,8 @@ pub async fn test_sort_order(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let payment3 = Payment {
@@ -2740,6 +2850,8 @@ pub async fn test_sort_order(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     storage.apply_payment_update(payment1).await.unwrap();
@@ -2867,6 +2979,8 @@ pub async fn test_payment_details_update_persistence(storage: Box<dyn Storage>)
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Insert the payment into storage
@@ -2942,6 +3056,8 @@ pub async fn test_payment_terminal_status_is_not_replaced(storage: Box<dyn Stora
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     let should_emit = storage.apply_payment_update(payment.clone()).await.unwrap();
@@ -2996,6 +3112,8 @@ pub async fn test_payment_metadata_merge(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
     storage.apply_payment_update(payment).await.unwrap();
 
@@ -3010,6 +3128,8 @@ pub async fn test_payment_metadata_merge(storage: Box<dyn Storage>) {
         method: PaymentMethod::Spark,
         details: None,
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
     storage.apply_payment_update(parent_payment).await.unwrap();
 
@@ -3082,6 +3202,35 @@ pub async fn test_payment_metadata_merge(storage: Box<dyn Storage>) {
         conversion_info.as_ref().unwrap(),
         crate::ConversionInfo::Amm { conversion_id, .. } if conversion_id == "conv_123"
     ));
+
+    // Step 3: Set metadata with only fiat_value
+    let metadata3 = PaymentMetadata {
+        fiat_value: Some(crate::PaymentFiatValue {
+            currency: "USD".to_string(),
+            rate: 60_000.0,
+            amount: 0.6,
+            recorded_at: 1_700_000_002,
+        }),
+        ..Default::default()
+    };
+    storage
+        .insert_payment_metadata(payment_id.clone(), metadata3)
+        .await
+        .unwrap();
+
+    // Verify fiat_value is set and conversion_info is still present
+    let fetched = storage.get_payment_by_id(payment_id.clone()).await.unwrap();
+    let fiat_value = fetched.fiat_value.expect("fiat_value should be set");
+    assert_eq!(fiat_value.currency, "USD");
+    assert!((fiat_value.rate - 60_000.0).abs() < f64::EPSILON);
+    assert_eq!(fiat_value.recorded_at, 1_700_000_002);
+    assert!(matches!(
+        fetched.details,
+        Some(PaymentDetails::Spark {
+            conversion_info: Some(_),
+            ..
+        })
+    ));
 }
 
 #[allow(clippy::too_many_lines)]
@@ -3111,6 +3260,8 @@ pub async fn test_lightning_htlc_details_and_status_filtering(storage: Box<dyn S
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Lightning payment with htlc_details PreimageShared (claimed)
@@ -3138,6 +3289,8 @@ pub async fn test_lightning_htlc_details_and_status_filtering(storage: Box<dyn S
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Regular Lightning payment
@@ -3165,6 +3318,8 @@ pub async fn test_lightning_htlc_details_and_status_filtering(storage: Box<dyn S
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // Non-Lightning payment (should never appear in Lightning filters)
@@ -3182,6 +3337,8 @@ pub async fn test_lightning_htlc_details_and_status_filtering(storage: Box<dyn S
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     storage
@@ -3276,6 +3433,9 @@ pub async fn test_contacts_crud(storage: Box<dyn Storage>) {
         id: "c1".to_string(),
         name: "Alice".to_string(),
         payment_identifier: "alice@example.com".to_string(),
+        additional_payment_identifiers: Vec::new(),
+        avatar_url: None,
+        notes: None,
         created_at: 1000,
         updated_at: 1000,
     };
@@ -3308,6 +3468,9 @@ pub async fn test_contacts_crud(storage: Box<dyn Storage>) {
         id: "c1".to_string(),
         name: "Alice B".to_string(),
         payment_identifier: "alice@example.com".to_string(),
+        additional_payment_identifiers: vec!["alice@other.com".to_string()],
+        avatar_url: Some("https://example.com/alice.png".to_string()),
+        notes: Some("Met at the conference".to_string()),
         created_at: 0, // Should be ignored by ON CONFLICT
         updated_at: 2000,
     };
@@ -3315,6 +3478,15 @@ pub async fn test_contacts_crud(storage: Box<dyn Storage>) {
     let updated = storage.get_contact("c1".to_string()).await.unwrap();
     assert_eq!(updated.name, "Alice B");
     assert_eq!(updated.created_at, 1000); // Verify created_at preserved
+    assert_eq!(
+        updated.additional_payment_identifiers,
+        vec!["alice@other.com"]
+    );
+    assert_eq!(
+        updated.avatar_url.as_deref(),
+        Some("https://example.com/alice.png")
+    );
+    assert_eq!(updated.notes.as_deref(), Some("Met at the conference"));
 
     // Test delete
     storage.delete_contact("c1".to_string()).await.unwrap();
@@ -3329,6 +3501,9 @@ pub async fn test_contacts_crud(storage: Box<dyn Storage>) {
         id: "c2".to_string(),
         name: "Bob".to_string(),
         payment_identifier: "bob@example.com".to_string(),
+        additional_payment_identifiers: Vec::new(),
+        avatar_url: None,
+        notes: None,
         created_at: 1000,
         updated_at: 1000,
     };
@@ -3337,6 +3512,9 @@ pub async fn test_contacts_crud(storage: Box<dyn Storage>) {
         id: "c3".to_string(),
         name: "Bob".to_string(),
         payment_identifier: "bob@example.com".to_string(),
+        additional_payment_identifiers: Vec::new(),
+        avatar_url: None,
+        notes: None,
         created_at: 1000,
         updated_at: 1000,
     };
@@ -3347,6 +3525,9 @@ pub async fn test_contacts_crud(storage: Box<dyn Storage>) {
         id: "c4".to_string(),
         name: "Carol".to_string(),
         payment_identifier: "carol@example.com".to_string(),
+        additional_payment_identifiers: Vec::new(),
+        avatar_url: None,
+        notes: None,
         created_at: 1000,
         updated_at: 1000,
     };
@@ -3355,6 +3536,9 @@ pub async fn test_contacts_crud(storage: Box<dyn Storage>) {
         id: "c4".to_string(),
         name: "Bob".to_string(),
         payment_identifier: "bob@example.com".to_string(),
+        additional_payment_identifiers: Vec::new(),
+        avatar_url: None,
+        notes: None,
         created_at: 0,
         updated_at: 2000,
     };
@@ -3369,6 +3553,9 @@ pub async fn test_contacts_crud(storage: Box<dyn Storage>) {
             id: format!("p{i}"),
             name: format!("User{i}"),
             payment_identifier: format!("u{i}@example.com"),
+            additional_payment_identifiers: Vec::new(),
+            avatar_url: None,
+            notes: None,
             created_at: 1000,
             updated_at: 1000,
         };
@@ -3529,6 +3716,8 @@ pub async fn test_conversion_status_persistence(storage: Box<dyn Storage>) {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     // --- Test 1: All ConversionStatus variants round-trip ---
@@ -3705,6 +3894,8 @@ fn boltz_payment(id: &str) -> Payment {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     }
 }
 
@@ -3836,3 +4027,57 @@ pub async fn test_update_boltz_status_to_completed(storage: Box<dyn Storage>) {
     assert_eq!(bridge_ref, Some("0xabc123".to_string()));
     assert!(fetched.conversion_details.is_none());
 }
+
+pub async fn test_delete_all_data(storage: Box<dyn Storage>) {
+    use crate::{Contact, ListContactsRequest};
+
+    storage
+        .apply_payment_update(boltz_payment("payment"))
+        .await
+        .unwrap();
+    storage
+        .insert_contact(Contact {
+            id: "c1".to_string(),
+            name: "Alice".to_string(),
+            payment_identifier: "alice@example.com".to_string(),
+            additional_payment_identifiers: Vec::new(),
+            avatar_url: None,
+            notes: None,
+            created_at: 1000,
+            updated_at: 1000,
+        })
+        .await
+        .unwrap();
+    storage
+        .set_cached_item("key".to_string(), "value".to_string())
+        .await
+        .unwrap();
+
+    storage.delete_all_data().await.unwrap();
+
+    let payments = storage
+        .list_payments(StorageListPaymentsRequest::default())
+        .await
+        .unwrap();
+    assert!(payments.is_empty());
+    let contacts = storage
+        .list_contacts(ListContactsRequest::default())
+        .await
+        .unwrap();
+    assert!(contacts.is_empty());
+    assert_eq!(
+        storage.get_cached_item("key".to_string()).await.unwrap(),
+        None
+    );
+    assert_eq!(storage.get_last_revision().await.unwrap(), 0);
+
+    // The storage remains usable after the deletion
+    storage
+        .set_cached_item("key".to_string(), "value".to_string())
+        .await
+        .unwrap();
+    assert_eq!(
+        storage.get_cached_item("key".to_string()).await.unwrap(),
+        Some("value".to_string())
+    );
+}
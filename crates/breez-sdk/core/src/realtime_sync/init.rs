@@ -1,11 +1,13 @@
 use std::sync::Arc;
 
-use breez_sdk_common::sync::{BreezSyncerClient, SigningClient, SyncProcessor, SyncService};
+use breez_sdk_common::sync::{
+    BreezSyncerClient, SigningClient, SyncProcessor, SyncService, SyncerClient,
+};
 use tracing::debug;
 use uuid::Uuid;
 
 use crate::{
-    EventEmitter,
+    Clock, EventEmitter,
     error::SdkError,
     lnurl::LnurlServerClient,
     persist::Storage,
@@ -13,15 +15,30 @@ use crate::{
     sync_storage::SyncStorageWrapper,
 };
 
+/// Where the real-time sync records are stored
+pub enum RealTimeSyncTransport {
+    /// The Breez sync server, or another instance of it
+    Server {
+        server_url: String,
+        api_key: Option<String>,
+        user_agent: String,
+    },
+    /// Encrypted events on Nostr relays, see `SdkBuilder::with_nostr_sync_storage`
+    #[cfg(feature = "nostr-sync")]
+    Nostr(super::NostrSyncerClient),
+}
+
 pub struct RealTimeSyncParams {
-    pub server_url: String,
-    pub api_key: Option<String>,
-    pub user_agent: String,
+    pub transport: RealTimeSyncTransport,
     pub signer: Arc<dyn breez_sdk_common::sync::SyncSigner>,
     pub storage: Arc<dyn Storage>,
     pub shutdown_receiver: tokio::sync::watch::Receiver<()>,
+    /// Whether the background sync is paused, which closes the update
+    /// subscription
+    pub paused_receiver: tokio::sync::watch::Receiver<bool>,
     pub event_emitter: Arc<EventEmitter>,
     pub lnurl_server_client: Option<Arc<dyn LnurlServerClient>>,
+    pub clock: Option<Arc<dyn Clock>>,
 }
 
 pub async fn init_and_start_real_time_sync(
@@ -34,6 +51,7 @@ pub async fn init_and_start_real_time_sync(
     let synced_storage = Arc::new(SyncedStorage::new(
         Arc::clone(&params.storage),
         Arc::clone(&sync_service),
+        params.clock.clone(),
     ));
 
     synced_storage.initial_setup();
@@ -46,14 +64,18 @@ pub async fn init_and_start_real_time_sync(
         params.event_emitter,
         params.lnurl_server_client,
     ));
-    let sync_client: Arc<dyn breez_sdk_common::sync::SyncerClient> = Arc::new(
-        BreezSyncerClient::new(
-            &params.server_url,
-            params.api_key.as_deref(),
-            &params.user_agent,
-        )
-        .map_err(|e| SdkError::Generic(e.to_string()))?,
-    );
+    let sync_client: Arc<dyn SyncerClient> = match params.transport {
+        RealTimeSyncTransport::Server {
+            server_url,
+            api_key,
+            user_agent,
+        } => Arc::new(
+            BreezSyncerClient::new(&server_url, api_key.as_deref(), &user_agent)
+                .map_err(|e| SdkError::Generic(e.to_string()))?,
+        ),
+        #[cfg(feature = "nostr-sync")]
+        RealTimeSyncTransport::Nostr(client) => Arc::new(client),
+    };
 
     let signing_client = SigningClient::new(
         Arc::clone(&sync_client),
@@ -61,12 +83,15 @@ pub async fn init_and_start_real_time_sync(
         Uuid::now_v7().to_string(),
     );
 
-    let sync_processor = Arc::new(SyncProcessor::new(
-        signing_client.clone(),
-        sync_service.get_sync_trigger(),
-        record_handler,
-        Arc::clone(&sync_storage),
-    ));
+    let sync_processor = Arc::new(
+        SyncProcessor::new(
+            signing_client.clone(),
+            sync_service.get_sync_trigger(),
+            record_handler,
+            Arc::clone(&sync_storage),
+        )
+        .with_pause_signal(params.paused_receiver),
+    );
 
     sync_processor
         .start(params.shutdown_receiver)
@@ -1,4 +1,8 @@
 mod init;
+#[cfg(feature = "nostr-sync")]
+mod nostr_client;
 mod storage;
 
+#[cfg(feature = "nostr-sync")]
+pub use nostr_client::NostrSyncerClient;
 pub use {init::*, storage::*};
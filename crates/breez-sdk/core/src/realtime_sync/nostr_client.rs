@@ -0,0 +1,390 @@
+use std::{collections::HashSet, time::Duration};
+
+use anyhow::{Result, anyhow};
+use base64::{Engine, engine::general_purpose::STANDARD as BASE64};
+use bitcoin::bip32::DerivationPath;
+use bitcoin::hashes::{Hash, HashEngine, Hmac, HmacEngine, sha256};
+use breez_sdk_common::sync::{
+    NotificationStream, SyncerClient,
+    proto::{
+        GetLockReply, GetLockRequest, ListChangesReply, ListChangesRequest, ListenChangesRequest,
+        Notification, Record, SetLockReply, SetLockRequest, SetRecordReply, SetRecordRequest,
+        SetRecordStatus,
+    },
+};
+use nostr::{Event, EventBuilder, Filter, Kind, Tag, TagKind, Timestamp};
+use nostr_sdk::{Client, RelayPoolNotification};
+use platform_utils::{
+    time::{SystemTime, UNIX_EPOCH},
+    tokio::{
+        self,
+        sync::{OnceCell, broadcast::error::RecvError},
+    },
+};
+use tracing::{debug, warn};
+
+use crate::{Network, signer::HmacSigner};
+
+/// Path of the key seeding the Nostr sync identity, a hardened branch of the
+/// real-time sync purpose that is never used for signing records
+const NOSTR_SYNC_KEY_DERIVATION_PATH: &str = "m/1220588449'/2'";
+const NOSTR_SYNC_KEY_TAG: &[u8] = b"breez-realtime-sync-nostr:";
+const NOSTR_SYNC_ID_TAG: &[u8] = b"breez-realtime-sync-nostr-id:";
+
+const REVISION_TAG: &str = "revision";
+const SCHEMA_VERSION_TAG: &str = "schema_version";
+const CLIENT_ID_TAG: &str = "client_id";
+
+const RELAY_TIMEOUT_SECS: u64 = 30;
+/// Events requested per relay query. Relays cap the events a query returns,
+/// so the records are fetched page by page, from the newest.
+const PAGE_SIZE: usize = 500;
+
+/// A real-time sync transport storing the records as encrypted NIP-78
+/// application data events on user-selected Nostr relays, in place of the
+/// Breez sync server.
+///
+/// Each record is a replaceable event authored by a key derived from the
+/// wallet seed, so every device of the wallet reads and writes the same
+/// events. The record data is encrypted by the `SigningClient` before it gets
+/// here, and the event identifiers are keyed hashes of the record ids, so the
+/// relays learn neither.
+///
+/// Relays don't order writes, so a write takes the revision following the
+/// highest revision of all the records on the relays, keeping revisions
+/// increasing without relying on the device clock. A conflicting write is
+/// detected when the record's revision on the relays moved since the change
+/// was made, like on the sync server, but two devices writing within the same
+/// relay round trip may both succeed, the later event replacing the earlier.
+/// Locks aren't supported.
+pub struct NostrSyncerClient {
+    keys: nostr::Keys,
+    id_key: [u8; 32],
+    relays: Vec<String>,
+    client: OnceCell<Client>,
+}
+
+impl NostrSyncerClient {
+    pub async fn new(hmac: &dyn HmacSigner, network: Network, relays: Vec<String>) -> Result<Self> {
+        if relays.is_empty() {
+            return Err(anyhow!("Nostr sync storage needs at least one relay"));
+        }
+        let path: DerivationPath = NOSTR_SYNC_KEY_DERIVATION_PATH.parse()?;
+        let network_tag = network.to_string();
+        let secret = hmac
+            .hmac_sha256(
+                &path,
+                &[NOSTR_SYNC_KEY_TAG, network_tag.as_bytes()].concat(),
+            )
+            .await?;
+        let id_key = hmac
+            .hmac_sha256(&path, &[NOSTR_SYNC_ID_TAG, network_tag.as_bytes()].concat())
+            .await?;
+        let keys = nostr::Keys::new(nostr::SecretKey::from_slice(secret.as_byte_array())?);
+        debug!("Nostr sync storage identity: {}", keys.public_key());
+        Ok(Self {
+            keys,
+            id_key: id_key.to_byte_array(),
+            relays,
+            client: OnceCell::new(),
+        })
+    }
+
+    async fn client(&self) -> Result<&Client> {
+        self.client
+            .get_or_try_init(|| async {
+                let client = Client::new(self.keys.clone());
+                let mut added = 0usize;
+                for relay in &self.relays {
+                    match client.add_relay(relay.as_str()).await {
+                        #[allow(clippy::arithmetic_side_effects)]
+                        Ok(_) => added += 1,
+                        Err(e) => warn!("Failed to add sync relay {relay}: {e}"),
+                    }
+                }
+                if added == 0 {
+                    return Err(anyhow!("Failed to add any sync relay"));
+                }
+                client.connect().await;
+                Ok(client)
+            })
+            .await
+    }
+
+    /// The keyed hash identifying a record's event on the relays
+    fn event_identifier(&self, record_id: &str) -> String {
+        let mut engine = HmacEngine::<sha256::Hash>::new(&self.id_key);
+        engine.input(record_id.as_bytes());
+        Hmac::<sha256::Hash>::from_engine(engine).to_string()
+    }
+
+    fn records_filter(&self) -> Filter {
+        Filter::new()
+            .author(self.keys.public_key())
+            .kind(Kind::ApplicationSpecificData)
+    }
+
+    async fn fetch_events(&self, filter: Filter) -> Result<Vec<Event>> {
+        let events = self
+            .client()
+            .await?
+            .fetch_events(filter, Duration::from_secs(RELAY_TIMEOUT_SECS))
+            .await?;
+        Ok(events.into_iter().collect())
+    }
+
+    /// Every record event on the relays, fetched page by page going back in
+    /// time so that relay result caps don't hide older records
+    async fn fetch_all_events(&self) -> Result<Vec<Event>> {
+        let mut events: Vec<Event> = Vec::new();
+        let mut seen = HashSet::new();
+        let mut until: Option<Timestamp> = None;
+        loop {
+            let mut filter = self.records_filter().limit(PAGE_SIZE);
+            if let Some(until) = until {
+                filter = filter.until(until);
+            }
+            let page = self.fetch_events(filter).await?;
+            let page_len = page.len();
+            // `until` is inclusive, events sharing the oldest timestamp of
+            // the previous page come again
+            let mut added = 0usize;
+            for event in page {
+                until = Some(until.map_or(event.created_at, |u| u.min(event.created_at)));
+                if seen.insert(event.id) {
+                    added = added.saturating_add(1);
+                    events.push(event);
+                }
+            }
+            if page_len < PAGE_SIZE || added == 0 {
+                break;
+            }
+        }
+        Ok(events)
+    }
+
+    /// The stored record of an event, `None` if the event isn't one
+    fn parse_record(event: &Event) -> Option<Record> {
+        let identifier = event.tags.identifier()?;
+        let revision = tag_value(event, REVISION_TAG)?.parse().ok()?;
+        let schema_version = tag_value(event, SCHEMA_VERSION_TAG)?;
+        let data = BASE64.decode(&event.content).ok()?;
+        Some(Record {
+            id: identifier.to_string(),
+            revision,
+            schema_version: schema_version.to_string(),
+            data,
+        })
+    }
+}
+
+fn tag_value<'a>(event: &'a Event, name: &str) -> Option<&'a str> {
+    event
+        .tags
+        .find(TagKind::custom(name.to_string()))
+        .and_then(Tag::content)
+}
+
+fn revision_of(event: &Event) -> Option<u64> {
+    tag_value(event, REVISION_TAG)?.parse().ok()
+}
+
+#[macros::async_trait]
+impl SyncerClient for NostrSyncerClient {
+    async fn set_record(&self, req: SetRecordRequest) -> Result<SetRecordReply> {
+        let record = req
+            .record
+            .ok_or_else(|| anyhow!("Missing record in set record request"))?;
+        let identifier = self.event_identifier(&record.id);
+
+        let events = self.fetch_all_events().await?;
+        let current = events
+            .iter()
+            .filter(|event| event.tags.identifier() == Some(identifier.as_str()))
+            .max_by_key(|event| revision_of(event).unwrap_or_default());
+        let current_revision = current.and_then(revision_of);
+        if let Some(current_revision) = current_revision
+            && current_revision != record.revision
+        {
+            debug!(
+                "Conflict setting record: revision {current_revision} on the relays, {} expected",
+                record.revision
+            );
+            return Ok(SetRecordReply {
+                status: SetRecordStatus::Conflict.into(),
+                new_revision: 0,
+            });
+        }
+
+        // Past every revision written, so that no device's cursor is already
+        // beyond it
+        let new_revision = events
+            .iter()
+            .filter_map(revision_of)
+            .max()
+            .unwrap_or_default()
+            .saturating_add(1);
+        let now = SystemTime::now().duration_since(UNIX_EPOCH)?;
+        // Relays keep the replaceable event created last
+        let created_at = current
+            .map(|event| event.created_at.as_u64().saturating_add(1))
+            .unwrap_or_default()
+            .max(now.as_secs());
+
+        let mut tags = vec![
+            Tag::identifier(identifier),
+            Tag::custom(TagKind::custom(REVISION_TAG), [new_revision.to_string()]),
+            Tag::custom(TagKind::custom(SCHEMA_VERSION_TAG), [record.schema_version]),
+        ];
+        if let Some(client_id) = req.client_id {
+            tags.push(Tag::custom(TagKind::custom(CLIENT_ID_TAG), [client_id]));
+        }
+        let event = EventBuilder::new(Kind::ApplicationSpecificData, BASE64.encode(record.data))
+            .tags(tags)
+            .custom_created_at(Timestamp::from(created_at))
+            .sign_with_keys(&self.keys)?;
+        self.client().await?.send_event(&event).await?;
+
+        Ok(SetRecordReply {
+            status: SetRecordStatus::Success.into(),
+            new_revision,
+        })
+    }
+
+    async fn list_changes(&self, req: ListChangesRequest) -> Result<ListChangesReply> {
+        let mut changes: Vec<Record> = Vec::new();
+        for record in self
+            .fetch_all_events()
+            .await?
+            .iter()
+            .filter_map(Self::parse_record)
+        {
+            if record.revision <= req.since_revision {
+                continue;
+            }
+            // Relays that missed a write may still hold an older event
+            match changes.iter_mut().find(|change| change.id == record.id) {
+                Some(change) if change.revision < record.revision => *change = record,
+                Some(_) => {}
+                None => changes.push(record),
+            }
+        }
+        changes.sort_by_key(|change| change.revision);
+        Ok(ListChangesReply { changes })
+    }
+
+    async fn listen_changes(&self, _req: ListenChangesRequest) -> Result<NotificationStream> {
+        let client = self.client().await?;
+        let notifications = client.notifications();
+        client
+            .subscribe(self.records_filter().since(Timestamp::now()), None)
+            .await?;
+        let stream = futures::stream::unfold(notifications, |mut notifications| async move {
+            loop {
+                match notifications.recv().await {
+                    Ok(RelayPoolNotification::Event { event, .. }) => {
+                        let notification = Notification {
+                            client_id: tag_value(&event, CLIENT_ID_TAG).map(ToString::to_string),
+                        };
+                        return Some((Ok(notification), notifications));
+                    }
+                    Ok(_) => {}
+                    // Missed events still mean something changed
+                    Err(RecvError::Lagged(_)) => {
+                        return Some((Ok(Notification { client_id: None }), notifications));
+                    }
+                    Err(RecvError::Closed) => return None,
+                }
+            }
+        });
+        Ok(Box::pin(stream))
+    }
+
+    async fn set_lock(&self, _req: SetLockRequest) -> Result<SetLockReply> {
+        Err(anyhow!("Locks are not supported by Nostr sync storage"))
+    }
+
+    async fn get_lock(&self, _req: GetLockRequest) -> Result<GetLockReply> {
+        Err(anyhow!("Locks are not supported by Nostr sync storage"))
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use bitcoin::bip32::Xpriv;
+    use macros::async_test_all;
+
+    use super::*;
+    use crate::signer::breez::BreezSignerImpl;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn signer(seed: u8) -> BreezSignerImpl {
+        BreezSignerImpl::new(Xpriv::new_master(bitcoin::Network::Regtest, &[seed; 32]).unwrap())
+    }
+
+    fn relays() -> Vec<String> {
+        vec!["wss://relay.example.com".to_string()]
+    }
+
+    #[async_test_all]
+    async fn test_identity_is_stable_per_seed_and_network() {
+        let client = NostrSyncerClient::new(&signer(1), Network::Mainnet, relays())
+            .await
+            .unwrap();
+        let same = NostrSyncerClient::new(&signer(1), Network::Mainnet, relays())
+            .await
+            .unwrap();
+        let regtest = NostrSyncerClient::new(&signer(1), Network::Regtest, relays())
+            .await
+            .unwrap();
+        let other = NostrSyncerClient::new(&signer(2), Network::Mainnet, relays())
+            .await
+            .unwrap();
+
+        assert_eq!(client.keys.public_key(), same.keys.public_key());
+        assert_ne!(client.keys.public_key(), regtest.keys.public_key());
+        assert_ne!(client.keys.public_key(), other.keys.public_key());
+    }
+
+    #[async_test_all]
+    async fn test_event_identifier_hides_record_id() {
+        let client = NostrSyncerClient::new(&signer(1), Network::Mainnet, relays())
+            .await
+            .unwrap();
+        let identifier = client.event_identifier("Contact:c1");
+
+        assert_eq!(identifier, client.event_identifier("Contact:c1"));
+        assert_ne!(identifier, client.event_identifier("Contact:c2"));
+        assert!(!identifier.contains("c1"));
+        assert_eq!(identifier.len(), 64);
+    }
+
+    #[async_test_all]
+    async fn test_requires_a_relay() {
+        let result = NostrSyncerClient::new(&signer(1), Network::Mainnet, Vec::new()).await;
+        assert!(result.is_err());
+    }
+
+    #[async_test_all]
+    async fn test_parse_record_round_trip() {
+        let client = NostrSyncerClient::new(&signer(1), Network::Mainnet, relays())
+            .await
+            .unwrap();
+        let event = EventBuilder::new(Kind::ApplicationSpecificData, BASE64.encode([1u8, 2, 3]))
+            .tags([
+                Tag::identifier("abc"),
+                Tag::custom(TagKind::custom(REVISION_TAG), ["42"]),
+                Tag::custom(TagKind::custom(SCHEMA_VERSION_TAG), ["1.0.0"]),
+            ])
+            .sign_with_keys(&client.keys)
+            .unwrap();
+
+        let record = NostrSyncerClient::parse_record(&event).unwrap();
+        assert_eq!(record.id, "abc");
+        assert_eq!(record.revision, 42);
+        assert_eq!(record.schema_version, "1.0.0");
+        assert_eq!(record.data, vec![1, 2, 3]);
+    }
+}
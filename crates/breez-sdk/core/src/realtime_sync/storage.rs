@@ -11,18 +11,21 @@ use breez_sdk_common::sync::{
     SchemaVersion, SyncService,
 };
 use serde_json::Value;
-use tracing::{Instrument, debug, error, warn};
+use tracing::{Instrument, debug, error, info, warn};
 
 use crate::{
-    Contact, DepositInfo, EventEmitter, ListContactsRequest, Payment, PaymentDetails,
-    PaymentMetadata, Storage, StorageError, UpdateDepositPayload,
+    Clock, Contact, DepositInfo, EventEmitter, ListContactsRequest, Payment, PaymentDetails,
+    PaymentMetadata, Storage, StorageError, SyncConflict, SyncRecordType, UpdateDepositPayload,
     events::{InternalSyncedEvent, SdkEvent},
     lnurl::LnurlServerClient,
     persist::{
-        LIGHTNING_ADDRESS_KEY, ObjectCacheRepository, StorageListPaymentsRequest,
-        StoredCrossChainSwap, parse_cached_lightning_address,
+        DEVICE_NAME_KEY, DISPLAY_CURRENCY_KEY, LIGHTNING_ADDRESS_KEY, ObjectCacheRepository,
+        StorageListPaymentsRequest, StoredCrossChainSwap, SyncedDevice,
+        parse_cached_lightning_address,
     },
+    sdk::clock_now,
     sync_storage::{IncomingChange, OutgoingChange, Record, UnversionedRecordChange},
+    utils::contact_merge::save_contact_merging_duplicates,
 };
 use platform_utils::tokio;
 use serde::{Deserialize, Serialize};
@@ -34,6 +37,8 @@ enum RecordType {
     Contact,
     LightningAddress,
     CrossChainSwap,
+    Device,
+    UserSettings,
 }
 
 impl RecordType {
@@ -41,9 +46,24 @@ impl RecordType {
     const fn schema_version(&self) -> SchemaVersion {
         match self {
             Self::PaymentMetadata => SchemaVersion::new(1, 0, 0),
-            Self::Contact => SchemaVersion::new(1, 0, 0),
+            Self::Contact => SchemaVersion::new(1, 1, 0),
             Self::LightningAddress => SchemaVersion::new(1, 0, 0),
             Self::CrossChainSwap => SchemaVersion::new(1, 0, 0),
+            Self::Device => SchemaVersion::new(1, 0, 0),
+            Self::UserSettings => SchemaVersion::new(1, 0, 0),
+        }
+    }
+}
+
+impl From<&RecordType> for SyncRecordType {
+    fn from(value: &RecordType) -> Self {
+        match value {
+            RecordType::PaymentMetadata => SyncRecordType::PaymentMetadata,
+            RecordType::Contact => SyncRecordType::Contact,
+            RecordType::LightningAddress => SyncRecordType::LightningAddress,
+            RecordType::CrossChainSwap => SyncRecordType::CrossChainSwap,
+            RecordType::Device => SyncRecordType::Device,
+            RecordType::UserSettings => SyncRecordType::UserSettings,
         }
     }
 }
@@ -55,6 +75,8 @@ impl Display for RecordType {
             RecordType::Contact => "Contact",
             RecordType::LightningAddress => "LightningAddress",
             RecordType::CrossChainSwap => "CrossChainSwap",
+            RecordType::Device => "Device",
+            RecordType::UserSettings => "UserSettings",
         };
         write!(f, "{s}")
     }
@@ -69,12 +91,15 @@ impl FromStr for RecordType {
             "Contact" => Ok(RecordType::Contact),
             "LightningAddress" => Ok(RecordType::LightningAddress),
             "CrossChainSwap" => Ok(RecordType::CrossChainSwap),
+            "Device" => Ok(RecordType::Device),
+            "UserSettings" => Ok(RecordType::UserSettings),
             _ => Err(format!("Unknown record type: {s}")),
         }
     }
 }
 
 const LIGHTNING_ADDRESS_DATA_ID: &str = "current";
+const USER_SETTINGS_DATA_ID: &str = "current";
 const DELETED_AT_FIELD: &str = "deleted_at";
 
 /// Internal sync model for contacts
@@ -83,6 +108,13 @@ struct ContactSyncData {
     pub id: String,
     pub name: String,
     pub payment_identifier: String,
+    /// Added in schema 1.1.0, along with `avatar_url` and `notes`
+    #[serde(default)]
+    pub additional_payment_identifiers: Vec<String>,
+    #[serde(default)]
+    pub avatar_url: Option<String>,
+    #[serde(default)]
+    pub notes: Option<String>,
     pub created_at: u64,
     pub updated_at: u64,
     #[serde(skip_serializing_if = "Option::is_none")]
@@ -90,6 +122,12 @@ struct ContactSyncData {
     pub deleted_at: Option<u64>,
 }
 
+/// Internal sync model for the user settings kept in local storage
+#[derive(Serialize, Deserialize)]
+struct UserSettingsSyncData {
+    pub display_currency: Option<String>,
+}
+
 /// Storage wrapper that mirrors local writes into the real-time sync queue.
 ///
 /// This is `sdk.storage`, which is reachable from the `EventEmitter` (handlers
@@ -99,6 +137,9 @@ struct ContactSyncData {
 pub struct SyncedStorage {
     inner: Arc<dyn Storage>,
     sync_service: Arc<SyncService>,
+    /// Clock the timestamps of outgoing records are read from, unset to use
+    /// the system clock
+    clock: Option<Arc<dyn Clock>>,
 }
 
 /// Applies incoming and replayed sync records to local storage and reports
@@ -156,10 +197,15 @@ impl NewRecordHandler for SyncedRecordHandler {
 }
 
 impl SyncedStorage {
-    pub fn new(inner: Arc<dyn Storage>, sync_service: Arc<SyncService>) -> Self {
+    pub fn new(
+        inner: Arc<dyn Storage>,
+        sync_service: Arc<SyncService>,
+        clock: Option<Arc<dyn Clock>>,
+    ) -> Self {
         SyncedStorage {
             inner,
             sync_service,
+            clock,
         }
     }
 
@@ -171,6 +217,15 @@ impl SyncedStorage {
                 if let Err(e) = clone.feed_existing_payment_metadata().await {
                     error!("Failed to feed existing payment metadata for sync: {}", e);
                 }
+                // Refreshes this device's last seen time on the other devices
+                match clone
+                    .inner
+                    .get_cached_item(DEVICE_NAME_KEY.to_string())
+                    .await
+                {
+                    Ok(name) => clone.push_device_sync(name).await,
+                    Err(e) => error!("Failed to read device name for sync: {e:?}"),
+                }
             }
             .instrument(span),
         );
@@ -192,35 +247,37 @@ impl SyncedStorage {
             .list_payments(StorageListPaymentsRequest::default())
             .await?;
         for payment in payments {
-            let Some(details) = payment.details else {
-                continue;
-            };
-            let (description, lnurl_pay_info, lnurl_withdraw_info, conversion_info) = match details
-            {
-                PaymentDetails::Lightning {
-                    description,
-                    lnurl_pay_info,
-                    lnurl_withdraw_info,
-                    conversion_info,
-                    ..
-                } => (
-                    description,
-                    lnurl_pay_info,
-                    lnurl_withdraw_info,
-                    conversion_info,
-                ),
-                PaymentDetails::Spark {
-                    conversion_info, ..
-                }
-                | PaymentDetails::Token {
-                    conversion_info, ..
-                } => (None, None, None, conversion_info),
-                _ => continue,
-            };
+            let (description, lnurl_pay_info, lnurl_withdraw_info, conversion_info) =
+                match payment.details {
+                    Some(PaymentDetails::Lightning {
+                        description,
+                        lnurl_pay_info,
+                        lnurl_withdraw_info,
+                        conversion_info,
+                        ..
+                    }) => (
+                        description,
+                        lnurl_pay_info,
+                        lnurl_withdraw_info,
+                        conversion_info,
+                    ),
+                    Some(
+                        PaymentDetails::Spark {
+                            conversion_info, ..
+                        }
+                        | PaymentDetails::Token {
+                            conversion_info, ..
+                        },
+                    ) => (None, None, None, conversion_info),
+                    _ => (None, None, None, None),
+                };
 
+            // Fiat values are synced so that every device shows the value
+            // recorded at settlement
             if lnurl_pay_info.is_none()
                 && lnurl_withdraw_info.is_none()
                 && conversion_info.is_none()
+                && payment.fiat_value.is_none()
             {
                 continue;
             }
@@ -230,6 +287,7 @@ impl SyncedStorage {
                 lnurl_pay_info,
                 lnurl_withdraw_info,
                 conversion_info,
+                fiat_value: payment.fiat_value,
                 ..Default::default()
             };
             let record_id = RecordId::new(RecordType::PaymentMetadata.to_string(), &payment.id);
@@ -268,6 +326,46 @@ impl SyncedStorage {
             error!("Failed to push lightning address sync signal: {e:?}");
         }
     }
+
+    async fn push_device_sync(&self, name: Option<String>) {
+        let result: anyhow::Result<()> = async {
+            let device_id = ObjectCacheRepository::new(Arc::clone(&self.inner))
+                .fetch_or_create_device_id()
+                .await?;
+            let device = SyncedDevice {
+                name,
+                last_seen_at: clock_now(self.clock.as_ref()),
+            };
+            self.sync_service
+                .set_outgoing_record(&RecordChangeRequest {
+                    id: RecordId::new(RecordType::Device.to_string(), &device_id),
+                    schema_version: RecordType::Device.schema_version(),
+                    updated_fields: serde_json::from_value(serde_json::to_value(&device)?)?,
+                })
+                .await
+        }
+        .await;
+        if let Err(e) = result {
+            error!("Failed to push device sync: {e:?}");
+        }
+    }
+
+    async fn push_user_settings_sync(&self, display_currency: Option<String>) {
+        let result: anyhow::Result<()> = async {
+            let settings = UserSettingsSyncData { display_currency };
+            self.sync_service
+                .set_outgoing_record(&RecordChangeRequest {
+                    id: RecordId::new(RecordType::UserSettings.to_string(), USER_SETTINGS_DATA_ID),
+                    schema_version: RecordType::UserSettings.schema_version(),
+                    updated_fields: serde_json::from_value(serde_json::to_value(&settings)?)?,
+                })
+                .await
+        }
+        .await;
+        if let Err(e) = result {
+            error!("Failed to push user settings sync: {e:?}");
+        }
+    }
 }
 
 impl SyncedRecordHandler {
@@ -309,6 +407,12 @@ impl SyncedRecordHandler {
             return Ok(RecordOutcome::Deferred);
         }
 
+        let conflict = (!change.overridden_fields.is_empty()).then(|| SyncConflict {
+            record_type: (&record_type).into(),
+            record_id: change.new_state.id.data_id.clone(),
+            kept_local_fields: change.overridden_fields.clone(),
+        });
+
         match record_type {
             RecordType::PaymentMetadata => {
                 self.handle_payment_metadata_update(
@@ -328,7 +432,25 @@ impl SyncedRecordHandler {
                 self.handle_cross_chain_swap_change(change.new_state.data)
                     .await
             }
+            RecordType::Device => {
+                self.handle_device_change(change.new_state.data, &change.new_state.id.data_id)
+                    .await
+            }
+            RecordType::UserSettings => {
+                self.handle_user_settings_change(change.new_state.data)
+                    .await
+            }
         }?;
+
+        if let Some(conflict) = conflict {
+            info!(
+                "Kept local values of {:?} over the incoming {} record {}",
+                conflict.kept_local_fields, change.new_state.id.r#type, conflict.record_id
+            );
+            self.event_emitter
+                .emit(&SdkEvent::SyncConflictResolved { conflict })
+                .await;
+        }
         Ok(RecordOutcome::Completed)
     }
 
@@ -362,7 +484,7 @@ impl SyncedRecordHandler {
                 self.handle_contact_change(change.change.updated_fields, change.change.id.data_id)
                     .await
             }
-            RecordType::LightningAddress => Ok(()),
+            RecordType::LightningAddress | RecordType::Device | RecordType::UserSettings => Ok(()),
             RecordType::CrossChainSwap => {
                 self.handle_cross_chain_swap_change(change.change.updated_fields)
                     .await
@@ -387,6 +509,39 @@ impl SyncedRecordHandler {
         Ok(())
     }
 
+    async fn handle_device_change(
+        &self,
+        fields: HashMap<String, Value>,
+        device_id: &str,
+    ) -> anyhow::Result<()> {
+        let device: SyncedDevice = serde_json::from_value(
+            serde_json::to_value(&fields)
+                .map_err(|e| StorageError::Serialization(e.to_string()))?,
+        )
+        .map_err(|e| StorageError::Serialization(e.to_string()))?;
+        ObjectCacheRepository::new(Arc::clone(&self.storage))
+            .save_synced_device(device_id, &device)
+            .await?;
+        Ok(())
+    }
+
+    async fn handle_user_settings_change(
+        &self,
+        fields: HashMap<String, Value>,
+    ) -> anyhow::Result<()> {
+        let settings: UserSettingsSyncData = serde_json::from_value(
+            serde_json::to_value(&fields)
+                .map_err(|e| StorageError::Serialization(e.to_string()))?,
+        )
+        .map_err(|e| StorageError::Serialization(e.to_string()))?;
+        let cache = ObjectCacheRepository::new(Arc::clone(&self.storage));
+        match settings.display_currency {
+            Some(currency) => cache.save_display_currency(&currency).await?,
+            None => cache.delete_display_currency().await?,
+        }
+        Ok(())
+    }
+
     async fn handle_contact_change(
         &self,
         fields: HashMap<String, Value>,
@@ -408,10 +563,16 @@ impl SyncedRecordHandler {
             id: data_id,
             name: sync_data.name,
             payment_identifier: sync_data.payment_identifier,
+            additional_payment_identifiers: sync_data.additional_payment_identifiers,
+            avatar_url: sync_data.avatar_url,
+            notes: sync_data.notes,
             created_at: sync_data.created_at,
             updated_at: sync_data.updated_at,
         };
-        self.storage.insert_contact(contact).await?;
+        // Two devices adding the same address before syncing create two
+        // records. Both devices merge them the same way, so there's nothing
+        // to push back.
+        save_contact_merging_duplicates(self.storage.as_ref(), contact).await?;
 
         Ok(())
     }
@@ -508,6 +669,9 @@ impl SyncedRecordHandler {
 #[macros::async_trait]
 impl Storage for SyncedStorage {
     async fn delete_cached_item(&self, key: String) -> Result<(), StorageError> {
+        if key == DISPLAY_CURRENCY_KEY {
+            self.push_user_settings_sync(None).await;
+        }
         self.inner.delete_cached_item(key).await
     }
     async fn get_cached_item(&self, key: String) -> Result<Option<String>, StorageError> {
@@ -520,6 +684,12 @@ impl Storage for SyncedStorage {
         {
             self.push_lightning_address_sync().await;
         }
+        if key == DEVICE_NAME_KEY {
+            self.push_device_sync(Some(value.clone())).await;
+        }
+        if key == DISPLAY_CURRENCY_KEY {
+            self.push_user_settings_sync(Some(value.clone())).await;
+        }
         self.inner.set_cached_item(key, value).await
     }
     async fn list_payments(
@@ -628,6 +798,9 @@ impl Storage for SyncedStorage {
             id: contact.id.clone(),
             name: contact.name.clone(),
             payment_identifier: contact.payment_identifier.clone(),
+            additional_payment_identifiers: contact.additional_payment_identifiers.clone(),
+            avatar_url: contact.avatar_url.clone(),
+            notes: contact.notes.clone(),
             created_at: contact.created_at,
             updated_at: contact.updated_at,
             deleted_at: None,
@@ -648,9 +821,7 @@ impl Storage for SyncedStorage {
     }
 
     async fn delete_contact(&self, id: String) -> Result<(), StorageError> {
-        let now = platform_utils::time::SystemTime::now()
-            .duration_since(platform_utils::time::UNIX_EPOCH)
-            .map_or(0, |d| d.as_secs());
+        let now = clock_now(self.clock.as_ref());
         let mut updated_fields = HashMap::new();
         updated_fields.insert(DELETED_AT_FIELD.to_string(), serde_json::json!(now));
         self.sync_service
@@ -743,6 +914,10 @@ impl Storage for SyncedStorage {
     async fn update_record_from_incoming(&self, record: Record) -> Result<(), StorageError> {
         self.inner.update_record_from_incoming(record).await
     }
+
+    async fn delete_all_data(&self) -> Result<(), StorageError> {
+        self.inner.delete_all_data().await
+    }
 }
 
 #[cfg(all(test, feature = "sqlite"))]
@@ -766,7 +941,7 @@ mod tests {
             crate::sync_storage::SyncStorageWrapper::new(Arc::clone(&storage)),
         );
         let sync_service = Arc::new(SyncService::new(sync_storage));
-        SyncedStorage::new(storage, sync_service)
+        SyncedStorage::new(storage, sync_service, None)
     }
 
     fn create_test_record_handler(storage: Arc<dyn Storage>) -> SyncedRecordHandler {
@@ -788,6 +963,7 @@ mod tests {
                 data,
             },
             old_state: None,
+            overridden_fields: Vec::new(),
         }
     }
 
@@ -833,6 +1009,8 @@ mod tests {
                 conversion_info: None,
             }),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -1123,6 +1301,45 @@ mod tests {
         assert_eq!(contact.payment_identifier, "alice@example.com");
     }
 
+    #[tokio::test]
+    async fn test_incoming_duplicate_contact_is_merged() {
+        let temp_dir = create_temp_dir("incoming_contact_merge");
+        let storage: Arc<dyn Storage> = Arc::new(SqliteStorage::new(&temp_dir).unwrap());
+        let handler = create_test_record_handler(Arc::clone(&storage));
+
+        // The same address, added on this device before syncing
+        storage
+            .insert_contact(Contact {
+                id: "c2".to_string(),
+                name: "Al".to_string(),
+                payment_identifier: "Alice@example.com".to_string(),
+                additional_payment_identifiers: Vec::new(),
+                avatar_url: None,
+                notes: None,
+                created_at: 500,
+                updated_at: 500,
+            })
+            .await
+            .unwrap();
+
+        let data = make_contact_data("Alice", "alice@example.com");
+        let change =
+            make_incoming_change("Contact", "c1", RecordType::Contact.schema_version(), data);
+        handler.handle_incoming_change(change).await.unwrap();
+
+        let contacts = storage
+            .list_contacts(ListContactsRequest {
+                offset: None,
+                limit: None,
+            })
+            .await
+            .unwrap();
+        assert_eq!(contacts.len(), 1);
+        assert_eq!(contacts[0].id, "c1");
+        assert_eq!(contacts[0].name, "Alice");
+        assert_eq!(contacts[0].created_at, 500);
+    }
+
     #[tokio::test]
     async fn test_incoming_contact_with_deleted_at_deletes() {
         let temp_dir = create_temp_dir("incoming_contact_delete");
@@ -1135,6 +1352,9 @@ mod tests {
                 id: "c1".to_string(),
                 name: "Alice".to_string(),
                 payment_identifier: "alice@example.com".to_string(),
+                additional_payment_identifiers: Vec::new(),
+                avatar_url: None,
+                notes: None,
                 created_at: 1000,
                 updated_at: 1000,
             })
@@ -1181,6 +1401,9 @@ mod tests {
                 id: "c3".to_string(),
                 name: "Charlie".to_string(),
                 payment_identifier: "charlie@example.com".to_string(),
+                additional_payment_identifiers: Vec::new(),
+                avatar_url: None,
+                notes: None,
                 created_at: 1000,
                 updated_at: 1000,
             })
@@ -1301,6 +1524,7 @@ mod tests {
             username: "test".to_string(),
             description: "Test".to_string(),
             lnurl: crate::LnurlInfo::new("https://example.com/.well-known/lnurlp/test".to_string()),
+            label: None,
         };
         cache.save_lightning_address(&address, false).await.unwrap();
 
@@ -1322,6 +1546,7 @@ mod tests {
             username: "test".to_string(),
             description: "Test".to_string(),
             lnurl: crate::LnurlInfo::new("https://example.com/.well-known/lnurlp/test".to_string()),
+            label: None,
         };
         cache.save_lightning_address(&address, true).await.unwrap();
 
@@ -1353,4 +1578,106 @@ mod tests {
 
         assert_eq!(lightning_address_outgoing_count(&storage).await, 0);
     }
+
+    #[tokio::test]
+    async fn test_set_device_name_triggers_device_sync() {
+        let temp_dir = create_temp_dir("device_name_sync");
+        let storage: Arc<dyn Storage> = Arc::new(SqliteStorage::new(&temp_dir).unwrap());
+        let synced = create_test_synced_storage(Arc::clone(&storage));
+
+        let cache = ObjectCacheRepository::new(Arc::new(synced) as Arc<dyn Storage>);
+        cache.save_device_name("Laptop").await.unwrap();
+
+        let device_id = cache.fetch_or_create_device_id().await.unwrap();
+        let changes = storage.get_pending_outgoing_changes(100).await.unwrap();
+        let device_change = changes
+            .iter()
+            .find(|c| c.change.id.r#type == RecordType::Device.to_string())
+            .unwrap();
+        assert_eq!(device_change.change.id.data_id, device_id);
+        assert_eq!(
+            device_change.change.updated_fields.get("name"),
+            Some(&"\"Laptop\"".to_string())
+        );
+    }
+
+    #[tokio::test]
+    async fn test_incoming_device_is_saved() {
+        let temp_dir = create_temp_dir("incoming_device");
+        let storage: Arc<dyn Storage> = Arc::new(SqliteStorage::new(&temp_dir).unwrap());
+        let handler = create_test_record_handler(Arc::clone(&storage));
+
+        let mut data = HashMap::new();
+        data.insert("name".to_string(), serde_json::json!("Phone"));
+        data.insert("last_seen_at".to_string(), serde_json::json!(1000));
+        let change =
+            make_incoming_change("Device", "d1", RecordType::Device.schema_version(), data);
+        let result = handler.handle_incoming_change(change).await;
+        assert_eq!(result.unwrap(), RecordOutcome::Completed);
+
+        let devices = ObjectCacheRepository::new(storage)
+            .fetch_synced_devices()
+            .await
+            .unwrap();
+        let device = devices.get("d1").unwrap();
+        assert_eq!(device.name.as_deref(), Some("Phone"));
+        assert_eq!(device.last_seen_at, 1000);
+    }
+
+    #[tokio::test]
+    async fn test_set_display_currency_triggers_user_settings_sync() {
+        let temp_dir = create_temp_dir("display_currency_sync");
+        let storage: Arc<dyn Storage> = Arc::new(SqliteStorage::new(&temp_dir).unwrap());
+        let synced = create_test_synced_storage(Arc::clone(&storage));
+
+        let cache = ObjectCacheRepository::new(Arc::new(synced) as Arc<dyn Storage>);
+        cache.save_display_currency("EUR").await.unwrap();
+
+        let changes = storage.get_pending_outgoing_changes(100).await.unwrap();
+        let settings_change = changes
+            .iter()
+            .find(|c| c.change.id.r#type == RecordType::UserSettings.to_string())
+            .unwrap();
+        assert_eq!(
+            settings_change
+                .change
+                .updated_fields
+                .get("display_currency"),
+            Some(&"\"EUR\"".to_string())
+        );
+    }
+
+    #[tokio::test]
+    async fn test_incoming_user_settings_are_applied() {
+        let temp_dir = create_temp_dir("incoming_user_settings");
+        let storage: Arc<dyn Storage> = Arc::new(SqliteStorage::new(&temp_dir).unwrap());
+        let handler = create_test_record_handler(Arc::clone(&storage));
+        let cache = ObjectCacheRepository::new(Arc::clone(&storage));
+
+        let mut data = HashMap::new();
+        data.insert("display_currency".to_string(), serde_json::json!("USD"));
+        let change = make_incoming_change(
+            "UserSettings",
+            "current",
+            RecordType::UserSettings.schema_version(),
+            data,
+        );
+        let result = handler.handle_incoming_change(change).await;
+        assert_eq!(result.unwrap(), RecordOutcome::Completed);
+        assert_eq!(
+            cache.fetch_display_currency().await.unwrap().as_deref(),
+            Some("USD")
+        );
+
+        let mut data = HashMap::new();
+        data.insert("display_currency".to_string(), Value::Null);
+        let change = make_incoming_change(
+            "UserSettings",
+            "current",
+            RecordType::UserSettings.schema_version(),
+            data,
+        );
+        handler.handle_incoming_change(change).await.unwrap();
+        assert_eq!(cache.fetch_display_currency().await.unwrap(), None);
+    }
 }
@@ -0,0 +1,396 @@
+//! Embedded WebSocket RPC service for server deployments.
+//!
+//! Lets processes in the same deployment subscribe to the SDK's events and call
+//! a read-and-receive subset of the wallet API over a single WebSocket, without
+//! running a second SDK instance. Spending calls are deliberately not exposed,
+//! and the service is meant to be bound to a loopback or private address.
+//!
+//! Connections must authenticate with the access token set with the service,
+//! as an `Authorization: Bearer <token>` header. Browsers can't set that
+//! header on a WebSocket, and upgrades carrying an `Origin` other than the
+//! service's own are rejected as well, so a web page can't reach the service
+//! through the user's browser.
+//!
+//! Every frame is a JSON text message. Events are pushed as
+//! `{"event": <SdkEvent>}`. Calls are sent as
+//! `{"id": <any>, "method": "<name>", "params": {...}}` and answered with
+//! `{"id": <same>, "result": ...}` or `{"id": <same>, "error": "<message>"}`.
+
+use std::sync::Arc;
+
+use axum::{
+    Router,
+    extract::{
+        State,
+        ws::{Message, WebSocket, WebSocketUpgrade},
+    },
+    http::{HeaderMap, StatusCode, header},
+    response::{IntoResponse, Response},
+    routing::get,
+};
+use serde::{Deserialize, Serialize, de::DeserializeOwned};
+use serde_json::Value;
+use tokio::{
+    net::TcpListener,
+    sync::{mpsc, watch},
+};
+use tracing::{debug, error, info, warn};
+
+use crate::{
+    BreezSdk, GetInfoRequest, GetPaymentRequest, ListPaymentsRequest, ReceivePaymentMethod,
+    ReceivePaymentRequest,
+    error::SdkError,
+    events::{EventListener, SdkEvent},
+};
+
+/// Events buffered per connection before slow subscribers start losing them.
+const EVENT_BUFFER_SIZE: usize = 256;
+
+/// Binds the listening socket, so an unusable address or token fails `build()`
+/// rather than the background task.
+pub(crate) async fn bind(addr: &str, token: &str) -> Result<TcpListener, SdkError> {
+    if token.is_empty() {
+        return Err(SdkError::InvalidInput(
+            "RPC server access token must not be empty".to_string(),
+        ));
+    }
+    TcpListener::bind(addr)
+        .await
+        .map_err(|e| SdkError::Generic(format!("Failed to bind RPC server to {addr}: {e}")))
+}
+
+#[derive(Clone)]
+struct RpcState {
+    sdk: BreezSdk,
+    token: Arc<String>,
+}
+
+/// Serves the RPC service on `listener` until the SDK shuts down, accepting
+/// connections authenticated with `token`.
+pub(crate) fn start(
+    sdk: BreezSdk,
+    listener: TcpListener,
+    token: String,
+    mut shutdown_receiver: watch::Receiver<()>,
+) {
+    let state = RpcState {
+        sdk,
+        token: Arc::new(token),
+    };
+    let app = Router::new().route("/ws", get(upgrade)).with_state(state);
+    tokio::spawn(async move {
+        if let Ok(addr) = listener.local_addr() {
+            info!("RPC server listening on ws://{addr}/ws");
+        }
+        let shutdown = async move {
+            let _ = shutdown_receiver.changed().await;
+        };
+        if let Err(e) = axum::serve(listener, app)
+            .with_graceful_shutdown(shutdown)
+            .await
+        {
+            error!("RPC server stopped: {e}");
+        }
+        debug!("RPC server shut down");
+    });
+}
+
+async fn upgrade(
+    State(state): State<RpcState>,
+    headers: HeaderMap,
+    ws: WebSocketUpgrade,
+) -> Response {
+    if is_foreign_origin(&headers) {
+        warn!("Rejected RPC connection from a foreign origin");
+        return StatusCode::FORBIDDEN.into_response();
+    }
+    if !is_authorized(&headers, &state.token) {
+        warn!("Rejected unauthenticated RPC connection");
+        return StatusCode::UNAUTHORIZED.into_response();
+    }
+    let sdk = state.sdk;
+    ws.on_upgrade(move |socket| handle_socket(sdk, socket))
+}
+
+/// Whether the request carries the access token as a bearer token.
+fn is_authorized(headers: &HeaderMap, token: &str) -> bool {
+    let Some(provided) = headers
+        .get(header::AUTHORIZATION)
+        .and_then(|value| value.to_str().ok())
+        .and_then(|value| value.strip_prefix("Bearer "))
+    else {
+        return false;
+    };
+    // Compared in constant time, so the token can't be guessed byte by byte
+    provided.len() == token.len()
+        && provided
+            .bytes()
+            .zip(token.bytes())
+            .fold(0u8, |diff, (a, b)| diff | (a ^ b))
+            == 0
+}
+
+/// Whether the request was made by a page of another origin. Clients other
+/// than browsers don't send an `Origin`.
+fn is_foreign_origin(headers: &HeaderMap) -> bool {
+    let Some(origin) = headers.get(header::ORIGIN) else {
+        return false;
+    };
+    let origin_host = origin.to_str().ok().and_then(|origin| {
+        origin
+            .strip_prefix("http://")
+            .or_else(|| origin.strip_prefix("https://"))
+    });
+    let host = headers
+        .get(header::HOST)
+        .and_then(|host| host.to_str().ok());
+    match (origin_host, host) {
+        (Some(origin_host), Some(host)) => !origin_host.eq_ignore_ascii_case(host),
+        _ => true,
+    }
+}
+
+struct EventForwarder {
+    sender: mpsc::Sender<SdkEvent>,
+}
+
+#[macros::async_trait]
+impl EventListener for EventForwarder {
+    async fn on_event(&self, event: SdkEvent) {
+        if self.sender.try_send(event).is_err() {
+            warn!("RPC subscriber is lagging, dropping event");
+        }
+    }
+}
+
+async fn handle_socket(sdk: BreezSdk, mut socket: WebSocket) {
+    let (sender, mut events) = mpsc::channel(EVENT_BUFFER_SIZE);
+    let listener_id = sdk
+        .add_event_listener(Box::new(EventForwarder { sender }))
+        .await;
+
+    loop {
+        let outgoing = tokio::select! {
+            // The SDK drops its listeners on disconnect, closing the channel
+            event = events.recv() => match event {
+                Some(event) => serde_json::json!({ "event": event }),
+                None => break,
+            },
+            message = socket.recv() => match message {
+                Some(Ok(Message::Text(text))) => handle_call(&sdk, &text).await,
+                Some(Ok(Message::Close(_)) | Err(_)) | None => break,
+                Some(Ok(_)) => continue,
+            },
+        };
+        if socket
+            .send(Message::Text(outgoing.to_string().into()))
+            .await
+            .is_err()
+        {
+            break;
+        }
+    }
+
+    sdk.remove_event_listener(&listener_id).await;
+}
+
+#[derive(Deserialize)]
+struct RpcCall {
+    #[serde(default)]
+    id: Value,
+    method: String,
+    #[serde(default)]
+    params: Value,
+}
+
+#[derive(Deserialize)]
+struct ListPaymentsParams {
+    offset: Option<u32>,
+    limit: Option<u32>,
+}
+
+#[derive(Deserialize)]
+struct GetPaymentParams {
+    payment_id: String,
+}
+
+#[derive(Deserialize)]
+struct ReceiveBolt11Params {
+    description: String,
+    amount_sats: Option<u64>,
+    expiry_secs: Option<u32>,
+}
+
+async fn handle_call(sdk: &BreezSdk, text: &str) -> Value {
+    let call: RpcCall = match serde_json::from_str(text) {
+        Ok(call) => call,
+        Err(e) => return serde_json::json!({ "id": Value::Null, "error": e.to_string() }),
+    };
+    match dispatch(sdk, &call.method, call.params).await {
+        Ok(result) => serde_json::json!({ "id": call.id, "result": result }),
+        Err(e) => serde_json::json!({ "id": call.id, "error": e.to_string() }),
+    }
+}
+
+async fn dispatch(sdk: &BreezSdk, method: &str, params: Value) -> Result<Value, SdkError> {
+    match method {
+        "get_info" => to_value(
+            sdk.get_info(GetInfoRequest {
+                ensure_synced: None,
+                include_hidden: None,
+            })
+            .await?,
+        ),
+        "list_payments" => {
+            let params: ListPaymentsParams = from_params(params)?;
+            to_value(
+                sdk.list_payments(ListPaymentsRequest {
+                    offset: params.offset,
+                    limit: params.limit,
+                    ..Default::default()
+                })
+                .await?,
+            )
+        }
+        "get_payment" => {
+            let params: GetPaymentParams = from_params(params)?;
+            to_value(
+                sdk.get_payment(GetPaymentRequest {
+                    payment_id: params.payment_id,
+                })
+                .await?,
+            )
+        }
+        "receive_spark_address" => receive(sdk, ReceivePaymentMethod::SparkAddress).await,
+        "receive_bitcoin_address" => {
+            receive(
+                sdk,
+                ReceivePaymentMethod::BitcoinAddress { new_address: None },
+            )
+            .await
+        }
+        "receive_bolt11_invoice" => {
+            let params: ReceiveBolt11Params = from_params(params)?;
+            receive(
+                sdk,
+                ReceivePaymentMethod::Bolt11Invoice {
+                    description: params.description,
+                    amount_sats: params.amount_sats,
+                    expiry_secs: params.expiry_secs,
+                    payment_hash: None,
+                },
+            )
+            .await
+        }
+        _ => Err(SdkError::InvalidInput(format!("Unknown method: {method}"))),
+    }
+}
+
+async fn receive(sdk: &BreezSdk, payment_method: ReceivePaymentMethod) -> Result<Value, SdkError> {
+    to_value(
+        sdk.receive_payment(ReceivePaymentRequest { payment_method })
+            .await?,
+    )
+}
+
+/// Parses call params, treating omitted params as an empty object.
+fn from_params<T: DeserializeOwned>(params: Value) -> Result<T, SdkError> {
+    let params = if params.is_null() {
+        Value::Object(serde_json::Map::new())
+    } else {
+        params
+    };
+    serde_json::from_value(params)
+        .map_err(|e| SdkError::InvalidInput(format!("Invalid params: {e}")))
+}
+
+fn to_value<T: Serialize>(value: T) -> Result<Value, SdkError> {
+    serde_json::to_value(value).map_err(|e| SdkError::Generic(e.to_string()))
+}
+
+#[cfg(test)]
+mod tests {
+    use serde_json::json;
+
+    use super::*;
+
+    fn headers(pairs: &[(header::HeaderName, &str)]) -> HeaderMap {
+        let mut headers = HeaderMap::new();
+        for (name, value) in pairs {
+            headers.insert(name.clone(), value.parse().unwrap());
+        }
+        headers
+    }
+
+    #[test]
+    fn requires_bearer_token() {
+        let token = "secret";
+        assert!(is_authorized(
+            &headers(&[(header::AUTHORIZATION, "Bearer secret")]),
+            token
+        ));
+        assert!(!is_authorized(
+            &headers(&[(header::AUTHORIZATION, "Bearer secreT")]),
+            token
+        ));
+        assert!(!is_authorized(
+            &headers(&[(header::AUTHORIZATION, "Bearer secret2")]),
+            token
+        ));
+        assert!(!is_authorized(
+            &headers(&[(header::AUTHORIZATION, "secret")]),
+            token
+        ));
+        assert!(!is_authorized(&headers(&[]), token));
+    }
+
+    #[test]
+    fn rejects_foreign_origins() {
+        let host = (header::HOST, "127.0.0.1:9737");
+        assert!(!is_foreign_origin(&headers(&[host.clone()])));
+        assert!(!is_foreign_origin(&headers(&[
+            host.clone(),
+            (header::ORIGIN, "http://127.0.0.1:9737"),
+        ])));
+        assert!(is_foreign_origin(&headers(&[
+            host.clone(),
+            (header::ORIGIN, "https://evil.example"),
+        ])));
+        assert!(is_foreign_origin(&headers(&[
+            host,
+            (header::ORIGIN, "null"),
+        ])));
+        assert!(is_foreign_origin(&headers(&[(
+            header::ORIGIN,
+            "http://127.0.0.1:9737"
+        )])));
+    }
+
+    #[test]
+    fn parses_call_without_params() {
+        let call: RpcCall = serde_json::from_str(r#"{"id":1,"method":"get_info"}"#).unwrap();
+        assert_eq!(call.id, json!(1));
+        assert_eq!(call.method, "get_info");
+        assert_eq!(call.params, Value::Null);
+    }
+
+    #[test]
+    fn rejects_missing_params() {
+        let result: Result<GetPaymentParams, _> = from_params(json!({}));
+        assert!(matches!(result, Err(SdkError::InvalidInput(_))));
+    }
+
+    #[test]
+    fn omitted_params_leave_optional_fields_unset() {
+        let params: ListPaymentsParams = from_params(Value::Null).unwrap();
+        assert_eq!(params.offset, None);
+        assert_eq!(params.limit, None);
+    }
+
+    #[test]
+    fn parses_partial_params() {
+        let params: ListPaymentsParams = from_params(json!({"limit": 10})).unwrap();
+        assert_eq!(params.offset, None);
+        assert_eq!(params.limit, Some(10));
+    }
+}
@@ -0,0 +1,469 @@
+use std::{collections::BTreeMap, fmt::Write};
+
+use chrono::{DateTime, SecondsFormat, Utc};
+
+use crate::{
+    AccountingAssetTotals, Contact, ExportPaymentsRequest, ExportPaymentsResponse,
+    GetAccountingReportRequest, GetAccountingReportResponse, ListContactsRequest, Payment,
+    PaymentDetails, PaymentExportFormat, PaymentExportRecord, PaymentStatus, PaymentType,
+    error::SdkError,
+};
+
+use super::{BreezSdk, ledger::payment_token_identifier, token_amount::format_base_units};
+
+const CSV_HEADER: &str = "timestamp,payment_id,payment_type,status,method,asset,decimals,amount,fees,fiat_currency,fiat_rate,fiat_amount,label,counterparty";
+const BTC_TICKER: &str = "BTC";
+const BTC_DECIMALS: u32 = 8;
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Exports payments for accounting, with their fees, token amounts, fiat
+    /// value at completion, label and counterparty.
+    ///
+    /// # Arguments
+    ///
+    /// * `request` - The export format and the optional filter of the payments
+    ///
+    /// # Returns
+    ///
+    /// The exported payments in the requested format
+    pub async fn export_payments(
+        &self,
+        request: ExportPaymentsRequest,
+    ) -> Result<ExportPaymentsResponse, SdkError> {
+        let payments = self
+            .list_payments(request.filter.unwrap_or_default())
+            .await?
+            .payments;
+        let contacts = self
+            .storage
+            .list_contacts(ListContactsRequest::default())
+            .await?;
+        let records: Vec<PaymentExportRecord> = payments
+            .into_iter()
+            .map(|p| export_record(p, &contacts))
+            .collect();
+        let data = match request.format {
+            PaymentExportFormat::Csv => records_to_csv(&records),
+            PaymentExportFormat::Json => serde_json::to_string(&records)
+                .map_err(|e| SdkError::Generic(format!("Failed to serialize payments: {e}")))?,
+            PaymentExportFormat::Camt053 => records_to_camt053(&records, self.now()?),
+        };
+        Ok(ExportPaymentsResponse {
+            format: request.format,
+            data,
+        })
+    }
+
+    /// Summarizes the completed payments of a period per asset: the amounts
+    /// received and sent, and the fees paid.
+    ///
+    /// The legs of conversions are included, so a conversion counts as sent
+    /// in one asset and received in the other.
+    pub async fn get_accounting_report(
+        &self,
+        request: GetAccountingReportRequest,
+    ) -> Result<GetAccountingReportResponse, SdkError> {
+        let payments = self
+            .list_ledger_payments()
+            .await?
+            .into_iter()
+            .map(|(p, _)| p)
+            .collect();
+        Ok(build_report(payments, request))
+    }
+}
+
+fn export_record(payment: Payment, contacts: &[Contact]) -> PaymentExportRecord {
+    let (asset_ticker, asset_decimals) = match &payment.details {
+        Some(PaymentDetails::Token { metadata, .. }) => {
+            (metadata.ticker.clone(), metadata.decimals)
+        }
+        _ => (BTC_TICKER.to_string(), BTC_DECIMALS),
+    };
+    PaymentExportRecord {
+        token_identifier: payment_token_identifier(&payment).map(ToString::to_string),
+        label: payment_label(&payment),
+        counterparty: payment_counterparty(&payment, contacts),
+        payment_id: payment.id,
+        timestamp: payment.timestamp,
+        payment_type: payment.payment_type,
+        status: payment.status,
+        method: payment.method,
+        amount: payment.amount,
+        fees: payment.fees,
+        asset_ticker,
+        asset_decimals,
+        fiat_value: payment.fiat_value,
+    }
+}
+
+fn payment_label(payment: &Payment) -> Option<String> {
+    match &payment.details {
+        Some(PaymentDetails::Lightning {
+            description,
+            lnurl_pay_info,
+            ..
+        }) => lnurl_pay_info
+            .as_ref()
+            .and_then(|info| info.comment.clone())
+            .or_else(|| description.clone()),
+        Some(
+            PaymentDetails::Spark {
+                invoice_details: Some(invoice_details),
+                ..
+            }
+            | PaymentDetails::Token {
+                invoice_details: Some(invoice_details),
+                ..
+            },
+        ) => invoice_details.description.clone(),
+        _ => None,
+    }
+}
+
+/// The Lightning address paid, named after the matching contact if any.
+fn payment_counterparty(payment: &Payment, contacts: &[Contact]) -> Option<String> {
+    let Some(PaymentDetails::Lightning {
+        lnurl_pay_info: Some(info),
+        ..
+    }) = &payment.details
+    else {
+        return None;
+    };
+    let ln_address = info.ln_address.as_ref()?;
+    Some(
+        contacts
+            .iter()
+            .find(|c| c.has_payment_identifier(ln_address))
+            .map_or_else(|| ln_address.clone(), |c| c.name.clone()),
+    )
+}
+
+fn build_report(
+    payments: Vec<Payment>,
+    request: GetAccountingReportRequest,
+) -> GetAccountingReportResponse {
+    // `None` sorts first, so Bitcoin comes before the tokens
+    let mut assets: BTreeMap<Option<String>, AccountingAssetTotals> = BTreeMap::new();
+    for payment in payments {
+        if payment.status != PaymentStatus::Completed
+            || request
+                .from_timestamp
+                .is_some_and(|from| payment.timestamp < from)
+            || request
+                .to_timestamp
+                .is_some_and(|to| payment.timestamp >= to)
+        {
+            continue;
+        }
+        let token_identifier = payment_token_identifier(&payment).map(ToString::to_string);
+        let totals =
+            assets
+                .entry(token_identifier.clone())
+                .or_insert_with(|| AccountingAssetTotals {
+                    token_identifier,
+                    total_in: 0,
+                    total_out: 0,
+                    fees_paid: 0,
+                    received_count: 0,
+                    sent_count: 0,
+                });
+        match payment.payment_type {
+            PaymentType::Receive => {
+                totals.total_in = totals.total_in.saturating_add(payment.amount);
+                totals.received_count = totals.received_count.saturating_add(1);
+            }
+            PaymentType::Send => {
+                totals.total_out = totals.total_out.saturating_add(payment.amount);
+                totals.fees_paid = totals.fees_paid.saturating_add(payment.fees);
+                totals.sent_count = totals.sent_count.saturating_add(1);
+            }
+        }
+    }
+
+    GetAccountingReportResponse {
+        from_timestamp: request.from_timestamp,
+        to_timestamp: request.to_timestamp,
+        assets: assets.into_values().collect(),
+    }
+}
+
+/// Quotes a CSV field when it contains a separator, quote or line break.
+fn csv_field(value: &str) -> String {
+    if value.contains([',', '"', '\n', '\r']) {
+        format!("\"{}\"", value.replace('"', "\"\""))
+    } else {
+        value.to_string()
+    }
+}
+
+fn records_to_csv(records: &[PaymentExportRecord]) -> String {
+    let mut csv = String::from(CSV_HEADER);
+    csv.push('\n');
+    for record in records {
+        let (fiat_currency, fiat_rate, fiat_amount) = record
+            .fiat_value
+            .as_ref()
+            .map_or((String::new(), String::new(), String::new()), |f| {
+                (f.currency.clone(), f.rate.to_string(), f.amount.to_string())
+            });
+        let _ = writeln!(
+            csv,
+            "{},{},{},{},{},{},{},{},{},{},{},{},{},{}",
+            record.timestamp,
+            csv_field(&record.payment_id),
+            record.payment_type,
+            record.status,
+            record.method,
+            csv_field(&record.asset_ticker),
+            record.asset_decimals,
+            record.amount,
+            record.fees,
+            csv_field(&fiat_currency),
+            fiat_rate,
+            fiat_amount,
+            csv_field(record.label.as_deref().unwrap_or_default()),
+            csv_field(record.counterparty.as_deref().unwrap_or_default()),
+        );
+    }
+    csv
+}
+
+fn xml_escape(value: &str) -> String {
+    value
+        .replace('&', "&amp;")
+        .replace('<', "&lt;")
+        .replace('>', "&gt;")
+        .replace('"', "&quot;")
+        .replace('\'', "&apos;")
+}
+
+fn iso_date_time(timestamp: u64) -> String {
+    i64::try_from(timestamp)
+        .ok()
+        .and_then(|secs| DateTime::<Utc>::from_timestamp(secs, 0))
+        .unwrap_or_default()
+        .to_rfc3339_opts(SecondsFormat::Secs, true)
+}
+
+/// Builds a CAMT.053 statement with an entry per completed or pending
+/// payment. Assets use their ticker as currency code.
+fn records_to_camt053(records: &[PaymentExportRecord], created_at: u64) -> String {
+    let created_at = iso_date_time(created_at);
+    let mut xml = String::from("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n");
+    xml.push_str("<Document xmlns=\"urn:iso:std:iso:20022:tech:xsd:camt.053.001.08\">\n");
+    xml.push_str("<BkToCstmrStmt>\n");
+    let _ = writeln!(
+        xml,
+        "<GrpHdr><MsgId>payments-{created_at}</MsgId><CreDtTm>{created_at}</CreDtTm></GrpHdr>"
+    );
+    xml.push_str("<Stmt>\n");
+    let _ = writeln!(
+        xml,
+        "<Id>payments-{created_at}</Id><CreDtTm>{created_at}</CreDtTm>"
+    );
+    for record in records {
+        let status = match record.status {
+            PaymentStatus::Completed => "BOOK",
+            PaymentStatus::Pending => "PDNG",
+            PaymentStatus::Failed => continue,
+        };
+        let (indicator, party) = match record.payment_type {
+            PaymentType::Receive => ("CRDT", "Dbtr"),
+            PaymentType::Send => ("DBIT", "Cdtr"),
+        };
+        let currency = xml_escape(&record.asset_ticker);
+        let id = xml_escape(&record.payment_id);
+        xml.push_str("<Ntry>\n");
+        let _ = writeln!(xml, "<NtryRef>{id}</NtryRef>");
+        let _ = writeln!(
+            xml,
+            "<Amt Ccy=\"{currency}\">{}</Amt>",
+            format_base_units(record.amount, record.asset_decimals)
+        );
+        let _ = writeln!(xml, "<CdtDbtInd>{indicator}</CdtDbtInd>");
+        let _ = writeln!(xml, "<Sts><Cd>{status}</Cd></Sts>");
+        let _ = writeln!(
+            xml,
+            "<BookgDt><DtTm>{}</DtTm></BookgDt>",
+            iso_date_time(record.timestamp)
+        );
+        let _ = writeln!(
+            xml,
+            "<BkTxCd><Prtry><Cd>{}</Cd></Prtry></BkTxCd>",
+            record.method
+        );
+        if record.fees > 0 {
+            let _ = writeln!(
+                xml,
+                "<Chrgs><Rcrd><Amt Ccy=\"{currency}\">{}</Amt></Rcrd></Chrgs>",
+                format_base_units(record.fees, record.asset_decimals)
+            );
+        }
+        xml.push_str("<NtryDtls><TxDtls>\n");
+        let _ = writeln!(xml, "<Refs><EndToEndId>{id}</EndToEndId></Refs>");
+        if let Some(fiat_value) = &record.fiat_value {
+            let fiat_currency = xml_escape(&fiat_value.currency);
+            let _ = writeln!(
+                xml,
+                "<AmtDtls><CntrValAmt><Amt Ccy=\"{fiat_currency}\">{:.2}</Amt><CcyXchg><SrcCcy>{currency}</SrcCcy><TrgtCcy>{fiat_currency}</TrgtCcy><XchgRate>{}</XchgRate></CcyXchg></CntrValAmt></AmtDtls>",
+                fiat_value.amount, fiat_value.rate
+            );
+        }
+        if let Some(counterparty) = &record.counterparty {
+            let _ = writeln!(
+                xml,
+                "<RltdPties><{party}><Pty><Nm>{}</Nm></Pty></{party}></RltdPties>",
+                xml_escape(counterparty)
+            );
+        }
+        if let Some(label) = &record.label {
+            let _ = writeln!(xml, "<RmtInf><Ustrd>{}</Ustrd></RmtInf>", xml_escape(label));
+        }
+        xml.push_str("</TxDtls></NtryDtls>\n");
+        xml.push_str("</Ntry>\n");
+    }
+    xml.push_str("</Stmt>\n</BkToCstmrStmt>\n</Document>\n");
+    xml
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::{
+        LnurlPayInfo, PaymentFiatValue, PaymentMethod, SparkHtlcDetails, SparkHtlcStatus,
+        sdk::ledger::tests::payment,
+    };
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn lightning_payment(ln_address: &str, comment: &str) -> Payment {
+        Payment {
+            method: PaymentMethod::Lightning,
+            details: Some(PaymentDetails::Lightning {
+                description: Some("invoice description".to_string()),
+                invoice: "lnbc1".to_string(),
+                destination_pubkey: "pubkey".to_string(),
+                htlc_details: SparkHtlcDetails {
+                    payment_hash: "hash".to_string(),
+                    preimage: None,
+                    expiry_time: 0,
+                    status: SparkHtlcStatus::PreimageShared,
+                },
+                lnurl_pay_info: Some(LnurlPayInfo {
+                    ln_address: Some(ln_address.to_string()),
+                    comment: Some(comment.to_string()),
+                    domain: None,
+                    metadata: None,
+                    processed_success_action: None,
+                    raw_success_action: None,
+                }),
+                lnurl_withdraw_info: None,
+                lnurl_receive_metadata: None,
+                conversion_info: None,
+            }),
+            ..payment("ln", PaymentType::Send, 1_000, 5, 1)
+        }
+    }
+
+    #[test_all]
+    fn test_build_report() {
+        let mut failed = payment("d", PaymentType::Send, 700, 7, 2);
+        failed.status = PaymentStatus::Failed;
+        let payments = vec![
+            payment("a", PaymentType::Receive, 1_000, 0, 1),
+            payment("b", PaymentType::Send, 300, 10, 2),
+            payment("c", PaymentType::Receive, 50, 0, 3),
+            failed,
+        ];
+
+        let report = build_report(
+            payments,
+            GetAccountingReportRequest {
+                from_timestamp: Some(1),
+                to_timestamp: Some(3),
+            },
+        );
+
+        assert_eq!(
+            report.assets,
+            vec![AccountingAssetTotals {
+                token_identifier: None,
+                total_in: 1_000,
+                total_out: 300,
+                fees_paid: 10,
+                received_count: 1,
+                sent_count: 1,
+            }]
+        );
+    }
+
+    #[test_all]
+    fn test_export_record_label_and_counterparty() {
+        let contacts = vec![Contact {
+            id: "1".to_string(),
+            name: "Alice".to_string(),
+            payment_identifier: "alice@example.com".to_string(),
+            additional_payment_identifiers: vec!["alice@other.com".to_string()],
+            avatar_url: None,
+            notes: None,
+            created_at: 0,
+            updated_at: 0,
+        }];
+
+        let record = export_record(lightning_payment("Alice@example.com", "lunch"), &contacts);
+        assert_eq!(record.label.as_deref(), Some("lunch"));
+        assert_eq!(record.counterparty.as_deref(), Some("Alice"));
+        assert_eq!(record.asset_ticker, BTC_TICKER);
+
+        let record = export_record(lightning_payment("Alice@Other.com", "dinner"), &contacts);
+        assert_eq!(record.counterparty.as_deref(), Some("Alice"));
+
+        let record = export_record(lightning_payment("bob@example.com", "rent"), &contacts);
+        assert_eq!(record.counterparty.as_deref(), Some("bob@example.com"));
+    }
+
+    #[test_all]
+    fn test_records_to_csv() {
+        let mut payment = lightning_payment("bob@example.com", "lunch, drinks");
+        payment.fiat_value = Some(PaymentFiatValue {
+            currency: "USD".to_string(),
+            rate: 60_000.0,
+            amount: 0.6,
+            recorded_at: 1,
+        });
+        let records = vec![export_record(payment, &[])];
+
+        let csv = records_to_csv(&records);
+
+        assert_eq!(
+            csv,
+            format!(
+                "{CSV_HEADER}\n1,ln,send,completed,lightning,BTC,8,1000,5,USD,60000,0.6,\"lunch, drinks\",bob@example.com\n"
+            )
+        );
+    }
+
+    #[test_all]
+    fn test_records_to_camt053() {
+        let mut failed = payment("failed", PaymentType::Send, 1, 0, 1);
+        failed.status = PaymentStatus::Failed;
+        let records = vec![
+            export_record(lightning_payment("bob@example.com", "a < b"), &[]),
+            export_record(failed, &[]),
+        ];
+
+        let xml = records_to_camt053(&records, 0);
+
+        assert!(xml.contains("<Amt Ccy=\"BTC\">0.00001</Amt>"));
+        assert!(xml.contains("<CdtDbtInd>DBIT</CdtDbtInd>"));
+        assert!(xml.contains("<Chrgs><Rcrd><Amt Ccy=\"BTC\">0.00000005</Amt></Rcrd></Chrgs>"));
+        assert!(xml.contains("<Cdtr><Pty><Nm>bob@example.com</Nm></Pty></Cdtr>"));
+        assert!(xml.contains("<Ustrd>a &lt; b</Ustrd>"));
+        assert!(!xml.contains("failed"));
+    }
+}
@@ -5,21 +5,24 @@ use tracing::{debug, info};
 
 use crate::{
     BuyBitcoinRequest, BuyBitcoinResponse, CheckMessageRequest, CheckMessageResponse,
-    CrossChainRouteFilter, CrossChainRoutePair, GetTokensMetadataRequest,
-    GetTokensMetadataResponse, InputType, ListFiatCurrenciesResponse, ListFiatRatesResponse,
-    Network, OptimizationMode, OptimizeLeavesRequest, OptimizeLeavesResponse,
-    RegisterWebhookRequest, RegisterWebhookResponse, SignMessageRequest, SignMessageResponse,
-    UnregisterWebhookRequest, UpdateUserSettingsRequest, UserSettings, Webhook,
+    CrossChainRouteFilter, CrossChainRoutePair, FetchHistoricalRatesRequest,
+    FetchHistoricalRatesResponse, GetTokenFiatRatesRequest, GetTokenFiatRatesResponse,
+    GetTokensMetadataRequest, GetTokensMetadataResponse, HostConditions, InputType,
+    ListFiatCurrenciesResponse, ListFiatRatesResponse, ListLeavesResponse, Network,
+    OptimizationMode, OptimizeLeavesRequest, OptimizeLeavesResponse, PaymentMiddleware,
+    PaymentStage, RegisterWebhookRequest, RegisterWebhookResponse, SignMessageRequest,
+    SignMessageResponse, UnregisterWebhookRequest, UpdateUserSettingsRequest, UserSettings,
+    Webhook,
     chain::RecommendedFees,
     error::SdkError,
     events::EventListener,
     issuer::TokenIssuer,
-    models::{GetInfoRequest, GetInfoResponse, StableBalanceActiveLabel},
+    models::{DisplayCurrency, GetInfoRequest, GetInfoResponse, StableBalanceActiveLabel},
     persist::ObjectCacheRepository,
     utils::token::get_tokens_metadata_cached_or_query,
 };
 
-use super::{BreezSdk, helpers::get_deposit_address, parse_input};
+use super::{BreezSdk, helpers::get_deposit_address, leaves};
 
 #[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
 #[allow(clippy::needless_pass_by_value)]
@@ -55,13 +58,37 @@ impl BreezSdk {
         self.event_emitter.remove_external_listener(id).await
     }
 
+    /// Registers middleware that runs at each [`PaymentStage`] of the send
+    /// and receive flows
+    ///
+    /// Middleware runs in registration order. The first middleware to reject
+    /// a stage stops the flow, failing the call with
+    /// [`SdkError::PaymentRejected`]. Like event listeners, middleware is held
+    /// until it is removed or until `disconnect` unregisters it.
+    ///
+    /// # Returns
+    ///
+    /// A unique identifier for the middleware, which can be used to remove it later
+    pub async fn add_payment_middleware(&self, middleware: Box<dyn PaymentMiddleware>) -> String {
+        self.payment_middleware.add(middleware).await
+    }
+
+    /// Removes previously registered payment middleware
+    ///
+    /// # Returns
+    ///
+    /// `true` if the middleware was found and removed, `false` otherwise
+    pub async fn remove_payment_middleware(&self, id: &str) -> bool {
+        self.payment_middleware.remove(id).await
+    }
+
     /// Stops the SDK's background tasks
     ///
     /// This method stops the background tasks started by the `start()` method.
     /// It should be called before your application terminates to ensure proper cleanup.
     ///
-    /// It also unregisters all event listeners, so listeners that reference
-    /// the SDK no longer keep it alive after this call.
+    /// It also unregisters all event listeners and payment middleware, so
+    /// listeners that reference the SDK no longer keep it alive after this call.
     ///
     /// # Returns
     ///
@@ -69,6 +96,8 @@ impl BreezSdk {
     pub async fn disconnect(&self) -> Result<(), SdkError> {
         info!("Disconnecting Breez SDK");
         self.event_emitter.clear_external_listeners().await;
+        self.payment_middleware.clear().await;
+        self.stop_plugins().await;
         if self.shutdown_sender.send(()).is_err() {
             // A `watch::Sender::send` error means every receiver has been
             // dropped, i.e. no background task is listening. This is the
@@ -85,7 +114,24 @@ impl BreezSdk {
     }
 
     pub async fn parse(&self, input: &str) -> Result<InputType, SdkError> {
-        parse_input(input, Some(self.external_input_parsers.clone())).await
+        self.parse_with_input_parsers(input).await
+    }
+
+    /// Parses the input without any network request, for scanning flows that
+    /// must never block.
+    ///
+    /// BIP-21 URIs, BOLT11 invoices, BOLT12 offers, Spark addresses and
+    /// invoices and bitcoin addresses are parsed locally. Lightning addresses
+    /// and LNURLs are only returned if they were resolved by a recent
+    /// [`BreezSdk::parse`], see [`Config::parse_cache_ttl_secs`](crate::Config::parse_cache_ttl_secs),
+    /// and otherwise fail with an error. Use [`BreezSdk::parse`] to resolve
+    /// them.
+    pub fn parse_offline(&self, input: &str) -> Result<InputType, SdkError> {
+        let input = input.trim();
+        if let Some(input_type) = self.parse_cache.get(input) {
+            return Ok(input_type);
+        }
+        Ok(breez_sdk_common::input::parse_offline(input)?.into())
     }
 
     /// Returns the available cross-chain routes.
@@ -121,7 +167,16 @@ impl BreezSdk {
     /// Returns the balance of the wallet in satoshis
     #[allow(unused_variables)]
     pub async fn get_info(&self, request: GetInfoRequest) -> Result<GetInfoResponse, SdkError> {
-        self.runtime.get_info(self, request).await
+        let include_hidden = request.include_hidden.unwrap_or_default();
+        let mut info = self.runtime.get_info(self, request).await?;
+        self.apply_token_visibility(&mut info, include_hidden)
+            .await?;
+        if let Some(dust_config) = &self.config.dust_config
+            && dust_config.suppress_dust_balances
+        {
+            dust_config.suppress_dust_balances(&mut info);
+        }
+        Ok(info)
     }
 
     /// List fiat currencies for which there is a known exchange rate,
@@ -149,6 +204,56 @@ impl BreezSdk {
         Ok(ListFiatRatesResponse { rates })
     }
 
+    /// Fetch the rates of a fiat currency over a time range, sorted by
+    /// timestamp. Requires a fiat service that supports historical rates.
+    pub async fn fetch_historical_rates(
+        &self,
+        request: FetchHistoricalRatesRequest,
+    ) -> Result<FetchHistoricalRatesResponse, SdkError> {
+        if request.from_timestamp > request.to_timestamp {
+            return Err(SdkError::InvalidInput(
+                "from_timestamp must not be after to_timestamp".to_string(),
+            ));
+        }
+        let rates = self
+            .fiat_service
+            .fetch_historical_rates(
+                request.currency,
+                request.from_timestamp,
+                request.to_timestamp,
+                request.resolution.into(),
+            )
+            .await?
+            .into_iter()
+            .map(From::from)
+            .collect();
+        Ok(FetchHistoricalRatesResponse { rates })
+    }
+
+    /// Fetch the live fiat rates of tokens from the token price service, in
+    /// fiat units per whole token. Tokens without a known price are left out.
+    pub async fn get_token_fiat_rates(
+        &self,
+        request: GetTokenFiatRatesRequest,
+    ) -> Result<GetTokenFiatRatesResponse, SdkError> {
+        let tokens = get_tokens_metadata_cached_or_query(
+            &self.spark_wallet,
+            &ObjectCacheRepository::new(self.storage.clone()),
+            &request
+                .token_identifiers
+                .iter()
+                .map(String::as_str)
+                .collect::<Vec<_>>(),
+        )
+        .await?;
+        let rates = self
+            .token_price_service
+            .fetch_token_fiat_rates(tokens)
+            .await
+            .map_err(|e| SdkError::NetworkError(e.to_string()))?;
+        Ok(GetTokenFiatRatesResponse { rates })
+    }
+
     /// Get the recommended BTC fees based on the configured chain service.
     pub async fn recommended_fees(&self) -> Result<RecommendedFees, SdkError> {
         Ok(self.chain_service.recommended_fees().await?)
@@ -240,10 +345,14 @@ impl BreezSdk {
             Some(sb) => sb.get_active_label().await,
             None => None,
         };
+        let display_currency = ObjectCacheRepository::new(self.storage.clone())
+            .fetch_display_currency()
+            .await?;
 
         Ok(UserSettings {
             spark_private_mode_enabled: spark_user_settings.private_enabled,
             stable_balance_active_label,
+            display_currency,
         })
     }
 
@@ -273,12 +382,31 @@ impl BreezSdk {
             sb.set_active_token(label).await?;
         }
 
+        if let Some(display_currency) = request.display_currency {
+            let cache = ObjectCacheRepository::new(self.storage.clone());
+            match display_currency {
+                DisplayCurrency::Set { currency } => {
+                    // Fails for currencies without a rate to record values at
+                    self.live_fiat_rate(&currency).await?;
+                    cache.save_display_currency(&currency).await?;
+                }
+                DisplayCurrency::Unset => cache.delete_display_currency().await?,
+            }
+        }
+
         Ok(())
     }
 
     /// Returns an instance of the [`TokenIssuer`] for managing token issuance.
     pub fn get_token_issuer(&self) -> TokenIssuer {
-        TokenIssuer::new(self.spark_wallet.clone(), self.storage.clone())
+        TokenIssuer::new(
+            self.spark_wallet.clone(),
+            self.storage.clone(),
+            self.event_emitter.clone(),
+            self.issuer_approver.clone(),
+            self.spending_caps.clone(),
+            self.clock.clone(),
+        )
     }
 
     /// Manually drives leaf optimization, blocking until the requested work
@@ -294,7 +422,9 @@ impl BreezSdk {
     /// Returns an error if another optimization run (auto or manual) is
     /// already in flight ([`SdkError::OptimizationAlreadyRunning`]), or if
     /// the SDK preempted this run to free leaves for a payment
-    /// ([`SdkError::OptimizationCancelled`]).
+    /// ([`SdkError::OptimizationCancelled`]). Fails with
+    /// [`SdkError::WalletFrozen`] while the wallet is frozen, as the swap
+    /// moves leaves out of the wallet.
     ///
     /// Manual runs do not emit events; events ([`SdkEvent::AutoOptimization`])
     /// are reserved for the background auto-optimizer.
@@ -302,6 +432,7 @@ impl BreezSdk {
         &self,
         request: OptimizeLeavesRequest,
     ) -> Result<OptimizeLeavesResponse, SdkError> {
+        self.ensure_not_frozen().await?;
         let max_rounds = match request.mode {
             OptimizationMode::Full => None,
             OptimizationMode::SingleRound => Some(1),
@@ -310,6 +441,26 @@ impl BreezSdk {
         Ok(OptimizeLeavesResponse { outcome })
     }
 
+    /// Lists the leaves held by the wallet, largest first, together with
+    /// their state and aggregate statistics.
+    ///
+    /// Useful to understand why a payment could not be routed or whether
+    /// the wallet would benefit from [`BreezSdk::optimize_leaves`]. The age
+    /// of a leaf is only known once it has been seen by a sync.
+    pub async fn list_leaves(&self) -> Result<ListLeavesResponse, SdkError> {
+        leaves::list_leaves(self).await
+    }
+
+    /// Reports the conditions of the host device that automatic leaf
+    /// optimization is gated on, see
+    /// [`LeafOptimizationConfig::auto_policy`](crate::LeafOptimizationConfig::auto_policy).
+    ///
+    /// Hosts should call this whenever the conditions change, e.g. when the
+    /// device switches between wifi and cellular data.
+    pub async fn set_host_conditions(&self, conditions: HostConditions) {
+        *self.host_conditions.lock().await = conditions;
+    }
+
     /// Registers a webhook to receive notifications for wallet events.
     ///
     /// When registered events occur (e.g., a Lightning payment is received),
@@ -0,0 +1,85 @@
+use bitcoin::bip32::DerivationPath;
+use bitcoin::hashes::Hash;
+use bitcoin::secp256k1::{Secp256k1, SecretKey};
+
+use crate::{
+    DeriveApplicationKeyRequest, DeriveApplicationKeyResponse, error::SdkError, signer::HmacSigner,
+};
+
+use super::BreezSdk;
+
+/// Hardened path under the identity master whose key seeds the application
+/// keys. It is never used for payments.
+const APPLICATION_KEY_DERIVATION_PATH: &str = "m/1097887844'/0'";
+const APPLICATION_KEY_TAG: &[u8] = b"breez-application-key:";
+const MAX_LABEL_LEN: usize = 128;
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Derives a key from the wallet seed for application use, such as
+    /// encryption or a Nostr identity.
+    ///
+    /// The same label always yields the same key, and different labels yield
+    /// unrelated keys. The keys are derived on a hardened branch separate from
+    /// the payment keys, so sharing them doesn't expose the funds.
+    pub async fn derive_application_key(
+        &self,
+        request: DeriveApplicationKeyRequest,
+    ) -> Result<DeriveApplicationKeyResponse, SdkError> {
+        let hmac = self
+            .application_key_signer
+            .as_deref()
+            .ok_or(SdkError::Generic(
+                "Deriving application keys requires a signer that supports HMAC".to_string(),
+            ))?;
+        let secret_key = application_key(hmac, &request.label).await?;
+        Ok(DeriveApplicationKeyResponse {
+            private_key: hex::encode(secret_key.secret_bytes()),
+            public_key: secret_key.public_key(&Secp256k1::new()).to_string(),
+        })
+    }
+}
+
+async fn application_key(hmac: &dyn HmacSigner, label: &str) -> Result<SecretKey, SdkError> {
+    if label.is_empty() || label.len() > MAX_LABEL_LEN {
+        return Err(SdkError::InvalidInput(format!(
+            "Label must be between 1 and {MAX_LABEL_LEN} bytes"
+        )));
+    }
+    let path: DerivationPath = APPLICATION_KEY_DERIVATION_PATH
+        .parse()
+        .map_err(|e| SdkError::Generic(format!("Invalid application key path: {e}")))?;
+    let mut input = APPLICATION_KEY_TAG.to_vec();
+    input.extend_from_slice(label.as_bytes());
+    let digest = hmac.hmac_sha256(&path, &input).await?;
+    SecretKey::from_slice(digest.as_byte_array())
+        .map_err(|e| SdkError::Generic(format!("Failed to derive application key: {e}")))
+}
+
+#[cfg(test)]
+mod tests {
+    use bitcoin::bip32::Xpriv;
+    use macros::async_test_all;
+
+    use super::*;
+    use crate::signer::breez::BreezSignerImpl;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn signer(seed: u8) -> BreezSignerImpl {
+        BreezSignerImpl::new(Xpriv::new_master(bitcoin::Network::Regtest, &[seed; 32]).unwrap())
+    }
+
+    #[async_test_all]
+    async fn test_application_key_is_stable_per_label() {
+        let signer = signer(1);
+        let nostr = application_key(&signer, "nostr").await.unwrap();
+
+        assert_eq!(application_key(&signer, "nostr").await.unwrap(), nostr);
+        assert_ne!(application_key(&signer, "backup").await.unwrap(), nostr);
+        assert_ne!(application_key(&signer(2), "nostr").await.unwrap(), nostr);
+        assert!(application_key(&signer, "").await.is_err());
+    }
+}
@@ -0,0 +1,243 @@
+use platform_utils::tokio;
+use tracing::{Instrument, debug, error, info};
+
+use crate::{
+    HostConditions, LeafOptimizationPolicy, QuietHours,
+    error::SdkError,
+    events::{AutoOptimizationEvent, SdkEvent},
+    persist::StorageListPaymentsRequest,
+};
+
+use super::BreezSdk;
+
+/// Starts a leaf optimization run in the background if the configured
+/// [`LeafOptimizationPolicy`] allows it. Called after each wallet sync.
+pub(super) async fn maybe_optimize_leaves(sdk: &BreezSdk) {
+    let leaf_config = &sdk.config.leaf_optimization_config;
+    let Some(policy) = leaf_config.auto_policy.as_ref() else {
+        return;
+    };
+    if !leaf_config.auto_enabled || !sdk.config.background_tasks_enabled {
+        return;
+    }
+    // Optimizing swaps leaves with the SSP, which moves funds out of the wallet
+    match sdk.is_wallet_frozen().await {
+        Ok(false) => {}
+        Ok(true) => {
+            debug!("Skipping leaf optimization: wallet is frozen");
+            return;
+        }
+        Err(e) => {
+            error!("Failed to check wallet freeze: {e:?}");
+            return;
+        }
+    }
+
+    let conditions = match current_conditions(sdk, policy).await {
+        Ok(conditions) => conditions,
+        Err(e) => {
+            error!("Failed to check leaf optimization policy: {e:?}");
+            return;
+        }
+    };
+    if let Some(reason) = blocked_reason(policy, &conditions) {
+        debug!("Skipping leaf optimization: {reason}");
+        return;
+    }
+
+    let task_sdk = sdk.clone();
+    let span = tracing::Span::current();
+    tokio::spawn(
+        async move {
+            run_optimization(&task_sdk).await;
+        }
+        .instrument(span),
+    );
+}
+
+/// Runs the optimization to completion, reporting it the same way the
+/// background auto-optimizer does.
+async fn run_optimization(sdk: &BreezSdk) {
+    let optimization_event = match sdk.spark_wallet.optimize_leaves(None).await {
+        Ok(spark_wallet::OptimizationOutcome::Completed { rounds_executed: 0 }) => {
+            AutoOptimizationEvent::Skipped
+        }
+        Ok(_) => {
+            info!("Leaf optimization completed");
+            AutoOptimizationEvent::Completed
+        }
+        Err(spark_wallet::OptimizationError::AlreadyRunning) => {
+            debug!("Leaf optimization already running");
+            return;
+        }
+        Err(spark_wallet::OptimizationError::Cancelled) => AutoOptimizationEvent::Cancelled,
+        Err(e) => {
+            error!("Leaf optimization failed: {e:?}");
+            AutoOptimizationEvent::Failed {
+                error: e.to_string(),
+            }
+        }
+    };
+    sdk.event_emitter
+        .emit(&SdkEvent::AutoOptimization { optimization_event })
+        .await;
+}
+
+/// The state a [`LeafOptimizationPolicy`] is evaluated against.
+struct PolicyConditions {
+    host: HostConditions,
+    leaf_count: usize,
+    /// Seconds since the last payment, `None` if there was none
+    idle_secs: Option<u64>,
+    has_pending_payments: bool,
+    /// Current time of day, in UTC hours
+    hour: u8,
+}
+
+async fn current_conditions(
+    sdk: &BreezSdk,
+    policy: &LeafOptimizationPolicy,
+) -> Result<PolicyConditions, SdkError> {
+    let now = sdk.now()?;
+
+    // Only query what the policy checks
+    let leaf_count = match policy.min_leaf_count {
+        Some(_) => sdk.spark_wallet.list_leaves().await?.available.len(),
+        None => 0,
+    };
+    let idle_secs = match policy.min_idle_secs {
+        Some(_) => sdk
+            .storage
+            .list_payments(StorageListPaymentsRequest {
+                limit: Some(1),
+                ..Default::default()
+            })
+            .await?
+            .first()
+            .map(|payment| now.saturating_sub(payment.timestamp)),
+        None => None,
+    };
+
+    Ok(PolicyConditions {
+        host: sdk.host_conditions.lock().await.clone(),
+        leaf_count,
+        idle_secs,
+        has_pending_payments: !sdk.pending_payments.lock().await.is_empty(),
+        hour: u8::try_from(now % 86_400 / 3_600).unwrap_or_default(),
+    })
+}
+
+/// Returns why the policy doesn't allow optimizing now, or `None` if it does.
+fn blocked_reason(
+    policy: &LeafOptimizationPolicy,
+    conditions: &PolicyConditions,
+) -> Option<&'static str> {
+    if let Some(min_leaf_count) = policy.min_leaf_count
+        && conditions.leaf_count <= min_leaf_count as usize
+    {
+        return Some("leaf count below threshold");
+    }
+    if let Some(min_idle_secs) = policy.min_idle_secs
+        && (conditions.has_pending_payments
+            || conditions
+                .idle_secs
+                .is_some_and(|idle_secs| idle_secs < min_idle_secs))
+    {
+        return Some("wallet is not idle");
+    }
+    if policy.unmetered_only && conditions.host.is_metered != Some(false) {
+        return Some("connection is metered or unknown");
+    }
+    if let Some(quiet_hours) = &policy.quiet_hours
+        && in_quiet_hours(quiet_hours, conditions.hour)
+    {
+        return Some("within quiet hours");
+    }
+    None
+}
+
+fn in_quiet_hours(quiet_hours: &QuietHours, hour: u8) -> bool {
+    if quiet_hours.start_hour <= quiet_hours.end_hour {
+        (quiet_hours.start_hour..quiet_hours.end_hour).contains(&hour)
+    } else {
+        hour >= quiet_hours.start_hour || hour < quiet_hours.end_hour
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn conditions() -> PolicyConditions {
+        PolicyConditions {
+            host: HostConditions {
+                is_metered: Some(false),
+            },
+            leaf_count: 100,
+            idle_secs: Some(3_600),
+            has_pending_payments: false,
+            hour: 12,
+        }
+    }
+
+    #[test_all]
+    fn test_blocked_reason() {
+        let policy = LeafOptimizationPolicy {
+            min_leaf_count: Some(50),
+            min_idle_secs: Some(600),
+            unmetered_only: true,
+            quiet_hours: Some(QuietHours {
+                start_hour: 22,
+                end_hour: 6,
+            }),
+        };
+        assert_eq!(blocked_reason(&policy, &conditions()), None);
+        assert_eq!(
+            blocked_reason(&LeafOptimizationPolicy::default(), &conditions()),
+            None
+        );
+
+        let mut few_leaves = conditions();
+        few_leaves.leaf_count = 50;
+        assert!(blocked_reason(&policy, &few_leaves).is_some());
+
+        let mut busy = conditions();
+        busy.idle_secs = Some(60);
+        assert!(blocked_reason(&policy, &busy).is_some());
+        let mut pending = conditions();
+        pending.has_pending_payments = true;
+        assert!(blocked_reason(&policy, &pending).is_some());
+
+        let mut unknown_network = conditions();
+        unknown_network.host.is_metered = None;
+        assert!(blocked_reason(&policy, &unknown_network).is_some());
+
+        let mut night = conditions();
+        night.hour = 23;
+        assert!(blocked_reason(&policy, &night).is_some());
+    }
+
+    #[test_all]
+    fn test_in_quiet_hours() {
+        let day = QuietHours {
+            start_hour: 9,
+            end_hour: 17,
+        };
+        assert!(in_quiet_hours(&day, 9));
+        assert!(!in_quiet_hours(&day, 17));
+        assert!(!in_quiet_hours(&day, 3));
+
+        let night = QuietHours {
+            start_hour: 22,
+            end_hour: 6,
+        };
+        assert!(in_quiet_hours(&night, 23));
+        assert!(in_quiet_hours(&night, 0));
+        assert!(!in_quiet_hours(&night, 6));
+        assert!(!in_quiet_hours(&night, 12));
+    }
+}
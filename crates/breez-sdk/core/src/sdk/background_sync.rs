@@ -0,0 +1,43 @@
+use tracing::info;
+
+use super::{BreezSdk, SyncType};
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl BreezSdk {
+    /// Pauses the periodic background sync, e.g. when the app is moved to the
+    /// background or the device switches to a metered connection.
+    ///
+    /// Besides the periodic sync, the syncs triggered by the Spark wallet
+    /// syncing, the service status refresh, token sweeps, leaf optimization,
+    /// the token metadata refresh and the real-time sync subscription are
+    /// stopped. Syncs requested with [`BreezSdk::sync_wallet`] and those
+    /// triggered by payments still run, as do pushes of local changes to
+    /// real-time sync. The sync stays paused until
+    /// [`BreezSdk::resume_background_sync`] is called.
+    pub fn pause_background_sync(&self) {
+        if !self.background_sync_paused.send_replace(true) {
+            info!("Background sync paused");
+        }
+    }
+
+    /// Resumes the periodic background sync paused with
+    /// [`BreezSdk::pause_background_sync`] or
+    /// `SdkBuilder::with_background_sync_paused`, syncing right away if the
+    /// sync interval has elapsed.
+    pub async fn resume_background_sync(&self) {
+        if !self.background_sync_paused.send_replace(false) {
+            return;
+        }
+        info!("Background sync resumed");
+        if self.config.background_tasks_enabled {
+            self.sync_coordinator
+                .trigger_sync_no_wait(SyncType::Full, false)
+                .await;
+        }
+    }
+
+    /// Whether the periodic background sync is paused
+    pub fn is_background_sync_paused(&self) -> bool {
+        *self.background_sync_paused.borrow()
+    }
+}
@@ -0,0 +1,52 @@
+use std::sync::Arc;
+
+use platform_utils::time::{SystemTime, UNIX_EPOCH};
+
+use crate::{Clock, error::SdkError};
+
+use super::BreezSdk;
+
+impl BreezSdk {
+    /// Returns the current time in seconds since the Unix epoch, as reported
+    /// by the clock set with `SdkBuilder::with_clock` or else the system clock
+    pub(in crate::sdk) fn now(&self) -> Result<u64, SdkError> {
+        match &self.clock {
+            Some(clock) => Ok(clock.now_secs()),
+            None => system_now(),
+        }
+    }
+}
+
+/// Returns the current time in seconds since the Unix epoch for components
+/// that hold the clock set with `SdkBuilder::with_clock` rather than the SDK,
+/// falling back to the system clock
+pub(crate) fn clock_now(clock: Option<&Arc<dyn Clock>>) -> u64 {
+    match clock {
+        Some(clock) => clock.now_secs(),
+        None => SystemTime::now()
+            .duration_since(UNIX_EPOCH)
+            .map_or(0, |d| d.as_secs()),
+    }
+}
+
+/// Returns the current time in milliseconds since the Unix epoch, like
+/// [`clock_now`]. A clock set with `SdkBuilder::with_clock` only has a
+/// resolution of seconds.
+pub(crate) fn clock_now_ms(clock: Option<&Arc<dyn Clock>>) -> u128 {
+    match clock {
+        Some(clock) => u128::from(clock.now_secs()).saturating_mul(1000),
+        None => SystemTime::now()
+            .duration_since(UNIX_EPOCH)
+            .map_or(0, |d| d.as_millis()),
+    }
+}
+
+/// Returns the current system time in seconds since the Unix epoch. Only the
+/// fallback when no clock is set, use [`BreezSdk::now`] or [`clock_now`] to
+/// honor `SdkBuilder::with_clock`.
+pub(in crate::sdk) fn system_now() -> Result<u64, SdkError> {
+    Ok(SystemTime::now()
+        .duration_since(UNIX_EPOCH)
+        .map_err(|_| SdkError::Generic("Failed to read current time".to_string()))?
+        .as_secs())
+}
@@ -1,73 +1,103 @@
+use std::collections::HashMap;
+
+use spark_wallet::SparkAddress;
+
 use crate::{
-    AddContactRequest, Contact, ListContactsRequest, UpdateContactRequest, error::SdkError,
-    utils::contacts_validation::validate_contact_input,
+    AddContactRequest, Contact, ContactSuggestion, ContactSuggestionKind,
+    GetContactPaymentsRequest, GetContactPaymentsResponse, ListContactsRequest,
+    ListPaymentsRequest, Payment, PaymentDetails, PaymentStatus, PaymentType,
+    SuggestContactsRequest, SuggestContactsResponse, UpdateContactRequest,
+    error::SdkError,
+    utils::{
+        contact_merge::save_contact_merging_duplicates,
+        contacts_validation::{validate_contact_details, validate_contact_input},
+    },
 };
 
 use super::BreezSdk;
 
+/// Sends read from storage at a time when matching a contact's payments
+const CONTACT_PAYMENTS_PAGE_SIZE: u32 = 100;
+
 #[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
 #[allow(clippy::needless_pass_by_value)]
 impl BreezSdk {
     /// Adds a new contact.
     ///
+    /// If a contact with the same lightning address exists, the two are
+    /// merged into the existing contact, which takes the new name.
+    ///
     /// # Arguments
     ///
     /// * `request` - The request containing the contact details
     ///
     /// # Returns
     ///
-    /// The created contact or an error
+    /// The created or merged contact, or an error
     pub async fn add_contact(&self, request: AddContactRequest) -> Result<Contact, SdkError> {
         let name = validate_contact_input(&request.name, &request.payment_identifier)?;
+        let details = validate_contact_details(
+            &request.payment_identifier,
+            &request.additional_payment_identifiers,
+            request.avatar_url.as_deref(),
+            request.notes.as_deref(),
+        )?;
         let payment_identifier = request.payment_identifier.trim().to_string();
 
-        let now = platform_utils::time::SystemTime::now()
-            .duration_since(platform_utils::time::UNIX_EPOCH)
-            .map(|d| d.as_secs())
-            .map_err(|_| SdkError::Generic("Failed to get current time".to_string()))?;
+        let now = self.now()?;
 
         let contact = Contact {
             id: uuid::Uuid::now_v7().to_string(),
             name,
             payment_identifier,
+            additional_payment_identifiers: details.additional_payment_identifiers,
+            avatar_url: details.avatar_url,
+            notes: details.notes,
             created_at: now,
             updated_at: now,
         };
 
-        self.storage.insert_contact(contact.clone()).await?;
-        Ok(contact)
+        Ok(save_contact_merging_duplicates(self.storage.as_ref(), contact).await?)
     }
 
     /// Updates an existing contact.
     ///
+    /// If the new lightning address is already used by another contact, the
+    /// two are merged and the contact with the lowest id is kept.
+    ///
     /// # Arguments
     ///
     /// * `request` - The request containing the updated contact details
     ///
     /// # Returns
     ///
-    /// The updated contact or an error
+    /// The updated or merged contact, or an error
     pub async fn update_contact(&self, request: UpdateContactRequest) -> Result<Contact, SdkError> {
         let name = validate_contact_input(&request.name, &request.payment_identifier)?;
+        let details = validate_contact_details(
+            &request.payment_identifier,
+            &request.additional_payment_identifiers,
+            request.avatar_url.as_deref(),
+            request.notes.as_deref(),
+        )?;
         let payment_identifier = request.payment_identifier.trim().to_string();
 
         let existing = self.storage.get_contact(request.id.clone()).await?;
 
-        let now = platform_utils::time::SystemTime::now()
-            .duration_since(platform_utils::time::UNIX_EPOCH)
-            .map(|d| d.as_secs())
-            .map_err(|_| SdkError::Generic("Failed to get current time".to_string()))?;
+        let now = self.now()?;
 
         let contact = Contact {
             id: request.id,
             name,
             payment_identifier,
+            additional_payment_identifiers: details.additional_payment_identifiers,
+            avatar_url: details.avatar_url,
+            notes: details.notes,
             created_at: existing.created_at,
             updated_at: now,
         };
 
-        self.storage.insert_contact(contact.clone()).await?;
-        Ok(contact)
+        Ok(save_contact_merging_duplicates(self.storage.as_ref(), contact).await?)
     }
 
     /// Deletes a contact by its ID.
@@ -103,4 +133,297 @@ impl BreezSdk {
         let contacts = self.storage.list_contacts(request).await?;
         Ok(contacts)
     }
+
+    /// Lists the payments made to a contact, newest first.
+    ///
+    /// Payments are matched to the contact by the Lightning address they
+    /// were sent to, so that apps can show the history with each contact.
+    ///
+    /// # Arguments
+    ///
+    /// * `request` - The contact ID and optional pagination parameters
+    ///
+    /// # Returns
+    ///
+    /// The contact's payments or an error
+    pub async fn get_contact_payments(
+        &self,
+        request: GetContactPaymentsRequest,
+    ) -> Result<GetContactPaymentsResponse, SdkError> {
+        let contact = self.storage.get_contact(request.contact_id).await?;
+        let mut to_skip = request.offset.unwrap_or(0) as usize;
+        let limit = request.limit.map_or(usize::MAX, |limit| limit as usize);
+        let mut payments = Vec::new();
+        let mut page_offset = 0u32;
+        // Sends are read page by page, up to the last one needed
+        while payments.len() < limit {
+            let page = self
+                .list_payments(ListPaymentsRequest {
+                    type_filter: Some(vec![PaymentType::Send]),
+                    offset: Some(page_offset),
+                    limit: Some(CONTACT_PAYMENTS_PAGE_SIZE),
+                    ..Default::default()
+                })
+                .await?
+                .payments;
+            let page_len = page.len();
+            for payment in page {
+                if !is_paid_to_contact(&payment, &contact) {
+                    continue;
+                }
+                if to_skip > 0 {
+                    to_skip = to_skip.saturating_sub(1);
+                } else if payments.len() < limit {
+                    payments.push(payment);
+                }
+            }
+            if page_len < CONTACT_PAYMENTS_PAGE_SIZE as usize {
+                break;
+            }
+            page_offset = page_offset.saturating_add(CONTACT_PAYMENTS_PAGE_SIZE);
+        }
+        Ok(GetContactPaymentsResponse { payments })
+    }
+
+    /// Suggests contacts from the payment history: the Lightning addresses,
+    /// Spark addresses and LNURL domains paid repeatedly that aren't
+    /// contacts yet, so that apps can offer to save them.
+    ///
+    /// Spark addresses are known only for payments of Spark invoices.
+    ///
+    /// # Arguments
+    ///
+    /// * `request` - The minimum number of payments and the maximum number of
+    ///   suggestions
+    ///
+    /// # Returns
+    ///
+    /// The suggestions, most paid first, or an error
+    pub async fn suggest_contacts(
+        &self,
+        request: SuggestContactsRequest,
+    ) -> Result<SuggestContactsResponse, SdkError> {
+        let payments = self
+            .list_payments(ListPaymentsRequest {
+                type_filter: Some(vec![PaymentType::Send]),
+                status_filter: Some(vec![PaymentStatus::Completed]),
+                ..Default::default()
+            })
+            .await?
+            .payments;
+        let contacts = self
+            .storage
+            .list_contacts(ListContactsRequest::default())
+            .await?;
+        let mut suggestions =
+            contact_suggestions(&payments, &contacts, request.min_payment_count.unwrap_or(2));
+        if let Some(limit) = request.limit {
+            suggestions.truncate(limit as usize);
+        }
+        Ok(SuggestContactsResponse { suggestions })
+    }
+}
+
+/// The counterparties paid at least `min_payment_count` times that aren't
+/// contacts, most paid first, then most recently paid first
+fn contact_suggestions(
+    payments: &[Payment],
+    contacts: &[Contact],
+    min_payment_count: u32,
+) -> Vec<ContactSuggestion> {
+    let mut counterparties: HashMap<(ContactSuggestionKind, String), ContactSuggestion> =
+        HashMap::new();
+    for payment in payments.iter().filter(|p| !p.is_conversion_child()) {
+        let Some((kind, identifier)) = payment_counterparty(payment) else {
+            continue;
+        };
+        let suggestion = counterparties
+            .entry((kind, identifier.to_lowercase()))
+            .or_insert_with(|| ContactSuggestion {
+                payment_identifier: identifier,
+                kind,
+                payment_count: 0,
+                last_paid_at: 0,
+            });
+        suggestion.payment_count = suggestion.payment_count.saturating_add(1);
+        suggestion.last_paid_at = suggestion.last_paid_at.max(payment.timestamp);
+    }
+
+    let mut suggestions: Vec<ContactSuggestion> = counterparties
+        .into_values()
+        .filter(|s| s.payment_count >= min_payment_count.max(1))
+        .filter(|s| !is_known_counterparty(s, contacts))
+        .collect();
+    suggestions.sort_by(|a, b| {
+        b.payment_count
+            .cmp(&a.payment_count)
+            .then_with(|| b.last_paid_at.cmp(&a.last_paid_at))
+            .then_with(|| a.payment_identifier.cmp(&b.payment_identifier))
+    });
+    suggestions
+}
+
+/// The Lightning address, LNURL domain or Spark address a payment was sent to
+fn payment_counterparty(payment: &Payment) -> Option<(ContactSuggestionKind, String)> {
+    match payment.details.as_ref()? {
+        PaymentDetails::Lightning {
+            lnurl_pay_info: Some(info),
+            ..
+        } => match (&info.ln_address, &info.domain) {
+            (Some(address), _) => Some((ContactSuggestionKind::LightningAddress, address.clone())),
+            (None, Some(domain)) => Some((ContactSuggestionKind::LnurlDomain, domain.clone())),
+            (None, None) => None,
+        },
+        PaymentDetails::Spark {
+            invoice_details: Some(invoice),
+            ..
+        }
+        | PaymentDetails::Token {
+            invoice_details: Some(invoice),
+            ..
+        } => {
+            let address: SparkAddress = invoice.invoice.parse().ok()?;
+            let address = SparkAddress::new(address.identity_public_key, address.network, None)
+                .to_address_string()
+                .ok()?;
+            Some((ContactSuggestionKind::SparkAddress, address))
+        }
+        _ => None,
+    }
+}
+
+/// Whether a contact already has the Lightning address, or one on the LNURL
+/// domain
+fn is_known_counterparty(suggestion: &ContactSuggestion, contacts: &[Contact]) -> bool {
+    match suggestion.kind {
+        ContactSuggestionKind::LightningAddress => contacts
+            .iter()
+            .any(|c| c.has_payment_identifier(&suggestion.payment_identifier)),
+        ContactSuggestionKind::LnurlDomain => contacts.iter().any(|c| {
+            std::iter::once(&c.payment_identifier)
+                .chain(&c.additional_payment_identifiers)
+                .filter_map(|id| id.rsplit_once('@'))
+                .any(|(_, domain)| domain.eq_ignore_ascii_case(&suggestion.payment_identifier))
+        }),
+        ContactSuggestionKind::SparkAddress => false,
+    }
+}
+
+/// Whether the payment was sent to one of the contact's Lightning addresses
+fn is_paid_to_contact(payment: &Payment, contact: &Contact) -> bool {
+    matches!(
+        &payment.details,
+        Some(PaymentDetails::Lightning {
+            lnurl_pay_info: Some(info),
+            ..
+        }) if info
+            .ln_address
+            .as_deref()
+            .is_some_and(|address| contact.has_payment_identifier(address))
+    )
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+    use crate::{LnurlPayInfo, PaymentMethod, SparkHtlcDetails, SparkHtlcStatus};
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn lnurl_payment(ln_address: Option<&str>, domain: Option<&str>, ts: u64) -> Payment {
+        Payment {
+            id: format!("p{ts}"),
+            payment_type: PaymentType::Send,
+            status: PaymentStatus::Completed,
+            amount: 1_000,
+            fees: 1,
+            timestamp: ts,
+            method: PaymentMethod::Lightning,
+            details: Some(PaymentDetails::Lightning {
+                description: None,
+                invoice: "lnbc1".to_string(),
+                destination_pubkey: "pubkey".to_string(),
+                htlc_details: SparkHtlcDetails {
+                    payment_hash: "hash".to_string(),
+                    preimage: None,
+                    expiry_time: 0,
+                    status: SparkHtlcStatus::PreimageShared,
+                },
+                lnurl_pay_info: Some(LnurlPayInfo {
+                    ln_address: ln_address.map(ToString::to_string),
+                    comment: None,
+                    domain: domain.map(ToString::to_string),
+                    metadata: None,
+                    processed_success_action: None,
+                    raw_success_action: None,
+                }),
+                lnurl_withdraw_info: None,
+                lnurl_receive_metadata: None,
+                conversion_info: None,
+            }),
+            conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
+        }
+    }
+
+    fn contact(payment_identifier: &str) -> Contact {
+        Contact {
+            id: "c1".to_string(),
+            name: "Alice".to_string(),
+            payment_identifier: payment_identifier.to_string(),
+            additional_payment_identifiers: Vec::new(),
+            avatar_url: None,
+            notes: None,
+            created_at: 0,
+            updated_at: 0,
+        }
+    }
+
+    #[test_all]
+    fn test_suggests_recurring_counterparties_most_paid_first() {
+        let payments = vec![
+            lnurl_payment(Some("bob@example.com"), None, 1),
+            lnurl_payment(Some("Bob@Example.com"), None, 5),
+            lnurl_payment(None, Some("shop.example.com"), 2),
+            lnurl_payment(None, Some("shop.example.com"), 3),
+            lnurl_payment(None, Some("shop.example.com"), 4),
+            lnurl_payment(Some("carol@example.com"), None, 6),
+        ];
+
+        let suggestions = contact_suggestions(&payments, &[], 2);
+        assert_eq!(
+            suggestions,
+            vec![
+                ContactSuggestion {
+                    payment_identifier: "shop.example.com".to_string(),
+                    kind: ContactSuggestionKind::LnurlDomain,
+                    payment_count: 3,
+                    last_paid_at: 4,
+                },
+                ContactSuggestion {
+                    payment_identifier: "bob@example.com".to_string(),
+                    kind: ContactSuggestionKind::LightningAddress,
+                    payment_count: 2,
+                    last_paid_at: 5,
+                },
+            ]
+        );
+    }
+
+    #[test_all]
+    fn test_skips_counterparties_already_in_contacts() {
+        let payments = vec![
+            lnurl_payment(Some("alice@example.com"), None, 1),
+            lnurl_payment(Some("alice@example.com"), None, 2),
+            lnurl_payment(None, Some("Example.com"), 3),
+            lnurl_payment(None, Some("example.com"), 4),
+        ];
+
+        let suggestions = contact_suggestions(&payments, &[contact("Alice@example.com")], 2);
+        assert!(suggestions.is_empty());
+    }
 }
@@ -1,15 +1,28 @@
 use std::{str::FromStr, time::Duration};
 
-use bitcoin::{consensus::serialize, hex::DisplayHex};
+use bitcoin::{
+    Address, Transaction,
+    consensus::{encode::deserialize_hex, serialize},
+    hex::DisplayHex,
+};
 use platform_utils::tokio;
 use spark_wallet::{ListTransfersRequest, TransferId, WalletTransfer};
-use tracing::{error, trace};
+use tracing::{error, info, trace, warn};
 
 use crate::{
-    ClaimDepositRequest, ClaimDepositResponse, ListUnclaimedDepositsRequest,
-    ListUnclaimedDepositsResponse, RefundDepositRequest, RefundDepositResponse, error::SdkError,
-    models::Payment, persist::UpdateDepositPayload, sdk::RuntimeEvent,
-    utils::utxo_fetcher::CachedUtxoFetcher,
+    BumpRefundFeeRequest, BumpRefundFeeResponse, ClaimDepositRequest, ClaimDepositResponse,
+    ClaimDepositResult, ClaimDepositsRequest, ClaimDepositsResponse, DepositAddressInfo,
+    DepositRefund, Fee, GetDepositAddressHistoryResponse, ListUnclaimedDepositsRequest,
+    ListUnclaimedDepositsResponse, MaxFee, RefundDepositRequest, RefundDepositResponse, TxStatus,
+    chain::Outspend,
+    error::SdkError,
+    events::SdkEvent,
+    models::Payment,
+    persist::{
+        IdempotentOperation, IssuedDepositAddress, ObjectCacheRepository, UpdateDepositPayload,
+    },
+    sdk::RuntimeEvent,
+    utils::{idempotency::run_idempotent_payment, utxo_fetcher::CachedUtxoFetcher},
 };
 
 use super::BreezSdk;
@@ -27,97 +40,462 @@ impl BreezSdk {
         request: ClaimDepositRequest,
     ) -> Result<ClaimDepositResponse, SdkError> {
         self.maybe_ensure_spark_private_mode_initialized().await?;
-        let detailed_utxo =
-            CachedUtxoFetcher::new(self.chain_service.clone(), self.storage.clone())
-                .fetch_detailed_utxo(&request.txid, request.vout)
-                .await?;
+        let payment = run_idempotent_payment(
+            self.storage.clone(),
+            request.idempotency_key.as_deref(),
+            IdempotentOperation::ClaimDeposit,
+            || self.claim_deposit_inner(request.txid, request.vout, request.max_fee),
+        )
+        .await?;
+        Ok(ClaimDepositResponse { payment })
+    }
 
-        let max_fee = request
-            .max_fee
-            .or(self.config.max_deposit_claim_fee.clone());
-        match self.claim_utxo(&detailed_utxo, max_fee).await {
-            Ok(transfer_id) => {
-                let transfer = self.lookup_claim_transfer_with_retry(transfer_id).await?;
-                let payment: Payment = transfer.try_into()?;
-                // Insert the payment before returning so callers that
-                // immediately list payments see the claim.
-                let should_emit_event = self.storage.apply_payment_update(payment.clone()).await?;
-                self.storage
-                    .delete_deposit(detailed_utxo.txid.to_string(), detailed_utxo.vout)
-                    .await?;
-                self.event_emitter
-                    .emit_runtime_event(RuntimeEvent::DepositClaimed {
-                        payment: Box::new(payment.clone()),
-                        should_emit_event,
-                    })
-                    .await;
-                Ok(ClaimDepositResponse { payment })
-            }
-            Err(e) => {
-                error!("Failed to claim deposit: {e:?}");
-                self.storage
-                    .update_deposit(
-                        detailed_utxo.txid.to_string(),
-                        detailed_utxo.vout,
-                        UpdateDepositPayload::ClaimError {
-                            error: e.clone().into(),
-                        },
-                    )
-                    .await?;
-                Err(e)
+    /// Claims several deposits in one call, bounded by a total fee.
+    ///
+    /// Spark claims each deposit with its own quote, so the deposits are
+    /// claimed one after the other and a failed claim doesn't stop the
+    /// others. The outcome of each claim is reported in the response.
+    pub async fn claim_deposits(
+        &self,
+        request: ClaimDepositsRequest,
+    ) -> Result<ClaimDepositsResponse, SdkError> {
+        if request.deposits.is_empty() {
+            return Err(SdkError::InvalidInput(
+                "At least one deposit is required".to_string(),
+            ));
+        }
+        for (i, deposit) in request.deposits.iter().enumerate() {
+            if request.deposits[..i]
+                .iter()
+                .any(|d| d.txid == deposit.txid && d.vout == deposit.vout)
+            {
+                return Err(SdkError::InvalidInput(format!(
+                    "Deposit {}:{} is listed more than once",
+                    deposit.txid, deposit.vout
+                )));
             }
         }
+        self.maybe_ensure_spark_private_mode_initialized().await?;
+
+        let mut remaining_fee_sat = request.max_total_fee_sat;
+        let mut total_fee_sat = 0u64;
+        let mut results = Vec::with_capacity(request.deposits.len());
+        for deposit in request.deposits {
+            let max_fee = remaining_fee_sat.map(|amount| MaxFee::Fixed { amount });
+            let result = self
+                .claim_deposit_inner(deposit.txid.clone(), deposit.vout, max_fee)
+                .await;
+            let (payment, error) = match result {
+                Ok(payment) => {
+                    let fee_sat = u64::try_from(payment.fees).unwrap_or(u64::MAX);
+                    total_fee_sat = total_fee_sat.saturating_add(fee_sat);
+                    remaining_fee_sat = remaining_fee_sat.map(|r| r.saturating_sub(fee_sat));
+                    (Some(payment), None)
+                }
+                Err(e) => (None, Some(e.to_string())),
+            };
+            results.push(ClaimDepositResult {
+                txid: deposit.txid,
+                vout: deposit.vout,
+                payment,
+                error,
+            });
+        }
+        info!(
+            "Claimed {} of {} deposits for a total fee of {total_fee_sat} sats",
+            results.iter().filter(|r| r.payment.is_some()).count(),
+            results.len()
+        );
+        Ok(ClaimDepositsResponse {
+            results,
+            total_fee_sat,
+        })
     }
 
     pub async fn refund_deposit(
         &self,
         request: RefundDepositRequest,
     ) -> Result<RefundDepositResponse, SdkError> {
+        let (tx, refund) = self
+            .sign_refund(
+                &request.txid,
+                request.vout,
+                &request.destination_address,
+                request.fee,
+            )
+            .await?;
+        let tx_hex = self.publish_refund(&tx, &refund).await?;
+        Ok(RefundDepositResponse {
+            tx_id: refund.tx_id,
+            tx_hex,
+        })
+    }
+
+    /// Replaces an unconfirmed deposit refund with one paying a higher fee,
+    /// relying on replace-by-fee. The replacement spends the same deposit to
+    /// the same destination address.
+    pub async fn bump_refund_fee(
+        &self,
+        request: BumpRefundFeeRequest,
+    ) -> Result<BumpRefundFeeResponse, SdkError> {
+        let previous = ObjectCacheRepository::new(self.storage.clone())
+            .fetch_deposit_refunds()
+            .await?
+            .into_iter()
+            .find(|refund| refund.tx_id == request.tx_id)
+            .ok_or_else(|| {
+                SdkError::InvalidInput(format!(
+                    "No unconfirmed refund found with id {}",
+                    request.tx_id
+                ))
+            })?;
+        // Any refund of the deposit may have confirmed, not only the latest
+        if let Ok(Outspend::Spent {
+            status: TxStatus {
+                confirmed: true, ..
+            },
+            ..
+        }) = self
+            .chain_service
+            .get_outspend(previous.deposit_txid.clone(), previous.deposit_vout)
+            .await
+        {
+            return Err(SdkError::InvalidInput(
+                "The deposit was already spent by a confirmed transaction".to_string(),
+            ));
+        }
+
+        let (tx, refund) = self
+            .sign_refund(
+                &previous.deposit_txid,
+                previous.deposit_vout,
+                &previous.destination_address,
+                request.fee,
+            )
+            .await?;
+        if refund.fee_sats <= previous.fee_sats {
+            return Err(SdkError::InvalidInput(format!(
+                "The new fee of {} sats must be higher than the current fee of {} sats",
+                refund.fee_sats, previous.fee_sats
+            )));
+        }
+        let tx_hex = self.publish_refund(&tx, &refund).await?;
+        info!(
+            "Bumped refund {} to {} with a {} sats fee",
+            previous.tx_id, refund.tx_id, refund.fee_sats
+        );
+
+        let tx_id = refund.tx_id.clone();
+        self.event_emitter
+            .emit(&SdkEvent::RefundFeeBumped {
+                refund,
+                replaced_tx_id: previous.tx_id,
+            })
+            .await;
+        Ok(BumpRefundFeeResponse { tx_id, tx_hex })
+    }
+
+    #[allow(unused_variables)]
+    pub async fn list_unclaimed_deposits(
+        &self,
+        request: ListUnclaimedDepositsRequest,
+    ) -> Result<ListUnclaimedDepositsResponse, SdkError> {
+        let deposits = self.storage.list_deposits().await?;
+        Ok(ListUnclaimedDepositsResponse { deposits })
+    }
+
+    /// Lists the static deposit addresses of the wallet with the deposits
+    /// each one received, most recently issued first.
+    pub async fn get_deposit_address_history(
+        &self,
+    ) -> Result<GetDepositAddressHistoryResponse, SdkError> {
+        let mut addresses = Vec::new();
+        let mut paging = None;
+        loop {
+            let page = self
+                .spark_wallet
+                .list_static_deposit_addresses(paging)
+                .await?;
+            addresses.extend(page.items.into_iter().map(|a| a.to_string()));
+            match page.next {
+                Some(next) => paging = Some(next),
+                None => break,
+            }
+        }
+
+        let issued = ObjectCacheRepository::new(self.storage.clone())
+            .fetch_deposit_addresses()
+            .await?;
+        let mut history = Vec::with_capacity(addresses.len());
+        for address in addresses {
+            let txos = self.chain_service.get_address_txos(address.clone()).await?;
+            history.push(DepositAddressInfo {
+                issued_at: issued
+                    .iter()
+                    .find(|i| i.address == address)
+                    .map(|i| i.issued_at),
+                deposit_count: u32::try_from(txos.len()).unwrap_or(u32::MAX),
+                total_received_sats: txos.iter().map(|txo| txo.value).sum(),
+                address,
+            });
+        }
+        sort_deposit_address_history(&mut history);
+        Ok(GetDepositAddressHistoryResponse { addresses: history })
+    }
+}
+
+impl BreezSdk {
+    /// Records a deposit address returned by `receive_payment`, keeping the
+    /// time it was first issued.
+    pub(super) async fn record_issued_deposit_address(
+        &self,
+        address: &str,
+    ) -> Result<(), SdkError> {
+        let cache = ObjectCacheRepository::new(self.storage.clone());
+        let mut issued = cache.fetch_deposit_addresses().await?;
+        if issued.iter().any(|i| i.address == address) {
+            return Ok(());
+        }
+        issued.push(IssuedDepositAddress {
+            address: address.to_string(),
+            issued_at: self.now()?,
+        });
+        cache.save_deposit_addresses(&issued).await?;
+        Ok(())
+    }
+
+    async fn sign_refund(
+        &self,
+        txid: &str,
+        vout: u32,
+        destination_address: &str,
+        fee: Fee,
+    ) -> Result<(Transaction, DepositRefund), SdkError> {
+        self.ensure_not_frozen().await?;
         let detailed_utxo =
             CachedUtxoFetcher::new(self.chain_service.clone(), self.storage.clone())
-                .fetch_detailed_utxo(&request.txid, request.vout)
+                .fetch_detailed_utxo(txid, vout)
                 .await?;
         let tx = self
             .spark_wallet
             .refund_static_deposit(
                 detailed_utxo.clone().tx,
                 Some(detailed_utxo.vout),
-                &request.destination_address,
-                request.fee.into(),
+                destination_address,
+                fee.into(),
             )
             .await?;
-        let tx_hex = serialize(&tx).as_hex().to_string();
-        let tx_id = tx.compute_txid().as_raw_hash().to_string();
+
+        let output_sats = tx
+            .output
+            .iter()
+            .map(|output| output.value.to_sat())
+            .fold(0u64, u64::saturating_add);
+        let refund = DepositRefund {
+            deposit_txid: detailed_utxo.txid.to_string(),
+            deposit_vout: detailed_utxo.vout,
+            tx_id: tx.compute_txid().as_raw_hash().to_string(),
+            destination_address: destination_address.to_string(),
+            fee_sats: detailed_utxo.value.saturating_sub(output_sats),
+        };
+        Ok((tx, refund))
+    }
+
+    /// Stores the refund on its deposit, tracks it until it confirms and
+    /// broadcasts it. Returns the transaction hex.
+    async fn publish_refund(
+        &self,
+        tx: &Transaction,
+        refund: &DepositRefund,
+    ) -> Result<String, SdkError> {
+        let tx_hex = serialize(tx).as_hex().to_string();
 
         // Store the refund transaction details separately
         self.storage
             .update_deposit(
-                detailed_utxo.txid.to_string(),
-                detailed_utxo.vout,
+                refund.deposit_txid.clone(),
+                refund.deposit_vout,
                 UpdateDepositPayload::Refund {
                     refund_tx: tx_hex.clone(),
-                    refund_txid: tx_id.clone(),
+                    refund_txid: refund.tx_id.clone(),
                 },
             )
             .await?;
 
+        // Only the latest refund of a deposit is tracked, as it replaces the
+        // previous ones
+        let cache = ObjectCacheRepository::new(self.storage.clone());
+        let mut refunds = cache.fetch_deposit_refunds().await?;
+        refunds.retain(|r| {
+            r.deposit_txid != refund.deposit_txid || r.deposit_vout != refund.deposit_vout
+        });
+        refunds.push(refund.clone());
+        cache.save_deposit_refunds(&refunds).await?;
+
         self.chain_service
             .broadcast_transaction(tx_hex.clone())
             .await?;
-        Ok(RefundDepositResponse { tx_id, tx_hex })
+        if let Err(e) = self.monitor_refund(refund).await {
+            warn!("Failed to monitor refund {}: {e:?}", refund.tx_id);
+        }
+        Ok(tx_hex)
     }
 
-    #[allow(unused_variables)]
-    pub async fn list_unclaimed_deposits(
+    /// Emits `RefundConfirmed` for the tracked refunds that confirmed and
+    /// stops tracking them. Called during sync.
+    ///
+    /// The spend of the deposit output is checked rather than the latest
+    /// refund transaction, so that a replaced refund confirming in its place
+    /// is seen too.
+    pub(super) async fn check_refund_confirmations(&self) {
+        let result: Result<(), SdkError> = async {
+            let cache = ObjectCacheRepository::new(self.storage.clone());
+            let mut pending = Vec::new();
+            let mut confirmed = Vec::new();
+            let refunds = cache.fetch_deposit_refunds().await?;
+            let tracked = refunds.len();
+            for refund in refunds {
+                match self
+                    .chain_service
+                    .get_outspend(refund.deposit_txid.clone(), refund.deposit_vout)
+                    .await
+                {
+                    Ok(Outspend::Spent { txid, status, .. }) if status.confirmed => {
+                        if txid == refund.tx_id {
+                            confirmed.push(refund);
+                            continue;
+                        }
+                        match self.replaced_refund(&refund, &txid).await {
+                            Ok(Some(replaced)) => confirmed.push(replaced),
+                            Ok(None) => warn!(
+                                "Deposit {}:{} of refund {} was spent by {txid}, which is not a refund",
+                                refund.deposit_txid, refund.deposit_vout, refund.tx_id
+                            ),
+                            Err(e) => {
+                                warn!("Failed to check spend {txid} of refund {}: {e:?}", refund.tx_id);
+                                pending.push(refund);
+                            }
+                        }
+                    }
+                    Ok(_) => pending.push(refund),
+                    Err(e) => {
+                        warn!("Failed to get status of refund {}: {e:?}", refund.tx_id);
+                        pending.push(refund);
+                    }
+                }
+            }
+            if pending.len() == tracked {
+                return Ok(());
+            }
+
+            cache.save_deposit_refunds(&pending).await?;
+            for refund in confirmed {
+                info!("Refund {} confirmed", refund.tx_id);
+                self.event_emitter
+                    .emit(&SdkEvent::RefundConfirmed { refund })
+                    .await;
+            }
+            Ok(())
+        }
+        .await;
+        if let Err(e) = result {
+            error!("Failed to check refund confirmations: {e:?}");
+        }
+    }
+
+    /// Returns the refund `spender_txid` replaced, if it is one: a
+    /// transaction paying the refund's destination address.
+    async fn replaced_refund(
         &self,
-        request: ListUnclaimedDepositsRequest,
-    ) -> Result<ListUnclaimedDepositsResponse, SdkError> {
-        let deposits = self.storage.list_deposits().await?;
-        Ok(ListUnclaimedDepositsResponse { deposits })
+        refund: &DepositRefund,
+        spender_txid: &str,
+    ) -> Result<Option<DepositRefund>, SdkError> {
+        let tx_hex = self
+            .chain_service
+            .get_transaction_hex(spender_txid.to_string())
+            .await?;
+        let tx: Transaction = deserialize_hex(&tx_hex)
+            .map_err(|e| SdkError::Generic(format!("Invalid transaction {spender_txid}: {e}")))?;
+        let destination = Address::from_str(&refund.destination_address)
+            .map_err(|e| SdkError::Generic(format!("Invalid refund destination address: {e}")))?
+            .assume_checked()
+            .script_pubkey();
+        if !tx.output.iter().any(|o| o.script_pubkey == destination) {
+            return Ok(None);
+        }
+
+        let deposit_sats = CachedUtxoFetcher::new(self.chain_service.clone(), self.storage.clone())
+            .fetch_detailed_utxo(&refund.deposit_txid, refund.deposit_vout)
+            .await?
+            .value;
+        let output_sats = tx
+            .output
+            .iter()
+            .map(|output| output.value.to_sat())
+            .fold(0u64, u64::saturating_add);
+        self.storage
+            .update_deposit(
+                refund.deposit_txid.clone(),
+                refund.deposit_vout,
+                UpdateDepositPayload::Refund {
+                    refund_tx: tx_hex,
+                    refund_txid: spender_txid.to_string(),
+                },
+            )
+            .await?;
+        Ok(Some(DepositRefund {
+            tx_id: spender_txid.to_string(),
+            fee_sats: deposit_sats.saturating_sub(output_sats),
+            ..refund.clone()
+        }))
+    }
+
+    async fn claim_deposit_inner(
+        &self,
+        txid: String,
+        vout: u32,
+        max_fee: Option<MaxFee>,
+    ) -> Result<Payment, SdkError> {
+        let detailed_utxo =
+            CachedUtxoFetcher::new(self.chain_service.clone(), self.storage.clone())
+                .fetch_detailed_utxo(&txid, vout)
+                .await?;
+
+        let max_fee = max_fee.or(self.config.max_deposit_claim_fee.clone());
+        match self.claim_utxo(&detailed_utxo, max_fee).await {
+            Ok(transfer_id) => {
+                let transfer = self.lookup_claim_transfer_with_retry(transfer_id).await?;
+                let payment: Payment = transfer.try_into()?;
+                // Insert the payment before returning so callers that
+                // immediately list payments see the claim.
+                let should_emit_event = self.storage.apply_payment_update(payment.clone()).await?;
+                self.storage
+                    .delete_deposit(detailed_utxo.txid.to_string(), detailed_utxo.vout)
+                    .await?;
+                self.event_emitter
+                    .emit_runtime_event(RuntimeEvent::DepositClaimed {
+                        payment: Box::new(payment.clone()),
+                        should_emit_event,
+                    })
+                    .await;
+                Ok(payment)
+            }
+            Err(e) => {
+                error!("Failed to claim deposit: {e:?}");
+                self.storage
+                    .update_deposit(
+                        detailed_utxo.txid.to_string(),
+                        detailed_utxo.vout,
+                        UpdateDepositPayload::ClaimError {
+                            error: e.clone().into(),
+                        },
+                    )
+                    .await?;
+                Err(e)
+            }
+        }
     }
-}
 
-impl BreezSdk {
     /// Looks up the transfer produced by a static deposit claim, retrying
     /// while the Spark operators have not yet indexed it. The SSP commits
     /// the claim synchronously, but there is a brief window before the
@@ -165,3 +543,37 @@ impl BreezSdk {
             .unwrap_or_else(|| SdkError::Generic("transfer not found after claim".to_string())))
     }
 }
+
+/// Sorts the addresses most recently issued first, followed by those with no
+/// recorded issue time.
+fn sort_deposit_address_history(history: &mut [DepositAddressInfo]) {
+    history.sort_by(|a, b| b.issued_at.cmp(&a.issued_at));
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_sort_deposit_address_history() {
+        let info = |address: &str, issued_at| DepositAddressInfo {
+            address: address.to_string(),
+            issued_at,
+            deposit_count: 0,
+            total_received_sats: 0,
+        };
+        let mut history = vec![
+            info("unknown", None),
+            info("first", Some(100)),
+            info("second", Some(200)),
+        ];
+
+        sort_deposit_address_history(&mut history);
+        let addresses: Vec<_> = history.iter().map(|i| i.address.as_str()).collect();
+        assert_eq!(addresses, vec!["second", "first", "unknown"]);
+    }
+}
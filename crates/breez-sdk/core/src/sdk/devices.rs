@@ -0,0 +1,110 @@
+use std::collections::HashMap;
+
+use crate::{
+    DeviceInfo, ListDevicesResponse, SetDeviceNameRequest,
+    error::SdkError,
+    persist::{ObjectCacheRepository, SyncedDevice},
+};
+
+use super::BreezSdk;
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Lists the devices running this wallet.
+    ///
+    /// Other devices are learned through real-time sync, each one reporting
+    /// its name and the last time it connected. Without real-time sync only
+    /// this device is listed.
+    pub async fn list_devices(&self) -> Result<ListDevicesResponse, SdkError> {
+        let cache = ObjectCacheRepository::new(self.storage.clone());
+        let current = DeviceInfo {
+            id: cache.fetch_or_create_device_id().await?,
+            name: cache.fetch_device_name().await?,
+            last_seen_at: self.now()?,
+            is_current: true,
+        };
+        let synced = cache.fetch_synced_devices().await?;
+        Ok(ListDevicesResponse {
+            devices: device_list(current, synced),
+        })
+    }
+
+    /// Names this device, e.g. "Work laptop", so that it can be told apart in
+    /// [`BreezSdk::list_devices`] on the other devices.
+    pub async fn set_device_name(&self, request: SetDeviceNameRequest) -> Result<(), SdkError> {
+        let name = request.name.trim();
+        if name.is_empty() {
+            return Err(SdkError::InvalidInput(
+                "Device name can't be empty".to_string(),
+            ));
+        }
+        ObjectCacheRepository::new(self.storage.clone())
+            .save_device_name(name)
+            .await?;
+        Ok(())
+    }
+}
+
+/// This device first, then the other devices from the most recently seen
+fn device_list(current: DeviceInfo, synced: HashMap<String, SyncedDevice>) -> Vec<DeviceInfo> {
+    let mut others: Vec<DeviceInfo> = synced
+        .into_iter()
+        .filter(|(id, _)| *id != current.id)
+        .map(|(id, device)| DeviceInfo {
+            id,
+            name: device.name,
+            last_seen_at: device.last_seen_at,
+            is_current: false,
+        })
+        .collect();
+    others.sort_by(|a, b| {
+        b.last_seen_at
+            .cmp(&a.last_seen_at)
+            .then_with(|| a.id.cmp(&b.id))
+    });
+    let mut devices = vec![current];
+    devices.extend(others);
+    devices
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn synced(name: &str, last_seen_at: u64) -> SyncedDevice {
+        SyncedDevice {
+            name: Some(name.to_string()),
+            last_seen_at,
+        }
+    }
+
+    #[test_all]
+    fn test_device_list_orders_current_first_then_most_recent() {
+        let current = DeviceInfo {
+            id: "current".to_string(),
+            name: None,
+            last_seen_at: 300,
+            is_current: true,
+        };
+        let synced = HashMap::from([
+            ("phone".to_string(), synced("Phone", 100)),
+            ("laptop".to_string(), synced("Laptop", 200)),
+            // This device's own record, echoed back by real-time sync
+            ("current".to_string(), synced("Stale", 50)),
+        ]);
+
+        let devices = device_list(current, synced);
+        let ids: Vec<&str> = devices.iter().map(|d| d.id.as_str()).collect();
+        assert_eq!(ids, vec!["current", "laptop", "phone"]);
+        assert!(devices[0].is_current);
+        assert_eq!(devices[0].name, None);
+        assert!(!devices[1].is_current);
+        assert_eq!(devices[1].name.as_deref(), Some("Laptop"));
+    }
+}
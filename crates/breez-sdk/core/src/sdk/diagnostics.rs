@@ -0,0 +1,119 @@
+use serde::Serialize;
+use tracing::{info, warn};
+
+use crate::{
+    Config, DumpDiagnosticsResponse, LeafStats, LogEntry, SetLogFilterRequest, error::SdkError,
+    logger,
+};
+
+use super::{BreezSdk, leaves};
+
+const REDACTED: &str = "<redacted>";
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Replaces the log filter of the sinks installed by `init_logging`.
+    ///
+    /// Lets support raise the logging of a misbehaving session to `debug`
+    /// without restarting the app. The filter stays in effect until it is set
+    /// again or the app restarts. Fails if logging wasn't initialized with
+    /// `init_logging` or `init_logging_with_config`.
+    pub async fn set_log_filter(&self, request: SetLogFilterRequest) -> Result<(), SdkError> {
+        logger::set_log_filter(&request.filter)?;
+        info!("Log filter set to {}", request.filter);
+        Ok(())
+    }
+
+    /// Produces a support bundle for troubleshooting a session.
+    ///
+    /// The bundle holds the SDK version, the config with its API key and
+    /// credentials redacted, the leaf statistics and the most recent error
+    /// log entries. It doesn't contain keys or the seed, and is safe to
+    /// share with support.
+    pub async fn dump_diagnostics(&self) -> Result<DumpDiagnosticsResponse, SdkError> {
+        // Diagnostics are wanted most when the wallet is unhealthy, so a
+        // failure to fetch the leaves doesn't fail the bundle
+        let leaf_stats = match leaves::leaf_stats(self).await {
+            Ok(stats) => Some(stats),
+            Err(e) => {
+                warn!("Failed to get leaf stats for diagnostics: {e:?}");
+                None
+            }
+        };
+        let bundle = DiagnosticsBundle {
+            sdk_version: crate::default_user_agent(),
+            network: self.config.network.to_string(),
+            config: format!("{:?}", redacted_config(&self.config)),
+            leaf_stats,
+            last_errors: logger::recent_errors(),
+        };
+        let bundle = serde_json::to_string_pretty(&bundle)
+            .map_err(|e| SdkError::Generic(format!("Failed to serialize diagnostics: {e}")))?;
+        Ok(DumpDiagnosticsResponse { bundle })
+    }
+}
+
+#[derive(Serialize)]
+struct DiagnosticsBundle {
+    sdk_version: String,
+    network: String,
+    config: String,
+    leaf_stats: Option<LeafStats>,
+    last_errors: Vec<LogEntry>,
+}
+
+/// A copy of the config with the values that grant access to services
+/// replaced, so it can be shared with support
+fn redacted_config(config: &Config) -> Config {
+    let mut config = config.clone();
+    if config.api_key.is_some() {
+        config.api_key = Some(REDACTED.to_string());
+    }
+    if let Some(credentials) = config
+        .faucet_config
+        .as_mut()
+        .and_then(|faucet| faucet.credentials.as_mut())
+    {
+        credentials.password = REDACTED.to_string();
+    }
+    // Parser URLs can embed API keys of the parser provider
+    for parser in config.external_input_parsers.iter_mut().flatten() {
+        parser.parser_url = REDACTED.to_string();
+    }
+    config
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::{Credentials, ExternalInputParser, FaucetConfig, Network, default_config};
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_redacted_config_hides_secrets() {
+        let mut config = default_config(Network::Regtest);
+        config.api_key = Some("secret-api-key".to_string());
+        config.faucet_config = Some(FaucetConfig {
+            url: "https://faucet.example.com".to_string(),
+            credentials: Some(Credentials {
+                username: "user".to_string(),
+                password: "secret-password".to_string(),
+            }),
+        });
+        config.external_input_parsers = Some(vec![ExternalInputParser {
+            provider_id: "provider".to_string(),
+            input_regex: ".*".to_string(),
+            parser_url: "https://parser.example.com/<input>?key=secret-parser-key".to_string(),
+        }]);
+
+        let redacted = format!("{:?}", redacted_config(&config));
+        assert!(!redacted.contains("secret-api-key"));
+        assert!(!redacted.contains("secret-password"));
+        assert!(!redacted.contains("secret-parser-key"));
+        assert!(redacted.contains("https://faucet.example.com"));
+    }
+}
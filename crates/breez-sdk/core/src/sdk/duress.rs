@@ -0,0 +1,104 @@
+use crate::{DuressConfig, Network, error::SdkError, utils::secret::SecretHash};
+
+/// Creates a duress configuration for `duress_pin`, to be persisted by the
+/// app and passed to `SdkBuilder::with_duress` on every connect.
+///
+/// Only a scrypt hash of the PIN is kept, but a short PIN can still be
+/// guessed from it offline, so the configuration should be stored like other
+/// app secrets. The decoy account number must differ from the account number
+/// of the real wallet: 0 on Regtest and 1 on all other networks, unless set
+/// with `SdkBuilder::with_account_number`.
+#[cfg_attr(feature = "uniffi", uniffi::export)]
+#[allow(clippy::needless_pass_by_value)]
+pub fn create_duress_config(
+    duress_pin: String,
+    decoy_account_number: u32,
+) -> Result<DuressConfig, SdkError> {
+    if duress_pin.is_empty() {
+        return Err(SdkError::InvalidInput(
+            "Duress PIN must not be empty".to_string(),
+        ));
+    }
+    Ok(DuressConfig {
+        pin_hash: SecretHash::new(&duress_pin)?.to_string(),
+        decoy_account_number,
+    })
+}
+
+/// Whether `pin` is the duress PIN of `duress_config`.
+#[cfg_attr(feature = "uniffi", uniffi::export)]
+#[allow(clippy::needless_pass_by_value)]
+pub fn is_duress_pin(duress_config: DuressConfig, pin: String) -> bool {
+    duress_config
+        .pin_hash
+        .parse::<SecretHash>()
+        .and_then(|hash| hash.matches(&pin))
+        .unwrap_or(false)
+}
+
+/// Resolves the account number to open with a duress configuration: the
+/// decoy account number when the duress PIN was entered, and
+/// `account_number` otherwise.
+///
+/// Fails when the decoy account number is the account number of the real
+/// wallet, whether or not the duress PIN was entered, so a misconfiguration
+/// shows up on the first connect rather than under duress.
+pub fn duress_account_number(
+    account_number: Option<u32>,
+    decoy_account_number: u32,
+    duress_pin_entered: bool,
+    network: Network,
+) -> Result<Option<u32>, SdkError> {
+    let real_account_number =
+        account_number.unwrap_or_else(|| spark_wallet::default_account_number(network.into()));
+    if decoy_account_number == real_account_number {
+        return Err(SdkError::InvalidInput(format!(
+            "Decoy account number {decoy_account_number} is the account number of the real wallet"
+        )));
+    }
+    Ok(if duress_pin_entered {
+        Some(decoy_account_number)
+    } else {
+        account_number
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_duress_pin_matches() {
+        let config = create_duress_config("1234".to_string(), 7).unwrap();
+        assert_eq!(config.decoy_account_number, 7);
+        assert!(is_duress_pin(config.clone(), "1234".to_string()));
+        assert!(!is_duress_pin(config.clone(), "4321".to_string()));
+        assert!(!is_duress_pin(config, String::new()));
+        assert!(create_duress_config(String::new(), 7).is_err());
+    }
+
+    #[test_all]
+    fn test_duress_account_number() {
+        assert_eq!(
+            duress_account_number(None, 7, true, Network::Mainnet).unwrap(),
+            Some(7)
+        );
+        assert_eq!(
+            duress_account_number(None, 7, false, Network::Mainnet).unwrap(),
+            None
+        );
+        assert_eq!(
+            duress_account_number(Some(3), 7, false, Network::Mainnet).unwrap(),
+            Some(3)
+        );
+        // The decoy must not be the real wallet, defaulted or set, even when
+        // the duress PIN wasn't entered
+        assert!(duress_account_number(None, 1, false, Network::Mainnet).is_err());
+        assert!(duress_account_number(None, 0, true, Network::Regtest).is_err());
+        assert!(duress_account_number(Some(7), 7, false, Network::Mainnet).is_err());
+    }
+}
@@ -0,0 +1,205 @@
+use std::collections::HashMap;
+
+use tracing::debug;
+
+use crate::{
+    ConvertFiatAmountRequest, ConvertFiatAmountResponse, LockExchangeRateRequest,
+    LockExchangeRateResponse, LockedExchangeRate, PrepareSendPaymentRequest, error::SdkError,
+};
+
+use super::BreezSdk;
+
+const DEFAULT_LOCK_TTL_SECS: u32 = 600;
+const MAX_LOCK_TTL_SECS: u32 = 3600;
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Locks the current exchange rate of a fiat currency for a checkout.
+    ///
+    /// Amounts converted with [`BreezSdk::convert_fiat_amount`] against the
+    /// returned lock use the same rate until it expires, so a checkout showing
+    /// a fiat price charges the sat amount that matches what was displayed even
+    /// if the market moves while the payment is made. Locks are held in memory
+    /// and don't survive [`BreezSdk::disconnect`].
+    pub async fn lock_exchange_rate(
+        &self,
+        request: LockExchangeRateRequest,
+    ) -> Result<LockExchangeRateResponse, SdkError> {
+        let ttl_secs = request.ttl_secs.unwrap_or(DEFAULT_LOCK_TTL_SECS);
+        if ttl_secs == 0 || ttl_secs > MAX_LOCK_TTL_SECS {
+            return Err(SdkError::InvalidInput(format!(
+                "ttl_secs must be between 1 and {MAX_LOCK_TTL_SECS}"
+            )));
+        }
+        let rate = self.live_fiat_rate(&request.currency).await?;
+
+        let now = self.now()?;
+        let locked_rate = LockedExchangeRate {
+            id: uuid::Uuid::now_v7().to_string(),
+            currency: request.currency,
+            rate,
+            expires_at: now.saturating_add(u64::from(ttl_secs)),
+        };
+        debug!(
+            "Locked {} rate {} until {}",
+            locked_rate.currency, locked_rate.rate, locked_rate.expires_at
+        );
+
+        let mut locked_rates = self.locked_exchange_rates.lock().await;
+        prune_expired(&mut locked_rates, now);
+        locked_rates.insert(locked_rate.id.clone(), locked_rate.clone());
+        Ok(LockExchangeRateResponse { locked_rate })
+    }
+
+    /// Converts a fiat amount to sats at a rate locked with
+    /// [`BreezSdk::lock_exchange_rate`].
+    ///
+    /// Use the result as the amount of `receive_payment`. Sends can set their
+    /// amount in fiat against the lock directly, with the `fiat_amount` of
+    /// `prepare_send_payment`. Fails once the lock has expired.
+    pub async fn convert_fiat_amount(
+        &self,
+        request: ConvertFiatAmountRequest,
+    ) -> Result<ConvertFiatAmountResponse, SdkError> {
+        let locked_rate = self.locked_exchange_rate(&request.locked_rate_id).await?;
+        let amount_sats = fiat_to_sats(request.fiat_amount, locked_rate.rate)?;
+        Ok(ConvertFiatAmountResponse {
+            amount_sats,
+            locked_rate,
+        })
+    }
+}
+
+impl BreezSdk {
+    /// Fetches the current rate of a fiat currency, in fiat units per bitcoin
+    pub(super) async fn live_fiat_rate(&self, currency: &str) -> Result<f64, SdkError> {
+        self.fiat_service
+            .fetch_fiat_rates()
+            .await?
+            .into_iter()
+            .find(|r| r.coin == currency)
+            .map(|r| r.value)
+            .ok_or_else(|| SdkError::InvalidInput(format!("No fiat rate for currency {currency}")))
+    }
+
+    /// Returns a lock that hasn't expired yet
+    pub(super) async fn locked_exchange_rate(
+        &self,
+        locked_rate_id: &str,
+    ) -> Result<LockedExchangeRate, SdkError> {
+        let now = self.now()?;
+        let mut locked_rates = self.locked_exchange_rates.lock().await;
+        prune_expired(&mut locked_rates, now);
+        locked_rates.get(locked_rate_id).cloned().ok_or_else(|| {
+            SdkError::InvalidInput("Exchange rate lock not found or expired".to_string())
+        })
+    }
+
+    /// Replaces the fiat amount of a send with its amount in sats at the
+    /// locked rate
+    pub(super) async fn resolve_fiat_amount(
+        &self,
+        mut request: PrepareSendPaymentRequest,
+    ) -> Result<PrepareSendPaymentRequest, SdkError> {
+        let Some(fiat_amount) = request.fiat_amount.take() else {
+            return Ok(request);
+        };
+        if request.amount.is_some() || request.token_identifier.is_some() {
+            return Err(SdkError::InvalidInput(
+                "fiat_amount can't be combined with amount or token_identifier".to_string(),
+            ));
+        }
+        let locked_rate = self
+            .locked_exchange_rate(&fiat_amount.locked_rate_id)
+            .await?;
+        let amount_sats = fiat_to_sats(fiat_amount.fiat_amount, locked_rate.rate)?;
+        if amount_sats == 0 {
+            return Err(SdkError::InvalidInput(
+                "fiat_amount converts to less than one sat".to_string(),
+            ));
+        }
+        debug!(
+            "Sending {} {} as {amount_sats} sats",
+            fiat_amount.fiat_amount, locked_rate.currency
+        );
+        request.amount = Some(u128::from(amount_sats));
+        Ok(request)
+    }
+}
+
+fn prune_expired(locked_rates: &mut HashMap<String, LockedExchangeRate>, now: u64) {
+    locked_rates.retain(|_, locked_rate| locked_rate.expires_at > now);
+}
+
+#[allow(
+    clippy::cast_possible_truncation,
+    clippy::cast_precision_loss,
+    clippy::cast_sign_loss
+)]
+pub(super) fn fiat_to_sats(fiat_amount: f64, rate: f64) -> Result<u64, SdkError> {
+    if !fiat_amount.is_finite() || fiat_amount < 0.0 {
+        return Err(SdkError::InvalidInput(
+            "fiat_amount must be a non-negative number".to_string(),
+        ));
+    }
+    if !rate.is_finite() || rate <= 0.0 {
+        return Err(SdkError::Generic(format!("Invalid exchange rate {rate}")));
+    }
+    let amount_sats = (fiat_amount / rate * 100_000_000f64).round();
+    if amount_sats > u64::MAX as f64 {
+        return Err(SdkError::InvalidInput(
+            "fiat_amount is too large".to_string(),
+        ));
+    }
+    Ok(amount_sats as u64)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn locked_rate(id: &str, expires_at: u64) -> LockedExchangeRate {
+        LockedExchangeRate {
+            id: id.to_string(),
+            currency: "EUR".to_string(),
+            rate: 50_000.0,
+            expires_at,
+        }
+    }
+
+    #[test_all]
+    fn test_fiat_to_sats_rounds_to_nearest_sat() {
+        assert_eq!(fiat_to_sats(10.0, 50_000.0).unwrap(), 20_000);
+        assert_eq!(fiat_to_sats(0.01, 60_000.0).unwrap(), 17);
+        assert_eq!(fiat_to_sats(0.0, 60_000.0).unwrap(), 0);
+    }
+
+    #[test_all]
+    fn test_fiat_to_sats_rejects_invalid_amounts() {
+        assert!(matches!(
+            fiat_to_sats(-1.0, 50_000.0),
+            Err(SdkError::InvalidInput(_))
+        ));
+        assert!(matches!(
+            fiat_to_sats(f64::NAN, 50_000.0),
+            Err(SdkError::InvalidInput(_))
+        ));
+        assert!(matches!(fiat_to_sats(10.0, 0.0), Err(SdkError::Generic(_))));
+    }
+
+    #[test_all]
+    fn test_prune_expired_keeps_live_locks() {
+        let mut locked_rates = HashMap::from([
+            ("expired".to_string(), locked_rate("expired", 100)),
+            ("live".to_string(), locked_rate("live", 101)),
+        ]);
+        prune_expired(&mut locked_rates, 100);
+        assert_eq!(locked_rates.len(), 1);
+        assert!(locked_rates.contains_key("live"));
+    }
+}
@@ -0,0 +1,166 @@
+use std::collections::HashMap;
+
+use platform_utils::{ContentType, add_basic_auth_header, add_content_type_header};
+use serde::{Deserialize, Serialize};
+use tracing::info;
+
+use crate::{
+    Network, ReceivePaymentMethod, ReceivePaymentRequest, RequestTestFundsRequest,
+    RequestTestFundsResponse, error::SdkError,
+};
+
+use super::BreezSdk;
+
+const REQUEST_FUNDS_QUERY: &str = "mutation RequestRegtestFunds($address: String!, $amount_sats: Long!) { \
+     request_regtest_funds(input: {address: $address, amount_sats: $amount_sats}) { transaction_hash } }";
+
+#[derive(Serialize)]
+struct GraphQlRequest<'a> {
+    #[serde(rename = "operationName")]
+    operation_name: &'a str,
+    variables: FaucetVariables<'a>,
+    query: &'a str,
+}
+
+#[derive(Serialize)]
+struct FaucetVariables<'a> {
+    amount_sats: u64,
+    address: &'a str,
+}
+
+#[derive(Deserialize)]
+struct GraphQlResponse {
+    data: Option<ResponseData>,
+    errors: Option<Vec<GraphQlError>>,
+}
+
+#[derive(Deserialize)]
+struct ResponseData {
+    request_regtest_funds: RequestRegtestFunds,
+}
+
+#[derive(Deserialize)]
+struct RequestRegtestFunds {
+    transaction_hash: String,
+}
+
+#[derive(Deserialize)]
+struct GraphQlError {
+    message: String,
+}
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Funds the wallet from the configured regtest faucet.
+    ///
+    /// The faucet sends the amount to a deposit address of the wallet, so the
+    /// funds arrive as an on-chain deposit and are claimed once confirmed,
+    /// like any other deposit. Only available on regtest.
+    pub async fn request_test_funds(
+        &self,
+        request: RequestTestFundsRequest,
+    ) -> Result<RequestTestFundsResponse, SdkError> {
+        if self.config.network != Network::Regtest {
+            return Err(SdkError::InvalidInput(
+                "Test funds can only be requested on regtest".to_string(),
+            ));
+        }
+        let Some(faucet) = &self.config.faucet_config else {
+            return Err(SdkError::Generic("No faucet is configured".to_string()));
+        };
+        if request.amount_sats == 0 {
+            return Err(SdkError::InvalidInput(
+                "Amount must be greater than 0".to_string(),
+            ));
+        }
+
+        let address = self
+            .receive_payment(ReceivePaymentRequest {
+                payment_method: ReceivePaymentMethod::BitcoinAddress { new_address: None },
+            })
+            .await?
+            .payment_request;
+        let body = serde_json::to_string(&GraphQlRequest {
+            operation_name: "RequestRegtestFunds",
+            variables: FaucetVariables {
+                amount_sats: request.amount_sats,
+                address: &address,
+            },
+            query: REQUEST_FUNDS_QUERY,
+        })
+        .map_err(|e| SdkError::Generic(format!("Failed to serialize faucet request: {e}")))?;
+
+        let mut headers = HashMap::new();
+        add_content_type_header(&mut headers, ContentType::Json);
+        if let Some(credentials) = &faucet.credentials {
+            add_basic_auth_header(&mut headers, &credentials.username, &credentials.password);
+        }
+        let response = self
+            .faucet_client
+            .post(faucet.url.clone(), Some(headers), Some(body))
+            .await
+            .map_err(|e| SdkError::NetworkError(format!("Failed to request test funds: {e}")))?;
+        if !response.is_success() {
+            return Err(SdkError::NetworkError(format!(
+                "Failed to request test funds: status {}",
+                response.status
+            )));
+        }
+
+        let txid = parse_faucet_response(&response.body)?;
+        info!(
+            "Requested {} sats from the faucet to {address}: {txid}",
+            request.amount_sats
+        );
+        Ok(RequestTestFundsResponse { address, txid })
+    }
+}
+
+fn parse_faucet_response(body: &str) -> Result<String, SdkError> {
+    let response: GraphQlResponse = serde_json::from_str(body)
+        .map_err(|e| SdkError::Generic(format!("Invalid faucet response: {e}")))?;
+    if let Some(errors) = response.errors
+        && !errors.is_empty()
+    {
+        let messages: Vec<String> = errors.into_iter().map(|e| e.message).collect();
+        return Err(SdkError::Generic(format!(
+            "Faucet returned errors: {}",
+            messages.join(", ")
+        )));
+    }
+    response
+        .data
+        .map(|data| data.request_regtest_funds.transaction_hash)
+        .ok_or(SdkError::Generic(
+            "Faucet response is missing data".to_string(),
+        ))
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_parse_faucet_response() {
+        assert_eq!(
+            parse_faucet_response(
+                r#"{"data":{"request_regtest_funds":{"transaction_hash":"abcd"}}}"#
+            )
+            .unwrap(),
+            "abcd"
+        );
+        assert!(
+            parse_faucet_response(r#"{"data":null,"errors":[{"message":"rate limited"}]}"#)
+                .unwrap_err()
+                .to_string()
+                .contains("rate limited")
+        );
+        assert!(parse_faucet_response(r#"{"data":null}"#).is_err());
+    }
+}
@@ -0,0 +1,102 @@
+use tracing::debug;
+
+use crate::{
+    FiatReceiveMethod, PaymentFiatValue, ReceiveFiatPaymentRequest, ReceiveFiatPaymentResponse,
+    ReceivePaymentMethod, ReceivePaymentRequest,
+    error::SdkError,
+    persist::{ObjectCacheRepository, PaymentMetadata},
+};
+
+use super::{BreezSdk, exchange_rate_lock::fiat_to_sats};
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Receives a payment whose amount is set in fiat.
+    ///
+    /// The fiat amount is converted to sats at the current rate, or at the
+    /// rate of a lock from [`BreezSdk::lock_exchange_rate`]. The fiat amount
+    /// and rate are recorded as the payment's
+    /// [`Payment::fiat_value`](crate::Payment::fiat_value) once it is
+    /// received, in place of the value at completion recorded for
+    /// [`Config::payment_fiat_currency`](crate::Config::payment_fiat_currency).
+    pub async fn receive_fiat_payment(
+        &self,
+        request: ReceiveFiatPaymentRequest,
+    ) -> Result<ReceiveFiatPaymentResponse, SdkError> {
+        let rate = match &request.locked_rate_id {
+            Some(locked_rate_id) => {
+                let locked_rate = self.locked_exchange_rate(locked_rate_id).await?;
+                if locked_rate.currency != request.currency {
+                    return Err(SdkError::InvalidInput(format!(
+                        "Exchange rate lock is for {}, not {}",
+                        locked_rate.currency, request.currency
+                    )));
+                }
+                locked_rate.rate
+            }
+            None => self.live_fiat_rate(&request.currency).await?,
+        };
+        let amount_sats = fiat_to_sats(request.fiat_amount, rate)?;
+        if amount_sats == 0 {
+            return Err(SdkError::InvalidInput(
+                "fiat_amount converts to less than one sat".to_string(),
+            ));
+        }
+
+        let payment_method = match request.payment_method {
+            FiatReceiveMethod::Bolt11Invoice {
+                description,
+                expiry_secs,
+            } => ReceivePaymentMethod::Bolt11Invoice {
+                description,
+                amount_sats: Some(amount_sats),
+                expiry_secs,
+                payment_hash: None,
+            },
+            FiatReceiveMethod::SparkInvoice {
+                description,
+                expiry_time,
+                sender_public_key,
+            } => ReceivePaymentMethod::SparkInvoice {
+                amount: Some(u128::from(amount_sats)),
+                token_identifier: None,
+                expiry_time,
+                description,
+                sender_public_key,
+                accept_partial_payments: None,
+            },
+        };
+        let response = self
+            .receive_payment(ReceivePaymentRequest { payment_method })
+            .await?;
+
+        let fiat_value = PaymentFiatValue {
+            currency: request.currency,
+            rate,
+            amount: request.fiat_amount,
+            recorded_at: self.now()?,
+        };
+        debug!(
+            "Receiving {} {} as {amount_sats} sats",
+            fiat_value.amount, fiat_value.currency
+        );
+        // Applied to the payment when it is synced, keyed by its invoice
+        ObjectCacheRepository::new(self.storage.clone())
+            .save_payment_metadata(
+                &response.payment_request,
+                &PaymentMetadata {
+                    fiat_value: Some(fiat_value.clone()),
+                    ..Default::default()
+                },
+            )
+            .await?;
+
+        Ok(ReceiveFiatPaymentResponse {
+            payment_request: response.payment_request,
+            fee: response.fee,
+            amount_sats,
+            fiat_value,
+        })
+    }
+}
@@ -0,0 +1,212 @@
+use std::{collections::HashMap, sync::Arc};
+
+use breez_sdk_common::fiat::FiatService;
+use tokio::sync::Mutex;
+use tracing::{debug, warn};
+
+use crate::{
+    Clock, Payment, PaymentDetails, PaymentFiatValue, Rate, TokenMetadata, TokenPriceService,
+    cross_chain::{CachedFiatService, DEFAULT_FIAT_CACHE_TTL},
+    error::SdkError,
+    events::{EventMiddleware, SdkEvent},
+    persist::{ObjectCacheRepository, PaymentMetadata, Storage},
+};
+
+use super::clock::{clock_now, clock_now_ms};
+
+/// Records the fiat value of payments as they succeed, at the live rate of
+/// the display currency user setting or else the configured currency, and
+/// attaches it to the forwarded event. Token payments are valued at the rate
+/// of the token price service.
+///
+/// The rates are cached for a minute, so that a burst of payments doesn't
+/// fetch them for each one.
+pub(crate) struct FiatValueMiddleware {
+    storage: Arc<dyn Storage>,
+    fiat_service: CachedFiatService,
+    token_price_service: Arc<dyn TokenPriceService>,
+    /// [`Config::payment_fiat_currency`](crate::Config::payment_fiat_currency)
+    default_currency: Option<String>,
+    clock: Option<Arc<dyn Clock>>,
+    /// The fiat rates of the tokens, by token identifier, with when they
+    /// expire in milliseconds since the epoch
+    token_rates: Mutex<HashMap<String, (Vec<Rate>, u128)>>,
+}
+
+#[macros::async_trait]
+impl EventMiddleware for FiatValueMiddleware {
+    async fn process(&self, event: SdkEvent) -> Option<SdkEvent> {
+        let SdkEvent::PaymentSucceeded { mut payment } = event else {
+            return Some(event);
+        };
+        if payment.fiat_value.is_none() {
+            // A value recorded when the payment was requested, such as by
+            // `receive_fiat_payment`, or synced from another device takes
+            // precedence, so a recorded value is never updated
+            if let Ok(stored) = self.storage.get_payment_by_id(payment.id.clone()).await
+                && stored.fiat_value.is_some()
+            {
+                payment.fiat_value = stored.fiat_value;
+                return Some(SdkEvent::PaymentSucceeded { payment });
+            }
+            match self.record(&payment).await {
+                Ok(fiat_value) => payment.fiat_value = fiat_value,
+                Err(e) => warn!(
+                    "Failed to record fiat value of payment {}: {e:?}",
+                    payment.id
+                ),
+            }
+        }
+        Some(SdkEvent::PaymentSucceeded { payment })
+    }
+}
+
+impl FiatValueMiddleware {
+    pub(crate) fn new(
+        storage: Arc<dyn Storage>,
+        fiat_service: Arc<dyn FiatService>,
+        token_price_service: Arc<dyn TokenPriceService>,
+        default_currency: Option<String>,
+        clock: Option<Arc<dyn Clock>>,
+    ) -> Self {
+        Self {
+            storage,
+            fiat_service: CachedFiatService::new(fiat_service, DEFAULT_FIAT_CACHE_TTL)
+                .with_clock(clock.clone()),
+            token_price_service,
+            default_currency,
+            clock,
+            token_rates: Mutex::new(HashMap::new()),
+        }
+    }
+
+    /// Returns the fiat rates of the token, fetched at most once per cache
+    /// period. The lock is held across the fetch, so that concurrent payments
+    /// of the token share it.
+    async fn token_fiat_rates(&self, token: &TokenMetadata) -> Result<Vec<Rate>, SdkError> {
+        let mut token_rates = self.token_rates.lock().await;
+        let now = clock_now_ms(self.clock.as_ref());
+        if let Some((rates, expires_at_ms)) = token_rates.get(&token.identifier)
+            && *expires_at_ms > now
+        {
+            return Ok(rates.clone());
+        }
+        let rates = self
+            .token_price_service
+            .fetch_token_fiat_rates(vec![token.clone()])
+            .await
+            .map_err(|e| SdkError::NetworkError(e.to_string()))?
+            .into_iter()
+            .find(|r| r.token_identifier == token.identifier)
+            .map(|r| r.rates)
+            .unwrap_or_default();
+        token_rates.retain(|_, (_, expires_at_ms)| *expires_at_ms > now);
+        token_rates.insert(
+            token.identifier.clone(),
+            (
+                rates.clone(),
+                now.saturating_add(DEFAULT_FIAT_CACHE_TTL.as_millis()),
+            ),
+        );
+        Ok(rates)
+    }
+
+    /// Records the value in the currency set at the time of settlement, if any
+    async fn record(&self, payment: &Payment) -> Result<Option<PaymentFiatValue>, SdkError> {
+        let display_currency = ObjectCacheRepository::new(Arc::clone(&self.storage))
+            .fetch_display_currency()
+            .await?;
+        let Some(currency) = display_currency.or_else(|| self.default_currency.clone()) else {
+            return Ok(None);
+        };
+        let (rate, decimals) = match &payment.details {
+            Some(PaymentDetails::Token { metadata, .. }) => {
+                let rate = self
+                    .token_fiat_rates(metadata)
+                    .await?
+                    .into_iter()
+                    .find(|r| r.coin == currency)
+                    .map(|r| r.value);
+                (rate, metadata.decimals)
+            }
+            _ => {
+                let rate = self
+                    .fiat_service
+                    .fetch_fiat_rates()
+                    .await?
+                    .into_iter()
+                    .find(|r| r.coin == currency)
+                    .map(|r| r.value);
+                (rate, BTC_DECIMALS)
+            }
+        };
+        let rate =
+            rate.ok_or_else(|| SdkError::Generic(format!("No fiat rate for currency {currency}")))?;
+        let fiat_value = payment_fiat_value(
+            payment.amount,
+            decimals,
+            &currency,
+            rate,
+            clock_now(self.clock.as_ref()),
+        );
+        debug!(
+            "Recording fiat value {} {} for payment {}",
+            fiat_value.amount, fiat_value.currency, payment.id
+        );
+        self.storage
+            .insert_payment_metadata(
+                payment.id.clone(),
+                PaymentMetadata {
+                    fiat_value: Some(fiat_value.clone()),
+                    ..Default::default()
+                },
+            )
+            .await?;
+        Ok(Some(fiat_value))
+    }
+}
+
+/// Decimals of a bitcoin amount in sats
+const BTC_DECIMALS: u32 = 8;
+
+/// Values an amount in base units at a rate per whole unit of `decimals`
+#[allow(clippy::cast_precision_loss)]
+fn payment_fiat_value(
+    amount: u128,
+    decimals: u32,
+    currency: &str,
+    rate: f64,
+    recorded_at: u64,
+) -> PaymentFiatValue {
+    PaymentFiatValue {
+        currency: currency.to_string(),
+        rate,
+        amount: amount as f64 * rate / 10f64.powi(i32::try_from(decimals).unwrap_or(i32::MAX)),
+        recorded_at,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_payment_fiat_value() {
+        let fiat_value = payment_fiat_value(250_000, BTC_DECIMALS, "USD", 60_000.0, 1_700_000_000);
+        assert_eq!(fiat_value.currency, "USD");
+        assert!((fiat_value.amount - 150.0).abs() < f64::EPSILON);
+        assert_eq!(fiat_value.recorded_at, 1_700_000_000);
+    }
+
+    #[test_all]
+    fn test_token_payment_fiat_value() {
+        // 2.5 tokens of 6 decimals at 0.99 per token
+        let fiat_value = payment_fiat_value(2_500_000, 6, "USD", 0.99, 1_700_000_000);
+        assert!((fiat_value.amount - 2.475).abs() < 1e-9);
+        assert!((fiat_value.rate - 0.99).abs() < f64::EPSILON);
+    }
+}
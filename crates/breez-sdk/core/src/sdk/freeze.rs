@@ -0,0 +1,107 @@
+use std::sync::Arc;
+
+use tokio::sync::Mutex;
+use tracing::{info, warn};
+
+use crate::{
+    FreezeWalletRequest, Storage, UnfreezeWalletRequest,
+    error::SdkError,
+    persist::{ObjectCacheRepository, WalletFreeze},
+    utils::secret::{FailedAttempts, SecretHash},
+};
+
+use super::BreezSdk;
+
+/// Serializes freezing and unfreezing, so concurrent unfreeze attempts can't
+/// each read the failed attempts before the others record theirs.
+static FREEZE_LOCK: Mutex<()> = Mutex::const_new(());
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Freezes the wallet, blocking every operation that moves funds out of
+    /// it until [`BreezSdk::unfreeze_wallet`] is called with the same
+    /// password.
+    ///
+    /// Receiving payments and the read APIs keep working. The freeze is
+    /// persisted, so it survives a restart. Only a scrypt hash of the
+    /// password is stored.
+    pub async fn freeze_wallet(&self, request: FreezeWalletRequest) -> Result<(), SdkError> {
+        if request.password.is_empty() {
+            return Err(SdkError::InvalidInput(
+                "Password must not be empty".to_string(),
+            ));
+        }
+        let _guard = FREEZE_LOCK.lock().await;
+        let cache = ObjectCacheRepository::new(self.storage.clone());
+        if cache.fetch_wallet_freeze().await?.is_some() {
+            return Err(SdkError::InvalidInput(
+                "Wallet is already frozen".to_string(),
+            ));
+        }
+
+        cache
+            .save_wallet_freeze(&WalletFreeze {
+                password_hash: SecretHash::new(&request.password)?,
+                frozen_at: self.now()?,
+                failed_attempts: FailedAttempts::default(),
+            })
+            .await?;
+        info!("Wallet frozen");
+        Ok(())
+    }
+
+    /// Lifts a freeze set with [`BreezSdk::freeze_wallet`].
+    ///
+    /// Wrong passwords are counted across restarts. After a few of them each
+    /// further attempt has to wait, doubling up to a day, and fails with
+    /// [`SdkError::TooManyAttempts`] until then.
+    pub async fn unfreeze_wallet(&self, request: UnfreezeWalletRequest) -> Result<(), SdkError> {
+        let _guard = FREEZE_LOCK.lock().await;
+        let cache = ObjectCacheRepository::new(self.storage.clone());
+        let Some(mut freeze) = cache.fetch_wallet_freeze().await? else {
+            return Err(SdkError::InvalidInput("Wallet is not frozen".to_string()));
+        };
+        let now = self.now()?;
+        freeze.failed_attempts.ensure_can_attempt(now)?;
+        if !freeze.password_hash.matches(&request.password)? {
+            warn!("Failed attempt to unfreeze the wallet");
+            freeze.failed_attempts.record_failure(now);
+            cache.save_wallet_freeze(&freeze).await?;
+            return Err(SdkError::InvalidInput("Invalid password".to_string()));
+        }
+
+        cache.delete_wallet_freeze().await?;
+        info!("Wallet unfrozen");
+        Ok(())
+    }
+}
+
+impl BreezSdk {
+    pub(super) async fn is_wallet_frozen(&self) -> Result<bool, SdkError> {
+        is_wallet_frozen(&self.storage).await
+    }
+
+    /// Fails with [`SdkError::WalletFrozen`] while the wallet is frozen. Called
+    /// before signing anything that moves funds out of the wallet.
+    pub(super) async fn ensure_not_frozen(&self) -> Result<(), SdkError> {
+        ensure_not_frozen(&self.storage).await
+    }
+}
+
+pub(crate) async fn is_wallet_frozen(storage: &Arc<dyn Storage>) -> Result<bool, SdkError> {
+    Ok(ObjectCacheRepository::new(storage.clone())
+        .fetch_wallet_freeze()
+        .await?
+        .is_some())
+}
+
+/// Fails with [`SdkError::WalletFrozen`] while the wallet is frozen. For the
+/// spend paths that run outside [`BreezSdk`], such as the token issuer and the
+/// stable balance conversions.
+pub(crate) async fn ensure_not_frozen(storage: &Arc<dyn Storage>) -> Result<(), SdkError> {
+    if is_wallet_frozen(storage).await? {
+        return Err(SdkError::WalletFrozen);
+    }
+    Ok(())
+}
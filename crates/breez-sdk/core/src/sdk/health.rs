@@ -0,0 +1,194 @@
+use std::fmt::Display;
+
+use base64::{Engine, engine::general_purpose::STANDARD as BASE64};
+use platform_utils::time::{Duration, Instant};
+use platform_utils::tokio;
+use tracing::{debug, warn};
+
+use crate::{
+    CheckHealthResponse, DependencyHealth, HealthDependency, HealthStatus, error::SdkError,
+    lnurl::LnurlServerError,
+};
+
+use super::BreezSdk;
+
+/// How long a dependency may take to answer before it's reported unhealthy
+const CHECK_TIMEOUT: Duration = Duration::from_secs(10);
+/// Username looked up on the LNURL server, which requires a valid API key
+const CHECK_USERNAME: &str = "healthcheck";
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl BreezSdk {
+    /// Checks the health of the SDK's external dependencies.
+    ///
+    /// Each dependency is sent a cheap read-only request, concurrently and with
+    /// a timeout, and reported with its status and latency. Server deployments
+    /// can use the result as a readiness probe, and apps can show it on a
+    /// status screen. Unlike [`get_spark_status`](crate::get_spark_status),
+    /// which reports the status published by Spark, this checks the services
+    /// as reached from this SDK instance.
+    pub async fn check_health(&self) -> Result<CheckHealthResponse, SdkError> {
+        let (spark_operators, service_provider, chain_service, fiat_service, lnurl) = tokio::join!(
+            check(self.spark_wallet.query_wallet_settings()),
+            check(self.spark_wallet.list_wallet_webhooks()),
+            check(self.chain_service.recommended_fees()),
+            check(self.fiat_service.fetch_fiat_currencies()),
+            self.check_lnurl_service(),
+        );
+        let (lnurl_service, api_key) = lnurl;
+
+        let dependencies = vec![
+            with_dependency(HealthDependency::SparkOperators, spark_operators),
+            with_dependency(HealthDependency::ServiceProvider, service_provider),
+            with_dependency(HealthDependency::ChainService, chain_service),
+            with_dependency(HealthDependency::LnurlService, lnurl_service),
+            with_dependency(HealthDependency::FiatService, fiat_service),
+            with_dependency(HealthDependency::ApiKey, api_key),
+        ];
+        for dependency in &dependencies {
+            if let HealthStatus::Unhealthy { error } = &dependency.status {
+                warn!(
+                    "Health check of {:?} failed: {error}",
+                    dependency.dependency
+                );
+            }
+        }
+        let healthy = !dependencies
+            .iter()
+            .any(|d| matches!(d.status, HealthStatus::Unhealthy { .. }));
+        debug!("Health check completed, healthy: {healthy}");
+        Ok(CheckHealthResponse {
+            healthy,
+            dependencies,
+        })
+    }
+}
+
+impl BreezSdk {
+    /// Checks the LNURL server, and the API key against it
+    async fn check_lnurl_service(&self) -> (CheckResult, CheckResult) {
+        let Some(client) = &self.lnurl_server_client else {
+            let api_key = match &self.config.api_key {
+                None => (HealthStatus::NotConfigured, None),
+                Some(api_key) if BASE64.decode(api_key).is_err() => {
+                    (unhealthy("API key is malformed"), None)
+                }
+                Some(_) => (
+                    HealthStatus::Unknown {
+                        reason: "No LNURL server to verify the API key with".to_string(),
+                    },
+                    None,
+                ),
+            };
+            return ((HealthStatus::NotConfigured, None), api_key);
+        };
+
+        let start = Instant::now();
+        let result = tokio::time::timeout(
+            CHECK_TIMEOUT,
+            client.check_username_available(CHECK_USERNAME),
+        )
+        .await;
+        let latency_ms = Some(elapsed_ms(start));
+        match result {
+            Ok(Ok(_)) => {
+                let api_key = match &self.config.api_key {
+                    None => HealthStatus::NotConfigured,
+                    Some(_) => HealthStatus::Healthy,
+                };
+                ((HealthStatus::Healthy, latency_ms), (api_key, latency_ms))
+            }
+            // The server answered, so it's the key rather than the server
+            // that is at fault
+            Ok(Err(LnurlServerError::InvalidApiKey)) => (
+                (HealthStatus::Healthy, latency_ms),
+                (unhealthy("API key was rejected"), latency_ms),
+            ),
+            Ok(Err(e)) => (
+                (unhealthy(e), latency_ms),
+                (
+                    HealthStatus::Unknown {
+                        reason: "LNURL server is unavailable".to_string(),
+                    },
+                    None,
+                ),
+            ),
+            Err(_) => (
+                (timed_out(), latency_ms),
+                (
+                    HealthStatus::Unknown {
+                        reason: "LNURL server is unavailable".to_string(),
+                    },
+                    None,
+                ),
+            ),
+        }
+    }
+}
+
+/// The status of a check and its latency, if a request was made
+type CheckResult = (HealthStatus, Option<u64>);
+
+async fn check<T, E: Display>(request: impl Future<Output = Result<T, E>>) -> CheckResult {
+    let start = Instant::now();
+    let status = match tokio::time::timeout(CHECK_TIMEOUT, request).await {
+        Ok(Ok(_)) => HealthStatus::Healthy,
+        Ok(Err(e)) => unhealthy(e),
+        Err(_) => timed_out(),
+    };
+    (status, Some(elapsed_ms(start)))
+}
+
+fn with_dependency(
+    dependency: HealthDependency,
+    (status, latency_ms): CheckResult,
+) -> DependencyHealth {
+    DependencyHealth {
+        dependency,
+        status,
+        latency_ms,
+    }
+}
+
+fn unhealthy(error: impl Display) -> HealthStatus {
+    HealthStatus::Unhealthy {
+        error: error.to_string(),
+    }
+}
+
+fn timed_out() -> HealthStatus {
+    HealthStatus::Unhealthy {
+        error: format!("Timed out after {}s", CHECK_TIMEOUT.as_secs()),
+    }
+}
+
+fn elapsed_ms(start: Instant) -> u64 {
+    u64::try_from(start.elapsed().as_millis()).unwrap_or(u64::MAX)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::async_test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[async_test_all]
+    async fn test_check_reports_success_with_latency() {
+        let (status, latency_ms) = check(async { Ok::<_, String>(()) }).await;
+        assert_eq!(status, HealthStatus::Healthy);
+        assert!(latency_ms.is_some());
+    }
+
+    #[async_test_all]
+    async fn test_check_reports_error() {
+        let (status, _) = check(async { Err::<(), _>("connection refused") }).await;
+        assert_eq!(
+            status,
+            HealthStatus::Unhealthy {
+                error: "connection refused".to_string()
+            }
+        );
+    }
+}
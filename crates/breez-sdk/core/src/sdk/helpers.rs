@@ -2,7 +2,7 @@ use base64::Engine;
 use bitcoin::hashes::{Hash, sha256};
 use breez_sdk_common::lnurl::{
     error::LnurlError,
-    pay::{AesSuccessActionDataResult, SuccessAction, SuccessActionProcessed},
+    pay::{SuccessAction, SuccessActionProcessed},
 };
 use spark_wallet::SparkWallet;
 use std::{str::FromStr, sync::Arc};
@@ -20,6 +20,8 @@ use crate::{
     utils::payments::update_balances,
 };
 
+use super::success_action::decrypt_aes_success_action;
+
 /// Looks up the payment matching `identifier` from storage, if present.
 ///
 /// Used as a fast-path check by `wait_for_incoming_payment` — if the
@@ -75,7 +77,6 @@ pub(crate) struct InternalEventListener {
 }
 
 impl InternalEventListener {
-    #[allow(unused)]
     pub fn new(tx: mpsc::Sender<SdkEvent>) -> Self {
         Self { tx }
     }
@@ -119,13 +120,7 @@ pub(crate) fn process_success_action(
 
     let preimage =
         sha256::Hash::from_str(preimage).map_err(|_| LnurlError::general("Invalid preimage"))?;
-    let preimage = preimage.as_byte_array();
-    let result: AesSuccessActionDataResult = match (data, preimage).try_into() {
-        Ok(data) => AesSuccessActionDataResult::Decrypted { data },
-        Err(e) => AesSuccessActionDataResult::ErrorStatus {
-            reason: e.to_string(),
-        },
-    };
+    let result = decrypt_aes_success_action(data, preimage.as_byte_array());
 
     Ok(Some(SuccessActionProcessed::Aes { result }))
 }
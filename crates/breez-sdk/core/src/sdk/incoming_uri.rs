@@ -0,0 +1,233 @@
+use breez_sdk_common::input::percent_encode;
+
+use crate::{
+    Bip21Details, HandleIncomingUriRequest, HandleIncomingUriResponse, IncomingUriAction,
+    InputType, error::SdkError,
+};
+
+use super::BreezSdk;
+
+/// Prefixes browsers require on the schemes a web app registers to handle
+const WEB_SCHEME_PREFIX: &str = "web+";
+/// Wraps a percent-encoded input, e.g. `breez:lnbc1...`
+const BREEZ_SCHEME: &str = "breez";
+/// Schemes wrapping an input [`BreezSdk::parse`] recognizes without them
+const WRAPPING_SCHEMES: [&str; 2] = ["spark", "lnurl"];
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Parses a URI the app was opened with, such as a deep link, and returns
+    /// the SDK call to continue with.
+    ///
+    /// Besides the inputs [`BreezSdk::parse`] recognizes, `spark:` and
+    /// `lnurl:` URIs, `web+` prefixed schemes and `breez:` deep links wrapping
+    /// a percent-encoded input are handled.
+    pub async fn handle_incoming_uri(
+        &self,
+        request: HandleIncomingUriRequest,
+    ) -> Result<HandleIncomingUriResponse, SdkError> {
+        let input = unwrap_uri(&request.uri)?;
+        let input_type = self.parse(&input).await?;
+        let action = action(&input_type);
+        Ok(HandleIncomingUriResponse { input_type, action })
+    }
+}
+
+/// Strips the schemes [`BreezSdk::parse`] doesn't recognize.
+fn unwrap_uri(uri: &str) -> Result<String, SdkError> {
+    let uri = uri.trim();
+    let uri = strip_prefix_ignore_case(uri, WEB_SCHEME_PREFIX).unwrap_or(uri);
+    if let Some(inner) = strip_scheme(uri, BREEZ_SCHEME) {
+        let inner = percent_encode::decode(inner)
+            .map_err(|e| SdkError::InvalidInput(format!("Invalid deep link: {e}")))?;
+        return unwrap_uri(&inner);
+    }
+    for scheme in WRAPPING_SCHEMES {
+        if let Some(inner) = strip_scheme(uri, scheme) {
+            return Ok(inner.to_string());
+        }
+    }
+    Ok(uri.to_string())
+}
+
+fn strip_scheme<'a>(uri: &'a str, scheme: &str) -> Option<&'a str> {
+    let rest = strip_prefix_ignore_case(uri, scheme)?.strip_prefix(':')?;
+    Some(rest.strip_prefix("//").unwrap_or(rest))
+}
+
+fn strip_prefix_ignore_case<'a>(input: &'a str, prefix: &str) -> Option<&'a str> {
+    let head = input.get(..prefix.len())?;
+    head.eq_ignore_ascii_case(prefix)
+        .then_some(&input[prefix.len()..])
+}
+
+fn action(input_type: &InputType) -> IncomingUriAction {
+    match input_type {
+        InputType::Bolt11Invoice(details) => IncomingUriAction::PrepareSendPayment {
+            input: details.invoice.bolt11.clone(),
+            amount_sats: None,
+        },
+        InputType::SparkInvoice(details) => IncomingUriAction::PrepareSendPayment {
+            input: details.invoice.clone(),
+            amount_sats: None,
+        },
+        InputType::SparkAddress(details) => IncomingUriAction::PrepareSendPayment {
+            input: details.address.clone(),
+            amount_sats: None,
+        },
+        InputType::BitcoinAddress(details) => IncomingUriAction::PrepareSendPayment {
+            input: details.address.clone(),
+            amount_sats: None,
+        },
+        InputType::LightningAddress(details) => IncomingUriAction::PrepareLnurlPay {
+            pay_request: details.pay_request.clone(),
+        },
+        InputType::LnurlPay(details) => IncomingUriAction::PrepareLnurlPay {
+            pay_request: details.clone(),
+        },
+        InputType::LnurlWithdraw(details) => IncomingUriAction::LnurlWithdraw {
+            withdraw_request: details.clone(),
+        },
+        InputType::LnurlAuth(details) => IncomingUriAction::LnurlAuth {
+            request_data: details.clone(),
+        },
+        InputType::CrossChainAddress(details) => IncomingUriAction::GetCrossChainRoutes {
+            address: details.clone(),
+        },
+        InputType::Url(url) => IncomingUriAction::OpenUrl { url: url.clone() },
+        InputType::Bip21(details) => bip21_action(details),
+        InputType::Bolt12Invoice(_)
+        | InputType::Bolt12Offer(_)
+        | InputType::Bolt12InvoiceRequest(_) => IncomingUriAction::Unsupported {
+            reason: "BOLT12 is not supported".to_string(),
+        },
+        InputType::SilentPaymentAddress(_) => IncomingUriAction::Unsupported {
+            reason: "Sending to a silent payment address is not supported".to_string(),
+        },
+    }
+}
+
+/// The cheapest payment method a BIP21 URI offers: Spark, then Lightning,
+/// then on-chain.
+pub(in crate::sdk) fn preferred_bip21_method(details: &Bip21Details) -> Option<&InputType> {
+    details
+        .payment_methods
+        .iter()
+        .filter_map(|method| bip21_preference(method).map(|rank| (rank, method)))
+        .min_by_key(|(rank, _)| *rank)
+        .map(|(_, method)| method)
+}
+
+/// Continues with the cheapest payment method the URI offers.
+fn bip21_action(details: &Bip21Details) -> IncomingUriAction {
+    let Some(method) = preferred_bip21_method(details) else {
+        return IncomingUriAction::Unsupported {
+            reason: "No supported payment method in the URI".to_string(),
+        };
+    };
+
+    match (action(method), method) {
+        (
+            IncomingUriAction::PrepareSendPayment { input, .. },
+            InputType::SparkAddress(_) | InputType::BitcoinAddress(_),
+        ) => IncomingUriAction::PrepareSendPayment {
+            input,
+            amount_sats: details.amount_sat,
+        },
+        (action, _) => action,
+    }
+}
+
+fn bip21_preference(method: &InputType) -> Option<u8> {
+    match method {
+        InputType::SparkInvoice(_) => Some(0),
+        InputType::SparkAddress(_) => Some(1),
+        InputType::Bolt11Invoice(_) => Some(2),
+        InputType::LightningAddress(_) | InputType::LnurlPay(_) => Some(3),
+        InputType::BitcoinAddress(_) => Some(4),
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::{BitcoinAddressDetails, BitcoinNetwork, PaymentRequestSource, SparkAddressDetails};
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_unwrap_uri() {
+        assert_eq!(unwrap_uri(" lnbc1abc ").unwrap(), "lnbc1abc");
+        assert_eq!(
+            unwrap_uri("lightning:lnbc1abc").unwrap(),
+            "lightning:lnbc1abc"
+        );
+        assert_eq!(
+            unwrap_uri("web+lightning:lnbc1abc").unwrap(),
+            "lightning:lnbc1abc"
+        );
+        assert_eq!(unwrap_uri("SPARK:sp1abc").unwrap(), "sp1abc");
+        assert_eq!(unwrap_uri("spark://sp1abc").unwrap(), "sp1abc");
+        assert_eq!(unwrap_uri("lnurl:LNURL1abc").unwrap(), "LNURL1abc");
+        // Not to be confused with the LUD-17 schemes
+        assert_eq!(
+            unwrap_uri("lnurlp://example.com/pay").unwrap(),
+            "lnurlp://example.com/pay"
+        );
+        assert_eq!(
+            unwrap_uri("breez:bitcoin%3Abc1qabc%3Famount%3D0.001").unwrap(),
+            "bitcoin:bc1qabc?amount=0.001"
+        );
+        assert_eq!(unwrap_uri("breez://spark%3Asp1abc").unwrap(), "sp1abc");
+        assert!(unwrap_uri("breez:%zz").is_err());
+    }
+
+    fn bitcoin_address() -> InputType {
+        InputType::BitcoinAddress(BitcoinAddressDetails {
+            address: "bc1qabc".to_string(),
+            network: BitcoinNetwork::Bitcoin,
+            source: PaymentRequestSource::default(),
+        })
+    }
+
+    fn spark_address() -> InputType {
+        InputType::SparkAddress(SparkAddressDetails {
+            address: "sp1abc".to_string(),
+            identity_public_key: "02abc".to_string(),
+            network: BitcoinNetwork::Bitcoin,
+            source: PaymentRequestSource::default(),
+        })
+    }
+
+    fn bip21(payment_methods: Vec<InputType>) -> Bip21Details {
+        Bip21Details {
+            amount_sat: Some(100_000),
+            asset_id: None,
+            uri: "bitcoin:bc1qabc?amount=0.001".to_string(),
+            extras: Vec::new(),
+            label: None,
+            message: None,
+            payment_methods,
+        }
+    }
+
+    #[test_all]
+    fn test_bip21_action_prefers_spark() {
+        let action = bip21_action(&bip21(vec![bitcoin_address(), spark_address()]));
+        let IncomingUriAction::PrepareSendPayment { input, amount_sats } = action else {
+            panic!("expected PrepareSendPayment, got {action:?}");
+        };
+        assert_eq!(input, "sp1abc");
+        assert_eq!(amount_sats, Some(100_000));
+    }
+
+    #[test_all]
+    fn test_bip21_action_without_supported_method() {
+        let action = bip21_action(&bip21(Vec::new()));
+        assert!(matches!(action, IncomingUriAction::Unsupported { .. }));
+    }
+}
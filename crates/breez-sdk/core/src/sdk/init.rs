@@ -1,11 +1,23 @@
 use platform_utils::tokio;
-use std::sync::Arc;
-use tokio::sync::{OnceCell, watch};
+use std::{
+    collections::{HashMap, HashSet},
+    sync::Arc,
+};
+use tokio::sync::{Mutex, OnceCell, watch};
 use tracing::{Instrument, error, info};
 
-use crate::{Network, error::SdkError, persist::ObjectCacheRepository};
+use crate::{
+    HostConditions, Network,
+    error::SdkError,
+    middleware::{MiddlewarePipeline, SettledPaymentListener},
+    persist::ObjectCacheRepository,
+    utils::parse_cache::ParseCache,
+};
 
-use super::{BreezSdk, BreezSdkParams, helpers::validate_breez_api_key};
+use super::{
+    BreezSdk, BreezSdkParams, SpendingCaps, helpers::validate_breez_api_key,
+    onchain_monitor::OnchainWithdrawalListener, service_status::ServiceStatusCache,
+};
 
 impl BreezSdk {
     /// Creates a new instance of the `BreezSdk`
@@ -20,6 +32,15 @@ impl BreezSdk {
         }
         let (initial_synced_sender, initial_synced_watcher) = watch::channel(false);
         let external_input_parsers = params.config.get_all_external_input_parsers();
+        let parse_cache = Arc::new(ParseCache::new(
+            params.config.parse_cache_ttl_secs,
+            params.clock.clone(),
+        ));
+        let spending_caps = Arc::new(SpendingCaps::new(
+            params.config.spending_caps.clone(),
+            params.storage.clone(),
+            params.clock.clone(),
+        ));
 
         let sdk = Self {
             config: params.config,
@@ -27,24 +48,56 @@ impl BreezSdk {
             storage: params.storage,
             chain_service: params.chain_service,
             fiat_service: params.fiat_service,
+            token_price_service: params.token_price_service,
+            issuer_approver: params.issuer_approver,
             lnurl_client: params.lnurl_client,
+            backup_client: params.backup_client,
+            faucet_client: params.faucet_client,
             lnurl_server_client: params.lnurl_server_client,
             lnurl_auth_signer: params.lnurl_auth_signer,
+            application_key_signer: params.application_key_signer,
+            state_backup_signer: params.state_backup_signer,
             event_emitter: params.event_emitter,
             shutdown_sender: params.shutdown_sender,
             runtime: params.runtime,
             sync_coordinator: params.sync_coordinator,
             initial_synced_watcher,
             external_input_parsers,
+            parse_cache,
             spark_private_mode_initialized: Arc::new(OnceCell::new()),
             token_converter: params.token_converter,
             stable_balance: params.stable_balance,
             buy_bitcoin_provider: params.buy_bitcoin_provider,
             cross_chain_context: params.cross_chain_context,
             lightning_sender: params.lightning_sender,
+            pending_payments: Arc::new(Mutex::new(HashSet::new())),
+            open_payment_streams: Arc::new(Mutex::new(HashSet::new())),
+            host_conditions: Arc::new(Mutex::new(HostConditions::default())),
+            locked_exchange_rates: Arc::new(Mutex::new(HashMap::new())),
+            spending_caps,
+            payment_middleware: Arc::new(MiddlewarePipeline::default()),
+            seed_backup: params.seed_backup,
+            plugins: Arc::new(params.plugins),
+            input_parsers: Arc::new(params.input_parsers),
+            jobs: Arc::new(Mutex::new(Vec::new())),
+            service_status: Arc::new(Mutex::new(ServiceStatusCache::default())),
+            clock: params.clock,
+            background_sync_paused: params.background_sync_paused,
         };
+        sdk.event_emitter
+            .add_internal_listener(Box::new(SettledPaymentListener {
+                pipeline: sdk.payment_middleware.clone(),
+            }))
+            .await;
+        sdk.event_emitter
+            .add_internal_listener(Box::new(OnchainWithdrawalListener {
+                storage: sdk.storage.clone(),
+                clock: sdk.clock.clone(),
+            }))
+            .await;
 
         sdk.start(initial_synced_sender).await;
+        sdk.start_plugins().await;
         Ok(sdk)
     }
 
@@ -126,6 +179,7 @@ impl BreezSdk {
         self.update_user_settings(crate::UpdateUserSettingsRequest {
             spark_private_mode_enabled: Some(true),
             stable_balance_active_label: None,
+            display_currency: None,
         })
         .await?;
         ObjectCacheRepository::new(self.storage.clone())
@@ -0,0 +1,191 @@
+use std::{collections::HashSet, sync::Arc};
+
+use breez_sdk_common::input::requires_network_lookup;
+use platform_utils::time::Duration;
+use platform_utils::tokio;
+use tracing::{debug, warn};
+
+use crate::{InputParser, InputParserInfo, InputParserPriority, InputType, error::SdkError};
+
+use super::BreezSdk;
+
+/// How long an input parser may take when its info doesn't set a timeout
+const DEFAULT_TIMEOUT_SECS: u32 = 10;
+
+/// The input parsers registered with `SdkBuilder::with_input_parser`, with
+/// the info each reported when the SDK was built
+pub(crate) struct InputParserRegistry {
+    parsers: Vec<(InputParserInfo, Arc<dyn InputParser>)>,
+}
+
+impl InputParserRegistry {
+    pub(crate) fn new(parsers: Vec<Arc<dyn InputParser>>) -> Result<Self, SdkError> {
+        let mut names = HashSet::new();
+        let parsers = parsers
+            .into_iter()
+            .map(|parser| {
+                let info = parser.info();
+                if info.name.trim().is_empty() {
+                    return Err(SdkError::InvalidInput(
+                        "Input parser name can't be empty".to_string(),
+                    ));
+                }
+                if !names.insert(info.name.clone()) {
+                    return Err(SdkError::InvalidInput(format!(
+                        "Input parser {} is registered more than once",
+                        info.name
+                    )));
+                }
+                if info.timeout_secs == Some(0) {
+                    return Err(SdkError::InvalidInput(format!(
+                        "Input parser {} timeout must be greater than 0",
+                        info.name
+                    )));
+                }
+                Ok((info, parser))
+            })
+            .collect::<Result<_, _>>()?;
+        Ok(Self { parsers })
+    }
+
+    fn has_priority(&self, priority: InputParserPriority) -> bool {
+        self.parsers
+            .iter()
+            .any(|(info, _)| info.priority == priority)
+    }
+}
+
+impl BreezSdk {
+    /// Parses the input with the built-in parsers, the configured
+    /// [`ExternalInputParser`](crate::ExternalInputParser)s and the input
+    /// parsers registered with `SdkBuilder::with_input_parser`, in the order
+    /// of their priority.
+    pub(super) async fn parse_with_input_parsers(
+        &self,
+        input: &str,
+    ) -> Result<InputType, SdkError> {
+        let trimmed = input.trim();
+        if let Some(input_type) = self
+            .run_input_parsers(trimmed, InputParserPriority::BeforeBuiltIn)
+            .await?
+        {
+            return Ok(input_type);
+        }
+
+        // Cache the inputs resolved with a network lookup, so that scanning
+        // the same code again is instant
+        let cacheable = self.parse_cache.is_enabled() && requires_network_lookup(trimmed);
+        if cacheable && let Some(input_type) = self.parse_cache.get(trimmed) {
+            return Ok(input_type);
+        }
+        let result = super::parse_input(input, Some(self.external_input_parsers.clone())).await;
+        if cacheable && let Ok(input_type) = &result {
+            self.parse_cache.insert(trimmed, input_type.clone());
+        }
+        if result.is_ok()
+            || !self
+                .input_parsers
+                .has_priority(InputParserPriority::AfterBuiltIn)
+        {
+            return result;
+        }
+        match self
+            .run_input_parsers(trimmed, InputParserPriority::AfterBuiltIn)
+            .await?
+        {
+            Some(input_type) => Ok(input_type),
+            None => result,
+        }
+    }
+
+    /// Runs the input parsers with the given priority in registration order,
+    /// returning the first recognized input. A parser that times out is
+    /// skipped, and all are cancelled if the SDK is disconnected.
+    async fn run_input_parsers(
+        &self,
+        input: &str,
+        priority: InputParserPriority,
+    ) -> Result<Option<InputType>, SdkError> {
+        if input.is_empty() {
+            return Ok(None);
+        }
+        let mut shutdown_receiver = self.shutdown_sender.subscribe();
+        let parsers = self
+            .input_parsers
+            .parsers
+            .iter()
+            .filter(|(info, _)| info.priority == priority);
+        for (info, parser) in parsers {
+            let timeout =
+                Duration::from_secs(u64::from(info.timeout_secs.unwrap_or(DEFAULT_TIMEOUT_SECS)));
+            let result = tokio::select! {
+                result = tokio::time::timeout(timeout, parser.parse(input.to_string())) => result,
+                _ = shutdown_receiver.changed() => {
+                    return Err(SdkError::Generic(
+                        "Parsing cancelled, the SDK was disconnected".to_string(),
+                    ));
+                }
+            };
+            match result {
+                Ok(Ok(Some(input_type))) => {
+                    debug!("Input recognized by input parser {}", info.name);
+                    return Ok(Some(input_type));
+                }
+                Ok(Ok(None)) => {}
+                Ok(Err(e)) => {
+                    return Err(SdkError::InvalidInput(format!(
+                        "Input parser {} failed: {e}",
+                        info.name
+                    )));
+                }
+                Err(_) => warn!("Input parser {} timed out", info.name),
+            }
+        }
+        Ok(None)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+    use crate::InputParserError;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    struct NamedParser(&'static str, Option<u32>);
+
+    #[macros::async_trait]
+    impl InputParser for NamedParser {
+        fn info(&self) -> InputParserInfo {
+            InputParserInfo {
+                name: self.0.to_string(),
+                priority: InputParserPriority::AfterBuiltIn,
+                timeout_secs: self.1,
+            }
+        }
+
+        async fn parse(&self, _input: String) -> Result<Option<InputType>, InputParserError> {
+            Ok(None)
+        }
+    }
+
+    fn registry(parsers: Vec<NamedParser>) -> Result<InputParserRegistry, SdkError> {
+        InputParserRegistry::new(
+            parsers
+                .into_iter()
+                .map(|p| Arc::new(p) as Arc<dyn InputParser>)
+                .collect(),
+        )
+    }
+
+    #[test_all]
+    fn test_registry_rejects_invalid_parsers() {
+        assert!(registry(vec![NamedParser("nfc", None), NamedParser("qr", Some(5))]).is_ok());
+        assert!(registry(vec![NamedParser(" ", None)]).is_err());
+        assert!(registry(vec![NamedParser("nfc", None), NamedParser("nfc", None)]).is_err());
+        assert!(registry(vec![NamedParser("nfc", Some(0))]).is_err());
+    }
+}
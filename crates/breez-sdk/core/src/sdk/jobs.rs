@@ -0,0 +1,340 @@
+use std::time::Duration;
+
+use platform_utils::tokio::{self, sync::watch};
+use tracing::{Instrument, debug};
+
+use crate::{
+    CancelJobRequest, GetJobRequest, GetJobResponse, Job, JobKind, JobRequest, JobResult,
+    JobStatus, ListJobsResponse, OptimizationMode, OptimizationOutcome, OptimizeLeavesRequest,
+    StartJobRequest, StartJobResponse, UnilateralExitLeafStage, error::SdkError, events::SdkEvent,
+    persist::ObjectCacheRepository,
+};
+
+use super::BreezSdk;
+
+/// Finished jobs kept for `get_job` and `list_jobs`, oldest dropped first
+const MAX_FINISHED_JOBS: usize = 50;
+
+/// How often a unilateral exit job checks the chain for progress
+const UNILATERAL_EXIT_POLL_INTERVAL: Duration = Duration::from_secs(60);
+
+pub(crate) struct JobEntry {
+    job: Job,
+    cancel_requested: bool,
+}
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Starts a long-running operation in the background and returns
+    /// immediately with the id of its job.
+    ///
+    /// The progress and outcome of the job are reported by
+    /// [`SdkEvent::JobUpdated`] events, and can be polled with
+    /// [`BreezSdk::get_job`]. Jobs are held in memory and are cancelled by
+    /// [`BreezSdk::disconnect`].
+    pub async fn start_job(&self, request: StartJobRequest) -> Result<StartJobResponse, SdkError> {
+        let (kind, total_steps) = match &request.job {
+            JobRequest::OptimizeLeaves => (JobKind::OptimizeLeaves, None),
+            JobRequest::ExportPayments { .. } => (JobKind::ExportPayments, Some(1)),
+            JobRequest::ExportLedger { .. } => (JobKind::ExportLedger, Some(1)),
+            JobRequest::UnilateralExit => (JobKind::UnilateralExit, None),
+            JobRequest::Conversion { request } => {
+                if request.prepare_response.conversion_estimate.is_none() {
+                    return Err(SdkError::InvalidInput(
+                        "The payment was not prepared with a conversion".to_string(),
+                    ));
+                }
+                (JobKind::Conversion, Some(1))
+            }
+        };
+        let now = self.now()?;
+        let job = Job {
+            id: uuid::Uuid::now_v7().to_string(),
+            kind,
+            status: JobStatus::Running,
+            completed_steps: 0,
+            total_steps,
+            created_at: now,
+            updated_at: now,
+        };
+        {
+            let mut jobs = self.jobs.lock().await;
+            prune_finished(&mut jobs);
+            jobs.push(JobEntry {
+                job: job.clone(),
+                cancel_requested: false,
+            });
+        }
+        debug!("Started job {} ({:?})", job.id, job.kind);
+        self.event_emitter
+            .emit(&SdkEvent::JobUpdated { job: job.clone() })
+            .await;
+
+        let sdk = self.clone();
+        let job_id = job.id.clone();
+        let span = tracing::Span::current();
+        tokio::spawn(
+            async move {
+                run_job(&sdk, &job_id, request.job).await;
+            }
+            .instrument(span),
+        );
+        Ok(StartJobResponse { job_id: job.id })
+    }
+
+    /// Returns a job started with [`BreezSdk::start_job`]
+    pub async fn get_job(&self, request: GetJobRequest) -> Result<GetJobResponse, SdkError> {
+        self.jobs
+            .lock()
+            .await
+            .iter()
+            .find(|entry| entry.job.id == request.job_id)
+            .map(|entry| GetJobResponse {
+                job: entry.job.clone(),
+            })
+            .ok_or_else(|| SdkError::InvalidInput("Job not found".to_string()))
+    }
+
+    /// Lists the running jobs and the most recently finished ones
+    pub async fn list_jobs(&self) -> Result<ListJobsResponse, SdkError> {
+        let jobs = self
+            .jobs
+            .lock()
+            .await
+            .iter()
+            .map(|entry| entry.job.clone())
+            .collect();
+        Ok(ListJobsResponse { jobs })
+    }
+
+    /// Cancels a running job.
+    ///
+    /// The job stops once its current step finishes, e.g. the optimization
+    /// round in progress, and is reported as [`JobStatus::Cancelled`].
+    pub async fn cancel_job(&self, request: CancelJobRequest) -> Result<(), SdkError> {
+        let mut jobs = self.jobs.lock().await;
+        let entry = jobs
+            .iter_mut()
+            .find(|entry| entry.job.id == request.job_id)
+            .ok_or_else(|| SdkError::InvalidInput("Job not found".to_string()))?;
+        if !matches!(entry.job.status, JobStatus::Running) {
+            return Err(SdkError::InvalidInput("Job is not running".to_string()));
+        }
+        entry.cancel_requested = true;
+        Ok(())
+    }
+}
+
+impl BreezSdk {
+    /// Applies `update` to a job and emits the updated job
+    async fn update_job(&self, job_id: &str, update: impl FnOnce(&mut Job)) {
+        let job = {
+            let mut jobs = self.jobs.lock().await;
+            let Some(entry) = jobs.iter_mut().find(|entry| entry.job.id == job_id) else {
+                return;
+            };
+            update(&mut entry.job);
+            entry.job.updated_at = self.now().unwrap_or(entry.job.updated_at);
+            entry.job.clone()
+        };
+        self.event_emitter.emit(&SdkEvent::JobUpdated { job }).await;
+    }
+
+    async fn job_cancel_requested(&self, job_id: &str) -> bool {
+        self.jobs
+            .lock()
+            .await
+            .iter()
+            .any(|entry| entry.job.id == job_id && entry.cancel_requested)
+    }
+}
+
+async fn run_job(sdk: &BreezSdk, job_id: &str, request: JobRequest) {
+    let shutdown = sdk.shutdown_sender.subscribe();
+    let status = match execute(sdk, job_id, request, &shutdown).await {
+        Ok(Some(result)) => JobStatus::Completed { result },
+        Ok(None) => JobStatus::Cancelled,
+        Err(e) => JobStatus::Failed {
+            error: e.to_string(),
+        },
+    };
+    debug!("Job {job_id} finished: {status:?}");
+    sdk.update_job(job_id, |job| {
+        match &status {
+            JobStatus::Completed {
+                result: JobResult::OptimizeLeaves { rounds_executed },
+            } => job.completed_steps = *rounds_executed,
+            JobStatus::Completed { .. } => {
+                job.completed_steps = job.total_steps.unwrap_or(job.completed_steps);
+            }
+            _ => {}
+        }
+        job.status = status;
+    })
+    .await;
+}
+
+/// Runs the steps of a job, checking for cancellation between them. Returns
+/// `None` if the job was cancelled.
+async fn execute(
+    sdk: &BreezSdk,
+    job_id: &str,
+    request: JobRequest,
+    shutdown: &watch::Receiver<()>,
+) -> Result<Option<JobResult>, SdkError> {
+    let result = match request {
+        JobRequest::OptimizeLeaves => {
+            let mut rounds_executed = 0u32;
+            loop {
+                if should_stop(sdk, job_id, shutdown).await {
+                    return Ok(None);
+                }
+                let outcome = sdk
+                    .optimize_leaves(OptimizeLeavesRequest {
+                        mode: OptimizationMode::SingleRound,
+                    })
+                    .await?
+                    .outcome;
+                match outcome {
+                    OptimizationOutcome::Completed {
+                        rounds_executed: last_rounds,
+                    } => {
+                        break JobResult::OptimizeLeaves {
+                            rounds_executed: rounds_executed.saturating_add(last_rounds),
+                        };
+                    }
+                    OptimizationOutcome::InProgress => {
+                        rounds_executed = rounds_executed.saturating_add(1);
+                        sdk.update_job(job_id, |job| job.completed_steps = rounds_executed)
+                            .await;
+                    }
+                }
+            }
+        }
+        JobRequest::ExportPayments { request } => JobResult::ExportPayments {
+            response: sdk.export_payments(request).await?,
+        },
+        JobRequest::ExportLedger { request } => JobResult::ExportLedger {
+            response: sdk.export_ledger(request).await?,
+        },
+        JobRequest::UnilateralExit => {
+            let cache = ObjectCacheRepository::new(sdk.storage.clone());
+            let mut shutdown = shutdown.clone();
+            loop {
+                if should_stop(sdk, job_id, &shutdown).await {
+                    return Ok(None);
+                }
+                sdk.check_unilateral_exit_progress().await;
+                let progress = cache.fetch_unilateral_exit().await?.ok_or_else(|| {
+                    SdkError::InvalidInput("No unilateral exit to follow".to_string())
+                })?;
+                let total = u32::try_from(progress.leaves.len()).unwrap_or(u32::MAX);
+                let swept = u32::try_from(
+                    progress
+                        .leaves
+                        .iter()
+                        .filter(|leaf| leaf.stage == UnilateralExitLeafStage::Swept)
+                        .count(),
+                )
+                .unwrap_or(u32::MAX);
+                sdk.update_job(job_id, |job| {
+                    job.completed_steps = swept;
+                    job.total_steps = Some(total);
+                })
+                .await;
+                if swept == total {
+                    break JobResult::UnilateralExit { progress };
+                }
+                tokio::select! {
+                    () = tokio::time::sleep(UNILATERAL_EXIT_POLL_INTERVAL) => {}
+                    _ = shutdown.changed() => return Ok(None),
+                }
+            }
+        }
+        JobRequest::Conversion { request } => {
+            if should_stop(sdk, job_id, shutdown).await {
+                return Ok(None);
+            }
+            // The payment can't be taken back once sent, so its result is
+            // always reported
+            return Ok(Some(JobResult::Conversion {
+                response: sdk.send_payment(request).await?,
+            }));
+        }
+    };
+    // A cancellation requested while the last step ran discards its result
+    if !matches!(
+        result,
+        JobResult::OptimizeLeaves { .. } | JobResult::UnilateralExit { .. }
+    ) && should_stop(sdk, job_id, shutdown).await
+    {
+        return Ok(None);
+    }
+    Ok(Some(result))
+}
+
+async fn should_stop(sdk: &BreezSdk, job_id: &str, shutdown: &watch::Receiver<()>) -> bool {
+    shutdown.has_changed().unwrap_or(true) || sdk.job_cancel_requested(job_id).await
+}
+
+/// Drops the oldest finished jobs beyond [`MAX_FINISHED_JOBS`]
+fn prune_finished(jobs: &mut Vec<JobEntry>) {
+    let finished = jobs
+        .iter()
+        .filter(|entry| !matches!(entry.job.status, JobStatus::Running))
+        .count();
+    let mut excess = finished.saturating_sub(MAX_FINISHED_JOBS);
+    jobs.retain(|entry| {
+        if excess > 0 && !matches!(entry.job.status, JobStatus::Running) {
+            excess -= 1;
+            return false;
+        }
+        true
+    });
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn entry(id: usize, status: JobStatus) -> JobEntry {
+        JobEntry {
+            job: Job {
+                id: id.to_string(),
+                kind: JobKind::ExportPayments,
+                status,
+                completed_steps: 0,
+                total_steps: Some(1),
+                created_at: 0,
+                updated_at: 0,
+            },
+            cancel_requested: false,
+        }
+    }
+
+    #[test_all]
+    fn test_prune_finished_keeps_running_jobs() {
+        let mut jobs: Vec<JobEntry> = (0..MAX_FINISHED_JOBS + 2)
+            .map(|id| entry(id, JobStatus::Cancelled))
+            .collect();
+        jobs.insert(0, entry(100, JobStatus::Running));
+        prune_finished(&mut jobs);
+
+        assert_eq!(jobs.len(), MAX_FINISHED_JOBS + 1);
+        // The running job is kept and the two oldest finished ones dropped
+        assert_eq!(jobs[0].job.id, "100");
+        assert_eq!(jobs[1].job.id, "2");
+    }
+
+    #[test_all]
+    fn test_prune_finished_below_limit_keeps_all() {
+        let mut jobs = vec![entry(0, JobStatus::Cancelled), entry(1, JobStatus::Running)];
+        prune_finished(&mut jobs);
+        assert_eq!(jobs.len(), 2);
+    }
+}
@@ -0,0 +1,206 @@
+use std::collections::{BTreeMap, HashMap, HashSet};
+
+use spark_wallet::{WalletLeaf, WalletLeaves};
+use tracing::error;
+
+use crate::{
+    LeafDenomination, LeafInfo, LeafState, LeafStats, ListLeavesResponse, error::SdkError,
+    persist::ObjectCacheRepository,
+};
+
+use super::BreezSdk;
+
+pub(super) async fn list_leaves(sdk: &BreezSdk) -> Result<ListLeavesResponse, SdkError> {
+    let wallet_leaves = sdk.spark_wallet.list_leaves().await?;
+    let first_seen = ObjectCacheRepository::new(sdk.storage.clone())
+        .fetch_leaf_first_seen()
+        .await?;
+
+    let leaves = leaf_infos(wallet_leaves, &first_seen, sdk.now()?);
+    let stats = leaf_stats_of(&leaves);
+    Ok(ListLeavesResponse { leaves, stats })
+}
+
+pub(super) async fn leaf_stats(sdk: &BreezSdk) -> Result<LeafStats, SdkError> {
+    let wallet_leaves = sdk.spark_wallet.list_leaves().await?;
+    Ok(leaf_stats_of(&leaf_infos(
+        wallet_leaves,
+        &HashMap::new(),
+        sdk.now()?,
+    )))
+}
+
+/// Records when leaves are first seen, so their age can be reported, and
+/// forgets the leaves the wallet no longer holds. Called during sync.
+pub(super) async fn record_leaves_first_seen(sdk: &BreezSdk) {
+    let result: Result<(), SdkError> = async {
+        let wallet_leaves = sdk.spark_wallet.list_leaves().await?;
+        let cache = ObjectCacheRepository::new(sdk.storage.clone());
+        let first_seen = cache.fetch_leaf_first_seen().await?;
+        let updated = update_first_seen(&first_seen, &wallet_leaves, sdk.now()?);
+        if updated != first_seen {
+            cache.save_leaf_first_seen(&updated).await?;
+        }
+        Ok(())
+    }
+    .await;
+    if let Err(e) = result {
+        error!("Failed to record leaves first seen: {e:?}");
+    }
+}
+
+fn update_first_seen(
+    first_seen: &HashMap<String, u64>,
+    wallet_leaves: &WalletLeaves,
+    now: u64,
+) -> HashMap<String, u64> {
+    states(wallet_leaves)
+        .map(|(leaf, _)| {
+            let id = leaf.id.to_string();
+            let seen_at = first_seen.get(&id).copied().unwrap_or(now);
+            (id, seen_at)
+        })
+        .collect()
+}
+
+fn leaf_infos(
+    wallet_leaves: WalletLeaves,
+    first_seen: &HashMap<String, u64>,
+    now: u64,
+) -> Vec<LeafInfo> {
+    // A leaf is reported once, in its first matching state
+    let mut seen = HashSet::new();
+    let mut leaves: Vec<LeafInfo> = states(&wallet_leaves)
+        .filter(|(leaf, _)| seen.insert(leaf.id.to_string()))
+        .map(|(leaf, state)| {
+            let id = leaf.id.to_string();
+            let age_secs = first_seen
+                .get(&id)
+                .map(|seen_at| now.saturating_sub(*seen_at));
+            LeafInfo {
+                id,
+                value_sats: leaf.value,
+                state,
+                age_secs,
+            }
+        })
+        .collect();
+    leaves.sort_by(|a, b| b.value_sats.cmp(&a.value_sats));
+    leaves
+}
+
+/// Pairs every leaf with its state. Reserved leaves come first, as they can
+/// also be listed with the state they had before being reserved.
+fn states(wallet_leaves: &WalletLeaves) -> impl Iterator<Item = (&WalletLeaf, LeafState)> {
+    with_state(&wallet_leaves.reserved_for_swap, LeafState::LockedForSwap)
+        .chain(with_state(
+            &wallet_leaves.reserved_for_payment,
+            LeafState::PendingTransfer,
+        ))
+        .chain(with_state(&wallet_leaves.available, LeafState::Available))
+        .chain(with_state(
+            &wallet_leaves.available_missing_from_operators,
+            LeafState::Available,
+        ))
+        .chain(with_state(
+            &wallet_leaves.not_available,
+            LeafState::Unavailable,
+        ))
+}
+
+fn with_state(
+    leaves: &[WalletLeaf],
+    state: LeafState,
+) -> impl Iterator<Item = (&WalletLeaf, LeafState)> {
+    leaves.iter().map(move |leaf| (leaf, state))
+}
+
+#[allow(clippy::cast_possible_truncation)]
+fn leaf_stats_of(leaves: &[LeafInfo]) -> LeafStats {
+    let available: Vec<u64> = leaves
+        .iter()
+        .filter(|leaf| leaf.state == LeafState::Available)
+        .map(|leaf| leaf.value_sats)
+        .collect();
+    let locked_leaf_count = leaves
+        .iter()
+        .filter(|leaf| {
+            matches!(
+                leaf.state,
+                LeafState::LockedForSwap | LeafState::PendingTransfer
+            )
+        })
+        .count();
+
+    let mut denominations: BTreeMap<u64, u32> = BTreeMap::new();
+    for value_sats in &available {
+        *denominations.entry(*value_sats).or_default() += 1;
+    }
+
+    LeafStats {
+        leaf_count: leaves.len() as u32,
+        available_leaf_count: available.len() as u32,
+        locked_leaf_count: locked_leaf_count as u32,
+        smallest_available_sats: available.iter().min().copied(),
+        largest_available_sats: available.iter().max().copied(),
+        available_denominations: denominations
+            .into_iter()
+            .rev()
+            .map(|(value_sats, count)| LeafDenomination { value_sats, count })
+            .collect(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn leaf(id: &str, value_sats: u64, state: LeafState) -> LeafInfo {
+        LeafInfo {
+            id: id.to_string(),
+            value_sats,
+            state,
+            age_secs: None,
+        }
+    }
+
+    #[test_all]
+    fn test_leaf_stats() {
+        let stats = leaf_stats_of(&[
+            leaf("a", 1024, LeafState::Available),
+            leaf("b", 256, LeafState::Available),
+            leaf("c", 256, LeafState::Available),
+            leaf("d", 2048, LeafState::LockedForSwap),
+            leaf("e", 512, LeafState::PendingTransfer),
+            leaf("f", 64, LeafState::Unavailable),
+        ]);
+
+        assert_eq!(stats.leaf_count, 6);
+        assert_eq!(stats.available_leaf_count, 3);
+        assert_eq!(stats.locked_leaf_count, 2);
+        assert_eq!(stats.smallest_available_sats, Some(256));
+        assert_eq!(stats.largest_available_sats, Some(1024));
+        assert_eq!(
+            stats.available_denominations,
+            vec![
+                LeafDenomination {
+                    value_sats: 1024,
+                    count: 1
+                },
+                LeafDenomination {
+                    value_sats: 256,
+                    count: 2
+                },
+            ]
+        );
+    }
+
+    #[test_all]
+    fn test_leaf_stats_empty() {
+        assert_eq!(leaf_stats_of(&[]), LeafStats::default());
+    }
+}
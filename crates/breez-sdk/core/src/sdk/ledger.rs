@@ -0,0 +1,329 @@
+use std::fmt::Write;
+
+use tracing::warn;
+
+use crate::{
+    ExportLedgerRequest, ExportLedgerResponse, GetLedgerRequest, GetLedgerResponse, LedgerEntry,
+    LedgerExportFormat, Payment, PaymentDetails, PaymentStatus, PaymentType, error::SdkError,
+    persist::StorageListPaymentsRequest,
+};
+
+use super::BreezSdk;
+
+const CSV_HEADER: &str =
+    "timestamp,payment_id,parent_payment_id,payment_type,method,credit,debit,fees,balance";
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Builds the ledger of balance changes for one asset.
+    ///
+    /// The ledger has an entry for every completed payment that changed the
+    /// balance, including the legs of conversions, in chronological order and
+    /// with the running balance after each entry.
+    ///
+    /// # Arguments
+    ///
+    /// * `request` - The asset and the optional time range of the ledger
+    ///
+    /// # Returns
+    ///
+    /// The ledger entries with the opening and closing balance of the range
+    pub async fn get_ledger(
+        &self,
+        request: GetLedgerRequest,
+    ) -> Result<GetLedgerResponse, SdkError> {
+        let payments = self.list_ledger_payments().await?;
+        Ok(build_ledger(payments, request))
+    }
+
+    /// Exports the ledger of balance changes for one asset.
+    ///
+    /// See [`BreezSdk::get_ledger`] for the entries included.
+    pub async fn export_ledger(
+        &self,
+        request: ExportLedgerRequest,
+    ) -> Result<ExportLedgerResponse, SdkError> {
+        let ledger = self
+            .get_ledger(GetLedgerRequest {
+                token_identifier: request.token_identifier,
+                from_timestamp: request.from_timestamp,
+                to_timestamp: request.to_timestamp,
+            })
+            .await?;
+        let data = match request.format {
+            LedgerExportFormat::Csv => ledger_to_csv(&ledger.entries),
+            LedgerExportFormat::Json => serde_json::to_string(&ledger)
+                .map_err(|e| SdkError::Generic(format!("Failed to serialize ledger: {e}")))?,
+        };
+        Ok(ExportLedgerResponse {
+            format: request.format,
+            data,
+        })
+    }
+}
+
+impl BreezSdk {
+    /// Returns all completed payments, including the child payments of
+    /// conversions, which `list_payments` leaves out.
+    pub(super) async fn list_ledger_payments(
+        &self,
+    ) -> Result<Vec<(Payment, Option<String>)>, SdkError> {
+        let payments = self
+            .storage
+            .list_payments(StorageListPaymentsRequest {
+                status_filter: Some(vec![PaymentStatus::Completed]),
+                sort_ascending: Some(true),
+                ..Default::default()
+            })
+            .await?;
+
+        let parent_ids: Vec<String> = payments
+            .iter()
+            .filter(|p| p.conversion_details.is_some())
+            .map(|p| p.id.clone())
+            .collect();
+        let children = if parent_ids.is_empty() {
+            std::collections::HashMap::default()
+        } else {
+            self.storage.get_payments_by_parent_ids(parent_ids).await?
+        };
+
+        let mut result: Vec<(Payment, Option<String>)> =
+            payments.into_iter().map(|p| (p, None)).collect();
+        for (parent_id, child_payments) in children {
+            result.extend(
+                child_payments
+                    .into_iter()
+                    .filter(|p| p.status == PaymentStatus::Completed)
+                    .map(|p| (p, Some(parent_id.clone()))),
+            );
+        }
+        Ok(result)
+    }
+}
+
+pub(super) fn payment_token_identifier(payment: &Payment) -> Option<&str> {
+    match &payment.details {
+        Some(PaymentDetails::Token { metadata, .. }) => Some(metadata.identifier.as_str()),
+        _ => None,
+    }
+}
+
+/// Folds the payments of the requested asset into ledger entries. Payments are
+/// paired with the id of their parent payment, if any.
+fn build_ledger(
+    mut payments: Vec<(Payment, Option<String>)>,
+    request: GetLedgerRequest,
+) -> GetLedgerResponse {
+    payments.retain(|(p, _)| payment_token_identifier(p) == request.token_identifier.as_deref());
+    payments.sort_by_key(|(p, _)| p.timestamp);
+
+    let mut balance: u128 = 0;
+    let mut opening_balance: u128 = 0;
+    let mut entries = Vec::new();
+    for (payment, parent_payment_id) in payments {
+        if request
+            .to_timestamp
+            .is_some_and(|to| payment.timestamp >= to)
+        {
+            break;
+        }
+
+        let (credit, debit) = match payment.payment_type {
+            PaymentType::Receive => (payment.amount, 0),
+            PaymentType::Send => (0, payment.amount.saturating_add(payment.fees)),
+        };
+        if debit > balance {
+            warn!(
+                "Ledger debit of {debit} for payment {} exceeds the running balance of {balance}",
+                payment.id
+            );
+        }
+        balance = balance.saturating_add(credit).saturating_sub(debit);
+
+        if request
+            .from_timestamp
+            .is_some_and(|from| payment.timestamp < from)
+        {
+            opening_balance = balance;
+            continue;
+        }
+
+        entries.push(LedgerEntry {
+            payment_id: payment.id,
+            parent_payment_id,
+            timestamp: payment.timestamp,
+            payment_type: payment.payment_type,
+            method: payment.method,
+            credit,
+            debit,
+            fees: payment.fees,
+            balance,
+        });
+    }
+
+    GetLedgerResponse {
+        token_identifier: request.token_identifier,
+        opening_balance,
+        closing_balance: entries.last().map_or(opening_balance, |e| e.balance),
+        entries,
+    }
+}
+
+fn ledger_to_csv(entries: &[LedgerEntry]) -> String {
+    let mut csv = String::from(CSV_HEADER);
+    csv.push('\n');
+    for entry in entries {
+        let _ = writeln!(
+            csv,
+            "{},{},{},{},{},{},{},{},{}",
+            entry.timestamp,
+            entry.payment_id,
+            entry.parent_payment_id.as_deref().unwrap_or_default(),
+            entry.payment_type,
+            entry.method,
+            entry.credit,
+            entry.debit,
+            entry.fees,
+            entry.balance
+        );
+    }
+    csv
+}
+
+#[cfg(test)]
+pub(crate) mod tests {
+    use super::*;
+    use crate::{PaymentMethod, TokenMetadata, TokenTransactionType};
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    /// A completed Spark payment, shared with the tests of other modules
+    pub(crate) fn payment(
+        id: &str,
+        payment_type: PaymentType,
+        amount: u128,
+        fees: u128,
+        ts: u64,
+    ) -> Payment {
+        Payment {
+            id: id.to_string(),
+            payment_type,
+            status: PaymentStatus::Completed,
+            amount,
+            fees,
+            timestamp: ts,
+            method: PaymentMethod::Spark,
+            details: None,
+            conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
+        }
+    }
+
+    /// A completed payment of the `btkn1` token, shared with the tests of
+    /// other modules
+    pub(crate) fn token_payment(
+        id: &str,
+        payment_type: PaymentType,
+        amount: u128,
+        ts: u64,
+        tx_type: TokenTransactionType,
+    ) -> Payment {
+        Payment {
+            method: PaymentMethod::Token,
+            details: Some(PaymentDetails::Token {
+                metadata: TokenMetadata {
+                    identifier: "btkn1".to_string(),
+                    issuer_public_key: String::new(),
+                    name: "Token".to_string(),
+                    ticker: "TKN".to_string(),
+                    decimals: 0,
+                    max_supply: 0,
+                    is_freezable: true,
+                },
+                tx_hash: String::new(),
+                tx_type,
+                invoice_details: None,
+                conversion_info: None,
+            }),
+            ..payment(id, payment_type, amount, 0, ts)
+        }
+    }
+
+    #[test_all]
+    fn test_build_ledger_running_balance() {
+        let payments = vec![
+            (payment("b", PaymentType::Send, 300, 10, 2), None),
+            (payment("a", PaymentType::Receive, 1_000, 0, 1), None),
+            (
+                payment("c", PaymentType::Receive, 50, 0, 3),
+                Some("b".to_string()),
+            ),
+        ];
+
+        let ledger = build_ledger(payments, GetLedgerRequest::default());
+
+        let balances: Vec<u128> = ledger.entries.iter().map(|e| e.balance).collect();
+        assert_eq!(balances, vec![1_000, 690, 740]);
+        assert_eq!(ledger.entries[1].debit, 310);
+        assert_eq!(ledger.entries[2].parent_payment_id.as_deref(), Some("b"));
+        assert_eq!(ledger.opening_balance, 0);
+        assert_eq!(ledger.closing_balance, 740);
+    }
+
+    #[test_all]
+    fn test_build_ledger_time_range() {
+        let payments = vec![
+            (payment("a", PaymentType::Receive, 1_000, 0, 1), None),
+            (payment("b", PaymentType::Send, 300, 10, 2), None),
+            (payment("c", PaymentType::Receive, 50, 0, 3), None),
+        ];
+
+        let ledger = build_ledger(
+            payments,
+            GetLedgerRequest {
+                token_identifier: None,
+                from_timestamp: Some(2),
+                to_timestamp: Some(3),
+            },
+        );
+
+        assert_eq!(ledger.entries.len(), 1);
+        assert_eq!(ledger.entries[0].payment_id, "b");
+        assert_eq!(ledger.opening_balance, 1_000);
+        assert_eq!(ledger.closing_balance, 690);
+    }
+
+    #[test_all]
+    fn test_build_ledger_filters_token_payments() {
+        let payments = vec![(payment("a", PaymentType::Receive, 1_000, 0, 1), None)];
+
+        let ledger = build_ledger(
+            payments,
+            GetLedgerRequest {
+                token_identifier: Some("token".to_string()),
+                ..Default::default()
+            },
+        );
+
+        assert!(ledger.entries.is_empty());
+        assert_eq!(ledger.closing_balance, 0);
+    }
+
+    #[test_all]
+    fn test_ledger_to_csv() {
+        let payments = vec![(payment("a", PaymentType::Receive, 1_000, 0, 1), None)];
+        let ledger = build_ledger(payments, GetLedgerRequest::default());
+
+        let csv = ledger_to_csv(&ledger.entries);
+
+        assert_eq!(
+            csv,
+            format!("{CSV_HEADER}\n1,a,,receive,spark,1000,0,0,1000\n")
+        );
+    }
+}
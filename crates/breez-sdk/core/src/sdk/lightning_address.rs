@@ -1,10 +1,16 @@
+use std::str::FromStr;
+
 use bitcoin::hex::DisplayHex;
+use bitcoin::secp256k1::XOnlyPublicKey;
 use lnurl_models::sanitize_username;
 
 use crate::{
     AuthorizeTransferRequest, CheckLightningAddressRequest, ClaimTransferRequest,
-    LightningAddressInfo, LnurlInfo, RegisterLightningAddressRequest, TransferAuthorization,
-    error::SdkError, lnurl::LnurlServerError, persist::ObjectCacheRepository,
+    DeleteLabeledLightningAddressRequest, LightningAddressInfo, LnurlInfo,
+    RegisterLightningAddressRequest, TransferAuthorization, UpdateLightningAddressNostrKeyRequest,
+    error::SdkError,
+    lnurl::{LnurlServerClient, LnurlServerError},
+    persist::ObjectCacheRepository,
 };
 
 use super::BreezSdk;
@@ -36,6 +42,11 @@ impl BreezSdk {
         Ok(cached.flatten())
     }
 
+    /// Registers a lightning address for the wallet. Without a label it
+    /// replaces the wallet's primary address, returned by
+    /// [`BreezSdk::get_lightning_address`]. With a label it's registered as an
+    /// additional address, for example to keep a business address apart from
+    /// a personal one.
     pub async fn register_lightning_address(
         &self,
         request: RegisterLightningAddressRequest,
@@ -48,6 +59,20 @@ impl BreezSdk {
         };
 
         let username = sanitize_username(&request.username);
+        let label = request.label.map(|label| label.trim().to_string());
+        if label.as_ref().is_some_and(String::is_empty) {
+            return Err(SdkError::InvalidInput(
+                "Lightning address label can't be empty".to_string(),
+            ));
+        }
+
+        if let (Some(min), Some(max)) = (request.min_sendable_sats, request.max_sendable_sats)
+            && min > max
+        {
+            return Err(SdkError::InvalidInput(
+                "Minimum sendable amount can't be more than the maximum".to_string(),
+            ));
+        }
 
         let description = match request.description {
             Some(description) => description,
@@ -57,6 +82,15 @@ impl BreezSdk {
         let params = crate::lnurl::RegisterLightningAddressRequest {
             username: username.clone(),
             description: description.clone(),
+            label: label.clone(),
+            image: request.image,
+            long_description: request.long_description,
+            min_sendable_msat: request
+                .min_sendable_sats
+                .map(|sats| sats.saturating_mul(1000)),
+            max_sendable_msat: request
+                .max_sendable_sats
+                .map(|sats| sats.saturating_mul(1000)),
         };
 
         let response = client.register_lightning_address(&params).await?;
@@ -65,11 +99,28 @@ impl BreezSdk {
             description,
             lnurl: LnurlInfo::new(response.lnurl),
             username,
+            label,
         };
-        cache.save_lightning_address(&address_info, false).await?;
+        // Only the primary address is cached
+        if address_info.label.is_none() {
+            cache.save_lightning_address(&address_info, false).await?;
+        }
         Ok(address_info)
     }
 
+    /// Lists the lightning addresses registered for the wallet, the primary
+    /// address first, followed by the labeled ones.
+    pub async fn list_lightning_addresses(&self) -> Result<Vec<LightningAddressInfo>, SdkError> {
+        let Some(client) = &self.lnurl_server_client else {
+            return Err(SdkError::Generic(
+                "LNURL server is not configured".to_string(),
+            ));
+        };
+
+        let response = client.list_lightning_addresses().await?;
+        Ok(response.addresses.into_iter().map(Into::into).collect())
+    }
+
     /// Authorize transferring the current owner's registered lightning address
     /// username to `request.transferee_pubkey`. Returns a
     /// [`TransferAuthorization`] to hand to the new owner, who
@@ -132,6 +183,7 @@ impl BreezSdk {
             description,
             lnurl: LnurlInfo::new(response.lnurl),
             username,
+            label: None,
         };
         cache.save_lightning_address(&address_info, false).await?;
         Ok(address_info)
@@ -174,6 +226,93 @@ impl BreezSdk {
         cache.delete_lightning_address(false).await?;
         Ok(())
     }
+
+    /// Deletes the address registered under `label`, leaving the primary
+    /// address, deleted with [`BreezSdk::delete_lightning_address`], and the
+    /// other labeled addresses in place.
+    pub async fn delete_labeled_lightning_address(
+        &self,
+        request: DeleteLabeledLightningAddressRequest,
+    ) -> Result<(), SdkError> {
+        let Some(client) = &self.lnurl_server_client else {
+            return Err(SdkError::Generic(
+                "LNURL server is not configured".to_string(),
+            ));
+        };
+
+        delete_labeled_address(client.as_ref(), &request.label).await
+    }
+
+    /// Attaches a nostr pubkey to a lightning address, or detaches it. The
+    /// LNURL server then serves NIP-05 verification for the address, and
+    /// only accepts zaps to it for that pubkey.
+    pub async fn update_lightning_address_nostr_key(
+        &self,
+        request: UpdateLightningAddressNostrKeyRequest,
+    ) -> Result<(), SdkError> {
+        let Some(client) = &self.lnurl_server_client else {
+            return Err(SdkError::Generic(
+                "LNURL server is not configured".to_string(),
+            ));
+        };
+
+        let nostr_pubkey = request
+            .nostr_pubkey
+            .map(|nostr_pubkey| {
+                XOnlyPublicKey::from_str(nostr_pubkey.trim())
+                    .map(|nostr_pubkey| nostr_pubkey.to_string())
+                    .map_err(|_| SdkError::InvalidInput("Invalid nostr pubkey".to_string()))
+            })
+            .transpose()?;
+        let username = match request.username {
+            Some(username) => sanitize_username(&username),
+            None => {
+                let Some(address_info) = self.get_lightning_address().await? else {
+                    return Err(SdkError::Generic(
+                        "No lightning address registered".to_string(),
+                    ));
+                };
+                address_info.username
+            }
+        };
+
+        client
+            .set_lightning_address_nostr_pubkey(
+                &crate::lnurl::SetLightningAddressNostrPubkeyRequest {
+                    username,
+                    nostr_pubkey,
+                },
+            )
+            .await?;
+        Ok(())
+    }
+}
+
+/// Unregisters the address registered under `label`. Labels are trimmed, as
+/// they are when registering.
+async fn delete_labeled_address(
+    client: &dyn LnurlServerClient,
+    label: &str,
+) -> Result<(), SdkError> {
+    let label = label.trim();
+    let Some(address) = client
+        .list_lightning_addresses()
+        .await?
+        .addresses
+        .into_iter()
+        .find(|address| address.label.as_deref() == Some(label))
+    else {
+        return Err(SdkError::InvalidInput(format!(
+            "No lightning address is registered under label '{label}'"
+        )));
+    };
+
+    client
+        .unregister_lightning_address(&crate::lnurl::UnregisterLightningAddressRequest {
+            username: address.username,
+        })
+        .await?;
+    Ok(())
 }
 
 // Private lightning address methods
@@ -211,6 +350,9 @@ mod tests {
 
     use crate::{LightningAddressInfo, LnurlInfo, persist::sqlite::SqliteStorage};
 
+    use super::delete_labeled_address;
+    use crate::SdkError;
+    use crate::lnurl::{LnurlServerClient, LnurlServerError};
     use crate::persist::ObjectCacheRepository;
 
     fn create_temp_dir(name: &str) -> PathBuf {
@@ -232,6 +374,7 @@ mod tests {
             username: "test".to_string(),
             description: "Test address".to_string(),
             lnurl: LnurlInfo::new("https://example.com/.well-known/lnurlp/test".to_string()),
+            label: None,
         }
     }
 
@@ -282,4 +425,121 @@ mod tests {
             .expect("Expected Some(Some(info)) after save");
         assert_eq!(info.lightning_address, "test@example.com");
     }
+
+    /// Serves the addresses of one wallet and records the unregistered
+    /// usernames.
+    struct MockLnurlServerClient {
+        addresses: Vec<(String, Option<String>)>,
+        unregistered: std::sync::Mutex<Vec<String>>,
+    }
+
+    #[macros::async_trait]
+    impl LnurlServerClient for MockLnurlServerClient {
+        fn domain(&self) -> &str {
+            "example.com"
+        }
+
+        async fn check_username_available(&self, _: &str) -> Result<bool, LnurlServerError> {
+            unimplemented!()
+        }
+
+        async fn recover_lightning_address(
+            &self,
+        ) -> Result<Option<lnurl_models::RecoverLnurlPayResponse>, LnurlServerError> {
+            unimplemented!()
+        }
+
+        async fn list_lightning_addresses(
+            &self,
+        ) -> Result<lnurl_models::ListLnurlPayResponse, LnurlServerError> {
+            let addresses = self
+                .addresses
+                .iter()
+                .map(|(username, label)| lnurl_models::RegisteredLnurlPay {
+                    lnurl: format!("https://example.com/lnurlp/{username}"),
+                    lightning_address: format!("{username}@example.com"),
+                    username: username.clone(),
+                    description: String::new(),
+                    label: label.clone(),
+                })
+                .collect();
+            Ok(lnurl_models::ListLnurlPayResponse { addresses })
+        }
+
+        async fn register_lightning_address(
+            &self,
+            _: &crate::lnurl::RegisterLightningAddressRequest,
+        ) -> Result<lnurl_models::RegisterLnurlPayResponse, LnurlServerError> {
+            unimplemented!()
+        }
+
+        async fn transfer_lightning_address(
+            &self,
+            _: &crate::lnurl::TransferLightningAddressRequest,
+        ) -> Result<lnurl_models::RegisterLnurlPayResponse, LnurlServerError> {
+            unimplemented!()
+        }
+
+        async fn unregister_lightning_address(
+            &self,
+            request: &crate::lnurl::UnregisterLightningAddressRequest,
+        ) -> Result<(), LnurlServerError> {
+            self.unregistered
+                .lock()
+                .unwrap()
+                .push(request.username.clone());
+            Ok(())
+        }
+
+        async fn set_lightning_address_nostr_pubkey(
+            &self,
+            _: &crate::lnurl::SetLightningAddressNostrPubkeyRequest,
+        ) -> Result<(), LnurlServerError> {
+            unimplemented!()
+        }
+
+        async fn list_metadata(
+            &self,
+            _: &crate::lnurl::ListMetadataRequest,
+        ) -> Result<lnurl_models::ListMetadataResponse, LnurlServerError> {
+            unimplemented!()
+        }
+
+        async fn create_payment_link(
+            &self,
+            _: &crate::lnurl::CreatePaymentLinkRequest,
+        ) -> Result<lnurl_models::PaymentLink, LnurlServerError> {
+            unimplemented!()
+        }
+
+        async fn list_payment_links(
+            &self,
+            _: &crate::lnurl::ListPaymentLinksRequest,
+        ) -> Result<lnurl_models::ListPaymentLinksResponse, LnurlServerError> {
+            unimplemented!()
+        }
+
+        async fn delete_payment_link(&self, _: &str) -> Result<(), LnurlServerError> {
+            unimplemented!()
+        }
+    }
+
+    #[tokio::test]
+    async fn test_delete_labeled_address_unregisters_only_that_label() {
+        let client = MockLnurlServerClient {
+            addresses: vec![
+                ("alice".to_string(), None),
+                ("alice.shop".to_string(), Some("shop".to_string())),
+                ("alice.work".to_string(), Some("work".to_string())),
+            ],
+            unregistered: std::sync::Mutex::new(Vec::new()),
+        };
+
+        delete_labeled_address(&client, " shop ").await.unwrap();
+        assert_eq!(*client.unregistered.lock().unwrap(), vec!["alice.shop"]);
+
+        let err = delete_labeled_address(&client, "home").await.unwrap_err();
+        assert!(matches!(err, SdkError::InvalidInput(_)));
+        assert_eq!(client.unregistered.lock().unwrap().len(), 1);
+    }
 }
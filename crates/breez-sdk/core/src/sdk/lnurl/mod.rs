@@ -3,15 +3,19 @@ use breez_sdk_common::lnurl::{self, error::LnurlError};
 use crate::{
     BuildUnsignedLnurlPayPackageRequest, LnurlAuthRequestDetails, LnurlCallbackStatus,
     LnurlPayRequest, LnurlPayResponse, LnurlWithdrawInfo, LnurlWithdrawRequest,
-    LnurlWithdrawResponse, PrepareLnurlPayRequest, PrepareLnurlPayResponse,
-    PublishSignedLnurlPayPackageRequest, PublishSignedLnurlPayResponse, UnsignedTransferPackage,
-    WaitForPaymentIdentifier,
+    LnurlWithdrawResponse, PaymentRail, PaymentStage, PrepareLnurlPayRequest,
+    PrepareLnurlPayResponse, PublishSignedLnurlPayPackageRequest, PublishSignedLnurlPayResponse,
+    UnsignedTransferPackage, WaitForPaymentIdentifier,
     error::SdkError,
-    persist::{ObjectCacheRepository, PaymentMetadata},
+    persist::{
+        CachedIdempotencyRecord, IdempotentOperation, ObjectCacheRepository, PaymentMetadata,
+    },
+    utils::idempotency::begin_idempotent_operation,
 };
 use breez_sdk_common::lnurl::withdraw::execute_lnurl_withdraw;
+use tracing::warn;
 
-use super::BreezSdk;
+use super::{BreezSdk, CappedSend, payments};
 
 mod pay;
 
@@ -22,10 +26,43 @@ impl BreezSdk {
         &self,
         request: PrepareLnurlPayRequest,
     ) -> Result<PrepareLnurlPayResponse, SdkError> {
-        pay::prepare(self, request).await
+        self.ensure_rail_enabled(PaymentRail::Lightning)?;
+        self.payment_middleware
+            .run(PaymentStage::BeforePrepareLnurlPay {
+                request: request.clone(),
+            })
+            .await?;
+        let mut response = pay::prepare(self, request).await?;
+        response.duplicate_payment = payments::duplicate::find_duplicate_lnurl_payment(
+            self,
+            &response.pay_request,
+            response.amount_sats,
+        )
+        .await
+        .unwrap_or_else(|e| {
+            warn!("Failed to check for a duplicate payment: {e:?}");
+            None
+        });
+        Ok(response)
     }
 
     pub async fn lnurl_pay(&self, request: LnurlPayRequest) -> Result<LnurlPayResponse, SdkError> {
+        self.ensure_rail_enabled(PaymentRail::Lightning)?;
+        let _reservation = self
+            .spending_caps
+            .reserve(CappedSend {
+                rail: Some(PaymentRail::Lightning),
+                token_identifier: None,
+                plugin: false,
+                amount: u128::from(request.prepare_response.amount_sats)
+                    .saturating_add(request.prepare_response.fee_sats.into()),
+            })
+            .await?;
+        self.payment_middleware
+            .run(PaymentStage::BeforeLnurlPay {
+                prepare_response: request.prepare_response.clone(),
+            })
+            .await?;
         pay::send(self, request).await
     }
 
@@ -73,11 +110,13 @@ impl BreezSdk {
         &self,
         request: LnurlWithdrawRequest,
     ) -> Result<LnurlWithdrawResponse, SdkError> {
+        self.ensure_rail_enabled(PaymentRail::Lightning)?;
         self.maybe_ensure_spark_private_mode_initialized().await?;
         let LnurlWithdrawRequest {
             amount_sats,
             withdraw_request,
             completion_timeout_secs,
+            idempotency_key,
         } = request;
         let withdraw_request: breez_sdk_common::lnurl::withdraw::LnurlWithdrawRequestDetails =
             withdraw_request.into();
@@ -87,45 +126,81 @@ impl BreezSdk {
             ));
         }
 
-        // Generate a Lightning invoice for the withdraw, keeping the SSP-side
-        // receive id for the targeted wait below.
-        let receive = self
-            .receive_bolt11_invoice_inner(
-                withdraw_request.default_description.clone(),
-                Some(amount_sats),
-                None,
-                None,
-            )
-            .await?;
-        let payment_request = receive.invoice.clone();
-        let ssp_receive_id = receive.id;
-
-        // Store the LNURL withdraw metadata before executing the withdraw
         let cache = ObjectCacheRepository::new(self.storage.clone());
-        cache
-            .save_payment_metadata(
+        let previous = match idempotency_key.as_deref() {
+            Some(key) => {
+                begin_idempotent_operation(&cache, key, IdempotentOperation::LnurlWithdraw).await?
+            }
+            None => None,
+        };
+
+        let (payment_request, ssp_receive_id, callback_done) = match previous {
+            // Reuse the invoice of a previous attempt, so the service can pay
+            // at most once however many times the withdraw is retried.
+            Some(CachedIdempotencyRecord {
+                withdraw_invoice: Some(invoice),
+                withdraw_ssp_receive_id: Some(ssp_receive_id),
+                completed,
+                ..
+            }) => (invoice, ssp_receive_id, completed),
+            _ => {
+                // Generate a Lightning invoice for the withdraw, keeping the SSP-side
+                // receive id for the targeted wait below.
+                let receive = self
+                    .receive_bolt11_invoice_inner(
+                        withdraw_request.default_description.clone(),
+                        Some(amount_sats),
+                        None,
+                        None,
+                    )
+                    .await?;
+
+                // Store the LNURL withdraw metadata before executing the withdraw
+                cache
+                    .save_payment_metadata(
+                        &receive.invoice,
+                        &PaymentMetadata {
+                            lnurl_withdraw_info: Some(LnurlWithdrawInfo {
+                                withdraw_url: withdraw_request.callback.clone(),
+                            }),
+                            lnurl_description: Some(withdraw_request.default_description.clone()),
+                            ..Default::default()
+                        },
+                    )
+                    .await?;
+
+                if let Some(key) = idempotency_key.as_deref() {
+                    let mut record =
+                        CachedIdempotencyRecord::new(IdempotentOperation::LnurlWithdraw);
+                    record.withdraw_invoice = Some(receive.invoice.clone());
+                    record.withdraw_ssp_receive_id = Some(receive.id.clone());
+                    cache.save_idempotency_record(key, &record).await?;
+                }
+                (receive.invoice, receive.id, false)
+            }
+        };
+
+        if !callback_done {
+            // Perform the LNURL withdraw using the generated invoice
+            let withdraw_response = execute_lnurl_withdraw(
+                self.lnurl_client.as_ref(),
+                &withdraw_request,
                 &payment_request,
-                &PaymentMetadata {
-                    lnurl_withdraw_info: Some(LnurlWithdrawInfo {
-                        withdraw_url: withdraw_request.callback.clone(),
-                    }),
-                    lnurl_description: Some(withdraw_request.default_description.clone()),
-                    ..Default::default()
-                },
             )
             .await?;
+            if let lnurl::withdraw::ValidatedCallbackResponse::EndpointError { data } =
+                withdraw_response
+            {
+                return Err(LnurlError::EndpointError(data.reason).into());
+            }
 
-        // Perform the LNURL withdraw using the generated invoice
-        let withdraw_response = execute_lnurl_withdraw(
-            self.lnurl_client.as_ref(),
-            &withdraw_request,
-            &payment_request,
-        )
-        .await?;
-        if let lnurl::withdraw::ValidatedCallbackResponse::EndpointError { data } =
-            withdraw_response
-        {
-            return Err(LnurlError::EndpointError(data.reason).into());
+            if let Some(key) = idempotency_key.as_deref() {
+                let mut record = CachedIdempotencyRecord::new(IdempotentOperation::LnurlWithdraw);
+                record.completed = true;
+                record.withdraw_invoice = Some(payment_request.clone());
+                record.withdraw_ssp_receive_id = Some(ssp_receive_id.clone());
+                cache.save_idempotency_record(key, &record).await?;
+            }
         }
 
         let completion_timeout_secs = match completion_timeout_secs {
@@ -5,19 +5,22 @@ use breez_sdk_common::lnurl::{
 use tracing::info;
 
 use crate::{
-    ConversionEstimate, ConversionType, FeePolicy, InputType, LnurlPayContext, LnurlPayInfo,
-    LnurlPayRequest, LnurlPayRequestDetails, LnurlPayResponse, PrepareLnurlPayRequest,
-    PrepareLnurlPayResponse, PublishSignedLnurlPayResponse, SendPaymentMethod,
-    SignedTransferPackage, SuccessAction, TransferTarget, UnsignedTransferPackage,
+    ConversionEstimate, ConversionOptions, ConversionType, FeePolicy, InputType, LnurlPayContext,
+    LnurlPayInfo, LnurlPayRequest, LnurlPayRequestDetails, LnurlPayResponse, LnurlPayTokenQuote,
+    PrepareLnurlPayRequest, PrepareLnurlPayResponse, PublishSignedLnurlPayResponse,
+    SendPaymentMethod, SignedTransferPackage, SuccessAction, TransferTarget,
+    UnsignedTransferPackage,
     error::SdkError,
     events::SdkEvent,
     models::{PrepareSendPaymentResponse, SendPaymentRequest},
-    persist::PaymentMetadata,
+    persist::{ObjectCacheRepository, PaymentMetadata},
     sdk::{
         BreezSdk,
         helpers::process_success_action,
         payments::{client_signing, conversion, send, validation},
     },
+    token_conversion::ConversionAmount,
+    utils::token::get_tokens_metadata_cached_or_query,
 };
 
 /// Validates an LNURL pay request and returns the (possibly upgraded) fee policy.
@@ -40,16 +43,35 @@ fn validate_request(request: &PrepareLnurlPayRequest) -> Result<(), SdkError> {
         ));
     }
 
+    // A price quoted in a token is either paid with that token, or in sats
+    if let (Some(quote_token_identifier), Some(token_identifier)) =
+        (&request.quote_token_identifier, &request.token_identifier)
+        && quote_token_identifier != token_identifier
+    {
+        return Err(SdkError::InvalidInput(
+            "Quote token identifier must match token_identifier".to_string(),
+        ));
+    }
+
     Ok(())
 }
 
 pub(super) async fn prepare(
     sdk: &BreezSdk,
-    request: PrepareLnurlPayRequest,
+    mut request: PrepareLnurlPayRequest,
 ) -> Result<PrepareLnurlPayResponse, SdkError> {
     validate_request(&request)?;
     let fee_policy = request.fee_policy.unwrap_or_default();
 
+    // A price quoted in a token paid in sats is converted to sats upfront,
+    // at the rate sats would buy the quoted token amount
+    let quoted_token_amount = request.amount;
+    if let Some(quote_token_identifier) = &request.quote_token_identifier
+        && request.token_identifier.is_none()
+    {
+        request.amount = sats_for_token_amount(sdk, quote_token_identifier, request.amount).await?;
+    }
+
     // Only run the token-conversion estimator when a ToBitcoin conversion is
     // actually configured. Otherwise the request is plain sats and the user's
     // amount passes through with no estimate attached.
@@ -80,12 +102,20 @@ pub(super) async fn prepare(
     // guaranteed `FeesIncluded` by `validate_request`.
     let amount = estimated_sats;
 
+    let token_quote = match &request.quote_token_identifier {
+        Some(quote_token_identifier) => {
+            Some(token_quote(sdk, quote_token_identifier, quoted_token_amount, amount).await?)
+        }
+        None => None,
+    };
+
     // FeesIncluded uses the double-query approach
     if fee_policy == FeePolicy::FeesIncluded {
         let amount_sats: u64 = amount
             .try_into()
             .map_err(|_| SdkError::InvalidInput("Amount too large for LNURL".to_string()))?;
-        return prepare_fees_included(sdk, request, amount_sats, conversion_estimate).await;
+        return prepare_fees_included(sdk, request, amount_sats, conversion_estimate, token_quote)
+            .await;
     }
 
     // Regular send (no FeesIncluded, no conversion)
@@ -97,7 +127,7 @@ pub(super) async fn prepare(
         query_lnurl_invoice(sdk, &request, amount_sats.saturating_mul(1_000)).await?;
 
     let prepare_response = sdk
-        .prepare_send_payment(crate::PrepareSendPaymentRequest {
+        .prepare_send_payment_inner(crate::PrepareSendPaymentRequest {
             payment_request: crate::PaymentRequest::Input {
                 input: success_data.pr,
             },
@@ -105,6 +135,7 @@ pub(super) async fn prepare(
             token_identifier: request.token_identifier.clone(),
             conversion_options: request.conversion_options.clone(),
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -128,9 +159,72 @@ pub(super) async fn prepare(
         success_action: success_data.success_action.map(From::from),
         conversion_estimate: prepare_response.conversion_estimate,
         fee_policy,
+        token_quote,
+        duplicate_payment: None,
     })
 }
 
+/// Returns the sats needed to buy `token_amount` of the token through the
+/// token converter.
+async fn sats_for_token_amount(
+    sdk: &BreezSdk,
+    token_identifier: &String,
+    token_amount: u128,
+) -> Result<u128, SdkError> {
+    let estimate = conversion::estimate_conversion(
+        sdk,
+        Some(&ConversionOptions {
+            conversion_type: ConversionType::FromBitcoin,
+            max_slippage_bps: None,
+            completion_timeout_secs: None,
+        }),
+        Some(token_identifier),
+        ConversionAmount::MinAmountOut(token_amount),
+    )
+    .await?
+    .ok_or(SdkError::InvalidInput(
+        "Token conversion is not available for the quoted token and amount".to_string(),
+    ))?;
+    Ok(estimate.amount_in)
+}
+
+async fn token_quote(
+    sdk: &BreezSdk,
+    token_identifier: &str,
+    token_amount: u128,
+    amount_sats: u128,
+) -> Result<LnurlPayTokenQuote, SdkError> {
+    let metadata = get_tokens_metadata_cached_or_query(
+        &sdk.spark_wallet,
+        &ObjectCacheRepository::new(sdk.storage.clone()),
+        &[token_identifier],
+    )
+    .await?
+    .into_iter()
+    .next()
+    .ok_or(SdkError::InvalidInput(format!(
+        "Unknown token {token_identifier}"
+    )))?;
+
+    Ok(LnurlPayTokenQuote {
+        token_identifier: token_identifier.to_string(),
+        token_amount,
+        amount_sats: amount_sats
+            .try_into()
+            .map_err(|_| SdkError::InvalidInput("Amount too large for LNURL".to_string()))?,
+        sats_per_token: sats_per_token(token_amount, amount_sats, metadata.decimals),
+    })
+}
+
+#[allow(clippy::cast_precision_loss)]
+fn sats_per_token(token_amount: u128, amount_sats: u128, decimals: u32) -> f64 {
+    if token_amount == 0 {
+        return 0.0;
+    }
+    amount_sats as f64 * 10f64.powi(i32::try_from(decimals).unwrap_or(i32::MAX))
+        / token_amount as f64
+}
+
 /// Prepares an LNURL pay `FeesIncluded` operation using a double-query approach.
 ///
 /// This method:
@@ -144,6 +238,7 @@ async fn prepare_fees_included(
     request: PrepareLnurlPayRequest,
     amount_sats: u64,
     conversion_estimate: Option<ConversionEstimate>,
+    token_quote: Option<LnurlPayTokenQuote>,
 ) -> Result<PrepareLnurlPayResponse, SdkError> {
     if amount_sats == 0 {
         return Err(SdkError::InvalidInput(
@@ -225,6 +320,8 @@ async fn prepare_fees_included(
         success_action: success_data.success_action.map(From::from),
         conversion_estimate,
         fee_policy: FeePolicy::FeesIncluded,
+        token_quote,
+        duplicate_payment: None,
     })
 }
 
@@ -314,9 +411,13 @@ pub(super) async fn send(
                 token_identifier: None,
                 conversion_estimate: request.prepare_response.conversion_estimate,
                 fee_policy: internal_fee_policy,
+                duplicate_payment: None,
             },
             options: None,
             idempotency_key: request.idempotency_key,
+            max_fee: request.max_fee,
+            deliver_after: None,
+            leaf_selection: None,
         },
         true,
         // For conversions, don't pass amount_override — let
@@ -439,6 +540,7 @@ pub(super) async fn build_package(
         token_identifier: None,
         conversion_estimate: None,
         fee_policy: prepare_response.fee_policy,
+        duplicate_payment: None,
     };
 
     let mut package = client_signing::build_unsigned_transfer_package(sdk, &internal, None).await?;
@@ -530,7 +632,7 @@ async fn query_lnurl_invoice(
 
 #[cfg(test)]
 mod tests {
-    use super::validate_request;
+    use super::{sats_per_token, validate_request};
     use crate::{
         ConversionOptions, ConversionType, FeePolicy, LnurlPayRequestDetails,
         PrepareLnurlPayRequest, error::SdkError,
@@ -568,6 +670,7 @@ mod tests {
             token_identifier: token_identifier.map(String::from),
             conversion_options: None,
             fee_policy,
+            quote_token_identifier: None,
         }
     }
 
@@ -668,4 +771,28 @@ mod tests {
         // Plain LNURL pay (no token, no fee policy) — must work.
         assert!(validate_request(&request_with(1_000, None, None)).is_ok());
     }
+
+    // ---- Token quote ----
+
+    #[test_all]
+    fn test_validate_lnurl_pay_quote_token_mismatch_rejected() {
+        let mut request = request_with(1_000, Some("token123"), Some(FeePolicy::FeesIncluded));
+        request.quote_token_identifier = Some("token456".to_string());
+        let result = validate_request(&request);
+        if let Err(SdkError::InvalidInput(msg)) = result {
+            assert!(msg.contains("Quote token identifier"));
+        } else {
+            panic!("Expected InvalidInput error");
+        }
+
+        request.quote_token_identifier = Some("token123".to_string());
+        assert!(validate_request(&request).is_ok());
+    }
+
+    #[test_all]
+    fn test_sats_per_token() {
+        // 2.5 tokens with 6 decimals for 5_000 sats
+        assert!((sats_per_token(2_500_000, 5_000, 6) - 2_000.0).abs() < f64::EPSILON);
+        assert!(sats_per_token(0, 5_000, 6).abs() < f64::EPSILON);
+    }
 }
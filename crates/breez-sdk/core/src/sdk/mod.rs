@@ -1,34 +1,94 @@
+mod accounting;
 mod api;
+mod application_keys;
+mod auto_optimization;
+mod background_sync;
+mod clock;
 mod contacts;
 mod deposits;
+mod devices;
+mod diagnostics;
+mod duress;
+mod exchange_rate_lock;
+mod faucet;
+mod fiat_receive;
+mod fiat_value;
+mod freeze;
+mod health;
 mod helpers;
+mod incoming_uri;
 mod init;
+mod input_parsers;
+mod jobs;
+mod leaves;
+pub(crate) mod ledger;
 mod lightning_address;
 mod lightning_sender;
 mod lnurl;
+#[cfg(feature = "sqlite")]
+mod notification;
+mod onboarding;
+mod onchain_monitor;
+mod payment_links;
 mod payments;
+mod plugins;
 mod runtime;
+mod seed_backup;
+mod service_status;
+mod state_backup;
+mod success_action;
 mod sync;
 mod sync_coordinator;
+mod token_activity;
+mod token_amount;
+mod token_metadata;
+mod token_sweep;
+mod token_visibility;
 mod unilateral_exit;
+mod wallet_data;
 
+pub(crate) use clock::{clock_now, clock_now_ms};
+pub use duress::{create_duress_config, duress_account_number, is_duress_pin};
+pub(crate) use fiat_value::FiatValueMiddleware;
+pub(crate) use freeze::{ensure_not_frozen, is_wallet_frozen};
+pub(crate) use input_parsers::InputParserRegistry;
 pub(crate) use lightning_sender::LightningSender;
-pub(crate) use runtime::{RuntimeEvent, SdkRuntime, runtime_from_config};
+pub(crate) use payments::spending_caps::{CapReservation, CappedSend, SpendingCaps};
+pub(crate) use plugins::PluginRegistry;
+pub(crate) use runtime::{RuntimeEvent, SdkRuntime, claiming_runtime, runtime_from_config};
+pub(crate) use seed_backup::SeedBackup;
+pub(crate) use success_action::complete_lnurl_success_action;
+pub use success_action::decrypt_success_action;
 pub(crate) use sync_coordinator::SyncCoordinator;
+pub use token_amount::{amount_to_base_units, base_units_to_amount};
 
 use bitflags::bitflags;
 use breez_sdk_common::{buy::moonpay::MoonpayProvider, fiat::FiatService};
 use platform_utils::HttpClient;
 use platform_utils::tokio;
 use spark_wallet::SparkWallet;
-use std::sync::Arc;
+use std::{
+    collections::{HashMap, HashSet},
+    sync::Arc,
+};
 use tokio::sync::{Mutex, OnceCell, oneshot, watch};
 
 use crate::{
-    BitcoinChainService, ExternalInputParser, InputType, LeafOptimizationConfig, Logger, Network,
-    TokenOptimizationConfig, error::SdkError, events::EventEmitter, lnurl::LnurlServerClient,
-    logger, models::Config, persist::Storage, signer::lnurl_auth::LnurlAuthSignerAdapter,
-    stable_balance::StableBalance, token_conversion::TokenConverter,
+    BitcoinChainService, Clock, ExternalInputParser, FaucetConfig, HostConditions, InputType,
+    LeafOptimizationConfig, LockedExchangeRate, Logger, LoggingConfig, Network, PaymentRail,
+    TokenOptimizationConfig, TokenPriceService,
+    error::SdkError,
+    events::EventEmitter,
+    issuer::IssuerApprover,
+    lnurl::LnurlServerClient,
+    logger,
+    middleware::MiddlewarePipeline,
+    models::Config,
+    persist::Storage,
+    signer::{EciesSigner, HmacSigner, lnurl_auth::LnurlAuthSignerAdapter},
+    stable_balance::StableBalance,
+    token_conversion::TokenConverter,
+    utils::parse_cache::ParseCache,
 };
 
 #[cfg(not(all(target_family = "wasm", target_os = "unknown")))]
@@ -37,6 +97,8 @@ const BREEZ_SYNC_SERVICE_URL: &str = "https://datasync.breez.technology";
 #[cfg(all(target_family = "wasm", target_os = "unknown"))]
 const BREEZ_SYNC_SERVICE_URL: &str = "https://datasync.breez.technology:442";
 
+const REGTEST_FAUCET_URL: &str = "https://api.lightspark.com/graphql/spark/rc";
+
 pub(crate) const CLAIM_TX_SIZE_VBYTES: u64 = 99;
 pub(crate) const SYNC_PAGING_LIMIT: u32 = 100;
 
@@ -47,10 +109,14 @@ bitflags! {
         const WalletState = 1 << 1;
         const Deposits = 1 << 2;
         const LnurlMetadata = 1 << 3;
+        /// Token outputs and payments only, which `WalletState` already
+        /// covers when both are set
+        const Tokens = 1 << 4;
         const Full = Self::Wallet.0.0
             | Self::WalletState.0.0
             | Self::Deposits.0.0
-            | Self::LnurlMetadata.0.0;
+            | Self::LnurlMetadata.0.0
+            | Self::Tokens.0.0;
     }
 }
 
@@ -85,9 +151,21 @@ pub struct BreezSdk {
     pub(crate) storage: Arc<dyn Storage>,
     pub(crate) chain_service: Arc<dyn BitcoinChainService>,
     pub(crate) fiat_service: Arc<dyn FiatService>,
+    /// Prices tokens in fiat, set with `SdkBuilder::with_token_price_service`
+    pub(crate) token_price_service: Arc<dyn TokenPriceService>,
+    /// Approves issuer operations, set with `SdkBuilder::with_issuer_approver`
+    pub(crate) issuer_approver: Option<Arc<IssuerApprover>>,
     pub(crate) lnurl_client: Arc<dyn HttpClient>,
+    /// Uploads and downloads state backups
+    pub(crate) backup_client: Arc<dyn HttpClient>,
+    /// Requests test funds from the faucet
+    pub(crate) faucet_client: Arc<dyn HttpClient>,
     pub(crate) lnurl_server_client: Option<Arc<dyn LnurlServerClient>>,
     pub(crate) lnurl_auth_signer: Option<Arc<LnurlAuthSignerAdapter>>,
+    /// Keys the application keys, unset when the signer can't compute HMACs
+    pub(crate) application_key_signer: Option<Arc<dyn HmacSigner>>,
+    /// Encrypts state backups, unset when the signer can't perform ECIES
+    pub(crate) state_backup_signer: Option<Arc<dyn EciesSigner>>,
     pub(crate) event_emitter: Arc<EventEmitter>,
     pub(crate) shutdown_sender: watch::Sender<()>,
     pub(crate) runtime: SdkRuntime,
@@ -95,6 +173,8 @@ pub struct BreezSdk {
     pub(crate) sync_coordinator: SyncCoordinator,
     pub(crate) initial_synced_watcher: watch::Receiver<bool>,
     pub(crate) external_input_parsers: Vec<ExternalInputParser>,
+    /// Results of the inputs parsed with a network lookup
+    pub(crate) parse_cache: Arc<ParseCache>,
     pub(crate) spark_private_mode_initialized: Arc<OnceCell<()>>,
     pub(crate) token_converter: Arc<dyn TokenConverter>,
     pub(crate) stable_balance: Option<Arc<StableBalance>>,
@@ -105,6 +185,35 @@ pub struct BreezSdk {
     /// need to pay an LN invoice as part of a larger flow.
     #[allow(dead_code)]
     pub(crate) lightning_sender: Arc<LightningSender>,
+    /// Handles of payments started with `send_payment_async` that can still
+    /// be cancelled
+    pub(crate) pending_payments: Arc<Mutex<HashSet<String>>>,
+    /// Ids of the payment streams opened with `open_payment_stream` that
+    /// were not closed yet
+    pub(crate) open_payment_streams: Arc<Mutex<HashSet<String>>>,
+    /// Host conditions last reported with `set_host_conditions`
+    pub(crate) host_conditions: Arc<Mutex<HostConditions>>,
+    /// Exchange rates locked with `lock_exchange_rate`, by lock id
+    pub(crate) locked_exchange_rates: Arc<Mutex<HashMap<String, LockedExchangeRate>>>,
+    /// `Config::spending_caps`, and the sends reserved against them
+    pub(crate) spending_caps: Arc<SpendingCaps>,
+    /// Payment middleware registered with `add_payment_middleware`
+    pub(crate) payment_middleware: Arc<MiddlewarePipeline>,
+    /// Digests of the mnemonic words, unset when the SDK wasn't built from a
+    /// mnemonic
+    pub(crate) seed_backup: Option<Arc<SeedBackup>>,
+    /// Plugins registered with `SdkBuilder::with_plugin`
+    pub(crate) plugins: Arc<PluginRegistry>,
+    /// Input parsers registered with `SdkBuilder::with_input_parser`
+    pub(crate) input_parsers: Arc<InputParserRegistry>,
+    /// Jobs started with `start_job`, oldest first
+    pub(crate) jobs: Arc<Mutex<Vec<jobs::JobEntry>>>,
+    /// Status of the Spark network last fetched from the status feed
+    pub(crate) service_status: Arc<Mutex<service_status::ServiceStatusCache>>,
+    /// Clock set with `SdkBuilder::with_clock`, unset to use the system clock
+    pub(crate) clock: Option<Arc<dyn Clock>>,
+    /// Whether the periodic sync is paused with `pause_background_sync`
+    pub(crate) background_sync_paused: Arc<watch::Sender<bool>>,
 }
 
 pub(crate) struct BreezSdkParams {
@@ -112,9 +221,15 @@ pub(crate) struct BreezSdkParams {
     pub storage: Arc<dyn Storage>,
     pub chain_service: Arc<dyn BitcoinChainService>,
     pub fiat_service: Arc<dyn FiatService>,
+    pub token_price_service: Arc<dyn TokenPriceService>,
+    pub issuer_approver: Option<Arc<IssuerApprover>>,
     pub lnurl_client: Arc<dyn HttpClient>,
+    pub backup_client: Arc<dyn HttpClient>,
+    pub faucet_client: Arc<dyn HttpClient>,
     pub lnurl_server_client: Option<Arc<dyn LnurlServerClient>>,
     pub lnurl_auth_signer: Option<Arc<LnurlAuthSignerAdapter>>,
+    pub application_key_signer: Option<Arc<dyn HmacSigner>>,
+    pub state_backup_signer: Option<Arc<dyn EciesSigner>>,
     pub shutdown_sender: watch::Sender<()>,
     pub runtime: SdkRuntime,
     pub spark_wallet: Arc<SparkWallet>,
@@ -125,6 +240,11 @@ pub(crate) struct BreezSdkParams {
     pub sync_coordinator: SyncCoordinator,
     pub cross_chain_context: crate::cross_chain::CrossChainContext,
     pub lightning_sender: Arc<LightningSender>,
+    pub seed_backup: Option<Arc<SeedBackup>>,
+    pub plugins: PluginRegistry,
+    pub input_parsers: InputParserRegistry,
+    pub clock: Option<Arc<dyn Clock>>,
+    pub background_sync_paused: Arc<watch::Sender<bool>>,
 }
 
 pub async fn parse_input(
@@ -149,6 +269,16 @@ pub fn init_logging(
     logger::init_logging(log_dir.as_deref(), app_logger, log_filter.as_deref())
 }
 
+/// Initializes logging with a rotating log file of text or JSON entries, and
+/// separate module-level filters for the log file and the app logger.
+#[cfg_attr(feature = "uniffi", uniffi::export)]
+pub fn init_logging_with_config(
+    config: LoggingConfig,
+    app_logger: Option<Box<dyn Logger>>,
+) -> Result<(), SdkError> {
+    logger::init_logging_with_config(config, app_logger)
+}
+
 /// Connects to the Spark network using the provided configuration and mnemonic.
 ///
 /// # Arguments
@@ -223,12 +353,92 @@ pub async fn connect_with_signing_only_signer(
     Ok(sdk)
 }
 
+/// Connects with only the receive and claim machinery running: incoming
+/// transfers and deposits are claimed, while the payment history sync, fiat
+/// values, leaf and token optimization and real-time sync are left out.
+///
+/// Suited to notification service extensions and serverless environments
+/// with strict memory and time limits. The features of the config that
+/// depend on the services left out are turned off.
+///
+/// # Arguments
+///
+/// * `request` - The connection request object
+///
+/// # Returns
+///
+/// Result containing either the initialized `BreezSdk` or an `SdkError`
+#[cfg(feature = "sqlite")]
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+pub async fn connect_for_claiming(request: crate::ConnectRequest) -> Result<BreezSdk, SdkError> {
+    let builder =
+        super::sdk_builder::SdkBuilder::new(claiming_config(request.config), request.seed)
+            .with_default_storage(request.storage_dir)
+            .for_claiming();
+    let sdk = builder.build().await?;
+    Ok(sdk)
+}
+
+#[cfg(feature = "sqlite")]
+fn claiming_config(mut config: Config) -> Config {
+    config.background_tasks_enabled = true;
+    config.real_time_sync_server_url = None;
+    config.leaf_optimization_config.auto_enabled = false;
+    config.token_optimization_config.auto_enabled = false;
+    config.cross_chain_config = None;
+    config.stable_balance_config = None;
+    config.token_sweep_policy = None;
+    config.payment_fiat_currency = None;
+    config
+}
+
+/// Handles a push notification sent for a webhook registered with
+/// [`BreezSdk::register_webhook`], e.g. from a notification service extension
+/// while the app is in the background.
+///
+/// Rather than a full [`connect`], the SDK is started without background
+/// services, syncs only what the notification's event needs to claim or
+/// update the payment or deposit, and is disconnected again.
+///
+/// # Arguments
+///
+/// * `request` - The connection details and the notification payload
+///
+/// # Returns
+///
+/// Result containing the payments claimed or updated, or an `SdkError`
+#[cfg(feature = "sqlite")]
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+pub async fn handle_notification_payload(
+    request: crate::HandleNotificationPayloadRequest,
+) -> Result<crate::HandleNotificationPayloadResponse, SdkError> {
+    let event_type = notification::parse_payload(&request.payload)?;
+    let builder = super::sdk_builder::SdkBuilder::new(
+        notification::notification_config(request.config),
+        request.seed,
+    )
+    .with_default_storage(request.storage_dir);
+    let sdk = builder.build().await?;
+    let result = notification::handle_notification(&sdk, event_type).await;
+    if let Err(e) = sdk.disconnect().await {
+        tracing::warn!("Failed to disconnect after handling notification: {e:?}");
+    }
+    result
+}
+
 #[cfg_attr(feature = "uniffi", uniffi::export)]
 pub fn default_config(network: Network) -> Config {
     let lnurl_domain = match network {
         Network::Mainnet => Some("breez.tips".to_string()),
         Network::Regtest => None,
     };
+    let faucet_config = match network {
+        Network::Mainnet => None,
+        Network::Regtest => Some(FaucetConfig {
+            url: REGTEST_FAUCET_URL.to_string(),
+            credentials: None,
+        }),
+    };
     Config {
         api_key: None,
         network,
@@ -243,6 +453,7 @@ pub fn default_config(network: Network) -> Config {
         leaf_optimization_config: LeafOptimizationConfig {
             auto_enabled: true,
             multiplicity: 1,
+            auto_policy: None,
         },
         token_optimization_config: TokenOptimizationConfig {
             auto_enabled: true,
@@ -254,6 +465,24 @@ pub fn default_config(network: Network) -> Config {
         spark_config: Some(default_spark_config(network)),
         background_tasks_enabled: true,
         cross_chain_config: None,
+        operator_allowlist: None,
+        conversion_max_price_deviation_bps: None,
+        dust_config: None,
+        token_visibility: None,
+        auto_refund_htlc_payments: true,
+        max_claims_per_second: None,
+        rotate_deposit_address: false,
+        faucet_config,
+        enabled_rails: vec![
+            PaymentRail::Lightning,
+            PaymentRail::Bitcoin,
+            PaymentRail::Spark,
+        ],
+        payment_fiat_currency: None,
+        spending_caps: vec![],
+        token_sweep_policy: None,
+        service_endpoints: None,
+        parse_cache_ttl_secs: 300,
     }
 }
 
@@ -306,7 +535,7 @@ pub fn default_server_config(network: Network) -> Config {
 /// Surfaced through [`default_config`] as `Config::spark_config` so callers can read the
 /// baked-in operator and SSP endpoints and selectively override individual fields (e.g. to
 /// point at a staging environment) before passing the [`Config`] to [`connect`].
-fn default_spark_config(network: Network) -> crate::models::SparkConfig {
+pub(crate) fn default_spark_config(network: Network) -> crate::models::SparkConfig {
     use crate::models::{SparkSigningOperator, SparkSspConfig};
 
     let wallet_config = spark_wallet::SparkWalletConfig::default_config(network.into());
@@ -350,6 +579,8 @@ fn default_spark_config(network: Network) -> crate::models::SparkConfig {
             .tokens_config
             .expected_withdraw_relative_block_locktime,
         max_token_transaction_inputs: None,
+        deposit_confirmations: None,
+        expected_transfer_claim_secs: None,
     }
 }
 
@@ -419,61 +650,10 @@ pub fn default_external_signers(
 /// across the Spark Operators and SSP services.
 #[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
 pub async fn get_spark_status() -> Result<crate::SparkStatus, SdkError> {
-    use chrono::DateTime;
-    use platform_utils::DefaultHttpClient;
-
-    #[derive(serde::Deserialize)]
-    struct StatusApiResponse {
-        services: Vec<StatusApiService>,
-        #[serde(rename = "lastUpdated")]
-        last_updated: String,
-    }
-
-    #[derive(serde::Deserialize)]
-    struct StatusApiService {
-        name: String,
-        status: String,
-    }
-
-    fn parse_service_status(s: &str) -> crate::ServiceStatus {
-        match s {
-            "operational" => crate::ServiceStatus::Operational,
-            "degraded" => crate::ServiceStatus::Degraded,
-            "partial" => crate::ServiceStatus::Partial,
-            "major" => crate::ServiceStatus::Major,
-            _ => {
-                tracing::warn!("Unknown service status: {s}");
-                crate::ServiceStatus::Unknown
-            }
-        }
-    }
-
-    let http_client = DefaultHttpClient::default();
-
-    let response = http_client
-        .get("https://spark.money/api/v1/status".to_string(), None)
-        .await
-        .map_err(|e| SdkError::NetworkError(e.to_string()))?;
-
-    let api_response: StatusApiResponse = response
-        .json()
-        .map_err(|e| SdkError::Generic(format!("Failed to parse status response: {e}")))?;
-
-    let status = api_response
-        .services
-        .iter()
-        .filter(|s| s.name == "Spark Operators" || s.name == "SSP")
-        .map(|s| parse_service_status(&s.status))
-        .max()
-        .unwrap_or(crate::ServiceStatus::Unknown);
-
-    let last_updated = DateTime::parse_from_rfc3339(&api_response.last_updated)
-        .map(|dt| dt.timestamp().cast_unsigned())
-        .map_err(|e| SdkError::Generic(format!("Failed to parse lastUpdated timestamp: {e}")))?;
-
+    let feed = service_status::fetch_status_feed().await?;
     Ok(crate::SparkStatus {
-        status,
-        last_updated,
+        status: feed.worst_status(&[service_status::OPERATORS, service_status::SSP]),
+        last_updated: feed.last_updated,
     })
 }
 
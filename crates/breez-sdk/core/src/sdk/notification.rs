@@ -0,0 +1,129 @@
+use std::sync::Arc;
+
+use platform_utils::tokio;
+use serde::Deserialize;
+use spark_wallet::SparkWalletWebhookEventType;
+use tokio::sync::Mutex;
+
+use crate::{
+    Config, HandleNotificationPayloadResponse, Payment, WebhookEventType,
+    error::SdkError,
+    events::{EventListener, SdkEvent},
+};
+
+use super::{BreezSdk, SyncType};
+
+#[derive(Deserialize)]
+struct NotificationPayload {
+    #[serde(rename = "type")]
+    event_type: SparkWalletWebhookEventType,
+}
+
+pub(super) fn parse_payload(payload: &str) -> Result<WebhookEventType, SdkError> {
+    let payload: NotificationPayload = serde_json::from_str(payload)
+        .map_err(|e| SdkError::InvalidInput(format!("Invalid notification payload: {e}")))?;
+    Ok(payload.event_type.into())
+}
+
+/// The config the SDK is started with to handle a notification: background
+/// services, and the features that depend on them, are turned off.
+pub(super) fn notification_config(mut config: Config) -> Config {
+    config.background_tasks_enabled = false;
+    config.real_time_sync_server_url = None;
+    config.leaf_optimization_config.auto_enabled = false;
+    config.token_optimization_config.auto_enabled = false;
+    config.cross_chain_config = None;
+    config.stable_balance_config = None;
+    config
+}
+
+/// Syncs what the notification's event needs, collecting the payments
+/// reported along the way.
+pub(super) async fn handle_notification(
+    sdk: &BreezSdk,
+    event_type: WebhookEventType,
+) -> Result<HandleNotificationPayloadResponse, SdkError> {
+    let payments = Arc::new(Mutex::new(Vec::new()));
+    let listener_id = sdk
+        .add_event_listener(Box::new(PaymentCollector {
+            payments: payments.clone(),
+        }))
+        .await;
+    let result = sdk.sync_wallet_internal(sync_type(&event_type), true).await;
+    sdk.remove_event_listener(&listener_id).await;
+    result?;
+
+    let payments = payments.lock().await.clone();
+    Ok(HandleNotificationPayloadResponse {
+        event_type,
+        payments,
+    })
+}
+
+/// Only deposits need their chain state checked, everything else is
+/// claimed or updated by the wallet sync.
+fn sync_type(event_type: &WebhookEventType) -> SyncType {
+    match event_type {
+        WebhookEventType::StaticDepositFinished => {
+            SyncType::Wallet | SyncType::WalletState | SyncType::Deposits
+        }
+        WebhookEventType::LightningReceiveFinished
+        | WebhookEventType::LightningSendFinished
+        | WebhookEventType::CoopExitFinished
+        | WebhookEventType::Unknown(_) => SyncType::Wallet | SyncType::WalletState,
+    }
+}
+
+struct PaymentCollector {
+    payments: Arc<Mutex<Vec<Payment>>>,
+}
+
+#[macros::async_trait]
+impl EventListener for PaymentCollector {
+    async fn on_event(&self, event: SdkEvent) {
+        let payment = match event {
+            SdkEvent::PaymentSucceeded { payment }
+            | SdkEvent::PaymentPending { payment }
+            | SdkEvent::PaymentFailed { payment } => payment,
+            _ => return,
+        };
+        let mut payments = self.payments.lock().await;
+        // Keep the latest state of each payment
+        match payments.iter_mut().find(|p| p.id == payment.id) {
+            Some(existing) => *existing = payment,
+            None => payments.push(payment),
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_parse_payload() {
+        let event_type =
+            parse_payload(r#"{"type":"SPARK_LIGHTNING_RECEIVE_FINISHED","id":"abc"}"#).unwrap();
+        assert_eq!(event_type, WebhookEventType::LightningReceiveFinished);
+
+        let event_type = parse_payload(r#"{"type":"SPARK_NEW_EVENT"}"#).unwrap();
+        assert_eq!(
+            event_type,
+            WebhookEventType::Unknown("SPARK_NEW_EVENT".to_string())
+        );
+
+        assert!(parse_payload("not json").is_err());
+    }
+
+    #[test_all]
+    fn test_sync_type() {
+        assert!(sync_type(&WebhookEventType::StaticDepositFinished).contains(SyncType::Deposits));
+        assert!(
+            !sync_type(&WebhookEventType::LightningReceiveFinished).contains(SyncType::Deposits)
+        );
+    }
+}
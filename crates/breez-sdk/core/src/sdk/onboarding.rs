@@ -0,0 +1,168 @@
+use crate::{
+    GetReceiveOnboardingInfoResponse, PaymentRail, ReceiveOnboardingInfo, SparkConfig,
+    chain::RecommendedFees, error::SdkError,
+};
+
+use super::{BreezSdk, CLAIM_TX_SIZE_VBYTES, payments::validation};
+
+/// Confirmations the default Spark operators require before a deposit can be
+/// claimed
+const DEFAULT_DEPOSIT_CONFIRMATIONS: u32 = 3;
+const BLOCK_INTERVAL_SECS: u64 = 600;
+/// Time for the default Spark operators to let an incoming transfer,
+/// including the one a Lightning receive settles into, be claimed
+const DEFAULT_TRANSFER_CLAIM_SECS: u64 = 5;
+
+/// The receive policy of the Spark operators, as set in
+/// [`SparkConfig`] or the defaults of the default operators
+struct ReceivePolicy {
+    deposit_confirmations: u32,
+    transfer_claim_secs: u64,
+}
+
+impl ReceivePolicy {
+    fn from_config(spark_config: Option<&SparkConfig>) -> Self {
+        Self {
+            deposit_confirmations: spark_config
+                .and_then(|c| c.deposit_confirmations)
+                .unwrap_or(DEFAULT_DEPOSIT_CONFIRMATIONS),
+            transfer_claim_secs: spark_config
+                .and_then(|c| c.expected_transfer_claim_secs)
+                .unwrap_or(DEFAULT_TRANSFER_CLAIM_SECS),
+        }
+    }
+}
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl BreezSdk {
+    /// Describes the minimum amount, expected fee and timing of a receive
+    /// over each payment rail, so onboarding screens of a new wallet can show
+    /// what its first receive costs.
+    ///
+    /// Spark and Lightning receives are free and credited within seconds. An
+    /// on-chain deposit is credited once confirmed, less the claim fee at the
+    /// current fee rates.
+    pub async fn get_receive_onboarding_info(
+        &self,
+    ) -> Result<GetReceiveOnboardingInfoResponse, SdkError> {
+        let enabled =
+            |rail| validation::validate_rail_enabled(&self.config.enabled_rails, rail).is_ok();
+        let policy = ReceivePolicy::from_config(self.config.spark_config.as_ref());
+        let fees = self.chain_service.recommended_fees().await?;
+        let max_claim_fee_sats = match &self.config.max_deposit_claim_fee {
+            Some(max_fee) => Some(
+                max_fee
+                    .to_fee(self.chain_service.as_ref())
+                    .await?
+                    .to_sats(CLAIM_TX_SIZE_VBYTES),
+            ),
+            None => None,
+        };
+        Ok(GetReceiveOnboardingInfoResponse {
+            rails: vec![
+                offchain_info(PaymentRail::Spark, enabled(PaymentRail::Spark), &policy),
+                offchain_info(
+                    PaymentRail::Lightning,
+                    enabled(PaymentRail::Lightning),
+                    &policy,
+                ),
+                deposit_info(
+                    enabled(PaymentRail::Bitcoin),
+                    &policy,
+                    &fees,
+                    max_claim_fee_sats,
+                ),
+            ],
+        })
+    }
+}
+
+fn offchain_info(
+    rail: PaymentRail,
+    enabled: bool,
+    policy: &ReceivePolicy,
+) -> ReceiveOnboardingInfo {
+    ReceiveOnboardingInfo {
+        rail,
+        enabled,
+        min_amount_sats: 1,
+        estimated_fee_sats: 0,
+        required_confirmations: 0,
+        estimated_settlement_secs: policy.transfer_claim_secs,
+        claimed_automatically: true,
+    }
+}
+
+/// The claim fee is estimated the way the service provider quotes it, at the
+/// fastest fee rate for the size of a claim transaction
+fn deposit_info(
+    enabled: bool,
+    policy: &ReceivePolicy,
+    fees: &RecommendedFees,
+    max_claim_fee_sats: Option<u64>,
+) -> ReceiveOnboardingInfo {
+    let estimated_fee_sats = fees.fastest_fee.saturating_mul(CLAIM_TX_SIZE_VBYTES);
+    ReceiveOnboardingInfo {
+        rail: PaymentRail::Bitcoin,
+        enabled,
+        min_amount_sats: estimated_fee_sats.saturating_add(1),
+        estimated_fee_sats,
+        required_confirmations: policy.deposit_confirmations,
+        estimated_settlement_secs: u64::from(policy.deposit_confirmations)
+            .saturating_mul(BLOCK_INTERVAL_SECS)
+            .saturating_add(policy.transfer_claim_secs),
+        claimed_automatically: max_claim_fee_sats.is_some_and(|max| max >= estimated_fee_sats),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn fees(fastest_fee: u64) -> RecommendedFees {
+        RecommendedFees {
+            fastest_fee,
+            half_hour_fee: fastest_fee,
+            hour_fee: fastest_fee,
+            economy_fee: fastest_fee,
+            minimum_fee: 1,
+        }
+    }
+
+    #[allow(clippy::arithmetic_side_effects)]
+    #[test_all]
+    fn test_deposit_info_estimates_claim_fee() {
+        let policy = ReceivePolicy::from_config(None);
+        let info = deposit_info(true, &policy, &fees(2), Some(1_000));
+        assert_eq!(info.estimated_fee_sats, 2 * CLAIM_TX_SIZE_VBYTES);
+        assert_eq!(info.min_amount_sats, 2 * CLAIM_TX_SIZE_VBYTES + 1);
+        assert_eq!(info.required_confirmations, DEFAULT_DEPOSIT_CONFIRMATIONS);
+        assert!(info.claimed_automatically);
+    }
+
+    #[test_all]
+    fn test_deposit_info_above_max_claim_fee_is_claimed_manually() {
+        let policy = ReceivePolicy::from_config(None);
+        assert!(!deposit_info(true, &policy, &fees(20), Some(1_000)).claimed_automatically);
+        assert!(!deposit_info(true, &policy, &fees(1), None).claimed_automatically);
+    }
+
+    #[allow(clippy::arithmetic_side_effects)]
+    #[test_all]
+    fn test_deposit_info_follows_operator_policy() {
+        let mut spark_config = crate::sdk::default_spark_config(crate::Network::Regtest);
+        spark_config.deposit_confirmations = Some(1);
+        spark_config.expected_transfer_claim_secs = Some(30);
+        let policy = ReceivePolicy::from_config(Some(&spark_config));
+        let info = deposit_info(true, &policy, &fees(2), None);
+        assert_eq!(info.required_confirmations, 1);
+        assert_eq!(info.estimated_settlement_secs, BLOCK_INTERVAL_SECS + 30);
+        let info = offchain_info(PaymentRail::Spark, true, &policy);
+        assert_eq!(info.estimated_settlement_secs, 30);
+    }
+}
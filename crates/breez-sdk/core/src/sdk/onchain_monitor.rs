@@ -0,0 +1,380 @@
+use std::sync::Arc;
+
+use bitcoin::{Transaction, consensus::encode::deserialize_hex};
+use tracing::{debug, error, info, warn};
+
+use crate::{
+    Clock, DepositRefund, ListOnchainTransactionsRequest, ListOnchainTransactionsResponse,
+    OnchainTransaction, OnchainTransactionKind, OnchainTransactionStatus, PaymentDetails,
+    PaymentType, UnilateralExitTransaction,
+    chain::{Outspend, TxStatus},
+    error::SdkError,
+    events::{EventListener, SdkEvent},
+    persist::{CachedOnchainTransaction, ObjectCacheRepository, Storage},
+};
+
+use super::{BreezSdk, clock::clock_now};
+
+/// The number of confirmations after which a transaction is no longer
+/// monitored.
+const FINAL_CONFIRMATIONS: u32 = 6;
+/// How long a transaction is still listed after reaching a final status.
+const FINAL_RETENTION_SECS: u64 = 7 * 24 * 60 * 60;
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Lists the on-chain transactions monitored by the SDK: withdrawals,
+    /// deposit refunds and the unilateral exit transactions it broadcast, with their status as of the last sync. Changes are
+    /// also emitted as `SdkEvent::OnchainTransactionUpdated`.
+    pub async fn list_onchain_transactions(
+        &self,
+        request: ListOnchainTransactionsRequest,
+    ) -> Result<ListOnchainTransactionsResponse, SdkError> {
+        let _ = request;
+        let mut tracked = ObjectCacheRepository::new(self.storage.clone())
+            .fetch_cached_list::<CachedOnchainTransaction>()
+            .await?;
+        tracked.sort_by(|a, b| b.broadcast_at.cmp(&a.broadcast_at));
+        Ok(ListOnchainTransactionsResponse {
+            transactions: tracked.into_iter().map(|t| t.transaction).collect(),
+        })
+    }
+}
+
+impl BreezSdk {
+    /// Starts monitoring a broadcast refund. Earlier refunds of the same
+    /// deposit are replaced by it.
+    pub(super) async fn monitor_refund(&self, refund: &DepositRefund) -> Result<(), SdkError> {
+        let now = self.now()?;
+        let replaced = ObjectCacheRepository::new(self.storage.clone())
+            .update_cached_list(|tracked: &mut Vec<CachedOnchainTransaction>| {
+                let mut replaced = Vec::new();
+                for t in tracked.iter_mut() {
+                    let OnchainTransactionKind::DepositRefund {
+                        deposit_txid,
+                        deposit_vout,
+                    } = &t.transaction.kind
+                    else {
+                        continue;
+                    };
+                    if *deposit_txid != refund.deposit_txid
+                        || *deposit_vout != refund.deposit_vout
+                        || t.transaction.tx_id == refund.tx_id
+                        || is_final(&t.transaction.status)
+                    {
+                        continue;
+                    }
+                    t.transaction.status = OnchainTransactionStatus::Replaced {
+                        replacement_tx_id: refund.tx_id.clone(),
+                    };
+                    t.updated_at = now;
+                    replaced.push(t.transaction.clone());
+                }
+                if !tracked.iter().any(|t| t.transaction.tx_id == refund.tx_id) {
+                    tracked.push(CachedOnchainTransaction {
+                        transaction: OnchainTransaction {
+                            tx_id: refund.tx_id.clone(),
+                            kind: OnchainTransactionKind::DepositRefund {
+                                deposit_txid: refund.deposit_txid.clone(),
+                                deposit_vout: refund.deposit_vout,
+                            },
+                            status: OnchainTransactionStatus::Broadcast,
+                        },
+                        spent_outpoint: Some((refund.deposit_txid.clone(), refund.deposit_vout)),
+                        broadcast_at: now,
+                        updated_at: now,
+                    });
+                }
+                replaced
+            })
+            .await?;
+        for transaction in replaced {
+            self.event_emitter
+                .emit(&SdkEvent::OnchainTransactionUpdated { transaction })
+                .await;
+        }
+        Ok(())
+    }
+
+    /// Starts monitoring a unilateral exit transaction the SDK broadcast.
+    pub(super) async fn monitor_exit_transaction(
+        &self,
+        tx: &UnilateralExitTransaction,
+    ) -> Result<(), SdkError> {
+        let now = self.now()?;
+        let spent_outpoint = deserialize_hex::<Transaction>(&tx.tx_hex)
+            .ok()
+            .and_then(|parsed| {
+                parsed.input.first().map(|input| {
+                    (
+                        input.previous_output.txid.to_string(),
+                        input.previous_output.vout,
+                    )
+                })
+            });
+        ObjectCacheRepository::new(self.storage.clone())
+            .update_cached_list(|tracked: &mut Vec<CachedOnchainTransaction>| {
+                if tracked.iter().any(|t| t.transaction.tx_id == tx.txid) {
+                    return;
+                }
+                tracked.push(CachedOnchainTransaction {
+                    transaction: OnchainTransaction {
+                        tx_id: tx.txid.clone(),
+                        kind: OnchainTransactionKind::UnilateralExit {
+                            tx_kind: tx.kind,
+                            node_id: tx.node_id.clone(),
+                        },
+                        status: OnchainTransactionStatus::Broadcast,
+                    },
+                    spent_outpoint,
+                    broadcast_at: now,
+                    updated_at: now,
+                });
+            })
+            .await?;
+        Ok(())
+    }
+
+    /// Updates the status of the monitored transactions from the chain and
+    /// emits `OnchainTransactionUpdated` for each change. Called during sync.
+    ///
+    /// The chain is queried without holding the list lock, and a status is
+    /// only applied if the entry didn't change meanwhile, e.g. by a refund
+    /// marking it replaced.
+    pub(super) async fn check_onchain_transactions(&self) {
+        let result: Result<(), SdkError> = async {
+            let cache = ObjectCacheRepository::new(self.storage.clone());
+            let tracked = cache
+                .fetch_cached_list::<CachedOnchainTransaction>()
+                .await?;
+            if tracked.is_empty() {
+                return Ok(());
+            }
+            let tip_height = match self.chain_service.get_tip_height().await {
+                Ok(height) => Some(height),
+                Err(e) => {
+                    debug!("Tip height not available: {e:?}");
+                    None
+                }
+            };
+
+            let now = self.now()?;
+            let mut checked = Vec::new();
+            for mut t in tracked {
+                if is_final(&t.transaction.status) {
+                    continue;
+                }
+                let previous = t.transaction.status.clone();
+                let status = self.next_onchain_status(&mut t, tip_height).await;
+                checked.push((t, previous, status));
+            }
+
+            let updated = cache
+                .update_cached_list(|tracked: &mut Vec<CachedOnchainTransaction>| {
+                    let mut updated = Vec::new();
+                    for (checked, previous, status) in checked {
+                        let Some(t) = tracked
+                            .iter_mut()
+                            .find(|t| t.transaction.tx_id == checked.transaction.tx_id)
+                        else {
+                            continue;
+                        };
+                        if t.transaction.status != previous {
+                            continue;
+                        }
+                        if t.spent_outpoint.is_none() {
+                            t.spent_outpoint = checked.spent_outpoint;
+                        }
+                        if let Some(status) = status
+                            && status != previous
+                        {
+                            t.transaction.status = status;
+                            t.updated_at = now;
+                            updated.push(t.transaction.clone());
+                        }
+                    }
+                    tracked.retain(|t| {
+                        !is_final(&t.transaction.status)
+                            || now.saturating_sub(t.updated_at) < FINAL_RETENTION_SECS
+                    });
+                    updated
+                })
+                .await?;
+            for transaction in updated {
+                info!(
+                    "On-chain transaction {} is now {:?}",
+                    transaction.tx_id, transaction.status
+                );
+                self.event_emitter
+                    .emit(&SdkEvent::OnchainTransactionUpdated { transaction })
+                    .await;
+            }
+            Ok(())
+        }
+        .await;
+        if let Err(e) = result {
+            error!("Failed to check on-chain transactions: {e:?}");
+        }
+    }
+
+    /// The current status of a monitored transaction, or `None` when it can't
+    /// be determined right now.
+    async fn next_onchain_status(
+        &self,
+        tracked: &mut CachedOnchainTransaction,
+        tip_height: Option<u32>,
+    ) -> Option<OnchainTransactionStatus> {
+        let tx_id = tracked.transaction.tx_id.clone();
+        match self
+            .chain_service
+            .get_transaction_status(tx_id.clone())
+            .await
+        {
+            Ok(TxStatus {
+                confirmed: true,
+                block_height: Some(block_height),
+                ..
+            }) => Some(OnchainTransactionStatus::Confirmed {
+                block_height,
+                confirmations: tip_height
+                    .map(|tip| tip.saturating_sub(block_height).saturating_add(1)),
+            }),
+            Ok(TxStatus {
+                confirmed: true, ..
+            }) => None,
+            Ok(_) => {
+                if tracked.spent_outpoint.is_none() {
+                    tracked.spent_outpoint = self.first_spent_outpoint(&tx_id).await;
+                }
+                Some(OnchainTransactionStatus::InMempool)
+            }
+            // The chain service doesn't tell a missing transaction from a
+            // failed request, so look at what spends its input instead
+            Err(e) => {
+                debug!("Failed to get status of on-chain transaction {tx_id}: {e:?}");
+                let (txid, vout) = tracked.spent_outpoint.clone()?;
+                match self.chain_service.get_outspend(txid, vout).await {
+                    Ok(Outspend::Spent { txid, .. }) if txid != tx_id => {
+                        Some(OnchainTransactionStatus::Replaced {
+                            replacement_tx_id: txid,
+                        })
+                    }
+                    Ok(Outspend::Unspent)
+                        if tracked.transaction.status == OnchainTransactionStatus::InMempool =>
+                    {
+                        Some(OnchainTransactionStatus::Evicted)
+                    }
+                    Ok(_) => None,
+                    Err(e) => {
+                        warn!("Failed to get outspend of on-chain transaction {tx_id}: {e:?}");
+                        None
+                    }
+                }
+            }
+        }
+    }
+
+    async fn first_spent_outpoint(&self, tx_id: &str) -> Option<(String, u32)> {
+        let hex = self
+            .chain_service
+            .get_transaction_hex(tx_id.to_string())
+            .await
+            .ok()?;
+        let tx = deserialize_hex::<Transaction>(&hex).ok()?;
+        let input = tx.input.first()?;
+        Some((
+            input.previous_output.txid.to_string(),
+            input.previous_output.vout,
+        ))
+    }
+}
+
+/// Whether a monitored transaction reached a status that no longer changes.
+/// Without the tip height, the first confirmation is final.
+fn is_final(status: &OnchainTransactionStatus) -> bool {
+    match status {
+        OnchainTransactionStatus::Broadcast | OnchainTransactionStatus::InMempool => false,
+        OnchainTransactionStatus::Confirmed { confirmations, .. } => {
+            confirmations.is_none_or(|c| c >= FINAL_CONFIRMATIONS)
+        }
+        OnchainTransactionStatus::Evicted | OnchainTransactionStatus::Replaced { .. } => true,
+    }
+}
+
+/// Starts monitoring withdrawals as their payments are reported.
+pub(crate) struct OnchainWithdrawalListener {
+    pub(crate) storage: Arc<dyn Storage>,
+    pub(crate) clock: Option<Arc<dyn Clock>>,
+}
+
+#[macros::async_trait]
+impl EventListener for OnchainWithdrawalListener {
+    async fn on_event(&self, event: SdkEvent) {
+        let (SdkEvent::PaymentPending { payment } | SdkEvent::PaymentSucceeded { payment }) = event
+        else {
+            return;
+        };
+        let Some(PaymentDetails::Withdraw { tx_id }) = &payment.details else {
+            return;
+        };
+        if payment.payment_type != PaymentType::Send {
+            return;
+        }
+        let result: Result<(), SdkError> = async {
+            let now = clock_now(self.clock.as_ref());
+            ObjectCacheRepository::new(self.storage.clone())
+                .update_cached_list(|tracked: &mut Vec<CachedOnchainTransaction>| {
+                    if tracked.iter().any(|t| t.transaction.tx_id == *tx_id) {
+                        return;
+                    }
+                    tracked.push(CachedOnchainTransaction {
+                        transaction: OnchainTransaction {
+                            tx_id: tx_id.clone(),
+                            kind: OnchainTransactionKind::Withdrawal {
+                                payment_id: payment.id.clone(),
+                            },
+                            status: OnchainTransactionStatus::Broadcast,
+                        },
+                        spent_outpoint: None,
+                        broadcast_at: now,
+                        updated_at: now,
+                    });
+                })
+                .await?;
+            Ok(())
+        }
+        .await;
+        if let Err(e) = result {
+            error!("Failed to monitor withdrawal {tx_id}: {e:?}");
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_is_final() {
+        assert!(!is_final(&OnchainTransactionStatus::Broadcast));
+        assert!(!is_final(&OnchainTransactionStatus::InMempool));
+        assert!(!is_final(&OnchainTransactionStatus::Confirmed {
+            block_height: 100,
+            confirmations: Some(FINAL_CONFIRMATIONS - 1),
+        }));
+        assert!(is_final(&OnchainTransactionStatus::Confirmed {
+            block_height: 100,
+            confirmations: Some(FINAL_CONFIRMATIONS),
+        }));
+        assert!(is_final(&OnchainTransactionStatus::Confirmed {
+            block_height: 100,
+            confirmations: None,
+        }));
+        assert!(is_final(&OnchainTransactionStatus::Evicted));
+    }
+}
@@ -0,0 +1,160 @@
+use crate::{
+    CreatePaymentLinkRequest, CreatePaymentLinkResponse, DeletePaymentLinkRequest,
+    ListPaymentLinksRequest, ListPaymentLinksResponse, PaymentLink, PaymentLinkStatus,
+    error::SdkError, lnurl::LnurlServerClient,
+};
+
+use super::BreezSdk;
+
+/// Links fetched per request when listing all of them
+const PAYMENT_LINKS_PAGE_SIZE: u32 = 100;
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Creates a payment link: a short url on the LNURL server's domain that
+    /// serves a Lightning invoice for `amount_sats` to whoever opens it.
+    ///
+    /// The payment arrives like any other Lightning receive, emitting
+    /// [`SdkEvent::PaymentSucceeded`](crate::SdkEvent::PaymentSucceeded).
+    pub async fn create_payment_link(
+        &self,
+        request: CreatePaymentLinkRequest,
+    ) -> Result<CreatePaymentLinkResponse, SdkError> {
+        if request.amount_sats == 0 {
+            return Err(SdkError::InvalidInput(
+                "Amount must be greater than 0".to_string(),
+            ));
+        }
+        let client = self.payment_links_client()?;
+        let link = client
+            .create_payment_link(&crate::lnurl::CreatePaymentLinkRequest {
+                amount_sat: request.amount_sats,
+                memo: request.memo,
+            })
+            .await?;
+        Ok(CreatePaymentLinkResponse {
+            link: self.to_payment_link(link).await?,
+        })
+    }
+
+    pub async fn list_payment_links(
+        &self,
+        request: ListPaymentLinksRequest,
+    ) -> Result<ListPaymentLinksResponse, SdkError> {
+        let client = self.payment_links_client()?;
+        let response = client
+            .list_payment_links(&crate::lnurl::ListPaymentLinksRequest {
+                offset: request.offset,
+                limit: request.limit,
+            })
+            .await?;
+        let mut links = Vec::with_capacity(response.links.len());
+        for link in response.links {
+            links.push(self.to_payment_link(link).await?);
+        }
+        Ok(ListPaymentLinksResponse { links })
+    }
+
+    /// Deletes a payment link, after which its url no longer serves an invoice.
+    /// An invoice already served stays payable until it expires.
+    pub async fn delete_payment_link(
+        &self,
+        request: DeletePaymentLinkRequest,
+    ) -> Result<(), SdkError> {
+        let client = self.payment_links_client()?;
+        client.delete_payment_link(&request.id).await?;
+        Ok(())
+    }
+}
+
+impl BreezSdk {
+    /// Ids of every payment link of the wallet, or none when no LNURL server
+    /// is configured.
+    pub(super) async fn payment_link_ids(&self) -> Result<Vec<String>, SdkError> {
+        let Some(client) = self.lnurl_server_client.as_deref() else {
+            return Ok(Vec::new());
+        };
+        let mut ids = Vec::new();
+        loop {
+            let page = client
+                .list_payment_links(&crate::lnurl::ListPaymentLinksRequest {
+                    offset: Some(u32::try_from(ids.len()).unwrap_or(u32::MAX)),
+                    limit: Some(PAYMENT_LINKS_PAGE_SIZE),
+                })
+                .await?;
+            let page_len = page.links.len();
+            ids.extend(page.links.into_iter().map(|link| link.id));
+            if page_len < PAYMENT_LINKS_PAGE_SIZE as usize {
+                return Ok(ids);
+            }
+        }
+    }
+
+    fn payment_links_client(&self) -> Result<&dyn LnurlServerClient, SdkError> {
+        self.lnurl_server_client
+            .as_deref()
+            .ok_or_else(|| SdkError::Generic("LNURL server is not configured".to_string()))
+    }
+
+    /// Resolves the received payment of a paid link from storage.
+    async fn to_payment_link(
+        &self,
+        link: lnurl_models::PaymentLink,
+    ) -> Result<PaymentLink, SdkError> {
+        let status = status(&link);
+        let payment_id = match (status, link.invoice) {
+            (PaymentLinkStatus::Paid, Some(invoice)) => self
+                .storage
+                .get_payment_by_invoice(invoice)
+                .await?
+                .map(|payment| payment.id),
+            _ => None,
+        };
+        Ok(PaymentLink {
+            id: link.id,
+            url: link.url,
+            amount_sats: link.amount_sat,
+            memo: link.memo,
+            created_at: u64::try_from(link.created_at / 1000).unwrap_or_default(),
+            status,
+            payment_id,
+        })
+    }
+}
+
+fn status(link: &lnurl_models::PaymentLink) -> PaymentLinkStatus {
+    if link.preimage.is_some() {
+        PaymentLinkStatus::Paid
+    } else {
+        PaymentLinkStatus::Pending
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn link(preimage: Option<&str>) -> lnurl_models::PaymentLink {
+        lnurl_models::PaymentLink {
+            id: "abc".to_string(),
+            url: "https://example.com/link/abc".to_string(),
+            amount_sat: 1_000,
+            memo: "dinner".to_string(),
+            created_at: 1_700_000_000_000,
+            payment_hash: Some("hash".to_string()),
+            invoice: Some("lnbc1".to_string()),
+            preimage: preimage.map(ToString::to_string),
+        }
+    }
+
+    #[test_all]
+    fn test_status() {
+        assert_eq!(status(&link(None)), PaymentLinkStatus::Pending);
+        assert_eq!(status(&link(Some("pre"))), PaymentLinkStatus::Paid);
+    }
+}
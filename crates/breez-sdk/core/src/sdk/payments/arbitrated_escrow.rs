@@ -0,0 +1,507 @@
+use bitcoin::{
+    hex::DisplayHex,
+    secp256k1::{
+        PublicKey,
+        ecdsa::Signature,
+        rand::{RngCore, thread_rng},
+    },
+};
+use spark_wallet::{Preimage, SparkAddress};
+use std::str::FromStr;
+use tracing::{error, info};
+
+use crate::{
+    ArbitratedEscrow, ArbitratedEscrowStatus, CreateArbitratedEscrowRequest,
+    PrepareSendPaymentRequest, SendPaymentMethod, SendPaymentOptions, SendPaymentRequest,
+    SparkHtlcOptions, SparkHtlcStatus,
+    error::SdkError,
+    events::SdkEvent,
+    models::PaymentRequest,
+    persist::{CachedArbitratedEscrow, ObjectCacheRepository},
+    sdk::BreezSdk,
+};
+
+use super::{escrow, time_lock::random_preimage};
+
+const BUYER: u8 = 0;
+const SELLER: u8 = 1;
+const ARBITER: u8 = 2;
+const PART_LEN: usize = 32;
+
+/// Locks the funds in an HTLC to the seller, whose preimage is split between
+/// the buyer, the seller and the arbiter so that any two of them can
+/// recover it.
+pub(super) async fn create_arbitrated_escrow(
+    sdk: &BreezSdk,
+    request: CreateArbitratedEscrowRequest,
+) -> Result<ArbitratedEscrow, SdkError> {
+    let arbiter_pubkey = PublicKey::from_str(&request.arbiter_pubkey)
+        .map_err(|_| SdkError::InvalidInput("Invalid arbiter public key".to_string()))?;
+    if request.amount_sats == 0 {
+        return Err(SdkError::InvalidInput(
+            "Escrow amount must be greater than 0".to_string(),
+        ));
+    }
+
+    let preimage = random_preimage()?;
+    let [buyer_share, seller_share, arbiter_share] = split_preimage(&preimage);
+    let arbiter_secret = utils::ecies::encrypt(&arbiter_pubkey.serialize(), &arbiter_share)
+        .map_err(|e| SdkError::Generic(format!("Failed to encrypt share for the arbiter: {e}")))?;
+    let payment_hash = preimage.compute_hash().to_string();
+
+    let prepare_response = sdk
+        .prepare_send_payment_inner(PrepareSendPaymentRequest {
+            payment_request: PaymentRequest::Input {
+                input: request.seller_address.clone(),
+            },
+            amount: Some(request.amount_sats.into()),
+            token_identifier: None,
+            conversion_options: None,
+            fee_policy: None,
+            fiat_amount: None,
+        })
+        .await?;
+    if !matches!(
+        prepare_response.payment_method,
+        SendPaymentMethod::SparkAddress { .. }
+    ) {
+        return Err(SdkError::InvalidInput(
+            "The seller address must be a Spark address".to_string(),
+        ));
+    }
+    let now = sdk.now()?;
+    let send_response = sdk
+        .send_payment_inner(SendPaymentRequest {
+            prepare_response,
+            options: Some(SendPaymentOptions::SparkAddress {
+                htlc_options: Some(SparkHtlcOptions {
+                    payment_hash: payment_hash.clone(),
+                    expiry_duration_secs: request.expiry_duration_secs,
+                }),
+            }),
+            idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
+        })
+        .await?;
+
+    let escrow = ArbitratedEscrow {
+        id: uuid::Uuid::now_v7().to_string(),
+        payment_hash,
+        seller_address: request.seller_address,
+        arbiter_pubkey: request.arbiter_pubkey,
+        arbiter_secret: hex::encode(arbiter_secret),
+        amount_sats: request.amount_sats,
+        payment_id: send_response.payment.id,
+        expiry_time: now.saturating_add(request.expiry_duration_secs),
+        status: ArbitratedEscrowStatus::Funded,
+        dispute_reason: None,
+        buyer_share: None,
+        seller_share: None,
+        created_at: now,
+    };
+    info!("Created arbitrated escrow {}", escrow.id);
+    // The seller's share is only returned here and not kept, so that this
+    // wallet can't recover the preimage on its own.
+    let cached = CachedArbitratedEscrow {
+        escrow,
+        buyer_share: buyer_share.to_lower_hex_string(),
+    };
+    save(sdk, &cached).await?;
+    let mut escrow = updated(sdk, cached).await;
+    escrow.seller_share = Some(seller_share.to_lower_hex_string());
+    Ok(escrow)
+}
+
+pub(super) async fn list_arbitrated_escrows(
+    sdk: &BreezSdk,
+) -> Result<Vec<ArbitratedEscrow>, SdkError> {
+    let cache = ObjectCacheRepository::new(sdk.storage.clone());
+    let mut escrows = Vec::new();
+    for cached in cache.fetch_cached_list::<CachedArbitratedEscrow>().await? {
+        let status = cached.escrow.status;
+        let refreshed = refresh(sdk, cached).await?;
+        if refreshed.escrow.status != status {
+            save(sdk, &refreshed).await?;
+        }
+        escrows.push(refreshed);
+    }
+    Ok(escrows.into_iter().map(reveal).collect())
+}
+
+/// Reveals the buyer's share, so that the seller can claim the funds by
+/// combining it with its own share.
+pub(super) async fn release_arbitrated_escrow(
+    sdk: &BreezSdk,
+    escrow_id: &str,
+) -> Result<ArbitratedEscrow, SdkError> {
+    sdk.ensure_not_frozen().await?;
+    let mut cached = load(sdk, escrow_id).await?;
+    if !matches!(
+        cached.escrow.status,
+        ArbitratedEscrowStatus::Funded | ArbitratedEscrowStatus::Disputed
+    ) {
+        return Err(SdkError::InvalidInput(format!(
+            "Escrow can't be released once {}",
+            cached.escrow.status
+        )));
+    }
+
+    cached.escrow.status = ArbitratedEscrowStatus::Released;
+    save(sdk, &cached).await?;
+    info!("Released arbitrated escrow {escrow_id}");
+    Ok(updated(sdk, cached).await)
+}
+
+/// Approves returning the funds to this wallet once the HTLC expires.
+///
+/// The buyer's approval is implied, so a signature of the seller or the
+/// arbiter over [`refund_message`] is required as the second approval.
+pub(super) async fn refund_arbitrated_escrow(
+    sdk: &BreezSdk,
+    escrow_id: &str,
+    signature: &str,
+) -> Result<ArbitratedEscrow, SdkError> {
+    let mut cached = load(sdk, escrow_id).await?;
+    if !matches!(
+        cached.escrow.status,
+        ArbitratedEscrowStatus::Funded | ArbitratedEscrowStatus::Disputed
+    ) {
+        return Err(SdkError::InvalidInput(format!(
+            "Escrow can't be refunded once {}",
+            cached.escrow.status
+        )));
+    }
+
+    let signature = hex::decode(signature)
+        .ok()
+        .and_then(|bytes| {
+            Signature::from_der(&bytes)
+                .or_else(|_| Signature::from_compact(&bytes))
+                .ok()
+        })
+        .ok_or(SdkError::InvalidInput(
+            "Not a valid DER or compact encoded signature".to_string(),
+        ))?;
+    let seller_pubkey = cached
+        .escrow
+        .seller_address
+        .parse::<SparkAddress>()
+        .map_err(|_| SdkError::Generic("Invalid seller address".to_string()))?
+        .identity_public_key;
+    let arbiter_pubkey = PublicKey::from_str(&cached.escrow.arbiter_pubkey)
+        .map_err(|_| SdkError::Generic("Invalid arbiter public key".to_string()))?;
+    let message = refund_message(&cached.escrow.payment_hash);
+    let mut approved = false;
+    for pubkey in [seller_pubkey, arbiter_pubkey] {
+        if sdk
+            .spark_wallet
+            .verify_message(&message, &signature, &pubkey)
+            .await
+            .is_ok()
+        {
+            approved = true;
+            break;
+        }
+    }
+    if !approved {
+        return Err(SdkError::InvalidInput(
+            "The refund must be approved by the seller or the arbiter".to_string(),
+        ));
+    }
+
+    cached.escrow.status = ArbitratedEscrowStatus::RefundApproved;
+    save(sdk, &cached).await?;
+    info!("Approved refund of arbitrated escrow {escrow_id}");
+    Ok(updated(sdk, cached).await)
+}
+
+/// Recovers the preimage of an escrow from the shares of two of the
+/// buyer, the seller and the arbiter.
+pub(super) fn recover_arbitrated_escrow_preimage(shares: &[String]) -> Result<String, SdkError> {
+    let [first, second] = shares else {
+        return Err(SdkError::InvalidInput(
+            "Exactly two shares are required".to_string(),
+        ));
+    };
+    let first = decode_share(first)?;
+    let second = decode_share(second)?;
+    if first[0] == second[0] {
+        return Err(SdkError::InvalidInput(
+            "The shares must belong to different parties".to_string(),
+        ));
+    }
+
+    let mut parts = [[0u8; PART_LEN]; 3];
+    for share in [&first, &second] {
+        for (part, index) in held_parts(share[0]).into_iter().enumerate() {
+            let offset = 1 + part * PART_LEN;
+            parts[usize::from(index)].copy_from_slice(&share[offset..offset + PART_LEN]);
+        }
+    }
+    let mut preimage = [0u8; PART_LEN];
+    for part in parts {
+        for (byte, part_byte) in preimage.iter_mut().zip(part) {
+            *byte ^= part_byte;
+        }
+    }
+    Ok(preimage.to_lower_hex_string())
+}
+
+/// The message the seller or the arbiter signs to approve a refund.
+fn refund_message(payment_hash: &str) -> String {
+    format!("refund:{payment_hash}")
+}
+
+/// Whether the escrow the HTLC payment funds is disputed, in which case it
+/// is not refunded on expiry until the refund is approved.
+pub(super) async fn is_refund_blocked(sdk: &BreezSdk, payment_id: &str) -> Result<bool, SdkError> {
+    Ok(ObjectCacheRepository::new(sdk.storage.clone())
+        .fetch_cached_list::<CachedArbitratedEscrow>()
+        .await?
+        .iter()
+        .any(|c| {
+            c.escrow.payment_id == payment_id && c.escrow.status == ArbitratedEscrowStatus::Disputed
+        }))
+}
+
+/// Records a dispute, leaving the decision to the arbiter.
+pub(super) async fn dispute_arbitrated_escrow(
+    sdk: &BreezSdk,
+    escrow_id: &str,
+    reason: String,
+) -> Result<ArbitratedEscrow, SdkError> {
+    let mut cached = load(sdk, escrow_id).await?;
+    if cached.escrow.status != ArbitratedEscrowStatus::Funded {
+        return Err(SdkError::InvalidInput(format!(
+            "Escrow can't be disputed once {}",
+            cached.escrow.status
+        )));
+    }
+
+    cached.escrow.status = ArbitratedEscrowStatus::Disputed;
+    cached.escrow.dispute_reason = Some(reason);
+    save(sdk, &cached).await?;
+    info!("Disputed arbitrated escrow {escrow_id}");
+    Ok(updated(sdk, cached).await)
+}
+
+/// Updates the status of the escrows whose HTLC was claimed or returned,
+/// emitting an event for each change. Called during sync.
+pub(in crate::sdk) async fn refresh_arbitrated_escrows(sdk: &BreezSdk) {
+    let result: Result<(), SdkError> = async {
+        let cache = ObjectCacheRepository::new(sdk.storage.clone());
+        for cached in cache.fetch_cached_list::<CachedArbitratedEscrow>().await? {
+            if is_final(cached.escrow.status) {
+                continue;
+            }
+            let status = cached.escrow.status;
+            let refreshed = refresh(sdk, cached).await?;
+            if refreshed.escrow.status != status {
+                save(sdk, &refreshed).await?;
+                updated(sdk, refreshed).await;
+            }
+        }
+        Ok(())
+    }
+    .await;
+    if let Err(e) = result {
+        error!("Failed to refresh arbitrated escrows: {e:?}");
+    }
+}
+
+async fn refresh(
+    sdk: &BreezSdk,
+    mut cached: CachedArbitratedEscrow,
+) -> Result<CachedArbitratedEscrow, SdkError> {
+    if is_final(cached.escrow.status) {
+        return Ok(cached);
+    }
+
+    let htlc_status = escrow::sent_htlc_details(sdk, &cached.escrow.payment_id)
+        .await?
+        .status;
+    cached.escrow.status = match htlc_status {
+        SparkHtlcStatus::PreimageShared => ArbitratedEscrowStatus::Claimed,
+        SparkHtlcStatus::Returned => ArbitratedEscrowStatus::Refunded,
+        SparkHtlcStatus::WaitingForPreimage => cached.escrow.status,
+    };
+    Ok(cached)
+}
+
+fn is_final(status: ArbitratedEscrowStatus) -> bool {
+    matches!(
+        status,
+        ArbitratedEscrowStatus::Claimed | ArbitratedEscrowStatus::Refunded
+    )
+}
+
+/// Splits the preimage into three parts that XOR to it, each party holding
+/// the two parts other than its own index. A share is the party index
+/// followed by the parts it holds, so any two shares cover all three parts.
+fn split_preimage(preimage: &Preimage) -> [Vec<u8>; 3] {
+    let mut parts = [[0u8; PART_LEN]; 3];
+    thread_rng().fill_bytes(&mut parts[0]);
+    thread_rng().fill_bytes(&mut parts[1]);
+    for (i, byte) in preimage.to_vec().into_iter().enumerate() {
+        parts[2][i] = byte ^ parts[0][i] ^ parts[1][i];
+    }
+
+    [BUYER, SELLER, ARBITER].map(|party| {
+        let mut share = vec![party];
+        for index in held_parts(party) {
+            share.extend_from_slice(&parts[usize::from(index)]);
+        }
+        share
+    })
+}
+
+fn held_parts(party: u8) -> [u8; 2] {
+    match party {
+        BUYER => [1, 2],
+        SELLER => [0, 2],
+        _ => [0, 1],
+    }
+}
+
+fn decode_share(share: &str) -> Result<Vec<u8>, SdkError> {
+    let bytes = hex::decode(share)
+        .map_err(|_| SdkError::InvalidInput("Not a valid hex encoded share".to_string()))?;
+    if bytes.len() != 1 + 2 * PART_LEN || bytes[0] > ARBITER {
+        return Err(SdkError::InvalidInput(
+            "Not a valid escrow share".to_string(),
+        ));
+    }
+    Ok(bytes)
+}
+
+/// Exposes the buyer's share of an escrow only once it is released.
+fn reveal(cached: CachedArbitratedEscrow) -> ArbitratedEscrow {
+    let mut escrow = cached.escrow;
+    if matches!(
+        escrow.status,
+        ArbitratedEscrowStatus::Released | ArbitratedEscrowStatus::Claimed
+    ) {
+        escrow.buyer_share = Some(cached.buyer_share);
+    }
+    escrow
+}
+
+/// Emits the update of an escrow and returns it.
+async fn updated(sdk: &BreezSdk, cached: CachedArbitratedEscrow) -> ArbitratedEscrow {
+    let escrow = reveal(cached);
+    sdk.event_emitter
+        .emit(&SdkEvent::ArbitratedEscrowUpdated {
+            escrow: escrow.clone(),
+        })
+        .await;
+    escrow
+}
+
+async fn load(sdk: &BreezSdk, escrow_id: &str) -> Result<CachedArbitratedEscrow, SdkError> {
+    let cached = ObjectCacheRepository::new(sdk.storage.clone())
+        .fetch_cached_list_entry(escrow_id)
+        .await?
+        .ok_or(SdkError::InvalidInput(format!(
+            "Escrow {escrow_id} not found"
+        )))?;
+    refresh(sdk, cached).await
+}
+
+async fn save(sdk: &BreezSdk, cached: &CachedArbitratedEscrow) -> Result<(), SdkError> {
+    ObjectCacheRepository::new(sdk.storage.clone())
+        .upsert_cached_list_entry(cached)
+        .await?;
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use bitcoin::secp256k1::{Secp256k1, rand::thread_rng};
+    use macros::test_all;
+
+    use super::*;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_reveal_share_once_released() {
+        let cached = |status| CachedArbitratedEscrow {
+            escrow: ArbitratedEscrow {
+                id: "id".to_string(),
+                payment_hash: String::new(),
+                seller_address: String::new(),
+                arbiter_pubkey: String::new(),
+                arbiter_secret: String::new(),
+                amount_sats: 1_000,
+                payment_id: String::new(),
+                expiry_time: 0,
+                status,
+                dispute_reason: None,
+                buyer_share: None,
+                seller_share: None,
+                created_at: 0,
+            },
+            buyer_share: "share".to_string(),
+        };
+
+        assert_eq!(
+            reveal(cached(ArbitratedEscrowStatus::Funded)).buyer_share,
+            None
+        );
+        assert_eq!(
+            reveal(cached(ArbitratedEscrowStatus::Disputed)).buyer_share,
+            None
+        );
+        assert_eq!(
+            reveal(cached(ArbitratedEscrowStatus::RefundApproved)).buyer_share,
+            None
+        );
+        assert_eq!(
+            reveal(cached(ArbitratedEscrowStatus::Released)).buyer_share,
+            Some("share".to_string())
+        );
+    }
+
+    #[test_all]
+    fn test_any_two_shares_recover_preimage() {
+        let preimage = random_preimage().unwrap();
+        let shares = split_preimage(&preimage).map(|s| s.to_lower_hex_string());
+
+        for (a, b) in [(0, 1), (0, 2), (1, 2), (2, 0)] {
+            let recovered =
+                recover_arbitrated_escrow_preimage(&[shares[a].clone(), shares[b].clone()])
+                    .unwrap();
+            assert_eq!(recovered, preimage.encode_hex());
+        }
+    }
+
+    #[test_all]
+    fn test_single_share_does_not_recover_preimage() {
+        let preimage = random_preimage().unwrap();
+        let shares = split_preimage(&preimage).map(|s| s.to_lower_hex_string());
+
+        assert!(recover_arbitrated_escrow_preimage(&[shares[0].clone()]).is_err());
+        assert!(
+            recover_arbitrated_escrow_preimage(&[shares[1].clone(), shares[1].clone()]).is_err()
+        );
+        assert!(
+            recover_arbitrated_escrow_preimage(&[shares[0].clone(), "00".to_string()]).is_err()
+        );
+    }
+
+    #[test_all]
+    fn test_arbiter_secret_decrypts_to_arbiter_share() {
+        let secp = Secp256k1::new();
+        let (secret_key, public_key) = secp.generate_keypair(&mut thread_rng());
+        let preimage = random_preimage().unwrap();
+        let [_, _, arbiter_share] = split_preimage(&preimage);
+
+        let arbiter_secret =
+            utils::ecies::encrypt(&public_key.serialize(), &arbiter_share).unwrap();
+        let decrypted = utils::ecies::decrypt(&secret_key.secret_bytes(), &arbiter_secret).unwrap();
+        assert_eq!(decrypted, arbiter_share);
+    }
+}
@@ -0,0 +1,149 @@
+use tracing::info;
+
+use crate::{
+    Payment, PaymentDetails, PaymentStatus, PaymentType, SparkHtlcStatus,
+    error::SdkError,
+    sdk::BreezSdk,
+    utils::payments::{
+        fetch_and_process_payment, get_payment_with_conversion_details, record_payment_update,
+    },
+};
+
+/// Moves an outgoing payment stuck in `Pending` to a final status.
+///
+/// The operators are asked for the payment's current status first, so a
+/// payment that settled or failed without the SDK noticing is recorded as
+/// such. A payment that failed is returned as cancelled, while a payment that
+/// completed is an error. A payment still pending is only failed locally once
+/// it can no longer complete, i.e. its HTLC expired without the preimage being
+/// shared.
+pub(super) async fn cancel_payment(
+    sdk: &BreezSdk,
+    payment_id: String,
+) -> Result<Payment, SdkError> {
+    let payment = sdk.storage.get_payment_by_id(payment_id.clone()).await?;
+    if payment.payment_type != PaymentType::Send {
+        return Err(SdkError::InvalidInput(
+            "Only outgoing payments can be cancelled".to_string(),
+        ));
+    }
+    if payment.status != PaymentStatus::Pending {
+        return Err(SdkError::InvalidInput(format!(
+            "Payment is already {}",
+            payment.status
+        )));
+    }
+
+    let now = sdk.now()?;
+
+    let fetched =
+        fetch_and_process_payment(&sdk.spark_wallet, sdk.storage.clone(), &payment_id, true)
+            .await?;
+    let final_payment = match fetched {
+        Some(fetched) if fetched.status == PaymentStatus::Failed => fetched,
+        Some(fetched) if fetched.status == PaymentStatus::Completed => {
+            // Record the settlement the SDK missed, but don't report it as cancelled
+            record_payment_update(&sdk.storage, &sdk.event_emitter, fetched, true).await;
+            return Err(SdkError::InvalidInput(
+                "Payment completed and can't be cancelled".to_string(),
+            ));
+        }
+        // Still pending with the operators, or not visible to them yet. The
+        // stored payment holds the HTLC details the expiry is checked against.
+        _ => expire_payment(payment, now)?,
+    };
+
+    info!(
+        "Cancelled pending payment {payment_id}, status = {}",
+        final_payment.status
+    );
+    record_payment_update(&sdk.storage, &sdk.event_emitter, final_payment, true).await;
+    get_payment_with_conversion_details(payment_id, sdk.storage.clone()).await
+}
+
+/// Fails a payment the operators still report as pending, if it can no
+/// longer complete.
+fn expire_payment(mut payment: Payment, now: u64) -> Result<Payment, SdkError> {
+    let Some(PaymentDetails::Lightning { htlc_details, .. }) = &mut payment.details else {
+        return Err(SdkError::InvalidInput(
+            "Payment is still being processed and can't be cancelled".to_string(),
+        ));
+    };
+    if htlc_details.status != SparkHtlcStatus::WaitingForPreimage {
+        return Err(SdkError::InvalidInput(
+            "Payment preimage was shared and the payment can't be cancelled".to_string(),
+        ));
+    }
+    if htlc_details.expiry_time > now {
+        return Err(SdkError::InvalidInput(format!(
+            "Payment can't be cancelled before its HTLC expires at {}",
+            htlc_details.expiry_time
+        )));
+    }
+
+    // The expired HTLC is returned to the wallet by the operators
+    htlc_details.status = SparkHtlcStatus::Returned;
+    payment.status = PaymentStatus::Failed;
+    Ok(payment)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::{PaymentMethod, SparkHtlcDetails};
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn lightning_payment(htlc_status: SparkHtlcStatus, expiry_time: u64) -> Payment {
+        Payment {
+            id: "payment".to_string(),
+            payment_type: PaymentType::Send,
+            status: PaymentStatus::Pending,
+            amount: 1_000,
+            fees: 10,
+            timestamp: 0,
+            method: PaymentMethod::Lightning,
+            details: Some(PaymentDetails::Lightning {
+                description: None,
+                invoice: "lnbc".to_string(),
+                destination_pubkey: String::new(),
+                htlc_details: SparkHtlcDetails {
+                    payment_hash: String::new(),
+                    preimage: None,
+                    expiry_time,
+                    status: htlc_status,
+                },
+                lnurl_pay_info: None,
+                lnurl_withdraw_info: None,
+                lnurl_receive_metadata: None,
+                conversion_info: None,
+            }),
+            conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
+        }
+    }
+
+    #[test_all]
+    fn test_expire_payment() {
+        let expired = expire_payment(
+            lightning_payment(SparkHtlcStatus::WaitingForPreimage, 100),
+            100,
+        )
+        .unwrap();
+        assert_eq!(expired.status, PaymentStatus::Failed);
+
+        assert!(
+            expire_payment(
+                lightning_payment(SparkHtlcStatus::WaitingForPreimage, 101),
+                100
+            )
+            .is_err()
+        );
+        assert!(
+            expire_payment(lightning_payment(SparkHtlcStatus::PreimageShared, 0), 100).is_err()
+        );
+    }
+}
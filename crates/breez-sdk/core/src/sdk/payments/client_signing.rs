@@ -137,6 +137,9 @@ pub(in crate::sdk) async fn build_unsigned_transfer_package(
         SendPaymentMethod::CrossChainAddress { .. } => Err(SdkError::InvalidInput(
             "client signing is not supported for cross-chain sends".to_string(),
         )),
+        SendPaymentMethod::Plugin { .. } => Err(SdkError::InvalidInput(
+            "client signing is not supported for plugin sends".to_string(),
+        )),
     }
 }
 
@@ -36,7 +36,7 @@ async fn get_conversion_options_for_payment(
 ///
 /// For `AmountIn`: validates with the given options directly (caller knows what to convert).
 /// For `MinAmountOut`: auto-populates conversion options from stable balance config when applicable.
-pub(super) async fn estimate_conversion(
+pub(in crate::sdk) async fn estimate_conversion(
     sdk: &BreezSdk,
     request_options: Option<&ConversionOptions>,
     token_identifier: Option<&String>,
@@ -371,6 +371,9 @@ async fn execute_pre_send_conversion(
             .await?;
             Ok((response, purpose, uses_amount_in))
         }
+        SendPaymentMethod::Plugin { .. } => Err(SdkError::InvalidInput(
+            "Conversions are not supported for plugin sends".to_string(),
+        )),
     }
 }
 
@@ -0,0 +1,284 @@
+use crate::{
+    DuplicatePayment, LnurlPayRequestDetails, Payment, PaymentDetails, PaymentStatus, PaymentType,
+    PrepareSendPaymentResponse, SendPaymentMethod,
+    error::SdkError,
+    persist::{CachedSentDestination, ObjectCacheRepository, StorageListPaymentsRequest},
+    sdk::BreezSdk,
+};
+
+/// How far back sent payments are checked for an earlier payment to the same
+/// destination
+const DUPLICATE_PAYMENT_WINDOW_SECS: u64 = 24 * 60 * 60;
+
+/// Returns the most recent pending or completed payment to the destination
+/// being prepared, if it was sent within [`DUPLICATE_PAYMENT_WINDOW_SECS`].
+/// Invoices match on the invoice alone, addresses on the address together
+/// with the amount and token.
+pub(super) async fn find_duplicate_payment(
+    sdk: &BreezSdk,
+    prepared: &PrepareSendPaymentResponse,
+) -> Result<Option<DuplicatePayment>, SdkError> {
+    if let Some(invoice) = prepared_invoice(&prepared.payment_method) {
+        return find_recent_send(sdk, |payment| {
+            paid_invoice(payment).is_some_and(|paid| paid.eq_ignore_ascii_case(invoice))
+        })
+        .await;
+    }
+    let Some(destination) = prepared_address(&prepared.payment_method) else {
+        return Ok(None);
+    };
+    let since = sdk.now()?.saturating_sub(DUPLICATE_PAYMENT_WINDOW_SECS);
+    let mut sent = ObjectCacheRepository::new(sdk.storage.clone())
+        .fetch_cached_list::<CachedSentDestination>()
+        .await?;
+    sent.sort_by(|a, b| b.sent_at.cmp(&a.sent_at));
+    for entry in sent {
+        if entry.sent_at < since
+            || entry.destination != destination
+            || entry.amount != prepared.amount
+            || entry.token_identifier != prepared.token_identifier
+        {
+            continue;
+        }
+        let Ok(payment) = sdk.storage.get_payment_by_id(entry.payment_id).await else {
+            continue;
+        };
+        if matches!(
+            payment.status,
+            PaymentStatus::Pending | PaymentStatus::Completed
+        ) {
+            return Ok(Some(duplicate_of(payment)));
+        }
+    }
+    Ok(None)
+}
+
+/// Returns the most recent pending or completed payment of the same amount to
+/// the LNURL pay endpoint being prepared, if it was sent within
+/// [`DUPLICATE_PAYMENT_WINDOW_SECS`]. The endpoint is matched on its
+/// lightning address when it has one, and on its domain and metadata
+/// otherwise.
+pub(in crate::sdk) async fn find_duplicate_lnurl_payment(
+    sdk: &BreezSdk,
+    pay_request: &LnurlPayRequestDetails,
+    amount_sats: u64,
+) -> Result<Option<DuplicatePayment>, SdkError> {
+    find_recent_send(sdk, |payment| {
+        payment.amount == u128::from(amount_sats) && paid_lnurl(payment, pay_request)
+    })
+    .await
+}
+
+/// Records the address a payment was sent to, so that a repeat send can be
+/// flagged by [`find_duplicate_payment`]. Entries older than
+/// [`DUPLICATE_PAYMENT_WINDOW_SECS`] are pruned.
+pub(super) async fn record_sent_destination(
+    sdk: &BreezSdk,
+    prepared: &PrepareSendPaymentResponse,
+    payment: &Payment,
+) -> Result<(), SdkError> {
+    let Some(destination) = prepared_address(&prepared.payment_method) else {
+        return Ok(());
+    };
+    let now = sdk.now()?;
+    let since = now.saturating_sub(DUPLICATE_PAYMENT_WINDOW_SECS);
+    let entry = CachedSentDestination {
+        payment_id: payment.id.clone(),
+        destination: destination.to_string(),
+        amount: prepared.amount,
+        token_identifier: prepared.token_identifier.clone(),
+        sent_at: now,
+    };
+    ObjectCacheRepository::new(sdk.storage.clone())
+        .update_cached_list(|sent: &mut Vec<CachedSentDestination>| {
+            sent.retain(|e| e.sent_at >= since && e.payment_id != entry.payment_id);
+            sent.push(entry);
+        })
+        .await?;
+    Ok(())
+}
+
+async fn find_recent_send(
+    sdk: &BreezSdk,
+    matches: impl Fn(&Payment) -> bool,
+) -> Result<Option<DuplicatePayment>, SdkError> {
+    let payments = sdk
+        .storage
+        .list_payments(StorageListPaymentsRequest {
+            type_filter: Some(vec![PaymentType::Send]),
+            status_filter: Some(vec![PaymentStatus::Pending, PaymentStatus::Completed]),
+            from_timestamp: Some(sdk.now()?.saturating_sub(DUPLICATE_PAYMENT_WINDOW_SECS)),
+            ..Default::default()
+        })
+        .await?;
+    Ok(payments.into_iter().find(|p| matches(p)).map(duplicate_of))
+}
+
+fn duplicate_of(payment: Payment) -> DuplicatePayment {
+    DuplicatePayment {
+        payment_id: payment.id,
+        status: payment.status,
+        timestamp: payment.timestamp,
+    }
+}
+
+fn prepared_invoice(payment_method: &SendPaymentMethod) -> Option<&str> {
+    match payment_method {
+        SendPaymentMethod::Bolt11Invoice {
+            invoice_details, ..
+        } => Some(&invoice_details.invoice.bolt11),
+        SendPaymentMethod::SparkInvoice {
+            spark_invoice_details,
+            ..
+        } => Some(&spark_invoice_details.invoice),
+        _ => None,
+    }
+}
+
+fn prepared_address(payment_method: &SendPaymentMethod) -> Option<&str> {
+    match payment_method {
+        SendPaymentMethod::SparkAddress { address, .. } => Some(address),
+        SendPaymentMethod::BitcoinAddress { address, .. } => Some(&address.address),
+        _ => None,
+    }
+}
+
+fn paid_invoice(payment: &Payment) -> Option<&str> {
+    match &payment.details {
+        Some(PaymentDetails::Lightning { invoice, .. }) => Some(invoice),
+        Some(
+            PaymentDetails::Spark {
+                invoice_details: Some(invoice_details),
+                ..
+            }
+            | PaymentDetails::Token {
+                invoice_details: Some(invoice_details),
+                ..
+            },
+        ) => Some(&invoice_details.invoice),
+        _ => None,
+    }
+}
+
+fn paid_lnurl(payment: &Payment, pay_request: &LnurlPayRequestDetails) -> bool {
+    let Some(PaymentDetails::Lightning {
+        lnurl_pay_info: Some(info),
+        ..
+    }) = &payment.details
+    else {
+        return false;
+    };
+    match &pay_request.address {
+        Some(address) => info
+            .ln_address
+            .as_deref()
+            .is_some_and(|paid| paid.eq_ignore_ascii_case(address)),
+        None => {
+            info.domain.as_deref() == Some(pay_request.domain.as_str())
+                && info.metadata.as_deref() == Some(pay_request.metadata_str.as_str())
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+    use crate::{
+        LnurlPayInfo, SparkHtlcDetails, SparkHtlcStatus, SparkInvoicePaymentDetails, sdk::ledger,
+    };
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn payment(details: Option<PaymentDetails>) -> Payment {
+        Payment {
+            details,
+            ..ledger::tests::payment("payment", PaymentType::Send, 1_000, 0, 0)
+        }
+    }
+
+    #[test_all]
+    fn test_paid_invoice_of_spark_invoice_payment() {
+        let payment = payment(Some(PaymentDetails::Spark {
+            invoice_details: Some(SparkInvoicePaymentDetails {
+                description: None,
+                invoice: "spark1invoice".to_string(),
+            }),
+            htlc_details: None,
+            conversion_info: None,
+        }));
+        assert_eq!(paid_invoice(&payment), Some("spark1invoice"));
+    }
+
+    #[test_all]
+    fn test_paid_invoice_of_payment_without_invoice() {
+        assert_eq!(paid_invoice(&payment(None)), None);
+        assert_eq!(
+            paid_invoice(&payment(Some(PaymentDetails::Withdraw {
+                tx_id: "txid".to_string(),
+            }))),
+            None
+        );
+    }
+
+    fn lnurl_payment(info: LnurlPayInfo) -> Payment {
+        payment(Some(PaymentDetails::Lightning {
+            description: None,
+            invoice: "lnbc1invoice".to_string(),
+            destination_pubkey: "pubkey".to_string(),
+            htlc_details: SparkHtlcDetails {
+                payment_hash: "hash".to_string(),
+                preimage: None,
+                expiry_time: 0,
+                status: SparkHtlcStatus::PreimageShared,
+            },
+            lnurl_pay_info: Some(info),
+            lnurl_withdraw_info: None,
+            lnurl_receive_metadata: None,
+            conversion_info: None,
+        }))
+    }
+
+    fn pay_request(address: Option<&str>) -> LnurlPayRequestDetails {
+        LnurlPayRequestDetails {
+            callback: "https://example.com/callback".to_string(),
+            min_sendable: 1_000,
+            max_sendable: 100_000_000_000,
+            metadata_str: "[]".to_string(),
+            comment_allowed: 0,
+            domain: "example.com".to_string(),
+            url: "https://example.com".to_string(),
+            address: address.map(ToString::to_string),
+            allows_nostr: None,
+            nostr_pubkey: None,
+        }
+    }
+
+    #[test_all]
+    fn test_paid_lnurl_matches_lightning_address() {
+        let payment = lnurl_payment(LnurlPayInfo {
+            ln_address: Some("Alice@example.com".to_string()),
+            ..Default::default()
+        });
+        assert!(paid_lnurl(
+            &payment,
+            &pay_request(Some("alice@example.com"))
+        ));
+        assert!(!paid_lnurl(&payment, &pay_request(Some("bob@example.com"))));
+    }
+
+    #[test_all]
+    fn test_paid_lnurl_matches_domain_and_metadata_without_address() {
+        let mut info = LnurlPayInfo {
+            domain: Some("example.com".to_string()),
+            metadata: Some("[]".to_string()),
+            ..Default::default()
+        };
+        assert!(paid_lnurl(&lnurl_payment(info.clone()), &pay_request(None)));
+        info.metadata = Some("[[\"text/plain\",\"other\"]]".to_string());
+        assert!(!paid_lnurl(&lnurl_payment(info), &pay_request(None)));
+        assert!(!paid_lnurl(&payment(None), &pay_request(None)));
+    }
+}
@@ -0,0 +1,344 @@
+use std::str::FromStr;
+
+use bitcoin::hashes::sha256;
+use spark_wallet::{Preimage, SparkAddress, TransferId, WalletTransfer};
+use tracing::info;
+
+use crate::{
+    ClaimHtlcPaymentRequest, CreateEscrowRequest, Escrow, EscrowRole, EscrowStatus,
+    PrepareSendPaymentRequest, SendPaymentMethod, SendPaymentOptions, SendPaymentRequest,
+    SparkHtlcDetails, SparkHtlcOptions, SparkHtlcStatus, error::SdkError, models::PaymentRequest,
+    persist::ObjectCacheRepository, sdk::BreezSdk,
+};
+
+use super::{htlc_refund, receive};
+
+/// The minimum time between the expiry of the participant's HTLC and the
+/// initiator's, leaving the participant time to claim once the preimage is
+/// revealed.
+const MIN_EXPIRY_DELTA_SECS: u64 = 600;
+
+pub(super) async fn create_escrow(
+    sdk: &BreezSdk,
+    request: CreateEscrowRequest,
+) -> Result<Escrow, SdkError> {
+    let payment_hash = sha256::Hash::from_str(&request.payment_hash)
+        .map_err(|_| SdkError::InvalidInput("Invalid payment hash".to_string()))?;
+    if request.send_amount_sats == 0 || request.receive_amount_sats == 0 {
+        return Err(SdkError::InvalidInput(
+            "Escrow amounts must be greater than 0".to_string(),
+        ));
+    }
+    let now = sdk.now()?;
+    let send_expiry_time = now.saturating_add(request.expiry_duration_secs);
+
+    // The participant locks its funds only once the initiator's HTLC is
+    // visible, and makes sure to expire first
+    let status = match request.role {
+        EscrowRole::Initiator => EscrowStatus::Funded,
+        EscrowRole::Participant => {
+            let counterparty_htlc =
+                find_counterparty_htlc(sdk, &payment_hash, &request.counterparty_address)
+                    .await?
+                    .ok_or(SdkError::InvalidInput(
+                        "The initiator's HTLC is not visible yet".to_string(),
+                    ))?;
+            let counterparty_expiry_time = htlc_details(&counterparty_htlc)?.expiry_time;
+            validate_counterparty_htlc(
+                &request,
+                counterparty_htlc.total_value_sat,
+                counterparty_expiry_time,
+                send_expiry_time,
+            )?;
+            EscrowStatus::CounterpartyFunded
+        }
+    };
+
+    let prepare_response = sdk
+        .prepare_send_payment_inner(PrepareSendPaymentRequest {
+            payment_request: PaymentRequest::Input {
+                input: request.counterparty_address.clone(),
+            },
+            amount: Some(request.send_amount_sats.into()),
+            token_identifier: None,
+            conversion_options: None,
+            fee_policy: None,
+            fiat_amount: None,
+        })
+        .await?;
+    if !matches!(
+        prepare_response.payment_method,
+        SendPaymentMethod::SparkAddress { .. }
+    ) {
+        return Err(SdkError::InvalidInput(
+            "The counterparty address must be a Spark address".to_string(),
+        ));
+    }
+    let send_response = sdk
+        .send_payment_inner(SendPaymentRequest {
+            prepare_response,
+            options: Some(SendPaymentOptions::SparkAddress {
+                htlc_options: Some(SparkHtlcOptions {
+                    payment_hash: request.payment_hash.clone(),
+                    expiry_duration_secs: request.expiry_duration_secs,
+                }),
+            }),
+            idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
+        })
+        .await?;
+
+    let escrow = Escrow {
+        id: uuid::Uuid::now_v7().to_string(),
+        payment_hash: request.payment_hash,
+        role: request.role,
+        counterparty_address: request.counterparty_address,
+        send_amount_sats: request.send_amount_sats,
+        receive_amount_sats: request.receive_amount_sats,
+        send_payment_id: send_response.payment.id,
+        send_expiry_time,
+        receive_payment_id: None,
+        status,
+        created_at: now,
+    };
+    info!(
+        "Created escrow {} for payment hash {}",
+        escrow.id, escrow.payment_hash
+    );
+    save_escrow(sdk, &escrow).await?;
+    Ok(escrow)
+}
+
+/// Returns the escrow with its status updated from the state of the HTLCs.
+pub(super) async fn get_escrow(sdk: &BreezSdk, escrow_id: &str) -> Result<Escrow, SdkError> {
+    let escrow = load_escrow(sdk, escrow_id).await?;
+    let escrow = refresh_escrow(sdk, escrow).await?;
+    save_escrow(sdk, &escrow).await?;
+    Ok(escrow)
+}
+
+pub(super) async fn list_escrows(sdk: &BreezSdk) -> Result<Vec<Escrow>, SdkError> {
+    let cache = ObjectCacheRepository::new(sdk.storage.clone());
+    let mut escrows = Vec::new();
+    for escrow in cache.fetch_cached_list::<Escrow>().await? {
+        let refreshed = refresh_escrow(sdk, escrow.clone()).await?;
+        if refreshed != escrow {
+            cache.upsert_cached_list_entry(&refreshed).await?;
+        }
+        escrows.push(refreshed);
+    }
+    Ok(escrows)
+}
+
+/// Claims the counterparty's HTLC, which reveals the preimage to the
+/// counterparty.
+pub(super) async fn release_escrow(
+    sdk: &BreezSdk,
+    escrow_id: &str,
+    preimage: Option<String>,
+) -> Result<Escrow, SdkError> {
+    let mut escrow = get_escrow(sdk, escrow_id).await?;
+    if escrow.status != EscrowStatus::CounterpartyFunded {
+        return Err(SdkError::InvalidInput(format!(
+            "Escrow can't be released while {}",
+            escrow.status
+        )));
+    }
+
+    let preimage = match (preimage, escrow.role) {
+        (Some(preimage), _) => preimage,
+        (None, EscrowRole::Initiator) => {
+            return Err(SdkError::InvalidInput(
+                "The preimage is required to release the escrow".to_string(),
+            ));
+        }
+        // The initiator reveals the preimage by claiming the participant's HTLC
+        (None, EscrowRole::Participant) => sent_htlc_details(sdk, &escrow.send_payment_id)
+            .await?
+            .preimage
+            .ok_or(SdkError::InvalidInput(
+                "The initiator did not claim its HTLC yet".to_string(),
+            ))?,
+    };
+    let payment_hash = Preimage::from_hex(&preimage)
+        .map_err(|_| SdkError::InvalidInput("Invalid preimage".to_string()))?
+        .compute_hash();
+    if payment_hash.to_string() != escrow.payment_hash {
+        return Err(SdkError::InvalidInput(
+            "Preimage does not match the escrow payment hash".to_string(),
+        ));
+    }
+
+    let response = receive::claim_htlc_payment(sdk, ClaimHtlcPaymentRequest { preimage }).await?;
+    info!("Released escrow {}", escrow.id);
+    escrow.receive_payment_id = Some(response.payment.id);
+    escrow.status = EscrowStatus::Released;
+    save_escrow(sdk, &escrow).await?;
+    Ok(escrow)
+}
+
+/// Reclaims the funds of this wallet's HTLC once it expired.
+pub(super) async fn refund_escrow(sdk: &BreezSdk, escrow_id: &str) -> Result<Escrow, SdkError> {
+    let mut escrow = get_escrow(sdk, escrow_id).await?;
+    if matches!(
+        escrow.status,
+        EscrowStatus::Released | EscrowStatus::Refunded
+    ) {
+        return Err(SdkError::InvalidInput(format!(
+            "Escrow can't be refunded once {}",
+            escrow.status
+        )));
+    }
+
+    htlc_refund::refund_htlc_payment(sdk, escrow.send_payment_id.clone()).await?;
+    info!("Refunded escrow {}", escrow.id);
+    escrow.status = EscrowStatus::Refunded;
+    save_escrow(sdk, &escrow).await?;
+    Ok(escrow)
+}
+
+async fn refresh_escrow(sdk: &BreezSdk, mut escrow: Escrow) -> Result<Escrow, SdkError> {
+    if matches!(
+        escrow.status,
+        EscrowStatus::Released | EscrowStatus::Refunded
+    ) {
+        return Ok(escrow);
+    }
+
+    // The HTLC may have been refunded during sync
+    if sent_htlc_details(sdk, &escrow.send_payment_id)
+        .await?
+        .status
+        == SparkHtlcStatus::Returned
+    {
+        escrow.status = EscrowStatus::Refunded;
+        return Ok(escrow);
+    }
+    if escrow.status == EscrowStatus::Funded {
+        let payment_hash = sha256::Hash::from_str(&escrow.payment_hash)
+            .map_err(|_| SdkError::Generic("Invalid escrow payment hash".to_string()))?;
+        if let Some(counterparty_htlc) =
+            find_counterparty_htlc(sdk, &payment_hash, &escrow.counterparty_address).await?
+            && counterparty_htlc.total_value_sat >= escrow.receive_amount_sats
+        {
+            escrow.status = EscrowStatus::CounterpartyFunded;
+        }
+    }
+    Ok(escrow)
+}
+
+/// Checks the initiator's HTLC before the participant locks its funds.
+fn validate_counterparty_htlc(
+    request: &CreateEscrowRequest,
+    counterparty_amount_sats: u64,
+    counterparty_expiry_time: u64,
+    send_expiry_time: u64,
+) -> Result<(), SdkError> {
+    if counterparty_amount_sats < request.receive_amount_sats {
+        return Err(SdkError::InvalidInput(format!(
+            "The initiator locked {counterparty_amount_sats} sats, expected at least {}",
+            request.receive_amount_sats
+        )));
+    }
+    if send_expiry_time.saturating_add(MIN_EXPIRY_DELTA_SECS) > counterparty_expiry_time {
+        return Err(SdkError::InvalidInput(format!(
+            "The HTLC has to expire at least {MIN_EXPIRY_DELTA_SECS} seconds before the initiator's, at {counterparty_expiry_time}"
+        )));
+    }
+    Ok(())
+}
+
+/// Finds the claimable HTLC locked to `payment_hash` that was sent by the
+/// counterparty, so an HTLC from anyone else with the same hash is ignored.
+async fn find_counterparty_htlc(
+    sdk: &BreezSdk,
+    payment_hash: &sha256::Hash,
+    counterparty_address: &str,
+) -> Result<Option<WalletTransfer>, SdkError> {
+    let counterparty = counterparty_address
+        .parse::<SparkAddress>()
+        .map_err(|_| SdkError::InvalidInput("Invalid counterparty address".to_string()))?
+        .identity_public_key;
+    let claimable_htlc_transfers = sdk.spark_wallet.list_claimable_htlc_transfers(None).await?;
+    Ok(claimable_htlc_transfers.into_iter().find(|t| {
+        t.sender_id == counterparty
+            && t.htlc_preimage_request
+                .as_ref()
+                .is_some_and(|p| p.payment_hash == *payment_hash)
+    }))
+}
+
+/// Queries the operators for the current state of an HTLC sent by this
+/// wallet.
+pub(super) async fn sent_htlc_details(
+    sdk: &BreezSdk,
+    payment_id: &str,
+) -> Result<SparkHtlcDetails, SdkError> {
+    let transfer_id = TransferId::from_str(payment_id)
+        .map_err(|_| SdkError::Generic(format!("Invalid transfer id {payment_id}")))?;
+    let transfer = sdk
+        .spark_wallet
+        .get_sent_htlc_transfer(&transfer_id)
+        .await?
+        .ok_or(SdkError::Generic(format!("HTLC {payment_id} not found")))?;
+    htlc_details(&transfer)
+}
+
+fn htlc_details(transfer: &WalletTransfer) -> Result<SparkHtlcDetails, SdkError> {
+    transfer
+        .htlc_preimage_request
+        .clone()
+        .ok_or(SdkError::Generic(format!(
+            "Transfer {} is not an HTLC",
+            transfer.id
+        )))?
+        .try_into()
+}
+
+async fn load_escrow(sdk: &BreezSdk, escrow_id: &str) -> Result<Escrow, SdkError> {
+    ObjectCacheRepository::new(sdk.storage.clone())
+        .fetch_cached_list_entry(escrow_id)
+        .await?
+        .ok_or(SdkError::InvalidInput(format!(
+            "Escrow {escrow_id} not found"
+        )))
+}
+
+async fn save_escrow(sdk: &BreezSdk, escrow: &Escrow) -> Result<(), SdkError> {
+    ObjectCacheRepository::new(sdk.storage.clone())
+        .upsert_cached_list_entry(escrow)
+        .await?;
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn participant_request() -> CreateEscrowRequest {
+        CreateEscrowRequest {
+            payment_hash: String::new(),
+            role: EscrowRole::Participant,
+            counterparty_address: String::new(),
+            send_amount_sats: 1_000,
+            receive_amount_sats: 2_000,
+            expiry_duration_secs: 3_600,
+        }
+    }
+
+    #[test_all]
+    fn test_validate_counterparty_htlc() {
+        let request = participant_request();
+        assert!(validate_counterparty_htlc(&request, 2_000, 10_000, 9_400).is_ok());
+        // Not enough funds locked by the initiator
+        assert!(validate_counterparty_htlc(&request, 1_999, 10_000, 9_400).is_err());
+        // Expiring too close to the initiator's HTLC
+        assert!(validate_counterparty_htlc(&request, 2_000, 10_000, 9_401).is_err());
+    }
+}
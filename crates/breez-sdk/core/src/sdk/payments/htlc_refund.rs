@@ -0,0 +1,158 @@
+use std::str::FromStr;
+
+use spark_wallet::TransferId;
+use tracing::{debug, error, info};
+
+use crate::{
+    Payment, PaymentDetails, PaymentStatus, PaymentType, SparkHtlcDetails, SparkHtlcStatus,
+    error::SdkError,
+    events::SdkEvent,
+    persist::{StorageListPaymentsRequest, StoragePaymentDetailsFilter},
+    sdk::BreezSdk,
+    utils::payments::record_payment_update,
+};
+
+use super::arbitrated_escrow;
+
+/// Reclaims the funds of an outgoing Spark HTLC that expired without the
+/// receiver claiming it.
+pub(super) async fn refund_htlc_payment(
+    sdk: &BreezSdk,
+    payment_id: String,
+) -> Result<Payment, SdkError> {
+    let payment = sdk.storage.get_payment_by_id(payment_id).await?;
+    let htlc_details = sent_htlc_details(&payment)?;
+    match htlc_details.status {
+        SparkHtlcStatus::Returned => {
+            return Err(SdkError::InvalidInput(
+                "HTLC was already refunded".to_string(),
+            ));
+        }
+        SparkHtlcStatus::PreimageShared => {
+            return Err(SdkError::InvalidInput(
+                "HTLC was claimed by the receiver".to_string(),
+            ));
+        }
+        SparkHtlcStatus::WaitingForPreimage => {}
+    }
+    if htlc_details.expiry_time > sdk.now()? {
+        return Err(SdkError::InvalidInput(format!(
+            "HTLC can't be refunded before it expires at {}",
+            htlc_details.expiry_time
+        )));
+    }
+    if arbitrated_escrow::is_refund_blocked(sdk, &payment.id).await? {
+        return Err(SdkError::InvalidInput(
+            "HTLC funds a disputed escrow, whose refund must be approved first".to_string(),
+        ));
+    }
+
+    try_refund(sdk, &payment).await?.ok_or(SdkError::Generic(
+        "HTLC was not returned by the operators yet, try again later".to_string(),
+    ))
+}
+
+/// Refunds the expired outgoing HTLCs the operators returned. Called during
+/// sync when `Config::auto_refund_htlc_payments` is set.
+pub(in crate::sdk) async fn refund_expired_htlc_payments(sdk: &BreezSdk) {
+    let pending_htlcs = match sdk
+        .storage
+        .list_payments(StorageListPaymentsRequest {
+            type_filter: Some(vec![PaymentType::Send]),
+            status_filter: Some(vec![PaymentStatus::Pending]),
+            payment_details_filter: Some(vec![StoragePaymentDetailsFilter::Spark {
+                htlc_status: Some(vec![SparkHtlcStatus::WaitingForPreimage]),
+                conversion_filter: None,
+            }]),
+            ..Default::default()
+        })
+        .await
+    {
+        Ok(payments) => payments,
+        Err(e) => {
+            error!("Failed to list pending HTLC payments for refund: {e:?}");
+            return;
+        }
+    };
+
+    let Ok(now) = sdk.now() else {
+        return;
+    };
+    for payment in pending_htlcs {
+        let Ok(htlc_details) = sent_htlc_details(&payment) else {
+            continue;
+        };
+        if htlc_details.expiry_time > now {
+            continue;
+        }
+        match arbitrated_escrow::is_refund_blocked(sdk, &payment.id).await {
+            Ok(false) => {}
+            Ok(true) => {
+                debug!("Expired HTLC {} funds a disputed escrow", payment.id);
+                continue;
+            }
+            Err(e) => {
+                error!(
+                    "Failed to check escrow of expired HTLC {}: {e:?}",
+                    payment.id
+                );
+                continue;
+            }
+        }
+        match try_refund(sdk, &payment).await {
+            Ok(Some(_)) => {}
+            Ok(None) => debug!("Expired HTLC {} not returned yet", payment.id),
+            Err(e) => error!("Failed to refund expired HTLC {}: {e:?}", payment.id),
+        }
+    }
+}
+
+/// Returns `None` if the operators did not return the HTLC yet.
+async fn try_refund(sdk: &BreezSdk, payment: &Payment) -> Result<Option<Payment>, SdkError> {
+    let transfer_id = TransferId::from_str(&payment.id)
+        .map_err(|_| SdkError::Generic(format!("Invalid transfer id {}", payment.id)))?;
+    let Some(transfer) = sdk
+        .spark_wallet
+        .get_sent_htlc_transfer(&transfer_id)
+        .await?
+    else {
+        return Err(SdkError::Generic(format!("HTLC {} not found", payment.id)));
+    };
+    let updated: Payment = transfer.try_into()?;
+    let htlc_status = sent_htlc_details(&updated)?.status;
+
+    match htlc_status {
+        SparkHtlcStatus::WaitingForPreimage => Ok(None),
+        SparkHtlcStatus::PreimageShared => {
+            record_payment_update(&sdk.storage, &sdk.event_emitter, updated, true).await;
+            Err(SdkError::InvalidInput(
+                "HTLC was claimed by the receiver".to_string(),
+            ))
+        }
+        SparkHtlcStatus::Returned => {
+            // The returned leaves come back as an incoming transfer to claim
+            sdk.spark_wallet.claim_pending_transfers().await?;
+            info!("Refunded expired HTLC {}", updated.id);
+
+            record_payment_update(&sdk.storage, &sdk.event_emitter, updated.clone(), true).await;
+            sdk.event_emitter
+                .emit(&SdkEvent::HtlcRefunded {
+                    payment: updated.clone(),
+                })
+                .await;
+            Ok(Some(updated))
+        }
+    }
+}
+
+fn sent_htlc_details(payment: &Payment) -> Result<&SparkHtlcDetails, SdkError> {
+    match &payment.details {
+        Some(PaymentDetails::Spark {
+            htlc_details: Some(htlc_details),
+            ..
+        }) if payment.payment_type == PaymentType::Send => Ok(htlc_details),
+        _ => Err(SdkError::InvalidInput(
+            "Payment is not an outgoing HTLC payment".to_string(),
+        )),
+    }
+}
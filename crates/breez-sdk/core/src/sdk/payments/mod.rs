@@ -1,28 +1,53 @@
 use spark_wallet::LightningReceivePayment;
-use tracing::instrument;
+use tracing::{instrument, warn};
 
 use crate::{
-    ClaimHtlcPaymentRequest, ClaimHtlcPaymentResponse, FetchConversionLimitsRequest,
-    FetchConversionLimitsResponse, GetPaymentRequest, GetPaymentResponse, WaitForPaymentIdentifier,
+    CancelHeldPaymentRequest, CancelPaymentRequest, CancelPaymentResponse,
+    CancelPendingPaymentRequest, CancelTimeLockedPaymentRequest, CancelTimeLockedPaymentResponse,
+    ClaimHtlcPaymentRequest, ClaimHtlcPaymentResponse, ClaimSpecificTransferRequest,
+    ClaimSpecificTransferResponse, ClosePaymentStreamRequest, ClosePaymentStreamResponse,
+    CreateArbitratedEscrowRequest, CreateArbitratedEscrowResponse, CreateEscrowRequest,
+    CreateEscrowResponse, DisputeArbitratedEscrowRequest, DisputeArbitratedEscrowResponse,
+    FetchConversionLimitsRequest, FetchConversionLimitsResponse, GetEscrowRequest,
+    GetEscrowResponse, GetPaymentRequest, GetPaymentResponse, GetRemainingAllowanceRequest,
+    GetRemainingAllowanceResponse, ListArbitratedEscrowsResponse, ListEscrowsResponse,
+    ListPaymentStreamsResponse, ListTimeLockedPaymentsResponse, OpenPaymentStreamRequest,
+    OpenPaymentStreamResponse, PaymentHandle, PaymentRail, PaymentStage,
+    RecoverArbitratedEscrowPreimageRequest, RecoverArbitratedEscrowPreimageResponse,
+    RefundArbitratedEscrowRequest, RefundArbitratedEscrowResponse, RefundEscrowRequest,
+    RefundEscrowResponse, ReleaseArbitratedEscrowRequest, ReleaseArbitratedEscrowResponse,
+    ReleaseEscrowRequest, ReleaseEscrowResponse, SettleHeldPaymentRequest,
+    SettleHeldPaymentResponse, WaitForPaymentIdentifier,
     error::SdkError,
     models::{
         BuildUnsignedTransferPackageRequest, ListPaymentsRequest, ListPaymentsResponse, Payment,
         PaymentRequest, PrepareSendPaymentRequest, PrepareSendPaymentResponse,
         PublishSignedTransferPackageRequest, PublishSignedTransferPackageResponse,
-        ReceivePaymentRequest, ReceivePaymentResponse, SendPaymentRequest, SendPaymentResponse,
-        UnsignedTransferPackage,
+        ReceivePaymentRequest, ReceivePaymentResponse, RefundHtlcPaymentRequest,
+        RefundHtlcPaymentResponse, SendPaymentRequest, SendPaymentResponse,
+        SimulateSendPaymentRequest, SimulateSendPaymentResponse, UnsignedTransferPackage,
     },
     utils::payments::get_payment_with_conversion_details,
 };
 
-use super::BreezSdk;
+use super::{BreezSdk, CapReservation, CappedSend};
 
+pub(in crate::sdk) mod arbitrated_escrow;
+mod cancel;
 pub(in crate::sdk) mod client_signing;
 pub(in crate::sdk) mod conversion;
+pub(in crate::sdk) mod duplicate;
+mod escrow;
+pub(in crate::sdk) mod htlc_refund;
+mod payment_stream;
 mod polling;
 pub(in crate::sdk) mod prepare;
 mod receive;
 pub(in crate::sdk) mod send;
+mod send_async;
+mod simulate;
+pub(in crate::sdk) mod spending_caps;
+mod time_lock;
 pub(in crate::sdk) mod validation;
 
 #[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
@@ -32,6 +57,12 @@ impl BreezSdk {
         &self,
         request: ReceivePaymentRequest,
     ) -> Result<ReceivePaymentResponse, SdkError> {
+        self.ensure_rail_enabled(validation::receive_rail(&request.payment_method))?;
+        self.payment_middleware
+            .run(PaymentStage::BeforeReceive {
+                request: request.clone(),
+            })
+            .await?;
         receive::receive_payment(self, request).await
     }
 
@@ -42,36 +73,263 @@ impl BreezSdk {
         receive::claim_htlc_payment(self, request).await
     }
 
+    /// Settles the payment of a HODL invoice, created by providing a
+    /// `payment_hash` to [`BreezSdk::receive_payment`], once its HTLC arrived.
+    ///
+    /// The arrival of the HTLC is notified by a
+    /// [`SdkEvent::PaymentPending`](crate::SdkEvent::PaymentPending) event.
+    pub async fn settle_held_payment(
+        &self,
+        request: SettleHeldPaymentRequest,
+    ) -> Result<SettleHeldPaymentResponse, SdkError> {
+        receive::settle_held_payment(self, request).await
+    }
+
+    /// Refuses to settle the payment of a HODL invoice.
+    ///
+    /// The payment can no longer be settled or claimed afterwards. HTLCs
+    /// can't be returned early, so the funds go back to the sender once the
+    /// HTLC expires and the payment stays pending until then.
+    pub async fn cancel_held_payment(
+        &self,
+        request: CancelHeldPaymentRequest,
+    ) -> Result<(), SdkError> {
+        receive::cancel_held_payment(self, request).await
+    }
+
+    /// Claims a single incoming transfer by its id, without syncing the
+    /// whole wallet.
+    ///
+    /// Meant for contexts with a limited time budget that are notified of
+    /// the transfer, such as mobile background notifications or server-side
+    /// claim services. The claimed payment is stored and its event emitted
+    /// as during a sync.
+    pub async fn claim_specific_transfer(
+        &self,
+        request: ClaimSpecificTransferRequest,
+    ) -> Result<ClaimSpecificTransferResponse, SdkError> {
+        receive::claim_specific_transfer(self, request).await
+    }
+
+    /// Reclaims the funds of an outgoing Spark HTLC that expired without
+    /// being claimed by the receiver.
+    ///
+    /// Fails if the HTLC did not expire yet, was claimed, or was not returned
+    /// by the operators yet. Expired HTLCs are also refunded during sync when
+    /// [`Config::auto_refund_htlc_payments`](crate::Config::auto_refund_htlc_payments)
+    /// is set.
+    pub async fn refund_htlc_payment(
+        &self,
+        request: RefundHtlcPaymentRequest,
+    ) -> Result<RefundHtlcPaymentResponse, SdkError> {
+        let payment = htlc_refund::refund_htlc_payment(self, request.payment_id).await?;
+        Ok(RefundHtlcPaymentResponse { payment })
+    }
+
+    /// Locks funds in an atomic swap escrow with a counterparty, by sending
+    /// it a Spark HTLC.
+    ///
+    /// As participant, the initiator's HTLC has to be visible already: its
+    /// amount and expiry are checked before any funds are locked.
+    pub async fn create_escrow(
+        &self,
+        request: CreateEscrowRequest,
+    ) -> Result<CreateEscrowResponse, SdkError> {
+        let escrow = escrow::create_escrow(self, request).await?;
+        Ok(CreateEscrowResponse { escrow })
+    }
+
+    /// Returns an escrow with its status updated from the state of its HTLCs.
+    pub async fn get_escrow(
+        &self,
+        request: GetEscrowRequest,
+    ) -> Result<GetEscrowResponse, SdkError> {
+        let escrow = escrow::get_escrow(self, &request.escrow_id).await?;
+        Ok(GetEscrowResponse { escrow })
+    }
+
+    pub async fn list_escrows(&self) -> Result<ListEscrowsResponse, SdkError> {
+        let escrows = escrow::list_escrows(self).await?;
+        Ok(ListEscrowsResponse { escrows })
+    }
+
+    /// Claims the counterparty's HTLC of an escrow.
+    ///
+    /// The participant can release without the preimage once the initiator
+    /// released, as claiming the participant's HTLC reveals it.
+    pub async fn release_escrow(
+        &self,
+        request: ReleaseEscrowRequest,
+    ) -> Result<ReleaseEscrowResponse, SdkError> {
+        let escrow = escrow::release_escrow(self, &request.escrow_id, request.preimage).await?;
+        Ok(ReleaseEscrowResponse { escrow })
+    }
+
+    /// Reclaims the funds locked in an escrow once its HTLC expired.
+    pub async fn refund_escrow(
+        &self,
+        request: RefundEscrowRequest,
+    ) -> Result<RefundEscrowResponse, SdkError> {
+        let escrow = escrow::refund_escrow(self, &request.escrow_id).await?;
+        Ok(RefundEscrowResponse { escrow })
+    }
+
+    /// Locks funds in a marketplace escrow, by sending the seller a Spark
+    /// HTLC that is released by two of this wallet as buyer, the seller and
+    /// an arbiter.
+    ///
+    /// The returned escrow carries the seller's share, to be handed to the
+    /// seller, and the arbiter's share encrypted to its public key, to be
+    /// handed to the arbiter.
+    pub async fn create_arbitrated_escrow(
+        &self,
+        request: CreateArbitratedEscrowRequest,
+    ) -> Result<CreateArbitratedEscrowResponse, SdkError> {
+        let escrow = arbitrated_escrow::create_arbitrated_escrow(self, request).await?;
+        Ok(CreateArbitratedEscrowResponse { escrow })
+    }
+
+    /// Returns the arbitrated escrows with their status updated from the
+    /// state of their HTLCs.
+    pub async fn list_arbitrated_escrows(&self) -> Result<ListArbitratedEscrowsResponse, SdkError> {
+        let escrows = arbitrated_escrow::list_arbitrated_escrows(self).await?;
+        Ok(ListArbitratedEscrowsResponse { escrows })
+    }
+
+    /// Releases an arbitrated escrow to the seller.
+    ///
+    /// The returned escrow includes the buyer's share, to be passed on to the
+    /// seller, who recovers the preimage with
+    /// [`BreezSdk::recover_arbitrated_escrow_preimage`] and claims the funds
+    /// with [`BreezSdk::claim_htlc_payment`].
+    pub async fn release_arbitrated_escrow(
+        &self,
+        request: ReleaseArbitratedEscrowRequest,
+    ) -> Result<ReleaseArbitratedEscrowResponse, SdkError> {
+        let escrow = arbitrated_escrow::release_arbitrated_escrow(self, &request.escrow_id).await?;
+        Ok(ReleaseArbitratedEscrowResponse { escrow })
+    }
+
+    /// Disputes an arbitrated escrow, leaving the decision to the arbiter.
+    ///
+    /// The arbiter releases the escrow by decrypting its share and passing it
+    /// on to the seller, or approves a refund by signing it. A disputed
+    /// escrow is not refunded on expiry until the refund is approved.
+    pub async fn dispute_arbitrated_escrow(
+        &self,
+        request: DisputeArbitratedEscrowRequest,
+    ) -> Result<DisputeArbitratedEscrowResponse, SdkError> {
+        let escrow =
+            arbitrated_escrow::dispute_arbitrated_escrow(self, &request.escrow_id, request.reason)
+                .await?;
+        Ok(DisputeArbitratedEscrowResponse { escrow })
+    }
+
+    /// Approves returning the funds of an arbitrated escrow to this wallet.
+    ///
+    /// Together with this wallet's approval as buyer, the refund needs the
+    /// seller's or the arbiter's signature of `refund:<payment_hash>`. The
+    /// funds are returned once the HTLC expires, see
+    /// [`BreezSdk::refund_htlc_payment`].
+    pub async fn refund_arbitrated_escrow(
+        &self,
+        request: RefundArbitratedEscrowRequest,
+    ) -> Result<RefundArbitratedEscrowResponse, SdkError> {
+        let escrow = arbitrated_escrow::refund_arbitrated_escrow(
+            self,
+            &request.escrow_id,
+            &request.signature,
+        )
+        .await?;
+        Ok(RefundArbitratedEscrowResponse { escrow })
+    }
+
+    /// Recovers the preimage of an arbitrated escrow from the shares of two of
+    /// the buyer, the seller and the arbiter, for the seller to claim the
+    /// funds with [`BreezSdk::claim_htlc_payment`].
+    pub fn recover_arbitrated_escrow_preimage(
+        &self,
+        request: RecoverArbitratedEscrowPreimageRequest,
+    ) -> Result<RecoverArbitratedEscrowPreimageResponse, SdkError> {
+        let preimage = arbitrated_escrow::recover_arbitrated_escrow_preimage(&request.shares)?;
+        Ok(RecoverArbitratedEscrowPreimageResponse { preimage })
+    }
+
+    /// Lists the payments sent with a delivery time, see
+    /// [`SendPaymentRequest::deliver_after`], with their status updated from
+    /// the state of their HTLCs.
+    ///
+    /// Once a payment is deliverable its preimage is included. The SDK doesn't
+    /// deliver it: the app has to pass it on to the receiver, who claims the
+    /// payment with [`BreezSdk::claim_htlc_payment`].
+    pub async fn list_time_locked_payments(
+        &self,
+    ) -> Result<ListTimeLockedPaymentsResponse, SdkError> {
+        let payments = time_lock::list_time_locked_payments(self).await?;
+        Ok(ListTimeLockedPaymentsResponse { payments })
+    }
+
+    /// Cancels a time-locked payment before its delivery time.
+    ///
+    /// HTLCs can't be returned early, so the funds stay locked until the HTLC
+    /// expires, a week after the delivery time, and only then go back to this
+    /// wallet. See
+    /// [`TimeLockedPayment::expiry_time`](crate::TimeLockedPayment::expiry_time).
+    pub async fn cancel_time_locked_payment(
+        &self,
+        request: CancelTimeLockedPaymentRequest,
+    ) -> Result<CancelTimeLockedPaymentResponse, SdkError> {
+        let payment = time_lock::cancel_time_locked_payment(self, &request.payment_id).await?;
+        Ok(CancelTimeLockedPaymentResponse { payment })
+    }
+
+    /// Opens a stream paying a Spark address or Spark invoice at a fixed rate,
+    /// until its maximum amount is sent or it is closed.
+    ///
+    /// A transfer covering the next interval is sent right away, then one per
+    /// interval. Each transfer and the end of the stream are reported by
+    /// [`SdkEvent::PaymentStreamUpdated`] events. A failed transfer stops the
+    /// stream. Streams don't survive [`BreezSdk::disconnect`].
+    ///
+    /// To receive the stream as a single payment, the receiver creates a Spark
+    /// invoice accepting partial payments for the maximum amount, which the
+    /// stream pays.
+    ///
+    /// [`SdkEvent::PaymentStreamUpdated`]: crate::SdkEvent::PaymentStreamUpdated
+    pub async fn open_payment_stream(
+        &self,
+        request: OpenPaymentStreamRequest,
+    ) -> Result<OpenPaymentStreamResponse, SdkError> {
+        let stream = payment_stream::open_payment_stream(self, request).await?;
+        Ok(OpenPaymentStreamResponse { stream })
+    }
+
+    /// Closes an open payment stream. Transfers already sent are kept by the
+    /// receiver.
+    pub async fn close_payment_stream(
+        &self,
+        request: ClosePaymentStreamRequest,
+    ) -> Result<ClosePaymentStreamResponse, SdkError> {
+        let stream = payment_stream::close_payment_stream(self, &request.stream_id).await?;
+        Ok(ClosePaymentStreamResponse { stream })
+    }
+
+    /// Lists the payment streams opened by this wallet.
+    pub async fn list_payment_streams(&self) -> Result<ListPaymentStreamsResponse, SdkError> {
+        let streams = payment_stream::list_payment_streams(self).await?;
+        Ok(ListPaymentStreamsResponse { streams })
+    }
+
     pub async fn prepare_send_payment(
         &self,
         request: PrepareSendPaymentRequest,
     ) -> Result<PrepareSendPaymentResponse, SdkError> {
-        // Cross-chain has its own request type (no parse step required) — early-dispatch
-        // before falling through to the generic `Input` path.
-        if let PaymentRequest::CrossChain {
-            ref address,
-            ref route,
-            max_slippage_bps,
-            target_overpay_bps,
-        } = request.payment_request
-        {
-            let amount = request.amount.ok_or(SdkError::InvalidInput(
-                "Amount is required for cross-chain sends".to_string(),
-            ))?;
-            return prepare::cross_chain::prepare(
-                self,
-                address,
-                route,
-                amount,
-                request.token_identifier.clone(),
-                request.conversion_options.clone(),
-                request.fee_policy.unwrap_or_default(),
-                max_slippage_bps,
-                target_overpay_bps,
-            )
-            .await;
-        }
-        prepare::prepare(self, request).await
+        self.payment_middleware
+            .run(PaymentStage::BeforePrepareSend {
+                request: request.clone(),
+            })
+            .await?;
+        self.prepare_send_payment_inner(request).await
     }
 
     #[instrument(
@@ -88,9 +346,93 @@ impl BreezSdk {
         if let Some(key) = request.idempotency_key.as_deref() {
             tracing::Span::current().record("payment_id", key);
         }
+        let _reservation = self.ensure_send_allowed(&request.prepare_response).await?;
+        self.payment_middleware
+            .run(PaymentStage::BeforeSend {
+                prepare_response: request.prepare_response.clone(),
+            })
+            .await?;
         Box::pin(send::orchestrate_send(self, request, false, None)).await
     }
 
+    /// Starts sending a prepared payment in the background and returns
+    /// immediately.
+    ///
+    /// The progress of the payment is reported by [`SdkEvent::PaymentProgress`]
+    /// events carrying the returned handle. Until the transfer is initiated,
+    /// the payment can be cancelled with [`BreezSdk::cancel_pending_payment`].
+    ///
+    /// [`SdkEvent::PaymentProgress`]: crate::SdkEvent::PaymentProgress
+    pub async fn send_payment_async(
+        &self,
+        request: SendPaymentRequest,
+    ) -> Result<PaymentHandle, SdkError> {
+        self.maybe_ensure_spark_private_mode_initialized().await?;
+        let reservation = self.ensure_send_allowed(&request.prepare_response).await?;
+        self.payment_middleware
+            .run(PaymentStage::BeforeSend {
+                prepare_response: request.prepare_response.clone(),
+            })
+            .await?;
+        send_async::send_payment_async(self, request, reservation).await
+    }
+
+    /// Cancels a payment started with [`BreezSdk::send_payment_async`] whose
+    /// transfer was not initiated yet.
+    pub async fn cancel_pending_payment(
+        &self,
+        request: CancelPendingPaymentRequest,
+    ) -> Result<(), SdkError> {
+        send_async::cancel_pending_payment(self, &request.handle).await
+    }
+
+    /// Moves an outgoing payment stuck in `Pending` to a final status.
+    ///
+    /// The payment is reconciled with the operators first. If they still
+    /// report it as pending, it is failed once it can no longer complete,
+    /// which for Lightning payments is once the HTLC expired without the
+    /// preimage being shared. A [`SdkEvent::PaymentSucceeded`] or
+    /// [`SdkEvent::PaymentFailed`] event is emitted for the final status.
+    ///
+    /// [`SdkEvent::PaymentSucceeded`]: crate::SdkEvent::PaymentSucceeded
+    /// [`SdkEvent::PaymentFailed`]: crate::SdkEvent::PaymentFailed
+    pub async fn cancel_payment(
+        &self,
+        request: CancelPaymentRequest,
+    ) -> Result<CancelPaymentResponse, SdkError> {
+        let payment = cancel::cancel_payment(self, request.payment_id).await?;
+        Ok(CancelPaymentResponse { payment })
+    }
+
+    /// Returns how much can still be sent under each of `Config::spending_caps`,
+    /// counting the pending and completed sends within its rolling window.
+    pub async fn get_remaining_allowance(
+        &self,
+        request: GetRemainingAllowanceRequest,
+    ) -> Result<GetRemainingAllowanceResponse, SdkError> {
+        let _ = request;
+        self.spending_caps.get_remaining_allowance().await
+    }
+
+    /// Simulates sending a prepared payment without reserving leaves or
+    /// broadcasting anything.
+    ///
+    /// The simulation selects the leaves the payment would spend, computes its
+    /// fee and applies the balance, max fee and destination checks of the send.
+    /// Checks that fail are reported as failure reasons rather than errors.
+    pub async fn simulate_send_payment(
+        &self,
+        request: SimulateSendPaymentRequest,
+    ) -> Result<SimulateSendPaymentResponse, SdkError> {
+        simulate::simulate_send_payment(
+            self,
+            &request.prepare_response,
+            request.options.as_ref(),
+            request.max_fee,
+        )
+        .await
+    }
+
     pub async fn build_unsigned_transfer_package(
         &self,
         request: BuildUnsignedTransferPackageRequest,
@@ -182,6 +524,7 @@ impl BreezSdk {
         };
 
         for payment in &mut payments {
+            payment.fill_receive_metadata();
             let has_conversion_details = payment.conversion_details.is_some();
             let has_crosschain_info = extract_conversion_info(payment.details.clone())
                 .is_some_and(|info| !matches!(info, crate::ConversionInfo::Amm { .. }));
@@ -230,6 +573,98 @@ impl BreezSdk {
 
 // Private payment methods
 impl BreezSdk {
+    /// [`BreezSdk::prepare_send_payment`] without running the payment
+    /// middleware, for payments the SDK prepares as part of its own flows
+    pub(crate) async fn prepare_send_payment_inner(
+        &self,
+        request: PrepareSendPaymentRequest,
+    ) -> Result<PrepareSendPaymentResponse, SdkError> {
+        let request = self.resolve_fiat_amount(request).await?;
+        // Cross-chain has its own request type (no parse step required) — early-dispatch
+        // before falling through to the generic `Input` path.
+        let mut response = if let PaymentRequest::CrossChain {
+            ref address,
+            ref route,
+            max_slippage_bps,
+            target_overpay_bps,
+        } = request.payment_request
+        {
+            let amount = request.amount.ok_or(SdkError::InvalidInput(
+                "Amount is required for cross-chain sends".to_string(),
+            ))?;
+            prepare::cross_chain::prepare(
+                self,
+                address,
+                route,
+                amount,
+                request.token_identifier.clone(),
+                request.conversion_options.clone(),
+                request.fee_policy.unwrap_or_default(),
+                max_slippage_bps,
+                target_overpay_bps,
+            )
+            .await?
+        } else {
+            prepare::prepare(self, request).await?
+        };
+        validation::validate_payable_amount(
+            self.config.dust_config.as_ref(),
+            response.amount,
+            response.token_identifier.as_deref(),
+        )?;
+        response.duplicate_payment = duplicate::find_duplicate_payment(self, &response)
+            .await
+            .unwrap_or_else(|e| {
+                warn!("Failed to check for a duplicate payment: {e:?}");
+                None
+            });
+        Ok(response)
+    }
+
+    /// [`BreezSdk::send_payment`] without running the payment middleware, for
+    /// payments the SDK sends as part of its own flows
+    pub(crate) async fn send_payment_inner(
+        &self,
+        request: SendPaymentRequest,
+    ) -> Result<SendPaymentResponse, SdkError> {
+        self.maybe_ensure_spark_private_mode_initialized().await?;
+        let _reservation = self.ensure_send_allowed(&request.prepare_response).await?;
+        Box::pin(send::orchestrate_send(self, request, false, None)).await
+    }
+
+    /// Fails when the prepared send uses a disabled rail or would exceed one
+    /// of `Config::spending_caps`. The returned reservation must be held
+    /// until the send is stored or failed.
+    async fn ensure_send_allowed(
+        &self,
+        prepare_response: &PrepareSendPaymentResponse,
+    ) -> Result<CapReservation, SdkError> {
+        if let Some(rail) = validation::send_rail(&prepare_response.payment_method) {
+            self.ensure_rail_enabled(rail)?;
+        }
+        self.reserve_spending_caps(prepare_response).await
+    }
+
+    /// Fails with [`SdkError::PaymentRailDisabled`] when `rail` is not in
+    /// `Config::enabled_rails`.
+    pub(in crate::sdk) fn ensure_rail_enabled(&self, rail: PaymentRail) -> Result<(), SdkError> {
+        validation::validate_rail_enabled(&self.config.enabled_rails, rail)
+    }
+
+    /// Reserves the prepared send against `Config::spending_caps`, failing
+    /// with [`SdkError::SpendingCapExceeded`] when it would exceed one.
+    async fn reserve_spending_caps(
+        &self,
+        prepare_response: &PrepareSendPaymentResponse,
+    ) -> Result<CapReservation, SdkError> {
+        let send = CappedSend::from_prepared(
+            &prepare_response.payment_method,
+            prepare_response.amount,
+            prepare_response.token_identifier.clone(),
+        );
+        self.spending_caps.reserve(send).await
+    }
+
     pub(crate) async fn receive_bolt11_invoice(
         &self,
         description: String,
@@ -0,0 +1,372 @@
+use platform_utils::time::Duration;
+use platform_utils::tokio::{self, select};
+use tracing::{Instrument, error, info};
+
+use crate::{
+    OpenPaymentStreamRequest, PaymentStream, PaymentStreamStatus, PrepareSendPaymentRequest,
+    PrepareSendPaymentResponse, SendPaymentMethod, SendPaymentRequest, error::SdkError,
+    events::SdkEvent, models::PaymentRequest, persist::ObjectCacheRepository, sdk::BreezSdk,
+};
+
+const DEFAULT_INTERVAL_SECS: u32 = 10;
+
+/// Opens a stream and starts sending its transfers in the background.
+pub(super) async fn open_payment_stream(
+    sdk: &BreezSdk,
+    request: OpenPaymentStreamRequest,
+) -> Result<PaymentStream, SdkError> {
+    let interval_secs = request.interval_secs.unwrap_or(DEFAULT_INTERVAL_SECS);
+    if request.rate_sats_per_sec == 0 || request.max_amount_sats == 0 || interval_secs == 0 {
+        return Err(SdkError::InvalidInput(
+            "Rate, maximum amount and interval must be greater than 0".to_string(),
+        ));
+    }
+
+    let stream = PaymentStream {
+        id: uuid::Uuid::now_v7().to_string(),
+        destination: request.destination,
+        rate_sats_per_sec: request.rate_sats_per_sec,
+        max_amount_sats: request.max_amount_sats,
+        interval_secs,
+        sent_sats: 0,
+        status: PaymentStreamStatus::Open,
+        payment_ids: Vec::new(),
+        error: None,
+        started_at: sdk.now()?,
+        closed_at: None,
+    };
+    // Resolving the destination up front fails early on anything that
+    // can't be streamed to
+    prepare_transfer(sdk, &stream.destination, next_amount(&stream)).await?;
+
+    save(sdk, &stream).await?;
+    sdk.open_payment_streams
+        .lock()
+        .await
+        .insert(stream.id.clone());
+    info!("Opened payment stream {}", stream.id);
+
+    let task_sdk = sdk.clone();
+    let task_stream = stream.clone();
+    let span = tracing::Span::current();
+    tokio::spawn(
+        async move {
+            run_stream(&task_sdk, task_stream).await;
+        }
+        .instrument(span),
+    );
+    Ok(stream)
+}
+
+/// Stops a stream. Transfers already sent are not reverted.
+pub(super) async fn close_payment_stream(
+    sdk: &BreezSdk,
+    stream_id: &str,
+) -> Result<PaymentStream, SdkError> {
+    let now = sdk.now()?;
+    if !sdk.open_payment_streams.lock().await.remove(stream_id) {
+        return Err(SdkError::InvalidInput(format!(
+            "Payment stream {stream_id} is not open"
+        )));
+    }
+    let Some((stream, closed)) = ObjectCacheRepository::new(sdk.storage.clone())
+        .update_cached_list_entry(stream_id, |stream: &mut PaymentStream| {
+            if stream.status != PaymentStreamStatus::Open {
+                return false;
+            }
+            stream.status = PaymentStreamStatus::Closed;
+            stream.closed_at = Some(now);
+            true
+        })
+        .await?
+    else {
+        return Err(SdkError::InvalidInput(format!(
+            "Payment stream {stream_id} not found"
+        )));
+    };
+    if !closed {
+        // The stream task stopped it concurrently and reported it
+        return Err(SdkError::InvalidInput(format!(
+            "Payment stream {stream_id} is not open"
+        )));
+    }
+
+    info!("Closed payment stream {stream_id}");
+    updated(sdk, &stream).await;
+    Ok(stream)
+}
+
+/// Returns the streams, reporting those left open by a previous session as
+/// closed.
+pub(super) async fn list_payment_streams(sdk: &BreezSdk) -> Result<Vec<PaymentStream>, SdkError> {
+    let cache = ObjectCacheRepository::new(sdk.storage.clone());
+    let open_streams = sdk.open_payment_streams.lock().await;
+    let is_stale = |stream: &PaymentStream| {
+        stream.status == PaymentStreamStatus::Open && !open_streams.contains(&stream.id)
+    };
+    let streams = cache.fetch_cached_list::<PaymentStream>().await?;
+    if !streams.iter().any(is_stale) {
+        return Ok(streams);
+    }
+    let streams = cache
+        .update_cached_list(|streams: &mut Vec<PaymentStream>| {
+            for stream in streams.iter_mut().filter(|s| is_stale(s)) {
+                stream.status = PaymentStreamStatus::Closed;
+            }
+            streams.clone()
+        })
+        .await?;
+    Ok(streams)
+}
+
+async fn run_stream(sdk: &BreezSdk, mut stream: PaymentStream) {
+    let mut shutdown_receiver = sdk.shutdown_sender.subscribe();
+    loop {
+        let amount = next_amount(&stream);
+        let result = send_transfer(sdk, &stream.destination, amount).await;
+        if let Err(e) = &result {
+            error!("Payment stream {} failed: {e:?}", stream.id);
+        }
+
+        // The stored stream is updated in place, as it may have been closed
+        // while the transfer was in flight
+        let now = sdk.now().ok();
+        let was_open = match ObjectCacheRepository::new(sdk.storage.clone())
+            .update_cached_list_entry(&stream.id, |s: &mut PaymentStream| {
+                let was_open = s.status == PaymentStreamStatus::Open;
+                record_transfer(s, amount, &result, now);
+                was_open
+            })
+            .await
+        {
+            Ok(Some((stored, was_open))) => {
+                stream = stored;
+                was_open
+            }
+            Ok(None) => {
+                error!("Payment stream {} not found", stream.id);
+                sdk.open_payment_streams.lock().await.remove(&stream.id);
+                return;
+            }
+            Err(e) => {
+                error!("Failed to save payment stream {}: {e:?}", stream.id);
+                let was_open = stream.status == PaymentStreamStatus::Open;
+                record_transfer(&mut stream, amount, &result, now);
+                was_open
+            }
+        };
+
+        if !was_open {
+            // Closed with `close_payment_stream`, which reported it. Only the
+            // transfer that was in flight is left to report.
+            if result.is_ok() {
+                updated(sdk, &stream).await;
+            }
+            return;
+        }
+        if stream.status != PaymentStreamStatus::Open {
+            sdk.open_payment_streams.lock().await.remove(&stream.id);
+            info!("Payment stream {} stopped: {}", stream.id, stream.status);
+            updated(sdk, &stream).await;
+            return;
+        }
+        updated(sdk, &stream).await;
+
+        select! {
+            _ = shutdown_receiver.changed() => {
+                close_on_shutdown(sdk, &stream.id).await;
+                return;
+            }
+            () = tokio::time::sleep(Duration::from_secs(stream.interval_secs.into())) => {}
+        }
+
+        if !sdk.open_payment_streams.lock().await.contains(&stream.id) {
+            // Closed with `close_payment_stream`, which reported it
+            return;
+        }
+    }
+}
+
+/// Records the outcome of a transfer. A stream closed while the transfer was
+/// in flight keeps its status.
+fn record_transfer(
+    stream: &mut PaymentStream,
+    amount: u64,
+    result: &Result<String, SdkError>,
+    now: Option<u64>,
+) {
+    if let Ok(payment_id) = result {
+        stream.sent_sats = stream.sent_sats.saturating_add(amount);
+        stream.payment_ids.push(payment_id.clone());
+    }
+    if stream.status != PaymentStreamStatus::Open {
+        return;
+    }
+    match result {
+        Err(e) => {
+            stream.status = PaymentStreamStatus::Failed;
+            stream.error = Some(e.to_string());
+        }
+        Ok(_) if stream.sent_sats >= stream.max_amount_sats => {
+            stream.status = PaymentStreamStatus::CapReached;
+        }
+        Ok(_) => return,
+    }
+    stream.closed_at = now;
+}
+
+/// Closes a stream the SDK stopped on shutdown, unless it was closed
+/// already.
+async fn close_on_shutdown(sdk: &BreezSdk, stream_id: &str) {
+    sdk.open_payment_streams.lock().await.remove(stream_id);
+    let now = sdk.now().ok();
+    match ObjectCacheRepository::new(sdk.storage.clone())
+        .update_cached_list_entry(stream_id, |stream: &mut PaymentStream| {
+            if stream.status != PaymentStreamStatus::Open {
+                return false;
+            }
+            stream.status = PaymentStreamStatus::Closed;
+            stream.closed_at = now;
+            true
+        })
+        .await
+    {
+        Ok(Some((stream, true))) => {
+            info!("Payment stream {stream_id} stopped: {}", stream.status);
+            updated(sdk, &stream).await;
+        }
+        Ok(_) => {}
+        Err(e) => error!("Failed to save payment stream {stream_id}: {e:?}"),
+    }
+}
+
+/// Returns the amount of the next transfer, which covers one interval
+/// without exceeding the maximum amount of the stream.
+fn next_amount(stream: &PaymentStream) -> u64 {
+    stream
+        .rate_sats_per_sec
+        .saturating_mul(stream.interval_secs.into())
+        .min(stream.max_amount_sats.saturating_sub(stream.sent_sats))
+}
+
+async fn prepare_transfer(
+    sdk: &BreezSdk,
+    destination: &str,
+    amount: u64,
+) -> Result<PrepareSendPaymentResponse, SdkError> {
+    let prepare_response = sdk
+        .prepare_send_payment_inner(PrepareSendPaymentRequest {
+            payment_request: PaymentRequest::Input {
+                input: destination.to_string(),
+            },
+            amount: Some(amount.into()),
+            token_identifier: None,
+            conversion_options: None,
+            fee_policy: None,
+            fiat_amount: None,
+        })
+        .await?;
+    if !matches!(
+        prepare_response.payment_method,
+        SendPaymentMethod::SparkAddress { .. } | SendPaymentMethod::SparkInvoice { .. }
+    ) {
+        return Err(SdkError::InvalidInput(
+            "Payment streams can only pay a Spark address or Spark invoice".to_string(),
+        ));
+    }
+    Ok(prepare_response)
+}
+
+async fn send_transfer(sdk: &BreezSdk, destination: &str, amount: u64) -> Result<String, SdkError> {
+    let prepare_response = prepare_transfer(sdk, destination, amount).await?;
+    let response = sdk
+        .send_payment_inner(SendPaymentRequest {
+            prepare_response,
+            options: None,
+            idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
+        })
+        .await?;
+    Ok(response.payment.id)
+}
+
+async fn updated(sdk: &BreezSdk, stream: &PaymentStream) {
+    sdk.event_emitter
+        .emit(&SdkEvent::PaymentStreamUpdated {
+            stream: stream.clone(),
+        })
+        .await;
+}
+
+async fn save(sdk: &BreezSdk, stream: &PaymentStream) -> Result<(), SdkError> {
+    ObjectCacheRepository::new(sdk.storage.clone())
+        .upsert_cached_list_entry(stream)
+        .await?;
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn stream(sent_sats: u64) -> PaymentStream {
+        PaymentStream {
+            id: "id".to_string(),
+            destination: String::new(),
+            rate_sats_per_sec: 3,
+            max_amount_sats: 100,
+            interval_secs: 10,
+            sent_sats,
+            status: PaymentStreamStatus::Open,
+            payment_ids: Vec::new(),
+            error: None,
+            started_at: 0,
+            closed_at: None,
+        }
+    }
+
+    #[test_all]
+    fn test_record_transfer() {
+        let mut open = stream(60);
+        record_transfer(&mut open, 30, &Ok("payment".to_string()), Some(1));
+        assert_eq!(open.status, PaymentStreamStatus::Open);
+        assert_eq!(open.sent_sats, 90);
+        assert_eq!(open.closed_at, None);
+
+        record_transfer(&mut open, 10, &Ok("payment".to_string()), Some(2));
+        assert_eq!(open.status, PaymentStreamStatus::CapReached);
+        assert_eq!(open.payment_ids.len(), 2);
+        assert_eq!(open.closed_at, Some(2));
+
+        let mut closed = stream(60);
+        closed.status = PaymentStreamStatus::Closed;
+        record_transfer(&mut closed, 30, &Ok("payment".to_string()), Some(3));
+        assert_eq!(closed.status, PaymentStreamStatus::Closed);
+        assert_eq!(closed.sent_sats, 90);
+
+        let mut failed = stream(60);
+        record_transfer(
+            &mut failed,
+            30,
+            &Err(SdkError::Generic("failed".to_string())),
+            Some(4),
+        );
+        assert_eq!(failed.status, PaymentStreamStatus::Failed);
+        assert_eq!(failed.sent_sats, 60);
+        assert_eq!(failed.closed_at, Some(4));
+    }
+
+    #[test_all]
+    fn test_next_amount() {
+        assert_eq!(next_amount(&stream(0)), 30);
+        assert_eq!(next_amount(&stream(60)), 30);
+        assert_eq!(next_amount(&stream(90)), 10);
+        assert_eq!(next_amount(&stream(100)), 0);
+    }
+}
@@ -175,6 +175,7 @@ async fn prepare_sats_denominated(
         token_identifier,
         conversion_estimate,
         fee_policy,
+        duplicate_payment: None,
     };
 
     Ok(response)
@@ -248,6 +249,7 @@ async fn prepare_token_denominated(
         token_identifier: None,
         conversion_estimate,
         fee_policy,
+        duplicate_payment: None,
     })
 }
 
@@ -778,6 +778,7 @@ fn build_response(
         token_identifier: response_token_identifier,
         conversion_estimate,
         fee_policy,
+        duplicate_payment: None,
     }
 }
 
@@ -11,6 +11,8 @@ use crate::{
     sdk::BreezSdk,
 };
 
+use super::validation;
+
 pub(super) async fn prepare(
     sdk: &BreezSdk,
     request: PrepareSendPaymentRequest,
@@ -25,7 +27,16 @@ pub(super) async fn prepare(
             ));
         }
     };
-    let parsed_input = sdk.parse(&input).await?;
+    let parsed_input = match sdk.parse(&input).await {
+        Ok(parsed_input) => parsed_input,
+        // A destination the SDK can't parse may still be payable by a plugin
+        Err(e) => {
+            return sdk.prepare_plugin_send(&input, &request).await?.ok_or(e);
+        }
+    };
+    if let Some(rail) = validation::input_rail(&parsed_input) {
+        sdk.ensure_rail_enabled(rail)?;
+    }
 
     let fee_policy = request.fee_policy.unwrap_or_default();
     let token_identifier = request.token_identifier.clone();
@@ -48,9 +59,16 @@ pub(super) async fn prepare(
              routes, then PaymentRequest::CrossChain { address, route }."
                 .to_string(),
         )),
-        _ => Err(SdkError::InvalidInput(
-            "Unsupported payment method".to_string(),
+        // The on-chain transaction of a Spark withdrawal is built from the
+        // SSP's inputs, so the output tweak, which depends on the sender's
+        // input keys, can't be derived
+        InputType::SilentPaymentAddress(_) => Err(SdkError::InvalidInput(
+            "Sending to a silent payment address is not supported".to_string(),
         )),
+        _ => sdk
+            .prepare_plugin_send(&input, &request)
+            .await?
+            .ok_or_else(|| SdkError::InvalidInput("Unsupported payment method".to_string())),
     }
 }
 
@@ -68,6 +86,7 @@ pub(crate) mod test_helpers {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         }
     }
 
@@ -80,6 +99,7 @@ pub(crate) mod test_helpers {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         }
     }
 
@@ -95,6 +115,7 @@ pub(crate) mod test_helpers {
             token_identifier: Some(token_identifier.to_string()),
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         }
     }
 
@@ -107,6 +128,7 @@ pub(crate) mod test_helpers {
             token_identifier: None,
             conversion_options: None,
             fee_policy: Some(FeePolicy::FeesIncluded),
+            fiat_amount: None,
         }
     }
 
@@ -71,6 +71,7 @@ pub(super) async fn prepare(
         token_identifier: response_token_identifier,
         conversion_estimate,
         fee_policy,
+        duplicate_payment: None,
     };
 
     Ok(response)
@@ -1,5 +1,3 @@
-use platform_utils::time::{Duration, SystemTime, UNIX_EPOCH};
-
 use crate::{
     ConversionOptions, ConversionType, FeePolicy, SendPaymentMethod, SparkInvoiceDetails,
     error::SdkError,
@@ -13,6 +11,7 @@ fn validate_request(
     spark_invoice_details: &SparkInvoiceDetails,
     request: &PrepareSendPaymentRequest,
     identity_public_key: &str,
+    now: u64,
 ) -> Result<(), SdkError> {
     validation::validate_amount(request.amount)?;
     validation::validate_fee_policy_for_conversion(
@@ -73,10 +72,7 @@ fn validate_request(
 
     // Validate expiry time
     if let Some(expiry_time) = spark_invoice_details.expiry_time {
-        let current_time = SystemTime::now()
-            .duration_since(UNIX_EPOCH)
-            .map_err(|_| SdkError::Generic("Failed to get current time".to_string()))?;
-        if current_time > Duration::from_secs(expiry_time) {
+        if now > expiry_time {
             return Err(SdkError::InvalidInput("Invoice has expired".to_string()));
         }
     }
@@ -122,6 +118,7 @@ pub(super) async fn prepare(
         details,
         request,
         &sdk.spark_wallet.get_identity_public_key().to_string(),
+        sdk.now()?,
     )?;
 
     // Use request's token_identifier if provided, otherwise fall back to invoice's
@@ -156,6 +153,7 @@ pub(super) async fn prepare(
         token_identifier: response_token_identifier,
         conversion_estimate,
         fee_policy,
+        duplicate_payment: None,
     };
 
     Ok(response)
@@ -167,11 +165,15 @@ mod tests {
     use super::validate_request;
     use crate::{ConversionOptions, ConversionType, error::SdkError};
     use macros::test_all;
-    use platform_utils::time::{SystemTime, UNIX_EPOCH};
 
     #[cfg(feature = "browser-tests")]
     wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
 
+    /// The time the invoices are validated at
+    fn now() -> u64 {
+        1_700_000_000
+    }
+
     // ---- Token identifier match / mismatch / allowed / not allowed ----
 
     #[test_all]
@@ -182,7 +184,7 @@ mod tests {
         let request = create_token_amount_request(1000, "token123");
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_ok(),
             "Should succeed when token identifiers match"
@@ -197,7 +199,7 @@ mod tests {
         let request = create_token_amount_request(1000, "token456");
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_err(),
             "Should fail when token identifiers don't match"
@@ -221,7 +223,7 @@ mod tests {
         let request = create_test_request(); // No pay_amount - defers to invoice
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_ok(),
             "Should succeed when pay_amount is None for token invoice (defers to invoice)"
@@ -235,7 +237,7 @@ mod tests {
         let request = create_token_amount_request(1000, "token123");
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_err(),
             "Should fail when token identifier is provided for non-token invoice"
@@ -258,7 +260,7 @@ mod tests {
         let request = create_fees_included_request(1000);
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_ok(),
             "Should succeed when FeesIncluded is used for amountless Spark invoice"
@@ -272,7 +274,7 @@ mod tests {
         let request = create_fees_included_request(1000);
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_err(),
             "Should fail when FeesIncluded is used for Spark invoice with fixed amount"
@@ -295,7 +297,7 @@ mod tests {
         request.token_identifier = Some("token123".to_string());
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_ok(),
             "Should succeed when FeesIncluded is used for token Spark invoice"
@@ -307,16 +309,12 @@ mod tests {
     #[test_all]
     fn test_validate_spark_invoice_expired() {
         let mut invoice = create_test_invoice();
-        let expired_time = SystemTime::now()
-            .duration_since(UNIX_EPOCH)
-            .unwrap()
-            .as_secs()
-            .saturating_sub(1);
+        let expired_time = now().saturating_sub(1);
         invoice.expiry_time = Some(expired_time);
 
         let request = create_test_request();
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(result.is_err(), "Should fail when invoice has expired");
         if let Err(SdkError::InvalidInput(msg)) = result {
             assert!(
@@ -333,16 +331,12 @@ mod tests {
     fn test_validate_spark_invoice_valid_expiry_time() {
         let mut invoice = create_test_invoice();
         invoice.amount = Some(1000); // Invoice specifies amount
-        let future_time = SystemTime::now()
-            .duration_since(UNIX_EPOCH)
-            .unwrap()
-            .as_secs()
-            + 3600;
+        let future_time = now() + 3600;
         invoice.expiry_time = Some(future_time);
 
         let request = create_test_request();
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(result.is_ok(), "Should succeed when invoice hasn't expired");
     }
 
@@ -356,7 +350,7 @@ mod tests {
 
         let request = create_test_request();
         let identity_key = "sender_key123".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_ok(),
             "Should succeed when sender public key matches"
@@ -370,7 +364,7 @@ mod tests {
 
         let request = create_test_request();
         let identity_key = "different_key".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_err(),
             "Should fail when sender public key doesn't match"
@@ -395,7 +389,7 @@ mod tests {
         let request = create_bitcoin_amount_request(1000);
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(result.is_ok(), "Should succeed when amounts match");
     }
 
@@ -407,7 +401,7 @@ mod tests {
         let request = create_bitcoin_amount_request(2000);
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(result.is_err(), "Should fail when amounts don't match");
         if let Err(SdkError::InvalidInput(msg)) = result {
             assert!(
@@ -427,7 +421,7 @@ mod tests {
         let request = create_test_request(); // No amount in request
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_ok(),
             "Should succeed when only invoice has amount"
@@ -441,7 +435,7 @@ mod tests {
         let request = create_test_request(); // No pay_amount
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_err(),
             "Should fail when neither invoice nor request has amount"
@@ -465,7 +459,7 @@ mod tests {
         let request = create_test_request(); // No pay_amount
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_err(),
             "Should fail when neither token invoice nor request has amount"
@@ -489,17 +483,13 @@ mod tests {
         invoice.token_identifier = Some("token123".to_string());
         invoice.amount = Some(1000);
         invoice.sender_public_key = Some("sender_key123".to_string());
-        let future_time = SystemTime::now()
-            .duration_since(UNIX_EPOCH)
-            .unwrap()
-            .as_secs()
-            + 3600;
+        let future_time = now() + 3600;
         invoice.expiry_time = Some(future_time);
 
         let request = create_token_amount_request(1000, "token123");
 
         let identity_key = "sender_key123".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(result.is_ok(), "Should succeed when all validations pass");
     }
 
@@ -520,7 +510,7 @@ mod tests {
         });
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_ok(),
             "Should succeed when conversion to Bitcoin is provided"
@@ -540,7 +530,7 @@ mod tests {
         });
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_ok(),
             "Should succeed when conversion from Bitcoin is provided"
@@ -559,7 +549,7 @@ mod tests {
         });
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_err(),
             "Should fail when conversion from Bitcoin is provided"
@@ -581,7 +571,7 @@ mod tests {
         });
 
         let identity_key = "test_identity".to_string();
-        let result = validate_request(&invoice, &request, &identity_key);
+        let result = validate_request(&invoice, &request, &identity_key, now());
         assert!(
             result.is_err(),
             "Should fail when conversion to Bitcoin is provided"
@@ -3,15 +3,21 @@ use std::str::FromStr;
 use bitcoin::hashes::sha256;
 use bitcoin::secp256k1::PublicKey;
 use platform_utils::time::{Duration, SystemTime};
-use spark_wallet::{InvoiceDescription, LightningReceivePayment, Preimage};
+use spark_wallet::{InvoiceDescription, LightningReceivePayment, Preimage, TransferId};
+use tracing::info;
 
 use crate::{
-    ClaimHtlcPaymentRequest, ClaimHtlcPaymentResponse,
+    CancelHeldPaymentRequest, ClaimHtlcPaymentRequest, ClaimHtlcPaymentResponse,
+    ClaimSpecificTransferRequest, ClaimSpecificTransferResponse, SettleHeldPaymentRequest,
+    SettleHeldPaymentResponse,
     error::SdkError,
     models::{Payment, ReceivePaymentMethod, ReceivePaymentRequest, ReceivePaymentResponse},
+    persist::{CachedPartialInvoice, ObjectCacheRepository},
+    utils::payments::fetch_and_process_payment,
 };
 
 use super::super::{BreezSdk, helpers::get_deposit_address};
+use super::polling::finalize_payment;
 
 pub(super) async fn receive_payment(
     sdk: &BreezSdk,
@@ -35,15 +41,32 @@ pub(super) async fn receive_payment(
             expiry_time,
             description,
             sender_public_key,
+            accept_partial_payments,
         } => {
             let sender_public_key = sender_public_key
                 .map(|key| PublicKey::from_str(&key))
                 .transpose()
                 .map_err(|_| SdkError::InvalidInput("Invalid sender public key".to_string()))?;
+            let accept_partial_payments = accept_partial_payments.unwrap_or(false);
+            // A partial payment invoice is created amountless so payers can fulfill
+            // any part of it, the target amount is tracked locally.
+            let partial_target_amount = match (accept_partial_payments, amount) {
+                (true, Some(0) | None) => {
+                    return Err(SdkError::InvalidInput(
+                        "Amount is required for invoices accepting partial payments".to_string(),
+                    ));
+                }
+                (true, Some(amount)) => Some(amount),
+                (false, _) => None,
+            };
             let invoice = sdk
                 .spark_wallet
                 .create_spark_invoice(
-                    amount,
+                    if accept_partial_payments {
+                        None
+                    } else {
+                        amount
+                    },
                     token_identifier.clone(),
                     expiry_time
                         .map(|time| {
@@ -56,14 +79,30 @@ pub(super) async fn receive_payment(
                     sender_public_key,
                 )
                 .await?;
+            if let Some(target_amount) = partial_target_amount {
+                ObjectCacheRepository::new(sdk.storage.clone())
+                    .save_partial_invoice(
+                        &invoice,
+                        &CachedPartialInvoice {
+                            target_amount,
+                            token_identifier,
+                            ..Default::default()
+                        },
+                    )
+                    .await?;
+            }
             Ok(ReceivePaymentResponse {
                 fee: 0,
                 payment_request: invoice,
             })
         }
         ReceivePaymentMethod::BitcoinAddress { new_address } => {
-            let address =
-                get_deposit_address(&sdk.spark_wallet, new_address.unwrap_or(false)).await?;
+            let address = get_deposit_address(
+                &sdk.spark_wallet,
+                new_address.unwrap_or(sdk.config.rotate_deposit_address),
+            )
+            .await?;
+            sdk.record_issued_deposit_address(&address).await?;
             Ok(ReceivePaymentResponse {
                 payment_request: address,
                 fee: 0,
@@ -86,6 +125,15 @@ pub(super) async fn claim_htlc_payment(
         .map_err(|_| SdkError::InvalidInput("Invalid preimage".to_string()))?;
     let payment_hash = preimage.compute_hash();
 
+    if ObjectCacheRepository::new(sdk.storage.clone())
+        .fetch_held_payment_cancelled(&payment_hash.to_string())
+        .await?
+    {
+        return Err(SdkError::InvalidInput(
+            "The held payment with the given payment hash was cancelled".to_string(),
+        ));
+    }
+
     // Check if there is a claimable HTLC with the given payment hash
     let claimable_htlc_transfers = sdk.spark_wallet.list_claimable_htlc_transfers(None).await?;
     if !claimable_htlc_transfers
@@ -107,6 +155,74 @@ pub(super) async fn claim_htlc_payment(
     Ok(ClaimHtlcPaymentResponse { payment })
 }
 
+/// Settles the payment of a HODL invoice by claiming its HTLC.
+pub(super) async fn settle_held_payment(
+    sdk: &BreezSdk,
+    request: SettleHeldPaymentRequest,
+) -> Result<SettleHeldPaymentResponse, SdkError> {
+    let preimage = Preimage::from_hex(&request.preimage)
+        .map_err(|_| SdkError::InvalidInput("Invalid preimage".to_string()))?;
+    let payment_hash = sha256::Hash::from_str(&request.payment_hash)
+        .map_err(|e| SdkError::InvalidInput(format!("Invalid payment hash: {e}")))?;
+    if preimage.compute_hash() != payment_hash {
+        return Err(SdkError::InvalidInput(
+            "Preimage does not match the payment hash".to_string(),
+        ));
+    }
+
+    let response = claim_htlc_payment(
+        sdk,
+        ClaimHtlcPaymentRequest {
+            preimage: request.preimage,
+        },
+    )
+    .await?;
+    Ok(SettleHeldPaymentResponse {
+        payment: response.payment,
+    })
+}
+
+/// Refuses to settle the payment of a HODL invoice.
+///
+/// The operators have no way to return an HTLC before it expires, so the
+/// funds go back to the sender once the HTLC expires. Until then the payment
+/// stays pending, but it can no longer be claimed.
+pub(super) async fn cancel_held_payment(
+    sdk: &BreezSdk,
+    request: CancelHeldPaymentRequest,
+) -> Result<(), SdkError> {
+    // Stored in the normalized form the claim looks it up by
+    let payment_hash = sha256::Hash::from_str(&request.payment_hash)
+        .map_err(|e| SdkError::InvalidInput(format!("Invalid payment hash: {e}")))?
+        .to_string();
+    ObjectCacheRepository::new(sdk.storage.clone())
+        .save_held_payment_cancelled(&payment_hash)
+        .await?;
+    info!("Cancelled held payment {payment_hash}");
+    Ok(())
+}
+
+pub(super) async fn claim_specific_transfer(
+    sdk: &BreezSdk,
+    request: ClaimSpecificTransferRequest,
+) -> Result<ClaimSpecificTransferResponse, SdkError> {
+    // Payment ids that are not transfer ids are token payments
+    TransferId::from_str(&request.transfer_id)
+        .map_err(|_| SdkError::InvalidInput("Invalid transfer id".to_string()))?;
+
+    let payment = fetch_and_process_payment(
+        &sdk.spark_wallet,
+        sdk.storage.clone(),
+        &request.transfer_id,
+        false,
+    )
+    .await?;
+    if let Some(payment) = &payment {
+        finalize_payment(sdk, payment.clone()).await;
+    }
+    Ok(ClaimSpecificTransferResponse { payment })
+}
+
 pub(super) async fn receive_bolt11_invoice(
     sdk: &BreezSdk,
     description: String,
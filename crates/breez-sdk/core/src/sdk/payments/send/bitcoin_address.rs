@@ -13,6 +13,8 @@ use crate::{
     utils::bitcoin_dust::get_dust_limit_sats,
 };
 
+use super::leaf_selection;
+
 pub(super) async fn send(
     sdk: &BreezSdk,
     address: &BitcoinAddressDetails,
@@ -46,6 +48,10 @@ pub(super) async fn send(
         total_sats
     };
 
+    if let Some(max_fee) = request.max_fee {
+        max_fee.check(amount_sats, fee_sats)?;
+    }
+
     // Validate the output amount meets the dust limit for this address type
     let dust_limit_sats = get_dust_limit_sats(&address.address)?;
     if amount_sats < dust_limit_sats {
@@ -59,16 +65,37 @@ pub(super) async fn send(
         .as_ref()
         .map(|idempotency_key| TransferId::from_str(idempotency_key))
         .transpose()?;
-    let response = sdk
-        .spark_wallet
-        .withdraw(
-            &address.address,
-            Some(amount_sats),
-            exit_speed,
-            fee_quote.clone().into(),
-            transfer_id,
-        )
-        .await?;
+    // Selected leaves are withdrawn whole, with the fee deducted from them
+    let leaf_ids = match &request.leaf_selection {
+        Some(selection) => {
+            leaf_selection::select(sdk, selection, amount_sats.saturating_add(fee_sats)).await?
+        }
+        None => None,
+    };
+    let response = match leaf_ids {
+        Some(leaf_ids) => {
+            sdk.spark_wallet
+                .withdraw_leaves(
+                    &address.address,
+                    &leaf_ids,
+                    exit_speed,
+                    fee_quote.clone().into(),
+                    transfer_id,
+                )
+                .await?
+        }
+        None => {
+            sdk.spark_wallet
+                .withdraw(
+                    &address.address,
+                    Some(amount_sats),
+                    exit_speed,
+                    fee_quote.clone().into(),
+                    transfer_id,
+                )
+                .await?
+        }
+    };
 
     let payment: Payment = response.try_into()?;
 
@@ -95,12 +95,29 @@ pub(super) async fn send(
         amount
     };
 
+    // The prepared fee is the default fee limit. A caller-provided max fee
+    // replaces it, so the send tolerates fee changes up to that limit. Under
+    // FeesIncluded the fee was already deducted from the amount, so it stays
+    // the limit and is only checked against the max fee.
+    let max_fee_sats = match request.max_fee {
+        Some(max_fee) => {
+            let amount_sats: u64 = displayed_amount.try_into()?;
+            max_fee.check(amount_sats, fee_sats)?;
+            if is_fees_included {
+                fee_sats
+            } else {
+                max_fee.max_fee_sats(amount_sats)
+            }
+        }
+        None => fee_sats,
+    };
+
     let payment = sdk
         .lightning_sender
         .pay_and_persist_lightning_invoice(
             &invoice_details.invoice.bolt11,
             amount_to_send_sats,
-            fee_sats,
+            max_fee_sats,
             prefer_spark,
             displayed_amount,
             transfer_id,
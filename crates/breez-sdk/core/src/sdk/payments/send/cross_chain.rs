@@ -1,5 +1,5 @@
 use crate::{
-    ConversionOptions, SendPaymentMethod, SendPaymentResponse,
+    ConversionOptions, SendMaxFee, SendPaymentMethod, SendPaymentResponse,
     cross_chain::CrossChainPrepared,
     error::SdkError,
     sdk::{BreezSdk, SyncType},
@@ -16,6 +16,7 @@ pub(in crate::sdk) async fn send(
     method: &SendPaymentMethod,
     token_identifier: Option<String>,
     idempotency_key: Option<String>,
+    max_fee: Option<SendMaxFee>,
 ) -> Result<SendPaymentResponse, SdkError> {
     let SendPaymentMethod::CrossChainAddress {
         route,
@@ -37,6 +38,14 @@ pub(in crate::sdk) async fn send(
         ));
     };
 
+    // Provider fees are quoted in the destination asset and locked in the
+    // prepare response; the max fee bounds the sats-denominated source leg.
+    if let Some(max_fee) = max_fee
+        && token_identifier.is_none()
+    {
+        max_fee.check(u64::try_from(*amount_in)?, *source_transfer_fee_sats)?;
+    }
+
     let prepared = CrossChainPrepared {
         amount_in: *amount_in,
         asset_amount_in: *asset_amount_in,
@@ -0,0 +1,172 @@
+use std::{collections::HashMap, str::FromStr};
+
+use bitcoin::secp256k1::rand::{seq::SliceRandom, thread_rng};
+use spark_wallet::TreeNodeId;
+use tracing::{info, warn};
+
+use crate::{LeafSelectionStrategy, SendLeafSelection, error::SdkError, sdk::BreezSdk};
+
+/// Resolves the leaves to spend for `amount_sat`. Returns `None` when the
+/// wallet's default selection should be used instead.
+pub(super) async fn select(
+    sdk: &BreezSdk,
+    selection: &SendLeafSelection,
+    amount_sat: u64,
+) -> Result<Option<Vec<TreeNodeId>>, SdkError> {
+    let available: Vec<(String, u64)> = sdk
+        .spark_wallet
+        .list_leaves()
+        .await?
+        .available
+        .iter()
+        .map(|leaf| (leaf.id.to_string(), leaf.value))
+        .collect();
+
+    let leaf_ids = match selection {
+        SendLeafSelection::Specific { leaf_ids } => {
+            check_manual(&available, leaf_ids, amount_sat)?;
+            leaf_ids.clone()
+        }
+        SendLeafSelection::Strategy { strategy } => {
+            if *strategy == LeafSelectionStrategy::MinimizeFee {
+                return Ok(None);
+            }
+            let Some(leaf_ids) = pick(available, *strategy, amount_sat) else {
+                warn!(
+                    "No leaves add up to {amount_sat} sats for the {strategy:?} strategy, \
+                     falling back to the default selection"
+                );
+                return Ok(None);
+            };
+            info!(
+                "Selected {} leaves for {amount_sat} sats with the {strategy:?} strategy",
+                leaf_ids.len()
+            );
+            leaf_ids
+        }
+    };
+    leaf_ids
+        .iter()
+        .map(|id| TreeNodeId::from_str(id).map_err(SdkError::InvalidInput))
+        .collect::<Result<Vec<_>, _>>()
+        .map(Some)
+}
+
+fn check_manual(
+    available: &[(String, u64)],
+    leaf_ids: &[String],
+    amount_sat: u64,
+) -> Result<(), SdkError> {
+    let values: HashMap<&str, u64> = available
+        .iter()
+        .map(|(id, value)| (id.as_str(), *value))
+        .collect();
+    let mut total_sat = 0u64;
+    for id in leaf_ids {
+        let value = values
+            .get(id.as_str())
+            .ok_or_else(|| SdkError::InvalidInput(format!("Leaf {id} is not available")))?;
+        total_sat = total_sat.saturating_add(*value);
+    }
+    if total_sat != amount_sat {
+        return Err(SdkError::InvalidInput(format!(
+            "The selected leaves add up to {total_sat} sats instead of {amount_sat} sats"
+        )));
+    }
+    Ok(())
+}
+
+/// Picks the leaves adding up to exactly `amount_sat`, largest first.
+/// Returns `None` if there is no such combination or the strategy defers to
+/// the default selection.
+fn pick(
+    mut leaves: Vec<(String, u64)>,
+    strategy: LeafSelectionStrategy,
+    amount_sat: u64,
+) -> Option<Vec<String>> {
+    if amount_sat == 0 {
+        return None;
+    }
+    match strategy {
+        LeafSelectionStrategy::MinimizeFee => return None,
+        LeafSelectionStrategy::MinimizeInputs => {}
+        LeafSelectionStrategy::Privacy => leaves.shuffle(&mut thread_rng()),
+    }
+    // The sort is stable, so leaves of the same value keep their shuffled order
+    leaves.sort_by(|a, b| b.1.cmp(&a.1));
+
+    let mut remaining_sat = amount_sat;
+    let mut selected = Vec::new();
+    for (id, value) in leaves {
+        if value <= remaining_sat {
+            remaining_sat -= value;
+            selected.push(id);
+        }
+        if remaining_sat == 0 {
+            return Some(selected);
+        }
+    }
+    None
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn leaves(values: &[u64]) -> Vec<(String, u64)> {
+        values
+            .iter()
+            .enumerate()
+            .map(|(i, value)| (format!("leaf-{i}"), *value))
+            .collect()
+    }
+
+    #[test_all]
+    fn test_pick_minimize_inputs() {
+        let available = leaves(&[256, 1024, 512, 256, 64]);
+
+        assert_eq!(
+            pick(
+                available.clone(),
+                LeafSelectionStrategy::MinimizeInputs,
+                1280
+            ),
+            Some(vec!["leaf-1".to_string(), "leaf-0".to_string()])
+        );
+        assert_eq!(
+            pick(
+                available.clone(),
+                LeafSelectionStrategy::MinimizeInputs,
+                100
+            ),
+            None
+        );
+        assert_eq!(
+            pick(available, LeafSelectionStrategy::MinimizeFee, 1280),
+            None
+        );
+    }
+
+    #[test_all]
+    fn test_pick_privacy_sums_to_amount() {
+        let available = leaves(&[256, 256, 256, 1024]);
+        let values: HashMap<String, u64> = available.iter().cloned().collect();
+
+        let selected = pick(available, LeafSelectionStrategy::Privacy, 1536).unwrap();
+        assert_eq!(selected.len(), 3);
+        assert_eq!(selected.iter().map(|id| values[id]).sum::<u64>(), 1536);
+    }
+
+    #[test_all]
+    fn test_check_manual() {
+        let available = leaves(&[256, 1024]);
+
+        assert!(check_manual(&available, &["leaf-0".to_string()], 256).is_ok());
+        assert!(check_manual(&available, &["leaf-0".to_string()], 300).is_err());
+        assert!(check_manual(&available, &["leaf-9".to_string()], 256).is_err());
+    }
+}
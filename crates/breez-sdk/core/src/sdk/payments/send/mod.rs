@@ -1,6 +1,7 @@
 pub(super) mod bitcoin_address;
 pub(in crate::sdk) mod bolt11;
 pub(in crate::sdk::payments) mod cross_chain;
+mod leaf_selection;
 pub(super) mod spark_address;
 pub(super) mod spark_invoice;
 
@@ -14,9 +15,10 @@ use crate::{
         PublishSignedTransferPackageResponse, SendPaymentRequest, SendPaymentResponse,
         SignedTransferPackage, TransferSignature, TransferTarget, UnsignedTransferPackage,
     },
-    persist::ObjectCacheRepository,
+    persist::{IdempotentOperation, ObjectCacheRepository},
     sdk::BreezSdk,
     signer::{ExternalPrepareTransferRequest, ExternalPreparedTransfer},
+    utils::idempotency::run_idempotent_payment,
 };
 
 use super::conversion;
@@ -118,6 +120,7 @@ pub(in crate::sdk::payments) async fn publish_signed_transfer_package(
     sdk: &BreezSdk,
     signed_package: &SignedTransferPackage,
 ) -> Result<PublishSignedTransferPackageResponse, SdkError> {
+    sdk.ensure_not_frozen().await?;
     if matches!(
         &signed_package.unsigned,
         UnsignedTransferPackage::Transfer {
@@ -215,22 +218,36 @@ async fn deferred_transfer_send(
 //   7. emit payment event (unless suppressed)
 pub(in crate::sdk) async fn orchestrate_send(
     sdk: &BreezSdk,
-    request: SendPaymentRequest,
+    mut request: SendPaymentRequest,
     mut suppress_payment_event: bool,
     amount_override: Option<u64>,
 ) -> Result<SendPaymentResponse, SdkError> {
+    sdk.ensure_not_frozen().await?;
     let token_identifier = request.prepare_response.token_identifier.clone();
 
     // Token transfers have no idempotency hook; retrying would re-spend the
-    // source. Sats-only sends are covered by the provider's TransferId.
+    // source. They are deduplicated by a stored record of the key instead.
+    // Sats-only sends are covered by the provider's TransferId.
     let has_token_leg =
         token_identifier.is_some() || request.prepare_response.conversion_estimate.is_some();
-    if request.idempotency_key.is_some() && has_token_leg {
-        return Err(SdkError::InvalidInput(
-            "Idempotency key is not supported for payments with a token \
-             transfer leg (direct token send or AMM conversion)."
-                .to_string(),
-        ));
+    if has_token_leg && let Some(idempotency_key) = request.idempotency_key.take() {
+        let payment = run_idempotent_payment(
+            sdk.storage.clone(),
+            Some(&idempotency_key),
+            IdempotentOperation::TokenSend,
+            || async {
+                Box::pin(orchestrate_send(
+                    sdk,
+                    request,
+                    suppress_payment_event,
+                    amount_override,
+                ))
+                .await
+                .map(|response| response.payment)
+            },
+        )
+        .await?;
+        return Ok(SendPaymentResponse { payment });
     }
     if let Some(idempotency_key) = &request.idempotency_key {
         // If an idempotency key is provided, check if a payment with that id already exists
@@ -256,6 +273,16 @@ pub(in crate::sdk) async fn orchestrate_send(
     } else {
         Box::pin(send_internal(sdk, &request, amount_override)).await
     };
+    if let Ok(response) = &res
+        && let Err(e) = super::duplicate::record_sent_destination(
+            sdk,
+            &request.prepare_response,
+            &response.payment,
+        )
+        .await
+    {
+        warn!("Failed to record the payment destination: {e:?}");
+    }
     // Emit payment status event. Client runtime listens to payment events
     // and schedules a wallet-state refresh when background sync is active.
     if let Ok(response) = &res
@@ -277,6 +304,28 @@ pub(super) async fn send_internal(
     let amount = request.prepare_response.amount;
     let token_identifier = request.prepare_response.token_identifier.clone();
 
+    if request.deliver_after.is_some()
+        && !matches!(
+            request.prepare_response.payment_method,
+            SendPaymentMethod::SparkAddress { .. }
+        )
+    {
+        return Err(SdkError::InvalidInput(
+            "A delivery time is only supported for payments to a Spark address".to_string(),
+        ));
+    }
+    if request.leaf_selection.is_some()
+        && !matches!(
+            request.prepare_response.payment_method,
+            SendPaymentMethod::SparkAddress { .. } | SendPaymentMethod::BitcoinAddress { .. }
+        )
+    {
+        return Err(SdkError::InvalidInput(
+            "A leaf selection is only supported for payments to a Spark or Bitcoin address"
+                .to_string(),
+        ));
+    }
+
     match &request.prepare_response.payment_method {
         SendPaymentMethod::SparkAddress { address, .. } => {
             Box::pin(spark_address::send(
@@ -286,6 +335,8 @@ pub(super) async fn send_internal(
                 amount_override.map_or(amount, u128::from),
                 request.options.as_ref(),
                 request.idempotency_key.clone(),
+                request.deliver_after,
+                request.leaf_selection.as_ref(),
             ))
             .await
         }
@@ -295,7 +346,7 @@ pub(super) async fn send_internal(
         } => {
             spark_invoice::send(
                 sdk,
-                &spark_invoice_details.invoice,
+                spark_invoice_details,
                 request,
                 amount_override.map_or(amount, u128::from),
             )
@@ -327,8 +378,17 @@ pub(super) async fn send_internal(
                 method,
                 token_identifier,
                 request.idempotency_key.clone(),
+                request.max_fee,
             )
             .await
         }
+        SendPaymentMethod::Plugin {
+            plugin_name, quote, ..
+        } => {
+            if let Some(max_fee) = request.max_fee {
+                max_fee.check(quote.amount_sats, quote.fee_sats)?;
+            }
+            sdk.send_plugin_payment(plugin_name, quote).await
+        }
     }
 }
@@ -5,11 +5,12 @@ use platform_utils::time::Duration;
 use spark_wallet::{PreparedTokenTransfer, SparkAddress, TransferId, TransferTokenOutput};
 
 use crate::{
-    ConversionOptions, ConversionPurpose, SendPaymentOptions, SparkHtlcOptions,
+    ConversionOptions, ConversionPurpose, SendLeafSelection, SendPaymentOptions, SparkHtlcOptions,
     error::SdkError,
     models::{Payment, SendPaymentResponse},
     sdk::BreezSdk,
-    sdk::payments::conversion,
+    sdk::payments::{conversion, time_lock},
+    sdk::token_activity,
     signer::{
         ExternalPrepareTransferRequest, ExternalPreparedTokenTransaction, ExternalPreparedTransfer,
     },
@@ -17,6 +18,9 @@ use crate::{
     utils::token::map_and_persist_token_transaction,
 };
 
+use super::leaf_selection;
+
+#[allow(clippy::too_many_arguments)]
 pub(super) async fn send(
     sdk: &BreezSdk,
     address: &str,
@@ -24,11 +28,54 @@ pub(super) async fn send(
     amount: u128,
     options: Option<&SendPaymentOptions>,
     idempotency_key: Option<String>,
+    deliver_after: Option<u64>,
+    leaf_selection: Option<&SendLeafSelection>,
 ) -> Result<SendPaymentResponse, SdkError> {
     let spark_address = address
         .parse::<SparkAddress>()
         .map_err(|_| SdkError::InvalidInput("Invalid spark address".to_string()))?;
 
+    let has_htlc_options = matches!(
+        options,
+        Some(SendPaymentOptions::SparkAddress {
+            htlc_options: Some(_)
+        })
+    );
+    if leaf_selection.is_some()
+        && (token_identifier.is_some() || deliver_after.is_some() || has_htlc_options)
+    {
+        return Err(SdkError::InvalidInput(
+            "A leaf selection is only supported for plain Bitcoin transfers".to_string(),
+        ));
+    }
+
+    // If a delivery time is provided, send a time-locked HTLC transfer
+    if let Some(deliver_after) = deliver_after {
+        if token_identifier.is_some() {
+            return Err(SdkError::InvalidInput(
+                "Can't provide both token identifier and delivery time".to_string(),
+            ));
+        }
+        if let Some(SendPaymentOptions::SparkAddress {
+            htlc_options: Some(_),
+        }) = options
+        {
+            return Err(SdkError::InvalidInput(
+                "Can't provide both HTLC options and delivery time".to_string(),
+            ));
+        }
+
+        return Box::pin(time_lock::send(
+            sdk,
+            address,
+            &spark_address,
+            amount.try_into()?,
+            deliver_after,
+            idempotency_key,
+        ))
+        .await;
+    }
+
     // If HTLC options are provided, send an HTLC transfer
     if let Some(SendPaymentOptions::SparkAddress { htlc_options }) = options
         && let Some(htlc_options) = htlc_options
@@ -56,10 +103,23 @@ pub(super) async fn send(
             .as_ref()
             .map(|key| TransferId::from_str(key))
             .transpose()?;
-        let transfer = sdk
-            .spark_wallet
-            .transfer(amount.try_into()?, &spark_address, transfer_id)
-            .await?;
+        let amount_sat: u64 = amount.try_into()?;
+        let leaf_ids = match leaf_selection {
+            Some(selection) => leaf_selection::select(sdk, selection, amount_sat).await?,
+            None => None,
+        };
+        let transfer = match leaf_ids {
+            Some(leaf_ids) => {
+                sdk.spark_wallet
+                    .transfer_leaves(&leaf_ids, &spark_address, transfer_id)
+                    .await?
+            }
+            None => {
+                sdk.spark_wallet
+                    .transfer(amount_sat, &spark_address, transfer_id)
+                    .await?
+            }
+        };
         transfer.try_into()?
     };
 
@@ -69,7 +129,7 @@ pub(super) async fn send(
     Ok(SendPaymentResponse { payment })
 }
 
-async fn send_htlc(
+pub(in crate::sdk::payments) async fn send_htlc(
     sdk: &BreezSdk,
     address: &SparkAddress,
     amount_sat: u64,
@@ -165,11 +225,11 @@ async fn send_token_address(
     amount: u128,
     receiver_address: SparkAddress,
 ) -> Result<Payment, SdkError> {
-    let token_transaction = sdk
+    let result = sdk
         .spark_wallet
         .transfer_tokens(
             vec![TransferTokenOutput {
-                token_id: token_identifier,
+                token_id: token_identifier.clone(),
                 amount,
                 receiver_address: receiver_address.clone(),
                 spark_invoice: None,
@@ -177,7 +237,10 @@ async fn send_token_address(
             None,
             None,
         )
-        .await?;
+        .await
+        .map_err(SdkError::from);
+    token_activity::record_holder_freeze(sdk, &token_identifier, &result).await;
+    let token_transaction = result?;
 
     map_and_persist_token_transaction(&sdk.spark_wallet, &sdk.storage, &token_transaction).await
 }
@@ -3,7 +3,7 @@ use std::str::FromStr;
 use spark_wallet::TransferId;
 
 use crate::{
-    ConversionOptions, ConversionPurpose, SparkInvoiceDetails,
+    ConversionOptions, ConversionPurpose, SendPaymentOptions, SparkInvoiceDetails,
     error::SdkError,
     models::{SendPaymentRequest, SendPaymentResponse},
     sdk::BreezSdk,
@@ -14,10 +14,23 @@ use crate::{
 
 pub(super) async fn send(
     sdk: &BreezSdk,
-    invoice: &str,
+    spark_invoice_details: &SparkInvoiceDetails,
     request: &SendPaymentRequest,
     amount: u128,
 ) -> Result<SendPaymentResponse, SdkError> {
+    if let Some(SendPaymentOptions::SparkInvoice {
+        min_amount,
+        max_amount,
+    }) = &request.options
+    {
+        validate_amount_guardrails(
+            spark_invoice_details.amount,
+            amount,
+            *min_amount,
+            *max_amount,
+        )?;
+    }
+
     let transfer_id = request
         .idempotency_key
         .as_ref()
@@ -26,7 +39,7 @@ pub(super) async fn send(
 
     let payment = match sdk
         .spark_wallet
-        .fulfill_spark_invoice(invoice, Some(amount), transfer_id)
+        .fulfill_spark_invoice(&spark_invoice_details.invoice, Some(amount), transfer_id)
         .await?
     {
         spark_wallet::FulfillSparkInvoiceResult::Transfer(wallet_transfer) => {
@@ -44,6 +57,46 @@ pub(super) async fn send(
     Ok(SendPaymentResponse { payment })
 }
 
+/// Checks the amount being sent to an amountless invoice against the payer's
+/// `min_amount`/`max_amount` guardrails.
+fn validate_amount_guardrails(
+    invoice_amount: Option<u128>,
+    amount: u128,
+    min_amount: Option<u128>,
+    max_amount: Option<u128>,
+) -> Result<(), SdkError> {
+    if min_amount.is_none() && max_amount.is_none() {
+        return Ok(());
+    }
+    if invoice_amount.is_some() {
+        return Err(SdkError::InvalidInput(
+            "Amount guardrails are only supported for amountless invoices".to_string(),
+        ));
+    }
+    if let (Some(min_amount), Some(max_amount)) = (min_amount, max_amount)
+        && min_amount > max_amount
+    {
+        return Err(SdkError::InvalidInput(
+            "Minimum amount can't be greater than the maximum amount".to_string(),
+        ));
+    }
+    if let Some(min_amount) = min_amount
+        && amount < min_amount
+    {
+        return Err(SdkError::InvalidInput(format!(
+            "Amount {amount} is below the minimum amount {min_amount}"
+        )));
+    }
+    if let Some(max_amount) = max_amount
+        && amount > max_amount
+    {
+        return Err(SdkError::InvalidInput(format!(
+            "Amount {amount} is above the maximum amount {max_amount}"
+        )));
+    }
+    Ok(())
+}
+
 /// Runs the token conversion for a Spark-invoice send, returning the conversion
 /// response and its purpose. The purpose is `SelfTransfer` when the invoice is
 /// payable to our own identity (the conversion stays in-wallet), otherwise an
@@ -73,3 +126,45 @@ pub(in crate::sdk::payments) async fn convert_token(
         .await?;
     Ok((response, purpose))
 }
+
+#[cfg(test)]
+mod tests {
+    use super::validate_amount_guardrails;
+    use crate::error::SdkError;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_amount_guardrails_within_bounds() {
+        assert!(validate_amount_guardrails(None, 1_000, Some(500), Some(1_500)).is_ok());
+        assert!(validate_amount_guardrails(None, 1_000, Some(1_000), None).is_ok());
+        assert!(validate_amount_guardrails(None, 1_000, None, Some(1_000)).is_ok());
+        assert!(validate_amount_guardrails(Some(1_000), 1_000, None, None).is_ok());
+    }
+
+    #[test_all]
+    fn test_amount_guardrails_out_of_bounds() {
+        assert!(matches!(
+            validate_amount_guardrails(None, 400, Some(500), Some(1_500)),
+            Err(SdkError::InvalidInput(msg)) if msg.contains("below the minimum")
+        ));
+        assert!(matches!(
+            validate_amount_guardrails(None, 1_600, Some(500), Some(1_500)),
+            Err(SdkError::InvalidInput(msg)) if msg.contains("above the maximum")
+        ));
+    }
+
+    #[test_all]
+    fn test_amount_guardrails_invalid() {
+        assert!(matches!(
+            validate_amount_guardrails(Some(1_000), 1_000, Some(500), None),
+            Err(SdkError::InvalidInput(msg)) if msg.contains("amountless")
+        ));
+        assert!(matches!(
+            validate_amount_guardrails(None, 1_000, Some(1_500), Some(500)),
+            Err(SdkError::InvalidInput(msg)) if msg.contains("greater than")
+        ));
+    }
+}
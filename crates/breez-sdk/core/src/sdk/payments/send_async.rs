@@ -0,0 +1,175 @@
+use platform_utils::tokio::{self, sync::mpsc};
+use tracing::{Instrument, warn};
+
+use crate::{
+    Payment, PaymentDetails, PaymentHandle, PaymentProgressStage, PaymentStatus,
+    error::SdkError,
+    events::SdkEvent,
+    models::SendPaymentRequest,
+    sdk::{BreezSdk, CapReservation, helpers::InternalEventListener},
+};
+
+use super::send;
+
+/// Starts the payment in the background and returns its handle. The spending
+/// cap reservation is held until the transfer is initiated or failed.
+pub(super) async fn send_payment_async(
+    sdk: &BreezSdk,
+    request: SendPaymentRequest,
+    reservation: CapReservation,
+) -> Result<PaymentHandle, SdkError> {
+    // Checked again before the transfer, but failing here reports the freeze
+    // to the caller rather than as a failed payment
+    sdk.ensure_not_frozen().await?;
+    let handle = PaymentHandle {
+        id: request
+            .idempotency_key
+            .clone()
+            .unwrap_or_else(|| uuid::Uuid::now_v7().to_string()),
+    };
+    if !sdk.pending_payments.lock().await.insert(handle.id.clone()) {
+        return Err(SdkError::InvalidInput(
+            "A payment with this idempotency key is already pending".to_string(),
+        ));
+    }
+
+    let task_sdk = sdk.clone();
+    let task_handle = handle.clone();
+    let span = tracing::Span::current();
+    tokio::spawn(
+        async move {
+            run_payment(&task_sdk, &task_handle, request, reservation).await;
+        }
+        .instrument(span),
+    );
+    Ok(handle)
+}
+
+/// Cancels a payment started with `send_payment_async`. Fails if the payment
+/// is unknown or its transfer was already initiated.
+pub(super) async fn cancel_pending_payment(
+    sdk: &BreezSdk,
+    handle: &PaymentHandle,
+) -> Result<(), SdkError> {
+    // Removing the handle is what the payment task checks before initiating
+    // the transfer, so the task reports the cancellation itself
+    if sdk.pending_payments.lock().await.remove(&handle.id) {
+        Ok(())
+    } else {
+        Err(SdkError::InvalidInput(
+            "Payment is not pending or can no longer be cancelled".to_string(),
+        ))
+    }
+}
+
+async fn run_payment(
+    sdk: &BreezSdk,
+    handle: &PaymentHandle,
+    request: SendPaymentRequest,
+    reservation: CapReservation,
+) {
+    emit_progress(sdk, handle, PaymentProgressStage::Queued).await;
+
+    // Removing the handle marks the point after which the payment can no
+    // longer be cancelled
+    if !sdk.pending_payments.lock().await.remove(&handle.id) {
+        emit_progress(sdk, handle, PaymentProgressStage::Cancelled).await;
+        return;
+    }
+
+    let result = Box::pin(send::orchestrate_send(sdk, request, false, None)).await;
+    // The payment is stored by now, so it counts against the caps from there
+    drop(reservation);
+    let payment = match result {
+        Ok(response) => response.payment,
+        Err(e) => {
+            let error = e.to_string();
+            emit_progress(sdk, handle, PaymentProgressStage::Failed { error }).await;
+            return;
+        }
+    };
+
+    let payment = if payment.status == PaymentStatus::Pending {
+        emit_progress(sdk, handle, PaymentProgressStage::TransferInitiated).await;
+        match wait_for_final_payment(sdk, payment).await {
+            Ok(payment) => payment,
+            Err(e) => {
+                warn!("Stopped tracking pending payment {}: {e}", handle.id);
+                let error = format!("Stopped tracking the pending payment: {e}");
+                emit_progress(sdk, handle, PaymentProgressStage::Failed { error }).await;
+                return;
+            }
+        }
+    } else {
+        payment
+    };
+
+    if let Some(PaymentDetails::Lightning { htlc_details, .. }) = &payment.details
+        && let Some(preimage) = &htlc_details.preimage
+    {
+        let preimage = preimage.clone();
+        emit_progress(
+            sdk,
+            handle,
+            PaymentProgressStage::PreimageReceived { preimage },
+        )
+        .await;
+    }
+    let stage = match payment.status {
+        PaymentStatus::Failed => PaymentProgressStage::Failed {
+            error: "Payment failed".to_string(),
+        },
+        _ => PaymentProgressStage::Settled { payment },
+    };
+    emit_progress(sdk, handle, stage).await;
+}
+
+/// Waits for the success or failure event of a pending payment.
+async fn wait_for_final_payment(sdk: &BreezSdk, payment: Payment) -> Result<Payment, SdkError> {
+    let (tx, mut rx) = mpsc::channel(10);
+    let listener_id = sdk
+        .event_emitter
+        .add_internal_listener(Box::new(InternalEventListener::new(tx)))
+        .await;
+
+    // The payment may have settled before the listener was added
+    let stored = sdk.storage.get_payment_by_id(payment.id.clone()).await;
+    let result = match stored {
+        Ok(stored) if stored.status != PaymentStatus::Pending => Ok(stored),
+        _ => {
+            let mut shutdown = sdk.shutdown_sender.subscribe();
+            loop {
+                tokio::select! {
+                    _ = shutdown.changed() => {
+                        break Err(SdkError::Generic("Shutdown received".to_string()));
+                    }
+                    event = rx.recv() => match event {
+                        Some(
+                            SdkEvent::PaymentSucceeded { payment: p }
+                            | SdkEvent::PaymentFailed { payment: p },
+                        ) if p.id == payment.id => break Ok(p),
+                        Some(_) => {}
+                        None => break Err(SdkError::Generic("Event channel closed".to_string())),
+                    },
+                }
+            }
+        }
+    };
+
+    // Closing the channel first unblocks an emit waiting on a full channel,
+    // which would otherwise hold the listeners lock needed for the removal
+    drop(rx);
+    sdk.event_emitter
+        .remove_internal_listener(&listener_id)
+        .await;
+    result
+}
+
+async fn emit_progress(sdk: &BreezSdk, handle: &PaymentHandle, stage: PaymentProgressStage) {
+    sdk.event_emitter
+        .emit(&SdkEvent::PaymentProgress {
+            handle: handle.clone(),
+            stage,
+        })
+        .await;
+}
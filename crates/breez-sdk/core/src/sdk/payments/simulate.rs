@@ -0,0 +1,297 @@
+use crate::{
+    ConversionEstimate, ConversionType, FeePolicy, OnchainConfirmationSpeed,
+    PrepareSendPaymentResponse, SendFailureReason, SendMaxFee, SendPaymentMethod,
+    SendPaymentOptions, SimulateSendPaymentResponse, SimulatedLeaf, error::SdkError, sdk::BreezSdk,
+    utils::bitcoin_dust::get_dust_limit_sats,
+};
+
+// Rough completion times reported by the simulation. They are indications for
+// the user, not timeouts.
+const SPARK_TRANSFER_SECS: u64 = 5;
+const LIGHTNING_PAYMENT_SECS: u64 = 30;
+const LEAF_SWAP_SECS: u64 = 5;
+const CONVERSION_SECS: u64 = 30;
+const BLOCK_INTERVAL_SECS: u64 = 600;
+
+/// The payment as it would be sent, derived from the prepare response and the
+/// send options without any network calls.
+struct SimulatedSend {
+    /// Amount reaching the receiver
+    amount: u128,
+    fee: u128,
+    /// Amount and fee in sats that the max fee is checked against, for
+    /// payments whose fee is paid in sats
+    max_fee_check: Option<(u64, u64)>,
+    /// Whether the fee is paid from separate leaves, as coop exits do
+    separate_fee_leaves: bool,
+    estimated_completion_secs: Option<u64>,
+}
+
+/// Runs the selection and policy checks of a send without reserving leaves or
+/// broadcasting anything.
+pub(super) async fn simulate_send_payment(
+    sdk: &BreezSdk,
+    prepare_response: &PrepareSendPaymentResponse,
+    options: Option<&SendPaymentOptions>,
+    max_fee: Option<SendMaxFee>,
+) -> Result<SimulateSendPaymentResponse, SdkError> {
+    let mut failure_reasons = Vec::new();
+    let mut send = simulate_method(sdk, prepare_response, options, &mut failure_reasons)?;
+
+    if let Some(max_fee) = max_fee
+        && let Some((amount_sats, fee_sats)) = send.max_fee_check
+    {
+        failure_reasons.extend(check_max_fee(max_fee, amount_sats, fee_sats));
+    }
+
+    // Funds are taken from the conversion input when there is one
+    let (source_token_identifier, required) = match &prepare_response.conversion_estimate {
+        Some(ConversionEstimate {
+            options, amount_in, ..
+        }) => {
+            send.estimated_completion_secs = send
+                .estimated_completion_secs
+                .map(|secs| secs.saturating_add(CONVERSION_SECS));
+            match &options.conversion_type {
+                ConversionType::FromBitcoin => (None, *amount_in),
+                ConversionType::ToBitcoin {
+                    from_token_identifier,
+                } => (Some(from_token_identifier.clone()), *amount_in),
+            }
+        }
+        None => (
+            prepare_response.token_identifier.clone(),
+            send.amount.saturating_add(send.fee),
+        ),
+    };
+
+    let mut leaves = Vec::new();
+    let mut requires_swap = false;
+    let available = match &source_token_identifier {
+        Some(token_identifier) => sdk
+            .spark_wallet
+            .get_token_balances()
+            .await?
+            .get(token_identifier)
+            .map_or(0, |b| b.balance),
+        None => u128::from(sdk.spark_wallet.get_balance().await?),
+    };
+    if required > available {
+        failure_reasons.push(SendFailureReason::InsufficientFunds {
+            required,
+            available,
+        });
+    } else if source_token_identifier.is_none() && required > 0 {
+        // Leaves of a conversion input are spent by the conversion, which
+        // has its own fee, so the whole input is selected as the amount
+        let (amount_sats, fee_sats) =
+            if send.separate_fee_leaves && prepare_response.conversion_estimate.is_none() {
+                (u64::try_from(send.amount)?, Some(u64::try_from(send.fee)?))
+            } else {
+                (u64::try_from(required)?, None)
+            };
+        let selection = sdk
+            .spark_wallet
+            .simulate_leaf_selection(amount_sats, fee_sats)
+            .await?;
+        requires_swap = selection.requires_swap;
+        if requires_swap {
+            send.estimated_completion_secs = send
+                .estimated_completion_secs
+                .map(|secs| secs.saturating_add(LEAF_SWAP_SECS));
+        }
+        leaves = selection
+            .leaves
+            .into_iter()
+            .map(|leaf| SimulatedLeaf {
+                id: leaf.id.to_string(),
+                value: leaf.value,
+            })
+            .collect();
+    }
+
+    Ok(SimulateSendPaymentResponse {
+        will_succeed: failure_reasons.is_empty(),
+        amount: send.amount,
+        fee: send.fee,
+        token_identifier: prepare_response.token_identifier.clone(),
+        leaves,
+        requires_swap,
+        estimated_completion_secs: send.estimated_completion_secs,
+        failure_reasons,
+    })
+}
+
+fn simulate_method(
+    sdk: &BreezSdk,
+    prepare_response: &PrepareSendPaymentResponse,
+    options: Option<&SendPaymentOptions>,
+    failure_reasons: &mut Vec<SendFailureReason>,
+) -> Result<SimulatedSend, SdkError> {
+    let fees_included = prepare_response.fee_policy == FeePolicy::FeesIncluded;
+    let is_sats = prepare_response.token_identifier.is_none();
+    let net_amount = |fee: u128| {
+        if fees_included {
+            prepare_response.amount.saturating_sub(fee)
+        } else {
+            prepare_response.amount
+        }
+    };
+
+    let send = match &prepare_response.payment_method {
+        SendPaymentMethod::SparkAddress { fee, .. }
+        | SendPaymentMethod::SparkInvoice { fee, .. } => {
+            let amount = net_amount(*fee);
+            SimulatedSend {
+                amount,
+                fee: *fee,
+                max_fee_check: None,
+                separate_fee_leaves: false,
+                estimated_completion_secs: Some(SPARK_TRANSFER_SECS),
+            }
+        }
+        SendPaymentMethod::Bolt11Invoice {
+            spark_transfer_fee_sats,
+            lightning_fee_sats,
+            ..
+        } => {
+            let prefer_spark = match options {
+                Some(SendPaymentOptions::Bolt11Invoice { prefer_spark, .. }) => *prefer_spark,
+                _ => sdk.config.prefer_spark_over_lightning,
+            };
+            let (fee_sats, estimated_completion_secs) =
+                match spark_transfer_fee_sats.filter(|_| prefer_spark) {
+                    Some(fee_sats) => (fee_sats, SPARK_TRANSFER_SECS),
+                    None => (*lightning_fee_sats, LIGHTNING_PAYMENT_SECS),
+                };
+            let amount = net_amount(fee_sats.into());
+            SimulatedSend {
+                amount,
+                fee: fee_sats.into(),
+                max_fee_check: Some((u64::try_from(amount)?, fee_sats)),
+                separate_fee_leaves: false,
+                estimated_completion_secs: Some(estimated_completion_secs),
+            }
+        }
+        SendPaymentMethod::BitcoinAddress { address, fee_quote } => {
+            let confirmation_speed = match options {
+                Some(SendPaymentOptions::BitcoinAddress { confirmation_speed }) => {
+                    confirmation_speed.clone()
+                }
+                None => OnchainConfirmationSpeed::Fast,
+                Some(_) => {
+                    failure_reasons.push(SendFailureReason::InvalidOptions {
+                        reason: "Invalid options for Bitcoin address payment".to_string(),
+                    });
+                    OnchainConfirmationSpeed::Fast
+                }
+            };
+            let (speed_quote, blocks) = match confirmation_speed {
+                OnchainConfirmationSpeed::Fast => (&fee_quote.speed_fast, 1),
+                OnchainConfirmationSpeed::Medium => (&fee_quote.speed_medium, 3),
+                OnchainConfirmationSpeed::Slow => (&fee_quote.speed_slow, 6),
+            };
+            let fee_sats = speed_quote.total_fee_sat();
+
+            let now = sdk.now()?;
+            if fee_quote.expires_at <= now {
+                failure_reasons.push(SendFailureReason::QuoteExpired);
+            }
+
+            let amount_sats = u64::try_from(net_amount(fee_sats.into()))?;
+            let dust_limit_sats = get_dust_limit_sats(&address.address)?;
+            if amount_sats < dust_limit_sats {
+                failure_reasons.push(SendFailureReason::BelowDustLimit {
+                    amount_sats,
+                    dust_limit_sats,
+                });
+            }
+            SimulatedSend {
+                amount: amount_sats.into(),
+                fee: fee_sats.into(),
+                max_fee_check: Some((amount_sats, fee_sats)),
+                separate_fee_leaves: true,
+                estimated_completion_secs: Some(blocks * BLOCK_INTERVAL_SECS),
+            }
+        }
+        SendPaymentMethod::CrossChainAddress {
+            amount_in,
+            source_transfer_fee_sats,
+            ..
+        } => {
+            // Provider fees are quoted in the destination asset, so only the
+            // source transfer fee is spent from the wallet on top of the
+            // amount. Completion depends on the provider and the chain.
+            let fee = if is_sats {
+                u128::from(*source_transfer_fee_sats)
+            } else {
+                0
+            };
+            let max_fee_check = if is_sats {
+                Some((u64::try_from(*amount_in)?, *source_transfer_fee_sats))
+            } else {
+                None
+            };
+            SimulatedSend {
+                amount: *amount_in,
+                fee,
+                max_fee_check,
+                separate_fee_leaves: false,
+                estimated_completion_secs: None,
+            }
+        }
+        // Plugins pay from their own balance, which the SDK can't check
+        SendPaymentMethod::Plugin { .. } => {
+            return Err(SdkError::InvalidInput(
+                "Payments through a plugin can't be simulated".to_string(),
+            ));
+        }
+    };
+
+    // Token fees are not denominated in sats and are not bounded by the max fee
+    Ok(SimulatedSend {
+        max_fee_check: send.max_fee_check.filter(|_| is_sats),
+        ..send
+    })
+}
+
+fn check_max_fee(
+    max_fee: SendMaxFee,
+    amount_sats: u64,
+    fee_sats: u64,
+) -> Option<SendFailureReason> {
+    match max_fee.check(amount_sats, fee_sats) {
+        Err(SdkError::MaxFeeExceeded {
+            fee_sats,
+            max_fee_sats,
+        }) => Some(SendFailureReason::MaxFeeExceeded {
+            fee_sats,
+            max_fee_sats,
+        }),
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_check_max_fee() {
+        assert_eq!(
+            check_max_fee(SendMaxFee::Fixed { amount: 10 }, 1_000, 11),
+            Some(SendFailureReason::MaxFeeExceeded {
+                fee_sats: 11,
+                max_fee_sats: 10,
+            })
+        );
+        assert_eq!(
+            check_max_fee(SendMaxFee::Proportional { ppm: 10_000 }, 1_000, 10),
+            None
+        );
+    }
+}
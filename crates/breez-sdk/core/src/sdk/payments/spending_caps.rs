@@ -0,0 +1,370 @@
+//! Rolling-window caps on the amount sent, per rail, per token or for the
+//! payment rail plugins.
+//!
+//! Nothing is tracked separately: the spending of a window is summed from the
+//! pending and completed sends in storage, so it survives restarts and
+//! includes payments made from other instances once synced. Sends that are in
+//! flight and not stored yet are counted through their reservation.
+
+use std::sync::{
+    Arc,
+    atomic::{AtomicU64, Ordering},
+};
+
+use platform_utils::tokio::sync::Mutex;
+
+use crate::{
+    Clock, GetRemainingAllowanceResponse, Payment, PaymentMethod, PaymentRail, PaymentStatus,
+    PaymentType, SendPaymentMethod, SpendingAllowance, SpendingCap, SpendingCapScope, Storage,
+    error::SdkError,
+    persist::StorageListPaymentsRequest,
+    sdk::{clock::system_now, ledger::payment_token_identifier},
+};
+
+use super::validation;
+
+/// The amount a send counts against the caps of its scopes.
+#[derive(Debug, Clone)]
+pub(crate) struct CappedSend {
+    pub rail: Option<PaymentRail>,
+    pub token_identifier: Option<String>,
+    /// Whether the send goes through a payment rail plugin
+    pub plugin: bool,
+    /// Amount including fees
+    pub amount: u128,
+}
+
+impl CappedSend {
+    /// The highest fee is assumed for on-chain sends, whose speed is only
+    /// chosen when sending.
+    pub(in crate::sdk) fn from_prepared(
+        method: &SendPaymentMethod,
+        amount: u128,
+        token_identifier: Option<String>,
+    ) -> Self {
+        let fee: u128 = match method {
+            SendPaymentMethod::BitcoinAddress { fee_quote, .. } => {
+                fee_quote.speed_fast.total_fee_sat().into()
+            }
+            SendPaymentMethod::Bolt11Invoice {
+                lightning_fee_sats, ..
+            } => (*lightning_fee_sats).into(),
+            SendPaymentMethod::SparkAddress { fee, .. }
+            | SendPaymentMethod::SparkInvoice { fee, .. } => *fee,
+            SendPaymentMethod::CrossChainAddress {
+                source_transfer_fee_sats,
+                ..
+            } => (*source_transfer_fee_sats).into(),
+            SendPaymentMethod::Plugin { quote, .. } => quote.fee_sats.into(),
+        };
+        CappedSend {
+            rail: validation::send_rail(method),
+            token_identifier,
+            plugin: matches!(method, SendPaymentMethod::Plugin { .. }),
+            amount: amount.saturating_add(fee),
+        }
+    }
+
+    fn from_payment(payment: &Payment) -> Self {
+        CappedSend {
+            rail: payment_rail(payment),
+            token_identifier: payment_token_identifier(payment).map(ToString::to_string),
+            plugin: payment.method == PaymentMethod::Plugin,
+            amount: payment.amount.saturating_add(payment.fees),
+        }
+    }
+}
+
+/// The configured caps, and the sends reserved against them that are not
+/// stored yet.
+pub(crate) struct SpendingCaps {
+    caps: Vec<SpendingCap>,
+    storage: Arc<dyn Storage>,
+    clock: Option<Arc<dyn Clock>>,
+    /// Serializes reservations, so that concurrent sends can't both fit in
+    /// the same remaining allowance
+    reserve_lock: Mutex<()>,
+    reserved: Arc<std::sync::Mutex<Vec<(u64, CappedSend)>>>,
+    next_reservation_id: AtomicU64,
+}
+
+impl SpendingCaps {
+    pub(crate) fn new(
+        caps: Vec<SpendingCap>,
+        storage: Arc<dyn Storage>,
+        clock: Option<Arc<dyn Clock>>,
+    ) -> Self {
+        Self {
+            caps,
+            storage,
+            clock,
+            reserve_lock: Mutex::new(()),
+            reserved: Arc::new(std::sync::Mutex::new(Vec::new())),
+            next_reservation_id: AtomicU64::new(0),
+        }
+    }
+
+    /// Reserves `send` against the remaining allowance of the caps of its
+    /// scope, failing with [`SdkError::SpendingCapExceeded`] when it doesn't
+    /// fit. The reservation is released when dropped, so it must be held
+    /// until the send is stored or failed.
+    pub(crate) async fn reserve(&self, send: CappedSend) -> Result<CapReservation, SdkError> {
+        if !self.caps.iter().any(|cap| applies_to(&cap.scope, &send)) {
+            return Ok(CapReservation::none());
+        }
+        let _guard = self.reserve_lock.lock().await;
+        for allowance in self.allowances().await? {
+            if applies_to(&allowance.cap.scope, &send) && send.amount > allowance.remaining {
+                return Err(SdkError::SpendingCapExceeded {
+                    scope: allowance.cap.scope,
+                    remaining: allowance.remaining,
+                });
+            }
+        }
+        let id = self.next_reservation_id.fetch_add(1, Ordering::Relaxed);
+        self.reserved_sends().push((id, send));
+        Ok(CapReservation {
+            reservation: Some((id, self.reserved.clone())),
+        })
+    }
+
+    pub(crate) async fn get_remaining_allowance(
+        &self,
+    ) -> Result<GetRemainingAllowanceResponse, SdkError> {
+        let allowances = self.allowances().await?;
+        Ok(GetRemainingAllowanceResponse { allowances })
+    }
+
+    async fn allowances(&self) -> Result<Vec<SpendingAllowance>, SdkError> {
+        let Some(longest_window) = self.caps.iter().map(|c| c.window_secs).max() else {
+            return Ok(Vec::new());
+        };
+        let now = match &self.clock {
+            Some(clock) => clock.now_secs(),
+            None => system_now()?,
+        };
+        let sends = self
+            .storage
+            .list_payments(StorageListPaymentsRequest {
+                type_filter: Some(vec![PaymentType::Send]),
+                status_filter: Some(vec![PaymentStatus::Pending, PaymentStatus::Completed]),
+                from_timestamp: Some(now.saturating_sub(longest_window)),
+                ..Default::default()
+            })
+            .await?;
+        // A send being stored may be counted both from storage and from its
+        // reservation until the reservation is dropped, which only errs on
+        // the side of the cap
+        let reserved: Vec<CappedSend> = self
+            .reserved_sends()
+            .iter()
+            .map(|(_, send)| send.clone())
+            .collect();
+        Ok(self
+            .caps
+            .iter()
+            .map(|cap| allowance(cap, &sends, &reserved, now))
+            .collect())
+    }
+
+    fn reserved_sends(&self) -> std::sync::MutexGuard<'_, Vec<(u64, CappedSend)>> {
+        self.reserved
+            .lock()
+            .unwrap_or_else(std::sync::PoisonError::into_inner)
+    }
+}
+
+/// A send reserved with [`SpendingCaps::reserve`], released when dropped.
+#[must_use]
+pub(crate) struct CapReservation {
+    reservation: Option<(u64, Arc<std::sync::Mutex<Vec<(u64, CappedSend)>>>)>,
+}
+
+impl CapReservation {
+    /// A reservation for a send no cap applies to
+    fn none() -> Self {
+        Self { reservation: None }
+    }
+}
+
+impl Drop for CapReservation {
+    fn drop(&mut self) {
+        if let Some((id, reserved)) = self.reservation.take() {
+            reserved
+                .lock()
+                .unwrap_or_else(std::sync::PoisonError::into_inner)
+                .retain(|(reserved_id, _)| *reserved_id != id);
+        }
+    }
+}
+
+/// The allowance of `cap`, counting the stored `sends` within its window and
+/// the `reserved` sends.
+fn allowance(
+    cap: &SpendingCap,
+    sends: &[Payment],
+    reserved: &[CappedSend],
+    now: u64,
+) -> SpendingAllowance {
+    let window_start = now.saturating_sub(cap.window_secs);
+    let mut spent: u128 = 0;
+    let mut oldest: Option<u64> = None;
+    for payment in sends {
+        let send = CappedSend::from_payment(payment);
+        if payment.timestamp < window_start || !applies_to(&cap.scope, &send) {
+            continue;
+        }
+        spent = spent.saturating_add(send.amount);
+        oldest = Some(oldest.map_or(payment.timestamp, |o| o.min(payment.timestamp)));
+    }
+    for send in reserved {
+        if applies_to(&cap.scope, send) {
+            spent = spent.saturating_add(send.amount);
+        }
+    }
+    SpendingAllowance {
+        cap: cap.clone(),
+        spent,
+        remaining: cap.max_amount.saturating_sub(spent),
+        next_release_at: oldest.map(|t| t.saturating_add(cap.window_secs)),
+    }
+}
+
+fn applies_to(scope: &SpendingCapScope, send: &CappedSend) -> bool {
+    match scope {
+        SpendingCapScope::Rail { rail: cap_rail } => {
+            send.token_identifier.is_none() && send.rail == Some(*cap_rail)
+        }
+        SpendingCapScope::Token {
+            token_identifier: cap_token,
+        } => send.token_identifier.as_deref() == Some(cap_token.as_str()),
+        SpendingCapScope::Plugins => send.plugin,
+    }
+}
+
+fn payment_rail(payment: &Payment) -> Option<PaymentRail> {
+    match payment.method {
+        PaymentMethod::Lightning => Some(PaymentRail::Lightning),
+        PaymentMethod::Spark | PaymentMethod::Token => Some(PaymentRail::Spark),
+        PaymentMethod::Withdraw | PaymentMethod::Deposit => Some(PaymentRail::Bitcoin),
+        PaymentMethod::Plugin | PaymentMethod::Unknown => None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn send(id: &str, method: PaymentMethod, amount: u128, fees: u128, ts: u64) -> Payment {
+        Payment {
+            id: id.to_string(),
+            payment_type: PaymentType::Send,
+            status: PaymentStatus::Completed,
+            amount,
+            fees,
+            timestamp: ts,
+            method,
+            details: None,
+            conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
+        }
+    }
+
+    fn capped(
+        rail: Option<PaymentRail>,
+        token_identifier: Option<&str>,
+        plugin: bool,
+        amount: u128,
+    ) -> CappedSend {
+        CappedSend {
+            rail,
+            token_identifier: token_identifier.map(ToString::to_string),
+            plugin,
+            amount,
+        }
+    }
+
+    fn lightning_cap() -> SpendingCap {
+        SpendingCap {
+            scope: SpendingCapScope::Rail {
+                rail: PaymentRail::Lightning,
+            },
+            max_amount: 100_000,
+            window_secs: 86_400,
+        }
+    }
+
+    #[test_all]
+    fn test_allowance_rolling_window() {
+        let now = 1_000_000;
+        let sends = vec![
+            // Left the window
+            send("old", PaymentMethod::Lightning, 50_000, 0, now - 86_401),
+            send("a", PaymentMethod::Lightning, 30_000, 100, now - 3_600),
+            send("b", PaymentMethod::Lightning, 20_000, 0, now - 60),
+            // Other rail
+            send("c", PaymentMethod::Spark, 40_000, 0, now - 60),
+        ];
+
+        let allowance = allowance(&lightning_cap(), &sends, &[], now);
+
+        assert_eq!(allowance.spent, 50_100);
+        assert_eq!(allowance.remaining, 49_900);
+        assert_eq!(allowance.next_release_at, Some(now - 3_600 + 86_400));
+    }
+
+    #[test_all]
+    fn test_allowance_exhausted() {
+        let now = 1_000_000;
+        let sends = vec![send("a", PaymentMethod::Lightning, 150_000, 0, now)];
+
+        let allowance = allowance(&lightning_cap(), &sends, &[], now);
+
+        assert_eq!(allowance.remaining, 0);
+    }
+
+    #[test_all]
+    fn test_allowance_counts_reserved_sends() {
+        let now = 1_000_000;
+        let sends = vec![send("a", PaymentMethod::Lightning, 30_000, 0, now - 60)];
+        let reserved = vec![
+            capped(Some(PaymentRail::Lightning), None, false, 40_000),
+            // Other rail
+            capped(Some(PaymentRail::Spark), None, false, 10_000),
+        ];
+
+        let allowance = allowance(&lightning_cap(), &sends, &reserved, now);
+
+        assert_eq!(allowance.spent, 70_000);
+        assert_eq!(allowance.remaining, 30_000);
+        assert_eq!(allowance.next_release_at, Some(now - 60 + 86_400));
+    }
+
+    #[test_all]
+    fn test_applies_to() {
+        let rail = SpendingCapScope::Rail {
+            rail: PaymentRail::Spark,
+        };
+        let token = SpendingCapScope::Token {
+            token_identifier: "usdt".to_string(),
+        };
+
+        let plugins = SpendingCapScope::Plugins;
+        let spark = Some(PaymentRail::Spark);
+
+        assert!(applies_to(&rail, &capped(spark, None, false, 1)));
+        assert!(!applies_to(&rail, &capped(spark, Some("usdt"), false, 1)));
+        assert!(!applies_to(&rail, &capped(None, None, false, 1)));
+        assert!(applies_to(&token, &capped(spark, Some("usdt"), false, 1)));
+        assert!(!applies_to(&token, &capped(spark, Some("other"), false, 1)));
+        assert!(!applies_to(&token, &capped(spark, None, false, 1)));
+        assert!(applies_to(&plugins, &capped(None, None, true, 1)));
+        assert!(!applies_to(&plugins, &capped(None, None, false, 1)));
+        assert!(!applies_to(&rail, &capped(None, None, true, 1)));
+    }
+}
@@ -0,0 +1,243 @@
+use bitcoin::secp256k1::rand::{RngCore, thread_rng};
+use spark_wallet::{Preimage, SparkAddress};
+use tracing::info;
+
+use crate::{
+    SparkHtlcOptions, SparkHtlcStatus, TimeLockedPayment, TimeLockedPaymentStatus,
+    error::SdkError,
+    models::SendPaymentResponse,
+    persist::{CachedTimeLockedPayment, ObjectCacheRepository},
+    sdk::BreezSdk,
+};
+
+use super::{escrow, send::spark_address};
+
+/// The time the receiver has to claim a time-locked payment after its
+/// delivery time, before the funds are returned to the sender.
+const CLAIM_WINDOW_SECS: u64 = 7 * 24 * 60 * 60;
+
+/// Sends an HTLC to the receiver whose preimage is only revealed after
+/// `deliver_after`.
+pub(super) async fn send(
+    sdk: &BreezSdk,
+    receiver_address: &str,
+    spark_address: &SparkAddress,
+    amount_sat: u64,
+    deliver_after: u64,
+    idempotency_key: Option<String>,
+) -> Result<SendPaymentResponse, SdkError> {
+    let now = sdk.now()?;
+    if deliver_after <= now {
+        return Err(SdkError::InvalidInput(
+            "Delivery time must be in the future".to_string(),
+        ));
+    }
+
+    let preimage = random_preimage()?;
+    let expiry_time = deliver_after.saturating_add(CLAIM_WINDOW_SECS);
+    let htlc_options = SparkHtlcOptions {
+        payment_hash: preimage.compute_hash().to_string(),
+        expiry_duration_secs: expiry_time.saturating_sub(now),
+    };
+    let response = Box::pin(spark_address::send_htlc(
+        sdk,
+        spark_address,
+        amount_sat,
+        &htlc_options,
+        idempotency_key,
+    ))
+    .await?;
+
+    let payment = TimeLockedPayment {
+        payment_id: response.payment.id.clone(),
+        receiver_address: receiver_address.to_string(),
+        amount_sats: amount_sat,
+        deliver_after,
+        expiry_time,
+        status: TimeLockedPaymentStatus::Locked,
+        preimage: None,
+        created_at: now,
+    };
+    info!(
+        "Sent time-locked payment {} deliverable after {deliver_after}",
+        payment.payment_id
+    );
+    save(
+        sdk,
+        &CachedTimeLockedPayment {
+            payment,
+            preimage: preimage.encode_hex(),
+        },
+    )
+    .await?;
+    Ok(response)
+}
+
+/// Returns the time-locked payments with their status updated from the state
+/// of their HTLCs.
+pub(super) async fn list_time_locked_payments(
+    sdk: &BreezSdk,
+) -> Result<Vec<TimeLockedPayment>, SdkError> {
+    let cache = ObjectCacheRepository::new(sdk.storage.clone());
+    let now = sdk.now()?;
+    let mut cached_payments = Vec::new();
+    for cached in cache.fetch_cached_list::<CachedTimeLockedPayment>().await? {
+        let status = cached.payment.status;
+        let refreshed = refresh(sdk, cached, now).await?;
+        if refreshed.payment.status != status {
+            cache.upsert_cached_list_entry(&refreshed).await?;
+        }
+        cached_payments.push(refreshed);
+    }
+    Ok(cached_payments.into_iter().map(reveal).collect())
+}
+
+/// Withholds the preimage of a time-locked payment whose delivery time did
+/// not pass yet, so that its funds are returned once the HTLC expires.
+pub(super) async fn cancel_time_locked_payment(
+    sdk: &BreezSdk,
+    payment_id: &str,
+) -> Result<TimeLockedPayment, SdkError> {
+    let cached = ObjectCacheRepository::new(sdk.storage.clone())
+        .fetch_cached_list_entry::<CachedTimeLockedPayment>(payment_id)
+        .await?
+        .ok_or(SdkError::InvalidInput(format!(
+            "Time-locked payment {payment_id} not found"
+        )))?;
+    let mut cached = refresh(sdk, cached, sdk.now()?).await?;
+    if cached.payment.status != TimeLockedPaymentStatus::Locked {
+        return Err(SdkError::InvalidInput(format!(
+            "Time-locked payment can't be cancelled once {}",
+            cached.payment.status
+        )));
+    }
+
+    cached.payment.status = TimeLockedPaymentStatus::Cancelled;
+    save(sdk, &cached).await?;
+    info!("Cancelled time-locked payment {payment_id}");
+    Ok(reveal(cached))
+}
+
+async fn refresh(
+    sdk: &BreezSdk,
+    mut cached: CachedTimeLockedPayment,
+    now: u64,
+) -> Result<CachedTimeLockedPayment, SdkError> {
+    if matches!(
+        cached.payment.status,
+        TimeLockedPaymentStatus::Claimed | TimeLockedPaymentStatus::Returned
+    ) {
+        return Ok(cached);
+    }
+
+    let htlc_status = escrow::sent_htlc_details(sdk, &cached.payment.payment_id)
+        .await?
+        .status;
+    cached.payment.status = next_status(
+        cached.payment.status,
+        htlc_status,
+        cached.payment.deliver_after,
+        now,
+    );
+    Ok(cached)
+}
+
+fn next_status(
+    status: TimeLockedPaymentStatus,
+    htlc_status: SparkHtlcStatus,
+    deliver_after: u64,
+    now: u64,
+) -> TimeLockedPaymentStatus {
+    match htlc_status {
+        SparkHtlcStatus::PreimageShared => TimeLockedPaymentStatus::Claimed,
+        SparkHtlcStatus::Returned => TimeLockedPaymentStatus::Returned,
+        SparkHtlcStatus::WaitingForPreimage => match status {
+            TimeLockedPaymentStatus::Cancelled => TimeLockedPaymentStatus::Cancelled,
+            _ if now >= deliver_after => TimeLockedPaymentStatus::Deliverable,
+            _ => TimeLockedPaymentStatus::Locked,
+        },
+    }
+}
+
+/// Exposes the preimage of a payment only once it is deliverable.
+fn reveal(cached: CachedTimeLockedPayment) -> TimeLockedPayment {
+    let mut payment = cached.payment;
+    if matches!(
+        payment.status,
+        TimeLockedPaymentStatus::Deliverable | TimeLockedPaymentStatus::Claimed
+    ) {
+        payment.preimage = Some(cached.preimage);
+    }
+    payment
+}
+
+pub(super) fn random_preimage() -> Result<Preimage, SdkError> {
+    let mut bytes = [0u8; 32];
+    thread_rng().fill_bytes(&mut bytes);
+    Preimage::try_from(bytes.to_vec())
+        .map_err(|e| SdkError::Generic(format!("Failed to generate preimage: {e}")))
+}
+
+async fn save(sdk: &BreezSdk, cached: &CachedTimeLockedPayment) -> Result<(), SdkError> {
+    ObjectCacheRepository::new(sdk.storage.clone())
+        .upsert_cached_list_entry(cached)
+        .await?;
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_next_status() {
+        use TimeLockedPaymentStatus::*;
+
+        let waiting = SparkHtlcStatus::WaitingForPreimage;
+        assert_eq!(next_status(Locked, waiting, 100, 99), Locked);
+        assert_eq!(next_status(Locked, waiting, 100, 100), Deliverable);
+        assert_eq!(next_status(Cancelled, waiting, 100, 200), Cancelled);
+        assert_eq!(
+            next_status(Deliverable, SparkHtlcStatus::PreimageShared, 100, 200),
+            Claimed
+        );
+        assert_eq!(
+            next_status(Cancelled, SparkHtlcStatus::Returned, 100, 200),
+            Returned
+        );
+    }
+
+    #[test_all]
+    fn test_reveal_preimage_once_deliverable() {
+        let cached = |status| CachedTimeLockedPayment {
+            payment: TimeLockedPayment {
+                payment_id: "id".to_string(),
+                receiver_address: String::new(),
+                amount_sats: 1_000,
+                deliver_after: 100,
+                expiry_time: 100 + CLAIM_WINDOW_SECS,
+                status,
+                preimage: None,
+                created_at: 0,
+            },
+            preimage: "preimage".to_string(),
+        };
+
+        assert_eq!(
+            reveal(cached(TimeLockedPaymentStatus::Locked)).preimage,
+            None
+        );
+        assert_eq!(
+            reveal(cached(TimeLockedPaymentStatus::Cancelled)).preimage,
+            None
+        );
+        assert_eq!(
+            reveal(cached(TimeLockedPaymentStatus::Deliverable)).preimage,
+            Some("preimage".to_string())
+        );
+    }
+}
@@ -4,7 +4,11 @@
 //! per-type `prepare/<type>.rs::validate_request` calls them first, so the
 //! complete set of rules for an input type is visible in that type's own file.
 
-use crate::{ConversionOptions, ConversionType, FeePolicy, error::SdkError};
+use crate::{
+    ConversionOptions, ConversionType, DustConfig, FeePolicy, InputType, PaymentRail,
+    ReceivePaymentMethod, SendPaymentMethod, error::SdkError,
+    sdk::incoming_uri::preferred_bip21_method,
+};
 
 /// Validates that amount is > 0 if provided.
 pub(in crate::sdk) fn validate_amount(amount: Option<u128>) -> Result<(), SdkError> {
@@ -39,9 +43,85 @@ pub(in crate::sdk) fn validate_fee_policy_for_conversion(
     Ok(())
 }
 
+/// Validates that the amount sent is not below the payable minimum of its
+/// asset.
+pub(in crate::sdk) fn validate_payable_amount(
+    dust_config: Option<&DustConfig>,
+    amount: u128,
+    token_identifier: Option<&str>,
+) -> Result<(), SdkError> {
+    let Some(limit) = dust_config.and_then(|c| c.limit(token_identifier)) else {
+        return Ok(());
+    };
+    if amount < limit.min_payable {
+        return Err(SdkError::InvalidInput(format!(
+            "Amount must be at least {}",
+            limit.min_payable
+        )));
+    }
+    Ok(())
+}
+
+/// Validates that `rail` is one of the enabled payment rails.
+pub(in crate::sdk) fn validate_rail_enabled(
+    enabled_rails: &[PaymentRail],
+    rail: PaymentRail,
+) -> Result<(), SdkError> {
+    if !enabled_rails.contains(&rail) {
+        return Err(SdkError::PaymentRailDisabled { rail });
+    }
+    Ok(())
+}
+
+/// The rail a parsed send input is paid over, if it maps to one. A BIP21 URI
+/// is paid over the rail of its preferred payment method.
+pub(in crate::sdk) fn input_rail(input: &InputType) -> Option<PaymentRail> {
+    match input {
+        InputType::Bip21(details) => preferred_bip21_method(details).and_then(input_rail),
+        InputType::SparkAddress(_) | InputType::SparkInvoice(_) => Some(PaymentRail::Spark),
+        InputType::Bolt11Invoice(_)
+        | InputType::Bolt12Invoice(_)
+        | InputType::Bolt12Offer(_)
+        | InputType::LightningAddress(_)
+        | InputType::LnurlPay(_)
+        | InputType::LnurlWithdraw(_) => Some(PaymentRail::Lightning),
+        InputType::BitcoinAddress(_) | InputType::SilentPaymentAddress(_) => {
+            Some(PaymentRail::Bitcoin)
+        }
+        _ => None,
+    }
+}
+
+/// The rail a prepared send is paid over. Cross-chain and plugin sends have
+/// none.
+pub(in crate::sdk) fn send_rail(method: &SendPaymentMethod) -> Option<PaymentRail> {
+    match method {
+        SendPaymentMethod::BitcoinAddress { .. } => Some(PaymentRail::Bitcoin),
+        SendPaymentMethod::Bolt11Invoice { .. } => Some(PaymentRail::Lightning),
+        SendPaymentMethod::SparkAddress { .. } | SendPaymentMethod::SparkInvoice { .. } => {
+            Some(PaymentRail::Spark)
+        }
+        SendPaymentMethod::CrossChainAddress { .. } | SendPaymentMethod::Plugin { .. } => None,
+    }
+}
+
+/// The rail a payment is received over.
+pub(in crate::sdk) fn receive_rail(method: &ReceivePaymentMethod) -> PaymentRail {
+    match method {
+        ReceivePaymentMethod::SparkAddress | ReceivePaymentMethod::SparkInvoice { .. } => {
+            PaymentRail::Spark
+        }
+        ReceivePaymentMethod::BitcoinAddress { .. } => PaymentRail::Bitcoin,
+        ReceivePaymentMethod::Bolt11Invoice { .. } => PaymentRail::Lightning,
+    }
+}
+
 #[cfg(test)]
 mod tests {
+    use std::collections::HashMap;
+
     use super::*;
+    use crate::AssetDustLimit;
     use macros::test_all;
 
     #[cfg(feature = "browser-tests")]
@@ -122,4 +202,78 @@ mod tests {
         // FeesIncluded with no conversion options is fine.
         assert!(validate_fee_policy_for_conversion(Some(FeePolicy::FeesIncluded), None).is_ok());
     }
+
+    #[test_all]
+    fn test_validate_payable_amount() {
+        let dust_config = DustConfig {
+            sats: AssetDustLimit {
+                min_displayable: 0,
+                min_payable: 100,
+            },
+            tokens: HashMap::from([(
+                "token".to_string(),
+                AssetDustLimit {
+                    min_displayable: 0,
+                    min_payable: 1_000,
+                },
+            )]),
+            suppress_dust_balances: false,
+        };
+
+        assert!(validate_payable_amount(None, 1, None).is_ok());
+        assert!(validate_payable_amount(Some(&dust_config), 99, None).is_err());
+        assert!(validate_payable_amount(Some(&dust_config), 100, None).is_ok());
+        assert!(validate_payable_amount(Some(&dust_config), 999, Some("token")).is_err());
+        assert!(validate_payable_amount(Some(&dust_config), 1_000, Some("token")).is_ok());
+        assert!(validate_payable_amount(Some(&dust_config), 1, Some("other")).is_ok());
+    }
+
+    #[test_all]
+    fn test_validate_rail_enabled() {
+        let enabled = [PaymentRail::Lightning, PaymentRail::Spark];
+        assert!(validate_rail_enabled(&enabled, PaymentRail::Lightning).is_ok());
+        assert!(matches!(
+            validate_rail_enabled(&enabled, PaymentRail::Bitcoin),
+            Err(SdkError::PaymentRailDisabled {
+                rail: PaymentRail::Bitcoin
+            })
+        ));
+        assert!(validate_rail_enabled(&[], PaymentRail::Spark).is_err());
+    }
+
+    #[test_all]
+    fn test_input_rail_of_bip21_follows_preferred_method() {
+        let bitcoin_address = InputType::BitcoinAddress(crate::BitcoinAddressDetails {
+            address: "bc1qabc".to_string(),
+            network: crate::BitcoinNetwork::Bitcoin,
+            source: crate::PaymentRequestSource::default(),
+        });
+        let spark_address = InputType::SparkAddress(crate::SparkAddressDetails {
+            address: "sp1abc".to_string(),
+            identity_public_key: "02abc".to_string(),
+            network: crate::BitcoinNetwork::Bitcoin,
+            source: crate::PaymentRequestSource::default(),
+        });
+        let bip21 = |payment_methods| {
+            InputType::Bip21(crate::Bip21Details {
+                amount_sat: None,
+                asset_id: None,
+                uri: "bitcoin:bc1qabc".to_string(),
+                extras: Vec::new(),
+                label: None,
+                message: None,
+                payment_methods,
+            })
+        };
+
+        assert_eq!(
+            input_rail(&bip21(vec![bitcoin_address.clone()])),
+            Some(PaymentRail::Bitcoin)
+        );
+        assert_eq!(
+            input_rail(&bip21(vec![bitcoin_address, spark_address])),
+            Some(PaymentRail::Spark)
+        );
+        assert_eq!(input_rail(&bip21(Vec::new())), None);
+    }
 }
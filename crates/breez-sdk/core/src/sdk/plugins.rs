@@ -0,0 +1,259 @@
+use std::{collections::HashSet, sync::Arc};
+
+use futures::future::join_all;
+use tracing::{debug, error, warn};
+
+use crate::{
+    FeePolicy, Payment, PaymentMethod, PaymentType, PluginBalance, PluginInfo, PluginSendQuote,
+    PrepareSendPaymentRequest, PrepareSendPaymentResponse, SdkPlugin, SendPaymentMethod,
+    SendPaymentResponse, error::SdkError,
+};
+
+use super::BreezSdk;
+
+/// The plugins registered with `SdkBuilder::with_plugin`, with the info each
+/// reported when the SDK was built
+pub(crate) struct PluginRegistry {
+    plugins: Vec<(PluginInfo, Arc<dyn SdkPlugin>)>,
+}
+
+impl PluginRegistry {
+    pub(crate) fn new(plugins: Vec<Arc<dyn SdkPlugin>>) -> Result<Self, SdkError> {
+        let mut names = HashSet::new();
+        let plugins = plugins
+            .into_iter()
+            .map(|plugin| {
+                let info = plugin.info();
+                if info.name.trim().is_empty() {
+                    return Err(SdkError::InvalidInput(
+                        "Plugin name can't be empty".to_string(),
+                    ));
+                }
+                if !names.insert(info.name.clone()) {
+                    return Err(SdkError::InvalidInput(format!(
+                        "Plugin {} is registered more than once",
+                        info.name
+                    )));
+                }
+                Ok((info, plugin))
+            })
+            .collect::<Result<_, _>>()?;
+        Ok(Self { plugins })
+    }
+
+    fn get(&self, name: &str) -> Option<&Arc<dyn SdkPlugin>> {
+        self.plugins
+            .iter()
+            .find(|(info, _)| info.name == name)
+            .map(|(_, plugin)| plugin)
+    }
+}
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl BreezSdk {
+    /// Lists the plugins registered with `SdkBuilder::with_plugin`
+    pub fn list_plugins(&self) -> Vec<PluginInfo> {
+        self.plugins
+            .plugins
+            .iter()
+            .map(|(info, _)| info.clone())
+            .collect()
+    }
+}
+
+impl BreezSdk {
+    /// Starts every plugin. A plugin failing to start is logged and doesn't
+    /// fail the connect.
+    pub(super) async fn start_plugins(&self) {
+        for (info, plugin) in &self.plugins.plugins {
+            match plugin.start().await {
+                Ok(()) => debug!("Started plugin {}", info.name),
+                Err(e) => error!("Failed to start plugin {}: {e}", info.name),
+            }
+        }
+    }
+
+    pub(super) async fn stop_plugins(&self) {
+        for (info, plugin) in &self.plugins.plugins {
+            if let Err(e) = plugin.stop().await {
+                warn!("Failed to stop plugin {}: {e}", info.name);
+            }
+        }
+    }
+
+    /// Fetches the balances of the balance provider plugins
+    pub(super) async fn plugin_balances(&self) -> Vec<PluginBalance> {
+        let providers = self
+            .plugins
+            .plugins
+            .iter()
+            .filter(|(info, _)| info.capabilities.balance_provider);
+        join_all(providers.map(|(info, plugin)| async move {
+            let balance_sats = plugin
+                .get_balance()
+                .await
+                .inspect_err(|e| warn!("Failed to get balance of plugin {}: {e}", info.name))
+                .ok();
+            PluginBalance {
+                plugin_name: info.name.clone(),
+                balance_sats,
+            }
+        }))
+        .await
+    }
+
+    /// Asks the payment rail plugins, in registration order, to quote a
+    /// payment to `destination`. Returns the first quote, or `None` if no
+    /// plugin can pay it. A plugin failing to quote is logged and the next
+    /// one is asked. Token payments, conversions and fees included
+    /// payments aren't offered to plugins.
+    pub(super) async fn prepare_plugin_send(
+        &self,
+        destination: &str,
+        request: &PrepareSendPaymentRequest,
+    ) -> Result<Option<PrepareSendPaymentResponse>, SdkError> {
+        if request.token_identifier.is_some()
+            || request.conversion_options.is_some()
+            || request.fee_policy == Some(FeePolicy::FeesIncluded)
+        {
+            return Ok(None);
+        }
+        let amount_sats = request.amount.map(u64::try_from).transpose()?;
+        let rails = self
+            .plugins
+            .plugins
+            .iter()
+            .filter(|(info, _)| info.capabilities.payment_rail);
+        for (info, plugin) in rails {
+            let quote = match plugin
+                .prepare_send(destination.to_string(), amount_sats)
+                .await
+            {
+                Ok(quote) => quote,
+                Err(e) => {
+                    warn!(
+                        "Plugin {} failed to quote the payment to {destination}: {e}",
+                        info.name
+                    );
+                    continue;
+                }
+            };
+            if let Some(quote) = quote {
+                debug!("Plugin {} quoted the payment to {destination}", info.name);
+                return Ok(Some(PrepareSendPaymentResponse {
+                    amount: u128::from(quote.amount_sats),
+                    payment_method: SendPaymentMethod::Plugin {
+                        plugin_name: info.name.clone(),
+                        destination: destination.to_string(),
+                        quote,
+                    },
+                    token_identifier: None,
+                    conversion_estimate: None,
+                    fee_policy: FeePolicy::FeesExcluded,
+                    duplicate_payment: None,
+                }));
+            }
+        }
+        Ok(None)
+    }
+
+    /// Sends a payment quoted by a payment rail plugin and stores it with the
+    /// status the plugin reported, so that it is listed with the other
+    /// payments. Its events are emitted by the send flow.
+    pub(super) async fn send_plugin_payment(
+        &self,
+        plugin_name: &str,
+        quote: &PluginSendQuote,
+    ) -> Result<SendPaymentResponse, SdkError> {
+        let plugin = self.plugins.get(plugin_name).ok_or_else(|| {
+            SdkError::InvalidInput(format!("Plugin {plugin_name} is not registered"))
+        })?;
+        let result = plugin.send(quote.clone()).await?;
+        let payment = Payment {
+            id: result.payment_id,
+            payment_type: PaymentType::Send,
+            status: result.status,
+            amount: u128::from(quote.amount_sats),
+            fees: u128::from(quote.fee_sats),
+            timestamp: self.now()?,
+            method: PaymentMethod::Plugin,
+            details: None,
+            conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
+        };
+        // The payment was sent: failing to store it must not report a failure
+        if let Err(e) = self.storage.apply_payment_update(payment.clone()).await {
+            error!("Failed to store plugin payment {}: {e:?}", payment.id);
+        }
+        Ok(SendPaymentResponse { payment })
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+    use crate::{PluginCapabilities, PluginError, PluginSendResult};
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    struct NamedPlugin(&'static str);
+
+    #[macros::async_trait]
+    impl SdkPlugin for NamedPlugin {
+        fn info(&self) -> PluginInfo {
+            PluginInfo {
+                name: self.0.to_string(),
+                capabilities: PluginCapabilities::default(),
+            }
+        }
+        async fn start(&self) -> Result<(), PluginError> {
+            Ok(())
+        }
+        async fn stop(&self) -> Result<(), PluginError> {
+            Ok(())
+        }
+        async fn get_balance(&self) -> Result<u64, PluginError> {
+            Ok(0)
+        }
+        async fn prepare_send(
+            &self,
+            _destination: String,
+            _amount_sats: Option<u64>,
+        ) -> Result<Option<PluginSendQuote>, PluginError> {
+            Ok(None)
+        }
+        async fn send(&self, _quote: PluginSendQuote) -> Result<PluginSendResult, PluginError> {
+            Err(PluginError::Generic("Not supported".to_string()))
+        }
+    }
+
+    #[test_all]
+    fn test_registry_rejects_duplicate_names() {
+        let result = PluginRegistry::new(vec![
+            Arc::new(NamedPlugin("fedimint")),
+            Arc::new(NamedPlugin("fedimint")),
+        ]);
+        assert!(matches!(result, Err(SdkError::InvalidInput(_))));
+    }
+
+    #[test_all]
+    fn test_registry_rejects_empty_name() {
+        let result = PluginRegistry::new(vec![Arc::new(NamedPlugin(" "))]);
+        assert!(matches!(result, Err(SdkError::InvalidInput(_))));
+    }
+
+    #[test_all]
+    fn test_registry_finds_plugin_by_name() {
+        let registry = PluginRegistry::new(vec![
+            Arc::new(NamedPlugin("fedimint")),
+            Arc::new(NamedPlugin("exchange")),
+        ])
+        .unwrap();
+        assert!(registry.get("exchange").is_some());
+        assert!(registry.get("cashu").is_none());
+    }
+}
@@ -0,0 +1,156 @@
+use std::sync::Arc;
+
+use platform_utils::tokio;
+use spark_wallet::WalletEvent;
+use tokio::{
+    select,
+    sync::{broadcast, watch},
+};
+use tracing::{Instrument, error, info, trace};
+
+use crate::{
+    EventEmitter, GetInfoRequest, GetInfoResponse, Storage, error::SdkError,
+    utils::payments::get_payment_and_emit_event,
+};
+
+use super::{RuntimeEvent, RuntimeProfile, client::handle_wallet_event, server::ServerRuntime};
+use crate::sdk::{BreezSdk, SyncType};
+
+/// Runs only what receiving needs: the wallet's event stream, which claims
+/// incoming transfers, and the claiming of deposits when the wallet syncs.
+///
+/// Payment history is not paged through, nothing is synced periodically and
+/// no other background service is started, keeping memory and time within
+/// the limits of notification extensions and serverless hosts.
+pub(super) struct ClaimingRuntime;
+
+#[macros::async_trait]
+impl RuntimeProfile for ClaimingRuntime {
+    fn starts_background_services(&self) -> bool {
+        true
+    }
+
+    async fn start_sdk_services(&self, sdk: &BreezSdk, initial_synced_sender: watch::Sender<bool>) {
+        sdk.event_emitter
+            .add_runtime_event_handler(Box::new(ClaimingRuntimeEventHandler {
+                storage: sdk.storage.clone(),
+            }))
+            .await;
+        spawn_claiming_loop(sdk, initial_synced_sender);
+        sdk.spark_wallet.start_background_processing().await;
+    }
+
+    async fn run_user_sync(
+        &self,
+        sdk: &BreezSdk,
+        sync_type: SyncType,
+        force: bool,
+    ) -> Result<(), SdkError> {
+        sdk.sync_wallet_internal(sync_type, force).await
+    }
+
+    async fn get_info(
+        &self,
+        sdk: &BreezSdk,
+        request: GetInfoRequest,
+    ) -> Result<GetInfoResponse, SdkError> {
+        if request.ensure_synced.unwrap_or_default() {
+            sdk.initial_synced_watcher
+                .clone()
+                .wait_for(|synced| *synced)
+                .await
+                .map_err(|_| {
+                    SdkError::Generic("Failed to receive initial synced signal".to_string())
+                })?;
+        }
+        // Balances aren't cached without the wallet state sync, read them live
+        ServerRuntime
+            .get_info(
+                sdk,
+                GetInfoRequest {
+                    ensure_synced: None,
+                    include_hidden: None,
+                },
+            )
+            .await
+    }
+
+    async fn maybe_ensure_spark_private_mode_initialized(
+        &self,
+        _sdk: &BreezSdk,
+    ) -> Result<(), SdkError> {
+        Ok(())
+    }
+}
+
+fn spawn_claiming_loop(sdk: &BreezSdk, initial_synced_sender: watch::Sender<bool>) {
+    let sdk = sdk.clone();
+    let mut shutdown_receiver = sdk.shutdown_sender.subscribe();
+    let mut wallet_events = sdk.spark_wallet.subscribe_events();
+    let span = tracing::Span::current();
+
+    tokio::spawn(
+        async move {
+            loop {
+                select! {
+                    _ = shutdown_receiver.changed() => {
+                        info!("Claiming runtime loop shutdown signal received");
+                        return;
+                    }
+
+                    event = wallet_events.recv() => {
+                        Box::pin(on_wallet_event(&sdk, event, &initial_synced_sender)).await;
+                    }
+                }
+            }
+        }
+        .instrument(span),
+    );
+}
+
+async fn on_wallet_event(
+    sdk: &BreezSdk,
+    event: Result<WalletEvent, broadcast::error::RecvError>,
+    initial_synced_sender: &watch::Sender<bool>,
+) {
+    let event = match event {
+        Ok(event) => event,
+        Err(e) => {
+            error!("Failed to receive event: {e:?}");
+            return;
+        }
+    };
+    trace!("Received event: {:?}", event);
+    let wallet_synced = matches!(&event, WalletEvent::Synced);
+    Box::pin(handle_wallet_event(sdk, event)).await;
+
+    if wallet_synced {
+        if let Err(e) = sdk.sync_wallet_internal(SyncType::Deposits, true).await {
+            error!("Failed to claim deposits: {e:?}");
+        }
+        if let Err(e) = initial_synced_sender.send(true) {
+            error!("Failed to send initial synced signal: {e:?}");
+        }
+    }
+}
+
+struct ClaimingRuntimeEventHandler {
+    storage: Arc<dyn Storage>,
+}
+
+#[macros::async_trait]
+impl crate::events::RuntimeEventHandler for ClaimingRuntimeEventHandler {
+    async fn handle(&self, emitter: &EventEmitter, event: RuntimeEvent) {
+        match event {
+            RuntimeEvent::DepositClaimed {
+                payment,
+                should_emit_event,
+            } => {
+                if should_emit_event {
+                    get_payment_and_emit_event(&self.storage, emitter, *payment).await;
+                }
+            }
+            RuntimeEvent::StableBalanceConversionCompleted => {}
+        }
+    }
+}
@@ -24,7 +24,9 @@ use crate::{
 use crate::{PaymentType, StorageListPaymentsRequest, StoragePaymentDetailsFilter};
 
 use super::{RuntimeEvent, RuntimeProfile};
-use crate::sdk::{BreezSdk, SyncCoordinator, SyncRequest, SyncType, helpers::BalanceWatcher};
+use crate::sdk::{
+    BreezSdk, SyncCoordinator, SyncRequest, SyncType, helpers::BalanceWatcher, leaves,
+};
 
 pub(super) struct ClientRuntime;
 
@@ -90,6 +92,11 @@ impl RuntimeProfile for ClientRuntime {
             identity_pubkey: sdk.spark_wallet.get_identity_public_key().to_string(),
             balance_sats: account_info.balance_sats,
             token_balances: account_info.token_balances,
+            hidden_tokens: Vec::new(),
+            leaf_stats: leaves::leaf_stats(sdk).await?,
+            claim_queue_depth: sdk.spark_wallet.claim_queue_depth().await,
+            wallet_frozen: sdk.is_wallet_frozen().await?,
+            plugin_balances: sdk.plugin_balances().await,
         })
     }
 
@@ -144,6 +151,9 @@ fn spawn_client_runtime_loop(sdk: &BreezSdk, initial_synced_sender: watch::Sende
                     }
 
                     () = tokio::time::sleep(Duration::from_secs(10)) => {
+                        if sdk.is_background_sync_paused() {
+                            continue;
+                        }
                         let now = SystemTime::now();
                         if let Ok(elapsed) = now.duration_since(last_sync_time) && elapsed.as_secs() >= sync_interval {
                             sdk.sync_coordinator.trigger_sync_no_wait(SyncType::Full, false).await;
@@ -168,7 +178,9 @@ async fn on_wallet_event(sdk: &BreezSdk, event: Result<WalletEvent, broadcast::e
             );
             let payment_event_emitted = Box::pin(handle_wallet_event(sdk, event)).await;
 
-            if wallet_synced {
+            // The Spark wallet syncs on its own, e.g. on reconnect. The full
+            // sync following it is skipped while the background sync is paused.
+            if wallet_synced && !sdk.is_background_sync_paused() {
                 sdk.sync_coordinator
                     .trigger_sync_no_wait(SyncType::Full, true)
                     .await;
@@ -260,7 +272,7 @@ async fn on_sync_request(
     )
 }
 
-async fn handle_wallet_event(sdk: &BreezSdk, event: WalletEvent) -> bool {
+pub(super) async fn handle_wallet_event(sdk: &BreezSdk, event: WalletEvent) -> bool {
     match event {
         WalletEvent::DepositConfirmed(_) => {
             info!("Deposit confirmed");
@@ -336,6 +348,13 @@ async fn handle_wallet_event(sdk: &BreezSdk, event: WalletEvent) -> bool {
                 .await;
             false
         }
+        WalletEvent::ClaimQueueChanged(queue_depth) => {
+            info!("Claim queue depth: {queue_depth}");
+            sdk.event_emitter
+                .emit(&SdkEvent::ClaimQueueChanged { queue_depth })
+                .await;
+            false
+        }
     }
 }
 
@@ -6,9 +6,11 @@ use crate::{GetInfoRequest, GetInfoResponse, error::SdkError, models::Config};
 
 use super::{BreezSdk, SyncType};
 
+mod claiming;
 mod client;
 mod server;
 
+use claiming::ClaimingRuntime;
 use client::ClientRuntime;
 use server::ServerRuntime;
 
@@ -35,6 +37,12 @@ pub(crate) fn runtime_from_config(config: &Config) -> SdkRuntime {
     }
 }
 
+/// The runtime of an SDK connected with `connect_for_claiming`, which only
+/// claims incoming payments and deposits.
+pub(crate) fn claiming_runtime() -> SdkRuntime {
+    Arc::new(ClaimingRuntime)
+}
+
 #[macros::async_trait]
 pub(crate) trait RuntimeProfile: Send + Sync {
     fn starts_background_services(&self) -> bool;
@@ -64,7 +72,7 @@ pub(crate) trait RuntimeProfile: Send + Sync {
 mod tests {
     use crate::{Network, default_config, default_server_config};
 
-    use super::runtime_from_config;
+    use super::{claiming_runtime, runtime_from_config};
 
     #[test]
     fn runtime_from_default_config_starts_background_services() {
@@ -80,4 +88,10 @@ mod tests {
 
         assert!(!runtime.starts_background_services());
     }
+
+    #[test]
+    fn claiming_runtime_starts_background_services() {
+        // The wallet's event stream is what claims incoming transfers
+        assert!(claiming_runtime().starts_background_services());
+    }
 }
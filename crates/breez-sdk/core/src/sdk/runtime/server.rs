@@ -6,7 +6,7 @@ use tracing::error;
 use crate::{EventEmitter, GetInfoRequest, GetInfoResponse, Storage, error::SdkError};
 
 use super::{RuntimeEvent, RuntimeProfile};
-use crate::sdk::{BreezSdk, SyncType};
+use crate::sdk::{BreezSdk, SyncType, leaves};
 use crate::utils::payments::get_payment_and_emit_event;
 
 pub(super) struct ServerRuntime;
@@ -63,6 +63,11 @@ impl RuntimeProfile for ServerRuntime {
             identity_pubkey: sdk.spark_wallet.get_identity_public_key().to_string(),
             balance_sats,
             token_balances,
+            hidden_tokens: Vec::new(),
+            leaf_stats: leaves::leaf_stats(sdk).await?,
+            claim_queue_depth: sdk.spark_wallet.claim_queue_depth().await,
+            wallet_frozen: sdk.is_wallet_frozen().await?,
+            plugin_balances: sdk.plugin_balances().await,
         })
     }
 
@@ -0,0 +1,257 @@
+use std::sync::Mutex;
+
+use bitcoin::hashes::{Hash, sha256};
+use bitcoin::secp256k1::rand::{RngCore, seq::index::sample, thread_rng};
+use tracing::{info, warn};
+
+use crate::{
+    GetSeedBackupChallengeRequest, GetSeedBackupChallengeResponse, SeedBackupWord,
+    SignerSelfTestResponse, VerifySeedBackupRequest, VerifySeedBackupResponse, error::SdkError,
+    persist::ObjectCacheRepository,
+};
+
+use super::BreezSdk;
+
+const DEFAULT_CHALLENGE_WORD_COUNT: u32 = 3;
+
+/// Serializes verifications, so concurrent attempts can't each read the
+/// failed attempts before the others record theirs.
+static VERIFY_LOCK: tokio::sync::Mutex<()> = tokio::sync::Mutex::const_new(());
+
+/// Salted digests of the words of the mnemonic the SDK was built with, so that
+/// a backup can be checked without keeping the words themselves.
+pub(crate) struct SeedBackup {
+    salt: [u8; 16],
+    word_digests: Vec<sha256::Hash>,
+    /// The positions handed out by the last challenge, until it is used
+    challenge: Mutex<Option<Vec<u32>>>,
+}
+
+impl SeedBackup {
+    pub(crate) fn from_mnemonic(mnemonic: &str) -> Result<Self, SdkError> {
+        let mnemonic = bip39::Mnemonic::parse(mnemonic)
+            .map_err(|e| SdkError::InvalidInput(format!("Invalid mnemonic: {e}")))?;
+        let mut salt = [0u8; 16];
+        thread_rng().fill_bytes(&mut salt);
+        let word_digests = mnemonic
+            .words()
+            .zip(1u32..)
+            .map(|(word, position)| word_digest(&salt, position, word))
+            .collect();
+        Ok(Self {
+            salt,
+            word_digests,
+            challenge: Mutex::new(None),
+        })
+    }
+
+    fn word_count(&self) -> u32 {
+        u32::try_from(self.word_digests.len()).unwrap_or(u32::MAX)
+    }
+
+    /// Whether `word` is the word at the 1-based `position`.
+    fn matches(&self, position: u32, word: &str) -> bool {
+        let Some(index) = position.checked_sub(1) else {
+            return false;
+        };
+        self.word_digests
+            .get(index as usize)
+            .is_some_and(|digest| *digest == word_digest(&self.salt, position, word))
+    }
+
+    fn set_challenge(&self, positions: Vec<u32>) {
+        if let Ok(mut challenge) = self.challenge.lock() {
+            *challenge = Some(positions);
+        }
+    }
+
+    fn take_challenge(&self) -> Option<Vec<u32>> {
+        self.challenge.lock().ok()?.take()
+    }
+
+    /// Whether `words` holds every position of `challenge` exactly once, and
+    /// each word matches. Every word is checked, so the time taken doesn't
+    /// tell which one was wrong.
+    fn verify(&self, challenge: &[u32], words: &[SeedBackupWord]) -> bool {
+        let mut positions: Vec<u32> = words.iter().map(|w| w.position).collect();
+        positions.sort_unstable();
+        let answers_challenge = positions == challenge;
+        words.iter().fold(answers_challenge, |verified, w| {
+            self.matches(w.position, &w.word) & verified
+        })
+    }
+}
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Picks the positions of the mnemonic words to ask the user for, to
+    /// check their backup with [`BreezSdk::verify_seed_backup`]. Replaces any
+    /// earlier challenge.
+    pub async fn get_seed_backup_challenge(
+        &self,
+        request: GetSeedBackupChallengeRequest,
+    ) -> Result<GetSeedBackupChallengeResponse, SdkError> {
+        self.ensure_not_frozen().await?;
+        let seed_backup = self.loaded_seed_backup()?;
+        let word_count = request.word_count.unwrap_or(DEFAULT_CHALLENGE_WORD_COUNT);
+        if word_count == 0 || word_count > seed_backup.word_count() {
+            return Err(SdkError::InvalidInput(format!(
+                "Word count must be between 1 and {}",
+                seed_backup.word_count()
+            )));
+        }
+
+        let mut positions: Vec<u32> = sample(
+            &mut thread_rng(),
+            seed_backup.word_count() as usize,
+            word_count as usize,
+        )
+        .into_iter()
+        .filter_map(|index| u32::try_from(index).ok())
+        .map(|index| index.saturating_add(1))
+        .collect();
+        positions.sort_unstable();
+        seed_backup.set_challenge(positions.clone());
+        Ok(GetSeedBackupChallengeResponse {
+            positions,
+            seed_word_count: seed_backup.word_count(),
+        })
+    }
+
+    /// Checks the words asked for by the last
+    /// [`BreezSdk::get_seed_backup_challenge`] against the mnemonic the SDK was
+    /// built with. The mnemonic itself is never returned, and only whether
+    /// all the words match is reported.
+    ///
+    /// Each challenge can be answered once, whether the answer is right or
+    /// not. Wrong answers are counted across restarts, and after a few of
+    /// them each further attempt has to wait, doubling up to a day, and fails
+    /// with [`SdkError::TooManyAttempts`] until then.
+    pub async fn verify_seed_backup(
+        &self,
+        request: VerifySeedBackupRequest,
+    ) -> Result<VerifySeedBackupResponse, SdkError> {
+        self.ensure_not_frozen().await?;
+        let seed_backup = self.loaded_seed_backup()?;
+        let _guard = VERIFY_LOCK.lock().await;
+        let cache = ObjectCacheRepository::new(self.storage.clone());
+        let mut attempts = cache.fetch_seed_backup_attempts().await?;
+        let now = self.now()?;
+        attempts.ensure_can_attempt(now)?;
+        let Some(challenge) = seed_backup.take_challenge() else {
+            return Err(SdkError::InvalidInput(
+                "No seed backup challenge, call get_seed_backup_challenge first".to_string(),
+            ));
+        };
+
+        let verified = seed_backup.verify(&challenge, &request.words);
+        if verified {
+            info!("Seed backup verified");
+            cache.delete_seed_backup_attempts().await?;
+        } else {
+            warn!("Seed backup verification failed");
+            attempts.record_failure(now);
+            cache.save_seed_backup_attempts(&attempts).await?;
+        }
+        Ok(VerifySeedBackupResponse { verified })
+    }
+
+    /// Signs a random message with the identity key and verifies the
+    /// signature, to check that the signer works.
+    pub async fn run_signer_self_test(&self) -> Result<SignerSelfTestResponse, SdkError> {
+        let mut nonce = [0u8; 32];
+        thread_rng().fill_bytes(&mut nonce);
+        let message = format!("breez-signer-self-test-{}", hex::encode(nonce));
+        let pubkey = self.spark_wallet.get_identity_public_key();
+
+        let result = match self.spark_wallet.sign_message(&message).await {
+            Ok(signature) => self
+                .spark_wallet
+                .verify_message(&message, &signature, &pubkey)
+                .await
+                .map_err(|e| format!("Signature verification failed: {e}")),
+            Err(e) => Err(format!("Signing failed: {e}")),
+        };
+        if let Err(e) = &result {
+            warn!("Signer self-test failed: {e}");
+        }
+        Ok(SignerSelfTestResponse {
+            passed: result.is_ok(),
+            error: result.err(),
+        })
+    }
+}
+
+impl BreezSdk {
+    fn loaded_seed_backup(&self) -> Result<&SeedBackup, SdkError> {
+        self.seed_backup.as_deref().ok_or(SdkError::InvalidInput(
+            "Seed backup verification requires the SDK to be built from a mnemonic".to_string(),
+        ))
+    }
+}
+
+fn word_digest(salt: &[u8], position: u32, word: &str) -> sha256::Hash {
+    let mut data = salt.to_vec();
+    data.extend_from_slice(&position.to_be_bytes());
+    data.extend_from_slice(word.trim().to_lowercase().as_bytes());
+    sha256::Hash::hash(&data)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    const MNEMONIC: &str = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about";
+
+    #[test_all]
+    fn test_seed_backup_matches_words() {
+        let seed_backup = SeedBackup::from_mnemonic(MNEMONIC).unwrap();
+        assert_eq!(seed_backup.word_count(), 12);
+        assert!(seed_backup.matches(1, "abandon"));
+        assert!(seed_backup.matches(12, " About "));
+        assert!(!seed_backup.matches(12, "abandon"));
+        assert!(!seed_backup.matches(0, "abandon"));
+        assert!(!seed_backup.matches(13, "abandon"));
+    }
+
+    fn words(words: &[(u32, &str)]) -> Vec<SeedBackupWord> {
+        words
+            .iter()
+            .map(|(position, word)| SeedBackupWord {
+                position: *position,
+                word: (*word).to_string(),
+            })
+            .collect()
+    }
+
+    #[test_all]
+    fn test_seed_backup_verifies_only_the_challenge() {
+        let seed_backup = SeedBackup::from_mnemonic(MNEMONIC).unwrap();
+        let challenge = [2, 12];
+        assert!(seed_backup.verify(&challenge, &words(&[(12, "about"), (2, "abandon")])));
+        assert!(!seed_backup.verify(&challenge, &words(&[(2, "abandon"), (12, "abandon")])));
+        // Correct words at other positions, or missing or repeated ones,
+        // don't answer the challenge
+        assert!(!seed_backup.verify(&challenge, &words(&[(1, "abandon"), (12, "about")])));
+        assert!(!seed_backup.verify(&challenge, &words(&[(2, "abandon")])));
+        assert!(!seed_backup.verify(
+            &challenge,
+            &words(&[(2, "abandon"), (2, "abandon"), (12, "about")])
+        ));
+    }
+
+    #[test_all]
+    fn test_seed_backup_challenge_is_used_once() {
+        let seed_backup = SeedBackup::from_mnemonic(MNEMONIC).unwrap();
+        assert_eq!(seed_backup.take_challenge(), None);
+        seed_backup.set_challenge(vec![1, 2]);
+        seed_backup.set_challenge(vec![3, 4]);
+        assert_eq!(seed_backup.take_challenge(), Some(vec![3, 4]));
+        assert_eq!(seed_backup.take_challenge(), None);
+    }
+}
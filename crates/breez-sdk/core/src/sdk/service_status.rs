@@ -0,0 +1,409 @@
+use chrono::DateTime;
+use platform_utils::{DefaultHttpClient, HttpClient, tokio};
+use tracing::{Instrument, debug, info, warn};
+
+use crate::{
+    MaintenanceWindow, PaymentRail, RailStatus, ServiceStatus, ServiceStatusReport,
+    error::SdkError, events::SdkEvent,
+};
+
+use super::BreezSdk;
+
+const STATUS_URL: &str = "https://spark.money/api/v1/status";
+/// How often the status is refreshed after a wallet sync
+const REFRESH_INTERVAL_SECS: u64 = 300;
+
+/// Names of the services in the status feed
+pub(super) const OPERATORS: &str = "Spark Operators";
+pub(super) const SSP: &str = "SSP";
+
+/// The services each rail depends on. Lightning payments and on-chain
+/// deposits and withdrawals go through the SSP, Spark transfers only through
+/// the operators.
+const RAIL_SERVICES: [(PaymentRail, &[&str]); 3] = [
+    (PaymentRail::Lightning, &[OPERATORS, SSP]),
+    (PaymentRail::Bitcoin, &[OPERATORS, SSP]),
+    (PaymentRail::Spark, &[OPERATORS]),
+];
+
+/// The last status fetched, shared by `get_service_status` and the refresh
+/// after each sync
+#[derive(Default)]
+pub(crate) struct ServiceStatusCache {
+    last_refresh: u64,
+    report: Option<ServiceStatusReport>,
+}
+
+/// The services published by the Spark status API
+pub(super) struct StatusFeed {
+    services: Vec<(String, ServiceStatus)>,
+    maintenances: Vec<FeedMaintenance>,
+    pub(super) last_updated: u64,
+}
+
+/// Planned maintenance published by the Spark status API. Without named
+/// services, the maintenance affects all of them.
+struct FeedMaintenance {
+    title: String,
+    services: Vec<String>,
+    starts_at: u64,
+    ends_at: Option<u64>,
+}
+
+impl FeedMaintenance {
+    fn rails(&self) -> Vec<PaymentRail> {
+        RAIL_SERVICES
+            .iter()
+            .filter(|(_, names)| {
+                self.services.is_empty()
+                    || names
+                        .iter()
+                        .any(|name| self.services.iter().any(|s| s == name))
+            })
+            .map(|(rail, _)| *rail)
+            .collect()
+    }
+}
+
+impl StatusFeed {
+    /// The worst status across the named services, `Unknown` if none of them
+    /// is in the feed
+    pub(super) fn worst_status(&self, names: &[&str]) -> ServiceStatus {
+        self.services
+            .iter()
+            .filter(|(name, _)| names.contains(&name.as_str()))
+            .map(|(_, status)| *status)
+            .max()
+            .unwrap_or(ServiceStatus::Unknown)
+    }
+
+    /// The report as of `now`. Maintenance that has ended is left out.
+    fn report(&self, now: u64) -> ServiceStatusReport {
+        let rails: Vec<RailStatus> = RAIL_SERVICES
+            .iter()
+            .map(|(rail, names)| RailStatus {
+                rail: *rail,
+                status: self.worst_status(names),
+            })
+            .collect();
+        let status = rails
+            .iter()
+            .map(|rail| rail.status)
+            .max()
+            .unwrap_or(ServiceStatus::Unknown);
+        let mut maintenance_windows: Vec<MaintenanceWindow> = self
+            .maintenances
+            .iter()
+            .filter(|m| m.ends_at.is_none_or(|ends_at| ends_at > now))
+            .map(|m| MaintenanceWindow {
+                title: m.title.clone(),
+                rails: m.rails(),
+                starts_at: m.starts_at,
+                ends_at: m.ends_at,
+            })
+            .filter(|w| !w.rails.is_empty())
+            .collect();
+        maintenance_windows.sort_by_key(|w| w.starts_at);
+        ServiceStatusReport {
+            status,
+            rails,
+            maintenance_windows,
+            last_updated: self.last_updated,
+        }
+    }
+}
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl BreezSdk {
+    /// Fetches the status of the Spark network, overall and for each payment
+    /// rail.
+    ///
+    /// Apps can use it to tell users a rail is degraded before they pay,
+    /// instead of surfacing the errors of failed payments. While background
+    /// tasks are enabled the status is also refreshed periodically, and
+    /// changes are reported by [`SdkEvent::ServiceStatusChanged`] events.
+    /// Ongoing and upcoming planned maintenance is reported with the rails it
+    /// affects.
+    pub async fn get_service_status(&self) -> Result<ServiceStatusReport, SdkError> {
+        let report = fetch_status_feed().await?.report(self.now()?);
+        self.update_service_status(report.clone()).await;
+        Ok(report)
+    }
+}
+
+impl BreezSdk {
+    /// Caches the report and emits it if the status changed
+    async fn update_service_status(&self, report: ServiceStatusReport) {
+        let changed = {
+            let mut cache = self.service_status.lock().await;
+            cache.last_refresh = self.now().unwrap_or(cache.last_refresh);
+            let changed = status_changed(cache.report.as_ref(), &report);
+            cache.report = Some(report.clone());
+            changed
+        };
+        if changed {
+            info!("Service status changed to {:?}", report.status);
+            self.event_emitter
+                .emit(&SdkEvent::ServiceStatusChanged { status: report })
+                .await;
+        }
+    }
+}
+
+/// Refreshes the status in the background if it wasn't refreshed in the last
+/// [`REFRESH_INTERVAL_SECS`]. Called after each wallet sync.
+pub(super) async fn maybe_refresh_service_status(sdk: &BreezSdk) {
+    if !sdk.config.background_tasks_enabled {
+        return;
+    }
+    let Ok(now) = sdk.now() else {
+        return;
+    };
+    {
+        let mut cache = sdk.service_status.lock().await;
+        if cache.report.is_some() && now < cache.last_refresh.saturating_add(REFRESH_INTERVAL_SECS)
+        {
+            return;
+        }
+        // Set before fetching, so that syncs during the fetch don't start another
+        cache.last_refresh = now;
+    }
+
+    let task_sdk = sdk.clone();
+    let span = tracing::Span::current();
+    tokio::spawn(
+        async move {
+            match fetch_status_feed().await {
+                Ok(feed) => task_sdk.update_service_status(feed.report(now)).await,
+                Err(e) => warn!("Failed to refresh service status: {e:?}"),
+            }
+        }
+        .instrument(span),
+    );
+}
+
+/// Whether a report differs from the previous one, in the status of a rail or
+/// the planned maintenance. The first report counts as a change only when
+/// something isn't operational or maintenance is planned.
+fn status_changed(previous: Option<&ServiceStatusReport>, report: &ServiceStatusReport) -> bool {
+    match previous {
+        Some(previous) => {
+            previous.rails != report.rails
+                || previous.maintenance_windows != report.maintenance_windows
+        }
+        None => {
+            report.status != ServiceStatus::Operational || !report.maintenance_windows.is_empty()
+        }
+    }
+}
+
+pub(super) async fn fetch_status_feed() -> Result<StatusFeed, SdkError> {
+    #[derive(serde::Deserialize)]
+    struct StatusApiResponse {
+        services: Vec<StatusApiService>,
+        #[serde(default)]
+        maintenances: Vec<StatusApiMaintenance>,
+        #[serde(rename = "lastUpdated")]
+        last_updated: String,
+    }
+
+    #[derive(serde::Deserialize)]
+    struct StatusApiService {
+        name: String,
+        status: String,
+    }
+
+    #[derive(serde::Deserialize)]
+    struct StatusApiMaintenance {
+        title: String,
+        #[serde(default)]
+        services: Vec<String>,
+        #[serde(rename = "startsAt")]
+        starts_at: String,
+        #[serde(rename = "endsAt")]
+        ends_at: Option<String>,
+    }
+
+    let http_client = DefaultHttpClient::default();
+
+    let response = http_client
+        .get(STATUS_URL.to_string(), None)
+        .await
+        .map_err(|e| SdkError::NetworkError(e.to_string()))?;
+
+    let api_response: StatusApiResponse = response
+        .json()
+        .map_err(|e| SdkError::Generic(format!("Failed to parse status response: {e}")))?;
+
+    let last_updated = parse_timestamp(&api_response.last_updated)
+        .map_err(|e| SdkError::Generic(format!("Failed to parse lastUpdated timestamp: {e}")))?;
+    debug!("Fetched service status updated at {last_updated}");
+
+    // A maintenance with unparsable times is skipped rather than failing the
+    // whole status
+    let maintenances = api_response
+        .maintenances
+        .into_iter()
+        .filter_map(|m| {
+            let starts_at = parse_timestamp(&m.starts_at);
+            let ends_at = m.ends_at.as_deref().map(parse_timestamp).transpose();
+            match (starts_at, ends_at) {
+                (Ok(starts_at), Ok(ends_at)) => Some(FeedMaintenance {
+                    title: m.title,
+                    services: m.services,
+                    starts_at,
+                    ends_at,
+                }),
+                (Err(e), _) | (_, Err(e)) => {
+                    warn!("Skipping maintenance {} with invalid times: {e}", m.title);
+                    None
+                }
+            }
+        })
+        .collect();
+
+    Ok(StatusFeed {
+        services: api_response
+            .services
+            .into_iter()
+            .map(|s| {
+                let status = parse_service_status(&s.status);
+                (s.name, status)
+            })
+            .collect(),
+        maintenances,
+        last_updated,
+    })
+}
+
+fn parse_timestamp(s: &str) -> Result<u64, chrono::ParseError> {
+    DateTime::parse_from_rfc3339(s).map(|dt| dt.timestamp().cast_unsigned())
+}
+
+fn parse_service_status(s: &str) -> ServiceStatus {
+    match s {
+        "operational" => ServiceStatus::Operational,
+        "degraded" => ServiceStatus::Degraded,
+        "partial" => ServiceStatus::Partial,
+        "major" => ServiceStatus::Major,
+        _ => {
+            warn!("Unknown service status: {s}");
+            ServiceStatus::Unknown
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    const NOW: u64 = 1_700_000_000;
+
+    fn feed(operators: ServiceStatus, ssp: ServiceStatus) -> StatusFeed {
+        StatusFeed {
+            services: vec![
+                (OPERATORS.to_string(), operators),
+                (SSP.to_string(), ssp),
+                ("Website".to_string(), ServiceStatus::Major),
+            ],
+            maintenances: vec![],
+            last_updated: 1_700_000_000,
+        }
+    }
+
+    fn rail_status(report: &ServiceStatusReport, rail: PaymentRail) -> ServiceStatus {
+        report
+            .rails
+            .iter()
+            .find(|r| r.rail == rail)
+            .map(|r| r.status)
+            .unwrap()
+    }
+
+    #[test_all]
+    fn test_degraded_ssp_affects_lightning_and_bitcoin_only() {
+        let report = feed(ServiceStatus::Operational, ServiceStatus::Degraded).report(NOW);
+        assert_eq!(report.status, ServiceStatus::Degraded);
+        assert_eq!(
+            rail_status(&report, PaymentRail::Lightning),
+            ServiceStatus::Degraded
+        );
+        assert_eq!(
+            rail_status(&report, PaymentRail::Bitcoin),
+            ServiceStatus::Degraded
+        );
+        assert_eq!(
+            rail_status(&report, PaymentRail::Spark),
+            ServiceStatus::Operational
+        );
+    }
+
+    #[test_all]
+    fn test_missing_services_are_unknown() {
+        let feed = StatusFeed {
+            services: vec![],
+            maintenances: vec![],
+            last_updated: 0,
+        };
+        assert_eq!(feed.worst_status(&[OPERATORS]), ServiceStatus::Unknown);
+    }
+
+    #[test_all]
+    fn test_status_changed() {
+        let operational = feed(ServiceStatus::Operational, ServiceStatus::Operational).report(NOW);
+        let degraded = feed(ServiceStatus::Operational, ServiceStatus::Degraded).report(NOW);
+
+        assert!(!status_changed(None, &operational));
+        assert!(status_changed(None, &degraded));
+        assert!(status_changed(Some(&operational), &degraded));
+        assert!(!status_changed(Some(&degraded), &degraded));
+    }
+
+    fn maintenance(services: &[&str], starts_at: u64, ends_at: Option<u64>) -> FeedMaintenance {
+        FeedMaintenance {
+            title: "Upgrade".to_string(),
+            services: services.iter().map(ToString::to_string).collect(),
+            starts_at,
+            ends_at,
+        }
+    }
+
+    #[allow(clippy::arithmetic_side_effects)]
+    #[test_all]
+    fn test_maintenance_windows() {
+        let mut feed = feed(ServiceStatus::Operational, ServiceStatus::Operational);
+        feed.maintenances = vec![
+            maintenance(&[SSP], NOW + 3_600, Some(NOW + 7_200)),
+            maintenance(&[], NOW - 3_600, None),
+            maintenance(&[OPERATORS], NOW - 7_200, Some(NOW - 3_600)),
+            maintenance(&["Website"], NOW + 3_600, None),
+        ];
+        let report = feed.report(NOW);
+        assert_eq!(
+            report.maintenance_windows,
+            vec![
+                MaintenanceWindow {
+                    title: "Upgrade".to_string(),
+                    rails: vec![
+                        PaymentRail::Lightning,
+                        PaymentRail::Bitcoin,
+                        PaymentRail::Spark
+                    ],
+                    starts_at: NOW - 3_600,
+                    ends_at: None,
+                },
+                MaintenanceWindow {
+                    title: "Upgrade".to_string(),
+                    rails: vec![PaymentRail::Lightning, PaymentRail::Bitcoin],
+                    starts_at: NOW + 3_600,
+                    ends_at: Some(NOW + 7_200),
+                },
+            ]
+        );
+        assert!(status_changed(None, &report));
+    }
+}
@@ -0,0 +1,218 @@
+use base64::{Engine, engine::general_purpose::STANDARD as BASE64};
+use bitcoin::bip32::DerivationPath;
+use serde::{Deserialize, Serialize};
+use tracing::info;
+
+use crate::{
+    BackupStateRequest, BackupStateResponse, Contact, DisplayCurrency, ListContactsRequest,
+    RestoreStateRequest, RestoreStateResponse, StableBalanceActiveLabel, UpdateUserSettingsRequest,
+    error::SdkError, signer::EciesSigner, utils::contact_merge::save_contact_merging_duplicates,
+};
+
+use super::BreezSdk;
+
+/// Hardened derivation path for the encryption of state backups.
+/// `1111573323` == ASCII "BACK". The key is deterministic per mnemonic and
+/// network, so a backup can be restored after a reinstall. Never change it:
+/// altering the path makes existing backups undecryptable.
+const STATE_BACKUP_ENCRYPTION_PATH: &str = "m/1111573323'/0'/0'/0/0";
+const STATE_BACKUP_VERSION: u32 = 1;
+
+/// The state stored in a backup, serialized and encrypted before upload.
+#[derive(Serialize, Deserialize)]
+struct StateBackup {
+    version: u32,
+    backed_up_at: u64,
+    contacts: Vec<Contact>,
+    spark_private_mode_enabled: bool,
+    stable_balance_active_label: Option<String>,
+    /// Missing from backups made before the setting existed
+    #[serde(default)]
+    display_currency: Option<String>,
+}
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+#[allow(clippy::needless_pass_by_value)]
+impl BreezSdk {
+    /// Uploads an encrypted backup of the contacts and user settings.
+    ///
+    /// The backup is encrypted with a key derived from the wallet seed and
+    /// posted to `url`, so it can be restored with
+    /// [`BreezSdk::restore_state`] after a reinstall.
+    pub async fn backup_state(
+        &self,
+        request: BackupStateRequest,
+    ) -> Result<BackupStateResponse, SdkError> {
+        let ecies = self.state_backup_encryption()?;
+        let contacts = self
+            .storage
+            .list_contacts(ListContactsRequest {
+                offset: None,
+                limit: None,
+            })
+            .await?;
+        let settings = self.get_user_settings().await?;
+        let backup = StateBackup {
+            version: STATE_BACKUP_VERSION,
+            backed_up_at: self.now()?,
+            contacts,
+            spark_private_mode_enabled: settings.spark_private_mode_enabled,
+            stable_balance_active_label: settings.stable_balance_active_label,
+            display_currency: settings.display_currency,
+        };
+
+        let plaintext = serde_json::to_vec(&backup)
+            .map_err(|e| SdkError::Generic(format!("Failed to serialize backup: {e}")))?;
+        let ciphertext = ecies.encrypt_ecies(&plaintext, &encryption_path()?).await?;
+        let response = self
+            .backup_client
+            .post(request.url, None, Some(BASE64.encode(ciphertext)))
+            .await
+            .map_err(|e| SdkError::NetworkError(format!("Failed to upload backup: {e}")))?;
+        if !response.is_success() {
+            return Err(SdkError::NetworkError(format!(
+                "Failed to upload backup: status {}",
+                response.status
+            )));
+        }
+
+        let contact_count = u32::try_from(backup.contacts.len()).unwrap_or(u32::MAX);
+        info!("Backed up state with {contact_count} contacts");
+        Ok(BackupStateResponse {
+            contact_count,
+            backed_up_at: backup.backed_up_at,
+        })
+    }
+
+    /// Downloads and decrypts a backup made with [`BreezSdk::backup_state`],
+    /// then restores its contacts and user settings.
+    ///
+    /// Contacts in the backup replace those with the same id and are merged
+    /// with those with the same lightning address, keeping the name updated
+    /// last. Other contacts are kept.
+    pub async fn restore_state(
+        &self,
+        request: RestoreStateRequest,
+    ) -> Result<RestoreStateResponse, SdkError> {
+        let ecies = self.state_backup_encryption()?;
+        let response = self
+            .backup_client
+            .get(request.url, None)
+            .await
+            .map_err(|e| SdkError::NetworkError(format!("Failed to download backup: {e}")))?;
+        if !response.is_success() {
+            return Err(SdkError::NetworkError(format!(
+                "Failed to download backup: status {}",
+                response.status
+            )));
+        }
+
+        let ciphertext = BASE64
+            .decode(response.body.trim().as_bytes())
+            .map_err(|e| SdkError::Generic(format!("Invalid backup encoding: {e}")))?;
+        let plaintext = ecies
+            .decrypt_ecies(&ciphertext, &encryption_path()?)
+            .await
+            .map_err(|e| SdkError::Generic(format!("Failed to decrypt backup: {e}")))?;
+        let backup: StateBackup = serde_json::from_slice(&plaintext)
+            .map_err(|e| SdkError::Generic(format!("Invalid backup: {e}")))?;
+        if backup.version > STATE_BACKUP_VERSION {
+            return Err(SdkError::Generic(format!(
+                "Backup version {} is not supported",
+                backup.version
+            )));
+        }
+
+        let contacts_restored = u32::try_from(backup.contacts.len()).unwrap_or(u32::MAX);
+        for contact in backup.contacts {
+            save_contact_merging_duplicates(self.storage.as_ref(), contact).await?;
+        }
+        self.update_user_settings(UpdateUserSettingsRequest {
+            spark_private_mode_enabled: Some(backup.spark_private_mode_enabled),
+            // The active stable balance token can only be restored when
+            // stable balance is configured
+            stable_balance_active_label: self.stable_balance.as_ref().map(|_| {
+                match backup.stable_balance_active_label {
+                    Some(label) => StableBalanceActiveLabel::Set { label },
+                    None => StableBalanceActiveLabel::Unset,
+                }
+            }),
+            display_currency: Some(match backup.display_currency {
+                Some(currency) => DisplayCurrency::Set { currency },
+                None => DisplayCurrency::Unset,
+            }),
+        })
+        .await?;
+
+        info!("Restored state with {contacts_restored} contacts");
+        Ok(RestoreStateResponse {
+            contacts_restored,
+            backed_up_at: backup.backed_up_at,
+        })
+    }
+}
+
+impl BreezSdk {
+    fn state_backup_encryption(&self) -> Result<&dyn EciesSigner, SdkError> {
+        self.state_backup_signer.as_deref().ok_or(SdkError::Generic(
+            "State backups require a signer that supports ECIES".to_string(),
+        ))
+    }
+}
+
+fn encryption_path() -> Result<DerivationPath, SdkError> {
+    STATE_BACKUP_ENCRYPTION_PATH
+        .parse()
+        .map_err(|e| SdkError::Generic(format!("Invalid state backup path: {e}")))
+}
+
+#[cfg(test)]
+mod tests {
+    use bitcoin::bip32::Xpriv;
+    use macros::async_test_all;
+
+    use super::*;
+    use crate::signer::breez::BreezSignerImpl;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[async_test_all]
+    async fn test_backup_decrypts_with_same_seed_only() {
+        let signer = |seed| {
+            BreezSignerImpl::new(Xpriv::new_master(bitcoin::Network::Regtest, &[seed; 32]).unwrap())
+        };
+        let backup = StateBackup {
+            version: STATE_BACKUP_VERSION,
+            backed_up_at: 100,
+            contacts: vec![Contact {
+                id: "id".to_string(),
+                name: "Alice".to_string(),
+                payment_identifier: "alice@example.com".to_string(),
+                additional_payment_identifiers: Vec::new(),
+                avatar_url: None,
+                notes: None,
+                created_at: 1,
+                updated_at: 2,
+            }],
+            spark_private_mode_enabled: true,
+            stable_balance_active_label: Some("usd".to_string()),
+            display_currency: Some("EUR".to_string()),
+        };
+        let path = encryption_path().unwrap();
+        let ciphertext = signer(1)
+            .encrypt_ecies(&serde_json::to_vec(&backup).unwrap(), &path)
+            .await
+            .unwrap();
+
+        let plaintext = signer(1).decrypt_ecies(&ciphertext, &path).await.unwrap();
+        let restored: StateBackup = serde_json::from_slice(&plaintext).unwrap();
+        assert_eq!(restored.contacts.len(), 1);
+        assert_eq!(restored.contacts[0].payment_identifier, "alice@example.com");
+        assert!(restored.spark_private_mode_enabled);
+        assert_eq!(restored.stable_balance_active_label.as_deref(), Some("usd"));
+        assert_eq!(restored.display_currency.as_deref(), Some("EUR"));
+
+        assert!(signer(2).decrypt_ecies(&ciphertext, &path).await.is_err());
+    }
+}
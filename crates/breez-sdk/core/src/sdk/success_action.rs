@@ -0,0 +1,149 @@
+use std::{str::FromStr, sync::Arc};
+
+use bitcoin::hashes::{Hash, sha256};
+use breez_sdk_common::lnurl::pay as lnurl_pay;
+use tracing::{debug, warn};
+
+use crate::{
+    AesSuccessActionData, AesSuccessActionDataResult, Payment, PaymentDetails, PaymentMetadata,
+    PaymentStatus, persist::Storage,
+};
+
+use super::helpers::process_success_action;
+
+/// Decrypts the AES success action of an LNURL-pay with the preimage of the
+/// payment, so that a redemption code can be shown again after the payment
+/// was sent. The preimage is hex encoded, as in the payment's HTLC details.
+#[cfg_attr(feature = "uniffi", uniffi::export)]
+#[allow(clippy::needless_pass_by_value)]
+pub fn decrypt_success_action(
+    data: AesSuccessActionData,
+    preimage: String,
+) -> AesSuccessActionDataResult {
+    let Ok(preimage) = sha256::Hash::from_str(&preimage) else {
+        return AesSuccessActionDataResult::ErrorStatus {
+            reason: "Invalid preimage".to_string(),
+        };
+    };
+    decrypt_aes_success_action(&data.into(), preimage.as_byte_array()).into()
+}
+
+pub(crate) fn decrypt_aes_success_action(
+    data: &lnurl_pay::AesSuccessActionData,
+    preimage: &[u8; 32],
+) -> lnurl_pay::AesSuccessActionDataResult {
+    match (data, preimage).try_into() {
+        Ok(data) => lnurl_pay::AesSuccessActionDataResult::Decrypted { data },
+        Err(e) => lnurl_pay::AesSuccessActionDataResult::ErrorStatus {
+            reason: e.to_string(),
+        },
+    }
+}
+
+/// Processes the success action of a completed LNURL-pay whose preimage
+/// wasn't known yet when it was sent, and persists it with the payment's
+/// LNURL info.
+pub(crate) async fn complete_lnurl_success_action(
+    storage: &Arc<dyn Storage>,
+    payment: &mut Payment,
+) {
+    if payment.status != PaymentStatus::Completed {
+        return;
+    }
+    let processed = match &payment.details {
+        Some(PaymentDetails::Lightning {
+            lnurl_pay_info: Some(lnurl_pay_info),
+            ..
+        }) if lnurl_pay_info.processed_success_action.is_none() => process_success_action(
+            payment,
+            lnurl_pay_info
+                .raw_success_action
+                .clone()
+                .map(Into::into)
+                .as_ref(),
+        ),
+        _ => return,
+    };
+    let processed = match processed {
+        Ok(Some(processed)) => processed,
+        Ok(None) => return,
+        Err(e) => {
+            warn!(
+                "Failed to process success action of payment {}: {e:?}",
+                payment.id
+            );
+            return;
+        }
+    };
+    let Some(PaymentDetails::Lightning {
+        lnurl_pay_info: Some(lnurl_pay_info),
+        ..
+    }) = &mut payment.details
+    else {
+        return;
+    };
+    lnurl_pay_info.processed_success_action = Some(processed.into());
+    let metadata = PaymentMetadata {
+        lnurl_pay_info: Some(lnurl_pay_info.clone()),
+        ..Default::default()
+    };
+    match storage
+        .insert_payment_metadata(payment.id.clone(), metadata)
+        .await
+    {
+        Ok(()) => debug!("Persisted success action of payment {}", payment.id),
+        Err(e) => warn!(
+            "Failed to persist success action of payment {}: {e:?}",
+            payment.id
+        ),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use base64::{Engine, prelude::BASE64_STANDARD};
+    use macros::test_all;
+
+    use super::*;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn aes_data() -> AesSuccessActionData {
+        AesSuccessActionData {
+            description: "Voucher".to_string(),
+            ciphertext: BASE64_STANDARD.encode(
+                hex::decode(
+                    "91239ab5d94369a18474ee58372c7d0fcee5e227903f671bfe19ef32f1cada804d10f0f006265289d936317343dbc0ca",
+                )
+                .unwrap(),
+            ),
+            iv: BASE64_STANDARD.encode([0x24; 16]),
+        }
+    }
+
+    #[test_all]
+    fn test_decrypt_success_action_with_preimage() {
+        let preimage = sha256::Hash::hash(&[0x42; 16]).to_string();
+        let AesSuccessActionDataResult::Decrypted { data } =
+            decrypt_success_action(aes_data(), preimage)
+        else {
+            panic!("Expected the success action to be decrypted");
+        };
+        assert_eq!(data.description, "Voucher");
+        assert_eq!(data.plaintext, "hello world! this is my plaintext.");
+    }
+
+    #[test_all]
+    fn test_decrypt_success_action_with_wrong_preimage() {
+        let preimage = sha256::Hash::hash(&[0x43; 16]).to_string();
+        assert!(matches!(
+            decrypt_success_action(aes_data(), preimage),
+            AesSuccessActionDataResult::ErrorStatus { .. }
+        ));
+        assert!(matches!(
+            decrypt_success_action(aes_data(), "not hex".to_string()),
+            AesSuccessActionDataResult::ErrorStatus { .. }
+        ));
+    }
+}
@@ -1,13 +1,20 @@
-use platform_utils::time::{Instant, SystemTime};
+use platform_utils::time::Instant;
 use platform_utils::tokio;
-use std::sync::Arc;
+use std::sync::{
+    Arc,
+    atomic::{AtomicU32, Ordering},
+};
 use tracing::{debug, error, info, trace, warn};
 
-use super::{BreezSdk, CLAIM_TX_SIZE_VBYTES, SYNC_PAGING_LIMIT, SyncType, parse_input};
+use super::{
+    BreezSdk, CLAIM_TX_SIZE_VBYTES, SYNC_PAGING_LIMIT, SyncType, auto_optimization, leaves,
+    parse_input, payments, service_status, token_metadata, token_sweep,
+};
 use crate::{
-    DepositInfo, InputType, MaxFee, PaymentDetails, PaymentType,
+    DepositInfo, DomainSyncStatus, GetSyncStatusResponse, InputType, MaxFee, PaymentDetails,
+    PaymentType, SyncDomain, SyncProgress, SyncStage,
     error::SdkError,
-    events::{InternalSyncedEvent, SdkEvent},
+    events::{EventEmitter, InternalSyncedEvent, SdkEvent},
     lnurl::ListMetadataRequest,
     models::{Payment, SyncWalletRequest, SyncWalletResponse},
     persist::{ObjectCacheRepository, UpdateDepositPayload},
@@ -113,9 +120,7 @@ impl BreezSdk {
         let cache = ObjectCacheRepository::new(self.storage.clone());
         let sync_interval_secs = u64::from(self.config.sync_interval_secs);
 
-        let now = SystemTime::now()
-            .duration_since(SystemTime::UNIX_EPOCH)
-            .map_or(0, |d| d.as_secs());
+        let now = self.now()?;
 
         // Skip if we synced recently (unless forced).
         if !force
@@ -138,6 +143,7 @@ impl BreezSdk {
         }
 
         let start_time = Instant::now();
+        let progress = SyncProgressReporter::new(&self.event_emitter, &sync_type);
 
         let sync_wallet = async {
             let wallet_synced = if sync_type.contains(SyncType::Wallet) {
@@ -163,6 +169,11 @@ impl BreezSdk {
                 trace!("sync_wallet_internal: Skipping Wallet sync");
                 false
             };
+            if sync_type.contains(SyncType::Wallet) {
+                progress
+                    .stage_finished(SyncStage::Wallet, wallet_synced)
+                    .await;
+            }
 
             let wallet_state_synced = if sync_type.contains(SyncType::WalletState) {
                 debug!("sync_wallet_internal: Starting WalletState sync");
@@ -187,12 +198,48 @@ impl BreezSdk {
                 trace!("sync_wallet_internal: Skipping WalletState sync");
                 false
             };
+            if sync_type.contains(SyncType::WalletState) {
+                progress
+                    .stage_finished(SyncStage::Payments, wallet_state_synced)
+                    .await;
+            }
 
-            (wallet_synced, wallet_state_synced)
+            let tokens_synced = if sync_type.contains(SyncType::WalletState) {
+                wallet_synced && wallet_state_synced
+            } else if sync_type.contains(SyncType::Tokens) {
+                debug!("sync_wallet_internal: Starting Tokens sync");
+                let tokens_start = Instant::now();
+                match self.sync_tokens_to_storage().await {
+                    Ok(()) => {
+                        debug!(
+                            "sync_wallet_internal: Tokens sync completed in {:?}",
+                            tokens_start.elapsed()
+                        );
+                        true
+                    }
+                    Err(e) => {
+                        error!(
+                            "sync_wallet_internal: Failed to sync tokens to storage in {:?}: {e:?}",
+                            tokens_start.elapsed()
+                        );
+                        false
+                    }
+                }
+            } else {
+                trace!("sync_wallet_internal: Skipping Tokens sync");
+                false
+            };
+            if syncs_tokens_alone(&sync_type) {
+                progress
+                    .stage_finished(SyncStage::Tokens, tokens_synced)
+                    .await;
+            }
+
+            (wallet_synced, wallet_state_synced, tokens_synced)
         };
 
         let sync_lnurl = async {
-            if sync_type.contains(SyncType::LnurlMetadata) {
+            let lnurl_synced = if sync_type.contains(SyncType::LnurlMetadata) {
                 debug!("sync_wallet_internal: Starting LnurlMetadata sync");
                 let lnurl_start = Instant::now();
                 match self.sync_lnurl_metadata().await {
@@ -214,11 +261,17 @@ impl BreezSdk {
             } else {
                 trace!("sync_wallet_internal: Skipping LnurlMetadata sync");
                 false
+            };
+            if sync_type.contains(SyncType::LnurlMetadata) {
+                progress
+                    .stage_finished(SyncStage::LightningAddress, lnurl_synced)
+                    .await;
             }
+            lnurl_synced
         };
 
         let sync_deposits = async {
-            if sync_type.contains(SyncType::Deposits) {
+            let deposits_synced = if sync_type.contains(SyncType::Deposits) {
                 debug!("sync_wallet_internal: Starting Deposits sync");
                 let deposits_start = Instant::now();
                 match self.check_and_claim_static_deposits().await {
@@ -240,12 +293,29 @@ impl BreezSdk {
             } else {
                 trace!("sync_wallet_internal: Skipping Deposits sync");
                 false
+            };
+            if sync_type.contains(SyncType::Deposits) {
+                progress
+                    .stage_finished(SyncStage::Deposits, deposits_synced)
+                    .await;
             }
+            deposits_synced
         };
 
-        let ((wallet, wallet_state), lnurl_metadata, deposits) =
+        let ((wallet, wallet_state, tokens), lnurl_metadata, deposits) =
             tokio::join!(sync_wallet, sync_lnurl, sync_deposits);
 
+        self.record_domain_syncs(
+            now,
+            &[
+                (SyncDomain::Payments, wallet && wallet_state),
+                (SyncDomain::Tokens, tokens),
+                (SyncDomain::Deposits, deposits),
+                (SyncDomain::LightningAddress, lnurl_metadata),
+            ],
+        )
+        .await;
+
         let elapsed = start_time.elapsed();
         let event = InternalSyncedEvent {
             wallet,
@@ -271,6 +341,42 @@ impl BreezSdk {
         );
         sync_service.sync_payments(initial_sync_complete).await?;
 
+        if self.config.auto_refund_htlc_payments {
+            payments::htlc_refund::refund_expired_htlc_payments(self).await;
+        }
+        payments::arbitrated_escrow::refresh_arbitrated_escrows(self).await;
+        leaves::record_leaves_first_seen(self).await;
+        // Background work that would otherwise keep the network busy, stopped
+        // while the background sync is paused
+        if !self.is_background_sync_paused() {
+            auto_optimization::maybe_optimize_leaves(self).await;
+            token_sweep::maybe_sweep_tokens(self).await;
+            service_status::maybe_refresh_service_status(self).await;
+            token_metadata::maybe_refresh_tokens_metadata(self).await;
+        }
+
+        Ok(())
+    }
+
+    /// Synchronizes only the token outputs, balances and payments to storage,
+    /// for a [`SyncDomain::Tokens`] sync without the rest of the wallet state
+    async fn sync_tokens_to_storage(&self) -> Result<(), SdkError> {
+        self.spark_wallet.sync_tokens().await?;
+        update_balances(self.spark_wallet.clone(), self.storage.clone()).await?;
+
+        let initial_sync_complete = *self.initial_synced_watcher.borrow();
+        SparkSyncService::new(
+            self.spark_wallet.clone(),
+            self.storage.clone(),
+            self.event_emitter.clone(),
+        )
+        .sync_token_payments(initial_sync_complete)
+        .await?;
+
+        if !self.is_background_sync_paused() {
+            token_metadata::maybe_refresh_tokens_metadata(self).await;
+        }
+
         Ok(())
     }
 
@@ -354,6 +460,9 @@ impl BreezSdk {
                 .emit(&SdkEvent::ClaimedDeposits { claimed_deposits })
                 .await;
         }
+        self.check_refund_confirmations().await;
+        self.check_onchain_transactions().await;
+        self.check_unilateral_exit_progress().await;
         Ok(())
     }
 
@@ -385,6 +494,9 @@ impl BreezSdk {
 
         let cache = ObjectCacheRepository::new(Arc::clone(&self.storage));
         let mut updated_after = cache.fetch_lnurl_metadata_updated_after().await?;
+        // The first sync restores the history, so there are no new receipts
+        let notify_zap_receipts = updated_after > 0;
+        let mut zap_receipt_hashes = cache.fetch_lnurl_zap_receipts().await?;
 
         loop {
             debug!("Syncing lnurl metadata from updated_after {updated_after}");
@@ -403,9 +515,26 @@ impl BreezSdk {
 
             let len = u32::try_from(metadata.metadata.len())?;
             let last_updated_at = metadata.metadata.last().map(|m| m.updated_at);
+            // Metadata syncs again whenever it's updated, so only a receipt
+            // the stored metadata didn't have yet is new
+            let zap_receipts: Vec<SdkEvent> = metadata
+                .metadata
+                .iter()
+                .filter_map(|m| {
+                    let zap_receipt = m.nostr_zap_receipt.clone()?;
+                    zap_receipt_hashes.insert(m.payment_hash.clone()).then(|| {
+                        SdkEvent::NostrZapReceiptIssued {
+                            payment_hash: m.payment_hash.clone(),
+                            zap_receipt,
+                        }
+                    })
+                })
+                .filter(|_| notify_zap_receipts)
+                .collect();
             self.storage
                 .set_lnurl_metadata(metadata.metadata.into_iter().map(From::from).collect())
                 .await?;
+            cache.save_lnurl_zap_receipts(&zap_receipt_hashes).await?;
 
             debug!(
                 "Synchronized {} lnurl metadata at updated_after {updated_after}",
@@ -415,6 +544,9 @@ impl BreezSdk {
             cache
                 .save_lnurl_metadata_updated_after(updated_after)
                 .await?;
+            for event in zap_receipts {
+                self.event_emitter.emit(&event).await;
+            }
 
             if len < SYNC_PAGING_LIMIT {
                 // No more invoices to fetch
@@ -489,15 +621,182 @@ impl BreezSdk {
 #[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
 #[allow(clippy::needless_pass_by_value)]
 impl BreezSdk {
-    /// Synchronizes the wallet with the Spark network
-    #[allow(unused_variables)]
+    /// Synchronizes the wallet with the Spark network.
+    ///
+    /// Syncs the [`SyncDomain`]s in the request, or all of them if unset.
+    /// The progress of the sync is reported by [`SdkEvent::SyncProgress`]
+    /// events.
     pub async fn sync_wallet(
         &self,
         request: SyncWalletRequest,
     ) -> Result<SyncWalletResponse, SdkError> {
-        self.runtime
-            .run_user_sync(self, super::SyncType::Full, true)
-            .await?;
+        let sync_type = match &request.domains {
+            Some(domains) => sync_type_for_domains(domains)?,
+            None => SyncType::Full,
+        };
+        self.runtime.run_user_sync(self, sync_type, true).await?;
         Ok(SyncWalletResponse {})
     }
+
+    /// Returns when each [`SyncDomain`] was last synced successfully
+    pub async fn get_sync_status(&self) -> Result<GetSyncStatusResponse, SdkError> {
+        let times = ObjectCacheRepository::new(self.storage.clone())
+            .fetch_domain_sync_times()
+            .await?;
+        let domains = ALL_SYNC_DOMAINS
+            .iter()
+            .map(|domain| DomainSyncStatus {
+                domain: *domain,
+                last_synced_at: times.get(domain).copied(),
+            })
+            .collect();
+        Ok(GetSyncStatusResponse { domains })
+    }
+}
+
+impl BreezSdk {
+    /// Saves `now` as the last sync time of the domains that synced
+    async fn record_domain_syncs(&self, now: u64, domains: &[(SyncDomain, bool)]) {
+        if !domains.iter().any(|(_, synced)| *synced) {
+            return;
+        }
+        let cache = ObjectCacheRepository::new(self.storage.clone());
+        let mut times = match cache.fetch_domain_sync_times().await {
+            Ok(times) => times,
+            Err(e) => {
+                error!("sync_wallet_internal: Failed to read domain sync times: {e:?}");
+                return;
+            }
+        };
+        for (domain, _) in domains.iter().filter(|(_, synced)| *synced) {
+            times.insert(*domain, now);
+        }
+        if let Err(e) = cache.save_domain_sync_times(&times).await {
+            error!("sync_wallet_internal: Failed to save domain sync times: {e:?}");
+        }
+    }
+}
+
+const ALL_SYNC_DOMAINS: [SyncDomain; 4] = [
+    SyncDomain::Payments,
+    SyncDomain::Tokens,
+    SyncDomain::Deposits,
+    SyncDomain::LightningAddress,
+];
+
+fn sync_type_for_domains(domains: &[SyncDomain]) -> Result<SyncType, SdkError> {
+    if domains.is_empty() {
+        return Err(SdkError::InvalidInput(
+            "At least one sync domain is required".to_string(),
+        ));
+    }
+    Ok(domains.iter().fold(SyncType::empty(), |sync_type, domain| {
+        sync_type
+            | match domain {
+                SyncDomain::Payments => SyncType::Wallet | SyncType::WalletState,
+                SyncDomain::Tokens => SyncType::Tokens,
+                SyncDomain::Deposits => SyncType::Deposits,
+                SyncDomain::LightningAddress => SyncType::LnurlMetadata,
+            }
+    }))
+}
+
+/// Whether the tokens are synced as a stage of their own, rather than as part
+/// of the wallet state
+fn syncs_tokens_alone(sync_type: &SyncType) -> bool {
+    sync_type.contains(SyncType::Tokens) && !sync_type.contains(SyncType::WalletState)
+}
+
+/// Emits a [`SdkEvent::SyncProgress`] as each stage of a sync finishes
+struct SyncProgressReporter<'a> {
+    event_emitter: &'a EventEmitter,
+    total_stages: u32,
+    finished_stages: AtomicU32,
+}
+
+impl<'a> SyncProgressReporter<'a> {
+    fn new(event_emitter: &'a EventEmitter, sync_type: &SyncType) -> Self {
+        let total_stages = [
+            SyncType::Wallet,
+            SyncType::WalletState,
+            SyncType::Deposits,
+            SyncType::LnurlMetadata,
+        ]
+        .into_iter()
+        .filter(|stage| sync_type.contains(stage.clone()))
+        .count()
+            + usize::from(syncs_tokens_alone(sync_type));
+        Self {
+            event_emitter,
+            total_stages: u32::try_from(total_stages).unwrap_or(u32::MAX),
+            finished_stages: AtomicU32::new(0),
+        }
+    }
+
+    async fn stage_finished(&self, stage: SyncStage, succeeded: bool) {
+        let finished = self
+            .finished_stages
+            .fetch_add(1, Ordering::SeqCst)
+            .saturating_add(1);
+        self.event_emitter
+            .emit(&SdkEvent::SyncProgress {
+                progress: SyncProgress {
+                    stage,
+                    succeeded,
+                    percent: sync_percent(finished, self.total_stages),
+                },
+            })
+            .await;
+    }
+}
+
+fn sync_percent(finished: u32, total: u32) -> u8 {
+    if total == 0 {
+        return 100;
+    }
+    let percent = u64::from(finished.min(total)) * 100 / u64::from(total);
+    u8::try_from(percent).unwrap_or(100)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_sync_type_for_domains() {
+        assert_eq!(
+            sync_type_for_domains(&[SyncDomain::Payments]).unwrap(),
+            SyncType::Wallet | SyncType::WalletState
+        );
+        assert_eq!(
+            sync_type_for_domains(&[SyncDomain::Deposits, SyncDomain::LightningAddress]).unwrap(),
+            SyncType::Deposits | SyncType::LnurlMetadata
+        );
+        assert_eq!(
+            sync_type_for_domains(&[SyncDomain::Tokens]).unwrap(),
+            SyncType::Tokens
+        );
+        assert_eq!(
+            sync_type_for_domains(&ALL_SYNC_DOMAINS).unwrap(),
+            SyncType::Full
+        );
+        assert!(syncs_tokens_alone(&SyncType::Tokens));
+        assert!(!syncs_tokens_alone(&SyncType::Full));
+        assert!(matches!(
+            sync_type_for_domains(&[]),
+            Err(SdkError::InvalidInput(_))
+        ));
+    }
+
+    #[test_all]
+    fn test_sync_percent() {
+        assert_eq!(sync_percent(1, 4), 25);
+        assert_eq!(sync_percent(3, 4), 75);
+        assert_eq!(sync_percent(4, 4), 100);
+        assert_eq!(sync_percent(0, 0), 100);
+    }
 }
@@ -0,0 +1,179 @@
+use tracing::warn;
+
+use crate::{
+    AssetFilter, GetTokenActivityRequest, GetTokenActivityResponse, ListPaymentsRequest, Payment,
+    TokenActivity,
+    error::SdkError,
+    persist::{CachedTokenFreeze, ObjectCacheRepository},
+};
+
+use super::{BreezSdk, clock_now};
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl BreezSdk {
+    /// Lists the activity of one token, newest first.
+    ///
+    /// The activity includes the transfers, mints and burns of the token by
+    /// this wallet, the freezes and unfreezes this wallet made as the token
+    /// issuer, and the freezes of the token held by this wallet. The
+    /// operators don't notify holders of freezes, so a freeze of the held
+    /// token is only known once a send of it is rejected, and its unfreeze
+    /// once a send goes through again.
+    ///
+    /// # Arguments
+    ///
+    /// * `request` - The token and the pagination of its activity
+    pub async fn get_token_activity(
+        &self,
+        request: GetTokenActivityRequest,
+    ) -> Result<GetTokenActivityResponse, SdkError> {
+        // Freezes can precede any of the payments, so the page may need all
+        // the payments up to its end
+        let payments = self
+            .list_payments(ListPaymentsRequest {
+                asset_filter: Some(AssetFilter::Token {
+                    token_identifier: Some(request.token_identifier.clone()),
+                }),
+                limit: request
+                    .limit
+                    .map(|limit| limit.saturating_add(request.offset.unwrap_or(0))),
+                ..Default::default()
+            })
+            .await?
+            .payments;
+        let freezes = ObjectCacheRepository::new(self.storage.clone())
+            .fetch_token_freezes(&request.token_identifier)
+            .await?;
+        Ok(GetTokenActivityResponse {
+            activity: merge_token_activity(payments, freezes, request.offset, request.limit),
+        })
+    }
+}
+
+/// Records a freeze of a token held by this wallet when a send of it is
+/// rejected because the token is frozen, and the unfreeze when a send of it
+/// goes through after such a freeze.
+pub(in crate::sdk) async fn record_holder_freeze<T>(
+    sdk: &BreezSdk,
+    token_identifier: &str,
+    send_result: &Result<T, SdkError>,
+) {
+    let frozen = match send_result {
+        Ok(_) => false,
+        Err(e) if e.to_string().to_lowercase().contains("frozen") => true,
+        Err(_) => return,
+    };
+    let result: Result<(), SdkError> = async {
+        let address = sdk.spark_wallet.get_spark_address()?.to_string();
+        let cache = ObjectCacheRepository::new(sdk.storage.clone());
+        let was_frozen = cache
+            .fetch_token_freezes(token_identifier)
+            .await?
+            .iter()
+            .rev()
+            .find(|freeze| freeze.address == address)
+            .is_some_and(|freeze| freeze.frozen);
+        if was_frozen == frozen {
+            return Ok(());
+        }
+        let impacted_token_amount = cache
+            .fetch_account_info()
+            .await?
+            .and_then(|info| info.token_balances.get(token_identifier).map(|b| b.balance))
+            .unwrap_or_default();
+        cache
+            .add_token_freeze(
+                token_identifier,
+                CachedTokenFreeze {
+                    address,
+                    frozen,
+                    impacted_token_amount,
+                    timestamp: clock_now(sdk.clock.as_ref()),
+                },
+            )
+            .await?;
+        Ok(())
+    }
+    .await;
+    if let Err(e) = result {
+        warn!("Failed to record the freeze of held token {token_identifier}: {e:?}");
+    }
+}
+
+/// Merges the payments and freezes of a token into one page of activity,
+/// newest first
+fn merge_token_activity(
+    payments: Vec<Payment>,
+    freezes: Vec<CachedTokenFreeze>,
+    offset: Option<u32>,
+    limit: Option<u32>,
+) -> Vec<TokenActivity> {
+    let mut activity: Vec<TokenActivity> = payments
+        .into_iter()
+        .map(|payment| TokenActivity::Payment { payment })
+        .chain(freezes.into_iter().map(|freeze| TokenActivity::Freeze {
+            address: freeze.address,
+            frozen: freeze.frozen,
+            impacted_token_amount: freeze.impacted_token_amount,
+            timestamp: freeze.timestamp,
+        }))
+        .collect();
+    activity.sort_by_key(|a| std::cmp::Reverse(a.timestamp()));
+    activity
+        .into_iter()
+        .skip(offset.unwrap_or(0) as usize)
+        .take(limit.map_or(usize::MAX, |limit| limit as usize))
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+    use crate::{PaymentType, sdk::ledger};
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn payment(id: &str, timestamp: u64) -> Payment {
+        ledger::tests::payment(id, PaymentType::Receive, 100, 0, timestamp)
+    }
+
+    fn freeze(timestamp: u64) -> CachedTokenFreeze {
+        CachedTokenFreeze {
+            address: "spark1address".to_string(),
+            frozen: true,
+            impacted_token_amount: 50,
+            timestamp,
+        }
+    }
+
+    #[test_all]
+    fn test_activity_is_sorted_newest_first() {
+        let activity = merge_token_activity(
+            vec![payment("newest", 30), payment("oldest", 10)],
+            vec![freeze(20)],
+            None,
+            None,
+        );
+        let timestamps: Vec<u64> = activity.iter().map(TokenActivity::timestamp).collect();
+        assert_eq!(timestamps, vec![30, 20, 10]);
+        assert!(matches!(
+            activity[1],
+            TokenActivity::Freeze { frozen: true, .. }
+        ));
+    }
+
+    #[test_all]
+    fn test_activity_is_paginated() {
+        let activity = merge_token_activity(
+            vec![payment("a", 40), payment("b", 30), payment("c", 10)],
+            vec![freeze(20)],
+            Some(1),
+            Some(2),
+        );
+        let timestamps: Vec<u64> = activity.iter().map(TokenActivity::timestamp).collect();
+        assert_eq!(timestamps, vec![30, 20]);
+    }
+}
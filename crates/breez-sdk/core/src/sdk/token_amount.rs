@@ -0,0 +1,150 @@
+use crate::{AmountRounding, TokenMetadata, error::SdkError};
+
+/// Converts a decimal token amount, such as `"12.5"`, to the token base units
+/// used by payment requests, using the decimals of the token.
+///
+/// Digits beyond the decimals of the token are rounded according to
+/// `rounding`.
+#[cfg_attr(feature = "uniffi", uniffi::export)]
+#[allow(clippy::needless_pass_by_value)]
+pub fn amount_to_base_units(
+    amount: String,
+    token_metadata: TokenMetadata,
+    rounding: AmountRounding,
+) -> Result<u128, SdkError> {
+    let invalid_amount = || SdkError::InvalidInput(format!("Invalid amount: {amount}"));
+    let overflow = || SdkError::InvalidInput(format!("Amount is too large: {amount}"));
+
+    let (integer, fraction) = match amount.trim().split_once('.') {
+        Some((integer, fraction)) => (integer, fraction),
+        None => (amount.trim(), ""),
+    };
+    if (integer.is_empty() && fraction.is_empty())
+        || !integer
+            .chars()
+            .chain(fraction.chars())
+            .all(|c| c.is_ascii_digit())
+    {
+        return Err(invalid_amount());
+    }
+
+    let decimals = usize::try_from(token_metadata.decimals)?;
+    let (kept, dropped) = fraction.split_at(fraction.len().min(decimals));
+    let digits = format!("{integer}{kept:0<decimals$}");
+    let digits = digits.trim_start_matches('0');
+    let base_units = if digits.is_empty() {
+        0
+    } else {
+        digits.parse::<u128>().map_err(|_| overflow())?
+    };
+
+    if round_up(base_units, dropped, rounding) {
+        base_units.checked_add(1).ok_or_else(overflow)
+    } else {
+        Ok(base_units)
+    }
+}
+
+/// Converts token base units to a decimal token amount, such as `"12.5"`,
+/// using the decimals of the token. The conversion is exact.
+#[cfg_attr(feature = "uniffi", uniffi::export)]
+#[allow(clippy::needless_pass_by_value)]
+pub fn base_units_to_amount(base_units: u128, token_metadata: TokenMetadata) -> String {
+    format_base_units(base_units, token_metadata.decimals)
+}
+
+/// Formats `base_units` of an asset with `decimals` as a decimal amount,
+/// without trailing zeros.
+pub(super) fn format_base_units(base_units: u128, decimals: u32) -> String {
+    let decimals = decimals as usize;
+    if decimals == 0 {
+        return base_units.to_string();
+    }
+
+    let digits = format!("{base_units:0>width$}", width = decimals + 1);
+    let (integer, fraction) = digits.split_at(digits.len() - decimals);
+    let fraction = fraction.trim_end_matches('0');
+    if fraction.is_empty() {
+        integer.to_string()
+    } else {
+        format!("{integer}.{fraction}")
+    }
+}
+
+/// Whether the truncated `base_units` have to be rounded up given the
+/// `dropped` digits.
+fn round_up(base_units: u128, dropped: &str, rounding: AmountRounding) -> bool {
+    let mut dropped_digits = dropped.bytes().map(|b| b - b'0');
+    match rounding {
+        AmountRounding::Down => false,
+        AmountRounding::Up => dropped_digits.any(|d| d > 0),
+        AmountRounding::HalfUp => dropped_digits.next().is_some_and(|d| d >= 5),
+        AmountRounding::HalfEven => match dropped_digits.next() {
+            Some(d) if d > 5 => true,
+            Some(5) => dropped_digits.any(|d| d > 0) || base_units % 2 == 1,
+            _ => false,
+        },
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn token(decimals: u32) -> TokenMetadata {
+        TokenMetadata {
+            identifier: String::new(),
+            issuer_public_key: String::new(),
+            name: String::new(),
+            ticker: String::new(),
+            decimals,
+            max_supply: u128::MAX,
+            is_freezable: false,
+        }
+    }
+
+    fn to_base_units(amount: &str, decimals: u32, rounding: AmountRounding) -> u128 {
+        amount_to_base_units(amount.to_string(), token(decimals), rounding).unwrap()
+    }
+
+    #[test_all]
+    fn test_amount_to_base_units() {
+        assert_eq!(to_base_units("12.5", 6, AmountRounding::Down), 12_500_000);
+        assert_eq!(to_base_units("12", 6, AmountRounding::Down), 12_000_000);
+        assert_eq!(to_base_units(".000001", 6, AmountRounding::Down), 1);
+        assert_eq!(to_base_units("0.0", 6, AmountRounding::Down), 0);
+        assert_eq!(to_base_units("7", 0, AmountRounding::Down), 7);
+
+        for invalid in ["", ".", "-1", "1.2.3", "1e6", "1,5"] {
+            assert!(
+                amount_to_base_units(invalid.to_string(), token(6), AmountRounding::Down).is_err()
+            );
+        }
+        assert!(amount_to_base_units("1".to_string(), token(39), AmountRounding::Down).is_err());
+    }
+
+    #[test_all]
+    fn test_amount_to_base_units_rounding() {
+        assert_eq!(to_base_units("1.25", 1, AmountRounding::Down), 12);
+        assert_eq!(to_base_units("1.25", 1, AmountRounding::Up), 13);
+        assert_eq!(to_base_units("1.20", 1, AmountRounding::Up), 12);
+        assert_eq!(to_base_units("1.25", 1, AmountRounding::HalfUp), 13);
+        assert_eq!(to_base_units("1.24", 1, AmountRounding::HalfUp), 12);
+        assert_eq!(to_base_units("1.25", 1, AmountRounding::HalfEven), 12);
+        assert_eq!(to_base_units("1.35", 1, AmountRounding::HalfEven), 14);
+        assert_eq!(to_base_units("1.251", 1, AmountRounding::HalfEven), 13);
+    }
+
+    #[test_all]
+    fn test_base_units_to_amount() {
+        assert_eq!(base_units_to_amount(12_500_000, token(6)), "12.5");
+        assert_eq!(base_units_to_amount(12_000_000, token(6)), "12");
+        assert_eq!(base_units_to_amount(1, token(6)), "0.000001");
+        assert_eq!(base_units_to_amount(0, token(6)), "0");
+        assert_eq!(base_units_to_amount(7, token(0)), "7");
+    }
+}
@@ -0,0 +1,161 @@
+use tracing::{info, warn};
+
+use crate::{
+    RefreshTokensMetadataRequest, RefreshTokensMetadataResponse, TokenMetadata,
+    error::SdkError,
+    events::SdkEvent,
+    persist::{CachedTokenMetadata, ObjectCacheRepository},
+    utils::token::{is_token_metadata_stale, refresh_tokens_metadata},
+};
+
+use super::BreezSdk;
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl BreezSdk {
+    /// Refreshes the cached metadata of tokens from the Spark network.
+    ///
+    /// Token metadata is cached when a token is first seen, and is served
+    /// from the cache afterwards, also while offline. While background tasks
+    /// are enabled, the metadata of held tokens is refreshed after a wallet
+    /// sync once it's older than a day. Changes to the metadata of held
+    /// tokens are reported by [`SdkEvent::TokenMetadataChanged`] events.
+    pub async fn refresh_tokens_metadata(
+        &self,
+        request: RefreshTokensMetadataRequest,
+    ) -> Result<RefreshTokensMetadataResponse, SdkError> {
+        let token_identifiers = if request.token_identifiers.is_empty() {
+            self.held_token_identifiers().await?
+        } else {
+            request.token_identifiers
+        };
+        let tokens_metadata = self
+            .refresh_and_report_tokens_metadata(&token_identifiers)
+            .await?;
+        Ok(RefreshTokensMetadataResponse { tokens_metadata })
+    }
+}
+
+impl BreezSdk {
+    /// The tokens in the last balances saved by a wallet sync
+    async fn held_token_identifiers(&self) -> Result<Vec<String>, SdkError> {
+        Ok(ObjectCacheRepository::new(self.storage.clone())
+            .fetch_account_info()
+            .await?
+            .map(|info| info.token_balances.into_keys().collect())
+            .unwrap_or_default())
+    }
+
+    /// Refreshes the cached metadata of the tokens, and emits the metadata of
+    /// the held tokens that changed
+    async fn refresh_and_report_tokens_metadata(
+        &self,
+        token_identifiers: &[String],
+    ) -> Result<Vec<TokenMetadata>, SdkError> {
+        let held = self.held_token_identifiers().await?;
+        let refreshed = refresh_tokens_metadata(
+            &self.spark_wallet,
+            &ObjectCacheRepository::new(self.storage.clone()),
+            &token_identifiers
+                .iter()
+                .map(String::as_str)
+                .collect::<Vec<_>>(),
+            self.now()?,
+        )
+        .await?;
+
+        let mut tokens_metadata = Vec::with_capacity(refreshed.len());
+        for (previous, metadata) in refreshed {
+            if metadata_changed(previous.as_ref(), &metadata) && held.contains(&metadata.identifier)
+            {
+                info!("Token metadata changed for {}", metadata.identifier);
+                self.event_emitter
+                    .emit(&SdkEvent::TokenMetadataChanged {
+                        metadata: metadata.clone(),
+                    })
+                    .await;
+            }
+            tokens_metadata.push(metadata);
+        }
+        Ok(tokens_metadata)
+    }
+}
+
+/// Whether refreshed metadata differs from the cached metadata. A token
+/// without cached metadata wasn't seen before, so there's no change to report.
+fn metadata_changed(previous: Option<&TokenMetadata>, metadata: &TokenMetadata) -> bool {
+    previous.is_some_and(|previous| previous != metadata)
+}
+
+/// Refreshes the metadata of the held tokens whose cached metadata is stale.
+/// Called after each wallet sync, which also stamps metadata cached on first
+/// sight since the last sync as fetched now.
+pub(super) async fn maybe_refresh_tokens_metadata(sdk: &BreezSdk) {
+    if !sdk.config.background_tasks_enabled {
+        return;
+    }
+    let Ok(now) = sdk.now() else {
+        return;
+    };
+    let result = async {
+        let cache = ObjectCacheRepository::new(sdk.storage.clone());
+        let mut stale = Vec::new();
+        for token_identifier in sdk.held_token_identifiers().await? {
+            match cache.fetch_token_metadata(&token_identifier).await? {
+                Some(CachedTokenMetadata {
+                    metadata,
+                    fetched_at: None,
+                }) => {
+                    cache
+                        .save_token_metadata(&CachedTokenMetadata {
+                            metadata,
+                            fetched_at: Some(now),
+                        })
+                        .await?;
+                }
+                Some(CachedTokenMetadata {
+                    fetched_at: Some(fetched_at),
+                    ..
+                }) if !is_token_metadata_stale(fetched_at, now) => {}
+                _ => stale.push(token_identifier),
+            }
+        }
+        if stale.is_empty() {
+            return Ok::<_, SdkError>(());
+        }
+        sdk.refresh_and_report_tokens_metadata(&stale).await?;
+        Ok(())
+    }
+    .await;
+    if let Err(e) = result {
+        warn!("Failed to refresh tokens metadata: {e}");
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn metadata(ticker: &str) -> TokenMetadata {
+        TokenMetadata {
+            identifier: "btkn1".to_string(),
+            issuer_public_key: "02aa".to_string(),
+            name: "Token".to_string(),
+            ticker: ticker.to_string(),
+            decimals: 6,
+            max_supply: 1_000_000,
+            is_freezable: false,
+        }
+    }
+
+    #[test_all]
+    fn test_metadata_changed() {
+        assert!(!metadata_changed(None, &metadata("TKN")));
+        assert!(!metadata_changed(Some(&metadata("TKN")), &metadata("TKN")));
+        assert!(metadata_changed(Some(&metadata("TKN")), &metadata("NEW")));
+    }
+}
@@ -0,0 +1,309 @@
+use platform_utils::tokio;
+use tracing::{Instrument, debug, error, info, warn};
+
+use crate::{
+    ConversionStatus, PaymentRail, TokenSweepPolicy,
+    error::SdkError,
+    events::{SdkEvent, TokenSweepEvent},
+    persist::{ObjectCacheRepository, PaymentMetadata},
+    token_conversion::{
+        ConversionAmount, ConversionOptions, ConversionPurpose, ConversionType,
+        FetchConversionLimitsRequest, TokenConversionResponse,
+    },
+};
+
+use super::{BreezSdk, CappedSend};
+
+/// Starts a sweep of token balances in the background if the configured
+/// [`TokenSweepPolicy`] interval has elapsed. Called after each wallet sync.
+pub(super) async fn maybe_sweep_tokens(sdk: &BreezSdk) {
+    let Some(policy) = sdk.config.token_sweep_policy.clone() else {
+        return;
+    };
+    if !sdk.config.background_tasks_enabled {
+        return;
+    }
+    if !sdk.pending_payments.lock().await.is_empty() {
+        debug!("Skipping token sweep: payments in flight");
+        return;
+    }
+    match sdk.is_wallet_frozen().await {
+        Ok(false) => {}
+        Ok(true) => {
+            debug!("Skipping token sweep: wallet is frozen");
+            return;
+        }
+        Err(e) => {
+            error!("Failed to check wallet freeze: {e:?}");
+            return;
+        }
+    }
+
+    let object_repository = ObjectCacheRepository::new(sdk.storage.clone());
+    let now = match sdk.now() {
+        Ok(now) => now,
+        Err(e) => {
+            error!("Failed to check token sweep interval: {e:?}");
+            return;
+        }
+    };
+    match object_repository.fetch_last_token_sweep().await {
+        Ok(Some(last_sweep)) if now < last_sweep.saturating_add(policy.interval_secs) => return,
+        Ok(_) => {}
+        Err(e) => {
+            error!("Failed to read last token sweep: {e:?}");
+            return;
+        }
+    }
+    // Saved before sweeping, so that syncs during the sweep don't start another
+    if let Err(e) = object_repository.save_last_token_sweep(now).await {
+        error!("Failed to save last token sweep: {e:?}");
+        return;
+    }
+
+    let task_sdk = sdk.clone();
+    let span = tracing::Span::current();
+    tokio::spawn(
+        async move {
+            run_sweep(&task_sdk, &policy).await;
+        }
+        .instrument(span),
+    );
+}
+
+/// Converts each swept balance to sats, emitting a [`TokenSweepEvent`] per
+/// token and one when done.
+async fn run_sweep(sdk: &BreezSdk, policy: &TokenSweepPolicy) {
+    let sweep_id = uuid::Uuid::now_v7().to_string();
+    let balances = match swept_balances(sdk, policy).await {
+        Ok(balances) => balances,
+        Err(e) => {
+            error!("Failed to select token balances to sweep: {e:?}");
+            return;
+        }
+    };
+
+    let mut swept_count: u32 = 0;
+    for (token_identifier, amount) in balances {
+        let sweep_event = match sweep_token(sdk, &token_identifier, amount).await {
+            Ok(Some(response)) => {
+                swept_count = swept_count.saturating_add(1);
+                TokenSweepEvent::Swept {
+                    sweep_id: sweep_id.clone(),
+                    token_identifier,
+                    amount,
+                    sent_payment_id: response.sent_payment_id,
+                    received_payment_id: response.received_payment_id,
+                }
+            }
+            Ok(None) => continue,
+            Err(e) => {
+                warn!("Failed to sweep token {token_identifier}: {e:?}");
+                TokenSweepEvent::Failed {
+                    sweep_id: sweep_id.clone(),
+                    token_identifier,
+                    error: e.to_string(),
+                }
+            }
+        };
+        sdk.event_emitter
+            .emit(&SdkEvent::TokenSweep { sweep_event })
+            .await;
+    }
+
+    info!("Token sweep {sweep_id} completed: {swept_count} balances swept");
+    sdk.event_emitter
+        .emit(&SdkEvent::TokenSweep {
+            sweep_event: TokenSweepEvent::Completed {
+                sweep_id,
+                swept_count,
+            },
+        })
+        .await;
+}
+
+/// The token balances the policy sweeps, as token identifier and balance.
+async fn swept_balances(
+    sdk: &BreezSdk,
+    policy: &TokenSweepPolicy,
+) -> Result<Vec<(String, u128)>, SdkError> {
+    let active_token = match &sdk.stable_balance {
+        Some(stable_balance) => stable_balance.get_active_token_identifier().await,
+        None => None,
+    };
+
+    let mut swept = Vec::new();
+    for (token_identifier, token_balance) in sdk.spark_wallet.get_token_balances().await? {
+        if token_balance.balance == 0 || active_token.as_ref() == Some(&token_identifier) {
+            continue;
+        }
+        let value_sat = if sweeps_regardless_of_value(policy, &token_identifier) {
+            None
+        } else {
+            let Some(value_sat) =
+                estimate_value_sat(sdk, &token_identifier, token_balance.balance).await
+            else {
+                debug!("Not sweeping token {token_identifier}: value can't be estimated");
+                continue;
+            };
+            Some(value_sat)
+        };
+        if should_sweep(policy, &token_identifier, value_sat) {
+            swept.push((token_identifier, token_balance.balance));
+        }
+    }
+    Ok(swept)
+}
+
+fn sweeps_regardless_of_value(policy: &TokenSweepPolicy, token_identifier: &str) -> bool {
+    policy.sweep_unrecognized_tokens
+        && !policy
+            .recognized_token_identifiers
+            .iter()
+            .any(|id| id == token_identifier)
+}
+
+/// Whether the balance of a token is swept. `value_sat` is the estimated
+/// value of the balance, `None` when it doesn't need to be known.
+fn should_sweep(
+    policy: &TokenSweepPolicy,
+    token_identifier: &str,
+    value_sat: Option<u128>,
+) -> bool {
+    if sweeps_regardless_of_value(policy, token_identifier) {
+        return true;
+    }
+    value_sat.is_some_and(|value_sat| value_sat < u128::from(policy.max_balance_sat))
+}
+
+async fn estimate_value_sat(sdk: &BreezSdk, token_identifier: &str, balance: u128) -> Option<u128> {
+    let options = to_bitcoin_options(token_identifier);
+    match sdk
+        .token_converter
+        .validate(
+            Some(&options),
+            Some(&token_identifier.to_string()),
+            ConversionAmount::AmountIn(balance),
+        )
+        .await
+    {
+        Ok(estimate) => estimate.map(|estimate| estimate.amount_out),
+        Err(e) => {
+            debug!("Failed to estimate value of token {token_identifier}: {e:?}");
+            None
+        }
+    }
+}
+
+/// Converts the full balance of a token to sats. Returns `None` when the
+/// balance is below the minimum conversion amount. The conversion spends the
+/// token, so it is subject to the wallet freeze and the token's spending caps.
+async fn sweep_token(
+    sdk: &BreezSdk,
+    token_identifier: &str,
+    amount: u128,
+) -> Result<Option<TokenConversionResponse>, SdkError> {
+    sdk.ensure_not_frozen().await?;
+    let _reservation = sdk
+        .spending_caps
+        .reserve(CappedSend {
+            rail: Some(PaymentRail::Spark),
+            token_identifier: Some(token_identifier.to_string()),
+            plugin: false,
+            amount,
+        })
+        .await?;
+    let options = to_bitcoin_options(token_identifier);
+    let limits = sdk
+        .token_converter
+        .fetch_limits(&FetchConversionLimitsRequest {
+            conversion_type: options.conversion_type.clone(),
+            token_identifier: Some(token_identifier.to_string()),
+        })
+        .await?;
+    if let Some(min_from) = limits.min_from_amount
+        && amount < min_from
+    {
+        debug!("Not sweeping token {token_identifier}: balance {amount} < min {min_from}");
+        return Ok(None);
+    }
+
+    info!("Sweeping {amount} of token {token_identifier} into sats");
+    let response = sdk
+        .token_converter
+        .convert(
+            sdk.event_emitter.clone(),
+            &options,
+            &ConversionPurpose::AutoConversion,
+            Some(&token_identifier.to_string()),
+            ConversionAmount::AmountIn(amount),
+            None,
+        )
+        .await?;
+
+    // Link the sent payment as child of the received one, as for other
+    // auto-conversions
+    sdk.storage
+        .insert_payment_metadata(
+            response.sent_payment_id.clone(),
+            PaymentMetadata {
+                parent_payment_id: Some(response.received_payment_id.clone()),
+                ..Default::default()
+            },
+        )
+        .await?;
+    sdk.storage
+        .insert_payment_metadata(
+            response.received_payment_id.clone(),
+            PaymentMetadata {
+                conversion_status: Some(ConversionStatus::Completed),
+                ..Default::default()
+            },
+        )
+        .await?;
+    Ok(Some(response))
+}
+
+fn to_bitcoin_options(token_identifier: &str) -> ConversionOptions {
+    ConversionOptions {
+        conversion_type: ConversionType::ToBitcoin {
+            from_token_identifier: token_identifier.to_string(),
+        },
+        max_slippage_bps: None,
+        completion_timeout_secs: None,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn policy(sweep_unrecognized_tokens: bool) -> TokenSweepPolicy {
+        TokenSweepPolicy {
+            max_balance_sat: 1_000,
+            recognized_token_identifiers: vec!["usdb".to_string()],
+            sweep_unrecognized_tokens,
+            interval_secs: 86_400,
+        }
+    }
+
+    #[test_all]
+    fn test_should_sweep_recognized_below_threshold() {
+        let policy = policy(true);
+        assert!(should_sweep(&policy, "usdb", Some(999)));
+        assert!(!should_sweep(&policy, "usdb", Some(1_000)));
+        assert!(!should_sweep(&policy, "usdb", None));
+    }
+
+    #[test_all]
+    fn test_should_sweep_unrecognized() {
+        assert!(should_sweep(&policy(true), "other", None));
+        assert!(should_sweep(&policy(true), "other", Some(50_000)));
+        assert!(!should_sweep(&policy(false), "other", Some(50_000)));
+        assert!(should_sweep(&policy(false), "other", Some(10)));
+    }
+}
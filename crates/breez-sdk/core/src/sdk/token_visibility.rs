@@ -0,0 +1,167 @@
+use std::collections::HashMap;
+
+use crate::{
+    GetInfoResponse, HideTokenRequest, TokenVisibilityConfig, UnhideTokenRequest, error::SdkError,
+    persist::ObjectCacheRepository,
+};
+
+use super::BreezSdk;
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl BreezSdk {
+    /// Hides a token from the balances returned by `get_info`, for example an
+    /// unsolicited spam token. The token is still listed with
+    /// [`GetInfoRequest::include_hidden`](crate::GetInfoRequest::include_hidden).
+    pub async fn hide_token(&self, request: HideTokenRequest) -> Result<(), SdkError> {
+        self.set_token_hidden(request.token_identifier, true).await
+    }
+
+    /// Lists a token in the balances returned by `get_info` again, also when
+    /// it's hidden by [`Config::token_visibility`](crate::Config::token_visibility).
+    pub async fn unhide_token(&self, request: UnhideTokenRequest) -> Result<(), SdkError> {
+        self.set_token_hidden(request.token_identifier, false).await
+    }
+}
+
+impl BreezSdk {
+    async fn set_token_hidden(
+        &self,
+        token_identifier: String,
+        hidden: bool,
+    ) -> Result<(), SdkError> {
+        if token_identifier.trim().is_empty() {
+            return Err(SdkError::InvalidInput(
+                "Token identifier can't be empty".to_string(),
+            ));
+        }
+        let cache = ObjectCacheRepository::new(self.storage.clone());
+        let mut visibility = cache.fetch_token_visibility().await?;
+        visibility.insert(token_identifier, hidden);
+        cache.save_token_visibility(&visibility).await?;
+        Ok(())
+    }
+
+    /// Sets the hidden tokens of `info`, and leaves their balances out unless
+    /// `include_hidden` is set
+    pub(super) async fn apply_token_visibility(
+        &self,
+        info: &mut GetInfoResponse,
+        include_hidden: bool,
+    ) -> Result<(), SdkError> {
+        let visibility = ObjectCacheRepository::new(self.storage.clone())
+            .fetch_token_visibility()
+            .await?;
+        apply_token_visibility(
+            info,
+            self.config.token_visibility.as_ref(),
+            &visibility,
+            include_hidden,
+        );
+        Ok(())
+    }
+}
+
+fn apply_token_visibility(
+    info: &mut GetInfoResponse,
+    config: Option<&TokenVisibilityConfig>,
+    visibility: &HashMap<String, bool>,
+    include_hidden: bool,
+) {
+    let mut hidden_tokens: Vec<String> = info
+        .token_balances
+        .keys()
+        .filter(|token_identifier| {
+            visibility
+                .get(*token_identifier)
+                .copied()
+                .unwrap_or_else(|| config.is_some_and(|config| config.hides(token_identifier)))
+        })
+        .cloned()
+        .collect();
+    hidden_tokens.sort();
+    if !include_hidden {
+        info.token_balances
+            .retain(|token_identifier, _| !hidden_tokens.contains(token_identifier));
+    }
+    info.hidden_tokens = hidden_tokens;
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+    use crate::{LeafStats, TokenBalance, TokenMetadata};
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn info(token_identifiers: &[&str]) -> GetInfoResponse {
+        GetInfoResponse {
+            identity_pubkey: "pubkey".to_string(),
+            balance_sats: 0,
+            token_balances: token_identifiers
+                .iter()
+                .map(|id| {
+                    (
+                        (*id).to_string(),
+                        TokenBalance {
+                            balance: 1,
+                            token_metadata: TokenMetadata {
+                                identifier: (*id).to_string(),
+                                issuer_public_key: "issuer".to_string(),
+                                name: "Token".to_string(),
+                                ticker: "TKN".to_string(),
+                                decimals: 0,
+                                max_supply: 1,
+                                is_freezable: false,
+                            },
+                        },
+                    )
+                })
+                .collect(),
+            hidden_tokens: Vec::new(),
+            leaf_stats: LeafStats::default(),
+            claim_queue_depth: 0,
+            wallet_frozen: false,
+            plugin_balances: Vec::new(),
+        }
+    }
+
+    fn listed(info: &GetInfoResponse) -> Vec<String> {
+        let mut listed: Vec<String> = info.token_balances.keys().cloned().collect();
+        listed.sort();
+        listed
+    }
+
+    #[test_all]
+    fn test_user_hidden_tokens_are_left_out() {
+        let mut info = info(&["a", "spam"]);
+        let visibility = HashMap::from([("spam".to_string(), true)]);
+        apply_token_visibility(&mut info, None, &visibility, false);
+        assert_eq!(listed(&info), vec!["a"]);
+        assert_eq!(info.hidden_tokens, vec!["spam"]);
+    }
+
+    #[test_all]
+    fn test_config_lists_apply_unless_unhidden() {
+        let config = TokenVisibilityConfig {
+            allowlist: Some(vec!["a".to_string(), "b".to_string()]),
+            denylist: vec!["b".to_string()],
+        };
+        let mut info = info(&["a", "b", "c", "d"]);
+        let visibility = HashMap::from([("d".to_string(), false)]);
+        apply_token_visibility(&mut info, Some(&config), &visibility, false);
+        assert_eq!(listed(&info), vec!["a", "d"]);
+        assert_eq!(info.hidden_tokens, vec!["b", "c"]);
+    }
+
+    #[test_all]
+    fn test_include_hidden_keeps_all_balances() {
+        let mut info = info(&["a", "spam"]);
+        let visibility = HashMap::from([("spam".to_string(), true)]);
+        apply_token_visibility(&mut info, None, &visibility, true);
+        assert_eq!(listed(&info), vec!["a", "spam"]);
+        assert_eq!(info.hidden_tokens, vec!["spam"]);
+    }
+}
@@ -15,17 +15,20 @@ use spark_wallet::{
     is_ephemeral_anchor_output, next_chain_queries,
 };
 
-use tracing::{debug, trace, warn};
+use tracing::{debug, error, info, trace, warn};
 
 use crate::{
     chain::{BitcoinChainService, Outspend},
     error::SdkError,
+    events::SdkEvent,
     models::{
         ConfirmationStatus, CpfpFundingKind, CpfpInput as ModelCpfpInput, ExitLeafSelection,
-        PerBranchFunding, PrepareUnilateralExitRequest, PrepareUnilateralExitResponse,
-        UnilateralExitLeaf, UnilateralExitRequest, UnilateralExitResponse,
-        UnilateralExitTransaction, UnilateralExitTxKind,
+        GetUnilateralExitProgressResponse, PerBranchFunding, PrepareUnilateralExitRequest,
+        PrepareUnilateralExitResponse, UnilateralExitLeaf, UnilateralExitLeafProgress,
+        UnilateralExitLeafStage, UnilateralExitProgress, UnilateralExitRequest,
+        UnilateralExitResponse, UnilateralExitTransaction, UnilateralExitTxKind,
     },
+    persist::ObjectCacheRepository,
     signer::CpfpSigner,
 };
 
@@ -135,8 +138,12 @@ impl BreezSdk {
 
     /// Builds and signs a complete unilateral exit from a `prepare_unilateral_exit`
     /// quote and the actual funding UTXOs, returning the full transaction set in
-    /// topological broadcast order without broadcasting. Broadcast it over time,
-    /// respecting each transaction's `depends_on` and `csv_timelock_blocks`.
+    /// topological broadcast order. The exit is persisted and broadcast over
+    /// time during sync: each transaction goes out once its `depends_on`
+    /// confirmed and its `csv_timelock_blocks` matured, so the exit resumes
+    /// after a restart. Broadcasting a transaction with a CPFP child needs
+    /// [`BitcoinChainService::broadcast_package`]; otherwise broadcast those
+    /// yourself from the returned set.
     ///
     /// It resolves on-chain state first (see [`resolve_exit_observations`]): an
     /// already-confirmed fan-out or CPFP node is not rebuilt, and a leaf refund
@@ -149,6 +156,7 @@ impl BreezSdk {
         request: UnilateralExitRequest,
         signer: Arc<dyn CpfpSigner>,
     ) -> Result<UnilateralExitResponse, SdkError> {
+        self.ensure_not_frozen().await?;
         let UnilateralExitRequest {
             prepared,
             funding_inputs,
@@ -308,12 +316,14 @@ impl BreezSdk {
         // is on-chain yet. A later run sweeps any refund that surfaces.
         if build.refund_outputs.is_empty() {
             debug!("unilateral_exit: no refund outputs to sweep, omitting the sweep");
-            return Ok(UnilateralExitResponse {
+            let response = UnilateralExitResponse {
                 recoverable_value_sat,
                 total_fee_sat: build_fee_sat,
                 leaves,
                 transactions,
-            });
+            };
+            self.track_unilateral_exit(&prepared, &response).await?;
+            return Ok(response);
         }
 
         let refund_txids: Vec<String> = build
@@ -355,15 +365,267 @@ impl BreezSdk {
             transactions = transactions.len(),
             recoverable_value_sat, total_fee_sat, "unilateral_exit: complete"
         );
-        Ok(UnilateralExitResponse {
+        let response = UnilateralExitResponse {
             recoverable_value_sat,
             total_fee_sat,
             leaves,
             transactions,
+        };
+        self.track_unilateral_exit(&prepared, &response).await?;
+        Ok(response)
+    }
+
+    /// Returns the exit last built with [`BreezSdk::unilateral_exit`], with the
+    /// status of its transactions and the stage of each leaf updated from the
+    /// chain.
+    ///
+    /// The exit is persisted when built, so after a restart the transactions
+    /// still to broadcast can be read from here rather than from the original
+    /// response. Rebuilding the exit, e.g. at a higher fee, replaces it.
+    pub async fn get_unilateral_exit_progress(
+        &self,
+    ) -> Result<GetUnilateralExitProgressResponse, SdkError> {
+        let cache = ObjectCacheRepository::new(self.storage.clone());
+        let Some(progress) = cache.fetch_unilateral_exit().await? else {
+            return Ok(GetUnilateralExitProgressResponse { progress: None });
+        };
+        let (progress, _, _) = self.refresh_unilateral_exit(progress).await?;
+        Ok(GetUnilateralExitProgressResponse {
+            progress: Some(progress),
         })
     }
 }
 
+impl BreezSdk {
+    /// Persists a built exit so that its progress can be tracked.
+    async fn track_unilateral_exit(
+        &self,
+        prepared: &PrepareUnilateralExitResponse,
+        response: &UnilateralExitResponse,
+    ) -> Result<(), SdkError> {
+        let leaves = response
+            .leaves
+            .iter()
+            .map(|leaf| UnilateralExitLeafProgress {
+                leaf_id: leaf.leaf_id.clone(),
+                value: leaf.value,
+                stage: leaf_stage(&leaf.leaf_id, &response.transactions),
+            })
+            .collect();
+        let progress = UnilateralExitProgress {
+            destination: prepared.destination.clone(),
+            fee_rate_sat_per_vbyte: prepared.fee_rate_sat_per_vbyte,
+            leaves,
+            transactions: response.transactions.clone(),
+            built_at: self.now()?,
+        };
+        ObjectCacheRepository::new(self.storage.clone())
+            .save_unilateral_exit(&progress)
+            .await?;
+        Ok(())
+    }
+
+    /// Drives the tracked exit forward: broadcasts each transaction whose
+    /// dependencies confirmed and that the chain does not know yet, and emits
+    /// `UnilateralExitProgress` for each leaf that moved to a new stage. Called
+    /// during sync, so the exit resumes from its persisted state after a
+    /// restart.
+    pub(super) async fn check_unilateral_exit_progress(&self) {
+        let result: Result<(), SdkError> = async {
+            let cache = ObjectCacheRepository::new(self.storage.clone());
+            let Some(progress) = cache.fetch_unilateral_exit().await? else {
+                return Ok(());
+            };
+            if progress
+                .leaves
+                .iter()
+                .all(|leaf| leaf.stage == UnilateralExitLeafStage::Swept)
+            {
+                return Ok(());
+            }
+            let (progress, advanced, unseen) = self.refresh_unilateral_exit(progress).await?;
+            self.broadcast_ready_exit_transactions(&progress, &unseen)
+                .await;
+            for leaf in advanced {
+                info!(
+                    "Unilateral exit of leaf {} is {:?}",
+                    leaf.leaf_id, leaf.stage
+                );
+                self.event_emitter
+                    .emit(&SdkEvent::UnilateralExitProgress { leaf })
+                    .await;
+            }
+            Ok(())
+        }
+        .await;
+        if let Err(e) = result {
+            error!("Failed to check unilateral exit progress: {e:?}");
+        }
+    }
+
+    /// Broadcasts the exit transactions the chain does not know yet once all
+    /// their dependencies confirmed. A transaction with a CPFP child goes out
+    /// as a package with it. A failed broadcast, e.g. while a CSV timelock has
+    /// not matured, is retried on the next sync.
+    async fn broadcast_ready_exit_transactions(
+        &self,
+        progress: &UnilateralExitProgress,
+        unseen: &[String],
+    ) {
+        let confirmed = |txid: &String| {
+            progress
+                .transactions
+                .iter()
+                .any(|tx| &tx.txid == txid && tx.status == ConfirmationStatus::Confirmed)
+        };
+        for tx in &progress.transactions {
+            if !unseen.contains(&tx.txid) {
+                continue;
+            }
+            // A dependency outside the set was already on-chain when the exit
+            // was built.
+            let ready = tx.depends_on.iter().all(|txid| {
+                confirmed(txid)
+                    || progress
+                        .transactions
+                        .iter()
+                        .all(|other| &other.txid != txid)
+            });
+            if !ready {
+                continue;
+            }
+            let result = match &tx.cpfp_tx_hex {
+                Some(cpfp_tx_hex) => {
+                    self.chain_service
+                        .broadcast_package(vec![tx.tx_hex.clone(), cpfp_tx_hex.clone()])
+                        .await
+                }
+                None => {
+                    self.chain_service
+                        .broadcast_transaction(tx.tx_hex.clone())
+                        .await
+                }
+            };
+            match result {
+                Ok(()) => {
+                    info!("Broadcast unilateral exit tx {} ({:?})", tx.txid, tx.kind);
+                    if let Err(e) = self.monitor_exit_transaction(tx).await {
+                        error!("Failed to monitor unilateral exit tx {}: {e:?}", tx.txid);
+                    }
+                }
+                Err(e) => debug!(
+                    "Unilateral exit tx {} ({:?}) not broadcast yet: {e:?}",
+                    tx.txid, tx.kind
+                ),
+            }
+        }
+    }
+
+    /// Updates the status of the unconfirmed transactions from the chain and
+    /// returns the exit along with the leaves that moved to a new stage and
+    /// the txids the chain does not know.
+    async fn refresh_unilateral_exit(
+        &self,
+        mut progress: UnilateralExitProgress,
+    ) -> Result<
+        (
+            UnilateralExitProgress,
+            Vec<UnilateralExitLeafProgress>,
+            Vec<String>,
+        ),
+        SdkError,
+    > {
+        let mut changed = false;
+        let mut unseen = Vec::new();
+        for tx in &mut progress.transactions {
+            if tx.status == ConfirmationStatus::Confirmed {
+                continue;
+            }
+            match self
+                .chain_service
+                .get_transaction_status(tx.txid.clone())
+                .await
+            {
+                Ok(status) if status.confirmed => {
+                    tx.status = ConfirmationStatus::Confirmed;
+                    changed = true;
+                }
+                Ok(_) => {}
+                Err(e) => {
+                    debug!("Exit tx {} not found on chain: {e:?}", tx.txid);
+                    unseen.push(tx.txid.clone());
+                }
+            }
+        }
+        if !changed {
+            return Ok((progress, Vec::new(), unseen));
+        }
+
+        let mut advanced = Vec::new();
+        for leaf in &mut progress.leaves {
+            let stage = leaf_stage(&leaf.leaf_id, &progress.transactions);
+            if stage != leaf.stage {
+                leaf.stage = stage;
+                advanced.push(leaf.clone());
+            }
+        }
+        ObjectCacheRepository::new(self.storage.clone())
+            .save_unilateral_exit(&progress)
+            .await?;
+        Ok((progress, advanced, unseen))
+    }
+}
+
+/// The stage of a leaf, from the confirmation status of its node and refund
+/// transactions and of the sweep.
+fn leaf_stage(
+    leaf_id: &str,
+    transactions: &[UnilateralExitTransaction],
+) -> UnilateralExitLeafStage {
+    let confirmed = |tx: &UnilateralExitTransaction| tx.status == ConfirmationStatus::Confirmed;
+    let leaf_tx = |kind| {
+        transactions
+            .iter()
+            .find(|tx| tx.kind == kind && tx.node_id.as_deref() == Some(leaf_id))
+    };
+    let Some(refund) = leaf_tx(UnilateralExitTxKind::Refund) else {
+        // The refund was already on-chain when the exit was built, so the sweep
+        // spends it directly. Which of the sweep's inputs is this leaf's refund
+        // is not recorded: the leaf is swept once a confirmed sweep spends a
+        // refund from outside the set, and pending until then.
+        let swept = transactions.iter().any(|tx| {
+            tx.kind == UnilateralExitTxKind::Sweep
+                && confirmed(tx)
+                && tx
+                    .depends_on
+                    .iter()
+                    .any(|txid| transactions.iter().all(|other| &other.txid != txid))
+        });
+        return if swept {
+            UnilateralExitLeafStage::Swept
+        } else {
+            UnilateralExitLeafStage::Pending
+        };
+    };
+    if confirmed(refund) {
+        let swept = transactions.iter().any(|tx| {
+            tx.kind == UnilateralExitTxKind::Sweep
+                && confirmed(tx)
+                && tx.depends_on.contains(&refund.txid)
+        });
+        return if swept {
+            UnilateralExitLeafStage::Swept
+        } else {
+            UnilateralExitLeafStage::RefundConfirmed
+        };
+    }
+    // Without a node transaction the leaf was already on-chain
+    match leaf_tx(UnilateralExitTxKind::Node) {
+        Some(node) if !confirmed(node) => UnilateralExitLeafStage::Pending,
+        _ => UnilateralExitLeafStage::LeafConfirmed,
+    }
+}
+
 /// The sweep's fee: total input value minus output value.
 fn sweep_fee(sweep_psbt: &bitcoin::Psbt) -> u64 {
     let in_value: u64 = sweep_psbt
@@ -879,4 +1141,69 @@ mod tests {
         let result = sign_psbt_via(unsigned_two_input_psbt(), &PartialSigner { finalize: 2 }).await;
         assert!(result.is_ok());
     }
+
+    fn exit_tx(
+        kind: UnilateralExitTxKind,
+        node_id: Option<&str>,
+        txid: &str,
+        depends_on: &[&str],
+        status: ConfirmationStatus,
+    ) -> UnilateralExitTransaction {
+        UnilateralExitTransaction {
+            kind,
+            node_id: node_id.map(str::to_string),
+            txid: txid.to_string(),
+            tx_hex: String::new(),
+            cpfp_tx_hex: None,
+            csv_timelock_blocks: None,
+            depends_on: depends_on.iter().map(|d| (*d).to_string()).collect(),
+            status,
+        }
+    }
+
+    #[test]
+    fn leaf_stage_follows_confirmations() {
+        use ConfirmationStatus::{Confirmed, Unconfirmed};
+        use UnilateralExitTxKind::{Node, Refund, Sweep};
+
+        let txs = |node, refund, sweep| {
+            vec![
+                exit_tx(Node, Some("leaf"), "node", &[], node),
+                exit_tx(Refund, Some("leaf"), "refund", &["node"], refund),
+                exit_tx(Sweep, None, "sweep", &["refund"], sweep),
+            ]
+        };
+        assert_eq!(
+            leaf_stage("leaf", &txs(Unconfirmed, Unconfirmed, Unconfirmed)),
+            UnilateralExitLeafStage::Pending
+        );
+        assert_eq!(
+            leaf_stage("leaf", &txs(Confirmed, Unconfirmed, Unconfirmed)),
+            UnilateralExitLeafStage::LeafConfirmed
+        );
+        assert_eq!(
+            leaf_stage("leaf", &txs(Confirmed, Confirmed, Unconfirmed)),
+            UnilateralExitLeafStage::RefundConfirmed
+        );
+        assert_eq!(
+            leaf_stage("leaf", &txs(Confirmed, Confirmed, Confirmed)),
+            UnilateralExitLeafStage::Swept
+        );
+        // No refund tx and no sweep of an on-chain refund: not known to be swept
+        assert_eq!(
+            leaf_stage("other", &txs(Confirmed, Confirmed, Confirmed)),
+            UnilateralExitLeafStage::Pending
+        );
+        // The refund was on-chain when the exit was built: swept with the sweep
+        let onchain_refund =
+            |sweep| vec![exit_tx(Sweep, None, "sweep", &["onchain_refund"], sweep)];
+        assert_eq!(
+            leaf_stage("leaf", &onchain_refund(Unconfirmed)),
+            UnilateralExitLeafStage::Pending
+        );
+        assert_eq!(
+            leaf_stage("leaf", &onchain_refund(Confirmed)),
+            UnilateralExitLeafStage::Swept
+        );
+    }
 }
@@ -0,0 +1,112 @@
+use tracing::{info, warn};
+
+use crate::{
+    DeletePaymentLinkRequest, DeleteWalletDataResponse, UnregisterWebhookRequest, error::SdkError,
+    lnurl::UnregisterLightningAddressRequest, persist::ObjectCacheRepository,
+};
+
+use super::BreezSdk;
+
+#[cfg_attr(feature = "uniffi", uniffi::export(async_runtime = "tokio"))]
+impl BreezSdk {
+    /// Deletes the data of the wallet, for a user asking to be forgotten.
+    ///
+    /// The lightning addresses, the payment links and the webhooks are removed
+    /// from the servers first. Every removal is attempted, and if any of them
+    /// fails the call fails naming them, before anything local is deleted, so
+    /// it can be retried. The SDK is then disconnected and its storage wiped, after
+    /// which this instance must not be used anymore.
+    ///
+    /// The funds are not affected: they remain recoverable from the mnemonic.
+    /// The real-time sync server offers no way to delete records, so records
+    /// previously synced across devices are kept there, encrypted so that
+    /// only the mnemonic can decrypt them. The response reports whether that
+    /// is the case.
+    pub async fn delete_wallet_data(&self) -> Result<DeleteWalletDataResponse, SdkError> {
+        let mut failed_deletions = Vec::new();
+
+        let has_lightning_address = ObjectCacheRepository::new(self.storage.clone())
+            .fetch_lightning_address()
+            .await?
+            .flatten()
+            .is_some();
+        let lightning_address_deleted = has_lightning_address
+            && match self.delete_lightning_address().await {
+                Ok(()) => true,
+                Err(e) => {
+                    failed_deletions.push(format!("lightning address: {e}"));
+                    false
+                }
+            };
+
+        let mut labeled_lightning_addresses_deleted = 0u32;
+        if let Some(client) = &self.lnurl_server_client {
+            let addresses = client.list_lightning_addresses().await?.addresses;
+            for address in addresses.into_iter().filter(|a| a.label.is_some()) {
+                match client
+                    .unregister_lightning_address(&UnregisterLightningAddressRequest {
+                        username: address.username,
+                    })
+                    .await
+                {
+                    Ok(()) => {
+                        labeled_lightning_addresses_deleted =
+                            labeled_lightning_addresses_deleted.saturating_add(1);
+                    }
+                    Err(e) => failed_deletions.push(format!(
+                        "lightning address {}: {e}",
+                        address.lightning_address
+                    )),
+                }
+            }
+        }
+
+        let mut payment_links_deleted = 0u32;
+        for id in self.payment_link_ids().await? {
+            match self
+                .delete_payment_link(DeletePaymentLinkRequest { id: id.clone() })
+                .await
+            {
+                Ok(()) => payment_links_deleted = payment_links_deleted.saturating_add(1),
+                Err(e) => failed_deletions.push(format!("payment link {id}: {e}")),
+            }
+        }
+
+        let mut webhooks_deleted = 0u32;
+        for webhook in self.list_webhooks().await? {
+            match self
+                .unregister_webhook(UnregisterWebhookRequest {
+                    webhook_id: webhook.id.clone(),
+                })
+                .await
+            {
+                Ok(()) => webhooks_deleted = webhooks_deleted.saturating_add(1),
+                Err(e) => failed_deletions.push(format!("webhook {}: {e}", webhook.id)),
+            }
+        }
+
+        if !failed_deletions.is_empty() {
+            warn!("Failed to delete remote wallet data: {failed_deletions:?}");
+            return Err(SdkError::Generic(format!(
+                "Failed to delete remote wallet data, nothing local was deleted: {}",
+                failed_deletions.join("; ")
+            )));
+        }
+
+        // Stop the background tasks first, so that syncing doesn't write the
+        // data back after it is deleted
+        self.disconnect().await?;
+        self.storage.delete_all_data().await?;
+
+        info!(
+            "Deleted wallet data, {payment_links_deleted} payment links and {webhooks_deleted} webhooks"
+        );
+        Ok(DeleteWalletDataResponse {
+            lightning_address_deleted,
+            labeled_lightning_addresses_deleted,
+            payment_links_deleted,
+            webhooks_deleted,
+            sync_records_kept: self.config.real_time_sync_server_url.is_some(),
+        })
+    }
+}
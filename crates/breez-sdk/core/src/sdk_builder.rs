@@ -2,8 +2,9 @@
     all(target_family = "wasm", target_os = "unknown"),
     allow(clippy::arc_with_non_send_sync)
 )]
-use std::sync::Arc;
+use std::{str::FromStr, sync::Arc};
 
+use bitcoin::secp256k1::PublicKey;
 use breez_sdk_common::breez_server::BreezServer;
 use breez_sdk_common::buy::moonpay::MoonpayProvider;
 
@@ -13,28 +14,36 @@ use spark_wallet::{
 use tokio::sync::watch;
 use tracing::{debug, info};
 
-use flashnet::{FlashnetConfig, IntegratorConfig};
+use flashnet::{CacheStore, FlashnetClient, FlashnetConfig, IntegratorConfig};
 
 use crate::{
-    Credentials, EventEmitter, FiatService, FiatServiceWrapper, Network, Seed,
+    Clock, Credentials, DuressConfig, EventEmitter, FiatService, FiatServiceWrapper,
+    IssuerApprovalConfig, IssuerApprovalSigner, Network, Seed, TokenPriceService,
     chain::{
         BitcoinChainService,
         rest_client::{BasicAuth, ChainApiType, RestClientChainService},
     },
     error::SdkError,
+    input_parser::InputParser,
+    issuer::IssuerApprover,
     lnurl::{DefaultLnurlServerClient, LnurlServerClient},
     models::Config,
-    payment_observer::{PaymentObserver, SparkTransferObserver},
+    payment_observer::{PaymentObserver, SendApprover, SparkTransferObserver},
     persist::backend::{ResolvedStores, StorageBackend},
-    realtime_sync::{RealTimeSyncParams, init_and_start_real_time_sync},
-    sdk::{BreezSdk, BreezSdkParams, SyncCoordinator, runtime_from_config},
+    plugin::SdkPlugin,
+    realtime_sync::{RealTimeSyncParams, RealTimeSyncTransport, init_and_start_real_time_sync},
+    sdk::{
+        BreezSdk, BreezSdkParams, InputParserRegistry, PluginRegistry, SeedBackup, SyncCoordinator,
+        claiming_runtime, runtime_from_config,
+    },
     sdk_context::{SdkContext, SdkContextConfig, new_shared_sdk_context},
     signer::{breez::BreezSignerImpl, lnurl_auth::LnurlAuthSignerAdapter, rtsync::RTSyncSigner},
     stable_balance::StableBalance,
     token_conversion::TokenConversionMiddleware,
     token_conversion::{
+        ConversionPriceSource, ConversionPriceVerifier, DEFAULT_CONVERSION_MAX_PRICE_DEVIATION_BPS,
         DEFAULT_INTEGRATOR_FEE_BPS, DEFAULT_INTEGRATOR_PUBKEY, FlashnetTokenConverter,
-        TokenConverter,
+        FlashnetTokenPriceService, TokenConverter,
     },
 };
 
@@ -78,6 +87,7 @@ enum SignerSource {
 /// encryption, cross-chain) and on HMAC (`lnurl_auth`) are then absent too.
 struct Signers {
     ecies: Option<Arc<dyn crate::signer::EciesSigner>>,
+    hmac: Option<Arc<dyn crate::signer::HmacSigner>>,
     spark: Arc<dyn SparkSigner>,
     rtsync: Option<Arc<RTSyncSigner>>,
     lnurl_auth: Option<Arc<LnurlAuthSignerAdapter>>,
@@ -96,6 +106,7 @@ struct BuildSparkWalletParams {
     tree_store: Option<Arc<dyn spark_wallet::TreeStore>>,
     token_output_store: Option<Arc<dyn spark_wallet::TokenOutputStore>>,
     payment_observer: Option<Arc<dyn PaymentObserver>>,
+    send_approver: Option<Arc<dyn SendApprover>>,
     context: Arc<SdkContext>,
 }
 
@@ -111,9 +122,33 @@ pub struct SdkBuilder {
     rest_chain_service_config: Option<RestChainServiceConfig>,
     fiat_service: Option<Arc<dyn FiatService>>,
     lnurl_client: Option<Arc<dyn platform_utils::HttpClient>>,
+    backup_client: Option<Arc<dyn platform_utils::HttpClient>>,
     lnurl_server_client: Option<Arc<dyn LnurlServerClient>>,
     payment_observer: Option<Arc<dyn PaymentObserver>>,
+    send_approver: Option<Arc<dyn SendApprover>>,
+    plugins: Vec<Arc<dyn SdkPlugin>>,
+    input_parsers: Vec<Arc<dyn InputParser>>,
+    clock: Option<Arc<dyn Clock>>,
+    /// Whether the periodic background sync starts paused, see
+    /// `with_background_sync_paused`
+    background_sync_paused: bool,
+    conversion_price_source: Option<Arc<dyn ConversionPriceSource>>,
+    token_price_service: Option<Arc<dyn TokenPriceService>>,
+    /// Decoy account number of the duress configuration, and whether the
+    /// duress PIN was entered, see `with_duress`
+    duress: Option<(u32, bool)>,
+    /// Key approving issuer operations, see `with_issuer_approver`
+    issuer_approver: Option<(Arc<dyn IssuerApprovalSigner>, IssuerApprovalConfig)>,
     context: Option<Arc<SdkContext>>,
+    /// Whether to start only the claiming machinery, see `connect_for_claiming`
+    claiming_only: bool,
+    /// Relays real-time sync runs over, see `with_nostr_sync_storage`
+    #[cfg(feature = "nostr-sync")]
+    nostr_sync_relays: Option<Vec<String>>,
+    /// Address and access token of the embedded RPC service, see
+    /// `with_rpc_server`
+    #[cfg(feature = "rpc-server")]
+    rpc_server: Option<(String, String)>,
 }
 
 impl SdkBuilder {
@@ -138,9 +173,24 @@ impl SdkBuilder {
             rest_chain_service_config: None,
             fiat_service: None,
             lnurl_client: None,
+            backup_client: None,
             lnurl_server_client: None,
             payment_observer: None,
+            send_approver: None,
+            plugins: Vec::new(),
+            input_parsers: Vec::new(),
+            clock: None,
+            background_sync_paused: false,
+            conversion_price_source: None,
+            token_price_service: None,
+            duress: None,
+            issuer_approver: None,
             context: None,
+            claiming_only: false,
+            #[cfg(feature = "nostr-sync")]
+            nostr_sync_relays: None,
+            #[cfg(feature = "rpc-server")]
+            rpc_server: None,
         }
     }
 
@@ -201,12 +251,36 @@ impl SdkBuilder {
             rest_chain_service_config: None,
             fiat_service: None,
             lnurl_client: None,
+            backup_client: None,
             lnurl_server_client: None,
             payment_observer: None,
+            send_approver: None,
+            plugins: Vec::new(),
+            input_parsers: Vec::new(),
+            clock: None,
+            background_sync_paused: false,
+            conversion_price_source: None,
+            token_price_service: None,
+            duress: None,
+            issuer_approver: None,
             context: None,
+            claiming_only: false,
+            #[cfg(feature = "nostr-sync")]
+            nostr_sync_relays: None,
+            #[cfg(feature = "rpc-server")]
+            rpc_server: None,
         }
     }
 
+    /// Starts only the receive and claim machinery instead of the runtime
+    /// chosen by the config.
+    #[must_use]
+    #[cfg_attr(not(feature = "sqlite"), allow(dead_code))]
+    pub(crate) fn for_claiming(mut self) -> Self {
+        self.claiming_only = true;
+        self
+    }
+
     /// Sets the account number for key derivation. All wallet keys derive from
     /// the seed at `m/8797555'/<account number>'`, so each account number
     /// yields an independent wallet from the same seed.
@@ -231,6 +305,31 @@ impl SdkBuilder {
         self
     }
 
+    /// Opens the decoy wallet of `duress_config` if `pin` is its duress PIN.
+    /// Any other PIN opens the real wallet, so the app can pass whichever PIN
+    /// the user entered.
+    ///
+    /// The decoy wallet is derived from the same seed at the decoy account
+    /// number and keeps its data apart from the real wallet, so nothing of
+    /// the real wallet is visible while the decoy is open. The decoy account
+    /// number is applied on build, so it takes precedence over
+    /// [`SdkBuilder::with_account_number`] whichever is called last, and
+    /// building fails if it is the account number of the real wallet.
+    ///
+    /// Note: Like [`SdkBuilder::with_account_number`], this only applies when
+    /// using a seed-based signer.
+    ///
+    /// # Arguments
+    /// - `duress_config`: The duress configuration.
+    /// - `pin`: The PIN the user entered.
+    #[must_use]
+    #[allow(clippy::needless_pass_by_value)]
+    pub fn with_duress(mut self, duress_config: DuressConfig, pin: String) -> Self {
+        let duress_pin_entered = crate::is_duress_pin(duress_config.clone(), pin);
+        self.duress = Some((duress_config.decoy_account_number, duress_pin_entered));
+        self
+    }
+
     #[cfg(feature = "sqlite")]
     #[must_use]
     /// Sets the root storage directory to initialize the default storage with.
@@ -373,6 +472,54 @@ impl SdkBuilder {
         self
     }
 
+    /// Sets the service providing the fiat prices of tokens, used by
+    /// `get_token_fiat_rates` and to record the fiat value of token payments.
+    /// Arguments:
+    /// - `token_price_service`: The token price service to be used.
+    #[must_use]
+    pub fn with_token_price_service(
+        mut self,
+        token_price_service: Arc<dyn TokenPriceService>,
+    ) -> Self {
+        self.token_price_service = Some(token_price_service);
+        self
+    }
+
+    /// Requires mints, burns, freezes and unfreezes of the issuer token to be
+    /// approved by an additional key, so day-to-day issuer operations can be
+    /// separated from the issuance key. Before each operation the approval
+    /// signer is given the operation and signs it with the key at
+    /// `config.derivation_path`, and the operation fails with
+    /// `SdkError::IssuerApprovalFailed` unless the signature matches
+    /// `config.public_key`.
+    ///
+    /// The approval is checked by the SDK only. Anyone holding the seed can
+    /// still issue without it, so it doesn't replace protecting the seed.
+    /// Arguments:
+    /// - `approval_signer`: The signer holding the approval key.
+    /// - `config`: The approval key to expect.
+    #[must_use]
+    pub fn with_issuer_approver(
+        mut self,
+        approval_signer: Arc<dyn IssuerApprovalSigner>,
+        config: IssuerApprovalConfig,
+    ) -> Self {
+        self.issuer_approver = Some((approval_signer, config));
+        self
+    }
+
+    /// Sets the external price source used to verify token conversion quotes.
+    /// Arguments:
+    /// - `conversion_price_source`: The price source to check conversion rates against.
+    #[must_use]
+    pub fn with_conversion_price_source(
+        mut self,
+        conversion_price_source: Arc<dyn ConversionPriceSource>,
+    ) -> Self {
+        self.conversion_price_source = Some(conversion_price_source);
+        self
+    }
+
     #[must_use]
     pub fn with_lnurl_client(mut self, lnurl_client: Arc<dyn crate::RestClient>) -> Self {
         self.lnurl_client = Some(Arc::new(crate::common::rest::RestClientWrapper::new(
@@ -381,6 +528,18 @@ impl SdkBuilder {
         self
     }
 
+    /// Sets the REST client used to upload and download state backups, such
+    /// as one authenticating with the app's blob store.
+    /// Arguments:
+    /// - `backup_client`: The REST client to be used.
+    #[must_use]
+    pub fn with_backup_client(mut self, backup_client: Arc<dyn crate::RestClient>) -> Self {
+        self.backup_client = Some(Arc::new(crate::common::rest::RestClientWrapper::new(
+            backup_client,
+        )));
+        self
+    }
+
     #[must_use]
     #[allow(unused)]
     pub fn with_lnurl_server_client(
@@ -402,6 +561,97 @@ impl SdkBuilder {
         self
     }
 
+    /// Sets the send approver to be used by the SDK.
+    /// The approver is asked to approve every outgoing payment before funds move, and can
+    /// reject it with a reason.
+    /// Arguments:
+    /// - `send_approver`: The send approver to be used.
+    #[must_use]
+    pub fn with_send_approver(mut self, send_approver: Arc<dyn SendApprover>) -> Self {
+        self.send_approver = Some(send_approver);
+        self
+    }
+
+    /// Registers a plugin that provides a custom payment rail or balance
+    /// provider. Plugins are offered payments in the order they are
+    /// registered, and each must have a unique name.
+    /// Arguments:
+    /// - `plugin`: The plugin to be registered.
+    #[must_use]
+    pub fn with_plugin(mut self, plugin: Arc<dyn SdkPlugin>) -> Self {
+        self.plugins.push(plugin);
+        self
+    }
+
+    /// Registers a parser for inputs the SDK doesn't recognize, such as
+    /// app-specific QR codes. Unlike [`Config::external_input_parsers`], the
+    /// parser receives the raw input and can resolve it with its own async
+    /// work. Parsers of the same priority run in the order they are
+    /// registered, and each must have a unique name.
+    /// Arguments:
+    /// - `parser`: The input parser to be registered.
+    #[must_use]
+    pub fn with_input_parser(mut self, parser: Arc<dyn InputParser>) -> Self {
+        self.input_parsers.push(parser);
+        self
+    }
+
+    /// Sets the clock the SDK reads the current time from when checking
+    /// expiries and recording timestamps, instead of the system clock.
+    /// Intended for tests that need to control time.
+    /// Arguments:
+    /// - `clock`: The clock to be used.
+    #[must_use]
+    pub fn with_clock(mut self, clock: Arc<dyn Clock>) -> Self {
+        self.clock = Some(clock);
+        self
+    }
+
+    /// Starts the SDK with the periodic background sync paused, e.g. when the
+    /// app is launched in the background or on a metered connection. Resume it
+    /// with [`BreezSdk::resume_background_sync`](crate::BreezSdk::resume_background_sync).
+    #[must_use]
+    pub fn with_background_sync_paused(mut self) -> Self {
+        self.background_sync_paused = true;
+        self
+    }
+
+    /// Runs real-time sync over the given Nostr relays instead of the sync
+    /// server set in [`Config::real_time_sync_server_url`], so that
+    /// multi-device sync doesn't depend on a Breez-hosted service.
+    ///
+    /// The records are stored as events encrypted with a key derived from
+    /// the seed, so the relays can't read them. All devices of the wallet
+    /// must use the same relays.
+    /// Arguments:
+    /// - `relays`: The relay URLs, e.g. `wss://relay.example.com`.
+    #[cfg(feature = "nostr-sync")]
+    #[must_use]
+    pub fn with_nostr_sync_storage(mut self, relays: Vec<String>) -> Self {
+        self.nostr_sync_relays = Some(relays);
+        self
+    }
+
+    /// Serves an embedded WebSocket RPC service on `addr` (for example
+    /// `127.0.0.1:9737`) for as long as the SDK is connected. Other processes in
+    /// the same deployment can subscribe to SDK events and call a
+    /// read-and-receive subset of the wallet API through it, without running a
+    /// second SDK instance.
+    ///
+    /// Connections must send `token` as an `Authorization: Bearer <token>`
+    /// header. The service exposes no spending calls. Bind it to a loopback or
+    /// private address only.
+    /// Arguments:
+    /// - `addr`: The socket address to listen on.
+    /// - `token`: The access token connections authenticate with. Must not be
+    ///   empty.
+    #[cfg(feature = "rpc-server")]
+    #[must_use]
+    pub fn with_rpc_server(mut self, addr: String, token: String) -> Self {
+        self.rpc_server = Some((addr, token));
+        self
+    }
+
     /// Builds a [`SparkWalletConfig`](spark_wallet::SparkWalletConfig) from a
     /// [`SparkConfig`](crate::models::SparkConfig).
     fn build_spark_wallet_config(
@@ -465,11 +715,25 @@ impl SdkBuilder {
     #[allow(clippy::too_many_lines)]
     pub async fn build(self) -> Result<BreezSdk, SdkError> {
         self.config.validate()?;
-        let runtime = runtime_from_config(&self.config);
+        let runtime = if self.claiming_only {
+            claiming_runtime()
+        } else {
+            runtime_from_config(&self.config)
+        };
         let background_services_enabled = runtime.starts_background_services();
         validate_server_mode(&self.config, background_services_enabled)?;
-
-        let signers = build_signers(&self.config, self.signer_source)?;
+        let plugins = PluginRegistry::new(self.plugins)?;
+        let input_parsers = InputParserRegistry::new(self.input_parsers)?;
+
+        let signer_source = apply_duress(self.signer_source, self.duress, self.config.network)?;
+        let seed_backup = match &signer_source {
+            SignerSource::Seed {
+                seed: Seed::Mnemonic { mnemonic, .. },
+                ..
+            } => Some(Arc::new(SeedBackup::from_mnemonic(mnemonic)?)),
+            _ => None,
+        };
+        let signers = build_signers(&self.config, signer_source)?;
         validate_signer_capabilities(&self.config, signers.ecies.is_some())?;
 
         let creates_context = self.context.is_none();
@@ -492,13 +756,34 @@ impl SdkBuilder {
         let user_agent = crate::default_user_agent();
         info!("Building sdk with user agent: {}", user_agent);
 
+        let endpoints = self.config.service_endpoints.clone().unwrap_or_default();
         let fiat_service: Arc<dyn breez_sdk_common::fiat::FiatService> = match self.fiat_service {
             Some(service) => Arc::new(FiatServiceWrapper::new(service)),
+            None => match &endpoints.fiat_rates_url {
+                Some(url) => breez_server_at(url, &user_agent)?,
+                None => context.breez_server.clone(),
+            },
+        };
+        let buy_bitcoin_server = match &endpoints.buy_bitcoin_url {
+            Some(url) => breez_server_at(url, &user_agent)?,
             None => context.breez_server.clone(),
         };
         let lnurl_client: Arc<dyn platform_utils::HttpClient> = self
             .lnurl_client
             .unwrap_or_else(|| context.http_client.clone());
+        let backup_client: Arc<dyn platform_utils::HttpClient> = self
+            .backup_client
+            .unwrap_or_else(|| context.http_client.clone());
+        let issuer_approver = self
+            .issuer_approver
+            .map(|(signer, config)| IssuerApprover::new(signer, config).map(Arc::new))
+            .transpose()?;
+
+        #[cfg(feature = "rpc-server")]
+        let rpc_server_listener = match self.rpc_server {
+            Some((addr, token)) => Some((crate::rpc_server::bind(&addr, &token).await?, token)),
+            None => None,
+        };
 
         let spark_wallet_config =
             finalize_spark_wallet_config(&self.config, &user_agent, background_services_enabled)?;
@@ -520,6 +805,7 @@ impl SdkBuilder {
             tree_store: stores.tree_store.clone(),
             token_output_store: stores.token_output_store.clone(),
             payment_observer: self.payment_observer,
+            send_approver: self.send_approver,
             context: Arc::clone(&context),
         })
         .await?;
@@ -529,27 +815,53 @@ impl SdkBuilder {
             &self.config,
             &context,
             &spark_wallet,
+            self.clock.as_ref(),
         );
 
-        let real_time_sync_active =
-            background_services_enabled && self.config.real_time_sync_server_url.is_some();
+        let real_time_sync_transport = resolve_real_time_sync_transport(
+            &self.config,
+            background_services_enabled,
+            user_agent,
+            #[cfg(feature = "nostr-sync")]
+            self.nostr_sync_relays,
+            signers.hmac.as_deref(),
+        )
+        .await?;
+        let real_time_sync_active = real_time_sync_transport.is_some();
         let event_emitter = Arc::new(EventEmitter::new(real_time_sync_active));
 
+        let background_sync_paused = Arc::new(watch::Sender::new(self.background_sync_paused));
         let storage = maybe_wrap_storage_with_real_time_sync(
             Arc::clone(&stores.storage),
-            &self.config,
-            background_services_enabled,
-            user_agent,
+            real_time_sync_transport,
             signers.rtsync,
             shutdown_sender.subscribe(),
+            background_sync_paused.subscribe(),
             Arc::clone(&event_emitter),
             lnurl_server_client.clone(),
+            self.clock.clone(),
         )
         .await?;
 
-        let buy_bitcoin_provider = Arc::new(MoonpayProvider::new(context.breez_server.clone()));
-        let token_converter =
-            build_token_converter(&self.config, &storage, &spark_wallet, &context);
+        let buy_bitcoin_provider = Arc::new(MoonpayProvider::new(buy_bitcoin_server));
+        let token_converter = build_token_converter(
+            &self.config,
+            &storage,
+            &spark_wallet,
+            &context,
+            self.conversion_price_source.clone(),
+        );
+        let token_price_service = self.token_price_service.unwrap_or_else(|| {
+            Arc::new(FlashnetTokenPriceService::new(
+                Arc::new(FlashnetClient::new(
+                    default_flashnet_config(&self.config),
+                    Arc::clone(&spark_wallet),
+                    Arc::new(CacheStore::default()),
+                    context.http_client.clone(),
+                )),
+                Arc::clone(&fiat_service),
+            ))
+        });
 
         let sync_coordinator = SyncCoordinator::new();
 
@@ -572,6 +884,7 @@ impl SdkBuilder {
             &lightning_sender,
             Arc::clone(&fiat_service),
             shutdown_sender.subscribe(),
+            self.clock.as_ref(),
         );
 
         let stable_balance = build_stable_balance(
@@ -580,6 +893,7 @@ impl SdkBuilder {
             &spark_wallet,
             &storage,
             &event_emitter,
+            self.clock.as_ref(),
         )
         .await;
 
@@ -589,14 +903,39 @@ impl SdkBuilder {
             .add_middleware(Box::new(TokenConversionMiddleware))
             .await;
 
+        // Register FiatValueMiddleware last, so it only records the value of
+        // payments that reach external listeners. Registered whether or not a
+        // currency is configured, as the display currency user setting can be
+        // set at any time, but not when only claiming, which fetches no rates.
+        if !self.claiming_only {
+            event_emitter
+                .add_middleware(Box::new(crate::sdk::FiatValueMiddleware::new(
+                    Arc::clone(&storage),
+                    Arc::clone(&fiat_service),
+                    Arc::clone(&token_price_service),
+                    self.config.payment_fiat_currency.clone(),
+                    self.clock.clone(),
+                )))
+                .await;
+        }
+
+        #[cfg(feature = "rpc-server")]
+        let rpc_server_shutdown_receiver = shutdown_sender.subscribe();
+
         let sdk = BreezSdk::init_and_start(BreezSdkParams {
             config: self.config,
             storage,
             chain_service,
             fiat_service,
+            token_price_service,
+            issuer_approver,
             lnurl_client,
+            backup_client,
+            faucet_client: context.http_client.clone(),
             lnurl_server_client,
             lnurl_auth_signer: signers.lnurl_auth,
+            application_key_signer: signers.hmac,
+            state_backup_signer: signers.ecies.clone(),
             shutdown_sender,
             runtime,
             spark_wallet,
@@ -607,10 +946,20 @@ impl SdkBuilder {
             sync_coordinator,
             cross_chain_context,
             lightning_sender,
+            seed_backup,
+            plugins,
+            input_parsers,
+            clock: self.clock,
+            background_sync_paused,
         })
         .await?;
         debug!("Initialized and started breez sdk.");
 
+        #[cfg(feature = "rpc-server")]
+        if let Some((listener, token)) = rpc_server_listener {
+            crate::rpc_server::start(sdk.clone(), listener, token, rpc_server_shutdown_receiver);
+        }
+
         Ok(sdk)
     }
 }
@@ -674,10 +1023,38 @@ fn validate_signer_capabilities(config: &Config, has_ecies: bool) -> Result<(),
     Ok(())
 }
 
+/// Switches a seed signer source to the account number resolved by
+/// [`crate::duress_account_number`]. External signers are left unchanged.
+fn apply_duress(
+    signer_source: SignerSource,
+    duress: Option<(u32, bool)>,
+    network: Network,
+) -> Result<SignerSource, SdkError> {
+    let Some((decoy_account_number, duress_pin_entered)) = duress else {
+        return Ok(signer_source);
+    };
+    match signer_source {
+        SignerSource::Seed {
+            seed,
+            account_number,
+        } => Ok(SignerSource::Seed {
+            seed,
+            account_number: crate::duress_account_number(
+                account_number,
+                decoy_account_number,
+                duress_pin_entered,
+                network,
+            )?,
+        }),
+        external @ SignerSource::External { .. } => Ok(external),
+    }
+}
+
 /// Derives the SDK-layer signers from one signer source: the Spark signer, and
-/// (when the signer can perform ECIES/HMAC) the `ecies` signer plus the
-/// real-time-sync and lnurl-auth signers. A signing-only external signer can do
-/// neither, so `ecies`, `rtsync`, and `lnurl_auth` are all left `None`.
+/// (when the signer can perform ECIES/HMAC) the `ecies` and `hmac` signers plus
+/// the real-time-sync and lnurl-auth signers. A signing-only external signer can
+/// do neither, so `ecies`, `hmac`, `rtsync`, and `lnurl_auth` are all left
+/// `None`.
 fn build_signers(config: &Config, signer_source: SignerSource) -> Result<Signers, SdkError> {
     use crate::signer::{
         BreezSigner, EciesSigner, ExternalBreezSignerAdapter, ExternalSigningSignerAdapter,
@@ -744,6 +1121,7 @@ fn build_signers(config: &Config, signer_source: SignerSource) -> Result<Signers
 
     Ok(Signers {
         ecies,
+        hmac,
         spark,
         rtsync,
         lnurl_auth,
@@ -863,17 +1241,29 @@ fn finalize_spark_wallet_config(
     user_agent: &str,
     background_services_enabled: bool,
 ) -> Result<SparkWalletConfig, SdkError> {
-    let mut spark_wallet_config = if let Some(env_config) = &config.spark_config {
-        SdkBuilder::build_spark_wallet_config(config.network.into(), env_config)?
-    } else {
-        SparkWalletConfig::default_config(config.network.into())
+    let mut spark_wallet_config = match (&config.spark_config, &config.operator_allowlist) {
+        (Some(env_config), Some(allowlist)) => SdkBuilder::build_spark_wallet_config(
+            config.network.into(),
+            &apply_operator_allowlist(env_config.clone(), allowlist)?,
+        )?,
+        (None, Some(allowlist)) => SdkBuilder::build_spark_wallet_config(
+            config.network.into(),
+            &apply_operator_allowlist(crate::sdk::default_spark_config(config.network), allowlist)?,
+        )?,
+        (Some(env_config), None) => {
+            SdkBuilder::build_spark_wallet_config(config.network.into(), env_config)?
+        }
+        (None, None) => SparkWalletConfig::default_config(config.network.into()),
     };
     spark_wallet_config.operator_pool = spark_wallet_config
         .operator_pool
         .with_user_agent(Some(user_agent.to_string()));
     spark_wallet_config.service_provider_config.user_agent = Some(user_agent.to_string());
-    spark_wallet_config.leaf_auto_optimize_enabled =
-        background_services_enabled && config.leaf_optimization_config.auto_enabled;
+    // With a policy set, the SDK starts the auto-optimizer itself once the
+    // policy allows it, see `LeafOptimizationConfig::auto_policy`
+    spark_wallet_config.leaf_auto_optimize_enabled = background_services_enabled
+        && config.leaf_optimization_config.auto_enabled
+        && config.leaf_optimization_config.auto_policy.is_none();
     spark_wallet_config.leaf_optimization_options.multiplicity =
         config.leaf_optimization_config.multiplicity;
 
@@ -886,9 +1276,71 @@ fn finalize_spark_wallet_config(
         token_options.auto_optimize_interval = None;
     }
     spark_wallet_config.max_concurrent_claims = config.max_concurrent_claims;
+    spark_wallet_config.max_claims_per_second = config.max_claims_per_second;
     Ok(spark_wallet_config)
 }
 
+/// Checks every operator and the SSP of `spark_config` against the operator
+/// allowlist, failing closed on the first mismatch. Allowed operators' CA
+/// certificates become their TLS trust roots in the returned config.
+fn apply_operator_allowlist(
+    mut spark_config: crate::models::SparkConfig,
+    allowlist: &crate::models::OperatorAllowlistConfig,
+) -> Result<crate::models::SparkConfig, SdkError> {
+    let parse_key = |key: &str| {
+        PublicKey::from_str(key).map_err(|_| {
+            SdkError::InvalidInput(format!("Invalid allowed identity public key: {key}"))
+        })
+    };
+
+    let allowed = allowlist
+        .operators
+        .iter()
+        .map(|op| parse_key(&op.identity_public_key).map(|key| (key, op)))
+        .collect::<Result<Vec<_>, _>>()?;
+
+    for operator in &mut spark_config.signing_operators {
+        let key = PublicKey::from_str(&operator.identity_public_key).map_err(|_| {
+            SdkError::OperatorNotAllowed(format!(
+                "operator {} has an invalid identity public key",
+                operator.id
+            ))
+        })?;
+        let Some((_, allowed_operator)) =
+            allowed.iter().find(|(allowed_key, _)| *allowed_key == key)
+        else {
+            return Err(SdkError::OperatorNotAllowed(format!(
+                "operator {} at {} has identity key {key} which is not in the allowlist",
+                operator.id, operator.address
+            )));
+        };
+        if let Some(address) = &allowed_operator.address
+            && address.trim_end_matches('/') != operator.address.trim_end_matches('/')
+        {
+            return Err(SdkError::OperatorNotAllowed(format!(
+                "operator {key} is configured at {} but only allowed at {address}",
+                operator.address
+            )));
+        }
+        if let Some(cert) = &allowed_operator.ca_cert_pem {
+            operator.ca_cert_pem = Some(cert.clone());
+        }
+    }
+
+    if let Some(ssp_key) = &allowlist.ssp_identity_public_key {
+        let expected = parse_key(ssp_key)?;
+        let actual = PublicKey::from_str(&spark_config.ssp_config.identity_public_key).ok();
+        if actual != Some(expected) {
+            return Err(SdkError::OperatorNotAllowed(format!(
+                "service provider identity key {} does not match the expected key {ssp_key}",
+                spark_config.ssp_config.identity_public_key
+            )));
+        }
+    }
+
+    Ok(spark_config)
+}
+
 /// Wraps the resolved session store (or an in-memory default) in the in-memory
 /// caching layer. Tokens are stored as-is: the SDK applies no encryption (see
 /// [`SdkBuilder::with_session_store`] to layer your own).
@@ -917,9 +1369,10 @@ async fn build_spark_wallet(params: BuildSparkWalletParams) -> Result<Arc<SparkW
                 Arc::clone(provider) as Arc<dyn spark_wallet::HeaderProvider>
             );
     }
-    if let Some(observer) = params.payment_observer {
-        let observer: Arc<dyn spark_wallet::TransferObserver> =
-            Arc::new(SparkTransferObserver::new(observer));
+    if params.payment_observer.is_some() || params.send_approver.is_some() {
+        let observer: Arc<dyn spark_wallet::TransferObserver> = Arc::new(
+            SparkTransferObserver::new(params.payment_observer, params.send_approver),
+        );
         wallet_builder = wallet_builder.with_transfer_observer(observer);
     }
     if let Some(tree_store) = params.tree_store {
@@ -941,47 +1394,97 @@ fn resolve_lnurl_server_client(
     config: &Config,
     context: &SdkContext,
     spark_wallet: &Arc<SparkWallet>,
+    clock: Option<&Arc<dyn Clock>>,
 ) -> Option<Arc<dyn LnurlServerClient>> {
     if let Some(client) = explicit {
         return Some(client);
     }
     config.lnurl_domain.as_ref().map(|domain| {
-        Arc::new(DefaultLnurlServerClient::new(
-            context.http_client.clone(),
-            domain.clone(),
-            config.api_key.clone(),
-            Arc::clone(spark_wallet),
-        )) as Arc<dyn LnurlServerClient>
+        Arc::new(
+            DefaultLnurlServerClient::new(
+                context.http_client.clone(),
+                domain.clone(),
+                config.api_key.clone(),
+                Arc::clone(spark_wallet),
+            )
+            .with_server_url(
+                config
+                    .service_endpoints
+                    .as_ref()
+                    .and_then(|endpoints| endpoints.lnurl_server_url.clone()),
+            )
+            .with_clock(clock.cloned()),
+        ) as Arc<dyn LnurlServerClient>
     })
 }
 
-/// Wraps the base storage with the real-time-sync layer when configured and
-/// background services are enabled. Otherwise returns the storage unchanged.
-#[allow(clippy::too_many_arguments)]
-async fn maybe_wrap_storage_with_real_time_sync(
-    storage: Arc<dyn crate::persist::Storage>,
+/// Connects to a Breez server at a URL set in [`Config::service_endpoints`].
+fn breez_server_at(url: &str, user_agent: &str) -> Result<Arc<BreezServer>, SdkError> {
+    BreezServer::new(url, None, user_agent)
+        .map(Arc::new)
+        .map_err(|e| SdkError::Generic(format!("Invalid Breez server URL {url}: {e}")))
+}
+
+/// The transport real-time sync runs over when background services are
+/// enabled: the Nostr relays set with `with_nostr_sync_storage`, else the
+/// configured sync server. `None` when real-time sync is off.
+#[cfg_attr(not(feature = "nostr-sync"), allow(unused_variables))]
+async fn resolve_real_time_sync_transport(
     config: &Config,
     background_services_enabled: bool,
     user_agent: String,
+    #[cfg(feature = "nostr-sync")] nostr_sync_relays: Option<Vec<String>>,
+    hmac: Option<&dyn crate::signer::HmacSigner>,
+) -> Result<Option<RealTimeSyncTransport>, SdkError> {
+    if !background_services_enabled {
+        return Ok(None);
+    }
+    #[cfg(feature = "nostr-sync")]
+    if let Some(relays) = nostr_sync_relays {
+        let hmac = hmac.ok_or(SdkError::InvalidInput(
+            "Nostr sync storage requires a signer that supports HMAC".to_string(),
+        ))?;
+        let client = crate::realtime_sync::NostrSyncerClient::new(hmac, config.network, relays)
+            .await
+            .map_err(|e| SdkError::InvalidInput(format!("Invalid Nostr sync storage: {e}")))?;
+        return Ok(Some(RealTimeSyncTransport::Nostr(client)));
+    }
+    Ok(config
+        .real_time_sync_server_url
+        .as_ref()
+        .map(|server_url| RealTimeSyncTransport::Server {
+            server_url: server_url.clone(),
+            api_key: config.api_key.clone(),
+            user_agent,
+        }))
+}
+
+/// Wraps the base storage with the real-time-sync layer when a transport was
+/// resolved. Otherwise returns the storage unchanged.
+async fn maybe_wrap_storage_with_real_time_sync(
+    storage: Arc<dyn crate::persist::Storage>,
+    transport: Option<RealTimeSyncTransport>,
     rtsync_signer: Option<Arc<RTSyncSigner>>,
     shutdown_receiver: watch::Receiver<()>,
+    paused_receiver: watch::Receiver<bool>,
     event_emitter: Arc<EventEmitter>,
     lnurl_server_client: Option<Arc<dyn LnurlServerClient>>,
+    clock: Option<Arc<dyn Clock>>,
 ) -> Result<Arc<dyn crate::persist::Storage>, SdkError> {
     // `validate_signer_capabilities` rejects real-time sync without an
-    // ECIES-capable signer, so `rtsync_signer` is present whenever the URL is
-    // set; a missing signer can't be reached and falls through to the no-op arm.
-    match (&config.real_time_sync_server_url, rtsync_signer) {
-        (Some(server_url), Some(signer)) if background_services_enabled => {
+    // ECIES-capable signer, so `rtsync_signer` is present whenever a transport
+    // is; a missing signer can't be reached and falls through to the no-op arm.
+    match (transport, rtsync_signer) {
+        (Some(transport), Some(signer)) => {
             init_and_start_real_time_sync(RealTimeSyncParams {
-                server_url: server_url.clone(),
-                api_key: config.api_key.clone(),
-                user_agent,
+                transport,
                 signer,
                 storage,
                 shutdown_receiver,
+                paused_receiver,
                 event_emitter,
                 lnurl_server_client,
+                clock,
             })
             .await
         }
@@ -989,14 +1492,9 @@ async fn maybe_wrap_storage_with_real_time_sync(
     }
 }
 
-/// Builds the [`FlashnetTokenConverter`] used for in-SDK token conversion.
-fn build_token_converter(
-    config: &Config,
-    storage: &Arc<dyn crate::persist::Storage>,
-    spark_wallet: &Arc<SparkWallet>,
-    context: &SdkContext,
-) -> Arc<dyn TokenConverter> {
-    let flashnet_config = FlashnetConfig::default_config(
+/// The Flashnet config of the network, with the Breez integrator fee
+fn default_flashnet_config(config: &Config) -> FlashnetConfig {
+    FlashnetConfig::default_config(
         config.network.into(),
         DEFAULT_INTEGRATOR_PUBKEY
             .parse()
@@ -1005,13 +1503,33 @@ fn build_token_converter(
                 pubkey,
                 fee_bps: DEFAULT_INTEGRATOR_FEE_BPS,
             }),
-    );
+    )
+}
+
+/// Builds the [`FlashnetTokenConverter`] used for in-SDK token conversion.
+fn build_token_converter(
+    config: &Config,
+    storage: &Arc<dyn crate::persist::Storage>,
+    spark_wallet: &Arc<SparkWallet>,
+    context: &SdkContext,
+    price_source: Option<Arc<dyn ConversionPriceSource>>,
+) -> Arc<dyn TokenConverter> {
+    let flashnet_config = default_flashnet_config(config);
+    let price_verifier = price_source.map(|source| {
+        ConversionPriceVerifier::new(
+            source,
+            config
+                .conversion_max_price_deviation_bps
+                .unwrap_or(DEFAULT_CONVERSION_MAX_PRICE_DEVIATION_BPS),
+        )
+    });
     Arc::new(FlashnetTokenConverter::new(
         flashnet_config,
         Arc::clone(storage),
         Arc::clone(spark_wallet),
         config.network,
         context.http_client.clone(),
+        price_verifier,
     ))
 }
 
@@ -1023,6 +1541,7 @@ async fn build_stable_balance(
     spark_wallet: &Arc<SparkWallet>,
     storage: &Arc<dyn crate::persist::Storage>,
     event_emitter: &Arc<EventEmitter>,
+    clock: Option<&Arc<dyn Clock>>,
 ) -> Option<Arc<StableBalance>> {
     let stable_config = config.stable_balance_config.as_ref()?;
     Some(Arc::new(
@@ -1032,6 +1551,7 @@ async fn build_stable_balance(
             Arc::clone(spark_wallet),
             Arc::clone(storage),
             Arc::clone(event_emitter),
+            clock.cloned(),
         )
         .await,
     ))
@@ -1049,13 +1569,16 @@ fn build_cross_chain_context(
     lightning_sender: &Arc<crate::sdk::LightningSender>,
     fiat_service: Arc<dyn breez_sdk_common::fiat::FiatService>,
     shutdown_receiver: watch::Receiver<()>,
+    clock: Option<&Arc<dyn Clock>>,
 ) -> crate::cross_chain::CrossChainContext {
     // Cache scoped to cross-chain: providers + dispatcher share one TTL window.
-    let cached_fiat: Arc<dyn breez_sdk_common::fiat::FiatService> =
-        Arc::new(crate::cross_chain::CachedFiatService::new(
+    let cached_fiat: Arc<dyn breez_sdk_common::fiat::FiatService> = Arc::new(
+        crate::cross_chain::CachedFiatService::new(
             fiat_service,
             crate::cross_chain::DEFAULT_FIAT_CACHE_TTL,
-        ));
+        )
+        .with_clock(clock.cloned()),
+    );
     let mut providers = crate::cross_chain::CrossChainContext::new(Arc::clone(&cached_fiat));
     if config.cross_chain_config.is_none() {
         return providers;
@@ -1077,6 +1600,7 @@ fn build_cross_chain_context(
                 Arc::clone(storage),
                 Arc::clone(&cached_fiat),
                 shutdown_receiver.clone(),
+                clock.cloned(),
             )),
         );
     }
@@ -1089,6 +1613,7 @@ fn build_cross_chain_context(
         cached_fiat,
         Arc::clone(lightning_sender),
         shutdown_receiver,
+        clock.cloned(),
     ) {
         Ok(Some(service)) => {
             providers.insert(crate::cross_chain::CrossChainProvider::Boltz, service);
@@ -1131,6 +1656,63 @@ mod tests {
         }
     }
 
+    #[test]
+    fn operator_allowlist_fails_closed_on_unknown_operator() {
+        use crate::models::{AllowedOperator, OperatorAllowlistConfig};
+
+        let spark_config = default_config(Network::Regtest)
+            .spark_config
+            .expect("default_config must populate spark_config");
+        let mut allowlist = OperatorAllowlistConfig {
+            operators: spark_config
+                .signing_operators
+                .iter()
+                .map(|op| AllowedOperator {
+                    identity_public_key: op.identity_public_key.clone(),
+                    address: Some(op.address.clone()),
+                    ca_cert_pem: Some("operator-ca".to_string()),
+                })
+                .collect(),
+            ssp_identity_public_key: Some(spark_config.ssp_config.identity_public_key.clone()),
+        };
+
+        let allowed = super::apply_operator_allowlist(spark_config.clone(), &allowlist).unwrap();
+        assert!(
+            allowed
+                .signing_operators
+                .iter()
+                .all(|op| op.ca_cert_pem.as_deref() == Some("operator-ca"))
+        );
+
+        // Dropping one operator from the allowlist must reject the config.
+        allowlist.operators.pop();
+        match super::apply_operator_allowlist(spark_config.clone(), &allowlist) {
+            Err(SdkError::OperatorNotAllowed(m)) => {
+                assert!(m.contains("not in the allowlist"), "got: {m}");
+            }
+            other => panic!("expected OperatorNotAllowed, got {other:?}"),
+        }
+
+        // A changed SSP key must reject the config as well.
+        let mut other_ssp = spark_config.clone();
+        other_ssp.ssp_config.identity_public_key = spark_config.signing_operators[0]
+            .identity_public_key
+            .clone();
+        allowlist.operators = spark_config
+            .signing_operators
+            .iter()
+            .map(|op| AllowedOperator {
+                identity_public_key: op.identity_public_key.clone(),
+                address: None,
+                ca_cert_pem: None,
+            })
+            .collect();
+        assert!(matches!(
+            super::apply_operator_allowlist(other_ssp, &allowlist),
+            Err(SdkError::OperatorNotAllowed(_))
+        ));
+    }
+
     #[test]
     fn validate_signer_capabilities_gates_encryption_features() {
         // ECIES/HMAC supported: encryption-dependent features are allowed.
@@ -1620,6 +2202,40 @@ mod tests {
         );
     }
 
+    #[test]
+    fn duress_account_number_is_applied_on_build() {
+        let duress_config = crate::create_duress_config("1234".to_string(), 7).unwrap();
+        let account_number = |builder: SdkBuilder| match super::apply_duress(
+            builder.signer_source,
+            builder.duress,
+            Network::Mainnet,
+        ) {
+            Ok(super::SignerSource::Seed { account_number, .. }) => Ok(account_number),
+            Ok(super::SignerSource::External { .. }) => panic!("expected a seed signer"),
+            Err(e) => Err(e),
+        };
+        let builder = || SdkBuilder::new(default_config(Network::Mainnet), test_seed());
+
+        // A later account number doesn't reopen the real wallet
+        let decoy = builder()
+            .with_duress(duress_config.clone(), "1234".to_string())
+            .with_account_number(3);
+        assert_eq!(account_number(decoy).unwrap(), Some(7));
+        let real = builder()
+            .with_duress(duress_config.clone(), "0000".to_string())
+            .with_account_number(3);
+        assert_eq!(account_number(real).unwrap(), Some(3));
+
+        // The decoy can't be the real wallet, whichever PIN was entered
+        let same = builder()
+            .with_account_number(7)
+            .with_duress(duress_config, "0000".to_string());
+        assert!(matches!(
+            account_number(same),
+            Err(SdkError::InvalidInput(_))
+        ));
+    }
+
     const TEST_MNEMONIC: &str = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about";
 
     fn test_seed() -> crate::Seed {
@@ -1697,7 +2313,7 @@ mod tests {
         let event_emitter = std::sync::Arc::downgrade(&sdk.event_emitter);
         let spark_wallet = std::sync::Arc::downgrade(&sdk.spark_wallet);
 
-        sdk.sync_wallet(SyncWalletRequest {})
+        sdk.sync_wallet(SyncWalletRequest::default())
             .await
             .expect("sync_wallet should succeed");
 
@@ -1954,6 +2570,18 @@ mod tests {
         assert!(result.leaf_auto_optimize_enabled);
     }
 
+    #[test]
+    fn finalize_spark_wallet_config_leaf_auto_policy_disables_wallet_auto_optimize() {
+        let mut config = default_config(Network::Regtest);
+        config.leaf_optimization_config.auto_enabled = true;
+        config.leaf_optimization_config.auto_policy = Some(crate::LeafOptimizationPolicy {
+            min_leaf_count: Some(100),
+            ..Default::default()
+        });
+        let result = super::finalize_spark_wallet_config(&config, "test-agent", true).unwrap();
+        assert!(!result.leaf_auto_optimize_enabled);
+    }
+
     #[test]
     fn finalize_spark_wallet_config_applies_user_agent() {
         let config = default_config(Network::Regtest);
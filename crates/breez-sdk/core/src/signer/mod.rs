@@ -99,8 +99,10 @@ pub use external_spark_adapter::ExternalSparkSignerAdapter;
 pub mod breez;
 pub mod cpfp;
 pub mod lnurl_auth;
+pub mod psbt;
 pub mod rtsync;
 pub mod single_key_signer;
 
 pub use cpfp::CpfpSigner;
+pub use psbt::{PsbtSigner, psbt_cpfp_signer};
 pub use single_key_signer::{SingleKeySigner, single_key_cpfp_signer};
@@ -0,0 +1,177 @@
+use std::sync::Arc;
+
+use base64::{Engine, engine::general_purpose::STANDARD as BASE64};
+use bitcoin::Witness;
+
+use crate::error::SignerError;
+
+use super::cpfp::CpfpSigner;
+
+/// Signer that takes and returns base64-encoded PSBTs, the format used by
+/// hardware wallets (Ledger, Trezor, Coldcard) and their host apps.
+///
+/// The SDK hands over the unsigned PSBT and the host signs the inputs that are
+/// not already finalized, on the device or otherwise. The returned PSBT may be
+/// finalized or carry only the signatures: P2WPKH partial signatures and P2TR
+/// key-path signatures are finalized by the SDK.
+#[cfg_attr(feature = "uniffi", uniffi::export(with_foreign))]
+#[macros::async_trait]
+pub trait PsbtSigner: Send + Sync {
+    async fn sign_psbt(&self, psbt_base64: String) -> Result<String, SignerError>;
+}
+
+/// Wraps a [`PsbtSigner`] as a [`CpfpSigner`], so the on-chain transactions of
+/// a unilateral exit can be signed by a hardware wallet.
+#[cfg_attr(feature = "uniffi", uniffi::export)]
+pub fn psbt_cpfp_signer(signer: Arc<dyn PsbtSigner>) -> Arc<dyn CpfpSigner> {
+    Arc::new(Base64PsbtSigner { inner: signer })
+}
+
+struct Base64PsbtSigner {
+    inner: Arc<dyn PsbtSigner>,
+}
+
+#[macros::async_trait]
+impl CpfpSigner for Base64PsbtSigner {
+    async fn sign_psbt(&self, psbt_bytes: Vec<u8>) -> Result<Vec<u8>, SignerError> {
+        let signed = self.inner.sign_psbt(BASE64.encode(psbt_bytes)).await?;
+        let signed_bytes = BASE64
+            .decode(signed.trim())
+            .map_err(|e| SignerError::InvalidInput(format!("Invalid PSBT encoding: {e}")))?;
+        let mut psbt = bitcoin::Psbt::deserialize(&signed_bytes)
+            .map_err(|e| SignerError::InvalidInput(format!("Invalid PSBT: {e}")))?;
+        finalize_signed_inputs(&mut psbt);
+        Ok(psbt.serialize())
+    }
+}
+
+/// Finalizes the inputs a signer signed without finalizing: P2WPKH inputs
+/// with a single partial signature and P2TR inputs with a key-path signature.
+/// Inputs of any other script are left to the signer to finalize.
+fn finalize_signed_inputs(psbt: &mut bitcoin::Psbt) {
+    for input in &mut psbt.inputs {
+        if input.final_script_witness.is_some() || input.final_script_sig.is_some() {
+            continue;
+        }
+        let Some(tx_out) = &input.witness_utxo else {
+            continue;
+        };
+        if tx_out.script_pubkey.is_p2wpkh() && input.partial_sigs.len() == 1 {
+            let Some((pubkey, signature)) = input.partial_sigs.pop_first() else {
+                continue;
+            };
+            let mut witness = Witness::new();
+            witness.push(signature.to_vec());
+            witness.push(pubkey.to_bytes());
+            input.final_script_witness = Some(witness);
+        } else if tx_out.script_pubkey.is_p2tr()
+            && let Some(signature) = input.tap_key_sig.take()
+        {
+            let mut witness = Witness::new();
+            witness.push(signature.to_vec());
+            input.final_script_witness = Some(witness);
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use bitcoin::{
+        Amount, OutPoint, ScriptBuf, Sequence, Transaction, TxIn, TxOut, Txid,
+        absolute::LockTime,
+        hashes::Hash,
+        key::{Keypair, Secp256k1},
+        secp256k1::{Message, SecretKey},
+        sighash::{EcdsaSighashType, TapSighashType},
+        transaction::Version,
+    };
+    use macros::async_test_all;
+
+    use super::*;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    /// Adds signatures to the PSBT without finalizing it, like a hardware
+    /// wallet does.
+    struct PartialSigner;
+
+    #[macros::async_trait]
+    impl PsbtSigner for PartialSigner {
+        async fn sign_psbt(&self, psbt_base64: String) -> Result<String, SignerError> {
+            let mut psbt =
+                bitcoin::Psbt::deserialize(&BASE64.decode(psbt_base64).unwrap()).unwrap();
+            let secp = Secp256k1::new();
+            let secret_key = SecretKey::from_slice(&[1; 32]).unwrap();
+            let msg = Message::from_digest([2; 32]);
+            psbt.inputs[0].partial_sigs.insert(
+                bitcoin::PublicKey::new(secret_key.public_key(&secp)),
+                bitcoin::ecdsa::Signature {
+                    signature: secp.sign_ecdsa(&msg, &secret_key),
+                    sighash_type: EcdsaSighashType::All,
+                },
+            );
+            psbt.inputs[1].tap_key_sig = Some(bitcoin::taproot::Signature {
+                signature: secp
+                    .sign_schnorr_no_aux_rand(&msg, &Keypair::from_secret_key(&secp, &secret_key)),
+                sighash_type: TapSighashType::Default,
+            });
+            Ok(BASE64.encode(psbt.serialize()))
+        }
+    }
+
+    fn unsigned_psbt() -> bitcoin::Psbt {
+        let secp = Secp256k1::new();
+        let secret_key = SecretKey::from_slice(&[1; 32]).unwrap();
+        let pubkey = bitcoin::CompressedPublicKey(secret_key.public_key(&secp));
+        let tx = Transaction {
+            version: Version::TWO,
+            lock_time: LockTime::ZERO,
+            input: (0..3)
+                .map(|vout| TxIn {
+                    previous_output: OutPoint {
+                        txid: Txid::all_zeros(),
+                        vout,
+                    },
+                    script_sig: ScriptBuf::new(),
+                    sequence: Sequence::ENABLE_RBF_NO_LOCKTIME,
+                    witness: Witness::new(),
+                })
+                .collect(),
+            output: vec![TxOut {
+                value: Amount::from_sat(1_000),
+                script_pubkey: ScriptBuf::new_p2wpkh(&pubkey.wpubkey_hash()),
+            }],
+        };
+        let mut psbt = bitcoin::Psbt::from_unsigned_tx(tx).unwrap();
+        let (xonly, _) = pubkey.0.x_only_public_key();
+        let scripts = [
+            ScriptBuf::new_p2wpkh(&pubkey.wpubkey_hash()),
+            ScriptBuf::new_p2tr(&secp, xonly, None),
+            ScriptBuf::new_p2wsh(&ScriptBuf::new().wscript_hash()),
+        ];
+        for (input, script_pubkey) in psbt.inputs.iter_mut().zip(scripts) {
+            input.witness_utxo = Some(TxOut {
+                value: Amount::from_sat(10_000),
+                script_pubkey,
+            });
+        }
+        psbt
+    }
+
+    #[async_test_all]
+    async fn test_finalizes_partial_signatures() {
+        let signer = psbt_cpfp_signer(Arc::new(PartialSigner));
+        let signed = signer.sign_psbt(unsigned_psbt().serialize()).await.unwrap();
+        let psbt = bitcoin::Psbt::deserialize(&signed).unwrap();
+
+        let p2wpkh = psbt.inputs[0].final_script_witness.as_ref().unwrap();
+        assert_eq!(p2wpkh.len(), 2);
+        assert!(psbt.inputs[0].partial_sigs.is_empty());
+        let p2tr = psbt.inputs[1].final_script_witness.as_ref().unwrap();
+        assert_eq!(p2tr.len(), 1);
+        assert!(psbt.inputs[1].tap_key_sig.is_none());
+        // Other scripts are left for the signer to finalize
+        assert!(psbt.inputs[2].final_script_witness.is_none());
+    }
+}
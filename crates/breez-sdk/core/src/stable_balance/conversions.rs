@@ -11,6 +11,7 @@ use tracing::{debug, info};
 
 use crate::models::{ConversionStatus, PaymentDetails};
 use crate::persist::PaymentMetadata;
+use crate::sdk::is_wallet_frozen;
 use crate::token_conversion::{
     ConversionAmount, ConversionError, ConversionOptions, ConversionPurpose, ConversionType,
     FetchConversionLimitsRequest,
@@ -32,6 +33,10 @@ impl StableBalance {
             debug!("Per-receive conversion skipped: stable balance is inactive");
             return Ok(false);
         };
+        if is_wallet_frozen(&self.core.storage).await? {
+            debug!("Per-receive conversion skipped: wallet is frozen");
+            return Ok(false);
+        }
 
         // Fetch payment from storage to get latest metadata and amount
         let payment = self
@@ -145,6 +150,7 @@ impl StableBalance {
     /// Skips if:
     /// - A send-with-conversion payment is in flight (payment guard held)
     /// - Stable balance is inactive
+    /// - The wallet is frozen
     /// - Balance is below the trigger amount
     pub(super) async fn auto_convert(&self) -> Result<bool, ConversionError> {
         // Get the active token, skip if stable balance is inactive
@@ -152,6 +158,10 @@ impl StableBalance {
             debug!("Auto-conversion skipped: stable balance is inactive");
             return Ok(false);
         };
+        if is_wallet_frozen(&self.core.storage).await? {
+            debug!("Auto-conversion skipped: wallet is frozen");
+            return Ok(false);
+        }
 
         // Lock to atomically check "no payments in flight" + read balance.
         // This prevents a payment from starting between the check and the read,
@@ -258,6 +268,11 @@ impl StableBalance {
         &self,
         token_identifier: &str,
     ) -> Result<bool, ConversionError> {
+        if is_wallet_frozen(&self.core.storage).await? {
+            debug!("Deactivation conversion skipped: wallet is frozen");
+            return Ok(false);
+        }
+
         // Get the current token balance
         let token_balances = self.spark_wallet.get_token_balances().await?;
         let token_balance = token_balances
@@ -145,7 +145,8 @@ pub(crate) use self::queue::PendingConversion;
 
 use crate::events::{EventEmitter, EventMiddleware, SdkEvent};
 use crate::models::{
-    ConversionDetails, ConversionStatus, Payment, PaymentMethod, PaymentType, StableBalanceToken,
+    Clock, ConversionDetails, ConversionStatus, Payment, PaymentMethod, PaymentType,
+    StableBalanceToken,
 };
 use crate::persist::{ObjectCacheRepository, PaymentMetadata, Storage};
 use crate::sdk::RuntimeEvent;
@@ -273,6 +274,7 @@ impl StableBalance {
         spark_wallet: Arc<SparkWallet>,
         storage: Arc<dyn Storage>,
         event_emitter: Arc<EventEmitter>,
+        clock: Option<Arc<dyn Clock>>,
     ) -> Self {
         let initial_active_token =
             StableBalanceCore::resolve_initial_token(&config, &storage).await;
@@ -291,8 +293,8 @@ impl StableBalance {
             active_token: RwLock::new(initial_active_token),
             token_converter,
             storage: Arc::clone(&storage),
-            effective_values: ExpiringCell::new(),
-            queue: ConversionQueue::new(storage),
+            effective_values: ExpiringCell::new().with_clock(clock.clone()),
+            queue: ConversionQueue::new(storage, clock),
             synced_notify: Notify::new(),
         });
 
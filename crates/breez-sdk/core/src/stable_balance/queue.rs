@@ -5,25 +5,17 @@
 
 use std::sync::Arc;
 
-use platform_utils::{
-    time::{SystemTime, UNIX_EPOCH},
-    tokio,
-};
+use platform_utils::tokio;
 use serde::{Deserialize, Serialize};
 use tokio::sync::{Mutex, Notify, watch};
 use tracing::{Instrument, debug, info, warn};
 
-use crate::models::ConversionStatus;
+use crate::models::{Clock, ConversionStatus};
 use crate::persist::{ObjectCacheRepository, PaymentMetadata, Storage};
+use crate::sdk::clock_now;
 
 use super::{StableBalance, per_receive_transfer_id};
 
-fn now_secs() -> u64 {
-    SystemTime::now()
-        .duration_since(UNIX_EPOCH)
-        .map_or(0, |d| d.as_secs())
-}
-
 /// A conversion task to be processed by the worker.
 #[derive(Clone, Debug)]
 pub(crate) enum ConversionTask {
@@ -87,10 +79,12 @@ pub(crate) struct ConversionQueue {
     state: Mutex<ConversionQueueState>,
     pub(crate) notify: Arc<Notify>,
     storage: Arc<dyn Storage>,
+    /// Clock tasks are timestamped and expired by, unset to use the system clock
+    clock: Option<Arc<dyn Clock>>,
 }
 
 impl ConversionQueue {
-    pub fn new(storage: Arc<dyn Storage>) -> Self {
+    pub fn new(storage: Arc<dyn Storage>, clock: Option<Arc<dyn Clock>>) -> Self {
         Self {
             state: Mutex::new(ConversionQueueState {
                 per_receive: Vec::new(),
@@ -98,6 +92,7 @@ impl ConversionQueue {
             }),
             notify: Arc::new(Notify::new()),
             storage,
+            clock,
         }
     }
 
@@ -109,7 +104,7 @@ impl ConversionQueue {
             state.per_receive.push(PendingConversion {
                 payment_id,
                 state: PendingState::Ready,
-                created_at: now_secs(),
+                created_at: clock_now(self.clock.as_ref()),
             });
             self.persist_pending(&state).await;
             self.notify.notify_one();
@@ -233,7 +228,7 @@ impl ConversionQueue {
     /// Remove deferred tasks that have exceeded the timeout and return their `payment_ids`.
     /// Called on `Synced` events to clean up tasks that were never resolved.
     pub async fn clear_expired_tasks(&self) -> Vec<String> {
-        let now = now_secs();
+        let now = clock_now(self.clock.as_ref());
         let mut state = self.state.lock().await;
         let mut timed_out = Vec::new();
         state.per_receive.retain(|p| {
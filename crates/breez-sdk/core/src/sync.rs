@@ -45,6 +45,13 @@ impl SparkSyncService {
         Ok(())
     }
 
+    /// Syncs only the token payments, see [`SparkSyncService::sync_payments`]
+    pub async fn sync_token_payments(&self, initial_sync_complete: bool) -> Result<(), SdkError> {
+        let object_repository = ObjectCacheRepository::new(self.storage.clone());
+        self.sync_token_payments_to_storage(&object_repository, initial_sync_complete)
+            .await
+    }
+
     async fn sync_bitcoin_payments_to_storage(
         &self,
         object_repository: &ObjectCacheRepository,
@@ -223,8 +230,26 @@ impl SparkSyncService {
 
         // Get the payment metadata from storage for this payment
         let cache = ObjectCacheRepository::new(self.storage.clone());
-        let Some(metadata) = cache.fetch_payment_metadata(identifier).await? else {
-            return Ok(());
+        let (identifier, metadata) = match cache.fetch_payment_metadata(identifier).await? {
+            Some(metadata) => (identifier, metadata),
+            // Metadata of a payment to a Spark invoice we created is keyed by
+            // the invoice, as the payment id is unknown until it arrives
+            None => {
+                let Some(PaymentDetails::Spark {
+                    invoice_details: Some(invoice_details),
+                    ..
+                }) = &payment.details
+                else {
+                    return Ok(());
+                };
+                let Some(metadata) = cache
+                    .fetch_payment_metadata(&invoice_details.invoice)
+                    .await?
+                else {
+                    return Ok(());
+                };
+                (invoice_details.invoice.as_str(), metadata)
+            }
         };
 
         self.storage
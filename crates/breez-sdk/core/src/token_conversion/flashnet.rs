@@ -13,18 +13,20 @@ use tracing::{debug, error, info, warn};
 use crate::{
     AmountAdjustmentReason, EventEmitter, Network, Payment, PaymentDetails, PaymentMetadata,
     Storage,
-    persist::{StorageListPaymentsRequest, StoragePaymentDetailsFilter},
+    persist::{ObjectCacheRepository, StorageListPaymentsRequest, StoragePaymentDetailsFilter},
     token_conversion::{ConversionAmount, DEFAULT_CONVERSION_MAX_SLIPPAGE_BPS},
     utils::{
         payments::{fetch_and_process_payment, insert_payment_with_metadata},
         polling::{PollSchedule, poll_until},
+        token::get_tokens_metadata_cached_or_query,
     },
 };
 
 use super::{
-    ConversionError, ConversionEstimate, ConversionInfo, ConversionOptions, ConversionPurpose,
-    ConversionStatus, ConversionType, FeeSplit, FetchConversionLimitsRequest,
-    FetchConversionLimitsResponse, TokenConversionPool, TokenConversionResponse, TokenConverter,
+    ConversionError, ConversionEstimate, ConversionInfo, ConversionOptions,
+    ConversionPriceVerifier, ConversionPurpose, ConversionStatus, ConversionType, FeeSplit,
+    FetchConversionLimitsRequest, FetchConversionLimitsResponse, TokenConversionPool,
+    TokenConversionResponse, TokenConverter,
 };
 
 // Polling cadence for the received leg of a freshly-completed conversion.
@@ -46,6 +48,7 @@ pub(crate) struct FlashnetTokenConverter {
     network: Network,
     refund_trigger: broadcast::Sender<()>,
     integrator_fee_bps: u32,
+    price_verifier: Option<ConversionPriceVerifier>,
 }
 
 impl FlashnetTokenConverter {
@@ -55,12 +58,14 @@ impl FlashnetTokenConverter {
     /// * `storage` - Storage for payment lookups and metadata updates
     /// * `spark_wallet` - Spark wallet for transfer/transaction lookups
     /// * `network` - The network configuration
+    /// * `price_verifier` - Optional external price check run before each swap
     pub fn new(
         flashnet_config: FlashnetConfig,
         storage: Arc<dyn Storage>,
         spark_wallet: Arc<SparkWallet>,
         network: Network,
         http_client: Arc<dyn platform_utils::HttpClient>,
+        price_verifier: Option<ConversionPriceVerifier>,
     ) -> Self {
         let integrator_fee_bps = flashnet_config
             .integrator_config
@@ -83,6 +88,7 @@ impl FlashnetTokenConverter {
             network,
             refund_trigger,
             integrator_fee_bps,
+            price_verifier,
         }
     }
 
@@ -576,6 +582,48 @@ impl FlashnetTokenConverter {
         ))
     }
 
+    /// Verifies the rate of a conversion against the configured price source,
+    /// if any, before the swap is executed.
+    async fn verify_price(
+        &self,
+        conversion_type: &ConversionType,
+        token_identifier: Option<&String>,
+        amount_in: u128,
+        min_amount_out: u128,
+    ) -> Result<(), ConversionError> {
+        let Some(price_verifier) = &self.price_verifier else {
+            return Ok(());
+        };
+
+        let token_identifier = match conversion_type {
+            ConversionType::FromBitcoin => token_identifier.ok_or_else(|| {
+                ConversionError::ValidationFailed(
+                    "Token identifier is required for from Bitcoin conversion".to_string(),
+                )
+            })?,
+            ConversionType::ToBitcoin {
+                from_token_identifier,
+            } => from_token_identifier,
+        };
+        let token = get_tokens_metadata_cached_or_query(
+            &self.spark_wallet,
+            &ObjectCacheRepository::new(Arc::clone(&self.storage)),
+            &[token_identifier.as_str()],
+        )
+        .await?
+        .into_iter()
+        .next()
+        .ok_or_else(|| {
+            ConversionError::ValidationFailed(format!(
+                "Token metadata not found for {token_identifier}"
+            ))
+        })?;
+
+        price_verifier
+            .verify(conversion_type, token, amount_in, min_amount_out)
+            .await
+    }
+
     /// Insert local `Payment` records for both legs of a completed swap so
     /// the conversion's `from`/`to` are immediately visible to callers
     /// without waiting for the next `sync_wallet`.
@@ -702,6 +750,15 @@ impl TokenConverter for FlashnetTokenConverter {
             .resolve_amount(options, token_identifier, &amount)
             .await?;
 
+        // Cross-check the quoted rate against the external price source
+        self.verify_price(
+            &options.conversion_type,
+            token_identifier,
+            amount_in,
+            min_amount_out,
+        )
+        .await?;
+
         // Get the conversion pool for execution
         let conversion_pool = self
             .get_conversion_pool(options, token_identifier, min_amount_out)
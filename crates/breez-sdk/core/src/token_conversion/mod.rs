@@ -2,11 +2,16 @@ mod error;
 mod flashnet;
 mod middleware;
 mod models;
+mod price_check;
+mod token_price;
 
 pub use error::ConversionError;
 pub(crate) use flashnet::FlashnetTokenConverter;
 pub(crate) use middleware::TokenConversionMiddleware;
 pub use models::*;
+pub(crate) use price_check::ConversionPriceVerifier;
+pub use price_check::{ConversionPriceSource, DEFAULT_CONVERSION_MAX_PRICE_DEVIATION_BPS};
+pub(crate) use token_price::FlashnetTokenPriceService;
 
 use std::sync::Arc;
 
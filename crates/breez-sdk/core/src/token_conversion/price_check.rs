@@ -0,0 +1,187 @@
+use std::sync::Arc;
+
+use tracing::{info, warn};
+
+use crate::{ServiceConnectivityError, TokenMetadata};
+
+use super::{ConversionError, ConversionType};
+
+/// Default maximum adverse deviation between an AMM quote and the external
+/// price source, in basis points (2%).
+pub const DEFAULT_CONVERSION_MAX_PRICE_DEVIATION_BPS: u32 = 200;
+
+/// An external price source used to independently verify AMM quotes before a
+/// conversion is executed.
+///
+/// Set it with `SdkBuilder::with_conversion_price_source`. When set, every
+/// conversion compares the rate it is about to execute at against this source
+/// and is refused if the rate is worse by more than
+/// [`Config::conversion_max_price_deviation_bps`](crate::Config::conversion_max_price_deviation_bps).
+#[cfg_attr(feature = "uniffi", uniffi::export(with_foreign))]
+#[macros::async_trait]
+pub trait ConversionPriceSource: Send + Sync {
+    /// Returns the price of one whole token (`10^decimals` base units) in
+    /// satoshis.
+    async fn fetch_token_price_sats(
+        &self,
+        token: TokenMetadata,
+    ) -> Result<f64, ServiceConnectivityError>;
+}
+
+/// Checks conversion quotes against a [`ConversionPriceSource`].
+#[derive(Clone)]
+pub(crate) struct ConversionPriceVerifier {
+    source: Arc<dyn ConversionPriceSource>,
+    max_deviation_bps: u32,
+}
+
+impl ConversionPriceVerifier {
+    pub(crate) fn new(source: Arc<dyn ConversionPriceSource>, max_deviation_bps: u32) -> Self {
+        Self {
+            source,
+            max_deviation_bps,
+        }
+    }
+
+    /// Fails closed if the price source is unavailable or the quote is worse
+    /// than the reference price by more than the configured deviation.
+    ///
+    /// `amount_in` and `min_amount_out` are the amounts the conversion is
+    /// about to be executed with, so the check covers the worst rate the
+    /// conversion can settle at.
+    pub(crate) async fn verify(
+        &self,
+        conversion_type: &ConversionType,
+        token: TokenMetadata,
+        amount_in: u128,
+        min_amount_out: u128,
+    ) -> Result<(), ConversionError> {
+        let token_identifier = token.identifier.clone();
+        let decimals = token.decimals;
+        let reference_price = self
+            .source
+            .fetch_token_price_sats(token)
+            .await
+            .map_err(|e| {
+                warn!("Conversion price source failed for {token_identifier}: {e}");
+                ConversionError::ValidationFailed(format!(
+                    "Unable to verify conversion rate, price source failed: {e}"
+                ))
+            })?;
+
+        let deviation_bps = adverse_deviation_bps(
+            conversion_type,
+            decimals,
+            amount_in,
+            min_amount_out,
+            reference_price,
+        )
+        .ok_or_else(|| {
+            ConversionError::ValidationFailed(format!(
+                "Unable to verify conversion rate against reference price {reference_price}"
+            ))
+        })?;
+
+        if deviation_bps > f64::from(self.max_deviation_bps) {
+            return Err(ConversionError::ValidationFailed(format!(
+                "Conversion rate deviates {deviation_bps:.0} bps from the reference price, above the allowed {} bps",
+                self.max_deviation_bps
+            )));
+        }
+
+        info!(
+            "Conversion rate for {token_identifier} verified: {deviation_bps:.0} bps adverse deviation"
+        );
+        Ok(())
+    }
+}
+
+/// Returns how much worse (in basis points) the quoted rate is than the
+/// reference price of one whole token in satoshis. A quote better than the
+/// reference yields 0. Returns `None` when the inputs can't form a rate.
+#[allow(clippy::cast_precision_loss)]
+fn adverse_deviation_bps(
+    conversion_type: &ConversionType,
+    decimals: u32,
+    amount_in: u128,
+    min_amount_out: u128,
+    reference_price_sats: f64,
+) -> Option<f64> {
+    if amount_in == 0
+        || min_amount_out == 0
+        || !reference_price_sats.is_finite()
+        || reference_price_sats <= 0.0
+    {
+        return None;
+    }
+
+    let scale = 10f64.powi(i32::try_from(decimals).ok()?);
+    let deviation = match conversion_type {
+        // Paying sats for tokens: a higher price per token is worse.
+        ConversionType::FromBitcoin => {
+            let quoted = amount_in as f64 / (min_amount_out as f64 / scale);
+            quoted / reference_price_sats - 1.0
+        }
+        // Selling tokens for sats: a lower price per token is worse.
+        ConversionType::ToBitcoin { .. } => {
+            let quoted = min_amount_out as f64 / (amount_in as f64 / scale);
+            1.0 - quoted / reference_price_sats
+        }
+    };
+
+    Some((deviation * 10_000.0).max(0.0))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn to_bitcoin() -> ConversionType {
+        ConversionType::ToBitcoin {
+            from_token_identifier: "token".to_string(),
+        }
+    }
+
+    #[test_all]
+    fn test_from_bitcoin_deviation() {
+        // 1 token (6 decimals) at 1_000 sats is fair against a 1_000 sats reference.
+        let fair =
+            adverse_deviation_bps(&ConversionType::FromBitcoin, 6, 1_000, 1_000_000, 1_000.0)
+                .unwrap();
+        assert!(fair.abs() < 1e-6);
+
+        // Paying 1_100 sats for the same token is 10% (1_000 bps) worse.
+        let worse =
+            adverse_deviation_bps(&ConversionType::FromBitcoin, 6, 1_100, 1_000_000, 1_000.0)
+                .unwrap();
+        assert!((worse - 1_000.0).abs() < 1e-6);
+
+        // Paying less than the reference is never adverse.
+        let better =
+            adverse_deviation_bps(&ConversionType::FromBitcoin, 6, 900, 1_000_000, 1_000.0)
+                .unwrap();
+        assert!(better.abs() < f64::EPSILON);
+    }
+
+    #[test_all]
+    fn test_to_bitcoin_deviation() {
+        // Selling 2 tokens for 1_800 sats is 10% worse than a 1_000 sats reference.
+        let worse = adverse_deviation_bps(&to_bitcoin(), 6, 2_000_000, 1_800, 1_000.0).unwrap();
+        assert!((worse - 1_000.0).abs() < 1e-6);
+
+        let better = adverse_deviation_bps(&to_bitcoin(), 6, 2_000_000, 2_200, 1_000.0).unwrap();
+        assert!(better.abs() < f64::EPSILON);
+    }
+
+    #[test_all]
+    fn test_invalid_inputs() {
+        assert!(adverse_deviation_bps(&to_bitcoin(), 6, 0, 1_800, 1_000.0).is_none());
+        assert!(adverse_deviation_bps(&to_bitcoin(), 6, 1, 0, 1_000.0).is_none());
+        assert!(adverse_deviation_bps(&to_bitcoin(), 6, 1, 1, 0.0).is_none());
+        assert!(adverse_deviation_bps(&to_bitcoin(), 6, 1, 1, f64::NAN).is_none());
+    }
+}
@@ -0,0 +1,197 @@
+use std::sync::Arc;
+
+use breez_sdk_common::fiat::FiatService;
+use flashnet::{BTC_ASSET_ADDRESS, FlashnetClient, ListPoolsRequest, Pool, PoolSortOrder};
+use tracing::warn;
+
+use crate::{Rate, ServiceConnectivityError, TokenFiatRates, TokenMetadata, TokenPriceService};
+
+/// Default [`TokenPriceService`], pricing each token by the Flashnet pool
+/// with the highest 24h volume trading it against bitcoin, and converting the
+/// price to fiat with the live bitcoin rates of the fiat service.
+pub(crate) struct FlashnetTokenPriceService {
+    flashnet_client: Arc<FlashnetClient>,
+    fiat_service: Arc<dyn FiatService>,
+}
+
+impl FlashnetTokenPriceService {
+    pub(crate) fn new(
+        flashnet_client: Arc<FlashnetClient>,
+        fiat_service: Arc<dyn FiatService>,
+    ) -> Self {
+        Self {
+            flashnet_client,
+            fiat_service,
+        }
+    }
+
+    /// Returns the price of one whole token in satoshis, or `None` if no
+    /// pool trades it against bitcoin
+    async fn fetch_token_price_sats(&self, token: &TokenMetadata) -> Option<f64> {
+        let (btc_a_pools, btc_b_pools) = tokio::join!(
+            self.flashnet_client.list_pools(ListPoolsRequest {
+                asset_a_address: Some(BTC_ASSET_ADDRESS.to_string()),
+                asset_b_address: Some(token.identifier.clone()),
+                sort: Some(PoolSortOrder::Volume24hDesc),
+                ..Default::default()
+            }),
+            self.flashnet_client.list_pools(ListPoolsRequest {
+                asset_a_address: Some(token.identifier.clone()),
+                asset_b_address: Some(BTC_ASSET_ADDRESS.to_string()),
+                sort: Some(PoolSortOrder::Volume24hDesc),
+                ..Default::default()
+            }),
+        );
+        let pools = [btc_a_pools, btc_b_pools]
+            .into_iter()
+            .filter_map(|res| match res {
+                Ok(res) => Some(res.pools),
+                Err(e) => {
+                    warn!("Failed to list pools for token {}: {e}", token.identifier);
+                    None
+                }
+            })
+            .flatten();
+        let pool = pools.max_by(|a, b| pool_volume_sats(a).total_cmp(&pool_volume_sats(b)))?;
+        pool_price_sats(&pool, token.decimals)
+    }
+}
+
+#[macros::async_trait]
+impl TokenPriceService for FlashnetTokenPriceService {
+    async fn fetch_token_fiat_rates(
+        &self,
+        tokens: Vec<TokenMetadata>,
+    ) -> Result<Vec<TokenFiatRates>, ServiceConnectivityError> {
+        if tokens.is_empty() {
+            return Ok(Vec::new());
+        }
+        let btc_rates = self.fiat_service.fetch_fiat_rates().await?;
+        let prices = futures::future::join_all(
+            tokens
+                .iter()
+                .map(|token| self.fetch_token_price_sats(token)),
+        )
+        .await;
+        Ok(tokens
+            .into_iter()
+            .zip(prices)
+            .filter_map(|(token, price_sats)| {
+                let price_btc = price_sats? / 100_000_000f64;
+                Some(TokenFiatRates {
+                    token_identifier: token.identifier,
+                    rates: btc_rates
+                        .iter()
+                        .map(|rate| Rate {
+                            coin: rate.coin.clone(),
+                            value: rate.value * price_btc,
+                        })
+                        .collect(),
+                })
+            })
+            .collect())
+    }
+}
+
+/// Returns the price of one whole token in satoshis from the spot price of a
+/// pool trading it against bitcoin. The pool price is in base units of asset
+/// B per base unit of asset A.
+fn pool_price_sats(pool: &Pool, decimals: u32) -> Option<f64> {
+    let price = pool
+        .current_price_a_in_b
+        .filter(|p| p.is_finite() && *p > 0.0)?;
+    let sats_per_base_unit = if pool.asset_a_address == BTC_ASSET_ADDRESS {
+        1.0 / price
+    } else {
+        price
+    };
+    Some(sats_per_base_unit * 10f64.powi(i32::try_from(decimals).ok()?))
+}
+
+/// Returns the 24h volume of a pool trading a token against bitcoin in
+/// satoshis, so that pools of both orientations compare. The volume is in
+/// base units of asset B, the token when bitcoin is asset A.
+#[allow(clippy::cast_precision_loss)]
+fn pool_volume_sats(pool: &Pool) -> f64 {
+    let volume = pool.volume_24h_asset_b.unwrap_or(0) as f64;
+    if pool.asset_a_address != BTC_ASSET_ADDRESS {
+        return volume;
+    }
+    match pool
+        .current_price_a_in_b
+        .filter(|p| p.is_finite() && *p > 0.0)
+    {
+        Some(price) => volume / price,
+        None => 0.0,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn pool(asset_a_address: &str, asset_b_address: &str, price: Option<f64>) -> Pool {
+        pool_with_volume(asset_a_address, asset_b_address, price, None)
+    }
+
+    fn pool_with_volume(
+        asset_a_address: &str,
+        asset_b_address: &str,
+        price: Option<f64>,
+        volume: Option<u64>,
+    ) -> Pool {
+        serde_json::from_value(serde_json::json!({
+            "lpPublicKey": "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+            "hostName": "host",
+            "hostFeeBps": 0,
+            "lpFeeBps": 0,
+            "assetAAddress": asset_a_address,
+            "assetBAddress": asset_b_address,
+            "currentPriceAInB": price.map(|p| p.to_string()),
+            "volume24hAssetB": volume.map(|v| v.to_string()),
+            "createdAt": "",
+            "updatedAt": "",
+        }))
+        .unwrap()
+    }
+
+    #[test_all]
+    fn test_pool_price_sats() {
+        // 2 token base units per sat, so a whole token of 6 decimals is 500,000 sats
+        let btc_a = pool(BTC_ASSET_ADDRESS, "tok", Some(2.0));
+        assert_eq!(pool_price_sats(&btc_a, 6), Some(500_000.0));
+
+        // 0.5 sats per token base unit
+        let token_a = pool("tok", BTC_ASSET_ADDRESS, Some(0.5));
+        assert_eq!(pool_price_sats(&token_a, 6), Some(500_000.0));
+
+        assert_eq!(
+            pool_price_sats(&pool("tok", BTC_ASSET_ADDRESS, None), 6),
+            None
+        );
+        assert_eq!(
+            pool_price_sats(&pool("tok", BTC_ASSET_ADDRESS, Some(0.0)), 6),
+            None
+        );
+    }
+
+    #[test_all]
+    fn test_pool_volume_sats_compares_both_orientations() {
+        // 1,000,000 token base units at 2 per sat is 500,000 sats
+        let btc_a = pool_with_volume(BTC_ASSET_ADDRESS, "tok", Some(2.0), Some(1_000_000));
+        assert!((pool_volume_sats(&btc_a) - 500_000.0).abs() < f64::EPSILON);
+
+        // The volume of a token/BTC pool is already in sats
+        let token_a = pool_with_volume("tok", BTC_ASSET_ADDRESS, Some(0.5), Some(600_000));
+        assert!((pool_volume_sats(&token_a) - 600_000.0).abs() < f64::EPSILON);
+        assert!(pool_volume_sats(&token_a) > pool_volume_sats(&btc_a));
+
+        let unpriced = pool_with_volume(BTC_ASSET_ADDRESS, "tok", None, Some(1_000_000));
+        assert!(pool_volume_sats(&unpriced).abs() < f64::EPSILON);
+    }
+}
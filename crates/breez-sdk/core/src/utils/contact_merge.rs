@@ -0,0 +1,170 @@
+use std::cmp::Ordering;
+
+use crate::{Contact, ListContactsRequest, Storage, persist::StorageError};
+
+/// Whether two contacts share a lightning address, the main one or an
+/// additional one. Lightning addresses are case-insensitive.
+pub(crate) fn is_duplicate_contact(a: &Contact, b: &Contact) -> bool {
+    std::iter::once(&b.payment_identifier)
+        .chain(&b.additional_payment_identifiers)
+        .any(|identifier| a.has_payment_identifier(identifier))
+}
+
+/// Merges two contacts for the same lightning address into one.
+///
+/// The merge doesn't depend on the order of the arguments, so devices merging
+/// the same pair end up with the same contact: the lowest id and the earliest
+/// creation time are kept, along with the details of the contact updated
+/// last. The additional addresses of both are kept, and the avatar and notes
+/// of the other contact fill in those the latest one doesn't have.
+pub(crate) fn merge_contacts(a: Contact, b: Contact) -> Contact {
+    let id = a.id.clone().min(b.id.clone());
+    let created_at = a.created_at.min(b.created_at);
+    let (latest, other) = match a
+        .updated_at
+        .cmp(&b.updated_at)
+        .then_with(|| a.name.cmp(&b.name))
+    {
+        Ordering::Less => (b, a),
+        _ => (a, b),
+    };
+    let mut additional_payment_identifiers = latest.additional_payment_identifiers.clone();
+    for identifier in
+        std::iter::once(other.payment_identifier).chain(other.additional_payment_identifiers)
+    {
+        let known = latest.payment_identifier.eq_ignore_ascii_case(&identifier)
+            || additional_payment_identifiers
+                .iter()
+                .any(|i| i.eq_ignore_ascii_case(&identifier));
+        if !known {
+            additional_payment_identifiers.push(identifier);
+        }
+    }
+    Contact {
+        id,
+        created_at,
+        additional_payment_identifiers,
+        avatar_url: latest.avatar_url.or(other.avatar_url),
+        notes: latest.notes.or(other.notes),
+        ..latest
+    }
+}
+
+/// Saves a contact, merging it with the stored contacts for the same
+/// lightning address so that an address is listed once. Returns the contact
+/// that was kept.
+pub(crate) async fn save_contact_merging_duplicates(
+    storage: &dyn Storage,
+    contact: Contact,
+) -> Result<Contact, StorageError> {
+    let duplicates: Vec<Contact> = storage
+        .list_contacts(ListContactsRequest {
+            offset: None,
+            limit: None,
+        })
+        .await?
+        .into_iter()
+        .filter(|c| c.id != contact.id && is_duplicate_contact(c, &contact))
+        .collect();
+
+    let contact_id = contact.id.clone();
+    let duplicate_ids: Vec<String> = duplicates.iter().map(|c| c.id.clone()).collect();
+    let kept = duplicates.into_iter().fold(contact, merge_contacts);
+
+    storage.insert_contact(kept.clone()).await?;
+    for id in duplicate_ids.into_iter().filter(|id| *id != kept.id) {
+        storage.delete_contact(id).await?;
+    }
+    if contact_id != kept.id {
+        // The contact may not have been stored before. Ignore not-found errors.
+        let _ = storage.delete_contact(contact_id).await;
+    }
+    Ok(kept)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn contact(id: &str, name: &str, address: &str, updated_at: u64) -> Contact {
+        Contact {
+            id: id.to_string(),
+            name: name.to_string(),
+            payment_identifier: address.to_string(),
+            additional_payment_identifiers: Vec::new(),
+            avatar_url: None,
+            notes: None,
+            created_at: updated_at,
+            updated_at,
+        }
+    }
+
+    #[test]
+    fn test_is_duplicate_ignores_case() {
+        let a = contact("a", "Alice", "alice@example.com", 1);
+        let b = contact("b", "Al", " Alice@Example.com", 2);
+        let c = contact("c", "Bob", "bob@example.com", 3);
+        assert!(is_duplicate_contact(&a, &b));
+        assert!(!is_duplicate_contact(&a, &c));
+    }
+
+    #[test]
+    fn test_is_duplicate_matches_additional_addresses() {
+        let mut a = contact("a", "Alice", "alice@example.com", 1);
+        a.additional_payment_identifiers = vec!["alice@other.com".to_string()];
+        let b = contact("b", "Alice", "Alice@Other.com", 2);
+        let c = contact("c", "Bob", "bob@example.com", 3);
+        assert!(is_duplicate_contact(&a, &b));
+        assert!(is_duplicate_contact(&b, &a));
+        assert!(!is_duplicate_contact(&a, &c));
+    }
+
+    #[test]
+    fn test_merge_keeps_lowest_id_and_latest_name() {
+        let older = contact("b", "Alice", "alice@example.com", 10);
+        let newer = contact("a", "Alice Smith", "Alice@example.com", 20);
+
+        let merged = merge_contacts(older.clone(), newer.clone());
+        assert_eq!(merged.id, "a");
+        assert_eq!(merged.name, "Alice Smith");
+        assert_eq!(merged.payment_identifier, "Alice@example.com");
+        assert_eq!(merged.created_at, 10);
+        assert_eq!(merged.updated_at, 20);
+
+        let reversed = merge_contacts(newer, older);
+        assert_eq!(reversed.id, merged.id);
+        assert_eq!(reversed.name, merged.name);
+        assert_eq!(reversed.created_at, merged.created_at);
+    }
+
+    #[test]
+    fn test_merge_keeps_details_of_both() {
+        let mut older = contact("a", "Alice", "alice@example.com", 10);
+        older.additional_payment_identifiers = vec!["alice@other.com".to_string()];
+        older.notes = Some("Neighbour".to_string());
+        let mut newer = contact("b", "Alice", "alice@example.com", 20);
+        newer.additional_payment_identifiers = vec!["Alice@Other.com".to_string()];
+        newer.avatar_url = Some("https://example.com/alice.png".to_string());
+
+        let merged = merge_contacts(older, newer);
+        assert_eq!(
+            merged.additional_payment_identifiers,
+            vec!["Alice@Other.com"]
+        );
+        assert_eq!(
+            merged.avatar_url.as_deref(),
+            Some("https://example.com/alice.png")
+        );
+        assert_eq!(merged.notes.as_deref(), Some("Neighbour"));
+    }
+
+    #[test]
+    fn test_merge_is_symmetric_on_ties() {
+        let a = contact("a", "Alice", "alice@example.com", 10);
+        let b = contact("b", "Ally", "alice@example.com", 10);
+        assert_eq!(
+            merge_contacts(a.clone(), b.clone()).name,
+            merge_contacts(b, a).name
+        );
+    }
+}
@@ -2,6 +2,18 @@ use breez_sdk_common::input::validate_lightning_address_format;
 
 use crate::SdkError;
 
+const MAX_ADDITIONAL_PAYMENT_IDENTIFIERS: usize = 20;
+/// Large enough for a small image inlined as a `data:` URL
+const MAX_AVATAR_URL_LEN: usize = 100_000;
+const MAX_NOTES_LEN: usize = 1000;
+
+/// The optional details of a contact, validated and trimmed
+pub struct ContactDetails {
+    pub additional_payment_identifiers: Vec<String>,
+    pub avatar_url: Option<String>,
+    pub notes: Option<String>,
+}
+
 /// Validates contact input, returns trimmed name on success
 pub fn validate_contact_input(name: &str, payment_identifier: &str) -> Result<String, SdkError> {
     let name = name.trim().to_string();
@@ -15,7 +27,69 @@ pub fn validate_contact_input(name: &str, payment_identifier: &str) -> Result<St
             "Contact name cannot exceed 100 characters".to_string(),
         ));
     }
-    let payment_identifier = payment_identifier.trim();
+    validate_payment_identifier(payment_identifier.trim())?;
+    Ok(name)
+}
+
+/// Validates the optional contact details. Additional payment identifiers
+/// that repeat the main one or each other are dropped, and empty avatar URLs
+/// and notes are unset.
+pub fn validate_contact_details(
+    payment_identifier: &str,
+    additional_payment_identifiers: &[String],
+    avatar_url: Option<&str>,
+    notes: Option<&str>,
+) -> Result<ContactDetails, SdkError> {
+    if additional_payment_identifiers.len() > MAX_ADDITIONAL_PAYMENT_IDENTIFIERS {
+        return Err(SdkError::InvalidInput(format!(
+            "A contact cannot have more than {MAX_ADDITIONAL_PAYMENT_IDENTIFIERS} additional payment identifiers"
+        )));
+    }
+    let mut identifiers: Vec<String> = Vec::new();
+    for identifier in additional_payment_identifiers {
+        let identifier = identifier.trim();
+        validate_payment_identifier(identifier)?;
+        if !identifier.eq_ignore_ascii_case(payment_identifier.trim())
+            && !identifiers
+                .iter()
+                .any(|i| i.eq_ignore_ascii_case(identifier))
+        {
+            identifiers.push(identifier.to_string());
+        }
+    }
+
+    let avatar_url = avatar_url.map(str::trim).filter(|url| !url.is_empty());
+    if let Some(url) = avatar_url {
+        if url.len() > MAX_AVATAR_URL_LEN {
+            return Err(SdkError::InvalidInput(format!(
+                "Avatar URL cannot exceed {MAX_AVATAR_URL_LEN} characters"
+            )));
+        }
+        if !["https://", "http://", "data:image/"]
+            .iter()
+            .any(|prefix| url.starts_with(prefix))
+        {
+            return Err(SdkError::InvalidInput(
+                "Avatar URL must be an http(s) URL or a data:image URL".to_string(),
+            ));
+        }
+    }
+
+    let notes = notes.map(str::trim).filter(|notes| !notes.is_empty());
+    if notes.is_some_and(|notes| notes.chars().count() > MAX_NOTES_LEN) {
+        return Err(SdkError::InvalidInput(format!(
+            "Contact notes cannot exceed {MAX_NOTES_LEN} characters"
+        )));
+    }
+
+    Ok(ContactDetails {
+        additional_payment_identifiers: identifiers,
+        avatar_url: avatar_url.map(ToString::to_string),
+        notes: notes.map(ToString::to_string),
+    })
+}
+
+fn validate_payment_identifier(payment_identifier: &str) -> Result<(), SdkError> {
     if payment_identifier.is_empty() {
         return Err(SdkError::InvalidInput(
             "Payment identifier cannot be empty".to_string(),
@@ -32,8 +106,7 @@ pub fn validate_contact_input(name: &str, payment_identifier: &str) -> Result<St
             "Payment identifier must be a valid lightning address (user@domain)".to_string(),
         ));
     }
-
-    Ok(name)
+    Ok(())
 }
 
 #[cfg(test)]
@@ -75,4 +148,38 @@ mod tests {
     fn test_rejects_empty_payment_identifier() {
         assert!(validate_contact_input(VALID_NAME, "").is_err());
     }
+
+    #[test]
+    fn test_contact_details_are_trimmed_and_deduplicated() {
+        let details = validate_contact_details(
+            "alice@example.com",
+            &[
+                " Alice@example.com".to_string(),
+                "alice@other.com ".to_string(),
+                "ALICE@other.com".to_string(),
+            ],
+            Some(" "),
+            Some(" Met at the conference "),
+        )
+        .unwrap();
+        assert_eq!(
+            details.additional_payment_identifiers,
+            vec!["alice@other.com"]
+        );
+        assert_eq!(details.avatar_url, None);
+        assert_eq!(details.notes.as_deref(), Some("Met at the conference"));
+    }
+
+    #[test]
+    fn test_contact_details_reject_invalid_values() {
+        let details = |ids: &[&str], avatar: Option<&str>, notes: Option<&str>| {
+            let ids: Vec<String> = ids.iter().map(ToString::to_string).collect();
+            validate_contact_details("alice@example.com", &ids, avatar, notes)
+        };
+        assert!(details(&["not_an_address"], None, None).is_err());
+        assert!(details(&[], Some("ftp://example.com/a.png"), None).is_err());
+        assert!(details(&[], None, Some(&"a".repeat(1001))).is_err());
+        assert!(details(&[], Some("data:image/png;base64,AAAA"), None).is_ok());
+        assert!(details(&[], Some("https://example.com/a.png"), None).is_ok());
+    }
 }
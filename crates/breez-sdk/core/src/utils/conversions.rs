@@ -541,6 +541,8 @@ mod tests {
                 conversion_info: Some(info),
             }),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -565,6 +567,8 @@ mod tests {
                 conversion_info: Some(info),
             }),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -593,6 +597,8 @@ mod tests {
                 conversion_info: Some(info),
             }),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -664,6 +670,8 @@ mod tests {
                 status: ConversionStatus::Completed,
                 conversions: vec![],
             }),
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -690,6 +698,8 @@ mod tests {
                 status: ConversionStatus::Completed,
                 conversions: vec![],
             }),
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -711,6 +721,8 @@ mod tests {
                 status: ConversionStatus::Completed,
                 conversions: vec![],
             }),
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -1,22 +1,36 @@
-use platform_utils::time::{SystemTime, UNIX_EPOCH};
+use std::sync::Arc;
+
 use platform_utils::tokio;
 use tokio::sync::RwLock;
 
+use crate::{Clock, sdk::clock_now_ms};
+
 /// A cell that holds a value with a time-to-live (TTL) expiration.
 ///
 /// Similar to `OnceCell`, but the cached value expires after a specified duration.
 /// After expiration, `get()` returns `None` and a new value can be set.
 pub(crate) struct ExpiringCell<T> {
     inner: RwLock<Option<(T, u128)>>, // (value, expiration_ms)
+    /// Clock the value expires by, unset to use the system clock
+    clock: Option<Arc<dyn Clock>>,
 }
 
 impl<T> ExpiringCell<T> {
     pub fn new() -> Self {
         Self {
             inner: RwLock::new(None),
+            clock: None,
         }
     }
 
+    /// Expires the value by `clock`, e.g. the one set with
+    /// `SdkBuilder::with_clock`, instead of the system clock.
+    #[must_use]
+    pub fn with_clock(mut self, clock: Option<Arc<dyn Clock>>) -> Self {
+        self.clock = clock;
+        self
+    }
+
     /// Clears the cached value, if any.
     pub async fn clear(&self) {
         *self.inner.write().await = None;
@@ -28,10 +42,7 @@ impl<T: Clone> ExpiringCell<T> {
     pub async fn get(&self) -> Option<T> {
         let guard = self.inner.read().await;
         let (value, expiration) = guard.as_ref()?;
-        let now = SystemTime::now()
-            .duration_since(UNIX_EPOCH)
-            .ok()?
-            .as_millis();
+        let now = clock_now_ms(self.clock.as_ref());
         if now < *expiration {
             Some(value.clone())
         } else {
@@ -41,9 +52,7 @@ impl<T: Clone> ExpiringCell<T> {
 
     /// Sets a new value with the specified TTL in milliseconds.
     pub async fn set(&self, value: T, ttl_ms: u128) {
-        let expiration = SystemTime::now()
-            .duration_since(UNIX_EPOCH)
-            .map_or(0, |d| d.as_millis().saturating_add(ttl_ms));
+        let expiration = clock_now_ms(self.clock.as_ref()).saturating_add(ttl_ms);
         *self.inner.write().await = Some((value, expiration));
     }
 }
@@ -0,0 +1,272 @@
+use std::{future::Future, sync::Arc};
+
+use tokio::sync::Mutex;
+use tracing::warn;
+
+use crate::{
+    Payment, Storage,
+    error::SdkError,
+    persist::{CachedIdempotencyRecord, IdempotentOperation, ObjectCacheRepository},
+};
+
+/// Serializes the check and reservation of idempotency keys, so two concurrent
+/// calls with the same key can't both reserve it
+static RESERVATION_LOCK: Mutex<()> = Mutex::const_new(());
+
+/// Returns the record of a previous call with the same idempotency key, or
+/// reserves the key for `operation` if there is none. The check and the
+/// reservation happen atomically.
+pub(crate) async fn begin_idempotent_operation(
+    cache: &ObjectCacheRepository,
+    idempotency_key: &str,
+    operation: IdempotentOperation,
+) -> Result<Option<CachedIdempotencyRecord>, SdkError> {
+    uuid::Uuid::parse_str(idempotency_key)
+        .map_err(|_| SdkError::InvalidUuid(idempotency_key.to_string()))?;
+
+    let _guard = RESERVATION_LOCK.lock().await;
+    match cache.fetch_idempotency_record(idempotency_key).await? {
+        Some(record) if record.operation != operation => Err(SdkError::InvalidInput(format!(
+            "Idempotency key was already used for a different operation ({:?})",
+            record.operation
+        ))),
+        Some(record) => Ok(Some(record)),
+        None => {
+            cache
+                .save_idempotency_record(idempotency_key, &CachedIdempotencyRecord::new(operation))
+                .await?;
+            Ok(None)
+        }
+    }
+}
+
+/// Runs an operation that produces a payment at most once per idempotency key.
+///
+/// A retry of a completed call returns the same payment. A call that failed
+/// before anything was sent releases the key so it can be retried. A call that
+/// failed in a way that leaves its outcome unknown, or was interrupted before
+/// reporting its outcome, keeps the key reserved, and retries fail with
+/// [`SdkError::IdempotencyKeyInUse`] rather than risk running it twice.
+pub(crate) async fn run_idempotent_payment<F, Fut>(
+    storage: Arc<dyn Storage>,
+    idempotency_key: Option<&str>,
+    operation: IdempotentOperation,
+    run: F,
+) -> Result<Payment, SdkError>
+where
+    F: FnOnce() -> Fut,
+    Fut: Future<Output = Result<Payment, SdkError>>,
+{
+    let Some(idempotency_key) = idempotency_key else {
+        return run().await;
+    };
+
+    let cache = ObjectCacheRepository::new(storage.clone());
+    if let Some(record) = begin_idempotent_operation(&cache, idempotency_key, operation).await? {
+        return match record.payment_id {
+            Some(payment_id) if record.completed => {
+                Ok(storage.get_payment_by_id(payment_id).await?)
+            }
+            _ => Err(SdkError::IdempotencyKeyInUse(format!(
+                "a previous {operation:?} call with this key did not complete"
+            ))),
+        };
+    }
+
+    match run().await {
+        Ok(payment) => {
+            let mut record = CachedIdempotencyRecord::new(operation);
+            record.completed = true;
+            record.payment_id = Some(payment.id.clone());
+            if let Err(e) = cache
+                .save_idempotency_record(idempotency_key, &record)
+                .await
+            {
+                // The key stays reserved, so retries fail instead of repeating the operation
+                warn!(
+                    "Failed to save result of {operation:?} for idempotency key {idempotency_key}: {e}"
+                );
+            }
+            Ok(payment)
+        }
+        Err(e) if fails_before_sending(&e) => {
+            cache.delete_idempotency_record(idempotency_key).await?;
+            Err(e)
+        }
+        Err(e) => {
+            // The operation may have gone out, so the key stays reserved
+            warn!(
+                "{operation:?} with idempotency key {idempotency_key} failed with an unknown outcome: {e}"
+            );
+            Err(e)
+        }
+    }
+}
+
+/// Whether the error is only returned before the operation sends anything,
+/// so running it again can't repeat it
+pub(crate) fn fails_before_sending(error: &SdkError) -> bool {
+    matches!(
+        error,
+        SdkError::InvalidInput(_)
+            | SdkError::InvalidUuid(_)
+            | SdkError::InsufficientFunds
+            | SdkError::MaxFeeExceeded { .. }
+            | SdkError::MaxDepositClaimFeeExceeded { .. }
+            | SdkError::PaymentRejected(_)
+            | SdkError::WalletFrozen
+            | SdkError::IssuerApprovalFailed(_)
+            | SdkError::PaymentRailDisabled { .. }
+            | SdkError::SpendingCapExceeded { .. }
+    )
+}
+
+#[cfg(all(test, not(target_family = "wasm")))]
+mod tests {
+    use super::*;
+    use crate::{PaymentType, persist::sqlite::SqliteStorage, sdk::ledger::tests::payment};
+
+    fn temp_storage() -> Arc<dyn Storage> {
+        let mut dir = std::env::temp_dir();
+        dir.push(format!("breez-idempotency-test-{}", uuid::Uuid::new_v4()));
+        Arc::new(SqliteStorage::new(&dir).expect("create sqlite storage"))
+    }
+
+    #[tokio::test]
+    async fn test_completed_call_returns_same_payment() {
+        let storage = temp_storage();
+        let key = uuid::Uuid::now_v7().to_string();
+        let first = run_idempotent_payment(
+            storage.clone(),
+            Some(&key),
+            IdempotentOperation::MintIssuerToken,
+            || async {
+                let payment = payment("mint-1", PaymentType::Send, 1_000, 0, 1_700_000_000);
+                storage.apply_payment_update(payment.clone()).await?;
+                Ok(payment)
+            },
+        )
+        .await
+        .unwrap();
+
+        let retry = run_idempotent_payment(
+            storage.clone(),
+            Some(&key),
+            IdempotentOperation::MintIssuerToken,
+            || async { panic!("operation must not run again") },
+        )
+        .await
+        .unwrap();
+        assert_eq!(retry.id, first.id);
+    }
+
+    #[tokio::test]
+    async fn test_failed_call_releases_key() {
+        let storage = temp_storage();
+        let key = uuid::Uuid::now_v7().to_string();
+        let res = run_idempotent_payment(
+            storage.clone(),
+            Some(&key),
+            IdempotentOperation::BurnIssuerToken,
+            || async { Err(SdkError::InsufficientFunds) },
+        )
+        .await;
+        assert!(res.is_err());
+
+        let retry = run_idempotent_payment(
+            storage.clone(),
+            Some(&key),
+            IdempotentOperation::BurnIssuerToken,
+            || async {
+                Ok(payment(
+                    "burn-1",
+                    PaymentType::Send,
+                    1_000,
+                    0,
+                    1_700_000_000,
+                ))
+            },
+        )
+        .await
+        .unwrap();
+        assert_eq!(retry.id, "burn-1");
+    }
+
+    #[tokio::test]
+    async fn test_call_with_unknown_outcome_keeps_key() {
+        let storage = temp_storage();
+        let key = uuid::Uuid::now_v7().to_string();
+        let res = run_idempotent_payment(
+            storage.clone(),
+            Some(&key),
+            IdempotentOperation::TokenSend,
+            || async { Err(SdkError::NetworkError("timeout".to_string())) },
+        )
+        .await;
+        assert!(res.is_err());
+
+        let retry = run_idempotent_payment(
+            storage.clone(),
+            Some(&key),
+            IdempotentOperation::TokenSend,
+            || async { panic!("operation must not run again") },
+        )
+        .await;
+        assert!(matches!(retry, Err(SdkError::IdempotencyKeyInUse(_))));
+    }
+
+    #[tokio::test]
+    async fn test_concurrent_calls_reserve_key_once() {
+        let storage = temp_storage();
+        let cache = ObjectCacheRepository::new(storage);
+        let key = uuid::Uuid::now_v7().to_string();
+        let (first, second) = tokio::join!(
+            begin_idempotent_operation(&cache, &key, IdempotentOperation::TokenSend),
+            begin_idempotent_operation(&cache, &key, IdempotentOperation::TokenSend),
+        );
+        let reserved = [first.unwrap(), second.unwrap()]
+            .iter()
+            .filter(|record| record.is_none())
+            .count();
+        assert_eq!(reserved, 1);
+    }
+
+    #[tokio::test]
+    async fn test_interrupted_call_is_not_retried() {
+        let storage = temp_storage();
+        let cache = ObjectCacheRepository::new(storage.clone());
+        let key = uuid::Uuid::now_v7().to_string();
+        // A call that reserved the key but never recorded its outcome
+        begin_idempotent_operation(&cache, &key, IdempotentOperation::TokenSend)
+            .await
+            .unwrap();
+
+        let res = run_idempotent_payment(
+            storage.clone(),
+            Some(&key),
+            IdempotentOperation::TokenSend,
+            || async { panic!("operation must not run again") },
+        )
+        .await;
+        assert!(matches!(res, Err(SdkError::IdempotencyKeyInUse(_))));
+    }
+
+    #[tokio::test]
+    async fn test_key_reuse_across_operations_is_rejected() {
+        let storage = temp_storage();
+        let cache = ObjectCacheRepository::new(storage);
+        let key = uuid::Uuid::now_v7().to_string();
+        begin_idempotent_operation(&cache, &key, IdempotentOperation::ClaimDeposit)
+            .await
+            .unwrap();
+
+        let res =
+            begin_idempotent_operation(&cache, &key, IdempotentOperation::LnurlWithdraw).await;
+        assert!(matches!(res, Err(SdkError::InvalidInput(_))));
+
+        let res =
+            begin_idempotent_operation(&cache, "not-a-uuid", IdempotentOperation::ClaimDeposit)
+                .await;
+        assert!(matches!(res, Err(SdkError::InvalidUuid(_))));
+    }
+}
@@ -1,11 +1,15 @@
 pub(crate) mod bitcoin_dust;
+pub(crate) mod contact_merge;
 pub(crate) mod contacts_validation;
 pub(crate) mod conversions;
 pub(crate) mod deposit_chain_syncer;
 pub(crate) mod expiring_cell;
 pub(crate) mod fees;
+pub(crate) mod idempotency;
+pub(crate) mod parse_cache;
 pub(crate) mod payments;
 pub(crate) mod polling;
+pub(crate) mod secret;
 pub mod serde_helpers;
 pub(crate) mod token;
 pub(crate) mod utxo_fetcher;
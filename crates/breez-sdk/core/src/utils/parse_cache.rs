@@ -0,0 +1,188 @@
+use std::collections::HashMap;
+use std::sync::{Arc, Mutex};
+
+use crate::{Clock, InputType, sdk::clock_now_ms};
+
+/// Maximum number of parse results kept, the least recently used is evicted
+/// first
+const PARSE_CACHE_CAPACITY: usize = 100;
+
+/// A least recently used cache of the inputs parsed with a network lookup,
+/// such as Lightning addresses and LNURLs, so that scanning the same code
+/// again doesn't repeat the lookup.
+///
+/// LNURL-withdraw and LNURL-auth results aren't cached: the `k1` the server
+/// issues with them may be single-use or rotate.
+pub(crate) struct ParseCache {
+    ttl_ms: u128,
+    clock: Option<Arc<dyn Clock>>,
+    entries: Mutex<HashMap<String, CachedParse>>,
+}
+
+struct CachedParse {
+    input_type: InputType,
+    expires_at_ms: u128,
+    last_used_ms: u128,
+}
+
+impl ParseCache {
+    /// Creates a cache keeping results for `ttl_secs`. A TTL of 0 disables
+    /// caching.
+    pub fn new(ttl_secs: u32, clock: Option<Arc<dyn Clock>>) -> Self {
+        Self {
+            ttl_ms: u128::from(ttl_secs) * 1000,
+            clock,
+            entries: Mutex::new(HashMap::new()),
+        }
+    }
+
+    pub fn is_enabled(&self) -> bool {
+        self.ttl_ms > 0
+    }
+
+    /// Returns the cached result for the input if it hasn't expired
+    pub fn get(&self, input: &str) -> Option<InputType> {
+        self.get_at(input, clock_now_ms(self.clock.as_ref()))
+    }
+
+    pub fn insert(&self, input: &str, input_type: InputType) {
+        self.insert_at(input, input_type, clock_now_ms(self.clock.as_ref()));
+    }
+
+    fn get_at(&self, input: &str, now_ms: u128) -> Option<InputType> {
+        if !self.is_enabled() {
+            return None;
+        }
+        let mut entries = self.entries.lock().ok()?;
+        let entry = entries.get_mut(input)?;
+        if entry.expires_at_ms <= now_ms {
+            entries.remove(input);
+            return None;
+        }
+        entry.last_used_ms = now_ms;
+        Some(entry.input_type.clone())
+    }
+
+    fn insert_at(&self, input: &str, input_type: InputType, now_ms: u128) {
+        if !self.is_enabled()
+            || matches!(
+                input_type,
+                InputType::LnurlWithdraw(_) | InputType::LnurlAuth(_)
+            )
+        {
+            return;
+        }
+        let Ok(mut entries) = self.entries.lock() else {
+            return;
+        };
+        entries.retain(|_, entry| entry.expires_at_ms > now_ms);
+        if entries.len() >= PARSE_CACHE_CAPACITY
+            && !entries.contains_key(input)
+            && let Some(lru) = entries
+                .iter()
+                .min_by_key(|(_, entry)| entry.last_used_ms)
+                .map(|(key, _)| key.clone())
+        {
+            entries.remove(&lru);
+        }
+        entries.insert(
+            input.to_string(),
+            CachedParse {
+                input_type,
+                expires_at_ms: now_ms.saturating_add(self.ttl_ms),
+                last_used_ms: now_ms,
+            },
+        );
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use macros::test_all;
+
+    use super::*;
+    use crate::{Bip21Details, LnurlAuthRequestDetails, LnurlWithdrawRequestDetails};
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    fn input_type(uri: &str) -> InputType {
+        InputType::Bip21(Bip21Details {
+            amount_sat: None,
+            asset_id: None,
+            uri: uri.to_string(),
+            extras: Vec::new(),
+            label: None,
+            message: None,
+            payment_methods: Vec::new(),
+        })
+    }
+
+    fn cached_uri(cache: &ParseCache, input: &str, now_ms: u128) -> Option<String> {
+        match cache.get_at(input, now_ms)? {
+            InputType::Bip21(details) => Some(details.uri),
+            _ => None,
+        }
+    }
+
+    #[test_all]
+    fn test_entries_expire_after_ttl() {
+        let cache = ParseCache::new(60, None);
+        cache.insert_at("alice@example.com", input_type("a"), 1_000);
+        assert_eq!(
+            cached_uri(&cache, "alice@example.com", 60_999).as_deref(),
+            Some("a")
+        );
+        assert_eq!(cached_uri(&cache, "alice@example.com", 61_000), None);
+    }
+
+    #[test_all]
+    fn test_evicts_least_recently_used() {
+        let cache = ParseCache::new(60, None);
+        for i in 0..PARSE_CACHE_CAPACITY {
+            cache.insert_at(&format!("user{i}@example.com"), input_type("u"), i as u128);
+        }
+        // Using the oldest entry makes the second oldest the least recently used
+        assert!(cached_uri(&cache, "user0@example.com", 1_000).is_some());
+        cache.insert_at("new@example.com", input_type("n"), 1_001);
+
+        assert!(cached_uri(&cache, "user0@example.com", 1_002).is_some());
+        assert!(cached_uri(&cache, "user1@example.com", 1_002).is_none());
+        assert!(cached_uri(&cache, "new@example.com", 1_002).is_some());
+    }
+
+    #[test_all]
+    fn test_skips_lnurl_withdraw_and_auth() {
+        let cache = ParseCache::new(60, None);
+        cache.insert_at(
+            "lnurlw",
+            InputType::LnurlWithdraw(LnurlWithdrawRequestDetails {
+                callback: "https://example.com/withdraw".to_string(),
+                k1: "k1".to_string(),
+                default_description: String::new(),
+                min_withdrawable: 1_000,
+                max_withdrawable: 2_000,
+            }),
+            1_000,
+        );
+        cache.insert_at(
+            "lnurla",
+            InputType::LnurlAuth(LnurlAuthRequestDetails {
+                k1: "k1".to_string(),
+                action: None,
+                domain: "example.com".to_string(),
+                url: "https://example.com/auth".to_string(),
+            }),
+            1_000,
+        );
+        assert!(cache.get_at("lnurlw", 1_000).is_none());
+        assert!(cache.get_at("lnurla", 1_000).is_none());
+    }
+
+    #[test_all]
+    fn test_zero_ttl_disables_cache() {
+        let cache = ParseCache::new(0, None);
+        cache.insert_at("alice@example.com", input_type("a"), 1_000);
+        assert_eq!(cached_uri(&cache, "alice@example.com", 1_000), None);
+    }
+}
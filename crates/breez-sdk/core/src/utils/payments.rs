@@ -8,8 +8,8 @@ use spark_wallet::{
 use tracing::{debug, error, info, warn};
 
 use crate::{
-    ConversionInfo, ConversionStatus, EventEmitter, Payment, PaymentMetadata, PaymentStatus,
-    PaymentType, Storage,
+    ConversionInfo, ConversionStatus, EventEmitter, Payment, PaymentDetails, PaymentMetadata,
+    PaymentStatus, PaymentType, Storage,
     error::SdkError,
     events::SdkEvent,
     persist::{CachedAccountInfo, ObjectCacheRepository},
@@ -52,7 +52,7 @@ pub(crate) async fn get_payment_and_emit_event(
     event_emitter: &EventEmitter,
     payment: Payment,
 ) {
-    let payment =
+    let mut payment =
         match get_payment_with_conversion_details(payment.id.clone(), Arc::clone(storage)).await {
             Ok(payment) => payment,
             Err(e) => {
@@ -60,8 +60,77 @@ pub(crate) async fn get_payment_and_emit_event(
                 payment
             }
         };
+    crate::sdk::complete_lnurl_success_action(storage, &mut payment).await;
     info!("Emitting payment event: {payment:?}");
-    event_emitter.emit(&SdkEvent::from_payment(payment)).await;
+    event_emitter
+        .emit(&SdkEvent::from_payment(payment.clone()))
+        .await;
+    track_partial_invoice_payment(storage, event_emitter, &payment).await;
+}
+
+/// Aggregates a completed received payment towards a Spark invoice accepting
+/// partial payments and emits its progress. Payments not fulfilling such an
+/// invoice, or already counted, are ignored.
+async fn track_partial_invoice_payment(
+    storage: &Arc<dyn Storage>,
+    event_emitter: &EventEmitter,
+    payment: &Payment,
+) {
+    if payment.payment_type != PaymentType::Receive || payment.status != PaymentStatus::Completed {
+        return;
+    }
+    let Some(
+        PaymentDetails::Spark {
+            invoice_details: Some(invoice_details),
+            ..
+        }
+        | PaymentDetails::Token {
+            invoice_details: Some(invoice_details),
+            ..
+        },
+    ) = &payment.details
+    else {
+        return;
+    };
+    let invoice = &invoice_details.invoice;
+
+    let cache = ObjectCacheRepository::new(Arc::clone(storage));
+    let mut partial_invoice = match cache.fetch_partial_invoice(invoice).await {
+        Ok(Some(partial_invoice)) => partial_invoice,
+        Ok(None) => return,
+        Err(e) => {
+            error!(
+                "Failed to fetch partial invoice for payment {}: {e:?}",
+                payment.id
+            );
+            return;
+        }
+    };
+    if partial_invoice.payment_ids.contains(&payment.id) {
+        return;
+    }
+    partial_invoice.received_amount = partial_invoice
+        .received_amount
+        .saturating_add(payment.amount);
+    partial_invoice.payment_ids.push(payment.id.clone());
+    if let Err(e) = cache.save_partial_invoice(invoice, &partial_invoice).await {
+        error!(
+            "Failed to save partial invoice for payment {}: {e:?}",
+            payment.id
+        );
+        return;
+    }
+
+    event_emitter
+        .emit(&SdkEvent::PartialInvoicePaymentProgress {
+            invoice: invoice.clone(),
+            payment_id: payment.id.clone(),
+            received_amount: partial_invoice.received_amount,
+            target_amount: partial_invoice.target_amount,
+            token_identifier: partial_invoice.token_identifier,
+            completed: partial_invoice.received_amount >= partial_invoice.target_amount,
+        })
+        .await;
 }
 
 /// Process an already-fetched Spark transfer, claiming it locally if
@@ -280,6 +349,7 @@ pub async fn get_payment_with_conversion_details(
     storage: Arc<dyn Storage>,
 ) -> Result<Payment, SdkError> {
     let mut payment = storage.get_payment_by_id(id).await?;
+    payment.fill_receive_metadata();
     enrich_payment_conversions(&mut payment, &storage).await?;
     Ok(payment)
 }
@@ -566,6 +636,8 @@ mod tests {
                 conversion_info: Some(amm_info()),
             }),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -584,6 +656,8 @@ mod tests {
                 conversion_info: Some(amm_info()),
             }),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -655,6 +729,8 @@ mod tests {
                 status: ConversionStatus::Completed,
                 conversions: vec![],
             }),
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -681,6 +757,8 @@ mod tests {
                 status: ConversionStatus::Completed,
                 conversions: vec![],
             }),
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -702,6 +780,8 @@ mod tests {
                 status: ConversionStatus::Completed,
                 conversions: vec![],
             }),
+            fiat_value: None,
+            receive_metadata: None,
         }
     }
 
@@ -0,0 +1,210 @@
+use std::{fmt, str::FromStr};
+
+use bitcoin::secp256k1::rand::{RngCore, thread_rng};
+use serde::{Deserialize, Serialize};
+use subtle::ConstantTimeEq;
+
+use crate::error::SdkError;
+
+/// scrypt cost parameters for new hashes: 32 MiB of memory per hash, which
+/// keeps a check interactive on a phone while making offline guessing of
+/// short passwords and PINs expensive. Tests use a lower cost, which the
+/// stored parameters make safe.
+#[cfg(not(test))]
+const SCRYPT_LOG_N: u8 = 15;
+#[cfg(test)]
+const SCRYPT_LOG_N: u8 = 4;
+const SCRYPT_R: u32 = 8;
+const SCRYPT_P: u32 = 1;
+const HASH_LEN: usize = 32;
+
+/// Failed attempts allowed before checks are throttled.
+const FREE_ATTEMPTS: u32 = 5;
+/// Wait after the first throttled attempt, doubled on each further failure.
+const BASE_BACKOFF_SECS: u64 = 30;
+const MAX_BACKOFF_SECS: u64 = 24 * 60 * 60;
+
+/// A password or PIN hashed with scrypt. The cost parameters are stored with
+/// the hash, so they can be raised for new hashes without breaking the
+/// stored ones.
+#[derive(Clone, Debug, Serialize, Deserialize)]
+pub(crate) struct SecretHash {
+    salt: String,
+    hash: String,
+    log_n: u8,
+    r: u32,
+    p: u32,
+}
+
+impl SecretHash {
+    pub(crate) fn new(secret: &str) -> Result<Self, SdkError> {
+        let mut salt = [0u8; 16];
+        thread_rng().fill_bytes(&mut salt);
+        let hash = scrypt_hash(secret, &salt, SCRYPT_LOG_N, SCRYPT_R, SCRYPT_P)?;
+        Ok(Self {
+            salt: hex::encode(salt),
+            hash: hex::encode(hash),
+            log_n: SCRYPT_LOG_N,
+            r: SCRYPT_R,
+            p: SCRYPT_P,
+        })
+    }
+
+    /// Whether `secret` is the hashed secret, compared in constant time.
+    pub(crate) fn matches(&self, secret: &str) -> Result<bool, SdkError> {
+        let salt = hex::decode(&self.salt)
+            .map_err(|e| SdkError::Generic(format!("Invalid secret salt: {e}")))?;
+        let expected = hex::decode(&self.hash)
+            .map_err(|e| SdkError::Generic(format!("Invalid secret hash: {e}")))?;
+        let hash = scrypt_hash(secret, &salt, self.log_n, self.r, self.p)?;
+        Ok(hash.ct_eq(&expected).into())
+    }
+}
+
+/// Encodes the hash as `scrypt$<log_n>$<r>$<p>$<salt>$<hash>`, for secrets
+/// the app stores itself.
+impl fmt::Display for SecretHash {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(
+            f,
+            "scrypt${}${}${}${}${}",
+            self.log_n, self.r, self.p, self.salt, self.hash
+        )
+    }
+}
+
+impl FromStr for SecretHash {
+    type Err = SdkError;
+
+    fn from_str(s: &str) -> Result<Self, Self::Err> {
+        let invalid = || SdkError::InvalidInput("Invalid secret hash".to_string());
+        let parts: Vec<&str> = s.split('$').collect();
+        let ["scrypt", log_n, r, p, salt, hash] = parts.as_slice() else {
+            return Err(invalid());
+        };
+        Ok(Self {
+            salt: (*salt).to_string(),
+            hash: (*hash).to_string(),
+            log_n: log_n.parse().map_err(|_| invalid())?,
+            r: r.parse().map_err(|_| invalid())?,
+            p: p.parse().map_err(|_| invalid())?,
+        })
+    }
+}
+
+fn scrypt_hash(
+    secret: &str,
+    salt: &[u8],
+    log_n: u8,
+    r: u32,
+    p: u32,
+) -> Result<[u8; HASH_LEN], SdkError> {
+    let params = scrypt::Params::new(log_n, r, p, HASH_LEN)
+        .map_err(|e| SdkError::Generic(format!("Invalid scrypt parameters: {e}")))?;
+    let mut hash = [0u8; HASH_LEN];
+    scrypt::scrypt(secret.as_bytes(), salt, &params, &mut hash)
+        .map_err(|e| SdkError::Generic(format!("Failed to hash secret: {e}")))?;
+    Ok(hash)
+}
+
+/// Persisted count of failed attempts at a secret, used to throttle guessing:
+/// after a few free attempts, each failure doubles the wait before the next
+/// one, up to a day.
+#[derive(Clone, Debug, Default, Serialize, Deserialize)]
+pub(crate) struct FailedAttempts {
+    count: u32,
+    last_failed_at: u64,
+}
+
+impl FailedAttempts {
+    /// Fails with [`SdkError::TooManyAttempts`] while the wait after the last
+    /// failure hasn't passed.
+    pub(crate) fn ensure_can_attempt(&self, now: u64) -> Result<(), SdkError> {
+        let retry_at = self.last_failed_at.saturating_add(self.backoff_secs());
+        if now < retry_at {
+            return Err(SdkError::TooManyAttempts {
+                retry_after_secs: retry_at.saturating_sub(now),
+            });
+        }
+        Ok(())
+    }
+
+    pub(crate) fn record_failure(&mut self, now: u64) {
+        self.count = self.count.saturating_add(1);
+        self.last_failed_at = now;
+    }
+
+    fn backoff_secs(&self) -> u64 {
+        let Some(throttled) = self.count.checked_sub(FREE_ATTEMPTS) else {
+            return 0;
+        };
+        BASE_BACKOFF_SECS
+            .saturating_mul(1 << throttled.min(32))
+            .min(MAX_BACKOFF_SECS)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use macros::test_all;
+
+    #[cfg(feature = "browser-tests")]
+    wasm_bindgen_test::wasm_bindgen_test_configure!(run_in_browser);
+
+    #[test_all]
+    fn test_secret_hash_matches_only_the_secret() {
+        let hash = SecretHash::new("password").unwrap();
+        assert!(hash.matches("password").unwrap());
+        assert!(!hash.matches("passwore").unwrap());
+        assert!(!hash.matches("").unwrap());
+
+        let other = SecretHash::new("password").unwrap();
+        assert_ne!(hash.salt, other.salt);
+        assert_ne!(hash.hash, other.hash);
+    }
+
+    #[test_all]
+    fn test_secret_hash_encoding() {
+        let hash = SecretHash::new("1234").unwrap();
+        let encoded = hash.to_string();
+        assert!(encoded.starts_with("scrypt$4$8$1$"));
+        let decoded: SecretHash = encoded.parse().unwrap();
+        assert!(decoded.matches("1234").unwrap());
+        assert!(!decoded.matches("4321").unwrap());
+
+        assert!("scrypt$4$8$1$00".parse::<SecretHash>().is_err());
+        assert!("bcrypt$4$8$1$00$00".parse::<SecretHash>().is_err());
+        assert!("scrypt$x$8$1$00$00".parse::<SecretHash>().is_err());
+    }
+
+    #[test_all]
+    fn test_failed_attempts_back_off() {
+        let mut attempts = FailedAttempts::default();
+        for _ in 0..FREE_ATTEMPTS {
+            attempts.ensure_can_attempt(1_000).unwrap();
+            attempts.record_failure(1_000);
+        }
+        assert!(matches!(
+            attempts.ensure_can_attempt(1_010),
+            Err(SdkError::TooManyAttempts {
+                retry_after_secs: 20
+            })
+        ));
+        attempts.ensure_can_attempt(1_030).unwrap();
+
+        attempts.record_failure(1_030);
+        assert!(attempts.ensure_can_attempt(1_089).is_err());
+        attempts.ensure_can_attempt(1_090).unwrap();
+
+        for _ in 0..40 {
+            attempts.record_failure(2_000);
+        }
+        assert!(matches!(
+            attempts.ensure_can_attempt(2_000),
+            Err(SdkError::TooManyAttempts {
+                retry_after_secs: MAX_BACKOFF_SECS
+            })
+        ));
+    }
+}
@@ -1,21 +1,27 @@
 use std::sync::Arc;
 
 use breez_sdk_common::input::{InputType, PaymentRequestSource, parse_spark_address};
-use platform_utils::time::UNIX_EPOCH;
 use spark_wallet::{BURN_PUBLIC_KEY, PublicKey, SparkWallet};
 use tracing::{debug, warn};
 
 use crate::{
     Payment, PaymentDetails, PaymentMethod, PaymentStatus, PaymentType, SdkError, Storage,
-    TokenMetadata, TokenTransactionType, persist::ObjectCacheRepository,
+    TokenMetadata, TokenTransactionType,
+    persist::{CachedTokenMetadata, ObjectCacheRepository},
 };
 
+/// How long cached token metadata is considered fresh before it's refreshed
+/// from the Spark network
+pub(crate) const TOKEN_METADATA_TTL_SECS: u64 = 24 * 60 * 60;
+
 /// Returns the metadata for the given token identifiers.
 ///
 /// Results are not guaranteed to be in the same order as the input token identifiers.
 ///
 /// If the metadata is not found in the object cache, it will be queried from the Spark network.
-/// The metadata is then cached in the object cache.
+/// The metadata is then cached in the object cache. Cached metadata is returned even when it's
+/// older than [`TOKEN_METADATA_TTL_SECS`], so previously seen tokens never need the network;
+/// stale entries are refreshed by [`refresh_tokens_metadata`].
 pub async fn get_tokens_metadata_cached_or_query(
     spark_wallet: &SparkWallet,
     object_repository: &ObjectCacheRepository,
@@ -28,13 +34,16 @@ pub async fn get_tokens_metadata_cached_or_query(
             .fetch_token_metadata(token_identifier)
             .await?
         {
-            cached_results.push(metadata);
+            cached_results.push(metadata.metadata);
         } else {
             uncached_identifiers.push(*token_identifier);
         }
     }
 
-    let queried_results = spark_wallet
+    if uncached_identifiers.is_empty() {
+        return Ok(cached_results);
+    }
+    let queried_results: Vec<TokenMetadata> = spark_wallet
         .get_tokens_metadata(uncached_identifiers.as_slice(), &[])
         .await?
         .into_iter()
@@ -42,12 +51,57 @@ pub async fn get_tokens_metadata_cached_or_query(
         .collect();
 
     for result in &queried_results {
-        object_repository.save_token_metadata(result).await?;
+        object_repository
+            .save_token_metadata(&CachedTokenMetadata {
+                metadata: result.clone(),
+                fetched_at: None,
+            })
+            .await?;
     }
 
     Ok([cached_results, queried_results].concat())
 }
 
+/// Queries the metadata of the given token identifiers from the Spark network and
+/// caches it, regardless of what's already cached.
+///
+/// Returns each queried metadata together with the metadata that was cached before, if any.
+pub(crate) async fn refresh_tokens_metadata(
+    spark_wallet: &SparkWallet,
+    object_repository: &ObjectCacheRepository,
+    token_identifiers: &[&str],
+    fetched_at: u64,
+) -> Result<Vec<(Option<TokenMetadata>, TokenMetadata)>, SdkError> {
+    let queried: Vec<TokenMetadata> = spark_wallet
+        .refresh_tokens_metadata(token_identifiers)
+        .await?
+        .into_iter()
+        .map(Into::into)
+        .collect();
+
+    let mut results = Vec::with_capacity(queried.len());
+    for metadata in queried {
+        let previous = object_repository
+            .fetch_token_metadata(&metadata.identifier)
+            .await?
+            .map(|cached| cached.metadata);
+        object_repository
+            .save_token_metadata(&CachedTokenMetadata {
+                metadata: metadata.clone(),
+                fetched_at: Some(fetched_at),
+            })
+            .await?;
+        results.push((previous, metadata));
+    }
+    Ok(results)
+}
+
+/// Returns whether cached metadata fetched at `fetched_at` is older than
+/// [`TOKEN_METADATA_TTL_SECS`] at `now`
+pub(crate) fn is_token_metadata_stale(fetched_at: u64, now: u64) -> bool {
+    now.saturating_sub(fetched_at) >= TOKEN_METADATA_TTL_SECS
+}
+
 /// Returns whether the inputs of `transaction` are owned by `identity_public_key`.
 ///
 /// For transfer inputs, the owner is determined by looking up the spent output
@@ -202,6 +256,8 @@ pub async fn token_transaction_to_payments(
                 conversion_info: None,
             }),
             conversion_details: None,
+            fiat_value: None,
+            receive_metadata: None,
         };
         payments.push(payment);
     }
@@ -214,6 +270,22 @@ pub(crate) async fn map_and_persist_token_transaction(
     storage: &Arc<dyn Storage>,
     token_transaction: &spark_wallet::TokenTransaction,
 ) -> Result<Payment, SdkError> {
+    map_and_persist_token_transaction_payments(spark_wallet, storage, token_transaction)
+        .await?
+        .into_iter()
+        .next()
+        .ok_or(SdkError::Generic(
+            "No payment created from token invoice".to_string(),
+        ))
+}
+
+/// Like [`map_and_persist_token_transaction`], but returns all the payments
+/// of the transaction, one per output not owned by the wallet.
+pub(crate) async fn map_and_persist_token_transaction_payments(
+    spark_wallet: &SparkWallet,
+    storage: &Arc<dyn Storage>,
+    token_transaction: &spark_wallet::TokenTransaction,
+) -> Result<Vec<Payment>, SdkError> {
     let object_repository = ObjectCacheRepository::new(storage.clone());
     let payments =
         token_transaction_to_payments(spark_wallet, &object_repository, token_transaction, true)
@@ -221,13 +293,7 @@ pub(crate) async fn map_and_persist_token_transaction(
     for payment in &payments {
         storage.apply_payment_update(payment.clone()).await?;
     }
-
-    payments
-        .first()
-        .ok_or(SdkError::Generic(
-            "No payment created from token invoice".to_string(),
-        ))
-        .cloned()
+    Ok(payments)
 }
 
 #[cfg(test)]
@@ -365,4 +431,19 @@ mod tests {
         };
         assert!(!token_tx_inputs_are_ours(&tx, None, identity).unwrap());
     }
+
+    #[macros::test_all]
+    fn token_metadata_is_stale_after_ttl() {
+        assert!(!is_token_metadata_stale(1_000, 1_000));
+        assert!(!is_token_metadata_stale(
+            1_000,
+            1_000 + TOKEN_METADATA_TTL_SECS - 1
+        ));
+        assert!(is_token_metadata_stale(
+            1_000,
+            1_000 + TOKEN_METADATA_TTL_SECS
+        ));
+        // Entries cached before the fetch time was recorded
+        assert!(is_token_metadata_stale(0, 1_000 + TOKEN_METADATA_TTL_SECS));
+    }
 }
@@ -22,9 +22,41 @@ pub struct RecoverLnurlPayResponse {
 #[derive(Debug, Serialize, Deserialize)]
 pub struct RegisterLnurlPayRequest {
     pub username: String,
+    /// Hex-encoded DER ECDSA signature over `"{username}-{timestamp}"`, or
+    /// `"{username}:{label}-{timestamp}"` when a label is set.
     pub signature: String,
     pub timestamp: u64,
     pub description: String,
+    /// Registers the address as an additional address of the pubkey under
+    /// this label, instead of replacing its primary address. Registering a
+    /// label again replaces the address it names.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub label: Option<String>,
+    /// Base64 encoded PNG added to the LNURL-pay metadata.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub image: Option<String>,
+    /// Longer form description added to the LNURL-pay metadata.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub long_description: Option<String>,
+    /// Minimum sendable amount in millisats, within the server's bounds.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub min_sendable: Option<u64>,
+    /// Maximum sendable amount in millisats, within the server's bounds.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub max_sendable: Option<u64>,
+}
+
+#[derive(Debug, Serialize, Deserialize)]
+pub struct SetNostrPubkeyRequest {
+    pub username: String,
+    /// Hex encoded x-only nostr pubkey to attach to the address, or `None` to
+    /// detach it.
+    pub nostr_pubkey: Option<String>,
+    /// Hex-encoded DER ECDSA signature over
+    /// `"nostr:{username}:{nostr_pubkey}-{timestamp}"`, with an empty
+    /// `nostr_pubkey` when detaching.
+    pub signature: String,
+    pub timestamp: u64,
 }
 
 #[derive(Debug, Serialize, Deserialize)]
@@ -40,6 +72,28 @@ pub struct RegisterLnurlPayResponse {
     pub lightning_address: String,
 }
 
+#[derive(Debug, Serialize, Deserialize)]
+pub struct ListLnurlPayRequest {
+    pub signature: String,
+    pub timestamp: u64,
+}
+
+#[derive(Debug, Serialize, Deserialize)]
+pub struct ListLnurlPayResponse {
+    /// The addresses of the pubkey, the primary address first
+    pub addresses: Vec<RegisteredLnurlPay>,
+}
+
+#[derive(Debug, Serialize, Deserialize)]
+pub struct RegisteredLnurlPay {
+    pub lnurl: String,
+    pub lightning_address: String,
+    pub username: String,
+    pub description: String,
+    /// `None` for the primary address
+    pub label: Option<String>,
+}
+
 #[derive(Debug, Serialize, Deserialize)]
 pub struct TransferLnurlPayRequest {
     pub username: String,
@@ -86,6 +140,58 @@ pub struct ListMetadataMetadata {
     pub updated_at: i64,
     /// The payment preimage if invoice has been paid
     pub preimage: Option<String>,
+    /// The lightning address the invoice was served for, if any
+    #[serde(default)]
+    pub lightning_address: Option<String>,
+}
+
+#[derive(Debug, Serialize, Deserialize)]
+pub struct CreatePaymentLinkRequest {
+    pub signature: String,
+    pub timestamp: u64,
+    pub amount_sat: u64,
+    pub memo: String,
+}
+
+#[derive(Debug, Serialize, Deserialize)]
+pub struct CreatePaymentLinkResponse {
+    pub link: PaymentLink,
+}
+
+#[derive(Debug, Serialize, Deserialize)]
+pub struct ListPaymentLinksRequest {
+    pub signature: String,
+    pub timestamp: u64,
+    pub offset: Option<u32>,
+    pub limit: Option<u32>,
+}
+
+#[derive(Debug, Serialize, Deserialize)]
+pub struct ListPaymentLinksResponse {
+    pub links: Vec<PaymentLink>,
+}
+
+#[derive(Debug, Serialize, Deserialize)]
+pub struct DeletePaymentLinkRequest {
+    pub signature: String,
+    pub timestamp: u64,
+}
+
+#[derive(Debug, Serialize, Deserialize)]
+pub struct PaymentLink {
+    pub id: String,
+    /// The shareable url serving the link's invoice
+    pub url: String,
+    pub amount_sat: u64,
+    pub memo: String,
+    /// Unix timestamp (milliseconds) when the link was created
+    pub created_at: i64,
+    /// The payment hash of the invoice last served for the link, if any
+    pub payment_hash: Option<String>,
+    /// The invoice last served for the link, if any
+    pub invoice: Option<String>,
+    /// The payment preimage once the served invoice has been paid
+    pub preimage: Option<String>,
 }
 
 pub fn sanitize_username(username: &str) -> String {
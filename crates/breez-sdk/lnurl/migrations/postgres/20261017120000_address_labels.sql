@@ -0,0 +1,9 @@
+-- A pubkey may hold several addresses per domain: its primary address, with
+-- an empty label, and any number of labeled ones.
+ALTER TABLE users ADD COLUMN label VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users ADD PRIMARY KEY (domain, pubkey, label);
+
+-- The username an invoice was served for, so payments can be told apart by
+-- the address that received them.
+ALTER TABLE invoices ADD COLUMN username VARCHAR(64);
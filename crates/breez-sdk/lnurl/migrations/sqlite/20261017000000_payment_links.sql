@@ -0,0 +1,12 @@
+-- Shareable fixed-amount payment requests. payment_hash is the invoice last
+-- served for the link, replaced once it expires unpaid.
+CREATE TABLE payment_links (
+    id TEXT PRIMARY KEY,
+    domain TEXT NOT NULL,
+    user_pubkey TEXT NOT NULL,
+    amount_sat BIGINT NOT NULL,
+    memo TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    payment_hash TEXT
+);
+CREATE INDEX idx_payment_links_user_pubkey ON payment_links(user_pubkey);
@@ -0,0 +1,22 @@
+-- A pubkey may hold several addresses per domain: its primary address, with
+-- an empty label, and any number of labeled ones. SQLite can't alter a
+-- primary key, so the table is rebuilt.
+CREATE TABLE users_new(
+	domain VARCHAR(255) NOT NULL,
+	pubkey VARCHAR(66) NOT NULL,
+	name VARCHAR(64) NOT NULL,
+	description VARCHAR(255) NOT NULL,
+	updated_at INTEGER NOT NULL,
+	label VARCHAR(64) NOT NULL DEFAULT '',
+	PRIMARY KEY (domain, pubkey, label),
+	UNIQUE(domain, name)
+);
+INSERT INTO users_new (domain, pubkey, name, description, updated_at)
+SELECT domain, pubkey, name, description, updated_at FROM users;
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+CREATE INDEX idx_users_pubkey ON users(pubkey);
+
+-- The username an invoice was served for, so payments can be told apart by
+-- the address that received them.
+ALTER TABLE invoices ADD COLUMN username VARCHAR(64);
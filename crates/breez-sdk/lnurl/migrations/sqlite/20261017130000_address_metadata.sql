@@ -0,0 +1,6 @@
+-- Optional LNURL-pay metadata of an address: a base64 PNG image, a long
+-- description and overrides of the server's sendable bounds, in millisats.
+ALTER TABLE users ADD COLUMN image TEXT;
+ALTER TABLE users ADD COLUMN long_description TEXT;
+ALTER TABLE users ADD COLUMN min_sendable INTEGER;
+ALTER TABLE users ADD COLUMN max_sendable INTEGER;
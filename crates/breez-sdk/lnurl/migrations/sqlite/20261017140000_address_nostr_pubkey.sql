@@ -0,0 +1,3 @@
+-- The x-only nostr pubkey attached to an address, served for NIP-05
+-- verification and required as the recipient of zaps to the address.
+ALTER TABLE users ADD COLUMN nostr_pubkey VARCHAR(64);
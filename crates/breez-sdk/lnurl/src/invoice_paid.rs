@@ -93,7 +93,8 @@ pub async fn create_invoice<DB>(
     invoice: &str,
     invoice_expiry: i64,
     domain: &str,
-) -> Result<(), LnurlRepositoryError>
+    username: Option<&str>,
+) -> Result<Invoice, LnurlRepositoryError>
 where
     DB: LnurlRepository + Clone + Send + Sync + 'static,
 {
@@ -107,11 +108,12 @@ where
         created_at: now,
         updated_at: now,
         domain: Some(domain.to_string()),
+        username: username.map(ToString::to_string),
         amount_received_sat: None,
     };
     db.upsert_invoice(&invoice_record).await?;
     debug!("Created invoice record for payment hash {}", payment_hash);
-    Ok(())
+    Ok(invoice_record)
 }
 
 #[cfg(test)]
@@ -186,6 +188,7 @@ mod shared_tests {
             created_at: 1000,
             updated_at: 1000,
             domain: None,
+            username: None,
             amount_received_sat: None,
         })
         .await
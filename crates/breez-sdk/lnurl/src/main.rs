@@ -501,10 +501,27 @@ where
             "/lnurlpay/{pubkey}/recover",
             post(LnurlServer::<DB>::recover),
         )
+        .route("/lnurlpay/{pubkey}/addresses", get(LnurlServer::<DB>::list))
+        .route(
+            "/lnurlpay/{pubkey}/nostr",
+            post(LnurlServer::<DB>::set_nostr_pubkey),
+        )
         .route(
             "/lnurlpay/{pubkey}/metadata",
             get(LnurlServer::<DB>::list_metadata),
         )
+        .route(
+            "/lnurlpay/{pubkey}/links",
+            post(LnurlServer::<DB>::create_payment_link),
+        )
+        .route(
+            "/lnurlpay/{pubkey}/links",
+            get(LnurlServer::<DB>::list_payment_links),
+        )
+        .route(
+            "/lnurlpay/{pubkey}/links/{id}",
+            delete(LnurlServer::<DB>::delete_payment_link),
+        )
         .route_layer(middleware::from_fn_with_state(
             state.clone(),
             auth::auth::<DB>,
@@ -517,11 +534,13 @@ where
             "/lnurlp/{identifier}",
             get(LnurlServer::<DB>::handle_lnurl_pay),
         )
+        .route("/.well-known/nostr.json", get(LnurlServer::<DB>::nip05))
         .route(
             "/lnurlp/{identifier}/invoice",
             get(LnurlServer::<DB>::handle_invoice),
         )
         .route("/verify/{payment_hash}", get(LnurlServer::<DB>::verify))
+        .route("/link/{id}", get(LnurlServer::<DB>::serve_payment_link))
         .route("/webhook", post(LnurlServer::<DB>::webhook))
         .route("/health", get(|| async { StatusCode::OK }))
         .layer(Extension(state))
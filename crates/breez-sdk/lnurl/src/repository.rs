@@ -31,6 +31,9 @@ pub struct Invoice {
     pub updated_at: i64,
     /// The domain this invoice was created for, if any.
     pub domain: Option<String>,
+    /// The username this invoice was served for. `None` for invoices not
+    /// served for an address, such as those of payment links.
+    pub username: Option<String>,
     /// Amount received in satoshis (from the HTLC). NULL when unknown.
     pub amount_received_sat: Option<i64>,
 }
@@ -52,10 +55,28 @@ pub struct DomainConfig {
     pub jwt: Option<String>,
 }
 
+#[derive(Debug, Clone)]
+pub struct PaymentLink {
+    pub id: String,
+    pub domain: String,
+    pub user_pubkey: String,
+    pub amount_sat: i64,
+    pub memo: String,
+    pub created_at: i64,
+    /// The payment hash of the invoice last served for the link, if any.
+    pub payment_hash: Option<String>,
+    /// The served invoice. Read-only, joined from the invoices table.
+    pub invoice: Option<String>,
+    /// The preimage of the served invoice once paid. Read-only, joined from
+    /// the invoices table.
+    pub preimage: Option<String>,
+}
+
 #[async_trait::async_trait]
 pub trait LnurlRepository {
-    /// Delete `pubkey`'s row in `domain`, but only while it still holds `name`.
-    /// Returns whether a row was removed.
+    /// Delete `pubkey`'s address `name` in `domain`, whether primary or
+    /// labeled, but only while the pubkey still holds it. Returns whether a row
+    /// was removed.
     ///
     /// `name` is part of the condition so the caller's authorization check and
     /// the delete cannot disagree: a row that changed name in between is left
@@ -72,15 +93,34 @@ pub trait LnurlRepository {
         domain: &str,
         name: &str,
     ) -> Result<Option<User>, LnurlRepositoryError>;
+    /// Get the primary address of `pubkey` in `domain`.
     async fn get_user_by_pubkey(
         &self,
         domain: &str,
         pubkey: &str,
     ) -> Result<Option<User>, LnurlRepositoryError>;
+    /// List all addresses of `pubkey` in `domain`, the primary address first
+    /// and the labeled ones by label.
+    async fn list_users_by_pubkey(
+        &self,
+        domain: &str,
+        pubkey: &str,
+    ) -> Result<Vec<User>, LnurlRepositoryError>;
+    /// Insert or replace the address of `user.pubkey` with `user.label`, so a
+    /// pubkey holds one primary address and one address per label.
     async fn upsert_user(&self, user: &User) -> Result<(), LnurlRepositoryError>;
+    /// Attach `nostr_pubkey` to `pubkey`'s address `name` in `domain`, or
+    /// detach it with `None`. Returns whether the pubkey holds the address.
+    async fn set_user_nostr_pubkey(
+        &self,
+        domain: &str,
+        pubkey: &str,
+        name: &str,
+        nostr_pubkey: Option<&str>,
+    ) -> Result<bool, LnurlRepositoryError>;
 
     /// Atomically transfer ownership of `username` in `domain` from `from_pubkey`
-    /// to `to_pubkey`, replacing any existing row for `to_pubkey`.
+    /// to `to_pubkey`, where it replaces the primary address of `to_pubkey`.
     /// Returns [`LnurlRepositoryError::SourceNotOwner`] if `from_pubkey` does not
     /// currently own `username` in `domain`.
     async fn transfer_username(
@@ -171,6 +211,40 @@ pub trait LnurlRepository {
         &self,
         payment_hashes: &[String],
     ) -> Result<Vec<WebhookPayloadData>, LnurlRepositoryError>;
+
+    /// Insert a new payment link
+    async fn insert_payment_link(&self, link: &PaymentLink) -> Result<(), LnurlRepositoryError>;
+
+    /// Get a payment link by id
+    async fn get_payment_link(&self, id: &str)
+    -> Result<Option<PaymentLink>, LnurlRepositoryError>;
+
+    /// List the payment links of `pubkey`, newest first
+    async fn list_payment_links(
+        &self,
+        pubkey: &str,
+        offset: u32,
+        limit: u32,
+    ) -> Result<Vec<PaymentLink>, LnurlRepositoryError>;
+
+    /// Make `payment_hash` the invoice served for link `id`, but only while it
+    /// still serves `previous_payment_hash`. Returns whether the link was updated.
+    ///
+    /// Concurrent openers of a link each create an invoice; the condition lets
+    /// only one of them become the link's, so the others serve the winner's.
+    async fn set_payment_link_invoice(
+        &self,
+        id: &str,
+        previous_payment_hash: Option<&str>,
+        payment_hash: &str,
+    ) -> Result<bool, LnurlRepositoryError>;
+
+    /// Delete link `id` of `pubkey`. Returns whether a row was removed.
+    async fn delete_payment_link(
+        &self,
+        pubkey: &str,
+        id: &str,
+    ) -> Result<bool, LnurlRepositoryError>;
 }
 
 /// Data returned by the webhook enqueue query.
@@ -191,7 +265,7 @@ pub struct WebhookPayloadData {
 /// database with rows from other tests.
 #[cfg(test)]
 pub mod shared_tests {
-    use super::{LnurlRepository, LnurlRepositoryError};
+    use super::{Invoice, LnurlRepository, LnurlRepositoryError, PaymentLink};
     use crate::user::User;
 
     /// Upserting a name already owned by a different pubkey returns `NameTaken`
@@ -205,6 +279,12 @@ pub mod shared_tests {
             pubkey: "aaaa".into(),
             name: "alice".into(),
             description: "alice".into(),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable: None,
+            max_sendable: None,
+            nostr_pubkey: None,
         })
         .await
         .unwrap();
@@ -215,6 +295,12 @@ pub mod shared_tests {
                 pubkey: "bbbb".into(),
                 name: "alice".into(),
                 description: "bob".into(),
+                label: None,
+                image: None,
+                long_description: None,
+                min_sendable: None,
+                max_sendable: None,
+                nostr_pubkey: None,
             })
             .await;
         assert!(
@@ -239,7 +325,7 @@ pub mod shared_tests {
     ///
     /// Uses its own pubkey and name: the postgres harness shares one database
     /// across tests that run in parallel, and `users` is keyed by
-    /// `(domain, pubkey)` with `name` unique per domain.
+    /// `(domain, pubkey, label)` with `name` unique per domain.
     pub async fn deleting_a_name_the_pubkey_no_longer_holds_is_a_no_op<DB>(db: &DB)
     where
         DB: LnurlRepository + Clone + Send + Sync + 'static,
@@ -249,6 +335,12 @@ pub mod shared_tests {
             pubkey: "dddd".into(),
             name: "dave".into(),
             description: "dave".into(),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable: None,
+            max_sendable: None,
+            nostr_pubkey: None,
         })
         .await
         .unwrap();
@@ -276,6 +368,173 @@ pub mod shared_tests {
         );
     }
 
+    /// A pubkey holds one primary address and one address per label.
+    /// Registering with a label adds an address, re-registering a label
+    /// replaces the address it names and deleting one leaves the others.
+    pub async fn pubkey_holds_labeled_addresses<DB>(db: &DB)
+    where
+        DB: LnurlRepository + Clone + Send + Sync + 'static,
+    {
+        let user = |name: &str, label: Option<&str>| User {
+            domain: "a.com".into(),
+            pubkey: "ffff".into(),
+            name: name.into(),
+            description: name.into(),
+            label: label.map(Into::into),
+            image: None,
+            long_description: None,
+            min_sendable: None,
+            max_sendable: None,
+            nostr_pubkey: None,
+        };
+        db.upsert_user(&user("frank", None)).await.unwrap();
+        db.upsert_user(&user("frank.shop", Some("business")))
+            .await
+            .unwrap();
+        db.upsert_user(&user("frankie", Some("personal")))
+            .await
+            .unwrap();
+        db.upsert_user(&user("frank.store", Some("business")))
+            .await
+            .unwrap();
+
+        let names = |users: Vec<User>| {
+            users
+                .into_iter()
+                .map(|u| (u.name, u.label))
+                .collect::<Vec<_>>()
+        };
+        assert_eq!(
+            names(db.list_users_by_pubkey("a.com", "ffff").await.unwrap()),
+            vec![
+                ("frank".to_string(), None),
+                ("frank.store".to_string(), Some("business".to_string())),
+                ("frankie".to_string(), Some("personal".to_string())),
+            ],
+            "re-registering the business label must replace its address"
+        );
+        assert!(
+            db.get_user_by_name("a.com", "frank.shop")
+                .await
+                .unwrap()
+                .is_none(),
+            "the replaced address must be released"
+        );
+        assert_eq!(
+            db.get_user_by_pubkey("a.com", "ffff")
+                .await
+                .unwrap()
+                .map(|u| u.name),
+            Some("frank".to_string()),
+            "the primary address is the unlabeled one"
+        );
+
+        assert!(db.delete_user("a.com", "ffff", "frankie").await.unwrap());
+        assert_eq!(
+            names(db.list_users_by_pubkey("a.com", "ffff").await.unwrap()),
+            vec![
+                ("frank".to_string(), None),
+                ("frank.store".to_string(), Some("business".to_string())),
+            ]
+        );
+    }
+
+    /// The pay page metadata of an address is stored with it, and dropped
+    /// when the username is transferred to another pubkey.
+    pub async fn address_metadata_is_kept_until_transfer<DB>(db: &DB)
+    where
+        DB: LnurlRepository + Clone + Send + Sync + 'static,
+    {
+        db.upsert_user(&User {
+            domain: "a.com".into(),
+            pubkey: "gggg".into(),
+            name: "grace".into(),
+            description: "grace".into(),
+            label: None,
+            image: Some("iVBORw0KGgo=".into()),
+            long_description: Some("Grace's bakery".into()),
+            min_sendable: Some(10_000),
+            max_sendable: Some(5_000_000),
+            nostr_pubkey: None,
+        })
+        .await
+        .unwrap();
+
+        let user = db
+            .get_user_by_name("a.com", "grace")
+            .await
+            .unwrap()
+            .unwrap();
+        assert_eq!(user.image.as_deref(), Some("iVBORw0KGgo="));
+        assert_eq!(user.long_description.as_deref(), Some("Grace's bakery"));
+        assert_eq!(user.min_sendable, Some(10_000));
+        assert_eq!(user.max_sendable, Some(5_000_000));
+
+        db.transfer_username("a.com", "gggg", "hhhh", "grace", "grace")
+            .await
+            .unwrap();
+        let user = db
+            .get_user_by_name("a.com", "grace")
+            .await
+            .unwrap()
+            .unwrap();
+        assert_eq!(user.pubkey, "hhhh");
+        assert!(user.image.is_none());
+        assert!(user.long_description.is_none());
+        assert!(user.min_sendable.is_none());
+        assert!(user.max_sendable.is_none());
+    }
+
+    /// Only the pubkey holding an address can attach a nostr pubkey to it, and
+    /// re-registering the address keeps it.
+    pub async fn nostr_pubkey_is_set_by_the_address_owner<DB>(db: &DB)
+    where
+        DB: LnurlRepository + Clone + Send + Sync + 'static,
+    {
+        let user = User {
+            domain: "a.com".into(),
+            pubkey: "iiii".into(),
+            name: "ivan".into(),
+            description: "ivan".into(),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable: None,
+            max_sendable: None,
+            nostr_pubkey: None,
+        };
+        db.upsert_user(&user).await.unwrap();
+        let nostr_pubkey = "a".repeat(64);
+
+        assert!(
+            !db.set_user_nostr_pubkey("a.com", "jjjj", "ivan", Some(&nostr_pubkey))
+                .await
+                .unwrap(),
+            "another pubkey must not attach a nostr pubkey"
+        );
+        assert!(
+            db.set_user_nostr_pubkey("a.com", "iiii", "ivan", Some(&nostr_pubkey))
+                .await
+                .unwrap()
+        );
+        db.upsert_user(&User {
+            description: "ivan's address".into(),
+            ..user
+        })
+        .await
+        .unwrap();
+        let stored = db.get_user_by_name("a.com", "ivan").await.unwrap().unwrap();
+        assert_eq!(stored.nostr_pubkey, Some(nostr_pubkey));
+
+        assert!(
+            db.set_user_nostr_pubkey("a.com", "iiii", "ivan", None)
+                .await
+                .unwrap()
+        );
+        let stored = db.get_user_by_name("a.com", "ivan").await.unwrap().unwrap();
+        assert!(stored.nostr_pubkey.is_none());
+    }
+
     /// `list_domains` surfaces a domain's `api_key` and reports `None` for one
     /// with no key, added via `add_domain`. The caller seeds `a.com` with an
     /// `api_key` (`key-a`) first, since setting a key is a direct row write with
@@ -328,4 +587,62 @@ pub mod shared_tests {
             Some("tok")
         );
     }
+
+    /// `set_payment_link_invoice` only replaces the invoice the caller read, so
+    /// of two openers racing on a link only the first one's invoice sticks. The
+    /// served invoice's preimage shows through once it is paid.
+    pub async fn payment_link_serves_one_invoice_at_a_time<DB>(db: &DB)
+    where
+        DB: LnurlRepository + Clone + Send + Sync + 'static,
+    {
+        let link = PaymentLink {
+            id: "link1".to_string(),
+            domain: "a.com".to_string(),
+            user_pubkey: "eeee".to_string(),
+            amount_sat: 1_000,
+            memo: "dinner".to_string(),
+            created_at: 1,
+            payment_hash: None,
+            invoice: None,
+            preimage: None,
+        };
+        db.insert_payment_link(&link).await.unwrap();
+
+        assert!(
+            db.set_payment_link_invoice("link1", None, "hash1")
+                .await
+                .unwrap()
+        );
+        assert!(
+            !db.set_payment_link_invoice("link1", None, "hash2")
+                .await
+                .unwrap(),
+            "a stale read must not replace the served invoice"
+        );
+
+        db.upsert_invoice(&Invoice {
+            payment_hash: "hash1".to_string(),
+            user_pubkey: "eeee".to_string(),
+            invoice: "lnbc1".to_string(),
+            preimage: Some("pre1".to_string()),
+            invoice_expiry: 2,
+            created_at: 1,
+            updated_at: 1,
+            domain: Some("a.com".to_string()),
+            username: None,
+            amount_received_sat: Some(1_000),
+        })
+        .await
+        .unwrap();
+
+        let links = db.list_payment_links("eeee", 0, 10).await.unwrap();
+        assert_eq!(links.len(), 1);
+        assert_eq!(links[0].payment_hash.as_deref(), Some("hash1"));
+        assert_eq!(links[0].invoice.as_deref(), Some("lnbc1"));
+        assert_eq!(links[0].preimage.as_deref(), Some("pre1"));
+
+        assert!(!db.delete_payment_link("ffff", "link1").await.unwrap());
+        assert!(db.delete_payment_link("eeee", "link1").await.unwrap());
+        assert!(db.get_payment_link("link1").await.unwrap().is_none());
+    }
 }
@@ -2,22 +2,26 @@ use axum::{
     Extension, Json,
     body::Bytes,
     extract::{Path, Query},
-    http::{HeaderMap, StatusCode},
-    response::IntoResponse,
+    http::{HeaderMap, StatusCode, header},
+    response::{IntoResponse, Redirect, Response},
 };
 use axum_extra::extract::Host;
+use base64::{Engine, prelude::BASE64_STANDARD};
 use bitcoin::{
     hashes::{Hash, HashEngine, Hmac, HmacEngine, sha256},
     secp256k1::{PublicKey, XOnlyPublicKey, ecdsa::Signature},
 };
 use lightning_invoice::Bolt11Invoice;
 use lnurl_models::{
-    CheckUsernameAvailableResponse, ListMetadataRequest, ListMetadataResponse,
+    CheckUsernameAvailableResponse, CreatePaymentLinkRequest, CreatePaymentLinkResponse,
+    DeletePaymentLinkRequest, ListLnurlPayRequest, ListLnurlPayResponse, ListMetadataRequest,
+    ListMetadataResponse, ListPaymentLinksRequest, ListPaymentLinksResponse,
     RecoverLnurlPayRequest, RecoverLnurlPayResponse, RegisterLnurlPayRequest,
-    RegisterLnurlPayResponse, TransferLnurlPayRequest, TransferLnurlPayResponse,
-    UnregisterLnurlPayRequest, sanitize_username,
+    RegisterLnurlPayResponse, RegisteredLnurlPay, SetNostrPubkeyRequest, TransferLnurlPayRequest,
+    TransferLnurlPayResponse, UnregisterLnurlPayRequest, sanitize_username,
 };
 use nostr::{Alphabet, Event, JsonUtil, Kind, TagStandard};
+use rand::{Rng, distributions::Alphanumeric};
 use regex::Regex;
 use serde::{Deserialize, Serialize};
 use serde_json::{Value, json};
@@ -27,8 +31,8 @@ use tracing::{debug, error, trace, warn};
 
 use crate::{
     invoice_paid::{create_invoice, handle_invoice_paid},
-    repository::LnurlSenderComment,
-    time::{now_millis, now_u64},
+    repository::{Invoice, LnurlSenderComment, PaymentLink},
+    time::{now, now_millis, now_u64},
     zap::Zap,
 };
 use crate::{
@@ -44,6 +48,16 @@ const DEFAULT_METADATA_LIMIT: u32 = 100;
 const MAX_NOSTR_EVENT_SIZE: usize = 32_768;
 /// Maximum length of a sender comment (LUD-12).
 const MAX_COMMENT_LENGTH: usize = 255;
+/// Maximum length of a payment link memo, the longest bolt11 description.
+const MAX_MEMO_LENGTH: usize = 639;
+/// Maximum length of an address's long description.
+const MAX_LONG_DESCRIPTION_LENGTH: usize = 2000;
+/// Maximum size (bytes) of an address's decoded PNG image.
+const MAX_IMAGE_SIZE: usize = 65_536;
+const PNG_SIGNATURE: &[u8] = b"\x89PNG\r\n\x1a\n";
+const PAYMENT_LINK_ID_LENGTH: usize = 12;
+/// An invoice served for a payment link is replaced when it expires sooner.
+const PAYMENT_LINK_INVOICE_MIN_EXPIRY_SECS: i64 = 60;
 
 #[derive(Debug, Default, Serialize, Deserialize)]
 pub struct LnurlPayCallbackParams {
@@ -53,6 +67,11 @@ pub struct LnurlPayCallbackParams {
     pub expiry: Option<u32>,
 }
 
+#[derive(Debug, Default, Serialize, Deserialize)]
+pub struct Nip05Params {
+    pub name: Option<String>,
+}
+
 #[derive(Debug, Clone, PartialEq, Eq, Hash, Serialize, Deserialize)]
 pub enum Tag {
     #[serde(rename = "payRequest")]
@@ -138,21 +157,37 @@ where
     ) -> Result<Json<RegisterLnurlPayResponse>, (StatusCode, Json<Value>)> {
         let username = sanitize_username(&payload.username);
         validate_username(&username)?;
+        let label = payload.label.map(|label| label.trim().to_string());
+        validate_label(label.as_deref())?;
         let pubkey = validate(
             &pubkey,
             &payload.signature,
-            &username,
+            &register_message(&username, label.as_deref()),
             payload.timestamp,
             &state,
         )
         .await?;
         validate_description(&payload.description)?;
+        validate_long_description(payload.long_description.as_deref())?;
+        validate_image(payload.image.as_deref())?;
+        let (min_sendable, max_sendable) = validate_sendable_overrides(
+            payload.min_sendable,
+            payload.max_sendable,
+            state.min_sendable,
+            state.max_sendable,
+        )?;
 
         let user = User {
             domain: sanitize_domain(&state, &host).await?,
             pubkey: pubkey.to_string(),
             name: username,
             description: payload.description,
+            label,
+            image: payload.image,
+            long_description: payload.long_description,
+            min_sendable,
+            max_sendable,
+            nostr_pubkey: None,
         };
 
         if let Err(e) = state.db.upsert_user(&user).await {
@@ -171,7 +206,10 @@ where
             ));
         }
 
-        debug!("registered user '{}' for pubkey {}", user.name, pubkey);
+        debug!(
+            "registered user '{}' for pubkey {} with label {:?}",
+            user.name, pubkey, user.label
+        );
         let lnurl = format!("lnurlp://{}/lnurlp/{}", user.domain, user.name);
         Ok(Json(RegisterLnurlPayResponse {
             lnurl,
@@ -277,7 +315,7 @@ where
 
         let registered = state
             .db
-            .get_user_by_pubkey(&domain, &pubkey.to_string())
+            .list_users_by_pubkey(&domain, &pubkey.to_string())
             .await
             .map_err(|e| {
                 error!("failed to execute query: {}", e);
@@ -287,7 +325,7 @@ where
                 )
             })?;
 
-        match unregister_action(&username, registered.as_ref()) {
+        match unregister_action(&username, &registered) {
             UnregisterAction::Delete => {}
             UnregisterAction::AlreadyGone => {
                 debug!("pubkey {pubkey} holds no address, nothing to unregister");
@@ -295,7 +333,7 @@ where
             }
             UnregisterAction::NameMismatch => {
                 debug!(
-                    "unregister signature names '{username}', not the address pubkey {pubkey} holds"
+                    "unregister signature names '{username}', not an address pubkey {pubkey} holds"
                 );
                 return Err(unregister_name_mismatch());
             }
@@ -366,6 +404,132 @@ where
         }
     }
 
+    pub async fn list(
+        Host(host): Host,
+        Path(pubkey): Path<String>,
+        Query(params): Query<ListLnurlPayRequest>,
+        Extension(state): Extension<State<DB>>,
+    ) -> Result<Json<ListLnurlPayResponse>, (StatusCode, Json<Value>)> {
+        let pubkey = validate(
+            &pubkey,
+            &params.signature,
+            &pubkey,
+            params.timestamp,
+            &state,
+        )
+        .await?;
+
+        let users = state
+            .db
+            .list_users_by_pubkey(&sanitize_domain(&state, &host).await?, &pubkey.to_string())
+            .await
+            .map_err(|e| {
+                error!("failed to execute query: {}", e);
+                (
+                    StatusCode::INTERNAL_SERVER_ERROR,
+                    Json(Value::String("internal server error".into())),
+                )
+            })?;
+
+        let addresses = users
+            .into_iter()
+            .map(|user| RegisteredLnurlPay {
+                lnurl: format!("lnurlp://{}/lnurlp/{}", &user.domain, user.name),
+                lightning_address: format!("{}@{}", user.name, &user.domain),
+                username: user.name,
+                description: user.description,
+                label: user.label,
+            })
+            .collect();
+        Ok(Json(ListLnurlPayResponse { addresses }))
+    }
+
+    pub async fn set_nostr_pubkey(
+        Host(host): Host,
+        Path(pubkey): Path<String>,
+        Extension(state): Extension<State<DB>>,
+        Json(payload): Json<SetNostrPubkeyRequest>,
+    ) -> Result<(), (StatusCode, Json<Value>)> {
+        let username = sanitize_username(&payload.username);
+        let nostr_pubkey = payload
+            .nostr_pubkey
+            .as_deref()
+            .map(|nostr_pubkey| {
+                XOnlyPublicKey::from_str(nostr_pubkey.trim()).map_err(|e| {
+                    trace!("invalid nostr pubkey, could not parse: {}", e);
+                    (
+                        StatusCode::BAD_REQUEST,
+                        Json(Value::String("invalid nostr pubkey".into())),
+                    )
+                })
+            })
+            .transpose()?
+            .map(|nostr_pubkey| nostr_pubkey.to_string());
+        let pubkey = validate(
+            &pubkey,
+            &payload.signature,
+            &nostr_pubkey_message(&username, nostr_pubkey.as_deref()),
+            payload.timestamp,
+            &state,
+        )
+        .await?;
+
+        let updated = state
+            .db
+            .set_user_nostr_pubkey(
+                &sanitize_domain(&state, &host).await?,
+                &pubkey.to_string(),
+                &username,
+                nostr_pubkey.as_deref(),
+            )
+            .await
+            .map_err(|e| {
+                error!("failed to execute query: {}", e);
+                (
+                    StatusCode::INTERNAL_SERVER_ERROR,
+                    Json(Value::String("internal server error".into())),
+                )
+            })?;
+        if !updated {
+            trace!("pubkey {pubkey} doesn't hold '{username}'");
+            return Err((
+                StatusCode::NOT_FOUND,
+                Json(Value::String("address not found".into())),
+            ));
+        }
+
+        debug!("set nostr pubkey of '{username}' to {nostr_pubkey:?}");
+        Ok(())
+    }
+
+    /// Serves NIP-05 verification for the addresses with a nostr pubkey
+    /// attached. Unknown names get an empty `names` object, as NIP-05 asks.
+    pub async fn nip05(
+        Host(host): Host,
+        Query(params): Query<Nip05Params>,
+        Extension(state): Extension<State<DB>>,
+    ) -> Result<Json<Value>, (StatusCode, Json<Value>)> {
+        let Some(name) = params.name.map(|name| sanitize_username(&name)) else {
+            return Ok(Json(json!({ "names": {} })));
+        };
+        let user = state
+            .db
+            .get_user_by_name(&sanitize_domain(&state, &host).await?, &name)
+            .await
+            .map_err(|e| {
+                error!("failed to execute query: {}", e);
+                (
+                    StatusCode::INTERNAL_SERVER_ERROR,
+                    Json(Value::String("internal server error".into())),
+                )
+            })?;
+        let mut names = serde_json::Map::new();
+        if let Some(nostr_pubkey) = user.and_then(|user| user.nostr_pubkey) {
+            names.insert(name, Value::String(nostr_pubkey));
+        }
+        Ok(Json(json!({ "names": names })))
+    }
+
     pub async fn list_metadata(
         Path(pubkey): Path<String>,
         Query(params): Query<ListMetadataRequest>,
@@ -430,13 +594,15 @@ where
         } else {
             (None, None)
         };
+        let (min_sendable, max_sendable) =
+            sendable_bounds(&user, state.min_sendable, state.max_sendable);
         Ok(Json(PayResponse {
             callback: format!(
                 "{}://{}/lnurlp/{}/invoice",
                 state.scheme, &user.domain, user.name
             ),
-            max_sendable: state.max_sendable,
-            min_sendable: state.min_sendable,
+            max_sendable,
+            min_sendable,
             tag: Tag::Pay,
             metadata: get_metadata(&user.domain, &user),
             #[allow(clippy::cast_possible_truncation)]
@@ -482,7 +648,9 @@ where
             return Err(lnurl_error("amount must be a whole sat amount"));
         }
 
-        validate_amount_bounds(amount_msat, state.min_sendable, state.max_sendable)?;
+        let (min_sendable, max_sendable) =
+            sendable_bounds(&user, state.min_sendable, state.max_sendable);
+        validate_amount_bounds(amount_msat, min_sendable, max_sendable)?;
 
         let nostr_pubkey = state
             .nostr_keys
@@ -513,6 +681,7 @@ where
                 lnurl_error("invalid nostr event")
             })?;
             validate_nostr_zap_request(amount_msat, &event)?;
+            validate_zap_recipient(&event, user.nostr_pubkey.as_deref())?;
             sha256::Hash::hash(event.as_json().as_bytes())
         } else {
             let metadata = get_metadata(&user.domain, &user);
@@ -611,6 +780,7 @@ where
             &res.invoice,
             invoice_expiry,
             &domain,
+            Some(&user.name),
         )
         .await
         {
@@ -658,6 +828,171 @@ where
         }))
     }
 
+    pub async fn create_payment_link(
+        Host(host): Host,
+        Path(pubkey): Path<String>,
+        Extension(state): Extension<State<DB>>,
+        Json(payload): Json<CreatePaymentLinkRequest>,
+    ) -> Result<Json<CreatePaymentLinkResponse>, (StatusCode, Json<Value>)> {
+        let pubkey = validate(
+            &pubkey,
+            &payload.signature,
+            &format!("payment-link:{}:{}", payload.amount_sat, payload.memo),
+            payload.timestamp,
+            &state,
+        )
+        .await?;
+        let amount_msat = payload.amount_sat.checked_mul(1000).ok_or_else(|| {
+            (
+                StatusCode::BAD_REQUEST,
+                Json(Value::String("amount out of bounds".into())),
+            )
+        })?;
+        validate_amount_bounds(amount_msat, state.min_sendable, state.max_sendable)?;
+        if payload.memo.len() > MAX_MEMO_LENGTH {
+            return Err((
+                StatusCode::BAD_REQUEST,
+                Json(Value::String("memo too long".into())),
+            ));
+        }
+
+        let link = PaymentLink {
+            id: new_payment_link_id(),
+            domain: sanitize_domain(&state, &host).await?,
+            user_pubkey: pubkey.to_string(),
+            // Bounded by max_sendable
+            amount_sat: i64::try_from(payload.amount_sat).unwrap_or(i64::MAX),
+            memo: payload.memo,
+            created_at: now_millis(),
+            payment_hash: None,
+            invoice: None,
+            preimage: None,
+        };
+        state.db.insert_payment_link(&link).await.map_err(|e| {
+            error!("failed to execute query: {}", e);
+            (
+                StatusCode::INTERNAL_SERVER_ERROR,
+                Json(Value::String("internal server error".into())),
+            )
+        })?;
+
+        debug!("created payment link {} for pubkey {}", link.id, pubkey);
+        Ok(Json(CreatePaymentLinkResponse {
+            link: payment_link_model(&state.scheme, link),
+        }))
+    }
+
+    pub async fn list_payment_links(
+        Path(pubkey): Path<String>,
+        Query(params): Query<ListPaymentLinksRequest>,
+        Extension(state): Extension<State<DB>>,
+    ) -> Result<Json<ListPaymentLinksResponse>, (StatusCode, Json<Value>)> {
+        let pubkey = validate(
+            &pubkey,
+            &params.signature,
+            &format!("payment-links:{pubkey}"),
+            params.timestamp,
+            &state,
+        )
+        .await?;
+        let offset = params.offset.unwrap_or(DEFAULT_METADATA_OFFSET);
+        let limit = params.limit.unwrap_or(DEFAULT_METADATA_LIMIT);
+        let links = state
+            .db
+            .list_payment_links(&pubkey.to_string(), offset, limit)
+            .await
+            .map_err(|e| {
+                error!("failed to execute query: {}", e);
+                (
+                    StatusCode::INTERNAL_SERVER_ERROR,
+                    Json(Value::String("internal server error".into())),
+                )
+            })?;
+        Ok(Json(ListPaymentLinksResponse {
+            links: links
+                .into_iter()
+                .map(|link| payment_link_model(&state.scheme, link))
+                .collect(),
+        }))
+    }
+
+    pub async fn delete_payment_link(
+        Path((pubkey, id)): Path<(String, String)>,
+        Extension(state): Extension<State<DB>>,
+        Json(payload): Json<DeletePaymentLinkRequest>,
+    ) -> Result<(), (StatusCode, Json<Value>)> {
+        let pubkey = validate(
+            &pubkey,
+            &payload.signature,
+            &format!("delete-payment-link:{id}"),
+            payload.timestamp,
+            &state,
+        )
+        .await?;
+        let deleted = state
+            .db
+            .delete_payment_link(&pubkey.to_string(), &id)
+            .await
+            .map_err(|e| {
+                error!("failed to execute query: {}", e);
+                (
+                    StatusCode::INTERNAL_SERVER_ERROR,
+                    Json(Value::String("internal server error".into())),
+                )
+            })?;
+        if !deleted {
+            return Err((
+                StatusCode::NOT_FOUND,
+                Json(Value::String("payment link not found".into())),
+            ));
+        }
+        debug!("deleted payment link {} of pubkey {}", id, pubkey);
+        Ok(())
+    }
+
+    /// Serves the invoice of a payment link, redirecting browsers to it as a
+    /// `lightning:` uri so the opener's wallet app handles it. The invoice is
+    /// reused until it expires or is paid, so the link is paid at most once.
+    pub async fn serve_payment_link(
+        Host(host): Host,
+        Path(id): Path<String>,
+        headers: HeaderMap,
+        Extension(state): Extension<State<DB>>,
+    ) -> Result<Response, (StatusCode, Json<Value>)> {
+        let domain = sanitize_domain(&state, &host).await?;
+        let link = state.db.get_payment_link(&id).await.map_err(|e| {
+            error!("failed to execute query: {}", e);
+            lnurl_error("internal server error")
+        })?;
+        let Some(link) = link.filter(|link| link.domain == domain) else {
+            return Err((StatusCode::NOT_FOUND, Json(Value::String(String::new()))));
+        };
+
+        let invoice = payment_link_invoice(&state, &link).await?;
+        let paid = invoice.preimage.is_some();
+        let accepts_html = headers
+            .get(header::ACCEPT)
+            .and_then(|accept| accept.to_str().ok())
+            .is_some_and(|accept| accept.contains("text/html"));
+        if accepts_html && !paid {
+            return Ok(Redirect::to(&format!("lightning:{}", invoice.invoice)).into_response());
+        }
+
+        let verify_url = format!(
+            "{}://{}/verify/{}",
+            state.scheme, domain, invoice.payment_hash
+        );
+        Ok(Json(json!({
+            "status": "OK",
+            "pr": invoice.invoice,
+            "amount_sat": link.amount_sat,
+            "memo": link.memo,
+            "paid": paid,
+            "verify": verify_url,
+        }))
+        .into_response())
+    }
+
     /// Webhook endpoint for SSP payment notifications.
     /// Verifies HMAC-SHA256 signature and processes payment preimages.
     pub async fn webhook(
@@ -961,6 +1296,64 @@ fn validate_amount_bounds(
     Ok(())
 }
 
+fn validate_label(label: Option<&str>) -> Result<(), (StatusCode, Json<Value>)> {
+    let Some(label) = label else {
+        return Ok(());
+    };
+    if label.is_empty() {
+        return Err((
+            StatusCode::BAD_REQUEST,
+            Json(Value::String("label can't be empty".into())),
+        ));
+    }
+    if label.chars().take(65).count() > 64 {
+        return Err((
+            StatusCode::BAD_REQUEST,
+            Json(Value::String("label too long".into())),
+        ));
+    }
+    Ok(())
+}
+
+/// The message a register signature covers. A label is part of it, so a
+/// signature for one address can't register the name under another label.
+fn register_message(username: &str, label: Option<&str>) -> String {
+    match label {
+        Some(label) => format!("{username}:{label}"),
+        None => username.to_string(),
+    }
+}
+
+/// The message a nostr pubkey signature covers. The pubkey is part of it, so
+/// a signature can't attach a different key.
+fn nostr_pubkey_message(username: &str, nostr_pubkey: Option<&str>) -> String {
+    format!("nostr:{username}:{}", nostr_pubkey.unwrap_or_default())
+}
+
+/// A zap to an address with a nostr pubkey attached must be for that pubkey,
+/// so the receipt credits the address owner's profile.
+fn validate_zap_recipient(
+    event: &Event,
+    nostr_pubkey: Option<&str>,
+) -> Result<(), (StatusCode, Json<Value>)> {
+    let Some(nostr_pubkey) = nostr_pubkey else {
+        return Ok(());
+    };
+    let recipient = event.tags.iter().find_map(|t| match t.as_standardized() {
+        Some(TagStandard::PublicKey {
+            public_key,
+            uppercase: false,
+            ..
+        }) => Some(public_key.to_hex()),
+        _ => None,
+    });
+    if recipient.as_deref() != Some(nostr_pubkey) {
+        trace!("zap request recipient isn't the address's nostr pubkey");
+        return Err(lnurl_error("zap recipient doesn't match the address"));
+    }
+    Ok(())
+}
+
 fn validate_description(description: &str) -> Result<(), (StatusCode, Json<Value>)> {
     if description.chars().take(256).count() > 255 {
         return Err((
@@ -971,6 +1364,87 @@ fn validate_description(description: &str) -> Result<(), (StatusCode, Json<Value
     Ok(())
 }
 
+fn validate_long_description(
+    long_description: Option<&str>,
+) -> Result<(), (StatusCode, Json<Value>)> {
+    let Some(long_description) = long_description else {
+        return Ok(());
+    };
+    if long_description
+        .chars()
+        .take(MAX_LONG_DESCRIPTION_LENGTH + 1)
+        .count()
+        > MAX_LONG_DESCRIPTION_LENGTH
+    {
+        return Err((
+            StatusCode::BAD_REQUEST,
+            Json(Value::String("long description too long".into())),
+        ));
+    }
+    Ok(())
+}
+
+fn validate_image(image: Option<&str>) -> Result<(), (StatusCode, Json<Value>)> {
+    let Some(image) = image else {
+        return Ok(());
+    };
+    let invalid_image = |reason: &str| {
+        (
+            StatusCode::BAD_REQUEST,
+            Json(Value::String(format!("invalid image: {reason}"))),
+        )
+    };
+    // The base64 length bounds the decoded size, reject before decoding
+    if image.len() > MAX_IMAGE_SIZE.div_ceil(3) * 4 {
+        return Err(invalid_image("too large"));
+    }
+    let bytes = BASE64_STANDARD.decode(image).map_err(|e| {
+        trace!("invalid image, could not decode: {}", e);
+        invalid_image("not base64")
+    })?;
+    if bytes.len() > MAX_IMAGE_SIZE {
+        return Err(invalid_image("too large"));
+    }
+    if !bytes.starts_with(PNG_SIGNATURE) {
+        return Err(invalid_image("not a PNG"));
+    }
+    Ok(())
+}
+
+/// Validates an address's sendable overrides against the server's bounds,
+/// returning them in the form they're stored.
+fn validate_sendable_overrides(
+    min_sendable: Option<u64>,
+    max_sendable: Option<u64>,
+    server_min_sendable: u64,
+    server_max_sendable: u64,
+) -> Result<(Option<i64>, Option<i64>), (StatusCode, Json<Value>)> {
+    let bad_request = |msg: &str| (StatusCode::BAD_REQUEST, Json(Value::String(msg.into())));
+    let min = min_sendable.unwrap_or(server_min_sendable);
+    let max = max_sendable.unwrap_or(server_max_sendable);
+    if min < server_min_sendable || max > server_max_sendable {
+        return Err(bad_request("sendable amount out of the server's bounds"));
+    }
+    if min > max {
+        return Err(bad_request("min sendable can't be more than max sendable"));
+    }
+    let to_stored = |v: Option<u64>| {
+        v.map(i64::try_from)
+            .transpose()
+            .map_err(|_| bad_request("sendable amount out of the server's bounds"))
+    };
+    Ok((to_stored(min_sendable)?, to_stored(max_sendable)?))
+}
+
+/// The amounts an address can receive: its overrides, or the server's bounds.
+fn sendable_bounds(user: &User, server_min_sendable: u64, server_max_sendable: u64) -> (u64, u64) {
+    let bound = |v: Option<i64>| v.and_then(|v| u64::try_from(v).ok());
+    (
+        bound(user.min_sendable).unwrap_or(server_min_sendable),
+        bound(user.max_sendable).unwrap_or(server_max_sendable),
+    )
+}
+
 /// Parse a signed request and bound it in time. Independent of the message the
 /// signature covers.
 fn parse_signed_request(
@@ -1044,27 +1518,29 @@ fn unregister_name_mismatch() -> (StatusCode, Json<Value>) {
     )
 }
 
-/// What an unregister request does to the address a pubkey holds.
+/// What an unregister request does to the addresses a pubkey holds.
 #[derive(Debug, PartialEq, Eq)]
 enum UnregisterAction {
-    /// The signature names the registered address: remove it.
+    /// The signature names one of the registered addresses: remove it.
     Delete,
     /// The pubkey holds no address, so the request's goal already holds.
     AlreadyGone,
-    /// The pubkey holds an address the signature does not name.
+    /// The pubkey holds addresses, none of which the signature names.
     NameMismatch,
 }
 
-/// Decide an unregister from the name the signature covers and the address the
-/// pubkey holds.
+/// Decide an unregister from the name the signature covers and the addresses
+/// the pubkey holds, primary and labeled.
 ///
 /// The signature approves removing one specific name, so a name it does not
-/// cover is never removed, whichever address the pubkey currently holds.
-fn unregister_action(signed_name: &str, registered: Option<&User>) -> UnregisterAction {
-    match registered {
-        None => UnregisterAction::AlreadyGone,
-        Some(user) if user.name == signed_name => UnregisterAction::Delete,
-        Some(_) => UnregisterAction::NameMismatch,
+/// cover is never removed, whichever addresses the pubkey currently holds.
+fn unregister_action(signed_name: &str, registered: &[User]) -> UnregisterAction {
+    if registered.is_empty() {
+        UnregisterAction::AlreadyGone
+    } else if registered.iter().any(|user| user.name == signed_name) {
+        UnregisterAction::Delete
+    } else {
+        UnregisterAction::NameMismatch
     }
 }
 
@@ -1162,11 +1638,154 @@ fn parse_pubkey(pubkey: &str) -> Result<PublicKey, (StatusCode, Json<Value>)> {
 }
 
 fn get_metadata(domain: &str, user: &User) -> String {
-    json!(vec![
+    let identifier = format!("{}@{}", user.name, domain);
+    let mut metadata = vec![
         vec!["text/plain", &user.description],
-        vec!["text/identifier", &format!("{}@{}", user.name, domain)],
-    ])
-    .to_string()
+        vec!["text/identifier", &identifier],
+    ];
+    if let Some(long_description) = &user.long_description {
+        metadata.push(vec!["text/long-desc", long_description]);
+    }
+    if let Some(image) = &user.image {
+        metadata.push(vec!["image/png;base64", image]);
+    }
+    json!(metadata).to_string()
+}
+
+fn new_payment_link_id() -> String {
+    rand::thread_rng()
+        .sample_iter(&Alphanumeric)
+        .take(PAYMENT_LINK_ID_LENGTH)
+        .map(char::from)
+        .collect()
+}
+
+fn payment_link_model(scheme: &str, link: PaymentLink) -> lnurl_models::PaymentLink {
+    lnurl_models::PaymentLink {
+        url: format!("{scheme}://{}/link/{}", link.domain, link.id),
+        id: link.id,
+        amount_sat: u64::try_from(link.amount_sat).unwrap_or_default(),
+        memo: link.memo,
+        created_at: link.created_at,
+        payment_hash: link.payment_hash,
+        invoice: link.invoice,
+        preimage: link.preimage,
+    }
+}
+
+/// The invoice a payment link serves: the one it last served while unpaid
+/// and not about to expire, or else a new one.
+async fn payment_link_invoice<DB>(
+    state: &State<DB>,
+    link: &PaymentLink,
+) -> Result<Invoice, (StatusCode, Json<Value>)>
+where
+    DB: LnurlRepository + Clone + Send + Sync + 'static,
+{
+    let internal_error = |e: LnurlRepositoryError| {
+        error!("failed to execute query: {}", e);
+        lnurl_error("internal server error")
+    };
+    if let Some(payment_hash) = &link.payment_hash {
+        let invoice = state
+            .db
+            .get_invoice_by_payment_hash(payment_hash)
+            .await
+            .map_err(internal_error)?;
+        if let Some(invoice) = invoice
+            && (invoice.preimage.is_some()
+                || invoice.invoice_expiry
+                    > now().saturating_add(PAYMENT_LINK_INVOICE_MIN_EXPIRY_SECS))
+        {
+            return Ok(invoice);
+        }
+    }
+
+    let invoice = create_payment_link_invoice(state, link).await?;
+    let replaced = state
+        .db
+        .set_payment_link_invoice(
+            &link.id,
+            link.payment_hash.as_deref(),
+            &invoice.payment_hash,
+        )
+        .await
+        .map_err(internal_error)?;
+    if replaced {
+        return Ok(invoice);
+    }
+
+    // Another opener replaced the invoice first, serve theirs
+    let winner = state
+        .db
+        .get_payment_link(&link.id)
+        .await
+        .map_err(internal_error)?
+        .and_then(|link| link.payment_hash);
+    let Some(payment_hash) = winner else {
+        return Err((StatusCode::NOT_FOUND, Json(Value::String(String::new()))));
+    };
+    state
+        .db
+        .get_invoice_by_payment_hash(&payment_hash)
+        .await
+        .map_err(internal_error)?
+        .ok_or_else(|| lnurl_error("internal server error"))
+}
+
+async fn create_payment_link_invoice<DB>(
+    state: &State<DB>,
+    link: &PaymentLink,
+) -> Result<Invoice, (StatusCode, Json<Value>)>
+where
+    DB: LnurlRepository + Clone + Send + Sync + 'static,
+{
+    let pubkey = parse_pubkey(&link.user_pubkey)?;
+    let wallet = state.invoice_wallet(&link.domain).await;
+    let res = wallet
+        .create_lightning_invoice(
+            u64::try_from(link.amount_sat).unwrap_or_default(),
+            Some(spark_wallet::InvoiceDescription::Memo(link.memo.clone())),
+            Some(pubkey),
+            None,
+            state.include_spark_address,
+        )
+        .await
+        .map_err(|e| {
+            error!("failed to create lightning invoice: {}", e);
+            lnurl_error("failed to create invoice")
+        })?;
+
+    let invoice = Bolt11Invoice::from_str(&res.invoice).map_err(|e| {
+        error!("failed to parse invoice: {}", e);
+        lnurl_error("internal server error")
+    })?;
+    let invoice_expiry = invoice
+        .expires_at()
+        .and_then(|expiry| i64::try_from(expiry.as_secs()).ok())
+        .ok_or_else(|| {
+            error!(
+                "invoice has invalid expiry: duration since epoch {}s, expiry time: {}s",
+                invoice.duration_since_epoch().as_secs(),
+                invoice.expiry_time().as_secs()
+            );
+            lnurl_error("internal server error")
+        })?;
+
+    create_invoice(
+        &state.db,
+        &invoice.payment_hash().to_string(),
+        &link.user_pubkey,
+        &res.invoice,
+        invoice_expiry,
+        &link.domain,
+        None,
+    )
+    .await
+    .map_err(|e| {
+        error!("Failed to create invoice record: {}", e);
+        lnurl_error("internal server error")
+    })
 }
 
 fn lnurl_error(message: &str) -> (StatusCode, Json<Value>) {
@@ -1220,7 +1839,8 @@ async fn sanitize_domain<DB>(
 mod tests {
     use super::*;
     use crate::repository::{
-        DomainConfig, Invoice, LnurlRepositoryError, LnurlSenderComment, PendingZapReceipt,
+        DomainConfig, Invoice, LnurlRepositoryError, LnurlSenderComment, PaymentLink,
+        PendingZapReceipt,
     };
     use crate::user::User;
     use crate::webhooks::repository::WebhookRepositoryError;
@@ -1265,9 +1885,25 @@ mod tests {
         ) -> Result<Option<User>, LnurlRepositoryError> {
             Ok(None)
         }
+        async fn list_users_by_pubkey(
+            &self,
+            _: &str,
+            _: &str,
+        ) -> Result<Vec<User>, LnurlRepositoryError> {
+            Ok(vec![])
+        }
         async fn upsert_user(&self, _: &User) -> Result<(), LnurlRepositoryError> {
             Ok(())
         }
+        async fn set_user_nostr_pubkey(
+            &self,
+            _: &str,
+            _: &str,
+            _: &str,
+            _: Option<&str>,
+        ) -> Result<bool, LnurlRepositoryError> {
+            Ok(false)
+        }
         async fn transfer_username(
             &self,
             _: &str,
@@ -1386,6 +2022,43 @@ mod tests {
         ) -> Result<Vec<crate::repository::WebhookPayloadData>, LnurlRepositoryError> {
             Ok(vec![])
         }
+
+        async fn insert_payment_link(&self, _: &PaymentLink) -> Result<(), LnurlRepositoryError> {
+            Ok(())
+        }
+
+        async fn get_payment_link(
+            &self,
+            _: &str,
+        ) -> Result<Option<PaymentLink>, LnurlRepositoryError> {
+            Ok(None)
+        }
+
+        async fn list_payment_links(
+            &self,
+            _: &str,
+            _: u32,
+            _: u32,
+        ) -> Result<Vec<PaymentLink>, LnurlRepositoryError> {
+            Ok(vec![])
+        }
+
+        async fn set_payment_link_invoice(
+            &self,
+            _: &str,
+            _: Option<&str>,
+            _: &str,
+        ) -> Result<bool, LnurlRepositoryError> {
+            Ok(true)
+        }
+
+        async fn delete_payment_link(
+            &self,
+            _: &str,
+            _: &str,
+        ) -> Result<bool, LnurlRepositoryError> {
+            Ok(true)
+        }
     }
 
     #[async_trait::async_trait]
@@ -1514,6 +2187,7 @@ mod tests {
                 created_at: 0,
                 updated_at: 0,
                 domain: None,
+                username: None,
                 amount_received_sat: None,
             },
         );
@@ -1876,6 +2550,7 @@ mod tests {
                 created_at: 0,
                 updated_at: 0,
                 domain: None,
+                username: None,
                 amount_received_sat: None,
             },
         );
@@ -2201,6 +2876,12 @@ mod tests {
             pubkey: "02abc123".to_string(),
             name: name.to_string(),
             description: String::new(),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable: None,
+            max_sendable: None,
+            nostr_pubkey: None,
         }
     }
 
@@ -2254,7 +2935,7 @@ mod tests {
             "the signature itself still verifies while the legacy form is accepted"
         );
         assert_eq!(
-            unregister_action(&pubkey_hex, Some(&registered_as("alice"))),
+            unregister_action(&pubkey_hex, &[registered_as("alice")]),
             UnregisterAction::NameMismatch
         );
     }
@@ -2262,21 +2943,122 @@ mod tests {
     #[test]
     fn deleting_requires_the_signed_name_to_be_the_registered_one() {
         assert_eq!(
-            unregister_action("alice", Some(&registered_as("alice"))),
+            unregister_action("alice", &[registered_as("alice")]),
+            UnregisterAction::Delete
+        );
+        assert_eq!(
+            unregister_action("bob", &[registered_as("alice")]),
+            UnregisterAction::NameMismatch
+        );
+    }
+
+    #[test]
+    fn unregistering_a_labeled_address_keeps_the_others() {
+        let mut business = registered_as("alice.shop");
+        business.label = Some("business".to_string());
+        let registered = [registered_as("alice"), business];
+        assert_eq!(
+            unregister_action("alice.shop", &registered),
             UnregisterAction::Delete
         );
         assert_eq!(
-            unregister_action("bob", Some(&registered_as("alice"))),
+            unregister_action("bob", &registered),
             UnregisterAction::NameMismatch
         );
     }
 
+    #[test]
+    fn register_signature_covers_the_label() {
+        assert_eq!(register_message("alice", None), "alice");
+        assert_eq!(
+            register_message("alice.shop", Some("business")),
+            "alice.shop:business"
+        );
+        assert!(validate_label(None).is_ok());
+        assert!(validate_label(Some("business")).is_ok());
+        assert!(validate_label(Some("")).is_err());
+        assert!(validate_label(Some(&"a".repeat(65))).is_err());
+    }
+
+    #[test]
+    fn zaps_to_an_address_with_a_nostr_pubkey_must_be_for_it() {
+        let parse_keys = |secret: u8| nostr::Keys::parse(&format!("{secret:064x}")).unwrap();
+        let (sender, owner, other) = (parse_keys(1), parse_keys(2), parse_keys(3));
+        let zap_request = |recipient: &nostr::Keys| {
+            let data = nostr::nips::nip57::ZapRequestData::new(
+                recipient.public_key(),
+                [nostr::RelayUrl::parse("wss://relay.example.com").unwrap()],
+            );
+            nostr::EventBuilder::public_zap_request(data)
+                .sign_with_keys(&sender)
+                .unwrap()
+        };
+
+        let owner_pubkey = owner.public_key().to_hex();
+        assert!(validate_zap_recipient(&zap_request(&owner), Some(&owner_pubkey)).is_ok());
+        assert!(validate_zap_recipient(&zap_request(&other), Some(&owner_pubkey)).is_err());
+        assert!(validate_zap_recipient(&zap_request(&other), None).is_ok());
+
+        assert_eq!(
+            nostr_pubkey_message("alice", Some(&owner_pubkey)),
+            format!("nostr:alice:{owner_pubkey}")
+        );
+        assert_eq!(nostr_pubkey_message("alice", None), "nostr:alice:");
+    }
+
+    #[test]
+    fn address_metadata_is_validated() {
+        let png = BASE64_STANDARD.encode([PNG_SIGNATURE, &[0u8; 16]].concat());
+        assert!(validate_image(None).is_ok());
+        assert!(validate_image(Some(&png)).is_ok());
+        assert!(validate_image(Some("not base64!")).is_err());
+        assert!(validate_image(Some(&BASE64_STANDARD.encode(b"GIF89a"))).is_err());
+        let too_large = BASE64_STANDARD.encode([PNG_SIGNATURE, &[0u8; MAX_IMAGE_SIZE]].concat());
+        assert!(validate_image(Some(&too_large)).is_err());
+
+        assert!(validate_long_description(Some(&"a".repeat(MAX_LONG_DESCRIPTION_LENGTH))).is_ok());
+        assert!(
+            validate_long_description(Some(&"a".repeat(MAX_LONG_DESCRIPTION_LENGTH + 1))).is_err()
+        );
+
+        assert_eq!(
+            validate_sendable_overrides(None, None, 1_000, 100_000).unwrap(),
+            (None, None)
+        );
+        assert_eq!(
+            validate_sendable_overrides(Some(5_000), Some(50_000), 1_000, 100_000).unwrap(),
+            (Some(5_000), Some(50_000))
+        );
+        assert!(validate_sendable_overrides(Some(500), None, 1_000, 100_000).is_err());
+        assert!(validate_sendable_overrides(None, Some(200_000), 1_000, 100_000).is_err());
+        assert!(validate_sendable_overrides(Some(50_000), Some(5_000), 1_000, 100_000).is_err());
+    }
+
+    #[test]
+    fn address_metadata_extends_the_lnurl_metadata() {
+        let mut user = registered_as("alice");
+        user.description = "Pay alice".to_string();
+        assert_eq!(
+            get_metadata("example.com", &user),
+            r#"[["text/plain","Pay alice"],["text/identifier","alice@example.com"]]"#
+        );
+
+        user.long_description = Some("Alice's bakery".to_string());
+        user.image = Some("iVBORw0KGgo=".to_string());
+        user.min_sendable = Some(5_000);
+        assert_eq!(
+            get_metadata("example.com", &user),
+            r#"[["text/plain","Pay alice"],["text/identifier","alice@example.com"],["text/long-desc","Alice's bakery"],["image/png;base64","iVBORw0KGgo="]]"#
+        );
+        assert_eq!(sendable_bounds(&user, 1_000, 100_000), (5_000, 100_000));
+    }
+
     #[test]
     fn unregistering_an_address_that_is_already_gone_succeeds() {
         // Nothing to remove, so the request's goal already holds. Reporting
         // success is what lets a client holding a stale name clear it.
         assert_eq!(
-            unregister_action("alice", None),
+            unregister_action("alice", &[]),
             UnregisterAction::AlreadyGone
         );
     }
@@ -2,7 +2,7 @@ use lnurl_models::ListMetadataMetadata;
 use sqlx::{Row, SqlitePool};
 
 use crate::repository::{
-    DomainConfig, Invoice, LnurlSenderComment, PendingZapReceipt, WebhookPayloadData,
+    DomainConfig, Invoice, LnurlSenderComment, PaymentLink, PendingZapReceipt, WebhookPayloadData,
 };
 use crate::webhooks::repository::{
     NewWebhookDelivery, WebhookConfig, WebhookDelivery, WebhookRepositoryError,
@@ -49,7 +49,7 @@ impl crate::repository::LnurlRepository for LnurlRepository {
         name: &str,
     ) -> Result<Option<User>, LnurlRepositoryError> {
         let maybe_user = sqlx::query(
-            "SELECT pubkey, name, description
+            "SELECT pubkey, name, description, label, image, long_description, min_sendable, max_sendable, nostr_pubkey
             FROM users
             WHERE domain = $1 AND name = $2",
         )
@@ -57,14 +57,7 @@ impl crate::repository::LnurlRepository for LnurlRepository {
         .bind(name)
         .fetch_optional(&self.pool)
         .await?
-        .map(|row| {
-            Ok::<_, sqlx::Error>(User {
-                domain: domain.to_string(),
-                pubkey: row.try_get(0)?,
-                name: row.try_get(1)?,
-                description: row.try_get(2)?,
-            })
-        })
+        .map(|row| user_from_row(domain, &row))
         .transpose()?;
         Ok(maybe_user)
     }
@@ -75,45 +68,92 @@ impl crate::repository::LnurlRepository for LnurlRepository {
         pubkey: &str,
     ) -> Result<Option<User>, LnurlRepositoryError> {
         let maybe_user = sqlx::query(
-            "SELECT pubkey, name, description
+            "SELECT pubkey, name, description, label, image, long_description, min_sendable, max_sendable, nostr_pubkey
                 FROM users
-                WHERE domain = $1 AND pubkey = $2",
+                WHERE domain = $1 AND pubkey = $2 AND label = ''",
         )
         .bind(domain)
         .bind(pubkey)
         .fetch_optional(&self.pool)
         .await?
-        .map(|row| {
-            Ok::<_, sqlx::Error>(User {
-                domain: domain.to_string(),
-                pubkey: row.try_get(0)?,
-                name: row.try_get(1)?,
-                description: row.try_get(2)?,
-            })
-        })
+        .map(|row| user_from_row(domain, &row))
         .transpose()?;
         Ok(maybe_user)
     }
 
+    async fn list_users_by_pubkey(
+        &self,
+        domain: &str,
+        pubkey: &str,
+    ) -> Result<Vec<User>, LnurlRepositoryError> {
+        let users = sqlx::query(
+            "SELECT pubkey, name, description, label, image, long_description, min_sendable, max_sendable, nostr_pubkey
+                FROM users
+                WHERE domain = $1 AND pubkey = $2
+                ORDER BY label ASC",
+        )
+        .bind(domain)
+        .bind(pubkey)
+        .fetch_all(&self.pool)
+        .await?
+        .iter()
+        .map(|row| user_from_row(domain, row))
+        .collect::<Result<Vec<_>, _>>()?;
+        Ok(users)
+    }
+
     async fn upsert_user(&self, user: &User) -> Result<(), LnurlRepositoryError> {
         sqlx::query(
-            "INSERT INTO users (domain, pubkey, name, description, updated_at)
-            VALUES ($1, $2, $3, $4, $5)
-            ON CONFLICT(domain, pubkey) DO UPDATE
+            "INSERT INTO users (domain, pubkey, name, description, updated_at, label
+            ,   image, long_description, min_sendable, max_sendable)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+            ON CONFLICT(domain, pubkey, label) DO UPDATE
             SET name = excluded.name
             ,   description = excluded.description
-            ,   updated_at = excluded.updated_at",
+            ,   updated_at = excluded.updated_at
+            ,   image = excluded.image
+            ,   long_description = excluded.long_description
+            ,   min_sendable = excluded.min_sendable
+            ,   max_sendable = excluded.max_sendable",
         )
         .bind(&user.domain)
         .bind(&user.pubkey)
         .bind(&user.name)
         .bind(&user.description)
         .bind(now())
+        .bind(user.label.as_deref().unwrap_or_default())
+        .bind(&user.image)
+        .bind(&user.long_description)
+        .bind(user.min_sendable)
+        .bind(user.max_sendable)
         .execute(&self.pool)
         .await?;
         Ok(())
     }
 
+    async fn set_user_nostr_pubkey(
+        &self,
+        domain: &str,
+        pubkey: &str,
+        name: &str,
+        nostr_pubkey: Option<&str>,
+    ) -> Result<bool, LnurlRepositoryError> {
+        let result = sqlx::query(
+            "UPDATE users
+             SET nostr_pubkey = $4
+             ,   updated_at = $5
+             WHERE domain = $1 AND pubkey = $2 AND name = $3",
+        )
+        .bind(domain)
+        .bind(pubkey)
+        .bind(name)
+        .bind(nostr_pubkey)
+        .bind(now())
+        .execute(&self.pool)
+        .await?;
+        Ok(result.rows_affected() > 0)
+    }
+
     async fn transfer_username(
         &self,
         domain: &str,
@@ -128,26 +168,31 @@ impl crate::repository::LnurlRepository for LnurlRepository {
             .await
             .map_err(|e| LnurlRepositoryError::General(e.into()))?;
 
-        let source_name: Option<(String,)> =
-            sqlx::query_as("DELETE FROM users WHERE domain = $1 AND pubkey = $2 RETURNING name")
-                .bind(domain)
-                .bind(from_pubkey)
-                .fetch_optional(&mut *tx)
-                .await?;
-        match source_name {
-            Some((name,)) if name == username => {}
-            // Source pubkey doesn't currently own this username. The tx is
-            // rolled back on drop, so the speculative DELETE is undone.
-            _ => return Err(LnurlRepositoryError::SourceNotOwner),
+        let source_name: Option<(String,)> = sqlx::query_as(
+            "DELETE FROM users WHERE domain = $1 AND pubkey = $2 AND name = $3 RETURNING name",
+        )
+        .bind(domain)
+        .bind(from_pubkey)
+        .bind(username)
+        .fetch_optional(&mut *tx)
+        .await?;
+        if source_name.is_none() {
+            // Source pubkey doesn't currently own this username.
+            return Err(LnurlRepositoryError::SourceNotOwner);
         }
 
         sqlx::query(
             "INSERT INTO users (domain, pubkey, name, description, updated_at)
              VALUES ($1, $2, $3, $4, $5)
-             ON CONFLICT(domain, pubkey) DO UPDATE
+             ON CONFLICT(domain, pubkey, label) DO UPDATE
              SET name = excluded.name
              ,   description = excluded.description
-             ,   updated_at = excluded.updated_at",
+             ,   updated_at = excluded.updated_at
+             ,   image = NULL
+             ,   long_description = NULL
+             ,   min_sendable = NULL
+             ,   max_sendable = NULL
+             ,   nostr_pubkey = NULL",
         )
         .bind(domain)
         .bind(to_pubkey)
@@ -217,6 +262,8 @@ impl crate::repository::LnurlRepository for LnurlRepository {
              ,      z.zap_event
              ,      MAX(COALESCE(z.updated_at, 0), COALESCE(sc.updated_at, 0), COALESCE(i.updated_at, 0)) AS updated_at
              ,      i.preimage
+             ,      i.username
+             ,      i.domain
              FROM (
                  SELECT payment_hash FROM invoices WHERE user_pubkey = $1 AND updated_at > $4
                  UNION
@@ -239,6 +286,8 @@ impl crate::repository::LnurlRepository for LnurlRepository {
         let metadata = rows
             .into_iter()
             .map(|row| {
+                let username: Option<String> = row.try_get(6)?;
+                let domain: Option<String> = row.try_get(7)?;
                 Ok(ListMetadataMetadata {
                     payment_hash: row.try_get(0)?,
                     sender_comment: row.try_get(1)?,
@@ -246,6 +295,7 @@ impl crate::repository::LnurlRepository for LnurlRepository {
                     nostr_zap_receipt: row.try_get(3)?,
                     updated_at: row.try_get(4)?,
                     preimage: row.try_get(5)?,
+                    lightning_address: username.zip(domain).map(|(n, d)| format!("{n}@{d}")),
                 })
             })
             .collect::<Result<Vec<_>, sqlx::Error>>()?;
@@ -298,8 +348,8 @@ impl crate::repository::LnurlRepository for LnurlRepository {
 
     async fn upsert_invoice(&self, invoice: &Invoice) -> Result<(), LnurlRepositoryError> {
         sqlx::query(
-            "INSERT INTO invoices (payment_hash, user_pubkey, invoice, preimage, invoice_expiry, created_at, updated_at, domain, amount_received_sat)
-            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+            "INSERT INTO invoices (payment_hash, user_pubkey, invoice, preimage, invoice_expiry, created_at, updated_at, domain, amount_received_sat, username)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
             ON CONFLICT(payment_hash) DO UPDATE SET
                 user_pubkey = excluded.user_pubkey,
                 invoice = excluded.invoice,
@@ -307,7 +357,8 @@ impl crate::repository::LnurlRepository for LnurlRepository {
                 invoice_expiry = excluded.invoice_expiry,
                 updated_at = excluded.updated_at,
                 domain = excluded.domain,
-                amount_received_sat = excluded.amount_received_sat",
+                amount_received_sat = excluded.amount_received_sat,
+                username = excluded.username",
         )
         .bind(&invoice.payment_hash)
         .bind(&invoice.user_pubkey)
@@ -318,6 +369,7 @@ impl crate::repository::LnurlRepository for LnurlRepository {
         .bind(invoice.updated_at)
         .bind(&invoice.domain)
         .bind(invoice.amount_received_sat)
+        .bind(&invoice.username)
         .execute(&self.pool)
         .await?;
         Ok(())
@@ -328,7 +380,7 @@ impl crate::repository::LnurlRepository for LnurlRepository {
         payment_hash: &str,
     ) -> Result<Option<Invoice>, LnurlRepositoryError> {
         let maybe_invoice = sqlx::query(
-            "SELECT payment_hash, user_pubkey, invoice, preimage, invoice_expiry, created_at, updated_at, domain, amount_received_sat
+            "SELECT payment_hash, user_pubkey, invoice, preimage, invoice_expiry, created_at, updated_at, domain, amount_received_sat, username
              FROM invoices
              WHERE payment_hash = $1",
         )
@@ -345,6 +397,7 @@ impl crate::repository::LnurlRepository for LnurlRepository {
                 created_at: row.try_get(5)?,
                 updated_at: row.try_get(6)?,
                 domain: row.try_get(7)?,
+                username: row.try_get(9)?,
                 amount_received_sat: row.try_get(8)?,
             })
         })
@@ -373,6 +426,7 @@ impl crate::repository::LnurlRepository for LnurlRepository {
              ,      i.updated_at     AS i_updated_at
              ,      i.domain         AS i_domain
              ,      i.amount_received_sat AS i_amount_received_sat
+             ,      i.username       AS i_username
              FROM (SELECT $1 AS payment_hash) ph
              LEFT JOIN zaps z ON z.payment_hash = ph.payment_hash
              LEFT JOIN invoices i ON i.payment_hash = ph.payment_hash",
@@ -408,6 +462,7 @@ impl crate::repository::LnurlRepository for LnurlRepository {
                     created_at: row.try_get("i_created_at")?,
                     updated_at: row.try_get("i_updated_at")?,
                     domain: row.try_get("i_domain")?,
+                    username: row.try_get("i_username")?,
                     amount_received_sat: row.try_get("i_amount_received_sat")?,
                 })
             })
@@ -534,11 +589,11 @@ impl crate::repository::LnurlRepository for LnurlRepository {
             .collect();
         let sql = format!(
             "SELECT i.payment_hash, i.user_pubkey, i.invoice, i.preimage, i.amount_received_sat,
-                    u.name, u.domain,
+                    COALESCE(i.username, u.name), i.domain,
                     sc.sender_comment,
                     i.domain
              FROM invoices i
-             LEFT JOIN users u ON u.pubkey = i.user_pubkey AND u.domain = i.domain
+             LEFT JOIN users u ON u.pubkey = i.user_pubkey AND u.domain = i.domain AND u.label = ''
              LEFT JOIN sender_comments sc ON sc.payment_hash = i.payment_hash
              WHERE i.payment_hash IN ({})
                AND i.domain IS NOT NULL
@@ -573,6 +628,111 @@ impl crate::repository::LnurlRepository for LnurlRepository {
             .collect::<Result<Vec<_>, _>>()?;
         Ok(results)
     }
+
+    async fn insert_payment_link(&self, link: &PaymentLink) -> Result<(), LnurlRepositoryError> {
+        sqlx::query(
+            "INSERT INTO payment_links (id, domain, user_pubkey, amount_sat, memo, created_at, payment_hash)
+             VALUES ($1, $2, $3, $4, $5, $6, $7)",
+        )
+        .bind(&link.id)
+        .bind(&link.domain)
+        .bind(&link.user_pubkey)
+        .bind(link.amount_sat)
+        .bind(&link.memo)
+        .bind(link.created_at)
+        .bind(&link.payment_hash)
+        .execute(&self.pool)
+        .await?;
+        Ok(())
+    }
+
+    async fn get_payment_link(
+        &self,
+        id: &str,
+    ) -> Result<Option<PaymentLink>, LnurlRepositoryError> {
+        let maybe_link = sqlx::query(
+            "SELECT l.id, l.domain, l.user_pubkey, l.amount_sat, l.memo, l.created_at, l.payment_hash, i.invoice, i.preimage
+             FROM payment_links l
+             LEFT JOIN invoices i ON l.payment_hash = i.payment_hash
+             WHERE l.id = $1",
+        )
+        .bind(id)
+        .fetch_optional(&self.pool)
+        .await?
+        .map(|row| map_payment_link(&row))
+        .transpose()?;
+        Ok(maybe_link)
+    }
+
+    async fn list_payment_links(
+        &self,
+        pubkey: &str,
+        offset: u32,
+        limit: u32,
+    ) -> Result<Vec<PaymentLink>, LnurlRepositoryError> {
+        let links = sqlx::query(
+            "SELECT l.id, l.domain, l.user_pubkey, l.amount_sat, l.memo, l.created_at, l.payment_hash, i.invoice, i.preimage
+             FROM payment_links l
+             LEFT JOIN invoices i ON l.payment_hash = i.payment_hash
+             WHERE l.user_pubkey = $1
+             ORDER BY l.created_at DESC
+             LIMIT $3 OFFSET $2",
+        )
+        .bind(pubkey)
+        .bind(i64::from(offset))
+        .bind(i64::from(limit))
+        .fetch_all(&self.pool)
+        .await?
+        .iter()
+        .map(map_payment_link)
+        .collect::<Result<Vec<_>, _>>()?;
+        Ok(links)
+    }
+
+    async fn set_payment_link_invoice(
+        &self,
+        id: &str,
+        previous_payment_hash: Option<&str>,
+        payment_hash: &str,
+    ) -> Result<bool, LnurlRepositoryError> {
+        let result = sqlx::query(
+            "UPDATE payment_links SET payment_hash = $3
+             WHERE id = $1 AND payment_hash IS $2",
+        )
+        .bind(id)
+        .bind(previous_payment_hash)
+        .bind(payment_hash)
+        .execute(&self.pool)
+        .await?;
+        Ok(result.rows_affected() > 0)
+    }
+
+    async fn delete_payment_link(
+        &self,
+        pubkey: &str,
+        id: &str,
+    ) -> Result<bool, LnurlRepositoryError> {
+        let result = sqlx::query("DELETE FROM payment_links WHERE id = $1 AND user_pubkey = $2")
+            .bind(id)
+            .bind(pubkey)
+            .execute(&self.pool)
+            .await?;
+        Ok(result.rows_affected() > 0)
+    }
+}
+
+fn map_payment_link(row: &sqlx::sqlite::SqliteRow) -> Result<PaymentLink, sqlx::Error> {
+    Ok(PaymentLink {
+        id: row.try_get(0)?,
+        domain: row.try_get(1)?,
+        user_pubkey: row.try_get(2)?,
+        amount_sat: row.try_get(3)?,
+        memo: row.try_get(4)?,
+        created_at: row.try_get(5)?,
+        payment_hash: row.try_get(6)?,
+        invoice: row.try_get(7)?,
+        preimage: row.try_get(8)?,
+    })
 }
 
 #[async_trait::async_trait]
@@ -755,6 +915,25 @@ impl crate::webhooks::WebhookRepository for LnurlRepository {
     }
 }
 
+/// Reads a `pubkey, name, description, label, image, long_description,
+/// min_sendable, max_sendable, nostr_pubkey` row. The primary address is stored with an
+/// empty label so it takes part in the primary key.
+fn user_from_row(domain: &str, row: &sqlx::sqlite::SqliteRow) -> Result<User, sqlx::Error> {
+    let label: String = row.try_get(3)?;
+    Ok(User {
+        domain: domain.to_string(),
+        pubkey: row.try_get(0)?,
+        name: row.try_get(1)?,
+        description: row.try_get(2)?,
+        label: Some(label).filter(|label| !label.is_empty()),
+        image: row.try_get(4)?,
+        long_description: row.try_get(5)?,
+        min_sendable: row.try_get(6)?,
+        max_sendable: row.try_get(7)?,
+        nostr_pubkey: row.try_get(8)?,
+    })
+}
+
 #[cfg(test)]
 mod sqlite_tests {
     use crate::repository::shared_tests;
@@ -808,4 +987,32 @@ mod sqlite_tests {
         let db = super::LnurlRepository::new(pool);
         shared_tests::deleting_a_name_the_pubkey_no_longer_holds_is_a_no_op(&db).await;
     }
+
+    #[tokio::test]
+    async fn pubkey_holds_labeled_addresses() {
+        let pool = setup_pool().await;
+        let db = super::LnurlRepository::new(pool);
+        shared_tests::pubkey_holds_labeled_addresses(&db).await;
+    }
+
+    #[tokio::test]
+    async fn address_metadata_is_kept_until_transfer() {
+        let pool = setup_pool().await;
+        let db = super::LnurlRepository::new(pool);
+        shared_tests::address_metadata_is_kept_until_transfer(&db).await;
+    }
+
+    #[tokio::test]
+    async fn nostr_pubkey_is_set_by_the_address_owner() {
+        let pool = setup_pool().await;
+        let db = super::LnurlRepository::new(pool);
+        shared_tests::nostr_pubkey_is_set_by_the_address_owner(&db).await;
+    }
+
+    #[tokio::test]
+    async fn payment_link_serves_one_invoice_at_a_time() {
+        let pool = setup_pool().await;
+        let db = super::LnurlRepository::new(pool);
+        shared_tests::payment_link_serves_one_invoice_at_a_time(&db).await;
+    }
 }
@@ -10,4 +10,18 @@ pub struct User {
     pub pubkey: String,
     pub name: String,
     pub description: String,
+    /// Set for the additional addresses of a pubkey, `None` for its primary
+    /// address.
+    pub label: Option<String>,
+    /// Base64 encoded PNG shown by wallets on the pay page.
+    pub image: Option<String>,
+    /// Longer form of the description, shown by wallets on the pay page.
+    pub long_description: Option<String>,
+    /// Overrides the server's minimum sendable amount, in millisats.
+    pub min_sendable: Option<i64>,
+    /// Overrides the server's maximum sendable amount, in millisats.
+    pub max_sendable: Option<i64>,
+    /// Hex encoded x-only nostr pubkey attached to the address, for NIP-05.
+    /// Set with `set_user_nostr_pubkey`, not by `upsert_user`.
+    pub nostr_pubkey: Option<String>,
 }
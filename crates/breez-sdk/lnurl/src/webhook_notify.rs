@@ -127,6 +127,12 @@ mod shared_tests {
             pubkey: "enqueue_pubkey".to_string(),
             domain: domain.to_string(),
             description: String::new(),
+            label: None,
+            image: None,
+            long_description: None,
+            min_sendable: None,
+            max_sendable: None,
+            nostr_pubkey: None,
         })
         .await
         .unwrap();
@@ -141,6 +147,7 @@ mod shared_tests {
             created_at: now,
             updated_at: now,
             domain: Some(domain.to_string()),
+            username: None,
             amount_received_sat: Some(1000),
         };
         db.upsert_invoice(&invoice).await.unwrap();
@@ -187,6 +194,7 @@ mod shared_tests {
             created_at: now,
             updated_at: now,
             domain: None,
+            username: None,
             amount_received_sat: None,
         };
         db.upsert_invoice(&invoice).await.unwrap();
@@ -225,6 +233,7 @@ mod shared_tests {
             created_at: now,
             updated_at: now,
             domain: Some(domain.to_string()),
+            username: None,
             amount_received_sat: Some(1000),
         };
         db.upsert_invoice(&invoice).await.unwrap();
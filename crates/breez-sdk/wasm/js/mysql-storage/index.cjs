@@ -69,6 +69,7 @@ const SELECT_PAYMENT_SQL = `
            pm.lnurl_withdraw_info,
            pm.conversion_info,
            pm.conversion_status,
+           pm.fiat_value,
            t.metadata AS token_metadata,
            t.tx_hash AS token_tx_hash,
            t.tx_type AS token_tx_type,
@@ -79,6 +80,7 @@ const SELECT_PAYMENT_SQL = `
            lrm.nostr_zap_receipt AS lnurl_nostr_zap_receipt,
            lrm.sender_comment AS lnurl_sender_comment,
            lrm.payment_hash AS lnurl_payment_hash,
+           lrm.lightning_address AS lnurl_lightning_address,
            pm.parent_payment_id
       FROM brz_payments p
       LEFT JOIN brz_payment_details_lightning l ON p.id = l.payment_id AND p.user_id = l.user_id
@@ -105,6 +107,20 @@ function toBool(value) {
   return value === 1 || value === "1" || value === true;
 }
 
+/** Maps a brz_contacts row to the camelCase Contact shape the SDK expects. */
+function contactFromRow(row) {
+  return {
+    id: row.id,
+    name: row.name,
+    paymentIdentifier: row.payment_identifier,
+    additionalPaymentIdentifiers: parseJson(row.additional_payment_identifiers) ?? [],
+    avatarUrl: row.avatar_url,
+    notes: row.notes,
+    createdAt: Number(row.created_at),
+    updatedAt: Number(row.updated_at),
+  };
+}
+
 /**
  * Maps a brz_cross_chain_swaps row to the camelCase StoredCrossChainSwap shape
  * the SDK expects, coercing TINYINT(1) to a boolean.
@@ -732,15 +748,16 @@ class MysqlStorage {
   async insertPaymentMetadata(paymentId, metadata) {
     try {
       await this.pool.query(
-        `INSERT INTO brz_payment_metadata (user_id, payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status)
-         VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        `INSERT INTO brz_payment_metadata (user_id, payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status, fiat_value)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
          ON DUPLICATE KEY UPDATE
            parent_payment_id = COALESCE(VALUES(parent_payment_id), parent_payment_id),
            lnurl_pay_info = COALESCE(VALUES(lnurl_pay_info), lnurl_pay_info),
            lnurl_withdraw_info = COALESCE(VALUES(lnurl_withdraw_info), lnurl_withdraw_info),
            lnurl_description = COALESCE(VALUES(lnurl_description), lnurl_description),
            conversion_info = COALESCE(VALUES(conversion_info), conversion_info),
-           conversion_status = COALESCE(VALUES(conversion_status), conversion_status)`,
+           conversion_status = COALESCE(VALUES(conversion_status), conversion_status),
+           fiat_value = COALESCE(VALUES(fiat_value), fiat_value)`,
         [
           this.identity,
           paymentId,
@@ -756,6 +773,7 @@ class MysqlStorage {
             ? JSON.stringify(metadata.conversionInfo)
             : null,
           metadata.conversionStatus ?? null,
+          metadata.fiatValue ? JSON.stringify(metadata.fiatValue) : null,
         ]
       );
     } catch (error) {
@@ -856,18 +874,20 @@ class MysqlStorage {
       await this._withTransaction(async (conn) => {
         for (const item of metadata) {
           await conn.query(
-            `INSERT INTO brz_lnurl_receive_metadata (user_id, payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment)
-             VALUES (?, ?, ?, ?, ?)
+            `INSERT INTO brz_lnurl_receive_metadata (user_id, payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment, lightning_address)
+             VALUES (?, ?, ?, ?, ?, ?)
              ON DUPLICATE KEY UPDATE
                nostr_zap_request = VALUES(nostr_zap_request),
                nostr_zap_receipt = VALUES(nostr_zap_receipt),
-               sender_comment = VALUES(sender_comment)`,
+               sender_comment = VALUES(sender_comment),
+               lightning_address = VALUES(lightning_address)`,
             [
               this.identity,
               item.paymentHash,
               item.nostrZapRequest || null,
               item.nostrZapReceipt || null,
               item.senderComment || null,
+              item.lightningAddress || null,
             ]
           );
         }
@@ -918,6 +938,7 @@ class MysqlStorage {
           nostrZapRequest: row.lnurl_nostr_zap_request || null,
           nostrZapReceipt: row.lnurl_nostr_zap_receipt || null,
           senderComment: row.lnurl_sender_comment || null,
+          lightningAddress: row.lnurl_lightning_address || null,
         };
       }
 
@@ -977,6 +998,7 @@ class MysqlStorage {
       conversionDetails: row.conversion_status
         ? { status: row.conversion_status, from: null, to: null }
         : null,
+      fiatValue: parseJson(row.fiat_value),
     };
   }
 
@@ -988,7 +1010,8 @@ class MysqlStorage {
       const limit = request.limit != null ? request.limit : 4294967295;
 
       const [rows] = await this.pool.query(
-        `SELECT id, name, payment_identifier, created_at, updated_at
+        `SELECT id, name, payment_identifier, created_at, updated_at,
+                additional_payment_identifiers, avatar_url, notes
          FROM brz_contacts
          WHERE user_id = ?
          ORDER BY name ASC
@@ -996,13 +1019,7 @@ class MysqlStorage {
         [this.identity, limit, offset]
       );
 
-      return rows.map((row) => ({
-        id: row.id,
-        name: row.name,
-        paymentIdentifier: row.payment_identifier,
-        createdAt: Number(row.created_at),
-        updatedAt: Number(row.updated_at),
-      }));
+      return rows.map(contactFromRow);
     } catch (error) {
       throw new StorageError(
         `Failed to list contacts: ${error.message}`,
@@ -1014,7 +1031,8 @@ class MysqlStorage {
   async getContact(id) {
     try {
       const [rows] = await this.pool.query(
-        `SELECT id, name, payment_identifier, created_at, updated_at
+        `SELECT id, name, payment_identifier, created_at, updated_at,
+                additional_payment_identifiers, avatar_url, notes
          FROM brz_contacts
          WHERE user_id = ? AND id = ?`,
         [this.identity, id]
@@ -1025,13 +1043,7 @@ class MysqlStorage {
       }
 
       const row = rows[0];
-      return {
-        id: row.id,
-        name: row.name,
-        paymentIdentifier: row.payment_identifier,
-        createdAt: Number(row.created_at),
-        updatedAt: Number(row.updated_at),
-      };
+      return contactFromRow(row);
     } catch (error) {
       throw new StorageError(`Failed to get contact: ${error.message}`, error);
     }
@@ -1040,12 +1052,15 @@ class MysqlStorage {
   async insertContact(contact) {
     try {
       await this.pool.query(
-        `INSERT INTO brz_contacts (user_id, id, name, payment_identifier, created_at, updated_at)
-         VALUES (?, ?, ?, ?, ?, ?)
+        `INSERT INTO brz_contacts (user_id, id, name, payment_identifier, created_at, updated_at, additional_payment_identifiers, avatar_url, notes)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
          ON DUPLICATE KEY UPDATE
            name = VALUES(name),
            payment_identifier = VALUES(payment_identifier),
-           updated_at = VALUES(updated_at)`,
+           updated_at = VALUES(updated_at),
+           additional_payment_identifiers = VALUES(additional_payment_identifiers),
+           avatar_url = VALUES(avatar_url),
+           notes = VALUES(notes)`,
         [
           this.identity,
           contact.id,
@@ -1053,6 +1068,9 @@ class MysqlStorage {
           contact.paymentIdentifier,
           contact.createdAt,
           contact.updatedAt,
+          JSON.stringify(contact.additionalPaymentIdentifiers || []),
+          contact.avatarUrl ?? null,
+          contact.notes ?? null,
         ]
       );
     } catch (error) {
@@ -1527,6 +1545,42 @@ class MysqlStorage {
       );
     }
   }
+
+  async deleteAllData() {
+    try {
+      await this._withTransaction(async (conn) => {
+        // Only this tenant's rows are deleted; other tenants sharing the
+        // database are untouched.
+        for (const table of [
+          "brz_payment_details_lightning",
+          "brz_payment_details_token",
+          "brz_payment_details_spark",
+          "brz_payment_details_deposit",
+          "brz_payment_metadata",
+          "brz_payments",
+          "brz_unclaimed_deposits",
+          "brz_lnurl_receive_metadata",
+          "brz_contacts",
+          "brz_cross_chain_swaps",
+          "brz_settings",
+          "brz_sync_outgoing",
+          "brz_sync_incoming",
+          "brz_sync_state",
+          "brz_sync_revision",
+        ]) {
+          await conn.query(`DELETE FROM ${table} WHERE user_id = ?`, [
+            this.identity,
+          ]);
+        }
+      });
+    } catch (error) {
+      if (error instanceof StorageError) throw error;
+      throw new StorageError(
+        `Failed to delete all data: ${error.message}`,
+        error
+      );
+    }
+  }
 }
 
 /**
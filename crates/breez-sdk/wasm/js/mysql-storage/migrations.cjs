@@ -562,6 +562,26 @@ class MysqlMigrationManager {
           )`,
         ],
       },
+      {
+        name: "Add fiat_value to brz_payment_metadata",
+        sql: [
+          `ALTER TABLE brz_payment_metadata ADD COLUMN fiat_value JSON NULL`,
+        ],
+      },
+      {
+        name: "Add contact details to brz_contacts",
+        sql: [
+          `ALTER TABLE brz_contacts ADD COLUMN additional_payment_identifiers JSON NULL`,
+          `ALTER TABLE brz_contacts ADD COLUMN avatar_url MEDIUMTEXT NULL`,
+          `ALTER TABLE brz_contacts ADD COLUMN notes TEXT NULL`,
+        ],
+      },
+      {
+        name: "Add lightning_address to brz_lnurl_receive_metadata",
+        sql: [
+          `ALTER TABLE brz_lnurl_receive_metadata ADD COLUMN lightning_address VARCHAR(255) NULL`,
+        ],
+      },
     ];
   }
 }
@@ -55,6 +55,7 @@ const SELECT_PAYMENT_SQL = `
            pm.lnurl_withdraw_info,
            pm.conversion_info,
            pm.conversion_status,
+           pm.fiat_value,
            t.metadata AS token_metadata,
            t.tx_hash AS token_tx_hash,
            t.tx_type AS token_tx_type,
@@ -65,6 +66,7 @@ const SELECT_PAYMENT_SQL = `
            lrm.nostr_zap_receipt AS lnurl_nostr_zap_receipt,
            lrm.sender_comment AS lnurl_sender_comment,
            lrm.payment_hash AS lnurl_payment_hash,
+           lrm.lightning_address AS lnurl_lightning_address,
            pm.parent_payment_id
       FROM payments p
       LEFT JOIN payment_details_lightning l ON p.id = l.payment_id
@@ -622,15 +624,16 @@ class SqliteStorage {
   insertPaymentMetadata(paymentId, metadata) {
     try {
       const stmt = this.db.prepare(`
-                INSERT INTO payment_metadata (payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status)
-                VALUES (?, ?, ?, ?, ?, ?, ?)
+                INSERT INTO payment_metadata (payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status, fiat_value)
+                VALUES (?, ?, ?, ?, ?, ?, ?, ?)
                 ON CONFLICT(payment_id) DO UPDATE SET
                     parent_payment_id = COALESCE(excluded.parent_payment_id, parent_payment_id),
                     lnurl_pay_info = COALESCE(excluded.lnurl_pay_info, lnurl_pay_info),
                     lnurl_withdraw_info = COALESCE(excluded.lnurl_withdraw_info, lnurl_withdraw_info),
                     lnurl_description = COALESCE(excluded.lnurl_description, lnurl_description),
                     conversion_info = COALESCE(excluded.conversion_info, conversion_info),
-                    conversion_status = COALESCE(excluded.conversion_status, conversion_status)
+                    conversion_status = COALESCE(excluded.conversion_status, conversion_status),
+                    fiat_value = COALESCE(excluded.fiat_value, fiat_value)
             `);
 
       stmt.run(
@@ -644,7 +647,8 @@ class SqliteStorage {
         metadata.conversionInfo
           ? JSON.stringify(metadata.conversionInfo)
           : null,
-        metadata.conversionStatus ?? null
+        metadata.conversionStatus ?? null,
+        metadata.fiatValue ? JSON.stringify(metadata.fiatValue) : null
       );
       return Promise.resolve();
     } catch (error) {
@@ -761,7 +765,7 @@ class SqliteStorage {
   setLnurlMetadata(metadata) {
     try {
       const stmt = this.db.prepare(
-        "INSERT OR REPLACE INTO lnurl_receive_metadata (payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment) VALUES (?, ?, ?, ?)"
+        "INSERT OR REPLACE INTO lnurl_receive_metadata (payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment, lightning_address) VALUES (?, ?, ?, ?, ?)"
       );
 
       const transaction = this.db.transaction(() => {
@@ -770,7 +774,8 @@ class SqliteStorage {
             item.paymentHash,
             item.nostrZapRequest || null,
             item.nostrZapReceipt || null,
-            item.senderComment || null
+            item.senderComment || null,
+            item.lightningAddress || null
           );
         }
       });
@@ -834,6 +839,7 @@ class SqliteStorage {
           nostrZapRequest: row.lnurl_nostr_zap_request || null,
           nostrZapReceipt: row.lnurl_nostr_zap_receipt || null,
           senderComment: row.lnurl_sender_comment || null,
+          lightningAddress: row.lnurl_lightning_address || null,
         };
       }
 
@@ -910,6 +916,7 @@ class SqliteStorage {
       conversionDetails: row.conversion_status
         ? { status: row.conversion_status, from: null, to: null }
         : null,
+      fiatValue: row.fiat_value ? JSON.parse(row.fiat_value) : null,
     };
   }
 
@@ -1335,6 +1342,41 @@ class SqliteStorage {
     }
   }
 
+  deleteAllData() {
+    try {
+      // Overwrite deleted content with zeros rather than only unlinking it
+      this.db.pragma("secure_delete = ON");
+      const transaction = this.db.transaction(() => {
+        this.db.exec(`
+          DELETE FROM payment_details_lightning;
+          DELETE FROM payment_details_token;
+          DELETE FROM payment_details_spark;
+          DELETE FROM payment_details_deposit;
+          DELETE FROM payment_metadata;
+          DELETE FROM payments;
+          DELETE FROM unclaimed_deposits;
+          DELETE FROM lnurl_receive_metadata;
+          DELETE FROM contacts;
+          DELETE FROM cross_chain_swaps;
+          DELETE FROM settings;
+          DELETE FROM sync_outgoing;
+          DELETE FROM sync_incoming;
+          DELETE FROM sync_state;
+          UPDATE sync_revision SET revision = 0;
+        `);
+      });
+
+      transaction();
+      // Release the freed pages so no deleted data is left in the file
+      this.db.exec("VACUUM");
+      return Promise.resolve();
+    } catch (error) {
+      return Promise.reject(
+        new StorageError(`Failed to delete all data: ${error.message}`, error)
+      );
+    }
+  }
+
   // ===== Contact Operations =====
 
   listContacts(request) {
@@ -1343,14 +1385,15 @@ class SqliteStorage {
       const limit = request.limit !== null && request.limit !== undefined ? request.limit : 4294967295;
 
       const stmt = this.db.prepare(`
-        SELECT id, name, payment_identifier AS paymentIdentifier, created_at AS createdAt, updated_at AS updatedAt
+        SELECT id, name, payment_identifier AS paymentIdentifier, created_at AS createdAt, updated_at AS updatedAt,
+               additional_payment_identifiers, avatar_url AS avatarUrl, notes
         FROM contacts
         ORDER BY name ASC
         LIMIT ? OFFSET ?
       `);
       const rows = stmt.all(limit, offset);
 
-      return Promise.resolve(rows);
+      return Promise.resolve(rows.map((row) => this._rowToContact(row)));
     } catch (error) {
       return Promise.reject(
         new StorageError(`Failed to list contacts: ${error.message}`, error)
@@ -1361,12 +1404,13 @@ class SqliteStorage {
   getContact(id) {
     try {
       const stmt = this.db.prepare(`
-        SELECT id, name, payment_identifier AS paymentIdentifier, created_at AS createdAt, updated_at AS updatedAt
+        SELECT id, name, payment_identifier AS paymentIdentifier, created_at AS createdAt, updated_at AS updatedAt,
+               additional_payment_identifiers, avatar_url AS avatarUrl, notes
         FROM contacts
         WHERE id = ?
       `);
       const row = stmt.get(id);
-      return Promise.resolve(row || null);
+      return Promise.resolve(row ? this._rowToContact(row) : null);
     } catch (error) {
       return Promise.reject(
         new StorageError(`Failed to get contact: ${error.message}`, error)
@@ -1374,15 +1418,28 @@ class SqliteStorage {
     }
   }
 
+  _rowToContact(row) {
+    const { additional_payment_identifiers, ...contact } = row;
+    return {
+      ...contact,
+      additionalPaymentIdentifiers: additional_payment_identifiers
+        ? JSON.parse(additional_payment_identifiers)
+        : [],
+    };
+  }
+
   insertContact(contact) {
     try {
       const stmt = this.db.prepare(`
-        INSERT INTO contacts (id, name, payment_identifier, created_at, updated_at)
-        VALUES (?, ?, ?, ?, ?)
+        INSERT INTO contacts (id, name, payment_identifier, created_at, updated_at, additional_payment_identifiers, avatar_url, notes)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
         ON CONFLICT(id) DO UPDATE SET
           name = excluded.name,
           payment_identifier = excluded.payment_identifier,
-          updated_at = excluded.updated_at
+          updated_at = excluded.updated_at,
+          additional_payment_identifiers = excluded.additional_payment_identifiers,
+          avatar_url = excluded.avatar_url,
+          notes = excluded.notes
       `);
 
       stmt.run(
@@ -1390,7 +1447,10 @@ class SqliteStorage {
         contact.name,
         contact.paymentIdentifier,
         contact.createdAt,
-        contact.updatedAt
+        contact.updatedAt,
+        JSON.stringify(contact.additionalPaymentIdentifiers || []),
+        contact.avatarUrl ?? null,
+        contact.notes ?? null
       );
 
       return Promise.resolve();
@@ -480,6 +480,23 @@ class MigrationManager {
             ON cross_chain_swaps(provider, is_terminal)`,
         ],
       },
+      {
+        name: "Add fiat_value to payment_metadata",
+        sql: `ALTER TABLE payment_metadata ADD COLUMN fiat_value TEXT`,
+      },
+      {
+        // The additional payment identifiers are a JSON array
+        name: "Add contact details",
+        sql: [
+          `ALTER TABLE contacts ADD COLUMN additional_payment_identifiers TEXT`,
+          `ALTER TABLE contacts ADD COLUMN avatar_url TEXT`,
+          `ALTER TABLE contacts ADD COLUMN notes TEXT`,
+        ],
+      },
+      {
+        name: "Add lightning_address to lnurl_receive_metadata",
+        sql: `ALTER TABLE lnurl_receive_metadata ADD COLUMN lightning_address TEXT`,
+      },
     ];
   }
 }
@@ -54,6 +54,7 @@ const SELECT_PAYMENT_SQL = `
            pm.lnurl_withdraw_info,
            pm.conversion_info,
            pm.conversion_status,
+           pm.fiat_value,
            t.metadata AS token_metadata,
            t.tx_hash AS token_tx_hash,
            t.tx_type AS token_tx_type,
@@ -64,6 +65,7 @@ const SELECT_PAYMENT_SQL = `
            lrm.nostr_zap_receipt AS lnurl_nostr_zap_receipt,
            lrm.sender_comment AS lnurl_sender_comment,
            lrm.payment_hash AS lnurl_payment_hash,
+           lrm.lightning_address AS lnurl_lightning_address,
            pm.parent_payment_id
       FROM brz_payments p
       LEFT JOIN brz_payment_details_lightning l ON p.id = l.payment_id AND p.user_id = l.user_id
@@ -661,15 +663,16 @@ class PostgresStorage {
   async insertPaymentMetadata(paymentId, metadata) {
     try {
       await this.pool.query(
-        `INSERT INTO brz_payment_metadata (user_id, payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status)
-         VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        `INSERT INTO brz_payment_metadata (user_id, payment_id, parent_payment_id, lnurl_pay_info, lnurl_withdraw_info, lnurl_description, conversion_info, conversion_status, fiat_value)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
          ON CONFLICT(user_id, payment_id) DO UPDATE SET
            parent_payment_id = COALESCE(EXCLUDED.parent_payment_id, brz_payment_metadata.parent_payment_id),
            lnurl_pay_info = COALESCE(EXCLUDED.lnurl_pay_info, brz_payment_metadata.lnurl_pay_info),
            lnurl_withdraw_info = COALESCE(EXCLUDED.lnurl_withdraw_info, brz_payment_metadata.lnurl_withdraw_info),
            lnurl_description = COALESCE(EXCLUDED.lnurl_description, brz_payment_metadata.lnurl_description),
            conversion_info = COALESCE(EXCLUDED.conversion_info, brz_payment_metadata.conversion_info),
-           conversion_status = COALESCE(EXCLUDED.conversion_status, brz_payment_metadata.conversion_status)`,
+           conversion_status = COALESCE(EXCLUDED.conversion_status, brz_payment_metadata.conversion_status),
+           fiat_value = COALESCE(EXCLUDED.fiat_value, brz_payment_metadata.fiat_value)`,
         [
           this.identity,
           paymentId,
@@ -685,6 +688,7 @@ class PostgresStorage {
             ? JSON.stringify(metadata.conversionInfo)
             : null,
           metadata.conversionStatus ?? null,
+          metadata.fiatValue ? JSON.stringify(metadata.fiatValue) : null,
         ]
       );
     } catch (error) {
@@ -784,18 +788,20 @@ class PostgresStorage {
       await this._withTransaction(async (client) => {
         for (const item of metadata) {
           await client.query(
-            `INSERT INTO brz_lnurl_receive_metadata (user_id, payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment)
-             VALUES ($1, $2, $3, $4, $5)
+            `INSERT INTO brz_lnurl_receive_metadata (user_id, payment_hash, nostr_zap_request, nostr_zap_receipt, sender_comment, lightning_address)
+             VALUES ($1, $2, $3, $4, $5, $6)
              ON CONFLICT(user_id, payment_hash) DO UPDATE SET
                nostr_zap_request = EXCLUDED.nostr_zap_request,
                nostr_zap_receipt = EXCLUDED.nostr_zap_receipt,
-               sender_comment = EXCLUDED.sender_comment`,
+               sender_comment = EXCLUDED.sender_comment,
+               lightning_address = EXCLUDED.lightning_address`,
             [
               this.identity,
               item.paymentHash,
               item.nostrZapRequest || null,
               item.nostrZapReceipt || null,
               item.senderComment || null,
+              item.lightningAddress || null,
             ]
           );
         }
@@ -853,6 +859,7 @@ class PostgresStorage {
           nostrZapRequest: row.lnurl_nostr_zap_request || null,
           nostrZapReceipt: row.lnurl_nostr_zap_receipt || null,
           senderComment: row.lnurl_sender_comment || null,
+          lightningAddress: row.lnurl_lightning_address || null,
         };
       }
 
@@ -941,6 +948,11 @@ class PostgresStorage {
       conversionDetails: row.conversion_status
         ? { status: row.conversion_status, from: null, to: null }
         : null,
+      fiatValue: row.fiat_value
+        ? typeof row.fiat_value === "string"
+          ? JSON.parse(row.fiat_value)
+          : row.fiat_value
+        : null,
     };
   }
 
@@ -952,7 +964,8 @@ class PostgresStorage {
       const limit = request.limit != null ? request.limit : 4294967295;
 
       const result = await this.pool.query(
-        `SELECT id, name, payment_identifier, created_at, updated_at
+        `SELECT id, name, payment_identifier, created_at, updated_at,
+                additional_payment_identifiers, avatar_url, notes
          FROM brz_contacts
          WHERE user_id = $1
          ORDER BY name ASC
@@ -960,13 +973,7 @@ class PostgresStorage {
         [this.identity, limit, offset]
       );
 
-      return result.rows.map((row) => ({
-        id: row.id,
-        name: row.name,
-        paymentIdentifier: row.payment_identifier,
-        createdAt: Number(row.created_at),
-        updatedAt: Number(row.updated_at),
-      }));
+      return result.rows.map(contactFromRow);
     } catch (error) {
       throw new StorageError(
         `Failed to list contacts: ${error.message}`,
@@ -978,7 +985,8 @@ class PostgresStorage {
   async getContact(id) {
     try {
       const result = await this.pool.query(
-        `SELECT id, name, payment_identifier, created_at, updated_at
+        `SELECT id, name, payment_identifier, created_at, updated_at,
+                additional_payment_identifiers, avatar_url, notes
          FROM brz_contacts
          WHERE user_id = $1 AND id = $2`,
         [this.identity, id]
@@ -989,13 +997,7 @@ class PostgresStorage {
       }
 
       const row = result.rows[0];
-      return {
-        id: row.id,
-        name: row.name,
-        paymentIdentifier: row.payment_identifier,
-        createdAt: Number(row.created_at),
-        updatedAt: Number(row.updated_at),
-      };
+      return contactFromRow(row);
     } catch (error) {
       throw new StorageError(
         `Failed to get contact: ${error.message}`,
@@ -1007,12 +1009,15 @@ class PostgresStorage {
   async insertContact(contact) {
     try {
       await this.pool.query(
-        `INSERT INTO brz_contacts (user_id, id, name, payment_identifier, created_at, updated_at)
-         VALUES ($1, $2, $3, $4, $5, $6)
+        `INSERT INTO brz_contacts (user_id, id, name, payment_identifier, created_at, updated_at, additional_payment_identifiers, avatar_url, notes)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
          ON CONFLICT(user_id, id) DO UPDATE SET
            name = EXCLUDED.name,
            payment_identifier = EXCLUDED.payment_identifier,
-           updated_at = EXCLUDED.updated_at`,
+           updated_at = EXCLUDED.updated_at,
+           additional_payment_identifiers = EXCLUDED.additional_payment_identifiers,
+           avatar_url = EXCLUDED.avatar_url,
+           notes = EXCLUDED.notes`,
         [
           this.identity,
           contact.id,
@@ -1020,6 +1025,9 @@ class PostgresStorage {
           contact.paymentIdentifier,
           contact.createdAt,
           contact.updatedAt,
+          JSON.stringify(contact.additionalPaymentIdentifiers || []),
+          contact.avatarUrl ?? null,
+          contact.notes ?? null,
         ]
       );
     } catch (error) {
@@ -1536,6 +1544,56 @@ class PostgresStorage {
       );
     }
   }
+
+  async deleteAllData() {
+    try {
+      await this._withTransaction(async (client) => {
+        // Only this tenant's rows are deleted; other tenants sharing the
+        // database are untouched.
+        for (const table of [
+          "brz_payment_details_lightning",
+          "brz_payment_details_token",
+          "brz_payment_details_spark",
+          "brz_payment_details_deposit",
+          "brz_payment_metadata",
+          "brz_payments",
+          "brz_unclaimed_deposits",
+          "brz_lnurl_receive_metadata",
+          "brz_contacts",
+          "brz_cross_chain_swaps",
+          "brz_settings",
+          "brz_sync_outgoing",
+          "brz_sync_incoming",
+          "brz_sync_state",
+          "brz_sync_revision",
+        ]) {
+          await client.query(`DELETE FROM ${table} WHERE user_id = $1`, [
+            this.identity,
+          ]);
+        }
+      });
+    } catch (error) {
+      if (error instanceof StorageError) throw error;
+      throw new StorageError(
+        `Failed to delete all data: ${error.message}`,
+        error
+      );
+    }
+  }
+}
+
+/** Maps a brz_contacts row to the camelCase Contact shape the SDK expects. */
+function contactFromRow(row) {
+  return {
+    id: row.id,
+    name: row.name,
+    paymentIdentifier: row.payment_identifier,
+    additionalPaymentIdentifiers: row.additional_payment_identifiers ?? [],
+    avatarUrl: row.avatar_url,
+    notes: row.notes,
+    createdAt: Number(row.created_at),
+    updatedAt: Number(row.updated_at),
+  };
 }
 
 /**
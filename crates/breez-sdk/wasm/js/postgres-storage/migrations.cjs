@@ -518,6 +518,26 @@ class PostgresMigrationManager {
              ON brz_cross_chain_swaps(user_id, provider, is_terminal)`,
         ],
       },
+      {
+        name: "Add fiat_value to brz_payment_metadata",
+        sql: [
+          `ALTER TABLE brz_payment_metadata ADD COLUMN IF NOT EXISTS fiat_value JSONB`,
+        ],
+      },
+      {
+        name: "Add contact details to brz_contacts",
+        sql: [
+          `ALTER TABLE brz_contacts ADD COLUMN IF NOT EXISTS additional_payment_identifiers JSONB`,
+          `ALTER TABLE brz_contacts ADD COLUMN IF NOT EXISTS avatar_url TEXT`,
+          `ALTER TABLE brz_contacts ADD COLUMN IF NOT EXISTS notes TEXT`,
+        ],
+      },
+      {
+        name: "Add lightning_address to brz_lnurl_receive_metadata",
+        sql: [
+          `ALTER TABLE brz_lnurl_receive_metadata ADD COLUMN IF NOT EXISTS lightning_address TEXT`,
+        ],
+      },
     ];
   }
 }
@@ -1365,6 +1365,9 @@ class IndexedDBStorage {
             ? JSON.stringify(metadata.conversionInfo)
             : existing.conversionInfo ?? null,
           conversionStatus: metadata.conversionStatus ?? existing.conversionStatus ?? null,
+          fiatValue: metadata.fiatValue
+            ? JSON.stringify(metadata.fiatValue)
+            : existing.fiatValue ?? null,
         };
 
         const putRequest = store.put(metadataToStore);
@@ -1595,6 +1598,7 @@ class IndexedDBStorage {
           nostrZapRequest: item.nostrZapRequest || null,
           nostrZapReceipt: item.nostrZapReceipt || null,
           senderComment: item.senderComment || null,
+          lightningAddress: item.lightningAddress || null,
         });
 
         request.onsuccess = () => {
@@ -2093,6 +2097,31 @@ class IndexedDBStorage {
     });
   }
 
+  async deleteAllData() {
+    if (!this.db) {
+      throw new StorageError("Database not initialized");
+    }
+
+    return new Promise((resolve, reject) => {
+      const storeNames = Array.from(this.db.objectStoreNames);
+      const transaction = this.db.transaction(storeNames, "readwrite");
+
+      for (const storeName of storeNames) {
+        transaction.objectStore(storeName).clear();
+      }
+      transaction.objectStore("sync_revision").put({ id: 1, revision: "0" });
+
+      transaction.oncomplete = () => resolve();
+      transaction.onerror = (event) => {
+        reject(
+          new StorageError(
+            `Failed to delete all data: ${event.target.error.message}`
+          )
+        );
+      };
+    });
+  }
+
   // ===== Contact Operations =====
 
   async listContacts(request) {
@@ -2599,6 +2628,7 @@ class IndexedDBStorage {
       conversionDetails: metadata?.conversionStatus
         ? { status: metadata.conversionStatus, from: null, to: null }
         : null,
+      fiatValue: metadata?.fiatValue ? JSON.parse(metadata.fiatValue) : null,
     };
   }
 
@@ -2628,6 +2658,7 @@ class IndexedDBStorage {
             nostrZapRequest: lnurlReceiveMetadata.nostrZapRequest || null,
             nostrZapReceipt: lnurlReceiveMetadata.nostrZapReceipt || null,
             senderComment: lnurlReceiveMetadata.senderComment || null,
+            lightningAddress: lnurlReceiveMetadata.lightningAddress || null,
           };
         }
         resolve(payment);
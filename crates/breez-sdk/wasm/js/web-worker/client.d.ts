@@ -0,0 +1,28 @@
+/** An SDK object living in the worker, with every method returning a Promise. */
+export type Remote<T> = {
+    [K in keyof T as T[K] extends (...args: any[]) => any ? K : never]: RemoteFunction<T[K]>;
+};
+
+/** The value returned across the worker boundary: SDK objects stay in the worker. */
+export type RemoteValue<T> = T extends { free(): void } ? Remote<T> : T;
+
+/** A function running in the worker. */
+export type RemoteFunction<F> = F extends (...args: infer A) => infer R
+    ? (...args: A) => Promise<RemoteValue<Awaited<R>>>
+    : never;
+
+/** An SDK class: its static methods, and `create` for classes with a public constructor. */
+export type RemoteClass<C> = Remote<C> &
+    (C extends new (...args: infer A) => infer I
+        ? { create(...args: A): Promise<Remote<I>> }
+        : {});
+
+export interface WorkerClient {
+    init(wasmInput?: any): Promise<void>;
+    call(name: string, args: unknown[]): Promise<unknown>;
+    callStatic(className: string, name: string, args: unknown[]): Promise<unknown>;
+    construct(className: string, args: unknown[]): Promise<unknown>;
+    terminate(): void;
+}
+
+export function createWorkerClient(worker: Worker): WorkerClient;
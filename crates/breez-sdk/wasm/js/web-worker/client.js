@@ -0,0 +1,186 @@
+/**
+ * Main thread side of the Breez SDK web worker mode.
+ *
+ * Forwards calls to the worker started by the generated facade
+ * (`./index.js`) and wraps the SDK objects living in the worker in proxies.
+ * Every call crosses the thread boundary, so every function and method of
+ * the facade returns a Promise, including those that are synchronous in the
+ * regular entry points.
+ */
+
+const REMOTE_ID = Symbol('breezRemoteId');
+
+/**
+ * Collects the names of the functions of `value` and its prototypes, which
+ * is how interface implementations such as event listeners, loggers or
+ * storages are passed to the SDK.
+ * @param {object} value
+ * @returns {string[]}
+ */
+function callbackMethods(value) {
+    const methods = new Set();
+    for (let o = value; o && o !== Object.prototype; o = Object.getPrototypeOf(o)) {
+        for (const key of Object.getOwnPropertyNames(o)) {
+            if (key !== 'constructor' && typeof value[key] === 'function') {
+                methods.add(key);
+            }
+        }
+    }
+    return [...methods];
+}
+
+/**
+ * Built-in objects have methods too, but are passed by value.
+ * @param {object} value
+ * @returns {boolean}
+ */
+function isBuiltIn(value) {
+    return (
+        Array.isArray(value) ||
+        ArrayBuffer.isView(value) ||
+        value instanceof ArrayBuffer ||
+        value instanceof Date ||
+        value instanceof Map ||
+        value instanceof Set
+    );
+}
+
+function decodeError(error) {
+    if (error && typeof error === 'object' && 'message' in error && 'name' in error) {
+        const decoded = new Error(error.message);
+        decoded.name = error.name;
+        if (error.stack) {
+            decoded.stack = error.stack;
+        }
+        return decoded;
+    }
+    return error;
+}
+
+/**
+ * Creates the client forwarding calls to `worker`.
+ * @param {Worker} worker
+ */
+export function createWorkerClient(worker) {
+    const pendingCalls = new Map();
+    let nextCallId = 1;
+
+    /** Main thread callbacks the worker may call, by id. */
+    const callbacks = new Map();
+    let nextCallbackId = 1;
+
+    const released = new FinalizationRegistry((objectId) => {
+        worker.postMessage({ type: 'release', objectId });
+    });
+
+    function encodeArg(arg) {
+        if (arg && typeof arg === 'object' && REMOTE_ID in arg) {
+            return { __breezRemote: 'object', id: arg[REMOTE_ID] };
+        }
+        if (typeof arg === 'function') {
+            const id = nextCallbackId++;
+            callbacks.set(id, arg);
+            return { __breezRemote: 'function', id };
+        }
+        if (arg && typeof arg === 'object' && !isBuiltIn(arg)) {
+            const methods = callbackMethods(arg);
+            if (methods.length > 0) {
+                const id = nextCallbackId++;
+                callbacks.set(id, arg);
+                return { __breezRemote: 'callback', id, methods };
+            }
+        }
+        return arg;
+    }
+
+    function decodeResult(value) {
+        if (value && typeof value === 'object' && value.__breezRemote === 'object') {
+            return remoteObject(value.id, value.className);
+        }
+        return value;
+    }
+
+    function remoteObject(objectId, className) {
+        const target = { [REMOTE_ID]: objectId, className };
+        const proxy = new Proxy(target, {
+            get(target, prop) {
+                if (prop in target) {
+                    return target[prop];
+                }
+                // Not a thenable, so that the proxy can be returned from async functions
+                if (prop === 'then' || typeof prop === 'symbol') {
+                    return undefined;
+                }
+                if (prop === 'free') {
+                    return () => {
+                        released.unregister(target);
+                        worker.postMessage({ type: 'release', objectId });
+                    };
+                }
+                return (...args) => send({ type: 'method', objectId, name: prop }, args);
+            },
+        });
+        released.register(proxy, objectId, target);
+        return proxy;
+    }
+
+    function send(message, args = []) {
+        const callId = nextCallId++;
+        return new Promise((resolve, reject) => {
+            pendingCalls.set(callId, { resolve, reject });
+            worker.postMessage({ ...message, callId, args: args.map(encodeArg) });
+        });
+    }
+
+    async function runCallback(message) {
+        const target = callbacks.get(message.targetId);
+        if (!target) {
+            throw new Error(`Breez SDK: unknown callback ${message.targetId}`);
+        }
+        const args = message.args ?? [];
+        return message.method === null
+            ? await target(...args)
+            : await target[message.method](...args);
+    }
+
+    worker.onmessage = async (event) => {
+        const message = event.data;
+        if (message.type === 'result') {
+            const pending = pendingCalls.get(message.callId);
+            pendingCalls.delete(message.callId);
+            if (message.ok) {
+                pending?.resolve(decodeResult(message.value));
+            } else {
+                pending?.reject(decodeError(message.error));
+            }
+        } else if (message.type === 'callback') {
+            try {
+                const value = await runCallback(message);
+                worker.postMessage({ type: 'callbackResult', callId: message.callId, ok: true, value });
+            } catch (error) {
+                worker.postMessage({
+                    type: 'callbackResult',
+                    callId: message.callId,
+                    ok: false,
+                    error: error instanceof Error ? { name: error.name, message: error.message } : error,
+                });
+            }
+        }
+    };
+
+    worker.onerror = (event) => {
+        const error = new Error(`Breez SDK worker error: ${event.message}`);
+        for (const { reject } of pendingCalls.values()) {
+            reject(error);
+        }
+        pendingCalls.clear();
+    };
+
+    return {
+        init: (wasmInput) => send({ type: 'init', wasmInput }),
+        call: (name, args) => send({ type: 'call', name }, args),
+        callStatic: (className, name, args) => send({ type: 'static', className, name }, args),
+        construct: (className, args) => send({ type: 'construct', className }, args),
+        terminate: () => worker.terminate(),
+    };
+}
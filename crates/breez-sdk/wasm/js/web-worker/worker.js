@@ -0,0 +1,159 @@
+/**
+ * Worker side of the Breez SDK web worker mode.
+ *
+ * Loads the WebAssembly module inside a dedicated worker and runs the calls
+ * it receives from the main thread facade (`./index.js`), so that
+ * cryptography and sync do not block the page. SDK objects stay in the
+ * worker and are referenced from the main thread by id.
+ */
+
+import init, * as sdk from '../index.js';
+
+/** SDK objects handed out to the main thread, by id. */
+const objects = new Map();
+let nextObjectId = 1;
+
+/** Callbacks into the main thread awaiting their result, by call id. */
+const pendingCallbacks = new Map();
+let nextCallbackCallId = 1;
+
+let initPromise = null;
+
+/**
+ * Whether `value` is an instance of a class exported by the SDK.
+ * @param {unknown} value
+ * @returns {boolean}
+ */
+function isSdkObject(value) {
+    return (
+        value !== null &&
+        typeof value === 'object' &&
+        '__wbg_ptr' in value &&
+        typeof sdk[value.constructor?.name] === 'function'
+    );
+}
+
+/**
+ * Replaces SDK objects by references the main thread can hold on to.
+ * @param {unknown} value
+ */
+function encodeResult(value) {
+    if (isSdkObject(value)) {
+        const id = nextObjectId++;
+        objects.set(id, value);
+        return { __breezRemote: 'object', id, className: value.constructor.name };
+    }
+    return value;
+}
+
+/**
+ * Resolves object references and turns callback references into proxies
+ * that forward calls to the main thread.
+ * @param {unknown} arg
+ */
+function decodeArg(arg) {
+    if (arg === null || typeof arg !== 'object' || !('__breezRemote' in arg)) {
+        return arg;
+    }
+    switch (arg.__breezRemote) {
+        case 'object': {
+            const object = objects.get(arg.id);
+            if (!object) {
+                throw new Error(`Breez SDK worker: unknown object ${arg.id}`);
+            }
+            return object;
+        }
+        case 'function':
+            return (...args) => callMainThread(arg.id, null, args);
+        case 'callback': {
+            const proxy = {};
+            for (const method of arg.methods) {
+                proxy[method] = (...args) => callMainThread(arg.id, method, args);
+            }
+            return proxy;
+        }
+        default:
+            return arg;
+    }
+}
+
+/**
+ * Errors are not always structured-cloneable, so only their description
+ * crosses the thread boundary.
+ * @param {unknown} error
+ */
+function encodeError(error) {
+    if (error instanceof Error) {
+        return { name: error.name, message: error.message, stack: error.stack };
+    }
+    try {
+        return structuredClone(error);
+    } catch {
+        return { name: 'Error', message: String(error) };
+    }
+}
+
+function callMainThread(targetId, method, args) {
+    const callId = nextCallbackCallId++;
+    return new Promise((resolve, reject) => {
+        pendingCallbacks.set(callId, { resolve, reject });
+        self.postMessage({ type: 'callback', callId, targetId, method, args });
+    });
+}
+
+async function run(message) {
+    const args = (message.args ?? []).map(decodeArg);
+    switch (message.type) {
+        case 'init':
+            initPromise ??= init(message.wasmInput);
+            return await initPromise;
+        case 'call':
+            return encodeResult(await sdk[message.name](...args));
+        case 'static':
+            return encodeResult(await sdk[message.className][message.name](...args));
+        case 'construct':
+            return encodeResult(new sdk[message.className](...args));
+        case 'method': {
+            const object = objects.get(message.objectId);
+            if (!object) {
+                throw new Error(`Breez SDK worker: unknown object ${message.objectId}`);
+            }
+            return encodeResult(await object[message.name](...args));
+        }
+        default:
+            throw new Error(`Breez SDK worker: unknown message type ${message.type}`);
+    }
+}
+
+self.onmessage = async (event) => {
+    const message = event.data;
+
+    if (message.type === 'callbackResult') {
+        const pending = pendingCallbacks.get(message.callId);
+        pendingCallbacks.delete(message.callId);
+        if (message.ok) {
+            pending?.resolve(message.value);
+        } else {
+            pending?.reject(message.error);
+        }
+        return;
+    }
+
+    if (message.type === 'release') {
+        objects.get(message.objectId)?.free();
+        objects.delete(message.objectId);
+        return;
+    }
+
+    try {
+        const value = await run(message);
+        self.postMessage({ type: 'result', callId: message.callId, ok: true, value });
+    } catch (error) {
+        self.postMessage({
+            type: 'result',
+            callId: message.callId,
+            ok: false,
+            error: encodeError(error),
+        });
+    }
+};
@@ -7,9 +7,10 @@ use crate::{
     models::{
         Payment, TokenBalance, TokenMetadata,
         issuer::{
-            BurnIssuerTokenRequest, CreateIssuerTokenRequest, FreezeIssuerTokenRequest,
-            FreezeIssuerTokenResponse, MintIssuerTokenRequest, UnfreezeIssuerTokenRequest,
-            UnfreezeIssuerTokenResponse,
+            BurnIssuerTokenRequest, CreateIssuerTokenRequest, DistributeTokensRequest,
+            DistributeTokensResponse, FreezeIssuerTokenRequest, FreezeIssuerTokenResponse,
+            GetIssuanceStatsRequest, IssuanceStats, MintIssuerTokenRequest,
+            UnfreezeIssuerTokenRequest, UnfreezeIssuerTokenResponse,
         },
     },
 };
@@ -84,4 +85,28 @@ impl TokenIssuer {
             .await?
             .into())
     }
+
+    #[wasm_bindgen(js_name = "getIssuanceStats")]
+    pub async fn get_issuance_stats(
+        &self,
+        request: GetIssuanceStatsRequest,
+    ) -> WasmResult<IssuanceStats> {
+        Ok(self
+            .token_issuer
+            .get_issuance_stats(request.into())
+            .await?
+            .into())
+    }
+
+    #[wasm_bindgen(js_name = "distributeTokens")]
+    pub async fn distribute_tokens(
+        &self,
+        request: DistributeTokensRequest,
+    ) -> WasmResult<DistributeTokensResponse> {
+        Ok(self
+            .token_issuer
+            .distribute_tokens(request.into())
+            .await?
+            .into())
+    }
 }
@@ -3,6 +3,7 @@ mod error;
 mod event;
 mod issuer;
 mod logger;
+mod middleware;
 mod models;
 mod passkey;
 mod persist;
@@ -1,13 +1,7 @@
 use tracing::{Event, Subscriber};
-use tracing_subscriber::{
-    Layer,
-    fmt::{FormatFields, format::Writer},
-    layer::Context,
-};
+use tracing_subscriber::{Layer, layer::Context};
 use wasm_bindgen::prelude::wasm_bindgen;
 
-use crate::models::LogEntry;
-
 thread_local! {
     pub(crate) static WASM_LOGGER: std::cell::RefCell<Option<Logger>> = const { std::cell::RefCell::new(None) };
 }
@@ -20,19 +14,10 @@ where
 {
     fn on_event(&self, event: &Event<'_>, _ctx: Context<'_, S>) {
         WASM_LOGGER.with_borrow(|logger| {
-            if let Some(logger) = logger.as_ref() {
-                let mut buf = String::new();
-                let writer = Writer::new(&mut buf);
-
-                if tracing_subscriber::fmt::format::DefaultFields::new()
-                    .format_fields(writer, event)
-                    .is_ok()
-                {
-                    logger.log(LogEntry {
-                        line: buf,
-                        level: event.metadata().level().to_string(),
-                    });
-                }
+            if let Some(logger) = logger.as_ref()
+                && let Some(entry) = breez_sdk_spark::log_entry_from_event(event)
+            {
+                logger.log(entry.into());
             }
         });
     }
@@ -0,0 +1,51 @@
+use wasm_bindgen::prelude::*;
+use wasm_bindgen_futures::JsFuture;
+use wasm_bindgen_futures::js_sys::Promise;
+
+use crate::models::{MiddlewareDecision, PaymentStage};
+
+pub struct WasmPaymentMiddleware {
+    pub middleware: PaymentMiddleware,
+}
+
+// This assumes that we'll always be running in a single thread (true for Wasm environments)
+unsafe impl Send for WasmPaymentMiddleware {}
+unsafe impl Sync for WasmPaymentMiddleware {}
+
+#[macros::async_trait]
+impl breez_sdk_spark::PaymentMiddleware for WasmPaymentMiddleware {
+    async fn on_stage(
+        &self,
+        stage: breez_sdk_spark::PaymentStage,
+    ) -> breez_sdk_spark::MiddlewareDecision {
+        // A throwing middleware rejects the stage, so a failing compliance
+        // check can't be bypassed
+        let reject = |reason: String| breez_sdk_spark::MiddlewareDecision::Reject { reason };
+        let promise = match self.middleware.on_stage(stage.into()) {
+            Ok(promise) => promise,
+            Err(e) => return reject(format!("JS error: {e:?}")),
+        };
+        let result = match JsFuture::from(promise).await {
+            Ok(result) => result,
+            Err(e) => return reject(format!("JS error: {e:?}")),
+        };
+        match serde_wasm_bindgen::from_value::<MiddlewareDecision>(result) {
+            Ok(decision) => decision.into(),
+            Err(e) => reject(format!("Failed to deserialize middleware decision: {e}")),
+        }
+    }
+}
+
+#[wasm_bindgen(typescript_custom_section)]
+const PAYMENT_MIDDLEWARE_INTERFACE: &'static str = r#"export interface PaymentMiddleware {
+    onStage: (stage: PaymentStage) => Promise<MiddlewareDecision>;
+}"#;
+
+#[wasm_bindgen]
+extern "C" {
+    #[wasm_bindgen(typescript_type = "PaymentMiddleware")]
+    pub type PaymentMiddleware;
+
+    #[wasm_bindgen(structural, method, js_name = onStage, catch)]
+    pub fn on_stage(this: &PaymentMiddleware, stage: PaymentStage) -> Result<Promise, JsValue>;
+}
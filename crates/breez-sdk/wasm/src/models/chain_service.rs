@@ -142,6 +142,19 @@ impl breez_sdk_spark::BitcoinChainService for WasmBitcoinChainService {
         Ok(())
     }
 
+    async fn broadcast_package(
+        &self,
+        txs: Vec<String>,
+    ) -> Result<(), breez_sdk_spark::ChainServiceError> {
+        let promise = self
+            .inner
+            .broadcast_package(txs)
+            .map_err(js_error_to_chain_service_error)?;
+        let future = JsFuture::from(promise);
+        future.await.map_err(js_error_to_chain_service_error)?;
+        Ok(())
+    }
+
     async fn recommended_fees(
         &self,
     ) -> Result<breez_sdk_spark::RecommendedFees, breez_sdk_spark::ChainServiceError> {
@@ -155,6 +168,18 @@ impl breez_sdk_spark::BitcoinChainService for WasmBitcoinChainService {
             .map_err(|e| breez_sdk_spark::ChainServiceError::Generic(e.to_string()))?;
         Ok(recommended_fees.into())
     }
+
+    async fn get_tip_height(&self) -> Result<u32, breez_sdk_spark::ChainServiceError> {
+        let promise = self
+            .inner
+            .get_tip_height()
+            .map_err(js_error_to_chain_service_error)?;
+        let future = JsFuture::from(promise);
+        let result = future.await.map_err(js_error_to_chain_service_error)?;
+        let height: u32 = serde_wasm_bindgen::from_value(result)
+            .map_err(|e| breez_sdk_spark::ChainServiceError::Generic(e.to_string()))?;
+        Ok(height)
+    }
 }
 
 #[wasm_bindgen(typescript_custom_section)]
@@ -165,7 +190,9 @@ const EVENT_INTERFACE: &'static str = r#"export interface BitcoinChainService {
     getTransactionHex(txid: string): Promise<string>;
     getOutspend(txid: string, vout: number): Promise<Outspend>;
     broadcastTransaction(tx: string): Promise<void>;
+    broadcastPackage?(txs: string[]): Promise<void>;
     recommendedFees(): Promise<RecommendedFees>;
+    getTipHeight?(): Promise<number>;
 }"#;
 
 #[wasm_bindgen]
@@ -210,6 +237,15 @@ extern "C" {
         tx: String,
     ) -> Result<Promise, JsValue>;
 
+    #[wasm_bindgen(structural, method, js_name = "broadcastPackage", catch)]
+    pub fn broadcast_package(
+        this: &BitcoinChainService,
+        txs: Vec<String>,
+    ) -> Result<Promise, JsValue>;
+
     #[wasm_bindgen(structural, method, js_name = "recommendedFees", catch)]
     pub fn recommended_fees(this: &BitcoinChainService) -> Result<Promise, JsValue>;
+
+    #[wasm_bindgen(structural, method, js_name = "getTipHeight", catch)]
+    pub fn get_tip_height(this: &BitcoinChainService) -> Result<Promise, JsValue>;
 }
@@ -0,0 +1,30 @@
+use wasm_bindgen::prelude::*;
+
+pub struct WasmClock {
+    pub clock: Clock,
+}
+
+// This assumes that we'll always be running in a single thread (true for Wasm environments)
+unsafe impl Send for WasmClock {}
+unsafe impl Sync for WasmClock {}
+
+impl breez_sdk_spark::Clock for WasmClock {
+    #[allow(clippy::cast_possible_truncation, clippy::cast_sign_loss)]
+    fn now_secs(&self) -> u64 {
+        self.clock.now_secs() as u64
+    }
+}
+
+#[wasm_bindgen(typescript_custom_section)]
+const CLOCK_INTERFACE: &'static str = r#"export interface Clock {
+    nowSecs: () => number;
+}"#;
+
+#[wasm_bindgen]
+extern "C" {
+    #[wasm_bindgen(typescript_type = "Clock")]
+    pub type Clock;
+
+    #[wasm_bindgen(structural, method, js_name = nowSecs)]
+    pub fn now_secs(this: &Clock) -> f64;
+}
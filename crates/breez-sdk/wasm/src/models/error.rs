@@ -19,6 +19,22 @@ pub(crate) fn js_error_to_payment_observer_error(
     breez_sdk_spark::PaymentObserverError::Generic(error_message)
 }
 
+pub(crate) fn js_error_to_plugin_error(js_error: JsValue) -> breez_sdk_spark::PluginError {
+    let error_message = js_error
+        .as_string()
+        .unwrap_or_else(|| "Plugin error occurred".to_string());
+    breez_sdk_spark::PluginError::Generic(error_message)
+}
+
+pub(crate) fn js_error_to_input_parser_error(
+    js_error: JsValue,
+) -> breez_sdk_spark::InputParserError {
+    let error_message = js_error
+        .as_string()
+        .unwrap_or_else(|| "Input parser error occurred".to_string());
+    breez_sdk_spark::InputParserError::Generic(error_message)
+}
+
 pub(crate) fn js_error_to_session_store_error(
     js_error: JsValue,
 ) -> breez_sdk_spark::SessionStoreError {
@@ -2,7 +2,10 @@ use breez_sdk_spark::ServiceConnectivityError;
 use wasm_bindgen::prelude::*;
 use wasm_bindgen_futures::{JsFuture, js_sys::Promise};
 
-use crate::models::{FiatCurrency, Rate, error::js_error_to_service_connectivity_error};
+use crate::models::{
+    FiatCurrency, HistoricalRate, Rate, RateResolution,
+    error::js_error_to_service_connectivity_error,
+};
 
 pub struct WasmFiatService {
     pub inner: FiatService,
@@ -45,12 +48,34 @@ impl breez_sdk_spark::FiatService for WasmFiatService {
             .map_err(|e| ServiceConnectivityError::Other(e.to_string()))?;
         Ok(rates.into_iter().map(|p| p.into()).collect())
     }
+
+    async fn fetch_historical_rates(
+        &self,
+        currency: String,
+        from_timestamp: u64,
+        to_timestamp: u64,
+        resolution: breez_sdk_spark::RateResolution,
+    ) -> Result<Vec<breez_sdk_spark::HistoricalRate>, ServiceConnectivityError> {
+        let resolution: RateResolution = resolution.into();
+        let promise = self
+            .inner
+            .fetch_historical_rates(currency, from_timestamp, to_timestamp, resolution)
+            .map_err(js_error_to_service_connectivity_error)?;
+        let future = JsFuture::from(promise);
+        let result = future
+            .await
+            .map_err(js_error_to_service_connectivity_error)?;
+        let rates: Vec<HistoricalRate> = serde_wasm_bindgen::from_value(result)
+            .map_err(|e| ServiceConnectivityError::Other(e.to_string()))?;
+        Ok(rates.into_iter().map(|p| p.into()).collect())
+    }
 }
 
 #[wasm_bindgen(typescript_custom_section)]
 const EVENT_INTERFACE: &'static str = r#"export interface FiatService {
     fetchFiatCurrencies(): Promise<FiatCurrency[]>;
     fetchFiatRates(): Promise<Rate[]>;
+    fetchHistoricalRates?(currency: string, fromTimestamp: number, toTimestamp: number, resolution: RateResolution): Promise<HistoricalRate[]>;
 }"#;
 
 #[wasm_bindgen]
@@ -63,4 +88,13 @@ extern "C" {
 
     #[wasm_bindgen(structural, method, js_name = "fetchFiatRates", catch)]
     pub fn fetch_fiat_rates(this: &FiatService) -> Result<Promise, JsValue>;
+
+    #[wasm_bindgen(structural, method, js_name = "fetchHistoricalRates", catch)]
+    pub fn fetch_historical_rates(
+        this: &FiatService,
+        currency: String,
+        from_timestamp: u64,
+        to_timestamp: u64,
+        resolution: RateResolution,
+    ) -> Result<Promise, JsValue>;
 }
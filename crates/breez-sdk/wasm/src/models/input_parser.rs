@@ -0,0 +1,72 @@
+use wasm_bindgen::prelude::*;
+use wasm_bindgen_futures::{JsFuture, js_sys::Promise};
+
+use crate::models::{InputParserInfo, InputType, error::js_error_to_input_parser_error};
+
+pub struct WasmInputParser {
+    pub parser: InputParser,
+    /// The info the parser reported when it was registered
+    pub info: breez_sdk_spark::InputParserInfo,
+}
+
+// This assumes that we'll always be running in a single thread (true for Wasm environments)
+unsafe impl Send for WasmInputParser {}
+unsafe impl Sync for WasmInputParser {}
+
+impl WasmInputParser {
+    pub fn new(parser: InputParser) -> Result<Self, JsValue> {
+        let info =
+            serde_wasm_bindgen::from_value::<InputParserInfo>(parser.info()?).map_err(|e| {
+                JsValue::from_str(&format!("Failed to deserialize input parser info: {e}"))
+            })?;
+        Ok(Self {
+            parser,
+            info: info.into(),
+        })
+    }
+}
+
+#[macros::async_trait]
+impl breez_sdk_spark::InputParser for WasmInputParser {
+    fn info(&self) -> breez_sdk_spark::InputParserInfo {
+        self.info.clone()
+    }
+
+    async fn parse(
+        &self,
+        input: String,
+    ) -> Result<Option<breez_sdk_spark::InputType>, breez_sdk_spark::InputParserError> {
+        let promise: Promise = self
+            .parser
+            .parse(input)
+            .map_err(js_error_to_input_parser_error)?;
+        let result = JsFuture::from(promise)
+            .await
+            .map_err(js_error_to_input_parser_error)?;
+        let input_type =
+            serde_wasm_bindgen::from_value::<Option<InputType>>(result).map_err(|e| {
+                breez_sdk_spark::InputParserError::Generic(format!(
+                    "Failed to deserialize input type: {e}"
+                ))
+            })?;
+        Ok(input_type.map(Into::into))
+    }
+}
+
+#[wasm_bindgen(typescript_custom_section)]
+const INPUT_PARSER_INTERFACE: &'static str = r#"export interface InputParser {
+    info: () => InputParserInfo;
+    parse: (input: string) => Promise<InputType | undefined>;
+}"#;
+
+#[wasm_bindgen]
+extern "C" {
+    #[wasm_bindgen(typescript_type = "InputParser")]
+    pub type InputParser;
+
+    #[wasm_bindgen(structural, method, js_name = info, catch)]
+    pub fn info(this: &InputParser) -> Result<JsValue, JsValue>;
+
+    #[wasm_bindgen(structural, method, js_name = parse, catch)]
+    pub fn parse(this: &InputParser, input: String) -> Result<Promise, JsValue>;
+}
@@ -1,3 +1,18 @@
+#[macros::extern_wasm_bindgen(breez_sdk_spark::IssuerApprovalConfig)]
+pub struct IssuerApprovalConfig {
+    pub public_key: String,
+    pub derivation_path: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::IssuerApprovalRequest)]
+pub struct IssuerApprovalRequest {
+    pub issuer_public_key: String,
+    pub token_identifier: String,
+    pub operation: String,
+    pub message: String,
+    pub derivation_path: String,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::CreateIssuerTokenRequest)]
 pub struct CreateIssuerTokenRequest {
     pub name: String,
@@ -10,11 +25,13 @@ pub struct CreateIssuerTokenRequest {
 #[macros::extern_wasm_bindgen(breez_sdk_spark::MintIssuerTokenRequest)]
 pub struct MintIssuerTokenRequest {
     pub amount: u128,
+    pub idempotency_key: Option<String>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::BurnIssuerTokenRequest)]
 pub struct BurnIssuerTokenRequest {
     pub amount: u128,
+    pub idempotency_key: Option<String>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::FreezeIssuerTokenRequest)]
@@ -38,3 +55,65 @@ pub struct UnfreezeIssuerTokenResponse {
     pub impacted_output_ids: Vec<String>,
     pub impacted_token_amount: u128,
 }
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TokenRecipient)]
+pub struct TokenRecipient {
+    pub address: String,
+    pub amount: u128,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DistributeTokensRequest)]
+pub struct DistributeTokensRequest {
+    pub recipients: Vec<TokenRecipient>,
+    pub distribution_id: Option<String>,
+    pub mint_missing_supply: Option<bool>,
+    pub chunk_size: Option<u32>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DistributeTokensResponse)]
+pub struct DistributeTokensResponse {
+    pub distribution_id: String,
+    pub mint_payment_id: Option<String>,
+    pub results: Vec<TokenRecipientResult>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TokenRecipientResult)]
+pub struct TokenRecipientResult {
+    pub address: String,
+    pub amount: u128,
+    pub status: TokenRecipientStatus,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TokenRecipientStatus)]
+pub enum TokenRecipientStatus {
+    Pending,
+    Sent { payment_id: String },
+    Failed { error: String },
+    Unknown,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetIssuanceStatsRequest)]
+pub struct GetIssuanceStatsRequest {
+    pub period_secs: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::IssuanceStats)]
+pub struct IssuanceStats {
+    pub token_identifier: String,
+    pub max_supply: u128,
+    pub total_minted: u128,
+    pub total_burned: u128,
+    pub total_supply: u128,
+    pub circulating_supply: u128,
+    pub holder_count: u32,
+    pub frozen_address_count: u32,
+    pub history: Vec<IssuancePeriod>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::IssuancePeriod)]
+pub struct IssuancePeriod {
+    pub start: u64,
+    pub minted: u128,
+    pub burned: u128,
+    pub total_supply: u128,
+}
@@ -0,0 +1,49 @@
+use breez_sdk_spark::{SignerError, signer::external_types as core_types};
+use wasm_bindgen::prelude::*;
+use wasm_bindgen_futures::{JsFuture, js_sys::Promise};
+
+use crate::{models::issuer::IssuerApprovalRequest, signer::EcdsaSignatureBytes};
+
+pub struct WasmIssuerApprovalSigner {
+    pub approval_signer: IssuerApprovalSigner,
+}
+
+// This assumes that we'll always be running in a single thread (true for Wasm environments)
+unsafe impl Send for WasmIssuerApprovalSigner {}
+unsafe impl Sync for WasmIssuerApprovalSigner {}
+
+#[macros::async_trait]
+impl breez_sdk_spark::IssuerApprovalSigner for WasmIssuerApprovalSigner {
+    async fn sign_approval(
+        &self,
+        request: breez_sdk_spark::IssuerApprovalRequest,
+    ) -> Result<core_types::EcdsaSignatureBytes, SignerError> {
+        let promise: Promise = self
+            .approval_signer
+            .sign_approval(request.into())
+            .map_err(|e| SignerError::Generic(format!("JS error: {e:?}")))?;
+        let result = JsFuture::from(promise)
+            .await
+            .map_err(|e| SignerError::Generic(format!("JS error: {e:?}")))?;
+        let signature: EcdsaSignatureBytes = serde_wasm_bindgen::from_value(result)
+            .map_err(|e| SignerError::Generic(format!("Failed to deserialize signature: {e}")))?;
+        Ok(signature.into())
+    }
+}
+
+#[wasm_bindgen(typescript_custom_section)]
+const ISSUER_APPROVAL_SIGNER_INTERFACE: &'static str = r#"export interface IssuerApprovalSigner {
+    signApproval: (request: IssuerApprovalRequest) => Promise<EcdsaSignatureBytes>;
+}"#;
+
+#[wasm_bindgen]
+extern "C" {
+    #[wasm_bindgen(typescript_type = "IssuerApprovalSigner")]
+    pub type IssuerApprovalSigner;
+
+    #[wasm_bindgen(structural, method, js_name = signApproval, catch)]
+    pub fn sign_approval(
+        this: &IssuerApprovalSigner,
+        request: IssuerApprovalRequest,
+    ) -> Result<Promise, JsValue>;
+}
@@ -1,11 +1,16 @@
 pub mod chain_service;
+pub mod clock;
 mod error;
 pub mod fiat_service;
+pub mod input_parser;
 pub mod issuer;
+pub mod issuer_approval_signer;
 pub mod passkey_prf_provider;
 pub mod payment_observer;
+pub mod plugin;
 pub mod rest_client;
 pub mod session_store;
+pub mod token_price_service;
 
 use std::collections::HashMap;
 
@@ -86,6 +91,72 @@ pub enum SdkEvent {
     NewDeposits {
         new_deposits: Vec<DepositInfo>,
     },
+    PartialInvoicePaymentProgress {
+        invoice: String,
+        payment_id: String,
+        #[tsify(type = "string")]
+        #[serde(with = "serde_u128_as_string")]
+        received_amount: u128,
+        #[tsify(type = "string")]
+        #[serde(with = "serde_u128_as_string")]
+        target_amount: u128,
+        token_identifier: Option<String>,
+        completed: bool,
+    },
+    PaymentProgress {
+        handle: PaymentHandle,
+        stage: PaymentProgressStage,
+    },
+    HtlcRefunded {
+        payment: Payment,
+    },
+    ArbitratedEscrowUpdated {
+        escrow: ArbitratedEscrow,
+    },
+    RefundFeeBumped {
+        refund: DepositRefund,
+        replaced_tx_id: String,
+    },
+    RefundConfirmed {
+        refund: DepositRefund,
+    },
+    PaymentStreamUpdated {
+        stream: PaymentStream,
+    },
+    ClaimQueueChanged {
+        queue_depth: u32,
+    },
+    UnilateralExitProgress {
+        leaf: UnilateralExitLeafProgress,
+    },
+    OnchainTransactionUpdated {
+        transaction: OnchainTransaction,
+    },
+    TokenSweep {
+        sweep_event: TokenSweepEvent,
+    },
+    TokenDistribution {
+        distribution_event: TokenDistributionEvent,
+    },
+    JobUpdated {
+        job: Job,
+    },
+    TokenMetadataChanged {
+        metadata: TokenMetadata,
+    },
+    ServiceStatusChanged {
+        status: ServiceStatusReport,
+    },
+    SyncProgress {
+        progress: SyncProgress,
+    },
+    SyncConflictResolved {
+        conflict: SyncConflict,
+    },
+    NostrZapReceiptIssued {
+        payment_hash: String,
+        zap_receipt: String,
+    },
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::AutoOptimizationEvent)]
@@ -105,6 +176,71 @@ pub enum AutoOptimizationEvent {
     Skipped,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TokenSweepEvent)]
+pub enum TokenSweepEvent {
+    Swept {
+        sweep_id: String,
+        token_identifier: String,
+        amount: u128,
+        sent_payment_id: String,
+        received_payment_id: String,
+    },
+    Failed {
+        sweep_id: String,
+        token_identifier: String,
+        error: String,
+    },
+    Completed {
+        sweep_id: String,
+        swept_count: u32,
+    },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TokenDistributionEvent)]
+pub enum TokenDistributionEvent {
+    Progress {
+        distribution_id: String,
+        processed_count: u32,
+        total_count: u32,
+        results: Vec<issuer::TokenRecipientResult>,
+    },
+    Completed {
+        distribution_id: String,
+        sent_count: u32,
+        failed_count: u32,
+        unknown_count: u32,
+    },
+}
+
+#[allow(clippy::large_enum_variant)]
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentStage)]
+pub enum PaymentStage {
+    BeforePrepareSend {
+        request: PrepareSendPaymentRequest,
+    },
+    BeforePrepareLnurlPay {
+        request: PrepareLnurlPayRequest,
+    },
+    BeforeReceive {
+        request: ReceivePaymentRequest,
+    },
+    BeforeSend {
+        prepare_response: PrepareSendPaymentResponse,
+    },
+    BeforeLnurlPay {
+        prepare_response: PrepareLnurlPayResponse,
+    },
+    AfterSettle {
+        payment: Payment,
+    },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::MiddlewareDecision)]
+pub enum MiddlewareDecision {
+    Continue,
+    Reject { reason: String },
+}
+
 #[derive(Clone)]
 #[macros::extern_wasm_bindgen(breez_sdk_spark::Seed)]
 pub enum Seed {
@@ -142,6 +278,7 @@ pub struct ClaimDepositRequest {
     pub txid: String,
     pub vout: u32,
     pub max_fee: Option<MaxFee>,
+    pub idempotency_key: Option<String>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::ClaimDepositResponse)]
@@ -149,6 +286,45 @@ pub struct ClaimDepositResponse {
     pub payment: Payment,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DepositOutpoint)]
+pub struct DepositOutpoint {
+    pub txid: String,
+    pub vout: u32,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ClaimDepositsRequest)]
+pub struct ClaimDepositsRequest {
+    pub deposits: Vec<DepositOutpoint>,
+    pub max_total_fee_sat: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ClaimDepositResult)]
+pub struct ClaimDepositResult {
+    pub txid: String,
+    pub vout: u32,
+    pub payment: Option<Payment>,
+    pub error: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ClaimDepositsResponse)]
+pub struct ClaimDepositsResponse {
+    pub results: Vec<ClaimDepositResult>,
+    pub total_fee_sat: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DepositAddressInfo)]
+pub struct DepositAddressInfo {
+    pub address: String,
+    pub issued_at: Option<u64>,
+    pub deposit_count: u32,
+    pub total_received_sats: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetDepositAddressHistoryResponse)]
+pub struct GetDepositAddressHistoryResponse {
+    pub addresses: Vec<DepositAddressInfo>,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::RefundDepositRequest)]
 pub struct RefundDepositRequest {
     pub txid: String,
@@ -163,6 +339,27 @@ pub struct RefundDepositResponse {
     pub tx_hex: String,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::BumpRefundFeeRequest)]
+pub struct BumpRefundFeeRequest {
+    pub tx_id: String,
+    pub fee: Fee,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::BumpRefundFeeResponse)]
+pub struct BumpRefundFeeResponse {
+    pub tx_id: String,
+    pub tx_hex: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DepositRefund)]
+pub struct DepositRefund {
+    pub deposit_txid: String,
+    pub deposit_vout: u32,
+    pub tx_id: String,
+    pub destination_address: String,
+    pub fee_sats: u64,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::ListUnclaimedDepositsRequest)]
 pub struct ListUnclaimedDepositsRequest {}
 
@@ -370,6 +567,8 @@ pub struct LnurlPayRequestDetails {
 pub struct SilentPaymentAddressDetails {
     pub address: String,
     pub network: BitcoinNetwork,
+    pub scan_public_key: String,
+    pub spend_public_key: String,
     pub source: PaymentRequestSource,
 }
 
@@ -447,6 +646,16 @@ pub struct Payment {
     pub method: PaymentMethod,
     pub details: Option<PaymentDetails>,
     pub conversion_details: Option<ConversionDetails>,
+    pub fiat_value: Option<PaymentFiatValue>,
+    pub receive_metadata: Option<ReceiveMetadata>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentFiatValue)]
+pub struct PaymentFiatValue {
+    pub currency: String,
+    pub rate: f64,
+    pub amount: f64,
+    pub recorded_at: u64,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::ConversionDetails)]
@@ -572,6 +781,7 @@ pub enum PaymentMethod {
     Token,
     Deposit,
     Withdraw,
+    Plugin,
     Unknown,
 }
 
@@ -675,6 +885,73 @@ pub struct Config {
     pub spark_config: Option<SparkConfig>,
     pub background_tasks_enabled: bool,
     pub cross_chain_config: Option<CrossChainConfig>,
+    pub operator_allowlist: Option<OperatorAllowlistConfig>,
+    pub conversion_max_price_deviation_bps: Option<u32>,
+    pub dust_config: Option<DustConfig>,
+    pub token_visibility: Option<TokenVisibilityConfig>,
+    pub auto_refund_htlc_payments: bool,
+    pub max_claims_per_second: Option<u32>,
+    pub rotate_deposit_address: bool,
+    pub faucet_config: Option<FaucetConfig>,
+    pub enabled_rails: Vec<PaymentRail>,
+    pub payment_fiat_currency: Option<String>,
+    pub spending_caps: Vec<SpendingCap>,
+    pub token_sweep_policy: Option<TokenSweepPolicy>,
+    pub service_endpoints: Option<ServiceEndpoints>,
+    pub parse_cache_ttl_secs: u32,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TokenSweepPolicy)]
+pub struct TokenSweepPolicy {
+    pub max_balance_sat: u64,
+    pub recognized_token_identifiers: Vec<String>,
+    pub sweep_unrecognized_tokens: bool,
+    pub interval_secs: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ServiceEndpoints)]
+pub struct ServiceEndpoints {
+    pub lnurl_server_url: Option<String>,
+    pub fiat_rates_url: Option<String>,
+    pub buy_bitcoin_url: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::FaucetConfig)]
+pub struct FaucetConfig {
+    pub url: String,
+    pub credentials: Option<Credentials>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DustConfig)]
+pub struct DustConfig {
+    pub sats: AssetDustLimit,
+    pub tokens: HashMap<String, AssetDustLimit>,
+    pub suppress_dust_balances: bool,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TokenVisibilityConfig)]
+pub struct TokenVisibilityConfig {
+    pub allowlist: Option<Vec<String>>,
+    pub denylist: Vec<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::AssetDustLimit)]
+pub struct AssetDustLimit {
+    pub min_displayable: u128,
+    pub min_payable: u128,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::OperatorAllowlistConfig)]
+pub struct OperatorAllowlistConfig {
+    pub operators: Vec<AllowedOperator>,
+    pub ssp_identity_public_key: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::AllowedOperator)]
+pub struct AllowedOperator {
+    pub identity_public_key: String,
+    pub address: Option<String>,
+    pub ca_cert_pem: Option<String>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::CrossChainConfig)]
@@ -692,6 +969,8 @@ pub struct SparkConfig {
     pub expected_withdraw_bond_sats: u64,
     pub expected_withdraw_relative_block_locktime: u64,
     pub max_token_transaction_inputs: Option<u32>,
+    pub deposit_confirmations: Option<u32>,
+    pub expected_transfer_claim_secs: Option<u64>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::SparkSigningOperator)]
@@ -714,6 +993,21 @@ pub struct SparkSspConfig {
 pub struct LeafOptimizationConfig {
     pub auto_enabled: bool,
     pub multiplicity: u8,
+    pub auto_policy: Option<LeafOptimizationPolicy>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LeafOptimizationPolicy)]
+pub struct LeafOptimizationPolicy {
+    pub min_leaf_count: Option<u32>,
+    pub min_idle_secs: Option<u64>,
+    pub unmetered_only: bool,
+    pub quiet_hours: Option<QuietHours>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::QuietHours)]
+pub struct QuietHours {
+    pub start_hour: u8,
+    pub end_hour: u8,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::TokenOptimizationConfig)]
@@ -737,6 +1031,12 @@ pub struct StableBalanceConfig {
     pub max_slippage_bps: Option<u32>,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SendMaxFee)]
+pub enum SendMaxFee {
+    Fixed { amount: u64 },
+    Proportional { ppm: u32 },
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::MaxFee)]
 pub enum MaxFee {
     Fixed { amount: u64 },
@@ -869,6 +1169,35 @@ pub struct UnilateralExitResponse {
     pub transactions: Vec<UnilateralExitTransaction>,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::UnilateralExitLeafStage)]
+pub enum UnilateralExitLeafStage {
+    Pending,
+    LeafConfirmed,
+    RefundConfirmed,
+    Swept,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::UnilateralExitLeafProgress)]
+pub struct UnilateralExitLeafProgress {
+    pub leaf_id: String,
+    pub value: u64,
+    pub stage: UnilateralExitLeafStage,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::UnilateralExitProgress)]
+pub struct UnilateralExitProgress {
+    pub destination: String,
+    pub fee_rate_sat_per_vbyte: u64,
+    pub leaves: Vec<UnilateralExitLeafProgress>,
+    pub transactions: Vec<UnilateralExitTransaction>,
+    pub built_at: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetUnilateralExitProgressResponse)]
+pub struct GetUnilateralExitProgressResponse {
+    pub progress: Option<UnilateralExitProgress>,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::Credentials)]
 pub struct Credentials {
     pub username: String,
@@ -878,6 +1207,7 @@ pub struct Credentials {
 #[macros::extern_wasm_bindgen(breez_sdk_spark::GetInfoRequest)]
 pub struct GetInfoRequest {
     pub ensure_synced: Option<bool>,
+    pub include_hidden: Option<bool>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::GetInfoResponse)]
@@ -885,6 +1215,11 @@ pub struct GetInfoResponse {
     pub identity_pubkey: String,
     pub balance_sats: u64,
     pub token_balances: HashMap<String, TokenBalance>,
+    pub hidden_tokens: Vec<String>,
+    pub leaf_stats: LeafStats,
+    pub claim_queue_depth: u32,
+    pub wallet_frozen: bool,
+    pub plugin_balances: Vec<PluginBalance>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::TokenBalance)]
@@ -893,6 +1228,24 @@ pub struct TokenBalance {
     pub token_metadata: TokenMetadata,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::HideTokenRequest)]
+pub struct HideTokenRequest {
+    pub token_identifier: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::UnhideTokenRequest)]
+pub struct UnhideTokenRequest {
+    pub token_identifier: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::AmountRounding)]
+pub enum AmountRounding {
+    Down,
+    Up,
+    HalfUp,
+    HalfEven,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::TokenMetadata)]
 pub struct TokenMetadata {
     pub identifier: String,
@@ -910,11 +1263,83 @@ pub struct TokenMetadata {
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::SyncWalletRequest)]
-pub struct SyncWalletRequest {}
+pub struct SyncWalletRequest {
+    pub domains: Option<Vec<SyncDomain>>,
+}
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::SyncWalletResponse)]
 pub struct SyncWalletResponse {}
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SyncDomain)]
+pub enum SyncDomain {
+    Payments,
+    Tokens,
+    Deposits,
+    LightningAddress,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SyncStage)]
+pub enum SyncStage {
+    Wallet,
+    Payments,
+    Tokens,
+    Deposits,
+    LightningAddress,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SyncProgress)]
+pub struct SyncProgress {
+    pub stage: SyncStage,
+    pub succeeded: bool,
+    pub percent: u8,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DomainSyncStatus)]
+pub struct DomainSyncStatus {
+    pub domain: SyncDomain,
+    pub last_synced_at: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetSyncStatusResponse)]
+pub struct GetSyncStatusResponse {
+    pub domains: Vec<DomainSyncStatus>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SyncRecordType)]
+pub enum SyncRecordType {
+    PaymentMetadata,
+    Contact,
+    LightningAddress,
+    CrossChainSwap,
+    Device,
+    UserSettings,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SyncConflict)]
+pub struct SyncConflict {
+    pub record_type: SyncRecordType,
+    pub record_id: String,
+    pub kept_local_fields: Vec<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DeviceInfo)]
+pub struct DeviceInfo {
+    pub id: String,
+    pub name: Option<String>,
+    pub last_seen_at: u64,
+    pub is_current: bool,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListDevicesResponse)]
+pub struct ListDevicesResponse {
+    pub devices: Vec<DeviceInfo>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SetDeviceNameRequest)]
+pub struct SetDeviceNameRequest {
+    pub name: String,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::ReceivePaymentMethod)]
 pub enum ReceivePaymentMethod {
     SparkAddress,
@@ -926,6 +1351,7 @@ pub enum ReceivePaymentMethod {
         expiry_time: Option<u64>,
         description: Option<String>,
         sender_public_key: Option<String>,
+        accept_partial_payments: Option<bool>,
     },
     BitcoinAddress {
         new_address: Option<bool>,
@@ -1082,6 +1508,11 @@ pub enum SendPaymentMethod {
         expires_at: String,
         provider_context: CrossChainProviderContext,
     },
+    Plugin {
+        plugin_name: String,
+        destination: String,
+        quote: PluginSendQuote,
+    },
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::ReceivePaymentRequest)]
@@ -1114,6 +1545,15 @@ pub struct PrepareLnurlPayRequest {
     pub token_identifier: Option<String>,
     pub conversion_options: Option<ConversionOptions>,
     pub fee_policy: Option<FeePolicy>,
+    pub quote_token_identifier: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LnurlPayTokenQuote)]
+pub struct LnurlPayTokenQuote {
+    pub token_identifier: String,
+    pub token_amount: u128,
+    pub amount_sats: u64,
+    pub sats_per_token: f64,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::PrepareLnurlPayResponse)]
@@ -1126,12 +1566,15 @@ pub struct PrepareLnurlPayResponse {
     pub success_action: Option<SuccessAction>,
     pub conversion_estimate: Option<ConversionEstimate>,
     pub fee_policy: FeePolicy,
+    pub token_quote: Option<LnurlPayTokenQuote>,
+    pub duplicate_payment: Option<DuplicatePayment>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::LnurlPayRequest)]
 pub struct LnurlPayRequest {
     pub prepare_response: PrepareLnurlPayResponse,
     pub idempotency_key: Option<String>,
+    pub max_fee: Option<SendMaxFee>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::LnurlPayResponse)]
@@ -1162,6 +1605,7 @@ pub struct LnurlWithdrawRequest {
     pub amount_sats: u64,
     pub withdraw_request: LnurlWithdrawRequestDetails,
     pub completion_timeout_secs: Option<u32>,
+    pub idempotency_key: Option<String>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::LnurlWithdrawResponse)]
@@ -1264,6 +1708,14 @@ pub struct PrepareSendPaymentRequest {
     pub token_identifier: Option<String>,
     pub conversion_options: Option<ConversionOptions>,
     pub fee_policy: Option<FeePolicy>,
+    pub fiat_amount: Option<LockedFiatAmount>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DuplicatePayment)]
+pub struct DuplicatePayment {
+    pub payment_id: String,
+    pub status: PaymentStatus,
+    pub timestamp: u64,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::PrepareSendPaymentResponse)]
@@ -1273,6 +1725,7 @@ pub struct PrepareSendPaymentResponse {
     pub token_identifier: Option<String>,
     pub conversion_estimate: Option<ConversionEstimate>,
     pub fee_policy: FeePolicy,
+    pub duplicate_payment: Option<DuplicatePayment>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::OnchainConfirmationSpeed)]
@@ -1294,6 +1747,14 @@ pub enum SendPaymentOptions {
     SparkAddress {
         htlc_options: Option<SparkHtlcOptions>,
     },
+    SparkInvoice {
+        #[tsify(type = "string")]
+        #[serde(with = "serde_option_u128_as_string")]
+        min_amount: Option<u128>,
+        #[tsify(type = "string")]
+        #[serde(with = "serde_option_u128_as_string")]
+        max_amount: Option<u128>,
+    },
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::SparkHtlcOptions)]
@@ -1307,6 +1768,67 @@ pub struct SendPaymentRequest {
     pub prepare_response: PrepareSendPaymentResponse,
     pub options: Option<SendPaymentOptions>,
     pub idempotency_key: Option<String>,
+    pub max_fee: Option<SendMaxFee>,
+    pub deliver_after: Option<u64>,
+    pub leaf_selection: Option<SendLeafSelection>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SendLeafSelection)]
+pub enum SendLeafSelection {
+    Specific { leaf_ids: Vec<String> },
+    Strategy { strategy: LeafSelectionStrategy },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LeafSelectionStrategy)]
+pub enum LeafSelectionStrategy {
+    MinimizeFee,
+    MinimizeInputs,
+    Privacy,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SimulateSendPaymentRequest)]
+pub struct SimulateSendPaymentRequest {
+    pub prepare_response: PrepareSendPaymentResponse,
+    pub options: Option<SendPaymentOptions>,
+    pub max_fee: Option<SendMaxFee>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SimulateSendPaymentResponse)]
+pub struct SimulateSendPaymentResponse {
+    pub will_succeed: bool,
+    pub amount: u128,
+    pub fee: u128,
+    pub token_identifier: Option<String>,
+    pub leaves: Vec<SimulatedLeaf>,
+    pub requires_swap: bool,
+    pub estimated_completion_secs: Option<u64>,
+    pub failure_reasons: Vec<SendFailureReason>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SimulatedLeaf)]
+pub struct SimulatedLeaf {
+    pub id: String,
+    pub value: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SendFailureReason)]
+pub enum SendFailureReason {
+    InsufficientFunds {
+        required: u128,
+        available: u128,
+    },
+    MaxFeeExceeded {
+        fee_sats: u64,
+        max_fee_sats: u64,
+    },
+    BelowDustLimit {
+        amount_sats: u64,
+        dust_limit_sats: u64,
+    },
+    QuoteExpired,
+    InvalidOptions {
+        reason: String,
+    },
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::PublishSignedTransferPackageRequest)]
@@ -1326,6 +1848,36 @@ pub struct SendPaymentResponse {
     pub payment: Payment,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentHandle)]
+pub struct PaymentHandle {
+    pub id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentProgressStage)]
+pub enum PaymentProgressStage {
+    Queued,
+    TransferInitiated,
+    PreimageReceived { preimage: String },
+    Settled { payment: Payment },
+    Failed { error: String },
+    Cancelled,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CancelPendingPaymentRequest)]
+pub struct CancelPendingPaymentRequest {
+    pub handle: PaymentHandle,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CancelPaymentRequest)]
+pub struct CancelPaymentRequest {
+    pub payment_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CancelPaymentResponse)]
+pub struct CancelPaymentResponse {
+    pub payment: Payment,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentDetailsFilter)]
 pub enum PaymentDetailsFilter {
     Spark {
@@ -1413,50 +1965,203 @@ pub struct GetPaymentResponse {
     pub payment: Payment,
 }
 
-#[macros::extern_wasm_bindgen(breez_sdk_spark::LogEntry)]
-pub struct LogEntry {
-    pub line: String,
-    pub level: String,
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetLedgerRequest)]
+pub struct GetLedgerRequest {
+    pub token_identifier: Option<String>,
+    pub from_timestamp: Option<u64>,
+    pub to_timestamp: Option<u64>,
 }
 
-#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentMetadata)]
-pub struct PaymentMetadata {
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LedgerEntry)]
+pub struct LedgerEntry {
+    pub payment_id: String,
     pub parent_payment_id: Option<String>,
-    pub lnurl_pay_info: Option<LnurlPayInfo>,
-    pub lnurl_withdraw_info: Option<LnurlWithdrawInfo>,
-    pub lnurl_description: Option<String>,
-    pub conversion_info: Option<ConversionInfo>,
-    pub conversion_status: Option<ConversionStatus>,
+    pub timestamp: u64,
+    pub payment_type: PaymentType,
+    pub method: PaymentMethod,
+    pub credit: u128,
+    pub debit: u128,
+    pub fees: u128,
+    pub balance: u128,
 }
 
-#[macros::extern_wasm_bindgen(breez_sdk_spark::SetLnurlMetadataItem)]
-pub struct SetLnurlMetadataItem {
-    pub payment_hash: String,
-    pub sender_comment: Option<String>,
-    pub nostr_zap_request: Option<String>,
-    pub nostr_zap_receipt: Option<String>,
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetLedgerResponse)]
+pub struct GetLedgerResponse {
+    pub token_identifier: Option<String>,
+    pub opening_balance: u128,
+    pub closing_balance: u128,
+    pub entries: Vec<LedgerEntry>,
 }
 
-#[macros::extern_wasm_bindgen(breez_sdk_spark::UpdateDepositPayload)]
-pub enum UpdateDepositPayload {
-    ClaimError {
-        error: DepositClaimError,
-    },
-    Refund {
-        refund_txid: String,
-        refund_tx: String,
-    },
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LedgerExportFormat)]
+pub enum LedgerExportFormat {
+    Csv,
+    Json,
 }
 
-#[macros::extern_wasm_bindgen(breez_sdk_spark::CheckLightningAddressRequest)]
-pub struct CheckLightningAddressRequest {
-    pub username: String,
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ExportLedgerRequest)]
+pub struct ExportLedgerRequest {
+    pub format: LedgerExportFormat,
+    pub token_identifier: Option<String>,
+    pub from_timestamp: Option<u64>,
+    pub to_timestamp: Option<u64>,
 }
 
-#[macros::extern_wasm_bindgen(breez_sdk_spark::RegisterLightningAddressRequest)]
-pub struct RegisterLightningAddressRequest {
-    pub username: String,
-    pub description: Option<String>,
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ExportLedgerResponse)]
+pub struct ExportLedgerResponse {
+    pub format: LedgerExportFormat,
+    pub data: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetTokenActivityRequest)]
+pub struct GetTokenActivityRequest {
+    pub token_identifier: String,
+    pub offset: Option<u32>,
+    pub limit: Option<u32>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TokenActivity)]
+pub enum TokenActivity {
+    Payment {
+        payment: Payment,
+    },
+    Freeze {
+        address: String,
+        frozen: bool,
+        impacted_token_amount: u128,
+        timestamp: u64,
+    },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetTokenActivityResponse)]
+pub struct GetTokenActivityResponse {
+    pub activity: Vec<TokenActivity>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentExportFormat)]
+pub enum PaymentExportFormat {
+    Csv,
+    Json,
+    Camt053,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ExportPaymentsRequest)]
+pub struct ExportPaymentsRequest {
+    pub format: PaymentExportFormat,
+    pub filter: Option<ListPaymentsRequest>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentExportRecord)]
+pub struct PaymentExportRecord {
+    pub payment_id: String,
+    pub timestamp: u64,
+    pub payment_type: PaymentType,
+    pub status: PaymentStatus,
+    pub method: PaymentMethod,
+    pub amount: u128,
+    pub fees: u128,
+    pub token_identifier: Option<String>,
+    pub asset_ticker: String,
+    pub asset_decimals: u32,
+    pub fiat_value: Option<PaymentFiatValue>,
+    pub label: Option<String>,
+    pub counterparty: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ExportPaymentsResponse)]
+pub struct ExportPaymentsResponse {
+    pub format: PaymentExportFormat,
+    pub data: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetAccountingReportRequest)]
+pub struct GetAccountingReportRequest {
+    pub from_timestamp: Option<u64>,
+    pub to_timestamp: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::AccountingAssetTotals)]
+pub struct AccountingAssetTotals {
+    pub token_identifier: Option<String>,
+    pub total_in: u128,
+    pub total_out: u128,
+    pub fees_paid: u128,
+    pub received_count: u32,
+    pub sent_count: u32,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetAccountingReportResponse)]
+pub struct GetAccountingReportResponse {
+    pub from_timestamp: Option<u64>,
+    pub to_timestamp: Option<u64>,
+    pub assets: Vec<AccountingAssetTotals>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LogEntry)]
+pub struct LogEntry {
+    pub line: String,
+    pub level: String,
+    pub target: String,
+    pub fields: HashMap<String, String>,
+    pub timestamp: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentMetadata)]
+pub struct PaymentMetadata {
+    pub parent_payment_id: Option<String>,
+    pub lnurl_pay_info: Option<LnurlPayInfo>,
+    pub lnurl_withdraw_info: Option<LnurlWithdrawInfo>,
+    pub lnurl_description: Option<String>,
+    pub conversion_info: Option<ConversionInfo>,
+    pub conversion_status: Option<ConversionStatus>,
+    pub fiat_value: Option<PaymentFiatValue>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SetLnurlMetadataItem)]
+pub struct SetLnurlMetadataItem {
+    pub payment_hash: String,
+    pub sender_comment: Option<String>,
+    pub nostr_zap_request: Option<String>,
+    pub nostr_zap_receipt: Option<String>,
+    pub lightning_address: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::UpdateDepositPayload)]
+pub enum UpdateDepositPayload {
+    ClaimError {
+        error: DepositClaimError,
+    },
+    Refund {
+        refund_txid: String,
+        refund_tx: String,
+    },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CheckLightningAddressRequest)]
+pub struct CheckLightningAddressRequest {
+    pub username: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RegisterLightningAddressRequest)]
+pub struct RegisterLightningAddressRequest {
+    pub username: String,
+    pub description: Option<String>,
+    pub label: Option<String>,
+    pub image: Option<String>,
+    pub long_description: Option<String>,
+    pub min_sendable_sats: Option<u64>,
+    pub max_sendable_sats: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DeleteLabeledLightningAddressRequest)]
+pub struct DeleteLabeledLightningAddressRequest {
+    pub label: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::UpdateLightningAddressNostrKeyRequest)]
+pub struct UpdateLightningAddressNostrKeyRequest {
+    pub nostr_pubkey: Option<String>,
+    pub username: Option<String>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::TransferAuthorization)]
@@ -1489,6 +2194,88 @@ pub struct LightningAddressInfo {
     pub lightning_address: String,
     pub lnurl: LnurlInfo,
     pub username: String,
+    pub label: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::HandleIncomingUriRequest)]
+pub struct HandleIncomingUriRequest {
+    pub uri: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::HandleIncomingUriResponse)]
+pub struct HandleIncomingUriResponse {
+    pub input_type: InputType,
+    pub action: IncomingUriAction,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::IncomingUriAction)]
+pub enum IncomingUriAction {
+    PrepareSendPayment {
+        input: String,
+        amount_sats: Option<u64>,
+    },
+    PrepareLnurlPay {
+        pay_request: LnurlPayRequestDetails,
+    },
+    LnurlWithdraw {
+        withdraw_request: LnurlWithdrawRequestDetails,
+    },
+    LnurlAuth {
+        request_data: LnurlAuthRequestDetails,
+    },
+    GetCrossChainRoutes {
+        address: CrossChainAddressDetails,
+    },
+    OpenUrl {
+        url: String,
+    },
+    Unsupported {
+        reason: String,
+    },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CreatePaymentLinkRequest)]
+pub struct CreatePaymentLinkRequest {
+    pub amount_sats: u64,
+    pub memo: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CreatePaymentLinkResponse)]
+pub struct CreatePaymentLinkResponse {
+    pub link: PaymentLink,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListPaymentLinksRequest)]
+pub struct ListPaymentLinksRequest {
+    pub offset: Option<u32>,
+    pub limit: Option<u32>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListPaymentLinksResponse)]
+pub struct ListPaymentLinksResponse {
+    pub links: Vec<PaymentLink>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DeletePaymentLinkRequest)]
+pub struct DeletePaymentLinkRequest {
+    pub id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentLink)]
+pub struct PaymentLink {
+    pub id: String,
+    pub url: String,
+    pub amount_sats: u64,
+    pub memo: String,
+    pub created_at: u64,
+    pub status: PaymentLinkStatus,
+    pub payment_id: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentLinkStatus)]
+pub enum PaymentLinkStatus {
+    Pending,
+    Paid,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::ListFiatCurrenciesResponse)]
@@ -1507,6 +2294,118 @@ pub struct Rate {
     pub value: f64,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::FetchHistoricalRatesRequest)]
+pub struct FetchHistoricalRatesRequest {
+    pub currency: String,
+    pub from_timestamp: u64,
+    pub to_timestamp: u64,
+    pub resolution: RateResolution,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::FetchHistoricalRatesResponse)]
+pub struct FetchHistoricalRatesResponse {
+    pub rates: Vec<HistoricalRate>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TokenFiatRates)]
+pub struct TokenFiatRates {
+    pub token_identifier: String,
+    pub rates: Vec<Rate>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetTokenFiatRatesRequest)]
+pub struct GetTokenFiatRatesRequest {
+    pub token_identifiers: Vec<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetTokenFiatRatesResponse)]
+pub struct GetTokenFiatRatesResponse {
+    pub rates: Vec<TokenFiatRates>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LockExchangeRateRequest)]
+pub struct LockExchangeRateRequest {
+    pub currency: String,
+    pub ttl_secs: Option<u32>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LockExchangeRateResponse)]
+pub struct LockExchangeRateResponse {
+    pub locked_rate: LockedExchangeRate,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LockedExchangeRate)]
+pub struct LockedExchangeRate {
+    pub id: String,
+    pub currency: String,
+    pub rate: f64,
+    pub expires_at: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ConvertFiatAmountRequest)]
+pub struct ConvertFiatAmountRequest {
+    pub locked_rate_id: String,
+    pub fiat_amount: f64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LockedFiatAmount)]
+pub struct LockedFiatAmount {
+    pub locked_rate_id: String,
+    pub fiat_amount: f64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ConvertFiatAmountResponse)]
+pub struct ConvertFiatAmountResponse {
+    pub amount_sats: u64,
+    pub locked_rate: LockedExchangeRate,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ReceiveFiatPaymentRequest)]
+pub struct ReceiveFiatPaymentRequest {
+    pub payment_method: FiatReceiveMethod,
+    pub currency: String,
+    pub fiat_amount: f64,
+    pub locked_rate_id: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::FiatReceiveMethod)]
+pub enum FiatReceiveMethod {
+    Bolt11Invoice {
+        description: String,
+        expiry_secs: Option<u32>,
+    },
+    SparkInvoice {
+        description: Option<String>,
+        expiry_time: Option<u64>,
+        sender_public_key: Option<String>,
+    },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ReceiveFiatPaymentResponse)]
+pub struct ReceiveFiatPaymentResponse {
+    pub payment_request: String,
+    pub fee: u128,
+    pub amount_sats: u64,
+    pub fiat_value: PaymentFiatValue,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DumpDiagnosticsResponse)]
+pub struct DumpDiagnosticsResponse {
+    pub bundle: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RateResolution)]
+pub enum RateResolution {
+    Hour,
+    Day,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::HistoricalRate)]
+pub struct HistoricalRate {
+    pub timestamp: u64,
+    pub value: f64,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::FiatCurrency)]
 pub struct FiatCurrency {
     pub id: String,
@@ -1555,6 +2454,16 @@ pub struct GetTokensMetadataResponse {
     pub tokens_metadata: Vec<TokenMetadata>,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RefreshTokensMetadataRequest)]
+pub struct RefreshTokensMetadataRequest {
+    pub token_identifiers: Vec<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RefreshTokensMetadataResponse)]
+pub struct RefreshTokensMetadataResponse {
+    pub tokens_metadata: Vec<TokenMetadata>,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::Session)]
 pub struct Session {
     pub token: String,
@@ -1591,6 +2500,12 @@ pub enum ProvisionalPaymentDetails {
     },
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SendApproval)]
+pub enum SendApproval {
+    Approved,
+    Rejected { reason: String },
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentIdUpdate)]
 pub struct PaymentIdUpdate {
     pub provisional_payment_id: String,
@@ -1667,6 +2582,7 @@ pub struct OutgoingChange {
 pub struct UserSettings {
     pub spark_private_mode_enabled: bool,
     pub stable_balance_active_label: Option<String>,
+    pub display_currency: Option<String>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::StableBalanceActiveLabel)]
@@ -1675,10 +2591,17 @@ pub enum StableBalanceActiveLabel {
     Unset,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DisplayCurrency)]
+pub enum DisplayCurrency {
+    Set { currency: String },
+    Unset,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::UpdateUserSettingsRequest)]
 pub struct UpdateUserSettingsRequest {
     pub spark_private_mode_enabled: Option<bool>,
     pub stable_balance_active_label: Option<StableBalanceActiveLabel>,
+    pub display_currency: Option<DisplayCurrency>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::ClaimHtlcPaymentRequest)]
@@ -1691,11 +2614,310 @@ pub struct ClaimHtlcPaymentResponse {
     pub payment: Payment,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SettleHeldPaymentRequest)]
+pub struct SettleHeldPaymentRequest {
+    pub payment_hash: String,
+    pub preimage: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SettleHeldPaymentResponse)]
+pub struct SettleHeldPaymentResponse {
+    pub payment: Payment,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CancelHeldPaymentRequest)]
+pub struct CancelHeldPaymentRequest {
+    pub payment_hash: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RefundHtlcPaymentRequest)]
+pub struct RefundHtlcPaymentRequest {
+    pub payment_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RefundHtlcPaymentResponse)]
+pub struct RefundHtlcPaymentResponse {
+    pub payment: Payment,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::Escrow)]
+pub struct Escrow {
+    pub id: String,
+    pub payment_hash: String,
+    pub role: EscrowRole,
+    pub counterparty_address: String,
+    pub send_amount_sats: u64,
+    pub receive_amount_sats: u64,
+    pub send_payment_id: String,
+    pub send_expiry_time: u64,
+    pub receive_payment_id: Option<String>,
+    pub status: EscrowStatus,
+    pub created_at: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::EscrowRole)]
+pub enum EscrowRole {
+    Initiator,
+    Participant,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::EscrowStatus)]
+pub enum EscrowStatus {
+    Funded,
+    CounterpartyFunded,
+    Released,
+    Refunded,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CreateEscrowRequest)]
+pub struct CreateEscrowRequest {
+    pub payment_hash: String,
+    pub role: EscrowRole,
+    pub counterparty_address: String,
+    pub send_amount_sats: u64,
+    pub receive_amount_sats: u64,
+    pub expiry_duration_secs: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CreateEscrowResponse)]
+pub struct CreateEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetEscrowRequest)]
+pub struct GetEscrowRequest {
+    pub escrow_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetEscrowResponse)]
+pub struct GetEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListEscrowsResponse)]
+pub struct ListEscrowsResponse {
+    pub escrows: Vec<Escrow>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ReleaseEscrowRequest)]
+pub struct ReleaseEscrowRequest {
+    pub escrow_id: String,
+    pub preimage: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ReleaseEscrowResponse)]
+pub struct ReleaseEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RefundEscrowRequest)]
+pub struct RefundEscrowRequest {
+    pub escrow_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RefundEscrowResponse)]
+pub struct RefundEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ArbitratedEscrow)]
+pub struct ArbitratedEscrow {
+    pub id: String,
+    pub payment_hash: String,
+    pub seller_address: String,
+    pub arbiter_pubkey: String,
+    pub arbiter_secret: String,
+    pub amount_sats: u64,
+    pub payment_id: String,
+    pub expiry_time: u64,
+    pub status: ArbitratedEscrowStatus,
+    pub dispute_reason: Option<String>,
+    pub buyer_share: Option<String>,
+    pub seller_share: Option<String>,
+    pub created_at: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ArbitratedEscrowStatus)]
+pub enum ArbitratedEscrowStatus {
+    Funded,
+    Disputed,
+    Released,
+    RefundApproved,
+    Claimed,
+    Refunded,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CreateArbitratedEscrowRequest)]
+pub struct CreateArbitratedEscrowRequest {
+    pub seller_address: String,
+    pub arbiter_pubkey: String,
+    pub amount_sats: u64,
+    pub expiry_duration_secs: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CreateArbitratedEscrowResponse)]
+pub struct CreateArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListArbitratedEscrowsResponse)]
+pub struct ListArbitratedEscrowsResponse {
+    pub escrows: Vec<ArbitratedEscrow>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ReleaseArbitratedEscrowRequest)]
+pub struct ReleaseArbitratedEscrowRequest {
+    pub escrow_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ReleaseArbitratedEscrowResponse)]
+pub struct ReleaseArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DisputeArbitratedEscrowRequest)]
+pub struct DisputeArbitratedEscrowRequest {
+    pub escrow_id: String,
+    pub reason: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DisputeArbitratedEscrowResponse)]
+pub struct DisputeArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RefundArbitratedEscrowRequest)]
+pub struct RefundArbitratedEscrowRequest {
+    pub escrow_id: String,
+    pub signature: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RefundArbitratedEscrowResponse)]
+pub struct RefundArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RecoverArbitratedEscrowPreimageRequest)]
+pub struct RecoverArbitratedEscrowPreimageRequest {
+    pub shares: Vec<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RecoverArbitratedEscrowPreimageResponse)]
+pub struct RecoverArbitratedEscrowPreimageResponse {
+    pub preimage: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TimeLockedPayment)]
+pub struct TimeLockedPayment {
+    pub payment_id: String,
+    pub receiver_address: String,
+    pub amount_sats: u64,
+    pub deliver_after: u64,
+    pub expiry_time: u64,
+    pub status: TimeLockedPaymentStatus,
+    pub preimage: Option<String>,
+    pub created_at: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::TimeLockedPaymentStatus)]
+pub enum TimeLockedPaymentStatus {
+    Locked,
+    Deliverable,
+    Claimed,
+    Cancelled,
+    Returned,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListTimeLockedPaymentsResponse)]
+pub struct ListTimeLockedPaymentsResponse {
+    pub payments: Vec<TimeLockedPayment>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CancelTimeLockedPaymentRequest)]
+pub struct CancelTimeLockedPaymentRequest {
+    pub payment_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CancelTimeLockedPaymentResponse)]
+pub struct CancelTimeLockedPaymentResponse {
+    pub payment: TimeLockedPayment,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentStream)]
+pub struct PaymentStream {
+    pub id: String,
+    pub destination: String,
+    pub rate_sats_per_sec: u64,
+    pub max_amount_sats: u64,
+    pub interval_secs: u32,
+    pub sent_sats: u64,
+    pub status: PaymentStreamStatus,
+    pub payment_ids: Vec<String>,
+    pub error: Option<String>,
+    pub started_at: u64,
+    pub closed_at: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentStreamStatus)]
+pub enum PaymentStreamStatus {
+    Open,
+    Closed,
+    CapReached,
+    Failed,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::OpenPaymentStreamRequest)]
+pub struct OpenPaymentStreamRequest {
+    pub destination: String,
+    pub rate_sats_per_sec: u64,
+    pub max_amount_sats: u64,
+    pub interval_secs: Option<u32>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::OpenPaymentStreamResponse)]
+pub struct OpenPaymentStreamResponse {
+    pub stream: PaymentStream,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ClosePaymentStreamRequest)]
+pub struct ClosePaymentStreamRequest {
+    pub stream_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ClosePaymentStreamResponse)]
+pub struct ClosePaymentStreamResponse {
+    pub stream: PaymentStream,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListPaymentStreamsResponse)]
+pub struct ListPaymentStreamsResponse {
+    pub streams: Vec<PaymentStream>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ClaimSpecificTransferRequest)]
+pub struct ClaimSpecificTransferRequest {
+    pub transfer_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ClaimSpecificTransferResponse)]
+pub struct ClaimSpecificTransferResponse {
+    pub payment: Option<Payment>,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::LnurlReceiveMetadata)]
 pub struct LnurlReceiveMetadata {
     pub nostr_zap_request: Option<String>,
     pub nostr_zap_receipt: Option<String>,
     pub sender_comment: Option<String>,
+    pub lightning_address: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ReceiveMetadata)]
+pub struct ReceiveMetadata {
+    pub sender_comment: Option<String>,
+    pub nostr_zap_request: Option<String>,
+    pub payer_identity: Option<String>,
+    pub payer_note: Option<String>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::OptimizationMode)]
@@ -1704,6 +2926,11 @@ pub enum OptimizationMode {
     SingleRound,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::HostConditions)]
+pub struct HostConditions {
+    pub is_metered: Option<bool>,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::OptimizeLeavesRequest)]
 pub struct OptimizeLeavesRequest {
     pub mode: OptimizationMode,
@@ -1720,6 +2947,44 @@ pub struct OptimizeLeavesResponse {
     pub outcome: OptimizationOutcome,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LeafState)]
+pub enum LeafState {
+    Available,
+    LockedForSwap,
+    PendingTransfer,
+    Unavailable,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LeafInfo)]
+pub struct LeafInfo {
+    pub id: String,
+    pub value_sats: u64,
+    pub state: LeafState,
+    pub age_secs: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LeafDenomination)]
+pub struct LeafDenomination {
+    pub value_sats: u64,
+    pub count: u32,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::LeafStats)]
+pub struct LeafStats {
+    pub leaf_count: u32,
+    pub available_leaf_count: u32,
+    pub locked_leaf_count: u32,
+    pub smallest_available_sats: Option<u64>,
+    pub largest_available_sats: Option<u64>,
+    pub available_denominations: Vec<LeafDenomination>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListLeavesResponse)]
+pub struct ListLeavesResponse {
+    pub leaves: Vec<LeafInfo>,
+    pub stats: LeafStats,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::ConversionEstimate)]
 pub struct ConversionEstimate {
     pub options: ConversionOptions,
@@ -1876,6 +3141,195 @@ pub struct SparkStatus {
     pub last_updated: u64,
 }
 
+#[macros::extern_wasm_bindgen(breez_sdk_spark::HealthDependency)]
+pub enum HealthDependency {
+    SparkOperators,
+    ServiceProvider,
+    ChainService,
+    LnurlService,
+    FiatService,
+    ApiKey,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::HealthStatus)]
+pub enum HealthStatus {
+    Healthy,
+    Unhealthy { error: String },
+    Unknown { reason: String },
+    NotConfigured,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DependencyHealth)]
+pub struct DependencyHealth {
+    pub dependency: HealthDependency,
+    pub status: HealthStatus,
+    pub latency_ms: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CheckHealthResponse)]
+pub struct CheckHealthResponse {
+    pub healthy: bool,
+    pub dependencies: Vec<DependencyHealth>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RailStatus)]
+pub struct RailStatus {
+    pub rail: PaymentRail,
+    pub status: ServiceStatus,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::MaintenanceWindow)]
+pub struct MaintenanceWindow {
+    pub title: String,
+    pub rails: Vec<PaymentRail>,
+    pub starts_at: u64,
+    pub ends_at: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ServiceStatusReport)]
+pub struct ServiceStatusReport {
+    pub status: ServiceStatus,
+    pub rails: Vec<RailStatus>,
+    pub maintenance_windows: Vec<MaintenanceWindow>,
+    pub last_updated: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::JobRequest)]
+pub enum JobRequest {
+    OptimizeLeaves,
+    ExportPayments { request: ExportPaymentsRequest },
+    ExportLedger { request: ExportLedgerRequest },
+    UnilateralExit,
+    Conversion { request: SendPaymentRequest },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::JobKind)]
+pub enum JobKind {
+    OptimizeLeaves,
+    ExportPayments,
+    ExportLedger,
+    UnilateralExit,
+    Conversion,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::JobResult)]
+pub enum JobResult {
+    OptimizeLeaves { rounds_executed: u32 },
+    ExportPayments { response: ExportPaymentsResponse },
+    ExportLedger { response: ExportLedgerResponse },
+    UnilateralExit { progress: UnilateralExitProgress },
+    Conversion { response: SendPaymentResponse },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::JobStatus)]
+pub enum JobStatus {
+    Running,
+    Completed { result: JobResult },
+    Failed { error: String },
+    Cancelled,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::Job)]
+pub struct Job {
+    pub id: String,
+    pub kind: JobKind,
+    pub status: JobStatus,
+    pub completed_steps: u32,
+    pub total_steps: Option<u32>,
+    pub created_at: u64,
+    pub updated_at: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::StartJobRequest)]
+pub struct StartJobRequest {
+    pub job: JobRequest,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::StartJobResponse)]
+pub struct StartJobResponse {
+    pub job_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetJobRequest)]
+pub struct GetJobRequest {
+    pub job_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetJobResponse)]
+pub struct GetJobResponse {
+    pub job: Job,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListJobsResponse)]
+pub struct ListJobsResponse {
+    pub jobs: Vec<Job>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::CancelJobRequest)]
+pub struct CancelJobRequest {
+    pub job_id: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ReceiveOnboardingInfo)]
+pub struct ReceiveOnboardingInfo {
+    pub rail: PaymentRail,
+    pub enabled: bool,
+    pub min_amount_sats: u64,
+    pub estimated_fee_sats: u64,
+    pub required_confirmations: u32,
+    pub estimated_settlement_secs: u64,
+    pub claimed_automatically: bool,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetReceiveOnboardingInfoResponse)]
+pub struct GetReceiveOnboardingInfoResponse {
+    pub rails: Vec<ReceiveOnboardingInfo>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::InputParserPriority)]
+pub enum InputParserPriority {
+    BeforeBuiltIn,
+    AfterBuiltIn,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::InputParserInfo)]
+pub struct InputParserInfo {
+    pub name: String,
+    pub priority: InputParserPriority,
+    pub timeout_secs: Option<u32>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PluginCapabilities)]
+pub struct PluginCapabilities {
+    pub balance_provider: bool,
+    pub payment_rail: bool,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PluginInfo)]
+pub struct PluginInfo {
+    pub name: String,
+    pub capabilities: PluginCapabilities,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PluginBalance)]
+pub struct PluginBalance {
+    pub plugin_name: String,
+    pub balance_sats: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PluginSendQuote)]
+pub struct PluginSendQuote {
+    pub amount_sats: u64,
+    pub fee_sats: u64,
+    pub data: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PluginSendResult)]
+pub struct PluginSendResult {
+    pub payment_id: String,
+    pub status: PaymentStatus,
+}
+
 #[macros::extern_wasm_bindgen(breez_sdk_spark::BuyBitcoinRequest)]
 pub enum BuyBitcoinRequest {
     Moonpay {
@@ -1897,6 +3351,12 @@ pub struct Contact {
     pub id: String,
     pub name: String,
     pub payment_identifier: String,
+    #[serde(default)]
+    pub additional_payment_identifiers: Vec<String>,
+    #[serde(default)]
+    pub avatar_url: Option<String>,
+    #[serde(default)]
+    pub notes: Option<String>,
     pub created_at: u64,
     pub updated_at: u64,
 }
@@ -1905,6 +3365,12 @@ pub struct Contact {
 pub struct AddContactRequest {
     pub name: String,
     pub payment_identifier: String,
+    #[serde(default)]
+    pub additional_payment_identifiers: Vec<String>,
+    #[serde(default)]
+    pub avatar_url: Option<String>,
+    #[serde(default)]
+    pub notes: Option<String>,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::UpdateContactRequest)]
@@ -1912,6 +3378,50 @@ pub struct UpdateContactRequest {
     pub id: String,
     pub name: String,
     pub payment_identifier: String,
+    #[serde(default)]
+    pub additional_payment_identifiers: Vec<String>,
+    #[serde(default)]
+    pub avatar_url: Option<String>,
+    #[serde(default)]
+    pub notes: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetContactPaymentsRequest)]
+pub struct GetContactPaymentsRequest {
+    pub contact_id: String,
+    pub offset: Option<u32>,
+    pub limit: Option<u32>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetContactPaymentsResponse)]
+pub struct GetContactPaymentsResponse {
+    pub payments: Vec<Payment>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SuggestContactsRequest)]
+pub struct SuggestContactsRequest {
+    pub min_payment_count: Option<u32>,
+    pub limit: Option<u32>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SuggestContactsResponse)]
+pub struct SuggestContactsResponse {
+    pub suggestions: Vec<ContactSuggestion>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ContactSuggestion)]
+pub struct ContactSuggestion {
+    pub payment_identifier: String,
+    pub kind: ContactSuggestionKind,
+    pub payment_count: u32,
+    pub last_paid_at: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ContactSuggestionKind)]
+pub enum ContactSuggestionKind {
+    LightningAddress,
+    SparkAddress,
+    LnurlDomain,
 }
 
 #[macros::extern_wasm_bindgen(breez_sdk_spark::ListContactsRequest)]
@@ -1963,3 +3473,186 @@ pub struct RegisterWebhookResponse {
 pub struct UnregisterWebhookRequest {
     pub webhook_id: String,
 }
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::FreezeWalletRequest)]
+pub struct FreezeWalletRequest {
+    pub password: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::UnfreezeWalletRequest)]
+pub struct UnfreezeWalletRequest {
+    pub password: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetSeedBackupChallengeRequest)]
+pub struct GetSeedBackupChallengeRequest {
+    pub word_count: Option<u32>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetSeedBackupChallengeResponse)]
+pub struct GetSeedBackupChallengeResponse {
+    pub positions: Vec<u32>,
+    pub seed_word_count: u32,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SeedBackupWord)]
+pub struct SeedBackupWord {
+    pub position: u32,
+    pub word: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::VerifySeedBackupRequest)]
+pub struct VerifySeedBackupRequest {
+    pub words: Vec<SeedBackupWord>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::VerifySeedBackupResponse)]
+pub struct VerifySeedBackupResponse {
+    pub verified: bool,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SignerSelfTestResponse)]
+pub struct SignerSelfTestResponse {
+    pub passed: bool,
+    pub error: Option<String>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DeriveApplicationKeyRequest)]
+pub struct DeriveApplicationKeyRequest {
+    pub label: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DeriveApplicationKeyResponse)]
+pub struct DeriveApplicationKeyResponse {
+    pub private_key: String,
+    pub public_key: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::BackupStateRequest)]
+pub struct BackupStateRequest {
+    pub url: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::BackupStateResponse)]
+pub struct BackupStateResponse {
+    pub contact_count: u32,
+    pub backed_up_at: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RestoreStateRequest)]
+pub struct RestoreStateRequest {
+    pub url: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RestoreStateResponse)]
+pub struct RestoreStateResponse {
+    pub contacts_restored: u32,
+    pub backed_up_at: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DeleteWalletDataResponse)]
+pub struct DeleteWalletDataResponse {
+    pub lightning_address_deleted: bool,
+    pub labeled_lightning_addresses_deleted: u32,
+    pub payment_links_deleted: u32,
+    pub webhooks_deleted: u32,
+    pub sync_records_kept: bool,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RequestTestFundsRequest)]
+pub struct RequestTestFundsRequest {
+    pub amount_sats: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::RequestTestFundsResponse)]
+pub struct RequestTestFundsResponse {
+    pub address: String,
+    pub txid: String,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::DuressConfig)]
+pub struct DuressConfig {
+    pub pin_hash: String,
+    pub decoy_account_number: u32,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::OnchainTransaction)]
+pub struct OnchainTransaction {
+    pub tx_id: String,
+    pub kind: OnchainTransactionKind,
+    pub status: OnchainTransactionStatus,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::OnchainTransactionKind)]
+pub enum OnchainTransactionKind {
+    Withdrawal {
+        payment_id: String,
+    },
+    DepositRefund {
+        deposit_txid: String,
+        deposit_vout: u32,
+    },
+    UnilateralExit {
+        tx_kind: UnilateralExitTxKind,
+        node_id: Option<String>,
+    },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::OnchainTransactionStatus)]
+pub enum OnchainTransactionStatus {
+    Broadcast,
+    InMempool,
+    Confirmed {
+        block_height: u32,
+        confirmations: Option<u32>,
+    },
+    Evicted,
+    Replaced {
+        replacement_tx_id: String,
+    },
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListOnchainTransactionsRequest)]
+pub struct ListOnchainTransactionsRequest {}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::ListOnchainTransactionsResponse)]
+pub struct ListOnchainTransactionsResponse {
+    pub transactions: Vec<OnchainTransaction>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::PaymentRail)]
+pub enum PaymentRail {
+    Lightning,
+    Bitcoin,
+    Spark,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SpendingCap)]
+pub struct SpendingCap {
+    pub scope: SpendingCapScope,
+    pub max_amount: u128,
+    pub window_secs: u64,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SpendingCapScope)]
+pub enum SpendingCapScope {
+    Rail { rail: PaymentRail },
+    Token { token_identifier: String },
+    Plugins,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetRemainingAllowanceRequest)]
+pub struct GetRemainingAllowanceRequest {}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::SpendingAllowance)]
+pub struct SpendingAllowance {
+    pub cap: SpendingCap,
+    pub spent: u128,
+    pub remaining: u128,
+    pub next_release_at: Option<u64>,
+}
+
+#[macros::extern_wasm_bindgen(breez_sdk_spark::GetRemainingAllowanceResponse)]
+pub struct GetRemainingAllowanceResponse {
+    pub allowances: Vec<SpendingAllowance>,
+}
@@ -2,7 +2,7 @@ use wasm_bindgen::prelude::*;
 use wasm_bindgen_futures::{JsFuture, js_sys::Promise};
 
 use crate::models::{
-    PaymentIdUpdate, ProvisionalPayment, error::js_error_to_payment_observer_error,
+    PaymentIdUpdate, ProvisionalPayment, SendApproval, error::js_error_to_payment_observer_error,
 };
 
 pub struct WasmPaymentObserver {
@@ -42,10 +42,44 @@ impl breez_sdk_spark::PaymentObserver for WasmPaymentObserver {
     }
 }
 
+pub struct WasmSendApprover {
+    pub send_approver: SendApprover,
+}
+
+// This assumes that we'll always be running in a single thread (true for Wasm environments)
+unsafe impl Send for WasmSendApprover {}
+unsafe impl Sync for WasmSendApprover {}
+
+#[macros::async_trait]
+impl breez_sdk_spark::SendApprover for WasmSendApprover {
+    async fn approve(
+        &self,
+        payment: breez_sdk_spark::ProvisionalPayment,
+    ) -> Result<breez_sdk_spark::SendApproval, breez_sdk_spark::PaymentObserverError> {
+        let promise = self
+            .send_approver
+            .approve(payment.into())
+            .map_err(js_error_to_payment_observer_error)?;
+        let result = JsFuture::from(promise)
+            .await
+            .map_err(js_error_to_payment_observer_error)?;
+        let approval = serde_wasm_bindgen::from_value::<SendApproval>(result).map_err(|e| {
+            breez_sdk_spark::PaymentObserverError::Generic(format!(
+                "Failed to deserialize send approval: {e}"
+            ))
+        })?;
+        Ok(approval.into())
+    }
+}
+
 #[wasm_bindgen(typescript_custom_section)]
 const EVENT_INTERFACE: &'static str = r#"export interface PaymentObserver {
     beforeSend: (payments: ProvisionalPayment[]) => Promise<void>;
     afterSend: (updates: PaymentIdUpdate[]) => Promise<void>;
+}
+
+export interface SendApprover {
+    approve: (payment: ProvisionalPayment) => Promise<SendApproval>;
 }"#;
 
 #[wasm_bindgen]
@@ -64,4 +98,10 @@ extern "C" {
         this: &PaymentObserver,
         updates: Vec<PaymentIdUpdate>,
     ) -> Result<Promise, JsValue>;
+
+    #[wasm_bindgen(typescript_type = "SendApprover")]
+    pub type SendApprover;
+
+    #[wasm_bindgen(structural, method, js_name = approve, catch)]
+    pub fn approve(this: &SendApprover, payment: ProvisionalPayment) -> Result<Promise, JsValue>;
 }
@@ -0,0 +1,125 @@
+use wasm_bindgen::prelude::*;
+use wasm_bindgen_futures::{JsFuture, js_sys::Promise};
+
+use crate::models::{
+    PluginInfo, PluginSendQuote, PluginSendResult, error::js_error_to_plugin_error,
+};
+
+pub struct WasmSdkPlugin {
+    pub plugin: SdkPlugin,
+    /// The info the plugin reported when it was registered
+    pub info: breez_sdk_spark::PluginInfo,
+}
+
+// This assumes that we'll always be running in a single thread (true for Wasm environments)
+unsafe impl Send for WasmSdkPlugin {}
+unsafe impl Sync for WasmSdkPlugin {}
+
+impl WasmSdkPlugin {
+    pub fn new(plugin: SdkPlugin) -> Result<Self, JsValue> {
+        let info = serde_wasm_bindgen::from_value::<PluginInfo>(plugin.info()?)
+            .map_err(|e| JsValue::from_str(&format!("Failed to deserialize plugin info: {e}")))?;
+        Ok(Self {
+            plugin,
+            info: info.into(),
+        })
+    }
+
+    async fn call(
+        &self,
+        promise: Result<Promise, JsValue>,
+    ) -> Result<JsValue, breez_sdk_spark::PluginError> {
+        let promise = promise.map_err(js_error_to_plugin_error)?;
+        JsFuture::from(promise)
+            .await
+            .map_err(js_error_to_plugin_error)
+    }
+}
+
+#[macros::async_trait]
+impl breez_sdk_spark::SdkPlugin for WasmSdkPlugin {
+    fn info(&self) -> breez_sdk_spark::PluginInfo {
+        self.info.clone()
+    }
+
+    async fn start(&self) -> Result<(), breez_sdk_spark::PluginError> {
+        self.call(self.plugin.start()).await?;
+        Ok(())
+    }
+
+    async fn stop(&self) -> Result<(), breez_sdk_spark::PluginError> {
+        self.call(self.plugin.stop()).await?;
+        Ok(())
+    }
+
+    async fn get_balance(&self) -> Result<u64, breez_sdk_spark::PluginError> {
+        let result = self.call(self.plugin.get_balance()).await?;
+        serde_wasm_bindgen::from_value::<u64>(result).map_err(|e| {
+            breez_sdk_spark::PluginError::Generic(format!("Failed to deserialize balance: {e}"))
+        })
+    }
+
+    async fn prepare_send(
+        &self,
+        destination: String,
+        amount_sats: Option<u64>,
+    ) -> Result<Option<breez_sdk_spark::PluginSendQuote>, breez_sdk_spark::PluginError> {
+        let result = self
+            .call(self.plugin.prepare_send(destination, amount_sats))
+            .await?;
+        let quote =
+            serde_wasm_bindgen::from_value::<Option<PluginSendQuote>>(result).map_err(|e| {
+                breez_sdk_spark::PluginError::Generic(format!("Failed to deserialize quote: {e}"))
+            })?;
+        Ok(quote.map(Into::into))
+    }
+
+    async fn send(
+        &self,
+        quote: breez_sdk_spark::PluginSendQuote,
+    ) -> Result<breez_sdk_spark::PluginSendResult, breez_sdk_spark::PluginError> {
+        let result = self.call(self.plugin.send(quote.into())).await?;
+        let result = serde_wasm_bindgen::from_value::<PluginSendResult>(result).map_err(|e| {
+            breez_sdk_spark::PluginError::Generic(format!("Failed to deserialize send result: {e}"))
+        })?;
+        Ok(result.into())
+    }
+}
+
+#[wasm_bindgen(typescript_custom_section)]
+const PLUGIN_INTERFACE: &'static str = r#"export interface SdkPlugin {
+    info: () => PluginInfo;
+    start: () => Promise<void>;
+    stop: () => Promise<void>;
+    getBalance: () => Promise<number>;
+    prepareSend: (destination: string, amountSats?: number) => Promise<PluginSendQuote | undefined>;
+    send: (quote: PluginSendQuote) => Promise<PluginSendResult>;
+}"#;
+
+#[wasm_bindgen]
+extern "C" {
+    #[wasm_bindgen(typescript_type = "SdkPlugin")]
+    pub type SdkPlugin;
+
+    #[wasm_bindgen(structural, method, js_name = info, catch)]
+    pub fn info(this: &SdkPlugin) -> Result<JsValue, JsValue>;
+
+    #[wasm_bindgen(structural, method, js_name = start, catch)]
+    pub fn start(this: &SdkPlugin) -> Result<Promise, JsValue>;
+
+    #[wasm_bindgen(structural, method, js_name = stop, catch)]
+    pub fn stop(this: &SdkPlugin) -> Result<Promise, JsValue>;
+
+    #[wasm_bindgen(structural, method, js_name = getBalance, catch)]
+    pub fn get_balance(this: &SdkPlugin) -> Result<Promise, JsValue>;
+
+    #[wasm_bindgen(structural, method, js_name = prepareSend, catch)]
+    pub fn prepare_send(
+        this: &SdkPlugin,
+        destination: String,
+        amount_sats: Option<u64>,
+    ) -> Result<Promise, JsValue>;
+
+    #[wasm_bindgen(structural, method, js_name = send, catch)]
+    pub fn send(this: &SdkPlugin, quote: PluginSendQuote) -> Result<Promise, JsValue>;
+}
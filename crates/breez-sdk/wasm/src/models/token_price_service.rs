@@ -0,0 +1,50 @@
+use breez_sdk_spark::ServiceConnectivityError;
+use wasm_bindgen::prelude::*;
+use wasm_bindgen_futures::{JsFuture, js_sys::Promise};
+
+use crate::models::{TokenFiatRates, TokenMetadata, error::js_error_to_service_connectivity_error};
+
+pub struct WasmTokenPriceService {
+    pub inner: TokenPriceService,
+}
+
+// This assumes that we'll always be running in a single thread (true for Wasm environments)
+unsafe impl Send for WasmTokenPriceService {}
+unsafe impl Sync for WasmTokenPriceService {}
+
+#[macros::async_trait]
+impl breez_sdk_spark::TokenPriceService for WasmTokenPriceService {
+    async fn fetch_token_fiat_rates(
+        &self,
+        tokens: Vec<breez_sdk_spark::TokenMetadata>,
+    ) -> Result<Vec<breez_sdk_spark::TokenFiatRates>, ServiceConnectivityError> {
+        let promise = self
+            .inner
+            .fetch_token_fiat_rates(tokens.into_iter().map(TokenMetadata::from).collect())
+            .map_err(js_error_to_service_connectivity_error)?;
+        let future = JsFuture::from(promise);
+        let result = future
+            .await
+            .map_err(js_error_to_service_connectivity_error)?;
+        let rates: Vec<TokenFiatRates> = serde_wasm_bindgen::from_value(result)
+            .map_err(|e| ServiceConnectivityError::Other(e.to_string()))?;
+        Ok(rates.into_iter().map(|r| r.into()).collect())
+    }
+}
+
+#[wasm_bindgen(typescript_custom_section)]
+const EVENT_INTERFACE: &'static str = r#"export interface TokenPriceService {
+    fetchTokenFiatRates(tokens: TokenMetadata[]): Promise<TokenFiatRates[]>;
+}"#;
+
+#[wasm_bindgen]
+extern "C" {
+    #[wasm_bindgen(typescript_type = "TokenPriceService")]
+    pub type TokenPriceService;
+
+    #[wasm_bindgen(structural, method, js_name = "fetchTokenFiatRates", catch)]
+    pub fn fetch_token_fiat_rates(
+        this: &TokenPriceService,
+        tokens: Vec<TokenMetadata>,
+    ) -> Result<Promise, JsValue>;
+}
@@ -534,6 +534,16 @@ impl breez_sdk_spark::Storage for WasmStorage {
         future.await.map_err(js_error_to_storage_error)?;
         Ok(())
     }
+
+    async fn delete_all_data(&self) -> Result<(), StorageError> {
+        let promise = self
+            .storage
+            .delete_all_data()
+            .map_err(js_error_to_storage_error)?;
+        let future = JsFuture::from(promise);
+        future.await.map_err(js_error_to_storage_error)?;
+        Ok(())
+    }
 }
 
 #[wasm_bindgen(typescript_custom_section)]
@@ -576,6 +586,7 @@ const STORAGE_INTERFACE: &'static str = r#"export interface Storage {
     syncGetIncomingRecords: (limit: number) => Promise<IncomingChange[]>;
     syncGetLatestOutgoingChange: () => Promise<OutgoingChange | null>;
     syncUpdateRecordFromIncoming: (record: Record) => Promise<void>;
+    deleteAllData: () => Promise<void>;
 }"#;
 
 #[wasm_bindgen]
@@ -722,4 +733,7 @@ extern "C" {
         this: &Storage,
         record: Record,
     ) -> Result<Promise, JsValue>;
+
+    #[wasm_bindgen(structural, method, js_name = deleteAllData, catch)]
+    pub fn delete_all_data(this: &Storage) -> Result<Promise, JsValue>;
 }
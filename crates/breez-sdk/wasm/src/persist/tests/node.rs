@@ -332,6 +332,8 @@ async fn test_migration_from_v17_to_v18() {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     breez_sdk_spark::Storage::apply_payment_update(&storage, new_payment.clone())
@@ -243,6 +243,8 @@ async fn test_migration_from_v2_to_v3() {
         method: breez_sdk_spark::PaymentMethod::Lightning,
         details: None,
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     breez_sdk_spark::Storage::apply_payment_update(&storage, new_payment.clone())
@@ -375,6 +377,8 @@ async fn test_migration_from_v8_to_v9() {
             conversion_info: None,
         }),
         conversion_details: None,
+        fiat_value: None,
+        receive_metadata: None,
     };
 
     breez_sdk_spark::Storage::apply_payment_update(&storage, new_payment.clone())
@@ -8,6 +8,7 @@ use crate::{
     event::{EventListener, WasmEventListener},
     issuer::TokenIssuer,
     logger::{Logger, WasmTracingLayer},
+    middleware::{PaymentMiddleware, WasmPaymentMiddleware},
     models::{chain_service::RecommendedFees, *},
     sdk_builder::SdkBuilder,
 };
@@ -82,6 +83,45 @@ pub fn default_server_config(network: Network) -> Config {
     breez_sdk_spark::default_server_config(network.into()).into()
 }
 
+#[wasm_bindgen(js_name = "createDuressConfig")]
+pub fn create_duress_config(
+    duress_pin: String,
+    decoy_account_number: u32,
+) -> WasmResult<DuressConfig> {
+    Ok(breez_sdk_spark::create_duress_config(duress_pin, decoy_account_number)?.into())
+}
+
+#[wasm_bindgen(js_name = "isDuressPin")]
+pub fn is_duress_pin(duress_config: DuressConfig, pin: String) -> bool {
+    breez_sdk_spark::is_duress_pin(duress_config.into(), pin)
+}
+
+#[wasm_bindgen(js_name = "amountToBaseUnits")]
+pub fn amount_to_base_units(
+    amount: String,
+    token_metadata: TokenMetadata,
+    rounding: AmountRounding,
+) -> WasmResult<u128> {
+    Ok(breez_sdk_spark::amount_to_base_units(
+        amount,
+        token_metadata.into(),
+        rounding.into(),
+    )?)
+}
+
+#[wasm_bindgen(js_name = "baseUnitsToAmount")]
+pub fn base_units_to_amount(base_units: u128, token_metadata: TokenMetadata) -> String {
+    breez_sdk_spark::base_units_to_amount(base_units, token_metadata.into())
+}
+
+#[wasm_bindgen(js_name = "decryptSuccessAction")]
+pub fn decrypt_success_action(
+    data: AesSuccessActionData,
+    preimage: String,
+) -> AesSuccessActionDataResult {
+    breez_sdk_spark::decrypt_success_action(data.into(), preimage).into()
+}
+
 #[wasm_bindgen(js_name = "getSparkStatus")]
 pub async fn get_spark_status() -> WasmResult<SparkStatus> {
     Ok(breez_sdk_spark::get_spark_status().await?.into())
@@ -194,6 +234,14 @@ pub fn single_key_cpfp_signer(
     Ok(crate::signer::DefaultCpfpSigner::new(signer))
 }
 
+/// Creates a CPFP signer that hands base64 PSBTs to a `PsbtSigner`, such as a
+/// hardware wallet.
+#[wasm_bindgen(js_name = "psbtCpfpSigner")]
+pub fn psbt_cpfp_signer(signer: crate::signer::JsPsbtSigner) -> crate::signer::DefaultCpfpSigner {
+    let signer = std::sync::Arc::new(crate::signer::WasmPsbtSigner::new(signer));
+    crate::signer::DefaultCpfpSigner::new(breez_sdk_spark::signer::psbt_cpfp_signer(signer))
+}
+
 #[wasm_bindgen]
 impl BreezSdk {
     #[wasm_bindgen(js_name = "addEventListener")]
@@ -208,6 +256,18 @@ impl BreezSdk {
         self.sdk.remove_event_listener(id).await
     }
 
+    #[wasm_bindgen(js_name = "addPaymentMiddleware")]
+    pub async fn add_payment_middleware(&self, middleware: PaymentMiddleware) -> String {
+        self.sdk
+            .add_payment_middleware(Box::new(WasmPaymentMiddleware { middleware }))
+            .await
+    }
+
+    #[wasm_bindgen(js_name = "removePaymentMiddleware")]
+    pub async fn remove_payment_middleware(&self, id: &str) -> bool {
+        self.sdk.remove_payment_middleware(id).await
+    }
+
     #[wasm_bindgen(js_name = "disconnect")]
     pub async fn disconnect(&self) -> WasmResult<()> {
         Ok(self.sdk.disconnect().await?)
@@ -218,6 +278,19 @@ impl BreezSdk {
         Ok(self.sdk.parse(input).await?.into())
     }
 
+    #[wasm_bindgen(js_name = "parseOffline")]
+    pub fn parse_offline(&self, input: &str) -> WasmResult<InputType> {
+        Ok(self.sdk.parse_offline(input)?.into())
+    }
+
+    #[wasm_bindgen(js_name = "handleIncomingUri")]
+    pub async fn handle_incoming_uri(
+        &self,
+        request: HandleIncomingUriRequest,
+    ) -> WasmResult<HandleIncomingUriResponse> {
+        Ok(self.sdk.handle_incoming_uri(request.into()).await?.into())
+    }
+
     #[wasm_bindgen(js_name = "getCrossChainRoutes")]
     pub async fn get_cross_chain_routes(
         &self,
@@ -264,6 +337,25 @@ impl BreezSdk {
             .into())
     }
 
+    #[wasm_bindgen(js_name = "getUnilateralExitProgress")]
+    pub async fn get_unilateral_exit_progress(
+        &self,
+    ) -> WasmResult<GetUnilateralExitProgressResponse> {
+        Ok(self.sdk.get_unilateral_exit_progress().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "listOnchainTransactions")]
+    pub async fn list_onchain_transactions(
+        &self,
+        request: ListOnchainTransactionsRequest,
+    ) -> WasmResult<ListOnchainTransactionsResponse> {
+        Ok(self
+            .sdk
+            .list_onchain_transactions(request.into())
+            .await?
+            .into())
+    }
+
     #[wasm_bindgen(js_name = "receivePayment")]
     pub async fn receive_payment(
         &self,
@@ -280,6 +372,175 @@ impl BreezSdk {
         Ok(self.sdk.claim_htlc_payment(request.into()).await?.into())
     }
 
+    #[wasm_bindgen(js_name = "settleHeldPayment")]
+    pub async fn settle_held_payment(
+        &self,
+        request: SettleHeldPaymentRequest,
+    ) -> WasmResult<SettleHeldPaymentResponse> {
+        Ok(self.sdk.settle_held_payment(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "cancelHeldPayment")]
+    pub async fn cancel_held_payment(&self, request: CancelHeldPaymentRequest) -> WasmResult<()> {
+        Ok(self.sdk.cancel_held_payment(request.into()).await?)
+    }
+
+    #[wasm_bindgen(js_name = "refundHtlcPayment")]
+    pub async fn refund_htlc_payment(
+        &self,
+        request: RefundHtlcPaymentRequest,
+    ) -> WasmResult<RefundHtlcPaymentResponse> {
+        Ok(self.sdk.refund_htlc_payment(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "createEscrow")]
+    pub async fn create_escrow(
+        &self,
+        request: CreateEscrowRequest,
+    ) -> WasmResult<CreateEscrowResponse> {
+        Ok(self.sdk.create_escrow(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "getEscrow")]
+    pub async fn get_escrow(&self, request: GetEscrowRequest) -> WasmResult<GetEscrowResponse> {
+        Ok(self.sdk.get_escrow(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "listEscrows")]
+    pub async fn list_escrows(&self) -> WasmResult<ListEscrowsResponse> {
+        Ok(self.sdk.list_escrows().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "releaseEscrow")]
+    pub async fn release_escrow(
+        &self,
+        request: ReleaseEscrowRequest,
+    ) -> WasmResult<ReleaseEscrowResponse> {
+        Ok(self.sdk.release_escrow(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "refundEscrow")]
+    pub async fn refund_escrow(
+        &self,
+        request: RefundEscrowRequest,
+    ) -> WasmResult<RefundEscrowResponse> {
+        Ok(self.sdk.refund_escrow(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "createArbitratedEscrow")]
+    pub async fn create_arbitrated_escrow(
+        &self,
+        request: CreateArbitratedEscrowRequest,
+    ) -> WasmResult<CreateArbitratedEscrowResponse> {
+        Ok(self
+            .sdk
+            .create_arbitrated_escrow(request.into())
+            .await?
+            .into())
+    }
+
+    #[wasm_bindgen(js_name = "listArbitratedEscrows")]
+    pub async fn list_arbitrated_escrows(&self) -> WasmResult<ListArbitratedEscrowsResponse> {
+        Ok(self.sdk.list_arbitrated_escrows().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "releaseArbitratedEscrow")]
+    pub async fn release_arbitrated_escrow(
+        &self,
+        request: ReleaseArbitratedEscrowRequest,
+    ) -> WasmResult<ReleaseArbitratedEscrowResponse> {
+        Ok(self
+            .sdk
+            .release_arbitrated_escrow(request.into())
+            .await?
+            .into())
+    }
+
+    #[wasm_bindgen(js_name = "disputeArbitratedEscrow")]
+    pub async fn dispute_arbitrated_escrow(
+        &self,
+        request: DisputeArbitratedEscrowRequest,
+    ) -> WasmResult<DisputeArbitratedEscrowResponse> {
+        Ok(self
+            .sdk
+            .dispute_arbitrated_escrow(request.into())
+            .await?
+            .into())
+    }
+
+    #[wasm_bindgen(js_name = "refundArbitratedEscrow")]
+    pub async fn refund_arbitrated_escrow(
+        &self,
+        request: RefundArbitratedEscrowRequest,
+    ) -> WasmResult<RefundArbitratedEscrowResponse> {
+        Ok(self
+            .sdk
+            .refund_arbitrated_escrow(request.into())
+            .await?
+            .into())
+    }
+
+    #[wasm_bindgen(js_name = "recoverArbitratedEscrowPreimage")]
+    pub fn recover_arbitrated_escrow_preimage(
+        &self,
+        request: RecoverArbitratedEscrowPreimageRequest,
+    ) -> WasmResult<RecoverArbitratedEscrowPreimageResponse> {
+        Ok(self
+            .sdk
+            .recover_arbitrated_escrow_preimage(request.into())?
+            .into())
+    }
+
+    #[wasm_bindgen(js_name = "listTimeLockedPayments")]
+    pub async fn list_time_locked_payments(&self) -> WasmResult<ListTimeLockedPaymentsResponse> {
+        Ok(self.sdk.list_time_locked_payments().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "cancelTimeLockedPayment")]
+    pub async fn cancel_time_locked_payment(
+        &self,
+        request: CancelTimeLockedPaymentRequest,
+    ) -> WasmResult<CancelTimeLockedPaymentResponse> {
+        Ok(self
+            .sdk
+            .cancel_time_locked_payment(request.into())
+            .await?
+            .into())
+    }
+
+    #[wasm_bindgen(js_name = "openPaymentStream")]
+    pub async fn open_payment_stream(
+        &self,
+        request: OpenPaymentStreamRequest,
+    ) -> WasmResult<OpenPaymentStreamResponse> {
+        Ok(self.sdk.open_payment_stream(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "closePaymentStream")]
+    pub async fn close_payment_stream(
+        &self,
+        request: ClosePaymentStreamRequest,
+    ) -> WasmResult<ClosePaymentStreamResponse> {
+        Ok(self.sdk.close_payment_stream(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "listPaymentStreams")]
+    pub async fn list_payment_streams(&self) -> WasmResult<ListPaymentStreamsResponse> {
+        Ok(self.sdk.list_payment_streams().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "claimSpecificTransfer")]
+    pub async fn claim_specific_transfer(
+        &self,
+        request: ClaimSpecificTransferRequest,
+    ) -> WasmResult<ClaimSpecificTransferResponse> {
+        Ok(self
+            .sdk
+            .claim_specific_transfer(request.into())
+            .await?
+            .into())
+    }
+
     #[wasm_bindgen(js_name = "prepareSendPayment")]
     pub async fn prepare_send_payment(
         &self,
@@ -361,6 +622,50 @@ impl BreezSdk {
         Ok(self.sdk.send_payment(request.into()).await?.into())
     }
 
+    #[wasm_bindgen(js_name = "sendPaymentAsync")]
+    pub async fn send_payment_async(
+        &self,
+        request: SendPaymentRequest,
+    ) -> WasmResult<PaymentHandle> {
+        Ok(self.sdk.send_payment_async(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "cancelPendingPayment")]
+    pub async fn cancel_pending_payment(
+        &self,
+        request: CancelPendingPaymentRequest,
+    ) -> WasmResult<()> {
+        Ok(self.sdk.cancel_pending_payment(request.into()).await?)
+    }
+
+    #[wasm_bindgen(js_name = "cancelPayment")]
+    pub async fn cancel_payment(
+        &self,
+        request: CancelPaymentRequest,
+    ) -> WasmResult<CancelPaymentResponse> {
+        Ok(self.sdk.cancel_payment(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "simulateSendPayment")]
+    pub async fn simulate_send_payment(
+        &self,
+        request: SimulateSendPaymentRequest,
+    ) -> WasmResult<SimulateSendPaymentResponse> {
+        Ok(self.sdk.simulate_send_payment(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "getRemainingAllowance")]
+    pub async fn get_remaining_allowance(
+        &self,
+        request: GetRemainingAllowanceRequest,
+    ) -> WasmResult<GetRemainingAllowanceResponse> {
+        Ok(self
+            .sdk
+            .get_remaining_allowance(request.into())
+            .await?
+            .into())
+    }
+
     #[wasm_bindgen(js_name = "publishSignedTransferPackage")]
     pub async fn publish_signed_transfer_package(
         &self,
@@ -378,6 +683,21 @@ impl BreezSdk {
         Ok(self.sdk.sync_wallet(request.into()).await?.into())
     }
 
+    #[wasm_bindgen(js_name = "getSyncStatus")]
+    pub async fn get_sync_status(&self) -> WasmResult<GetSyncStatusResponse> {
+        Ok(self.sdk.get_sync_status().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "listDevices")]
+    pub async fn list_devices(&self) -> WasmResult<ListDevicesResponse> {
+        Ok(self.sdk.list_devices().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "setDeviceName")]
+    pub async fn set_device_name(&self, request: SetDeviceNameRequest) -> WasmResult<()> {
+        Ok(self.sdk.set_device_name(request.into()).await?)
+    }
+
     #[wasm_bindgen(js_name = "listPayments")]
     pub async fn list_payments(
         &self,
@@ -391,6 +711,111 @@ impl BreezSdk {
         Ok(self.sdk.get_payment(request.into()).await?.into())
     }
 
+    #[wasm_bindgen(js_name = "getLedger")]
+    pub async fn get_ledger(&self, request: GetLedgerRequest) -> WasmResult<GetLedgerResponse> {
+        Ok(self.sdk.get_ledger(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "exportLedger")]
+    pub async fn export_ledger(
+        &self,
+        request: ExportLedgerRequest,
+    ) -> WasmResult<ExportLedgerResponse> {
+        Ok(self.sdk.export_ledger(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "getTokenActivity")]
+    pub async fn get_token_activity(
+        &self,
+        request: GetTokenActivityRequest,
+    ) -> WasmResult<GetTokenActivityResponse> {
+        Ok(self.sdk.get_token_activity(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "exportPayments")]
+    pub async fn export_payments(
+        &self,
+        request: ExportPaymentsRequest,
+    ) -> WasmResult<ExportPaymentsResponse> {
+        Ok(self.sdk.export_payments(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "getAccountingReport")]
+    pub async fn get_accounting_report(
+        &self,
+        request: GetAccountingReportRequest,
+    ) -> WasmResult<GetAccountingReportResponse> {
+        Ok(self.sdk.get_accounting_report(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "getSeedBackupChallenge")]
+    pub async fn get_seed_backup_challenge(
+        &self,
+        request: GetSeedBackupChallengeRequest,
+    ) -> WasmResult<GetSeedBackupChallengeResponse> {
+        Ok(self
+            .sdk
+            .get_seed_backup_challenge(request.into())
+            .await?
+            .into())
+    }
+
+    #[wasm_bindgen(js_name = "verifySeedBackup")]
+    pub async fn verify_seed_backup(
+        &self,
+        request: VerifySeedBackupRequest,
+    ) -> WasmResult<VerifySeedBackupResponse> {
+        Ok(self.sdk.verify_seed_backup(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "runSignerSelfTest")]
+    pub async fn run_signer_self_test(&self) -> WasmResult<SignerSelfTestResponse> {
+        Ok(self.sdk.run_signer_self_test().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "deriveApplicationKey")]
+    pub async fn derive_application_key(
+        &self,
+        request: DeriveApplicationKeyRequest,
+    ) -> WasmResult<DeriveApplicationKeyResponse> {
+        Ok(self
+            .sdk
+            .derive_application_key(request.into())
+            .await?
+            .into())
+    }
+
+    #[wasm_bindgen(js_name = "backupState")]
+    pub async fn backup_state(
+        &self,
+        request: BackupStateRequest,
+    ) -> WasmResult<BackupStateResponse> {
+        Ok(self.sdk.backup_state(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "restoreState")]
+    pub async fn restore_state(
+        &self,
+        request: RestoreStateRequest,
+    ) -> WasmResult<RestoreStateResponse> {
+        Ok(self.sdk.restore_state(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "deleteWalletData")]
+    pub async fn delete_wallet_data(&self) -> WasmResult<DeleteWalletDataResponse> {
+        Ok(self.sdk.delete_wallet_data().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "freezeWallet")]
+    pub async fn freeze_wallet(&self, request: FreezeWalletRequest) -> WasmResult<()> {
+        Ok(self.sdk.freeze_wallet(request.into()).await?)
+    }
+
+    #[wasm_bindgen(js_name = "unfreezeWallet")]
+    pub async fn unfreeze_wallet(&self, request: UnfreezeWalletRequest) -> WasmResult<()> {
+        Ok(self.sdk.unfreeze_wallet(request.into()).await?)
+    }
+
     #[wasm_bindgen(js_name = "claimDeposit")]
     pub async fn claim_deposit(
         &self,
@@ -399,6 +824,14 @@ impl BreezSdk {
         Ok(self.sdk.claim_deposit(request.into()).await?.into())
     }
 
+    #[wasm_bindgen(js_name = "claimDeposits")]
+    pub async fn claim_deposits(
+        &self,
+        request: ClaimDepositsRequest,
+    ) -> WasmResult<ClaimDepositsResponse> {
+        Ok(self.sdk.claim_deposits(request.into()).await?.into())
+    }
+
     #[wasm_bindgen(js_name = "refundDeposit")]
     pub async fn refund_deposit(
         &self,
@@ -407,6 +840,14 @@ impl BreezSdk {
         Ok(self.sdk.refund_deposit(request.into()).await?.into())
     }
 
+    #[wasm_bindgen(js_name = "bumpRefundFee")]
+    pub async fn bump_refund_fee(
+        &self,
+        request: BumpRefundFeeRequest,
+    ) -> WasmResult<BumpRefundFeeResponse> {
+        Ok(self.sdk.bump_refund_fee(request.into()).await?.into())
+    }
+
     #[wasm_bindgen(js_name = "listUnclaimedDeposits")]
     pub async fn list_unclaimed_deposits(
         &self,
@@ -419,6 +860,21 @@ impl BreezSdk {
             .into())
     }
 
+    #[wasm_bindgen(js_name = "getDepositAddressHistory")]
+    pub async fn get_deposit_address_history(
+        &self,
+    ) -> WasmResult<GetDepositAddressHistoryResponse> {
+        Ok(self.sdk.get_deposit_address_history().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "requestTestFunds")]
+    pub async fn request_test_funds(
+        &self,
+        request: RequestTestFundsRequest,
+    ) -> WasmResult<RequestTestFundsResponse> {
+        Ok(self.sdk.request_test_funds(request.into()).await?.into())
+    }
+
     #[wasm_bindgen(js_name = "checkLightningAddressAvailable")]
     pub async fn check_lightning_address_available(
         &self,
@@ -451,6 +907,17 @@ impl BreezSdk {
             .into())
     }
 
+    #[wasm_bindgen(js_name = "listLightningAddresses")]
+    pub async fn list_lightning_addresses(&self) -> WasmResult<Vec<LightningAddressInfo>> {
+        Ok(self
+            .sdk
+            .list_lightning_addresses()
+            .await?
+            .into_iter()
+            .map(Into::into)
+            .collect())
+    }
+
     #[wasm_bindgen(js_name = "authorizeLightningAddressTransfer")]
     pub async fn authorize_lightning_address_transfer(
         &self,
@@ -480,6 +947,49 @@ impl BreezSdk {
         Ok(self.sdk.delete_lightning_address().await?)
     }
 
+    #[wasm_bindgen(js_name = "deleteLabeledLightningAddress")]
+    pub async fn delete_labeled_lightning_address(
+        &self,
+        request: DeleteLabeledLightningAddressRequest,
+    ) -> WasmResult<()> {
+        Ok(self
+            .sdk
+            .delete_labeled_lightning_address(request.into())
+            .await?)
+    }
+
+    #[wasm_bindgen(js_name = "updateLightningAddressNostrKey")]
+    pub async fn update_lightning_address_nostr_key(
+        &self,
+        request: UpdateLightningAddressNostrKeyRequest,
+    ) -> WasmResult<()> {
+        Ok(self
+            .sdk
+            .update_lightning_address_nostr_key(request.into())
+            .await?)
+    }
+
+    #[wasm_bindgen(js_name = "createPaymentLink")]
+    pub async fn create_payment_link(
+        &self,
+        request: CreatePaymentLinkRequest,
+    ) -> WasmResult<CreatePaymentLinkResponse> {
+        Ok(self.sdk.create_payment_link(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "listPaymentLinks")]
+    pub async fn list_payment_links(
+        &self,
+        request: ListPaymentLinksRequest,
+    ) -> WasmResult<ListPaymentLinksResponse> {
+        Ok(self.sdk.list_payment_links(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "deletePaymentLink")]
+    pub async fn delete_payment_link(&self, request: DeletePaymentLinkRequest) -> WasmResult<()> {
+        Ok(self.sdk.delete_payment_link(request.into()).await?)
+    }
+
     #[wasm_bindgen(js_name = "listFiatCurrencies")]
     pub async fn list_fiat_currencies(&self) -> WasmResult<ListFiatCurrenciesResponse> {
         Ok(self.sdk.list_fiat_currencies().await?.into())
@@ -490,6 +1000,101 @@ impl BreezSdk {
         Ok(self.sdk.list_fiat_rates().await?.into())
     }
 
+    #[wasm_bindgen(js_name = "fetchHistoricalRates")]
+    pub async fn fetch_historical_rates(
+        &self,
+        request: FetchHistoricalRatesRequest,
+    ) -> WasmResult<FetchHistoricalRatesResponse> {
+        Ok(self
+            .sdk
+            .fetch_historical_rates(request.into())
+            .await?
+            .into())
+    }
+
+    #[wasm_bindgen(js_name = "getTokenFiatRates")]
+    pub async fn get_token_fiat_rates(
+        &self,
+        request: GetTokenFiatRatesRequest,
+    ) -> WasmResult<GetTokenFiatRatesResponse> {
+        Ok(self.sdk.get_token_fiat_rates(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "lockExchangeRate")]
+    pub async fn lock_exchange_rate(
+        &self,
+        request: LockExchangeRateRequest,
+    ) -> WasmResult<LockExchangeRateResponse> {
+        Ok(self.sdk.lock_exchange_rate(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "convertFiatAmount")]
+    pub async fn convert_fiat_amount(
+        &self,
+        request: ConvertFiatAmountRequest,
+    ) -> WasmResult<ConvertFiatAmountResponse> {
+        Ok(self.sdk.convert_fiat_amount(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "receiveFiatPayment")]
+    pub async fn receive_fiat_payment(
+        &self,
+        request: ReceiveFiatPaymentRequest,
+    ) -> WasmResult<ReceiveFiatPaymentResponse> {
+        Ok(self.sdk.receive_fiat_payment(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "dumpDiagnostics")]
+    pub async fn dump_diagnostics(&self) -> WasmResult<DumpDiagnosticsResponse> {
+        Ok(self.sdk.dump_diagnostics().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "checkHealth")]
+    pub async fn check_health(&self) -> WasmResult<CheckHealthResponse> {
+        Ok(self.sdk.check_health().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "getServiceStatus")]
+    pub async fn get_service_status(&self) -> WasmResult<ServiceStatusReport> {
+        Ok(self.sdk.get_service_status().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "getReceiveOnboardingInfo")]
+    pub async fn get_receive_onboarding_info(
+        &self,
+    ) -> WasmResult<GetReceiveOnboardingInfoResponse> {
+        Ok(self.sdk.get_receive_onboarding_info().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "startJob")]
+    pub async fn start_job(&self, request: StartJobRequest) -> WasmResult<StartJobResponse> {
+        Ok(self.sdk.start_job(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "getJob")]
+    pub async fn get_job(&self, request: GetJobRequest) -> WasmResult<GetJobResponse> {
+        Ok(self.sdk.get_job(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "listJobs")]
+    pub async fn list_jobs(&self) -> WasmResult<ListJobsResponse> {
+        Ok(self.sdk.list_jobs().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "cancelJob")]
+    pub async fn cancel_job(&self, request: CancelJobRequest) -> WasmResult<()> {
+        Ok(self.sdk.cancel_job(request.into()).await?)
+    }
+
+    #[wasm_bindgen(js_name = "listPlugins")]
+    pub fn list_plugins(&self) -> Vec<PluginInfo> {
+        self.sdk
+            .list_plugins()
+            .into_iter()
+            .map(Into::into)
+            .collect()
+    }
+
     #[wasm_bindgen(js_name = "recommendedFees")]
     pub async fn recommended_fees(&self) -> WasmResult<RecommendedFees> {
         Ok(self.sdk.recommended_fees().await?.into())
@@ -503,6 +1108,18 @@ impl BreezSdk {
         Ok(self.sdk.get_tokens_metadata(request.into()).await?.into())
     }
 
+    #[wasm_bindgen(js_name = "refreshTokensMetadata")]
+    pub async fn refresh_tokens_metadata(
+        &self,
+        request: RefreshTokensMetadataRequest,
+    ) -> WasmResult<RefreshTokensMetadataResponse> {
+        Ok(self
+            .sdk
+            .refresh_tokens_metadata(request.into())
+            .await?
+            .into())
+    }
+
     #[wasm_bindgen(js_name = "signMessage")]
     pub async fn sign_message(
         &self,
@@ -529,6 +1146,16 @@ impl BreezSdk {
         Ok(self.sdk.update_user_settings(request.into()).await?)
     }
 
+    #[wasm_bindgen(js_name = "hideToken")]
+    pub async fn hide_token(&self, request: HideTokenRequest) -> WasmResult<()> {
+        Ok(self.sdk.hide_token(request.into()).await?)
+    }
+
+    #[wasm_bindgen(js_name = "unhideToken")]
+    pub async fn unhide_token(&self, request: UnhideTokenRequest) -> WasmResult<()> {
+        Ok(self.sdk.unhide_token(request.into()).await?)
+    }
+
     #[wasm_bindgen(js_name = "getTokenIssuer")]
     pub fn get_token_issuer(&self) -> TokenIssuer {
         let token_issuer = self.sdk.get_token_issuer();
@@ -545,6 +1172,31 @@ impl BreezSdk {
         Ok(self.sdk.optimize_leaves(request.into()).await?.into())
     }
 
+    #[wasm_bindgen(js_name = "listLeaves")]
+    pub async fn list_leaves(&self) -> WasmResult<ListLeavesResponse> {
+        Ok(self.sdk.list_leaves().await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "setHostConditions")]
+    pub async fn set_host_conditions(&self, conditions: HostConditions) {
+        self.sdk.set_host_conditions(conditions.into()).await;
+    }
+
+    #[wasm_bindgen(js_name = "pauseBackgroundSync")]
+    pub fn pause_background_sync(&self) {
+        self.sdk.pause_background_sync();
+    }
+
+    #[wasm_bindgen(js_name = "resumeBackgroundSync")]
+    pub async fn resume_background_sync(&self) {
+        self.sdk.resume_background_sync().await;
+    }
+
+    #[wasm_bindgen(js_name = "isBackgroundSyncPaused")]
+    pub fn is_background_sync_paused(&self) -> bool {
+        self.sdk.is_background_sync_paused()
+    }
+
     #[wasm_bindgen(js_name = "fetchConversionLimits")]
     pub async fn fetch_conversion_limits(
         &self,
@@ -616,4 +1268,20 @@ impl BreezSdk {
             .map(Into::into)
             .collect())
     }
+
+    #[wasm_bindgen(js_name = "getContactPayments")]
+    pub async fn get_contact_payments(
+        &self,
+        request: GetContactPaymentsRequest,
+    ) -> WasmResult<GetContactPaymentsResponse> {
+        Ok(self.sdk.get_contact_payments(request.into()).await?.into())
+    }
+
+    #[wasm_bindgen(js_name = "suggestContacts")]
+    pub async fn suggest_contacts(
+        &self,
+        request: SuggestContactsRequest,
+    ) -> WasmResult<SuggestContactsResponse> {
+        Ok(self.sdk.suggest_contacts(request.into()).await?.into())
+    }
 }
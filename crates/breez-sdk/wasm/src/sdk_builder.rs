@@ -4,12 +4,18 @@ use crate::{
     error::{WasmError, WasmResult},
     logger::{Logger, WASM_LOGGER},
     models::{
-        Config, Credentials, Network, Seed,
+        Config, Credentials, DuressConfig, Network, Seed,
         chain_service::{BitcoinChainService, ChainApiType, WasmBitcoinChainService},
+        clock::{Clock, WasmClock},
         fiat_service::{FiatService, WasmFiatService},
-        payment_observer::{PaymentObserver, WasmPaymentObserver},
+        input_parser::{InputParser, WasmInputParser},
+        issuer::IssuerApprovalConfig,
+        issuer_approval_signer::{IssuerApprovalSigner, WasmIssuerApprovalSigner},
+        payment_observer::{PaymentObserver, SendApprover, WasmPaymentObserver, WasmSendApprover},
+        plugin::{SdkPlugin, WasmSdkPlugin},
         rest_client::{RestClient, WasmRestClient},
         session_store::{DefaultSessionStore, SessionStore, WasmSessionStore},
+        token_price_service::{TokenPriceService, WasmTokenPriceService},
     },
     persist::{
         Storage, WasmStorage,
@@ -27,7 +33,10 @@ use crate::{
     tree_store::WasmTreeStore,
 };
 use bitcoin::secp256k1::PublicKey;
-use breez_sdk_spark::{PrebuiltBackend, SessionStoreAdapter, StorageBackend, identity_public_key};
+use breez_sdk_spark::{
+    PrebuiltBackend, SessionStoreAdapter, StorageBackend, duress_account_number,
+    identity_public_key,
+};
 use platform_utils::tokio::sync::OnceCell;
 use wasm_bindgen::prelude::*;
 
@@ -221,6 +230,9 @@ pub struct SdkBuilder {
     /// JS MySQL pool supplied via `withSharedContext(ctx_with_pool)`.
     context_mysql_pool: Option<SharedMysqlPool>,
     account_number: Option<u32>,
+    /// Decoy account number of the duress configuration, and whether the
+    /// duress PIN was entered, see `withDuress`
+    duress: Option<(u32, bool)>,
 }
 
 #[wasm_bindgen]
@@ -239,6 +251,7 @@ impl SdkBuilder {
             context_postgres_pool: None,
             context_mysql_pool: None,
             account_number: None,
+            duress: None,
         }
     }
 
@@ -270,6 +283,7 @@ impl SdkBuilder {
             context_postgres_pool: None,
             context_mysql_pool: None,
             account_number: None,
+            duress: None,
         }
     }
 
@@ -305,6 +319,7 @@ impl SdkBuilder {
             context_postgres_pool: None,
             context_mysql_pool: None,
             account_number: None,
+            duress: None,
         }
     }
 
@@ -376,6 +391,19 @@ impl SdkBuilder {
         self
     }
 
+    /// Opens the decoy wallet of the duress configuration if `pin` is its
+    /// duress PIN. Any other PIN opens the real wallet. The decoy account
+    /// number is applied on build, so it takes precedence over
+    /// `withAccountNumber`.
+    #[wasm_bindgen(js_name = "withDuress")]
+    pub fn with_duress(mut self, duress_config: DuressConfig, pin: String) -> Self {
+        let duress_config: breez_sdk_spark::DuressConfig = duress_config.into();
+        let decoy_account_number = duress_config.decoy_account_number;
+        let duress_pin_entered = breez_sdk_spark::is_duress_pin(duress_config, pin);
+        self.duress = Some((decoy_account_number, duress_pin_entered));
+        self
+    }
+
     #[wasm_bindgen(js_name = "withChainService")]
     pub fn with_chain_service(mut self, chain_service: BitcoinChainService) -> Self {
         self.builder = self
@@ -409,6 +437,16 @@ impl SdkBuilder {
         self
     }
 
+    #[wasm_bindgen(js_name = "withTokenPriceService")]
+    pub fn with_token_price_service(mut self, token_price_service: TokenPriceService) -> Self {
+        self.builder = self
+            .builder
+            .with_token_price_service(Arc::new(WasmTokenPriceService {
+                inner: token_price_service,
+            }));
+        self
+    }
+
     #[wasm_bindgen(js_name = "withLnurlClient")]
     pub fn with_lnurl_client(mut self, lnurl_client: RestClient) -> Self {
         self.builder = self.builder.with_lnurl_client(Arc::new(WasmRestClient {
@@ -417,6 +455,14 @@ impl SdkBuilder {
         self
     }
 
+    #[wasm_bindgen(js_name = "withBackupClient")]
+    pub fn with_backup_client(mut self, backup_client: RestClient) -> Self {
+        self.builder = self.builder.with_backup_client(Arc::new(WasmRestClient {
+            inner: backup_client,
+        }));
+        self
+    }
+
     #[wasm_bindgen(js_name = "withPaymentObserver")]
     pub fn with_payment_observer(mut self, payment_observer: PaymentObserver) -> Self {
         self.builder = self
@@ -425,8 +471,75 @@ impl SdkBuilder {
         self
     }
 
+    #[wasm_bindgen(js_name = "withSendApprover")]
+    pub fn with_send_approver(mut self, send_approver: SendApprover) -> Self {
+        self.builder = self
+            .builder
+            .with_send_approver(Arc::new(WasmSendApprover { send_approver }));
+        self
+    }
+
+    #[wasm_bindgen(js_name = "withIssuerApprover")]
+    pub fn with_issuer_approver(
+        mut self,
+        approval_signer: IssuerApprovalSigner,
+        config: IssuerApprovalConfig,
+    ) -> Self {
+        self.builder = self.builder.with_issuer_approver(
+            Arc::new(WasmIssuerApprovalSigner { approval_signer }),
+            config.into(),
+        );
+        self
+    }
+
+    #[wasm_bindgen(js_name = "withPlugin")]
+    pub fn with_plugin(mut self, plugin: SdkPlugin) -> WasmResult<Self> {
+        self.builder = self
+            .builder
+            .with_plugin(Arc::new(WasmSdkPlugin::new(plugin)?));
+        Ok(self)
+    }
+
+    #[wasm_bindgen(js_name = "withInputParser")]
+    pub fn with_input_parser(mut self, parser: InputParser) -> WasmResult<Self> {
+        self.builder = self
+            .builder
+            .with_input_parser(Arc::new(WasmInputParser::new(parser)?));
+        Ok(self)
+    }
+
+    #[wasm_bindgen(js_name = "withClock")]
+    pub fn with_clock(mut self, clock: Clock) -> Self {
+        self.builder = self.builder.with_clock(Arc::new(WasmClock { clock }));
+        self
+    }
+
+    #[wasm_bindgen(js_name = "withNostrSyncStorage")]
+    pub fn with_nostr_sync_storage(mut self, relays: Vec<String>) -> Self {
+        self.builder = self.builder.with_nostr_sync_storage(relays);
+        self
+    }
+
+    #[wasm_bindgen(js_name = "withBackgroundSyncPaused")]
+    pub fn with_background_sync_paused(mut self) -> Self {
+        self.builder = self.builder.with_background_sync_paused();
+        self
+    }
+
     #[wasm_bindgen(js_name = "build")]
     pub async fn build(mut self) -> WasmResult<BreezSdk> {
+        if let Some((decoy_account_number, duress_pin_entered)) = self.duress {
+            self.account_number = duress_account_number(
+                self.account_number,
+                decoy_account_number,
+                duress_pin_entered,
+                self.network,
+            )?;
+            if let Some(account_number) = self.account_number {
+                self.builder = self.builder.with_account_number(account_number);
+            }
+        }
+
         // Derive the tenant identity from the seed. The JS-side stores use it
         // to scope every read/write by `user_id`.
         let identity_bytes = identity_public_key(
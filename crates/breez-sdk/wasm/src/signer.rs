@@ -1587,3 +1587,48 @@ impl DefaultCpfpSigner {
             .map_err(|e| JsValue::from_str(&format!("{e:?}")))
     }
 }
+
+#[wasm_bindgen(typescript_custom_section)]
+const PSBT_SIGNER_INTERFACE: &'static str = r#"export interface PsbtSigner {
+    signPsbt(psbtBase64: string): Promise<string>;
+}"#;
+
+#[wasm_bindgen]
+extern "C" {
+    #[wasm_bindgen(typescript_type = "PsbtSigner")]
+    pub type JsPsbtSigner;
+
+    #[wasm_bindgen(structural, method, js_name = "signPsbt", catch)]
+    pub fn sign_psbt(this: &JsPsbtSigner, psbt_base64: String) -> Result<Promise, JsValue>;
+}
+
+pub struct WasmPsbtSigner {
+    inner: JsPsbtSigner,
+}
+
+// Wasm runs single-threaded, so the non-Send JS handle is safe to mark Send+Sync.
+unsafe impl Send for WasmPsbtSigner {}
+unsafe impl Sync for WasmPsbtSigner {}
+
+impl WasmPsbtSigner {
+    pub fn new(inner: JsPsbtSigner) -> Self {
+        Self { inner }
+    }
+}
+
+#[macros::async_trait]
+impl breez_sdk_spark::signer::PsbtSigner for WasmPsbtSigner {
+    async fn sign_psbt(&self, psbt_base64: String) -> Result<String, breez_sdk_spark::SignerError> {
+        let promise = self
+            .inner
+            .sign_psbt(psbt_base64)
+            .map_err(|e| breez_sdk_spark::SignerError::Generic(format!("JS error: {e:?}")))?;
+        let future = wasm_bindgen_futures::JsFuture::from(promise);
+        let result = future
+            .await
+            .map_err(|e| breez_sdk_spark::SignerError::Generic(format!("JS error: {e:?}")))?;
+        result.as_string().ok_or_else(|| {
+            breez_sdk_spark::SignerError::Generic("Signed PSBT must be a base64 string".to_string())
+        })
+    }
+}
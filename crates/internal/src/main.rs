@@ -138,6 +138,7 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
                         spark_wallet::WalletEvent::TransferClaimStarting(transfer) => info!("Transfer claim starting: {}", transfer.id),
                         spark_wallet::WalletEvent::TokenTransaction(transaction) => info!("Token transaction: {}", transaction.hash),
                         spark_wallet::WalletEvent::AutoOptimization(event) => info!("Auto-optimization event: {:?}", event),
+                        spark_wallet::WalletEvent::ClaimQueueChanged(queue_depth) => info!("Claim queue depth: {queue_depth}"),
                     }
                 }
                 else => warn!("Event stream closed."),
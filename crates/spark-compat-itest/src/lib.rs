@@ -100,6 +100,7 @@ macro_rules! version_module {
                     },
                     self_payment_allowed: false,
                     max_concurrent_claims: 1,
+                    max_claims_per_second: None,
                 })
             }
 
@@ -93,6 +93,7 @@ impl TestFixtures {
             },
             self_payment_allowed: false,
             max_concurrent_claims: 1,
+            max_claims_per_second: None,
         })
     }
 }
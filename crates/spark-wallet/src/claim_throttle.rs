@@ -0,0 +1,117 @@
+use std::collections::VecDeque;
+
+use platform_utils::time::{Duration, Instant};
+use platform_utils::tokio;
+use spark::services::Transfer;
+use tokio::sync::{Mutex, Notify};
+
+const WINDOW: Duration = Duration::from_secs(1);
+
+/// Caps the number of incoming transfers claimed per second. Transfers
+/// received over the cap are deferred and claimed in order by a background
+/// task, instead of being claimed all at once.
+pub(crate) struct ClaimThrottle {
+    max_claims_per_second: usize,
+    claim_times: Mutex<VecDeque<Instant>>,
+    deferred: Mutex<VecDeque<Transfer>>,
+    deferred_notify: Notify,
+}
+
+impl ClaimThrottle {
+    pub(crate) fn new(max_claims_per_second: u32) -> Self {
+        Self {
+            max_claims_per_second: max_claims_per_second as usize,
+            claim_times: Mutex::new(VecDeque::new()),
+            deferred: Mutex::new(VecDeque::new()),
+            deferred_notify: Notify::new(),
+        }
+    }
+
+    /// Takes a claim slot if one is free in the current window.
+    pub(crate) async fn try_acquire(&self) -> bool {
+        let mut claim_times = self.claim_times.lock().await;
+        take_slot(&mut claim_times, Instant::now(), self.max_claims_per_second).is_none()
+    }
+
+    /// Waits for a claim slot to be free.
+    pub(crate) async fn acquire(&self) {
+        loop {
+            let wait = {
+                let mut claim_times = self.claim_times.lock().await;
+                match take_slot(&mut claim_times, Instant::now(), self.max_claims_per_second) {
+                    None => return,
+                    Some(wait) => wait,
+                }
+            };
+            tokio::time::sleep(wait).await;
+        }
+    }
+
+    /// Queues a transfer to be claimed later and returns the queue depth.
+    pub(crate) async fn defer(&self, transfer: Transfer) -> u32 {
+        let mut deferred = self.deferred.lock().await;
+        deferred.push_back(transfer);
+        self.deferred_notify.notify_one();
+        deferred.len() as u32
+    }
+
+    /// Returns the next deferred transfer along with the remaining queue
+    /// depth.
+    pub(crate) async fn next_deferred(&self) -> Option<(Transfer, u32)> {
+        let mut deferred = self.deferred.lock().await;
+        let transfer = deferred.pop_front()?;
+        Some((transfer, deferred.len() as u32))
+    }
+
+    /// Waits until a transfer is deferred.
+    pub(crate) async fn deferred_notified(&self) {
+        self.deferred_notify.notified().await;
+    }
+
+    pub(crate) async fn queue_depth(&self) -> u32 {
+        self.deferred.lock().await.len() as u32
+    }
+}
+
+/// Records a claim at `now` if fewer than `max` claims happened in the
+/// preceding window. Otherwise returns how long to wait for a free slot.
+fn take_slot(claim_times: &mut VecDeque<Instant>, now: Instant, max: usize) -> Option<Duration> {
+    while let Some(oldest) = claim_times.front() {
+        if now.duration_since(*oldest) >= WINDOW {
+            claim_times.pop_front();
+        } else {
+            break;
+        }
+    }
+    if claim_times.len() < max {
+        claim_times.push_back(now);
+        return None;
+    }
+    claim_times
+        .front()
+        .map(|oldest| WINDOW.saturating_sub(now.duration_since(*oldest)))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn take_slot_caps_claims_per_window() {
+        let start = Instant::now();
+        let mut claim_times = VecDeque::new();
+
+        assert_eq!(take_slot(&mut claim_times, start, 2), None);
+        assert_eq!(
+            take_slot(&mut claim_times, start + Duration::from_millis(100), 2),
+            None
+        );
+        assert_eq!(
+            take_slot(&mut claim_times, start + Duration::from_millis(400), 2),
+            Some(Duration::from_millis(600))
+        );
+        // The first claim left the window
+        assert_eq!(take_slot(&mut claim_times, start + WINDOW, 2), None);
+        assert_eq!(claim_times.len(), 2);
+    }
+}
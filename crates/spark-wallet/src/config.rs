@@ -30,6 +30,12 @@ pub struct SparkWalletConfig {
     /// Default is 1 (sequential claiming). Increase for server environments
     /// with high incoming payment volume to improve throughput.
     pub max_concurrent_claims: u32,
+    /// Maximum number of incoming transfers claimed per second.
+    ///
+    /// Transfers received over the cap are queued and claimed as the rate
+    /// allows, to keep bursts of incoming payments from overwhelming
+    /// low-power devices. Default is `None` (no cap).
+    pub max_claims_per_second: Option<u32>,
 }
 
 impl SparkWalletConfig {
@@ -46,6 +52,12 @@ impl SparkWalletConfig {
 
         self.token_outputs_optimization_options.validate()?;
 
+        if self.max_claims_per_second == Some(0) {
+            return Err(SparkWalletError::ValidationError(
+                "max_claims_per_second must be greater than 0".to_string(),
+            ));
+        }
+
         Ok(())
     }
 
@@ -72,6 +84,7 @@ impl SparkWalletConfig {
                 },
                 self_payment_allowed: false,
                 max_concurrent_claims: 1,
+                max_claims_per_second: None,
             },
             _ => Self {
                 network,
@@ -94,6 +107,7 @@ impl SparkWalletConfig {
                 },
                 self_payment_allowed: false,
                 max_concurrent_claims: 1,
+                max_claims_per_second: None,
             },
         }
     }
@@ -1,3 +1,4 @@
+mod claim_throttle;
 mod config;
 mod error;
 mod event;
@@ -37,6 +37,9 @@ pub enum WalletEvent {
     TokenTransaction(TokenTransaction),
     /// Auto-optimization lifecycle event.
     AutoOptimization(AutoOptimizationEvent),
+    /// The number of incoming transfers waiting to be claimed changed, see
+    /// [`crate::SparkWalletConfig::max_claims_per_second`].
+    ClaimQueueChanged(u32),
 }
 
 impl Display for WalletEvent {
@@ -212,6 +215,13 @@ impl From<TransferLeaf> for WalletTransferLeaf {
 pub struct WalletLeaves {
     pub available: Vec<WalletLeaf>,
     pub available_missing_from_operators: Vec<WalletLeaf>,
+    /// Leaves that are not spendable, e.g. locked by an incoming or outgoing
+    /// transfer.
+    pub not_available: Vec<WalletLeaf>,
+    /// Leaves reserved by a payment in progress.
+    pub reserved_for_payment: Vec<WalletLeaf>,
+    /// Leaves reserved by a swap in progress.
+    pub reserved_for_swap: Vec<WalletLeaf>,
 }
 
 impl From<Leaves> for WalletLeaves {
@@ -223,10 +233,31 @@ impl From<Leaves> for WalletLeaves {
                 .into_iter()
                 .map(Into::into)
                 .collect(),
+            not_available: value.not_available.into_iter().map(Into::into).collect(),
+            reserved_for_payment: value
+                .reserved_for_payment
+                .into_iter()
+                .map(Into::into)
+                .collect(),
+            reserved_for_swap: value
+                .reserved_for_swap
+                .into_iter()
+                .map(Into::into)
+                .collect(),
         }
     }
 }
 
+/// The leaves a send would spend, as selected by
+/// [`SparkWallet::simulate_leaf_selection`](crate::SparkWallet::simulate_leaf_selection).
+#[derive(Clone, Debug, Deserialize, Serialize, Eq, PartialEq)]
+pub struct SimulatedLeafSelection {
+    pub leaves: Vec<WalletLeaf>,
+    /// Whether the leaves add up to more than the target, so they must be
+    /// swapped for exact denominations before sending.
+    pub requires_swap: bool,
+}
+
 impl WalletLeaves {
     pub fn available_balance(&self) -> u64 {
         self.available.iter().map(|leaf| leaf.value).sum()
@@ -63,8 +63,11 @@ use crate::{
     FulfillSparkInvoiceResult, ListTokenTransactionsRequest, ListTransfersRequest, PreimageRequest,
     QuerySparkInvoiceResult, TokenBalance, WalletEvent, WalletLeaves, WalletSettings,
     WithdrawInnerParams,
+    claim_throttle::ClaimThrottle,
     event::EventManager,
-    model::{PayLightningInvoiceResult, WalletInfo, WalletLeaf, WalletTransfer},
+    model::{
+        PayLightningInvoiceResult, SimulatedLeafSelection, WalletInfo, WalletLeaf, WalletTransfer,
+    },
     unilateral_exit::{CpfpChangeInput, ExitLeafSelection, PreparedUnilateralExit, RefundOutput},
 };
 
@@ -272,6 +275,9 @@ pub struct SparkWallet {
     /// lifetime" is what we want here regardless of outcome — subsequent
     /// staleness is handled by the periodic + post-payment sync.
     select_leaves_refresh: tokio::sync::OnceCell<()>,
+    /// Caps the rate of incoming transfer claims, when
+    /// [`SparkWalletConfig::max_claims_per_second`] is set.
+    claim_throttle: Option<Arc<ClaimThrottle>>,
 }
 
 impl SparkWallet {
@@ -449,6 +455,10 @@ impl SparkWallet {
             }
         };
 
+        let claim_throttle = config
+            .max_claims_per_second
+            .map(|max_claims_per_second| Arc::new(ClaimThrottle::new(max_claims_per_second)));
+
         Ok(Self {
             cancel,
             cancellation_token: tokio::sync::Mutex::new(Some(cancellation_token)),
@@ -470,6 +480,7 @@ impl SparkWallet {
             htlc_service,
             leaf_optimizer,
             select_leaves_refresh: tokio::sync::OnceCell::new(),
+            claim_throttle,
         })
     }
 }
@@ -484,6 +495,41 @@ impl SparkWallet {
         Ok(leaves.into())
     }
 
+    /// Selects the leaves a send of `amount_sat` plus `fee_sat` would spend,
+    /// without reserving them. Nothing is sent, so the selection may differ
+    /// from the one made by the actual send if the leaves change in between.
+    pub async fn simulate_leaf_selection(
+        &self,
+        amount_sat: u64,
+        fee_sat: Option<u64>,
+    ) -> Result<SimulatedLeafSelection, SparkWalletError> {
+        use spark::tree::{TreeServiceError, select_leaves_by_minimum_amount};
+
+        let leaves = self.tree_service.list_leaves().await?.available;
+        let target_amounts = TargetAmounts::new_amount_and_fee(amount_sat, fee_sat);
+        match select_leaves_by_target_amounts(&leaves, Some(&target_amounts)) {
+            Ok(target_leaves) => Ok(SimulatedLeafSelection {
+                leaves: target_leaves
+                    .amount_leaves
+                    .into_iter()
+                    .chain(target_leaves.fee_leaves.unwrap_or_default())
+                    .map(Into::into)
+                    .collect(),
+                requires_swap: false,
+            }),
+            Err(TreeServiceError::UnselectableAmount) => {
+                let selected =
+                    select_leaves_by_minimum_amount(&leaves, target_amounts.total_sats())?
+                        .ok_or(TreeServiceError::InsufficientFunds)?;
+                Ok(SimulatedLeafSelection {
+                    leaves: selected.into_iter().map(Into::into).collect(),
+                    requires_swap: true,
+                })
+            }
+            Err(e) => Err(e.into()),
+        }
+    }
+
     /// Starts leaf optimization if auto-optimization is enabled.
     async fn maybe_start_optimization(&self) {
         if self.config.leaf_auto_optimize_enabled {
@@ -898,16 +944,7 @@ impl SparkWallet {
         transfer_id: Option<TransferId>,
         spark_invoice: Option<String>,
     ) -> Result<WalletTransfer, SparkWalletError> {
-        // validate receiver address and get its pubkey
-        if self.config.network != receiver_address.network {
-            return Err(SparkWalletError::InvalidNetwork);
-        }
-
-        if !self.config.self_payment_allowed
-            && receiver_address.identity_public_key == self.identity_public_key
-        {
-            return Err(SparkWalletError::SelfPaymentNotAllowed);
-        }
+        self.validate_receiver_address(receiver_address)?;
 
         // Transfer leaves with retry logic for concurrent leaf spending
         let target_amounts = TargetAmounts::new_amount_and_fee(amount_sat, None);
@@ -934,6 +971,74 @@ impl SparkWallet {
         ))
     }
 
+    /// Sends exactly the given leaves to another Spark user, instead of
+    /// selecting them. The transferred amount is the sum of their values.
+    pub async fn transfer_leaves(
+        &self,
+        leaf_ids: &[TreeNodeId],
+        receiver_address: &SparkAddress,
+        transfer_id: Option<TransferId>,
+    ) -> Result<WalletTransfer, SparkWalletError> {
+        if receiver_address.is_invoice() {
+            return Err(SparkWalletError::Generic(
+                "Receiver address is a Spark invoice. Use `fulfill_spark_invoice` instead."
+                    .to_string(),
+            ));
+        }
+        self.validate_receiver_address(receiver_address)?;
+
+        let reservation = self
+            .tree_service
+            .reserve_leaves_by_ids(leaf_ids, ReservationPurpose::Payment)
+            .await?;
+        let transfer = with_reserved_leaves(
+            self.tree_service.as_ref(),
+            self.transfer_service.transfer_leaves_to(
+                reservation.leaves.clone(),
+                &receiver_address.identity_public_key,
+                transfer_id,
+                None,
+            ),
+            &reservation,
+        )
+        .await?;
+
+        self.maybe_start_optimization().await;
+
+        Ok(WalletTransfer::from_transfer(
+            transfer,
+            None,
+            None,
+            self.identity_public_key,
+            self.config.service_provider_config.identity_public_key,
+        ))
+    }
+
+    fn validate_receiver_address(
+        &self,
+        receiver_address: &SparkAddress,
+    ) -> Result<(), SparkWalletError> {
+        if self.config.network != receiver_address.network {
+            return Err(SparkWalletError::InvalidNetwork);
+        }
+
+        if !self.config.self_payment_allowed
+            && receiver_address.identity_public_key == self.identity_public_key
+        {
+            return Err(SparkWalletError::SelfPaymentNotAllowed);
+        }
+        Ok(())
+    }
+
+    /// Returns the number of incoming transfers waiting to be claimed because
+    /// [`SparkWalletConfig::max_claims_per_second`] was reached.
+    pub async fn claim_queue_depth(&self) -> u32 {
+        match &self.claim_throttle {
+            Some(claim_throttle) => claim_throttle.queue_depth().await,
+            None => 0,
+        }
+    }
+
     /// Claims all pending transfers.
     pub async fn claim_pending_transfers(&self) -> Result<Vec<WalletTransfer>, SparkWalletError> {
         let transfers = claim_pending_transfers(
@@ -943,6 +1048,7 @@ impl SparkWallet {
             &self.htlc_service,
             &self.ssp_client,
             self.config.max_concurrent_claims,
+            self.claim_throttle.as_deref(),
         )
         .await?;
 
@@ -1085,6 +1191,38 @@ impl SparkWallet {
             .collect()
     }
 
+    /// Returns the HTLC of an outgoing transfer, with its current preimage
+    /// request status, or `None` if the transfer is not an HTLC we sent.
+    pub async fn get_sent_htlc_transfer(
+        &self,
+        transfer_id: &TransferId,
+    ) -> Result<Option<WalletTransfer>, SparkWalletError> {
+        let htlc = self
+            .htlc_service
+            .query_htlc(
+                QueryHtlcFilter {
+                    identity_public_key: self.identity_public_key,
+                    status: None,
+                    transfer_ids: vec![transfer_id.to_string()],
+                    payment_hashes: Vec::new(),
+                    match_role: PreimageRequestRole::Sender,
+                },
+                None,
+            )
+            .await?
+            .items
+            .into_iter()
+            .next();
+        htlc.map(|h| {
+            WalletTransfer::from_preimage_request_with_transfer(
+                h,
+                self.identity_public_key,
+                self.config.service_provider_config.identity_public_key,
+            )
+        })
+        .transpose()
+    }
+
     pub fn get_info(&self) -> WalletInfo {
         WalletInfo {
             identity_public_key: self.identity_public_key,
@@ -1266,6 +1404,13 @@ impl SparkWallet {
         Ok(())
     }
 
+    /// Refreshes only the token outputs, without the leaves and transfers
+    /// synced by [`SparkWallet::sync`].
+    pub async fn sync_tokens(&self) -> Result<(), SparkWalletError> {
+        self.token_output_service.refresh_tokens_outputs().await?;
+        Ok(())
+    }
+
     pub async fn withdraw(
         &self,
         withdrawal_address: &str,
@@ -1274,16 +1419,7 @@ impl SparkWallet {
         fee_quote: CoopExitFeeQuote,
         transfer_id: Option<TransferId>,
     ) -> Result<WalletTransfer, SparkWalletError> {
-        // Validate withdrawal address
-        let withdrawal_address = withdrawal_address
-            .parse::<Address<NetworkUnchecked>>()
-            .map_err(|_| {
-                SparkWalletError::InvalidAddress(format!(
-                    "Invalid withdrawal address: {withdrawal_address}"
-                ))
-            })?
-            .require_network(self.config.network.into())
-            .map_err(|_| SparkWalletError::InvalidNetwork)?;
+        let withdrawal_address = self.parse_withdrawal_address(withdrawal_address)?;
 
         // Calculate the fee based on the exit speed
         let fee_sats = fee_quote.fee_sats(&exit_speed);
@@ -1319,6 +1455,65 @@ impl SparkWallet {
         .await
     }
 
+    /// Withdraws exactly the given leaves, instead of selecting them. The fee
+    /// is deducted from the sum of their values.
+    pub async fn withdraw_leaves(
+        &self,
+        withdrawal_address: &str,
+        leaf_ids: &[TreeNodeId],
+        exit_speed: ExitSpeed,
+        fee_quote: CoopExitFeeQuote,
+        transfer_id: Option<TransferId>,
+    ) -> Result<WalletTransfer, SparkWalletError> {
+        let withdrawal_address = self.parse_withdrawal_address(withdrawal_address)?;
+        let fee_sats = fee_quote.fee_sats(&exit_speed);
+
+        let reservation = self
+            .tree_service
+            .reserve_leaves_by_ids(leaf_ids, ReservationPurpose::Payment)
+            .await?;
+        let transfer = with_reserved_leaves(
+            self.tree_service.as_ref(),
+            self.withdraw_inner(WithdrawInnerParams {
+                address: withdrawal_address,
+                exit_speed,
+                leaves_reservation: &reservation,
+                target_amounts: None,
+                fee_sats,
+                fee_quote_id: fee_quote.id,
+                transfer_id,
+            }),
+            &reservation,
+        )
+        .await?;
+
+        self.maybe_start_optimization().await;
+
+        create_transfer(
+            transfer,
+            &self.ssp_client,
+            &self.htlc_service,
+            self.identity_public_key,
+            self.config.service_provider_config.identity_public_key,
+        )
+        .await
+    }
+
+    fn parse_withdrawal_address(
+        &self,
+        withdrawal_address: &str,
+    ) -> Result<Address, SparkWalletError> {
+        withdrawal_address
+            .parse::<Address<NetworkUnchecked>>()
+            .map_err(|_| {
+                SparkWalletError::InvalidAddress(format!(
+                    "Invalid withdrawal address: {withdrawal_address}"
+                ))
+            })?
+            .require_network(self.config.network.into())
+            .map_err(|_| SparkWalletError::InvalidNetwork)
+    }
+
     async fn withdraw_inner(
         &self,
         params: WithdrawInnerParams<'_>,
@@ -1704,6 +1899,7 @@ impl SparkWallet {
                     Arc::clone(&self.token_service),
                     self.config.token_outputs_optimization_options.clone(),
                     self.config.max_concurrent_claims,
+                    self.claim_throttle.clone(),
                 ));
                 background_processor
                     .run_background_tasks(cancellation_token)
@@ -1831,6 +2027,18 @@ impl SparkWallet {
             .map_err(Into::into)
     }
 
+    /// Queries the metadata of the given tokens from the operators, also for
+    /// tokens whose metadata is already known locally.
+    pub async fn refresh_tokens_metadata(
+        &self,
+        token_identifiers: &[&str],
+    ) -> Result<Vec<TokenMetadata>, SparkWalletError> {
+        self.token_service
+            .refresh_tokens_metadata(token_identifiers)
+            .await
+            .map_err(Into::into)
+    }
+
     pub async fn get_issuer_token_balance(&self) -> Result<TokenBalance, SparkWalletError> {
         let token_identifier = self.get_issuer_token_metadata().await?.identifier;
         let token_balances = self.get_token_balances().await?;
@@ -1847,6 +2055,19 @@ impl SparkWallet {
         Ok(self.token_service.get_issuer_token_metadata().await?)
     }
 
+    /// Returns the balance of each holder of a token, keyed by the holder's
+    /// identity public key, from the unspent token outputs known to the
+    /// operators.
+    pub async fn query_token_holder_balances(
+        &self,
+        token_identifier: &str,
+    ) -> Result<HashMap<PublicKey, u128>, SparkWalletError> {
+        Ok(self
+            .token_service
+            .query_token_holder_balances(token_identifier)
+            .await?)
+    }
+
     pub async fn create_issuer_token(
         &self,
         name: &str,
@@ -2211,6 +2432,7 @@ async fn claim_pending_transfers(
     htlc_service: &Arc<HtlcService>,
     ssp_client: &Arc<ServiceProvider>,
     max_concurrent_claims: u32,
+    claim_throttle: Option<&ClaimThrottle>,
 ) -> Result<Vec<WalletTransfer>, SparkWalletError> {
     debug!("Claiming all pending transfers");
     let transfers = transfer_service
@@ -2261,6 +2483,9 @@ async fn claim_pending_transfers(
             let transfer_service = Arc::clone(transfer_service);
             let tree_service = Arc::clone(tree_service);
             async move {
+                if let Some(claim_throttle) = claim_throttle {
+                    claim_throttle.acquire().await;
+                }
                 debug!("Claiming transfer {}: {}", i + 1, transfer.id);
                 let result = claim_transfer(&transfer, &transfer_service, &tree_service).await;
                 match &result {
@@ -2450,6 +2675,7 @@ struct BackgroundProcessor {
     token_service: Arc<TokenService>,
     token_outputs_optimization_options: TokenOutputsOptimizationOptions,
     max_concurrent_claims: u32,
+    claim_throttle: Option<Arc<ClaimThrottle>>,
 }
 
 impl BackgroundProcessor {
@@ -2468,6 +2694,7 @@ impl BackgroundProcessor {
         token_service: Arc<TokenService>,
         token_outputs_optimization_options: TokenOutputsOptimizationOptions,
         max_concurrent_claims: u32,
+        claim_throttle: Option<Arc<ClaimThrottle>>,
     ) -> Self {
         Self {
             operator_pool,
@@ -2483,6 +2710,7 @@ impl BackgroundProcessor {
             token_service,
             token_outputs_optimization_options,
             max_concurrent_claims,
+            claim_throttle,
         }
     }
 
@@ -2552,6 +2780,20 @@ impl BackgroundProcessor {
             );
         }
 
+        if let Some(claim_throttle) = self.claim_throttle.clone() {
+            let cloned_self = Arc::clone(self);
+            let cancellation_token_clone = cancellation_token.clone();
+            let span = tracing::Span::current();
+            tokio::spawn(
+                async move {
+                    cloned_self
+                        .process_deferred_claims(&claim_throttle, cancellation_token_clone)
+                        .await;
+                }
+                .instrument(span),
+            );
+        }
+
         self.process_events(event_stream).await;
     }
 
@@ -2637,6 +2879,48 @@ impl BackgroundProcessor {
             return Ok(());
         }
 
+        if let Some(claim_throttle) = &self.claim_throttle {
+            let queue_depth = claim_throttle.queue_depth().await;
+            if queue_depth > 0 || !claim_throttle.try_acquire().await {
+                let queue_depth = claim_throttle.defer(transfer).await;
+                debug!("Claim rate reached, deferred transfer ({queue_depth} queued)");
+                self.event_manager
+                    .notify_listeners(WalletEvent::ClaimQueueChanged(queue_depth));
+                return Ok(());
+            }
+        }
+        self.claim_transfer_from_event(transfer).await
+    }
+
+    /// Claims the transfers deferred by the claim throttle, as the claim
+    /// rate allows.
+    async fn process_deferred_claims(
+        &self,
+        claim_throttle: &ClaimThrottle,
+        mut cancellation_token: watch::Receiver<()>,
+    ) {
+        loop {
+            tokio::select! {
+                _ = cancellation_token.changed() => {
+                    info!("Deferred claims processing cancelled");
+                    return;
+                }
+                () = claim_throttle.deferred_notified() => {}
+            }
+
+            while let Some((transfer, queue_depth)) = claim_throttle.next_deferred().await {
+                claim_throttle.acquire().await;
+                self.event_manager
+                    .notify_listeners(WalletEvent::ClaimQueueChanged(queue_depth));
+                let transfer_id = transfer.id.clone();
+                if let Err(e) = self.claim_transfer_from_event(transfer).await {
+                    warn!("Failed to claim deferred transfer {transfer_id}: {e:?}");
+                }
+            }
+        }
+    }
+
+    async fn claim_transfer_from_event(&self, transfer: Transfer) -> Result<(), SparkWalletError> {
         // get the ssp transfer details, if it fails just use None
         // Internal transfers will not have an SSP entry so just skip it
         let ssp_transfer = if transfer.transfer_type == spark::services::TransferType::Transfer {
@@ -2713,6 +2997,7 @@ impl BackgroundProcessor {
             &self.htlc_service,
             &self.ssp_client,
             self.max_concurrent_claims,
+            self.claim_throttle.as_deref(),
         )
         .await
         {
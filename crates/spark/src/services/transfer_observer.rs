@@ -14,6 +14,9 @@ pub enum TransferObserverError {
     ServiceConnectivity(String),
     #[error("Error: {0}")]
     Generic(String),
+    /// The transfer was rejected by the observer, with the reason given.
+    #[error("Rejected: {0}")]
+    Rejected(String),
 }
 
 #[macros::async_trait]
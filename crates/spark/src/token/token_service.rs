@@ -1,4 +1,4 @@
-use std::{ops::Not, sync::Arc};
+use std::{collections::HashMap, ops::Not, sync::Arc};
 
 use bitcoin::{
     bech32::{self, Bech32m, Hrp},
@@ -330,6 +330,18 @@ impl TokenService {
         Ok(all_metadata)
     }
 
+    /// Queries the metadata of the given tokens from the operators, bypassing
+    /// the metadata known from the local token outputs.
+    pub async fn refresh_tokens_metadata(
+        &self,
+        token_identifiers: &[&str],
+    ) -> Result<Vec<TokenMetadata>, ServiceError> {
+        if token_identifiers.is_empty() {
+            return Ok(Vec::new());
+        }
+        self.query_tokens_metadata(token_identifiers, &[]).await
+    }
+
     async fn query_tokens_metadata(
         &self,
         token_identifiers: &[&str],
@@ -452,6 +464,33 @@ impl TokenService {
         Ok(transactions)
     }
 
+    /// Returns the balance of each holder of a token, keyed by the holder's
+    /// identity public key, from the unspent token outputs known to the
+    /// operators.
+    pub async fn query_token_holder_balances(
+        &self,
+        token_id: &str,
+    ) -> Result<HashMap<PublicKey, u128>, ServiceError> {
+        let token_identifier = bech32m_decode_token_id(token_id, Some(self.network))?;
+        let outputs = self
+            .operator_pool
+            .get_coordinator()
+            .client
+            .query_all_token_outputs(rpc::QueryAllTokenOutputsRequest {
+                token_identifiers: vec![token_identifier],
+                network: self.network.to_proto_network().into(),
+                ..Default::default()
+            })
+            .await?;
+        let mut holders: HashMap<PublicKey, u128> = HashMap::new();
+        for output in outputs {
+            let output = TokenOutputWithPrevOut::try_from((output, self.network))?.output;
+            let balance = holders.entry(output.owner_public_key).or_default();
+            *balance = balance.saturating_add(output.token_amount);
+        }
+        Ok(holders)
+    }
+
     /// Queries token transactions by their hashes.
     ///
     /// This method uses the `QueryType::ByTxHash` variant which is limited to 100 hashes
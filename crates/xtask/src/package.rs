@@ -112,6 +112,7 @@ fn package_wasm_cmd(wasm_package: WasmPackages) -> Result<()> {
             create_nodejs_esm_wrapper(&pkg_dir)?;
             package_wasm_target(&wasm_crate_dir, &pkg_dir, "web", &clang_env)?;
             create_ssr_entry_point(&pkg_dir)?;
+            create_worker_entry_point(&wasm_crate_dir, &pkg_dir)?;
         }
         WasmPackages::Bundle => {
             println!("Packaging Bundle WASM target");
@@ -130,6 +131,7 @@ fn package_wasm_cmd(wasm_package: WasmPackages) -> Result<()> {
             println!("Packaging Web WASM target");
             package_wasm_target(&wasm_crate_dir, &pkg_dir, "web", &clang_env)?;
             create_ssr_entry_point(&pkg_dir)?;
+            create_worker_entry_point(&wasm_crate_dir, &pkg_dir)?;
         }
     }
 
@@ -377,6 +379,202 @@ export default function init(wasmInput?: any): Promise<void>;
     Ok(())
 }
 
+/// Static methods and constructor of an exported class.
+struct WasmClassMembers {
+    name: String,
+    static_methods: Vec<String>,
+    constructible: bool,
+}
+
+/// Parse the static methods and public constructors of the classes exported
+/// by a wasm-bindgen generated `.d.ts` file.
+///
+/// Recognises these line-level patterns inside an `export class NAME {` block:
+///   `  static NAME(`
+///   `  constructor(` (wasm-bindgen emits `private constructor();` otherwise)
+fn parse_wasm_class_members(dts_path: &Path) -> Result<Vec<WasmClassMembers>> {
+    let content = fs::read_to_string(dts_path)
+        .with_context(|| format!("Failed to read {}", dts_path.display()))?;
+
+    let mut classes: Vec<WasmClassMembers> = Vec::new();
+    let mut in_class = false;
+
+    for line in content.lines() {
+        if let Some(rest) = line.strip_prefix("export class ") {
+            if let Some(name) = rest.split_whitespace().next()
+                && !name.is_empty()
+            {
+                classes.push(WasmClassMembers {
+                    name: name.to_string(),
+                    static_methods: Vec::new(),
+                    constructible: false,
+                });
+                in_class = true;
+            }
+        } else if line.starts_with('}') {
+            in_class = false;
+        } else if in_class && let Some(class) = classes.last_mut() {
+            let member = line.trim_start();
+            if let Some(rest) = member.strip_prefix("static ") {
+                if let Some(name) = rest
+                    .split(|c: char| !c.is_alphanumeric() && c != '_')
+                    .next()
+                    && !name.is_empty()
+                {
+                    class.static_methods.push(name.to_string());
+                }
+            } else if member.starts_with("constructor(") {
+                class.constructible = true;
+            }
+        }
+    }
+
+    Ok(classes)
+}
+
+/// Generate the web worker entry point at `pkg_dir/web/worker/`.
+///
+/// The SDK is loaded in a dedicated worker so that cryptography and sync do
+/// not block the main thread. The generated module mirrors the exports of
+/// the web entry point, forwarding every call to the worker through the
+/// message channel client copied from `js/web-worker`.
+fn create_worker_entry_point(crate_dir: &Path, pkg_dir: &Path) -> Result<()> {
+    let src_dir = crate_dir.join("js/web-worker");
+    let worker_dir = pkg_dir.join("web/worker");
+    fs::create_dir_all(&worker_dir)?;
+
+    let files_to_copy = ["worker.js", "client.js", "client.d.ts"];
+    for file_name in files_to_copy {
+        let src_file = src_dir.join(file_name);
+        let dest_file = worker_dir.join(file_name);
+        fs::copy(&src_file, &dest_file).with_context(|| {
+            format!(
+                "Failed to copy {} to {}",
+                src_file.display(),
+                dest_file.display()
+            )
+        })?;
+        println!("Copied web worker file: {}", file_name);
+    }
+
+    let dts = pkg_dir.join("web/breez_sdk_spark_wasm.d.ts");
+    let exports = parse_wasm_exports(&dts)?;
+    let classes = parse_wasm_class_members(&dts)?;
+
+    // --- web/worker/index.js ---
+    let mut js = String::new();
+    js.push_str(
+        r#"// Web worker entry point for Breez SDK
+// Runs the SDK in a dedicated worker: every function and method returns a Promise.
+// Call init() before using any SDK functions.
+
+import { createWorkerClient } from './client.js';
+
+let _client = null;
+
+function _worker(name) {
+  if (!_client) {
+    throw new Error(
+      `@breeztech/breez-sdk-spark: "${name}" called before init(). ` +
+      `Call "await init()" before using SDK functions.`
+    );
+  }
+  return _client;
+}
+
+export default async function init(options = {}) {
+  if (_client) return;
+  const worker =
+    options.worker ?? new Worker(new URL('./worker.js', import.meta.url), { type: 'module' });
+  const client = createWorkerClient(worker);
+  await client.init(options.wasmInput);
+  _client = client;
+}
+
+export function terminate() {
+  _client?.terminate();
+  _client = null;
+}
+
+"#,
+    );
+
+    // Function stubs. `initSync` loads the module on the calling thread and
+    // has no worker equivalent.
+    let functions: Vec<&String> = exports
+        .functions
+        .iter()
+        .filter(|name| name.as_str() != "initSync")
+        .collect();
+    for name in &functions {
+        js.push_str(&format!(
+            "export function {name}(...args) {{\n  \
+             return _worker('{name}').call('{name}', args);\n\
+             }}\n\n"
+        ));
+    }
+
+    // Class stubs: static methods, and `create` in place of `new`
+    for class in &classes {
+        let name = &class.name;
+        js.push_str(&format!("export const {name} = {{\n"));
+        for method in &class.static_methods {
+            js.push_str(&format!(
+                "  {method}: (...args) => _worker('{name}.{method}').callStatic('{name}', '{method}', args),\n"
+            ));
+        }
+        if class.constructible {
+            js.push_str(&format!(
+                "  create: (...args) => _worker('new {name}').construct('{name}', args),\n"
+            ));
+        }
+        js.push_str("};\n\n");
+    }
+
+    fs::write(worker_dir.join("index.js"), &js)
+        .with_context(|| "Failed to write web/worker/index.js")?;
+
+    // --- web/worker/index.d.ts ---
+    let mut dts = String::from(
+        r#"import type * as wasm from "../breez_sdk_spark_wasm.js";
+import type { RemoteClass, RemoteFunction } from "./client.js";
+
+export type * from "../breez_sdk_spark_wasm.js";
+export type { Remote, RemoteClass, RemoteFunction, RemoteValue } from "./client.js";
+
+export interface InitOptions {
+  /** Worker to run the SDK in, instead of the bundled `worker.js`. */
+  worker?: Worker;
+  /** Passed to the WebAssembly initialization in the worker. */
+  wasmInput?: any;
+}
+
+export default function init(options?: InitOptions): Promise<void>;
+export function terminate(): void;
+
+"#,
+    );
+    for name in &functions {
+        dts.push_str(&format!(
+            "export declare const {name}: RemoteFunction<typeof wasm.{name}>;\n"
+        ));
+    }
+    for class in &classes {
+        let name = &class.name;
+        dts.push_str(&format!(
+            "export declare const {name}: RemoteClass<typeof wasm.{name}>;\n"
+        ));
+    }
+    fs::write(worker_dir.join("index.d.ts"), &dts)
+        .with_context(|| "Failed to write web/worker/index.d.ts")?;
+
+    println!(
+        "Created web worker entry point with {} stubs",
+        functions.len() + classes.len()
+    );
+    Ok(())
+}
+
 fn copy_passkey_prf_provider_files(crate_dir: &Path, out_path: &Path) -> Result<()> {
     let src_dir = crate_dir.join("js/passkey-prf-provider");
 
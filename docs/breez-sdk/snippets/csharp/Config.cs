@@ -119,7 +119,9 @@ namespace BreezSdkSnippets
                     ),
                     expectedWithdrawBondSats: 10000,
                     expectedWithdrawRelativeBlockLocktime: 1000,
-                    maxTokenTransactionInputs: null
+                    maxTokenTransactionInputs: null,
+                    depositConfirmations: null,
+                    expectedTransferClaimSecs: null
                 )
             };
             // ANCHOR_END: spark-config
@@ -76,7 +76,8 @@ namespace BreezSdkSnippets
                     amount: optionalAmountSats,
                     expiryTime: optionalExpiryTimeSeconds,
                     senderPublicKey: optionalSenderPublicKey,
-                    tokenIdentifier: null
+                    tokenIdentifier: null,
+                    acceptPartialPayments: null
                 )
             );
             var response = await sdk.ReceivePayment(request: request);
@@ -65,7 +65,8 @@ namespace BreezSdkSnippets
                     description: optionalDescription,
                     amount: optionalAmount,
                     expiryTime: optionalExpiryTimeSeconds,
-                    senderPublicKey: optionalSenderPublicKey
+                    senderPublicKey: optionalSenderPublicKey,
+                    acceptPartialPayments: null
                 )
             );
             var response = await sdk.ReceivePayment(request: request);
@@ -60,7 +60,8 @@ Future<Payment> sendWithClientSigning(BreezSdk sdk, PackageSigner signer) async
           amount: BigInt.from(5000),
           tokenIdentifier: null,
           conversionOptions: null,
-          feePolicy: null));
+          feePolicy: null,
+          fiatAmount: null));
 
   while (true) {
     final unsigned = await sdk.buildUnsignedTransferPackage(
@@ -5,6 +5,8 @@ Future<Contact> addContact(BreezSdk sdk) async {
   AddContactRequest request = AddContactRequest(
     name: "Alice",
     paymentIdentifier: "alice@example.com",
+    additionalPaymentIdentifiers: [],
+    notes: "Met at the conference",
   );
   Contact contact = await sdk.addContact(request: request);
   print("Contact added: $contact");
@@ -19,6 +21,7 @@ Future<Contact> updateContact(BreezSdk sdk) async {
     id: contactId,
     name: "Alice Smith",
     paymentIdentifier: "alice.smith@example.com",
+    additionalPaymentIdentifiers: ["alice@example.com"],
   );
   Contact contact = await sdk.updateContact(request: request);
   print("Contact updated: $contact");
@@ -40,6 +40,7 @@ Future<PrepareSendPaymentResponse> prepareSendPaymentCrossChain(
     tokenIdentifier: null,
     conversionOptions: null,
     feePolicy: null,
+    fiatAmount: null,
   );
   final response = await sdk.prepareSendPayment(request: request);
 
@@ -12,7 +12,8 @@ Future<Payment> sendHtlcPayment(BreezSdk sdk) async {
       amount: amountSats,
       tokenIdentifier: null,
       conversionOptions: null,
-      feePolicy: null);
+      feePolicy: null,
+      fiatAmount: null);
   final prepareResponse = await sdk.prepareSendPayment(request: prepareRequest);
 
   // If the fees are acceptable, continue to create the HTLC Payment
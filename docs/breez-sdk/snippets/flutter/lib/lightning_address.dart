@@ -31,6 +31,11 @@ Future<(String, String, String)> registerLightningAddress(BreezSdk sdk) async {
   final request = RegisterLightningAddressRequest(
     username: username,
     description: description,
+    label: null,
+    image: null,
+    longDescription: null,
+    minSendableSats: null,
+    maxSendableSats: null,
   );
 
   final addressInfo = await sdk.registerLightningAddress(request: request);
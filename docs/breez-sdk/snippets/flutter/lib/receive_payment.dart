@@ -77,6 +77,7 @@ Future<ReceivePaymentResponse> receivePaymentSparkInvoice(BreezSdk sdk) async {
         amount: optionalAmountSats,
         expiryTime: optionalExpiryTimeSeconds,
         senderPublicKey: optionalSenderPublicKey,
+        acceptPartialPayments: null,
       ));
   ReceivePaymentResponse response = await sdk.receivePayment(
     request: request,
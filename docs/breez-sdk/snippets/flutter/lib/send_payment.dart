@@ -12,7 +12,8 @@ Future<PrepareSendPaymentResponse> prepareSendPaymentLightningBolt11(
       amount: optionalAmountSats,
       tokenIdentifier: null,
       conversionOptions: null,
-      feePolicy: null);
+      feePolicy: null,
+      fiatAmount: null);
   final response = await sdk.prepareSendPayment(request: request);
 
   // If the fees are acceptable, continue to create the Send Payment
@@ -41,7 +42,8 @@ Future<PrepareSendPaymentResponse> prepareSendPaymentOnchain(
       amount: amountSats,
       tokenIdentifier: null,
       conversionOptions: null,
-      feePolicy: null);
+      feePolicy: null,
+      fiatAmount: null);
   final response = await sdk.prepareSendPayment(request: request);
 
   // Review the fee quote for each confirmation speed
@@ -71,7 +73,8 @@ Future<PrepareSendPaymentResponse> prepareSendPaymentSparkAddress(
       amount: amountSats,
       tokenIdentifier: null,
       conversionOptions: null,
-      feePolicy: null);
+      feePolicy: null,
+      fiatAmount: null);
   final response = await sdk.prepareSendPayment(request: request);
 
   // If the fees are acceptable, continue to create the Send Payment
@@ -96,7 +99,8 @@ Future<PrepareSendPaymentResponse> prepareSendPaymentSparkInvoice(
       amount: optionalAmountSats,
       tokenIdentifier: null,
       conversionOptions: null,
-      feePolicy: null);
+      feePolicy: null,
+      fiatAmount: null);
   final response = await sdk.prepareSendPayment(request: request);
 
   // If the fees are acceptable, continue to create the Send Payment
@@ -129,7 +133,8 @@ Future<PrepareSendPaymentResponse> prepareSendPaymentTokenConversion(
       amount: null,
       tokenIdentifier: null,
       conversionOptions: conversionOptions,
-      feePolicy: null);
+      feePolicy: null,
+      fiatAmount: null);
   final response = await sdk.prepareSendPayment(request: request);
 
   // If the fees are acceptable, continue to create the Send Payment
@@ -206,7 +211,8 @@ Future<PrepareSendPaymentResponse> prepareSendPaymentFeesIncluded(
       amount: amountSats,
       tokenIdentifier: null,
       conversionOptions: null,
-      feePolicy: FeePolicy.feesIncluded);
+      feePolicy: FeePolicy.feesIncluded,
+      fiatAmount: null);
   final response = await sdk.prepareSendPayment(request: request);
 
   // The response shows the fee policy used
@@ -240,7 +246,8 @@ Future<PrepareSendPaymentResponse> prepareSendPaymentSendAll(
       amount: tokenBalance.balance,
       tokenIdentifier: tokenIdentifier,
       conversionOptions: conversionOptions,
-      feePolicy: FeePolicy.feesIncluded);
+      feePolicy: FeePolicy.feesIncluded,
+      fiatAmount: null);
   final response = await sdk.prepareSendPayment(request: request);
 
   // The response amount is the estimated total sats available
@@ -54,6 +54,7 @@ Future<ReceivePaymentResponse> receiveTokenPaymentSparkInvoice(BreezSdk sdk) asy
         amount: optionalAmount,
         expiryTime: optionalExpiryTimeSeconds,
         senderPublicKey: optionalSenderPublicKey,
+        acceptPartialPayments: null,
       ));
   ReceivePaymentResponse response = await sdk.receivePayment(
     request: request,
@@ -82,6 +83,7 @@ Future<void> sendTokenPayment(BreezSdk sdk) async {
       tokenIdentifier: tokenIdentifier,
       conversionOptions: null,
       feePolicy: null,
+      fiatAmount: null,
     ),
   );
   
@@ -168,6 +170,7 @@ Future<void> prepareSendPaymentTokenConversion(BreezSdk sdk) async {
       tokenIdentifier: tokenIdentifier,
       conversionOptions: conversionOptions,
       feePolicy: null,
+      fiatAmount: null,
     ),
   );
   
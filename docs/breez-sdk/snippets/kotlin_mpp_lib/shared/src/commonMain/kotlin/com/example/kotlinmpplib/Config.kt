@@ -104,7 +104,9 @@ class Config {
             ),
             expectedWithdrawBondSats = 10_000u,
             expectedWithdrawRelativeBlockLocktime = 1_000u,
-            maxTokenTransactionInputs = null
+            maxTokenTransactionInputs = null,
+            depositConfirmations = null,
+            expectedTransferClaimSecs = null
         )
         // ANCHOR_END: spark-config
         println("Config: $config")
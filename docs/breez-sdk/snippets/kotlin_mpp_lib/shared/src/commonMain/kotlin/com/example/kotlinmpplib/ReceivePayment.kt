@@ -87,7 +87,8 @@ class ReceivePayment {
                     description = optionalDescription,
                     amount = optionalAmountSats,
                     expiryTime = optionalExpiryTimeSeconds,
-                    senderPublicKey = optionalSenderPublicKey
+                    senderPublicKey = optionalSenderPublicKey,
+                    acceptPartialPayments = null
                 )
             )
             val response = sdk.receivePayment(request)
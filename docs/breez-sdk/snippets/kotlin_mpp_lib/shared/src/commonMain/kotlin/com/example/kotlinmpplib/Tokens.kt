@@ -71,7 +71,8 @@ class Tokens {
                     description = optionalDescription,
                     amount = optionalAmount,
                     expiryTime = optionalExpiryTimeSeconds,
-                    senderPublicKey = optionalSenderPublicKey
+                    senderPublicKey = optionalSenderPublicKey,
+                    acceptPartialPayments = null
                 )
             )
             val response = sdk.receivePayment(request)
@@ -117,6 +117,8 @@ async def configure_spark_config():
         expected_withdraw_bond_sats=10_000,
         expected_withdraw_relative_block_locktime=1_000,
         max_token_transaction_inputs=None,
+        deposit_confirmations=None,
+        expected_transfer_claim_secs=None,
     )
     # ANCHOR_END: spark-config
     logging.info(f"Config: {config}")
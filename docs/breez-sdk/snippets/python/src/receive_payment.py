@@ -91,6 +91,7 @@ async def receive_spark_invoice(sdk: BreezSdk):
                 expiry_time=optional_expiry_time_seconds,
                 sender_public_key=optional_sender_public_key,
                 token_identifier=None,
+                accept_partial_payments=None,
             )
         )
         response = await sdk.receive_payment(request=request)
@@ -76,6 +76,7 @@ async def receive_token_payment_spark_invoice(sdk: BreezSdk):
                 amount=optional_amount,
                 expiry_time=optional_expiry_time_seconds,
                 sender_public_key=optional_sender_public_key,
+                accept_partial_payments=None,
             )
         )
         response = await sdk.receive_payment(request=request)
@@ -105,7 +105,9 @@ const exampleConfigureSparkConfig = () => {
     },
     expectedWithdrawBondSats: BigInt(10_000),
     expectedWithdrawRelativeBlockLocktime: BigInt(1_000),
-    maxTokenTransactionInputs: undefined
+    maxTokenTransactionInputs: undefined,
+    depositConfirmations: undefined,
+    expectedTransferClaimSecs: undefined
   }
   // ANCHOR_END: spark-config
   console.log('Config:', config)
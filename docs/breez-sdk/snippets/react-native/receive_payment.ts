@@ -70,7 +70,8 @@ const exampleReceiveSparkInvoice = async (sdk: BreezSdk) => {
       amount: optionalAmountSats,
       expiryTime: optionalExpiryTimeSeconds,
       senderPublicKey: optionalSenderPublicKey,
-      tokenIdentifier: undefined
+      tokenIdentifier: undefined,
+      acceptPartialPayments: undefined
     })
   })
 
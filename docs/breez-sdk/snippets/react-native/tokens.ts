@@ -59,7 +59,8 @@ const exampleReceiveTokenPaymentSparkInvoice = async (sdk: BreezSdk) => {
       description: optionalDescription,
       amount: optionalAmount,
       expiryTime: optionalExpiryTimeSeconds,
-      senderPublicKey: optionalSenderPublicKey
+      senderPublicKey: optionalSenderPublicKey,
+      acceptPartialPayments: undefined
     })
   })
 
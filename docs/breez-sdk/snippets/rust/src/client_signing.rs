@@ -82,6 +82,7 @@ async fn send_with_client_signing(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -43,6 +43,7 @@ pub(crate) fn configure_optimization_configuration() -> Result<()> {
     config.leaf_optimization_config = LeafOptimizationConfig {
         auto_enabled: true,
         multiplicity: 1,
+        auto_policy: None,
     };
     config.token_optimization_config = TokenOptimizationConfig {
         auto_enabled: true,
@@ -121,6 +122,8 @@ pub(crate) fn configure_spark_config() -> Result<()> {
         expected_withdraw_bond_sats: 10_000,
         expected_withdraw_relative_block_locktime: 1_000,
         max_token_transaction_inputs: None,
+        deposit_confirmations: None,
+        expected_transfer_claim_secs: None,
     });
     // ANCHOR_END: spark-config
     info!("Config: {:?}", config);
@@ -8,6 +8,9 @@ pub(crate) async fn add_contact(sdk: &BreezSdk) -> Result<()> {
         .add_contact(AddContactRequest {
             name: "Alice".to_string(),
             payment_identifier: "alice@example.com".to_string(),
+            additional_payment_identifiers: vec![],
+            avatar_url: None,
+            notes: Some("Met at the conference".to_string()),
         })
         .await?;
     info!("Contact added: {:?}", contact);
@@ -23,6 +26,9 @@ pub(crate) async fn update_contact(sdk: &BreezSdk) -> Result<()> {
             id: contact_id,
             name: "Alice Smith".to_string(),
             payment_identifier: "alice.smith@example.com".to_string(),
+            additional_payment_identifiers: vec!["alice@example.com".to_string()],
+            avatar_url: None,
+            notes: None,
         })
         .await?;
     info!("Contact updated: {:?}", contact);
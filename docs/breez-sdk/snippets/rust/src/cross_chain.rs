@@ -46,6 +46,7 @@ async fn prepare_send_payment_cross_chain(
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -78,6 +79,9 @@ async fn send_payment_cross_chain(
             prepare_response,
             options: None,
             idempotency_key: optional_idempotency_key,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     let payment = send_response.payment;
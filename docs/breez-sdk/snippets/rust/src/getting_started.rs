@@ -37,6 +37,7 @@ pub(crate) async fn getting_started_node_info(sdk: &BreezSdk) -> Result<()> {
             // ensure_synced: true will ensure the SDK is synced with the Spark network
             // before returning the balance
             ensure_synced: Some(false),
+            include_hidden: None,
         })
         .await?;
     let identity_pubkey = &info.identity_pubkey;
@@ -91,6 +92,72 @@ impl EventListener for SdkEventListener {
             SdkEvent::LightningAddressChanged { lightning_address } => {
                 // The lightning address has changed
             }
+            SdkEvent::PartialInvoicePaymentProgress {
+                invoice,
+                received_amount,
+                target_amount,
+                completed,
+                ..
+            } => {
+                // A payment was received towards an invoice accepting partial payments
+            }
+            SdkEvent::PaymentProgress { handle, stage } => {
+                // A payment started with `send_payment_async` reached a new stage
+            }
+            SdkEvent::HtlcRefunded { payment } => {
+                // The funds of an expired outgoing HTLC were reclaimed
+            }
+            SdkEvent::ArbitratedEscrowUpdated { escrow } => {
+                // An arbitrated escrow was created or changed status
+            }
+            SdkEvent::RefundFeeBumped {
+                refund,
+                replaced_tx_id,
+            } => {
+                // A deposit refund was replaced by one paying a higher fee
+            }
+            SdkEvent::RefundConfirmed { refund } => {
+                // A deposit refund transaction was confirmed
+            }
+            SdkEvent::PaymentStreamUpdated { stream } => {
+                // A payment stream sent a transfer or stopped
+            }
+            SdkEvent::ClaimQueueChanged { queue_depth } => {
+                // The number of incoming transfers waiting to be claimed changed
+            }
+            SdkEvent::UnilateralExitProgress { leaf } => {
+                // A leaf of the unilateral exit moved to a new stage
+            }
+            SdkEvent::OnchainTransactionUpdated { transaction } => {
+                // A withdrawal or deposit refund changed on-chain status
+            }
+            SdkEvent::TokenSweep { sweep_event } => {
+                // A residual token balance was swept into sats
+            }
+            SdkEvent::TokenDistribution { distribution_event } => {
+                // A token distribution sent a chunk of recipients or finished
+            }
+            SdkEvent::JobUpdated { job } => {
+                // A background job made progress or finished
+            }
+            SdkEvent::TokenMetadataChanged { metadata } => {
+                // The metadata of a held token changed
+            }
+            SdkEvent::ServiceStatusChanged { status } => {
+                // The status of the Spark network changed
+            }
+            SdkEvent::SyncProgress { progress } => {
+                // A stage of a wallet sync finished
+            }
+            SdkEvent::SyncConflictResolved { conflict } => {
+                // A change from another device conflicted with a local change
+            }
+            SdkEvent::NostrZapReceiptIssued {
+                payment_hash,
+                zap_receipt,
+            } => {
+                // A zap receipt was issued for a zap to the Lightning address
+            }
         }
     }
 }
@@ -15,6 +15,7 @@ async fn send_htlc_payment(sdk: &BreezSdk) -> Result<()> {
         token_identifier: None,
         conversion_options: None,
         fee_policy: None,
+        fiat_amount: None,
     };
     let prepare_response = sdk.prepare_send_payment(prepare_request).await?;
 
@@ -40,6 +41,9 @@ async fn send_htlc_payment(sdk: &BreezSdk) -> Result<()> {
         prepare_response,
         options: Some(options),
         idempotency_key: None,
+        max_fee: None,
+        deliver_after: None,
+        leaf_selection: None,
     };
     let send_response = sdk.send_payment(request).await?;
     let payment = send_response.payment;
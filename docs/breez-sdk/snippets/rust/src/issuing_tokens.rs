@@ -1,8 +1,8 @@
 use anyhow::Result;
 use breez_sdk_spark::{
-    default_config, BreezSdk, BurnIssuerTokenRequest, CreateIssuerTokenRequest,
-    FreezeIssuerTokenRequest, MintIssuerTokenRequest, Network, Payment, PaymentDetailsFilter,
-    SdkBuilder, Seed, TokenIssuer, TokenMetadata, TokenTransactionType, UnfreezeIssuerTokenRequest,
+    BreezSdk, BurnIssuerTokenRequest, CreateIssuerTokenRequest, FreezeIssuerTokenRequest,
+    MintIssuerTokenRequest, Network, Payment, PaymentDetailsFilter, SdkBuilder, Seed, TokenIssuer,
+    TokenMetadata, TokenTransactionType, UnfreezeIssuerTokenRequest, default_config,
 };
 use log::info;
 
@@ -50,7 +50,10 @@ async fn create_token_with_custom_account_number() -> Result<BreezSdk> {
 
 async fn mint_token(token_issuer: &TokenIssuer) -> Result<Payment> {
     // ANCHOR: mint-token
-    let request = MintIssuerTokenRequest { amount: 1_000 };
+    let request = MintIssuerTokenRequest {
+        amount: 1_000,
+        idempotency_key: None,
+    };
 
     let payment = token_issuer.mint_issuer_token(request).await?;
     // ANCHOR_END: mint-token
@@ -59,7 +62,10 @@ async fn mint_token(token_issuer: &TokenIssuer) -> Result<Payment> {
 
 async fn burn_token(token_issuer: &TokenIssuer) -> Result<Payment> {
     // ANCHOR: burn-token
-    let request = BurnIssuerTokenRequest { amount: 1_000 };
+    let request = BurnIssuerTokenRequest {
+        amount: 1_000,
+        idempotency_key: None,
+    };
 
     let payment = token_issuer.burn_issuer_token(request).await?;
     // ANCHOR_END: burn-token
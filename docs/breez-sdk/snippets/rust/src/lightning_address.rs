@@ -36,6 +36,11 @@ pub async fn register_lightning_address(
     let request = RegisterLightningAddressRequest {
         username,
         description,
+        label: None,
+        image: None,
+        long_description: None,
+        min_sendable_sats: None,
+        max_sendable_sats: None,
     };
 
     let address_info = sdk.register_lightning_address(request).await?;
@@ -23,6 +23,7 @@ async fn prepare_pay(sdk: &BreezSdk) -> Result<()> {
                 token_identifier: None,
                 conversion_options: None,
                 fee_policy: None,
+                quote_token_identifier: None,
             })
             .await?;
 
@@ -41,6 +42,7 @@ async fn pay(sdk: &BreezSdk, prepare_response: PrepareLnurlPayResponse) -> Resul
         .lnurl_pay(LnurlPayRequest {
             prepare_response,
             idempotency_key: optional_idempotency_key,
+            max_fee: None,
         })
         .await?;
     // ANCHOR_END: lnurl-pay
@@ -69,6 +71,7 @@ async fn prepare_pay_fees_included(
             token_identifier: None,
             conversion_options: None,
             fee_policy: Some(FeePolicy::FeesIncluded),
+            quote_token_identifier: None,
         })
         .await?;
 
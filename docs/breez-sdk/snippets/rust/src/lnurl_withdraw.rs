@@ -18,6 +18,7 @@ async fn withdraw(sdk: &BreezSdk) -> Result<()> {
                 amount_sats,
                 withdraw_request,
                 completion_timeout_secs: optional_completion_timeout_secs,
+                idempotency_key: None,
             })
             .await?;
 
@@ -78,6 +78,7 @@ async fn receive_spark_invoice(sdk: &BreezSdk) -> Result<()> {
                 amount: optional_amount_sats,
                 expiry_time: optional_expiry_time_seconds,
                 sender_public_key: optional_sender_public_key,
+                accept_partial_payments: None,
             },
         })
         .await?;
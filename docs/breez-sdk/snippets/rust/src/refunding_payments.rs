@@ -69,6 +69,7 @@ async fn handle_fee_exceeded(sdk: &BreezSdk, deposit: &DepositInfo) -> Result<()
                 max_fee: Some(MaxFee::Fixed {
                     amount: *required_fee_sats,
                 }),
+                idempotency_key: None,
             };
             sdk.claim_deposit(request).await?;
         }
@@ -139,6 +140,7 @@ async fn custom_claim_logic(sdk: &BreezSdk, deposit: &DepositInfo) -> Result<()>
                 max_fee: Some(MaxFee::Rate {
                     sat_per_vbyte: *required_fee_rate_sat_per_vbyte,
                 }),
+                idempotency_key: None,
             };
             sdk.claim_deposit(request).await?;
         }
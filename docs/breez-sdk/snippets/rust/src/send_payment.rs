@@ -17,6 +17,7 @@ async fn prepare_send_payment_lightning_bolt11(sdk: &BreezSdk) -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -51,6 +52,7 @@ async fn prepare_send_payment_onchain(sdk: &BreezSdk) -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -82,6 +84,7 @@ async fn prepare_send_payment_spark_address(sdk: &BreezSdk) -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -108,6 +111,7 @@ async fn prepare_send_payment_spark_invoice(sdk: &BreezSdk) -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -142,6 +146,7 @@ async fn prepare_send_payment_token_conversion(sdk: &BreezSdk) -> Result<()> {
             token_identifier: None,
             conversion_options,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -175,6 +180,9 @@ async fn send_payment_lightning_bolt11(
             prepare_response,
             options,
             idempotency_key: optional_idempotency_key,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     let payment = send_response.payment;
@@ -198,6 +206,9 @@ async fn send_payment_onchain(
             prepare_response,
             options,
             idempotency_key: optional_idempotency_key,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     let payment = send_response.payment;
@@ -217,6 +228,9 @@ async fn send_payment_spark(
             prepare_response,
             options: None,
             idempotency_key: optional_idempotency_key,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     let payment = send_response.payment;
@@ -242,6 +256,7 @@ async fn prepare_send_payment_fees_included(sdk: &BreezSdk) -> Result<()> {
             token_identifier: None,
             conversion_options: None,
             fee_policy: Some(FeePolicy::FeesIncluded),
+            fiat_amount: None,
         })
         .await?;
 
@@ -261,6 +276,7 @@ async fn prepare_send_payment_send_all(sdk: &BreezSdk) -> Result<()> {
     let info = sdk
         .get_info(GetInfoRequest {
             ensure_synced: Some(false),
+            include_hidden: None,
         })
         .await?;
 
@@ -286,6 +302,7 @@ async fn prepare_send_payment_send_all(sdk: &BreezSdk) -> Result<()> {
             token_identifier: Some(token_identifier),
             conversion_options,
             fee_policy: Some(FeePolicy::FeesIncluded),
+            fiat_amount: None,
         })
         .await?;
 
@@ -9,6 +9,7 @@ pub(crate) async fn fetch_token_balances(sdk: &BreezSdk) -> Result<()> {
             // ensure_synced: true will ensure the SDK is synced with the Spark network
             // before returning the balance
             ensure_synced: Some(false),
+            include_hidden: None,
         })
         .await?;
 
@@ -66,6 +67,7 @@ async fn receive_token_payment_spark_invoice(sdk: &BreezSdk) -> Result<()> {
                 amount: optional_amount,
                 expiry_time: optional_expiry_time_seconds,
                 sender_public_key: optional_sender_public_key,
+                accept_partial_payments: None,
             },
         })
         .await?;
@@ -95,6 +97,7 @@ async fn send_token_payment(sdk: &BreezSdk) -> Result<()> {
             token_identifier,
             conversion_options: None,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -125,6 +128,9 @@ async fn send_token_payment(sdk: &BreezSdk) -> Result<()> {
             prepare_response,
             options: None,
             idempotency_key: None,
+            max_fee: None,
+            deliver_after: None,
+            leaf_selection: None,
         })
         .await?;
     let payment = send_response.payment;
@@ -195,6 +201,7 @@ async fn prepare_send_payment_token_conversion(sdk: &BreezSdk) -> Result<()> {
             token_identifier,
             conversion_options,
             fee_policy: None,
+            fiat_amount: None,
         })
         .await?;
 
@@ -16,6 +16,7 @@ pub(crate) async fn update_user_settings(sdk: &BreezSdk) -> Result<()> {
     sdk.update_user_settings(UpdateUserSettingsRequest {
         spark_private_mode_enabled: Some(spark_private_mode_enabled),
         stable_balance_active_label: None,
+        display_currency: None,
     })
     .await?;
     // ANCHOR_END: update-user-settings
@@ -29,6 +30,7 @@ pub(crate) async fn activate_stable_balance(sdk: &BreezSdk) -> Result<()> {
         stable_balance_active_label: Some(StableBalanceActiveLabel::Set {
             label: "USDB".to_string(),
         }),
+        display_currency: None,
     })
     .await?;
     // ANCHOR_END: activate-stable-balance
@@ -40,6 +42,7 @@ pub(crate) async fn deactivate_stable_balance(sdk: &BreezSdk) -> Result<()> {
     sdk.update_user_settings(UpdateUserSettingsRequest {
         spark_private_mode_enabled: None,
         stable_balance_active_label: Some(StableBalanceActiveLabel::Unset),
+        display_currency: None,
     })
     .await?;
     // ANCHOR_END: deactivate-stable-balance
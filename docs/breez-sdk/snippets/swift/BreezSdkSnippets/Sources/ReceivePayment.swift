@@ -84,7 +84,8 @@ func receiveSparkInvoice(sdk: BreezSdk) async throws -> ReceivePaymentResponse {
                     tokenIdentifier: nil,
                     expiryTime: optionalExpiryTimeSeconds,
                     description: optionalDescription,
-                    senderPublicKey: optionalSenderPublicKey
+                    senderPublicKey: optionalSenderPublicKey,
+                    acceptPartialPayments: nil
                 )
             ))
 
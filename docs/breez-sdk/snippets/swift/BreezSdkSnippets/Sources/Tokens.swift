@@ -59,7 +59,8 @@ func receiveTokenPaymentSparkInvoice(sdk: BreezSdk) async throws -> ReceivePayme
                     tokenIdentifier: tokenIdentifier,
                     expiryTime: optionalExpiryTimeSeconds,
                     description: optionalDescription,
-                    senderPublicKey: optionalSenderPublicKey
+                    senderPublicKey: optionalSenderPublicKey,
+                    acceptPartialPayments: nil
                 )
             ))
 
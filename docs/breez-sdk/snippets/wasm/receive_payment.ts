@@ -66,7 +66,8 @@ const exampleReceiveSparkInvoice = async (sdk: BreezSdk) => {
       description: optionalDescription,
       amount: optionalAmountSats,
       expiryTime: optionalExpiryTimeSeconds,
-      senderPublicKey: optionalSenderPublicKey
+      senderPublicKey: optionalSenderPublicKey,
+      acceptPartialPayments: undefined
     }
   })
 
@@ -1,5 +1,7 @@
 pub use breez_sdk_spark::passkey::{PasskeyError, PrfProviderError};
-pub use breez_sdk_spark::{DepositClaimError, Fee, SdkError, StorageError};
+pub use breez_sdk_spark::{
+    DepositClaimError, Fee, PaymentRail, SdkError, SpendingCapScope, StorageError,
+};
 use flutter_rust_bridge::frb;
 
 #[frb(mirror(DepositClaimError))]
@@ -36,6 +38,10 @@ pub enum _SdkError {
         required_fee_sats: u64,
         required_fee_rate_sat_per_vbyte: u64,
     },
+    MaxFeeExceeded {
+        fee_sats: u64,
+        max_fee_sats: u64,
+    },
     MissingUtxo {
         tx: String,
         vout: u32,
@@ -44,8 +50,28 @@ pub enum _SdkError {
     Signer(String),
     OptimizationAlreadyRunning,
     OptimizationCancelled,
-    InsufficientCpfpFunds { required_sat: u64 },
-    FundingUtxoConflict { txid: String, vout: u32 },
+    InsufficientCpfpFunds {
+        required_sat: u64,
+    },
+    FundingUtxoConflict {
+        txid: String,
+        vout: u32,
+    },
+    OperatorNotAllowed(String),
+    IdempotencyKeyInUse(String),
+    PaymentRejected(String),
+    WalletFrozen,
+    TooManyAttempts {
+        retry_after_secs: u64,
+    },
+    IssuerApprovalFailed(String),
+    PaymentRailDisabled {
+        rail: PaymentRail,
+    },
+    SpendingCapExceeded {
+        scope: SpendingCapScope,
+        remaining: u128,
+    },
     Generic(String),
 }
 
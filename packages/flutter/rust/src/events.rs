@@ -1,6 +1,13 @@
 use crate::frb_generated::StreamSink;
-use breez_sdk_spark::{DepositInfo, EventListener, LightningAddressInfo, Payment};
-pub use breez_sdk_spark::{AutoOptimizationEvent, SdkEvent};
+use breez_sdk_spark::{
+    ArbitratedEscrow, DepositInfo, DepositRefund, EventListener, Job, LightningAddressInfo,
+    OnchainTransaction, Payment, PaymentHandle, PaymentProgressStage, PaymentStream,
+    ServiceStatusReport, SyncConflict, SyncProgress, TokenMetadata, TokenRecipientResult,
+    UnilateralExitLeafProgress,
+};
+pub use breez_sdk_spark::{
+    AutoOptimizationEvent, SdkEvent, TokenDistributionEvent, TokenSweepEvent,
+};
 use flutter_rust_bridge::frb;
 
 #[frb(mirror(SdkEvent))]
@@ -30,6 +37,68 @@ pub enum _SdkEvent {
     NewDeposits {
         new_deposits: Vec<DepositInfo>,
     },
+    PartialInvoicePaymentProgress {
+        invoice: String,
+        payment_id: String,
+        received_amount: u128,
+        target_amount: u128,
+        token_identifier: Option<String>,
+        completed: bool,
+    },
+    PaymentProgress {
+        handle: PaymentHandle,
+        stage: PaymentProgressStage,
+    },
+    HtlcRefunded {
+        payment: Payment,
+    },
+    ArbitratedEscrowUpdated {
+        escrow: ArbitratedEscrow,
+    },
+    RefundFeeBumped {
+        refund: DepositRefund,
+        replaced_tx_id: String,
+    },
+    RefundConfirmed {
+        refund: DepositRefund,
+    },
+    PaymentStreamUpdated {
+        stream: PaymentStream,
+    },
+    ClaimQueueChanged {
+        queue_depth: u32,
+    },
+    UnilateralExitProgress {
+        leaf: UnilateralExitLeafProgress,
+    },
+    OnchainTransactionUpdated {
+        transaction: OnchainTransaction,
+    },
+    TokenSweep {
+        sweep_event: TokenSweepEvent,
+    },
+    TokenDistribution {
+        distribution_event: TokenDistributionEvent,
+    },
+    JobUpdated {
+        job: Job,
+    },
+    TokenMetadataChanged {
+        metadata: TokenMetadata,
+    },
+    ServiceStatusChanged {
+        status: ServiceStatusReport,
+    },
+    SyncProgress {
+        progress: SyncProgress,
+    },
+    SyncConflictResolved {
+        conflict: SyncConflict,
+    },
+    NostrZapReceiptIssued {
+        payment_hash: String,
+        zap_receipt: String,
+    },
 }
 
 #[frb(mirror(AutoOptimizationEvent))]
@@ -49,6 +118,42 @@ pub enum _AutoOptimizationEvent {
     Skipped,
 }
 
+#[frb(mirror(TokenSweepEvent))]
+pub enum _TokenSweepEvent {
+    Swept {
+        sweep_id: String,
+        token_identifier: String,
+        amount: u128,
+        sent_payment_id: String,
+        received_payment_id: String,
+    },
+    Failed {
+        sweep_id: String,
+        token_identifier: String,
+        error: String,
+    },
+    Completed {
+        sweep_id: String,
+        swept_count: u32,
+    },
+}
+
+#[frb(mirror(TokenDistributionEvent))]
+pub enum _TokenDistributionEvent {
+    Progress {
+        distribution_id: String,
+        processed_count: u32,
+        total_count: u32,
+        results: Vec<TokenRecipientResult>,
+    },
+    Completed {
+        distribution_id: String,
+        sent_count: u32,
+        failed_count: u32,
+        unknown_count: u32,
+    },
+}
+
 pub struct BindingEventListener {
     pub listener: StreamSink<SdkEvent>,
 }
@@ -2,7 +2,7 @@ use std::panic::AssertUnwindSafe;
 use std::sync::Arc;
 
 use breez_sdk_spark::SignerError;
-use breez_sdk_spark::signer::CpfpSigner;
+use breez_sdk_spark::signer::{CpfpSigner, PsbtSigner};
 use flutter_rust_bridge::DartFnFuture;
 use futures::FutureExt;
 
@@ -33,3 +33,20 @@ impl CpfpSigner for CallbackCpfpSigner {
             .map_err(|e| SignerError::Signing(format!("{e}")))
     }
 }
+
+/// Wraps a Dart `sign_psbt` callback taking and returning base64 PSBTs as a
+/// [`PsbtSigner`], the format hardware wallets and their host apps use.
+pub(crate) struct CallbackPsbtSigner {
+    pub(crate) sign_psbt: Arc<dyn Fn(String) -> DartFnFuture<anyhow::Result<String>> + Send + Sync>,
+}
+
+#[async_trait::async_trait]
+impl PsbtSigner for CallbackPsbtSigner {
+    async fn sign_psbt(&self, psbt_base64: String) -> Result<String, SignerError> {
+        AssertUnwindSafe((self.sign_psbt)(psbt_base64))
+            .catch_unwind()
+            .await
+            .map_err(|e| SignerError::Signing(panic_message(e)))?
+            .map_err(|e| SignerError::Signing(format!("{e}")))
+    }
+}
@@ -0,0 +1,43 @@
+use std::panic::AssertUnwindSafe;
+use std::sync::Arc;
+
+use breez_sdk_spark::{InputParser, InputParserError, InputType};
+pub use breez_sdk_spark::{InputParserInfo, InputParserPriority};
+use flutter_rust_bridge::{DartFnFuture, frb};
+use futures::FutureExt;
+
+#[frb(mirror(InputParserPriority))]
+pub enum _InputParserPriority {
+    BeforeBuiltIn,
+    AfterBuiltIn,
+}
+
+#[frb(mirror(InputParserInfo))]
+pub struct _InputParserInfo {
+    pub name: String,
+    pub priority: InputParserPriority,
+    pub timeout_secs: Option<u32>,
+}
+
+/// Wraps a Dart `parse` callback as an [`InputParser`]. A Dart-side throw
+/// fails the parse.
+pub(crate) struct CallbackInputParser {
+    pub(crate) info: InputParserInfo,
+    pub(crate) parse: Arc<dyn Fn(String) -> DartFnFuture<Option<InputType>> + Send + Sync>,
+}
+
+#[async_trait::async_trait]
+impl InputParser for CallbackInputParser {
+    fn info(&self) -> InputParserInfo {
+        self.info.clone()
+    }
+
+    async fn parse(&self, input: String) -> Result<Option<InputType>, InputParserError> {
+        AssertUnwindSafe((self.parse)(input))
+            .catch_unwind()
+            .await
+            .map_err(|_| {
+                InputParserError::Generic("Dart input parser callback panicked".to_string())
+            })
+    }
+}
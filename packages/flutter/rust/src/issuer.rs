@@ -1,9 +1,10 @@
 use std::sync::Arc;
 
 use breez_sdk_spark::{
-    BurnIssuerTokenRequest, CreateIssuerTokenRequest, FreezeIssuerTokenRequest,
-    FreezeIssuerTokenResponse, MintIssuerTokenRequest, Payment, SdkError, TokenBalance,
-    TokenMetadata, UnfreezeIssuerTokenRequest, UnfreezeIssuerTokenResponse,
+    BurnIssuerTokenRequest, CreateIssuerTokenRequest, DistributeTokensRequest,
+    DistributeTokensResponse, FreezeIssuerTokenRequest, FreezeIssuerTokenResponse,
+    GetIssuanceStatsRequest, IssuanceStats, MintIssuerTokenRequest, Payment, SdkError,
+    TokenBalance, TokenMetadata, UnfreezeIssuerTokenRequest, UnfreezeIssuerTokenResponse,
 };
 
 pub struct TokenIssuer {
@@ -53,4 +54,18 @@ impl TokenIssuer {
     ) -> Result<UnfreezeIssuerTokenResponse, SdkError> {
         self.token_issuer.unfreeze_issuer_token(request).await
     }
+
+    pub async fn get_issuance_stats(
+        &self,
+        request: GetIssuanceStatsRequest,
+    ) -> Result<IssuanceStats, SdkError> {
+        self.token_issuer.get_issuance_stats(request).await
+    }
+
+    pub async fn distribute_tokens(
+        &self,
+        request: DistributeTokensRequest,
+    ) -> Result<DistributeTokensResponse, SdkError> {
+        self.token_issuer.distribute_tokens(request).await
+    }
 }
@@ -3,13 +3,16 @@ pub mod errors;
 pub mod events;
 pub mod exit_signer;
 mod frb_generated;
+pub mod input_parser;
 pub mod issuer;
 pub mod logger;
+pub mod middleware;
 pub mod models;
 pub mod passkey;
 pub mod sdk;
 pub mod sdk_builder;
 pub mod sdk_context;
+pub mod send_approver;
 
 pub use passkey::PasskeyClient;
 pub use sdk::BreezSdk;
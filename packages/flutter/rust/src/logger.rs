@@ -1,12 +1,34 @@
+use std::collections::HashMap;
+
 use crate::frb_generated::StreamSink;
-pub use breez_sdk_spark::LogEntry;
 use breez_sdk_spark::Logger;
+pub use breez_sdk_spark::{LogEntry, LogFileFormat, LoggingConfig};
 use flutter_rust_bridge::frb;
 
 #[frb(mirror(LogEntry))]
 pub struct _LogEntry {
     pub line: String,
     pub level: String,
+    pub target: String,
+    pub fields: HashMap<String, String>,
+    pub timestamp: u64,
+}
+
+#[frb(mirror(LoggingConfig))]
+pub struct _LoggingConfig {
+    pub log_dir: Option<String>,
+    pub log_filter: Option<String>,
+    pub app_logger_filter: Option<String>,
+    pub file_format: LogFileFormat,
+    pub max_file_size_bytes: Option<u64>,
+    pub max_file_age_secs: Option<u64>,
+    pub max_rotated_files: Option<u32>,
+}
+
+#[frb(mirror(LogFileFormat))]
+pub enum _LogFileFormat {
+    Text,
+    Json,
 }
 
 pub struct BindingLogger {
@@ -0,0 +1,57 @@
+use std::panic::AssertUnwindSafe;
+use std::sync::Arc;
+
+pub use breez_sdk_spark::{MiddlewareDecision, PaymentStage};
+use breez_sdk_spark::{
+    Payment, PaymentMiddleware, PrepareLnurlPayRequest, PrepareLnurlPayResponse,
+    PrepareSendPaymentRequest, PrepareSendPaymentResponse, ReceivePaymentRequest,
+};
+use flutter_rust_bridge::{DartFnFuture, frb};
+use futures::FutureExt;
+
+#[frb(mirror(PaymentStage))]
+pub enum _PaymentStage {
+    BeforePrepareSend {
+        request: PrepareSendPaymentRequest,
+    },
+    BeforePrepareLnurlPay {
+        request: PrepareLnurlPayRequest,
+    },
+    BeforeReceive {
+        request: ReceivePaymentRequest,
+    },
+    BeforeSend {
+        prepare_response: PrepareSendPaymentResponse,
+    },
+    BeforeLnurlPay {
+        prepare_response: PrepareLnurlPayResponse,
+    },
+    AfterSettle {
+        payment: Payment,
+    },
+}
+
+#[frb(mirror(MiddlewareDecision))]
+pub enum _MiddlewareDecision {
+    Continue,
+    Reject { reason: String },
+}
+
+/// Wraps a Dart `on_stage` callback as a [`PaymentMiddleware`]. A Dart-side
+/// throw rejects the stage, so a failing check can't be bypassed.
+pub(crate) struct CallbackPaymentMiddleware {
+    pub(crate) on_stage:
+        Arc<dyn Fn(PaymentStage) -> DartFnFuture<MiddlewareDecision> + Send + Sync>,
+}
+
+#[async_trait::async_trait]
+impl PaymentMiddleware for CallbackPaymentMiddleware {
+    async fn on_stage(&self, stage: PaymentStage) -> MiddlewareDecision {
+        AssertUnwindSafe((self.on_stage)(stage))
+            .catch_unwind()
+            .await
+            .unwrap_or_else(|_| MiddlewareDecision::Reject {
+                reason: "Dart middleware callback panicked".to_string(),
+            })
+    }
+}
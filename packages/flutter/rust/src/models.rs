@@ -46,6 +46,73 @@ pub struct _Config {
     pub spark_config: Option<SparkConfig>,
     pub background_tasks_enabled: bool,
     pub cross_chain_config: Option<CrossChainConfig>,
+    pub operator_allowlist: Option<OperatorAllowlistConfig>,
+    pub conversion_max_price_deviation_bps: Option<u32>,
+    pub dust_config: Option<DustConfig>,
+    pub token_visibility: Option<TokenVisibilityConfig>,
+    pub auto_refund_htlc_payments: bool,
+    pub max_claims_per_second: Option<u32>,
+    pub rotate_deposit_address: bool,
+    pub faucet_config: Option<FaucetConfig>,
+    pub enabled_rails: Vec<PaymentRail>,
+    pub payment_fiat_currency: Option<String>,
+    pub spending_caps: Vec<SpendingCap>,
+    pub token_sweep_policy: Option<TokenSweepPolicy>,
+    pub service_endpoints: Option<ServiceEndpoints>,
+    pub parse_cache_ttl_secs: u32,
+}
+
+#[frb(mirror(TokenSweepPolicy))]
+pub struct _TokenSweepPolicy {
+    pub max_balance_sat: u64,
+    pub recognized_token_identifiers: Vec<String>,
+    pub sweep_unrecognized_tokens: bool,
+    pub interval_secs: u64,
+}
+
+#[frb(mirror(ServiceEndpoints))]
+pub struct _ServiceEndpoints {
+    pub lnurl_server_url: Option<String>,
+    pub fiat_rates_url: Option<String>,
+    pub buy_bitcoin_url: Option<String>,
+}
+
+#[frb(mirror(FaucetConfig))]
+pub struct _FaucetConfig {
+    pub url: String,
+    pub credentials: Option<Credentials>,
+}
+
+#[frb(mirror(DustConfig))]
+pub struct _DustConfig {
+    pub sats: AssetDustLimit,
+    pub tokens: HashMap<String, AssetDustLimit>,
+    pub suppress_dust_balances: bool,
+}
+
+#[frb(mirror(TokenVisibilityConfig))]
+pub struct _TokenVisibilityConfig {
+    pub allowlist: Option<Vec<String>>,
+    pub denylist: Vec<String>,
+}
+
+#[frb(mirror(AssetDustLimit))]
+pub struct _AssetDustLimit {
+    pub min_displayable: u128,
+    pub min_payable: u128,
+}
+
+#[frb(mirror(OperatorAllowlistConfig))]
+pub struct _OperatorAllowlistConfig {
+    pub operators: Vec<AllowedOperator>,
+    pub ssp_identity_public_key: Option<String>,
+}
+
+#[frb(mirror(AllowedOperator))]
+pub struct _AllowedOperator {
+    pub identity_public_key: String,
+    pub address: Option<String>,
+    pub ca_cert_pem: Option<String>,
 }
 
 #[frb(mirror(CrossChainConfig))]
@@ -63,6 +130,8 @@ pub struct _SparkConfig {
     pub expected_withdraw_bond_sats: u64,
     pub expected_withdraw_relative_block_locktime: u64,
     pub max_token_transaction_inputs: Option<u32>,
+    pub deposit_confirmations: Option<u32>,
+    pub expected_transfer_claim_secs: Option<u64>,
 }
 
 #[frb(mirror(SparkSigningOperator))]
@@ -85,6 +154,26 @@ pub struct _SparkSspConfig {
 pub struct _LeafOptimizationConfig {
     pub auto_enabled: bool,
     pub multiplicity: u8,
+    pub auto_policy: Option<LeafOptimizationPolicy>,
+}
+
+#[frb(mirror(LeafOptimizationPolicy))]
+pub struct _LeafOptimizationPolicy {
+    pub min_leaf_count: Option<u32>,
+    pub min_idle_secs: Option<u64>,
+    pub unmetered_only: bool,
+    pub quiet_hours: Option<QuietHours>,
+}
+
+#[frb(mirror(QuietHours))]
+pub struct _QuietHours {
+    pub start_hour: u8,
+    pub end_hour: u8,
+}
+
+#[frb(mirror(HostConditions))]
+pub struct _HostConditions {
+    pub is_metered: Option<bool>,
 }
 
 #[frb(mirror(TokenOptimizationConfig))]
@@ -148,6 +237,7 @@ pub struct _ClaimDepositRequest {
     pub txid: String,
     pub vout: u32,
     pub max_fee: Option<MaxFee>,
+    pub idempotency_key: Option<String>,
 }
 
 #[frb(mirror(ClaimDepositResponse))]
@@ -155,6 +245,45 @@ pub struct _ClaimDepositResponse {
     pub payment: Payment,
 }
 
+#[frb(mirror(DepositOutpoint))]
+pub struct _DepositOutpoint {
+    pub txid: String,
+    pub vout: u32,
+}
+
+#[frb(mirror(ClaimDepositsRequest))]
+pub struct _ClaimDepositsRequest {
+    pub deposits: Vec<DepositOutpoint>,
+    pub max_total_fee_sat: Option<u64>,
+}
+
+#[frb(mirror(ClaimDepositResult))]
+pub struct _ClaimDepositResult {
+    pub txid: String,
+    pub vout: u32,
+    pub payment: Option<Payment>,
+    pub error: Option<String>,
+}
+
+#[frb(mirror(ClaimDepositsResponse))]
+pub struct _ClaimDepositsResponse {
+    pub results: Vec<ClaimDepositResult>,
+    pub total_fee_sat: u64,
+}
+
+#[frb(mirror(DepositAddressInfo))]
+pub struct _DepositAddressInfo {
+    pub address: String,
+    pub issued_at: Option<u64>,
+    pub deposit_count: u32,
+    pub total_received_sats: u64,
+}
+
+#[frb(mirror(GetDepositAddressHistoryResponse))]
+pub struct _GetDepositAddressHistoryResponse {
+    pub addresses: Vec<DepositAddressInfo>,
+}
+
 #[frb(mirror(Credentials))]
 pub struct _Credentials {
     pub username: String,
@@ -172,6 +301,12 @@ pub struct _DepositInfo {
     pub claim_error: Option<DepositClaimError>,
 }
 
+#[frb(mirror(SendMaxFee))]
+pub enum _SendMaxFee {
+    Fixed { amount: u64 },
+    Proportional { ppm: u32 },
+}
+
 #[frb(mirror(MaxFee))]
 pub enum _MaxFee {
     Fixed { amount: u64 },
@@ -297,9 +432,39 @@ pub struct _UnilateralExitResponse {
     pub transactions: Vec<UnilateralExitTransaction>,
 }
 
+#[frb(mirror(UnilateralExitLeafStage))]
+pub enum _UnilateralExitLeafStage {
+    Pending,
+    LeafConfirmed,
+    RefundConfirmed,
+    Swept,
+}
+
+#[frb(mirror(UnilateralExitLeafProgress))]
+pub struct _UnilateralExitLeafProgress {
+    pub leaf_id: String,
+    pub value: u64,
+    pub stage: UnilateralExitLeafStage,
+}
+
+#[frb(mirror(UnilateralExitProgress))]
+pub struct _UnilateralExitProgress {
+    pub destination: String,
+    pub fee_rate_sat_per_vbyte: u64,
+    pub leaves: Vec<UnilateralExitLeafProgress>,
+    pub transactions: Vec<UnilateralExitTransaction>,
+    pub built_at: u64,
+}
+
+#[frb(mirror(GetUnilateralExitProgressResponse))]
+pub struct _GetUnilateralExitProgressResponse {
+    pub progress: Option<UnilateralExitProgress>,
+}
+
 #[frb(mirror(GetInfoRequest))]
 pub struct _GetInfoRequest {
     pub ensure_synced: Option<bool>,
+    pub include_hidden: Option<bool>,
 }
 
 #[frb(mirror(GetInfoResponse))]
@@ -307,6 +472,11 @@ pub struct _GetInfoResponse {
     pub identity_pubkey: String,
     pub balance_sats: u64,
     pub token_balances: HashMap<String, TokenBalance>,
+    pub hidden_tokens: Vec<String>,
+    pub leaf_stats: LeafStats,
+    pub claim_queue_depth: u32,
+    pub wallet_frozen: bool,
+    pub plugin_balances: Vec<PluginBalance>,
 }
 
 #[frb(mirror(TokenBalance))]
@@ -315,6 +485,24 @@ pub struct _TokenBalance {
     pub token_metadata: TokenMetadata,
 }
 
+#[frb(mirror(HideTokenRequest))]
+pub struct _HideTokenRequest {
+    pub token_identifier: String,
+}
+
+#[frb(mirror(UnhideTokenRequest))]
+pub struct _UnhideTokenRequest {
+    pub token_identifier: String,
+}
+
+#[frb(mirror(AmountRounding))]
+pub enum _AmountRounding {
+    Down,
+    Up,
+    HalfUp,
+    HalfEven,
+}
+
 #[frb(mirror(TokenMetadata))]
 pub struct _TokenMetadata {
     pub identifier: String,
@@ -336,6 +524,138 @@ pub struct _GetPaymentResponse {
     pub payment: Payment,
 }
 
+#[frb(mirror(GetLedgerRequest))]
+pub struct _GetLedgerRequest {
+    pub token_identifier: Option<String>,
+    pub from_timestamp: Option<u64>,
+    pub to_timestamp: Option<u64>,
+}
+
+#[frb(mirror(LedgerEntry))]
+pub struct _LedgerEntry {
+    pub payment_id: String,
+    pub parent_payment_id: Option<String>,
+    pub timestamp: u64,
+    pub payment_type: PaymentType,
+    pub method: PaymentMethod,
+    pub credit: u128,
+    pub debit: u128,
+    pub fees: u128,
+    pub balance: u128,
+}
+
+#[frb(mirror(GetLedgerResponse))]
+pub struct _GetLedgerResponse {
+    pub token_identifier: Option<String>,
+    pub opening_balance: u128,
+    pub closing_balance: u128,
+    pub entries: Vec<LedgerEntry>,
+}
+
+#[frb(mirror(LedgerExportFormat))]
+pub enum _LedgerExportFormat {
+    Csv,
+    Json,
+}
+
+#[frb(mirror(ExportLedgerRequest))]
+pub struct _ExportLedgerRequest {
+    pub format: LedgerExportFormat,
+    pub token_identifier: Option<String>,
+    pub from_timestamp: Option<u64>,
+    pub to_timestamp: Option<u64>,
+}
+
+#[frb(mirror(ExportLedgerResponse))]
+pub struct _ExportLedgerResponse {
+    pub format: LedgerExportFormat,
+    pub data: String,
+}
+
+#[frb(mirror(GetTokenActivityRequest))]
+pub struct _GetTokenActivityRequest {
+    pub token_identifier: String,
+    pub offset: Option<u32>,
+    pub limit: Option<u32>,
+}
+
+#[frb(mirror(TokenActivity))]
+pub enum _TokenActivity {
+    Payment {
+        payment: Payment,
+    },
+    Freeze {
+        address: String,
+        frozen: bool,
+        impacted_token_amount: u128,
+        timestamp: u64,
+    },
+}
+
+#[frb(mirror(GetTokenActivityResponse))]
+pub struct _GetTokenActivityResponse {
+    pub activity: Vec<TokenActivity>,
+}
+
+#[frb(mirror(PaymentExportFormat))]
+pub enum _PaymentExportFormat {
+    Csv,
+    Json,
+    Camt053,
+}
+
+#[frb(mirror(ExportPaymentsRequest))]
+pub struct _ExportPaymentsRequest {
+    pub format: PaymentExportFormat,
+    pub filter: Option<ListPaymentsRequest>,
+}
+
+#[frb(mirror(PaymentExportRecord))]
+pub struct _PaymentExportRecord {
+    pub payment_id: String,
+    pub timestamp: u64,
+    pub payment_type: PaymentType,
+    pub status: PaymentStatus,
+    pub method: PaymentMethod,
+    pub amount: u128,
+    pub fees: u128,
+    pub token_identifier: Option<String>,
+    pub asset_ticker: String,
+    pub asset_decimals: u32,
+    pub fiat_value: Option<PaymentFiatValue>,
+    pub label: Option<String>,
+    pub counterparty: Option<String>,
+}
+
+#[frb(mirror(ExportPaymentsResponse))]
+pub struct _ExportPaymentsResponse {
+    pub format: PaymentExportFormat,
+    pub data: String,
+}
+
+#[frb(mirror(GetAccountingReportRequest))]
+pub struct _GetAccountingReportRequest {
+    pub from_timestamp: Option<u64>,
+    pub to_timestamp: Option<u64>,
+}
+
+#[frb(mirror(AccountingAssetTotals))]
+pub struct _AccountingAssetTotals {
+    pub token_identifier: Option<String>,
+    pub total_in: u128,
+    pub total_out: u128,
+    pub fees_paid: u128,
+    pub received_count: u32,
+    pub sent_count: u32,
+}
+
+#[frb(mirror(GetAccountingReportResponse))]
+pub struct _GetAccountingReportResponse {
+    pub from_timestamp: Option<u64>,
+    pub to_timestamp: Option<u64>,
+    pub assets: Vec<AccountingAssetTotals>,
+}
+
 #[frb(mirror(InputType))]
 pub enum _InputType {
     BitcoinAddress(BitcoinAddressDetails),
@@ -482,6 +802,7 @@ pub struct _LnurlPayInfo {
 pub struct _LnurlPayRequest {
     pub prepare_response: PrepareLnurlPayResponse,
     pub idempotency_key: Option<String>,
+    pub max_fee: Option<SendMaxFee>,
 }
 
 #[frb(mirror(LnurlPayResponse))]
@@ -500,6 +821,15 @@ pub struct _LnurlReceiveMetadata {
     pub nostr_zap_request: Option<String>,
     pub nostr_zap_receipt: Option<String>,
     pub sender_comment: Option<String>,
+    pub lightning_address: Option<String>,
+}
+
+#[frb(mirror(ReceiveMetadata))]
+pub struct _ReceiveMetadata {
+    pub sender_comment: Option<String>,
+    pub nostr_zap_request: Option<String>,
+    pub payer_identity: Option<String>,
+    pub payer_note: Option<String>,
 }
 
 #[frb(mirror(LnurlWithdrawRequest))]
@@ -507,6 +837,7 @@ pub struct _LnurlWithdrawRequest {
     pub amount_sats: u64,
     pub withdraw_request: LnurlWithdrawRequestDetails,
     pub completion_timeout_secs: Option<u32>,
+    pub idempotency_key: Option<String>,
 }
 
 #[frb(mirror(LnurlWithdrawResponse))]
@@ -550,6 +881,15 @@ pub struct _PrepareLnurlPayRequest {
     pub token_identifier: Option<String>,
     pub conversion_options: Option<ConversionOptions>,
     pub fee_policy: Option<FeePolicy>,
+    pub quote_token_identifier: Option<String>,
+}
+
+#[frb(mirror(LnurlPayTokenQuote))]
+pub struct _LnurlPayTokenQuote {
+    pub token_identifier: String,
+    pub token_amount: u128,
+    pub amount_sats: u64,
+    pub sats_per_token: f64,
 }
 
 #[frb(mirror(PrepareLnurlPayResponse))]
@@ -562,6 +902,8 @@ pub struct _PrepareLnurlPayResponse {
     pub success_action: Option<SuccessAction>,
     pub conversion_estimate: Option<ConversionEstimate>,
     pub fee_policy: FeePolicy,
+    pub token_quote: Option<LnurlPayTokenQuote>,
+    pub duplicate_payment: Option<DuplicatePayment>,
 }
 
 #[frb(mirror(PaymentRequest))]
@@ -752,6 +1094,14 @@ pub struct _PrepareSendPaymentRequest {
     pub token_identifier: Option<String>,
     pub conversion_options: Option<ConversionOptions>,
     pub fee_policy: Option<FeePolicy>,
+    pub fiat_amount: Option<LockedFiatAmount>,
+}
+
+#[frb(mirror(DuplicatePayment))]
+pub struct _DuplicatePayment {
+    pub payment_id: String,
+    pub status: PaymentStatus,
+    pub timestamp: u64,
 }
 
 #[frb(mirror(PrepareSendPaymentResponse))]
@@ -761,6 +1111,7 @@ pub struct _PrepareSendPaymentResponse {
     pub token_identifier: Option<String>,
     pub conversion_estimate: Option<ConversionEstimate>,
     pub fee_policy: FeePolicy,
+    pub duplicate_payment: Option<DuplicatePayment>,
 }
 
 #[frb(mirror(ReceivePaymentMethod))]
@@ -772,6 +1123,7 @@ pub enum _ReceivePaymentMethod {
         expiry_time: Option<u64>,
         description: Option<String>,
         sender_public_key: Option<String>,
+        accept_partial_payments: Option<bool>,
     },
     BitcoinAddress {
         new_address: Option<bool>,
@@ -809,6 +1161,27 @@ pub struct _RefundDepositResponse {
     pub tx_hex: String,
 }
 
+#[frb(mirror(BumpRefundFeeRequest))]
+pub struct _BumpRefundFeeRequest {
+    pub tx_id: String,
+    pub fee: Fee,
+}
+
+#[frb(mirror(BumpRefundFeeResponse))]
+pub struct _BumpRefundFeeResponse {
+    pub tx_id: String,
+    pub tx_hex: String,
+}
+
+#[frb(mirror(DepositRefund))]
+pub struct _DepositRefund {
+    pub deposit_txid: String,
+    pub deposit_vout: u32,
+    pub tx_id: String,
+    pub destination_address: String,
+    pub fee_sats: u64,
+}
+
 #[frb(mirror(SendOnchainFeeQuote))]
 pub struct _SendOnchainFeeQuote {
     pub id: String,
@@ -859,6 +1232,11 @@ pub enum _SendPaymentMethod {
         expires_at: String,
         provider_context: CrossChainProviderContext,
     },
+    Plugin {
+        plugin_name: String,
+        destination: String,
+        quote: PluginSendQuote,
+    },
 }
 
 #[frb(mirror(SendPaymentOptions))]
@@ -873,6 +1251,10 @@ pub enum _SendPaymentOptions {
     SparkAddress {
         htlc_options: Option<SparkHtlcOptions>,
     },
+    SparkInvoice {
+        min_amount: Option<u128>,
+        max_amount: Option<u128>,
+    },
 }
 
 #[frb(mirror(SparkHtlcOptions))]
@@ -886,6 +1268,97 @@ pub struct _SendPaymentRequest {
     pub prepare_response: PrepareSendPaymentResponse,
     pub options: Option<SendPaymentOptions>,
     pub idempotency_key: Option<String>,
+    pub max_fee: Option<SendMaxFee>,
+    pub deliver_after: Option<u64>,
+    pub leaf_selection: Option<SendLeafSelection>,
+}
+
+#[frb(mirror(SendLeafSelection))]
+pub enum _SendLeafSelection {
+    Specific { leaf_ids: Vec<String> },
+    Strategy { strategy: LeafSelectionStrategy },
+}
+
+#[frb(mirror(LeafSelectionStrategy))]
+pub enum _LeafSelectionStrategy {
+    MinimizeFee,
+    MinimizeInputs,
+    Privacy,
+}
+
+#[frb(mirror(SimulateSendPaymentRequest))]
+pub struct _SimulateSendPaymentRequest {
+    pub prepare_response: PrepareSendPaymentResponse,
+    pub options: Option<SendPaymentOptions>,
+    pub max_fee: Option<SendMaxFee>,
+}
+
+#[frb(mirror(SimulateSendPaymentResponse))]
+pub struct _SimulateSendPaymentResponse {
+    pub will_succeed: bool,
+    pub amount: u128,
+    pub fee: u128,
+    pub token_identifier: Option<String>,
+    pub leaves: Vec<SimulatedLeaf>,
+    pub requires_swap: bool,
+    pub estimated_completion_secs: Option<u64>,
+    pub failure_reasons: Vec<SendFailureReason>,
+}
+
+#[frb(mirror(SimulatedLeaf))]
+pub struct _SimulatedLeaf {
+    pub id: String,
+    pub value: u64,
+}
+
+#[frb(mirror(SendFailureReason))]
+pub enum _SendFailureReason {
+    InsufficientFunds {
+        required: u128,
+        available: u128,
+    },
+    MaxFeeExceeded {
+        fee_sats: u64,
+        max_fee_sats: u64,
+    },
+    BelowDustLimit {
+        amount_sats: u64,
+        dust_limit_sats: u64,
+    },
+    QuoteExpired,
+    InvalidOptions {
+        reason: String,
+    },
+}
+
+#[frb(mirror(PaymentHandle))]
+pub struct _PaymentHandle {
+    pub id: String,
+}
+
+#[frb(mirror(PaymentProgressStage))]
+pub enum _PaymentProgressStage {
+    Queued,
+    TransferInitiated,
+    PreimageReceived { preimage: String },
+    Settled { payment: Payment },
+    Failed { error: String },
+    Cancelled,
+}
+
+#[frb(mirror(CancelPendingPaymentRequest))]
+pub struct _CancelPendingPaymentRequest {
+    pub handle: PaymentHandle,
+}
+
+#[frb(mirror(CancelPaymentRequest))]
+pub struct _CancelPaymentRequest {
+    pub payment_id: String,
+}
+
+#[frb(mirror(CancelPaymentResponse))]
+pub struct _CancelPaymentResponse {
+    pub payment: Payment,
 }
 
 #[frb(mirror(PublishSignedTransferPackageRequest))]
@@ -947,11 +1420,83 @@ pub enum _SuccessActionProcessed {
 }
 
 #[frb(mirror(SyncWalletRequest))]
-pub struct _SyncWalletRequest {}
+pub struct _SyncWalletRequest {
+    pub domains: Option<Vec<SyncDomain>>,
+}
 
 #[frb(mirror(SyncWalletResponse))]
 pub struct _SyncWalletResponse {}
 
+#[frb(mirror(SyncDomain))]
+pub enum _SyncDomain {
+    Payments,
+    Tokens,
+    Deposits,
+    LightningAddress,
+}
+
+#[frb(mirror(SyncStage))]
+pub enum _SyncStage {
+    Wallet,
+    Payments,
+    Tokens,
+    Deposits,
+    LightningAddress,
+}
+
+#[frb(mirror(SyncProgress))]
+pub struct _SyncProgress {
+    pub stage: SyncStage,
+    pub succeeded: bool,
+    pub percent: u8,
+}
+
+#[frb(mirror(DomainSyncStatus))]
+pub struct _DomainSyncStatus {
+    pub domain: SyncDomain,
+    pub last_synced_at: Option<u64>,
+}
+
+#[frb(mirror(GetSyncStatusResponse))]
+pub struct _GetSyncStatusResponse {
+    pub domains: Vec<DomainSyncStatus>,
+}
+
+#[frb(mirror(SyncRecordType))]
+pub enum _SyncRecordType {
+    PaymentMetadata,
+    Contact,
+    LightningAddress,
+    CrossChainSwap,
+    Device,
+    UserSettings,
+}
+
+#[frb(mirror(SyncConflict))]
+pub struct _SyncConflict {
+    pub record_type: SyncRecordType,
+    pub record_id: String,
+    pub kept_local_fields: Vec<String>,
+}
+
+#[frb(mirror(DeviceInfo))]
+pub struct _DeviceInfo {
+    pub id: String,
+    pub name: Option<String>,
+    pub last_seen_at: u64,
+    pub is_current: bool,
+}
+
+#[frb(mirror(ListDevicesResponse))]
+pub struct _ListDevicesResponse {
+    pub devices: Vec<DeviceInfo>,
+}
+
+#[frb(mirror(SetDeviceNameRequest))]
+pub struct _SetDeviceNameRequest {
+    pub name: String,
+}
+
 #[frb(mirror(AesSuccessActionData))]
 pub struct _AesSuccessActionData {
     pub description: String,
@@ -1013,6 +1558,16 @@ pub struct _Payment {
     pub method: PaymentMethod,
     pub details: Option<PaymentDetails>,
     pub conversion_details: Option<ConversionDetails>,
+    pub fiat_value: Option<PaymentFiatValue>,
+    pub receive_metadata: Option<ReceiveMetadata>,
+}
+
+#[frb(mirror(PaymentFiatValue))]
+pub struct _PaymentFiatValue {
+    pub currency: String,
+    pub rate: f64,
+    pub amount: f64,
+    pub recorded_at: u64,
 }
 
 #[frb(mirror(ConversionDetails))]
@@ -1144,6 +1699,7 @@ pub enum _PaymentMethod {
     Token,
     Deposit,
     Withdraw,
+    Plugin,
     Unknown,
 }
 
@@ -1350,6 +1906,8 @@ pub struct _LnurlWithdrawRequestDetails {
 pub struct _SilentPaymentAddressDetails {
     pub address: String,
     pub network: BitcoinNetwork,
+    pub scan_public_key: String,
+    pub spend_public_key: String,
     pub source: PaymentRequestSource,
 }
 
@@ -1362,6 +1920,22 @@ pub struct _CheckLightningAddressRequest {
 pub struct _RegisterLightningAddressRequest {
     pub username: String,
     pub description: Option<String>,
+    pub label: Option<String>,
+    pub image: Option<String>,
+    pub long_description: Option<String>,
+    pub min_sendable_sats: Option<u64>,
+    pub max_sendable_sats: Option<u64>,
+}
+
+#[frb(mirror(DeleteLabeledLightningAddressRequest))]
+pub struct _DeleteLabeledLightningAddressRequest {
+    pub label: String,
+}
+
+#[frb(mirror(UpdateLightningAddressNostrKeyRequest))]
+pub struct _UpdateLightningAddressNostrKeyRequest {
+    pub nostr_pubkey: Option<String>,
+    pub username: Option<String>,
 }
 
 #[frb(mirror(TransferAuthorization))]
@@ -1394,28 +1968,227 @@ pub struct _LightningAddressInfo {
     pub lightning_address: String,
     pub lnurl: LnurlInfo,
     pub username: String,
+    pub label: Option<String>,
 }
 
-#[frb(mirror(ListFiatCurrenciesResponse))]
-pub struct _ListFiatCurrenciesResponse {
-    pub currencies: Vec<FiatCurrency>,
+#[frb(mirror(HandleIncomingUriRequest))]
+pub struct _HandleIncomingUriRequest {
+    pub uri: String,
 }
 
-#[frb(mirror(ListFiatRatesResponse))]
-pub struct _ListFiatRatesResponse {
-    pub rates: Vec<Rate>,
+#[frb(mirror(HandleIncomingUriResponse))]
+pub struct _HandleIncomingUriResponse {
+    pub input_type: InputType,
+    pub action: IncomingUriAction,
 }
 
-#[frb(mirror(Rate))]
-pub struct _Rate {
-    pub coin: String,
-    pub value: f64,
+#[frb(mirror(IncomingUriAction))]
+pub enum _IncomingUriAction {
+    PrepareSendPayment {
+        input: String,
+        amount_sats: Option<u64>,
+    },
+    PrepareLnurlPay {
+        pay_request: LnurlPayRequestDetails,
+    },
+    LnurlWithdraw {
+        withdraw_request: LnurlWithdrawRequestDetails,
+    },
+    LnurlAuth {
+        request_data: LnurlAuthRequestDetails,
+    },
+    GetCrossChainRoutes {
+        address: CrossChainAddressDetails,
+    },
+    OpenUrl {
+        url: String,
+    },
+    Unsupported {
+        reason: String,
+    },
 }
 
-#[frb(mirror(FiatCurrency))]
-pub struct _FiatCurrency {
-    pub id: String,
-    pub info: CurrencyInfo,
+#[frb(mirror(CreatePaymentLinkRequest))]
+pub struct _CreatePaymentLinkRequest {
+    pub amount_sats: u64,
+    pub memo: String,
+}
+
+#[frb(mirror(CreatePaymentLinkResponse))]
+pub struct _CreatePaymentLinkResponse {
+    pub link: PaymentLink,
+}
+
+#[frb(mirror(ListPaymentLinksRequest))]
+pub struct _ListPaymentLinksRequest {
+    pub offset: Option<u32>,
+    pub limit: Option<u32>,
+}
+
+#[frb(mirror(ListPaymentLinksResponse))]
+pub struct _ListPaymentLinksResponse {
+    pub links: Vec<PaymentLink>,
+}
+
+#[frb(mirror(DeletePaymentLinkRequest))]
+pub struct _DeletePaymentLinkRequest {
+    pub id: String,
+}
+
+#[frb(mirror(PaymentLink))]
+pub struct _PaymentLink {
+    pub id: String,
+    pub url: String,
+    pub amount_sats: u64,
+    pub memo: String,
+    pub created_at: u64,
+    pub status: PaymentLinkStatus,
+    pub payment_id: Option<String>,
+}
+
+#[frb(mirror(PaymentLinkStatus))]
+pub enum _PaymentLinkStatus {
+    Pending,
+    Paid,
+}
+
+#[frb(mirror(ListFiatCurrenciesResponse))]
+pub struct _ListFiatCurrenciesResponse {
+    pub currencies: Vec<FiatCurrency>,
+}
+
+#[frb(mirror(ListFiatRatesResponse))]
+pub struct _ListFiatRatesResponse {
+    pub rates: Vec<Rate>,
+}
+
+#[frb(mirror(Rate))]
+pub struct _Rate {
+    pub coin: String,
+    pub value: f64,
+}
+
+#[frb(mirror(FetchHistoricalRatesRequest))]
+pub struct _FetchHistoricalRatesRequest {
+    pub currency: String,
+    pub from_timestamp: u64,
+    pub to_timestamp: u64,
+    pub resolution: RateResolution,
+}
+
+#[frb(mirror(FetchHistoricalRatesResponse))]
+pub struct _FetchHistoricalRatesResponse {
+    pub rates: Vec<HistoricalRate>,
+}
+
+#[frb(mirror(TokenFiatRates))]
+pub struct _TokenFiatRates {
+    pub token_identifier: String,
+    pub rates: Vec<Rate>,
+}
+
+#[frb(mirror(GetTokenFiatRatesRequest))]
+pub struct _GetTokenFiatRatesRequest {
+    pub token_identifiers: Vec<String>,
+}
+
+#[frb(mirror(GetTokenFiatRatesResponse))]
+pub struct _GetTokenFiatRatesResponse {
+    pub rates: Vec<TokenFiatRates>,
+}
+
+#[frb(mirror(LockExchangeRateRequest))]
+pub struct _LockExchangeRateRequest {
+    pub currency: String,
+    pub ttl_secs: Option<u32>,
+}
+
+#[frb(mirror(LockExchangeRateResponse))]
+pub struct _LockExchangeRateResponse {
+    pub locked_rate: LockedExchangeRate,
+}
+
+#[frb(mirror(LockedExchangeRate))]
+pub struct _LockedExchangeRate {
+    pub id: String,
+    pub currency: String,
+    pub rate: f64,
+    pub expires_at: u64,
+}
+
+#[frb(mirror(ConvertFiatAmountRequest))]
+pub struct _ConvertFiatAmountRequest {
+    pub locked_rate_id: String,
+    pub fiat_amount: f64,
+}
+
+#[frb(mirror(LockedFiatAmount))]
+pub struct _LockedFiatAmount {
+    pub locked_rate_id: String,
+    pub fiat_amount: f64,
+}
+
+#[frb(mirror(ConvertFiatAmountResponse))]
+pub struct _ConvertFiatAmountResponse {
+    pub amount_sats: u64,
+    pub locked_rate: LockedExchangeRate,
+}
+
+#[frb(mirror(ReceiveFiatPaymentRequest))]
+pub struct _ReceiveFiatPaymentRequest {
+    pub payment_method: FiatReceiveMethod,
+    pub currency: String,
+    pub fiat_amount: f64,
+    pub locked_rate_id: Option<String>,
+}
+
+#[frb(mirror(FiatReceiveMethod))]
+pub enum _FiatReceiveMethod {
+    Bolt11Invoice {
+        description: String,
+        expiry_secs: Option<u32>,
+    },
+    SparkInvoice {
+        description: Option<String>,
+        expiry_time: Option<u64>,
+        sender_public_key: Option<String>,
+    },
+}
+
+#[frb(mirror(ReceiveFiatPaymentResponse))]
+pub struct _ReceiveFiatPaymentResponse {
+    pub payment_request: String,
+    pub fee: u128,
+    pub amount_sats: u64,
+    pub fiat_value: PaymentFiatValue,
+}
+
+#[frb(mirror(SetLogFilterRequest))]
+pub struct _SetLogFilterRequest {
+    pub filter: String,
+}
+
+#[frb(mirror(DumpDiagnosticsResponse))]
+pub struct _DumpDiagnosticsResponse {
+    pub bundle: String,
+}
+
+#[frb(mirror(RateResolution))]
+pub enum _RateResolution {
+    Hour,
+    Day,
+}
+
+#[frb(mirror(HistoricalRate))]
+pub struct _HistoricalRate {
+    pub timestamp: u64,
+    pub value: f64,
+}
+
+#[frb(mirror(FiatCurrency))]
+pub struct _FiatCurrency {
+    pub id: String,
+    pub info: CurrencyInfo,
 }
 
 #[frb(mirror(CurrencyInfo))]
@@ -1460,6 +2233,16 @@ pub struct _GetTokensMetadataResponse {
     pub tokens_metadata: Vec<TokenMetadata>,
 }
 
+#[frb(mirror(RefreshTokensMetadataRequest))]
+pub struct _RefreshTokensMetadataRequest {
+    pub token_identifiers: Vec<String>,
+}
+
+#[frb(mirror(RefreshTokensMetadataResponse))]
+pub struct _RefreshTokensMetadataResponse {
+    pub tokens_metadata: Vec<TokenMetadata>,
+}
+
 #[frb(mirror(RecordId))]
 pub struct _RecordId {
     pub r#type: String,
@@ -1506,6 +2289,7 @@ pub struct _RecordChange {
 pub struct _UserSettings {
     pub spark_private_mode_enabled: bool,
     pub stable_balance_active_label: Option<String>,
+    pub display_currency: Option<String>,
 }
 
 #[frb(mirror(StableBalanceActiveLabel))]
@@ -1514,10 +2298,17 @@ pub enum _StableBalanceActiveLabel {
     Unset,
 }
 
+#[frb(mirror(DisplayCurrency))]
+pub enum _DisplayCurrency {
+    Set { currency: String },
+    Unset,
+}
+
 #[frb(mirror(UpdateUserSettingsRequest))]
 pub struct _UpdateUserSettingsRequest {
     pub spark_private_mode_enabled: Option<bool>,
     pub stable_balance_active_label: Option<StableBalanceActiveLabel>,
+    pub display_currency: Option<DisplayCurrency>,
 }
 
 #[frb(mirror(CreateIssuerTokenRequest))]
@@ -1532,11 +2323,13 @@ pub struct _CreateIssuerTokenRequest {
 #[frb(mirror(MintIssuerTokenRequest))]
 pub struct _MintIssuerTokenRequest {
     pub amount: u128,
+    pub idempotency_key: Option<String>,
 }
 
 #[frb(mirror(BurnIssuerTokenRequest))]
 pub struct _BurnIssuerTokenRequest {
     pub amount: u128,
+    pub idempotency_key: Option<String>,
 }
 
 #[frb(mirror(FreezeIssuerTokenRequest))]
@@ -1561,6 +2354,68 @@ pub struct _UnfreezeIssuerTokenResponse {
     pub impacted_token_amount: u128,
 }
 
+#[frb(mirror(GetIssuanceStatsRequest))]
+pub struct _GetIssuanceStatsRequest {
+    pub period_secs: Option<u64>,
+}
+
+#[frb(mirror(IssuanceStats))]
+pub struct _IssuanceStats {
+    pub token_identifier: String,
+    pub max_supply: u128,
+    pub total_minted: u128,
+    pub total_burned: u128,
+    pub total_supply: u128,
+    pub circulating_supply: u128,
+    pub holder_count: u32,
+    pub frozen_address_count: u32,
+    pub history: Vec<IssuancePeriod>,
+}
+
+#[frb(mirror(IssuancePeriod))]
+pub struct _IssuancePeriod {
+    pub start: u64,
+    pub minted: u128,
+    pub burned: u128,
+    pub total_supply: u128,
+}
+
+#[frb(mirror(TokenRecipient))]
+pub struct _TokenRecipient {
+    pub address: String,
+    pub amount: u128,
+}
+
+#[frb(mirror(DistributeTokensRequest))]
+pub struct _DistributeTokensRequest {
+    pub recipients: Vec<TokenRecipient>,
+    pub distribution_id: Option<String>,
+    pub mint_missing_supply: Option<bool>,
+    pub chunk_size: Option<u32>,
+}
+
+#[frb(mirror(DistributeTokensResponse))]
+pub struct _DistributeTokensResponse {
+    pub distribution_id: String,
+    pub mint_payment_id: Option<String>,
+    pub results: Vec<TokenRecipientResult>,
+}
+
+#[frb(mirror(TokenRecipientResult))]
+pub struct _TokenRecipientResult {
+    pub address: String,
+    pub amount: u128,
+    pub status: TokenRecipientStatus,
+}
+
+#[frb(mirror(TokenRecipientStatus))]
+pub enum _TokenRecipientStatus {
+    Pending,
+    Sent { payment_id: String },
+    Failed { error: String },
+    Unknown,
+}
+
 #[frb(mirror(RecommendedFees))]
 pub struct _RecommendedFees {
     pub fastest_fee: u64,
@@ -1586,6 +2441,296 @@ pub struct _ClaimHtlcPaymentResponse {
     pub payment: Payment,
 }
 
+#[frb(mirror(SettleHeldPaymentRequest))]
+pub struct _SettleHeldPaymentRequest {
+    pub payment_hash: String,
+    pub preimage: String,
+}
+
+#[frb(mirror(SettleHeldPaymentResponse))]
+pub struct _SettleHeldPaymentResponse {
+    pub payment: Payment,
+}
+
+#[frb(mirror(CancelHeldPaymentRequest))]
+pub struct _CancelHeldPaymentRequest {
+    pub payment_hash: String,
+}
+
+#[frb(mirror(RefundHtlcPaymentRequest))]
+pub struct _RefundHtlcPaymentRequest {
+    pub payment_id: String,
+}
+
+#[frb(mirror(RefundHtlcPaymentResponse))]
+pub struct _RefundHtlcPaymentResponse {
+    pub payment: Payment,
+}
+
+#[frb(mirror(Escrow))]
+pub struct _Escrow {
+    pub id: String,
+    pub payment_hash: String,
+    pub role: EscrowRole,
+    pub counterparty_address: String,
+    pub send_amount_sats: u64,
+    pub receive_amount_sats: u64,
+    pub send_payment_id: String,
+    pub send_expiry_time: u64,
+    pub receive_payment_id: Option<String>,
+    pub status: EscrowStatus,
+    pub created_at: u64,
+}
+
+#[frb(mirror(EscrowRole))]
+pub enum _EscrowRole {
+    Initiator,
+    Participant,
+}
+
+#[frb(mirror(EscrowStatus))]
+pub enum _EscrowStatus {
+    Funded,
+    CounterpartyFunded,
+    Released,
+    Refunded,
+}
+
+#[frb(mirror(CreateEscrowRequest))]
+pub struct _CreateEscrowRequest {
+    pub payment_hash: String,
+    pub role: EscrowRole,
+    pub counterparty_address: String,
+    pub send_amount_sats: u64,
+    pub receive_amount_sats: u64,
+    pub expiry_duration_secs: u64,
+}
+
+#[frb(mirror(CreateEscrowResponse))]
+pub struct _CreateEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[frb(mirror(GetEscrowRequest))]
+pub struct _GetEscrowRequest {
+    pub escrow_id: String,
+}
+
+#[frb(mirror(GetEscrowResponse))]
+pub struct _GetEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[frb(mirror(ListEscrowsResponse))]
+pub struct _ListEscrowsResponse {
+    pub escrows: Vec<Escrow>,
+}
+
+#[frb(mirror(ReleaseEscrowRequest))]
+pub struct _ReleaseEscrowRequest {
+    pub escrow_id: String,
+    pub preimage: Option<String>,
+}
+
+#[frb(mirror(ReleaseEscrowResponse))]
+pub struct _ReleaseEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[frb(mirror(RefundEscrowRequest))]
+pub struct _RefundEscrowRequest {
+    pub escrow_id: String,
+}
+
+#[frb(mirror(RefundEscrowResponse))]
+pub struct _RefundEscrowResponse {
+    pub escrow: Escrow,
+}
+
+#[frb(mirror(ArbitratedEscrow))]
+pub struct _ArbitratedEscrow {
+    pub id: String,
+    pub payment_hash: String,
+    pub seller_address: String,
+    pub arbiter_pubkey: String,
+    pub arbiter_secret: String,
+    pub amount_sats: u64,
+    pub payment_id: String,
+    pub expiry_time: u64,
+    pub status: ArbitratedEscrowStatus,
+    pub dispute_reason: Option<String>,
+    pub buyer_share: Option<String>,
+    pub seller_share: Option<String>,
+    pub created_at: u64,
+}
+
+#[frb(mirror(ArbitratedEscrowStatus))]
+pub enum _ArbitratedEscrowStatus {
+    Funded,
+    Disputed,
+    Released,
+    RefundApproved,
+    Claimed,
+    Refunded,
+}
+
+#[frb(mirror(CreateArbitratedEscrowRequest))]
+pub struct _CreateArbitratedEscrowRequest {
+    pub seller_address: String,
+    pub arbiter_pubkey: String,
+    pub amount_sats: u64,
+    pub expiry_duration_secs: u64,
+}
+
+#[frb(mirror(CreateArbitratedEscrowResponse))]
+pub struct _CreateArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[frb(mirror(ListArbitratedEscrowsResponse))]
+pub struct _ListArbitratedEscrowsResponse {
+    pub escrows: Vec<ArbitratedEscrow>,
+}
+
+#[frb(mirror(ReleaseArbitratedEscrowRequest))]
+pub struct _ReleaseArbitratedEscrowRequest {
+    pub escrow_id: String,
+}
+
+#[frb(mirror(ReleaseArbitratedEscrowResponse))]
+pub struct _ReleaseArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[frb(mirror(DisputeArbitratedEscrowRequest))]
+pub struct _DisputeArbitratedEscrowRequest {
+    pub escrow_id: String,
+    pub reason: String,
+}
+
+#[frb(mirror(DisputeArbitratedEscrowResponse))]
+pub struct _DisputeArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[frb(mirror(RefundArbitratedEscrowRequest))]
+pub struct _RefundArbitratedEscrowRequest {
+    pub escrow_id: String,
+    pub signature: String,
+}
+
+#[frb(mirror(RefundArbitratedEscrowResponse))]
+pub struct _RefundArbitratedEscrowResponse {
+    pub escrow: ArbitratedEscrow,
+}
+
+#[frb(mirror(RecoverArbitratedEscrowPreimageRequest))]
+pub struct _RecoverArbitratedEscrowPreimageRequest {
+    pub shares: Vec<String>,
+}
+
+#[frb(mirror(RecoverArbitratedEscrowPreimageResponse))]
+pub struct _RecoverArbitratedEscrowPreimageResponse {
+    pub preimage: String,
+}
+
+#[frb(mirror(TimeLockedPayment))]
+pub struct _TimeLockedPayment {
+    pub payment_id: String,
+    pub receiver_address: String,
+    pub amount_sats: u64,
+    pub deliver_after: u64,
+    pub expiry_time: u64,
+    pub status: TimeLockedPaymentStatus,
+    pub preimage: Option<String>,
+    pub created_at: u64,
+}
+
+#[frb(mirror(TimeLockedPaymentStatus))]
+pub enum _TimeLockedPaymentStatus {
+    Locked,
+    Deliverable,
+    Claimed,
+    Cancelled,
+    Returned,
+}
+
+#[frb(mirror(ListTimeLockedPaymentsResponse))]
+pub struct _ListTimeLockedPaymentsResponse {
+    pub payments: Vec<TimeLockedPayment>,
+}
+
+#[frb(mirror(CancelTimeLockedPaymentRequest))]
+pub struct _CancelTimeLockedPaymentRequest {
+    pub payment_id: String,
+}
+
+#[frb(mirror(CancelTimeLockedPaymentResponse))]
+pub struct _CancelTimeLockedPaymentResponse {
+    pub payment: TimeLockedPayment,
+}
+
+#[frb(mirror(PaymentStream))]
+pub struct _PaymentStream {
+    pub id: String,
+    pub destination: String,
+    pub rate_sats_per_sec: u64,
+    pub max_amount_sats: u64,
+    pub interval_secs: u32,
+    pub sent_sats: u64,
+    pub status: PaymentStreamStatus,
+    pub payment_ids: Vec<String>,
+    pub error: Option<String>,
+    pub started_at: u64,
+    pub closed_at: Option<u64>,
+}
+
+#[frb(mirror(PaymentStreamStatus))]
+pub enum _PaymentStreamStatus {
+    Open,
+    Closed,
+    CapReached,
+    Failed,
+}
+
+#[frb(mirror(OpenPaymentStreamRequest))]
+pub struct _OpenPaymentStreamRequest {
+    pub destination: String,
+    pub rate_sats_per_sec: u64,
+    pub max_amount_sats: u64,
+    pub interval_secs: Option<u32>,
+}
+
+#[frb(mirror(OpenPaymentStreamResponse))]
+pub struct _OpenPaymentStreamResponse {
+    pub stream: PaymentStream,
+}
+
+#[frb(mirror(ClosePaymentStreamRequest))]
+pub struct _ClosePaymentStreamRequest {
+    pub stream_id: String,
+}
+
+#[frb(mirror(ClosePaymentStreamResponse))]
+pub struct _ClosePaymentStreamResponse {
+    pub stream: PaymentStream,
+}
+
+#[frb(mirror(ListPaymentStreamsResponse))]
+pub struct _ListPaymentStreamsResponse {
+    pub streams: Vec<PaymentStream>,
+}
+
+#[frb(mirror(ClaimSpecificTransferRequest))]
+pub struct _ClaimSpecificTransferRequest {
+    pub transfer_id: String,
+}
+
+#[frb(mirror(ClaimSpecificTransferResponse))]
+pub struct _ClaimSpecificTransferResponse {
+    pub payment: Option<Payment>,
+}
+
 #[frb(mirror(OptimizationMode))]
 pub enum _OptimizationMode {
     Full,
@@ -1608,6 +2753,44 @@ pub struct _OptimizeLeavesResponse {
     pub outcome: OptimizationOutcome,
 }
 
+#[frb(mirror(LeafState))]
+pub enum _LeafState {
+    Available,
+    LockedForSwap,
+    PendingTransfer,
+    Unavailable,
+}
+
+#[frb(mirror(LeafInfo))]
+pub struct _LeafInfo {
+    pub id: String,
+    pub value_sats: u64,
+    pub state: LeafState,
+    pub age_secs: Option<u64>,
+}
+
+#[frb(mirror(LeafDenomination))]
+pub struct _LeafDenomination {
+    pub value_sats: u64,
+    pub count: u32,
+}
+
+#[frb(mirror(LeafStats))]
+pub struct _LeafStats {
+    pub leaf_count: u32,
+    pub available_leaf_count: u32,
+    pub locked_leaf_count: u32,
+    pub smallest_available_sats: Option<u64>,
+    pub largest_available_sats: Option<u64>,
+    pub available_denominations: Vec<LeafDenomination>,
+}
+
+#[frb(mirror(ListLeavesResponse))]
+pub struct _ListLeavesResponse {
+    pub leaves: Vec<LeafInfo>,
+    pub stats: LeafStats,
+}
+
 #[frb(mirror(ConversionEstimate))]
 pub struct _ConversionEstimate {
     pub options: ConversionOptions,
@@ -1740,11 +2923,184 @@ pub struct _SparkStatus {
     pub last_updated: u64,
 }
 
+#[frb(mirror(HealthDependency))]
+pub enum _HealthDependency {
+    SparkOperators,
+    ServiceProvider,
+    ChainService,
+    LnurlService,
+    FiatService,
+    ApiKey,
+}
+
+#[frb(mirror(HealthStatus))]
+pub enum _HealthStatus {
+    Healthy,
+    Unhealthy { error: String },
+    Unknown { reason: String },
+    NotConfigured,
+}
+
+#[frb(mirror(DependencyHealth))]
+pub struct _DependencyHealth {
+    pub dependency: HealthDependency,
+    pub status: HealthStatus,
+    pub latency_ms: Option<u64>,
+}
+
+#[frb(mirror(CheckHealthResponse))]
+pub struct _CheckHealthResponse {
+    pub healthy: bool,
+    pub dependencies: Vec<DependencyHealth>,
+}
+
+#[frb(mirror(RailStatus))]
+pub struct _RailStatus {
+    pub rail: PaymentRail,
+    pub status: ServiceStatus,
+}
+
+#[frb(mirror(MaintenanceWindow))]
+pub struct _MaintenanceWindow {
+    pub title: String,
+    pub rails: Vec<PaymentRail>,
+    pub starts_at: u64,
+    pub ends_at: Option<u64>,
+}
+
+#[frb(mirror(ServiceStatusReport))]
+pub struct _ServiceStatusReport {
+    pub status: ServiceStatus,
+    pub rails: Vec<RailStatus>,
+    pub maintenance_windows: Vec<MaintenanceWindow>,
+    pub last_updated: u64,
+}
+
+#[frb(mirror(JobRequest))]
+pub enum _JobRequest {
+    OptimizeLeaves,
+    ExportPayments { request: ExportPaymentsRequest },
+    ExportLedger { request: ExportLedgerRequest },
+    UnilateralExit,
+    Conversion { request: SendPaymentRequest },
+}
+
+#[frb(mirror(JobKind))]
+pub enum _JobKind {
+    OptimizeLeaves,
+    ExportPayments,
+    ExportLedger,
+    UnilateralExit,
+    Conversion,
+}
+
+#[frb(mirror(JobResult))]
+pub enum _JobResult {
+    OptimizeLeaves { rounds_executed: u32 },
+    ExportPayments { response: ExportPaymentsResponse },
+    ExportLedger { response: ExportLedgerResponse },
+    UnilateralExit { progress: UnilateralExitProgress },
+    Conversion { response: SendPaymentResponse },
+}
+
+#[frb(mirror(JobStatus))]
+pub enum _JobStatus {
+    Running,
+    Completed { result: JobResult },
+    Failed { error: String },
+    Cancelled,
+}
+
+#[frb(mirror(Job))]
+pub struct _Job {
+    pub id: String,
+    pub kind: JobKind,
+    pub status: JobStatus,
+    pub completed_steps: u32,
+    pub total_steps: Option<u32>,
+    pub created_at: u64,
+    pub updated_at: u64,
+}
+
+#[frb(mirror(StartJobRequest))]
+pub struct _StartJobRequest {
+    pub job: JobRequest,
+}
+
+#[frb(mirror(StartJobResponse))]
+pub struct _StartJobResponse {
+    pub job_id: String,
+}
+
+#[frb(mirror(GetJobRequest))]
+pub struct _GetJobRequest {
+    pub job_id: String,
+}
+
+#[frb(mirror(GetJobResponse))]
+pub struct _GetJobResponse {
+    pub job: Job,
+}
+
+#[frb(mirror(ListJobsResponse))]
+pub struct _ListJobsResponse {
+    pub jobs: Vec<Job>,
+}
+
+#[frb(mirror(CancelJobRequest))]
+pub struct _CancelJobRequest {
+    pub job_id: String,
+}
+
+#[frb(mirror(ReceiveOnboardingInfo))]
+pub struct _ReceiveOnboardingInfo {
+    pub rail: PaymentRail,
+    pub enabled: bool,
+    pub min_amount_sats: u64,
+    pub estimated_fee_sats: u64,
+    pub required_confirmations: u32,
+    pub estimated_settlement_secs: u64,
+    pub claimed_automatically: bool,
+}
+
+#[frb(mirror(GetReceiveOnboardingInfoResponse))]
+pub struct _GetReceiveOnboardingInfoResponse {
+    pub rails: Vec<ReceiveOnboardingInfo>,
+}
+
+#[frb(mirror(PluginCapabilities))]
+pub struct _PluginCapabilities {
+    pub balance_provider: bool,
+    pub payment_rail: bool,
+}
+
+#[frb(mirror(PluginInfo))]
+pub struct _PluginInfo {
+    pub name: String,
+    pub capabilities: PluginCapabilities,
+}
+
+#[frb(mirror(PluginBalance))]
+pub struct _PluginBalance {
+    pub plugin_name: String,
+    pub balance_sats: Option<u64>,
+}
+
+#[frb(mirror(PluginSendQuote))]
+pub struct _PluginSendQuote {
+    pub amount_sats: u64,
+    pub fee_sats: u64,
+    pub data: String,
+}
+
 #[frb(mirror(Contact))]
 pub struct _Contact {
     pub id: String,
     pub name: String,
     pub payment_identifier: String,
+    pub additional_payment_identifiers: Vec<String>,
+    pub avatar_url: Option<String>,
+    pub notes: Option<String>,
     pub created_at: u64,
     pub updated_at: u64,
 }
@@ -1753,6 +3109,9 @@ pub struct _Contact {
 pub struct _AddContactRequest {
     pub name: String,
     pub payment_identifier: String,
+    pub additional_payment_identifiers: Vec<String>,
+    pub avatar_url: Option<String>,
+    pub notes: Option<String>,
 }
 
 #[frb(mirror(UpdateContactRequest))]
@@ -1760,6 +3119,47 @@ pub struct _UpdateContactRequest {
     pub id: String,
     pub name: String,
     pub payment_identifier: String,
+    pub additional_payment_identifiers: Vec<String>,
+    pub avatar_url: Option<String>,
+    pub notes: Option<String>,
+}
+
+#[frb(mirror(GetContactPaymentsRequest))]
+pub struct _GetContactPaymentsRequest {
+    pub contact_id: String,
+    pub offset: Option<u32>,
+    pub limit: Option<u32>,
+}
+
+#[frb(mirror(GetContactPaymentsResponse))]
+pub struct _GetContactPaymentsResponse {
+    pub payments: Vec<Payment>,
+}
+
+#[frb(mirror(SuggestContactsRequest))]
+pub struct _SuggestContactsRequest {
+    pub min_payment_count: Option<u32>,
+    pub limit: Option<u32>,
+}
+
+#[frb(mirror(SuggestContactsResponse))]
+pub struct _SuggestContactsResponse {
+    pub suggestions: Vec<ContactSuggestion>,
+}
+
+#[frb(mirror(ContactSuggestion))]
+pub struct _ContactSuggestion {
+    pub payment_identifier: String,
+    pub kind: ContactSuggestionKind,
+    pub payment_count: u32,
+    pub last_paid_at: u64,
+}
+
+#[frb(mirror(ContactSuggestionKind))]
+pub enum _ContactSuggestionKind {
+    LightningAddress,
+    SparkAddress,
+    LnurlDomain,
 }
 
 #[frb(mirror(ListContactsRequest))]
@@ -1801,6 +3201,20 @@ pub struct _UnregisterWebhookRequest {
     pub webhook_id: String,
 }
 
+#[frb(mirror(HandleNotificationPayloadRequest))]
+pub struct _HandleNotificationPayloadRequest {
+    pub config: Config,
+    pub seed: Seed,
+    pub storage_dir: String,
+    pub payload: String,
+}
+
+#[frb(mirror(HandleNotificationPayloadResponse))]
+pub struct _HandleNotificationPayloadResponse {
+    pub event_type: WebhookEventType,
+    pub payments: Vec<Payment>,
+}
+
 #[frb(mirror(PasskeyProviderOptions))]
 pub struct _PasskeyProviderOptions {
     pub rp_id: Option<String>,
@@ -1889,3 +3303,186 @@ pub struct _ConnectWithPasskeyResponse {
     pub credential: Option<PasskeyCredential>,
     pub labels: Vec<String>,
 }
+
+#[frb(mirror(FreezeWalletRequest))]
+pub struct _FreezeWalletRequest {
+    pub password: String,
+}
+
+#[frb(mirror(UnfreezeWalletRequest))]
+pub struct _UnfreezeWalletRequest {
+    pub password: String,
+}
+
+#[frb(mirror(GetSeedBackupChallengeRequest))]
+pub struct _GetSeedBackupChallengeRequest {
+    pub word_count: Option<u32>,
+}
+
+#[frb(mirror(GetSeedBackupChallengeResponse))]
+pub struct _GetSeedBackupChallengeResponse {
+    pub positions: Vec<u32>,
+    pub seed_word_count: u32,
+}
+
+#[frb(mirror(SeedBackupWord))]
+pub struct _SeedBackupWord {
+    pub position: u32,
+    pub word: String,
+}
+
+#[frb(mirror(VerifySeedBackupRequest))]
+pub struct _VerifySeedBackupRequest {
+    pub words: Vec<SeedBackupWord>,
+}
+
+#[frb(mirror(VerifySeedBackupResponse))]
+pub struct _VerifySeedBackupResponse {
+    pub verified: bool,
+}
+
+#[frb(mirror(SignerSelfTestResponse))]
+pub struct _SignerSelfTestResponse {
+    pub passed: bool,
+    pub error: Option<String>,
+}
+
+#[frb(mirror(DeriveApplicationKeyRequest))]
+pub struct _DeriveApplicationKeyRequest {
+    pub label: String,
+}
+
+#[frb(mirror(DeriveApplicationKeyResponse))]
+pub struct _DeriveApplicationKeyResponse {
+    pub private_key: String,
+    pub public_key: String,
+}
+
+#[frb(mirror(BackupStateRequest))]
+pub struct _BackupStateRequest {
+    pub url: String,
+}
+
+#[frb(mirror(BackupStateResponse))]
+pub struct _BackupStateResponse {
+    pub contact_count: u32,
+    pub backed_up_at: u64,
+}
+
+#[frb(mirror(RestoreStateRequest))]
+pub struct _RestoreStateRequest {
+    pub url: String,
+}
+
+#[frb(mirror(RestoreStateResponse))]
+pub struct _RestoreStateResponse {
+    pub contacts_restored: u32,
+    pub backed_up_at: u64,
+}
+
+#[frb(mirror(DeleteWalletDataResponse))]
+pub struct _DeleteWalletDataResponse {
+    pub lightning_address_deleted: bool,
+    pub labeled_lightning_addresses_deleted: u32,
+    pub payment_links_deleted: u32,
+    pub webhooks_deleted: u32,
+    pub sync_records_kept: bool,
+}
+
+#[frb(mirror(RequestTestFundsRequest))]
+pub struct _RequestTestFundsRequest {
+    pub amount_sats: u64,
+}
+
+#[frb(mirror(RequestTestFundsResponse))]
+pub struct _RequestTestFundsResponse {
+    pub address: String,
+    pub txid: String,
+}
+
+#[frb(mirror(DuressConfig))]
+pub struct _DuressConfig {
+    pub pin_hash: String,
+    pub decoy_account_number: u32,
+}
+
+#[frb(mirror(OnchainTransaction))]
+pub struct _OnchainTransaction {
+    pub tx_id: String,
+    pub kind: OnchainTransactionKind,
+    pub status: OnchainTransactionStatus,
+}
+
+#[frb(mirror(OnchainTransactionKind))]
+pub enum _OnchainTransactionKind {
+    Withdrawal {
+        payment_id: String,
+    },
+    DepositRefund {
+        deposit_txid: String,
+        deposit_vout: u32,
+    },
+    UnilateralExit {
+        tx_kind: UnilateralExitTxKind,
+        node_id: Option<String>,
+    },
+}
+
+#[frb(mirror(OnchainTransactionStatus))]
+pub enum _OnchainTransactionStatus {
+    Broadcast,
+    InMempool,
+    Confirmed {
+        block_height: u32,
+        confirmations: Option<u32>,
+    },
+    Evicted,
+    Replaced {
+        replacement_tx_id: String,
+    },
+}
+
+#[frb(mirror(ListOnchainTransactionsRequest))]
+pub struct _ListOnchainTransactionsRequest {}
+
+#[frb(mirror(ListOnchainTransactionsResponse))]
+pub struct _ListOnchainTransactionsResponse {
+    pub transactions: Vec<OnchainTransaction>,
+}
+
+#[frb(mirror(PaymentRail))]
+pub enum _PaymentRail {
+    Lightning,
+    Bitcoin,
+    Spark,
+}
+
+#[frb(mirror(SpendingCap))]
+pub struct _SpendingCap {
+    pub scope: SpendingCapScope,
+    pub max_amount: u128,
+    pub window_secs: u64,
+}
+
+#[frb(mirror(SpendingCapScope))]
+pub enum _SpendingCapScope {
+    Rail { rail: PaymentRail },
+    Token { token_identifier: String },
+    Plugins,
+}
+
+#[frb(mirror(GetRemainingAllowanceRequest))]
+pub struct _GetRemainingAllowanceRequest {}
+
+#[frb(mirror(SpendingAllowance))]
+pub struct _SpendingAllowance {
+    pub cap: SpendingCap,
+    pub spent: u128,
+    pub remaining: u128,
+    pub next_release_at: Option<u64>,
+}
+
+#[frb(mirror(GetRemainingAllowanceResponse))]
+pub struct _GetRemainingAllowanceResponse {
+    pub allowances: Vec<SpendingAllowance>,
+}
@@ -4,9 +4,10 @@ use breez_sdk_spark::*;
 use flutter_rust_bridge::{DartFnFuture, frb};
 
 use crate::events::BindingEventListener;
-use crate::exit_signer::CallbackCpfpSigner;
+use crate::exit_signer::{CallbackCpfpSigner, CallbackPsbtSigner};
 use crate::frb_generated::StreamSink;
 use crate::logger::BindingLogger;
+use crate::middleware::CallbackPaymentMiddleware;
 
 pub async fn get_spark_status() -> Result<SparkStatus, SdkError> {
     breez_sdk_spark::get_spark_status().await
@@ -19,6 +20,19 @@ pub async fn connect(request: ConnectRequest) -> Result<BreezSdk, SdkError> {
     })
 }
 
+pub async fn connect_for_claiming(request: ConnectRequest) -> Result<BreezSdk, SdkError> {
+    let sdk = breez_sdk_spark::connect_for_claiming(request).await?;
+    Ok(BreezSdk {
+        inner: Arc::new(sdk),
+    })
+}
+
+pub async fn handle_notification_payload(
+    request: HandleNotificationPayloadRequest,
+) -> Result<HandleNotificationPayloadResponse, SdkError> {
+    breez_sdk_spark::handle_notification_payload(request).await
+}
+
 #[frb(sync)]
 pub fn default_config(network: Network) -> Config {
     breez_sdk_spark::default_config(network)
@@ -29,6 +43,41 @@ pub fn default_server_config(network: Network) -> Config {
     breez_sdk_spark::default_server_config(network)
 }
 
+#[frb(sync)]
+pub fn create_duress_config(
+    duress_pin: String,
+    decoy_account_number: u32,
+) -> Result<DuressConfig, SdkError> {
+    breez_sdk_spark::create_duress_config(duress_pin, decoy_account_number)
+}
+
+#[frb(sync)]
+pub fn is_duress_pin(duress_config: DuressConfig, pin: String) -> bool {
+    breez_sdk_spark::is_duress_pin(duress_config, pin)
+}
+
+#[frb(sync)]
+pub fn amount_to_base_units(
+    amount: String,
+    token_metadata: TokenMetadata,
+    rounding: AmountRounding,
+) -> Result<u128, SdkError> {
+    breez_sdk_spark::amount_to_base_units(amount, token_metadata, rounding)
+}
+
+#[frb(sync)]
+pub fn base_units_to_amount(base_units: u128, token_metadata: TokenMetadata) -> String {
+    breez_sdk_spark::base_units_to_amount(base_units, token_metadata)
+}
+
+#[frb(sync)]
+pub fn decrypt_success_action(
+    data: AesSuccessActionData,
+    preimage: String,
+) -> AesSuccessActionDataResult {
+    breez_sdk_spark::decrypt_success_action(data, preimage)
+}
+
 #[frb(sync)]
 pub fn init_logging(
     log_dir: Option<String>,
@@ -39,6 +88,15 @@ pub fn init_logging(
     breez_sdk_spark::init_logging(log_dir, Some(app_logger), log_filter)
 }
 
+#[frb(sync)]
+pub fn init_logging_with_config(
+    config: LoggingConfig,
+    app_logger: StreamSink<LogEntry>,
+) -> Result<(), SdkError> {
+    let app_logger: Box<dyn Logger> = Box::new(BindingLogger { logger: app_logger });
+    breez_sdk_spark::init_logging_with_config(config, Some(app_logger))
+}
+
 pub struct BreezSdk {
     pub(crate) inner: Arc<breez_sdk_spark::BreezSdk>,
 }
@@ -54,6 +112,24 @@ impl BreezSdk {
         self.inner.remove_event_listener(id).await
     }
 
+    /// Registers payment middleware. The `on_stage` callback runs at each
+    /// stage of the send and receive flows, see
+    /// [`breez_sdk_spark::BreezSdk::add_payment_middleware`].
+    pub async fn add_payment_middleware(
+        &self,
+        on_stage: impl Fn(PaymentStage) -> DartFnFuture<MiddlewareDecision> + Send + Sync + 'static,
+    ) -> String {
+        self.inner
+            .add_payment_middleware(Box::new(CallbackPaymentMiddleware {
+                on_stage: Arc::new(on_stage),
+            }))
+            .await
+    }
+
+    pub async fn remove_payment_middleware(&self, id: &str) -> bool {
+        self.inner.remove_payment_middleware(id).await
+    }
+
     pub async fn disconnect(&self) -> Result<(), SdkError> {
         self.inner.disconnect().await
     }
@@ -62,6 +138,18 @@ impl BreezSdk {
         self.inner.parse(input).await
     }
 
+    #[frb(sync)]
+    pub fn parse_offline(&self, input: &str) -> Result<InputType, SdkError> {
+        self.inner.parse_offline(input)
+    }
+
+    pub async fn handle_incoming_uri(
+        &self,
+        request: HandleIncomingUriRequest,
+    ) -> Result<HandleIncomingUriResponse, SdkError> {
+        self.inner.handle_incoming_uri(request).await
+    }
+
     pub async fn get_cross_chain_routes(
         &self,
         filter: CrossChainRouteFilter,
@@ -106,10 +194,7 @@ impl BreezSdk {
     pub async fn unilateral_exit_with_signer(
         &self,
         request: UnilateralExitRequest,
-        sign_psbt: impl Fn(Vec<u8>) -> DartFnFuture<anyhow::Result<Vec<u8>>>
-        + Send
-        + Sync
-        + 'static,
+        sign_psbt: impl Fn(Vec<u8>) -> DartFnFuture<anyhow::Result<Vec<u8>>> + Send + Sync + 'static,
     ) -> Result<UnilateralExitResponse, SdkError> {
         let signer = Arc::new(CallbackCpfpSigner {
             sign_psbt: Arc::new(sign_psbt),
@@ -117,6 +202,38 @@ impl BreezSdk {
         self.inner.unilateral_exit(request, signer).await
     }
 
+    /// Builds and signs the unilateral exit with a signer that takes base64
+    /// PSBTs, such as a hardware wallet. The `sign_psbt` callback receives the
+    /// base64 CPFP PSBT and returns it signed; P2WPKH and P2TR key-path
+    /// signatures don't need to be finalized.
+    pub async fn unilateral_exit_with_psbt_signer(
+        &self,
+        request: UnilateralExitRequest,
+        sign_psbt: impl Fn(String) -> DartFnFuture<anyhow::Result<String>> + Send + Sync + 'static,
+    ) -> Result<UnilateralExitResponse, SdkError> {
+        let signer = breez_sdk_spark::signer::psbt_cpfp_signer(Arc::new(CallbackPsbtSigner {
+            sign_psbt: Arc::new(sign_psbt),
+        }));
+        self.inner.unilateral_exit(request, signer).await
+    }
+
+    /// Returns the last built unilateral exit, with its progress updated from
+    /// the chain.
+    pub async fn get_unilateral_exit_progress(
+        &self,
+    ) -> Result<GetUnilateralExitProgressResponse, SdkError> {
+        self.inner.get_unilateral_exit_progress().await
+    }
+
+    /// Lists the monitored withdrawals and deposit refunds with their
+    /// on-chain status.
+    pub async fn list_onchain_transactions(
+        &self,
+        request: ListOnchainTransactionsRequest,
+    ) -> Result<ListOnchainTransactionsResponse, SdkError> {
+        self.inner.list_onchain_transactions(request).await
+    }
+
     pub async fn receive_payment(
         &self,
         request: ReceivePaymentRequest,
@@ -131,6 +248,136 @@ impl BreezSdk {
         self.inner.claim_htlc_payment(request).await
     }
 
+    pub async fn settle_held_payment(
+        &self,
+        request: SettleHeldPaymentRequest,
+    ) -> Result<SettleHeldPaymentResponse, SdkError> {
+        self.inner.settle_held_payment(request).await
+    }
+
+    pub async fn cancel_held_payment(
+        &self,
+        request: CancelHeldPaymentRequest,
+    ) -> Result<(), SdkError> {
+        self.inner.cancel_held_payment(request).await
+    }
+
+    pub async fn refund_htlc_payment(
+        &self,
+        request: RefundHtlcPaymentRequest,
+    ) -> Result<RefundHtlcPaymentResponse, SdkError> {
+        self.inner.refund_htlc_payment(request).await
+    }
+
+    pub async fn create_escrow(
+        &self,
+        request: CreateEscrowRequest,
+    ) -> Result<CreateEscrowResponse, SdkError> {
+        self.inner.create_escrow(request).await
+    }
+
+    pub async fn get_escrow(
+        &self,
+        request: GetEscrowRequest,
+    ) -> Result<GetEscrowResponse, SdkError> {
+        self.inner.get_escrow(request).await
+    }
+
+    pub async fn list_escrows(&self) -> Result<ListEscrowsResponse, SdkError> {
+        self.inner.list_escrows().await
+    }
+
+    pub async fn release_escrow(
+        &self,
+        request: ReleaseEscrowRequest,
+    ) -> Result<ReleaseEscrowResponse, SdkError> {
+        self.inner.release_escrow(request).await
+    }
+
+    pub async fn refund_escrow(
+        &self,
+        request: RefundEscrowRequest,
+    ) -> Result<RefundEscrowResponse, SdkError> {
+        self.inner.refund_escrow(request).await
+    }
+
+    pub async fn create_arbitrated_escrow(
+        &self,
+        request: CreateArbitratedEscrowRequest,
+    ) -> Result<CreateArbitratedEscrowResponse, SdkError> {
+        self.inner.create_arbitrated_escrow(request).await
+    }
+
+    pub async fn list_arbitrated_escrows(&self) -> Result<ListArbitratedEscrowsResponse, SdkError> {
+        self.inner.list_arbitrated_escrows().await
+    }
+
+    pub async fn release_arbitrated_escrow(
+        &self,
+        request: ReleaseArbitratedEscrowRequest,
+    ) -> Result<ReleaseArbitratedEscrowResponse, SdkError> {
+        self.inner.release_arbitrated_escrow(request).await
+    }
+
+    pub async fn dispute_arbitrated_escrow(
+        &self,
+        request: DisputeArbitratedEscrowRequest,
+    ) -> Result<DisputeArbitratedEscrowResponse, SdkError> {
+        self.inner.dispute_arbitrated_escrow(request).await
+    }
+
+    pub async fn refund_arbitrated_escrow(
+        &self,
+        request: RefundArbitratedEscrowRequest,
+    ) -> Result<RefundArbitratedEscrowResponse, SdkError> {
+        self.inner.refund_arbitrated_escrow(request).await
+    }
+
+    pub fn recover_arbitrated_escrow_preimage(
+        &self,
+        request: RecoverArbitratedEscrowPreimageRequest,
+    ) -> Result<RecoverArbitratedEscrowPreimageResponse, SdkError> {
+        self.inner.recover_arbitrated_escrow_preimage(request)
+    }
+
+    pub async fn list_time_locked_payments(
+        &self,
+    ) -> Result<ListTimeLockedPaymentsResponse, SdkError> {
+        self.inner.list_time_locked_payments().await
+    }
+
+    pub async fn cancel_time_locked_payment(
+        &self,
+        request: CancelTimeLockedPaymentRequest,
+    ) -> Result<CancelTimeLockedPaymentResponse, SdkError> {
+        self.inner.cancel_time_locked_payment(request).await
+    }
+
+    pub async fn open_payment_stream(
+        &self,
+        request: OpenPaymentStreamRequest,
+    ) -> Result<OpenPaymentStreamResponse, SdkError> {
+        self.inner.open_payment_stream(request).await
+    }
+
+    pub async fn close_payment_stream(
+        &self,
+        request: ClosePaymentStreamRequest,
+    ) -> Result<ClosePaymentStreamResponse, SdkError> {
+        self.inner.close_payment_stream(request).await
+    }
+
+    pub async fn list_payment_streams(&self) -> Result<ListPaymentStreamsResponse, SdkError> {
+        self.inner.list_payment_streams().await
+    }
+
+    pub async fn claim_specific_transfer(
+        &self,
+        request: ClaimSpecificTransferRequest,
+    ) -> Result<ClaimSpecificTransferResponse, SdkError> {
+        self.inner.claim_specific_transfer(request).await
+    }
+
     pub async fn prepare_lnurl_pay(
         &self,
         request: PrepareLnurlPayRequest,
@@ -191,6 +438,41 @@ impl BreezSdk {
         self.inner.send_payment(request).await
     }
 
+    pub async fn send_payment_async(
+        &self,
+        request: SendPaymentRequest,
+    ) -> Result<PaymentHandle, SdkError> {
+        self.inner.send_payment_async(request).await
+    }
+
+    pub async fn cancel_pending_payment(
+        &self,
+        request: CancelPendingPaymentRequest,
+    ) -> Result<(), SdkError> {
+        self.inner.cancel_pending_payment(request).await
+    }
+
+    pub async fn cancel_payment(
+        &self,
+        request: CancelPaymentRequest,
+    ) -> Result<CancelPaymentResponse, SdkError> {
+        self.inner.cancel_payment(request).await
+    }
+
+    pub async fn simulate_send_payment(
+        &self,
+        request: SimulateSendPaymentRequest,
+    ) -> Result<SimulateSendPaymentResponse, SdkError> {
+        self.inner.simulate_send_payment(request).await
+    }
+
+    pub async fn get_remaining_allowance(
+        &self,
+        request: GetRemainingAllowanceRequest,
+    ) -> Result<GetRemainingAllowanceResponse, SdkError> {
+        self.inner.get_remaining_allowance(request).await
+    }
+
     pub async fn publish_signed_transfer_package(
         &self,
         request: PublishSignedTransferPackageRequest,
@@ -205,6 +487,18 @@ impl BreezSdk {
         self.inner.sync_wallet(request).await
     }
 
+    pub async fn get_sync_status(&self) -> Result<GetSyncStatusResponse, SdkError> {
+        self.inner.get_sync_status().await
+    }
+
+    pub async fn list_devices(&self) -> Result<ListDevicesResponse, SdkError> {
+        self.inner.list_devices().await
+    }
+
+    pub async fn set_device_name(&self, request: SetDeviceNameRequest) -> Result<(), SdkError> {
+        self.inner.set_device_name(request).await
+    }
+
     pub async fn list_payments(
         &self,
         request: ListPaymentsRequest,
@@ -219,6 +513,92 @@ impl BreezSdk {
         self.inner.get_payment(request).await
     }
 
+    pub async fn get_ledger(
+        &self,
+        request: GetLedgerRequest,
+    ) -> Result<GetLedgerResponse, SdkError> {
+        self.inner.get_ledger(request).await
+    }
+
+    pub async fn export_ledger(
+        &self,
+        request: ExportLedgerRequest,
+    ) -> Result<ExportLedgerResponse, SdkError> {
+        self.inner.export_ledger(request).await
+    }
+
+    pub async fn get_token_activity(
+        &self,
+        request: GetTokenActivityRequest,
+    ) -> Result<GetTokenActivityResponse, SdkError> {
+        self.inner.get_token_activity(request).await
+    }
+
+    pub async fn export_payments(
+        &self,
+        request: ExportPaymentsRequest,
+    ) -> Result<ExportPaymentsResponse, SdkError> {
+        self.inner.export_payments(request).await
+    }
+
+    pub async fn get_accounting_report(
+        &self,
+        request: GetAccountingReportRequest,
+    ) -> Result<GetAccountingReportResponse, SdkError> {
+        self.inner.get_accounting_report(request).await
+    }
+
+    pub async fn get_seed_backup_challenge(
+        &self,
+        request: GetSeedBackupChallengeRequest,
+    ) -> Result<GetSeedBackupChallengeResponse, SdkError> {
+        self.inner.get_seed_backup_challenge(request).await
+    }
+
+    pub async fn verify_seed_backup(
+        &self,
+        request: VerifySeedBackupRequest,
+    ) -> Result<VerifySeedBackupResponse, SdkError> {
+        self.inner.verify_seed_backup(request).await
+    }
+
+    pub async fn run_signer_self_test(&self) -> Result<SignerSelfTestResponse, SdkError> {
+        self.inner.run_signer_self_test().await
+    }
+
+    pub async fn derive_application_key(
+        &self,
+        request: DeriveApplicationKeyRequest,
+    ) -> Result<DeriveApplicationKeyResponse, SdkError> {
+        self.inner.derive_application_key(request).await
+    }
+
+    pub async fn backup_state(
+        &self,
+        request: BackupStateRequest,
+    ) -> Result<BackupStateResponse, SdkError> {
+        self.inner.backup_state(request).await
+    }
+
+    pub async fn restore_state(
+        &self,
+        request: RestoreStateRequest,
+    ) -> Result<RestoreStateResponse, SdkError> {
+        self.inner.restore_state(request).await
+    }
+
+    pub async fn delete_wallet_data(&self) -> Result<DeleteWalletDataResponse, SdkError> {
+        self.inner.delete_wallet_data().await
+    }
+
+    pub async fn freeze_wallet(&self, request: FreezeWalletRequest) -> Result<(), SdkError> {
+        self.inner.freeze_wallet(request).await
+    }
+
+    pub async fn unfreeze_wallet(&self, request: UnfreezeWalletRequest) -> Result<(), SdkError> {
+        self.inner.unfreeze_wallet(request).await
+    }
+
     pub async fn claim_deposit(
         &self,
         request: ClaimDepositRequest,
@@ -226,6 +606,13 @@ impl BreezSdk {
         self.inner.claim_deposit(request).await
     }
 
+    pub async fn claim_deposits(
+        &self,
+        request: ClaimDepositsRequest,
+    ) -> Result<ClaimDepositsResponse, SdkError> {
+        self.inner.claim_deposits(request).await
+    }
+
     pub async fn refund_deposit(
         &self,
         request: RefundDepositRequest,
@@ -233,6 +620,13 @@ impl BreezSdk {
         self.inner.refund_deposit(request).await
     }
 
+    pub async fn bump_refund_fee(
+        &self,
+        request: BumpRefundFeeRequest,
+    ) -> Result<BumpRefundFeeResponse, SdkError> {
+        self.inner.bump_refund_fee(request).await
+    }
+
     pub async fn list_unclaimed_deposits(
         &self,
         request: ListUnclaimedDepositsRequest,
@@ -240,6 +634,19 @@ impl BreezSdk {
         self.inner.list_unclaimed_deposits(request).await
     }
 
+    pub async fn get_deposit_address_history(
+        &self,
+    ) -> Result<GetDepositAddressHistoryResponse, SdkError> {
+        self.inner.get_deposit_address_history().await
+    }
+
+    pub async fn request_test_funds(
+        &self,
+        request: RequestTestFundsRequest,
+    ) -> Result<RequestTestFundsResponse, SdkError> {
+        self.inner.request_test_funds(request).await
+    }
+
     pub async fn check_lightning_address_available(
         &self,
         request: CheckLightningAddressRequest,
@@ -258,6 +665,10 @@ impl BreezSdk {
         self.inner.register_lightning_address(request).await
     }
 
+    pub async fn list_lightning_addresses(&self) -> Result<Vec<LightningAddressInfo>, SdkError> {
+        self.inner.list_lightning_addresses().await
+    }
+
     pub async fn authorize_lightning_address_transfer(
         &self,
         request: AuthorizeTransferRequest,
@@ -278,6 +689,41 @@ impl BreezSdk {
         self.inner.delete_lightning_address().await
     }
 
+    pub async fn delete_labeled_lightning_address(
+        &self,
+        request: DeleteLabeledLightningAddressRequest,
+    ) -> Result<(), SdkError> {
+        self.inner.delete_labeled_lightning_address(request).await
+    }
+
+    pub async fn update_lightning_address_nostr_key(
+        &self,
+        request: UpdateLightningAddressNostrKeyRequest,
+    ) -> Result<(), SdkError> {
+        self.inner.update_lightning_address_nostr_key(request).await
+    }
+
+    pub async fn create_payment_link(
+        &self,
+        request: CreatePaymentLinkRequest,
+    ) -> Result<CreatePaymentLinkResponse, SdkError> {
+        self.inner.create_payment_link(request).await
+    }
+
+    pub async fn list_payment_links(
+        &self,
+        request: ListPaymentLinksRequest,
+    ) -> Result<ListPaymentLinksResponse, SdkError> {
+        self.inner.list_payment_links(request).await
+    }
+
+    pub async fn delete_payment_link(
+        &self,
+        request: DeletePaymentLinkRequest,
+    ) -> Result<(), SdkError> {
+        self.inner.delete_payment_link(request).await
+    }
+
     pub async fn list_fiat_currencies(&self) -> Result<ListFiatCurrenciesResponse, SdkError> {
         self.inner.list_fiat_currencies().await
     }
@@ -286,6 +732,84 @@ impl BreezSdk {
         self.inner.list_fiat_rates().await
     }
 
+    pub async fn fetch_historical_rates(
+        &self,
+        request: FetchHistoricalRatesRequest,
+    ) -> Result<FetchHistoricalRatesResponse, SdkError> {
+        self.inner.fetch_historical_rates(request).await
+    }
+
+    pub async fn get_token_fiat_rates(
+        &self,
+        request: GetTokenFiatRatesRequest,
+    ) -> Result<GetTokenFiatRatesResponse, SdkError> {
+        self.inner.get_token_fiat_rates(request).await
+    }
+
+    pub async fn lock_exchange_rate(
+        &self,
+        request: LockExchangeRateRequest,
+    ) -> Result<LockExchangeRateResponse, SdkError> {
+        self.inner.lock_exchange_rate(request).await
+    }
+
+    pub async fn convert_fiat_amount(
+        &self,
+        request: ConvertFiatAmountRequest,
+    ) -> Result<ConvertFiatAmountResponse, SdkError> {
+        self.inner.convert_fiat_amount(request).await
+    }
+
+    pub async fn receive_fiat_payment(
+        &self,
+        request: ReceiveFiatPaymentRequest,
+    ) -> Result<ReceiveFiatPaymentResponse, SdkError> {
+        self.inner.receive_fiat_payment(request).await
+    }
+
+    pub async fn set_log_filter(&self, request: SetLogFilterRequest) -> Result<(), SdkError> {
+        self.inner.set_log_filter(request).await
+    }
+
+    pub async fn dump_diagnostics(&self) -> Result<DumpDiagnosticsResponse, SdkError> {
+        self.inner.dump_diagnostics().await
+    }
+
+    pub async fn check_health(&self) -> Result<CheckHealthResponse, SdkError> {
+        self.inner.check_health().await
+    }
+
+    pub async fn get_service_status(&self) -> Result<ServiceStatusReport, SdkError> {
+        self.inner.get_service_status().await
+    }
+
+    pub async fn get_receive_onboarding_info(
+        &self,
+    ) -> Result<GetReceiveOnboardingInfoResponse, SdkError> {
+        self.inner.get_receive_onboarding_info().await
+    }
+
+    pub async fn start_job(&self, request: StartJobRequest) -> Result<StartJobResponse, SdkError> {
+        self.inner.start_job(request).await
+    }
+
+    pub async fn get_job(&self, request: GetJobRequest) -> Result<GetJobResponse, SdkError> {
+        self.inner.get_job(request).await
+    }
+
+    pub async fn list_jobs(&self) -> Result<ListJobsResponse, SdkError> {
+        self.inner.list_jobs().await
+    }
+
+    pub async fn cancel_job(&self, request: CancelJobRequest) -> Result<(), SdkError> {
+        self.inner.cancel_job(request).await
+    }
+
+    #[frb(sync)]
+    pub fn list_plugins(&self) -> Vec<PluginInfo> {
+        self.inner.list_plugins()
+    }
+
     pub async fn recommended_fees(&self) -> Result<RecommendedFees, SdkError> {
         self.inner.recommended_fees().await
     }
@@ -297,6 +821,13 @@ impl BreezSdk {
         self.inner.get_tokens_metadata(request).await
     }
 
+    pub async fn refresh_tokens_metadata(
+        &self,
+        request: RefreshTokensMetadataRequest,
+    ) -> Result<RefreshTokensMetadataResponse, SdkError> {
+        self.inner.refresh_tokens_metadata(request).await
+    }
+
     pub async fn sign_message(
         &self,
         request: SignMessageRequest,
@@ -322,6 +853,14 @@ impl BreezSdk {
         self.inner.update_user_settings(request).await
     }
 
+    pub async fn hide_token(&self, request: HideTokenRequest) -> Result<(), SdkError> {
+        self.inner.hide_token(request).await
+    }
+
+    pub async fn unhide_token(&self, request: UnhideTokenRequest) -> Result<(), SdkError> {
+        self.inner.unhide_token(request).await
+    }
+
     #[frb(sync)]
     pub fn get_token_issuer(&self) -> crate::issuer::TokenIssuer {
         let token_issuer = self.inner.get_token_issuer();
@@ -337,6 +876,28 @@ impl BreezSdk {
         self.inner.optimize_leaves(request).await
     }
 
+    pub async fn list_leaves(&self) -> Result<ListLeavesResponse, SdkError> {
+        self.inner.list_leaves().await
+    }
+
+    pub async fn set_host_conditions(&self, conditions: HostConditions) {
+        self.inner.set_host_conditions(conditions).await;
+    }
+
+    #[frb(sync)]
+    pub fn pause_background_sync(&self) {
+        self.inner.pause_background_sync();
+    }
+
+    pub async fn resume_background_sync(&self) {
+        self.inner.resume_background_sync().await;
+    }
+
+    #[frb(sync)]
+    pub fn is_background_sync_paused(&self) -> bool {
+        self.inner.is_background_sync_paused()
+    }
+
     pub async fn fetch_conversion_limits(
         &self,
         request: FetchConversionLimitsRequest,
@@ -391,4 +952,18 @@ impl BreezSdk {
     ) -> Result<Vec<Contact>, SdkError> {
         self.inner.list_contacts(request).await
     }
+
+    pub async fn get_contact_payments(
+        &self,
+        request: GetContactPaymentsRequest,
+    ) -> Result<GetContactPaymentsResponse, SdkError> {
+        self.inner.get_contact_payments(request).await
+    }
+
+    pub async fn suggest_contacts(
+        &self,
+        request: SuggestContactsRequest,
+    ) -> Result<SuggestContactsResponse, SdkError> {
+        self.inner.suggest_contacts(request).await
+    }
 }
@@ -1,10 +1,14 @@
 use std::sync::Arc;
 
-use breez_sdk_spark::{ChainApiType, Config, Credentials, SdkError, Seed};
-use flutter_rust_bridge::frb;
+use breez_sdk_spark::{
+    ChainApiType, Config, Credentials, DuressConfig, InputParserInfo, InputType,
+    ProvisionalPayment, SdkError, Seed, SendApproval,
+};
+use flutter_rust_bridge::{DartFnFuture, frb};
 
 use crate::{
-    chain_service::BitcoinChainServiceHandle, sdk::BreezSdk, sdk_context::SdkContext,
+    chain_service::BitcoinChainServiceHandle, input_parser::CallbackInputParser, sdk::BreezSdk,
+    sdk_context::SdkContext, send_approver::CallbackSendApprover,
 };
 
 pub struct SdkBuilder {
@@ -37,6 +41,33 @@ impl SdkBuilder {
         }
     }
 
+    #[frb(sync)]
+    pub fn with_background_sync_paused(self) -> Self {
+        let builder = <breez_sdk_spark::SdkBuilder as Clone>::clone(&self.inner)
+            .with_background_sync_paused();
+        Self {
+            inner: Arc::new(builder),
+        }
+    }
+
+    #[frb(sync)]
+    pub fn with_nostr_sync_storage(self, relays: Vec<String>) -> Self {
+        let builder = <breez_sdk_spark::SdkBuilder as Clone>::clone(&self.inner)
+            .with_nostr_sync_storage(relays);
+        Self {
+            inner: Arc::new(builder),
+        }
+    }
+
+    #[frb(sync)]
+    pub fn with_duress(self, duress_config: DuressConfig, pin: String) -> Self {
+        let builder = <breez_sdk_spark::SdkBuilder as Clone>::clone(&self.inner)
+            .with_duress(duress_config, pin);
+        Self {
+            inner: Arc::new(builder),
+        }
+    }
+
     #[frb(sync)]
     pub fn with_rest_chain_service(
         self,
@@ -78,6 +109,41 @@ impl SdkBuilder {
         }
     }
 
+    /// Sets a send approver. The `approve` callback is asked to approve every
+    /// outgoing payment before funds move, see
+    /// [`breez_sdk_spark::SendApprover`].
+    pub fn with_send_approver(
+        self,
+        approve: impl Fn(ProvisionalPayment) -> DartFnFuture<SendApproval> + Send + Sync + 'static,
+    ) -> Self {
+        let builder = <breez_sdk_spark::SdkBuilder as Clone>::clone(&self.inner)
+            .with_send_approver(Arc::new(CallbackSendApprover {
+                approve: Arc::new(approve),
+            }));
+        Self {
+            inner: Arc::new(builder),
+        }
+    }
+
+    /// Registers an input parser. The `parse` callback resolves the inputs
+    /// the SDK doesn't recognize, or returns `null` to leave them unparsed,
+    /// see [`breez_sdk_spark::InputParser`].
+    pub fn with_input_parser(
+        self,
+        info: InputParserInfo,
+        parse: impl Fn(String) -> DartFnFuture<Option<InputType>> + Send + Sync + 'static,
+    ) -> Self {
+        let builder = <breez_sdk_spark::SdkBuilder as Clone>::clone(&self.inner).with_input_parser(
+            Arc::new(CallbackInputParser {
+                info,
+                parse: Arc::new(parse),
+            }),
+        );
+        Self {
+            inner: Arc::new(builder),
+        }
+    }
+
     pub async fn build(&self) -> Result<BreezSdk, SdkError> {
         let sdk = <breez_sdk_spark::SdkBuilder as Clone>::clone(&self.inner)
             .build()
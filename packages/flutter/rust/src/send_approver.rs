@@ -0,0 +1,58 @@
+use std::panic::AssertUnwindSafe;
+use std::sync::Arc;
+
+use breez_sdk_spark::{PaymentObserverError, SendApprover};
+pub use breez_sdk_spark::{ProvisionalPayment, ProvisionalPaymentDetails, SendApproval};
+use flutter_rust_bridge::{DartFnFuture, frb};
+use futures::FutureExt;
+
+#[frb(mirror(ProvisionalPayment))]
+pub struct _ProvisionalPayment {
+    pub payment_id: String,
+    pub amount: u128,
+    pub details: ProvisionalPaymentDetails,
+}
+
+#[frb(mirror(ProvisionalPaymentDetails))]
+pub enum _ProvisionalPaymentDetails {
+    Bitcoin {
+        withdrawal_address: String,
+    },
+    Lightning {
+        invoice: String,
+    },
+    Spark {
+        pay_request: String,
+    },
+    Token {
+        token_id: String,
+        pay_request: String,
+    },
+}
+
+#[frb(mirror(SendApproval))]
+pub enum _SendApproval {
+    Approved,
+    Rejected { reason: String },
+}
+
+/// Wraps a Dart `approve` callback as a [`SendApprover`]. A Dart-side throw
+/// rejects the payment, so a failing check can't be bypassed.
+pub(crate) struct CallbackSendApprover {
+    pub(crate) approve: Arc<dyn Fn(ProvisionalPayment) -> DartFnFuture<SendApproval> + Send + Sync>,
+}
+
+#[async_trait::async_trait]
+impl SendApprover for CallbackSendApprover {
+    async fn approve(
+        &self,
+        payment: ProvisionalPayment,
+    ) -> Result<SendApproval, PaymentObserverError> {
+        Ok(AssertUnwindSafe((self.approve)(payment))
+            .catch_unwind()
+            .await
+            .unwrap_or_else(|_| SendApproval::Rejected {
+                reason: "Dart send approver callback panicked".to_string(),
+            }))
+    }
+}